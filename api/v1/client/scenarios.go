@@ -0,0 +1,21 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	v1 "go.k6.io/k6/api/v1"
+)
+
+// StartScenario starts the dormant scenario with the provided name.
+func (c *Client) StartScenario(ctx context.Context, name string) (ret v1.Scenario, err error) {
+	var resp v1.ScenarioJSONAPI
+
+	apiURL := &url.URL{Path: "/v1/scenarios/" + name + "/start"}
+	if err = c.CallAPI(ctx, http.MethodPost, apiURL, nil, &resp); err != nil {
+		return ret, err
+	}
+
+	return resp.Scenario(), nil
+}
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"context"
+	"time"
 
 	"go.k6.io/k6/execution"
 	"go.k6.io/k6/lib"
@@ -18,3 +19,21 @@ type ControlSurface struct {
 	Scheduler     *execution.Scheduler
 	RunState      *lib.TestRunState
 }
+
+// summaryTrendStats returns the trend stats configured for the test run,
+// or nil if they are not available.
+func (cs *ControlSurface) summaryTrendStats() []string {
+	if cs.RunState == nil {
+		return nil
+	}
+	return cs.RunState.Options.SummaryTrendStats
+}
+
+// gaugeTTL returns the time after which the gauge metrics are considered stale,
+// or zero if they never expire.
+func (cs *ControlSurface) gaugeTTL() time.Duration {
+	if cs.RunState == nil {
+		return 0
+	}
+	return cs.RunState.Options.GaugeTTL.TimeDuration()
+}
@@ -21,17 +21,17 @@ type metricData struct {
 	Attributes Metric `json:"attributes"`
 }
 
-func newMetricEnvelope(m *metrics.Metric, t time.Duration) metricJSONAPI {
+func newMetricEnvelope(m *metrics.Metric, t time.Duration, trendStats []string) metricJSONAPI {
 	return metricJSONAPI{
-		Data: newMetricData(m, t),
+		Data: newMetricData(m, t, trendStats),
 	}
 }
 
-func newMetricsJSONAPI(list map[string]*metrics.Metric, t time.Duration) MetricsJSONAPI {
+func newMetricsJSONAPI(list map[string]*metrics.Metric, t time.Duration, trendStats []string) MetricsJSONAPI {
 	metrics := make([]metricData, 0, len(list))
 
 	for _, m := range list {
-		metrics = append(metrics, newMetricData(m, t))
+		metrics = append(metrics, newMetricData(m, t, trendStats))
 	}
 
 	return MetricsJSONAPI{
@@ -39,8 +39,17 @@ func newMetricsJSONAPI(list map[string]*metrics.Metric, t time.Duration) Metrics
 	}
 }
 
-func newMetricData(m *metrics.Metric, t time.Duration) metricData {
+// newMetricData returns the metric's data, if the trend stats are provided
+// then they are used for the trend metrics in place of the default ones.
+func newMetricData(m *metrics.Metric, t time.Duration, trendStats []string) metricData {
 	metric := NewMetric(m, t)
+	if sink, ok := m.Sink.(*metrics.TrendSink); ok && len(trendStats) > 0 {
+		// the trend stats are validated with the options,
+		// so an error is not expected here
+		if sample, err := sink.FormatTrendStats(trendStats); err == nil {
+			metric.Sample = sample
+		}
+	}
 
 	return metricData{
 		Type:       "metrics",
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"time"
+
+	"go.k6.io/k6/metrics"
 )
 
 func handleGetMetrics(cs *ControlSurface, rw http.ResponseWriter, _ *http.Request) {
@@ -13,7 +15,8 @@ func handleGetMetrics(cs *ControlSurface, rw http.ResponseWriter, _ *http.Reques
 	}
 
 	cs.MetricsEngine.MetricsLock.Lock()
-	metrics := newMetricsJSONAPI(cs.MetricsEngine.ObservedMetrics, t)
+	observed := withoutStaleGauges(cs.MetricsEngine.ObservedMetrics, time.Now(), cs.gaugeTTL())
+	metrics := newMetricsJSONAPI(observed, t, cs.summaryTrendStats())
 	cs.MetricsEngine.MetricsLock.Unlock()
 
 	data, err := json.Marshal(metrics)
@@ -32,12 +35,12 @@ func handleGetMetric(cs *ControlSurface, rw http.ResponseWriter, _ *http.Request
 
 	cs.MetricsEngine.MetricsLock.Lock()
 	metric, ok := cs.MetricsEngine.ObservedMetrics[id]
-	if !ok {
+	if !ok || isStaleGauge(metric, time.Now(), cs.gaugeTTL()) {
 		cs.MetricsEngine.MetricsLock.Unlock()
 		apiError(rw, "Not Found", "No metric with that ID was found", http.StatusNotFound)
 		return
 	}
-	wrappedMetric := newMetricEnvelope(metric, t)
+	wrappedMetric := newMetricEnvelope(metric, t, cs.summaryTrendStats())
 	cs.MetricsEngine.MetricsLock.Unlock()
 
 	data, err := json.Marshal(wrappedMetric)
@@ -47,3 +50,26 @@ func handleGetMetric(cs *ControlSurface, rw http.ResponseWriter, _ *http.Request
 	}
 	_, _ = rw.Write(data)
 }
+
+// withoutStaleGauges returns the metrics without the gauges
+// that haven't been updated during the last ttl.
+func withoutStaleGauges(list map[string]*metrics.Metric, now time.Time, ttl time.Duration) map[string]*metrics.Metric {
+	if ttl <= 0 {
+		return list
+	}
+
+	filtered := make(map[string]*metrics.Metric, len(list))
+	for name, m := range list {
+		if !isStaleGauge(m, now, ttl) {
+			filtered[name] = m
+		}
+	}
+	return filtered
+}
+
+// isStaleGauge returns true if the metric is a gauge
+// that hasn't been updated during the last ttl.
+func isStaleGauge(m *metrics.Metric, now time.Time, ttl time.Duration) bool {
+	sink, ok := m.Sink.(*metrics.GaugeSink)
+	return ok && sink.IsStale(now, ttl)
+}
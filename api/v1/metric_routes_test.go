@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -12,6 +13,7 @@ import (
 
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/testutils/minirunner"
+	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 )
 
@@ -71,6 +73,35 @@ func TestGetMetrics(t *testing.T) {
 	})
 }
 
+func TestGetMetricsSummaryTrendStats(t *testing.T) {
+	t.Parallel()
+
+	testState := getTestRunState(t, lib.Options{
+		SummaryTrendStats: []string{"count", "p(99.99)"},
+	}, &minirunner.MiniRunner{})
+	testMetric, err := testState.Registry.NewMetric("my_metric", metrics.Trend, metrics.Time)
+	require.NoError(t, err)
+	testMetric.Sink.Add(metrics.Sample{Value: 10})
+	testMetric.Sink.Add(metrics.Sample{Value: 20})
+
+	cs := getControlSurface(t, testState)
+	cs.MetricsEngine.ObservedMetrics = map[string]*metrics.Metric{
+		"my_metric": testMetric,
+	}
+
+	rw := httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/metrics/my_metric", nil))
+	res := rw.Result()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var envelop metricJSONAPI
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &envelop))
+	assert.Equal(t, map[string]float64{
+		"count":    2,
+		"p(99.99)": 10 + 10*0.9999,
+	}, envelop.Data.Attributes.Sample)
+}
+
 func TestGetMetric(t *testing.T) {
 	t.Parallel()
 
@@ -127,3 +158,36 @@ func TestGetMetric(t *testing.T) {
 		})
 	})
 }
+
+func TestGetMetricsStaleGauges(t *testing.T) {
+	t.Parallel()
+
+	testState := getTestRunState(t, lib.Options{
+		GaugeTTL: types.NullDurationFrom(time.Minute),
+	}, &minirunner.MiniRunner{})
+	staleGauge, err := testState.Registry.NewMetric("stale_gauge", metrics.Gauge)
+	require.NoError(t, err)
+	staleGauge.Sink.Add(metrics.Sample{Value: 1, Time: time.Now().Add(-time.Hour)})
+	currentGauge, err := testState.Registry.NewMetric("current_gauge", metrics.Gauge)
+	require.NoError(t, err)
+	currentGauge.Sink.Add(metrics.Sample{Value: 2, Time: time.Now()})
+
+	cs := getControlSurface(t, testState)
+	cs.MetricsEngine.ObservedMetrics = map[string]*metrics.Metric{
+		"stale_gauge":   staleGauge,
+		"current_gauge": currentGauge,
+	}
+
+	rw := httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/metrics", nil))
+	require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+
+	var envelop MetricsJSONAPI
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &envelop))
+	require.Len(t, envelop.Data, 1)
+	assert.Equal(t, "current_gauge", envelop.Data[0].ID)
+
+	rw = httptest.NewRecorder()
+	NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/metrics/stale_gauge", nil))
+	assert.Equal(t, http.StatusNotFound, rw.Result().StatusCode)
+}
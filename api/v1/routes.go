@@ -3,6 +3,7 @@ package v1
 
 import (
 	"net/http"
+	"strings"
 )
 
 // NewHandler returns the top handler for the v1 REST APIs
@@ -57,6 +58,20 @@ func NewHandler(cs *ControlSurface) http.Handler {
 		handleGetGroup(cs, rw, r, id)
 	})
 
+	mux.HandleFunc("/v1/scenarios/", func(rw http.ResponseWriter, r *http.Request) {
+		name, action, _ := strings.Cut(r.URL.Path[len("/v1/scenarios/"):], "/")
+		if action != "start" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method != http.MethodPost {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		handleStartScenario(cs, rw, r, name)
+	})
+
 	mux.HandleFunc("/v1/setup", func(rw http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
@@ -0,0 +1,38 @@
+package v1
+
+// Scenario contains the details of a scenario
+type Scenario struct {
+	Name     string `json:"-" yaml:"name"`
+	Executor string `json:"executor" yaml:"executor"`
+	Dormant  bool   `json:"dormant" yaml:"dormant"`
+	Started  bool   `json:"started" yaml:"started"`
+}
+
+// ScenarioJSONAPI is JSON API envelop for scenarios
+type ScenarioJSONAPI struct {
+	Data scenarioData `json:"data"`
+}
+
+type scenarioData struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Attributes Scenario `json:"attributes"`
+}
+
+// NewScenarioJSONAPI creates the JSON API scenario envelop
+func NewScenarioJSONAPI(s Scenario) ScenarioJSONAPI {
+	return ScenarioJSONAPI{
+		Data: scenarioData{
+			Type:       "scenarios",
+			ID:         s.Name,
+			Attributes: s,
+		},
+	}
+}
+
+// Scenario extracts the v1.Scenario from the JSON API envelop
+func (s ScenarioJSONAPI) Scenario() Scenario {
+	scenario := s.Data.Attributes
+	scenario.Name = s.Data.ID
+	return scenario
+}
@@ -0,0 +1,35 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// handleStartScenario starts the dormant scenario with the provided name
+func handleStartScenario(cs *ControlSurface, rw http.ResponseWriter, _ *http.Request, name string) {
+	var scenario *Scenario
+	for _, config := range cs.Scheduler.GetExecutorConfigs() {
+		if config.GetName() == name {
+			scenario = &Scenario{Name: name, Executor: config.GetType(), Dormant: config.IsDormant()}
+			break
+		}
+	}
+	if scenario == nil {
+		apiError(rw, "Not Found", "No scenario with that name was found", http.StatusNotFound)
+		return
+	}
+
+	if err := cs.Scheduler.StartScenario(name); err != nil {
+		apiError(rw, "Scenario start error", err.Error(), http.StatusBadRequest)
+		return
+	}
+	scenario.Started = true
+
+	data, err := json.Marshal(NewScenarioJSONAPI(*scenario))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = rw.Write(data)
+}
@@ -0,0 +1,58 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/minirunner"
+)
+
+func TestStartScenario(t *testing.T) {
+	t.Parallel()
+
+	scenarios := lib.ScenarioConfigs{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"baseline": {"executor": "constant-vus", "vus": 1, "duration": "10s"},
+		"spike": {"executor": "shared-iterations", "vus": 5, "iterations": 10, "dormant": true}
+	}`), &scenarios))
+	cs := getControlSurface(t, getTestRunState(t, lib.Options{Scenarios: scenarios}, &minirunner.MiniRunner{}))
+
+	startScenario := func(method, path string) *httptest.ResponseRecorder {
+		rw := httptest.NewRecorder()
+		NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(method, path, nil))
+		return rw
+	}
+
+	rw := startScenario(http.MethodPost, "/v1/scenarios/spike/start")
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rw.Header().Get("Content-Type"))
+
+	var doc ScenarioJSONAPI
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &doc))
+	assert.Equal(t, "scenarios", doc.Data.Type)
+	assert.Equal(t, Scenario{Name: "spike", Executor: "shared-iterations", Dormant: true, Started: true}, doc.Scenario())
+
+	testCases := map[string]struct {
+		method, path string
+		expCode      int
+	}{
+		"already started": {http.MethodPost, "/v1/scenarios/spike/start", http.StatusBadRequest},
+		"not dormant":     {http.MethodPost, "/v1/scenarios/baseline/start", http.StatusBadRequest},
+		"unknown":         {http.MethodPost, "/v1/scenarios/other/start", http.StatusNotFound},
+		"unknown action":  {http.MethodPost, "/v1/scenarios/spike/stop", http.StatusNotFound},
+		"wrong method":    {http.MethodGet, "/v1/scenarios/spike/start", http.StatusMethodNotAllowed},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expCode, startScenario(tc.method, tc.path).Code)
+		})
+	}
+}
@@ -49,6 +49,19 @@ func NewClient(logger logrus.FieldLogger, token, host, version string, timeout t
 	return c
 }
 
+// SetRetries sets the max number of attempts performed for each request,
+// and the interval between them. Setting retries to 1 disables retrying,
+// for callers implementing their own retry strategy.
+func (c *Client) SetRetries(retries int, interval time.Duration) {
+	c.retries = retries
+	c.retryInterval = interval
+}
+
+// SetTransport sets the transport used by the underlying HTTP client.
+func (c *Client) SetTransport(rt http.RoundTripper) {
+	c.client.Transport = rt
+}
+
 // BaseURL returns configured host.
 func (c *Client) BaseURL() string {
 	return c.baseURL
@@ -21,6 +21,19 @@ type Config struct {
 	Host    null.String        `json:"host" envconfig:"K6_CLOUD_HOST"`
 	Timeout types.NullDuration `json:"timeout" envconfig:"K6_CLOUD_TIMEOUT"`
 
+	// The URL of the HTTP proxy used for pushing the metrics. If it is not set,
+	// the proxy is resolved from the HTTP_PROXY and HTTPS_PROXY environment variables.
+	ProxyURL null.String `json:"proxyURL" envconfig:"K6_CLOUD_PROXY_URL"`
+
+	// The path of a PEM bundle of CA certificates trusted, in addition
+	// to the system ones, when pushing the metrics.
+	TLSCACert null.String `json:"tlsCACert" envconfig:"K6_CLOUD_TLS_CA_CERT"`
+
+	// The paths of the PEM client certificate and key used
+	// when pushing the metrics. They have to be set together.
+	TLSClientCert null.String `json:"tlsClientCert" envconfig:"K6_CLOUD_TLS_CLIENT_CERT"`
+	TLSClientKey  null.String `json:"tlsClientKey" envconfig:"K6_CLOUD_TLS_CLIENT_KEY"`
+
 	LogsTailURL    null.String `json:"-" envconfig:"K6_CLOUD_LOGS_TAIL_URL"`
 	WebAppURL      null.String `json:"webAppURL" envconfig:"K6_CLOUD_WEB_APP_URL"`
 	TestRunDetails null.String `json:"testRunDetails" envconfig:"K6_CLOUD_TEST_RUN_DETAILS"`
@@ -43,6 +56,62 @@ type Config struct {
 	// This is how many concurrent pushes will be done at the same time to the cloud
 	MetricPushConcurrency null.Int `json:"metricPushConcurrency" envconfig:"K6_CLOUD_METRIC_PUSH_CONCURRENCY"`
 
+	// The Content-Encoding used for compressing the metrics payloads,
+	// the supported values are snappy, gzip and zstd.
+	MetricPushEncoding null.String `json:"metricPushEncoding" envconfig:"K6_CLOUD_METRIC_PUSH_ENCODING"`
+
+	// The max number of aggregated time buckets waiting to be pushed to the cloud.
+	// When the pushes fall behind and the limit is hit, the oldest buckets are dropped.
+	// Zero means no limit.
+	MetricPushQueueSize null.Int `json:"metricPushQueueSize" envconfig:"K6_CLOUD_METRIC_PUSH_QUEUE_SIZE"`
+
+	// The policy applied when MetricPushMaxConsecutiveFailures consecutive flushes
+	// of the metrics fail: abort stops the test run, warn keeps running logging
+	// a warning, local-fallback writes the payloads to MetricPushFallbackDir.
+	MetricPushFailurePolicy null.String `json:"metricPushFailurePolicy" envconfig:"K6_CLOUD_METRIC_PUSH_FAILURE_POLICY"`
+
+	// The number of consecutive failed flushes of the metrics triggering the failure policy.
+	MetricPushMaxConsecutiveFailures null.Int `json:"metricPushMaxConsecutiveFailures" envconfig:"K6_CLOUD_METRIC_PUSH_MAX_CONSECUTIVE_FAILURES"`
+
+	// The directory where the metrics payloads are written by the local-fallback policy.
+	MetricPushFallbackDir null.String `json:"metricPushFallbackDir" envconfig:"K6_CLOUD_METRIC_PUSH_FALLBACK_DIR"`
+
+	// The max number of attempts for pushing a batch of metrics to the cloud,
+	// including the first one. Failed pushes are retried with an exponential backoff.
+	MetricPushMaxAttempts null.Int `json:"metricPushMaxAttempts" envconfig:"K6_CLOUD_METRIC_PUSH_MAX_ATTEMPTS"`
+
+	// The initial time interval to wait before retrying a failed metrics push.
+	// It doubles on each subsequent attempt, and a random jitter is applied to it.
+	MetricPushRetryInterval types.NullDuration `json:"metricPushRetryInterval" envconfig:"K6_CLOUD_METRIC_PUSH_RETRY_INTERVAL"`
+
+	// The max time spent retrying a failed metrics push, after which it is given up.
+	MetricPushRetryMaxElapsedTime types.NullDuration `json:"metricPushRetryMaxElapsedTime" envconfig:"K6_CLOUD_METRIC_PUSH_RETRY_MAX_ELAPSED_TIME"`
+
+	// Enables the encoding of the labels as references to a per-payload dictionary
+	// of strings, it requires the metrics protocol version 2.1.
+	MetricPushLabelDictionary null.Bool `json:"metricPushLabelDictionary" envconfig:"K6_CLOUD_METRIC_PUSH_LABEL_DICTIONARY"`
+
+	// The max time waited for the final flush of the metrics when the test stops.
+	MetricPushFinalFlushTimeout types.NullDuration `json:"metricPushFinalFlushTimeout" envconfig:"K6_CLOUD_METRIC_PUSH_FINAL_FLUSH_TIMEOUT"`
+
+	// The minimum resolution of the histograms used for aggregating the Trend metrics.
+	// The observed values are tracked as multiples of it, so it should be lowered
+	// for tracking sub-millisecond durations without losing precision.
+	TrendMinResolution null.Float `json:"trendMinResolution" envconfig:"K6_CLOUD_TREND_MIN_RESOLUTION"`
+
+	// The per-metric overrides of TrendMinResolution, as a comma-separated
+	// list of metric=resolution pairs, e.g. "http_req_waiting=0.0001,my_trend=1".
+	TrendMinResolutionOverrides null.String `json:"trendMinResolutionOverrides" envconfig:"K6_CLOUD_TREND_MIN_RESOLUTION_OVERRIDES"`
+
+	// The max number of active time series sent to the cloud, i.e. the ones with samples
+	// in the last 5 minutes, zero means no limit.
+	MaxActiveSeries null.Int `json:"maxActiveSeries" envconfig:"K6_CLOUD_MAX_ACTIVE_SERIES"`
+
+	// The policy applied to the samples of the new time series once MaxActiveSeries is exceeded:
+	// drop discards them, overflow aggregates them in a single time series per metric
+	// labeled with __overflow__.
+	ActiveSeriesLimitPolicy null.String `json:"activeSeriesLimitPolicy" envconfig:"K6_CLOUD_ACTIVE_SERIES_LIMIT_POLICY"`
+
 	// Indicates whether to send traces to the k6 Insights backend service.
 	TracesEnabled null.Bool `json:"tracesEnabled" envconfig:"K6_CLOUD_TRACES_ENABLED"`
 
@@ -169,6 +238,18 @@ func NewConfig() Config {
 		MetricPushInterval:    types.NewNullDuration(1*time.Second, false),
 		MetricPushConcurrency: null.NewInt(1, false),
 
+		MetricPushEncoding:               null.NewString("snappy", false),
+		MetricPushQueueSize:              null.NewInt(1000, false),
+		MetricPushMaxAttempts:            null.NewInt(3, false),
+		MetricPushFailurePolicy:          null.NewString("warn", false),
+		MetricPushMaxConsecutiveFailures: null.NewInt(5, false),
+		MetricPushRetryInterval:          types.NewNullDuration(500*time.Millisecond, false),
+		MetricPushRetryMaxElapsedTime:    types.NewNullDuration(10*time.Second, false),
+		MetricPushFinalFlushTimeout:      types.NewNullDuration(30*time.Second, false),
+		MetricPushLabelDictionary:        null.NewBool(false, false),
+		TrendMinResolution:               null.NewFloat(.001, false),
+		ActiveSeriesLimitPolicy:          null.NewString("overflow", false),
+
 		TracesEnabled:         null.NewBool(false, false),
 		TracesHost:            null.NewString("insights.k6.io:4443", false),
 		TracesPushInterval:    types.NewNullDuration(1*time.Second, false),
@@ -212,6 +293,18 @@ func (c Config) Apply(cfg Config) Config {
 	if cfg.Host.Valid && cfg.Host.String != "" {
 		c.Host = cfg.Host
 	}
+	if cfg.ProxyURL.Valid {
+		c.ProxyURL = cfg.ProxyURL
+	}
+	if cfg.TLSCACert.Valid {
+		c.TLSCACert = cfg.TLSCACert
+	}
+	if cfg.TLSClientCert.Valid {
+		c.TLSClientCert = cfg.TLSClientCert
+	}
+	if cfg.TLSClientKey.Valid {
+		c.TLSClientKey = cfg.TLSClientKey
+	}
 	if cfg.LogsTailURL.Valid && cfg.LogsTailURL.String != "" {
 		c.LogsTailURL = cfg.LogsTailURL
 	}
@@ -248,6 +341,48 @@ func (c Config) Apply(cfg Config) Config {
 	if cfg.MetricPushConcurrency.Valid {
 		c.MetricPushConcurrency = cfg.MetricPushConcurrency
 	}
+	if cfg.MetricPushEncoding.Valid {
+		c.MetricPushEncoding = cfg.MetricPushEncoding
+	}
+	if cfg.MetricPushQueueSize.Valid {
+		c.MetricPushQueueSize = cfg.MetricPushQueueSize
+	}
+	if cfg.MetricPushFailurePolicy.Valid {
+		c.MetricPushFailurePolicy = cfg.MetricPushFailurePolicy
+	}
+	if cfg.MetricPushMaxConsecutiveFailures.Valid {
+		c.MetricPushMaxConsecutiveFailures = cfg.MetricPushMaxConsecutiveFailures
+	}
+	if cfg.MetricPushFallbackDir.Valid {
+		c.MetricPushFallbackDir = cfg.MetricPushFallbackDir
+	}
+	if cfg.MetricPushMaxAttempts.Valid {
+		c.MetricPushMaxAttempts = cfg.MetricPushMaxAttempts
+	}
+	if cfg.MetricPushRetryInterval.Valid {
+		c.MetricPushRetryInterval = cfg.MetricPushRetryInterval
+	}
+	if cfg.MetricPushRetryMaxElapsedTime.Valid {
+		c.MetricPushRetryMaxElapsedTime = cfg.MetricPushRetryMaxElapsedTime
+	}
+	if cfg.MetricPushLabelDictionary.Valid {
+		c.MetricPushLabelDictionary = cfg.MetricPushLabelDictionary
+	}
+	if cfg.MetricPushFinalFlushTimeout.Valid {
+		c.MetricPushFinalFlushTimeout = cfg.MetricPushFinalFlushTimeout
+	}
+	if cfg.TrendMinResolution.Valid {
+		c.TrendMinResolution = cfg.TrendMinResolution
+	}
+	if cfg.TrendMinResolutionOverrides.Valid {
+		c.TrendMinResolutionOverrides = cfg.TrendMinResolutionOverrides
+	}
+	if cfg.MaxActiveSeries.Valid {
+		c.MaxActiveSeries = cfg.MaxActiveSeries
+	}
+	if cfg.ActiveSeriesLimitPolicy.Valid {
+		c.ActiveSeriesLimitPolicy = cfg.ActiveSeriesLimitPolicy
+	}
 	if cfg.TracesEnabled.Valid {
 		c.TracesEnabled = cfg.TracesEnabled
 	}
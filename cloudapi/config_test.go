@@ -24,34 +24,52 @@ func TestConfigApply(t *testing.T) {
 	assert.Equal(t, defaults, defaults.Apply(empty).Apply(empty))
 
 	full := Config{
-		Token:                           null.NewString("Token", true),
-		ProjectID:                       null.NewInt(1, true),
-		Name:                            null.NewString("Name", true),
-		Host:                            null.NewString("Host", true),
-		Timeout:                         types.NewNullDuration(5*time.Second, true),
-		LogsTailURL:                     null.NewString("LogsTailURL", true),
-		PushRefID:                       null.NewString("PushRefID", true),
-		WebAppURL:                       null.NewString("foo", true),
-		NoCompress:                      null.NewBool(true, true),
-		StopOnError:                     null.NewBool(true, true),
-		APIVersion:                      null.NewInt(2, true),
-		MaxMetricSamplesPerPackage:      null.NewInt(2, true),
-		MaxTimeSeriesInBatch:            null.NewInt(3, true),
-		MetricPushInterval:              types.NewNullDuration(1*time.Second, true),
-		MetricPushConcurrency:           null.NewInt(3, true),
-		TracesEnabled:                   null.NewBool(true, true),
-		TracesHost:                      null.NewString("TracesHost", true),
-		TracesPushInterval:              types.NewNullDuration(10*time.Second, true),
-		TracesPushConcurrency:           null.NewInt(6, true),
-		AggregationPeriod:               types.NewNullDuration(2*time.Second, true),
-		AggregationCalcInterval:         types.NewNullDuration(3*time.Second, true),
-		AggregationWaitPeriod:           types.NewNullDuration(4*time.Second, true),
-		AggregationMinSamples:           null.NewInt(4, true),
-		AggregationSkipOutlierDetection: null.NewBool(true, true),
-		AggregationOutlierAlgoThreshold: null.NewInt(5, true),
-		AggregationOutlierIqrRadius:     null.NewFloat(6, true),
-		AggregationOutlierIqrCoefLower:  null.NewFloat(7, true),
-		AggregationOutlierIqrCoefUpper:  null.NewFloat(8, true),
+		Token:                            null.NewString("Token", true),
+		ProjectID:                        null.NewInt(1, true),
+		Name:                             null.NewString("Name", true),
+		Host:                             null.NewString("Host", true),
+		Timeout:                          types.NewNullDuration(5*time.Second, true),
+		ProxyURL:                         null.NewString("ProxyURL", true),
+		TLSCACert:                        null.NewString("TLSCACert", true),
+		TLSClientCert:                    null.NewString("TLSClientCert", true),
+		TLSClientKey:                     null.NewString("TLSClientKey", true),
+		LogsTailURL:                      null.NewString("LogsTailURL", true),
+		PushRefID:                        null.NewString("PushRefID", true),
+		WebAppURL:                        null.NewString("foo", true),
+		NoCompress:                       null.NewBool(true, true),
+		StopOnError:                      null.NewBool(true, true),
+		APIVersion:                       null.NewInt(2, true),
+		MaxMetricSamplesPerPackage:       null.NewInt(2, true),
+		MaxTimeSeriesInBatch:             null.NewInt(3, true),
+		MetricPushInterval:               types.NewNullDuration(1*time.Second, true),
+		MetricPushConcurrency:            null.NewInt(3, true),
+		MetricPushEncoding:               null.NewString("zstd", true),
+		MetricPushQueueSize:              null.NewInt(10, true),
+		MetricPushMaxAttempts:            null.NewInt(5, true),
+		MetricPushFailurePolicy:          null.NewString("abort", true),
+		MetricPushMaxConsecutiveFailures: null.NewInt(2, true),
+		MetricPushFallbackDir:            null.NewString("/tmp/metrics", true),
+		MetricPushRetryInterval:          types.NewNullDuration(2*time.Second, true),
+		MetricPushRetryMaxElapsedTime:    types.NewNullDuration(20*time.Second, true),
+		MetricPushFinalFlushTimeout:      types.NewNullDuration(time.Minute, true),
+		MetricPushLabelDictionary:        null.NewBool(true, true),
+		TrendMinResolution:               null.NewFloat(.0001, true),
+		TrendMinResolutionOverrides:      null.NewString("http_req_waiting=0.00001", true),
+		MaxActiveSeries:                  null.NewInt(50000, true),
+		ActiveSeriesLimitPolicy:          null.NewString("drop", true),
+		TracesEnabled:                    null.NewBool(true, true),
+		TracesHost:                       null.NewString("TracesHost", true),
+		TracesPushInterval:               types.NewNullDuration(10*time.Second, true),
+		TracesPushConcurrency:            null.NewInt(6, true),
+		AggregationPeriod:                types.NewNullDuration(2*time.Second, true),
+		AggregationCalcInterval:          types.NewNullDuration(3*time.Second, true),
+		AggregationWaitPeriod:            types.NewNullDuration(4*time.Second, true),
+		AggregationMinSamples:            null.NewInt(4, true),
+		AggregationSkipOutlierDetection:  null.NewBool(true, true),
+		AggregationOutlierAlgoThreshold:  null.NewInt(5, true),
+		AggregationOutlierIqrRadius:      null.NewFloat(6, true),
+		AggregationOutlierIqrCoefLower:   null.NewFloat(7, true),
+		AggregationOutlierIqrCoefUpper:   null.NewFloat(8, true),
 	}
 
 	assert.Equal(t, full, full.Apply(empty))
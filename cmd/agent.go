@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/execution/distributed"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/loader"
+	"go.k6.io/k6/output"
+)
+
+// cmdAgent handles the `k6 agent` sub-command
+type cmdAgent struct {
+	gs *state.GlobalState
+
+	coordinatorAddress string
+	statusInterval     time.Duration
+}
+
+func (c *cmdAgent) run(cmd *cobra.Command, args []string) error {
+	if c.statusInterval <= 0 {
+		return errors.New("the status interval must be greater than zero")
+	}
+
+	ctx, cancel := context.WithCancel(c.gs.Ctx)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, c.coordinatorAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("couldn't connect to the coordinator on '%s': %w", c.coordinatorAddress, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	agent, err := distributed.NewAgentController(ctx, distributed.NewDistributedTestClient(conn), c.gs.Logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := agent.Close(); cerr != nil {
+			c.gs.Logger.WithError(cerr).Debug("Error while closing the connection with the coordinator")
+		}
+	}()
+	c.gs.Logger.Debugf("Registered with the coordinator on '%s' as instance %d",
+		c.coordinatorAddress, agent.InstanceID())
+
+	var execState *lib.ExecutionState
+	statusStreamed := make(chan struct{})
+	statusCtx, stopStatus := context.WithCancel(ctx)
+	defer stopStatus()
+
+	runCmd := &cmdRun{
+		gs:            c.gs,
+		executionType: fmt.Sprintf("agent (instance %d)", agent.InstanceID()),
+		loadTest: func(cmd *cobra.Command, args []string) (*loadedAndConfiguredTest, execution.Controller, error) {
+			test, lerr := c.loadTest(cmd, args, agent)
+			return test, agent, lerr
+		},
+		prepareScheduler: func(execScheduler *execution.Scheduler) ([]output.Output, error) {
+			agent.SetCommandHandler(func(command *distributed.Command) error {
+				return distributed.ApplyCommand(ctx, execScheduler, command)
+			})
+			agent.SetRebalanceHandler(func(et *lib.ExecutionTuple) error {
+				return execScheduler.UpdateExecutionTuple(ctx, et)
+			})
+
+			execState = execScheduler.GetState()
+			go func() {
+				defer close(statusStreamed)
+				agent.StreamStatus(statusCtx, execState, c.statusInterval)
+			}()
+
+			return []output.Output{distributed.NewMetricsOutput(agent, distributed.DefaultMetricsFlushInterval)}, nil
+		},
+	}
+	err = runCmd.run(cmd, args)
+
+	if execState != nil {
+		// the final status, with the error that the test
+		// has finished with, is sent after all the others
+		stopStatus()
+		<-statusStreamed
+		if serr := agent.SendStatus(distributed.NewInstanceStatus(execState, err)); serr != nil {
+			c.gs.Logger.WithError(serr).Debug("Unable to send the final execution status")
+		}
+		// the outputs have been stopped, so all the metric samples have
+		// been sent to the coordinator, which can now finish processing them
+		if serr := agent.Signal(distributed.FinishedEventID); serr != nil {
+			c.gs.Logger.WithError(serr).Debug("Unable to signal the end of the test")
+		}
+	}
+	return err
+}
+
+// loadTest gets the archive of the test from the coordinator and configures
+// the test from it. If neither the coordinator nor the other instances have
+// the archive yet, it may be created from the script passed to this instance.
+func (c *cmdAgent) loadTest(
+	cmd *cobra.Command, args []string, agent *distributed.AgentController,
+) (*loadedAndConfiguredTest, error) {
+	data, err := agent.GetOrCreateData(distributed.ArchiveDataID, func() ([]byte, error) {
+		if len(args) == 0 {
+			return nil, errors.New("the test script wasn't passed to the coordinator or to the agent archiving it")
+		}
+		c.gs.Logger.Debugf("Archiving the test '%s' for all the instances...", args[0])
+		arc, aerr := createArchive(c.gs, cmd, args)
+		if aerr != nil {
+			return nil, aerr
+		}
+		buf := &bytes.Buffer{}
+		if aerr = arc.Write(buf); aerr != nil {
+			return nil, aerr
+		}
+		return buf.Bytes(), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the archive of the test: %w", err)
+	}
+
+	et, err := agent.ExecutionTuple()
+	if err != nil {
+		return nil, err
+	}
+	c.gs.Logger.Debugf("Received the test archive with %d bytes, executing the segment %s", len(data), et)
+
+	pwd, err := c.gs.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	sourceRootPath := "archive from the coordinator"
+	if len(args) > 0 {
+		sourceRootPath = args[0]
+	}
+	src := &loader.SourceData{URL: &url.URL{Scheme: "file", Path: "/" + distributed.ArchiveDataID + ".tar"}, Data: data}
+	test, err := loadTestFromSource(c.gs, cmd, sourceRootPath, src, loader.CreateFilesystems(c.gs.FS), pwd, nil)
+	if err != nil {
+		return nil, err
+	}
+	// the coordinator computes the thresholds and the end-of-test
+	// summary from the metric samples of all the instances
+	test.preInitState.RuntimeOptions.NoThresholds = null.BoolFrom(true)
+	test.preInitState.RuntimeOptions.NoSummary = null.BoolFrom(true)
+
+	// the execution segment assigned by the coordinator
+	// overrides the ones from all the other config layers
+	return test.consolidateDeriveAndValidateConfig(c.gs, cmd, func(flags *pflag.FlagSet) (Config, error) {
+		conf, cerr := getConfig(flags)
+		if cerr != nil {
+			return conf, cerr
+		}
+		sequence := et.Sequence.ExecutionSegmentSequence
+		conf.ExecutionSegment = et.Segment
+		conf.ExecutionSegmentSequence = &sequence
+		return conf, nil
+	})
+}
+
+func (c *cmdAgent) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.AddFlagSet(newCmdRun(c.gs).flagSet())
+	flags.StringVar(&c.coordinatorAddress, "coordinator-address", defaultCoordinatorAddress,
+		"address of the coordinator's gRPC server")
+	flags.DurationVar(&c.statusInterval, "status-interval", distributed.DefaultStatusInterval,
+		"how often the execution status is sent to the coordinator")
+	return flags
+}
+
+func getCmdAgent(gs *state.GlobalState) *cobra.Command {
+	c := &cmdAgent{gs: gs}
+
+	exampleText := getExampleText(gs, `
+  # Start a coordinator for 2 instances, which shares the test with them.
+  {{.}} coordinator --instance-count 2 script.js
+
+  # Start the 2 agent instances, on the same or on different machines.
+  {{.}} agent --coordinator-address 10.0.0.1:6566
+  {{.}} agent --coordinator-address 10.0.0.1:6566
+
+  # Alternatively, pass the script to the agents, one of them archives it.
+  {{.}} agent --coordinator-address 10.0.0.1:6566 script.js`[1:])
+
+	agentCmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Start an agent instance of a distributed test",
+		Long: `Start an agent instance of a distributed test.
+
+  The agent registers with the coordinator and receives the archive of
+  the test from it, so the script doesn't have to be distributed to the
+  instances beforehand. If the coordinator wasn't started with a script,
+  the archive is created from the script passed to one of the agents.
+
+  Each agent executes the execution segment of the test assigned to it by
+  the coordinator, it streams its metrics and its execution status to the
+  coordinator, and it exits when its part of the test has finished.`,
+		Example: exampleText,
+		Args:    cobra.MaximumNArgs(1),
+		RunE:    c.run,
+	}
+	agentCmd.Flags().SortFlags = false
+	agentCmd.Flags().AddFlagSet(c.flagSet())
+
+	return agentCmd
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/spf13/pflag"
 
 	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/lib"
 )
 
 // cmdArchive handles the `k6 archive` sub-command
@@ -16,23 +17,11 @@ type cmdArchive struct {
 }
 
 func (c *cmdArchive) run(cmd *cobra.Command, args []string) error {
-	test, err := loadAndConfigureTest(c.gs, cmd, args, getPartialConfig)
+	arc, err := createArchive(c.gs, cmd, args)
 	if err != nil {
 		return err
 	}
 
-	// It's important to NOT set the derived options back to the runner
-	// here, only the consolidated ones. Otherwise, if the script used
-	// an execution shortcut option (e.g. `iterations` or `duration`),
-	// we will have multiple conflicting execution options since the
-	// derivation will set `scenarios` as well.
-	testRunState, err := test.buildTestRunState(test.consolidatedConfig.Options)
-	if err != nil {
-		return err
-	}
-
-	// Archive.
-	arc := testRunState.Runner.MakeArchive()
 	f, err := c.gs.FS.Create(c.archiveOut)
 	if err != nil {
 		return err
@@ -51,6 +40,27 @@ func (c *cmdArchive) run(cmd *cobra.Command, args []string) error {
 	return err
 }
 
+// createArchive loads the test and returns its archive, with the options
+// from the script and from the CLI flags, environment variables and config.
+func createArchive(gs *state.GlobalState, cmd *cobra.Command, args []string) (*lib.Archive, error) {
+	test, err := loadAndConfigureTest(gs, cmd, args, getPartialConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// It's important to NOT set the derived options back to the runner
+	// here, only the consolidated ones. Otherwise, if the script used
+	// an execution shortcut option (e.g. `iterations` or `duration`),
+	// we will have multiple conflicting execution options since the
+	// derivation will set `scenarios` as well.
+	testRunState, err := test.buildTestRunState(test.consolidatedConfig.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	return testRunState.Runner.MakeArchive(), nil
+}
+
 func (c *cmdArchive) flagSet() *pflag.FlagSet {
 	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
 	flags.SortFlags = false
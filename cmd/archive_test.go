@@ -155,6 +155,45 @@ func TestArchiveNotContainsEnv(t *testing.T) {
 	require.Len(t, metadata.Env, 0)
 }
 
+func TestArchiveContainsProfilePoints(t *testing.T) {
+	t.Parallel()
+
+	// given a script with a profile-replay scenario, which reads a profile file
+	testScript := []byte(`
+		export const options = {
+			scenarios: {
+				replay: { executor: "profile-replay", profile: "profile.csv", preAllocatedVUs: 1 },
+			},
+		};
+		export default function () {}
+	`)
+	ts := tests.NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "script.js"), testScript, 0o644))
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "profile.csv"), []byte("1,5\n3,10\n"), 0o644))
+
+	ts.CmdArgs = []string{"k6", "archive", "script.js"}
+	newRootCommand(ts.GlobalState).execute()
+	require.NoError(t, untar(t, ts.FS, "archive.tar", "tmp/"))
+
+	data, err := fsext.ReadFile(ts.FS, "tmp/metadata.json")
+	require.NoError(t, err)
+
+	metadata := struct {
+		Options struct {
+			Scenarios map[string]struct {
+				Points []map[string]interface{}
+			}
+		}
+	}{}
+
+	// then the points of the profile are a part of the archived options
+	require.NoError(t, json.Unmarshal(data, &metadata))
+	require.Equal(t, []map[string]interface{}{
+		{"time": "0s", "rate": float64(5)},
+		{"time": "2s", "rate": float64(10)},
+	}, metadata.Options.Scenarios["replay"].Points)
+}
+
 // untar untars a `fileName` file to a `destination` path
 func untar(t *testing.T, fileSystem fsext.Fs, fileName string, destination string) error {
 	t.Helper()
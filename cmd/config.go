@@ -221,6 +221,12 @@ func applyDefault(conf Config) Config {
 	if !conf.TeardownTimeout.Valid {
 		conf.TeardownTimeout.Duration = types.Duration(60 * time.Second)
 	}
+	if !conf.AbortPolicy.Valid {
+		conf.AbortPolicy.String = lib.AbortPolicyInterrupt
+	}
+	if !conf.AbortGracePeriod.Valid {
+		conf.AbortGracePeriod.Duration = types.Duration(30 * time.Second)
+	}
 	return conf
 }
 
@@ -0,0 +1,376 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/execution/distributed"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/loader"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/metrics/engine"
+	"go.k6.io/k6/output"
+)
+
+const defaultCoordinatorAddress = "localhost:6566"
+
+// cmdCoordinator handles the `k6 coordinator` sub-command
+type cmdCoordinator struct {
+	gs *state.GlobalState
+
+	address       string
+	instanceCount uint32
+	membership    distributed.MembershipConfig
+	policy        string
+}
+
+func (c *cmdCoordinator) run(cmd *cobra.Command, args []string) (err error) {
+	policy, err := distributed.InstanceLossPolicyString(c.policy)
+	if err != nil {
+		return fmt.Errorf("invalid instance loss policy '%s', it must be abort, continue or redistribute", c.policy)
+	}
+	c.membership.Policy = policy
+
+	coordinator, err := distributed.NewCoordinatorServer(c.instanceCount, c.membership, c.gs.Logger)
+	if err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		// the instances receive the archive of the test from the
+		// coordinator, instead of one of them creating it
+		arc, aerr := createArchive(c.gs, cmd, args)
+		if aerr != nil {
+			return aerr
+		}
+		buf := &bytes.Buffer{}
+		if aerr = arc.Write(buf); aerr != nil {
+			return aerr
+		}
+		coordinator.SetData(distributed.ArchiveDataID, buf.Bytes())
+	}
+
+	listener, err := net.Listen("tcp", c.address)
+	if err != nil {
+		return fmt.Errorf("couldn't listen on '%s': %w", c.address, err)
+	}
+
+	server := grpc.NewServer()
+	distributed.RegisterDistributedTestServer(server, coordinator)
+	distributed.RegisterCoordinatorControlServer(server, coordinator)
+
+	ctx, cancel := context.WithCancel(c.gs.Ctx)
+	defer cancel()
+	go coordinator.MonitorInstances(ctx)
+
+	stopSignalHandling := handleTestAbortSignals(c.gs, func(sig os.Signal) {
+		c.gs.Logger.WithField("sig", sig).Debug("Stopping the coordinator in response to signal...")
+		cancel()
+	}, func(sig os.Signal) {
+		c.gs.Logger.WithField("sig", sig).Debug("Hard stopping the coordinator in response to signal...")
+		server.Stop()
+	})
+	defer stopSignalHandling()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+	defer func() {
+		// the instances disconnect after they have finished the test
+		server.GracefulStop()
+		if serr := <-served; err == nil {
+			err = serr
+		}
+	}()
+
+	c.gs.Logger.Infof("The coordinator is waiting for %d instances on %s", c.instanceCount, listener.Addr())
+	return c.processMetrics(ctx, cmd, args, coordinator)
+}
+
+// processMetrics processes the metric samples streamed by the instances, once
+// it has the archive of the test: it sends them to the outputs, it computes
+// the thresholds and it generates the end-of-test summary, as `k6 run` does
+// for a local test. It returns when all the instances have finished the test.
+//
+//nolint:funlen
+func (c *cmdCoordinator) processMetrics(
+	ctx context.Context, cmd *cobra.Command, args []string, coordinator *distributed.CoordinatorServer,
+) (err error) {
+	logger := c.gs.Logger
+	data, err := coordinator.WaitForData(ctx, distributed.ArchiveDataID)
+	if ctx.Err() != nil {
+		// the coordinator has been stopped before the test
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't get the archive of the test: %w", err)
+	}
+	test, err := c.loadTest(cmd, args, data)
+	if err != nil {
+		return err
+	}
+	conf := test.derivedConfig
+	testRunState, err := test.buildTestRunState(conf.Options)
+	if err != nil {
+		return err
+	}
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	if err != nil {
+		return err
+	}
+	outputs, err := createOutputs(c.gs, test, conf.Scenarios.GetFullExecutionRequirements(et))
+	if err != nil {
+		return err
+	}
+
+	metricsEngine, err := engine.NewMetricsEngine(testRunState.Registry, logger)
+	if err != nil {
+		return err
+	}
+	runtimeOptions := testRunState.RuntimeOptions
+	var metricsIngester *engine.OutputIngester
+	if !runtimeOptions.NoSummary.Bool || !runtimeOptions.NoThresholds.Bool {
+		err = metricsEngine.InitSubMetricsAndThresholds(conf.Options, runtimeOptions.NoThresholds.Bool)
+		if err != nil {
+			return err
+		}
+		metricsIngester = metricsEngine.CreateIngester()
+		outputs = append(outputs, metricsIngester)
+
+		if webhook := conf.Options.ThresholdsWebhook; webhook.Valid && webhook.String != "" {
+			metricsEngine.OnThresholdBreach(engine.NewThresholdsWebhook(webhook.String, logger))
+		}
+	}
+
+	if !runtimeOptions.NoSummary.Bool {
+		defer func() {
+			logger.Debug("Generating the end-of-test summary...")
+			summaryResult, hsErr := test.initRunner.HandleSummary(c.gs.Ctx, &lib.Summary{
+				Metrics:         metricsEngine.ObservedMetrics,
+				RootGroup:       testRunState.Runner.GetDefaultGroup(),
+				TestRunDuration: coordinator.TestRunDuration(),
+				NoColor:         c.gs.Flags.NoColor,
+				UIState: lib.UIState{
+					IsStdOutTTY: c.gs.Stdout.IsTTY,
+					IsStdErrTTY: c.gs.Stderr.IsTTY,
+				},
+			})
+			if hsErr == nil {
+				hsErr = handleSummaryResult(c.gs.FS, c.gs.Stdout, c.gs.Stderr, summaryResult)
+			}
+			if hsErr != nil {
+				logger.WithError(hsErr).Error("failed to handle the end-of-test summary")
+			}
+		}()
+	}
+
+	outputManager := output.NewManager(outputs, logger, func(err error) {
+		if err != nil {
+			logger.WithError(err).Error("Received error to stop from output")
+			coordinator.Abort(err)
+		}
+	})
+	// the samples are sent to the outputs by the coordinator, the
+	// channel is only used for the lifecycle of the output manager
+	samples := make(chan metrics.SampleContainer)
+	waitOutputsFlushed, stopOutputs, err := outputManager.Start(samples)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		logger.Debug("Stopping outputs...")
+		stopOutputs(err)
+	}()
+	coordinator.SetMetricsOutputs(testRunState.Registry, outputs...)
+
+	if !runtimeOptions.NoThresholds.Bool {
+		finalizeThresholds := metricsEngine.StartThresholdCalculations(
+			metricsIngester, coordinator.Abort, coordinator.TestRunDuration,
+		)
+		if finalizeThresholds != nil {
+			defer func() {
+				logger.Debug("Finalizing thresholds...")
+				if breached := finalizeThresholds(); len(breached) > 0 && err == nil {
+					err = thresholdsCrossedError(breached)
+				}
+			}()
+		}
+	}
+
+	defer func() {
+		coordinator.SetMetricsOutputs(nil)
+		close(samples)
+		waitOutputsFlushed()
+	}()
+
+	printExecutionDescription(
+		c.gs, "coordinator", test.sourceRootPath, "", conf, et, conf.Scenarios.GetFullExecutionRequirements(et), outputs,
+	)
+
+	select {
+	case <-coordinator.Finished():
+		logger.Debug("All the instances have finished the test")
+		// the script could have marked the test run as failed on any instance
+		if reasons := coordinator.FailureReasons(); len(reasons) > 0 {
+			return testFailedError(reasons)
+		}
+		return nil
+	case <-ctx.Done():
+		return errext.WithExitCodeIfNone(
+			errors.New("the coordinator was stopped before the instances finished the test"), exitcodes.ExternalAbort,
+		)
+	}
+}
+
+// loadTest loads the test from its archive, with the options of the
+// coordinator's flags, so it has the same options as the instances.
+func (c *cmdCoordinator) loadTest(cmd *cobra.Command, args []string, data []byte) (*loadedAndConfiguredTest, error) {
+	pwd, err := c.gs.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	sourceRootPath := "archive from an agent"
+	if len(args) > 0 {
+		sourceRootPath = args[0]
+	}
+	src := &loader.SourceData{URL: &url.URL{Scheme: "file", Path: "/" + distributed.ArchiveDataID + ".tar"}, Data: data}
+	test, err := loadTestFromSource(c.gs, cmd, sourceRootPath, src, loader.CreateFilesystems(c.gs.FS), pwd, nil)
+	if err != nil {
+		return nil, err
+	}
+	return test.consolidateDeriveAndValidateConfig(c.gs, cmd, getConfig)
+}
+
+func (c *cmdCoordinator) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.Uint32Var(&c.instanceCount, "instance-count", 1, "number of agent instances that execute the test")
+	flags.DurationVar(&c.membership.HeartbeatInterval, "heartbeat-interval", 0,
+		"how often the instances send heartbeats, 0 disables them")
+	flags.DurationVar(&c.membership.FailureTimeout, "failure-timeout", 0,
+		"how long an instance can be silent before it's considered lost, "+
+			"it defaults to three heartbeat intervals")
+	flags.StringVar(&c.policy, "instance-loss-policy", distributed.InstanceLossAbort.String(),
+		"what happens when an instance is lost: abort, continue or redistribute")
+	flags.AddFlagSet(optionFlagSet())
+	flags.AddFlagSet(runtimeOptionFlagSet(false))
+	flags.AddFlagSet(configFlagSet())
+	return flags
+}
+
+// executeCoordinatorCommand sends the command to the coordinator listening
+// on the provided address, which applies it on all of its instances.
+func executeCoordinatorCommand(gs *state.GlobalState, address string, command *distributed.Command) error {
+	ctx, cancel := context.WithTimeout(gs.Ctx, time.Minute)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("couldn't connect to the coordinator on '%s': %w", address, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	resp, err := distributed.NewCoordinatorControlClient(conn).ExecuteCommand(ctx, command)
+	if err != nil {
+		return err
+	}
+	printToStdout(gs, fmt.Sprintf("The command was applied on %d instances\n", resp.GetInstances()))
+	return nil
+}
+
+func getCmdCoordinatorPause(gs *state.GlobalState, address *string, paused bool) *cobra.Command {
+	use, short := "pause", "Pause the test on all instances"
+	if !paused {
+		use, short = "resume", "Resume the test on all instances"
+	}
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return executeCoordinatorCommand(gs, *address, &distributed.Command{
+				Command: &distributed.Command_Pause{Pause: paused},
+			})
+		},
+	}
+}
+
+func getCmdCoordinatorScale(gs *state.GlobalState, address *string) *cobra.Command {
+	scale := &distributed.Scale{}
+	scaleCmd := &cobra.Command{
+		Use:   "scale",
+		Short: "Scale the test on all instances",
+		Long: `Scale the test on all instances.
+
+  The VUs of the externally-controlled scenarios and the rate of the
+  constant-arrival-rate ones can be changed. The values are for the
+  whole test, each instance applies its own part of them.`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if scale.Vus <= 0 && scale.MaxVus <= 0 && scale.Rate <= 0 {
+				return errors.New("specify either -u/--vus, -m/--max or --rate")
+			}
+			return executeCoordinatorCommand(gs, *address, &distributed.Command{
+				Command: &distributed.Command_Scale{Scale: scale},
+			})
+		},
+	}
+
+	flags := scaleCmd.Flags()
+	flags.Int64VarP(&scale.Vus, "vus", "u", 0, "number of virtual users")
+	flags.Int64VarP(&scale.MaxVus, "max", "m", 0, "max available virtual users")
+	flags.Int64Var(&scale.Rate, "rate", 0, "number of iterations started per time unit")
+	flags.StringVar(&scale.Scenario, "scenario", "", "scale only the scenario with this name")
+
+	return scaleCmd
+}
+
+func getCmdCoordinator(gs *state.GlobalState) *cobra.Command {
+	c := &cmdCoordinator{gs: gs}
+
+	coordinatorCmd := &cobra.Command{
+		Use:   "coordinator",
+		Short: "Start a coordinator for a distributed test",
+		Long: `Start a coordinator for a distributed test.
+
+  The coordinator synchronizes the agent instances that execute the test.
+  If a script is passed, its archive is sent to the agent instances, so
+  they don't need their own copy of it, otherwise it gets the archive from
+  the agent that creates it.
+
+  The instances stream their metric samples to the coordinator, which sends
+  them to its outputs, computes the thresholds and shows the end-of-test
+  summary for the whole test. It exits once all the instances have finished
+  the test. Its sub-commands pause, resume and scale the test on all the
+  connected instances at the same time.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: c.run,
+	}
+	coordinatorCmd.Flags().AddFlagSet(c.flagSet())
+	coordinatorCmd.PersistentFlags().StringVar(&c.address, "coordinator-address", defaultCoordinatorAddress,
+		"address of the coordinator's gRPC server")
+
+	coordinatorCmd.AddCommand(
+		getCmdCoordinatorPause(gs, &c.address, true),
+		getCmdCoordinatorPause(gs, &c.address, false),
+		getCmdCoordinatorScale(gs, &c.address),
+	)
+
+	return coordinatorCmd
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 	"gopkg.in/guregu/null.v3"
@@ -43,7 +44,14 @@ func optionFlagSet() *pflag.FlagSet {
 	flags.Bool("insecure-skip-tls-verify", false, "skip verification of TLS certificates")
 	flags.Bool("no-connection-reuse", false, "disable keep-alive connections")
 	flags.Bool("no-vu-connection-reuse", false, "don't reuse connections between iterations")
+	flags.Int64("max-connections-per-host", 0, "max connections of each VU per host, 0 for no limit")
+	flags.Int64("max-idle-connections", 0, "max idle keep-alive connections of each VU (default: batch)")
 	flags.Duration("min-iteration-duration", 0, "minimum amount of time k6 will take executing a single iteration")
+	flags.String("abort-policy", lib.AbortPolicyInterrupt, "what happens with the in-flight iterations when the "+
+		"test is aborted: 'interrupt' them, let them finish within the abort grace period with 'graceful', "+
+		"or let them 'finish' within the gracefulStop of their scenarios")
+	flags.Duration("abort-grace-period", 30*time.Second, "how long the in-flight iterations can keep running "+
+		"after the test is aborted with the graceful abort policy")
 	flags.BoolP("throw", "w", false, "throw warnings (like failed http requests) as errors")
 	flags.StringSlice("blacklist-ip", nil, "blacklist an `ip range` from being called")
 	flags.StringSlice("block-hostnames", nil, "block a case-insensitive hostname `pattern`,"+
@@ -66,6 +74,7 @@ func optionFlagSet() *pflag.FlagSet {
 	flags.StringSlice("system-tags", nil, systemTagsCliHelpText)
 	flags.StringSlice("tag", nil, "add a `tag` to be applied to all samples, as `[name]=[value]`")
 	flags.String("console-output", "", "redirects the console logging to the provided output file")
+	flags.String("har-output", "", "records the HTTP requests into the provided HAR file")
 	flags.Bool("discard-response-bodies", false, "Read but don't process or save HTTP response bodies")
 	flags.String("local-ips", "", "Client IP Ranges and/or CIDRs from which each VU will be making requests, "+
 		"e.g. '192.168.220.1,192.168.0.10-192.168.0.25', 'fd:1::0/120', etc.")
@@ -95,9 +104,14 @@ func getOptions(flags *pflag.FlagSet) (lib.Options, error) {
 		InsecureSkipTLSVerify:   getNullBool(flags, "insecure-skip-tls-verify"),
 		NoConnectionReuse:       getNullBool(flags, "no-connection-reuse"),
 		NoVUConnectionReuse:     getNullBool(flags, "no-vu-connection-reuse"),
+		MaxConnectionsPerHost:   getNullInt64(flags, "max-connections-per-host"),
+		MaxIdleConnections:      getNullInt64(flags, "max-idle-connections"),
 		MinIterationDuration:    getNullDuration(flags, "min-iteration-duration"),
+		AbortPolicy:             getNullString(flags, "abort-policy"),
+		AbortGracePeriod:        getNullDuration(flags, "abort-grace-period"),
 		Throw:                   getNullBool(flags, "throw"),
 		DiscardResponseBodies:   getNullBool(flags, "discard-response-bodies"),
+		HAROutput:               getNullString(flags, "har-output"),
 		MetricSamplesBufferSize: null.NewInt(1000, false),
 	}
 
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/spf13/pflag"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/execution/local"
+	"go.k6.io/k6/execution/redis"
+	"go.k6.io/k6/lib"
+)
+
+// redisControllerFlags are the `k6 run` options for synchronizing the
+// instances of a test, each one executing a segment of it, with Redis.
+type redisControllerFlags struct {
+	url         string
+	keyPrefix   string
+	keyTTL      time.Duration
+	waitTimeout time.Duration
+
+	client *goredis.Client
+}
+
+func (f *redisControllerFlags) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.StringVar(&f.url, "redis-controller", "",
+		"URL of a Redis server used to synchronize the instances of a test, e.g. redis://10.0.0.1:6379/0")
+	flags.StringVar(&f.keyPrefix, "redis-controller-prefix", "",
+		"prefix of the Redis keys of the test, it has to be unique for each test run")
+	flags.DurationVar(&f.keyTTL, "redis-controller-key-ttl", redis.DefaultKeyTTL,
+		"expiration of the Redis keys of the test")
+	flags.DurationVar(&f.waitTimeout, "redis-controller-wait-timeout", redis.DefaultWaitTimeout,
+		"max time to wait for the other instances of the test")
+	return flags
+}
+
+// newController returns the Redis controller if a server is configured,
+// otherwise the local one. The number of instances of the test is the
+// number of segments in its execution segment sequence.
+func (f *redisControllerFlags) newController(
+	gs *state.GlobalState, test *loadedAndConfiguredTest,
+) (execution.Controller, error) {
+	if f.url == "" {
+		return local.NewController(), nil
+	}
+	if f.keyPrefix == "" {
+		return nil, errext.WithExitCodeIfNone(
+			errors.New("the --redis-controller-prefix option is required with --redis-controller"),
+			exitcodes.InvalidConfig)
+	}
+
+	conf := test.derivedConfig
+	et, err := lib.NewExecutionTuple(conf.ExecutionSegment, conf.ExecutionSegmentSequence)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := goredis.ParseURL(f.url)
+	if err != nil {
+		return nil, errext.WithExitCodeIfNone(
+			fmt.Errorf("invalid Redis controller URL '%s': %w", f.url, err), exitcodes.InvalidConfig)
+	}
+	f.client = goredis.NewClient(opts)
+
+	return redis.NewController(gs.Ctx, f.client, redis.Config{
+		KeyPrefix:     f.keyPrefix,
+		InstanceCount: int64(len(et.Sequence.ExecutionSegmentSequence)),
+		KeyTTL:        f.keyTTL,
+		WaitTimeout:   f.waitTimeout,
+	}, gs.Logger)
+}
+
+// close closes the Redis client, if it was created.
+func (f *redisControllerFlags) close() {
+	if f.client == nil {
+		return
+	}
+	_ = f.client.Close()
+	f.client = nil
+}
@@ -57,9 +57,9 @@ func newRootCommand(gs *state.GlobalState) *rootCommand {
 	rootCmd.SetIn(gs.Stdin)
 
 	subCommands := []func(*state.GlobalState) *cobra.Command{
-		getCmdArchive, getCmdCloud, getCmdConvert, getCmdInspect,
+		getCmdAgent, getCmdArchive, getCmdCloud, getCmdConvert, getCmdCoordinator, getCmdInspect,
 		getCmdLogin, getCmdPause, getCmdResume, getCmdScale, getCmdRun,
-		getCmdStats, getCmdStatus, getCmdVersion,
+		getCmdStats, getCmdStatus, getCmdSuite, getCmdVersion,
 	}
 
 	for _, sc := range subCommands {
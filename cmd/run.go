@@ -25,6 +25,7 @@ import (
 	"go.k6.io/k6/errext/exitcodes"
 	"go.k6.io/k6/event"
 	"go.k6.io/k6/execution"
+	"go.k6.io/k6/execution/local"
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/consts"
@@ -38,6 +39,36 @@ import (
 // cmdRun handles the `k6 run` sub-command
 type cmdRun struct {
 	gs *state.GlobalState
+
+	// executionType describes where the test is executed, e.g. local.
+	executionType string
+	// loadTest loads and configures the test, and returns the controller
+	// that synchronizes its execution with any other instances.
+	loadTest func(cmd *cobra.Command, args []string) (*loadedAndConfiguredTest, execution.Controller, error)
+	// prepareScheduler, if set, is called after the execution scheduler is
+	// created, and it returns any additional outputs for the test.
+	prepareScheduler func(execScheduler *execution.Scheduler) ([]output.Output, error)
+	// handleSummary, if set, gets the end-of-test summary instead of it being
+	// written out, e.g. so that it's printed along with the others of a suite.
+	handleSummary func(summary map[string]io.Reader) error
+	// noBanner disables the banner, when it's printed once
+	// for multiple test runs, e.g. by `k6 suite`.
+	noBanner bool
+}
+
+// newCmdRun returns a cmdRun that executes the test locally.
+func newCmdRun(gs *state.GlobalState) *cmdRun {
+	return &cmdRun{
+		gs:            gs,
+		executionType: "local",
+		loadTest: func(cmd *cobra.Command, args []string) (*loadedAndConfiguredTest, execution.Controller, error) {
+			test, err := loadAndConfigureTest(gs, cmd, args, getConfig)
+			if err != nil {
+				return nil, nil, err
+			}
+			return test, local.NewController(), nil
+		},
+	}
 }
 
 // We use an excessively high timeout to wait for event processing to complete,
@@ -58,7 +89,9 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 			logger.WithError(err).Debug("Everything has finished, exiting k6 with an error!")
 		}
 	}()
-	printBanner(c.gs)
+	if !c.noBanner {
+		printBanner(c.gs)
+	}
 
 	globalCtx, globalCancel := context.WithCancel(c.gs.Ctx)
 	defer globalCancel()
@@ -93,7 +126,7 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 		c.gs.Events.UnsubscribeAll()
 	}()
 
-	test, err := loadAndConfigureTest(c.gs, cmd, args, getConfig)
+	test, controller, err := c.loadTest(cmd, args)
 	if err != nil {
 		return err
 	}
@@ -111,10 +144,17 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 	if err != nil {
 		return err
 	}
+	if closer, ok := testRunState.Runner.(io.Closer); ok {
+		defer func() {
+			if cErr := closer.Close(); cErr != nil {
+				logger.WithError(cErr).Warn("Error while closing the runner")
+			}
+		}()
+	}
 
-	// Create a local execution scheduler wrapping the runner.
+	// Create an execution scheduler wrapping the runner.
 	logger.Debug("Initializing the execution scheduler...")
-	execScheduler, err := execution.NewScheduler(testRunState)
+	execScheduler, err := execution.NewScheduler(testRunState, controller)
 	if err != nil {
 		return err
 	}
@@ -146,6 +186,13 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 	if err != nil {
 		return err
 	}
+	if c.prepareScheduler != nil {
+		extraOutputs, perr := c.prepareScheduler(execScheduler)
+		if perr != nil {
+			return perr
+		}
+		outputs = append(outputs, extraOutputs...)
+	}
 
 	metricsEngine, err := engine.NewMetricsEngine(testRunState.Registry, logger)
 	if err != nil {
@@ -166,6 +213,10 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 		// thresholds or the end-of-test summary are enabled.
 		metricsIngester = metricsEngine.CreateIngester()
 		outputs = append(outputs, metricsIngester)
+
+		if webhook := conf.Options.ThresholdsWebhook; webhook.Valid && webhook.String != "" {
+			metricsEngine.OnThresholdBreach(engine.NewThresholdsWebhook(webhook.String, logger))
+		}
 	}
 
 	executionState := execScheduler.GetState()
@@ -182,7 +233,11 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 					IsStdErrTTY: c.gs.Stderr.IsTTY,
 				},
 			})
-			if hsErr == nil {
+			switch {
+			case hsErr != nil:
+			case c.handleSummary != nil:
+				hsErr = c.handleSummary(summaryResult)
+			default:
 				hsErr = handleSummaryResult(c.gs.FS, c.gs.Stdout, c.gs.Stderr, summaryResult)
 			}
 			if hsErr != nil {
@@ -204,6 +259,9 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 		// TODO: attach run status and exit code?
 		runAbort(err)
 	})
+	if test.derivedConfig.NoWarmupExport.Bool {
+		outputManager.DropWarmupSamples()
+	}
 	samples := make(chan metrics.SampleContainer, test.derivedConfig.MetricSamplesBufferSize.Int64)
 	waitOutputsFlushed, stopOutputs, err := outputManager.Start(samples)
 	if err != nil {
@@ -232,12 +290,7 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 			if len(breachedThresholds) == 0 {
 				return
 			}
-			tErr := errext.WithAbortReasonIfNone(
-				errext.WithExitCodeIfNone(
-					fmt.Errorf("thresholds on metrics '%s' have been crossed", strings.Join(breachedThresholds, ", ")),
-					exitcodes.ThresholdsHaveFailed,
-				), errext.AbortedByThresholdsAfterTestEnd)
-
+			tErr := thresholdsCrossedError(breachedThresholds)
 			if err == nil {
 				err = tErr
 			} else {
@@ -296,7 +349,7 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 	}
 
 	printExecutionDescription(
-		c.gs, "local", args[0], "", conf, executionState.ExecutionTuple, executionPlan, outputs,
+		c.gs, c.executionType, test.sourceRootPath, "", conf, executionState.ExecutionTuple, executionPlan, outputs,
 	)
 
 	// Trap Interrupts, SIGINTs and SIGTERMs.
@@ -378,6 +431,11 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 		return err
 	}
 
+	// The script could have marked the test run as failed, without stopping it.
+	if reasons := executionState.GetFailureReasons(); len(reasons) > 0 {
+		return testFailedError(reasons)
+	}
+
 	// Warn if no iterations could be completed.
 	if executionState.GetFullIterationCount() == 0 {
 		logger.Warn("No script iterations fully finished, consider making the test duration longer")
@@ -388,6 +446,26 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 	return nil
 }
 
+// thresholdsCrossedError returns the error for the
+// thresholds that have been crossed at the end of a test.
+func thresholdsCrossedError(breachedThresholds []string) error {
+	return errext.WithAbortReasonIfNone(
+		errext.WithExitCodeIfNone(
+			fmt.Errorf("thresholds on metrics '%s' have been crossed", strings.Join(breachedThresholds, ", ")),
+			exitcodes.ThresholdsHaveFailed,
+		), errext.AbortedByThresholdsAfterTestEnd)
+}
+
+// testFailedError returns the error for a test run
+// that the script marked as failed with test.fail().
+func testFailedError(reasons []string) error {
+	return errext.WithAbortReasonIfNone(
+		errext.WithExitCodeIfNone(
+			fmt.Errorf("the test run was marked as failed: %s", strings.Join(reasons, "; ")),
+			exitcodes.ScriptMarkedAsFailed,
+		), errext.AbortedByScriptFail)
+}
+
 func (c *cmdRun) flagSet() *pflag.FlagSet {
 	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
 	flags.SortFlags = false
@@ -398,8 +476,18 @@ func (c *cmdRun) flagSet() *pflag.FlagSet {
 }
 
 func getCmdRun(gs *state.GlobalState) *cobra.Command {
-	c := &cmdRun{
-		gs: gs,
+	c := newCmdRun(gs)
+	redisFlags := &redisControllerFlags{}
+	c.loadTest = func(cmd *cobra.Command, args []string) (*loadedAndConfiguredTest, execution.Controller, error) {
+		test, err := loadAndConfigureTest(gs, cmd, args, getConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		controller, err := redisFlags.newController(gs, test)
+		if err != nil {
+			return nil, nil, err
+		}
+		return test, controller, nil
 	}
 
 	exampleText := getExampleText(gs, `
@@ -419,7 +507,11 @@ func getCmdRun(gs *state.GlobalState) *cobra.Command {
   {{.}} run -u 0 -s 10s:100 -s 60s:100 -s 10s:0
 
   # Send metrics to an influxdb server
-  {{.}} run -o influxdb=http://1.2.3.4:8086/k6`[1:])
+  {{.}} run -o influxdb=http://1.2.3.4:8086/k6
+
+  # Run the first of 2 instances of a test, synchronized with a Redis server.
+  {{.}} run --execution-segment 0:1/2 --execution-segment-sequence 0,1/2,1 \
+    --redis-controller redis://10.0.0.1:6379 --redis-controller-prefix test-123 script.js`[1:])
 
 	runCmd := &cobra.Command{
 		Use:   "run",
@@ -430,11 +522,15 @@ This also exposes a REST API to interact with it. Various k6 subcommands offer
 a commandline interface for interacting with it.`,
 		Example: exampleText,
 		Args:    exactArgsWithMsg(1, "arg should either be \"-\", if reading script from stdin, or a path to a script file"),
-		RunE:    c.run,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			defer redisFlags.close()
+			return c.run(cmd, args)
+		},
 	}
 
 	runCmd.Flags().SortFlags = false
 	runCmd.Flags().AddFlagSet(c.flagSet())
+	runCmd.Flags().AddFlagSet(redisFlags.flagSet())
 
 	return runCmd
 }
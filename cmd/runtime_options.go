@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -37,6 +38,15 @@ extended: base + Babel with parts of ES2015 preset
 		"",
 		"output the end-of-test summary report to JSON file",
 	)
+	flags.String("imports-lockfile", "",
+		"record the resolved URLs and the SHA-256 hashes of the remote modules in the lockfile, "+
+			"and check them on the next runs")
+	flags.Bool("frozen-imports", false,
+		"fail if a remote module is missing from the imports lockfile, instead of adding it")
+	flags.Bool("fs-write", false, "allow the scripts to write files with the k6/experimental/fs module")
+	flags.String("fs-write-dir", "",
+		"the directory the files written with k6/experimental/fs are confined to, a temporary directory by default")
+	flags.String("fs-write-quota", "", "the quota of the bytes written with k6/experimental/fs, 100MB by default")
 	return flags
 }
 
@@ -66,6 +76,11 @@ func getRuntimeOptions(flags *pflag.FlagSet, environment map[string]string) (lib
 		NoThresholds:         getNullBool(flags, "no-thresholds"),
 		NoSummary:            getNullBool(flags, "no-summary"),
 		SummaryExport:        getNullString(flags, "summary-export"),
+		ImportsLockfile:      getNullString(flags, "imports-lockfile"),
+		FrozenImports:        getNullBool(flags, "frozen-imports"),
+		FSWrite:              getNullBool(flags, "fs-write"),
+		FSWriteDir:           getNullString(flags, "fs-write-dir"),
+		FSWriteQuota:         getNullString(flags, "fs-write-quota"),
 		Env:                  make(map[string]string),
 	}
 
@@ -98,6 +113,26 @@ func getRuntimeOptions(flags *pflag.FlagSet, environment map[string]string) (lib
 		}
 	}
 
+	if envVar, ok := environment["K6_IMPORTS_LOCKFILE"]; ok && !opts.ImportsLockfile.Valid {
+		opts.ImportsLockfile = null.StringFrom(envVar)
+	}
+	if err := saveBoolFromEnv(environment, "K6_FROZEN_IMPORTS", &opts.FrozenImports); err != nil {
+		return opts, err
+	}
+	if opts.FrozenImports.Bool && opts.ImportsLockfile.String == "" {
+		return opts, errors.New("the imports can only be frozen with an imports lockfile")
+	}
+
+	if err := saveBoolFromEnv(environment, "K6_FS_WRITE", &opts.FSWrite); err != nil {
+		return opts, err
+	}
+	if envVar, ok := environment["K6_FS_WRITE_DIR"]; ok && !opts.FSWriteDir.Valid {
+		opts.FSWriteDir = null.StringFrom(envVar)
+	}
+	if envVar, ok := environment["K6_FS_WRITE_QUOTA"]; ok && !opts.FSWriteQuota.Valid {
+		opts.FSWriteQuota = null.StringFrom(envVar)
+	}
+
 	if envVar, ok := environment["SSLKEYLOGFILE"]; ok {
 		if !opts.KeyWriter.Valid {
 			opts.KeyWriter = null.StringFrom(envVar)
@@ -345,6 +345,36 @@ func TestRuntimeOptions(t *testing.T) {
 			cliFlags:  []string{"--no-summary", "true"},
 			expErr:    true,
 		},
+		"frozen imports with a lockfile": {
+			useSysEnv: false,
+			systemEnv: map[string]string{"K6_IMPORTS_LOCKFILE": "k6.lock.json"},
+			cliFlags:  []string{"--frozen-imports"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				ImportsLockfile:      null.NewString("k6.lock.json", true),
+				FrozenImports:        null.NewBool(true, true),
+			},
+		},
+		"frozen imports without a lockfile": {
+			useSysEnv: false,
+			systemEnv: map[string]string{"K6_FROZEN_IMPORTS": "true"},
+			expErr:    true,
+		},
+		"fs write": {
+			useSysEnv: false,
+			systemEnv: map[string]string{"K6_FS_WRITE": "true", "K6_FS_WRITE_DIR": "/tmp/out", "K6_FS_WRITE_QUOTA": "1GB"},
+			cliFlags:  []string{"--fs-write-dir", "out"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				FSWrite:              null.NewBool(true, true),
+				FSWriteDir:           null.NewString("out", true),
+				FSWriteQuota:         null.NewString("1GB", true),
+			},
+		},
 	}
 	for name, tc := range runtimeOptionsTestCases {
 		tc := tc
@@ -0,0 +1,352 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/event"
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/execution/local"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/metrics"
+)
+
+// cmdSuite handles the `k6 suite` sub-command
+type cmdSuite struct {
+	gs *state.GlobalState
+}
+
+// suiteConfig is the configuration of a test suite, read from a JSON file.
+type suiteConfig struct {
+	// Parallel makes all the tests of the suite run at the same time,
+	// otherwise they are executed one after the other, in their order.
+	Parallel bool              `json:"parallel"`
+	Tests    []suiteTestConfig `json:"tests"`
+}
+
+// suiteTestConfig is the configuration of a single test of a suite.
+type suiteTestConfig struct {
+	// Name identifies the test in the suite, it defaults to the script's file name.
+	Name string `json:"name"`
+	// Script is the path to the test's script or archive, if it's relative,
+	// it's resolved from the directory of the suite's config file.
+	Script string `json:"script"`
+	// Env contains environment variables that are set only for the test.
+	Env map[string]string `json:"env"`
+	// Thresholds replace the ones defined by the script, if they are set.
+	Thresholds map[string]metrics.Thresholds `json:"thresholds"`
+}
+
+// suiteTestResult is the result of a single test of a suite.
+type suiteTestResult struct {
+	name     string
+	skipped  bool
+	duration time.Duration
+	err      error
+	summary  map[string]io.Reader
+}
+
+func readSuiteConfig(gs *state.GlobalState, path string) (suiteConfig, error) {
+	var conf suiteConfig
+
+	if !filepath.IsAbs(path) {
+		pwd, err := gs.Getwd()
+		if err != nil {
+			return conf, err
+		}
+		path = filepath.Join(pwd, path)
+	}
+
+	data, err := fsext.ReadFile(gs.FS, path)
+	if err != nil {
+		return conf, fmt.Errorf("couldn't read the test suite config '%s': %w", path, err)
+	}
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return conf, errext.WithExitCodeIfNone(
+			fmt.Errorf("couldn't parse the test suite config '%s': %w", path, err), exitcodes.InvalidConfig)
+	}
+
+	if err := conf.validateAndResolve(filepath.Dir(path)); err != nil {
+		return conf, errext.WithExitCodeIfNone(
+			fmt.Errorf("invalid test suite config '%s': %w", path, err), exitcodes.InvalidConfig)
+	}
+	return conf, nil
+}
+
+// validateAndResolve validates the config, sets the default test names and
+// resolves the relative script paths from the provided directory.
+func (sc *suiteConfig) validateAndResolve(dir string) error {
+	if len(sc.Tests) == 0 {
+		return errors.New("the suite doesn't have any tests")
+	}
+
+	names := make(map[string]struct{}, len(sc.Tests))
+	for i := range sc.Tests {
+		test := &sc.Tests[i]
+		if test.Script == "" {
+			return fmt.Errorf("test #%d doesn't have a script", i+1)
+		}
+		if test.Name == "" {
+			base := filepath.Base(test.Script)
+			test.Name = strings.TrimSuffix(base, filepath.Ext(base))
+		}
+		if _, ok := names[test.Name]; ok {
+			return fmt.Errorf("there are multiple tests with the name '%s'", test.Name)
+		}
+		names[test.Name] = struct{}{}
+
+		if !filepath.IsAbs(test.Script) && !strings.Contains(test.Script, "://") {
+			test.Script = filepath.Join(dir, test.Script)
+		}
+	}
+
+	return nil
+}
+
+func (c *cmdSuite) run(cmd *cobra.Command, args []string) error {
+	conf, err := readSuiteConfig(c.gs, args[0])
+	if err != nil {
+		return err
+	}
+
+	printBanner(c.gs)
+
+	globalCtx, globalCancel := context.WithCancel(c.gs.Ctx)
+	defer globalCancel()
+
+	// suiteCtx is cancelled by Ctrl+C, so no further tests are started.
+	suiteCtx, suiteCancel := context.WithCancel(globalCtx)
+	defer suiteCancel()
+
+	// The runs of the tests handle the signals on their own, on top of that
+	// the first one skips the rest of the suite.
+	gracefulStop := func(sig os.Signal) {
+		c.gs.Logger.WithField("sig", sig).Debug("Stopping the test suite in response to signal...")
+		suiteCancel()
+	}
+	onHardStop := func(os.Signal) {
+		globalCancel()
+	}
+	stopSignalHandling := handleTestAbortSignals(c.gs, gracefulStop, onHardStop)
+	defer stopSignalHandling()
+
+	// All the tests share the same controller, each one with its own namespace.
+	controller := local.NewController()
+
+	results := make([]*suiteTestResult, len(conf.Tests))
+	runTest := func(i int) {
+		test := conf.Tests[i]
+		result := &suiteTestResult{name: test.Name}
+		if suiteCtx.Err() != nil {
+			result.skipped = true
+			results[i] = result
+			return
+		}
+
+		c.gs.Logger.Debugf("Running test '%s' of the suite...", test.Name)
+		start := time.Now()
+		result.summary, result.err = c.runTest(
+			globalCtx, cmd, test, execution.GetNamespacedController(test.Name, controller),
+		)
+		result.duration = time.Since(start)
+		results[i] = result
+	}
+
+	if conf.Parallel {
+		var wg sync.WaitGroup
+		for i := range conf.Tests {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				runTest(i)
+			}()
+		}
+		wg.Wait()
+	} else {
+		for i := range conf.Tests {
+			runTest(i)
+		}
+	}
+
+	return c.printSuiteSummary(results)
+}
+
+// printSuiteSummary prints the end-of-test summaries of all the tests, followed
+// by the overview of the suite, and it returns an error if any test failed.
+func (c *cmdSuite) printSuiteSummary(results []*suiteTestResult) error {
+	var failed []*suiteTestResult
+	for _, result := range results {
+		if result.err != nil || result.skipped {
+			failed = append(failed, result)
+		}
+		if result.summary == nil {
+			continue
+		}
+		printToStdout(c.gs, fmt.Sprintf("\n  █ test %s\n", result.name))
+		if err := handleSummaryResult(c.gs.FS, c.gs.Stdout, c.gs.Stderr, result.summary); err != nil {
+			c.gs.Logger.WithError(err).Errorf("failed to handle the end-of-test summary of test '%s'", result.name)
+		}
+	}
+
+	if !c.gs.Flags.Quiet {
+		noColor := c.gs.Flags.NoColor || !c.gs.Stdout.IsTTY
+		successColor := getColor(noColor, color.FgGreen)
+		failColor := getColor(noColor, color.FgRed)
+
+		buf := &strings.Builder{}
+		fmt.Fprintf(buf, "\n  █ suite: %d tests, %d passed, %d failed\n\n",
+			len(results), len(results)-len(failed), len(failed))
+		for _, result := range results {
+			switch {
+			case result.skipped:
+				fmt.Fprintf(buf, "    %s %s: skipped\n", failColor.Sprint("✗"), result.name)
+			case result.err != nil:
+				fmt.Fprintf(buf, "    %s %s (%s): %s\n", failColor.Sprint("✗"), result.name,
+					result.duration.Round(time.Millisecond), result.err)
+			default:
+				fmt.Fprintf(buf, "    %s %s (%s)\n", successColor.Sprint("✓"), result.name,
+					result.duration.Round(time.Millisecond))
+			}
+		}
+		fmt.Fprintf(buf, "\n")
+		printToStdout(c.gs, buf.String())
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	// the exit code is the one of the first failed test
+	exitCode := exitcodes.ExternalAbort
+	var ecErr errext.HasExitCode
+	if first := failed[0]; first.err != nil && errors.As(first.err, &ecErr) {
+		exitCode = ecErr.ExitCode()
+	}
+	return errext.WithExitCodeIfNone(
+		fmt.Errorf("%d of the %d tests in the suite have failed", len(failed), len(results)), exitCode)
+}
+
+// testGlobalState returns the GlobalState for running the given test, with
+// the test's environment variables on top of the global ones. Each test has
+// its own event system and it doesn't start the REST API server, since the
+// tests of a suite could run at the same time.
+func (c *cmdSuite) testGlobalState(ctx context.Context, test suiteTestConfig) *state.GlobalState {
+	gs := *c.gs
+	gs.Ctx = ctx
+	gs.Events = event.NewEventSystem(100, c.gs.Logger)
+	gs.Flags.Address = ""
+
+	gs.Env = make(map[string]string, len(c.gs.Env)+len(test.Env))
+	for k, v := range c.gs.Env {
+		gs.Env[k] = v
+	}
+	for k, v := range test.Env {
+		gs.Env[k] = v
+	}
+	return &gs
+}
+
+// runTest runs a single test of the suite, like `k6 run`, and returns
+// its end-of-test summary, unless it's disabled.
+func (c *cmdSuite) runTest(
+	ctx context.Context, cmd *cobra.Command, suiteTest suiteTestConfig, controller execution.Controller,
+) (summary map[string]io.Reader, err error) {
+	gs := c.testGlobalState(ctx, suiteTest)
+	runCmd := &cmdRun{
+		gs:            gs,
+		executionType: "local",
+		loadTest: func(cmd *cobra.Command, _ []string) (*loadedAndConfiguredTest, execution.Controller, error) {
+			test, err := loadAndConfigureTest(gs, cmd, []string{suiteTest.Script}, func(flags *pflag.FlagSet) (Config, error) {
+				conf, err := getConfig(flags)
+				if err == nil && suiteTest.Thresholds != nil {
+					conf.Options.Thresholds = suiteTest.Thresholds
+				}
+				return conf, err
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			// the suite doesn't wait for Ctrl+C after each one of its tests
+			test.derivedConfig.Linger = null.BoolFrom(false)
+			return test, controller, nil
+		},
+		handleSummary: func(s map[string]io.Reader) error {
+			summary = s
+			return nil
+		},
+		noBanner: true,
+	}
+
+	err = runCmd.run(cmd, []string{suiteTest.Script})
+	return summary, err
+}
+
+func (c *cmdSuite) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.AddFlagSet(optionFlagSet())
+	flags.AddFlagSet(runtimeOptionFlagSet(true))
+	flags.AddFlagSet(configFlagSet())
+	return flags
+}
+
+func getCmdSuite(gs *state.GlobalState) *cobra.Command {
+	c := &cmdSuite{
+		gs: gs,
+	}
+
+	exampleText := getExampleText(gs, `
+  # Run all the tests listed in a suite config file.
+  {{.}} suite suite.json
+
+  # Run all the tests of a suite with 10 VUs for 30s each.
+  {{.}} suite -u 10 -d 30s suite.json`[1:])
+
+	suiteCmd := &cobra.Command{
+		Use:   "suite",
+		Short: "Run a suite of tests",
+		Long: `Run a suite of tests.
+
+The suite is described by a JSON config file, listing the tests to run, either
+one after the other or at the same time:
+
+  {
+    "parallel": false,
+    "tests": [
+      { "name": "login", "script": "login.js", "env": { "USER": "admin" } },
+      { "script": "checkout.js", "thresholds": { "http_req_failed": ["rate<0.01"] } }
+    ]
+  }
+
+The relative script paths are resolved from the directory of the config file,
+the test names default to the scripts' file names. The thresholds of a test,
+if set, replace the ones defined by its script. The CLI flags apply to all the
+tests, and an end-of-test summary is shown for each one of them, followed by an
+overview of the whole suite.`,
+		Example: exampleText,
+		Args:    exactArgsWithMsg(1, "arg should be a path to a test suite config file"),
+		RunE:    c.run,
+	}
+
+	suiteCmd.Flags().SortFlags = false
+	suiteCmd.Flags().AddFlagSet(c.flagSet())
+
+	return suiteCmd
+}
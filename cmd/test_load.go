@@ -18,6 +18,7 @@ import (
 	"go.k6.io/k6/errext/exitcodes"
 	"go.k6.io/k6/js"
 	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/executor"
 	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/loader"
 	"go.k6.io/k6/metrics"
@@ -46,24 +47,72 @@ func loadTest(gs *state.GlobalState, cmd *cobra.Command, args []string) (*loaded
 		return nil, fmt.Errorf("k6 needs at least one argument to load the test")
 	}
 
+	lockfile, err := readImportsLockfile(gs, cmd)
+	if err != nil {
+		return nil, err
+	}
+
 	sourceRootPath := args[0]
 	gs.Logger.Debugf("Resolving and reading test '%s'...", sourceRootPath)
-	src, fileSystems, pwd, err := readSource(gs, sourceRootPath)
+	src, fileSystems, pwd, err := readSource(gs, sourceRootPath, lockfile)
 	if err != nil {
 		return nil, err
 	}
-	resolvedPath := src.URL.String()
 	gs.Logger.Debugf(
 		"'%s' resolved to '%s' and successfully loaded %d bytes!",
-		sourceRootPath, resolvedPath, len(src.Data),
+		sourceRootPath, src.URL.String(), len(src.Data),
 	)
 
+	test, err := loadTestFromSource(gs, cmd, sourceRootPath, src, fileSystems, pwd, lockfile)
+	if err != nil {
+		return nil, err
+	}
+	// all the modules are imported in the init context, so the first runner
+	// has loaded them all
+	if err = lockfile.Save(); err != nil {
+		return nil, err
+	}
+	return test, nil
+}
+
+// readImportsLockfile reads the lockfile of the remote modules, if one was
+// specified.
+func readImportsLockfile(gs *state.GlobalState, cmd *cobra.Command) (*loader.Lockfile, error) {
+	runtimeOptions, err := getRuntimeOptions(cmd.Flags(), gs.Env)
+	if err != nil {
+		return nil, err
+	}
+	if !runtimeOptions.ImportsLockfile.Valid || runtimeOptions.ImportsLockfile.String == "" {
+		return nil, nil //nolint:nilnil
+	}
+	filename := runtimeOptions.ImportsLockfile.String
+	if !filepath.IsAbs(filename) {
+		pwd, err := gs.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		filename = filepath.Join(pwd, filename)
+	}
+	return loader.ReadLockfile(gs.FS, filename, runtimeOptions.FrozenImports.Bool)
+}
+
+// loadTestFromSource initializes the test from its already read source,
+// e.g. an archive that was received over the network.
+func loadTestFromSource(
+	gs *state.GlobalState, cmd *cobra.Command, sourceRootPath string,
+	src *loader.SourceData, fileSystems map[string]fsext.Fs, pwd string, lockfile *loader.Lockfile,
+) (*loadedTest, error) {
 	gs.Logger.Debugf("Gathering k6 runtime options...")
 	runtimeOptions, err := getRuntimeOptions(cmd.Flags(), gs.Env)
 	if err != nil {
 		return nil, err
 	}
 
+	var importsVerifier lib.ImportsVerifier
+	if lockfile != nil {
+		importsVerifier = lockfile
+	}
+
 	registry := metrics.NewRegistry()
 	state := &lib.TestPreInitState{
 		Logger:         gs.Logger,
@@ -75,6 +124,8 @@ func loadTest(gs *state.GlobalState, cmd *cobra.Command, args []string) (*loaded
 			val, ok := gs.Env[key]
 			return val, ok
 		},
+		ImportsVerifier: importsVerifier,
+		ConnStats:       lib.NewConnStats(),
 	}
 
 	test := &loadedTest{
@@ -86,7 +137,7 @@ func loadTest(gs *state.GlobalState, cmd *cobra.Command, args []string) (*loaded
 		preInitState:   state,
 	}
 
-	gs.Logger.Debugf("Initializing k6 runner for '%s' (%s)...", sourceRootPath, resolvedPath)
+	gs.Logger.Debugf("Initializing k6 runner for '%s' (%s)...", sourceRootPath, src.URL.String())
 	if err := test.initializeFirstRunner(gs); err != nil {
 		return nil, fmt.Errorf("could not initialize '%s': %w", sourceRootPath, err)
 	}
@@ -160,14 +211,16 @@ func (lt *loadedTest) initializeFirstRunner(gs *state.GlobalState) error {
 
 // readSource is a small wrapper around loader.ReadSource returning
 // result of the load and filesystems map
-func readSource(gs *state.GlobalState, filename string) (*loader.SourceData, map[string]fsext.Fs, string, error) {
+func readSource(
+	gs *state.GlobalState, filename string, lockfile *loader.Lockfile,
+) (*loader.SourceData, map[string]fsext.Fs, string, error) {
 	pwd, err := gs.Getwd()
 	if err != nil {
 		return nil, nil, "", err
 	}
 
 	filesystems := loader.CreateFilesystems(gs.FS)
-	src, err := loader.ReadSource(gs.Logger, filename, pwd, filesystems, gs.Stdin)
+	src, err := loader.ReadSource(gs.Logger, filename, pwd, filesystems, lockfile, gs.Stdin)
 	return src, filesystems, pwd, err
 }
 
@@ -198,6 +251,10 @@ func (lt *loadedTest) consolidateDeriveAndValidateConfig(
 		return nil, err
 	}
 
+	if err = loadScenarioFiles(lt.fs, lt.pwd, consolidatedConfig.Scenarios); err != nil {
+		return nil, errext.WithExitCodeIfNone(err, exitcodes.InvalidConfig)
+	}
+
 	gs.Logger.Debug("Parsing thresholds and validating config...")
 	// Parse the thresholds, only if the --no-threshold flag is not set.
 	// If parsing the threshold expressions failed, consider it as an
@@ -228,6 +285,20 @@ func (lt *loadedTest) consolidateDeriveAndValidateConfig(
 	}, nil
 }
 
+// loadScenarioFiles loads the data that the scenarios read from files, like
+// the profiles of the profile-replay executor, in their configs. This way the
+// data is a part of the options, which are also saved in the archives.
+func loadScenarioFiles(fs fsext.Fs, pwd string, scenarios lib.ScenarioConfigs) error {
+	for _, scenario := range scenarios {
+		if prc, ok := scenario.(*executor.ProfileReplayConfig); ok {
+			if err := prc.LoadProfile(fs, pwd); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // loadedAndConfiguredTest contains the whole loadedTest, as well as the
 // consolidated test config and the full test run state.
 type loadedAndConfiguredTest struct {
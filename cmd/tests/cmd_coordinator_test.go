@@ -0,0 +1,217 @@
+package tests
+
+import (
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/cmd"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/lib/fsext"
+)
+
+func TestCoordinatorCommands(t *testing.T) {
+	t.Parallel()
+
+	addr := getFreeBindAddr(t)
+	coordinatorState := NewGlobalTestState(t)
+	coordinatorState.CmdArgs = []string{
+		"k6", "coordinator", "--coordinator-address", addr, "--instance-count", "2",
+		"--instance-loss-policy", "continue",
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		cmd.ExecuteWithGlobalState(coordinatorState.GlobalState)
+	}()
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// none of the agent instances are connected
+	ts := NewGlobalTestState(t)
+	ts.CmdArgs = []string{"k6", "coordinator", "scale", "--coordinator-address", addr, "--vus", "10"}
+	ts.ExpectedExitCode = -1
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Contains(t, ts.Stderr.String(), "only 0 of the 2 instances of the test are connected")
+
+	ts = NewGlobalTestState(t)
+	ts.CmdArgs = []string{"k6", "coordinator", "scale", "--coordinator-address", addr}
+	ts.ExpectedExitCode = -1
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Contains(t, ts.Stderr.String(), "specify either -u/--vus, -m/--max or --rate")
+
+	coordinatorState.Cancel()
+	wg.Wait()
+	assert.Contains(t, coordinatorState.Stderr.String(), "The coordinator is waiting for 2 instances")
+}
+
+func TestCoordinatorInvalidPolicy(t *testing.T) {
+	t.Parallel()
+
+	ts := NewGlobalTestState(t)
+	ts.CmdArgs = []string{"k6", "coordinator", "--instance-loss-policy", "retry"}
+	ts.ExpectedExitCode = -1
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Contains(t, ts.Stderr.String(), "invalid instance loss policy 'retry'")
+}
+
+func TestAgentsReceiveTheArchiveFromTheCoordinator(t *testing.T) {
+	t.Parallel()
+
+	script := `
+		export const options = {
+			scenarios: {
+				sc: { executor: 'shared-iterations', vus: 2, iterations: 10 },
+			},
+			thresholds: {
+				iterations: ['count == 10'],
+			},
+		};
+		export default function () {};
+	`
+
+	addr := getFreeBindAddr(t)
+	coordinatorState := NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(
+		coordinatorState.FS, filepath.Join(coordinatorState.Cwd, "test.js"), []byte(script), 0o644))
+	coordinatorState.CmdArgs = []string{
+		"k6", "coordinator", "--coordinator-address", addr, "--instance-count", "2", "test.js",
+	}
+
+	var coordinatorWG sync.WaitGroup
+	coordinatorWG.Add(1)
+	go func() {
+		defer coordinatorWG.Done()
+		cmd.ExecuteWithGlobalState(coordinatorState.GlobalState)
+	}()
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the agents don't have the script, they receive its archive
+	agents := []*GlobalTestState{NewGlobalTestState(t), NewGlobalTestState(t)}
+	var agentsWG sync.WaitGroup
+	for _, ts := range agents {
+		ts := ts
+		ts.CmdArgs = []string{"k6", "agent", "--coordinator-address", addr, "--status-interval", "50ms"}
+		agentsWG.Add(1)
+		go func() {
+			defer agentsWG.Done()
+			cmd.ExecuteWithGlobalState(ts.GlobalState)
+		}()
+	}
+	agentsWG.Wait()
+
+	for _, ts := range agents {
+		stdout := ts.Stdout.String()
+		assert.Contains(t, stdout, "execution: agent (instance")
+		assert.Contains(t, stdout, "distributed (coordinator, instance")
+		// the summary is shown by the coordinator, for the whole test
+		assert.NotContains(t, stdout, "iterations...........")
+	}
+
+	// the coordinator exits once the agents have finished the test
+	coordinatorWG.Wait()
+	stdout := coordinatorState.Stdout.String()
+	assert.Contains(t, stdout, "execution: coordinator")
+	assert.Contains(t, stdout, "✓ iterations...........: 10")
+	stderr := coordinatorState.Stderr.String()
+	assert.Contains(t, stderr, "Instance 1 is Ended, with 0 VUs and 5 complete iterations")
+	assert.Contains(t, stderr, "Instance 2 is Ended, with 0 VUs and 5 complete iterations")
+}
+
+func TestCoordinatorThresholds(t *testing.T) {
+	t.Parallel()
+
+	script := `
+		export const options = {
+			iterations: 4,
+			thresholds: {
+				iterations: ['count > 4'],
+			},
+		};
+		export default function () {};
+	`
+
+	addr := getFreeBindAddr(t)
+	coordinatorState := NewGlobalTestState(t)
+	coordinatorState.ExpectedExitCode = int(exitcodes.ThresholdsHaveFailed)
+	coordinatorState.CmdArgs = []string{"k6", "coordinator", "--coordinator-address", addr, "--instance-count", "1"}
+
+	var coordinatorWG sync.WaitGroup
+	coordinatorWG.Add(1)
+	go func() {
+		defer coordinatorWG.Done()
+		cmd.ExecuteWithGlobalState(coordinatorState.GlobalState)
+	}()
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the coordinator gets the archive created by the agent
+	ts := NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "test.js"), []byte(script), 0o644))
+	ts.CmdArgs = []string{"k6", "agent", "--coordinator-address", addr, "test.js"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	coordinatorWG.Wait()
+	assert.Contains(t, coordinatorState.Stdout.String(), "✗ iterations...........: 4")
+	assert.Contains(t, coordinatorState.Stderr.String(), "thresholds on metrics 'iterations' have been crossed")
+}
+
+func TestAgentWithoutScript(t *testing.T) {
+	t.Parallel()
+
+	addr := getFreeBindAddr(t)
+	coordinatorState := NewGlobalTestState(t)
+	coordinatorState.ExpectedExitCode = -1
+	coordinatorState.CmdArgs = []string{"k6", "coordinator", "--coordinator-address", addr}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		cmd.ExecuteWithGlobalState(coordinatorState.GlobalState)
+	}()
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	ts := NewGlobalTestState(t)
+	ts.CmdArgs = []string{"k6", "agent", "--coordinator-address", addr}
+	ts.ExpectedExitCode = -1
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Contains(t, ts.Stderr.String(), "the test script wasn't passed to the coordinator or to the agent archiving it")
+
+	// the coordinator can't get the archive either
+	wg.Wait()
+	assert.Contains(t, coordinatorState.Stderr.String(),
+		"couldn't get the archive of the test: the test script wasn't passed to the coordinator")
+}
@@ -230,6 +230,16 @@ func TestWrongEnvVarIterations(t *testing.T) {
 	assert.Empty(t, ts.LoggerHook.Drain())
 }
 
+func TestRedisControllerWithoutPrefix(t *testing.T) {
+	t.Parallel()
+
+	ts := getSingleFileTestState(t, `export default function() {};`,
+		[]string{"--redis-controller", "redis://127.0.0.1:6379"}, exitcodes.InvalidConfig)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.True(t, testutils.LogContains(ts.LoggerHook.Drain(), logrus.ErrorLevel,
+		"the --redis-controller-prefix option is required with --redis-controller"))
+}
+
 func getSingleFileTestState(tb testing.TB, script string, cliFlags []string, expExitCode exitcodes.ExitCode) *GlobalTestState {
 	if cliFlags == nil {
 		cliFlags = []string{"-v", "--log-output=stdout"}
@@ -434,7 +444,7 @@ func TestExecutionTestOptionsDefaultValues(t *testing.T) {
 	loglines := ts.LoggerHook.Drain()
 	require.Len(t, loglines, 1)
 
-	expected := `{"paused":null,"executionSegment":null,"executionSegmentSequence":null,"noSetup":null,"setupTimeout":null,"noTeardown":null,"teardownTimeout":null,"rps":null,"dns":{"ttl":null,"select":null,"policy":null},"maxRedirects":null,"userAgent":null,"batch":null,"batchPerHost":null,"httpDebug":null,"insecureSkipTLSVerify":null,"tlsCipherSuites":null,"tlsVersion":null,"tlsAuth":null,"throw":null,"thresholds":null,"blacklistIPs":null,"blockHostnames":null,"hosts":null,"noConnectionReuse":null,"noVUConnectionReuse":null,"minIterationDuration":null,"ext":null,"summaryTrendStats":["avg", "min", "med", "max", "p(90)", "p(95)"],"summaryTimeUnit":null,"systemTags":["check","error","error_code","expected_response","group","method","name","proto","scenario","service","status","subproto","tls_version","url"],"tags":null,"metricSamplesBufferSize":null,"noCookiesReset":null,"discardResponseBodies":null,"consoleOutput":null,"scenarios":{"default":{"vus":null,"iterations":1,"executor":"shared-iterations","maxDuration":null,"startTime":null,"env":null,"tags":null,"gracefulStop":null,"exec":null}},"localIPs":null}`
+	expected := `{"paused":null,"executionSegment":null,"executionSegmentSequence":null,"noSetup":null,"setupTimeout":null,"noTeardown":null,"teardownTimeout":null,"abortPolicy":null,"abortGracePeriod":null,"rps":null,"dns":{"ttl":null,"select":null,"policy":null},"maxRedirects":null,"userAgent":null,"batch":null,"batchPerHost":null,"httpDebug":null,"insecureSkipTLSVerify":null,"tlsCipherSuites":null,"tlsVersion":null,"tlsAuth":null,"throw":null,"thresholds":null,"thresholdsWebhook":null,"blacklistIPs":null,"blockHostnames":null,"hosts":null,"noConnectionReuse":null,"maxConnectionsPerHost":null,"maxIdleConnections":null,"noVUConnectionReuse":null,"minIterationDuration":null,"ext":null,"summaryTrendStats":["avg", "min", "med", "max", "p(90)", "p(95)"],"summaryTimeUnit":null,"trendSinkMaxValues":null,"timeSeriesLimit":null,"urlGrouping":null,"gaugeTTL":null,"noWarmupExport":null,"systemTags":["aborted","check","error","error_code","expected_response","group","method","name","proto","scenario","service","status","subproto","tls_version","url"],"tags":null,"metricSamplesBufferSize":null,"noCookiesReset":null,"cookies":null,"discardResponseBodies":null,"harSampleRate":null,"harTags":null,"consoleOutput":null,"scenarios":{"default":{"vus":null,"iterations":1,"executor":"shared-iterations","maxDuration":null,"startAfter":null,"dormant":null,"startTime":null,"env":null,"tags":null,"gracefulStop":null,"maxIterationDuration":null,"warmupDuration":null,"exec":null}},"localIPs":null}`
 	assert.JSONEq(t, expected, loglines[0].Message)
 }
 
@@ -1189,6 +1199,179 @@ func testAbortedByScriptTestAbort(t *testing.T, script string, runTest func(*tes
 	assert.Contains(t, stdout, "bogus summary")
 }
 
+func TestScriptMarkedAsFailed(t *testing.T) {
+	t.Parallel()
+	script := `
+		import exec from 'k6/execution';
+		import { Counter } from 'k6/metrics';
+
+		const finished = new Counter('finished');
+
+		export const options = {
+			iterations: 3,
+			thresholds: { finished: ['count == 3'] },
+		};
+
+		export default function () {
+			exec.test.fail('bad response');
+			finished.add(1);
+		};
+	`
+
+	ts := getSimpleCloudOutputTestState(
+		t, script, nil, cloudapi.RunStatusFinished, cloudapi.ResultStatusFailed, exitcodes.ScriptMarkedAsFailed,
+	)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Contains(t, stdout, "3 complete and 0 interrupted iterations")
+	assert.Contains(t, stdout, "the test run was marked as failed: bad response")
+	assert.Contains(t, stdout, `level=debug msg="Sending test finished" output=cloud ref=111 run_status=3 tainted=true`)
+}
+
+func TestScenarioAbortAndIterationSkip(t *testing.T) {
+	t.Parallel()
+	script := `
+		import exec from 'k6/execution';
+		import { Counter } from 'k6/metrics';
+
+		const aDone = new Counter('a_done');
+		const bDone = new Counter('b_done');
+
+		export const options = {
+			scenarios: {
+				a: { executor: 'per-vu-iterations', vus: 1, iterations: 10, exec: 'a' },
+				b: { executor: 'shared-iterations', vus: 1, iterations: 4, exec: 'b' },
+			},
+			thresholds: {
+				a_done: ['count == 2'],
+				b_done: ['count == 2'],
+			},
+		};
+
+		export function a() {
+			if (exec.scenario.iterationInTest == 2) {
+				exec.scenario.abort('enough');
+			}
+			aDone.add(1);
+		};
+
+		export function b() {
+			try {
+				if (exec.scenario.iterationInTest % 2 == 1) {
+					exec.iteration.skipRemaining();
+				}
+			} catch (e) {
+				bDone.add(100);
+			}
+			bDone.add(1);
+		};
+	`
+
+	ts := getSingleFileTestState(t, script, nil, 0)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Contains(t, stdout, "The scenario was aborted by the script: scenario aborted: enough")
+	assert.Contains(t, stdout, "6 complete and 1 interrupted iterations")
+	assert.NotContains(t, stdout, "level=error")
+}
+
+func TestLifecycleEventHandlers(t *testing.T) {
+	t.Parallel()
+	script := `
+		import exec from 'k6/execution';
+
+		exec.events.on('testStart', () => console.log('testStart in VU ' + __VU));
+		exec.events.on('scenarioStart', (e) => console.log('scenarioStart ' + e.scenario + ' in VU ' + e.vu));
+		exec.events.on('iterationEnd', (e) => {
+			console.log('iterationEnd ' + e.scenario + ' ' + e.iteration + ' error=' + e.error);
+		});
+		exec.events.on('testEnd', () => { throw new Error('failed reporter'); });
+
+		export const options = {
+			scenarios: {
+				a: { executor: 'shared-iterations', vus: 1, iterations: 2, exec: 'a' },
+				b: { executor: 'shared-iterations', vus: 1, iterations: 1, exec: 'b', startTime: '100ms' },
+			},
+		};
+
+		export function setup() {
+			try {
+				exec.events.on('testEnd', () => {});
+			} catch (e) {
+				console.log(e.message);
+			}
+		}
+
+		export function a() {};
+
+		export function b() {
+			throw new Error('oops');
+		};
+	`
+
+	ts := getSingleFileTestState(t, script, nil, 0)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	// the handlers are called by each VU running iterations
+	assert.Equal(t, 1, strings.Count(stdout, "testStart in VU 1"))
+	assert.Equal(t, 1, strings.Count(stdout, "testStart in VU 2"))
+	assert.Equal(t, 1, strings.Count(stdout, "scenarioStart a in VU 1"))
+	assert.Equal(t, 1, strings.Count(stdout, "scenarioStart b in VU 2"))
+	assert.Contains(t, stdout, "iterationEnd a 0 error=null")
+	assert.Contains(t, stdout, "iterationEnd a 1 error=null")
+	assert.Contains(t, stdout, "iterationEnd b 0 error=Error: oops")
+	assert.Equal(t, 2, strings.Count(stdout, "The handler of the testEnd event failed"))
+	assert.Contains(t, stdout, "the event handlers can only be registered in the init context")
+	assert.Contains(t, stdout, "failed reporter")
+}
+
+func TestTestingModuleWithStubbedHTTP(t *testing.T) {
+	t.Parallel()
+	ts := NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "users.js"), []byte(`
+		import http from 'k6/http';
+
+		export function getUserName(id) {
+			return http.get('https://users.invalid/' + id).json().name;
+		}
+	`), 0o644))
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "test.js"), []byte(`
+		import http from 'k6/http';
+		import { test, expect, stub } from 'k6/experimental/testing';
+		import { getUserName } from './users.js';
+
+		export default function () {
+			const get = stub(http, 'get', (url) => ({ json: () => ({ name: 'user ' + url.split('/').pop() }) }));
+			test('gets the name', () => {
+				expect(getUserName(1)).toBe('user 1');
+				expect(get.callCount).toBe(1);
+			});
+			test('is broken', () => {
+				expect(getUserName(2)).toBe('user 1');
+			});
+		}
+	`), 0o644))
+	ts.CmdArgs = []string{"k6", "run", "--quiet", "test.js"}
+	ts.ExpectedExitCode = int(exitcodes.ScriptMarkedAsFailed)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	stderr := ts.Stderr.String()
+	t.Log(stderr)
+	assert.Contains(t, stderr, `level=info msg="✓ gets the name" source=testing`)
+	assert.Contains(t, stderr, `✗ is broken: AssertionError: expected \"user 2\" to be \"user 1\"`)
+	assert.Contains(t, stderr, `the test run was marked as failed: the test \"is broken\" failed`)
+	assert.Contains(t, stdout, "✗ is broken")
+	assert.NotContains(t, stderr, "users.invalid")
+}
+
 func TestAbortedByInterruptDuringVUInit(t *testing.T) {
 	t.Parallel()
 	script := `
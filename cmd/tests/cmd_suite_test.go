@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/cmd"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/lib/testutils"
+)
+
+func getSuiteTestState(
+	tb testing.TB, suiteConfig string, scripts map[string]string, expExitCode exitcodes.ExitCode,
+) *GlobalTestState {
+	ts := NewGlobalTestState(tb)
+	for name, script := range scripts {
+		require.NoError(tb, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "scripts", name), []byte(script), 0o644))
+	}
+	require.NoError(tb, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "suite.json"), []byte(suiteConfig), 0o644))
+	ts.CmdArgs = []string{"k6", "suite", "--no-usage-report", "scripts/../suite.json"}
+	ts.ExpectedExitCode = int(expExitCode)
+
+	return ts
+}
+
+func TestSuiteSequential(t *testing.T) {
+	t.Parallel()
+
+	scripts := map[string]string{
+		"first.js": `
+			import { Counter } from 'k6/metrics';
+			const counter = new Counter('suite_counter');
+			export const options = { iterations: 2 };
+			export default function () {
+				counter.add(1);
+				console.log('first: ' + __ENV.SUITE_VAR);
+			};
+		`,
+		"second.js": `
+			import { Counter } from 'k6/metrics';
+			const counter = new Counter('suite_counter');
+			export const options = {
+				iterations: 1,
+				thresholds: { suite_counter: ['count == 1'] },
+			};
+			export default function () { counter.add(1); };
+		`,
+	}
+	suiteConfig := `{
+		"tests": [
+			{ "name": "first", "script": "scripts/first.js", "env": { "SUITE_VAR": "foo" } },
+			{ "script": "scripts/second.js", "thresholds": { "suite_counter": ["count > 1"] } }
+		]
+	}`
+
+	ts := getSuiteTestState(t, suiteConfig, scripts, exitcodes.ThresholdsHaveFailed)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Contains(t, stdout, "█ test first")
+	assert.Contains(t, stdout, "█ test second")
+	assert.Contains(t, stdout, "✗ suite_counter")
+	assert.Contains(t, stdout, "█ suite: 2 tests, 1 passed, 1 failed")
+	assert.Contains(t, stdout, "✓ first (")
+	assert.Contains(t, stdout, "thresholds on metrics 'suite_counter' have been crossed")
+	assert.True(t, testutils.LogContains(ts.LoggerHook.Drain(), logrus.InfoLevel, "first: foo"))
+}
+
+func TestSuiteParallel(t *testing.T) {
+	t.Parallel()
+
+	script := `
+		export const options = { iterations: 1 };
+		export function setup() { return { value: __ENV.SUITE_VAR }; }
+		export default function (data) { console.log('value: ' + data.value); };
+	`
+	suiteConfig := `{
+		"parallel": true,
+		"tests": [
+			{ "name": "a", "script": "scripts/test.js", "env": { "SUITE_VAR": "a" } },
+			{ "name": "b", "script": "scripts/test.js", "env": { "SUITE_VAR": "b" } }
+		]
+	}`
+
+	ts := getSuiteTestState(t, suiteConfig, map[string]string{"test.js": script}, 0)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Contains(t, stdout, "█ suite: 2 tests, 2 passed, 0 failed")
+
+	// each test has its own setup data, since their IDs are namespaced
+	logs := ts.LoggerHook.Drain()
+	assert.True(t, testutils.LogContains(logs, logrus.InfoLevel, "value: a"))
+	assert.True(t, testutils.LogContains(logs, logrus.InfoLevel, "value: b"))
+}
+
+func TestSuiteScriptMarkedAsFailed(t *testing.T) {
+	t.Parallel()
+
+	scripts := map[string]string{
+		"failed.js": `
+			import exec from 'k6/execution';
+			export const options = { iterations: 1 };
+			export default function () { exec.test.fail('bad response'); };
+		`,
+		"passed.js": `export default function () {};`,
+	}
+	suiteConfig := `{
+		"tests": [
+			{ "script": "scripts/failed.js" },
+			{ "script": "scripts/passed.js" }
+		]
+	}`
+
+	ts := getSuiteTestState(t, suiteConfig, scripts, exitcodes.ScriptMarkedAsFailed)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Contains(t, stdout, "█ suite: 2 tests, 1 passed, 1 failed")
+	assert.Contains(t, stdout, "the test run was marked as failed: bad response")
+}
+
+func TestSuiteInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"no tests":        `{ "tests": [] }`,
+		"no script":       `{ "tests": [{ "name": "a" }] }`,
+		"duplicate names": `{ "tests": [{ "script": "a.js" }, { "script": "other/a.js" }] }`,
+		"invalid json":    `{ "tests": `,
+	}
+
+	for name, suiteConfig := range testCases {
+		suiteConfig := suiteConfig
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := getSuiteTestState(t, suiteConfig, nil, exitcodes.InvalidConfig)
+			cmd.ExecuteWithGlobalState(ts.GlobalState)
+			assert.True(t, testutils.LogContains(ts.LoggerHook.Drain(), logrus.ErrorLevel, "suite.json"))
+		})
+	}
+}
@@ -307,7 +307,7 @@ func TestTracingInstrumentHTTP_Jaeger(t *testing.T) {
 	tb.Mux.HandleFunc("/tracing", func(w http.ResponseWriter, r *http.Request) {
 		atomic.AddInt64(&gotRequests, 1)
 		assert.NotEmpty(t, r.Header.Get("uber-trace-id"))
-		assert.Len(t, r.Header.Get("uber-trace-id"), 45)
+		assert.Len(t, r.Header.Get("uber-trace-id"), 53)
 	})
 
 	script := tb.Replacer.Replace(`
@@ -15,6 +15,7 @@ const (
 	AbortedByScriptAbort
 	AbortedByTimeout
 	AbortedByOutput
+	AbortedByScriptFail // the test run wasn't stopped, but the script marked it as failed
 )
 
 // HasAbortReason is a wrapper around an error with an attached abort reason.
@@ -53,4 +53,8 @@ const (
 
 	// GoPanic indicates the script was aborted by a panic in the Go runtime.
 	GoPanic ExitCode = 109
+
+	// ScriptMarkedAsFailed indicates the test run was marked as failed by a
+	// call to the k6 execution module's `test.fail()` function.
+	ScriptMarkedAsFailed ExitCode = 110
 )
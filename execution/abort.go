@@ -82,3 +82,53 @@ func GetCancelReasonIfTestAborted(ctx context.Context) error {
 	}
 	return nil
 }
+
+// scenarioAbortKey is the key used to store the abort function for the context
+// of the executor of a scenario, see AbortScenario().
+type scenarioAbortKey struct{}
+
+type scenarioAbortController struct {
+	cancel context.CancelFunc
+
+	lock   sync.Mutex
+	reason string
+	done   bool
+}
+
+func (sac *scenarioAbortController) abort(reason string) {
+	sac.lock.Lock()
+	defer sac.lock.Unlock()
+	if sac.done {
+		return
+	}
+	sac.reason, sac.done = reason, true
+	sac.cancel()
+}
+
+func (sac *scenarioAbortController) getReason() (string, bool) {
+	sac.lock.Lock()
+	defer sac.lock.Unlock()
+	return sac.reason, sac.done
+}
+
+// newScenarioContext returns a sub-context of the executors' run context for
+// the executor of a scenario, which is cancelled when the scenario is aborted,
+// while the other scenarios continue. The returned function returns the
+// reason the scenario was aborted for, if it was.
+func newScenarioContext(ctx context.Context) (context.Context, context.CancelFunc, func() (string, bool)) {
+	ctx, cancel := context.WithCancel(ctx)
+	controller := &scenarioAbortController{cancel: cancel}
+	return context.WithValue(ctx, scenarioAbortKey{}, controller), cancel, controller.getReason
+}
+
+// AbortScenario cancels the context of the scenario the provided context
+// belongs to, so its executor stops and its in-flight iterations are
+// interrupted. It returns false if the context isn't the context of a
+// scenario or a sub-context of one.
+func AbortScenario(ctx context.Context, reason string) bool {
+	if v, ok := ctx.Value(scenarioAbortKey{}).(*scenarioAbortController); ok {
+		v.abort(reason)
+		return true
+	}
+	return false
+}
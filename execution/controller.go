@@ -0,0 +1,106 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Controller implementations are used to control the k6 execution of a test,
+// either locally or across multiple k6 instances in a distributed run. They
+// synchronize the instances at specific events of the test life-cycle and
+// allow them to share data, e.g. the result of the setup() execution.
+//
+// Multiple tests, e.g. the ones of a test suite, can share the same Controller
+// by wrapping it with GetNamespacedController().
+type Controller interface {
+	// GetOrCreateData requests the data chunk with the given ID, if it already
+	// exists. If it doesn't (i.e. this was the first time this function was
+	// called with that ID), the given callback is called and its result and
+	// error are saved for the ID and returned for all other calls with it.
+	//
+	// This is an atomic function, so any calls to it while the callback is
+	// being executed for the same ID will wait for the first call to finish
+	// and receive its result.
+	GetOrCreateData(id string, callback func() ([]byte, error)) ([]byte, error)
+
+	// Signal is used to notify that the current instance has reached
+	// the given event ID.
+	Signal(eventID string) error
+
+	// SignalError is used to notify that the current instance has encountered
+	// the given error at the given event ID, e.g. when it has aborted the test.
+	// All the instances waiting for the event, including the ones that start
+	// waiting for it later, receive the error instead of waiting for the event
+	// to be reached by all of them.
+	SignalError(eventID string, err error) error
+
+	// Wait creates a listener for the specified event ID and returns a
+	// callback that blocks until all instances have reached it, i.e.
+	// all of them have signaled it.
+	//
+	// The listener is created before Wait returns, so the returned callback
+	// can't miss the event, even if it's signaled before it's called. The
+	// callback stops blocking and returns the context's error as soon as the
+	// given context is done, so waiting can be canceled or timed out.
+	Wait(ctx context.Context, eventID string) (wait func() error)
+}
+
+// SignalAndWait implements a rendezvous point / barrier, a way for all
+// instances to reach the same execution point and wait for each other, before
+// they all continue with the execution. It stops waiting for the other
+// instances when the given context is done, e.g. because one of them crashed.
+func SignalAndWait(ctx context.Context, c Controller, eventID string) error {
+	wait := c.Wait(ctx, eventID)
+	if err := c.Signal(eventID); err != nil {
+		return err
+	}
+	return wait()
+}
+
+// SignalAndWaitWithTimeout is like SignalAndWait, but it returns an error if
+// all the instances don't reach the event within the given timeout.
+func SignalAndWaitWithTimeout(ctx context.Context, c Controller, eventID string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := SignalAndWait(ctx, c, eventID)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s waiting for all instances to reach the event '%s': %w",
+			timeout, eventID, err)
+	}
+	return err
+}
+
+// GetNamespacedController returns a new Controller that prefixes all of the
+// data and event IDs with the given namespace, so multiple tests can use the
+// same underlying Controller without interfering with each other.
+func GetNamespacedController(namespace string, controller Controller) Controller {
+	return &namespacedController{namespace: namespace, c: controller}
+}
+
+type namespacedController struct {
+	namespace string
+	c         Controller
+}
+
+func (nc namespacedController) id(id string) string {
+	return fmt.Sprintf("%s/%s", nc.namespace, id)
+}
+
+func (nc namespacedController) GetOrCreateData(id string, callback func() ([]byte, error)) ([]byte, error) {
+	return nc.c.GetOrCreateData(nc.id(id), callback)
+}
+
+func (nc namespacedController) Signal(eventID string) error {
+	return nc.c.Signal(nc.id(eventID))
+}
+
+func (nc namespacedController) SignalError(eventID string, err error) error {
+	return nc.c.SignalError(nc.id(eventID), err)
+}
+
+func (nc namespacedController) Wait(ctx context.Context, eventID string) func() error {
+	return nc.c.Wait(ctx, nc.id(eventID))
+}
@@ -0,0 +1,113 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingController records the IDs it's called with. If block is
+// set, its waits block until their context is done.
+type recordingController struct {
+	mx    sync.Mutex
+	calls []string
+	block bool
+}
+
+func (rc *recordingController) record(call string) {
+	rc.mx.Lock()
+	defer rc.mx.Unlock()
+	rc.calls = append(rc.calls, call)
+}
+
+func (rc *recordingController) GetOrCreateData(id string, callback func() ([]byte, error)) ([]byte, error) {
+	rc.record("data:" + id)
+	return callback()
+}
+
+func (rc *recordingController) Signal(eventID string) error {
+	rc.record("signal:" + eventID)
+	return nil
+}
+
+func (rc *recordingController) SignalError(eventID string, err error) error {
+	rc.record("error:" + eventID + ":" + err.Error())
+	return nil
+}
+
+func (rc *recordingController) Wait(ctx context.Context, eventID string) func() error {
+	rc.record("wait:" + eventID)
+	return func() error {
+		if rc.block {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		rc.record("waited:" + eventID)
+		return nil
+	}
+}
+
+func TestSignalAndWait(t *testing.T) {
+	t.Parallel()
+
+	rc := &recordingController{}
+	require.NoError(t, SignalAndWait(context.Background(), rc, "test-start"))
+	assert.Equal(t, []string{"wait:test-start", "signal:test-start", "waited:test-start"}, rc.calls)
+}
+
+func TestSignalAndWaitCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rc := &recordingController{block: true}
+	require.ErrorIs(t, SignalAndWait(ctx, rc, "test-start"), context.Canceled)
+	assert.Equal(t, []string{"wait:test-start", "signal:test-start"}, rc.calls)
+}
+
+func TestSignalAndWaitWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reached", func(t *testing.T) {
+		t.Parallel()
+
+		rc := &recordingController{}
+		require.NoError(t, SignalAndWaitWithTimeout(context.Background(), rc, "test-start", time.Second))
+	})
+
+	t.Run("timed out", func(t *testing.T) {
+		t.Parallel()
+
+		rc := &recordingController{block: true}
+		err := SignalAndWaitWithTimeout(context.Background(), rc, "test-start", 50*time.Millisecond)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorContains(t, err, "timed out after 50ms waiting for all instances to reach the event 'test-start'")
+	})
+}
+
+func TestGetNamespacedController(t *testing.T) {
+	t.Parallel()
+
+	rc := &recordingController{}
+	nc := GetNamespacedController("suite-test-1", rc)
+
+	data, err := nc.GetOrCreateData("setup", func() ([]byte, error) { return []byte("data"), nil })
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+	require.NoError(t, SignalAndWait(context.Background(), nc, "test-done"))
+	require.NoError(t, nc.SignalError("test-abort", errors.New("aborted")))
+
+	assert.Equal(t, []string{
+		"data:suite-test-1/setup",
+		"wait:suite-test-1/test-done",
+		"signal:suite-test-1/test-done",
+		"waited:suite-test-1/test-done",
+		"error:suite-test-1/test-abort:aborted",
+	}, rc.calls)
+}
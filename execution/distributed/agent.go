@@ -0,0 +1,462 @@
+package distributed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/lib"
+)
+
+// AgentController implements the execution.Controller interface for an agent
+// instance of a distributed test. It synchronizes the instance with the others
+// through the coordinator, over the gRPC CommandAndControl stream.
+type AgentController struct {
+	instanceID uint32
+	logger     logrus.FieldLogger
+
+	sendMx sync.Mutex
+	stream DistributedTest_CommandAndControlClient
+
+	mx sync.Mutex
+	// events holds the state of each event that the instance is waiting
+	// for, or has been notified about.
+	events map[string]*event
+	// dataRequests holds a channel for each data chunk that the instance
+	// has requested to the coordinator, for receiving its response.
+	dataRequests map[string]chan *ControllerMessage
+	// membershipHandler is called when the coordinator
+	// notifies that some of the instances have been lost.
+	membershipHandler func(*Membership)
+	// segments holds the execution segments that the instance is using,
+	// rebalanceHandler is called when they are redistributed.
+	segments         *SegmentAssignment
+	rebalanceHandler func(*lib.ExecutionTuple) error
+	// rebalanceMx makes sure that the new segments are used in order.
+	rebalanceMx sync.Mutex
+	// commandHandler applies the commands sent by the coordinator.
+	commandHandler func(*Command) error
+
+	// stop is closed by Close, to stop sending the heartbeats.
+	stop     chan struct{}
+	stopOnce sync.Once
+	// done is closed when the stream with the coordinator is closed,
+	// err holds the reason.
+	done chan struct{}
+	err  error
+}
+
+// NewAgentController registers a new agent instance with the coordinator and
+// returns a Controller for it. The connection with the coordinator is kept
+// open until the provided context is done.
+func NewAgentController(
+	ctx context.Context, client DistributedTestClient, logger logrus.FieldLogger,
+) (*AgentController, error) {
+	resp, err := client.Register(ctx, &RegisterRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to register with the coordinator: %w", err)
+	}
+
+	stream, err := client.CommandAndControl(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the command and control stream: %w", err)
+	}
+
+	ac := &AgentController{
+		instanceID:   resp.GetInstanceId(),
+		logger:       logger.WithField("component", "distributed-agent"),
+		stream:       stream,
+		events:       make(map[string]*event),
+		dataRequests: make(map[string]chan *ControllerMessage),
+		segments:     resp.GetSegments(),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	// the first message identifies the instance on the stream
+	if err := ac.send(&AgentMessage{InstanceId: ac.instanceID}); err != nil {
+		return nil, fmt.Errorf("unable to connect to the coordinator: %w", err)
+	}
+
+	go ac.receive()
+	if generation := ac.segments.GetGeneration(); generation > 0 {
+		// the instance has joined a running test, the others
+		// wait for it before using the new segment assignment
+		if err := ac.Signal(rebalanceEventID(generation)); err != nil {
+			return nil, err
+		}
+	}
+	if interval := time.Duration(resp.GetHeartbeatIntervalMs()) * time.Millisecond; interval > 0 {
+		go ac.sendHeartbeats(ctx, interval)
+	}
+
+	return ac, nil
+}
+
+// ExecutionTuple returns the execution segment assigned to the instance by
+// the coordinator, with the segment sequence of all the live instances.
+func (ac *AgentController) ExecutionTuple() (*lib.ExecutionTuple, error) {
+	ac.mx.Lock()
+	defer ac.mx.Unlock()
+	return ac.segments.executionTuple(ac.instanceID)
+}
+
+// SetRebalanceHandler sets a callback that's called with the new execution
+// segment of the instance, when the coordinator redistributes the segments
+// between the live instances. All the instances call it at the same time,
+// after all of them have received the new segments.
+func (ac *AgentController) SetRebalanceHandler(handler func(*lib.ExecutionTuple) error) {
+	ac.mx.Lock()
+	defer ac.mx.Unlock()
+	ac.rebalanceHandler = handler
+}
+
+// SetCommandHandler sets a callback that applies the commands sent by the
+// coordinator, e.g. ApplyCommand for a local scheduler. All the instances call
+// it at the same time, after all of them have received the command.
+func (ac *AgentController) SetCommandHandler(handler func(*Command) error) {
+	ac.mx.Lock()
+	defer ac.mx.Unlock()
+	ac.commandHandler = handler
+}
+
+// SetMembershipHandler sets a callback that's called with the live and the
+// lost instances every time the coordinator notifies that an instance has
+// been lost. It's called on the goroutine that receives the messages from the
+// coordinator, so it shouldn't block.
+func (ac *AgentController) SetMembershipHandler(handler func(*Membership)) {
+	ac.mx.Lock()
+	defer ac.mx.Unlock()
+	ac.membershipHandler = handler
+}
+
+// InstanceID returns the ID assigned to the instance by the coordinator.
+func (ac *AgentController) InstanceID() uint32 {
+	return ac.instanceID
+}
+
+// GetOrCreateData implements the execution.Controller interface. The
+// coordinator asks only one of the instances to call the callback, all the
+// others receive its result.
+func (ac *AgentController) GetOrCreateData(id string, callback func() ([]byte, error)) ([]byte, error) {
+	resp := make(chan *ControllerMessage, 1)
+	ac.mx.Lock()
+	if _, ok := ac.dataRequests[id]; ok {
+		ac.mx.Unlock()
+		return nil, fmt.Errorf("the data '%s' has already been requested", id)
+	}
+	ac.dataRequests[id] = resp
+	ac.mx.Unlock()
+
+	defer func() {
+		ac.mx.Lock()
+		delete(ac.dataRequests, id)
+		ac.mx.Unlock()
+	}()
+
+	msg := &AgentMessage{
+		InstanceId: ac.instanceID,
+		Message:    &AgentMessage_GetOrCreateData{GetOrCreateData: id},
+	}
+	if err := ac.send(msg); err != nil {
+		return nil, err
+	}
+
+	var m *ControllerMessage
+	select {
+	case m = <-resp:
+	case <-ac.done:
+		return nil, ac.err
+	}
+
+	if data := m.GetData(); data != nil {
+		if data.GetError() != "" {
+			return nil, errors.New(data.GetError())
+		}
+		return data.GetData(), nil
+	}
+
+	ac.logger.Debugf("Creating the data '%s'", id)
+	data, err := callback()
+	packet := &DataPacket{Id: id, Data: data}
+	if err != nil {
+		packet.Error = err.Error()
+	}
+
+	msg = &AgentMessage{
+		InstanceId: ac.instanceID,
+		Message:    &AgentMessage_CreatedData{CreatedData: packet},
+	}
+	if sendErr := ac.send(msg); sendErr != nil {
+		return nil, sendErr
+	}
+
+	return data, err
+}
+
+// Signal implements the execution.Controller interface,
+// it notifies the coordinator that the instance has reached the event.
+func (ac *AgentController) Signal(eventID string) error {
+	return ac.send(&AgentMessage{
+		InstanceId: ac.instanceID,
+		Message:    &AgentMessage_Signal{Signal: eventID},
+	})
+}
+
+// SignalError implements the execution.Controller interface, it sends the
+// error to the coordinator, which propagates it to all the instances.
+func (ac *AgentController) SignalError(eventID string, err error) error {
+	return ac.send(&AgentMessage{
+		InstanceId: ac.instanceID,
+		Message: &AgentMessage_SignalError{
+			SignalError: &EventError{EventId: eventID, Error: err.Error()},
+		},
+	})
+}
+
+// Wait implements the execution.Controller interface, the returned callback
+// blocks until the coordinator notifies that all the instances have reached
+// the event, or that one of them has encountered an error at it, or until
+// the context is done.
+func (ac *AgentController) Wait(ctx context.Context, eventID string) func() error {
+	ev := ac.getEvent(eventID)
+	return func() error {
+		select {
+		case <-ev.done:
+			return ev.err
+		case <-ac.done:
+			return ac.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// event is closed when all the instances have reached it,
+// or when one of them has encountered an error at it.
+type event struct {
+	done chan struct{}
+	err  error
+}
+
+func (ac *AgentController) getEvent(eventID string) *event {
+	ac.mx.Lock()
+	defer ac.mx.Unlock()
+
+	ev, ok := ac.events[eventID]
+	if !ok {
+		ev = &event{done: make(chan struct{})}
+		ac.events[eventID] = ev
+	}
+	return ev
+}
+
+// finishEvent closes the event, with the provided
+// error, unless it has already been closed.
+func (ac *AgentController) finishEvent(eventID string, err error) {
+	ev := ac.getEvent(eventID)
+
+	ac.mx.Lock()
+	defer ac.mx.Unlock()
+
+	select {
+	case <-ev.done:
+		// already notified
+	default:
+		ev.err = err
+		close(ev.done)
+	}
+}
+
+// closeTimeout is how long Close waits for the coordinator
+// to close its side of the stream.
+const closeTimeout = 5 * time.Second
+
+// Close closes the connection with the coordinator. It waits for the
+// coordinator to close its side of the stream too, so all the previously
+// sent messages, e.g. the final status, are handled before the stream's
+// context can be canceled.
+func (ac *AgentController) Close() error {
+	ac.stopOnce.Do(func() { close(ac.stop) })
+
+	ac.sendMx.Lock()
+	err := ac.stream.CloseSend()
+	ac.sendMx.Unlock()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ac.done:
+	case <-time.After(closeTimeout):
+		ac.logger.Debug("The coordinator hasn't closed the stream in time")
+	}
+	return nil
+}
+
+func (ac *AgentController) send(msg *AgentMessage) error {
+	ac.sendMx.Lock()
+	defer ac.sendMx.Unlock()
+
+	if err := ac.stream.Send(msg); err != nil {
+		return fmt.Errorf("unable to send a message to the coordinator: %w", err)
+	}
+	return nil
+}
+
+// sendHeartbeats notifies the coordinator that the instance is still alive
+// at the provided interval, until the connection with it is closed.
+func (ac *AgentController) sendHeartbeats(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	heartbeat := &AgentMessage{
+		InstanceId: ac.instanceID,
+		Message:    &AgentMessage_Heartbeat{Heartbeat: &Heartbeat{}},
+	}
+	for {
+		select {
+		case <-ticker.C:
+			if err := ac.send(heartbeat); err != nil {
+				ac.logger.WithError(err).Debug("Unable to send a heartbeat")
+				return
+			}
+		case <-ac.stop:
+			return
+		case <-ac.done:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// receive handles the messages from the coordinator,
+// until the stream is closed.
+func (ac *AgentController) receive() {
+	for {
+		msg, err := ac.stream.Recv()
+		if err != nil {
+			ac.err = fmt.Errorf("the connection with the coordinator was closed: %w", err)
+			close(ac.done)
+			return
+		}
+
+		switch m := msg.GetMessage().(type) {
+		case *ControllerMessage_EventDone:
+			ac.logger.Debugf("All the instances have reached the event '%s'", m.EventDone)
+			ac.finishEvent(m.EventDone, nil)
+		case *ControllerMessage_EventError:
+			ac.logger.Debugf("An instance has encountered an error at the event '%s'", m.EventError.GetEventId())
+			ac.finishEvent(m.EventError.GetEventId(), errors.New(m.EventError.GetError()))
+		case *ControllerMessage_CreateData:
+			ac.respondToDataRequest(m.CreateData, msg)
+		case *ControllerMessage_Data:
+			ac.respondToDataRequest(m.Data.GetId(), msg)
+		case *ControllerMessage_Membership:
+			ac.handleMembership(m.Membership)
+		case *ControllerMessage_Command:
+			go ac.executeCommand(m.Command)
+		default:
+			ac.logger.Warnf("Received an unknown message type %T from the coordinator", m)
+		}
+	}
+}
+
+func (ac *AgentController) handleMembership(membership *Membership) {
+	ac.logger.Warnf("The instances of the distributed test have changed, %d are live and %v have been lost",
+		len(membership.GetLiveInstances()), membership.GetLostInstances())
+	if segments := membership.GetSegments(); segments != nil {
+		go ac.rebalance(segments)
+	}
+
+	ac.mx.Lock()
+	handler := ac.membershipHandler
+	ac.mx.Unlock()
+	if handler != nil {
+		handler(membership)
+	}
+}
+
+// rebalance starts using the new execution segments, after all the
+// live instances have received them.
+func (ac *AgentController) rebalance(segments *SegmentAssignment) {
+	eventID := rebalanceEventID(segments.GetGeneration())
+	if err := execution.SignalAndWait(context.Background(), ac, eventID); err != nil {
+		ac.logger.WithError(err).Error("Unable to redistribute the execution segments")
+		return
+	}
+
+	ac.rebalanceMx.Lock()
+	defer ac.rebalanceMx.Unlock()
+
+	ac.mx.Lock()
+	if segments.GetGeneration() <= ac.segments.GetGeneration() {
+		// a newer assignment is already being used
+		ac.mx.Unlock()
+		return
+	}
+	ac.segments = segments
+	handler := ac.rebalanceHandler
+	ac.mx.Unlock()
+
+	et, err := segments.executionTuple(ac.instanceID)
+	if err != nil {
+		ac.logger.WithError(err).Error("Unable to redistribute the execution segments")
+		return
+	}
+	ac.logger.Infof("Using the redistributed execution segment %s", et)
+	if handler == nil {
+		return
+	}
+	if err := handler(et); err != nil {
+		ac.logger.WithError(err).Error("Unable to apply the redistributed execution segment")
+	}
+}
+
+// executeCommand applies the command, after all the live instances have
+// received it, and sends the result to the coordinator.
+func (ac *AgentController) executeCommand(cmd *Command) {
+	err := execution.SignalAndWait(context.Background(), ac, commandEventID(cmd.GetId()))
+	if err == nil {
+		ac.mx.Lock()
+		handler := ac.commandHandler
+		ac.mx.Unlock()
+
+		if handler == nil {
+			err = errors.New("the instance doesn't support commands")
+		} else {
+			ac.logger.Debugf("Applying the command %d", cmd.GetId())
+			err = handler(cmd)
+		}
+	}
+
+	result := &CommandResult{Id: cmd.GetId()}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	sendErr := ac.send(&AgentMessage{
+		InstanceId: ac.instanceID,
+		Message:    &AgentMessage_CommandResult{CommandResult: result},
+	})
+	if sendErr != nil {
+		ac.logger.WithError(sendErr).Errorf("Unable to send the result of the command %d", cmd.GetId())
+	}
+}
+
+func (ac *AgentController) respondToDataRequest(id string, msg *ControllerMessage) {
+	ac.mx.Lock()
+	resp, ok := ac.dataRequests[id]
+	ac.mx.Unlock()
+
+	if !ok {
+		ac.logger.Warnf("Received the data '%s' from the coordinator without requesting it", id)
+		return
+	}
+	resp <- msg
+}
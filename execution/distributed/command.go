@@ -0,0 +1,97 @@
+package distributed
+
+import (
+	"context"
+	"fmt"
+
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/lib/executor"
+)
+
+// commandEventID returns the ID of the event that the instances reach after
+// they have received the command, so all of them apply it at the same time.
+func commandEventID(id uint64) string {
+	return fmt.Sprintf("command-%d", id)
+}
+
+// ApplyCommand applies the command to the test executed by the scheduler, it
+// can be used for handling the commands received by an AgentController.
+func ApplyCommand(ctx context.Context, scheduler *execution.Scheduler, cmd *Command) error {
+	switch c := cmd.GetCommand().(type) {
+	case *Command_Pause:
+		return scheduler.SetPaused(c.Pause)
+	case *Command_Scale:
+		return scale(ctx, scheduler, c.Scale)
+	default:
+		return fmt.Errorf("unknown command type %T", c)
+	}
+}
+
+// scale changes the number of VUs of the externally controlled scenarios
+// and the rate of the constant arrival rate ones, the other executors
+// can't be scaled in the middle of the test.
+func scale(ctx context.Context, scheduler *execution.Scheduler, params *Scale) error {
+	scaled := 0
+	for _, exec := range scheduler.GetExecutors() {
+		config := exec.GetConfig()
+		if params.GetScenario() != "" && config.GetName() != params.GetScenario() {
+			continue
+		}
+
+		var err error
+		switch e := exec.(type) {
+		case *executor.ExternallyControlled:
+			err = scaleExternallyControlled(ctx, e, params)
+		case *executor.ConstantArrivalRate:
+			err = scaleConstantArrivalRate(ctx, e, params)
+		default:
+			if params.GetScenario() == "" {
+				continue
+			}
+			err = fmt.Errorf("the %s executor can't be scaled", config.GetType())
+		}
+		if err != nil {
+			return fmt.Errorf("unable to scale the scenario '%s': %w", config.GetName(), err)
+		}
+		scaled++
+	}
+
+	if scaled == 0 {
+		if params.GetScenario() != "" {
+			return fmt.Errorf("the scenario '%s' doesn't exist", params.GetScenario())
+		}
+		return fmt.Errorf("none of the scenarios can be scaled")
+	}
+	return nil
+}
+
+func scaleExternallyControlled(ctx context.Context, e *executor.ExternallyControlled, params *Scale) error {
+	if params.GetRate() > 0 {
+		return fmt.Errorf("the externally controlled executor doesn't have an arrival rate")
+	}
+
+	config := e.GetCurrentConfig().ExternallyControlledConfigParams
+	if params.GetVus() > 0 {
+		config.VUs = null.IntFrom(params.GetVus())
+	}
+	if params.GetMaxVus() > 0 {
+		config.MaxVUs = null.IntFrom(params.GetMaxVus())
+	}
+	return e.UpdateConfig(ctx, config)
+}
+
+func scaleConstantArrivalRate(ctx context.Context, e *executor.ConstantArrivalRate, params *Scale) error {
+	if params.GetVus() > 0 || params.GetMaxVus() > 0 || params.GetRate() <= 0 {
+		return fmt.Errorf("only the arrival rate of the constant arrival rate executor can be changed")
+	}
+
+	config, ok := e.GetConfig().(*executor.ConstantArrivalRateConfig)
+	if !ok {
+		return fmt.Errorf("unexpected config type %T", e.GetConfig())
+	}
+	newConfig := *config
+	newConfig.Rate = null.IntFrom(params.GetRate())
+	return e.UpdateConfig(ctx, newConfig)
+}
@@ -0,0 +1,188 @@
+package distributed
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/execution/local"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/executor"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/lib/testutils/minirunner"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+func TestDistributedExecuteCommand(t *testing.T) {
+	t.Parallel()
+
+	coordinator, client := startTestCoordinator(t, 2, MembershipConfig{})
+	agents := []*AgentController{
+		newTestAgent(context.Background(), t, client),
+		newTestAgent(context.Background(), t, client),
+	}
+
+	waitForConnectedAgents(t, coordinator, 2)
+
+	var (
+		mx       sync.Mutex
+		received []uint32
+	)
+	for _, agent := range agents {
+		agent := agent
+		agent.SetCommandHandler(func(cmd *Command) error {
+			mx.Lock()
+			defer mx.Unlock()
+			received = append(received, agent.InstanceID())
+			if cmd.GetScale().GetVus() > 10 && agent.InstanceID() == 2 {
+				return errors.New("too many VUs")
+			}
+			return nil
+		})
+	}
+
+	resp, err := coordinator.ExecuteCommand(context.Background(), &Command{Command: &Command_Pause{Pause: true}})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), resp.GetInstances())
+	assert.ElementsMatch(t, []uint32{1, 2}, received)
+
+	_, err = coordinator.ExecuteCommand(context.Background(), &Command{
+		Command: &Command_Scale{Scale: &Scale{Vus: 20}},
+	})
+	require.ErrorContains(t, err, "the command has failed on 1 instances: instance 2: too many VUs")
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	_, err = coordinator.ExecuteCommand(context.Background(), &Command{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestDistributedExecuteCommandErrors(t *testing.T) {
+	t.Parallel()
+
+	coordinator, client := startTestCoordinator(t, 2, MembershipConfig{})
+	pause := &Command{Command: &Command_Pause{Pause: true}}
+
+	newTestAgent(context.Background(), t, client)
+	waitForConnectedAgents(t, coordinator, 1)
+	_, err := coordinator.ExecuteCommand(context.Background(), pause)
+	require.ErrorContains(t, err, "only 1 of the 2 instances of the test are connected")
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// the second instance doesn't have a command handler
+	newTestAgent(context.Background(), t, client)
+	waitForConnectedAgents(t, coordinator, 2)
+	_, err = coordinator.ExecuteCommand(context.Background(), pause)
+	require.ErrorContains(t, err, "the instance doesn't support commands")
+}
+
+// waitForConnectedAgents waits until the streams of the agents are
+// registered by the coordinator, since that happens after their Register call.
+func waitForConnectedAgents(t *testing.T, coordinator *CoordinatorServer, count int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		coordinator.mx.Lock()
+		defer coordinator.mx.Unlock()
+		return len(coordinator.agents) == count
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestApplyCommand(t *testing.T) {
+	t.Parallel()
+
+	runner := &minirunner.MiniRunner{
+		Fn: func(ctx context.Context, _ *lib.State, _ chan<- metrics.SampleContainer) error {
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		},
+	}
+	options := lib.Options{Scenarios: lib.ScenarioConfigs{
+		"manual": executor.ExternallyControlledConfig{
+			BaseConfig: executor.NewBaseConfig("manual", "externally-controlled"),
+			ExternallyControlledConfigParams: executor.ExternallyControlledConfigParams{
+				VUs:      null.IntFrom(1),
+				MaxVUs:   null.IntFrom(4),
+				Duration: types.NullDurationFrom(time.Minute),
+			},
+		},
+		"rate": &executor.ConstantArrivalRateConfig{
+			BaseConfig:      executor.NewBaseConfig("rate", "constant-arrival-rate"),
+			Rate:            null.IntFrom(10),
+			TimeUnit:        types.NullDurationFrom(time.Second),
+			Duration:        types.NullDurationFrom(time.Minute),
+			PreAllocatedVUs: null.IntFrom(1),
+			MaxVUs:          null.IntFrom(2),
+		},
+		"fixed": executor.ConstantVUsConfig{
+			BaseConfig: executor.NewBaseConfig("fixed", "constant-vus"),
+			VUs:        null.IntFrom(1),
+			Duration:   types.NullDurationFrom(time.Minute),
+		},
+	}}
+	require.NoError(t, runner.SetOptions(options))
+
+	logger := testutils.NewLogger(t)
+	registry := metrics.NewRegistry()
+	scheduler, err := execution.NewScheduler(&lib.TestRunState{
+		TestPreInitState: &lib.TestPreInitState{
+			Logger:         logger,
+			Registry:       registry,
+			BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		},
+		Options: options,
+		Runner:  runner,
+		RunTags: registry.RootTagSet(),
+	}, local.NewController())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	samples := make(chan metrics.SampleContainer, 1000)
+	go func() {
+		for range samples { //nolint:revive
+		}
+	}()
+	stopEmission, err := scheduler.Init(ctx, samples)
+	require.NoError(t, err)
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- scheduler.Run(ctx, ctx, samples) }()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-runErr)
+		stopEmission()
+		close(samples)
+	}()
+
+	scaleCommand := func(scale *Scale) *Command {
+		return &Command{Command: &Command_Scale{Scale: scale}}
+	}
+
+	require.NoError(t, ApplyCommand(ctx, scheduler, scaleCommand(&Scale{Scenario: "manual", Vus: 2})))
+	require.NoError(t, ApplyCommand(ctx, scheduler, scaleCommand(&Scale{Scenario: "rate", Rate: 20})))
+
+	err = ApplyCommand(ctx, scheduler, scaleCommand(&Scale{Scenario: "fixed", Vus: 2}))
+	require.ErrorContains(t, err, "the constant-vus executor can't be scaled")
+	err = ApplyCommand(ctx, scheduler, scaleCommand(&Scale{Scenario: "missing", Vus: 2}))
+	require.ErrorContains(t, err, "the scenario 'missing' doesn't exist")
+	err = ApplyCommand(ctx, scheduler, scaleCommand(&Scale{Scenario: "manual", Rate: 2}))
+	require.ErrorContains(t, err, "doesn't have an arrival rate")
+
+	for _, exec := range scheduler.GetExecutors() {
+		if manual, ok := exec.(*executor.ExternallyControlled); ok {
+			assert.Equal(t, null.IntFrom(2), manual.GetCurrentConfig().VUs)
+		}
+	}
+
+	// the constant-vus executor can't be paused after the test has started
+	err = ApplyCommand(ctx, scheduler, &Command{Command: &Command_Pause{Pause: true}})
+	require.ErrorContains(t, err, "doesn't support pause and resume")
+}
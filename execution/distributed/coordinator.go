@@ -0,0 +1,847 @@
+package distributed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/output"
+)
+
+// CoordinatorServer coordinates the agent instances of a distributed test. It
+// implements the DistributedTest gRPC service: it counts the instances that
+// have signaled each event, notifying all of them when the last one does, and
+// it asks a single instance to create each requested data chunk, sharing the
+// result with all the others.
+//
+// It also keeps track of the live instances. An instance is lost if its stream
+// breaks, or if it doesn't send anything for longer than the failure timeout,
+// and then it's handled according to the configured InstanceLossPolicy. Each
+// instance is assigned an equal execution segment of the test, which are
+// redistributed between the live instances under the redistribute policy,
+// both when an instance is lost and when a new one joins the running test.
+//
+// The metric samples streamed by the instances are sent to the outputs set
+// with SetMetricsOutputs, so they are processed once for the whole test. The
+// test has finished once all the live instances have signaled FinishedEventID,
+// after sending their last samples, which closes the Finished channel.
+//
+// It implements the CoordinatorControl service too, for pausing, resuming and
+// scaling the test on all the instances at the same time.
+//
+// The instances stream their execution status periodically, the latest one
+// of each instance is returned by InstanceStatuses.
+type CoordinatorServer struct {
+	UnimplementedDistributedTestServer
+	UnimplementedCoordinatorControlServer
+
+	instanceCount uint32
+	membership    MembershipConfig
+	logger        logrus.FieldLogger
+
+	mx         sync.Mutex
+	registered uint32
+	agents     map[uint32]*agentConn
+	lastSeen   map[uint32]time.Time
+	lost       map[uint32]bool
+	// signals holds the instances that have signaled each event, and
+	// doneEvents the events that all the live instances have reached.
+	signals    map[string]map[uint32]bool
+	doneEvents map[string]bool
+	// eventErrors holds the errors that the instances have encountered at
+	// the events, they are sent to the instances that connect later too.
+	eventErrors map[string]*EventError
+	data        map[string]*DataPacket
+	// dataReady holds the channels of WaitForData, closed when the data is set.
+	dataReady map[string]chan struct{}
+	// dataWaiters holds the instances waiting for the data chunks that
+	// are being created, the presence of the ID means it's in progress.
+	dataWaiters map[string][]uint32
+	// dataCreators holds the instance that's creating each data chunk.
+	dataCreators map[string]uint32
+	// segments holds the current execution segments of the instances.
+	segments *SegmentAssignment
+	// commands holds the commands that the instances are applying.
+	commands     map[uint64]*pendingCommand
+	commandCount uint64
+	// statuses holds the latest execution status of each instance.
+	statuses map[uint32]*InstanceStatus
+
+	metricsRegistry *metrics.Registry
+	metricsOutputs  []output.Output
+	// testStart is when all the instances have started the test.
+	testStart time.Time
+	finished  chan struct{}
+}
+
+// NewCoordinatorServer returns a new coordinator for a distributed
+// test executed by the provided number of agent instances.
+func NewCoordinatorServer(
+	instanceCount uint32, membership MembershipConfig, logger logrus.FieldLogger,
+) (*CoordinatorServer, error) {
+	if instanceCount == 0 {
+		return nil, errors.New("the number of instances must be greater than zero")
+	}
+	if !membership.Policy.IsAInstanceLossPolicy() {
+		return nil, fmt.Errorf("invalid instance loss policy %s", membership.Policy)
+	}
+
+	instances := make([]uint32, instanceCount)
+	for i := range instances {
+		instances[i] = uint32(i + 1)
+	}
+	segments, err := newSegmentAssignment(0, instances)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CoordinatorServer{
+		instanceCount: instanceCount,
+		membership:    membership,
+		logger:        logger.WithField("component", "distributed-coordinator"),
+		agents:        make(map[uint32]*agentConn),
+		lastSeen:      make(map[uint32]time.Time),
+		lost:          make(map[uint32]bool),
+		signals:       make(map[string]map[uint32]bool),
+		doneEvents:    make(map[string]bool),
+		eventErrors:   make(map[string]*EventError),
+		data:          make(map[string]*DataPacket),
+		dataReady:     make(map[string]chan struct{}),
+		dataWaiters:   make(map[string][]uint32),
+		dataCreators:  make(map[string]uint32),
+		segments:      segments,
+		commands:      make(map[uint64]*pendingCommand),
+		statuses:      make(map[uint32]*InstanceStatus),
+		finished:      make(chan struct{}),
+	}, nil
+}
+
+// Register implements the DistributedTestServer interface, it assigns an ID
+// to the agent instance, as long as the test needs more instances. Under the
+// redistribute policy, more instances can join the test after that, and the
+// execution segments are redistributed to include them.
+func (cs *CoordinatorServer) Register(_ context.Context, _ *RegisterRequest) (*RegisterResponse, error) {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+
+	joining := cs.registered >= cs.instanceCount
+	if joining && cs.membership.Policy != InstanceLossRedistribute {
+		return nil, status.Errorf(codes.ResourceExhausted,
+			"all the %d instances of the test have already been registered", cs.instanceCount)
+	}
+	cs.registered++
+	cs.lastSeen[cs.registered] = time.Now()
+
+	if joining {
+		cs.logger.Infof("Instance %d has joined the test, redistributing the execution segments", cs.registered)
+		cs.broadcastMembership()
+	} else {
+		cs.logger.Debugf("Registered instance %d of %d", cs.registered, cs.instanceCount)
+	}
+
+	return &RegisterResponse{
+		InstanceId:          cs.registered,
+		HeartbeatIntervalMs: uint32(cs.membership.HeartbeatInterval.Milliseconds()),
+		Segments:            cs.segments,
+	}, nil
+}
+
+// SetMetricsOutputs sets the started outputs that receive the metric samples
+// streamed by the agent instances, e.g. the ingester of the metrics engine.
+// The metrics of the samples are registered in the provided registry. The
+// samples are discarded if it isn't called before the instances start
+// streaming them, or after it's called without outputs.
+func (cs *CoordinatorServer) SetMetricsOutputs(registry *metrics.Registry, outputs ...output.Output) {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+
+	cs.metricsRegistry = registry
+	cs.metricsOutputs = outputs
+}
+
+// SetData sets the data with the provided ID, e.g. the archive of the test
+// with ArchiveDataID, so the instances receive it when they request it,
+// instead of one of them creating it.
+func (cs *CoordinatorServer) SetData(id string, data []byte) {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+
+	cs.setData(&DataPacket{Id: id, Data: data})
+}
+
+func (cs *CoordinatorServer) setData(data *DataPacket) {
+	cs.data[data.GetId()] = data
+	if ready, ok := cs.dataReady[data.GetId()]; ok {
+		close(ready)
+		delete(cs.dataReady, data.GetId())
+	}
+}
+
+// WaitForData returns the data with the provided ID, once it has been set or
+// created by one of the instances. It returns the error of the instance that
+// couldn't create it, or the one of the context if it's done first.
+func (cs *CoordinatorServer) WaitForData(ctx context.Context, id string) ([]byte, error) {
+	cs.mx.Lock()
+	if data, ok := cs.data[id]; ok {
+		cs.mx.Unlock()
+		return dataPacketResult(data)
+	}
+	ready, ok := cs.dataReady[id]
+	if !ok {
+		ready = make(chan struct{})
+		cs.dataReady[id] = ready
+	}
+	cs.mx.Unlock()
+
+	select {
+	case <-ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+	return dataPacketResult(cs.data[id])
+}
+
+func dataPacketResult(data *DataPacket) ([]byte, error) {
+	if data.GetError() != "" {
+		return nil, errors.New(data.GetError())
+	}
+	return data.GetData(), nil
+}
+
+// Finished returns a channel that's closed when all the live instances have
+// finished the test and have sent all their metric samples.
+func (cs *CoordinatorServer) Finished() <-chan struct{} {
+	return cs.finished
+}
+
+// TestRunDuration returns how long the test has been running on all the
+// instances, it's zero until all of them have started it.
+func (cs *CoordinatorServer) TestRunDuration() time.Duration {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+
+	if cs.testStart.IsZero() {
+		return 0
+	}
+	return time.Since(cs.testStart)
+}
+
+// Abort aborts the test on all the instances with the provided error, e.g.
+// when a threshold with abortOnFail has been crossed.
+func (cs *CoordinatorServer) Abort(err error) {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+
+	if _, ok := cs.eventErrors[execution.AbortEventID]; ok {
+		return
+	}
+	cs.logger.WithError(err).Warn("Aborting the test on all the instances")
+	eventErr := &EventError{EventId: execution.AbortEventID, Error: err.Error()}
+	cs.eventErrors[execution.AbortEventID] = eventErr
+	cs.broadcast(&ControllerMessage{Message: &ControllerMessage_EventError{EventError: eventErr}})
+}
+
+// InstanceStatuses returns the latest execution status
+// sent by each instance, by instance ID.
+func (cs *CoordinatorServer) InstanceStatuses() map[uint32]*InstanceStatus {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+
+	statuses := make(map[uint32]*InstanceStatus, len(cs.statuses))
+	for id, st := range cs.statuses {
+		statuses[id] = st
+	}
+	return statuses
+}
+
+// FailureReasons returns the distinct reasons the script marked the test run
+// as failed for, on any instance, according to their latest execution status.
+func (cs *CoordinatorServer) FailureReasons() []string {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+
+	ids := make([]uint32, 0, len(cs.statuses))
+	for id := range cs.statuses {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var reasons []string
+	seen := make(map[string]struct{})
+	for _, id := range ids {
+		for _, reason := range cs.statuses[id].GetFailureReasons() {
+			if _, ok := seen[reason]; !ok {
+				seen[reason] = struct{}{}
+				reasons = append(reasons, reason)
+			}
+		}
+	}
+	return reasons
+}
+
+// LiveInstances returns the IDs of the registered
+// instances that haven't been lost, in order.
+func (cs *CoordinatorServer) LiveInstances() []uint32 {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+	return cs.liveInstances()
+}
+
+func (cs *CoordinatorServer) liveInstances() []uint32 {
+	live := make([]uint32, 0, cs.registered)
+	for id := uint32(1); id <= cs.registered; id++ {
+		if !cs.lost[id] {
+			live = append(live, id)
+		}
+	}
+	return live
+}
+
+// MonitorInstances checks periodically for the instances that haven't sent
+// anything for longer than the failure timeout, until the context is done.
+// It returns immediately if the failure timeout isn't configured.
+func (cs *CoordinatorServer) MonitorInstances(ctx context.Context) {
+	timeout := cs.membership.failureTimeout()
+	if timeout <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(timeout / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			cs.checkInstances(now, timeout)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (cs *CoordinatorServer) checkInstances(now time.Time, timeout time.Duration) {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+
+	for id, lastSeen := range cs.lastSeen {
+		if silence := now.Sub(lastSeen); silence > timeout {
+			cs.handleInstanceLoss(id, fmt.Sprintf("nothing received for %s", silence.Round(time.Millisecond)))
+		}
+	}
+}
+
+// CommandAndControl implements the DistributedTestServer interface, it handles
+// the messages of a single agent instance, until it closes the stream.
+func (cs *CoordinatorServer) CommandAndControl(stream DistributedTest_CommandAndControlServer) (err error) {
+	msg, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	instanceID := msg.GetInstanceId()
+	agent := newAgentConn()
+	if err := cs.addAgent(instanceID, agent); err != nil {
+		return err
+	}
+
+	// the stream mustn't be used after the handler returns, so
+	// it waits for the forwarding to stop before returning.
+	var wg sync.WaitGroup
+	sendErr := make(chan error, 1)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		sendErr <- agent.forward(stream)
+	}()
+	defer func() {
+		cs.removeAgent(instanceID, agent, err)
+		wg.Wait()
+	}()
+
+	for {
+		if err := cs.handleMessage(instanceID, msg); err != nil {
+			return err
+		}
+
+		select {
+		case err := <-sendErr:
+			return err
+		default:
+		}
+
+		msg, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (cs *CoordinatorServer) addAgent(instanceID uint32, agent *agentConn) error {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+
+	if instanceID == 0 || instanceID > cs.registered {
+		return status.Errorf(codes.InvalidArgument, "instance %d isn't registered", instanceID)
+	}
+	if cs.lost[instanceID] {
+		return status.Errorf(codes.FailedPrecondition, "instance %d has already been lost", instanceID)
+	}
+	if _, ok := cs.agents[instanceID]; ok {
+		return status.Errorf(codes.AlreadyExists, "instance %d is already connected", instanceID)
+	}
+	cs.agents[instanceID] = agent
+	cs.lastSeen[instanceID] = time.Now()
+
+	for _, eventErr := range cs.eventErrors {
+		agent.send(&ControllerMessage{Message: &ControllerMessage_EventError{EventError: eventErr}})
+	}
+	// the instances joining a running test don't wait for the past events
+	for eventID := range cs.doneEvents {
+		agent.send(&ControllerMessage{Message: &ControllerMessage_EventDone{EventDone: eventID}})
+	}
+
+	return nil
+}
+
+// removeAgent removes the agent instance after its stream has ended. If the
+// stream was broken, instead of being closed by the agent, it's lost.
+func (cs *CoordinatorServer) removeAgent(instanceID uint32, agent *agentConn, streamErr error) {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+
+	agent.close()
+	if cs.agents[instanceID] != agent {
+		return // it has already been lost
+	}
+	delete(cs.agents, instanceID)
+	delete(cs.lastSeen, instanceID)
+
+	if streamErr != nil {
+		cs.handleInstanceLoss(instanceID, streamErr.Error())
+		return
+	}
+	cs.logger.Debugf("Instance %d has disconnected", instanceID)
+	cs.stopWaitingForCommands(instanceID)
+}
+
+// handleInstanceLoss marks the instance as lost and applies the configured
+// policy. The events and the data chunks stop waiting for the lost instance
+// regardless of the policy, so the live instances can finish the test.
+func (cs *CoordinatorServer) handleInstanceLoss(instanceID uint32, reason string) {
+	if cs.lost[instanceID] {
+		return
+	}
+	cs.lost[instanceID] = true
+	if agent, ok := cs.agents[instanceID]; ok {
+		agent.close()
+		delete(cs.agents, instanceID)
+	}
+	delete(cs.lastSeen, instanceID)
+
+	cs.logger.WithField("policy", cs.membership.Policy).Warnf(
+		"Instance %d has been lost (%s), %d instances are still live",
+		instanceID, reason, cs.expectedInstances())
+	cs.broadcastMembership()
+
+	if cs.membership.Policy == InstanceLossAbort {
+		cs.handleSignalError(instanceID, &EventError{
+			EventId: execution.AbortEventID,
+			Error:   fmt.Sprintf("instance %d of the distributed test has been lost: %s", instanceID, reason),
+		})
+	}
+
+	for eventID := range cs.signals {
+		cs.checkEventDone(eventID)
+	}
+	if cs.expectedInstances() == 0 {
+		// the test has finished even if no instance has signaled it
+		cs.checkEventDone(FinishedEventID)
+	}
+	cs.reassignDataCreation(instanceID)
+	cs.stopWaitingForCommands(instanceID)
+}
+
+// broadcastMembership notifies the live instances that the membership has
+// changed. Under the redistribute policy, it assigns new execution segments.
+func (cs *CoordinatorServer) broadcastMembership() {
+	live := cs.liveInstances()
+	lostInstances := make([]uint32, 0, len(cs.lost))
+	for id := range cs.lost {
+		lostInstances = append(lostInstances, id)
+	}
+	sort.Slice(lostInstances, func(i, j int) bool { return lostInstances[i] < lostInstances[j] })
+	membership := &Membership{LiveInstances: live, LostInstances: lostInstances}
+
+	if cs.membership.Policy == InstanceLossRedistribute && len(live) > 0 {
+		segments, err := newSegmentAssignment(cs.segments.GetGeneration()+1, live)
+		if err != nil {
+			cs.logger.WithError(err).Error("Unable to redistribute the execution segments")
+		} else {
+			cs.segments = segments
+			membership.Rebalance = true
+			membership.Segments = segments
+		}
+	}
+
+	cs.broadcast(&ControllerMessage{Message: &ControllerMessage_Membership{Membership: membership}})
+}
+
+// expectedInstances returns the number of instances that have to reach
+// the events, i.e. all the instances of the test that haven't been lost.
+func (cs *CoordinatorServer) expectedInstances() uint32 {
+	count := cs.instanceCount
+	if cs.registered > count {
+		count = cs.registered
+	}
+	return count - uint32(len(cs.lost))
+}
+
+// reassignDataCreation asks one of the instances waiting for the data chunks
+// that the lost instance was creating to create them instead.
+func (cs *CoordinatorServer) reassignDataCreation(lostID uint32) {
+	for id, creator := range cs.dataCreators {
+		if creator != lostID {
+			continue
+		}
+		waiters := cs.dataWaiters[id]
+		delete(cs.dataCreators, id)
+		if len(waiters) == 0 {
+			// the next instance that requests it will create it
+			delete(cs.dataWaiters, id)
+			continue
+		}
+
+		newCreator := waiters[0]
+		cs.dataWaiters[id] = waiters[1:]
+		cs.dataCreators[id] = newCreator
+		cs.logger.Debugf("Instance %d has to create the data '%s' instead of the lost instance %d",
+			newCreator, id, lostID)
+		if agent, ok := cs.agents[newCreator]; ok {
+			agent.send(&ControllerMessage{Message: &ControllerMessage_CreateData{CreateData: id}})
+		}
+	}
+}
+
+func (cs *CoordinatorServer) broadcast(msg *ControllerMessage) {
+	for _, agent := range cs.agents {
+		agent.send(msg)
+	}
+}
+
+func (cs *CoordinatorServer) handleMessage(instanceID uint32, msg *AgentMessage) error {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+
+	if cs.lost[instanceID] {
+		return status.Errorf(codes.FailedPrecondition, "instance %d has already been lost", instanceID)
+	}
+	cs.lastSeen[instanceID] = time.Now()
+
+	switch m := msg.GetMessage().(type) {
+	case *AgentMessage_Signal:
+		cs.handleSignal(instanceID, m.Signal)
+	case *AgentMessage_GetOrCreateData:
+		cs.handleGetOrCreateData(instanceID, m.GetOrCreateData)
+	case *AgentMessage_CreatedData:
+		return cs.handleCreatedData(instanceID, m.CreatedData)
+	case *AgentMessage_SignalError:
+		cs.handleSignalError(instanceID, m.SignalError)
+	case *AgentMessage_MetricSamples:
+		cs.handleMetricSamples(instanceID, m.MetricSamples)
+	case *AgentMessage_CommandResult:
+		cs.handleCommandResult(instanceID, m.CommandResult)
+	case *AgentMessage_Status:
+		cs.handleStatus(instanceID, m.Status)
+	case *AgentMessage_Heartbeat, nil:
+		// the heartbeats and the first message of a stream
+		// only show that the instance is still alive
+	default:
+		return status.Errorf(codes.InvalidArgument, "unknown message type %T", m)
+	}
+
+	return nil
+}
+
+func (cs *CoordinatorServer) handleSignal(instanceID uint32, eventID string) {
+	signaled, ok := cs.signals[eventID]
+	if !ok {
+		signaled = make(map[uint32]bool)
+		cs.signals[eventID] = signaled
+	}
+	signaled[instanceID] = true
+	cs.logger.Debugf("Instance %d has reached event '%s' (%d of %d)",
+		instanceID, eventID, len(signaled), cs.expectedInstances())
+
+	cs.checkEventDone(eventID)
+}
+
+// checkEventDone notifies all the instances about the event,
+// if all the live instances have reached it.
+func (cs *CoordinatorServer) checkEventDone(eventID string) {
+	if cs.doneEvents[eventID] {
+		return
+	}
+
+	live := 0
+	for id := range cs.signals[eventID] {
+		if !cs.lost[id] {
+			live++
+		}
+	}
+	if uint32(live) < cs.expectedInstances() {
+		return
+	}
+
+	cs.doneEvents[eventID] = true
+	cs.broadcast(&ControllerMessage{Message: &ControllerMessage_EventDone{EventDone: eventID}})
+
+	switch eventID {
+	case testStartEventID:
+		cs.testStart = time.Now()
+	case FinishedEventID:
+		close(cs.finished)
+	}
+}
+
+func (cs *CoordinatorServer) handleSignalError(instanceID uint32, eventErr *EventError) {
+	eventID := eventErr.GetEventId()
+	cs.logger.Debugf("Instance %d has encountered an error at event '%s': %s", instanceID, eventID, eventErr.GetError())
+	if _, ok := cs.eventErrors[eventID]; ok {
+		// only the first error is propagated
+		return
+	}
+	cs.eventErrors[eventID] = eventErr
+
+	cs.broadcast(&ControllerMessage{Message: &ControllerMessage_EventError{EventError: eventErr}})
+}
+
+func (cs *CoordinatorServer) handleMetricSamples(instanceID uint32, batch *MetricSamples) {
+	if len(cs.metricsOutputs) == 0 {
+		return
+	}
+
+	samples := make(metrics.Samples, 0, len(batch.GetSamples()))
+	for _, encoded := range batch.GetSamples() {
+		sample, err := decodeSample(cs.metricsRegistry, encoded)
+		if err != nil {
+			// a single invalid metric shouldn't make the instance lost
+			cs.logger.WithError(err).Warnf("Discarded a metric sample from instance %d", instanceID)
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	if len(samples) == 0 {
+		return
+	}
+
+	containers := []metrics.SampleContainer{samples}
+	for _, out := range cs.metricsOutputs {
+		out.AddMetricSamples(containers)
+	}
+}
+
+func (cs *CoordinatorServer) handleStatus(instanceID uint32, st *InstanceStatus) {
+	if prev, ok := cs.statuses[instanceID]; !ok || prev.GetStatus() != st.GetStatus() {
+		cs.logger.Infof("Instance %d is %s, with %d VUs and %d complete iterations",
+			instanceID, st.GetStatus(), st.GetVus(), st.GetFullIterations())
+	}
+	if st.GetError() != "" {
+		cs.logger.Warnf("Instance %d has finished the test with an error: %s", instanceID, st.GetError())
+	}
+	cs.statuses[instanceID] = st
+}
+
+func (cs *CoordinatorServer) handleGetOrCreateData(instanceID uint32, id string) {
+	if data, ok := cs.data[id]; ok {
+		cs.agents[instanceID].send(&ControllerMessage{Message: &ControllerMessage_Data{Data: data}})
+		return
+	}
+
+	if waiters, ok := cs.dataWaiters[id]; ok {
+		cs.dataWaiters[id] = append(waiters, instanceID)
+		return
+	}
+
+	cs.logger.Debugf("Instance %d has to create the data '%s'", instanceID, id)
+	cs.dataWaiters[id] = nil
+	cs.dataCreators[id] = instanceID
+	cs.agents[instanceID].send(&ControllerMessage{Message: &ControllerMessage_CreateData{CreateData: id}})
+}
+
+func (cs *CoordinatorServer) handleCreatedData(instanceID uint32, data *DataPacket) error {
+	waiters, ok := cs.dataWaiters[data.GetId()]
+	if !ok || cs.dataCreators[data.GetId()] != instanceID {
+		return status.Errorf(codes.FailedPrecondition,
+			"instance %d has sent the data '%s' without being asked to create it", instanceID, data.GetId())
+	}
+
+	cs.setData(data)
+	delete(cs.dataWaiters, data.GetId())
+	delete(cs.dataCreators, data.GetId())
+
+	msg := &ControllerMessage{Message: &ControllerMessage_Data{Data: data}}
+	for _, waiter := range waiters {
+		if agent, ok := cs.agents[waiter]; ok {
+			agent.send(msg)
+		}
+	}
+	if len(waiters) > 0 {
+		cs.logger.Debugf("Shared the data '%s' with %d instances", data.GetId(), len(waiters))
+	}
+
+	return nil
+}
+
+// pendingCommand tracks a command that the instances are applying.
+type pendingCommand struct {
+	waiting map[uint32]bool
+	applied uint32
+	errs    []string
+	done    chan struct{}
+}
+
+// ExecuteCommand implements the CoordinatorControlServer interface, it sends
+// the command to all the live instances, which apply it together after all of
+// them have received it, and it waits for all of them to apply it.
+func (cs *CoordinatorServer) ExecuteCommand(ctx context.Context, cmd *Command) (*CommandResponse, error) {
+	if cmd.GetCommand() == nil {
+		return nil, status.Error(codes.InvalidArgument, "the command is empty")
+	}
+
+	cs.mx.Lock()
+	if connected, expected := uint32(len(cs.agents)), cs.expectedInstances(); connected == 0 || connected < expected {
+		cs.mx.Unlock()
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"only %d of the %d instances of the test are connected", connected, expected)
+	}
+	cs.commandCount++
+	cmd = &Command{Id: cs.commandCount, Command: cmd.GetCommand()}
+	pending := &pendingCommand{waiting: make(map[uint32]bool, len(cs.agents)), done: make(chan struct{})}
+	cs.commands[cmd.GetId()] = pending
+	for id, agent := range cs.agents {
+		pending.waiting[id] = true
+		agent.send(&ControllerMessage{Message: &ControllerMessage_Command{Command: cmd}})
+	}
+	cs.logger.Debugf("Sent the command %d to %d instances", cmd.GetId(), len(pending.waiting))
+	cs.mx.Unlock()
+
+	select {
+	case <-pending.done:
+	case <-ctx.Done():
+		cs.mx.Lock()
+		delete(cs.commands, cmd.GetId())
+		cs.mx.Unlock()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	if len(pending.errs) > 0 {
+		return nil, status.Errorf(codes.Aborted, "the command has failed on %d instances: %s",
+			len(pending.errs), strings.Join(pending.errs, "; "))
+	}
+	return &CommandResponse{Instances: pending.applied}, nil
+}
+
+func (cs *CoordinatorServer) handleCommandResult(instanceID uint32, result *CommandResult) {
+	pending, ok := cs.commands[result.GetId()]
+	if !ok || !pending.waiting[instanceID] {
+		return // the command has been canceled
+	}
+
+	delete(pending.waiting, instanceID)
+	if result.GetError() != "" {
+		pending.errs = append(pending.errs, fmt.Sprintf("instance %d: %s", instanceID, result.GetError()))
+	} else {
+		pending.applied++
+	}
+	cs.checkCommandDone(result.GetId(), pending)
+}
+
+// stopWaitingForCommands stops waiting for the instance, which
+// has left the test, to apply the pending commands.
+func (cs *CoordinatorServer) stopWaitingForCommands(instanceID uint32) {
+	for id, pending := range cs.commands {
+		delete(pending.waiting, instanceID)
+		cs.checkCommandDone(id, pending)
+	}
+}
+
+func (cs *CoordinatorServer) checkCommandDone(id uint64, pending *pendingCommand) {
+	if len(pending.waiting) > 0 {
+		return
+	}
+	delete(cs.commands, id)
+	close(pending.done)
+}
+
+// agentConn queues the messages for a connected agent instance, so the
+// coordinator never blocks on a slow or broken stream while it holds its lock.
+type agentConn struct {
+	mx        sync.Mutex
+	queue     []*ControllerMessage
+	notify    chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newAgentConn() *agentConn {
+	return &agentConn{
+		notify: make(chan struct{}, 1),
+		closed: make(chan struct{}),
+	}
+}
+
+func (a *agentConn) send(msg *ControllerMessage) {
+	a.mx.Lock()
+	a.queue = append(a.queue, msg)
+	a.mx.Unlock()
+
+	select {
+	case a.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (a *agentConn) close() {
+	a.closeOnce.Do(func() { close(a.closed) })
+}
+
+// forward sends the queued messages over the stream, until
+// the connection is closed or the stream fails.
+func (a *agentConn) forward(stream DistributedTest_CommandAndControlServer) error {
+	for {
+		select {
+		case <-a.notify:
+		case <-a.closed:
+			return nil
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+
+		a.mx.Lock()
+		queue := a.queue
+		a.queue = nil
+		a.mx.Unlock()
+
+		for _, msg := range queue {
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
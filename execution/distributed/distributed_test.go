@@ -0,0 +1,630 @@
+package distributed
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils"
+)
+
+var _ execution.Controller = &AgentController{}
+
+// startTestCoordinator starts a coordinator for the provided number of
+// instances and returns it, with a client connected to it.
+func startTestCoordinator(
+	t *testing.T, instanceCount uint32, membership MembershipConfig,
+) (*CoordinatorServer, DistributedTestClient) {
+	t.Helper()
+
+	coordinator, err := NewCoordinatorServer(instanceCount, membership, testutils.NewLogger(t))
+	require.NoError(t, err)
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	RegisterDistributedTestServer(server, coordinator)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return coordinator, NewDistributedTestClient(conn)
+}
+
+// newTestAgents starts a coordinator for the provided number of instances
+// and returns a connected agent controller for each of them.
+func newTestAgents(t *testing.T, instanceCount uint32) []*AgentController {
+	t.Helper()
+
+	_, client := startTestCoordinator(t, instanceCount, MembershipConfig{})
+	agents := make([]*AgentController, instanceCount)
+	for i := range agents {
+		agents[i] = newTestAgent(context.Background(), t, client)
+		assert.Equal(t, uint32(i+1), agents[i].InstanceID())
+	}
+
+	return agents
+}
+
+// newTestAgent returns a new agent controller, connected to the
+// coordinator until the provided context or the test is done.
+func newTestAgent(ctx context.Context, t *testing.T, client DistributedTestClient) *AgentController {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(ctx)
+	t.Cleanup(cancel)
+
+	agent, err := NewAgentController(ctx, client, testutils.NewLogger(t))
+	require.NoError(t, err)
+	return agent
+}
+
+func TestNewCoordinatorServerInvalidInstanceCount(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewCoordinatorServer(0, MembershipConfig{}, testutils.NewLogger(t))
+	require.Error(t, err)
+}
+
+func TestDistributedSignalAndWait(t *testing.T) {
+	t.Parallel()
+
+	agents := newTestAgents(t, 3)
+
+	var reached int64
+	var wg sync.WaitGroup
+	for i, agent := range agents {
+		i, agent := i, agent
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// the last instance is the slowest one to reach the barrier
+			if i == len(agents)-1 {
+				time.Sleep(100 * time.Millisecond)
+				assert.Equal(t, int64(0), atomic.LoadInt64(&reached))
+			}
+			assert.NoError(t, execution.SignalAndWait(context.Background(), agent, "test-start"))
+			atomic.AddInt64(&reached, 1)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(len(agents)), atomic.LoadInt64(&reached))
+}
+
+func TestDistributedGetOrCreateData(t *testing.T) {
+	t.Parallel()
+
+	agents := newTestAgents(t, 4)
+
+	var calls int64
+	results := make([][]byte, len(agents))
+	var wg sync.WaitGroup
+	for i, agent := range agents {
+		i, agent := i, agent
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := agent.GetOrCreateData("setup", func() ([]byte, error) {
+				atomic.AddInt64(&calls, 1)
+				time.Sleep(50 * time.Millisecond)
+				return []byte(`{"token":"secret"}`), nil
+			})
+			assert.NoError(t, err)
+			results[i] = data
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+	for _, data := range results {
+		assert.Equal(t, []byte(`{"token":"secret"}`), data)
+	}
+
+	// the data is stored, so later requests receive it directly
+	data, err := agents[0].GetOrCreateData("setup", func() ([]byte, error) {
+		return nil, errors.New("unexpected call")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"token":"secret"}`), data)
+}
+
+func TestDistributedGetOrCreateDataError(t *testing.T) {
+	t.Parallel()
+
+	agents := newTestAgents(t, 2)
+
+	_, err := agents[0].GetOrCreateData("setup", func() ([]byte, error) {
+		return nil, errors.New("setup failed")
+	})
+	require.ErrorContains(t, err, "setup failed")
+
+	_, err = agents[1].GetOrCreateData("setup", func() ([]byte, error) {
+		return nil, errors.New("unexpected call")
+	})
+	require.ErrorContains(t, err, "setup failed")
+}
+
+func TestDistributedCoordinatorSetData(t *testing.T) {
+	t.Parallel()
+
+	coordinator, client := startTestCoordinator(t, 1, MembershipConfig{})
+	coordinator.SetData(ArchiveDataID, []byte("archive contents"))
+	agent := newTestAgent(context.Background(), t, client)
+
+	data, err := agent.GetOrCreateData(ArchiveDataID, func() ([]byte, error) {
+		return nil, errors.New("unexpected call")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("archive contents"), data)
+}
+
+func TestDistributedCoordinatorWaitForData(t *testing.T) {
+	t.Parallel()
+
+	coordinator, client := startTestCoordinator(t, 1, MembershipConfig{})
+	agent := newTestAgent(context.Background(), t, client)
+
+	received := make(chan []byte, 1)
+	go func() {
+		data, err := coordinator.WaitForData(context.Background(), ArchiveDataID)
+		assert.NoError(t, err)
+		received <- data
+	}()
+
+	_, err := agent.GetOrCreateData(ArchiveDataID, func() ([]byte, error) {
+		return []byte("archive contents"), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("archive contents"), <-received)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = coordinator.WaitForData(ctx, "missing")
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestDistributedCoordinatorFinished(t *testing.T) {
+	t.Parallel()
+
+	coordinator, client := startTestCoordinator(t, 2, MembershipConfig{})
+	agents := []*AgentController{
+		newTestAgent(context.Background(), t, client),
+		newTestAgent(context.Background(), t, client),
+	}
+	assert.Zero(t, coordinator.TestRunDuration())
+
+	for _, agent := range agents {
+		require.NoError(t, agent.Signal(testStartEventID))
+	}
+	require.Eventually(t, func() bool { return coordinator.TestRunDuration() > 0 }, time.Second, time.Millisecond)
+
+	require.NoError(t, agents[0].Signal(FinishedEventID))
+	select {
+	case <-coordinator.Finished():
+		t.Fatal("the test has finished before all the instances")
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.NoError(t, agents[1].Signal(FinishedEventID))
+	select {
+	case <-coordinator.Finished():
+	case <-time.After(time.Second):
+		t.Fatal("the test hasn't finished")
+	}
+}
+
+func TestDistributedInstanceStatus(t *testing.T) {
+	t.Parallel()
+
+	coordinator, client := startTestCoordinator(t, 1, MembershipConfig{})
+	agent := newTestAgent(context.Background(), t, client)
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	state := lib.NewExecutionState(nil, et, 0, 0)
+	state.SetExecutionStatus(lib.ExecutionStatusRunning)
+	state.AddFullIterations(3)
+	state.AddInterruptedIterations(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go agent.StreamStatus(ctx, state, 10*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		return coordinator.InstanceStatuses()[1].GetFullIterations() == 3
+	}, time.Second, 10*time.Millisecond)
+	st := coordinator.InstanceStatuses()[1]
+	assert.Equal(t, "Running", st.GetStatus())
+	assert.Equal(t, uint64(1), st.GetInterruptedIterations())
+	assert.Empty(t, st.GetError())
+
+	assert.Empty(t, coordinator.FailureReasons())
+
+	state.SetExecutionStatus(lib.ExecutionStatusEnded)
+	state.MarkFailed("bad response")
+	require.NoError(t, agent.SendStatus(NewInstanceStatus(state, errors.New("test failed"))))
+	require.Eventually(t, func() bool {
+		return coordinator.InstanceStatuses()[1].GetError() == "test failed"
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"bad response"}, coordinator.FailureReasons())
+}
+
+func TestDistributedNamespacedController(t *testing.T) {
+	t.Parallel()
+
+	agents := newTestAgents(t, 2)
+
+	first := execution.GetNamespacedController("first", agents[0])
+	second := execution.GetNamespacedController("second", agents[1])
+
+	dataA, err := first.GetOrCreateData("setup", func() ([]byte, error) { return []byte("a"), nil })
+	require.NoError(t, err)
+	dataB, err := second.GetOrCreateData("setup", func() ([]byte, error) { return []byte("b"), nil })
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("a"), dataA)
+	assert.Equal(t, []byte("b"), dataB)
+}
+
+func TestCoordinatorRegisterTooManyInstances(t *testing.T) {
+	t.Parallel()
+
+	coordinator, err := NewCoordinatorServer(1, MembershipConfig{}, testutils.NewLogger(t))
+	require.NoError(t, err)
+	_, err = coordinator.Register(context.Background(), &RegisterRequest{})
+	require.NoError(t, err)
+	_, err = coordinator.Register(context.Background(), &RegisterRequest{})
+	require.ErrorContains(t, err, "have already been registered")
+}
+
+func TestDistributedCoordinatorClosed(t *testing.T) {
+	t.Parallel()
+
+	agents := newTestAgents(t, 2)
+	wait := agents[0].Wait(context.Background(), "never")
+	require.NoError(t, agents[0].Signal("never"))
+	require.NoError(t, agents[0].Close())
+
+	require.ErrorContains(t, wait(), "the connection with the coordinator was closed")
+}
+
+func TestDistributedWaitCanceled(t *testing.T) {
+	t.Parallel()
+
+	agents := newTestAgents(t, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, execution.SignalAndWait(ctx, agents[0], "never"), context.DeadlineExceeded)
+}
+
+func TestDistributedSignalError(t *testing.T) {
+	t.Parallel()
+
+	agents := newTestAgents(t, 3)
+
+	var wg sync.WaitGroup
+	for _, agent := range agents[1:] {
+		agent := agent
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := execution.SignalAndWait(context.Background(), agent, "test-start")
+			assert.ErrorContains(t, err, "setup failed")
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, agents[0].SignalError("test-start", errors.New("setup failed")))
+	wg.Wait()
+
+	// the error is received by the instances that start waiting later too
+	require.ErrorContains(t, agents[0].Wait(context.Background(), "test-start")(), "setup failed")
+}
+
+func TestNewCoordinatorServerInvalidInstanceLossPolicy(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewCoordinatorServer(1, MembershipConfig{Policy: 42}, testutils.NewLogger(t))
+	require.ErrorContains(t, err, "invalid instance loss policy")
+}
+
+func TestInstanceLossPolicyText(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range InstanceLossPolicyValues() {
+		text, err := policy.MarshalText()
+		require.NoError(t, err)
+
+		var parsed InstanceLossPolicy
+		require.NoError(t, parsed.UnmarshalText(text))
+		assert.Equal(t, policy, parsed)
+	}
+
+	policy, err := InstanceLossPolicyString("redistribute")
+	require.NoError(t, err)
+	assert.Equal(t, InstanceLossRedistribute, policy)
+
+	var parsed InstanceLossPolicy
+	require.Error(t, parsed.UnmarshalText([]byte("ignore")))
+}
+
+// silentClient registers agent instances that don't send heartbeats.
+type silentClient struct {
+	DistributedTestClient
+}
+
+func (sc silentClient) Register(
+	ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption,
+) (*RegisterResponse, error) {
+	resp, err := sc.DistributedTestClient.Register(ctx, in, opts...)
+	if resp != nil {
+		resp.HeartbeatIntervalMs = 0
+	}
+	return resp, err
+}
+
+func TestDistributedHeartbeatTimeout(t *testing.T) {
+	t.Parallel()
+
+	coordinator, client := startTestCoordinator(t, 3, MembershipConfig{
+		HeartbeatInterval: 20 * time.Millisecond,
+		Policy:            InstanceLossContinue,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go coordinator.MonitorInstances(ctx)
+
+	agents := []*AgentController{
+		newTestAgent(ctx, t, client),
+		newTestAgent(ctx, t, client),
+		newTestAgent(ctx, t, silentClient{client}),
+	}
+	memberships := make(chan *Membership, 1)
+	agents[0].SetMembershipHandler(func(m *Membership) { memberships <- m })
+
+	// the silent instance is lost, so the others don't wait for it
+	var wg sync.WaitGroup
+	for _, agent := range agents[:2] {
+		agent := agent
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, execution.SignalAndWaitWithTimeout(ctx, agent, "test-start", 5*time.Second))
+		}()
+	}
+	wg.Wait()
+
+	membership := <-memberships
+	assert.Equal(t, []uint32{1, 2}, membership.GetLiveInstances())
+	assert.Equal(t, []uint32{3}, membership.GetLostInstances())
+	assert.False(t, membership.GetRebalance())
+	assert.Equal(t, []uint32{1, 2}, coordinator.LiveInstances())
+
+	// the lost instance is disconnected as soon as it sends anything
+	require.NoError(t, agents[2].Signal("test-start"))
+	require.ErrorContains(t, agents[2].Wait(ctx, "test-done")(), "has already been lost")
+}
+
+func TestDistributedInstanceLossAbort(t *testing.T) {
+	t.Parallel()
+
+	coordinator, client := startTestCoordinator(t, 3, MembershipConfig{Policy: InstanceLossAbort})
+	agents := []*AgentController{
+		newTestAgent(context.Background(), t, client),
+		newTestAgent(context.Background(), t, client),
+	}
+	lostCtx, loseInstance := context.WithCancel(context.Background())
+	agents = append(agents, newTestAgent(lostCtx, t, client))
+
+	// all the instances are connected after they've reached an event together
+	var wg sync.WaitGroup
+	for _, agent := range agents {
+		agent := agent
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, execution.SignalAndWait(context.Background(), agent, "test-start"))
+		}()
+	}
+	wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	waits := make([]func() error, 2)
+	for i, agent := range agents[:2] {
+		waits[i] = agent.Wait(ctx, execution.AbortEventID)
+	}
+
+	// breaking the stream, without closing it, loses the instance
+	loseInstance()
+	for _, wait := range waits {
+		require.ErrorContains(t, wait(), "instance 3 of the distributed test has been lost")
+	}
+	assert.Equal(t, []uint32{1, 2}, coordinator.LiveInstances())
+}
+
+func TestDistributedInstanceLossRedistribute(t *testing.T) {
+	t.Parallel()
+
+	_, client := startTestCoordinator(t, 2, MembershipConfig{Policy: InstanceLossRedistribute})
+	agent := newTestAgent(context.Background(), t, client)
+	memberships := make(chan *Membership, 1)
+	agent.SetMembershipHandler(func(m *Membership) { memberships <- m })
+
+	lostCtx, loseInstance := context.WithCancel(context.Background())
+	lostAgent := newTestAgent(lostCtx, t, client)
+
+	// the instance that's lost while creating the data never finishes it
+	creating, unblock := make(chan struct{}), make(chan struct{})
+	defer close(unblock)
+	go func() {
+		_, _ = lostAgent.GetOrCreateData("setup", func() ([]byte, error) {
+			close(creating)
+			<-unblock
+			return []byte("lost"), nil
+		})
+	}()
+	<-creating
+
+	result := make(chan []byte)
+	go func() {
+		data, err := agent.GetOrCreateData("setup", func() ([]byte, error) { return []byte("live"), nil })
+		assert.NoError(t, err)
+		result <- data
+	}()
+	time.Sleep(50 * time.Millisecond)
+	loseInstance()
+
+	// the live instance has to create the data instead
+	assert.Equal(t, []byte("live"), <-result)
+	membership := <-memberships
+	assert.Equal(t, []uint32{1}, membership.GetLiveInstances())
+	assert.True(t, membership.GetRebalance())
+}
+
+func TestDistributedGracefulLeave(t *testing.T) {
+	t.Parallel()
+
+	coordinator, client := startTestCoordinator(t, 2, MembershipConfig{Policy: InstanceLossAbort})
+	agents := []*AgentController{
+		newTestAgent(context.Background(), t, client),
+		newTestAgent(context.Background(), t, client),
+	}
+	require.NoError(t, agents[0].Signal("test-done"))
+	require.NoError(t, agents[1].Signal("test-done"))
+	require.NoError(t, agents[0].Close())
+	require.NoError(t, agents[1].Wait(context.Background(), "test-done")())
+
+	// closing the stream isn't a loss, so the test isn't aborted
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, agents[1].Wait(ctx, execution.AbortEventID)(), context.DeadlineExceeded)
+	assert.Equal(t, []uint32{1, 2}, coordinator.LiveInstances())
+}
+
+func TestNewSegmentAssignment(t *testing.T) {
+	t.Parallel()
+
+	assignment, err := newSegmentAssignment(2, []uint32{1, 3})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), assignment.GetGeneration())
+	assert.Equal(t, "0,1/2,1", assignment.GetExecutionSegmentSequence())
+	assert.Equal(t, map[uint32]string{1: "0:1/2", 3: "1/2:1"}, assignment.GetExecutionSegments())
+
+	et, err := assignment.executionTuple(3)
+	require.NoError(t, err)
+	assert.Equal(t, "1/2:1", et.Segment.String())
+	assert.Equal(t, 1, et.SegmentIndex)
+
+	_, err = assignment.executionTuple(2)
+	require.ErrorContains(t, err, "no execution segment has been assigned to instance 2")
+}
+
+// rebalancedSegments returns a channel receiving
+// the new execution segments of the agent.
+func rebalancedSegments(agent *AgentController) chan string {
+	segments := make(chan string, 1)
+	agent.SetRebalanceHandler(func(et *lib.ExecutionTuple) error {
+		segments <- et.Segment.String()
+		return nil
+	})
+	return segments
+}
+
+func TestDistributedRebalanceOnInstanceLoss(t *testing.T) {
+	t.Parallel()
+
+	_, client := startTestCoordinator(t, 3, MembershipConfig{Policy: InstanceLossRedistribute})
+	agents := []*AgentController{
+		newTestAgent(context.Background(), t, client),
+		newTestAgent(context.Background(), t, client),
+	}
+	lostCtx, loseInstance := context.WithCancel(context.Background())
+	agents = append(agents, newTestAgent(lostCtx, t, client))
+
+	for i, segment := range []string{"0:1/3", "1/3:2/3", "2/3:1"} {
+		et, err := agents[i].ExecutionTuple()
+		require.NoError(t, err)
+		assert.Equal(t, segment, et.Segment.String())
+	}
+
+	var wg sync.WaitGroup
+	for _, agent := range agents {
+		agent := agent
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, execution.SignalAndWait(context.Background(), agent, "test-start"))
+		}()
+	}
+	wg.Wait()
+
+	first, second := rebalancedSegments(agents[0]), rebalancedSegments(agents[1])
+	loseInstance()
+
+	// the live instances take over the segment of the lost one
+	assert.Equal(t, "0:1/2", <-first)
+	assert.Equal(t, "1/2:1", <-second)
+	et, err := agents[1].ExecutionTuple()
+	require.NoError(t, err)
+	assert.Equal(t, "0,1/2,1", et.Sequence.String())
+}
+
+func TestDistributedRebalanceOnInstanceJoin(t *testing.T) {
+	t.Parallel()
+
+	coordinator, client := startTestCoordinator(t, 2, MembershipConfig{Policy: InstanceLossRedistribute})
+	agents := []*AgentController{
+		newTestAgent(context.Background(), t, client),
+		newTestAgent(context.Background(), t, client),
+	}
+	first, second := rebalancedSegments(agents[0]), rebalancedSegments(agents[1])
+
+	var wg sync.WaitGroup
+	for _, agent := range agents {
+		agent := agent
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, execution.SignalAndWait(context.Background(), agent, "test-start"))
+		}()
+	}
+	wg.Wait()
+
+	joined := newTestAgent(context.Background(), t, client)
+	assert.Equal(t, uint32(3), joined.InstanceID())
+	et, err := joined.ExecutionTuple()
+	require.NoError(t, err)
+	assert.Equal(t, "2/3:1", et.Segment.String())
+
+	// the other instances use the new segments after the new one has connected
+	assert.Equal(t, "0:1/3", <-first)
+	assert.Equal(t, "1/3:2/3", <-second)
+	assert.Equal(t, []uint32{1, 2, 3}, coordinator.LiveInstances())
+
+	// the new instance doesn't wait for the events that the test has already reached
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, joined.Wait(ctx, "test-start")())
+}
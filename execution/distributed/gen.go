@@ -0,0 +1,6 @@
+// Package distributed implements the execution.Controller interface for
+// distributed tests, i.e. tests executed by multiple k6 agent instances
+// that are synchronized by a coordinator over gRPC.
+package distributed
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative ./distributed.proto
@@ -0,0 +1,62 @@
+// Code generated by "enumer -type=InstanceLossPolicy -transform=snake -trimprefix InstanceLoss -text -output instance_loss_policy_gen.go"; DO NOT EDIT.
+
+package distributed
+
+import (
+	"fmt"
+)
+
+const _InstanceLossPolicyName = "abortcontinueredistribute"
+
+var _InstanceLossPolicyIndex = [...]uint8{0, 5, 13, 25}
+
+func (i InstanceLossPolicy) String() string {
+	if i >= InstanceLossPolicy(len(_InstanceLossPolicyIndex)-1) {
+		return fmt.Sprintf("InstanceLossPolicy(%d)", i)
+	}
+	return _InstanceLossPolicyName[_InstanceLossPolicyIndex[i]:_InstanceLossPolicyIndex[i+1]]
+}
+
+var _InstanceLossPolicyValues = []InstanceLossPolicy{0, 1, 2}
+
+var _InstanceLossPolicyNameToValueMap = map[string]InstanceLossPolicy{
+	_InstanceLossPolicyName[0:5]:   0,
+	_InstanceLossPolicyName[5:13]:  1,
+	_InstanceLossPolicyName[13:25]: 2,
+}
+
+// InstanceLossPolicyString retrieves an enum value from the enum constants string name.
+// Throws an error if the param is not part of the enum.
+func InstanceLossPolicyString(s string) (InstanceLossPolicy, error) {
+	if val, ok := _InstanceLossPolicyNameToValueMap[s]; ok {
+		return val, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to InstanceLossPolicy values", s)
+}
+
+// InstanceLossPolicyValues returns all values of the enum
+func InstanceLossPolicyValues() []InstanceLossPolicy {
+	return _InstanceLossPolicyValues
+}
+
+// IsAInstanceLossPolicy returns "true" if the value is listed in the enum definition. "false" otherwise
+func (i InstanceLossPolicy) IsAInstanceLossPolicy() bool {
+	for _, v := range _InstanceLossPolicyValues {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for InstanceLossPolicy
+func (i InstanceLossPolicy) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for InstanceLossPolicy
+func (i *InstanceLossPolicy) UnmarshalText(text []byte) error {
+	var err error
+	*i, err = InstanceLossPolicyString(string(text))
+	return err
+}
@@ -0,0 +1,98 @@
+package distributed
+
+import (
+	"fmt"
+	"time"
+
+	"go.k6.io/k6/lib"
+)
+
+// InstanceLossPolicy determines what the coordinator does when
+// one of the agent instances disappears in the middle of a test.
+//
+//go:generate enumer -type=InstanceLossPolicy -transform=snake -trimprefix InstanceLoss -text -output instance_loss_policy_gen.go
+type InstanceLossPolicy uint8
+
+const (
+	// InstanceLossAbort aborts the test on all the other instances.
+	InstanceLossAbort InstanceLossPolicy = iota
+	// InstanceLossContinue lets the other instances continue the test
+	// without the lost one, so the test runs degraded.
+	InstanceLossContinue
+	// InstanceLossRedistribute lets the other instances continue the test and
+	// asks them to redistribute the work of the lost one between themselves.
+	// With it, new instances can also join the test while it's running.
+	InstanceLossRedistribute
+)
+
+// MembershipConfig configures how the coordinator detects
+// the lost agent instances and how it handles them.
+type MembershipConfig struct {
+	// HeartbeatInterval is how often the agent instances send heartbeats to
+	// the coordinator, zero disables them. Regardless of the heartbeats, an
+	// instance is always considered lost if its stream breaks, without it
+	// having been closed by the instance.
+	HeartbeatInterval time.Duration
+	// FailureTimeout is how long an instance can go without sending anything
+	// to the coordinator, before it's considered lost. It defaults to three
+	// times the heartbeat interval.
+	FailureTimeout time.Duration
+	// Policy determines what happens after an instance has been lost.
+	Policy InstanceLossPolicy
+}
+
+func (mc MembershipConfig) failureTimeout() time.Duration {
+	if mc.FailureTimeout > 0 || mc.HeartbeatInterval <= 0 {
+		return mc.FailureTimeout
+	}
+	return 3 * mc.HeartbeatInterval
+}
+
+// rebalanceEventID returns the ID of the event that the instances reach
+// after they have received the segment assignment with the provided
+// generation, so all of them start using it at the same time.
+func rebalanceEventID(generation uint64) string {
+	return fmt.Sprintf("rebalance-%d", generation)
+}
+
+// newSegmentAssignment splits the whole test in equal execution
+// segments, one for each of the provided instances, in order.
+func newSegmentAssignment(generation uint64, instances []uint32) (*SegmentAssignment, error) {
+	var fullSegment *lib.ExecutionSegment
+	segments, err := fullSegment.Split(int64(len(instances)))
+	if err != nil {
+		return nil, err
+	}
+
+	sequence, err := lib.NewExecutionSegmentSequence(segments...)
+	if err != nil {
+		return nil, err
+	}
+
+	assignment := &SegmentAssignment{
+		Generation:               generation,
+		ExecutionSegmentSequence: sequence.String(),
+		ExecutionSegments:        make(map[uint32]string, len(instances)),
+	}
+	for i, segment := range segments {
+		assignment.ExecutionSegments[instances[i]] = segment.String()
+	}
+	return assignment, nil
+}
+
+// executionTuple returns the execution tuple of the provided instance.
+func (sa *SegmentAssignment) executionTuple(instanceID uint32) (*lib.ExecutionTuple, error) {
+	segmentStr, ok := sa.GetExecutionSegments()[instanceID]
+	if !ok {
+		return nil, fmt.Errorf("no execution segment has been assigned to instance %d", instanceID)
+	}
+	segment, err := lib.NewExecutionSegmentFromString(segmentStr)
+	if err != nil {
+		return nil, err
+	}
+	sequence, err := lib.NewExecutionSegmentSequenceFromString(sa.GetExecutionSegmentSequence())
+	if err != nil {
+		return nil, err
+	}
+	return lib.NewExecutionTuple(segment, &sequence)
+}
@@ -0,0 +1,133 @@
+package distributed
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/output"
+)
+
+// DefaultMetricsFlushInterval is how often the MetricsOutput
+// sends the collected metric samples to the coordinator.
+const DefaultMetricsFlushInterval = time.Second
+
+// MetricsOutput is an output that streams the metric samples of an agent
+// instance to the coordinator, over the same stream used for the
+// synchronization, so the thresholds, the end-of-test summary and the other
+// outputs are computed once for the whole test by the coordinator, instead of
+// by every instance.
+type MetricsOutput struct {
+	output.SampleBuffer
+
+	agent         *AgentController
+	flushInterval time.Duration
+	flusher       *output.PeriodicFlusher
+	logger        logrus.FieldLogger
+}
+
+var _ output.Output = &MetricsOutput{}
+
+// NewMetricsOutput returns a new output that sends the metric samples to
+// the coordinator through the provided agent, at the given interval.
+func NewMetricsOutput(agent *AgentController, flushInterval time.Duration) *MetricsOutput {
+	return &MetricsOutput{
+		agent:         agent,
+		flushInterval: flushInterval,
+		logger:        agent.logger.WithField("output", "distributed"),
+	}
+}
+
+// Description returns a human-readable description of the output.
+func (mo *MetricsOutput) Description() string {
+	return fmt.Sprintf("distributed (coordinator, instance %d)", mo.agent.InstanceID())
+}
+
+// Start starts flushing the metric samples periodically.
+func (mo *MetricsOutput) Start() error {
+	flusher, err := output.NewPeriodicFlusher(mo.flushInterval, mo.flush)
+	if err != nil {
+		return err
+	}
+	mo.flusher = flusher
+	return nil
+}
+
+// Stop flushes the remaining metric samples and stops the output.
+func (mo *MetricsOutput) Stop() error {
+	mo.flusher.Stop()
+	return nil
+}
+
+func (mo *MetricsOutput) flush() {
+	containers := mo.GetBufferedSamples()
+	if len(containers) == 0 {
+		return
+	}
+
+	batch := &MetricSamples{}
+	for _, container := range containers {
+		for _, sample := range container.GetSamples() {
+			batch.Samples = append(batch.Samples, encodeSample(sample))
+		}
+	}
+
+	err := mo.agent.send(&AgentMessage{
+		InstanceId: mo.agent.instanceID,
+		Message:    &AgentMessage_MetricSamples{MetricSamples: batch},
+	})
+	if err != nil {
+		mo.logger.WithError(err).Errorf("Unable to send %d metric samples", len(batch.Samples))
+	}
+}
+
+func encodeSample(sample metrics.Sample) *MetricSample {
+	m := sample.Metric
+	encoded := &MetricSample{
+		Metric:   m.Name,
+		Type:     uint32(m.Type),
+		Contains: uint32(m.Contains),
+		Metadata: sample.Metadata,
+		Time:     sample.Time.UnixNano(),
+		Value:    sample.Value,
+		Weight:   sample.Weight,
+	}
+	if sample.Tags != nil {
+		encoded.Tags = sample.Tags.Map()
+	}
+	if hs, ok := m.Sink.(*metrics.HistogramSink); ok {
+		encoded.Buckets = hs.Buckets()
+	}
+	return encoded
+}
+
+// decodeSample returns the sample for the provided registry,
+// registering its metric there if it doesn't exist yet.
+func decodeSample(registry *metrics.Registry, sample *MetricSample) (metrics.Sample, error) {
+	var (
+		m   *metrics.Metric
+		err error
+	)
+	typ, contains := metrics.MetricType(sample.GetType()), metrics.ValueType(sample.GetContains())
+	if typ == metrics.Histogram && len(sample.GetBuckets()) > 0 {
+		m, err = registry.NewHistogram(sample.GetMetric(), sample.GetBuckets(), contains)
+	} else {
+		m, err = registry.NewMetric(sample.GetMetric(), typ, contains)
+	}
+	if err != nil {
+		return metrics.Sample{}, err
+	}
+
+	return metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: m,
+			Tags:   registry.RootTagSet().WithTagsFromMap(sample.GetTags()),
+		},
+		Time:     time.Unix(0, sample.GetTime()),
+		Value:    sample.GetValue(),
+		Weight:   sample.GetWeight(),
+		Metadata: sample.GetMetadata(),
+	}, nil
+}
@@ -0,0 +1,129 @@
+package distributed
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/lib/testutils/mockoutput"
+	"go.k6.io/k6/metrics"
+)
+
+func TestDistributedMetricsStreaming(t *testing.T) {
+	t.Parallel()
+
+	coordinator, client := startTestCoordinator(t, 2, MembershipConfig{})
+	registry := metrics.NewRegistry()
+	out := mockoutput.New()
+	coordinator.SetMetricsOutputs(registry, out)
+
+	now := time.Unix(1700000000, 123)
+	agents := make([]*AgentController, 2)
+	for i := range agents {
+		agent := newTestAgent(context.Background(), t, client)
+		agents[i] = agent
+
+		agentRegistry := metrics.NewRegistry()
+		counter := agentRegistry.MustNewMetric("requests", metrics.Counter)
+		histogram, err := agentRegistry.NewHistogram("sizes", []float64{10, 100}, metrics.Data)
+		require.NoError(t, err)
+		tags := agentRegistry.RootTagSet().With("instance", "agent")
+
+		metricsOutput := NewMetricsOutput(agent, 10*time.Millisecond)
+		require.NoError(t, metricsOutput.Start())
+		metricsOutput.AddMetricSamples([]metrics.SampleContainer{
+			metrics.Sample{
+				TimeSeries: metrics.TimeSeries{Metric: counter, Tags: tags},
+				Time:       now,
+				Value:      float64(i + 1),
+				Metadata:   map[string]string{"trace_id": "abc"},
+			},
+			metrics.Sample{
+				TimeSeries: metrics.TimeSeries{Metric: histogram, Tags: tags},
+				Time:       now,
+				Value:      50,
+				Weight:     3,
+			},
+		})
+		require.NoError(t, metricsOutput.Stop())
+	}
+
+	// the samples are received before the event, since the streams are ordered
+	var wg sync.WaitGroup
+	for _, agent := range agents {
+		agent := agent
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, execution.SignalAndWait(context.Background(), agent, "test-done"))
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, out.Samples, 4)
+	sort.SliceStable(out.Samples, func(i, j int) bool {
+		return out.Samples[i].Metric.Name < out.Samples[j].Metric.Name
+	})
+
+	var total float64
+	for _, sample := range out.Samples[:2] {
+		assert.Equal(t, registry.Get("requests"), sample.Metric)
+		assert.Equal(t, map[string]string{"instance": "agent"}, sample.Tags.Map())
+		assert.Equal(t, map[string]string{"trace_id": "abc"}, sample.Metadata)
+		assert.True(t, now.Equal(sample.Time))
+		total += sample.Value
+	}
+	assert.Equal(t, float64(3), total)
+
+	for _, sample := range out.Samples[2:] {
+		assert.Equal(t, metrics.Histogram, sample.Metric.Type)
+		assert.Equal(t, metrics.Data, sample.Metric.Contains)
+		assert.Equal(t, uint64(3), sample.Occurrences())
+		require.IsType(t, &metrics.HistogramSink{}, sample.Metric.Sink)
+		assert.Equal(t, []float64{10, 100}, sample.Metric.Sink.(*metrics.HistogramSink).Buckets())
+	}
+}
+
+func TestDistributedMetricsStreamingInvalidMetric(t *testing.T) {
+	t.Parallel()
+
+	coordinator, client := startTestCoordinator(t, 1, MembershipConfig{})
+	registry := metrics.NewRegistry()
+	registry.MustNewMetric("requests", metrics.Gauge)
+	out := mockoutput.New()
+	coordinator.SetMetricsOutputs(registry, out)
+	agent := newTestAgent(context.Background(), t, client)
+
+	agentRegistry := metrics.NewRegistry()
+	metricsOutput := NewMetricsOutput(agent, time.Hour)
+	require.NoError(t, metricsOutput.Start())
+	metricsOutput.AddMetricSamples([]metrics.SampleContainer{
+		metrics.Sample{
+			TimeSeries: metrics.TimeSeries{
+				Metric: agentRegistry.MustNewMetric("requests", metrics.Counter),
+				Tags:   agentRegistry.RootTagSet(),
+			},
+			Value: 1,
+		},
+		metrics.Sample{
+			TimeSeries: metrics.TimeSeries{
+				Metric: agentRegistry.MustNewMetric("errors", metrics.Counter),
+				Tags:   agentRegistry.RootTagSet(),
+			},
+			Value: 1,
+		},
+	})
+	require.NoError(t, metricsOutput.Stop())
+
+	// the sample with the conflicting metric type is discarded,
+	// without the instance being disconnected
+	require.NoError(t, execution.SignalAndWait(context.Background(), agent, "test-done"))
+	require.Len(t, out.Samples, 1)
+	assert.Equal(t, "errors", out.Samples[0].Metric.Name)
+}
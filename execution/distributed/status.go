@@ -0,0 +1,75 @@
+package distributed
+
+import (
+	"context"
+	"time"
+
+	"go.k6.io/k6/lib"
+)
+
+// ArchiveDataID is the ID of the data with the archive of the test, the
+// agent instances get it with GetOrCreateData before they start the test.
+const ArchiveDataID = "archive"
+
+// FinishedEventID is the ID of the event that the agent instances signal
+// after they have sent all their metric samples and their final execution
+// status, so the coordinator can process the metrics of the whole test.
+const FinishedEventID = "test-finished"
+
+// testStartEventID is the ID of the event of the execution.Scheduler that all
+// the instances reach when they start executing the scenarios.
+const testStartEventID = "test-start"
+
+// DefaultStatusInterval is how often the agent instances send
+// their execution status to the coordinator.
+const DefaultStatusInterval = 5 * time.Second
+
+// NewInstanceStatus returns the current execution status of the instance,
+// with the error that the test run has finished with, if any, and the reasons
+// the script marked the test run as failed for.
+func NewInstanceStatus(state *lib.ExecutionState, runErr error) *InstanceStatus {
+	st := &InstanceStatus{
+		Status:                state.GetCurrentExecutionStatus().String(),
+		Vus:                   state.GetCurrentlyActiveVUsCount(),
+		FullIterations:        state.GetFullIterationCount(),
+		InterruptedIterations: state.GetPartialIterationCount(),
+		FailureReasons:        state.GetFailureReasons(),
+	}
+	if runErr != nil {
+		st.Error = runErr.Error()
+	}
+	return st
+}
+
+// SendStatus sends the execution status of the instance to the coordinator.
+func (ac *AgentController) SendStatus(st *InstanceStatus) error {
+	return ac.send(&AgentMessage{
+		InstanceId: ac.instanceID,
+		Message:    &AgentMessage_Status{Status: st},
+	})
+}
+
+// StreamStatus sends the execution status of the instance to the coordinator
+// at the provided interval, until the context is done or the connection with
+// the coordinator is closed.
+func (ac *AgentController) StreamStatus(ctx context.Context, state *lib.ExecutionState, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := ac.SendStatus(NewInstanceStatus(state, nil)); err != nil {
+			ac.logger.WithError(err).Debug("Unable to send the execution status")
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ac.stop:
+			return
+		case <-ac.done:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package execution
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// abortDrainKey is the key used to store the abort drain signal in the context
+// of the executors, see GetAbortDrainSignal().
+type abortDrainKey struct{}
+
+// detachedContext has the values of its parent context, but not its deadline
+// and cancellation.
+type detachedContext struct {
+	parent context.Context //nolint:containedctx
+}
+
+func (dc detachedContext) Deadline() (time.Time, bool)       { return time.Time{}, false }
+func (dc detachedContext) Done() <-chan struct{}             { return nil }
+func (dc detachedContext) Err() error                        { return nil }
+func (dc detachedContext) Value(key interface{}) interface{} { return dc.parent.Value(key) }
+
+// newDrainContext returns a context for the executors that, unlike the test run
+// context it's derived from, isn't cancelled as soon as the test run is
+// aborted. The executors only stop starting new iterations then, and the
+// in-flight ones can finish. The returned context is cancelled after the grace
+// period, if there is one, or when the returned cancel function is called.
+func newDrainContext(
+	runCtx context.Context, logger logrus.FieldLogger, gracePeriod time.Duration, hasGracePeriod bool,
+) (context.Context, context.CancelFunc) {
+	aborted := runCtx.Done()
+	ctx, cancel := context.WithCancel(context.WithValue(detachedContext{runCtx}, abortDrainKey{}, aborted))
+	go func() {
+		select {
+		case <-aborted:
+		case <-ctx.Done():
+			return
+		}
+		if !hasGracePeriod {
+			logger.Info("The test run was aborted, waiting for the in-flight iterations to finish...")
+			return
+		}
+
+		logger.Infof("The test run was aborted, waiting up to %s for the in-flight iterations to finish...", gracePeriod)
+		timer := time.NewTimer(gracePeriod)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			logger.Debug("The abort grace period is over, interrupting the remaining iterations...")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// GetAbortDrainSignal returns a channel that's closed when the test run is
+// aborted and its in-flight iterations are drained, i.e. when the executors
+// should stop starting new iterations, even though their context isn't done
+// yet. It returns nil if the in-flight iterations are interrupted as soon as
+// the test run is aborted.
+func GetAbortDrainSignal(ctx context.Context) <-chan struct{} {
+	signal, _ := ctx.Value(abortDrainKey{}).(<-chan struct{})
+	return signal
+}
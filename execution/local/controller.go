@@ -0,0 +1,40 @@
+// Package local implements the execution.Controller interface for local
+// (i.e. single-instance) k6 execution.
+package local
+
+import "context"
+
+// Controller "controls" local tests. It doesn't actually do anything, it just
+// implements the execution.Controller interface with no-op operations. The
+// methods don't do anything because local tests have only a single instance.
+//
+// However, for test suites (https://github.com/grafana/k6/issues/1342) in the
+// future, we will probably need to actually implement some of these methods and
+// introduce simple synchronization primitives even for a single machine...
+type Controller struct{}
+
+// NewController creates a new local execution Controller.
+func NewController() *Controller {
+	return &Controller{}
+}
+
+// GetOrCreateData immediately calls the given callback and returns its results.
+func (c *Controller) GetOrCreateData(_ string, callback func() ([]byte, error)) ([]byte, error) {
+	return callback()
+}
+
+// Signal is a no-op, it immediately returns nil.
+func (c *Controller) Signal(_ string) error {
+	return nil
+}
+
+// SignalError is a no-op, it immediately returns nil, since there
+// are no other instances that can receive the error.
+func (c *Controller) SignalError(_ string, _ error) error {
+	return nil
+}
+
+// Wait returns a no-op callback that immediately returns nil.
+func (c *Controller) Wait(_ context.Context, _ string) func() error {
+	return func() error { return nil }
+}
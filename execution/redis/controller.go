@@ -0,0 +1,299 @@
+// Package redis implements the execution.Controller interface on top of Redis,
+// so multiple k6 instances can be synchronized as a single logical test
+// without a dedicated coordinator, only by sharing a Redis server.
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	dataField  = "data"
+	errorField = "error"
+
+	// DefaultKeyTTL is the default expiration of the keys of a test.
+	DefaultKeyTTL = 24 * time.Hour
+	// DefaultWaitTimeout is the default max time to wait for the other instances.
+	DefaultWaitTimeout = 10 * time.Minute
+)
+
+// Config is the configuration of a Redis Controller.
+type Config struct {
+	// KeyPrefix is prepended to all the keys and channels of the test,
+	// it has to be unique for each test run.
+	KeyPrefix string
+	// InstanceCount is the number of instances executing the test.
+	InstanceCount int64
+	// KeyTTL is the expiration of all the keys of the test, so they are
+	// cleaned up even when some of its instances are lost.
+	KeyTTL time.Duration
+	// WaitTimeout is the max time to wait for the other instances to reach an
+	// event or to create a data chunk. It's the expiration of the data locks
+	// too, so an instance lost while creating a data chunk doesn't hold it.
+	WaitTimeout time.Duration
+}
+
+// Controller implements the execution.Controller interface with Redis:
+//   - the data chunks are stored in hashes, a lock key makes sure only a single
+//     instance creates each of them, and a pub/sub channel notifies the others;
+//   - the events are counters incremented by every instance that signals them,
+//     the instance that makes a counter reach the number of instances publishes
+//     a notification on the event's pub/sub channel;
+//   - the first error signaled for an event is stored in its own key, and it's
+//     published on the same pub/sub channel.
+//
+// All the keys and channels are prefixed with the configured key prefix, so
+// multiple tests can share the same Redis server by using distinct prefixes.
+// All the keys expire after the configured TTL.
+type Controller struct {
+	ctx    context.Context //nolint:containedctx
+	client goredis.UniversalClient
+	conf   Config
+	logger logrus.FieldLogger
+}
+
+// NewController returns a new Redis Controller with the provided config, the
+// zero TTL and timeout are replaced with the default ones. The commands are
+// executed with the provided context, so all the pending operations are
+// aborted when it's done.
+func NewController(
+	ctx context.Context, client goredis.UniversalClient, conf Config, logger logrus.FieldLogger,
+) (*Controller, error) {
+	if conf.InstanceCount <= 0 {
+		return nil, errors.New("the number of instances must be greater than zero")
+	}
+	if conf.KeyPrefix == "" {
+		return nil, errors.New("the key prefix can't be empty")
+	}
+	if conf.KeyTTL < 0 || conf.WaitTimeout < 0 {
+		return nil, errors.New("the key TTL and the wait timeout can't be negative")
+	}
+	if conf.KeyTTL == 0 {
+		conf.KeyTTL = DefaultKeyTTL
+	}
+	if conf.WaitTimeout == 0 {
+		conf.WaitTimeout = DefaultWaitTimeout
+	}
+
+	return &Controller{
+		ctx:    ctx,
+		client: client,
+		conf:   conf,
+		logger: logger.WithField("component", "redis-controller"),
+	}, nil
+}
+
+func (c *Controller) dataKey(id string) string {
+	return c.conf.KeyPrefix + ":data:" + id
+}
+
+func (c *Controller) dataLockKey(id string) string {
+	return c.conf.KeyPrefix + ":data-lock:" + id
+}
+
+func (c *Controller) eventKey(eventID string) string {
+	return c.conf.KeyPrefix + ":event:" + eventID
+}
+
+func (c *Controller) eventErrorKey(eventID string) string {
+	return c.conf.KeyPrefix + ":event-error:" + eventID
+}
+
+// subscribe subscribes to the provided channel and waits for the
+// confirmation, so no message published after it returns can be missed.
+func (c *Controller) subscribe(ctx context.Context, channel string) (*goredis.PubSub, error) {
+	sub := c.client.Subscribe(ctx, channel)
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return nil, fmt.Errorf("unable to subscribe to the Redis channel '%s': %w", channel, err)
+	}
+	return sub, nil
+}
+
+// waitForMessage blocks until a message is published on the channel of the
+// provided subscription, or until the given or the controller's context is
+// done, or until the wait timeout expires.
+func (c *Controller) waitForMessage(ctx context.Context, sub *goredis.PubSub, what string) error {
+	timer := time.NewTimer(c.conf.WaitTimeout)
+	defer timer.Stop()
+
+	select {
+	case _, ok := <-sub.Channel():
+		if !ok {
+			return errors.New("the Redis subscription was closed")
+		}
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("timed out after %s while waiting for %s", c.conf.WaitTimeout, what)
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.ctx.Done():
+		return c.ctx.Err()
+	}
+}
+
+// expire sets the TTL of the provided key.
+func (c *Controller) expire(ctx context.Context, key string) error {
+	if err := c.client.Expire(ctx, key, c.conf.KeyTTL).Err(); err != nil {
+		return fmt.Errorf("unable to set the expiration of the Redis key '%s': %w", key, err)
+	}
+	return nil
+}
+
+// getData returns the stored data chunk with the provided ID, if it exists.
+func (c *Controller) getData(id string) (data []byte, found bool, err error) {
+	fields, err := c.client.HGetAll(c.ctx, c.dataKey(id)).Result()
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to get the data '%s' from Redis: %w", id, err)
+	}
+	if len(fields) == 0 {
+		return nil, false, nil
+	}
+	if errMsg := fields[errorField]; errMsg != "" {
+		return nil, true, errors.New(errMsg)
+	}
+	return []byte(fields[dataField]), true, nil
+}
+
+// GetOrCreateData implements the execution.Controller interface. Only the
+// instance that acquires the data's lock calls the callback, all the others
+// wait for its result to be stored and then return it.
+func (c *Controller) GetOrCreateData(id string, callback func() ([]byte, error)) ([]byte, error) {
+	// the subscription happens before checking for the data, otherwise
+	// the notification could be published in between and missed
+	sub, err := c.subscribe(c.ctx, c.dataKey(id))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = sub.Close() }()
+
+	if data, found, err := c.getData(id); found || err != nil {
+		return data, err
+	}
+
+	locked, err := c.client.SetNX(c.ctx, c.dataLockKey(id), 1, c.conf.WaitTimeout).Result()
+	if err != nil {
+		return nil, fmt.Errorf("unable to acquire the lock for the data '%s' from Redis: %w", id, err)
+	}
+
+	if !locked {
+		c.logger.Debugf("Waiting for another instance to create the data '%s'", id)
+		if err := c.waitForMessage(c.ctx, sub, fmt.Sprintf("another instance to create the data '%s'", id)); err != nil {
+			return nil, err
+		}
+		data, found, err := c.getData(id)
+		if !found && err == nil {
+			err = fmt.Errorf("the data '%s' wasn't stored in Redis", id)
+		}
+		return data, err
+	}
+
+	c.logger.Debugf("Creating the data '%s'", id)
+	data, err := callback()
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+	}
+
+	if setErr := c.client.HSet(c.ctx, c.dataKey(id), dataField, data, errorField, errMsg).Err(); setErr != nil {
+		return nil, fmt.Errorf("unable to store the data '%s' in Redis: %w", id, setErr)
+	}
+	if expErr := c.expire(c.ctx, c.dataKey(id)); expErr != nil {
+		return nil, expErr
+	}
+	if pubErr := c.client.Publish(c.ctx, c.dataKey(id), "").Err(); pubErr != nil {
+		return nil, fmt.Errorf("unable to publish the data '%s' in Redis: %w", id, pubErr)
+	}
+
+	return data, err
+}
+
+// Signal implements the execution.Controller interface, it increments the
+// event's counter and, if it's the last instance to reach the event,
+// it notifies all the other ones.
+func (c *Controller) Signal(eventID string) error {
+	count, err := c.client.Incr(c.ctx, c.eventKey(eventID)).Result()
+	if err != nil {
+		return fmt.Errorf("unable to signal the event '%s' in Redis: %w", eventID, err)
+	}
+	if err := c.expire(c.ctx, c.eventKey(eventID)); err != nil {
+		return err
+	}
+	c.logger.Debugf("Reached event '%s' (%d of %d)", eventID, count, c.conf.InstanceCount)
+	if count != c.conf.InstanceCount {
+		return nil
+	}
+
+	if err := c.client.Publish(c.ctx, c.eventKey(eventID), "").Err(); err != nil {
+		return fmt.Errorf("unable to publish the event '%s' in Redis: %w", eventID, err)
+	}
+	return nil
+}
+
+// SignalError implements the execution.Controller interface, it stores the
+// error for the event, unless another instance already did, and it notifies
+// all the instances that are waiting for the event.
+func (c *Controller) SignalError(eventID string, err error) error {
+	stored, setErr := c.client.SetNX(c.ctx, c.eventErrorKey(eventID), err.Error(), c.conf.KeyTTL).Result()
+	if setErr != nil {
+		return fmt.Errorf("unable to signal an error for the event '%s' in Redis: %w", eventID, setErr)
+	}
+	if !stored {
+		// only the first error is propagated
+		return nil
+	}
+
+	if pubErr := c.client.Publish(c.ctx, c.eventKey(eventID), "").Err(); pubErr != nil {
+		return fmt.Errorf("unable to publish the event '%s' in Redis: %w", eventID, pubErr)
+	}
+	return nil
+}
+
+// getEventError returns the error stored for the event, if there is one.
+func (c *Controller) getEventError(ctx context.Context, eventID string) error {
+	errMsg, err := c.client.Get(ctx, c.eventErrorKey(eventID)).Result()
+	if errors.Is(err, goredis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to get the error for the event '%s' from Redis: %w", eventID, err)
+	}
+	return errors.New(errMsg)
+}
+
+// Wait implements the execution.Controller interface. It subscribes to the
+// event's channel before returning, and the returned callback blocks until
+// all the instances have signaled the event, or until one of them signals an
+// error for it, or until the context is done, or until the wait timeout expires.
+func (c *Controller) Wait(ctx context.Context, eventID string) func() error {
+	sub, err := c.subscribe(ctx, c.eventKey(eventID))
+	if err != nil {
+		return func() error { return err }
+	}
+
+	return func() error {
+		defer func() { _ = sub.Close() }()
+
+		if err := c.getEventError(ctx, eventID); err != nil {
+			return err
+		}
+		count, err := c.client.Get(ctx, c.eventKey(eventID)).Int64()
+		if err != nil && !errors.Is(err, goredis.Nil) {
+			return fmt.Errorf("unable to get the event '%s' from Redis: %w", eventID, err)
+		}
+		if count >= c.conf.InstanceCount {
+			return nil
+		}
+
+		if err := c.waitForMessage(ctx, sub, fmt.Sprintf("the event '%s'", eventID)); err != nil {
+			return err
+		}
+		return c.getEventError(ctx, eventID)
+	}
+}
@@ -0,0 +1,266 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/lib/testutils"
+)
+
+var _ execution.Controller = &Controller{}
+
+// newTestControllers returns a controller for each of the instances of a
+// test, every one of them with its own client for the same stub server.
+func newTestControllers(t *testing.T, server *stubServer, instanceCount int64) []*Controller {
+	t.Helper()
+	return newTestControllersWithConfig(t, server, Config{KeyPrefix: "k6-test", InstanceCount: instanceCount})
+}
+
+func newTestControllersWithConfig(t *testing.T, server *stubServer, conf Config) []*Controller {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	controllers := make([]*Controller, conf.InstanceCount)
+	for i := range controllers {
+		client := goredis.NewClient(&goredis.Options{Addr: server.addr()})
+		t.Cleanup(func() { _ = client.Close() })
+
+		var err error
+		controllers[i], err = NewController(ctx, client, conf, testutils.NewLogger(t))
+		require.NoError(t, err)
+	}
+
+	return controllers
+}
+
+func TestNewControllerInvalidArguments(t *testing.T) {
+	t.Parallel()
+
+	client := goredis.NewClient(&goredis.Options{})
+	t.Cleanup(func() { _ = client.Close() })
+	logger := testutils.NewLogger(t)
+
+	_, err := NewController(context.Background(), client, Config{KeyPrefix: "k6-test"}, logger)
+	require.ErrorContains(t, err, "number of instances")
+	_, err = NewController(context.Background(), client, Config{InstanceCount: 1}, logger)
+	require.ErrorContains(t, err, "key prefix")
+	_, err = NewController(context.Background(), client, Config{
+		KeyPrefix: "k6-test", InstanceCount: 1, WaitTimeout: -time.Second,
+	}, logger)
+	require.ErrorContains(t, err, "can't be negative")
+}
+
+func TestControllerSignalAndWait(t *testing.T) {
+	t.Parallel()
+
+	controllers := newTestControllers(t, newStubServer(t), 3)
+
+	var reached int64
+	var wg sync.WaitGroup
+	for i, c := range controllers {
+		i, c := i, c
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// the last instance is the slowest one to reach the barrier
+			if i == len(controllers)-1 {
+				time.Sleep(100 * time.Millisecond)
+				assert.Equal(t, int64(0), atomic.LoadInt64(&reached))
+			}
+			assert.NoError(t, execution.SignalAndWait(context.Background(), c, "test-start"))
+			atomic.AddInt64(&reached, 1)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(len(controllers)), atomic.LoadInt64(&reached))
+
+	// the event has already been reached, so waiting for it doesn't block
+	require.NoError(t, controllers[0].Wait(context.Background(), "test-start")())
+}
+
+func TestControllerGetOrCreateData(t *testing.T) {
+	t.Parallel()
+
+	server := newStubServer(t)
+	controllers := newTestControllers(t, server, 4)
+
+	var calls int64
+	results := make([][]byte, len(controllers))
+	var wg sync.WaitGroup
+	for i, c := range controllers {
+		i, c := i, c
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := c.GetOrCreateData("setup", func() ([]byte, error) {
+				atomic.AddInt64(&calls, 1)
+				time.Sleep(50 * time.Millisecond)
+				return []byte(`{"token":"secret"}`), nil
+			})
+			assert.NoError(t, err)
+			results[i] = data
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+	assert.Equal(t, 1, server.commandCount("hset"))
+	for _, data := range results {
+		assert.Equal(t, []byte(`{"token":"secret"}`), data)
+	}
+
+	data, err := controllers[0].GetOrCreateData("setup", func() ([]byte, error) {
+		return nil, errors.New("unexpected call")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"token":"secret"}`), data)
+}
+
+func TestControllerGetOrCreateDataError(t *testing.T) {
+	t.Parallel()
+
+	controllers := newTestControllers(t, newStubServer(t), 2)
+
+	_, err := controllers[0].GetOrCreateData("setup", func() ([]byte, error) {
+		return nil, errors.New("setup failed")
+	})
+	require.ErrorContains(t, err, "setup failed")
+
+	_, err = controllers[1].GetOrCreateData("setup", func() ([]byte, error) {
+		return nil, errors.New("unexpected call")
+	})
+	require.ErrorContains(t, err, "setup failed")
+}
+
+func TestControllerKeyPrefixes(t *testing.T) {
+	t.Parallel()
+
+	server := newStubServer(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	logger := testutils.NewLogger(t)
+
+	first, err := NewController(context.Background(), client, Config{KeyPrefix: "first", InstanceCount: 1}, logger)
+	require.NoError(t, err)
+	second, err := NewController(context.Background(), client, Config{KeyPrefix: "second", InstanceCount: 1}, logger)
+	require.NoError(t, err)
+
+	dataA, err := first.GetOrCreateData("setup", func() ([]byte, error) { return []byte("a"), nil })
+	require.NoError(t, err)
+	dataB, err := second.GetOrCreateData("setup", func() ([]byte, error) { return []byte("b"), nil })
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("a"), dataA)
+	assert.Equal(t, []byte("b"), dataB)
+}
+
+func TestControllerWaitCanceled(t *testing.T) {
+	t.Parallel()
+
+	server := newStubServer(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	c, err := NewController(context.Background(), client, Config{KeyPrefix: "k6-test", InstanceCount: 2}, testutils.NewLogger(t))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, execution.SignalAndWait(ctx, c, "never"), context.DeadlineExceeded)
+}
+
+func TestControllerSignalError(t *testing.T) {
+	t.Parallel()
+
+	controllers := newTestControllers(t, newStubServer(t), 3)
+
+	var wg sync.WaitGroup
+	for _, c := range controllers[1:] {
+		c := c
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := execution.SignalAndWait(context.Background(), c, "test-start")
+			assert.ErrorContains(t, err, "setup failed")
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, controllers[0].SignalError("test-start", errors.New("setup failed")))
+	// only the first error is propagated
+	require.NoError(t, controllers[1].SignalError("test-start", errors.New("another error")))
+	wg.Wait()
+
+	// the error is received by the instances that start waiting later too
+	require.ErrorContains(t, controllers[0].Wait(context.Background(), "test-start")(), "setup failed")
+}
+
+func TestControllerKeyExpiration(t *testing.T) {
+	t.Parallel()
+
+	server := newStubServer(t)
+	controllers := newTestControllersWithConfig(t, server, Config{
+		KeyPrefix: "k6-test", InstanceCount: 2, KeyTTL: time.Hour, WaitTimeout: time.Minute,
+	})
+
+	_, err := controllers[0].GetOrCreateData("setup", func() ([]byte, error) { return []byte("data"), nil })
+	require.NoError(t, err)
+	require.NoError(t, controllers[0].Signal("test-start"))
+	require.NoError(t, controllers[0].SignalError("test-end", errors.New("failed")))
+
+	assertTTL := func(key string, expected time.Duration) {
+		ttl := server.ttl(key)
+		assert.True(t, ttl > expected-time.Minute/2 && ttl <= expected, "unexpected TTL %s for '%s'", ttl, key)
+	}
+	assertTTL("k6-test:data:setup", time.Hour)
+	assertTTL("k6-test:data-lock:setup", time.Minute)
+	assertTTL("k6-test:event:test-start", time.Hour)
+	assertTTL("k6-test:event-error:test-end", time.Hour)
+}
+
+func TestControllerWaitTimeout(t *testing.T) {
+	t.Parallel()
+
+	controllers := newTestControllersWithConfig(t, newStubServer(t), Config{
+		KeyPrefix: "k6-test", InstanceCount: 2, WaitTimeout: 50 * time.Millisecond,
+	})
+
+	err := execution.SignalAndWait(context.Background(), controllers[0], "test-start")
+	require.EqualError(t, err, "timed out after 50ms while waiting for the event 'test-start'")
+}
+
+func TestControllerGetOrCreateDataLockExpiration(t *testing.T) {
+	t.Parallel()
+
+	server := newStubServer(t)
+	controllers := newTestControllersWithConfig(t, server, Config{
+		KeyPrefix: "k6-test", InstanceCount: 2, WaitTimeout: 100 * time.Millisecond,
+	})
+
+	// an instance acquires the lock and it's lost before creating the data
+	client := goredis.NewClient(&goredis.Options{Addr: server.addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	require.NoError(t, client.SetNX(context.Background(), "k6-test:data-lock:setup", 1, 100*time.Millisecond).Err())
+
+	_, err := controllers[0].GetOrCreateData("setup", func() ([]byte, error) {
+		return nil, errors.New("unexpected call")
+	})
+	require.EqualError(t, err,
+		"timed out after 100ms while waiting for another instance to create the data 'setup'")
+
+	// the lock has expired, so another instance can create the data
+	data, err := controllers[1].GetOrCreateData("setup", func() ([]byte, error) { return []byte("data"), nil })
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+}
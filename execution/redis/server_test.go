@@ -0,0 +1,282 @@
+package redis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// stubServer is a minimal in-memory Redis server, supporting only the
+// commands used by the Controller, so it can be tested without Redis.
+type stubServer struct {
+	listener net.Listener
+
+	mx          sync.Mutex
+	strings     map[string]string
+	hashes      map[string]map[string]string
+	expirations map[string]time.Time
+	subscribers map[string]map[*stubConn]struct{}
+	commands    map[string]int
+}
+
+type stubConn struct {
+	mx     sync.Mutex
+	writer *bufio.Writer
+}
+
+func (c *stubConn) write(reply string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	_, _ = c.writer.WriteString(reply)
+	_ = c.writer.Flush()
+}
+
+func newStubServer(t *testing.T) *stubServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &stubServer{
+		listener:    listener,
+		strings:     make(map[string]string),
+		hashes:      make(map[string]map[string]string),
+		expirations: make(map[string]time.Time),
+		subscribers: make(map[string]map[*stubConn]struct{}),
+		commands:    make(map[string]int),
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *stubServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *stubServer) commandCount(name string) int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.commands[name]
+}
+
+// ttl returns the remaining time before the provided key expires,
+// or zero if it doesn't have an expiration.
+func (s *stubServer) ttl(key string) time.Duration {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if expiration, ok := s.expirations[key]; ok {
+		return time.Until(expiration)
+	}
+	return 0
+}
+
+// expireKey removes the provided key if it has expired.
+func (s *stubServer) expireKey(key string) {
+	if expiration, ok := s.expirations[key]; ok && !time.Now().Before(expiration) {
+		delete(s.strings, key)
+		delete(s.hashes, key)
+		delete(s.expirations, key)
+	}
+}
+
+func (s *stubServer) serve(netConn net.Conn) {
+	conn := &stubConn{writer: bufio.NewWriter(netConn)}
+	defer func() {
+		_ = netConn.Close()
+		s.mx.Lock()
+		for _, subs := range s.subscribers {
+			delete(subs, conn)
+		}
+		s.mx.Unlock()
+	}()
+
+	reader := bufio.NewReader(netConn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		if reply := s.handle(conn, args); reply != "" {
+			conn.write(reply)
+		}
+	}
+}
+
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(line, "\r\n"), nil
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected command line %q", line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		line, err := readLine(reader)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "$") {
+			return nil, fmt.Errorf("unexpected argument line %q", line)
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	if len(args) == 0 {
+		return nil, errors.New("empty command")
+	}
+	return args, nil
+}
+
+func bulkString(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func array(items ...string) string {
+	return fmt.Sprintf("*%d\r\n%s", len(items), strings.Join(items, ""))
+}
+
+func integer(n int) string {
+	return fmt.Sprintf(":%d\r\n", n)
+}
+
+func (s *stubServer) handle(conn *stubConn, args []string) string {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	cmd := strings.ToLower(args[0])
+	s.commands[cmd]++
+	if len(args) > 1 {
+		s.expireKey(args[1])
+	}
+
+	switch cmd {
+	case "ping":
+		return "+PONG\r\n"
+	case "get":
+		v, ok := s.strings[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return bulkString(v)
+	case "setnx":
+		if _, ok := s.strings[args[1]]; ok {
+			return integer(0)
+		}
+		s.strings[args[1]] = args[2]
+		return integer(1)
+	case "set":
+		// only the SET key value PX|EX ttl NX form is supported
+		if len(args) != 6 || strings.ToLower(args[5]) != "nx" {
+			return "-ERR syntax error\r\n"
+		}
+		ttl, err := strconv.Atoi(args[4])
+		if err != nil {
+			return "-ERR value is not an integer or out of range\r\n"
+		}
+		unit := time.Millisecond
+		if strings.ToLower(args[3]) == "ex" {
+			unit = time.Second
+		}
+		if _, ok := s.strings[args[1]]; ok {
+			return "$-1\r\n"
+		}
+		s.strings[args[1]] = args[2]
+		s.expirations[args[1]] = time.Now().Add(time.Duration(ttl) * unit)
+		return "+OK\r\n"
+	case "expire":
+		ttl, err := strconv.Atoi(args[2])
+		if err != nil {
+			return "-ERR value is not an integer or out of range\r\n"
+		}
+		_, isString := s.strings[args[1]]
+		_, isHash := s.hashes[args[1]]
+		if !isString && !isHash {
+			return integer(0)
+		}
+		s.expirations[args[1]] = time.Now().Add(time.Duration(ttl) * time.Second)
+		return integer(1)
+	case "incr":
+		n, _ := strconv.Atoi(s.strings[args[1]])
+		n++
+		s.strings[args[1]] = strconv.Itoa(n)
+		return integer(n)
+	case "hset":
+		hash, ok := s.hashes[args[1]]
+		if !ok {
+			hash = make(map[string]string)
+			s.hashes[args[1]] = hash
+		}
+		for i := 2; i+1 < len(args); i += 2 {
+			hash[args[i]] = args[i+1]
+		}
+		return integer((len(args) - 2) / 2)
+	case "hgetall":
+		items := make([]string, 0, 2*len(s.hashes[args[1]]))
+		for k, v := range s.hashes[args[1]] {
+			items = append(items, bulkString(k), bulkString(v))
+		}
+		return array(items...)
+	case "publish":
+		subs := s.subscribers[args[1]]
+		for sub := range subs {
+			sub.write(array(bulkString("message"), bulkString(args[1]), bulkString(args[2])))
+		}
+		return integer(len(subs))
+	case "subscribe":
+		replies := make([]string, 0, len(args)-1)
+		for i, channel := range args[1:] {
+			if s.subscribers[channel] == nil {
+				s.subscribers[channel] = make(map[*stubConn]struct{})
+			}
+			s.subscribers[channel][conn] = struct{}{}
+			replies = append(replies, array(bulkString("subscribe"), bulkString(channel), integer(i+1)))
+		}
+		// the confirmation is written while holding the lock, so it
+		// can't be preceded by a message published on the channel
+		conn.write(strings.Join(replies, ""))
+		return ""
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", cmd)
+	}
+}
@@ -2,8 +2,10 @@ package execution
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -27,13 +29,27 @@ type Scheduler struct {
 	maxDuration     time.Duration // cached value derived from the execution plan
 	maxPossibleVUs  uint64        // cached value derived from the execution plan
 	state           *lib.ExecutionState
+	controller      Controller
+
+	dormantScenariosMx sync.Mutex
+	dormantScenarios   map[string]*dormantScenario
+}
+
+// dormantScenario tracks whether a dormant scenario has been started manually.
+type dormantScenario struct {
+	start   chan struct{}
+	started bool
+	expired bool // the other scenarios finished before it was started
 }
 
 // NewScheduler creates and returns a new Scheduler instance, without
 // initializing it beyond the bare minimum. Specifically, it creates the needed
 // executor instances and a lot of state placeholders, but it doesn't initialize
 // the executors and it doesn't initialize or run VUs.
-func NewScheduler(trs *lib.TestRunState) (*Scheduler, error) {
+//
+// The controller synchronizes the test life-cycle with the other
+// instances of a distributed test, if there are any.
+func NewScheduler(trs *lib.TestRunState, controller Controller) (*Scheduler, error) {
 	options := trs.Options
 	et, err := lib.NewExecutionTuple(options.ExecutionSegment, options.ExecutionSegmentSequence)
 	if err != nil {
@@ -48,6 +64,7 @@ func NewScheduler(trs *lib.TestRunState) (*Scheduler, error) {
 
 	executorConfigs := options.Scenarios.GetSortedConfigs()
 	executors := make([]lib.Executor, 0, len(executorConfigs))
+	dormantScenarios := make(map[string]*dormantScenario)
 	// Only take executors which have work.
 	for _, sc := range executorConfigs {
 		if !sc.HasWork(et) {
@@ -65,6 +82,9 @@ func NewScheduler(trs *lib.TestRunState) (*Scheduler, error) {
 			return nil, err
 		}
 		executors = append(executors, s)
+		if sc.IsDormant() {
+			dormantScenarios[sc.GetName()] = &dormantScenario{start: make(chan struct{})}
+		}
 	}
 
 	if options.Paused.Bool {
@@ -81,6 +101,9 @@ func NewScheduler(trs *lib.TestRunState) (*Scheduler, error) {
 		maxDuration:     maxDuration,
 		maxPossibleVUs:  maxPossibleVUs,
 		state:           executionState,
+		controller:      controller,
+
+		dormantScenarios: dormantScenarios,
 	}, nil
 }
 
@@ -193,12 +216,17 @@ func (e *Scheduler) initVUsConcurrently(
 	return doneInits
 }
 
+// emitVUsAndVUsMax periodically emits the vus and vus_max gauges, along with
+// the gauges of the HTTP connections of all the VUs, when they are tracked.
 func (e *Scheduler) emitVUsAndVUsMax(ctx context.Context, out chan<- metrics.SampleContainer) func() {
 	e.state.Test.Logger.Debug("Starting emission of VUs and VUsMax metrics...")
 	tags := e.state.Test.RunTags
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
 
+	emitScenarioVUs := e.state.Test.Options.SystemTags.Has(metrics.TagScenario)
+	emitConnsHost := e.state.Test.Options.SystemTags.Has(metrics.TagHost)
+
 	emitMetrics := func() {
 		t := time.Now()
 		samples := metrics.ConnectedSamples{
@@ -222,6 +250,22 @@ func (e *Scheduler) emitVUsAndVUsMax(ctx context.Context, out chan<- metrics.Sam
 			Tags: tags,
 			Time: t,
 		}
+		if connStats := e.state.Test.ConnStats; connStats != nil {
+			samples.Samples = append(samples.Samples,
+				connStats.GetSamples(e.state.Test.BuiltinMetrics, tags, emitConnsHost, t)...)
+		}
+		if emitScenarioVUs {
+			for scenario, activeVUs := range e.state.GetScenarioActiveVUsCounts() {
+				samples.Samples = append(samples.Samples, metrics.Sample{
+					TimeSeries: metrics.TimeSeries{
+						Metric: e.state.Test.BuiltinMetrics.ScenarioVUs,
+						Tags:   tags.With(metrics.TagScenario.String(), scenario),
+					},
+					Time:  t,
+					Value: float64(activeVUs),
+				})
+			}
+		}
 		metrics.PushIfNotDone(ctx, out, samples)
 	}
 
@@ -322,11 +366,15 @@ func (e *Scheduler) initVUsAndExecutors(ctx context.Context, samplesOut chan<- m
 }
 
 // runExecutor gets called by the public Run() method once per configured
-// executor, each time in a new goroutine. It is responsible for waiting out the
-// configured startTime for the specific executor and then running its Run()
-// method.
+// executor, each time in a new goroutine. It is responsible for waiting for the
+// scenarios in the startAfter of the specific executor to finish, or for it to
+// be started manually if it's dormant, then waiting out its configured
+// startTime and then running its Run() method. The finished map contains
+// channels that are closed when the executors with the respective names are
+// done, and activeDone is closed when all of the not dormant ones are.
 func (e *Scheduler) runExecutor(
 	runCtx context.Context, runResults chan<- error, engineOut chan<- metrics.SampleContainer, executor lib.Executor,
+	finished map[string]chan struct{}, activeDone <-chan struct{},
 ) {
 	executorConfig := executor.GetConfig()
 	executorStartTime := executorConfig.GetStartTime()
@@ -336,6 +384,44 @@ func (e *Scheduler) runExecutor(
 		"startTime": executorStartTime,
 	})
 	executorProgress := executor.GetProgress()
+	defer close(finished[executorConfig.GetName()])
+
+	// Check if we have to wait for other scenarios to finish first
+	if startAfter := executorConfig.GetStartAfter(); len(startAfter) > 0 {
+		executorProgress.Modify(
+			pb.WithStatus(pb.Waiting),
+			pb.WithConstProgress(0, "waiting for "+strings.Join(startAfter, ", ")),
+		)
+
+		executorLogger.Debugf("Waiting for the scenarios %v to finish...", startAfter)
+		for _, name := range startAfter {
+			done, ok := finished[name]
+			if !ok {
+				continue // the scenario doesn't have any work in this instance
+			}
+			select {
+			case <-runCtx.Done():
+				runResults <- nil // no error since executor hasn't started yet
+				return
+			case <-done:
+				// continue
+			}
+		}
+	}
+
+	// Check if we have to wait for the executor to be started manually
+	if executorConfig.IsDormant() {
+		executorProgress.Modify(
+			pb.WithStatus(pb.Waiting),
+			pb.WithConstProgress(0, "dormant"),
+		)
+
+		executorLogger.Debugf("Waiting for the dormant executor to be started...")
+		if !e.waitForDormantStart(runCtx, executorConfig.GetName(), activeDone) {
+			runResults <- nil // no error since executor hasn't started yet
+			return
+		}
+	}
 
 	// Check if we have to wait before starting the actual executor execution
 	if executorStartTime > 0 {
@@ -363,7 +449,12 @@ func (e *Scheduler) runExecutor(
 		pb.WithConstProgress(0, "started"),
 	)
 	executorLogger.Debugf("Starting executor")
-	err := executor.Run(runCtx, engineOut) // executor should handle context cancel itself
+	scenarioCtx, scenarioCancel, getAbortReason := newScenarioContext(runCtx)
+	defer scenarioCancel()
+	err := executor.Run(scenarioCtx, engineOut) // executor should handle context cancel itself
+	if reason, aborted := getAbortReason(); aborted {
+		executorLogger.Infof("The scenario was aborted by the script: %s", reason)
+	}
 	if err == nil {
 		executorLogger.Debugf("Executor finished successfully")
 	} else {
@@ -372,6 +463,47 @@ func (e *Scheduler) runExecutor(
 	runResults <- err
 }
 
+// newExecutorsRunContext returns the context in which the executors run. With
+// the default abort policy, it's cancelled as soon as the test run is aborted,
+// so the in-flight iterations are interrupted. The other policies drain them.
+func (e *Scheduler) newExecutorsRunContext(
+	runCtx context.Context, logger logrus.FieldLogger,
+) (context.Context, context.CancelFunc) {
+	options := e.state.Test.Options
+	switch options.AbortPolicy.String {
+	case lib.AbortPolicyGraceful:
+		return newDrainContext(runCtx, logger, options.AbortGracePeriod.TimeDuration(), true)
+	case lib.AbortPolicyFinish:
+		return newDrainContext(runCtx, logger, 0, false)
+	default:
+		return context.WithCancel(runCtx)
+	}
+}
+
+// waitForDormantStart waits until the dormant scenario with the provided name
+// is started manually. It returns false if the context is done or all of the
+// not dormant scenarios finish before that, i.e. the scenario shouldn't run.
+func (e *Scheduler) waitForDormantStart(ctx context.Context, name string, activeDone <-chan struct{}) bool {
+	e.dormantScenariosMx.Lock()
+	ds := e.dormantScenarios[name]
+	e.dormantScenariosMx.Unlock()
+
+	select {
+	case <-ds.start:
+		return true
+	case <-ctx.Done():
+	case <-activeDone:
+	}
+
+	e.dormantScenariosMx.Lock()
+	defer e.dormantScenariosMx.Unlock()
+	if ds.started && ctx.Err() == nil {
+		return true
+	}
+	ds.expired = true
+	return false
+}
+
 // Init concurrently initializes all of the planned VUs and then sequentially
 // initializes all of the configured executors. It also starts the measurement
 // and emission of the `vus` and `vus_max` metrics.
@@ -445,25 +577,73 @@ func (e *Scheduler) Run(globalCtx, runCtx context.Context, samplesOut chan<- met
 	// its properties in their init context executions.
 	withExecStateCtx := lib.WithExecutionState(runCtx, e.state)
 
-	// Run setup() before any executors, if it's not disabled
+	// Wait for all the instances of the test to be ready to run
+	if err := SignalAndWait(runCtx, e.controller, "test-ready-to-run-setup"); err != nil {
+		return err
+	}
+
+	// Run setup() before any executors, if it's not disabled. It's executed
+	// only by one of the instances, the others receive its setup data.
 	if !e.state.Test.Options.NoSetup.Bool {
 		e.state.SetExecutionStatus(lib.ExecutionStatusSetup)
 		e.initProgress.Modify(pb.WithConstProgress(1, "setup()"))
-		if err := e.state.Test.Runner.Setup(withExecStateCtx, samplesOut); err != nil {
-			logger.WithField("error", err).Debug("setup() aborted by error")
+		actuallyRanSetup := false
+		data, err := e.controller.GetOrCreateData("setup", func() ([]byte, error) {
+			actuallyRanSetup = true
+			if err := e.state.Test.Runner.Setup(withExecStateCtx, samplesOut); err != nil {
+				logger.WithField("error", err).Debug("setup() aborted by error")
+				return nil, err
+			}
+			return e.state.Test.Runner.GetSetupData(), nil
+		})
+		if err != nil {
 			return err
 		}
+		if !actuallyRanSetup {
+			e.state.Test.Runner.SetSetupData(data)
+		}
 	}
 	e.initProgress.Modify(pb.WithHijack(e.getRunStats))
 
+	if err := SignalAndWait(runCtx, e.controller, "test-start"); err != nil {
+		return err
+	}
+
 	// Start all executors at their particular startTime in a separate goroutine...
 	logger.Debug("Start all executors...")
 	e.state.SetExecutionStatus(lib.ExecutionStatusRunning)
 
-	executorsRunCtx, executorsRunCancel := context.WithCancel(withExecStateCtx)
+	executorsRunCtx, executorsRunCancel := e.newExecutorsRunContext(withExecStateCtx, logger)
 	defer executorsRunCancel()
+
+	// If another instance aborts the test, it signals an error for the
+	// abort event, and the executors of this instance are stopped too.
+	waitForAbort := e.controller.Wait(executorsRunCtx, AbortEventID)
+	abortErr := make(chan error, 1)
+	go func() {
+		err := waitForAbort()
+		if err != nil && executorsRunCtx.Err() == nil {
+			logger.WithError(err).Debug("Another instance has aborted the test, cancelling test run...")
+			executorsRunCancel()
+		}
+		abortErr <- err
+	}()
+
+	finished := make(map[string]chan struct{}, len(e.executors))
+	for _, exec := range e.executors {
+		finished[exec.GetConfig().GetName()] = make(chan struct{})
+	}
+	activeDone := make(chan struct{})
+	go func() {
+		for _, exec := range e.executors {
+			if !exec.GetConfig().IsDormant() {
+				<-finished[exec.GetConfig().GetName()]
+			}
+		}
+		close(activeDone)
+	}()
 	for _, exec := range e.executors {
-		go e.runExecutor(executorsRunCtx, runResults, samplesOut, exec)
+		go e.runExecutor(executorsRunCtx, runResults, samplesOut, exec, finished, activeDone)
 	}
 
 	// Wait for all executors to finish
@@ -473,19 +653,42 @@ func (e *Scheduler) Run(globalCtx, runCtx context.Context, samplesOut chan<- met
 		if err != nil && firstErr == nil {
 			logger.WithError(err).Debug("Executor returned with an error, cancelling test run...")
 			firstErr = err
+			e.signalAbort(logger, err)
 			executorsRunCancel()
 		}
 	}
+	if interruptErr := GetCancelReasonIfTestAborted(runCtx); interruptErr != nil && firstErr == nil {
+		e.signalAbort(logger, interruptErr)
+	}
+
+	executorsRunCancel()
+	if err := <-abortErr; err != nil && firstErr == nil && !errors.Is(err, context.Canceled) {
+		firstErr = fmt.Errorf("the test was aborted by another instance: %w", err)
+	}
 
-	// Run teardown() after all executors are done, if it's not disabled
+	// Wait for the executors of all the instances to be done. Like teardown(),
+	// it's done with the global context, so an aborted test still waits for
+	// the other instances before one of them runs teardown().
+	if err := SignalAndWait(globalCtx, e.controller, "test-done"); err != nil {
+		return err
+	}
+
+	// Run teardown() after all executors are done, if it's not disabled.
+	// Like setup(), it's executed only by one of the instances.
 	if !e.state.Test.Options.NoTeardown.Bool {
 		e.state.SetExecutionStatus(lib.ExecutionStatusTeardown)
 		e.initProgress.Modify(pb.WithConstProgress(1, "teardown()"))
 
 		// We run teardown() with the global context, so it isn't interrupted by
 		// thresholds or test.abort() or even Ctrl+C (unless used twice).
-		if err := e.state.Test.Runner.Teardown(globalCtx, samplesOut); err != nil {
-			logger.WithField("error", err).Debug("teardown() aborted by error")
+		_, err := e.controller.GetOrCreateData("teardown", func() ([]byte, error) {
+			if err := e.state.Test.Runner.Teardown(globalCtx, samplesOut); err != nil {
+				logger.WithField("error", err).Debug("teardown() aborted by error")
+				return nil, err
+			}
+			return nil, nil
+		})
+		if err != nil {
 			return err
 		}
 	}
@@ -493,6 +696,17 @@ func (e *Scheduler) Run(globalCtx, runCtx context.Context, samplesOut chan<- met
 	return firstErr
 }
 
+// AbortEventID is the ID of the event that's never reached by all instances,
+// it's only used for propagating the abort of the test by one of them.
+const AbortEventID = "test-abort"
+
+// signalAbort notifies the other instances that this one has aborted the test.
+func (e *Scheduler) signalAbort(logger logrus.FieldLogger, err error) {
+	if signalErr := e.controller.SignalError(AbortEventID, err); signalErr != nil {
+		logger.WithError(signalErr).Warn("Unable to notify the other instances about the test abort")
+	}
+}
+
 // SetPaused pauses the test, or start/resumes it. To check if a test is paused,
 // use GetState().IsPaused().
 //
@@ -538,3 +752,67 @@ func (e *Scheduler) SetPaused(pause bool) error {
 	}
 	return e.state.Resume()
 }
+
+// StartScenario starts the dormant scenario with the provided name. It can be
+// called at any moment before all of the other scenarios are done, and every
+// dormant scenario can be started only once.
+func (e *Scheduler) StartScenario(name string) error {
+	e.dormantScenariosMx.Lock()
+	defer e.dormantScenariosMx.Unlock()
+
+	ds, ok := e.dormantScenarios[name]
+	if !ok {
+		for _, config := range e.executorConfigs {
+			if config.GetName() != name {
+				continue
+			}
+			if !config.IsDormant() {
+				return fmt.Errorf("the scenario '%s' isn't dormant", name)
+			}
+			e.state.Test.Logger.Debugf("The dormant scenario '%s' doesn't have any work in this instance", name)
+			return nil
+		}
+		return fmt.Errorf("there is no scenario '%s'", name)
+	}
+
+	switch {
+	case ds.started:
+		return fmt.Errorf("the scenario '%s' has already been started", name)
+	case ds.expired:
+		return fmt.Errorf("the scenario '%s' can't be started anymore, the other scenarios are done", name)
+	}
+	e.state.Test.Logger.Debugf("Starting the dormant scenario '%s'", name)
+	ds.started = true
+	close(ds.start)
+	return nil
+}
+
+// UpdateExecutionTuple changes the execution segment of the running test, e.g.
+// when the instances of a distributed test redistribute their work after one
+// of them has been lost. All the executors have to implement the
+// lib.SegmentUpdatableExecutor interface, otherwise the change is rejected
+// and the test keeps running with the original execution segment.
+func (e *Scheduler) UpdateExecutionTuple(ctx context.Context, et *lib.ExecutionTuple) error {
+	if !e.state.HasStarted() {
+		return fmt.Errorf("the execution segment can't be changed before the test has started")
+	}
+
+	updatableExecutors := make([]lib.SegmentUpdatableExecutor, 0, len(e.executors))
+	for _, exec := range e.executors {
+		updatableExecutor, ok := exec.(lib.SegmentUpdatableExecutor)
+		if !ok {
+			return fmt.Errorf(
+				"the execution segment can't be changed to %s, the %s executor of the scenario '%s' doesn't support it",
+				et, exec.GetConfig().GetType(), exec.GetConfig().GetName(),
+			)
+		}
+		updatableExecutors = append(updatableExecutors, updatableExecutor)
+	}
+
+	for _, updatableExecutor := range updatableExecutors {
+		if err := updatableExecutor.UpdateExecutionTuple(ctx, et); err != nil {
+			return err
+		}
+	}
+	return nil
+}
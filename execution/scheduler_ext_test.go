@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/url"
 	"reflect"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -19,6 +20,7 @@ import (
 	"gopkg.in/guregu/null.v3"
 
 	"go.k6.io/k6/execution"
+	"go.k6.io/k6/execution/local"
 	"go.k6.io/k6/js"
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/executor"
@@ -73,7 +75,7 @@ func newTestScheduler(
 		testRunState.Logger = logger
 	}
 
-	execScheduler, err = execution.NewScheduler(testRunState)
+	execScheduler, err = execution.NewScheduler(testRunState, local.NewController())
 	require.NoError(t, err)
 
 	samples = make(chan metrics.SampleContainer, newOpts.MetricSamplesBufferSize.Int64)
@@ -141,7 +143,7 @@ func TestSchedulerRunNonDefault(t *testing.T) {
 
 			testRunState := getTestRunState(t, piState, runner.GetOptions(), runner)
 
-			execScheduler, err := execution.NewScheduler(testRunState)
+			execScheduler, err := execution.NewScheduler(testRunState, local.NewController())
 			require.NoError(t, err)
 
 			ctx, cancel := context.WithCancel(context.Background())
@@ -258,7 +260,7 @@ func TestSchedulerRunEnv(t *testing.T) {
 			require.NoError(t, err)
 
 			testRunState := getTestRunState(t, piState, runner.GetOptions(), runner)
-			execScheduler, err := execution.NewScheduler(testRunState)
+			execScheduler, err := execution.NewScheduler(testRunState, local.NewController())
 			require.NoError(t, err)
 
 			ctx, cancel := context.WithCancel(context.Background())
@@ -332,7 +334,7 @@ func TestSchedulerSystemTags(t *testing.T) {
 	})))
 
 	testRunState := getTestRunState(t, piState, runner.GetOptions(), runner)
-	execScheduler, err := execution.NewScheduler(testRunState)
+	execScheduler, err := execution.NewScheduler(testRunState, local.NewController())
 	require.NoError(t, err)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -468,7 +470,7 @@ func TestSchedulerRunCustomTags(t *testing.T) {
 			require.NoError(t, err)
 
 			testRunState := getTestRunState(t, piState, runner.GetOptions(), runner)
-			execScheduler, err := execution.NewScheduler(testRunState)
+			execScheduler, err := execution.NewScheduler(testRunState, local.NewController())
 			require.NoError(t, err)
 
 			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -637,7 +639,7 @@ func TestSchedulerRunCustomConfigNoCrossover(t *testing.T) {
 	require.NoError(t, err)
 
 	testRunState := getTestRunState(t, piState, runner.GetOptions(), runner)
-	execScheduler, err := execution.NewScheduler(testRunState)
+	execScheduler, err := execution.NewScheduler(testRunState, local.NewController())
 	require.NoError(t, err)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -941,6 +943,106 @@ func TestSchedulerEndErrors(t *testing.T) {
 	assert.Empty(t, hook.Entries)
 }
 
+// abortingController simulates a distributed test, where another instance
+// aborts the test after the given delay, if an error is provided.
+type abortingController struct {
+	*local.Controller
+	delay time.Duration
+	err   error
+
+	mx           sync.Mutex
+	signaledErrs []error
+}
+
+func (ac *abortingController) SignalError(_ string, err error) error {
+	ac.mx.Lock()
+	defer ac.mx.Unlock()
+	ac.signaledErrs = append(ac.signaledErrs, err)
+	return nil
+}
+
+func (ac *abortingController) Wait(ctx context.Context, eventID string) func() error {
+	if eventID != "test-abort" || ac.err == nil {
+		return ac.Controller.Wait(ctx, eventID)
+	}
+	return func() error {
+		select {
+		case <-time.After(ac.delay):
+			return ac.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func newAbortTestScheduler(
+	t *testing.T, controller execution.Controller,
+) (*execution.Scheduler, chan metrics.SampleContainer) {
+	t.Helper()
+
+	exec := executor.NewConstantVUsConfig("long")
+	exec.VUs = null.IntFrom(2)
+	exec.Duration = types.NullDurationFrom(10 * time.Second)
+	exec.GracefulStop = types.NullDurationFrom(0)
+
+	runner := &minirunner.MiniRunner{
+		Fn: func(ctx context.Context, _ *lib.State, _ chan<- metrics.SampleContainer) error {
+			<-ctx.Done()
+			return nil
+		},
+		Options: lib.Options{
+			Scenarios:  lib.ScenarioConfigs{exec.GetName(): exec},
+			NoSetup:    null.BoolFrom(true),
+			NoTeardown: null.BoolFrom(true),
+		},
+	}
+	testRunState := getTestRunState(t, getTestPreInitState(t), runner.Options, runner)
+	execScheduler, err := execution.NewScheduler(testRunState, controller)
+	require.NoError(t, err)
+
+	samples := make(chan metrics.SampleContainer, 1000)
+	stopEmission, err := execScheduler.Init(context.Background(), samples)
+	require.NoError(t, err)
+	t.Cleanup(stopEmission)
+
+	return execScheduler, samples
+}
+
+func TestSchedulerAbortedByAnotherInstance(t *testing.T) {
+	t.Parallel()
+
+	controller := &abortingController{
+		Controller: local.NewController(),
+		delay:      200 * time.Millisecond,
+		err:        errors.New("thresholds have been crossed"),
+	}
+	execScheduler, samples := newAbortTestScheduler(t, controller)
+
+	startTime := time.Now()
+	err := execScheduler.Run(context.Background(), context.Background(), samples)
+	require.ErrorContains(t, err, "the test was aborted by another instance: thresholds have been crossed")
+	assert.Less(t, time.Since(startTime), 5*time.Second)
+	assert.Empty(t, controller.signaledErrs)
+}
+
+func TestSchedulerSignalsAbort(t *testing.T) {
+	t.Parallel()
+
+	controller := &abortingController{Controller: local.NewController()}
+	execScheduler, samples := newAbortTestScheduler(t, controller)
+
+	runCtx, abortTest := execution.NewTestRunContext(context.Background(), testutils.NewLogger(t))
+	time.AfterFunc(200*time.Millisecond, func() { abortTest(errors.New("test aborted")) })
+
+	err := execScheduler.Run(context.Background(), runCtx, samples)
+	require.ErrorContains(t, err, "test aborted")
+
+	controller.mx.Lock()
+	defer controller.mx.Unlock()
+	require.Len(t, controller.signaledErrs, 1)
+	assert.ErrorContains(t, controller.signaledErrs[0], "test aborted")
+}
+
 func TestSchedulerEndIterations(t *testing.T) {
 	t.Parallel()
 	registry := metrics.NewRegistry()
@@ -978,7 +1080,7 @@ func TestSchedulerEndIterations(t *testing.T) {
 	defer cancel()
 
 	testRunState := getTestRunState(t, getTestPreInitState(t), runner.GetOptions(), runner)
-	execScheduler, err := execution.NewScheduler(testRunState)
+	execScheduler, err := execution.NewScheduler(testRunState, local.NewController())
 	require.NoError(t, err)
 
 	samples := make(chan metrics.SampleContainer, 300)
@@ -1001,6 +1103,34 @@ func TestSchedulerEndIterations(t *testing.T) {
 	}
 }
 
+func TestSchedulerUpdateExecutionTupleUnsupported(t *testing.T) {
+	t.Parallel()
+	runner := &minirunner.MiniRunner{
+		Fn: func(ctx context.Context, _ *lib.State, _ chan<- metrics.SampleContainer) error {
+			<-ctx.Done()
+			return nil
+		},
+	}
+	ctx, cancel, execScheduler, samples := newTestScheduler(t, runner, nil, lib.Options{})
+	defer cancel()
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	assert.EqualError(t, execScheduler.UpdateExecutionTuple(ctx, et),
+		"the execution segment can't be changed before the test has started")
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- execScheduler.Run(ctx, ctx, samples) }()
+	for !execScheduler.GetState().HasStarted() {
+		time.Sleep(10 * time.Microsecond)
+	}
+	assert.EqualError(t, execScheduler.UpdateExecutionTuple(ctx, et),
+		"the execution segment can't be changed to 0:1 in 0,1, the per-vu-iterations executor of the scenario 'default' doesn't support it")
+
+	cancel()
+	require.NoError(t, <-runErr)
+}
+
 func TestSchedulerIsRunning(t *testing.T) {
 	t.Parallel()
 	runner := &minirunner.MiniRunner{
@@ -1190,7 +1320,7 @@ func TestRealTimeAndSetupTeardownMetrics(t *testing.T) {
 	require.NoError(t, err)
 
 	testRunState := getTestRunState(t, piState, options, runner)
-	execScheduler, err := execution.NewScheduler(testRunState)
+	execScheduler, err := execution.NewScheduler(testRunState, local.NewController())
 	require.NoError(t, err)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -1362,7 +1492,7 @@ func TestNewSchedulerHasWork(t *testing.T) {
 	require.NoError(t, err)
 
 	testRunState := getTestRunState(t, piState, runner.GetOptions(), runner)
-	execScheduler, err := execution.NewScheduler(testRunState)
+	execScheduler, err := execution.NewScheduler(testRunState, local.NewController())
 	require.NoError(t, err)
 
 	assert.Len(t, execScheduler.GetExecutors(), 2)
@@ -1372,3 +1502,206 @@ func TestNewSchedulerHasWork(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "doesn't support pause and resume operations after its start")
 }
+
+func TestSchedulerStartAfter(t *testing.T) {
+	t.Parallel()
+
+	newScenario := func(name string, iterations int64, startAfter ...string) executor.PerVUIterationsConfig {
+		config := executor.NewPerVUIterationsConfig(name)
+		config.VUs = null.IntFrom(1)
+		config.Iterations = null.IntFrom(iterations)
+		config.StartAfter = startAfter
+		return config
+	}
+	seed := newScenario("seed", 3)
+	load := newScenario("load", 2, "seed")
+	load.StartTime = types.NullDurationFrom(100 * time.Millisecond)
+	cleanup := newScenario("cleanup", 1, "load")
+
+	var (
+		mx         sync.Mutex
+		scenarios  []string
+		seedEnd    time.Time
+		loadStarts []time.Time
+	)
+	runner := &minirunner.MiniRunner{
+		Fn: func(ctx context.Context, _ *lib.State, _ chan<- metrics.SampleContainer) error {
+			name := lib.GetScenarioState(ctx).Name
+			mx.Lock()
+			scenarios = append(scenarios, name)
+			if name == "load" {
+				loadStarts = append(loadStarts, time.Now())
+			}
+			mx.Unlock()
+
+			time.Sleep(50 * time.Millisecond)
+
+			if name == "seed" {
+				mx.Lock()
+				seedEnd = time.Now()
+				mx.Unlock()
+			}
+			return nil
+		},
+	}
+	ctx, cancel, execScheduler, samples := newTestScheduler(t, runner, nil, lib.Options{
+		Scenarios: lib.ScenarioConfigs{"seed": seed, "load": load, "cleanup": cleanup},
+	})
+	defer cancel()
+
+	require.NoError(t, execScheduler.Run(ctx, ctx, samples))
+
+	mx.Lock()
+	defer mx.Unlock()
+	assert.Equal(t, []string{"seed", "seed", "seed", "load", "load", "cleanup"}, scenarios)
+	require.Len(t, loadStarts, 2)
+	assert.GreaterOrEqual(t, loadStarts[0].Sub(seedEnd), 100*time.Millisecond)
+}
+
+func TestSchedulerStartScenario(t *testing.T) {
+	t.Parallel()
+
+	baseline := executor.NewConstantVUsConfig("baseline")
+	baseline.VUs = null.IntFrom(1)
+	baseline.Duration = types.NullDurationFrom(time.Second)
+	spike := executor.NewSharedIterationsConfig("spike")
+	spike.VUs = null.IntFrom(2)
+	spike.Iterations = null.IntFrom(4)
+	spike.Dormant = null.BoolFrom(true)
+	unused := executor.NewSharedIterationsConfig("unused")
+	unused.Dormant = null.BoolFrom(true)
+
+	var mx sync.Mutex
+	iterations := map[string]int{}
+	runner := &minirunner.MiniRunner{
+		Fn: func(ctx context.Context, _ *lib.State, _ chan<- metrics.SampleContainer) error {
+			mx.Lock()
+			iterations[lib.GetScenarioState(ctx).Name]++
+			mx.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		},
+	}
+	ctx, cancel, execScheduler, samples := newTestScheduler(t, runner, nil, lib.Options{
+		Scenarios: lib.ScenarioConfigs{"baseline": baseline, "spike": spike, "unused": unused},
+	})
+	defer cancel()
+
+	assert.EqualError(t, execScheduler.StartScenario("other"), "there is no scenario 'other'")
+	assert.EqualError(t, execScheduler.StartScenario("baseline"), "the scenario 'baseline' isn't dormant")
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- execScheduler.Run(ctx, ctx, samples) }()
+
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, execScheduler.StartScenario("spike"))
+	assert.EqualError(t, execScheduler.StartScenario("spike"), "the scenario 'spike' has already been started")
+	require.NoError(t, <-runErr)
+
+	assert.EqualError(t, execScheduler.StartScenario("unused"),
+		"the scenario 'unused' can't be started anymore, the other scenarios are done")
+
+	mx.Lock()
+	defer mx.Unlock()
+	assert.Equal(t, 4, iterations["spike"])
+	assert.Zero(t, iterations["unused"])
+	assert.NotZero(t, iterations["baseline"])
+}
+
+func TestSchedulerAbortPolicy(t *testing.T) {
+	t.Parallel()
+
+	constantVUs := executor.NewConstantVUsConfig("default")
+	constantVUs.VUs = null.IntFrom(2)
+	constantVUs.Duration = types.NullDurationFrom(10 * time.Second)
+	rampingVUs := executor.NewRampingVUsConfig("default")
+	rampingVUs.StartVUs = null.IntFrom(2)
+	rampingVUs.Stages = []executor.Stage{{Duration: types.NullDurationFrom(10 * time.Second), Target: null.IntFrom(2)}}
+
+	testCases := []struct {
+		name        string
+		config      lib.ExecutorConfig
+		policy      string
+		gracePeriod time.Duration
+		finished    bool
+	}{
+		{name: "interrupt", config: constantVUs, policy: lib.AbortPolicyInterrupt, finished: false},
+		{name: "graceful", config: constantVUs, policy: lib.AbortPolicyGraceful, gracePeriod: 5 * time.Second, finished: true},
+		{
+			name: "graceful-expired", config: constantVUs, policy: lib.AbortPolicyGraceful,
+			gracePeriod: 50 * time.Millisecond, finished: false,
+		},
+		{name: "finish", config: constantVUs, policy: lib.AbortPolicyFinish, finished: true},
+		{name: "ramping-vus-finish", config: rampingVUs, policy: lib.AbortPolicyFinish, finished: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var started, finished int64
+			runner := &minirunner.MiniRunner{
+				Fn: func(ctx context.Context, _ *lib.State, _ chan<- metrics.SampleContainer) error {
+					atomic.AddInt64(&started, 1)
+					select {
+					case <-time.After(500 * time.Millisecond):
+						atomic.AddInt64(&finished, 1)
+					case <-ctx.Done():
+					}
+					return nil
+				},
+			}
+			ctx, cancel, execScheduler, samples := newTestScheduler(t, runner, nil, lib.Options{
+				Scenarios:        lib.ScenarioConfigs{"default": tc.config},
+				AbortPolicy:      null.StringFrom(tc.policy),
+				AbortGracePeriod: types.NullDurationFrom(tc.gracePeriod),
+			})
+			defer cancel()
+
+			runCtx, abortTest := execution.NewTestRunContext(ctx, testutils.NewLogger(t))
+			time.AfterFunc(200*time.Millisecond, func() { abortTest(errors.New("test aborted")) })
+
+			startTime := time.Now()
+			err := execScheduler.Run(ctx, runCtx, samples)
+			require.ErrorContains(t, err, "test aborted")
+			assert.Less(t, time.Since(startTime), 5*time.Second)
+
+			assert.Equal(t, int64(2), atomic.LoadInt64(&started))
+			if tc.finished {
+				assert.Equal(t, int64(2), atomic.LoadInt64(&finished))
+			} else {
+				assert.Zero(t, atomic.LoadInt64(&finished))
+			}
+		})
+	}
+}
+
+func TestSchedulerMaxIterationDuration(t *testing.T) {
+	t.Parallel()
+
+	config := executor.NewPerVUIterationsConfig("default")
+	config.VUs = null.IntFrom(1)
+	config.Iterations = null.IntFrom(3)
+	config.MaxIterationDuration = types.NullDurationFrom(50 * time.Millisecond)
+
+	var iterations int64
+	runner := &minirunner.MiniRunner{
+		Fn: func(ctx context.Context, _ *lib.State, _ chan<- metrics.SampleContainer) error {
+			if atomic.AddInt64(&iterations, 1) == 1 {
+				<-ctx.Done() // the first iteration hangs
+			}
+			return nil
+		},
+	}
+	ctx, cancel, execScheduler, samples := newTestScheduler(t, runner, nil, lib.Options{
+		Scenarios: lib.ScenarioConfigs{"default": config},
+	})
+	defer cancel()
+
+	startTime := time.Now()
+	require.NoError(t, execScheduler.Run(ctx, ctx, samples))
+	assert.Less(t, time.Since(startTime), 5*time.Second)
+	assert.Equal(t, uint64(2), execScheduler.GetState().GetFullIterationCount())
+	assert.Equal(t, uint64(1), execScheduler.GetState().GetPartialIterationCount())
+}
@@ -1,10 +1,13 @@
 package execution
 
 import (
+	"context"
 	"errors"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/execution/local"
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/testutils"
 	"go.k6.io/k6/lib/testutils/minirunner"
@@ -43,7 +46,7 @@ func TestSetPaused(t *testing.T) {
 	t.Run("second pause is an error", func(t *testing.T) {
 		t.Parallel()
 		testRunState := getBogusTestRunState(t)
-		sched, err := NewScheduler(testRunState)
+		sched, err := NewScheduler(testRunState, local.NewController())
 		require.NoError(t, err)
 		sched.executors = []lib.Executor{pausableExecutor{err: nil}}
 
@@ -56,7 +59,7 @@ func TestSetPaused(t *testing.T) {
 	t.Run("unpause at the start is an error", func(t *testing.T) {
 		t.Parallel()
 		testRunState := getBogusTestRunState(t)
-		sched, err := NewScheduler(testRunState)
+		sched, err := NewScheduler(testRunState, local.NewController())
 		require.NoError(t, err)
 		sched.executors = []lib.Executor{pausableExecutor{err: nil}}
 		err = sched.SetPaused(false)
@@ -67,7 +70,7 @@ func TestSetPaused(t *testing.T) {
 	t.Run("second unpause is an error", func(t *testing.T) {
 		t.Parallel()
 		testRunState := getBogusTestRunState(t)
-		sched, err := NewScheduler(testRunState)
+		sched, err := NewScheduler(testRunState, local.NewController())
 		require.NoError(t, err)
 		sched.executors = []lib.Executor{pausableExecutor{err: nil}}
 		require.NoError(t, sched.SetPaused(true))
@@ -80,7 +83,7 @@ func TestSetPaused(t *testing.T) {
 	t.Run("an error on pausing is propagated", func(t *testing.T) {
 		t.Parallel()
 		testRunState := getBogusTestRunState(t)
-		sched, err := NewScheduler(testRunState)
+		sched, err := NewScheduler(testRunState, local.NewController())
 		require.NoError(t, err)
 		expectedErr := errors.New("testing pausable executor error")
 		sched.executors = []lib.Executor{pausableExecutor{err: expectedErr}}
@@ -89,3 +92,53 @@ func TestSetPaused(t *testing.T) {
 		require.Equal(t, err, expectedErr)
 	})
 }
+
+// sharedDataController is a Controller that already has the data created by
+// another instance, so it never calls the callbacks for them.
+type sharedDataController struct {
+	recordingController
+	data map[string][]byte
+}
+
+func (sc *sharedDataController) GetOrCreateData(id string, callback func() ([]byte, error)) ([]byte, error) {
+	if data, ok := sc.data[id]; ok {
+		sc.record("data:" + id)
+		return data, nil
+	}
+	return sc.recordingController.GetOrCreateData(id, callback)
+}
+
+func TestSchedulerRunWithController(t *testing.T) {
+	t.Parallel()
+
+	runner := &minirunner.MiniRunner{
+		SetupFn: func(context.Context, chan<- metrics.SampleContainer) ([]byte, error) {
+			return nil, errors.New("setup() isn't expected to run on this instance")
+		},
+		TeardownFn: func(context.Context, chan<- metrics.SampleContainer) error {
+			return errors.New("teardown() isn't expected to run on this instance")
+		},
+	}
+	testRunState := getBogusTestRunState(t)
+	testRunState.Runner = runner
+
+	controller := &sharedDataController{data: map[string][]byte{
+		"setup":    []byte(`{"shared":true}`),
+		"teardown": nil,
+	}}
+	sched, err := NewScheduler(testRunState, controller)
+	require.NoError(t, err)
+
+	samples := make(chan metrics.SampleContainer, 100)
+	require.NoError(t, sched.Run(context.Background(), context.Background(), samples))
+
+	assert.Equal(t, []byte(`{"shared":true}`), runner.GetSetupData())
+	assert.Equal(t, []string{
+		"wait:test-ready-to-run-setup", "signal:test-ready-to-run-setup", "waited:test-ready-to-run-setup",
+		"data:setup",
+		"wait:test-start", "signal:test-start", "waited:test-start",
+		"wait:test-abort", "waited:test-abort",
+		"wait:test-done", "signal:test-done", "waited:test-done",
+		"data:teardown",
+	}, controller.calls)
+}
@@ -298,6 +298,11 @@ func (b *Bundle) instantiate(vuImpl *moduleVUImpl, vuID uint64) (*goja.Object, e
 		CWD:              b.pwd,
 	}
 
+	err = b.ModuleResolver.SetupGlobals(vuImpl)
+	if err != nil {
+		return nil, err
+	}
+
 	modSys := modules.NewModuleSystem(b.ModuleResolver, vuImpl)
 	unbindInit := b.setInitGlobals(rt, vuImpl, modSys)
 	vuImpl.initEnv = initenv
@@ -377,6 +382,10 @@ func (b *Bundle) setupJSRuntime(rt *goja.Runtime, vuID int64, logger logrus.Fiel
 	if err != nil {
 		return err
 	}
+	err = common.SetupAbortController(rt)
+	if err != nil {
+		return err
+	}
 
 	if b.CompatibilityMode == lib.CompatibilityModeExtended {
 		err = rt.Set("global", rt.GlobalObject())
@@ -442,7 +451,7 @@ func generateFileLoad(b *Bundle) modules.FileLoader {
 				"required, import them with the `file://` schema for slightly better compatibility",
 				name)
 		}
-		d, err := loader.Load(b.preInitState.Logger, b.filesystems, specifier, name)
+		d, err := loader.Load(b.preInitState.Logger, b.filesystems, b.preInitState.ImportsVerifier, specifier, name)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,170 @@
+package common
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dop251/goja"
+)
+
+// AbortSignal is the signal of an AbortController, which the scripts pass as
+// the signal param of the asynchronous operations, e.g. http.asyncRequest, to
+// cancel them. Its JS properties are only accessed on the event loop, while
+// the operations wait for the abort off the event loop, on Done.
+type AbortSignal struct {
+	Aborted bool       `js:"aborted"`
+	Reason  goja.Value `js:"reason"`
+	Onabort goja.Value `js:"onabort"`
+
+	rt        *goja.Runtime
+	done      chan struct{}
+	listeners []goja.Value
+}
+
+// NewAbortSignal returns a new signal, which isn't aborted.
+func NewAbortSignal(rt *goja.Runtime) *AbortSignal {
+	return &AbortSignal{Reason: goja.Undefined(), Onabort: goja.Null(), rt: rt, done: make(chan struct{})}
+}
+
+// AddEventListener adds the listener of the abort event,
+// the listeners of the other events are ignored.
+func (s *AbortSignal) AddEventListener(typ string, listener goja.Value) {
+	if typ != "abort" || IsNullish(listener) {
+		return
+	}
+	for _, l := range s.listeners {
+		if l.StrictEquals(listener) {
+			return
+		}
+	}
+	s.listeners = append(s.listeners, listener)
+}
+
+// RemoveEventListener removes the listener of the abort event.
+func (s *AbortSignal) RemoveEventListener(typ string, listener goja.Value) {
+	if typ != "abort" {
+		return
+	}
+	for i, l := range s.listeners {
+		if l.StrictEquals(listener) {
+			s.listeners = append(s.listeners[:i], s.listeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// ThrowIfAborted throws the reason of the abort, if the signal is aborted.
+func (s *AbortSignal) ThrowIfAborted() {
+	if s.Aborted {
+		panic(s.Reason)
+	}
+}
+
+// Done returns a channel which is closed once the signal is aborted,
+// it's safe to wait on it off the event loop.
+func (s *AbortSignal) Done() <-chan struct{} {
+	return s.done
+}
+
+// Context returns a context which is canceled once the signal is aborted,
+// or once the parent is done.
+func (s *AbortSignal) Context(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	go func() {
+		select {
+		case <-s.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// abort aborts the signal with the reason, and calls its listeners. The
+// reason defaults to an AbortError, as in the browsers.
+func (s *AbortSignal) abort(reason goja.Value) error {
+	if s.Aborted {
+		return nil
+	}
+	if IsNullish(reason) {
+		reason = newAbortError(s.rt)
+	}
+	s.Aborted = true
+	s.Reason = reason
+	close(s.done)
+
+	event := s.rt.NewObject()
+	if err := event.Set("type", "abort"); err != nil {
+		return err
+	}
+	this := s.rt.ToValue(s)
+	listeners := s.listeners
+	if !IsNullish(s.Onabort) {
+		listeners = append([]goja.Value{s.Onabort}, listeners...)
+	}
+	for _, listener := range listeners {
+		fn, ok := goja.AssertFunction(listener)
+		if !ok {
+			continue
+		}
+		if _, err := fn(this, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newAbortError(rt *goja.Runtime) goja.Value {
+	e, err := rt.New(rt.Get("Error"), rt.ToValue("This operation was aborted"))
+	if err != nil {
+		Throw(rt, err)
+	}
+	if err = e.Set("name", "AbortError"); err != nil {
+		Throw(rt, err)
+	}
+	return e
+}
+
+// AbortController aborts its signal, to cancel the operations it was passed to.
+type AbortController struct {
+	Signal *AbortSignal `js:"signal"`
+}
+
+// Abort aborts the signal of the controller, with the reason if any.
+func (c *AbortController) Abort(reason goja.Value) error {
+	return c.Signal.abort(reason)
+}
+
+// GetAbortSignal returns the AbortSignal of the signal param of an operation,
+// or nil if the param isn't set.
+func GetAbortSignal(v goja.Value) (*AbortSignal, error) {
+	if IsNullish(v) {
+		return nil, nil //nolint:nilnil
+	}
+	s, ok := v.Export().(*AbortSignal)
+	if !ok {
+		return nil, errors.New("the signal must be an AbortSignal")
+	}
+	return s, nil
+}
+
+// SetupAbortController defines the AbortController and AbortSignal globals
+// of the runtime. AbortSignal.abort(reason) returns an aborted signal.
+func SetupAbortController(rt *goja.Runtime) error {
+	if err := rt.Set("AbortController", func(goja.ConstructorCall) *goja.Object {
+		return rt.ToValue(&AbortController{Signal: NewAbortSignal(rt)}).ToObject(rt)
+	}); err != nil {
+		return err
+	}
+
+	signal := rt.ToValue(func(goja.ConstructorCall) *goja.Object {
+		panic(rt.NewTypeError("AbortSignal can't be constructed, use an AbortController"))
+	}).ToObject(rt)
+	if err := signal.Set("abort", func(reason goja.Value) (*AbortSignal, error) {
+		s := NewAbortSignal(rt)
+		return s, s.abort(reason)
+	}); err != nil {
+		return err
+	}
+	return rt.Set("AbortSignal", signal)
+}
@@ -0,0 +1,85 @@
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAbortController(t *testing.T) {
+	t.Parallel()
+
+	rt := goja.New()
+	rt.SetFieldNameMapper(FieldNameMapper{})
+	require.NoError(t, SetupAbortController(rt))
+
+	v, err := rt.RunString(`
+		var log = [];
+		const controller = new AbortController();
+		const signal = controller.signal;
+		log.push(signal.aborted);
+		signal.onabort = (e) => log.push("onabort " + e.type);
+		const listener = () => log.push("removed");
+		signal.addEventListener("abort", function () { log.push("listener " + this.aborted); });
+		signal.addEventListener("abort", listener);
+		signal.removeEventListener("abort", listener);
+		controller.abort();
+		controller.abort("again");
+		log.push(signal.aborted, signal.reason.name, signal.reason.message);
+		try {
+			signal.throwIfAborted();
+		} catch (e) {
+			log.push(e.name);
+		}
+		log.push(AbortSignal.abort("reason").reason);
+		log;
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		false, "onabort abort", "listener true",
+		true, "AbortError", "This operation was aborted", "AbortError", "reason",
+	}, v.Export())
+
+	_, err = rt.RunString(`new AbortSignal()`)
+	require.ErrorContains(t, err, "AbortSignal can't be constructed, use an AbortController")
+}
+
+func TestAbortSignal(t *testing.T) {
+	t.Parallel()
+
+	rt := goja.New()
+	s := NewAbortSignal(rt)
+
+	ctx, cancel := s.Context(context.Background())
+	defer cancel()
+	select {
+	case <-ctx.Done():
+		t.Fatal("the context is done before the abort")
+	default:
+	}
+
+	require.NoError(t, s.abort(rt.ToValue("reason")))
+	select {
+	case <-s.Done():
+	default:
+		t.Fatal("the signal isn't done after the abort")
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the context isn't done after the abort")
+	}
+
+	got, err := GetAbortSignal(rt.ToValue(s))
+	require.NoError(t, err)
+	assert.Same(t, s, got)
+	got, err = GetAbortSignal(goja.Undefined())
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	_, err = GetAbortSignal(rt.ToValue("signal"))
+	require.ErrorContains(t, err, "the signal must be an AbortSignal")
+}
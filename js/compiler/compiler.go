@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -171,7 +172,24 @@ type compilationState struct {
 // Compile the program in the given CompatibilityMode, wrapping it between pre and post code
 // TODO isESM will be used once goja support ESM modules natively
 func (c *Compiler) Compile(src, filename string, isESM bool) (*goja.Program, string, error) {
-	return c.compileImpl(src, filename, !isESM, c.Options.CompatibilityMode, nil)
+	pgm, code, err := c.compileImpl(src, filename, !isESM, c.Options.CompatibilityMode, nil)
+	if err != nil && isTypeScript(filename) {
+		err = fmt.Errorf("%w\n\nTypeScript isn't supported, the TypeScript scripts and modules "+
+			"have to be transpiled to JavaScript before running them, e.g. with esbuild or tsc", err)
+	}
+	return pgm, code, err
+}
+
+// isTypeScript returns true if the file has a TypeScript extension.
+// There is no TypeScript transpiler in k6, so the TypeScript files can
+// only be compiled if they don't use any of the TypeScript syntax.
+func isTypeScript(filename string) bool {
+	for _, ext := range []string{".ts", ".mts", ".cts"} {
+		if strings.HasSuffix(filename, ext) {
+			return true
+		}
+	}
+	return false
 }
 
 // sourceMapLoader is to be used with goja's WithSourceMapLoader
@@ -159,6 +159,23 @@ func TestCompile(t *testing.T) {
 		assert.Contains(t, err.Error(), `SyntaxError: script.js: Unexpected token (1:3)
 > 1 | 1+(=>2)()`)
 	})
+
+	t.Run("TypeScript", func(t *testing.T) {
+		t.Parallel()
+		c := New(testutils.NewLogger(t))
+		c.Options.CompatibilityMode = lib.CompatibilityModeExtended
+		_, _, err := c.Compile(`let n: number = 1;`, "script.ts", true)
+		var exception *goja.Exception
+		assert.ErrorAs(t, err, &exception)
+		assert.Contains(t, err.Error(), "TypeScript isn't supported")
+
+		_, _, err = c.Compile(`let n = 1;`, "script.ts", true)
+		assert.NoError(t, err)
+
+		_, _, err = c.Compile(`let n: number = 1;`, "script.js", true)
+		assert.Error(t, err)
+		assert.NotContains(t, err.Error(), "TypeScript")
+	})
 }
 
 func TestCorruptSourceMap(t *testing.T) {
@@ -84,7 +84,8 @@ func generateSourceMapLoader(logger logrus.FieldLogger, filesystems map[string]f
 		if err != nil {
 			return nil, err
 		}
-		data, err := loader.Load(logger, filesystems, u, path)
+		// the source maps aren't executed, so they aren't locked
+		data, err := loader.Load(logger, filesystems, nil, u, path)
 		if err != nil {
 			return nil, err
 		}
@@ -6,21 +6,36 @@ import (
 	"go.k6.io/k6/js/modules/k6/crypto"
 	"go.k6.io/k6/js/modules/k6/crypto/x509"
 	"go.k6.io/k6/js/modules/k6/data"
+	"go.k6.io/k6/js/modules/k6/dns"
 	"go.k6.io/k6/js/modules/k6/encoding"
 	"go.k6.io/k6/js/modules/k6/execution"
+	"go.k6.io/k6/js/modules/k6/experimental/amqp"
+	"go.k6.io/k6/js/modules/k6/experimental/archive"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/browser"
+	"go.k6.io/k6/js/modules/k6/experimental/csv"
+	"go.k6.io/k6/js/modules/k6/experimental/fake"
+	"go.k6.io/k6/js/modules/k6/experimental/fs"
+	"go.k6.io/k6/js/modules/k6/experimental/graphql"
+	"go.k6.io/k6/js/modules/k6/experimental/jwt"
+	"go.k6.io/k6/js/modules/k6/experimental/kafka"
+	explog "go.k6.io/k6/js/modules/k6/experimental/log"
+	"go.k6.io/k6/js/modules/k6/experimental/mqtt"
+	expnet "go.k6.io/k6/js/modules/k6/experimental/net"
+	"go.k6.io/k6/js/modules/k6/experimental/protobuf"
+	"go.k6.io/k6/js/modules/k6/experimental/redis"
+	expsql "go.k6.io/k6/js/modules/k6/experimental/sql"
+	"go.k6.io/k6/js/modules/k6/experimental/streams"
+	exptesting "go.k6.io/k6/js/modules/k6/experimental/testing"
 	"go.k6.io/k6/js/modules/k6/experimental/tracing"
+	"go.k6.io/k6/js/modules/k6/experimental/webcrypto"
+	expws "go.k6.io/k6/js/modules/k6/experimental/websockets"
+	"go.k6.io/k6/js/modules/k6/experimental/xml"
 	"go.k6.io/k6/js/modules/k6/grpc"
 	"go.k6.io/k6/js/modules/k6/html"
 	"go.k6.io/k6/js/modules/k6/http"
 	"go.k6.io/k6/js/modules/k6/metrics"
+	"go.k6.io/k6/js/modules/k6/timers"
 	"go.k6.io/k6/js/modules/k6/ws"
-
-	"github.com/grafana/xk6-browser/browser"
-	expGrpc "github.com/grafana/xk6-grpc/grpc"
-	"github.com/grafana/xk6-redis/redis"
-	"github.com/grafana/xk6-timers/timers"
-	"github.com/grafana/xk6-webcrypto/webcrypto"
-	expws "github.com/grafana/xk6-websockets/websockets"
 )
 
 func getInternalJSModules() map[string]interface{} {
@@ -31,17 +46,35 @@ func getInternalJSModules() map[string]interface{} {
 		"k6/data":                    data.New(),
 		"k6/encoding":                encoding.New(),
 		"k6/execution":               execution.New(),
+		"k6/experimental/amqp":       amqp.New(),
+		"k6/experimental/archive":    archive.New(),
+		"k6/experimental/csv":        csv.New(),
+		"k6/experimental/fake":       fake.New(),
+		"k6/experimental/fs":         fs.New(),
+		"k6/experimental/graphql":    graphql.New(),
+		"k6/experimental/jwt":        jwt.New(),
+		"k6/experimental/kafka":      kafka.New(),
+		"k6/experimental/log":        explog.New(),
+		"k6/experimental/mqtt":       mqtt.New(),
+		"k6/experimental/net":        expnet.New(),
+		"k6/experimental/protobuf":   protobuf.New(),
 		"k6/experimental/redis":      redis.New(),
+		"k6/experimental/sql":        expsql.New(),
+		"k6/experimental/streams":    streams.New(),
 		"k6/experimental/webcrypto":  webcrypto.New(),
+		"k6/experimental/xml":        xml.New(),
 		"k6/experimental/websockets": &expws.RootModule{},
-		"k6/experimental/grpc":       expGrpc.New(),
+		"k6/experimental/grpc":       grpc.New(),
+		"k6/experimental/testing":    exptesting.New(),
 		"k6/experimental/timers":     timers.New(),
 		"k6/experimental/tracing":    tracing.New(),
 		"k6/experimental/browser":    browser.New(),
+		"k6/net/dns":                 dns.New(),
 		"k6/net/grpc":                grpc.New(),
 		"k6/html":                    html.New(),
 		"k6/http":                    http.New(),
 		"k6/metrics":                 metrics.New(),
+		"k6/timers":                  timers.New(),
 		"k6/ws":                      ws.New(),
 	}
 }
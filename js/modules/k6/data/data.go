@@ -4,8 +4,10 @@ package data
 
 import (
 	"errors"
+	"math/rand"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/dop251/goja"
 	"go.k6.io/k6/js/common"
@@ -17,12 +19,15 @@ type (
 	// instances for each VU.
 	RootModule struct {
 		shared sharedArrays
+		feeds  dataFeeds
 	}
 
 	// Data represents an instance of the data module.
 	Data struct {
 		vu     modules.VU
 		shared *sharedArrays
+		feeds  *dataFeeds
+		random *rand.Rand
 	}
 
 	sharedArrays struct {
@@ -42,6 +47,9 @@ func New() *RootModule {
 		shared: sharedArrays{
 			data: make(map[string]sharedArray),
 		},
+		feeds: dataFeeds{
+			data: make(map[string]*feedIndex),
+		},
 	}
 }
 
@@ -51,6 +59,8 @@ func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 	return &Data{
 		vu:     vu,
 		shared: &rm.shared,
+		feeds:  &rm.feeds,
+		random: rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec
 	}
 }
 
@@ -59,6 +69,7 @@ func (d *Data) Exports() modules.Exports {
 	return modules.Exports{
 		Named: map[string]interface{}{
 			"SharedArray": d.sharedArray,
+			"DataFeed":    d.dataFeed,
 		},
 	}
 }
@@ -0,0 +1,165 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib"
+)
+
+// feedStrategy is how a DataFeed picks its next record.
+type feedStrategy string
+
+const (
+	// feedStrategySequential returns the records in order, across all the VUs
+	// and instances, and starts over once they have all been returned.
+	feedStrategySequential feedStrategy = "sequential"
+	// feedStrategyUnique returns the records in order, across all the VUs and
+	// instances, but only once, and then undefined.
+	feedStrategyUnique feedStrategy = "unique"
+	// feedStrategyRandom returns a random record.
+	feedStrategyRandom feedStrategy = "random"
+)
+
+// dataFeeds are the indexes of the DataFeeds, by name, shared by the VUs.
+type dataFeeds struct {
+	data map[string]*feedIndex
+	mu   sync.Mutex
+}
+
+// feedIndex is the index of the next record of a DataFeed. It's segmented
+// the same way as the iterations of the executors, so that the instances of
+// a distributed test get different records without any coordination.
+type feedIndex struct {
+	mu    sync.Mutex
+	index *lib.SegmentedIndex
+}
+
+func (f *dataFeeds) get(name string) *feedIndex {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	index, ok := f.data[name]
+	if !ok {
+		index = &feedIndex{}
+		f.data[name] = index
+	}
+	return index
+}
+
+// next returns the index of the next record in the whole test, which is
+// unique across the VUs and the instances.
+func (f *feedIndex) next(options lib.Options) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.index == nil {
+		et, err := lib.NewExecutionTuple(options.ExecutionSegment, options.ExecutionSegmentSequence)
+		if err != nil {
+			return 0, err
+		}
+		f.index = lib.NewSegmentedIndex(et)
+	}
+
+	_, unscaled := f.index.Next()
+	return unscaled - 1, nil
+}
+
+// dataFeed is a constructor returning a DataFeed, which distributes the records
+// of the data, e.g. a SharedArray, to the VUs with the strategy option. The
+// DataFeeds of all the VUs with the same name share the same records order.
+func (d *Data) dataFeed(call goja.ConstructorCall) *goja.Object {
+	rt := d.vu.Runtime()
+
+	if d.vu.State() != nil {
+		common.Throw(rt, errors.New("new DataFeed must be called in the init context"))
+	}
+
+	name := call.Argument(0).String()
+	if name == "" {
+		common.Throw(rt, errors.New("empty name provided to DataFeed's constructor"))
+	}
+
+	data := call.Argument(1)
+	if common.IsNullish(data) {
+		common.Throw(rt, errors.New("an array is expected as the second argument of DataFeed's constructor"))
+	}
+	records := data.ToObject(rt)
+	if common.IsNullish(records.Get("length")) {
+		common.Throw(rt, errors.New("an array is expected as the second argument of DataFeed's constructor"))
+	}
+
+	strategy := feedStrategySequential
+	if options := call.Argument(2); !common.IsNullish(options) {
+		if v := options.ToObject(rt).Get("strategy"); !common.IsNullish(v) {
+			strategy = feedStrategy(v.String())
+		}
+	}
+	switch strategy {
+	case feedStrategySequential, feedStrategyUnique, feedStrategyRandom:
+	default:
+		common.Throw(rt, fmt.Errorf("invalid strategy %q, it must be sequential, unique or random", strategy))
+	}
+
+	index := d.feeds.get(name)
+	get := recordGetter(rt, records)
+
+	obj := rt.NewObject()
+	must(rt, obj.DefineDataProperty("name", rt.ToValue(name), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+	must(rt, obj.DefineDataProperty("strategy", rt.ToValue(string(strategy)),
+		goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+	must(rt, obj.Set("next", func() goja.Value {
+		state := d.vu.State()
+		if state == nil {
+			common.Throw(rt, errors.New("the next record of a DataFeed can't be requested in the init context"))
+		}
+
+		length := records.Get("length").ToInteger()
+		if length == 0 {
+			return goja.Undefined()
+		}
+
+		if strategy == feedStrategyRandom {
+			return get(d.random.Int63n(length))
+		}
+
+		i, err := index.next(state.Options)
+		if err != nil {
+			common.Throw(rt, err)
+		}
+		if strategy == feedStrategyUnique && i >= length {
+			return goja.Undefined()
+		}
+		return get(i % length)
+	}))
+
+	return obj
+}
+
+// recordGetter returns the function getting the records of the data, by their
+// index or through its get method, e.g. for the tables of k6/experimental/csv.
+func recordGetter(rt *goja.Runtime, records *goja.Object) func(int64) goja.Value {
+	if get, ok := goja.AssertFunction(records.Get("get")); ok {
+		return func(i int64) goja.Value {
+			v, err := get(records, rt.ToValue(i))
+			if err != nil {
+				panic(err)
+			}
+			return v
+		}
+	}
+
+	return func(i int64) goja.Value {
+		return records.Get(strconv.FormatInt(i, 10))
+	}
+}
+
+func must(rt *goja.Runtime, err error) {
+	if err != nil {
+		common.Throw(rt, err)
+	}
+}
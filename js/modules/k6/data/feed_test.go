@@ -0,0 +1,134 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+)
+
+const makeFeedScript = `
+	function assertEquals(actual, expected) {
+		if (JSON.stringify(actual) !== JSON.stringify(expected)) {
+			throw new Error("expected " + JSON.stringify(expected) + ", got " + JSON.stringify(actual));
+		}
+	}
+	var users = new SharedArray("users", function() { return ["a", "b", "c"] });
+	var sequential = new data.DataFeed("sequential", users);
+	var unique = new data.DataFeed("unique", users, { strategy: "unique" });
+	var random = new data.DataFeed("random", ["a", "b", "c"], { strategy: "random" });
+	var table = new data.DataFeed("table", { length: 2, get: function(i) { return "row" + i } });
+`
+
+func newFeedRuntimes(t *testing.T, options lib.Options) (*modulestest.Runtime, *modulestest.Runtime) {
+	t.Helper()
+
+	first, err := newConfiguredRuntime(t)
+	require.NoError(t, err)
+	second, err := configuredRuntimeFromAnother(t, first)
+	require.NoError(t, err)
+
+	for _, ts := range []*modulestest.Runtime{first, second} {
+		_, err = ts.VU.Runtime().RunString(makeFeedScript)
+		require.NoError(t, err)
+		ts.MoveToVUContext(&lib.State{Options: options})
+	}
+	return first, second
+}
+
+func TestDataFeed(t *testing.T) {
+	t.Parallel()
+
+	first, second := newFeedRuntimes(t, lib.Options{})
+
+	_, err := first.VU.Runtime().RunString(`
+		assertEquals([sequential.next(), sequential.next()], ["a", "b"]);
+		assertEquals(unique.next(), "a");
+		assertEquals([table.next(), table.next(), table.next()], ["row0", "row1", "row0"]);
+		assertEquals(["a", "b", "c"].includes(random.next()), true);
+		assertEquals(sequential.name, "sequential");
+		assertEquals(random.strategy, "random");
+	`)
+	require.NoError(t, err)
+
+	// the records are shared with the DataFeeds of the other VUs
+	_, err = second.VU.Runtime().RunString(`
+		assertEquals([sequential.next(), sequential.next()], ["c", "a"]);
+		assertEquals([unique.next(), unique.next(), unique.next()], ["b", "c", undefined]);
+	`)
+	require.NoError(t, err)
+}
+
+func TestDataFeedSegmented(t *testing.T) {
+	t.Parallel()
+
+	segment, err := lib.NewExecutionSegmentFromString("1/2:1")
+	require.NoError(t, err)
+	sequence, err := lib.NewExecutionSegmentSequenceFromString("0,1/2,1")
+	require.NoError(t, err)
+
+	first, second := newFeedRuntimes(t, lib.Options{
+		ExecutionSegment:         segment,
+		ExecutionSegmentSequence: &sequence,
+	})
+
+	// the other instance gets the records of the other half of the segments
+	_, err = first.VU.Runtime().RunString(`assertEquals(unique.next(), "b")`)
+	require.NoError(t, err)
+	_, err = second.VU.Runtime().RunString(`assertEquals([unique.next(), unique.next()], [undefined, undefined])`)
+	require.NoError(t, err)
+}
+
+func TestDataFeedConstructorExceptions(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		code, err string
+	}{
+		"empty name": {
+			code: `new data.DataFeed("", [])`,
+			err:  "empty name provided to DataFeed's constructor",
+		},
+		"not an array": {
+			code: `new data.DataFeed("feed", 42)`,
+			err:  "an array is expected as the second argument of DataFeed's constructor",
+		},
+		"invalid strategy": {
+			code: `new data.DataFeed("feed", [], { strategy: "shuffled" })`,
+			err:  `invalid strategy "shuffled", it must be sequential, unique or random`,
+		},
+	}
+
+	for name, testCase := range cases {
+		name, testCase := name, testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ts, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+			_, err = ts.VU.Runtime().RunString(testCase.code)
+			require.ErrorContains(t, err, testCase.err)
+		})
+	}
+
+	t.Run("next in the init context", func(t *testing.T) {
+		t.Parallel()
+
+		ts, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+		_, err = ts.VU.Runtime().RunString(`new data.DataFeed("feed", ["a"]).next()`)
+		require.ErrorContains(t, err, "the next record of a DataFeed can't be requested in the init context")
+	})
+
+	t.Run("in the VU context", func(t *testing.T) {
+		t.Parallel()
+
+		ts, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+		ts.MoveToVUContext(&lib.State{})
+		_, err = ts.VU.Runtime().RunString(`new data.DataFeed("feed", ["a"])`)
+		require.ErrorContains(t, err, "new DataFeed must be called in the init context")
+	})
+}
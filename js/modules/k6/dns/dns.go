@@ -0,0 +1,150 @@
+// Package dns implements the k6/net/dns module, for DNS lookups and the hosts overrides of the VUs.
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the DNS module for every VU.
+	ModuleInstance struct {
+		vu modules.VU
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// ErrDNSForbiddenInInitContext is used when the DNS functions are called in the init context.
+var ErrDNSForbiddenInInitContext = common.NewInitContextError("Using k6/net/dns in the init context is not supported")
+
+// hostsDialer is implemented by the dialer of the VU, which resolves the
+// hostnames with the hosts overrides, and which the overrides can be set for.
+type hostsDialer interface {
+	LookupHost(ctx context.Context, host string) (net.IP, error)
+	SetVUHosts(hosts *types.Hosts)
+}
+
+// idleConnectionsCloser is implemented by the transports of the VU.
+type idleConnectionsCloser interface {
+	CloseIdleConnections()
+}
+
+// New returns a pointer to a new RootModule instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface to return
+// a new instance for each VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu}
+}
+
+// Exports returns the exports of the dns module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"lookup":   mi.Lookup,
+			"setHosts": mi.SetHosts,
+		},
+	}
+}
+
+func (mi *ModuleInstance) getDialer() (hostsDialer, error) {
+	state := mi.vu.State()
+	if state == nil {
+		return nil, ErrDNSForbiddenInInitContext
+	}
+	dialer, ok := state.Dialer.(hostsDialer)
+	if !ok {
+		return nil, errors.New("the dialer of the VU doesn't support the DNS functions")
+	}
+	return dialer, nil
+}
+
+// Lookup returns the IP address that the hostname is resolved to for the
+// connections of the VU, the same way as for its requests, with the hosts
+// overrides and the dns option. The duration of the lookup is measured by the
+// dns_lookup_duration metric.
+func (mi *ModuleInstance) Lookup(hostname string) (string, error) {
+	dialer, err := mi.getDialer()
+	if err != nil {
+		return "", err
+	}
+	state := mi.vu.State()
+	ctx := mi.vu.Context()
+
+	start := time.Now()
+	ip, err := dialer.LookupHost(ctx, hostname)
+	end := time.Now()
+
+	ctm := state.Tags.GetCurrentValues()
+	tags := ctm.Tags.With("host", hostname)
+	if err != nil {
+		tags = tags.With("error", err.Error())
+	}
+	metrics.PushIfNotDone(ctx, state.Samples, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: state.BuiltinMetrics.DNSLookupDuration,
+			Tags:   tags,
+		},
+		Time:     end,
+		Metadata: ctm.Metadata,
+		Value:    metrics.D(end.Sub(start)),
+	})
+	if err != nil {
+		return "", err
+	}
+	return ip.String(), nil
+}
+
+// SetHosts sets the IP addresses, with optional ports, that the hostnames are
+// resolved to for the next connections of the VU, instead of the ones from the
+// hosts option and the DNS. The overrides are removed if the hosts are null.
+// The idle connections of the VU are closed, since they could be to other IPs.
+func (mi *ModuleInstance) SetHosts(hosts goja.Value) error {
+	dialer, err := mi.getDialer()
+	if err != nil {
+		return err
+	}
+
+	var parsed *types.Hosts
+	if !common.IsNullish(hosts) {
+		var source map[string]string
+		if err = mi.vu.Runtime().ExportTo(hosts, &source); err != nil {
+			return fmt.Errorf("invalid hosts value: %w", err)
+		}
+		if parsed, err = types.ParseHosts(source); err != nil {
+			return fmt.Errorf("invalid hosts value: %w", err)
+		}
+	}
+	dialer.SetVUHosts(parsed)
+
+	state := mi.vu.State()
+	for _, transport := range []interface{}{
+		state.Transport, state.TransportReuse, state.TransportNoReuse, state.TransportH2C, state.TransportH3,
+	} {
+		if closer, ok := transport.(idleConnectionsCloser); ok {
+			closer.CloseIdleConnections()
+		}
+	}
+	return nil
+}
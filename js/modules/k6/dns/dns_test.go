@@ -0,0 +1,124 @@
+package dns
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/netext"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+func newTestRuntime(t *testing.T) (*modulestest.Runtime, chan metrics.SampleContainer) {
+	t.Helper()
+	runtime := modulestest.NewRuntime(t)
+	mi, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, runtime.VU.Runtime().Set("dns", mi.Exports().Named))
+
+	resolver := netext.NewResolver(func(host string) ([]net.IP, error) {
+		switch host {
+		case "example.com":
+			return []net.IP{net.ParseIP("192.0.2.1")}, nil
+		case "blue.example.com":
+			return []net.IP{net.ParseIP("192.0.2.2")}, nil
+		default:
+			return nil, errors.New("no such host")
+		}
+	}, 0, types.DNSfirst, types.DNSpreferIPv4)
+	dialer := netext.NewDialer(net.Dialer{}, resolver)
+	var err error
+	dialer.Hosts, err = types.ParseHosts(map[string]string{"green.example.com": "192.0.2.3"})
+	require.NoError(t, err)
+	blocked, err := types.NewHostnameTrie([]string{"blocked.example.com"})
+	require.NoError(t, err)
+	dialer.BlockedHostnames = blocked
+
+	registry := metrics.NewRegistry()
+	samples := make(chan metrics.SampleContainer, 100)
+	runtime.MoveToVUContext(&lib.State{
+		Dialer:         dialer,
+		Samples:        samples,
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+	})
+	return runtime, samples
+}
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+	runtime, samples := newTestRuntime(t)
+	rt := runtime.VU.Runtime()
+
+	for host, ip := range map[string]string{
+		"example.com":       "192.0.2.1",
+		"green.example.com": "192.0.2.3",
+		"192.0.2.10":        "192.0.2.10",
+	} {
+		v, err := rt.RunString(`dns.lookup("` + host + `")`)
+		require.NoError(t, err)
+		assert.Equal(t, ip, v.String())
+	}
+
+	_, err := rt.RunString(`dns.lookup("unknown.example.com")`)
+	require.ErrorContains(t, err, "no such host")
+	_, err = rt.RunString(`dns.lookup("blocked.example.com")`)
+	require.ErrorContains(t, err, "hostname (blocked.example.com) is in a blocked pattern")
+
+	var lookups []string
+	for _, c := range metrics.GetBufferedSamples(samples) {
+		for _, s := range c.GetSamples() {
+			require.Equal(t, metrics.DNSLookupDurationName, s.Metric.Name)
+			host, _ := s.Tags.Get("host")
+			_, hasError := s.Tags.Get("error")
+			if hasError {
+				host += " (error)"
+			}
+			lookups = append(lookups, host)
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		"example.com", "green.example.com", "192.0.2.10",
+		"unknown.example.com (error)", "blocked.example.com (error)",
+	}, lookups)
+}
+
+func TestSetHosts(t *testing.T) {
+	t.Parallel()
+	runtime, _ := newTestRuntime(t)
+	rt := runtime.VU.Runtime()
+
+	v, err := rt.RunString(`
+		dns.setHosts({ "example.com": "192.0.2.20", "green.example.com": "192.0.2.21:8443" });
+		[dns.lookup("example.com"), dns.lookup("green.example.com"), dns.lookup("blue.example.com")].join(",");
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.20,192.0.2.21,192.0.2.2", v.String())
+
+	v, err = rt.RunString(`
+		dns.setHosts(null);
+		[dns.lookup("example.com"), dns.lookup("green.example.com")].join(",");
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.1,192.0.2.3", v.String())
+
+	_, err = rt.RunString(`dns.setHosts({ "example.com": "invalid" })`)
+	require.ErrorContains(t, err, "invalid hosts value: invalid IP address 'invalid' for the host 'example.com'")
+}
+
+func TestInitContext(t *testing.T) {
+	t.Parallel()
+	runtime := modulestest.NewRuntime(t)
+	mi, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, runtime.VU.Runtime().Set("dns", mi.Exports().Named))
+
+	_, err := runtime.VU.Runtime().RunString(`dns.lookup("example.com")`)
+	require.ErrorContains(t, err, "Using k6/net/dns in the init context is not supported")
+}
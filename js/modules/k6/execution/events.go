@@ -0,0 +1,180 @@
+package execution
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/event"
+	"go.k6.io/k6/js/common"
+)
+
+// The lifecycle events the scripts can handle.
+const (
+	eventTestStart     = "testStart"
+	eventTestEnd       = "testEnd"
+	eventScenarioStart = "scenarioStart"
+	eventIterationEnd  = "iterationEnd"
+)
+
+// eventHandlers are the handlers of the lifecycle events registered by the
+// script in the init context of a VU.
+type eventHandlers struct {
+	handlers map[string][]goja.Callable
+	// the scenarios this VU has started running, for the scenarioStart event
+	startedScenarios map[string]struct{}
+}
+
+// newEventsInfo returns a goja.Object with the method to register the
+// handlers of the lifecycle events.
+func (mi *ModuleInstance) newEventsInfo() (*goja.Object, error) {
+	rt := mi.vu.Runtime()
+	o := rt.NewObject()
+	if err := o.Set("on", mi.onEvent); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// onEvent registers the handler of a lifecycle event, which is called by each
+// VU running iterations, while it isn't running one:
+//   - testStart, before the first iteration of the test,
+//   - testEnd, after the teardown,
+//   - scenarioStart, before the first iteration of a scenario in the VU, with
+//     the name of the scenario and the ID of the VU,
+//   - iterationEnd, after each iteration of the VU, with the name of the
+//     scenario, the ID of the VU, the iteration and its error, if any.
+//
+// The handlers are called synchronously, the promises they return aren't
+// awaited, and their errors are logged.
+func (mi *ModuleInstance) onEvent(name string, handler goja.Value) {
+	rt := mi.vu.Runtime()
+	if mi.vu.State() != nil {
+		common.Throw(rt, errors.New("the event handlers can only be registered in the init context"))
+	}
+	switch name {
+	case eventTestStart, eventTestEnd, eventScenarioStart, eventIterationEnd:
+	default:
+		common.Throw(rt, fmt.Errorf(
+			"unknown event %q, it must be %s, %s, %s or %s",
+			name, eventTestStart, eventTestEnd, eventScenarioStart, eventIterationEnd))
+	}
+	fn, ok := goja.AssertFunction(handler)
+	if !ok {
+		common.Throw(rt, fmt.Errorf("the handler of the %s event must be a function", name))
+	}
+
+	if mi.events == nil {
+		mi.events = &eventHandlers{
+			handlers:         make(map[string][]goja.Callable),
+			startedScenarios: make(map[string]struct{}),
+		}
+		mi.subscribeToEvents()
+	}
+	mi.events.handlers[name] = append(mi.events.handlers[name], fn)
+}
+
+// subscribeToEvents handles the global and VU events until the Exit event.
+func (mi *ModuleInstance) subscribeToEvents() {
+	events := mi.vu.Events()
+	if events.Global == nil || events.Local == nil {
+		return
+	}
+	globalSID, globalCh := events.Global.Subscribe(event.TestStart, event.TestEnd, event.Exit)
+	localSID, localCh := events.Local.Subscribe(event.IterStart, event.IterEnd)
+	go func() {
+		defer func() {
+			events.Global.Unsubscribe(globalSID)
+			events.Local.Unsubscribe(localSID)
+		}()
+		for {
+			select {
+			case evt, ok := <-globalCh:
+				if !ok {
+					return
+				}
+				mi.handleEvent(evt)
+				evt.Done()
+				if evt.Type == event.Exit {
+					return
+				}
+			case evt, ok := <-localCh:
+				if !ok {
+					return
+				}
+				mi.handleEvent(evt)
+				evt.Done()
+			}
+		}
+	}()
+}
+
+// handleEvent calls the handlers of the event. The VU isn't running any code
+// while the event is handled, since the emitters wait for the handlers.
+func (mi *ModuleInstance) handleEvent(evt *event.Event) {
+	state := mi.vu.State()
+	// the VUs of setup, teardown and handleSummary don't handle the events
+	if state == nil || state.VUID == 0 {
+		return
+	}
+	rt := mi.vu.Runtime()
+
+	var (
+		name string
+		args []goja.Value
+	)
+	switch evt.Type { //nolint:exhaustive
+	case event.TestStart:
+		name = eventTestStart
+	case event.TestEnd:
+		// the runtime was interrupted when the VU was deactivated at the end
+		// of the scenarios, but it can run the handlers now
+		rt.ClearInterrupt()
+		name = eventTestEnd
+	case event.IterStart:
+		data, ok := evt.Data.(event.IterData)
+		if !ok {
+			return
+		}
+		if _, started := mi.events.startedScenarios[data.ScenarioName]; started {
+			return
+		}
+		mi.events.startedScenarios[data.ScenarioName] = struct{}{}
+		name = eventScenarioStart
+		args = []goja.Value{rt.ToValue(map[string]interface{}{
+			"scenario": data.ScenarioName,
+			"vu":       data.VUID,
+		})}
+	case event.IterEnd:
+		data, ok := evt.Data.(event.IterData)
+		if !ok {
+			return
+		}
+		var iterErr interface{}
+		if data.Error != nil {
+			iterErr = data.Error.Error()
+		}
+		name = eventIterationEnd
+		args = []goja.Value{rt.ToValue(map[string]interface{}{
+			"scenario":  data.ScenarioName,
+			"vu":        data.VUID,
+			"iteration": data.Iteration,
+			"error":     iterErr,
+		})}
+	default:
+		return
+	}
+
+	for _, handler := range mi.events.handlers[name] {
+		_, err := handler(goja.Undefined(), args...)
+		var interruptErr *goja.InterruptedError
+		if errors.As(err, &interruptErr) {
+			// the iteration was interrupted, the VU is being stopped
+			return
+		}
+		if err != nil {
+			state.Logger.WithError(err).Warnf("The handler of the %s event failed", name)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package execution
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"github.com/dop251/goja"
 
 	"go.k6.io/k6/errext"
+	"go.k6.io/k6/execution"
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
 	"go.k6.io/k6/lib"
@@ -22,8 +24,9 @@ type (
 
 	// ModuleInstance represents an instance of the execution module.
 	ModuleInstance struct {
-		vu  modules.VU
-		obj *goja.Object
+		vu     modules.VU
+		obj    *goja.Object
+		events *eventHandlers
 	}
 )
 
@@ -55,7 +58,9 @@ func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 			common.Throw(rt, err)
 		}
 	}
+	defProp("events", mi.newEventsInfo)
 	defProp("instance", mi.newInstanceInfo)
+	defProp("iteration", mi.newIterationInfo)
 	defProp("scenario", mi.newScenarioInfo)
 	defProp("test", mi.newTestInfo)
 	defProp("vu", mi.newVUInfo)
@@ -121,11 +126,48 @@ func (mi *ModuleInstance) newScenarioInfo() (*goja.Object, error) {
 
 			return vuState.GetScenarioGlobalVUIter()
 		},
+		// stop the scenario, while the other scenarios continue
+		"abort": func() interface{} {
+			return func(msg goja.Value) {
+				name := getScenarioState().Name
+				reason := "scenario aborted"
+				if msg != nil && !goja.IsUndefined(msg) {
+					reason = fmt.Sprintf("%s: %s", reason, msg.String())
+				}
+				if !execution.AbortScenario(mi.vu.Context(), reason) {
+					common.Throw(rt, fmt.Errorf("the scenario %q can't be aborted", name))
+				}
+				// the iteration is interrupted right away, like the other
+				// in-flight iterations of the scenario
+				rt.Interrupt(context.Canceled)
+			}
+		},
 	}
 
 	return newInfoObj(rt, si)
 }
 
+// newIterationInfo returns a goja.Object with property accessors to control
+// the execution of the current iteration.
+func (mi *ModuleInstance) newIterationInfo() (*goja.Object, error) {
+	if mi.vu.State() == nil {
+		return nil, errors.New("getting iteration information in the init context is not supported")
+	}
+	rt := mi.vu.Runtime()
+
+	ii := map[string]func() interface{}{
+		// end the iteration early, without it being an error, by interrupting
+		// the runtime like test.abort(), so the script can't catch it
+		"skipRemaining": func() interface{} {
+			return func() {
+				rt.Interrupt(lib.ErrIterationSkipped)
+			}
+		},
+	}
+
+	return newInfoObj(rt, ii)
+}
+
 // newInstanceInfo returns a goja.Object with property accessors to retrieve
 // information about the local instance stats.
 func (mi *ModuleInstance) newInstanceInfo() (*goja.Object, error) {
@@ -174,6 +216,20 @@ func (mi *ModuleInstance) newTestInfo() (*goja.Object, error) {
 				rt.Interrupt(&errext.InterruptError{Reason: reason})
 			}
 		},
+		// mark the test run as failed, without stopping it
+		"fail": func() interface{} {
+			return func(msg goja.Value) {
+				es := lib.GetExecutionState(mi.vu.Context())
+				if es == nil {
+					common.Throw(rt, errors.New("marking the test as failed in the init context is not supported"))
+				}
+				reason := "no reason was given"
+				if msg != nil && !goja.IsUndefined(msg) {
+					reason = msg.String()
+				}
+				es.MarkFailed(reason)
+			}
+		},
 		"options": func() interface{} {
 			if optionsObject == nil {
 				opts, err := optionsAsObject(rt, mi.vu.State().Options)
@@ -223,7 +223,7 @@ func TestAbortTest(t *testing.T) { //nolint:tparallel
 func TestOptionsTestFull(t *testing.T) {
 	t.Parallel()
 
-	expected := `{"paused":true,"scenarios":{"const-vus":{"executor":"constant-vus","options":{"browser":{"someOption":true}},"startTime":"10s","gracefulStop":"30s","env":{"FOO":"bar"},"exec":"default","tags":{"tagkey":"tagvalue"},"vus":50,"duration":"10m0s"}},"executionSegment":"0:1/4","executionSegmentSequence":"0,1/4,1/2,1","noSetup":true,"setupTimeout":"1m0s","noTeardown":true,"teardownTimeout":"5m0s","rps":100,"dns":{"ttl":"1m","select":"roundRobin","policy":"any"},"maxRedirects":3,"userAgent":"k6-user-agent","batch":15,"batchPerHost":5,"httpDebug":"full","insecureSkipTLSVerify":true,"tlsCipherSuites":["TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"],"tlsVersion":{"min":"tls1.2","max":"tls1.3"},"tlsAuth":[{"domains":["example.com"],"cert":"mycert.pem","key":"mycert-key.pem","password":"mypwd"}],"throw":true,"thresholds":{"http_req_duration":[{"threshold":"rate>0.01","abortOnFail":true,"delayAbortEval":"10s"}]},"blacklistIPs":["192.0.2.0/24"],"blockHostnames":["test.k6.io","*.example.com"],"hosts":{"test.k6.io":"1.2.3.4:8443"},"noConnectionReuse":true,"noVUConnectionReuse":true,"minIterationDuration":"10s","ext":{"ext-one":{"rawkey":"rawvalue"}},"summaryTrendStats":["avg","min","max"],"summaryTimeUnit":"ms","systemTags":["iter","vu"],"tags":null,"metricSamplesBufferSize":8,"noCookiesReset":true,"discardResponseBodies":true,"consoleOutput":"loadtest.log","tags":{"runtag-key":"runtag-value"},"localIPs":"192.168.20.12-192.168.20.15,192.168.10.0/27"}`
+	expected := `{"paused":true,"scenarios":{"const-vus":{"executor":"constant-vus","options":{"browser":{"someOption":true}},"startTime":"10s","startAfter":null,"dormant":null,"gracefulStop":"30s","maxIterationDuration":null,"warmupDuration":null,"env":{"FOO":"bar"},"exec":"default","tags":{"tagkey":"tagvalue"},"pacing":null,"pacingJitter":null,"vus":50,"duration":"10m0s"}},"executionSegment":"0:1/4","executionSegmentSequence":"0,1/4,1/2,1","noSetup":true,"setupTimeout":"1m0s","noTeardown":true,"teardownTimeout":"5m0s","abortPolicy":null,"abortGracePeriod":null,"rps":100,"dns":{"ttl":"1m","select":"roundRobin","policy":"any"},"maxRedirects":3,"userAgent":"k6-user-agent","batch":15,"batchPerHost":5,"httpDebug":"full","insecureSkipTLSVerify":true,"tlsCipherSuites":["TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"],"tlsVersion":{"min":"tls1.2","max":"tls1.3"},"tlsAuth":[{"domains":["example.com"],"cert":"mycert.pem","key":"mycert-key.pem","password":"mypwd"}],"throw":true,"thresholds":{"http_req_duration":[{"threshold":"rate>0.01","abortOnFail":true,"delayAbortEval":"10s"}]},"thresholdsWebhook":"https://hooks.example.com/k6","blacklistIPs":["192.0.2.0/24"],"blockHostnames":["test.k6.io","*.example.com"],"hosts":{"test.k6.io":"1.2.3.4:8443"},"noConnectionReuse":true,"maxConnectionsPerHost":null,"maxIdleConnections":null,"noVUConnectionReuse":true,"minIterationDuration":"10s","ext":{"ext-one":{"rawkey":"rawvalue"}},"summaryTrendStats":["avg","min","max"],"summaryTimeUnit":"ms","trendSinkMaxValues":10000,"timeSeriesLimit":50000,"urlGrouping":true,"gaugeTTL":"5m0s","noWarmupExport":null,"systemTags":["iter","vu"],"tags":null,"metricSamplesBufferSize":8,"noCookiesReset":true,"cookies":null,"discardResponseBodies":true,"harSampleRate":null,"harTags":null,"consoleOutput":"loadtest.log","tags":{"runtag-key":"runtag-value"},"localIPs":"192.168.20.12-192.168.20.15,192.168.10.0/27"}`
 
 	var (
 		rt    = goja.New()
@@ -325,6 +325,7 @@ func TestOptionsTestFull(t *testing.T) {
 						},
 					},
 				},
+				ThresholdsWebhook: null.StringFrom("https://hooks.example.com/k6"),
 				BlockedHostnames: func() types.NullHostnameTrie {
 					bh, err := types.NewNullHostnameTrie([]string{"test.k6.io", "*.example.com"})
 					require.NoError(t, err)
@@ -343,8 +344,12 @@ func TestOptionsTestFull(t *testing.T) {
 				External: map[string]json.RawMessage{
 					"ext-one": json.RawMessage(`{"rawkey":"rawvalue"}`),
 				},
-				SummaryTrendStats: []string{"avg", "min", "max"},
-				SummaryTimeUnit:   null.StringFrom("ms"),
+				SummaryTrendStats:  []string{"avg", "min", "max"},
+				SummaryTimeUnit:    null.StringFrom("ms"),
+				TrendSinkMaxValues: null.IntFrom(10000),
+				TimeSeriesLimit:    null.IntFrom(50000),
+				URLGrouping:        null.BoolFrom(true),
+				GaugeTTL:           types.NullDurationFrom(5 * time.Minute),
 				SystemTags: func() *metrics.SystemTagSet {
 					sysm := metrics.SystemTagSet(metrics.TagIter | metrics.TagVU)
 					return &sysm
@@ -501,3 +506,109 @@ func TestTagsDynamicObjectSet(t *testing.T) {
 	require.NotNil(t, val)
 	assert.Equal(t, val.String(), "v1")
 }
+
+func TestFailTest(t *testing.T) {
+	t.Parallel()
+
+	rt := goja.New()
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := lib.NewExecutionState(nil, et, 0, 0)
+	m, ok := New().NewModuleInstance(
+		&modulestest.VU{
+			RuntimeField: rt,
+			CtxField:     lib.WithExecutionState(context.Background(), es),
+			StateField:   &lib.State{},
+		},
+	).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, rt.Set("exec", m.Exports().Default))
+
+	_, err = rt.RunString(`
+		exec.test.fail("first");
+		exec.test.fail();
+		exec.test.fail("first");
+		var after = true;
+	`)
+	require.NoError(t, err)
+	assert.True(t, rt.Get("after").ToBoolean())
+	assert.Equal(t, []string{"first", "no reason was given"}, es.GetFailureReasons())
+}
+
+func TestIterationSkipRemaining(t *testing.T) {
+	t.Parallel()
+
+	rt := goja.New()
+	m, ok := New().NewModuleInstance(
+		&modulestest.VU{
+			RuntimeField: rt,
+			CtxField:     context.Background(),
+			StateField:   &lib.State{},
+		},
+	).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, rt.Set("exec", m.Exports().Default))
+
+	_, err := rt.RunString(`
+		var calls = 0;
+		function nested() {
+			try {
+				exec.iteration.skipRemaining();
+				calls++;
+			} catch (e) {
+				calls++;
+			} finally {
+				calls++;
+			}
+		}
+		nested();
+		calls++;
+	`)
+	var interrupted *goja.InterruptedError
+	require.ErrorAs(t, err, &interrupted)
+	assert.Equal(t, lib.ErrIterationSkipped, interrupted.Value())
+	assert.Equal(t, int64(0), rt.Get("calls").ToInteger())
+
+	rt = goja.New()
+	m, ok = New().NewModuleInstance(
+		&modulestest.VU{RuntimeField: rt, CtxField: context.Background()},
+	).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, rt.Set("exec", m.Exports().Default))
+	_, err = rt.RunString(`exec.iteration.skipRemaining()`)
+	require.ErrorContains(t, err, "getting iteration information in the init context is not supported")
+}
+
+func TestEventsOn(t *testing.T) {
+	t.Parallel()
+
+	rt := goja.New()
+	m, ok := New().NewModuleInstance(
+		&modulestest.VU{RuntimeField: rt, CtxField: context.Background()},
+	).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, rt.Set("exec", m.Exports().Default))
+
+	_, err := rt.RunString(`
+		exec.events.on("testStart", () => {});
+		exec.events.on("iterationEnd", () => {});
+		exec.events.on("iterationEnd", () => {});
+	`)
+	require.NoError(t, err)
+	assert.Len(t, m.events.handlers["testStart"], 1)
+	assert.Len(t, m.events.handlers["iterationEnd"], 2)
+
+	_, err = rt.RunString(`exec.events.on("iterationStart", () => {})`)
+	require.ErrorContains(t, err, `unknown event "iterationStart"`)
+	_, err = rt.RunString(`exec.events.on("testEnd", "report")`)
+	require.ErrorContains(t, err, "the handler of the testEnd event must be a function")
+
+	rt = goja.New()
+	m, ok = New().NewModuleInstance(
+		&modulestest.VU{RuntimeField: rt, CtxField: context.Background(), StateField: &lib.State{}},
+	).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, rt.Set("exec", m.Exports().Default))
+	_, err = rt.RunString(`exec.events.on("testEnd", () => {})`)
+	require.ErrorContains(t, err, "the event handlers can only be registered in the init context")
+}
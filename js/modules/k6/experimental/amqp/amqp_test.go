@@ -0,0 +1,297 @@
+package amqp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/httpmultibin"
+	"go.k6.io/k6/metrics"
+)
+
+func newTestRuntime(t *testing.T) (*modulestest.Runtime, chan metrics.SampleContainer) {
+	t.Helper()
+	runtime := modulestest.NewRuntime(t)
+	mi, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, runtime.VU.Runtime().Set("amqp", mi.Exports().Named))
+
+	tb := httpmultibin.NewHTTPMultiBin(t)
+	registry := metrics.NewRegistry()
+	samples := make(chan metrics.SampleContainer, 1000)
+	runtime.MoveToVUContext(&lib.State{
+		Dialer:         tb.Dialer,
+		TLSConfig:      tb.TLSClientConfig,
+		Samples:        samples,
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+	})
+	return runtime, samples
+}
+
+func TestPublishConsume(t *testing.T) {
+	t.Parallel()
+	runtime, samples := newTestRuntime(t)
+	broker, addr := newTestBroker(t)
+	require.NoError(t, runtime.VU.Runtime().Set("addr", addr))
+
+	_, err := runtime.RunOnEventLoop(`
+		var client = new amqp.Client({ url: "amqp://" + addr, tags: { name: "orders" } });
+		client.declareExchange("orders", { type: "direct", durable: true });
+		var queue = client.declareQueue("orders-created", { durable: true });
+		if (queue.name !== "orders-created" || queue.messageCount !== 0 || queue.consumerCount !== 0) {
+			throw new Error("unexpected queue: " + JSON.stringify(queue));
+		}
+		client.bindQueue("orders-created", "orders", "created");
+
+		for (var i = 1; i <= 3; i++) {
+			client.publish({
+				exchange: "orders", routingKey: "created", body: JSON.stringify({ id: i }),
+				headers: { source: "k6", attempt: i }, contentType: "application/json", persistent: true,
+				correlationId: "c" + i,
+			});
+		}
+		// the messages of the other routing keys aren't routed to the queue
+		client.publish({ exchange: "orders", routingKey: "cancelled", body: "ignored" });
+
+		var messages = client.consume("orders-created", { limit: 2 });
+		if (messages.length !== 2) {
+			throw new Error("unexpected number of messages: " + messages.length);
+		}
+		var m = messages[1];
+		if (JSON.parse(m.body).id !== 2 || m.exchange !== "orders" || m.routingKey !== "created" ||
+			m.headers.source !== "k6" || m.headers.attempt !== 2 || m.contentType !== "application/json" ||
+			!m.persistent || m.correlationId !== "c2" || m.redelivered || m.binaryBody.byteLength !== 8) {
+			throw new Error("unexpected message: " + JSON.stringify(m));
+		}
+		messages = client.consume("orders-created", { limit: 10, timeout: "100ms" });
+		if (messages.length !== 1 || JSON.parse(messages[0].body).id !== 3) {
+			throw new Error("unexpected messages: " + JSON.stringify(messages));
+		}
+		client.close();
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, 0, broker.queueLen("orders-created"))
+	assert.Equal(t, []string{"/"}, broker.openedVhosts())
+
+	counts := map[string]float64{}
+	for _, c := range metrics.GetBufferedSamples(samples) {
+		for _, s := range c.GetSamples() {
+			counts[s.Metric.Name]++
+			tags := s.Tags.Map()
+			assert.Equal(t, "orders", tags["name"])
+			switch s.Metric.Name {
+			case "amqp_msgs_published", "amqp_publish_confirm_duration":
+				assert.Equal(t, "orders", tags["exchange"])
+			case "amqp_msgs_consumed", "amqp_delivery_latency":
+				assert.Equal(t, "orders-created", tags["queue"])
+				assert.GreaterOrEqual(t, s.Value, float64(0))
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{
+		"amqp_msgs_published":           4,
+		"amqp_publish_confirm_duration": 4,
+		"amqp_msgs_consumed":            3,
+		"amqp_delivery_latency":         3,
+	}, counts)
+}
+
+func TestManualAck(t *testing.T) {
+	t.Parallel()
+	runtime, _ := newTestRuntime(t)
+	broker, addr := newTestBroker(t)
+	require.NoError(t, runtime.VU.Runtime().Set("addr", addr))
+
+	_, err := runtime.RunOnEventLoop(`
+		var client = new amqp.Client({ url: "amqp://" + addr });
+		var queue = client.declareQueue("", { exclusive: true });
+		client.publish({ routingKey: queue.name, body: "first" });
+		client.publish({ routingKey: queue.name, body: "second" });
+
+		var messages = client.consume(queue.name, { limit: 2, ack: false });
+		client.ack(messages[0].deliveryTag);
+		client.nack(messages[1].deliveryTag);
+		messages = client.consume(queue.name, { ack: false });
+		if (messages.length !== 1 || messages[0].body !== "second" || !messages[0].redelivered) {
+			throw new Error("unexpected messages: " + JSON.stringify(messages));
+		}
+		client.nack(messages[0].deliveryTag, { requeue: false });
+		if (client.consume(queue.name, { timeout: "100ms" }).length !== 0) {
+			throw new Error("the rejected message was requeued");
+		}
+		client.close();
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, 0, broker.queueLen("amq.gen-1"))
+}
+
+func TestUnacknowledgedMessagesAreRequeued(t *testing.T) {
+	t.Parallel()
+	runtime, _ := newTestRuntime(t)
+	_, addr := newTestBroker(t)
+	require.NoError(t, runtime.VU.Runtime().Set("addr", addr))
+
+	_, err := runtime.RunOnEventLoop(`
+		var client = new amqp.Client({ url: "amqp://" + addr });
+		client.declareQueue("jobs");
+		client.publish({ routingKey: "jobs", body: "job" });
+		if (client.consume("jobs", { ack: false }).length !== 1) {
+			throw new Error("the message wasn't consumed");
+		}
+		client.close();
+
+		// the client connects again after it's closed
+		var messages = client.consume("jobs");
+		if (messages.length !== 1 || !messages[0].redelivered) {
+			throw new Error("unexpected messages: " + JSON.stringify(messages));
+		}
+		client.close();
+	`)
+	require.NoError(t, err)
+}
+
+func TestLargeBinaryBody(t *testing.T) {
+	t.Parallel()
+	runtime, _ := newTestRuntime(t)
+	_, addr := newTestBroker(t)
+	require.NoError(t, runtime.VU.Runtime().Set("addr", addr))
+
+	// the body is larger than the maximum frame size of the test broker
+	_, err := runtime.RunOnEventLoop(`
+		var client = new amqp.Client({ url: "amqp://" + addr });
+		client.declareExchange("events", { type: "fanout" });
+		client.declareQueue("events-1");
+		client.bindQueue("events-1", "events", "");
+		var body = new Uint8Array(10000);
+		for (var i = 0; i < body.length; i++) {
+			body[i] = i % 256;
+		}
+		client.publish({ exchange: "events", body: body.buffer });
+		var received = new Uint8Array(client.consume("events-1")[0].binaryBody);
+		if (received.length !== body.length || received[9999] !== 9999 % 256) {
+			throw new Error("unexpected body of " + received.length + " bytes");
+		}
+		client.close();
+	`)
+	require.NoError(t, err)
+}
+
+func TestChannelErrors(t *testing.T) {
+	t.Parallel()
+	runtime, _ := newTestRuntime(t)
+	_, addr := newTestBroker(t)
+	require.NoError(t, runtime.VU.Runtime().Set("addr", addr))
+
+	_, err := runtime.RunOnEventLoop(`
+		var client = new amqp.Client({ url: "amqp://" + addr });
+		try {
+			client.publish({ exchange: "missing", routingKey: "key", body: "lost" });
+			throw new Error("the message was published");
+		} catch (e) {
+			if (e.message.indexOf("NOT_FOUND - no exchange 'missing'") === -1) {
+				throw e;
+			}
+		}
+		try {
+			client.declareExchange("amq.fanout", { type: "topic" });
+			throw new Error("the exchange was declared");
+		} catch (e) {
+			if (e.message.indexOf("PRECONDITION_FAILED") === -1 || e.message.indexOf("(406)") === -1) {
+				throw e;
+			}
+		}
+
+		// the channel is reopened after the errors
+		client.declareQueue("tasks");
+		client.publish({ routingKey: "tasks", body: "task" });
+		if (client.consume("tasks").length !== 1) {
+			throw new Error("the message wasn't consumed");
+		}
+
+		// the consumer is cancelled by the broker after its queue is deleted
+		client.deleteQueue("tasks");
+		try {
+			client.consume("tasks", { timeout: "100ms" });
+			throw new Error("the deleted queue was consumed");
+		} catch (e) {
+			if (e.message.indexOf("NOT_FOUND - no queue 'tasks'") === -1) {
+				throw e;
+			}
+		}
+		client.close();
+	`)
+	require.NoError(t, err)
+}
+
+func TestVirtualHostAndCredentials(t *testing.T) {
+	t.Parallel()
+	runtime, _ := newTestRuntime(t)
+	broker, addr := newTestBroker(t)
+	broker.username, broker.password = "k6", "secret"
+	require.NoError(t, runtime.VU.Runtime().Set("addr", addr))
+
+	_, err := runtime.RunOnEventLoop(`
+		var client = new amqp.Client({ url: "amqp://k6:secret@" + addr + "/tests" });
+		client.declareQueue("q");
+		client.close();
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tests"}, broker.openedVhosts())
+
+	_, err = runtime.RunOnEventLoop(`
+		new amqp.Client({ url: "amqp://" + addr }).declareQueue("q");
+	`)
+	require.ErrorContains(t, err, "ACCESS_REFUSED")
+}
+
+func TestClientInInitContext(t *testing.T) {
+	t.Parallel()
+	runtime := modulestest.NewRuntime(t)
+	mi, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, runtime.VU.Runtime().Set("amqp", mi.Exports().Named))
+
+	_, err := runtime.VU.Runtime().RunString(`
+		var client = new amqp.Client({ url: "amqp://localhost" });
+		client.publish({ routingKey: "q", body: "init" });
+	`)
+	require.ErrorContains(t, err, "publishing AMQP messages in the init context is not supported")
+}
+
+func TestInvalidConfig(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name, script, err string
+	}{
+		{"missing config", `new amqp.Client()`, "missing config"},
+		{"missing url", `new amqp.Client({ timeout: "1s" })`, "the url is required"},
+		{"scheme", `new amqp.Client({ url: "http://localhost" })`, "unsupported scheme 'http'"},
+		{"unknown param", `new amqp.Client({ url: "amqp://localhost", queue: "q" })`, "unknown param 'queue'"},
+		{"prefetch", `new amqp.Client({ url: "amqp://localhost", prefetch: 0 })`, "invalid prefetch value"},
+		{
+			"publish param", `new amqp.Client({ url: "amqp://localhost" }).publish({ routingKey: "q", key: "k" })`,
+			"invalid publish() message: unknown param 'key'",
+		},
+		{
+			"priority", `new amqp.Client({ url: "amqp://localhost" }).publish({ routingKey: "q", priority: 10 })`,
+			"invalid priority value",
+		},
+		{
+			"consume limit", `new amqp.Client({ url: "amqp://localhost" }).consume("q", { limit: 0 })`,
+			"invalid consume() parameters: invalid limit value",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			runtime, _ := newTestRuntime(t)
+			_, err := runtime.RunOnEventLoop(tc.script)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,484 @@
+package amqp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testBroker is a minimal AMQP broker with the direct and fanout exchanges,
+// which acknowledges all the published messages, and delivers the messages
+// of the queues to their consumers without a prefetch limit.
+type testBroker struct {
+	username string
+	password string
+
+	mx        sync.Mutex
+	vhosts    []string
+	exchanges map[string]*testExchange
+	queues    map[string]*testQueue
+	lastQueue int
+}
+
+type testExchange struct {
+	typ      string
+	bindings []testBinding
+}
+
+type testBinding struct {
+	queue      string
+	routingKey string
+}
+
+type testQueue struct {
+	messages  []*testMessage
+	consumers []testConsumer
+}
+
+type testConsumer struct {
+	conn *testConn
+	tag  string
+}
+
+type testMessage struct {
+	exchange    string
+	routingKey  string
+	header      []byte
+	body        []byte
+	redelivered bool
+}
+
+// newTestBroker starts a broker with the default credentials and returns
+// its address.
+func newTestBroker(t *testing.T) (*testBroker, string) {
+	t.Helper()
+	b := &testBroker{
+		username:  "guest",
+		password:  "guest",
+		exchanges: map[string]*testExchange{"": {typ: "direct"}, "amq.fanout": {typ: "fanout"}},
+		queues:    make(map[string]*testQueue),
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = lis.Close() })
+	go func() {
+		for {
+			nc, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go (&testConn{broker: b, nc: nc, unacked: make(map[uint64]*unackedMessage)}).serve()
+		}
+	}()
+	return b, lis.Addr().String()
+}
+
+// queueLen returns the number of the ready messages of the queue.
+func (b *testBroker) queueLen(name string) int {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	if q, ok := b.queues[name]; ok {
+		return len(q.messages)
+	}
+	return -1
+}
+
+// openedVhosts returns the virtual hosts of the opened connections.
+func (b *testBroker) openedVhosts() []string {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	return append([]string(nil), b.vhosts...)
+}
+
+// dispatch delivers the ready messages to the consumers, it's called with
+// the lock.
+func (b *testBroker) dispatch() {
+	for _, q := range b.queues {
+		for len(q.messages) > 0 && len(q.consumers) > 0 {
+			m := q.messages[0]
+			q.messages = q.messages[1:]
+			c := q.consumers[0]
+			// the consumers receive the messages in turns
+			q.consumers = append(q.consumers[1:], c)
+			c.conn.deliver(c.tag, q, m)
+		}
+	}
+}
+
+type unackedMessage struct {
+	queue   *testQueue
+	message *testMessage
+}
+
+// testConn is a connection of the broker with one channel.
+type testConn struct {
+	broker *testBroker
+	nc     net.Conn
+
+	writeMx sync.Mutex
+	// the fields below are guarded by the lock of the broker
+	closing     bool
+	publishSeq  uint64
+	deliveryTag uint64
+	unacked     map[uint64]*unackedMessage
+}
+
+func (c *testConn) write(typ byte, channel uint16, payload []byte) {
+	c.writeMx.Lock()
+	defer c.writeMx.Unlock()
+	_, _ = c.nc.Write(appendFrame(nil, typ, channel, payload))
+}
+
+func (c *testConn) writeMethod(channel uint16, e *encoder) {
+	c.write(frameMethod, channel, e.buf)
+}
+
+// closeChannel closes the channel with an error, like RabbitMQ.
+func (c *testConn) closeChannel(code int, text string) {
+	c.closing = true
+	e := &encoder{}
+	e.method(methodChannelClose)
+	e.uint16(uint16(code))
+	e.shortString(text)
+	e.uint16(0)
+	e.uint16(0)
+	c.writeMethod(channelID, e)
+	c.release()
+}
+
+// release requeues the unacknowledged messages and removes the consumers.
+func (c *testConn) release() {
+	for tag, u := range c.unacked {
+		u.message.redelivered = true
+		u.queue.messages = append([]*testMessage{u.message}, u.queue.messages...)
+		delete(c.unacked, tag)
+	}
+	for _, q := range c.broker.queues {
+		consumers := q.consumers[:0]
+		for _, consumer := range q.consumers {
+			if consumer.conn != c {
+				consumers = append(consumers, consumer)
+			}
+		}
+		q.consumers = consumers
+	}
+	c.broker.dispatch()
+}
+
+func (c *testConn) deliver(tag string, q *testQueue, m *testMessage) {
+	c.deliveryTag++
+	c.unacked[c.deliveryTag] = &unackedMessage{queue: q, message: m}
+	e := &encoder{}
+	e.method(methodBasicDeliver)
+	e.shortString(tag)
+	e.uint64(c.deliveryTag)
+	e.bit(m.redelivered)
+	e.shortString(m.exchange)
+	e.shortString(m.routingKey)
+	c.writeMethod(channelID, e)
+	c.write(frameHeader, channelID, m.header)
+	c.write(frameBody, channelID, m.body)
+}
+
+func (c *testConn) serve() {
+	defer func() {
+		_ = c.nc.Close()
+		c.broker.mx.Lock()
+		c.release()
+		c.broker.mx.Unlock()
+	}()
+	r := bufio.NewReader(c.nc)
+	header := make([]byte, len(protocolHeader))
+	if _, err := io.ReadFull(r, header); err != nil || string(header) != protocolHeader {
+		return
+	}
+	if !c.handshake(r) {
+		return
+	}
+
+	var publish *testMessage
+	var remaining int
+	for {
+		f, err := readFrame(r)
+		if err != nil {
+			return
+		}
+		switch f.typ {
+		case frameMethod:
+			d := &decoder{buf: f.payload}
+			method := d.uint32()
+			if f.channel == 0 {
+				if method == methodConnectionClose {
+					e := &encoder{}
+					e.method(methodConnectionCloseOk)
+					c.writeMethod(0, e)
+					return
+				}
+				continue
+			}
+			publish = c.handleMethod(method, d)
+		case frameHeader:
+			if publish == nil {
+				continue
+			}
+			publish.header = f.payload
+			remaining, _, _ = decodeHeader(f.payload)
+			publish.body = []byte{}
+		case frameBody:
+			if publish == nil {
+				continue
+			}
+			publish.body = append(publish.body, f.payload...)
+			remaining -= len(f.payload)
+		}
+		if publish != nil && publish.body != nil && remaining <= 0 {
+			c.route(publish)
+			publish = nil
+		}
+	}
+}
+
+func (c *testConn) handshake(r *bufio.Reader) bool {
+	e := &encoder{}
+	e.method(methodConnectionStart)
+	e.octet(0)
+	e.octet(9)
+	_ = e.table(map[string]interface{}{"product": "test"})
+	e.longString([]byte("AMQPLAIN PLAIN"))
+	e.longString([]byte("en_US"))
+	c.writeMethod(0, e)
+
+	d, err := c.readMethod(r, methodConnectionStartOk)
+	if err != nil {
+		return false
+	}
+	d.table()
+	d.shortString()
+	if string(d.longString()) != "\x00"+c.broker.username+"\x00"+c.broker.password {
+		e = &encoder{}
+		e.method(methodConnectionClose)
+		e.uint16(403)
+		e.shortString("ACCESS_REFUSED - Login was refused using authentication mechanism PLAIN")
+		e.uint16(0)
+		e.uint16(0)
+		c.writeMethod(0, e)
+		return false
+	}
+
+	e = &encoder{}
+	e.method(methodConnectionTune)
+	e.uint16(2047)
+	e.uint32(4096)
+	e.uint16(60)
+	c.writeMethod(0, e)
+	if _, err = c.readMethod(r, methodConnectionTuneOk); err != nil {
+		return false
+	}
+	if d, err = c.readMethod(r, methodConnectionOpen); err != nil {
+		return false
+	}
+	c.broker.mx.Lock()
+	c.broker.vhosts = append(c.broker.vhosts, d.shortString())
+	c.broker.mx.Unlock()
+	e = &encoder{}
+	e.method(methodConnectionOpenOk)
+	e.shortString("")
+	c.writeMethod(0, e)
+	return true
+}
+
+func (c *testConn) readMethod(r *bufio.Reader, expected uint32) (*decoder, error) {
+	for {
+		f, err := readFrame(r)
+		if err != nil {
+			return nil, err
+		}
+		if f.typ != frameMethod {
+			continue
+		}
+		d := &decoder{buf: f.payload}
+		if method := d.uint32(); method != expected {
+			return nil, fmt.Errorf("unexpected method %d", method)
+		}
+		return d, nil
+	}
+}
+
+// handleMethod handles a method of the channel, and it returns the message
+// of a basic.publish, whose content follows.
+func (c *testConn) handleMethod(method uint32, d *decoder) *testMessage { //nolint:cyclop,funlen
+	b := c.broker
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	// the methods are ignored after the channel is closed, until the client
+	// confirms its closing
+	if c.closing {
+		if method == methodChannelCloseOk {
+			c.closing = false
+		}
+		return nil
+	}
+
+	reply := &encoder{}
+	switch method {
+	case methodChannelOpen:
+		c.publishSeq = 0
+		reply.method(methodChannelOpenOk)
+		reply.longString(nil)
+	case methodConfirmSelect:
+		reply.method(methodConfirmSelectOk)
+	case methodExchangeDeclare:
+		d.uint16()
+		name, typ := d.shortString(), d.shortString()
+		if x, ok := b.exchanges[name]; ok && x.typ != typ {
+			c.closeChannel(406, fmt.Sprintf(
+				"PRECONDITION_FAILED - inequivalent arg 'type' for exchange '%s' in vhost '/'", name))
+			return nil
+		}
+		if _, ok := b.exchanges[name]; !ok {
+			b.exchanges[name] = &testExchange{typ: typ}
+		}
+		reply.method(methodExchangeDeclareOk)
+	case methodExchangeDelete:
+		d.uint16()
+		delete(b.exchanges, d.shortString())
+		reply.method(methodExchangeDeleteOk)
+	case methodQueueDeclare:
+		d.uint16()
+		name := d.shortString()
+		if name == "" {
+			b.lastQueue++
+			name = fmt.Sprintf("amq.gen-%d", b.lastQueue)
+		}
+		q, ok := b.queues[name]
+		if !ok {
+			q = &testQueue{}
+			b.queues[name] = q
+		}
+		reply.method(methodQueueDeclareOk)
+		reply.shortString(name)
+		reply.uint32(uint32(len(q.messages)))
+		reply.uint32(uint32(len(q.consumers)))
+	case methodQueueBind:
+		d.uint16()
+		queue, exchange, routingKey := d.shortString(), d.shortString(), d.shortString()
+		x, ok := b.exchanges[exchange]
+		if !ok {
+			c.closeChannel(404, fmt.Sprintf("NOT_FOUND - no exchange '%s' in vhost '/'", exchange))
+			return nil
+		}
+		x.bindings = append(x.bindings, testBinding{queue: queue, routingKey: routingKey})
+		reply.method(methodQueueBindOk)
+	case methodQueueDelete:
+		d.uint16()
+		name := d.shortString()
+		q := b.queues[name]
+		delete(b.queues, name)
+		var count int
+		if q != nil {
+			count = len(q.messages)
+			for _, consumer := range q.consumers {
+				e := &encoder{}
+				e.method(methodBasicCancel)
+				e.shortString(consumer.tag)
+				e.bit(true)
+				consumer.conn.writeMethod(channelID, e)
+			}
+		}
+		reply.method(methodQueueDeleteOk)
+		reply.uint32(uint32(count))
+	case methodBasicQos:
+		reply.method(methodBasicQosOk)
+	case methodBasicConsume:
+		d.uint16()
+		name, tag := d.shortString(), d.shortString()
+		q, ok := b.queues[name]
+		if !ok {
+			c.closeChannel(404, fmt.Sprintf("NOT_FOUND - no queue '%s' in vhost '/'", name))
+			return nil
+		}
+		q.consumers = append(q.consumers, testConsumer{conn: c, tag: tag})
+		reply.method(methodBasicConsumeOk)
+		reply.shortString(tag)
+		c.writeMethod(channelID, reply)
+		b.dispatch()
+		return nil
+	case methodBasicAck:
+		tag, multiple := d.uint64(), d.bit()
+		c.settle(tag, multiple, false, false)
+		return nil
+	case methodBasicNack:
+		tag, multiple := d.uint64(), d.bit()
+		requeue := d.bit()
+		c.settle(tag, multiple, true, requeue)
+		return nil
+	case methodBasicPublish:
+		d.uint16()
+		return &testMessage{exchange: d.shortString(), routingKey: d.shortString()}
+	default:
+		return nil
+	}
+	c.writeMethod(channelID, reply)
+	return nil
+}
+
+// settle acknowledges or rejects the deliveries.
+func (c *testConn) settle(tag uint64, multiple, reject, requeue bool) {
+	for t, u := range c.unacked {
+		if t != tag && (!multiple || t > tag) {
+			continue
+		}
+		delete(c.unacked, t)
+		if reject && requeue {
+			u.message.redelivered = true
+			u.queue.messages = append(u.queue.messages, u.message)
+		}
+	}
+	c.broker.dispatch()
+}
+
+// route routes the published message to the queues, and it confirms it.
+func (c *testConn) route(m *testMessage) {
+	b := c.broker
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	if c.closing {
+		return
+	}
+	x, ok := b.exchanges[m.exchange]
+	if !ok {
+		c.closeChannel(404, fmt.Sprintf("NOT_FOUND - no exchange '%s' in vhost '/'", m.exchange))
+		return
+	}
+	var queues []string
+	switch {
+	case m.exchange == "":
+		queues = []string{m.routingKey}
+	default:
+		for _, binding := range x.bindings {
+			if x.typ == "fanout" || binding.routingKey == m.routingKey {
+				queues = append(queues, binding.queue)
+			}
+		}
+	}
+	for _, name := range queues {
+		if q, ok := b.queues[name]; ok {
+			copied := *m
+			q.messages = append(q.messages, &copied)
+		}
+	}
+
+	c.publishSeq++
+	e := &encoder{}
+	e.method(methodBasicAck)
+	e.uint64(c.publishSeq)
+	e.bit(false)
+	c.writeMethod(channelID, e)
+	b.dispatch()
+}
@@ -0,0 +1,739 @@
+package amqp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	defaultTimeout   = 10 * time.Second
+	defaultHeartbeat = 60 * time.Second
+	defaultPrefetch  = 100
+)
+
+// publishedAtHeader is the header of the published messages with their
+// publishing time in Unix nanoseconds, it's used to measure the end-to-end
+// delivery latency of the consumed messages.
+const publishedAtHeader = "k6-published-at"
+
+// config is the config of a client.
+type config struct {
+	url       *url.URL
+	timeout   time.Duration
+	heartbeat time.Duration
+	prefetch  int
+	tls       *tlsParams
+	tags      map[string]string
+}
+
+type tlsParams struct {
+	serverName         string
+	insecureSkipVerify bool
+}
+
+// Client is a client of an AMQP broker, its connection is opened on the
+// first use, and it's reopened if it's closed.
+type Client struct {
+	mi     *ModuleInstance
+	config config
+	conn   *conn
+	// consumers are the consumers of the queues, which are started by
+	// their first consume
+	consumers map[string]*consumer
+}
+
+// parseParams calls the parse function with the params of the input object,
+// the function returns false for the unknown params.
+func parseParams(rt *goja.Runtime, input goja.Value, parse func(k string, v goja.Value) (bool, error)) error {
+	if common.IsNullish(input) {
+		return nil
+	}
+	raw := input.ToObject(rt)
+	for _, k := range raw.Keys() {
+		ok, err := parse(k, raw.Get(k))
+		if err != nil {
+			return fmt.Errorf("invalid %s value: %w", k, err)
+		}
+		if !ok {
+			return fmt.Errorf("unknown param '%s'", k)
+		}
+	}
+	return nil
+}
+
+func newClient(mi *ModuleInstance, input goja.Value) (*Client, error) {
+	rt := mi.vu.Runtime()
+	c := config{timeout: defaultTimeout, heartbeat: defaultHeartbeat, prefetch: defaultPrefetch}
+	if common.IsNullish(input) {
+		return nil, errors.New("invalid Client config: missing config, the url is required")
+	}
+	err := parseParams(rt, input, func(k string, v goja.Value) (bool, error) {
+		var err error
+		switch k {
+		case "url":
+			c.url, err = parseURL(v.String())
+		case "timeout":
+			c.timeout, err = types.GetDurationValue(v.Export())
+		case "heartbeat":
+			c.heartbeat, err = types.GetDurationValue(v.Export())
+		case "prefetch":
+			c.prefetch = int(v.ToInteger())
+			if c.prefetch <= 0 || c.prefetch > 65535 {
+				err = errors.New("it needs to be between 1 and 65535")
+			}
+		case "tls":
+			obj := v.ToObject(rt)
+			c.tls = &tlsParams{
+				serverName:         obj.Get("serverName").String(),
+				insecureSkipVerify: obj.Get("insecureSkipVerify").ToBoolean(),
+			}
+			if common.IsNullish(obj.Get("serverName")) {
+				c.tls.serverName = ""
+			}
+		case "tags":
+			err = rt.ExportTo(v, &c.tags)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid Client config: %w", err)
+	}
+	if c.url == nil {
+		return nil, errors.New("invalid Client config: the url is required")
+	}
+	if c.timeout <= 0 {
+		return nil, fmt.Errorf("invalid Client config: invalid timeout value: %s", c.timeout)
+	}
+	return &Client{mi: mi, config: c, consumers: make(map[string]*consumer)}, nil
+}
+
+func parseURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "amqp" && u.Scheme != "amqps" {
+		return nil, fmt.Errorf("unsupported scheme '%s', it needs to be amqp or amqps", u.Scheme)
+	}
+	return u, nil
+}
+
+// connParams returns the params of the handshake, the default credentials
+// and virtual host are the ones of RabbitMQ.
+func (c config) connParams() connParams {
+	p := connParams{username: "guest", password: "guest", vhost: "/", heartbeat: c.heartbeat}
+	if c.url.User != nil {
+		p.username = c.url.User.Username()
+		p.password, _ = c.url.User.Password()
+	}
+	if vhost := strings.TrimPrefix(c.url.Path, "/"); vhost != "" {
+		p.vhost = vhost
+	}
+	return p
+}
+
+// init opens the connection if it isn't open, and it returns the tags and
+// the metadata of the metrics.
+func (c *Client) init(ctx context.Context, action string) (*lib.State, metrics.TagsAndMeta, error) {
+	state := c.mi.vu.State()
+	if state == nil {
+		return nil, metrics.TagsAndMeta{}, common.NewInitContextError(
+			fmt.Sprintf("%s in the init context is not supported", action))
+	}
+	ctm := state.Tags.GetCurrentValues()
+	tags := ctm.Tags
+	for k, v := range c.config.tags {
+		tags = tags.With(k, v)
+	}
+	tm := metrics.TagsAndMeta{Tags: tags, Metadata: ctm.Metadata}
+
+	if c.conn != nil && c.conn.err() != nil {
+		c.conn = nil
+		c.consumers = make(map[string]*consumer)
+	}
+	if c.conn != nil {
+		return state, tm, nil
+	}
+
+	u := c.config.url
+	port := "5672"
+	if u.Scheme == "amqps" {
+		port = "5671"
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	address := net.JoinHostPort(u.Hostname(), port)
+	nc, err := state.Dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, tm, err
+	}
+	if u.Scheme == "amqps" {
+		// the TLS config of the VU has the tlsAuth and the other TLS options
+		config := &tls.Config{MinVersion: tls.VersionTLS12} //nolint:gosec
+		if state.TLSConfig != nil {
+			config = state.TLSConfig.Clone()
+		}
+		config.ServerName = u.Hostname()
+		if c.config.tls != nil {
+			if c.config.tls.serverName != "" {
+				config.ServerName = c.config.tls.serverName
+			}
+			config.InsecureSkipVerify = c.config.tls.insecureSkipVerify
+		}
+		tlsConn := tls.Client(nc, config)
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			_ = nc.Close()
+			return nil, tm, fmt.Errorf("TLS handshake with %s failed: %w", address, err)
+		}
+		nc = tlsConn
+	}
+	if c.conn, err = openConn(ctx, nc, c.config.connParams()); err != nil {
+		_ = nc.Close()
+		return nil, tm, err
+	}
+	return state, tm, nil
+}
+
+func (c *Client) push(state *lib.State, metric *metrics.Metric, tags *metrics.TagSet, tm metrics.TagsAndMeta,
+	t time.Time, value float64,
+) {
+	metrics.PushIfNotDone(c.mi.vu.Context(), state.Samples, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{Metric: metric, Tags: tags},
+		Time:       t,
+		Metadata:   tm.Metadata,
+		Value:      value,
+	})
+}
+
+func (c *Client) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(c.mi.vu.Context(), c.config.timeout)
+}
+
+// DeclareExchange declares the exchange, which is created if it doesn't
+// exist, the type is direct by default.
+func (c *Client) DeclareExchange(name string, params goja.Value) error {
+	typ := "direct"
+	var durable, autoDelete, internal bool
+	var arguments map[string]interface{}
+	rt := c.mi.vu.Runtime()
+	err := parseParams(rt, params, func(k string, v goja.Value) (bool, error) {
+		switch k {
+		case "type":
+			typ = v.String()
+		case "durable":
+			durable = v.ToBoolean()
+		case "autoDelete":
+			autoDelete = v.ToBoolean()
+		case "internal":
+			internal = v.ToBoolean()
+		case "arguments":
+			return true, rt.ExportTo(v, &arguments)
+		default:
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("invalid declareExchange() parameters: %w", err)
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+	if _, _, err = c.init(ctx, "declaring AMQP exchanges"); err != nil {
+		return err
+	}
+	e := &encoder{}
+	e.method(methodExchangeDeclare)
+	e.uint16(0)
+	e.shortString(name)
+	e.shortString(typ)
+	e.bit(false) // passive
+	e.bit(durable)
+	e.bit(autoDelete)
+	e.bit(internal)
+	e.bit(false) // no-wait
+	if err = e.table(arguments); err != nil {
+		return fmt.Errorf("invalid declareExchange() arguments: %w", err)
+	}
+	_, err = c.conn.call(ctx, e, methodExchangeDeclareOk)
+	return err
+}
+
+// DeleteExchange deletes the exchange.
+func (c *Client) DeleteExchange(name string, params goja.Value) error {
+	var ifUnused bool
+	err := parseParams(c.mi.vu.Runtime(), params, func(k string, v goja.Value) (bool, error) {
+		if k != "ifUnused" {
+			return false, nil
+		}
+		ifUnused = v.ToBoolean()
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("invalid deleteExchange() parameters: %w", err)
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+	if _, _, err = c.init(ctx, "deleting AMQP exchanges"); err != nil {
+		return err
+	}
+	e := &encoder{}
+	e.method(methodExchangeDelete)
+	e.uint16(0)
+	e.shortString(name)
+	e.bit(ifUnused)
+	e.bit(false) // no-wait
+	_, err = c.conn.call(ctx, e, methodExchangeDeleteOk)
+	return err
+}
+
+// DeclaredQueue is the result of the declaration of a queue, the name is
+// generated by the broker if the declared one is empty.
+type DeclaredQueue struct {
+	Name          string `js:"name"`
+	MessageCount  int    `js:"messageCount"`
+	ConsumerCount int    `js:"consumerCount"`
+}
+
+// DeclareQueue declares the queue, which is created if it doesn't exist.
+func (c *Client) DeclareQueue(name string, params goja.Value) (*DeclaredQueue, error) {
+	var durable, exclusive, autoDelete bool
+	var arguments map[string]interface{}
+	rt := c.mi.vu.Runtime()
+	err := parseParams(rt, params, func(k string, v goja.Value) (bool, error) {
+		switch k {
+		case "durable":
+			durable = v.ToBoolean()
+		case "exclusive":
+			exclusive = v.ToBoolean()
+		case "autoDelete":
+			autoDelete = v.ToBoolean()
+		case "arguments":
+			return true, rt.ExportTo(v, &arguments)
+		default:
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid declareQueue() parameters: %w", err)
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+	if _, _, err = c.init(ctx, "declaring AMQP queues"); err != nil {
+		return nil, err
+	}
+	e := &encoder{}
+	e.method(methodQueueDeclare)
+	e.uint16(0)
+	e.shortString(name)
+	e.bit(false) // passive
+	e.bit(durable)
+	e.bit(exclusive)
+	e.bit(autoDelete)
+	e.bit(false) // no-wait
+	if err = e.table(arguments); err != nil {
+		return nil, fmt.Errorf("invalid declareQueue() arguments: %w", err)
+	}
+	d, err := c.conn.call(ctx, e, methodQueueDeclareOk)
+	if err != nil {
+		return nil, err
+	}
+	q := &DeclaredQueue{Name: d.shortString(), MessageCount: int(d.uint32()), ConsumerCount: int(d.uint32())}
+	return q, d.err
+}
+
+// DeleteQueue deletes the queue, and it returns the number of its messages
+// that were deleted.
+func (c *Client) DeleteQueue(name string, params goja.Value) (int, error) {
+	var ifUnused, ifEmpty bool
+	err := parseParams(c.mi.vu.Runtime(), params, func(k string, v goja.Value) (bool, error) {
+		switch k {
+		case "ifUnused":
+			ifUnused = v.ToBoolean()
+		case "ifEmpty":
+			ifEmpty = v.ToBoolean()
+		default:
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("invalid deleteQueue() parameters: %w", err)
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+	if _, _, err = c.init(ctx, "deleting AMQP queues"); err != nil {
+		return 0, err
+	}
+	e := &encoder{}
+	e.method(methodQueueDelete)
+	e.uint16(0)
+	e.shortString(name)
+	e.bit(ifUnused)
+	e.bit(ifEmpty)
+	e.bit(false) // no-wait
+	d, err := c.conn.call(ctx, e, methodQueueDeleteOk)
+	if err != nil {
+		return 0, err
+	}
+	delete(c.consumers, name)
+	return int(d.uint32()), d.err
+}
+
+// BindQueue binds the queue to the exchange with the routing key.
+func (c *Client) BindQueue(queue, exchange, routingKey string, params goja.Value) error {
+	var arguments map[string]interface{}
+	rt := c.mi.vu.Runtime()
+	err := parseParams(rt, params, func(k string, v goja.Value) (bool, error) {
+		if k != "arguments" {
+			return false, nil
+		}
+		return true, rt.ExportTo(v, &arguments)
+	})
+	if err != nil {
+		return fmt.Errorf("invalid bindQueue() parameters: %w", err)
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+	if _, _, err = c.init(ctx, "binding AMQP queues"); err != nil {
+		return err
+	}
+	e := &encoder{}
+	e.method(methodQueueBind)
+	e.uint16(0)
+	e.shortString(queue)
+	e.shortString(exchange)
+	e.shortString(routingKey)
+	e.bit(false) // no-wait
+	if err = e.table(arguments); err != nil {
+		return fmt.Errorf("invalid bindQueue() arguments: %w", err)
+	}
+	_, err = c.conn.call(ctx, e, methodQueueBindOk)
+	return err
+}
+
+// publishParams are the params of a published message.
+type publishParams struct {
+	exchange   string
+	routingKey string
+	body       []byte
+	props      properties
+}
+
+func (c *Client) newPublishParams(input goja.Value) (publishParams, error) { //nolint:cyclop
+	rt := c.mi.vu.Runtime()
+	var p publishParams
+	if common.IsNullish(input) {
+		return p, errors.New("missing message")
+	}
+	err := parseParams(rt, input, func(k string, v goja.Value) (bool, error) {
+		var err error
+		switch k {
+		case "exchange":
+			p.exchange = v.String()
+		case "routingKey":
+			p.routingKey = v.String()
+		case "body":
+			p.body, err = common.ToBytes(v.Export())
+		case "headers":
+			err = rt.ExportTo(v, &p.props.headers)
+		case "contentType":
+			p.props.contentType = v.String()
+		case "contentEncoding":
+			p.props.contentEncoding = v.String()
+		case "persistent":
+			if v.ToBoolean() {
+				p.props.deliveryMode = 2
+			}
+		case "priority":
+			priority := v.ToInteger()
+			if priority < 0 || priority > 9 {
+				err = errors.New("it needs to be between 0 and 9")
+			}
+			p.props.priority = byte(priority)
+		case "correlationId":
+			p.props.correlationID = v.String()
+		case "replyTo":
+			p.props.replyTo = v.String()
+		case "expiration":
+			var d time.Duration
+			if d, err = types.GetDurationValue(v.Export()); err == nil {
+				p.props.expiration = fmt.Sprint(d.Milliseconds())
+			}
+		case "messageId":
+			p.props.messageID = v.String()
+		case "type":
+			p.props.messageType = v.String()
+		case "appId":
+			p.props.appID = v.String()
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+	return p, err
+}
+
+// Publish publishes the message, and it waits for its confirmation by the
+// broker, the message is published with the publishing time as a header.
+func (c *Client) Publish(input goja.Value) error {
+	p, err := c.newPublishParams(input)
+	if err != nil {
+		return fmt.Errorf("invalid publish() message: %w", err)
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+	state, tm, err := c.init(ctx, "publishing AMQP messages")
+	if err != nil {
+		return err
+	}
+
+	headers := make(map[string]interface{}, len(p.props.headers)+1)
+	for k, v := range p.props.headers {
+		headers[k] = v
+	}
+	start := time.Now()
+	headers[publishedAtHeader] = start.UnixNano()
+	p.props.headers = headers
+	p.props.timestamp = start
+
+	ch, err := c.conn.publish(ctx, p.exchange, p.routingKey, false, p.props, p.body)
+	if err != nil {
+		return err
+	}
+	var confirm confirm
+	select {
+	case confirm = <-ch:
+	case <-ctx.Done():
+		return fmt.Errorf("the message wasn't confirmed by the broker: %w", ctx.Err())
+	}
+	if confirm.err != nil {
+		return confirm.err
+	}
+	if !confirm.ack {
+		return errors.New("the message was rejected by the broker")
+	}
+
+	tags := tm.Tags.With("exchange", p.exchange)
+	c.push(state, c.mi.metrics.MessagesPublished, tags, tm, confirm.at, 1)
+	c.push(state, c.mi.metrics.PublishConfirm, tags, tm, confirm.at, metrics.D(confirm.at.Sub(start)))
+	return nil
+}
+
+// consumeParams are the params of the consume method.
+type consumeParams struct {
+	limit   int
+	timeout time.Duration
+	ack     bool
+}
+
+func (c *Client) newConsumeParams(input goja.Value) (consumeParams, error) {
+	params := consumeParams{limit: 1, timeout: c.config.timeout, ack: true}
+	err := parseParams(c.mi.vu.Runtime(), input, func(k string, v goja.Value) (bool, error) {
+		var err error
+		switch k {
+		case "limit":
+			params.limit = int(v.ToInteger())
+			if params.limit <= 0 {
+				err = errors.New("it needs to be greater than 0")
+			}
+		case "timeout":
+			params.timeout, err = types.GetDurationValue(v.Export())
+		case "ack":
+			params.ack = v.ToBoolean()
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+	return params, err
+}
+
+// Consume returns the next messages of the queue, up to the limit. It waits
+// for the messages until the timeout, and it returns the ones that are
+// consumed until then, which can be none.
+//
+// The messages are acknowledged, unless the ack param is false, then they
+// need to be acknowledged with ack or nack, else they are redelivered after
+// the client is closed.
+func (c *Client) Consume(queue string, input goja.Value) ([]*goja.Object, error) {
+	params, err := c.newConsumeParams(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid consume() parameters: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(c.mi.vu.Context(), params.timeout)
+	defer cancel()
+	state, tm, err := c.init(ctx, "consuming AMQP messages")
+	if err != nil {
+		return nil, err
+	}
+	// a consumer that was cancelled, like after an error of the channel, is
+	// started again
+	cons, ok := c.consumers[queue]
+	if !ok || cons.drained() {
+		if _, cons, err = c.conn.consume(ctx, queue, c.config.prefetch); err != nil {
+			return nil, err
+		}
+		c.consumers[queue] = cons
+	}
+
+	var deliveries []*delivery
+	for len(deliveries) < params.limit {
+		taken, cancelled := cons.take(params.limit - len(deliveries))
+		deliveries = append(deliveries, taken...)
+		if len(deliveries) == params.limit {
+			break
+		}
+		if cancelled {
+			delete(c.consumers, queue)
+			if len(deliveries) == 0 {
+				if err = c.conn.err(); err == nil {
+					err = fmt.Errorf("the consumer of the queue '%s' was cancelled by the broker", queue)
+				}
+				return nil, err
+			}
+			break
+		}
+		select {
+		case <-cons.notify:
+			continue
+		case <-ctx.Done():
+		case <-c.conn.done:
+		}
+		break
+	}
+
+	tags := tm.Tags.With("queue", queue)
+	result := make([]*goja.Object, 0, len(deliveries))
+	for _, d := range deliveries {
+		if params.ack {
+			if err = c.conn.ack(d.deliveryTag, true, false, false); err != nil {
+				return nil, err
+			}
+		}
+		obj, err := c.toObject(d)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, obj)
+
+		c.push(state, c.mi.metrics.MessagesConsumed, tags, tm, d.received, 1)
+		if publishedAt, ok := d.props.headers[publishedAtHeader].(int64); ok {
+			latency := d.received.Sub(time.Unix(0, publishedAt))
+			c.push(state, c.mi.metrics.DeliveryLatency, tags, tm, d.received, metrics.D(latency))
+		}
+	}
+	return result, nil
+}
+
+// toObject returns the JS object of a delivery, the body is a string, and
+// an ArrayBuffer as binaryBody.
+func (c *Client) toObject(d *delivery) (*goja.Object, error) {
+	rt := c.mi.vu.Runtime()
+	obj := rt.NewObject()
+	headers := make(map[string]interface{}, len(d.props.headers))
+	for k, v := range d.props.headers {
+		if k != publishedAtHeader {
+			headers[k] = v
+		}
+	}
+	for k, v := range map[string]interface{}{
+		"body":          string(d.body),
+		"binaryBody":    rt.NewArrayBuffer(d.body),
+		"exchange":      d.exchange,
+		"routingKey":    d.routingKey,
+		"deliveryTag":   d.deliveryTag,
+		"redelivered":   d.redelivered,
+		"headers":       headers,
+		"contentType":   d.props.contentType,
+		"correlationId": d.props.correlationID,
+		"replyTo":       d.props.replyTo,
+		"messageId":     d.props.messageID,
+		"type":          d.props.messageType,
+		"appId":         d.props.appID,
+		"priority":      d.props.priority,
+		"persistent":    d.props.deliveryMode == 2,
+	} {
+		if err := obj.Set(k, v); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+// Ack acknowledges the delivery of a message consumed without ack, and
+// the previous ones too if multiple is true.
+func (c *Client) Ack(deliveryTag int64, params goja.Value) error {
+	var multiple bool
+	err := parseParams(c.mi.vu.Runtime(), params, func(k string, v goja.Value) (bool, error) {
+		if k != "multiple" {
+			return false, nil
+		}
+		multiple = v.ToBoolean()
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("invalid ack() parameters: %w", err)
+	}
+	if c.conn == nil {
+		return errors.New("the client isn't connected")
+	}
+	return c.conn.ack(uint64(deliveryTag), true, multiple, false)
+}
+
+// Nack rejects the delivery of a message consumed without ack, the message
+// is requeued unless requeue is false.
+func (c *Client) Nack(deliveryTag int64, params goja.Value) error {
+	var multiple bool
+	requeue := true
+	err := parseParams(c.mi.vu.Runtime(), params, func(k string, v goja.Value) (bool, error) {
+		switch k {
+		case "multiple":
+			multiple = v.ToBoolean()
+		case "requeue":
+			requeue = v.ToBoolean()
+		default:
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("invalid nack() parameters: %w", err)
+	}
+	if c.conn == nil {
+		return errors.New("the client isn't connected")
+	}
+	return c.conn.ack(uint64(deliveryTag), false, multiple, requeue)
+}
+
+// Close closes the connection, it's reopened if the client is used again.
+func (c *Client) Close() {
+	if c.conn != nil {
+		c.conn.close()
+		c.conn = nil
+		c.consumers = make(map[string]*consumer)
+	}
+}
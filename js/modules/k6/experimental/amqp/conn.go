@@ -0,0 +1,660 @@
+package amqp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// channelID is the only channel of the connections, the operations of a
+// client are made one at a time.
+const channelID uint16 = 1
+
+// defaultFrameMax is the maximum size of the frames proposed by the client.
+const defaultFrameMax = 128 * 1024
+
+// errConnClosed is the error of the operations after the connection is closed.
+var errConnClosed = errors.New("the AMQP connection is closed")
+
+// delivery is a message delivered to a consumer.
+type delivery struct {
+	consumerTag string
+	deliveryTag uint64
+	redelivered bool
+	exchange    string
+	routingKey  string
+	props       properties
+	body        []byte
+	received    time.Time
+}
+
+// consumer buffers the deliveries of a consumer, until they are consumed.
+type consumer struct {
+	mx         sync.Mutex
+	deliveries []*delivery
+	// notify is signaled when a delivery is added, or the consumer is cancelled
+	notify    chan struct{}
+	cancelled bool
+}
+
+func (c *consumer) push(d *delivery) {
+	c.mx.Lock()
+	c.deliveries = append(c.deliveries, d)
+	c.mx.Unlock()
+	c.signal()
+}
+
+func (c *consumer) cancel() {
+	c.mx.Lock()
+	c.cancelled = true
+	c.mx.Unlock()
+	c.signal()
+}
+
+func (c *consumer) signal() {
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// drained returns true if the consumer is cancelled, and it has no more
+// buffered deliveries.
+func (c *consumer) drained() bool {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.cancelled && len(c.deliveries) == 0
+}
+
+// take returns up to n buffered deliveries, and if the consumer is cancelled.
+func (c *consumer) take(n int) ([]*delivery, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if n > len(c.deliveries) {
+		n = len(c.deliveries)
+	}
+	taken := c.deliveries[:n:n]
+	c.deliveries = c.deliveries[n:]
+	return taken, c.cancelled
+}
+
+// confirm is the confirmation of a published message by the broker, err is
+// the error of the channel if it's closed before the confirmation.
+type confirm struct {
+	ack bool
+	err error
+	at  time.Time
+}
+
+// reply is a reply of a synchronous method, with its arguments.
+type reply struct {
+	method uint32
+	d      *decoder
+}
+
+// conn is a connection to the broker, with one channel in the confirm mode.
+// Its frames are read by a goroutine, which dispatches the replies of the
+// methods, the confirmations and the deliveries.
+type conn struct {
+	netConn  net.Conn
+	frameMax int
+
+	writeMx sync.Mutex
+
+	mx sync.Mutex
+	// replies receives the replies of the synchronous methods of the channel,
+	// or an empty reply if the channel is closed
+	replies chan reply
+	// open is false when the channel was closed by the broker, it's reopened
+	// by the next operation
+	open       bool
+	channelErr error
+	nextSeq    uint64
+	confirms   map[uint64]chan confirm
+	consumers  map[string]*consumer
+	// lastConsumer is the number of the last consumer tag
+	lastConsumer int
+	closeErr     error
+	done         chan struct{}
+	heartbeats   *time.Ticker
+}
+
+// connParams are the params of the connection handshake.
+type connParams struct {
+	username  string
+	password  string
+	vhost     string
+	heartbeat time.Duration
+}
+
+// openConn makes the handshake of the connection, and it opens its channel.
+func openConn(ctx context.Context, nc net.Conn, p connParams) (*conn, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = nc.SetDeadline(deadline)
+	}
+	r := bufio.NewReader(nc)
+	c := &conn{
+		netConn:   nc,
+		frameMax:  defaultFrameMax,
+		replies:   make(chan reply, 1),
+		confirms:  make(map[uint64]chan confirm),
+		consumers: make(map[string]*consumer),
+		done:      make(chan struct{}),
+	}
+	if _, err := nc.Write([]byte(protocolHeader)); err != nil {
+		return nil, err
+	}
+
+	// the handshake is made synchronously, before the reading goroutine starts
+	d, err := c.readMethod(r, methodConnectionStart)
+	if err != nil {
+		return nil, err
+	}
+	d.octet() // version-major
+	d.octet() // version-minor
+	d.table() // server-properties
+	mechanisms := string(d.longString())
+	if !containsString(strings.Fields(mechanisms), "PLAIN") {
+		return nil, fmt.Errorf("the broker doesn't support the PLAIN authentication, it supports %s", mechanisms)
+	}
+
+	e := &encoder{}
+	e.method(methodConnectionStartOk)
+	_ = e.table(map[string]interface{}{
+		"product": "k6",
+		"capabilities": map[string]interface{}{
+			"publisher_confirms":     true,
+			"consumer_cancel_notify": true,
+		},
+	})
+	e.shortString("PLAIN")
+	e.longString([]byte("\x00" + p.username + "\x00" + p.password))
+	e.shortString("en_US")
+	if err = c.writeMethod(0, e); err != nil {
+		return nil, err
+	}
+
+	if d, err = c.readMethod(r, methodConnectionTune); err != nil {
+		return nil, err
+	}
+	channelMax := d.uint16()
+	frameMax := int(d.uint32())
+	heartbeat := time.Duration(d.uint16()) * time.Second
+	if frameMax > 0 && frameMax < c.frameMax {
+		c.frameMax = frameMax
+	}
+	// the lower heartbeat of the broker and the client is used, zero disables them
+	if p.heartbeat < heartbeat || heartbeat == 0 {
+		heartbeat = p.heartbeat
+	}
+
+	e = &encoder{}
+	e.method(methodConnectionTuneOk)
+	e.uint16(channelMax)
+	e.uint32(uint32(c.frameMax))
+	e.uint16(uint16(heartbeat / time.Second))
+	if err = c.writeMethod(0, e); err != nil {
+		return nil, err
+	}
+
+	e = &encoder{}
+	e.method(methodConnectionOpen)
+	e.shortString(p.vhost)
+	e.shortString("")
+	e.bit(false)
+	if err = c.writeMethod(0, e); err != nil {
+		return nil, err
+	}
+	if _, err = c.readMethod(r, methodConnectionOpenOk); err != nil {
+		return nil, err
+	}
+
+	_ = nc.SetDeadline(time.Time{})
+	if heartbeat > 0 {
+		c.heartbeats = time.NewTicker(heartbeat / 2)
+		go c.sendHeartbeats()
+	}
+	go c.readLoop(r)
+	if err = c.openChannel(ctx); err != nil {
+		c.close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// readMethod reads a method frame of the handshake, which needs to be the
+// expected one, or the connection.close of the broker.
+func (c *conn) readMethod(r *bufio.Reader, expected uint32) (*decoder, error) {
+	for {
+		f, err := readFrame(r)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the AMQP handshake: %w", err)
+		}
+		if f.typ == frameHeartbeat {
+			continue
+		}
+		if f.typ != frameMethod {
+			return nil, fmt.Errorf("unexpected AMQP frame type %d during the handshake", f.typ)
+		}
+		d := &decoder{buf: f.payload}
+		switch method := d.uint32(); method {
+		case expected:
+			return d, nil
+		case methodConnectionClose:
+			return nil, decodeClose(d)
+		default:
+			return nil, fmt.Errorf("unexpected AMQP method %d.%d during the handshake", method>>16, method&0xFFFF)
+		}
+	}
+}
+
+func decodeClose(d *decoder) error {
+	code := d.uint16()
+	text := d.shortString()
+	return &Error{Code: int(code), Text: text}
+}
+
+func (c *conn) writeMethod(channel uint16, e *encoder) error {
+	return c.write(appendFrame(nil, frameMethod, channel, e.buf))
+}
+
+func (c *conn) write(b []byte) error {
+	c.writeMx.Lock()
+	defer c.writeMx.Unlock()
+	_, err := c.netConn.Write(b)
+	return err
+}
+
+func (c *conn) sendHeartbeats() {
+	for {
+		select {
+		case <-c.heartbeats.C:
+			if err := c.write(appendFrame(nil, frameHeartbeat, 0, nil)); err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// readLoop reads the frames until the connection is closed.
+func (c *conn) readLoop(r *bufio.Reader) { //nolint:cyclop,funlen
+	var err error
+	// pending is the delivery that is waiting for its content
+	var pending *delivery
+	var remaining int
+loop:
+	for {
+		var f frame
+		if f, err = readFrame(r); err != nil {
+			break
+		}
+		switch f.typ {
+		case frameMethod:
+			d := &decoder{buf: f.payload}
+			method := d.uint32()
+			switch {
+			case f.channel == 0 && method == methodConnectionClose:
+				err = decodeClose(d)
+				e := &encoder{}
+				e.method(methodConnectionCloseOk)
+				_ = c.writeMethod(0, e)
+				break loop
+			case f.channel == 0 && method == methodConnectionCloseOk:
+				break loop
+			case f.channel == 0:
+			case method == methodBasicDeliver:
+				pending = &delivery{
+					consumerTag: d.shortString(),
+					deliveryTag: d.uint64(),
+					redelivered: d.bit(),
+					exchange:    d.shortString(),
+					routingKey:  d.shortString(),
+				}
+			default:
+				c.handleMethod(method, d)
+			}
+		case frameHeader:
+			if pending == nil {
+				continue
+			}
+			if remaining, pending.props, err = decodeHeader(f.payload); err != nil {
+				break loop
+			}
+			pending.body = make([]byte, 0, remaining)
+		case frameBody:
+			if pending == nil || pending.body == nil {
+				continue
+			}
+			pending.body = append(pending.body, f.payload...)
+			remaining -= len(f.payload)
+		}
+		if pending != nil && pending.body != nil && remaining <= 0 {
+			pending.received = time.Now()
+			c.mx.Lock()
+			consumer := c.consumers[pending.consumerTag]
+			c.mx.Unlock()
+			if consumer != nil {
+				consumer.push(pending)
+			}
+			pending = nil
+		}
+	}
+	c.shutdown(err)
+}
+
+// handleMethod handles a method of the channel, other than the deliveries.
+func (c *conn) handleMethod(method uint32, d *decoder) {
+	switch method {
+	case methodBasicAck, methodBasicNack:
+		tag := d.uint64()
+		multiple := d.bit()
+		c.confirm(tag, multiple, method == methodBasicAck)
+	case methodBasicCancel:
+		// the consumer is cancelled by the broker, like if its queue is deleted
+		tag := d.shortString()
+		c.mx.Lock()
+		consumer := c.consumers[tag]
+		delete(c.consumers, tag)
+		c.mx.Unlock()
+		if consumer != nil {
+			consumer.cancel()
+		}
+	case methodChannelClose:
+		err := decodeClose(d)
+		e := &encoder{}
+		e.method(methodChannelCloseOk)
+		_ = c.writeMethod(channelID, e)
+		c.closeChannel(err)
+	default:
+		// the replies of the synchronous methods, the channel has
+		// one of them at a time
+		select {
+		case c.replies <- reply{method: method, d: d}:
+		default:
+		}
+	}
+}
+
+func (c *conn) confirm(tag uint64, multiple, ack bool) {
+	now := time.Now()
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	for seq, ch := range c.confirms {
+		if seq == tag || (multiple && seq < tag) {
+			ch <- confirm{ack: ack, at: now}
+			delete(c.confirms, seq)
+		}
+	}
+}
+
+// closeChannel closes the channel after the broker closed it, the pending
+// operations fail with the error, and the consumers are cancelled.
+func (c *conn) closeChannel(err error) {
+	c.mx.Lock()
+	c.open = false
+	c.channelErr = err
+	for seq, ch := range c.confirms {
+		ch <- confirm{err: err, at: time.Now()}
+		delete(c.confirms, seq)
+	}
+	consumers := c.consumers
+	c.consumers = make(map[string]*consumer)
+	c.mx.Unlock()
+	for _, consumer := range consumers {
+		consumer.cancel()
+	}
+	select {
+	case c.replies <- reply{}:
+	default:
+	}
+}
+
+// shutdown closes the connection after an error of the reading goroutine.
+func (c *conn) shutdown(err error) {
+	if err == nil {
+		err = errConnClosed
+	}
+	c.closeChannel(err)
+	c.mx.Lock()
+	if c.closeErr == nil {
+		c.closeErr = err
+	}
+	c.mx.Unlock()
+	if c.heartbeats != nil {
+		c.heartbeats.Stop()
+	}
+	close(c.done)
+	_ = c.netConn.Close()
+}
+
+// err returns the error of the connection, if it's closed.
+func (c *conn) err() error {
+	select {
+	case <-c.done:
+		c.mx.Lock()
+		defer c.mx.Unlock()
+		return c.closeErr
+	default:
+		return nil
+	}
+}
+
+// call sends a synchronous method of the channel and it waits for its reply.
+func (c *conn) call(ctx context.Context, e *encoder, expected uint32) (*decoder, error) {
+	if err := c.ensureChannel(ctx); err != nil {
+		return nil, err
+	}
+	return c.callChannel(ctx, e, expected)
+}
+
+func (c *conn) callChannel(ctx context.Context, e *encoder, expected uint32) (*decoder, error) {
+	// a reply of a previous method, whose wait was cancelled, is discarded
+	select {
+	case <-c.replies:
+	default:
+	}
+	if err := c.writeMethod(channelID, e); err != nil {
+		return nil, err
+	}
+	for {
+		select {
+		case r := <-c.replies:
+			if r.d == nil {
+				c.mx.Lock()
+				err := c.channelErr
+				c.mx.Unlock()
+				return nil, err
+			}
+			if r.method == expected {
+				return r.d, nil
+			}
+		case <-c.done:
+			return nil, c.err()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// ensureChannel reopens the channel, if it was closed by the broker.
+func (c *conn) ensureChannel(ctx context.Context) error {
+	if err := c.err(); err != nil {
+		return err
+	}
+	c.mx.Lock()
+	open := c.open
+	c.mx.Unlock()
+	if open {
+		return nil
+	}
+	return c.openChannel(ctx)
+}
+
+// openChannel opens the channel in the confirm mode.
+func (c *conn) openChannel(ctx context.Context) error {
+	e := &encoder{}
+	e.method(methodChannelOpen)
+	e.shortString("")
+	if _, err := c.callChannel(ctx, e, methodChannelOpenOk); err != nil {
+		return err
+	}
+	e = &encoder{}
+	e.method(methodConfirmSelect)
+	e.bit(false)
+	if _, err := c.callChannel(ctx, e, methodConfirmSelectOk); err != nil {
+		return err
+	}
+	c.mx.Lock()
+	c.open = true
+	c.channelErr = nil
+	c.nextSeq = 1
+	c.mx.Unlock()
+	return nil
+}
+
+// publish publishes the message, and it returns the channel of its
+// confirmation by the broker.
+func (c *conn) publish(
+	ctx context.Context, exchange, routingKey string, mandatory bool, props properties, body []byte,
+) (<-chan confirm, error) {
+	if err := c.ensureChannel(ctx); err != nil {
+		return nil, err
+	}
+	header, err := encodeHeader(len(body), props)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &encoder{}
+	e.method(methodBasicPublish)
+	e.uint16(0)
+	e.shortString(exchange)
+	e.shortString(routingKey)
+	e.bit(mandatory)
+	e.bit(false) // immediate
+
+	// the frames of a message are written at once, so they aren't
+	// interleaved with the frames of the heartbeats
+	buf := appendFrame(nil, frameMethod, channelID, e.buf)
+	buf = appendFrame(buf, frameHeader, channelID, header)
+	maxBody := c.frameMax - 8
+	for len(body) > 0 {
+		n := len(body)
+		if n > maxBody {
+			n = maxBody
+		}
+		buf = appendFrame(buf, frameBody, channelID, body[:n])
+		body = body[n:]
+	}
+
+	ch := make(chan confirm, 1)
+	c.mx.Lock()
+	seq := c.nextSeq
+	c.nextSeq++
+	c.confirms[seq] = ch
+	c.mx.Unlock()
+	if err = c.write(buf); err != nil {
+		c.mx.Lock()
+		delete(c.confirms, seq)
+		c.mx.Unlock()
+		return nil, err
+	}
+	return ch, nil
+}
+
+// consume starts a consumer of the queue, with the prefetch count.
+func (c *conn) consume(ctx context.Context, queue string, prefetch int) (string, *consumer, error) {
+	e := &encoder{}
+	e.method(methodBasicQos)
+	e.uint32(0)
+	e.uint16(uint16(prefetch))
+	e.bit(false)
+	if _, err := c.call(ctx, e, methodBasicQosOk); err != nil {
+		return "", nil, err
+	}
+
+	c.mx.Lock()
+	c.lastConsumer++
+	tag := fmt.Sprintf("k6-consumer-%d", c.lastConsumer)
+	c.mx.Unlock()
+
+	e = &encoder{}
+	e.method(methodBasicConsume)
+	e.uint16(0)
+	e.shortString(queue)
+	e.shortString(tag)
+	e.bit(false) // no-local
+	e.bit(false) // no-ack
+	e.bit(false) // exclusive
+	e.bit(false) // no-wait
+	_ = e.table(nil)
+	// the consumer is registered before the reply, as the deliveries
+	// can follow it immediately
+	cons := &consumer{notify: make(chan struct{}, 1)}
+	c.mx.Lock()
+	c.consumers[tag] = cons
+	c.mx.Unlock()
+	if _, err := c.callChannel(ctx, e, methodBasicConsumeOk); err != nil {
+		c.mx.Lock()
+		delete(c.consumers, tag)
+		c.mx.Unlock()
+		return "", nil, err
+	}
+	return tag, cons, nil
+}
+
+// ack acknowledges or rejects the delivery.
+func (c *conn) ack(tag uint64, ack, multiple, requeue bool) error {
+	if err := c.err(); err != nil {
+		return err
+	}
+	e := &encoder{}
+	if ack {
+		e.method(methodBasicAck)
+		e.uint64(tag)
+		e.bit(multiple)
+	} else {
+		e.method(methodBasicNack)
+		e.uint64(tag)
+		e.bit(multiple)
+		e.bit(requeue)
+	}
+	return c.writeMethod(channelID, e)
+}
+
+// close closes the connection gracefully, the unacknowledged deliveries are
+// requeued by the broker.
+func (c *conn) close() {
+	if c.err() == nil {
+		e := &encoder{}
+		e.method(methodConnectionClose)
+		e.uint16(200)
+		e.shortString("")
+		e.uint16(0)
+		e.uint16(0)
+		if c.writeMethod(0, e) == nil {
+			select {
+			case <-c.done:
+			case <-time.After(time.Second):
+			}
+		}
+	}
+	_ = c.netConn.Close()
+	<-c.done
+}
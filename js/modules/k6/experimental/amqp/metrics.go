@@ -0,0 +1,35 @@
+package amqp
+
+import "go.k6.io/k6/metrics"
+
+// instanceMetrics contains the metrics of the AMQP clients.
+type instanceMetrics struct {
+	MessagesPublished *metrics.Metric
+	PublishConfirm    *metrics.Metric
+	MessagesConsumed  *metrics.Metric
+	DeliveryLatency   *metrics.Metric
+}
+
+// registerMetrics registers and returns the metrics in the provided registry
+func registerMetrics(registry *metrics.Registry) (*instanceMetrics, error) {
+	var err error
+	m := &instanceMetrics{}
+
+	if m.MessagesPublished, err = registry.NewMetric("amqp_msgs_published", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.PublishConfirm, err = registry.NewMetric("amqp_publish_confirm_duration", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.MessagesConsumed, err = registry.NewMetric("amqp_msgs_consumed", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.DeliveryLatency, err = registry.NewMetric("amqp_delivery_latency", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,62 @@
+// Package amqp implements the k6/experimental/amqp module, an AMQP 0-9-1
+// client for the load tests of RabbitMQ and of the other message brokers.
+package amqp
+
+import (
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the JS module.
+	ModuleInstance struct {
+		vu      modules.VU
+		metrics *instanceMetrics
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new RootModule instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface to return
+// a new instance for each VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	m, err := registerMetrics(vu.InitEnv().Registry)
+	if err != nil {
+		common.Throw(vu.Runtime(), err)
+	}
+	return &ModuleInstance{vu: vu, metrics: m}
+}
+
+// Exports returns the exports of the amqp module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"Client": mi.newClient,
+		},
+	}
+}
+
+// newClient is the constructor of the Client, the clients can be created
+// in the init context, but they connect to the broker only in the VU context.
+func (mi *ModuleInstance) newClient(call goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+	c, err := newClient(mi, call.Argument(0))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	return rt.ToValue(c).ToObject(rt)
+}
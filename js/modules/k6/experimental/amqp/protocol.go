@@ -0,0 +1,505 @@
+package amqp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+// The types of the frames, and the end of every frame.
+const (
+	frameMethod    byte = 1
+	frameHeader    byte = 2
+	frameBody      byte = 3
+	frameHeartbeat byte = 8
+
+	frameEnd byte = 0xCE
+)
+
+// protocolHeader is sent by the client to start the AMQP 0-9-1 protocol.
+const protocolHeader = "AMQP\x00\x00\x09\x01"
+
+// The classes and the methods of the protocol that are used, as
+// class<<16 | method, so they can be compared at once.
+const (
+	methodConnectionStart   uint32 = 10<<16 | 10
+	methodConnectionStartOk uint32 = 10<<16 | 11
+	methodConnectionTune    uint32 = 10<<16 | 30
+	methodConnectionTuneOk  uint32 = 10<<16 | 31
+	methodConnectionOpen    uint32 = 10<<16 | 40
+	methodConnectionOpenOk  uint32 = 10<<16 | 41
+	methodConnectionClose   uint32 = 10<<16 | 50
+	methodConnectionCloseOk uint32 = 10<<16 | 51
+
+	methodChannelOpen    uint32 = 20<<16 | 10
+	methodChannelOpenOk  uint32 = 20<<16 | 11
+	methodChannelClose   uint32 = 20<<16 | 40
+	methodChannelCloseOk uint32 = 20<<16 | 41
+
+	methodExchangeDeclare   uint32 = 40<<16 | 10
+	methodExchangeDeclareOk uint32 = 40<<16 | 11
+	methodExchangeDelete    uint32 = 40<<16 | 20
+	methodExchangeDeleteOk  uint32 = 40<<16 | 21
+
+	methodQueueDeclare   uint32 = 50<<16 | 10
+	methodQueueDeclareOk uint32 = 50<<16 | 11
+	methodQueueBind      uint32 = 50<<16 | 20
+	methodQueueBindOk    uint32 = 50<<16 | 21
+	methodQueueDelete    uint32 = 50<<16 | 40
+	methodQueueDeleteOk  uint32 = 50<<16 | 41
+
+	methodBasicQos       uint32 = 60<<16 | 10
+	methodBasicQosOk     uint32 = 60<<16 | 11
+	methodBasicConsume   uint32 = 60<<16 | 20
+	methodBasicConsumeOk uint32 = 60<<16 | 21
+	methodBasicCancel    uint32 = 60<<16 | 30
+	methodBasicCancelOk  uint32 = 60<<16 | 31
+	methodBasicPublish   uint32 = 60<<16 | 40
+	methodBasicDeliver   uint32 = 60<<16 | 60
+	methodBasicAck       uint32 = 60<<16 | 80
+	methodBasicNack      uint32 = 60<<16 | 120
+
+	methodConfirmSelect   uint32 = 85<<16 | 10
+	methodConfirmSelectOk uint32 = 85<<16 | 11
+)
+
+// classBasic is the class of the content headers.
+const classBasic uint16 = 60
+
+// The flags of the basic properties of the content headers, in the order
+// of the properties.
+const (
+	flagContentType     uint16 = 1 << 15
+	flagContentEncoding uint16 = 1 << 14
+	flagHeaders         uint16 = 1 << 13
+	flagDeliveryMode    uint16 = 1 << 12
+	flagPriority        uint16 = 1 << 11
+	flagCorrelationID   uint16 = 1 << 10
+	flagReplyTo         uint16 = 1 << 9
+	flagExpiration      uint16 = 1 << 8
+	flagMessageID       uint16 = 1 << 7
+	flagTimestamp       uint16 = 1 << 6
+	flagType            uint16 = 1 << 5
+	flagUserID          uint16 = 1 << 4
+	flagAppID           uint16 = 1 << 3
+)
+
+// Error is an error of a channel or of the connection closed by the broker,
+// like 404 if a queue doesn't exist.
+type Error struct {
+	Code int
+	Text string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Text, e.Code)
+}
+
+// frame is a frame of the protocol.
+type frame struct {
+	typ     byte
+	channel uint16
+	payload []byte
+}
+
+func readFrame(r *bufio.Reader) (frame, error) {
+	var head [7]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return frame{}, err
+	}
+	f := frame{typ: head[0], channel: binary.BigEndian.Uint16(head[1:3])}
+	f.payload = make([]byte, binary.BigEndian.Uint32(head[3:7])+1)
+	if _, err := io.ReadFull(r, f.payload); err != nil {
+		return frame{}, err
+	}
+	if f.payload[len(f.payload)-1] != frameEnd {
+		return frame{}, errors.New("invalid AMQP frame end")
+	}
+	f.payload = f.payload[:len(f.payload)-1]
+	return f, nil
+}
+
+func appendFrame(buf []byte, typ byte, channel uint16, payload []byte) []byte {
+	buf = append(buf, typ)
+	buf = binary.BigEndian.AppendUint16(buf, channel)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(payload)))
+	buf = append(buf, payload...)
+	return append(buf, frameEnd)
+}
+
+// encoder writes the fields of the methods in the wire format.
+type encoder struct {
+	buf []byte
+	// bits is the number of the bits written in the last byte, the
+	// consecutive bits are packed in the same bytes
+	bits int
+}
+
+func (e *encoder) method(method uint32) {
+	e.uint32(method)
+}
+
+func (e *encoder) octet(v byte) {
+	e.bits = 0
+	e.buf = append(e.buf, v)
+}
+
+func (e *encoder) uint16(v uint16) {
+	e.bits = 0
+	e.buf = binary.BigEndian.AppendUint16(e.buf, v)
+}
+
+func (e *encoder) uint32(v uint32) {
+	e.bits = 0
+	e.buf = binary.BigEndian.AppendUint32(e.buf, v)
+}
+
+func (e *encoder) uint64(v uint64) {
+	e.bits = 0
+	e.buf = binary.BigEndian.AppendUint64(e.buf, v)
+}
+
+func (e *encoder) bit(v bool) {
+	if e.bits == 0 || e.bits == 8 {
+		e.buf = append(e.buf, 0)
+		e.bits = 0
+	}
+	if v {
+		e.buf[len(e.buf)-1] |= 1 << e.bits
+	}
+	e.bits++
+}
+
+func (e *encoder) shortString(s string) {
+	e.octet(byte(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) longString(s []byte) {
+	e.uint32(uint32(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+// table writes a field table, its keys are sorted so the encoding is stable.
+func (e *encoder) table(t map[string]interface{}) error {
+	fields := &encoder{}
+	keys := make([]string, 0, len(t))
+	for k := range t {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fields.shortString(k)
+		if err := fields.value(t[k]); err != nil {
+			return fmt.Errorf("invalid field '%s': %w", k, err)
+		}
+	}
+	e.longString(fields.buf)
+	return nil
+}
+
+// value writes a value of a field table, with the types of RabbitMQ.
+func (e *encoder) value(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		e.octet('V')
+	case bool:
+		e.octet('t')
+		var b byte
+		if v {
+			b = 1
+		}
+		e.octet(b)
+	case int:
+		e.octet('l')
+		e.uint64(uint64(v))
+	case int32:
+		e.octet('I')
+		e.uint32(uint32(v))
+	case int64:
+		e.octet('l')
+		e.uint64(uint64(v))
+	case float64:
+		e.octet('d')
+		e.uint64(math.Float64bits(v))
+	case string:
+		e.octet('S')
+		e.longString([]byte(v))
+	case []byte:
+		e.octet('x')
+		e.longString(v)
+	case time.Time:
+		e.octet('T')
+		e.uint64(uint64(v.Unix()))
+	case map[string]interface{}:
+		e.octet('F')
+		return e.table(v)
+	case []interface{}:
+		e.octet('A')
+		values := &encoder{}
+		for _, item := range v {
+			if err := values.value(item); err != nil {
+				return err
+			}
+		}
+		e.longString(values.buf)
+	default:
+		return fmt.Errorf("unsupported type %T", v)
+	}
+	return nil
+}
+
+// decoder reads the fields of the methods in the wire format.
+type decoder struct {
+	buf []byte
+	err error
+	// bitsByte is the byte of the consecutive bits, and bits is the
+	// number of the bits read from it
+	bitsByte byte
+	bits     int
+}
+
+func (d *decoder) next(n int) []byte {
+	d.bits = 0
+	if d.err != nil {
+		return nil
+	}
+	if len(d.buf) < n {
+		d.err = io.ErrUnexpectedEOF
+		d.buf = nil
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) octet() byte {
+	if b := d.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *decoder) uint16() uint16 {
+	if b := d.next(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *decoder) uint32() uint32 {
+	if b := d.next(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (d *decoder) uint64() uint64 {
+	if b := d.next(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (d *decoder) bit() bool {
+	if d.err != nil {
+		return false
+	}
+	if d.bits == 0 || d.bits == 8 {
+		d.bitsByte = d.octet()
+	}
+	v := d.bitsByte&(1<<d.bits) != 0
+	d.bits++
+	return v
+}
+
+func (d *decoder) shortString() string {
+	return string(d.next(int(d.octet())))
+}
+
+func (d *decoder) longString() []byte {
+	return d.next(int(d.uint32()))
+}
+
+func (d *decoder) table() map[string]interface{} {
+	fields := &decoder{buf: d.longString()}
+	if d.err != nil {
+		return nil
+	}
+	t := make(map[string]interface{})
+	for len(fields.buf) > 0 && fields.err == nil {
+		k := fields.shortString()
+		t[k] = fields.value()
+	}
+	if fields.err != nil {
+		d.err = fields.err
+	}
+	return t
+}
+
+func (d *decoder) value() interface{} { //nolint:cyclop
+	switch typ := d.octet(); typ {
+	case 't':
+		return d.octet() != 0
+	case 'b':
+		return int64(int8(d.octet()))
+	case 'B':
+		return int64(d.octet())
+	case 's':
+		return int64(int16(d.uint16()))
+	case 'u':
+		return int64(d.uint16())
+	case 'I':
+		return int64(int32(d.uint32()))
+	case 'i':
+		return int64(d.uint32())
+	case 'l':
+		return int64(d.uint64())
+	case 'f':
+		return float64(math.Float32frombits(d.uint32()))
+	case 'd':
+		return math.Float64frombits(d.uint64())
+	case 'D':
+		scale := d.octet()
+		return float64(int32(d.uint32())) / math.Pow10(int(scale))
+	case 'S':
+		return string(d.longString())
+	case 'x':
+		return append([]byte{}, d.longString()...)
+	case 'T':
+		return time.Unix(int64(d.uint64()), 0)
+	case 'F':
+		return d.table()
+	case 'A':
+		items := &decoder{buf: d.longString()}
+		values := []interface{}{}
+		for len(items.buf) > 0 && items.err == nil {
+			values = append(values, items.value())
+		}
+		if items.err != nil {
+			d.err = items.err
+		}
+		return values
+	case 'V':
+		return nil
+	default:
+		if d.err == nil {
+			d.err = fmt.Errorf("unsupported field type '%c'", typ)
+		}
+		return nil
+	}
+}
+
+// properties are the basic properties of the content headers.
+type properties struct {
+	contentType     string
+	contentEncoding string
+	headers         map[string]interface{}
+	deliveryMode    byte
+	priority        byte
+	correlationID   string
+	replyTo         string
+	expiration      string
+	messageID       string
+	timestamp       time.Time
+	messageType     string
+	userID          string
+	appID           string
+}
+
+// encodeHeader returns the payload of the content header of a body.
+func encodeHeader(size int, p properties) ([]byte, error) {
+	e := &encoder{}
+	e.uint16(classBasic)
+	e.uint16(0) // weight
+	e.uint64(uint64(size))
+
+	var flags uint16
+	props := &encoder{}
+	shortString := func(flag uint16, s string) {
+		if s != "" {
+			flags |= flag
+			props.shortString(s)
+		}
+	}
+	shortString(flagContentType, p.contentType)
+	shortString(flagContentEncoding, p.contentEncoding)
+	if len(p.headers) > 0 {
+		flags |= flagHeaders
+		if err := props.table(p.headers); err != nil {
+			return nil, fmt.Errorf("invalid headers: %w", err)
+		}
+	}
+	if p.deliveryMode != 0 {
+		flags |= flagDeliveryMode
+		props.octet(p.deliveryMode)
+	}
+	if p.priority != 0 {
+		flags |= flagPriority
+		props.octet(p.priority)
+	}
+	shortString(flagCorrelationID, p.correlationID)
+	shortString(flagReplyTo, p.replyTo)
+	shortString(flagExpiration, p.expiration)
+	shortString(flagMessageID, p.messageID)
+	if !p.timestamp.IsZero() {
+		flags |= flagTimestamp
+		props.uint64(uint64(p.timestamp.Unix()))
+	}
+	shortString(flagType, p.messageType)
+	shortString(flagUserID, p.userID)
+	shortString(flagAppID, p.appID)
+
+	e.uint16(flags)
+	e.buf = append(e.buf, props.buf...)
+	return e.buf, nil
+}
+
+// decodeHeader returns the size of the body and the properties of a
+// content header.
+func decodeHeader(payload []byte) (int, properties, error) {
+	d := &decoder{buf: payload}
+	d.uint16() // class
+	d.uint16() // weight
+	size := d.uint64()
+	flags := d.uint16()
+
+	var p properties
+	shortString := func(flag uint16) string {
+		if flags&flag == 0 {
+			return ""
+		}
+		return d.shortString()
+	}
+	p.contentType = shortString(flagContentType)
+	p.contentEncoding = shortString(flagContentEncoding)
+	if flags&flagHeaders != 0 {
+		p.headers = d.table()
+	}
+	if flags&flagDeliveryMode != 0 {
+		p.deliveryMode = d.octet()
+	}
+	if flags&flagPriority != 0 {
+		p.priority = d.octet()
+	}
+	p.correlationID = shortString(flagCorrelationID)
+	p.replyTo = shortString(flagReplyTo)
+	p.expiration = shortString(flagExpiration)
+	p.messageID = shortString(flagMessageID)
+	if flags&flagTimestamp != 0 {
+		p.timestamp = time.Unix(int64(d.uint64()), 0)
+	}
+	p.messageType = shortString(flagType)
+	p.userID = shortString(flagUserID)
+	p.appID = shortString(flagAppID)
+	if d.err != nil {
+		return 0, p, fmt.Errorf("invalid AMQP content header: %w", d.err)
+	}
+	return int(size), p, nil
+}
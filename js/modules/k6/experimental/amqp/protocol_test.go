@@ -0,0 +1,61 @@
+package amqp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeaderRoundTrip(t *testing.T) {
+	t.Parallel()
+	props := properties{
+		contentType: "application/json",
+		headers: map[string]interface{}{
+			"string": "value",
+			"int":    int64(-42),
+			"float":  1.5,
+			"bool":   true,
+			"bytes":  []byte{0, 1},
+			"null":   nil,
+			"table":  map[string]interface{}{"nested": "value"},
+			"array":  []interface{}{"a", int64(1)},
+		},
+		deliveryMode:  2,
+		priority:      5,
+		correlationID: "correlation",
+		expiration:    "60000",
+		timestamp:     time.Unix(1700000000, 0),
+		appID:         "k6",
+	}
+	payload, err := encodeHeader(11, props)
+	require.NoError(t, err)
+
+	size, decoded, err := decodeHeader(payload)
+	require.NoError(t, err)
+	assert.Equal(t, 11, size)
+	assert.Equal(t, props, decoded)
+}
+
+func TestHeaderUnsupportedValue(t *testing.T) {
+	t.Parallel()
+	_, err := encodeHeader(0, properties{headers: map[string]interface{}{"invalid": struct{}{}}})
+	require.ErrorContains(t, err, "invalid headers: invalid field 'invalid': unsupported type struct {}")
+}
+
+func TestBits(t *testing.T) {
+	t.Parallel()
+	e := &encoder{}
+	e.bit(true)
+	e.bit(false)
+	e.bit(true)
+	e.shortString("next")
+	e.bit(true)
+
+	d := &decoder{buf: e.buf}
+	assert.Equal(t, []bool{true, false, true}, []bool{d.bit(), d.bit(), d.bit()})
+	assert.Equal(t, "next", d.shortString())
+	assert.True(t, d.bit())
+	require.NoError(t, d.err)
+}
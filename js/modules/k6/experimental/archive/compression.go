@@ -0,0 +1,160 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+var (
+	errUnsupportedFormat = errors.New("unsupported format")
+	errTrailingData      = errors.New("there is data after the end of the compressed data")
+)
+
+// compressor compresses the chunks of a CompressionStream.
+type compressor struct {
+	buf bytes.Buffer
+	w   io.WriteCloser
+}
+
+func newCompressor(format string) (*compressor, error) {
+	c := &compressor{}
+	switch format {
+	case "gzip":
+		c.w = gzip.NewWriter(&c.buf)
+	case "deflate":
+		c.w = zlib.NewWriter(&c.buf)
+	case "deflate-raw":
+		// the error is only returned for an invalid level
+		c.w, _ = flate.NewWriter(&c.buf, flate.DefaultCompression)
+	default:
+		return nil, fmt.Errorf("%w %q, it must be gzip, deflate or deflate-raw", errUnsupportedFormat, format)
+	}
+	return c, nil
+}
+
+// Transform implements the streams.ByteTransformer interface.
+func (c *compressor) Transform(chunk []byte) ([]byte, error) {
+	if _, err := c.w.Write(chunk); err != nil {
+		return nil, fmt.Errorf("couldn't compress the data: %w", err)
+	}
+	return c.take(), nil
+}
+
+// Flush implements the streams.ByteTransformer interface.
+func (c *compressor) Flush() ([]byte, error) {
+	if err := c.w.Close(); err != nil {
+		return nil, fmt.Errorf("couldn't compress the data: %w", err)
+	}
+	return c.take(), nil
+}
+
+// take returns the data compressed so far.
+func (c *compressor) take() []byte {
+	data := append([]byte(nil), c.buf.Bytes()...)
+	c.buf.Reset()
+	return data
+}
+
+// decompressor decompresses the chunks of a DecompressionStream. The
+// decompression readers of Go read their input, so the chunks are written to
+// a pipe which is read by the decompression, in its own goroutine, until
+// the end of the stream.
+type decompressor struct {
+	pw *io.PipeWriter
+
+	mx  sync.Mutex
+	buf bytes.Buffer
+
+	// done is closed once the decompression has finished, with its error.
+	done chan struct{}
+	err  error
+}
+
+func newDecompressor(format string) (*decompressor, error) {
+	var newReader func(io.Reader) (io.Reader, error)
+	switch format {
+	case "gzip":
+		newReader = func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }
+	case "deflate":
+		newReader = func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) }
+	case "deflate-raw":
+		newReader = func(r io.Reader) (io.Reader, error) { return flate.NewReader(r), nil }
+	default:
+		return nil, fmt.Errorf("%w %q, it must be gzip, deflate or deflate-raw", errUnsupportedFormat, format)
+	}
+
+	pr, pw := io.Pipe()
+	d := &decompressor{pw: pw, done: make(chan struct{})}
+	go d.decompress(pr, newReader)
+	return d, nil
+}
+
+func (d *decompressor) decompress(pr *io.PipeReader, newReader func(io.Reader) (io.Reader, error)) {
+	defer close(d.done)
+
+	// the decompression readers only read what they decompress from a
+	// byte reader, so that the data after the end of the stream is detected
+	br := bufio.NewReader(pr)
+	r, err := newReader(br)
+	if err == nil {
+		_, err = io.Copy(decompressedWriter{d}, r)
+	}
+	if err == nil {
+		if _, rerr := br.ReadByte(); rerr == nil {
+			err = errTrailingData
+		}
+	}
+	if err != nil {
+		d.err = fmt.Errorf("couldn't decompress the data: %w", err)
+	}
+	// the next writes of the chunks fail once the decompression has finished
+	_ = pr.CloseWithError(d.err)
+}
+
+// Transform implements the streams.ByteTransformer interface.
+func (d *decompressor) Transform(chunk []byte) ([]byte, error) {
+	if _, err := d.pw.Write(chunk); err != nil {
+		<-d.done
+		return nil, d.err
+	}
+	return d.take(), nil
+}
+
+// Flush implements the streams.ByteTransformer interface.
+func (d *decompressor) Flush() ([]byte, error) {
+	_ = d.pw.Close()
+	<-d.done
+	if d.err != nil {
+		return nil, d.err
+	}
+	return d.take(), nil
+}
+
+// take returns the data decompressed so far.
+func (d *decompressor) take() []byte {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+
+	data := append([]byte(nil), d.buf.Bytes()...)
+	d.buf.Reset()
+	return data
+}
+
+// decompressedWriter writes the decompressed data to the buffer
+// of the decompressor.
+type decompressedWriter struct {
+	d *decompressor
+}
+
+func (w decompressedWriter) Write(p []byte) (int, error) {
+	w.d.mx.Lock()
+	defer w.d.mx.Unlock()
+	return w.d.buf.Write(p)
+}
@@ -0,0 +1,391 @@
+// Package archive implements a k6 JS module to create and extract gzip, zip
+// and tar archives in memory, e.g. to upload compressed bundles or to check
+// downloaded exports, and the compression streams to compress or decompress
+// big files or response bodies chunk by chunk.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/js/modules/k6/experimental/streams"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the JS module.
+	ModuleInstance struct {
+		vu modules.VU
+	}
+)
+
+// Ensure the interfaces are implemented correctly
+var (
+	_ modules.Instance = &ModuleInstance{}
+	_ modules.Module   = &RootModule{}
+)
+
+// New returns a pointer to a new RootModule instance
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns
+// a new instance for each VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu}
+}
+
+// Exports implements the modules.Instance interface and returns
+// the exports of the JS module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"gzip":                mi.gzip,
+			"gunzip":              mi.gunzip,
+			"zip":                 mi.zip,
+			"unzip":               mi.unzip,
+			"tar":                 mi.tar,
+			"untar":               mi.untar,
+			"CompressionStream":   mi.newCompressionStream,
+			"DecompressionStream": mi.newDecompressionStream,
+		},
+	}
+}
+
+// gzipMagic is the start of the gzip compressed data.
+var gzipMagic = []byte{0x1f, 0x8b} //nolint:gochecknoglobals
+
+// gzip compresses the data, a string, an ArrayBuffer or a typed array, with
+// the level option, between 1 and 9, and returns the compressed ArrayBuffer.
+func (mi *ModuleInstance) gzip(data goja.Value, options goja.Value) (goja.ArrayBuffer, error) {
+	rt := mi.vu.Runtime()
+	b, err := toBytes(data)
+	if err != nil {
+		return goja.ArrayBuffer{}, err
+	}
+
+	level := gzip.DefaultCompression
+	if !common.IsNullish(options) {
+		if v := options.ToObject(rt).Get("level"); !common.IsNullish(v) {
+			level = int(v.ToInteger())
+		}
+	}
+
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return goja.ArrayBuffer{}, fmt.Errorf("invalid level %d, it must be between 1 and 9", level)
+	}
+	if _, err = w.Write(b); err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		return goja.ArrayBuffer{}, fmt.Errorf("couldn't compress the data: %w", err)
+	}
+
+	return rt.NewArrayBuffer(buf.Bytes()), nil
+}
+
+// gunzip decompresses the gzip compressed data and returns its ArrayBuffer.
+func (mi *ModuleInstance) gunzip(data goja.Value) (goja.ArrayBuffer, error) {
+	b, err := toBytes(data)
+	if err != nil {
+		return goja.ArrayBuffer{}, err
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return goja.ArrayBuffer{}, fmt.Errorf("couldn't decompress the data: %w", err)
+	}
+	decompressed, err := io.ReadAll(r)
+	if err != nil {
+		return goja.ArrayBuffer{}, fmt.Errorf("couldn't decompress the data: %w", err)
+	}
+
+	return mi.vu.Runtime().NewArrayBuffer(decompressed), nil
+}
+
+// zip returns the ArrayBuffer of a zip archive of the entries, which are
+// objects with a name, data and an optional modified date. The entries
+// with a name ending with a slash are directories.
+func (mi *ModuleInstance) zip(entries goja.Value) (goja.ArrayBuffer, error) {
+	files, err := mi.exportEntries(entries)
+	if err != nil {
+		return goja.ArrayBuffer{}, err
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, f := range files {
+		header := &zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: f.modified}
+		if f.dir {
+			header.Method = zip.Store
+		}
+		fw, err := w.CreateHeader(header)
+		if err == nil {
+			_, err = fw.Write(f.data)
+		}
+		if err != nil {
+			return goja.ArrayBuffer{}, fmt.Errorf("couldn't add %q to the archive: %w", f.name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return goja.ArrayBuffer{}, fmt.Errorf("couldn't create the archive: %w", err)
+	}
+
+	return mi.vu.Runtime().NewArrayBuffer(buf.Bytes()), nil
+}
+
+// unzip returns the entries of the zip archive, in the order of the archive.
+func (mi *ModuleInstance) unzip(data goja.Value) ([]*Entry, error) {
+	b, err := toBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the archive: %w", err)
+	}
+
+	entries := make([]*Entry, 0, len(r.File))
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't extract %q: %w", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't extract %q: %w", f.Name, err)
+		}
+		entries = append(entries, mi.newEntry(f.Name, f.Modified, f.FileInfo().IsDir(), content))
+	}
+	return entries, nil
+}
+
+// tar returns the ArrayBuffer of a tar archive of the entries, as for zip,
+// which is compressed with gzip if the gzip option is true.
+func (mi *ModuleInstance) tar(entries goja.Value, options goja.Value) (goja.ArrayBuffer, error) {
+	rt := mi.vu.Runtime()
+	files, err := mi.exportEntries(entries)
+	if err != nil {
+		return goja.ArrayBuffer{}, err
+	}
+
+	var buf bytes.Buffer
+	var out io.Writer = &buf
+	var gw *gzip.Writer
+	if !common.IsNullish(options) && getBool(options.ToObject(rt), "gzip") {
+		gw = gzip.NewWriter(&buf)
+		out = gw
+	}
+
+	w := tar.NewWriter(out)
+	for _, f := range files {
+		header := &tar.Header{
+			Name: f.name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(f.data)), ModTime: f.modified,
+		}
+		if f.dir {
+			header.Typeflag, header.Mode = tar.TypeDir, 0o755
+		}
+		err := w.WriteHeader(header)
+		if err == nil {
+			_, err = w.Write(f.data)
+		}
+		if err != nil {
+			return goja.ArrayBuffer{}, fmt.Errorf("couldn't add %q to the archive: %w", f.name, err)
+		}
+	}
+	err = w.Close()
+	if err == nil && gw != nil {
+		err = gw.Close()
+	}
+	if err != nil {
+		return goja.ArrayBuffer{}, fmt.Errorf("couldn't create the archive: %w", err)
+	}
+
+	return rt.NewArrayBuffer(buf.Bytes()), nil
+}
+
+// untar returns the files and the directories of the tar archive, which is
+// decompressed first if it's compressed with gzip. The other entries,
+// e.g. the links, are skipped.
+func (mi *ModuleInstance) untar(data goja.Value) ([]*Entry, error) {
+	b, err := toBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var in io.Reader = bytes.NewReader(b)
+	if bytes.HasPrefix(b, gzipMagic) {
+		if in, err = gzip.NewReader(in); err != nil {
+			return nil, fmt.Errorf("couldn't decompress the archive: %w", err)
+		}
+	}
+
+	var entries []*Entry
+	r := tar.NewReader(in)
+	for {
+		header, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read the archive: %w", err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			entries = append(entries, mi.newEntry(header.Name, header.ModTime, true, nil))
+		case tar.TypeReg:
+			content, err := io.ReadAll(r)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't extract %q: %w", header.Name, err)
+			}
+			entries = append(entries, mi.newEntry(header.Name, header.ModTime, false, content))
+		}
+	}
+}
+
+// file is an entry to add to an archive.
+type file struct {
+	name     string
+	data     []byte
+	modified time.Time
+	dir      bool
+}
+
+// exportEntries returns the files of the array of entries of the script.
+func (mi *ModuleInstance) exportEntries(entries goja.Value) ([]file, error) {
+	rt := mi.vu.Runtime()
+	if common.IsNullish(entries) {
+		return nil, errors.New("the entries are required")
+	}
+	var values []goja.Value
+	if err := rt.ExportTo(entries, &values); err != nil {
+		return nil, errors.New("the entries must be an array")
+	}
+
+	now := time.Now()
+	files := make([]file, len(values))
+	for i, v := range values {
+		if common.IsNullish(v) {
+			return nil, fmt.Errorf("the entry %d is required", i)
+		}
+		obj := v.ToObject(rt)
+
+		name := obj.Get("name")
+		if common.IsNullish(name) || name.String() == "" {
+			return nil, fmt.Errorf("the name of the entry %d is required", i)
+		}
+		f := file{name: name.String(), modified: now, dir: strings.HasSuffix(name.String(), "/")}
+
+		if data := obj.Get("data"); !common.IsNullish(data) {
+			b, err := toBytes(data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid data of %q: %w", f.name, err)
+			}
+			if f.dir && len(b) > 0 {
+				return nil, fmt.Errorf("the directory %q can't have data", f.name)
+			}
+			f.data = b
+		}
+		if modified := obj.Get("modified"); !common.IsNullish(modified) {
+			t, ok := modified.Export().(time.Time)
+			if !ok {
+				return nil, fmt.Errorf("the modified date of %q must be a Date", f.name)
+			}
+			f.modified = t
+		}
+		files[i] = f
+	}
+	return files, nil
+}
+
+// Entry is an entry of an extracted archive, either a file or a directory.
+type Entry struct {
+	Name        string       `js:"name"`
+	Size        int          `js:"size"`
+	Modified    *goja.Object `js:"modified"`
+	IsDirectory bool         `js:"isDirectory"`
+	// Data is the content of the file, which is empty for the directories.
+	Data goja.ArrayBuffer `js:"data"`
+}
+
+func (mi *ModuleInstance) newEntry(name string, modified time.Time, dir bool, data []byte) *Entry {
+	rt := mi.vu.Runtime()
+	date, err := rt.New(rt.Get("Date"), rt.ToValue(modified.UnixMilli()))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	return &Entry{Name: name, Size: len(data), Modified: date, IsDirectory: dir, Data: rt.NewArrayBuffer(data)}
+}
+
+// Text returns the content of the file as a UTF-8 string.
+func (e *Entry) Text() string {
+	return string(e.Data.Bytes())
+}
+
+// getBool returns the boolean value of the property of the object,
+// which is false if it isn't defined.
+func getBool(obj *goja.Object, name string) bool {
+	value := obj.Get(name)
+	return value != nil && value.ToBoolean()
+}
+
+// toBytes returns the bytes of a string, an ArrayBuffer or a Uint8Array.
+func toBytes(data goja.Value) ([]byte, error) {
+	if common.IsNullish(data) {
+		return nil, errors.New("the data is required")
+	}
+	return common.ToBytes(data.Export())
+}
+
+// newCompressionStream is the CompressionStream(format) constructor, a
+// transform stream which compresses its chunks in the gzip, deflate
+// or deflate-raw format.
+func (mi *ModuleInstance) newCompressionStream(call goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+	c, err := newCompressor(call.Argument(0).String())
+	if err != nil {
+		panic(rt.NewTypeError(err.Error()))
+	}
+
+	obj, err := streams.NewByteTransformStream(mi.vu, c)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	return obj
+}
+
+// newDecompressionStream is the DecompressionStream(format) constructor, a
+// transform stream which decompresses its chunks, in the formats of the
+// CompressionStream.
+func (mi *ModuleInstance) newDecompressionStream(call goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+	d, err := newDecompressor(call.Argument(0).String())
+	if err != nil {
+		panic(rt.NewTypeError(err.Error()))
+	}
+
+	obj, err := streams.NewByteTransformStream(mi.vu, d)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	return obj
+}
@@ -0,0 +1,244 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/js/modules/k6/experimental/streams"
+	"go.k6.io/k6/js/modulestest"
+)
+
+func newTestRuntime(t *testing.T) *modulestest.Runtime {
+	t.Helper()
+	ts := modulestest.NewRuntime(t)
+	m, ok := New().NewModuleInstance(ts.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, ts.VU.Runtime().Set("archive", m.Exports().Named))
+	streamsModule, ok := streams.New().NewModuleInstance(ts.VU).(*streams.ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, ts.VU.Runtime().Set("streams", streamsModule.Exports().Named))
+
+	_, err := ts.VU.Runtime().RunString(`
+		function assertEquals(actual, expected) {
+			if (JSON.stringify(actual) !== JSON.stringify(expected)) {
+				throw new Error("expected " + JSON.stringify(expected) + ", got " + JSON.stringify(actual));
+			}
+		}
+	`)
+	require.NoError(t, err)
+	return ts
+}
+
+func TestGzip(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestRuntime(t)
+	v, err := ts.VU.Runtime().RunString(`
+		const text = "hello world! ".repeat(100);
+		const compressed = archive.gzip(text, { level: 9 });
+		if (compressed.byteLength >= text.length) {
+			throw new Error("the data isn't compressed: " + compressed.byteLength);
+		}
+		assertEquals(String.fromCharCode(...new Uint8Array(archive.gunzip(compressed))), text);
+		assertEquals(archive.gunzip(archive.gzip(new Uint8Array([1, 2, 3]))).byteLength, 3);
+		compressed;
+	`)
+	require.NoError(t, err)
+
+	ab, ok := v.Export().(goja.ArrayBuffer)
+	require.True(t, ok)
+	assert.Equal(t, gzipMagic, ab.Bytes()[:2])
+}
+
+func TestZip(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestRuntime(t)
+	v, err := ts.VU.Runtime().RunString(`
+		const modified = new Date(Date.UTC(2023, 0, 2, 3, 4, 6));
+		const zipped = archive.zip([
+			{ name: "data/", modified },
+			{ name: "data/users.csv", data: "id,name\n1,alice\n", modified },
+			{ name: "data/empty.bin", data: new ArrayBuffer(0) },
+		]);
+
+		const entries = archive.unzip(zipped);
+		assertEquals(entries.map(e => [e.name, e.size, e.isDirectory]),
+			[["data/", 0, true], ["data/users.csv", 16, false], ["data/empty.bin", 0, false]]);
+		assertEquals(entries[1].text(), "id,name\n1,alice\n");
+		assertEquals(entries[1].modified.getTime(), modified.getTime());
+		zipped;
+	`)
+	require.NoError(t, err)
+
+	// the archive can be read by the other tools
+	ab, ok := v.Export().(goja.ArrayBuffer)
+	require.True(t, ok)
+	r, err := zip.NewReader(bytes.NewReader(ab.Bytes()), int64(len(ab.Bytes())))
+	require.NoError(t, err)
+	require.Len(t, r.File, 3)
+	f, err := r.File[1].Open()
+	require.NoError(t, err)
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, "id,name\n1,alice\n", string(data))
+}
+
+func TestTar(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestRuntime(t)
+	_, err := ts.VU.Runtime().RunString(`
+		const entries = [
+			{ name: "bundle/", },
+			{ name: "bundle/index.js", data: "export default 1;" },
+		];
+		for (const gzip of [false, true]) {
+			const tarball = archive.tar(entries, { gzip });
+			assertEquals(new Uint8Array(tarball)[0] === 0x1f, gzip);
+
+			const extracted = archive.untar(tarball);
+			assertEquals(extracted.map(e => [e.name, e.isDirectory, e.text()]),
+				[["bundle/", true, ""], ["bundle/index.js", false, "export default 1;"]]);
+		}
+	`)
+	require.NoError(t, err)
+}
+
+func TestCompressionStreams(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []string{"gzip", "deflate", "deflate-raw"} {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestRuntime(t)
+			require.NoError(t, ts.VU.Runtime().Set("format", format))
+			_, err := ts.RunOnEventLoop(`
+				function source(chunks) {
+					return new streams.ReadableStream({
+						pull(controller) {
+							if (chunks.length === 0) {
+								controller.close();
+							} else {
+								controller.enqueue(chunks.shift());
+							}
+						},
+					});
+				}
+
+				async function collect(readable) {
+					const bytes = [];
+					await readable.pipeTo(new streams.WritableStream({
+						write(chunk) {
+							bytes.push(...chunk);
+						},
+					}));
+					return new Uint8Array(bytes);
+				}
+
+				(async () => {
+					const chunks = [];
+					for (let i = 0; i < 50; i++) {
+						chunks.push(new Uint8Array(Array.from("line " + i + "\n", c => c.charCodeAt(0))));
+					}
+					const expected = chunks.map(c => String.fromCharCode(...c)).join("");
+
+					const compressed = await collect(source(chunks).pipeThrough(new archive.CompressionStream(format)));
+					const compressedChunks = [];
+					for (let i = 0; i < compressed.length; i += 7) {
+						compressedChunks.push(compressed.slice(i, i + 7));
+					}
+
+					const decompressed = await collect(source(compressedChunks)
+						.pipeThrough(new archive.DecompressionStream(format)));
+					assertEquals(String.fromCharCode(...decompressed), expected);
+				})().catch(e => { throw e; });
+			`)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestDecompressionStreamErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, format, data, err string
+	}{
+		{name: "invalid", format: "gzip", data: "this is not gzip data", err: "gzip: invalid header"},
+		{name: "truncated", format: "gzip", data: "\x1f\x8b\x08\x00\x00\x00\x00\x00", err: "unexpected EOF"},
+		{name: "trailing data", format: "deflate-raw", data: "\x03\x00 trailing data", err: "there is data after the end"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestRuntime(t)
+			rt := ts.VU.Runtime()
+			require.NoError(t, rt.Set("format", tc.format))
+			require.NoError(t, rt.Set("data", tc.data))
+			require.NoError(t, rt.Set("expected", tc.err))
+			_, err := ts.RunOnEventLoop(`
+				(async () => {
+					const readable = new streams.ReadableStream({
+						start(controller) {
+							controller.enqueue(new Uint8Array(Array.from(data, c => c.charCodeAt(0))));
+							controller.close();
+						},
+					});
+					try {
+						await readable
+							.pipeThrough(new archive.DecompressionStream(format))
+							.pipeTo(new streams.WritableStream());
+						throw new Error("the decompression should fail");
+					} catch (e) {
+						if (!e.message.startsWith("couldn't decompress the data: ") || !e.message.includes(expected)) {
+							throw new Error("unexpected error: " + e.message);
+						}
+					}
+				})().catch(e => { throw e; });
+			`)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		script, err string
+	}{
+		{script: `archive.gzip()`, err: "the data is required"},
+		{script: `archive.gzip("a", { level: 10 })`, err: "invalid level 10, it must be between 1 and 9"},
+		{script: `archive.gunzip("this is not gzip data")`, err: "couldn't decompress the data: gzip: invalid header"},
+		{script: `archive.zip({})`, err: "the entries must be an array"},
+		{script: `archive.zip([{ data: "a" }])`, err: "the name of the entry 0 is required"},
+		{script: `archive.tar([{ name: "dir/", data: "a" }])`, err: `the directory "dir/" can't have data`},
+		{script: `archive.unzip("not zip")`, err: "couldn't read the archive: zip: not a valid zip file"},
+		{
+			script: `new archive.CompressionStream("br")`,
+			err:    `unsupported format "br", it must be gzip, deflate or deflate-raw`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.script, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestRuntime(t)
+			_, err := ts.VU.Runtime().RunString(tc.script)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -12,14 +12,14 @@ type BrowserContext interface {
 	ClearCookies()
 	ClearPermissions()
 	Close()
-	Cookies() ([]any, error) // TODO: make it []Cookie later on
+	Cookies(urls ...string) ([]any, error) // TODO: make it []Cookie later on
 	ExposeBinding(name string, callback goja.Callable, opts goja.Value)
 	ExposeFunction(name string, callback goja.Callable)
 	GrantPermissions(permissions []string, opts goja.Value)
 	NewCDPSession() CDPSession
 	NewPage() (Page, error)
 	Pages() []Page
-	Route(url goja.Value, handler goja.Callable)
+	Route(url goja.Value, handler goja.Value)
 	SetDefaultNavigationTimeout(timeout int64)
 	SetDefaultTimeout(timeout int64)
 	SetExtraHTTPHeaders(headers map[string]string) error
@@ -33,6 +33,6 @@ type BrowserContext interface {
 	SetHTTPCredentials(httpCredentials goja.Value)
 	SetOffline(offline bool)
 	StorageState(opts goja.Value)
-	Unroute(url goja.Value, handler goja.Callable)
+	Unroute(url goja.Value, handler goja.Value)
 	WaitForEvent(event string, optsOrPredicate goja.Value) any
 }
@@ -54,7 +54,7 @@ type Page interface {
 	Query(selector string) (ElementHandle, error)
 	QueryAll(selector string) ([]ElementHandle, error)
 	Reload(opts goja.Value) Response
-	Route(url goja.Value, handler goja.Callable)
+	Route(url goja.Value, handler goja.Value)
 	Screenshot(opts goja.Value) goja.ArrayBuffer
 	SelectOption(selector string, values goja.Value, opts goja.Value) []string
 	SetContent(html string, opts goja.Value)
@@ -68,7 +68,7 @@ type Page interface {
 	Title() string
 	Type(selector string, text string, opts goja.Value)
 	Uncheck(selector string, opts goja.Value)
-	Unroute(url goja.Value, handler goja.Callable)
+	Unroute(url goja.Value, handler goja.Value)
 	URL() string
 	Video() Video
 	ViewportSize() map[string]float64
@@ -7,9 +7,9 @@ import (
 
 	"github.com/dop251/goja"
 
-	"github.com/grafana/xk6-browser/api"
-	"github.com/grafana/xk6-browser/k6error"
-	"github.com/grafana/xk6-browser/k6ext"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/api"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/k6error"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/k6ext"
 
 	k6common "go.k6.io/k6/js/common"
 )
@@ -619,8 +619,8 @@ func mapBrowserContext(vu moduleVU, bc api.BrowserContext) mapping {
 		"clearCookies":     bc.ClearCookies,
 		"clearPermissions": bc.ClearPermissions,
 		"close":            bc.Close,
-		"cookies": func() ([]any, error) {
-			cc, err := bc.Cookies()
+		"cookies": func(urls ...string) ([]any, error) {
+			cc, err := bc.Cookies(urls...)
 			ctx := vu.Context()
 			panicIfFatalError(ctx, err)
 			return cc, err //nolint:wrapcheck
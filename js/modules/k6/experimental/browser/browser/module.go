@@ -9,9 +9,9 @@ import (
 
 	"github.com/dop251/goja"
 
-	"github.com/grafana/xk6-browser/common"
-	"github.com/grafana/xk6-browser/env"
-	"github.com/grafana/xk6-browser/k6ext"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/common"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/env"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/k6ext"
 
 	k6modules "go.k6.io/k6/js/modules"
 )
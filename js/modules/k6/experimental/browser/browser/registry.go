@@ -12,10 +12,10 @@ import (
 	"sync"
 	"sync/atomic"
 
-	"github.com/grafana/xk6-browser/api"
-	"github.com/grafana/xk6-browser/chromium"
-	"github.com/grafana/xk6-browser/env"
-	"github.com/grafana/xk6-browser/k6ext"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/api"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/chromium"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/env"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/k6ext"
 
 	k6event "go.k6.io/k6/event"
 	k6modules "go.k6.io/k6/js/modules"
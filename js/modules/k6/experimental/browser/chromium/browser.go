@@ -1,8 +1,8 @@
 package chromium
 
 import (
-	"github.com/grafana/xk6-browser/api"
-	"github.com/grafana/xk6-browser/common"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/api"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/common"
 )
 
 // Ensure Browser implements the api.Browser interface.
@@ -12,12 +12,12 @@ import (
 	"strings"
 	"time"
 
-	"github.com/grafana/xk6-browser/api"
-	"github.com/grafana/xk6-browser/common"
-	"github.com/grafana/xk6-browser/env"
-	"github.com/grafana/xk6-browser/k6ext"
-	"github.com/grafana/xk6-browser/log"
-	"github.com/grafana/xk6-browser/storage"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/api"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/common"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/env"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/k6ext"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/log"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/storage"
 
 	k6common "go.k6.io/k6/js/common"
 	k6modules "go.k6.io/k6/js/modules"
@@ -3,14 +3,16 @@ package common
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"reflect"
+	"strings"
 	"time"
 
-	"github.com/grafana/xk6-browser/api"
-	"github.com/grafana/xk6-browser/common/js"
-	"github.com/grafana/xk6-browser/k6error"
-	"github.com/grafana/xk6-browser/k6ext"
-	"github.com/grafana/xk6-browser/log"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/api"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/common/js"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/k6error"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/k6ext"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/log"
 
 	k6modules "go.k6.io/k6/js/modules"
 
@@ -44,6 +46,8 @@ type BrowserContext struct {
 	vu              k6modules.VU
 
 	evaluateOnNewDocumentSources []string
+
+	routes routes
 }
 
 // NewBrowserContext creates a new browser context.
@@ -177,9 +181,68 @@ func (b *BrowserContext) Close() {
 	}
 }
 
-// Cookies is not implemented.
-func (b *BrowserContext) Cookies() ([]any, error) {
-	return nil, fmt.Errorf("BrowserContext.cookies() has not been implemented yet: %w", k6error.ErrFatal)
+// Cookies returns the cookies of the browser context, including the HttpOnly
+// ones, so that a session started in the browser can be carried over to the
+// k6/http requests, e.g. with http.cookieJar().set(). If URLs are given, only
+// the cookies which would be sent to one of them are returned.
+func (b *BrowserContext) Cookies(urls ...string) ([]any, error) {
+	b.logger.Debugf("BrowserContext:Cookies", "bctxid:%v", b.id)
+
+	filter := make([]*url.URL, 0, len(urls))
+	for _, u := range urls {
+		pu, err := url.Parse(u)
+		if err != nil {
+			return nil, fmt.Errorf("parsing cookies URL %q: %w", u, err)
+		}
+		filter = append(filter, pu)
+	}
+
+	cookies, err := storage.GetCookies().WithBrowserContextID(b.id).Do(b.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving cookies: %w", err)
+	}
+
+	result := make([]any, 0, len(cookies))
+	for _, c := range cookies {
+		if len(filter) > 0 && !cookieMatchesAnyURL(c, filter) {
+			continue
+		}
+		// the same fields as the ones of addCookies, so the cookies can be added back
+		result = append(result, map[string]any{
+			"name":     c.Name,
+			"value":    c.Value,
+			"domain":   c.Domain,
+			"path":     c.Path,
+			"expires":  c.Expires,
+			"httpOnly": c.HTTPOnly,
+			"secure":   c.Secure,
+			"sameSite": c.SameSite.String(),
+		})
+	}
+	return result, nil
+}
+
+// cookieMatchesAnyURL returns true if the cookie would be sent to one of the URLs.
+func cookieMatchesAnyURL(c *network.Cookie, urls []*url.URL) bool {
+	for _, u := range urls {
+		if c.Secure && u.Scheme != "https" {
+			continue
+		}
+		host, domain := u.Hostname(), strings.TrimPrefix(c.Domain, ".")
+		if host != domain && !strings.HasSuffix(host, "."+domain) {
+			continue
+		}
+		path := u.Path
+		if path == "" {
+			path = "/"
+		}
+		if c.Path != "" && c.Path != "/" && path != c.Path &&
+			!strings.HasPrefix(path, strings.TrimSuffix(c.Path, "/")+"/") {
+			continue
+		}
+		return true
+	}
+	return false
 }
 
 // ExposeBinding is not implemented.
@@ -276,9 +339,19 @@ func (b *BrowserContext) Pages() []api.Page {
 	return pages
 }
 
-// Route is not implemented.
-func (b *BrowserContext) Route(url goja.Value, handler goja.Callable) {
-	k6ext.Panic(b.ctx, "BrowserContext.route(url, handler) has not been implemented yet")
+// Route intercepts the requests of all the pages of the browser context whose
+// URL matches the glob pattern or the RegExp, like Page.route() does.
+func (b *BrowserContext) Route(url goja.Value, handler goja.Value) {
+	b.logger.Debugf("BrowserContext:Route", "bctxid:%v", b.id)
+
+	r, err := newRoute(b.vu.Runtime(), url, handler)
+	if err != nil {
+		k6ext.Panic(b.ctx, "adding route: %w", err)
+	}
+	b.routes.add(r)
+	if err := b.updateRequestInterception(); err != nil {
+		k6ext.Panic(b.ctx, "adding route: %w", err)
+	}
 }
 
 // SetDefaultNavigationTimeout sets the default navigation timeout in milliseconds.
@@ -354,9 +427,33 @@ func (b *BrowserContext) StorageState(opts goja.Value) {
 	k6ext.Panic(b.ctx, "BrowserContext.storageState(opts) has not been implemented yet")
 }
 
-// Unroute is not implemented.
-func (b *BrowserContext) Unroute(url goja.Value, handler goja.Callable) {
-	k6ext.Panic(b.ctx, "BrowserContext.unroute(url, handler) has not been implemented yet")
+// Unroute removes the routes of the browser context with the URL pattern, and
+// with the handler if it's set.
+func (b *BrowserContext) Unroute(url goja.Value, handler goja.Value) {
+	b.logger.Debugf("BrowserContext:Unroute", "bctxid:%v", b.id)
+
+	pattern, err := routeURLPattern(b.vu.Runtime(), url)
+	if err != nil {
+		k6ext.Panic(b.ctx, "removing route: %w", err)
+	}
+	b.routes.remove(pattern, handler)
+	if err := b.updateRequestInterception(); err != nil {
+		k6ext.Panic(b.ctx, "removing route: %w", err)
+	}
+}
+
+// updateRequestInterception updates the request interception of the pages
+// of the browser context after its routes changed.
+func (b *BrowserContext) updateRequestInterception() error {
+	for _, p := range b.browser.getPages() {
+		if p.browserCtx != b {
+			continue
+		}
+		if err := p.updateRequestInterception(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // WaitForEvent waits for event.
@@ -0,0 +1,41 @@
+package common
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCookieMatchesAnyURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cookie  network.Cookie
+		url     string
+		matches bool
+	}{
+		{"same host", network.Cookie{Domain: "example.com", Path: "/"}, "https://example.com/", true},
+		{"subdomain", network.Cookie{Domain: ".example.com", Path: "/"}, "https://api.example.com/v1", true},
+		{"other host", network.Cookie{Domain: "example.com", Path: "/"}, "https://example.org/", false},
+		{"suffix host", network.Cookie{Domain: "example.com", Path: "/"}, "https://badexample.com/", false},
+		{"path prefix", network.Cookie{Domain: "example.com", Path: "/app"}, "https://example.com/app/login", true},
+		{"same path", network.Cookie{Domain: "example.com", Path: "/app"}, "https://example.com/app", true},
+		{"other path", network.Cookie{Domain: "example.com", Path: "/app"}, "https://example.com/application", false},
+		{"secure on http", network.Cookie{Domain: "example.com", Path: "/", Secure: true}, "http://example.com/", false},
+		{"secure on https", network.Cookie{Domain: "example.com", Path: "/", Secure: true}, "https://example.com/", true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			u, err := url.Parse(tt.url)
+			require.NoError(t, err)
+			assert.Equal(t, tt.matches, cookieMatchesAnyURL(&tt.cookie, []*url.URL{u}))
+		})
+	}
+}
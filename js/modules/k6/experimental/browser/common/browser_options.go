@@ -8,8 +8,8 @@ import (
 	"strings"
 	"time"
 
-	"github.com/grafana/xk6-browser/env"
-	"github.com/grafana/xk6-browser/log"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/env"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/log"
 
 	"go.k6.io/k6/lib/types"
 )
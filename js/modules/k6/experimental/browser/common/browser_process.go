@@ -11,8 +11,8 @@ import (
 	"os/exec"
 	"strings"
 
-	"github.com/grafana/xk6-browser/log"
-	"github.com/grafana/xk6-browser/storage"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/log"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/storage"
 )
 
 type BrowserProcess struct {
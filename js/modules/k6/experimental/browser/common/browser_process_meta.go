@@ -3,7 +3,7 @@ package common
 import (
 	"os"
 
-	"github.com/grafana/xk6-browser/storage"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/storage"
 )
 
 const (
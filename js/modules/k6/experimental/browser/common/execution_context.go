@@ -8,9 +8,9 @@ import (
 	"regexp"
 	"sync"
 
-	"github.com/grafana/xk6-browser/api"
-	"github.com/grafana/xk6-browser/k6ext"
-	"github.com/grafana/xk6-browser/log"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/api"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/k6ext"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/log"
 
 	k6modules "go.k6.io/k6/js/modules"
 
@@ -10,9 +10,9 @@ import (
 	"sync"
 	"time"
 
-	"github.com/grafana/xk6-browser/api"
-	"github.com/grafana/xk6-browser/k6ext"
-	"github.com/grafana/xk6-browser/log"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/api"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/k6ext"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/log"
 
 	k6modules "go.k6.io/k6/js/modules"
 	k6metrics "go.k6.io/k6/metrics"
@@ -438,7 +438,6 @@ func (fs *FrameSession) initOptions() error {
 	var (
 		opts       = fs.manager.page.browserCtx.opts
 		optActions = []Action{}
-		state      = fs.vu.State()
 	)
 
 	if fs.isMainFrame() {
@@ -480,12 +479,7 @@ func (fs *FrameSession) initOptions() error {
 	}
 	fs.updateExtraHTTPHeaders(true)
 
-	var reqIntercept bool
-	if state.Options.BlockedHostnames.Trie != nil ||
-		len(state.Options.BlacklistIPs) > 0 {
-		reqIntercept = true
-	}
-	if err := fs.updateRequestInterception(reqIntercept); err != nil {
+	if err := fs.updateRequestInterception(); err != nil {
 		return err
 	}
 
@@ -1023,13 +1017,21 @@ func (fs *FrameSession) updateOffline(initial bool) {
 	}
 }
 
-func (fs *FrameSession) updateRequestInterception(enable bool) error {
+// updateRequestInterception enables the request interception if the requests
+// have to be checked against the blocked hostnames and IPs, or if the page has
+// any route.
+func (fs *FrameSession) updateRequestInterception() error {
+	state := fs.vu.State()
+	enable := state.Options.BlockedHostnames.Trie != nil ||
+		len(state.Options.BlacklistIPs) > 0 ||
+		fs.page.hasRoutes()
+
 	fs.logger.Debugf("NewFrameSession:updateRequestInterception",
 		"sid:%v tid:%v on:%v",
 		fs.session.ID(),
 		fs.targetID, enable)
 
-	return fs.networkManager.setRequestInterception(enable || fs.page.hasRoutes())
+	return fs.networkManager.setRequestInterception(enable)
 }
 
 func (fs *FrameSession) updateViewport() error {
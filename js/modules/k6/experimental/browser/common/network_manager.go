@@ -10,9 +10,9 @@ import (
 	"sync"
 	"time"
 
-	"github.com/grafana/xk6-browser/log"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/log"
 
-	"github.com/grafana/xk6-browser/k6ext"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/k6ext"
 
 	k6modules "go.k6.io/k6/js/modules"
 	k6lib "go.k6.io/k6/lib"
@@ -167,7 +167,38 @@ func (m *NetworkManager) emitRequestMetrics(req *Request) {
 	})
 }
 
-func (m *NetworkManager) emitResponseMetrics(resp *Response, req *Request) {
+// resourceTiming is the duration, in milliseconds, of each phase of a request.
+// The phases which didn't happen, e.g. the connecting on a reused connection,
+// are zero.
+type resourceTiming struct {
+	dnsLookup, connecting, tlsHandshaking, sending, waiting, receiving float64
+}
+
+// newResourceTiming returns the phases of the request from the resource timing
+// of its response, and the time its loading finished at, if it's known.
+func newResourceTiming(timing *network.ResourceTiming, finished *cdp.MonotonicTime) resourceTiming {
+	phase := func(start, end float64) float64 {
+		if start < 0 || end < start {
+			return 0
+		}
+		return end - start
+	}
+	rt := resourceTiming{
+		dnsLookup:      phase(timing.DNSStart, timing.DNSEnd),
+		connecting:     phase(timing.ConnectStart, timing.ConnectEnd),
+		tlsHandshaking: phase(timing.SslStart, timing.SslEnd),
+		sending:        phase(timing.SendStart, timing.SendEnd),
+		waiting:        phase(timing.SendEnd, timing.ReceiveHeadersEnd),
+	}
+	if finished != nil {
+		// the resource timing is relative to its request time, in monotonic seconds
+		finishedAt := finished.Time().Sub(*cdp.MonotonicTimeEpoch).Seconds()
+		rt.receiving = phase(timing.ReceiveHeadersEnd, (finishedAt-timing.RequestTime)*1000)
+	}
+	return rt
+}
+
+func (m *NetworkManager) emitResponseMetrics(resp *Response, req *Request, finished *cdp.MonotonicTime) {
 	state := m.vu.State()
 
 	// In some scenarios we might not receive a ResponseReceived CDP event, in
@@ -247,7 +278,36 @@ func (m *NetworkManager) emitResponseMetrics(resp *Response, req *Request) {
 				},
 			},
 		})
+		m.emitResourceTimingMetrics(newResourceTiming(resp.timing, finished), req, tags, wallTime)
+	}
+}
+
+func (m *NetworkManager) emitResourceTimingMetrics(
+	rt resourceTiming, req *Request, tags *k6metrics.TagSet, wallTime time.Time,
+) {
+	state := m.vu.State()
+	tags = tags.With("resource_type", req.resourceType)
+
+	phases := []struct {
+		metric *k6metrics.Metric
+		value  float64
+	}{
+		{m.customMetrics.BrowserHTTPReqDNSLookup, rt.dnsLookup},
+		{m.customMetrics.BrowserHTTPReqConnecting, rt.connecting},
+		{m.customMetrics.BrowserHTTPReqTLSHandshaking, rt.tlsHandshaking},
+		{m.customMetrics.BrowserHTTPReqSending, rt.sending},
+		{m.customMetrics.BrowserHTTPReqWaiting, rt.waiting},
+		{m.customMetrics.BrowserHTTPReqReceiving, rt.receiving},
+	}
+	samples := make([]k6metrics.Sample, 0, len(phases))
+	for _, p := range phases {
+		samples = append(samples, k6metrics.Sample{
+			TimeSeries: k6metrics.TimeSeries{Metric: p.metric, Tags: tags},
+			Value:      p.value,
+			Time:       wallTime,
+		})
 	}
+	k6metrics.PushIfNotDone(m.vu.Context(), state.Samples, k6metrics.ConnectedSamples{Samples: samples})
 }
 
 func (m *NetworkManager) handleRequestRedirect(req *Request, redirectResponse *network.Response, timestamp *cdp.MonotonicTime) {
@@ -257,7 +317,7 @@ func (m *NetworkManager) handleRequestRedirect(req *Request, redirectResponse *n
 	req.responseMu.Unlock()
 	req.redirectChain = append(req.redirectChain, req)
 
-	m.emitResponseMetrics(resp, req)
+	m.emitResponseMetrics(resp, req, timestamp)
 	m.deleteRequestByID(req.requestID)
 
 	/*
@@ -371,7 +431,7 @@ func (m *NetworkManager) onLoadingFinished(event *network.EventLoadingFinished)
 	// Skip data and blob URLs when emitting metrics, since they're internal to the browser.
 	if !isInternalURL(req.url) {
 		req.responseMu.RLock()
-		m.emitResponseMetrics(req.response, req)
+		m.emitResponseMetrics(req.response, req, event.Timestamp)
 		req.responseMu.RUnlock()
 	}
 	m.deleteRequestByID(event.RequestID)
@@ -436,7 +496,10 @@ func (m *NetworkManager) onRequestPaused(event *fetch.EventRequestPaused) { //no
 	defer m.logger.Debugf("NetworkManager:onRequestPaused:return",
 		"sid:%s url:%v", m.session.ID(), event.Request.URL)
 
-	var failErr error
+	var (
+		failErr error
+		r       *route
+	)
 
 	defer func() {
 		if failErr != nil {
@@ -457,7 +520,10 @@ func (m *NetworkManager) onRequestPaused(event *fetch.EventRequestPaused) { //no
 
 			return
 		}
-		action := fetch.ContinueRequest(event.RequestID)
+		var action Action = fetch.ContinueRequest(event.RequestID)
+		if r != nil {
+			action = r.action(event.RequestID)
+		}
 		if err := action.Do(cdp.WithExecutor(m.ctx, m.session)); err != nil {
 			// Avoid logging as error when context is canceled.
 			// Most probably this happens when trying to continue a site's background request
@@ -470,10 +536,20 @@ func (m *NetworkManager) onRequestPaused(event *fetch.EventRequestPaused) { //no
 		}
 	}()
 
-	purl, err := url.Parse(event.Request.URL)
+	// The aborted and fulfilled requests don't reach the network,
+	// the continued ones are checked with their overridden URL.
+	reqURL := event.Request.URL
+	if r = m.route(reqURL); r != nil {
+		var continued bool
+		if reqURL, continued = r.continuedURL(reqURL); !continued {
+			return
+		}
+	}
+
+	purl, err := url.Parse(reqURL)
 	if err != nil {
 		m.logger.Errorf("NetworkManager:onRequestPaused",
-			"parsing URL %q: %s", event.Request.URL, err)
+			"parsing URL %q: %s", reqURL, err)
 		return
 	}
 
@@ -501,6 +577,14 @@ func (m *NetworkManager) onRequestPaused(event *fetch.EventRequestPaused) { //no
 	failErr = checkBlockedIPs(ip, state.Options.BlacklistIPs)
 }
 
+// route returns the route of the page intercepting the requests to the URL, if any.
+func (m *NetworkManager) route(url string) *route {
+	if m.frameManager == nil || m.frameManager.page == nil {
+		return nil
+	}
+	return m.frameManager.page.route(url)
+}
+
 func checkBlockedHosts(host string, blockedHosts *k6types.HostnameTrie) error {
 	if blockedHosts == nil {
 		return nil
@@ -586,7 +670,8 @@ func (m *NetworkManager) requestFromID(reqID network.RequestID) *Request {
 }
 
 func (m *NetworkManager) setRequestInterception(value bool) error {
-	m.userReqInterceptionEnabled = value
+	// the credentials are answered through the intercepted requests
+	m.userReqInterceptionEnabled = value || m.credentials != nil
 	return m.updateProtocolRequestInterception()
 }
 
@@ -0,0 +1,75 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewResourceTiming(t *testing.T) {
+	t.Parallel()
+
+	timing := &network.ResourceTiming{
+		RequestTime:       100,
+		DNSStart:          1,
+		DNSEnd:            4,
+		ConnectStart:      4,
+		ConnectEnd:        20,
+		SslStart:          10,
+		SslEnd:            20,
+		SendStart:         21,
+		SendEnd:           22,
+		ReceiveHeadersEnd: 72,
+	}
+	finished := cdp.MonotonicTime(cdp.MonotonicTimeEpoch.Add(100*time.Second + 80*time.Millisecond))
+
+	assert.Equal(t, resourceTiming{
+		dnsLookup:      3,
+		connecting:     16,
+		tlsHandshaking: 10,
+		sending:        1,
+		waiting:        50,
+		receiving:      8,
+	}, roundResourceTiming(newResourceTiming(timing, &finished)))
+}
+
+func TestNewResourceTimingReusedConnection(t *testing.T) {
+	t.Parallel()
+
+	timing := &network.ResourceTiming{
+		RequestTime:       100,
+		DNSStart:          -1,
+		DNSEnd:            -1,
+		ConnectStart:      -1,
+		ConnectEnd:        -1,
+		SslStart:          -1,
+		SslEnd:            -1,
+		SendStart:         1,
+		SendEnd:           2,
+		ReceiveHeadersEnd: 12,
+	}
+
+	assert.Equal(t, resourceTiming{
+		sending: 1,
+		waiting: 10,
+	}, newResourceTiming(timing, nil))
+}
+
+// roundResourceTiming rounds the phases to the microsecond,
+// since the receiving is computed from the floating point seconds.
+func roundResourceTiming(rt resourceTiming) resourceTiming {
+	round := func(v float64) float64 {
+		return float64(time.Duration(v * float64(time.Millisecond)).Round(time.Microsecond).Microseconds()) / 1000
+	}
+	return resourceTiming{
+		dnsLookup:      round(rt.dnsLookup),
+		connecting:     round(rt.connecting),
+		tlsHandshaking: round(rt.tlsHandshaking),
+		sending:        round(rt.sending),
+		waiting:        round(rt.waiting),
+		receiving:      round(rt.receiving),
+	}
+}
@@ -8,9 +8,9 @@ import (
 	"sync"
 	"time"
 
-	"github.com/grafana/xk6-browser/api"
-	"github.com/grafana/xk6-browser/k6ext"
-	"github.com/grafana/xk6-browser/log"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/api"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/k6ext"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/log"
 
 	k6modules "go.k6.io/k6/js/modules"
 
@@ -73,7 +73,7 @@ type Page struct {
 	// TODO: FrameSession changes by attachFrameSession (mutex?)
 	frameSessions map[cdp.FrameID]*FrameSession
 	workers       map[target.SessionID]*Worker
-	routes        []api.Route
+	routes        routes
 	vu            k6modules.VU
 
 	logger *log.Logger
@@ -106,7 +106,6 @@ func NewPage(
 		jsEnabled:        true,
 		frameSessions:    make(map[cdp.FrameID]*FrameSession),
 		workers:          make(map[target.SessionID]*Worker),
-		routes:           make([]api.Route, 0),
 		vu:               k6ext.GetVU(ctx),
 		logger:           logger,
 	}
@@ -285,7 +284,27 @@ func (p *Page) getFrameSession(frameID cdp.FrameID) *FrameSession {
 }
 
 func (p *Page) hasRoutes() bool {
-	return len(p.routes) > 0
+	return p.routes.len() > 0 || p.browserCtx.routes.len() > 0
+}
+
+// route returns the route intercepting the requests to the URL, if any.
+// The routes of the page take precedence over the ones of its browser context.
+func (p *Page) route(url string) *route {
+	if r := p.routes.match(url); r != nil {
+		return r
+	}
+	return p.browserCtx.routes.match(url)
+}
+
+// updateRequestInterception enables or disables the request interception
+// of the frame sessions, depending on the routes.
+func (p *Page) updateRequestInterception() error {
+	for _, fs := range p.frameSessions {
+		if err := fs.updateRequestInterception(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (p *Page) resetViewport() error {
@@ -824,9 +843,21 @@ func (p *Page) Reload(opts goja.Value) api.Response {
 	return resp
 }
 
-// Route is not implemented.
-func (p *Page) Route(url goja.Value, handler goja.Callable) {
-	k6ext.Panic(p.ctx, "Page.route(url, handler) has not been implemented yet")
+// Route intercepts the requests of the page whose URL matches the glob pattern
+// or the RegExp, and aborts, fulfills or continues them as the handler object
+// describes, e.g. {abort: "blockedbyclient"}, {fulfill: {status: 200, body: "ok"}}
+// or {continue: {url: "https://example.com/"}}.
+func (p *Page) Route(url goja.Value, handler goja.Value) {
+	p.logger.Debugf("Page:Route", "sid:%v", p.sessionID())
+
+	r, err := newRoute(p.vu.Runtime(), url, handler)
+	if err != nil {
+		k6ext.Panic(p.ctx, "adding route: %w", err)
+	}
+	p.routes.add(r)
+	if err := p.updateRequestInterception(); err != nil {
+		k6ext.Panic(p.ctx, "adding route: %w", err)
+	}
 }
 
 // Screenshot will instruct Chrome to save a screenshot of the current page and save it to specified file.
@@ -923,9 +954,19 @@ func (p *Page) Type(selector string, text string, opts goja.Value) {
 	p.MainFrame().Type(selector, text, opts)
 }
 
-// Unroute is not implemented.
-func (p *Page) Unroute(url goja.Value, handler goja.Callable) {
-	k6ext.Panic(p.ctx, "Page.unroute(url, handler) has not been implemented yet")
+// Unroute removes the routes of the page with the URL pattern, and with the
+// handler if it's set.
+func (p *Page) Unroute(url goja.Value, handler goja.Value) {
+	p.logger.Debugf("Page:Unroute", "sid:%v", p.sessionID())
+
+	pattern, err := routeURLPattern(p.vu.Runtime(), url)
+	if err != nil {
+		k6ext.Panic(p.ctx, "removing route: %w", err)
+	}
+	p.routes.remove(pattern, handler)
+	if err := p.updateRequestInterception(); err != nil {
+		k6ext.Panic(p.ctx, "removing route: %w", err)
+	}
 }
 
 // URL returns the location of the page.
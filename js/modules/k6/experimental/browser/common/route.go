@@ -0,0 +1,413 @@
+package common
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/dop251/goja"
+)
+
+// routeErrorReasons maps the error codes of route aborts to the network errors.
+var routeErrorReasons = map[string]network.ErrorReason{ //nolint:gochecknoglobals
+	"aborted":              network.ErrorReasonAborted,
+	"accessdenied":         network.ErrorReasonAccessDenied,
+	"addressunreachable":   network.ErrorReasonAddressUnreachable,
+	"blockedbyclient":      network.ErrorReasonBlockedByClient,
+	"blockedbyresponse":    network.ErrorReasonBlockedByResponse,
+	"connectionaborted":    network.ErrorReasonConnectionAborted,
+	"connectionclosed":     network.ErrorReasonConnectionClosed,
+	"connectionfailed":     network.ErrorReasonConnectionFailed,
+	"connectionrefused":    network.ErrorReasonConnectionRefused,
+	"connectionreset":      network.ErrorReasonConnectionReset,
+	"internetdisconnected": network.ErrorReasonInternetDisconnected,
+	"namenotresolved":      network.ErrorReasonNameNotResolved,
+	"timedout":             network.ErrorReasonTimedOut,
+	"failed":               network.ErrorReasonFailed,
+}
+
+// route intercepts the requests whose URL matches its pattern.
+//
+// The browser APIs block the event loop while they wait for the browser, e.g.
+// page.goto() until the navigation is done, so the routes can't call a JS
+// handler for the requests they intercept. Instead, the handler is an object
+// describing what to do with the requests: abort them, fulfill them with a
+// stubbed response, or continue them with some overrides.
+type route struct {
+	pattern string
+	url     *regexp.Regexp
+	handler goja.Value
+
+	// abort is the error the requests fail with, if they are aborted.
+	abort network.ErrorReason
+
+	// fulfill is the response the requests are fulfilled with, if any.
+	fulfill *routeFulfillment
+
+	// continueOverrides are the overrides of the continued requests.
+	continueOverrides *routeOverrides
+}
+
+// routeFulfillment is the response of the fulfilled requests.
+type routeFulfillment struct {
+	status  int64
+	headers []*fetch.HeaderEntry
+	body    []byte
+}
+
+// routeOverrides are the overrides of the continued requests.
+type routeOverrides struct {
+	url      string
+	method   string
+	postData string
+	headers  []*fetch.HeaderEntry
+}
+
+// newRoute returns a route for the URL pattern, a glob string or a RegExp,
+// with the action of the handler, an object with one of the properties:
+//   - abort: the error code the requests fail with, e.g. "blockedbyclient",
+//     or true for "failed".
+//   - fulfill: the status, headers, contentType and body of the response.
+//   - continue: the url, method, headers and postData overrides of the request.
+func newRoute(rt *goja.Runtime, url goja.Value, handler goja.Value) (*route, error) {
+	if !gojaValueExists(url) {
+		return nil, errors.New("the route URL must be a glob pattern or a RegExp")
+	}
+	if !gojaValueExists(handler) {
+		return nil, errors.New("the route handler must be an object with an abort, fulfill or continue action")
+	}
+	if _, isFunc := goja.AssertFunction(handler); isFunc {
+		return nil, errors.New("the route handler can't be a function, " +
+			"it must be an object with an abort, fulfill or continue action")
+	}
+
+	pattern, re, err := parseRouteURL(rt, url)
+	if err != nil {
+		return nil, err
+	}
+	r := &route{pattern: pattern, url: re, handler: handler}
+
+	h := handler.ToObject(rt)
+	var actions []string
+	for _, k := range h.Keys() {
+		v := h.Get(k)
+		switch k {
+		case "abort":
+			r.abort, err = parseRouteAbort(v)
+		case "fulfill":
+			r.fulfill, err = parseRouteFulfillment(rt, v)
+		case "continue":
+			r.continueOverrides, err = parseRouteOverrides(rt, v)
+		default:
+			return nil, fmt.Errorf("unknown route action %q, it must be abort, fulfill or continue", k)
+		}
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, k)
+	}
+	if len(actions) != 1 {
+		return nil, fmt.Errorf("the route handler must have exactly one abort, fulfill or continue action, got %d",
+			len(actions))
+	}
+
+	return r, nil
+}
+
+// parseRouteURL returns the source and the regexp of the URL pattern.
+func parseRouteURL(rt *goja.Runtime, url goja.Value) (string, *regexp.Regexp, error) {
+	if obj, ok := url.(*goja.Object); ok && obj.ClassName() == "RegExp" {
+		source := obj.Get("source").String()
+		flags := obj.Get("flags").String()
+		expr := source
+		if strings.Contains(flags, "i") {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return "", nil, fmt.Errorf("the route URL regexp /%s/%s isn't supported: %w", source, flags, err)
+		}
+		return "/" + source + "/" + flags, re, nil
+	}
+
+	var glob string
+	if err := rt.ExportTo(url, &glob); err != nil {
+		return "", nil, fmt.Errorf("the route URL must be a glob pattern or a RegExp: %w", err)
+	}
+	re, err := regexp.Compile(globToRegexp(glob))
+	if err != nil {
+		return "", nil, fmt.Errorf("the route URL %q isn't a valid glob pattern: %w", glob, err)
+	}
+	return glob, re, nil
+}
+
+// globToRegexp converts a URL glob pattern to a regexp. A "*" matches any
+// characters except "/", a "**" matches any characters, a "?" matches a
+// single character and a "{a,b}" matches one of the comma separated values.
+func globToRegexp(glob string) string {
+	var (
+		b       strings.Builder
+		inGroup bool
+	)
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == '*' && i+1 < len(glob) && glob[i+1] == '*':
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString(".")
+		case c == '{':
+			inGroup = true
+			b.WriteString("(?:")
+		case c == '}' && inGroup:
+			inGroup = false
+			b.WriteString(")")
+		case c == ',' && inGroup:
+			b.WriteString("|")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+func parseRouteAbort(v goja.Value) (network.ErrorReason, error) {
+	if b, ok := v.Export().(bool); ok {
+		if !b {
+			return "", errors.New("the route abort action must be true or an error code")
+		}
+		return network.ErrorReasonFailed, nil
+	}
+	reason, ok := routeErrorReasons[strings.ToLower(v.String())]
+	if !ok {
+		codes := make([]string, 0, len(routeErrorReasons))
+		for c := range routeErrorReasons {
+			codes = append(codes, c)
+		}
+		sort.Strings(codes)
+		return "", fmt.Errorf("unknown route abort error code %q, it must be one of: %s",
+			v.String(), strings.Join(codes, ", "))
+	}
+	return reason, nil
+}
+
+func parseRouteFulfillment(rt *goja.Runtime, v goja.Value) (*routeFulfillment, error) {
+	f := &routeFulfillment{status: 200}
+	if !gojaValueExists(v) {
+		return f, nil
+	}
+	var contentType string
+	opts := v.ToObject(rt)
+	for _, k := range opts.Keys() {
+		ov := opts.Get(k)
+		switch k {
+		case "status":
+			f.status = ov.ToInteger()
+		case "headers":
+			headers, err := parseRouteHeaders(rt, ov)
+			if err != nil {
+				return nil, err
+			}
+			f.headers = headers
+		case "contentType":
+			contentType = ov.String()
+		case "body":
+			switch body := ov.Export().(type) {
+			case goja.ArrayBuffer:
+				f.body = body.Bytes()
+			case []byte:
+				f.body = body
+			default:
+				f.body = []byte(ov.String())
+			}
+		default:
+			return nil, fmt.Errorf("unknown route fulfill option %q", k)
+		}
+	}
+	if contentType != "" {
+		f.headers = setHeaderEntry(f.headers, "Content-Type", contentType)
+	}
+	return f, nil
+}
+
+func parseRouteOverrides(rt *goja.Runtime, v goja.Value) (*routeOverrides, error) {
+	o := &routeOverrides{}
+	if !gojaValueExists(v) {
+		return o, nil
+	}
+	if b, ok := v.Export().(bool); ok && b {
+		return o, nil
+	}
+	opts := v.ToObject(rt)
+	for _, k := range opts.Keys() {
+		ov := opts.Get(k)
+		switch k {
+		case "url":
+			o.url = ov.String()
+		case "method":
+			o.method = ov.String()
+		case "postData":
+			o.postData = ov.String()
+		case "headers":
+			headers, err := parseRouteHeaders(rt, ov)
+			if err != nil {
+				return nil, err
+			}
+			o.headers = headers
+		default:
+			return nil, fmt.Errorf("unknown route continue option %q", k)
+		}
+	}
+	return o, nil
+}
+
+func parseRouteHeaders(rt *goja.Runtime, v goja.Value) ([]*fetch.HeaderEntry, error) {
+	var headers map[string]string
+	if err := rt.ExportTo(v, &headers); err != nil {
+		return nil, fmt.Errorf("the route headers must be an object of strings: %w", err)
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	entries := make([]*fetch.HeaderEntry, 0, len(headers))
+	for _, name := range names {
+		entries = append(entries, &fetch.HeaderEntry{Name: name, Value: headers[name]})
+	}
+	return entries, nil
+}
+
+func setHeaderEntry(headers []*fetch.HeaderEntry, name, value string) []*fetch.HeaderEntry {
+	for _, h := range headers {
+		if strings.EqualFold(h.Name, name) {
+			h.Value = value
+			return headers
+		}
+	}
+	return append(headers, &fetch.HeaderEntry{Name: name, Value: value})
+}
+
+// matches returns true if the route intercepts the requests to the URL.
+func (r *route) matches(url string) bool {
+	return r.url.MatchString(url)
+}
+
+// action returns the action of the route for the paused request.
+func (r *route) action(requestID fetch.RequestID) Action {
+	switch {
+	case r.abort != "":
+		return fetch.FailRequest(requestID, r.abort)
+	case r.fulfill != nil:
+		action := fetch.FulfillRequest(requestID, r.fulfill.status)
+		if len(r.fulfill.headers) > 0 {
+			action = action.WithResponseHeaders(r.fulfill.headers)
+		}
+		return action.WithBody(base64.StdEncoding.EncodeToString(r.fulfill.body))
+	default:
+		action := fetch.ContinueRequest(requestID)
+		if o := r.continueOverrides; o != nil {
+			if o.url != "" {
+				action = action.WithURL(o.url)
+			}
+			if o.method != "" {
+				action = action.WithMethod(o.method)
+			}
+			if o.postData != "" {
+				action = action.WithPostData(base64.StdEncoding.EncodeToString([]byte(o.postData)))
+			}
+			if len(o.headers) > 0 {
+				action = action.WithHeaders(o.headers)
+			}
+		}
+		return action
+	}
+}
+
+// continuedURL returns the URL the request is continued to, if it isn't
+// aborted or fulfilled.
+func (r *route) continuedURL(url string) (string, bool) {
+	if r.abort != "" || r.fulfill != nil {
+		return "", false
+	}
+	if r.continueOverrides != nil && r.continueOverrides.url != "" {
+		return r.continueOverrides.url, true
+	}
+	return url, true
+}
+
+// routes is the list of the routes of a page or a browser context.
+// It's safe for concurrent use, since the requests are intercepted
+// outside of the event loop.
+type routes struct {
+	mu     sync.RWMutex
+	routes []*route
+}
+
+func (rs *routes) add(r *route) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.routes = append(rs.routes, r)
+}
+
+// remove removes the routes with the URL pattern and, if it's set,
+// with the same handler.
+func (rs *routes) remove(pattern string, handler goja.Value) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	kept := rs.routes[:0]
+	for _, r := range rs.routes {
+		if r.pattern == pattern && (!gojaValueExists(handler) || handler.SameAs(r.handler)) {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	for i := len(kept); i < len(rs.routes); i++ {
+		rs.routes[i] = nil
+	}
+	rs.routes = kept
+}
+
+func (rs *routes) len() int {
+	if rs == nil {
+		return 0
+	}
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	return len(rs.routes)
+}
+
+// match returns the most recently added route matching the URL, if any.
+func (rs *routes) match(url string) *route {
+	if rs == nil {
+		return nil
+	}
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	for i := len(rs.routes) - 1; i >= 0; i-- {
+		if rs.routes[i].matches(url) {
+			return rs.routes[i]
+		}
+	}
+	return nil
+}
+
+// routeURLPattern returns the source of the URL pattern of an unroute call.
+func routeURLPattern(rt *goja.Runtime, url goja.Value) (string, error) {
+	if !gojaValueExists(url) {
+		return "", errors.New("the route URL must be a glob pattern or a RegExp")
+	}
+	pattern, _, err := parseRouteURL(rt, url)
+	return pattern, err
+}
@@ -0,0 +1,159 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteURLMatching(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		url     string
+		matches bool
+	}{
+		{`"**/analytics.js"`, "https://cdn.example.com/js/analytics.js", true},
+		{`"**/analytics.js"`, "https://cdn.example.com/js/analytics.json", false},
+		{`"https://example.com/*.png"`, "https://example.com/logo.png", true},
+		{`"https://example.com/*.png"`, "https://example.com/img/logo.png", false},
+		{`"https://example.com/**/*.{png,jpg}"`, "https://example.com/img/a.jpg", true},
+		{`"https://example.com/**/*.{png,jpg}"`, "https://example.com/img/a.gif", false},
+		{`"https://example.com/?"`, "https://example.com/a", true},
+		{`/google-analytics\.com/`, "https://www.google-analytics.com/collect", true},
+		{`/EXAMPLE\.com\/api/i`, "https://example.com/api/users", true},
+		{`/EXAMPLE\.com\/api/`, "https://example.com/api/users", false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.pattern+" "+tt.url, func(t *testing.T) {
+			t.Parallel()
+
+			rt := goja.New()
+			url, err := rt.RunString(tt.pattern)
+			require.NoError(t, err)
+			handler, err := rt.RunString(`({abort: true})`)
+			require.NoError(t, err)
+
+			r, err := newRoute(rt, url, handler)
+			require.NoError(t, err)
+			assert.Equal(t, tt.matches, r.matches(tt.url))
+		})
+	}
+}
+
+func TestRouteActions(t *testing.T) {
+	t.Parallel()
+
+	newTestRoute := func(t *testing.T, handler string) *route {
+		t.Helper()
+		rt := goja.New()
+		h, err := rt.RunString("(" + handler + ")")
+		require.NoError(t, err)
+		r, err := newRoute(rt, rt.ToValue("**"), h)
+		require.NoError(t, err)
+		return r
+	}
+
+	t.Run("abort", func(t *testing.T) {
+		t.Parallel()
+
+		r := newTestRoute(t, `{abort: "blockedbyclient"}`)
+		action, ok := r.action("1").(*fetch.FailRequestParams)
+		require.True(t, ok)
+		assert.Equal(t, network.ErrorReasonBlockedByClient, action.ErrorReason)
+		_, continued := r.continuedURL("https://example.com/")
+		assert.False(t, continued)
+
+		r = newTestRoute(t, `{abort: true}`)
+		action, ok = r.action("1").(*fetch.FailRequestParams)
+		require.True(t, ok)
+		assert.Equal(t, network.ErrorReasonFailed, action.ErrorReason)
+	})
+
+	t.Run("fulfill", func(t *testing.T) {
+		t.Parallel()
+
+		r := newTestRoute(t, `{fulfill: {status: 201, contentType: "application/json", body: '{"ok":true}'}}`)
+		action, ok := r.action("1").(*fetch.FulfillRequestParams)
+		require.True(t, ok)
+		assert.Equal(t, int64(201), action.ResponseCode)
+		assert.Equal(t, []*fetch.HeaderEntry{{Name: "Content-Type", Value: "application/json"}}, action.ResponseHeaders)
+		assert.Equal(t, "eyJvayI6dHJ1ZX0=", action.Body)
+		_, continued := r.continuedURL("https://example.com/")
+		assert.False(t, continued)
+	})
+
+	t.Run("continue", func(t *testing.T) {
+		t.Parallel()
+
+		r := newTestRoute(t, `{continue: {url: "https://stub.example.com/", headers: {"X-Test": "1"}}}`)
+		action, ok := r.action("1").(*fetch.ContinueRequestParams)
+		require.True(t, ok)
+		assert.Equal(t, "https://stub.example.com/", action.URL)
+		assert.Equal(t, []*fetch.HeaderEntry{{Name: "X-Test", Value: "1"}}, action.Headers)
+		u, continued := r.continuedURL("https://example.com/")
+		assert.True(t, continued)
+		assert.Equal(t, "https://stub.example.com/", u)
+	})
+}
+
+func TestRouteInvalidHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"function":       `(function() {})`,
+		"no action":      `({})`,
+		"many actions":   `({abort: true, continue: {}})`,
+		"unknown action": `({drop: true})`,
+		"unknown code":   `({abort: "nope"})`,
+		"unknown option": `({fulfill: {code: 200}})`,
+	}
+	for name, handler := range tests {
+		handler := handler
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			rt := goja.New()
+			h, err := rt.RunString(handler)
+			require.NoError(t, err)
+			_, err = newRoute(rt, rt.ToValue("**"), h)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRoutesMatchAndRemove(t *testing.T) {
+	t.Parallel()
+
+	rt := goja.New()
+	abort, err := rt.RunString(`({abort: true})`)
+	require.NoError(t, err)
+	fulfill, err := rt.RunString(`({fulfill: {body: "stub"}})`)
+	require.NoError(t, err)
+
+	var rs routes
+	r1, err := newRoute(rt, rt.ToValue("**/api/**"), abort)
+	require.NoError(t, err)
+	r2, err := newRoute(rt, rt.ToValue("**/api/**"), fulfill)
+	require.NoError(t, err)
+	rs.add(r1)
+	rs.add(r2)
+
+	// the most recently added route takes precedence
+	assert.Same(t, r2, rs.match("https://example.com/api/users"))
+	assert.Nil(t, rs.match("https://example.com/"))
+
+	rs.remove("**/api/**", fulfill)
+	assert.Same(t, r1, rs.match("https://example.com/api/users"))
+	assert.Equal(t, 1, rs.len())
+
+	rs.remove("**/api/**", goja.Undefined())
+	assert.Nil(t, rs.match("https://example.com/api/users"))
+	assert.Equal(t, 0, rs.len())
+}
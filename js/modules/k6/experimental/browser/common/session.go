@@ -9,7 +9,7 @@ import (
 	"github.com/chromedp/cdproto/target"
 	"github.com/mailru/easyjson"
 
-	"github.com/grafana/xk6-browser/log"
+	"go.k6.io/k6/js/modules/k6/experimental/browser/log"
 )
 
 // Ensure Session implements the EventEmitter and Executor interfaces.
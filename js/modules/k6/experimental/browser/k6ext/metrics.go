@@ -23,6 +23,13 @@ const (
 	browserDataReceivedName    = "browser_data_received"
 	browserHTTPReqDurationName = "browser_http_req_duration"
 	browserHTTPReqFailedName   = "browser_http_req_failed"
+
+	browserHTTPReqDNSLookupName      = "browser_http_req_dns_lookup"
+	browserHTTPReqConnectingName     = "browser_http_req_connecting"
+	browserHTTPReqTLSHandshakingName = "browser_http_req_tls_handshaking"
+	browserHTTPReqSendingName        = "browser_http_req_sending"
+	browserHTTPReqWaitingName        = "browser_http_req_waiting"
+	browserHTTPReqReceivingName      = "browser_http_req_receiving"
 )
 
 // CustomMetrics are the custom k6 metrics used by xk6-browser.
@@ -33,6 +40,14 @@ type CustomMetrics struct {
 	BrowserDataReceived    *k6metrics.Metric
 	BrowserHTTPReqDuration *k6metrics.Metric
 	BrowserHTTPReqFailed   *k6metrics.Metric
+
+	// The phases of the resource timing of the requests.
+	BrowserHTTPReqDNSLookup      *k6metrics.Metric
+	BrowserHTTPReqConnecting     *k6metrics.Metric
+	BrowserHTTPReqTLSHandshaking *k6metrics.Metric
+	BrowserHTTPReqSending        *k6metrics.Metric
+	BrowserHTTPReqWaiting        *k6metrics.Metric
+	BrowserHTTPReqReceiving      *k6metrics.Metric
 }
 
 // RegisterCustomMetrics creates and registers our custom metrics with the k6
@@ -66,5 +81,12 @@ func RegisterCustomMetrics(registry *k6metrics.Registry) *CustomMetrics {
 		BrowserDataReceived:    registry.MustNewMetric(browserDataReceivedName, k6metrics.Counter, k6metrics.Data),
 		BrowserHTTPReqDuration: registry.MustNewMetric(browserHTTPReqDurationName, k6metrics.Trend, k6metrics.Time),
 		BrowserHTTPReqFailed:   registry.MustNewMetric(browserHTTPReqFailedName, k6metrics.Rate),
+
+		BrowserHTTPReqDNSLookup:      registry.MustNewMetric(browserHTTPReqDNSLookupName, k6metrics.Trend, k6metrics.Time),
+		BrowserHTTPReqConnecting:     registry.MustNewMetric(browserHTTPReqConnectingName, k6metrics.Trend, k6metrics.Time),
+		BrowserHTTPReqTLSHandshaking: registry.MustNewMetric(browserHTTPReqTLSHandshakingName, k6metrics.Trend, k6metrics.Time),
+		BrowserHTTPReqSending:        registry.MustNewMetric(browserHTTPReqSendingName, k6metrics.Trend, k6metrics.Time),
+		BrowserHTTPReqWaiting:        registry.MustNewMetric(browserHTTPReqWaitingName, k6metrics.Trend, k6metrics.Time),
+		BrowserHTTPReqReceiving:      registry.MustNewMetric(browserHTTPReqReceivingName, k6metrics.Trend, k6metrics.Time),
 	}
 }
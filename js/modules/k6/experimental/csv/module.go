@@ -0,0 +1,267 @@
+// Package csv implements a k6 JS module to parse CSV files once, in the init
+// context, into read-only tables shared by all the VUs. Unlike a SharedArray
+// of the parsed records, the values are kept in typed columns, and the rows
+// are only created when they are accessed.
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct {
+		mu     sync.RWMutex
+		tables map[string]*table
+	}
+
+	// ModuleInstance represents an instance of the JS module.
+	ModuleInstance struct {
+		vu     modules.VU
+		root   *RootModule
+		random *rand.Rand
+	}
+)
+
+// Ensure the interfaces are implemented correctly
+var (
+	_ modules.Instance = &ModuleInstance{}
+	_ modules.Module   = &RootModule{}
+)
+
+// New returns a pointer to a new RootModule instance
+func New() *RootModule {
+	return &RootModule{tables: make(map[string]*table)}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns
+// a new instance for each VU.
+func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{
+		vu:     vu,
+		root:   rm,
+		random: rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec
+	}
+}
+
+// Exports implements the modules.Instance interface and returns
+// the exports of the JS module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"parse": mi.parse,
+		},
+	}
+}
+
+// parse parses the CSV file at the path, relative to the script, and returns
+// its table. The file is only parsed by the first VU, the others get the same
+// table, so like open(), it can only be called in the init context.
+//
+// The options are the delimiter, a comma by default, header, true by default,
+// for the first line to be the names of the columns, and types, an object of
+// the types of the columns, string, number or boolean, which are strings by
+// default.
+func (mi *ModuleInstance) parse(path goja.Value, options goja.Value) *goja.Object {
+	rt := mi.vu.Runtime()
+
+	if mi.vu.State() != nil {
+		common.Throw(rt, errors.New("parse must be called in the init context"))
+	}
+	initEnv := mi.vu.InitEnv()
+	if initEnv == nil {
+		common.Throw(rt, errors.New("missing init environment"))
+	}
+	if common.IsNullish(path) || path.String() == "" {
+		common.Throw(rt, errors.New("the path of the file is required"))
+	}
+
+	opts, err := parseParseOptions(rt, options)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	absPath := initEnv.GetAbsFilePath(path.String())
+	t, err := mi.root.table(absPath+"|"+opts.key(), func() (*table, error) {
+		f, err := initEnv.FileSystems["file"].Open(absPath)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+
+		return parseTable(f, opts)
+	})
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("couldn't parse %q: %w", path.String(), err))
+	}
+
+	return mi.newTableObject(t)
+}
+
+// table returns the table of the key, which is parsed by the parse function
+// on its first use.
+func (rm *RootModule) table(key string, parse func() (*table, error)) (*table, error) {
+	rm.mu.RLock()
+	t, ok := rm.tables[key]
+	rm.mu.RUnlock()
+	if ok {
+		return t, nil
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if t, ok = rm.tables[key]; ok {
+		return t, nil
+	}
+
+	t, err := parse()
+	if err != nil {
+		return nil, err
+	}
+	rm.tables[key] = t
+	return t, nil
+}
+
+func parseParseOptions(rt *goja.Runtime, options goja.Value) (parseOptions, error) {
+	opts := parseOptions{delimiter: ',', header: true}
+	if common.IsNullish(options) {
+		return opts, nil
+	}
+	obj := options.ToObject(rt)
+
+	if v := obj.Get("delimiter"); !common.IsNullish(v) {
+		delimiter := v.String()
+		if utf8.RuneCountInString(delimiter) != 1 {
+			return opts, fmt.Errorf("the delimiter must be a single character, got %q", delimiter)
+		}
+		opts.delimiter, _ = utf8.DecodeRuneInString(delimiter)
+		if opts.delimiter == '"' || opts.delimiter == '\r' || opts.delimiter == '\n' {
+			return opts, fmt.Errorf("invalid delimiter %q", delimiter)
+		}
+	}
+
+	if v := obj.Get("header"); !common.IsNullish(v) {
+		opts.header = v.ToBoolean()
+	}
+
+	if v := obj.Get("types"); !common.IsNullish(v) {
+		typesObj := v.ToObject(rt)
+		opts.types = make(map[string]columnType, len(typesObj.Keys()))
+		for _, name := range typesObj.Keys() {
+			typ := columnType(typesObj.Get(name).String())
+			switch typ {
+			case columnTypeString, columnTypeNumber, columnTypeBoolean:
+			default:
+				return opts, fmt.Errorf("invalid type %q of the column %q, it must be string, number or boolean", typ, name)
+			}
+			opts.types[name] = typ
+		}
+	}
+
+	return opts, nil
+}
+
+// newTableObject returns the Table object of the VU. Its rows are objects of
+// the values by column name, or arrays of the values without a header.
+func (mi *ModuleInstance) newTableObject(t *table) *goja.Object {
+	rt := mi.vu.Runtime()
+	obj := rt.NewObject()
+
+	names := make([]interface{}, len(t.columns))
+	for i, c := range t.columns {
+		names[i] = c.name
+	}
+
+	must(rt, obj.DefineDataProperty("length", rt.ToValue(t.length), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+	must(rt, obj.DefineAccessorProperty("columns", rt.ToValue(func() goja.Value {
+		return rt.NewArray(names...)
+	}), nil, goja.FLAG_FALSE, goja.FLAG_TRUE))
+
+	// get returns the row at the index, or undefined if it's out of range.
+	must(rt, obj.Set("get", func(index int) goja.Value {
+		if index < 0 || index >= t.length {
+			return goja.Undefined()
+		}
+		return mi.row(t, index)
+	}))
+
+	// value returns the value of the column at the row index.
+	must(rt, obj.Set("value", func(index int, name string) goja.Value {
+		i, ok := t.index[name]
+		if !ok {
+			common.Throw(rt, fmt.Errorf("unknown column %q", name))
+		}
+		if index < 0 || index >= t.length {
+			return goja.Undefined()
+		}
+		return rt.ToValue(t.columns[i].value(index))
+	}))
+
+	// random returns a random row, or undefined if the table is empty.
+	must(rt, obj.Set("random", func() goja.Value {
+		if t.length == 0 {
+			return goja.Undefined()
+		}
+		return mi.row(t, mi.random.Intn(t.length))
+	}))
+
+	must(rt, obj.SetSymbol(goja.SymIterator, func() *goja.Object {
+		return mi.newIterator(t)
+	}))
+
+	return obj
+}
+
+func (mi *ModuleInstance) newIterator(t *table) *goja.Object {
+	rt := mi.vu.Runtime()
+	iterator := rt.NewObject()
+
+	index := 0
+	must(rt, iterator.Set("next", func() *goja.Object {
+		result := rt.NewObject()
+		if index >= t.length {
+			must(rt, result.Set("done", true))
+			return result
+		}
+		must(rt, result.Set("value", mi.row(t, index)))
+		must(rt, result.Set("done", false))
+		index++
+		return result
+	}))
+
+	return iterator
+}
+
+func (mi *ModuleInstance) row(t *table, index int) goja.Value {
+	rt := mi.vu.Runtime()
+
+	if !t.header {
+		values := make([]interface{}, len(t.columns))
+		for i, c := range t.columns {
+			values[i] = c.value(index)
+		}
+		return rt.NewArray(values...)
+	}
+
+	row := rt.NewObject()
+	for _, c := range t.columns {
+		must(rt, row.Set(c.name, c.value(index)))
+	}
+	return row
+}
+
+func must(rt *goja.Runtime, err error) {
+	if err != nil {
+		common.Throw(rt, err)
+	}
+}
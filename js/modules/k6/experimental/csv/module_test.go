@@ -0,0 +1,161 @@
+package csv
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+)
+
+const usersCSV = `name,age,active
+alice,32,true
+bob,27,false
+"carol, jr",45,true
+`
+
+func newTestRuntime(t *testing.T, root *RootModule, fs fsext.Fs) *modulestest.Runtime {
+	t.Helper()
+	ts := modulestest.NewRuntime(t)
+	ts.VU.InitEnvField.FileSystems = map[string]fsext.Fs{"file": fs}
+	ts.VU.InitEnvField.CWD = &url.URL{Scheme: "file", Path: "/scripts/"}
+
+	m, ok := root.NewModuleInstance(ts.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, ts.VU.Runtime().Set("csv", m.Exports().Named))
+
+	_, err := ts.VU.Runtime().RunString(`
+		function assertEquals(actual, expected) {
+			if (JSON.stringify(actual) !== JSON.stringify(expected)) {
+				throw new Error("expected " + JSON.stringify(expected) + ", got " + JSON.stringify(actual));
+			}
+		}
+	`)
+	require.NoError(t, err)
+	return ts
+}
+
+func newTestFs(t *testing.T) fsext.Fs {
+	t.Helper()
+	fs := fsext.NewMemMapFs()
+	require.NoError(t, fsext.WriteFile(fs, "/scripts/users.csv", []byte(usersCSV), 0o644))
+	require.NoError(t, fsext.WriteFile(fs, "/scripts/points.csv", []byte("1;2\n3;4\n"), 0o644))
+	return fs
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestRuntime(t, New(), newTestFs(t))
+	_, err := ts.VU.Runtime().RunString(`
+		var users = csv.parse("users.csv", { types: { age: "number", active: "boolean" } });
+		var points = csv.parse("points.csv", { delimiter: ";", header: false, types: { 1: "number" } });
+	`)
+	require.NoError(t, err)
+
+	ts.MoveToVUContext(&lib.State{})
+	_, err = ts.VU.Runtime().RunString(`
+		assertEquals(users.length, 3);
+		assertEquals(users.columns, ["name", "age", "active"]);
+		assertEquals(users.get(0), { name: "alice", age: 32, active: true });
+		assertEquals(users.get(2), { name: "carol, jr", age: 45, active: true });
+		assertEquals(users.get(3), undefined);
+		assertEquals(users.value(1, "age"), 27);
+
+		assertEquals([...users].map(user => user.name), ["alice", "bob", "carol, jr"]);
+		for (let i = 0; i < 10; i++) {
+			const user = users.random();
+			assertEquals(users.columns.every(name => name in user), true);
+		}
+
+		users.get(0).name = "mallory";
+		assertEquals(users.get(0).name, "alice");
+
+		assertEquals(points.columns, ["0", "1"]);
+		assertEquals([...points], [["1", 2], ["3", 4]]);
+	`)
+	require.NoError(t, err)
+}
+
+func TestParseShared(t *testing.T) {
+	t.Parallel()
+
+	root := New()
+	fs := newTestFs(t)
+	for i := 0; i < 2; i++ {
+		ts := newTestRuntime(t, root, fs)
+		_, err := ts.VU.Runtime().RunString(`
+			var users = csv.parse("users.csv");
+			assertEquals(users.get(1).name, "bob");
+		`)
+		require.NoError(t, err)
+
+		// the file is only read by the first VU
+		require.NoError(t, fs.Remove("/scripts/users.csv"))
+		require.NoError(t, fsext.WriteFile(fs, "/scripts/users.csv", []byte("name\nmallory\n"), 0o644))
+	}
+
+	assert.Len(t, root.tables, 1)
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, script, err string
+	}{
+		{
+			name:   "missing file",
+			script: `csv.parse("missing.csv")`,
+			err:    `couldn't parse "missing.csv"`,
+		},
+		{
+			name:   "invalid number",
+			script: `csv.parse("users.csv", { types: { name: "number" } })`,
+			err:    `line 2: couldn't parse "alice" of the column "name" as a number`,
+		},
+		{
+			name:   "invalid type",
+			script: `csv.parse("users.csv", { types: { age: "date" } })`,
+			err:    `invalid type "date" of the column "age"`,
+		},
+		{
+			name:   "unknown column type",
+			script: `csv.parse("users.csv", { types: { email: "string" } })`,
+			err:    `unknown column "email" in the types option`,
+		},
+		{
+			name:   "invalid delimiter",
+			script: `csv.parse("users.csv", { delimiter: ";;" })`,
+			err:    `the delimiter must be a single character, got ";;"`,
+		},
+		{
+			name:   "unknown column",
+			script: `csv.parse("users.csv").value(0, "email")`,
+			err:    `unknown column "email"`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestRuntime(t, New(), newTestFs(t))
+			_, err := ts.VU.Runtime().RunString(tc.script)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+
+	t.Run("VU context", func(t *testing.T) {
+		t.Parallel()
+
+		ts := newTestRuntime(t, New(), newTestFs(t))
+		ts.MoveToVUContext(&lib.State{})
+		_, err := ts.VU.Runtime().RunString(`csv.parse("users.csv")`)
+		require.ErrorContains(t, err, "parse must be called in the init context")
+	})
+}
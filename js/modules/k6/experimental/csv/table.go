@@ -0,0 +1,161 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// columnType is the type of the values of a column.
+type columnType string
+
+const (
+	columnTypeString  columnType = "string"
+	columnTypeNumber  columnType = "number"
+	columnTypeBoolean columnType = "boolean"
+)
+
+// column holds the values of a column, in the slice of its type. The strings
+// of a column are kept in a single string, so that a table of many rows
+// doesn't need a string per value, and they are then sliced without copies.
+type column struct {
+	name string
+	typ  columnType
+
+	data    string
+	offsets []int
+	numbers []float64
+	bools   []bool
+}
+
+func (c *column) value(row int) interface{} {
+	switch c.typ {
+	case columnTypeNumber:
+		return c.numbers[row]
+	case columnTypeBoolean:
+		return c.bools[row]
+	default:
+		return c.data[c.offsets[row]:c.offsets[row+1]]
+	}
+}
+
+// table is a parsed CSV file. It's read-only once parsed,
+// so it's shared by all the VUs without any locking.
+type table struct {
+	header  bool
+	length  int
+	columns []*column
+	index   map[string]int
+}
+
+// parseOptions are the options of parse.
+type parseOptions struct {
+	delimiter rune
+	header    bool
+	types     map[string]columnType
+}
+
+// key returns the key of the options in the cache of the parsed tables,
+// as the same file can be parsed with different options.
+func (o parseOptions) key() string {
+	return fmt.Sprintf("%c|%t|%v", o.delimiter, o.header, o.types)
+}
+
+// parseTable reads the CSV records of r into a table. With the header option,
+// the first record is the names of the columns, otherwise the columns are
+// named by their index.
+func parseTable(r io.Reader, options parseOptions) (*table, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = options.delimiter
+	reader.ReuseRecord = true
+
+	t := &table{header: options.header, index: make(map[string]int)}
+	builders := []*strings.Builder{}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if t.columns == nil {
+			if err := t.initColumns(record, options); err != nil {
+				return nil, err
+			}
+			builders = make([]*strings.Builder, len(t.columns))
+			for i := range builders {
+				builders[i] = &strings.Builder{}
+			}
+			if options.header {
+				continue
+			}
+		}
+
+		line, _ := reader.FieldPos(0)
+		for i, c := range t.columns {
+			if err := c.append(record[i], builders[i]); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		t.length++
+	}
+
+	for i, c := range t.columns {
+		c.data = builders[i].String()
+	}
+	return t, nil
+}
+
+func (t *table) initColumns(record []string, options parseOptions) error {
+	t.columns = make([]*column, len(record))
+	for i, field := range record {
+		name := strconv.Itoa(i)
+		if options.header {
+			name = field
+		}
+		if _, ok := t.index[name]; ok {
+			return fmt.Errorf("duplicate column %q", name)
+		}
+		t.index[name] = i
+
+		typ, ok := options.types[name]
+		if !ok {
+			typ = columnTypeString
+		}
+		t.columns[i] = &column{name: name, typ: typ, offsets: []int{0}}
+	}
+
+	for name := range options.types {
+		if _, ok := t.index[name]; !ok {
+			return fmt.Errorf("unknown column %q in the types option", name)
+		}
+	}
+	return nil
+}
+
+func (c *column) append(field string, data *strings.Builder) error {
+	switch c.typ {
+	case columnTypeNumber:
+		v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return fmt.Errorf("couldn't parse %q of the column %q as a number", field, c.name)
+		}
+		c.numbers = append(c.numbers, v)
+	case columnTypeBoolean:
+		v, err := strconv.ParseBool(strings.TrimSpace(field))
+		if err != nil {
+			return fmt.Errorf("couldn't parse %q of the column %q as a boolean", field, c.name)
+		}
+		c.bools = append(c.bools, v)
+	default:
+		data.WriteString(field)
+		c.offsets = append(c.offsets, data.Len())
+	}
+	return nil
+}
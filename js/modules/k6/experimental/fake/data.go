@@ -0,0 +1,75 @@
+package fake
+
+// The data of the generators. It's shared by all the VUs, unlike the
+// data of the faker libraries bundled with the scripts.
+
+//nolint:gochecknoglobals
+var (
+	firstNames = []string{
+		"Aaliyah", "Adrian", "Aisha", "Alex", "Alice", "Amir", "Ana", "Andrea", "Ben", "Carlos",
+		"Chen", "Chloe", "Daniel", "David", "Elena", "Emma", "Ethan", "Fatima", "Felix", "Gabriel",
+		"Grace", "Hana", "Hugo", "Isabel", "Ivan", "Jack", "James", "Jin", "Julia", "Kai",
+		"Laura", "Leo", "Lucas", "Lucia", "Maria", "Mateo", "Maya", "Mia", "Mohammed", "Nina",
+		"Noah", "Olivia", "Omar", "Pablo", "Priya", "Rafael", "Sara", "Sofia", "Tom", "Yuki",
+	}
+	lastNames = []string{
+		"Ahmed", "Andersen", "Brown", "Chen", "Costa", "Dubois", "Fernandez", "Fischer", "Garcia", "Gonzalez",
+		"Hansen", "Hernandez", "Ivanov", "Jansen", "Johnson", "Kim", "Kowalski", "Kumar", "Lee", "Lopez",
+		"Martin", "Martinez", "Meyer", "Miller", "Moreau", "Muller", "Nakamura", "Nguyen", "Novak", "Okafor",
+		"Olsen", "Patel", "Perez", "Rossi", "Santos", "Schmidt", "Silva", "Singh", "Smith", "Tanaka",
+		"Taylor", "Thomas", "Wagner", "Wang", "Williams", "Wilson", "Yamamoto", "Young", "Zhang", "Zimmermann",
+	}
+	emailDomains = []string{"example.com", "example.net", "example.org"}
+	streetNames  = []string{
+		"Acacia", "Ash", "Bay", "Cedar", "Church", "Elm", "Forest", "Garden", "Highland", "Hill",
+		"Lake", "Maple", "Meadow", "Mill", "Oak", "Park", "Pine", "River", "Spring", "Station",
+		"Sunset", "Valley", "Walnut", "Willow", "Wood",
+	}
+	streetSuffixes = []string{"Avenue", "Boulevard", "Court", "Drive", "Lane", "Place", "Road", "Street", "Way"}
+	cities         = []string{
+		"Ashford", "Bridgeport", "Brookfield", "Clayton", "Fairview", "Franklin", "Georgetown", "Greenville",
+		"Kingston", "Lakewood", "Madison", "Marion", "Milford", "Newport", "Oakland", "Riverside",
+		"Salem", "Springfield", "Westfield", "Winchester",
+	}
+	states = []string{
+		"Alaska", "Arizona", "California", "Colorado", "Florida", "Georgia", "Illinois", "Maine",
+		"Nevada", "New York", "Ohio", "Oregon", "Texas", "Utah", "Vermont", "Washington",
+	}
+	countries = []string{
+		"Argentina", "Australia", "Brazil", "Canada", "France", "Germany", "India", "Italy", "Japan", "Kenya",
+		"Mexico", "Netherlands", "Nigeria", "Norway", "Poland", "Portugal", "South Korea", "Spain", "Sweden",
+		"United Kingdom", "United States",
+	}
+	loremWords = []string{
+		"a", "ac", "accumsan", "ad", "adipiscing", "aliqua", "aliquam", "aliquip", "amet", "ante",
+		"anim", "arcu", "at", "auctor", "augue", "aute", "bibendum", "blandit", "commodo", "condimentum",
+		"consectetur", "consequat", "cillum", "culpa", "cupidatat", "cursus", "dapibus", "deserunt", "diam", "dictum",
+		"dolor", "dolore", "donec", "dui", "duis", "egestas", "eget", "eiusmod", "elementum", "elit",
+		"enim", "erat", "eros", "esse", "est", "et", "eu", "euismod", "ex", "excepteur",
+		"exercitation", "facilisis", "fames", "faucibus", "felis", "fermentum", "feugiat", "fringilla", "fugiat", "fusce",
+		"gravida", "habitant", "hendrerit", "id", "in", "incididunt", "interdum", "ipsum", "irure", "justo",
+		"labore", "laboris", "lacus", "laoreet", "lectus", "leo", "libero", "ligula", "lorem", "magna",
+		"massa", "mattis", "mauris", "metus", "mi", "minim", "mollit", "morbi", "nam", "nec",
+		"neque", "nibh", "nisi", "nisl", "non", "nostrud", "nulla", "nunc", "occaecat", "odio",
+		"officia", "orci", "pariatur", "pellentesque", "pharetra", "placerat", "porta", "porttitor", "proident", "purus",
+		"quam", "qui", "quis", "quisque", "reprehenderit", "risus", "rutrum", "sagittis", "sapien", "sed",
+		"sem", "semper", "sint", "sit", "sollicitudin", "sunt", "tellus", "tempor", "tempus", "tincidunt",
+		"tortor", "turpis", "ullamco", "ultrices", "ultricies", "urna", "ut", "varius", "vel", "velit",
+		"veniam", "vitae", "voluptate", "vulputate",
+	}
+)
+
+// cardType is a type of credit card, with the prefixes and the length of
+// its numbers.
+type cardType struct {
+	prefixes []string
+	length   int
+}
+
+//nolint:gochecknoglobals
+var cardTypes = map[string]cardType{
+	"visa":       {prefixes: []string{"4"}, length: 16},
+	"mastercard": {prefixes: []string{"51", "52", "53", "54", "55"}, length: 16},
+	"amex":       {prefixes: []string{"34", "37"}, length: 15},
+	"discover":   {prefixes: []string{"6011", "65"}, length: 16},
+}
@@ -0,0 +1,268 @@
+package fake
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+)
+
+// faker generates the data from its source of randomness, so that it
+// generates the same data again when it's seeded with the same seed.
+type faker struct {
+	rt     *goja.Runtime
+	random *rand.Rand
+}
+
+func newFaker(rt *goja.Runtime, seed int64) *faker {
+	return &faker{rt: rt, random: rand.New(rand.NewSource(seed))} //nolint:gosec
+}
+
+// methods returns the generators of the faker, by their JS name.
+func (f *faker) methods() map[string]interface{} {
+	return map[string]interface{}{
+		"seed":       f.seed,
+		"firstName":  f.firstName,
+		"lastName":   f.lastName,
+		"name":       f.name,
+		"username":   f.username,
+		"email":      f.email,
+		"phone":      f.phone,
+		"street":     f.street,
+		"city":       f.city,
+		"state":      f.state,
+		"zipCode":    f.zipCode,
+		"country":    f.country,
+		"address":    f.address,
+		"uuid":       f.uuid,
+		"creditCard": f.creditCard,
+		"word":       f.word,
+		"words":      f.words,
+		"sentence":   f.sentence,
+		"paragraph":  f.paragraph,
+		"integer":    f.integer,
+		"float":      f.float,
+		"boolean":    f.boolean,
+		"pick":       f.pick,
+	}
+}
+
+// seed resets the source of randomness with the seed, e.g. with the ID of the
+// VU and its iteration, for each iteration to get the same data in each test.
+func (f *faker) seed(seed int64) {
+	f.random.Seed(seed)
+}
+
+func (f *faker) one(values []string) string {
+	return values[f.random.Intn(len(values))]
+}
+
+func (f *faker) digits(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('0' + f.random.Intn(10))
+	}
+	return string(b)
+}
+
+func (f *faker) firstName() string {
+	return f.one(firstNames)
+}
+
+func (f *faker) lastName() string {
+	return f.one(lastNames)
+}
+
+func (f *faker) name() string {
+	return f.firstName() + " " + f.lastName()
+}
+
+func (f *faker) username() string {
+	return strings.ToLower(f.firstName()+"."+f.lastName()) + f.digits(2)
+}
+
+// email returns an email address of the domains reserved for the examples,
+// so that the tests never send emails to real addresses.
+func (f *faker) email() string {
+	return f.username() + "@" + f.one(emailDomains)
+}
+
+// phone returns a phone number of the 555-01XX range, reserved for fiction.
+func (f *faker) phone() string {
+	return "+1-" + f.digits(3) + "-555-01" + f.digits(2)
+}
+
+func (f *faker) street() string {
+	return fmt.Sprintf("%d %s %s", 1+f.random.Intn(9999), f.one(streetNames), f.one(streetSuffixes))
+}
+
+func (f *faker) city() string {
+	return f.one(cities)
+}
+
+func (f *faker) state() string {
+	return f.one(states)
+}
+
+func (f *faker) zipCode() string {
+	return f.digits(5)
+}
+
+func (f *faker) country() string {
+	return f.one(countries)
+}
+
+func (f *faker) address() map[string]string {
+	return map[string]string{
+		"street":  f.street(),
+		"city":    f.city(),
+		"state":   f.state(),
+		"zipCode": f.zipCode(),
+		"country": f.country(),
+	}
+}
+
+// uuid returns a random (version 4) UUID.
+func (f *faker) uuid() string {
+	b := make([]byte, 16)
+	_, _ = f.random.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// creditCard returns a credit card of the type option, or of a random type,
+// with a number which passes the Luhn check, a future expiry date, and a CVV.
+func (f *faker) creditCard(options goja.Value) map[string]string {
+	var name string
+	if !common.IsNullish(options) {
+		if v := options.ToObject(f.rt).Get("type"); !common.IsNullish(v) {
+			name = v.String()
+		}
+	}
+	if name == "" {
+		names := make([]string, 0, len(cardTypes))
+		for n := range cardTypes {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		name = f.one(names)
+	}
+	card, ok := cardTypes[name]
+	if !ok {
+		common.Throw(f.rt, fmt.Errorf("unknown credit card type %q, it must be visa, mastercard, amex or discover", name))
+	}
+
+	number := f.one(card.prefixes)
+	number += f.digits(card.length - len(number) - 1)
+	number += string(rune('0' + luhnCheckDigit(number)))
+
+	cvvLength := 3
+	if name == "amex" {
+		cvvLength = 4
+	}
+
+	return map[string]string{
+		"type":   name,
+		"number": number,
+		"expiry": fmt.Sprintf("%02d/%02d", 1+f.random.Intn(12), (time.Now().Year()+1+f.random.Intn(5))%100),
+		"cvv":    f.digits(cvvLength),
+	}
+}
+
+// luhnCheckDigit returns the digit which makes the number pass the Luhn check.
+func luhnCheckDigit(number string) int {
+	sum := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		// the digits are doubled from the rightmost one, as the check digit is appended
+		if (len(number)-i)%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return (10 - sum%10) % 10
+}
+
+func (f *faker) word() string {
+	return f.one(loremWords)
+}
+
+func (f *faker) words(count goja.Value) string {
+	n := f.count(count, 3)
+	words := make([]string, n)
+	for i := range words {
+		words[i] = f.word()
+	}
+	return strings.Join(words, " ")
+}
+
+func (f *faker) sentence(count goja.Value) string {
+	words := f.words(f.rt.ToValue(f.count(count, 4+f.random.Intn(8))))
+	return strings.ToUpper(words[:1]) + words[1:] + "."
+}
+
+func (f *faker) paragraph(count goja.Value) string {
+	sentences := make([]string, f.count(count, 3+f.random.Intn(4)))
+	for i := range sentences {
+		sentences[i] = f.sentence(goja.Undefined())
+	}
+	return strings.Join(sentences, " ")
+}
+
+// count returns the count argument, or the default one if it's missing.
+func (f *faker) count(count goja.Value, defaultCount int) int {
+	if common.IsNullish(count) {
+		return defaultCount
+	}
+	n := int(count.ToInteger())
+	if n < 1 {
+		common.Throw(f.rt, fmt.Errorf("the count must be greater than zero, got %d", n))
+	}
+	return n
+}
+
+// integer returns an integer between min and max, both included.
+func (f *faker) integer(min, max int64) int64 {
+	if min > max {
+		common.Throw(f.rt, fmt.Errorf("the min %d is greater than the max %d", min, max))
+	}
+	return min + f.random.Int63n(max-min+1)
+}
+
+// float returns a number between min, included, and max, excluded.
+func (f *faker) float(min, max float64) float64 {
+	if min > max {
+		common.Throw(f.rt, fmt.Errorf("the min %v is greater than the max %v", min, max))
+	}
+	return min + f.random.Float64()*(max-min)
+}
+
+func (f *faker) boolean() bool {
+	return f.random.Intn(2) == 1
+}
+
+// pick returns a random element of the array.
+func (f *faker) pick(array goja.Value) goja.Value {
+	if common.IsNullish(array) {
+		common.Throw(f.rt, errors.New("an array is expected"))
+	}
+	obj := array.ToObject(f.rt)
+	length := obj.Get("length")
+	if common.IsNullish(length) {
+		common.Throw(f.rt, errors.New("an array is expected"))
+	}
+	if length.ToInteger() == 0 {
+		return goja.Undefined()
+	}
+	return obj.Get(strconv.FormatInt(f.random.Int63n(length.ToInteger()), 10))
+}
@@ -0,0 +1,73 @@
+// Package fake implements a k6 JS module to generate synthetic data, such as
+// names, addresses or credit cards, in Go. The data is generated from a
+// seedable source of randomness, so that the tests can be reproduced.
+package fake
+
+import (
+	"time"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the JS module.
+	ModuleInstance struct {
+		vu modules.VU
+		// faker is the faker of the functions exported by the module.
+		faker *faker
+	}
+)
+
+// Ensure the interfaces are implemented correctly
+var (
+	_ modules.Instance = &ModuleInstance{}
+	_ modules.Module   = &RootModule{}
+)
+
+// New returns a pointer to a new RootModule instance
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns
+// a new instance for each VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{
+		vu:    vu,
+		faker: newFaker(vu.Runtime(), time.Now().UnixNano()),
+	}
+}
+
+// Exports implements the modules.Instance interface and returns
+// the exports of the JS module. The generators are exported as functions of
+// a faker of the VU, which can be seeded, and of the Faker instances.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	named := mi.faker.methods()
+	named["Faker"] = mi.newFaker
+	return modules.Exports{Named: named}
+}
+
+// newFaker is the constructor of the Faker instances, which are seeded
+// with their seed argument, or randomly without it.
+func (mi *ModuleInstance) newFaker(call goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+
+	seed := time.Now().UnixNano()
+	if arg := call.Argument(0); !common.IsNullish(arg) {
+		seed = arg.ToInteger()
+	}
+
+	obj := rt.NewObject()
+	for name, method := range newFaker(rt, seed).methods() {
+		if err := obj.Set(name, method); err != nil {
+			common.Throw(rt, err)
+		}
+	}
+	return obj
+}
@@ -0,0 +1,157 @@
+package fake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/js/modulestest"
+)
+
+func newTestRuntime(t *testing.T) *modulestest.Runtime {
+	t.Helper()
+	ts := modulestest.NewRuntime(t)
+	m, ok := New().NewModuleInstance(ts.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, ts.VU.Runtime().Set("fake", m.Exports().Named))
+
+	_, err := ts.VU.Runtime().RunString(`
+		function assert(condition, message) {
+			if (!condition) {
+				throw new Error(message);
+			}
+		}
+	`)
+	require.NoError(t, err)
+	return ts
+}
+
+func TestGenerators(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestRuntime(t)
+	_, err := ts.VU.Runtime().RunString(`
+		for (let i = 0; i < 100; i++) {
+			const name = fake.name();
+			assert(/^[A-Z][a-z]+ [A-Z][a-z]+$/.test(name), name);
+			const email = fake.email();
+			assert(/^[a-z]+\.[a-z]+\d\d@example\.(com|net|org)$/.test(email), email);
+			assert(/^\+1-\d{3}-555-01\d\d$/.test(fake.phone()), fake.phone());
+			const uuid = fake.uuid();
+			assert(/^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$/.test(uuid), uuid);
+
+			const address = fake.address();
+			assert(/^\d+ [A-Z][a-z]+ [A-Z][a-z]+$/.test(address.street), address.street);
+			assert(/^\d{5}$/.test(address.zipCode), address.zipCode);
+			assert(address.city && address.state && address.country, JSON.stringify(address));
+
+			const sentence = fake.sentence();
+			assert(/^[A-Z][a-z]*( [a-z]+){3,10}\.$/.test(sentence), sentence);
+			assert(fake.words(5).split(" ").length === 5, "words");
+			assert(fake.paragraph(2).split(". ").length === 2, "paragraph");
+
+			const integer = fake.integer(-2, 2);
+			assert(Number.isInteger(integer) && integer >= -2 && integer <= 2, integer);
+			const float = fake.float(1, 1.5);
+			assert(float >= 1 && float < 1.5, float);
+			assert(typeof fake.boolean() === "boolean", "boolean");
+			assert(["a", "b"].includes(fake.pick(["a", "b"])), "pick");
+		}
+	`)
+	require.NoError(t, err)
+}
+
+func TestCreditCard(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestRuntime(t)
+	v, err := ts.VU.Runtime().RunString(`
+		const cards = [];
+		for (let i = 0; i < 50; i++) {
+			cards.push(fake.creditCard());
+		}
+		cards.push(fake.creditCard({ type: "amex" }));
+		cards
+	`)
+	require.NoError(t, err)
+
+	var cards []map[string]string
+	require.NoError(t, ts.VU.Runtime().ExportTo(v, &cards))
+	for _, card := range cards {
+		assert.True(t, luhnValid(card["number"]), card["number"])
+		assert.Regexp(t, `^(0[1-9]|1[0-2])/\d\d$`, card["expiry"])
+	}
+
+	amex := cards[len(cards)-1]
+	assert.Equal(t, "amex", amex["type"])
+	assert.Len(t, amex["number"], 15)
+	assert.Len(t, amex["cvv"], 4)
+	assert.Regexp(t, `^3[47]`, amex["number"])
+}
+
+func luhnValid(number string) bool {
+	sum := 0
+	for i := range number {
+		d := int(number[len(number)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+func TestLuhnCheckDigit(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 3, luhnCheckDigit("7992739871"))
+	assert.Equal(t, 1, luhnCheckDigit("411111111111111"))
+}
+
+func TestSeed(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestRuntime(t)
+	_, err := ts.VU.Runtime().RunString(`
+		const generate = faker => [faker.name(), faker.email(), faker.uuid(), faker.creditCard().number, faker.paragraph()];
+
+		const a = new fake.Faker(42);
+		const b = new fake.Faker(42);
+		const first = JSON.stringify(generate(a));
+		assert(first === JSON.stringify(generate(b)), "the fakers with the same seed should generate the same data");
+		assert(first !== JSON.stringify(generate(a)), "the fakers should generate new data");
+
+		fake.seed(42);
+		assert(first === JSON.stringify(generate(fake)), "the seeded faker of the module should generate the same data");
+		a.seed(42);
+		assert(first === JSON.stringify(generate(a)), "the reseeded faker should generate the same data");
+	`)
+	require.NoError(t, err)
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		script, err string
+	}{
+		{script: `fake.creditCard({ type: "jcb" })`, err: `unknown credit card type "jcb"`},
+		{script: `fake.integer(2, 1)`, err: "the min 2 is greater than the max 1"},
+		{script: `fake.words(0)`, err: "the count must be greater than zero, got 0"},
+		{script: `fake.pick(null)`, err: "an array is expected"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.script, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestRuntime(t)
+			_, err := ts.VU.Runtime().RunString(tc.script)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+
+	"github.com/dop251/goja"
+	"github.com/spf13/afero"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/js/modules/k6/experimental/streams"
+)
+
+// file is a file opened by a VU. The file is read and written off the event
+// loop, so its reads and writes are serialized.
+type file struct {
+	vu   modules.VU
+	root *RootModule
+	path string
+	mode openMode
+
+	mx   sync.Mutex
+	file afero.File
+}
+
+var (
+	errNotReadable = errors.New("the file isn't opened for reading")
+	errNotWritable = errors.New("the file isn't opened for writing")
+)
+
+// object returns the File object given to the script.
+func (f *file) object(rt *goja.Runtime) *goja.Object {
+	obj := rt.NewObject()
+	must(rt, obj.DefineDataProperty("path", rt.ToValue(f.path), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+	must(rt, obj.Set("stat", f.stat))
+	must(rt, obj.Set("read", f.read))
+	must(rt, obj.Set("seek", f.seek))
+	must(rt, obj.Set("readable", f.readable))
+	must(rt, obj.Set("write", f.write))
+	must(rt, obj.Set("close", f.close))
+	return obj
+}
+
+// stat returns a promise of the information about the file, its name and size.
+func (f *file) stat() *goja.Promise {
+	rt := f.vu.Runtime()
+	promise, resolve, reject := rt.NewPromise()
+
+	f.mx.Lock()
+	info, err := f.file.Stat()
+	f.mx.Unlock()
+	if err != nil {
+		reject(rt.NewGoError(err))
+		return promise
+	}
+
+	stat := rt.NewObject()
+	must(rt, stat.Set("name", filepath.Base(f.path)))
+	must(rt, stat.Set("size", info.Size()))
+	resolve(stat)
+	return promise
+}
+
+// read reads up to the length of the buffer, a Uint8Array or an ArrayBuffer,
+// into it, from the current position in the file. It returns a promise of
+// the number of bytes read, or of null once the end of the file is reached.
+func (f *file) read(buffer goja.Value) *goja.Promise {
+	rt := f.vu.Runtime()
+	promise, resolve, reject := rt.NewPromise()
+
+	if f.mode != openModeRead {
+		reject(rt.NewGoError(errNotReadable))
+		return promise
+	}
+
+	var into []byte
+	if !common.IsNullish(buffer) {
+		switch b := buffer.Export().(type) {
+		case []byte:
+			into = b
+		case goja.ArrayBuffer:
+			into = b.Bytes()
+		}
+	}
+	if into == nil {
+		reject(rt.NewTypeError("the buffer must be a Uint8Array or an ArrayBuffer"))
+		return promise
+	}
+
+	callback := f.vu.RegisterCallback()
+	go func() {
+		// the data is read in another buffer, as the one of
+		// the script can only be written on the event loop
+		data := make([]byte, len(into))
+		f.mx.Lock()
+		n, err := io.ReadFull(f.file, data)
+		f.mx.Unlock()
+
+		callback(func() error {
+			switch {
+			case n == 0 && errors.Is(err, io.EOF):
+				resolve(goja.Null())
+			case err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF):
+				reject(rt.NewGoError(fmt.Errorf("couldn't read %q: %w", f.path, err)))
+			default:
+				copy(into, data[:n])
+				resolve(n)
+			}
+			return nil
+		})
+	}()
+
+	return promise
+}
+
+// seek sets the current position in the file, to the offset relative to the
+// whence SeekMode, and returns a promise of the new position.
+func (f *file) seek(offset int64, whence SeekMode) *goja.Promise {
+	rt := f.vu.Runtime()
+	promise, resolve, reject := rt.NewPromise()
+
+	if whence < SeekModeStart || whence > SeekModeEnd {
+		reject(rt.NewTypeError(fmt.Sprintf("invalid seek mode %d", whence)))
+		return promise
+	}
+
+	f.mx.Lock()
+	position, err := f.file.Seek(offset, whence)
+	f.mx.Unlock()
+	if err != nil {
+		reject(rt.NewGoError(fmt.Errorf("couldn't seek in %q: %w", f.path, err)))
+		return promise
+	}
+
+	resolve(position)
+	return promise
+}
+
+// readable returns a ReadableStream, as the ones of the k6/experimental/streams
+// module, of the file from its current position, with Uint8Array chunks of up
+// to the chunkSize option bytes.
+func (f *file) readable(options goja.Value) (*goja.Object, error) {
+	if f.mode != openModeRead {
+		return nil, errNotReadable
+	}
+
+	chunkSize := streams.DefaultChunkSize
+	if !common.IsNullish(options) {
+		if v := options.ToObject(f.vu.Runtime()).Get("chunkSize"); !common.IsNullish(v) {
+			chunkSize = int(v.ToInteger())
+			if chunkSize <= 0 {
+				return nil, fmt.Errorf("the chunk size must be greater than zero, got %d", chunkSize)
+			}
+		}
+	}
+
+	return streams.NewReadableStreamFromReader(f.vu, fileReader{f}, chunkSize)
+}
+
+// write writes the data, a string, an ArrayBuffer or a typed array, to the
+// file, at its current position or at its end if it's opened in the a mode.
+// It returns a promise of the number of bytes written.
+func (f *file) write(data goja.Value) *goja.Promise {
+	rt := f.vu.Runtime()
+	promise, resolve, reject := rt.NewPromise()
+
+	if f.mode == openModeRead {
+		reject(rt.NewGoError(errNotWritable))
+		return promise
+	}
+
+	var b []byte
+	if !common.IsNullish(data) {
+		var err error
+		if b, err = common.ToBytes(data.Export()); err != nil {
+			reject(rt.NewTypeError("the data must be a string, an ArrayBuffer or a typed array"))
+			return promise
+		}
+		// the data of the script can only be read on the event loop
+		b = append([]byte{}, b...)
+	}
+
+	if err := f.root.reserve(int64(len(b))); err != nil {
+		reject(rt.NewGoError(fmt.Errorf("couldn't write to %q: %w", f.path, err)))
+		return promise
+	}
+
+	callback := f.vu.RegisterCallback()
+	go func() {
+		f.mx.Lock()
+		n, err := f.file.Write(b)
+		f.mx.Unlock()
+		f.root.release(int64(len(b) - n))
+
+		callback(func() error {
+			if err != nil {
+				reject(rt.NewGoError(fmt.Errorf("couldn't write to %q: %w", f.path, err)))
+				return nil
+			}
+			resolve(n)
+			return nil
+		})
+	}()
+
+	return promise
+}
+
+// close closes the file, which can't be read or written anymore, and returns
+// a promise resolved once it's closed.
+func (f *file) close() *goja.Promise {
+	rt := f.vu.Runtime()
+	promise, resolve, reject := rt.NewPromise()
+
+	f.mx.Lock()
+	err := f.file.Close()
+	f.mx.Unlock()
+	if err != nil {
+		reject(rt.NewGoError(fmt.Errorf("couldn't close %q: %w", f.path, err)))
+		return promise
+	}
+
+	resolve(goja.Undefined())
+	return promise
+}
+
+// fileReader reads the file for its ReadableStream. The file isn't closed
+// with the stream, so that it can still be read, e.g. after a seek.
+type fileReader struct {
+	f *file
+}
+
+func (r fileReader) Read(p []byte) (int, error) {
+	r.f.mx.Lock()
+	defer r.f.mx.Unlock()
+	return r.f.file.Read(p)
+}
+
+func (fileReader) Close() error {
+	return nil
+}
+
+func must(rt *goja.Runtime, err error) {
+	if err != nil {
+		common.Throw(rt, err)
+	}
+}
@@ -0,0 +1,346 @@
+// Package fs implements a k6 JS module to read files without loading them in
+// memory, unlike open(), so that scripts can process big datasets chunk by
+// chunk, e.g. through the ReadableStream of a file, and to write files, e.g.
+// logs or datasets for the later stages of a test. The files can only be
+// written with the --fs-write option, in the directory of the --fs-write-dir
+// option or in a temporary one, and up to the --fs-write-quota bytes.
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/dop251/goja"
+	"github.com/spf13/afero"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+)
+
+// defaultWriteQuota is the quota of the bytes written by all the VUs of the
+// instance, when the fs-write-quota option isn't set.
+const defaultWriteQuota = 100 << 20
+
+var errWriteDisabled = errors.New("writing files is disabled, it can be enabled with the --fs-write option")
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct {
+		// fs is the file system of the written files. The read files are
+		// read from the file system of the test, so that they're archived.
+		fs fsext.Fs
+
+		initOnce sync.Once
+		initErr  error
+		writable bool
+		quota    int64
+		written  int64
+
+		// writeDir is the directory the written files are confined to, it's
+		// the one of the fs-write-dir option or a temporary one of the test.
+		writeDirMu sync.Mutex
+		writeDir   string
+	}
+
+	// ModuleInstance represents an instance of the JS module.
+	ModuleInstance struct {
+		vu      modules.VU
+		root    *RootModule
+		tempDir string
+	}
+)
+
+// Ensure the interfaces are implemented correctly
+var (
+	_ modules.Instance = &ModuleInstance{}
+	_ modules.Module   = &RootModule{}
+)
+
+// SeekMode is the reference position of File.seek.
+type SeekMode = int
+
+const (
+	// SeekModeStart seeks relatively to the start of the file.
+	SeekModeStart SeekMode = iota
+	// SeekModeCurrent seeks relatively to the current position in the file.
+	SeekModeCurrent
+	// SeekModeEnd seeks relatively to the end of the file.
+	SeekModeEnd
+)
+
+// openMode is the mode in which a file is opened.
+type openMode string
+
+const (
+	// openModeRead opens an existing file for reading.
+	openModeRead openMode = "r"
+	// openModeWrite creates or truncates a file, for writing.
+	openModeWrite openMode = "w"
+	// openModeAppend creates a file or opens an existing one, for writing at its end.
+	openModeAppend openMode = "a"
+)
+
+// New returns a pointer to a new RootModule instance
+func New() *RootModule {
+	return &RootModule{fs: fsext.NewOsFs()}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns
+// a new instance for each VU.
+func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	initEnv := vu.InitEnv()
+	rm.initOnce.Do(func() {
+		if initEnv == nil || initEnv.TestPreInitState == nil {
+			return
+		}
+		rm.initErr = rm.configure(initEnv.TestPreInitState.RuntimeOptions)
+	})
+	if rm.initErr != nil {
+		common.Throw(vu.Runtime(), rm.initErr)
+	}
+
+	return &ModuleInstance{vu: vu, root: rm}
+}
+
+// configure sets the writing of the files up from the runtime options, the
+// files can only be written with the fs-write option.
+func (rm *RootModule) configure(opts lib.RuntimeOptions) error {
+	rm.writable = opts.FSWrite.Bool
+	if opts.FSWriteDir.String != "" {
+		dir, err := filepath.Abs(opts.FSWriteDir.String)
+		if err != nil {
+			return fmt.Errorf("invalid fs-write-dir: %w", err)
+		}
+		rm.writeDir = dir
+	}
+
+	rm.quota = defaultWriteQuota
+	if opts.FSWriteQuota.String != "" {
+		quota, err := parseSize(opts.FSWriteQuota.String)
+		if err != nil {
+			return fmt.Errorf("invalid fs-write-quota: %w", err)
+		}
+		if quota == 0 {
+			return errors.New("invalid fs-write-quota: it must be greater than zero")
+		}
+		rm.quota = quota
+	}
+	return nil
+}
+
+// parseSize parses a number of bytes, with an optional KB, MB or GB unit.
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for unit, m := range map[string]int64{"KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30} {
+		if strings.HasSuffix(s, unit) {
+			s, multiplier = strings.TrimSpace(strings.TrimSuffix(s, unit)), m
+			break
+		}
+	}
+	s = strings.TrimSuffix(s, "B")
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q isn't a size in bytes", s)
+	}
+	return n * multiplier, nil
+}
+
+// reserve accounts the bytes about to be written, if they don't exceed the quota.
+func (rm *RootModule) reserve(n int64) error {
+	if atomic.AddInt64(&rm.written, n) > rm.quota {
+		atomic.AddInt64(&rm.written, -n)
+		return fmt.Errorf("the write quota of %d bytes is exceeded", rm.quota)
+	}
+	return nil
+}
+
+// release accounts the bytes which were reserved, but not written.
+func (rm *RootModule) release(n int64) {
+	atomic.AddInt64(&rm.written, -n)
+}
+
+// Exports implements the modules.Instance interface and returns
+// the exports of the JS module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"open":    mi.open,
+			"tempDir": mi.getTempDir,
+			"SeekMode": map[string]interface{}{
+				"Start":   SeekModeStart,
+				"Current": SeekModeCurrent,
+				"End":     SeekModeEnd,
+			},
+		},
+	}
+}
+
+// open opens the file at the path in the mode, and returns a promise of it.
+// The mode is either r, the default, to read the file, w, to create or
+// truncate it, or a, to append to it.
+//
+// Like open(), the files can only be opened for reading in the init context,
+// so that they are part of the archive of the test, and their paths are
+// relative to the script. The files can only be opened for writing in the VU
+// context with the fs-write option, in the write directory, and their paths
+// are relative to it.
+func (mi *ModuleInstance) open(path goja.Value, mode goja.Value) *goja.Promise {
+	rt := mi.vu.Runtime()
+	promise, resolve, reject := rt.NewPromise()
+
+	file, err := mi.openFile(path, mode)
+	if err != nil {
+		reject(rt.NewGoError(err))
+		return promise
+	}
+
+	resolve(file.object(rt))
+	return promise
+}
+
+func (mi *ModuleInstance) openFile(path goja.Value, mode goja.Value) (*file, error) {
+	m := openModeRead
+	if !common.IsNullish(mode) {
+		m = openMode(mode.String())
+	}
+
+	if common.IsNullish(path) || path.String() == "" {
+		return nil, errors.New("the path of the file is required")
+	}
+
+	switch m {
+	case openModeRead:
+		return mi.openRead(path.String())
+	case openModeWrite, openModeAppend:
+		return mi.openWrite(path.String(), m)
+	default:
+		return nil, fmt.Errorf("invalid mode %q, it must be r, w or a", m)
+	}
+}
+
+func (mi *ModuleInstance) openRead(path string) (*file, error) {
+	if mi.vu.State() != nil {
+		return nil, errors.New("open must be called in the init context to read a file")
+	}
+	initEnv := mi.vu.InitEnv()
+	if initEnv == nil {
+		return nil, errors.New("missing init environment")
+	}
+
+	fs := initEnv.FileSystems["file"]
+	absPath := initEnv.GetAbsFilePath(path)
+
+	// the file is opened in the init context, so that it's then
+	// available in the archive of the test and in the VU context.
+	f, err := fs.Open(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open %q: %w", path, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("couldn't stat %q: %w", path, err)
+	}
+	if info.IsDir() {
+		_ = f.Close()
+		return nil, fmt.Errorf("open can't be used with directories, path: %q", path)
+	}
+
+	return &file{vu: mi.vu, root: mi.root, path: absPath, mode: openModeRead, file: f}, nil
+}
+
+func (mi *ModuleInstance) openWrite(path string, mode openMode) (*file, error) {
+	if !mi.root.writable {
+		return nil, errWriteDisabled
+	}
+	if mi.vu.State() == nil {
+		return nil, errors.New("open can't write a file in the init context")
+	}
+	absPath, err := mi.root.writePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	flag := syscall.O_WRONLY | syscall.O_CREAT | syscall.O_TRUNC
+	if mode == openModeAppend {
+		flag = syscall.O_WRONLY | syscall.O_CREAT | syscall.O_APPEND
+	}
+	f, err := mi.root.fs.OpenFile(absPath, flag, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open %q: %w", path, err)
+	}
+
+	return &file{vu: mi.vu, root: mi.root, path: absPath, mode: mode, file: f}, nil
+}
+
+// getTempDir returns the temporary directory of the VU, which is created on
+// its first use, in the write directory. The directories are kept after the
+// test, so that the files written in them can be used later.
+func (mi *ModuleInstance) getTempDir() (string, error) {
+	if mi.tempDir != "" {
+		return mi.tempDir, nil
+	}
+	if !mi.root.writable {
+		return "", errWriteDisabled
+	}
+	if mi.vu.State() == nil {
+		return "", errors.New("tempDir can't be called in the init context")
+	}
+
+	root, err := mi.root.getWriteDir()
+	if err != nil {
+		return "", err
+	}
+	dir, err := afero.TempDir(mi.root.fs, root, "vu-")
+	if err != nil {
+		return "", fmt.Errorf("couldn't create the temporary directory of the VU: %w", err)
+	}
+
+	mi.tempDir = dir
+	return dir, nil
+}
+
+// getWriteDir returns the write directory, the temporary one of the test is
+// created on the first use.
+func (rm *RootModule) getWriteDir() (string, error) {
+	rm.writeDirMu.Lock()
+	defer rm.writeDirMu.Unlock()
+
+	if rm.writeDir == "" {
+		dir, err := afero.TempDir(rm.fs, "", "k6-fs-")
+		if err != nil {
+			return "", fmt.Errorf("couldn't create the temporary directory of the test: %w", err)
+		}
+		rm.writeDir = dir
+	}
+	return rm.writeDir, nil
+}
+
+// writePath returns the absolute path of a written file, relative paths are
+// relative to the write directory, and the paths out of it are rejected.
+func (rm *RootModule) writePath(path string) (string, error) {
+	dir, err := rm.getWriteDir()
+	if err != nil {
+		return "", err
+	}
+	absPath := filepath.Clean(path)
+	if !filepath.IsAbs(absPath) {
+		absPath = filepath.Join(dir, absPath)
+	}
+	rel, err := filepath.Rel(dir, absPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%q isn't in the write directory %q", path, dir)
+	}
+	return absPath, nil
+}
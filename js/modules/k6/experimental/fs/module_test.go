@@ -0,0 +1,289 @@
+package fs
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/js/modules/k6/experimental/streams"
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+	"gopkg.in/guregu/null.v3"
+)
+
+func newTestRuntime(t *testing.T) *modulestest.Runtime {
+	t.Helper()
+	ts, _ := newTestRuntimeWithOptions(t, lib.RuntimeOptions{})
+	return ts
+}
+
+// newTestRuntimeWithOptions returns the runtime with the runtime options, and
+// the file system of the test, so that the written files can be checked.
+func newTestRuntimeWithOptions(t *testing.T, opts lib.RuntimeOptions) (*modulestest.Runtime, fsext.Fs) {
+	t.Helper()
+	ts := modulestest.NewRuntime(t)
+	ts.VU.InitEnvField.TestPreInitState.RuntimeOptions = opts
+
+	fs := fsext.NewMemMapFs()
+	require.NoError(t, fsext.WriteFile(fs, "/scripts/data.ndjson", []byte("{\"a\":1}\n{\"a\":2}\n{\"a\":3}\n"), 0o644))
+	require.NoError(t, fs.MkdirAll("/scripts/dir", 0o755))
+	ts.VU.InitEnvField.FileSystems = map[string]fsext.Fs{"file": fs}
+	ts.VU.InitEnvField.CWD = &url.URL{Scheme: "file", Path: "/scripts/"}
+
+	root := New()
+	root.fs = fs
+	m, ok := root.NewModuleInstance(ts.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, ts.VU.Runtime().Set("fs", m.Exports().Named))
+	streamsModule, ok := streams.New().NewModuleInstance(ts.VU).(*streams.ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, ts.VU.Runtime().Set("streams", streamsModule.Exports().Named))
+
+	_, err := ts.VU.Runtime().RunString(`
+		function assertEquals(actual, expected) {
+			if (JSON.stringify(actual) !== JSON.stringify(expected)) {
+				throw new Error("expected " + JSON.stringify(expected) + ", got " + JSON.stringify(actual));
+			}
+		}
+	`)
+	require.NoError(t, err)
+	return ts, fs
+}
+
+func TestFile(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestRuntime(t)
+	_, err := ts.RunOnEventLoop(`
+		var file;
+		(async () => {
+			file = await fs.open("data.ndjson");
+		})().catch(e => { throw e; });
+	`)
+	require.NoError(t, err)
+
+	ts.MoveToVUContext(&lib.State{})
+
+	_, err = ts.RunOnEventLoop(`
+		(async () => {
+			assertEquals(file.path, "/scripts/data.ndjson");
+			assertEquals(await file.stat(), { name: "data.ndjson", size: 24 });
+
+			const buffer = new Uint8Array(4);
+			assertEquals(await file.read(buffer), 4);
+			assertEquals(String.fromCharCode(...buffer), '{"a"');
+			assertEquals(await file.seek(-2, fs.SeekMode.End), 22);
+			assertEquals(await file.read(buffer), 2);
+			assertEquals(await file.read(buffer), null);
+
+			await file.seek(0);
+			const lines = [];
+			let buffered = "";
+			await file.readable({ chunkSize: 5 })
+				.pipeThrough(new streams.TextDecoderStream())
+				.pipeTo(new streams.WritableStream({
+					write(chunk) {
+						const parts = (buffered + chunk).split("\n");
+						buffered = parts.pop();
+						parts.forEach(line => lines.push(JSON.parse(line).a));
+					},
+				}));
+			assertEquals(lines, [1, 2, 3]);
+		})().catch(e => { throw e; });
+	`)
+	require.NoError(t, err)
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	ts, fs := newTestRuntimeWithOptions(t, lib.RuntimeOptions{
+		FSWrite:    null.BoolFrom(true),
+		FSWriteDir: null.StringFrom("/out"),
+	})
+	ts.MoveToVUContext(&lib.State{})
+
+	v, err := ts.RunOnEventLoop(`
+		var dir = fs.tempDir();
+		(async () => {
+			let file = await fs.open("out.txt", "w");
+			assertEquals(await file.write("hello"), 5);
+			assertEquals(await file.write(new Uint8Array([32, 119, 111, 114, 108, 100]).buffer), 6);
+			await file.close();
+
+			file = await fs.open("/out/out.txt", "a");
+			await file.write("!");
+			try {
+				await file.read(new Uint8Array(1));
+				throw new Error("the read should be rejected");
+			} catch (e) {
+				assertEquals(e.message, "the file isn't opened for reading");
+			}
+			await file.close();
+
+			assertEquals(fs.tempDir(), dir);
+			file = await fs.open(dir + "/vu.log", "w");
+			await file.write("iteration 1");
+			await file.close();
+		})().catch(e => { throw e; });
+		dir;
+	`)
+	require.NoError(t, err)
+
+	data, err := fsext.ReadFile(fs, "/out/out.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "hello world!", string(data))
+
+	assert.Equal(t, "/out", filepath.Dir(v.String()))
+	data, err = fsext.ReadFile(fs, v.String()+"/vu.log")
+	require.NoError(t, err)
+	assert.Equal(t, "iteration 1", string(data))
+}
+
+func TestWriteQuota(t *testing.T) {
+	t.Parallel()
+
+	ts, fs := newTestRuntimeWithOptions(t, lib.RuntimeOptions{
+		FSWrite:      null.BoolFrom(true),
+		FSWriteDir:   null.StringFrom("/out"),
+		FSWriteQuota: null.StringFrom("8B"),
+	})
+	ts.MoveToVUContext(&lib.State{})
+	_, err := ts.RunOnEventLoop(`
+		(async () => {
+			const file = await fs.open("out.txt", "w");
+			await file.write("12345");
+			try {
+				await file.write("6789");
+				throw new Error("the write should be rejected");
+			} catch (e) {
+				assertEquals(e.message, 'couldn\'t write to "/out/out.txt": the write quota of 8 bytes is exceeded');
+			}
+			await file.write("678");
+			await file.close();
+		})().catch(e => { throw e; });
+	`)
+	require.NoError(t, err)
+
+	data, err := fsext.ReadFile(fs, "/out/out.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "12345678", string(data))
+}
+
+func TestWriteDefaults(t *testing.T) {
+	t.Parallel()
+
+	ts, fs := newTestRuntimeWithOptions(t, lib.RuntimeOptions{FSWrite: null.BoolFrom(true)})
+	ts.MoveToVUContext(&lib.State{})
+	v, err := ts.RunOnEventLoop(`
+		(async () => {
+			const file = await fs.open("out.txt", "w");
+			await file.write("hello");
+			await file.close();
+		})().catch(e => { throw e; });
+		fs.tempDir();
+	`)
+	require.NoError(t, err)
+
+	// the write directory is a temporary one of the test, with the VU ones in it
+	writeDir := filepath.Dir(v.String())
+	assert.True(t, strings.HasPrefix(filepath.Base(writeDir), "k6-fs-"), writeDir)
+	data, err := fsext.ReadFile(fs, filepath.Join(writeDir, "out.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	rm := New()
+	require.NoError(t, rm.configure(lib.RuntimeOptions{}))
+	assert.False(t, rm.writable)
+	assert.Equal(t, int64(defaultWriteQuota), rm.quota)
+	require.EqualError(t, rm.configure(lib.RuntimeOptions{FSWriteQuota: null.StringFrom("0")}),
+		"invalid fs-write-quota: it must be greater than zero")
+}
+
+func TestParseSize(t *testing.T) {
+	t.Parallel()
+
+	for s, expected := range map[string]int64{"100": 100, "100B": 100, "2kb": 2048, "1 MB": 1 << 20, "3GB": 3 << 30} {
+		size, err := parseSize(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, expected, size, s)
+	}
+
+	for _, s := range []string{"", "MB", "-1", "1TB"} {
+		_, err := parseSize(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, path, mode, err string
+	}{
+		{name: "missing", path: "missing.csv", err: `couldn't open "missing.csv"`},
+		{name: "directory", path: "dir", err: `open can't be used with directories, path: "dir"`},
+		{name: "empty", path: "", err: "the path of the file is required"},
+		{name: "mode", path: "data.ndjson", mode: "x", err: `invalid mode "x", it must be r, w or a`},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestRuntime(t)
+			require.NoError(t, ts.VU.Runtime().Set("path", tc.path))
+			require.NoError(t, ts.VU.Runtime().Set("mode", tc.mode))
+			_, err := ts.RunOnEventLoop(`fs.open(path, mode || undefined)`)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+
+	t.Run("VU context", func(t *testing.T) {
+		t.Parallel()
+
+		ts := newTestRuntime(t)
+		ts.MoveToVUContext(&lib.State{})
+		_, err := ts.RunOnEventLoop(`fs.open("data.ndjson")`)
+		require.ErrorContains(t, err, "open must be called in the init context")
+	})
+
+	writeTests := []struct {
+		name, script, err string
+		initContext       bool
+		disabled          bool
+	}{
+		{name: "write disabled", script: `fs.open("out.txt", "w")`, disabled: true, err: "writing files is disabled"},
+		{name: "temp dir disabled", script: `fs.tempDir()`, disabled: true, err: "writing files is disabled"},
+		{name: "write in init context", script: `fs.open("out.txt", "w")`, initContext: true, err: "open can't write a file in the init context"},
+		{name: "temp dir in init context", script: `fs.tempDir()`, initContext: true, err: "tempDir can't be called in the init context"},
+		{name: "parent", script: `fs.open("../scripts/data.ndjson", "a")`, err: `"../scripts/data.ndjson" isn't in the write directory "/out"`},
+		{name: "absolute", script: `fs.open("/out/../etc/passwd", "w")`, err: `"/out/../etc/passwd" isn't in the write directory "/out"`},
+		{name: "write directory", script: `fs.open("/out", "w")`, err: `"/out" isn't in the write directory "/out"`},
+	}
+	for _, tc := range writeTests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts, fs := newTestRuntimeWithOptions(t, lib.RuntimeOptions{
+				FSWrite:    null.BoolFrom(!tc.disabled),
+				FSWriteDir: null.StringFrom("/out"),
+			})
+			if !tc.initContext {
+				ts.MoveToVUContext(&lib.State{})
+			}
+			_, err := ts.RunOnEventLoop(tc.script)
+			require.ErrorContains(t, err, tc.err)
+
+			data, err := fsext.ReadFile(fs, "/scripts/data.ndjson")
+			require.NoError(t, err)
+			assert.Len(t, data, 24)
+		})
+	}
+}
@@ -0,0 +1,269 @@
+// Package graphql implements a k6 JS module for making GraphQL requests with
+// the k6/http module.
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	httpmodule "go.k6.io/k6/js/modules/k6/http"
+)
+
+const (
+	// operationNameTag is the tag that the requests are tagged with, so
+	// the thresholds can target the individual operations.
+	operationNameTag = "operation_name"
+
+	persistedQueryNotFound     = "PersistedQueryNotFound"
+	persistedQueryNotFoundCode = "PERSISTED_QUERY_NOT_FOUND"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the JS module.
+	ModuleInstance struct {
+		vu modules.VU
+
+		// requestFunc holds the http module's request function, which is
+		// used to make the requests. It's looked up on the first request.
+		requestFunc HTTPRequestFunc
+	}
+
+	// HTTPRequestFunc is the prototype of the http module's request function.
+	HTTPRequestFunc func(method string, url goja.Value, args ...goja.Value) (*httpmodule.Response, error)
+)
+
+// Ensure the interfaces are implemented correctly
+var (
+	_ modules.Instance = &ModuleInstance{}
+	_ modules.Module   = &RootModule{}
+)
+
+// New returns a pointer to a new RootModule instance
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns
+// a new instance for each VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu}
+}
+
+// Exports implements the modules.Instance interface and returns
+// the exports of the JS module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"query":  mi.query,
+			"mutate": mi.mutate,
+		},
+	}
+}
+
+// query makes a request for a query operation of the document.
+//
+// The params are the ones of the http module, besides the operationName,
+// which selects the operation of documents with multiple operations, and
+// persisted, which enables the automatic persisted queries.
+func (mi *ModuleInstance) query(
+	url goja.Value, document string, variables, params goja.Value,
+) (*httpmodule.Response, error) {
+	return mi.request("query", url, document, variables, params)
+}
+
+// mutate makes a request for a mutation operation of the document, it has
+// the same arguments as query.
+func (mi *ModuleInstance) mutate(
+	url goja.Value, document string, variables, params goja.Value,
+) (*httpmodule.Response, error) {
+	return mi.request("mutation", url, document, variables, params)
+}
+
+// request is the body of a GraphQL request.
+type request struct {
+	Query         string                 `json:"query,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     interface{}            `json:"variables,omitempty"`
+	Extensions    map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (mi *ModuleInstance) request(
+	typ string, url goja.Value, document string, variables, params goja.Value,
+) (*httpmodule.Response, error) {
+	if mi.vu.State() == nil {
+		return nil, common.NewInitContextError("GraphQL requests can't be made in the init context")
+	}
+	rt := mi.vu.Runtime()
+
+	var paramsObj *goja.Object
+	if !common.IsNullish(params) {
+		paramsObj = params.ToObject(rt)
+	}
+	var operationName string
+	var persisted bool
+	if paramsObj != nil {
+		if v := paramsObj.Get("operationName"); !common.IsNullish(v) {
+			operationName = v.String()
+		}
+		if v := paramsObj.Get("persisted"); v != nil {
+			persisted = v.ToBoolean()
+		}
+	}
+
+	op, err := selectOperation(document, operationName)
+	if err != nil {
+		return nil, err
+	}
+	if op.typ != typ {
+		return nil, fmt.Errorf("expected a %s operation, got a %s", typ, op.typ)
+	}
+
+	body := request{Query: document, OperationName: op.name}
+	if !common.IsNullish(variables) {
+		body.Variables = variables.Export()
+	}
+	httpParams, err := mi.httpParams(paramsObj, op)
+	if err != nil {
+		return nil, err
+	}
+
+	if !persisted {
+		return mi.post(url, body, httpParams)
+	}
+
+	// The automatic persisted queries send the hash of the document, and
+	// the document itself only if the server doesn't know the hash yet.
+	hash := sha256.Sum256([]byte(document))
+	body.Extensions = map[string]interface{}{
+		"persistedQuery": map[string]interface{}{"version": 1, "sha256Hash": hex.EncodeToString(hash[:])},
+	}
+	body.Query = ""
+	resp, err := mi.post(url, body, httpParams)
+	if err != nil || !isPersistedQueryNotFound(resp) {
+		return resp, err
+	}
+	body.Query = document
+	return mi.post(url, body, httpParams)
+}
+
+func (mi *ModuleInstance) post(url goja.Value, body request, params *goja.Object) (*httpmodule.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode the GraphQL request: %w", err)
+	}
+	if mi.requestFunc == nil {
+		rt := mi.vu.Runtime()
+		httpModule, err := rt.RunString("require('k6/http')")
+		if err != nil {
+			return nil, fmt.Errorf("unable to require the k6/http module: %w", err)
+		}
+		if err := rt.ExportTo(httpModule.ToObject(rt).Get("request"), &mi.requestFunc); err != nil {
+			return nil, fmt.Errorf("unable to get the http.request function: %w", err)
+		}
+	}
+	return mi.requestFunc("POST", url, mi.vu.Runtime().ToValue(string(data)), params)
+}
+
+// httpParams returns the params of the http module for the request, which
+// are a copy of the provided params with the JSON content type and the tag
+// of the operation name.
+func (mi *ModuleInstance) httpParams(params *goja.Object, op operation) (*goja.Object, error) {
+	rt := mi.vu.Runtime()
+	result := rt.NewObject()
+	headers := rt.NewObject()
+	tags := rt.NewObject()
+
+	if params != nil {
+		for _, k := range params.Keys() {
+			v := params.Get(k)
+			switch k {
+			case "operationName", "persisted":
+				continue
+			case "headers", "tags":
+				if common.IsNullish(v) {
+					continue
+				}
+				dst := headers
+				if k == "tags" {
+					dst = tags
+				}
+				src := v.ToObject(rt)
+				for _, key := range src.Keys() {
+					if err := dst.Set(key, src.Get(key)); err != nil {
+						return nil, err
+					}
+				}
+			default:
+				if err := result.Set(k, v); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	hasContentType := false
+	for _, k := range headers.Keys() {
+		if strings.EqualFold(k, "Content-Type") {
+			hasContentType = true
+		}
+	}
+	if !hasContentType {
+		if err := headers.Set("Content-Type", "application/json"); err != nil {
+			return nil, err
+		}
+	}
+	if op.name != "" && common.IsNullish(tags.Get(operationNameTag)) {
+		if err := tags.Set(operationNameTag, op.name); err != nil {
+			return nil, err
+		}
+	}
+	if err := result.Set("headers", headers); err != nil {
+		return nil, err
+	}
+	if err := result.Set("tags", tags); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// isPersistedQueryNotFound returns true if the response has the error that the
+// servers return when they don't know the hash of a persisted query.
+func isPersistedQueryNotFound(resp *httpmodule.Response) bool {
+	var data []byte
+	switch body := resp.Body.(type) {
+	case string:
+		data = []byte(body)
+	case []byte:
+		data = body
+	default:
+		return false
+	}
+
+	var result struct {
+		Errors []struct {
+			Message    string `json:"message"`
+			Extensions struct {
+				Code string `json:"code"`
+			} `json:"extensions"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return false
+	}
+	for _, e := range result.Errors {
+		if e.Message == persistedQueryNotFound || e.Extensions.Code == persistedQueryNotFoundCode {
+			return true
+		}
+	}
+	return false
+}
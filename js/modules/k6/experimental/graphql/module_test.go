@@ -0,0 +1,152 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/js/compiler"
+	httpmodule "go.k6.io/k6/js/modules/k6/http"
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/httpmultibin"
+	"go.k6.io/k6/metrics"
+)
+
+type testServer struct {
+	mx        sync.Mutex
+	requests  []request
+	persisted map[string]string
+}
+
+// handle echoes the GraphQL requests and implements the automatic persisted queries.
+func (s *testServer) handle(w http.ResponseWriter, r *http.Request) {
+	var req request
+	if r.Header.Get("Content-Type") != "application/json" || json.NewDecoder(r.Body).Decode(&req) != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.requests = append(s.requests, req)
+	if pq, ok := req.Extensions["persistedQuery"].(map[string]interface{}); ok {
+		hash, _ := pq["sha256Hash"].(string)
+		if req.Query == "" {
+			if req.Query = s.persisted[hash]; req.Query == "" {
+				_, _ = w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotFound"}]}`))
+				return
+			}
+		}
+		s.persisted[hash] = req.Query
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": req})
+}
+
+func newTestRuntime(t *testing.T) (*modulestest.Runtime, *testServer, chan metrics.SampleContainer) {
+	t.Helper()
+	ts := modulestest.NewRuntime(t)
+	err := ts.SetupModuleSystem(map[string]interface{}{
+		"k6/http":                 httpmodule.New(),
+		"k6/experimental/graphql": New(),
+	}, nil, compiler.New(ts.VU.InitEnvField.Logger))
+	require.NoError(t, err)
+
+	server := &testServer{persisted: make(map[string]string)}
+	tb := httpmultibin.NewHTTPMultiBin(t)
+	tb.Mux.HandleFunc("/graphql", server.handle)
+
+	_, err = ts.VU.Runtime().RunString(tb.Replacer.Replace(`
+		var graphql = require('k6/experimental/graphql');
+		var url = "HTTPBIN_URL/graphql";
+	`))
+	require.NoError(t, err)
+
+	samples := make(chan metrics.SampleContainer, 1000)
+	ts.MoveToVUContext(&lib.State{
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(ts.VU.InitEnvField.Registry),
+		Tags:           lib.NewVUStateTags(ts.VU.InitEnvField.Registry.RootTagSet()),
+		Transport:      tb.HTTPTransport,
+		BufferPool:     lib.NewBufferPool(),
+		Samples:        samples,
+		Options:        lib.Options{SystemTags: &metrics.DefaultSystemTagSet},
+	})
+	return ts, server, samples
+}
+
+func TestQueryAndMutate(t *testing.T) {
+	t.Parallel()
+	ts, server, samples := newTestRuntime(t)
+
+	_, err := ts.VU.Runtime().RunString(`
+		var res = graphql.query(url, 'query GetUser($id: ID) { user(id: $id) { name } }', { id: 1 }, {
+			headers: { "X-Test": "1" },
+			tags: { team: "a" },
+		});
+		if (res.status !== 200 || res.json().data.operationName !== "GetUser") {
+			throw new Error("wrong response: " + res.body);
+		}
+		graphql.mutate(url, 'query A { a } mutation B { b }', null, { operationName: "B" });
+	`)
+	require.NoError(t, err)
+
+	require.Len(t, server.requests, 2)
+	assert.Equal(t, request{
+		Query:         "query GetUser($id: ID) { user(id: $id) { name } }",
+		OperationName: "GetUser",
+		Variables:     map[string]interface{}{"id": float64(1)},
+	}, server.requests[0])
+	assert.Equal(t, "B", server.requests[1].OperationName)
+	assert.Nil(t, server.requests[1].Variables)
+
+	close(samples)
+	var names []string
+	for container := range samples {
+		for _, sample := range container.GetSamples() {
+			if sample.Metric.Name != metrics.HTTPReqsName {
+				continue
+			}
+			name, _ := sample.Tags.Get(operationNameTag)
+			names = append(names, name)
+			if name == "GetUser" {
+				team, _ := sample.Tags.Get("team")
+				assert.Equal(t, "a", team)
+			}
+		}
+	}
+	assert.Equal(t, []string{"GetUser", "B"}, names)
+}
+
+func TestPersistedQueries(t *testing.T) {
+	t.Parallel()
+	ts, server, _ := newTestRuntime(t)
+
+	_, err := ts.VU.Runtime().RunString(`
+		for (var i = 0; i < 2; i++) {
+			var res = graphql.query(url, 'query Me { me }', null, { persisted: true });
+			if (res.json().data.query !== 'query Me { me }') { throw new Error("wrong response: " + res.body); }
+		}
+	`)
+	require.NoError(t, err)
+
+	// the first query isn't known by the server, so it's sent again with
+	// the document, while the second one is sent only with the hash
+	require.Len(t, server.requests, 3)
+	assert.Empty(t, server.requests[0].Query)
+	assert.Equal(t, "query Me { me }", server.requests[1].Query)
+	assert.Empty(t, server.requests[2].Query)
+	assert.NotNil(t, server.requests[2].Extensions["persistedQuery"])
+}
+
+func TestRequestErrors(t *testing.T) {
+	t.Parallel()
+	ts, _, _ := newTestRuntime(t)
+
+	_, err := ts.VU.Runtime().RunString(`graphql.query(url, 'mutation { logout }')`)
+	require.ErrorContains(t, err, "expected a query operation, got a mutation")
+	_, err = ts.VU.Runtime().RunString(`graphql.mutate(url, 'query A { a } mutation B { b }')`)
+	require.ErrorContains(t, err, "the operationName param is required")
+}
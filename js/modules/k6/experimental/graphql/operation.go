@@ -0,0 +1,138 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// operation is an operation definition of a GraphQL document.
+type operation struct {
+	typ  string // query, mutation or subscription
+	name string // empty for anonymous operations
+}
+
+// parseOperations returns the operations that are defined in the document.
+// It doesn't validate the document, it only scans its top level definitions,
+// skipping the comments, the strings and the selection sets.
+func parseOperations(document string) []operation {
+	var (
+		ops     []operation
+		depth   int
+		pending string // the keyword of the definition that is being scanned
+		named   bool
+	)
+	for i := 0; i < len(document); {
+		c := document[i]
+		switch {
+		case c == '#':
+			for i < len(document) && document[i] != '\n' {
+				i++
+			}
+		case c == '"':
+			i = skipString(document, i)
+		case c == '{':
+			if depth == 0 {
+				if pending == "" {
+					// the query shorthand, e.g. `{ user { id } }`
+					ops = append(ops, operation{typ: "query"})
+				}
+				pending, named = "", false
+			}
+			depth++
+			i++
+		case c == '}':
+			depth--
+			i++
+		case isNameStart(c):
+			start := i
+			for i < len(document) && isNameContinue(document[i]) {
+				i++
+			}
+			if depth > 0 {
+				continue
+			}
+			word := document[start:i]
+			switch {
+			case pending == "" && (word == "query" || word == "mutation" || word == "subscription"):
+				pending = word
+				ops = append(ops, operation{typ: word})
+			case pending == "" && word == "fragment":
+				pending = word
+			case pending != "" && pending != "fragment" && !named:
+				ops[len(ops)-1].name = word
+				named = true
+			}
+		case c == '(' && depth == 0:
+			// skip the variable definitions, so their names and
+			// default values aren't mistaken for the operation name
+			for parens := 0; i < len(document); i++ {
+				if document[i] == '"' {
+					i = skipString(document, i) - 1
+				} else if document[i] == '(' {
+					parens++
+				} else if document[i] == ')' {
+					if parens--; parens == 0 {
+						break
+					}
+				}
+			}
+			named = true
+			i++
+		default:
+			i++
+		}
+	}
+	return ops
+}
+
+// skipString returns the index right after the string or block string that
+// starts at the index i.
+func skipString(document string, i int) int {
+	if strings.HasPrefix(document[i:], `"""`) {
+		if end := strings.Index(document[i+3:], `"""`); end >= 0 {
+			return i + 3 + end + 3
+		}
+		return len(document)
+	}
+	for i++; i < len(document); i++ {
+		switch document[i] {
+		case '\\':
+			i++
+		case '"', '\n':
+			return i + 1
+		}
+	}
+	return len(document)
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameContinue(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
+
+// selectOperation returns the operation of the document that would be
+// executed for the provided operation name, which is only required if the
+// document has multiple operations.
+func selectOperation(document, operationName string) (operation, error) {
+	ops := parseOperations(document)
+	if operationName != "" {
+		for _, op := range ops {
+			if op.name == operationName {
+				return op, nil
+			}
+		}
+		return operation{}, fmt.Errorf("the operation '%s' isn't defined in the document", operationName)
+	}
+	switch len(ops) {
+	case 0:
+		return operation{}, errors.New("the document doesn't define any operation")
+	case 1:
+		return ops[0], nil
+	default:
+		return operation{}, errors.New("the document defines multiple operations, the operationName param is required")
+	}
+}
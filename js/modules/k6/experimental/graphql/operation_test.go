@@ -0,0 +1,72 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOperations(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		document string
+		want     []operation
+	}{
+		{
+			name:     "shorthand",
+			document: `{ user(id: 1) { name } }`,
+			want:     []operation{{typ: "query"}},
+		},
+		{
+			name:     "anonymous",
+			document: `mutation { logout }`,
+			want:     []operation{{typ: "mutation"}},
+		},
+		{
+			name:     "named with variables",
+			document: `query GetUser($id: ID = "query Other") @cached { user(id: $id) { name } }`,
+			want:     []operation{{typ: "query", name: "GetUser"}},
+		},
+		{
+			name:     "anonymous with variables",
+			document: `query ($id: ID) { user(id: $id) { name } }`,
+			want:     []operation{{typ: "query"}},
+		},
+		{
+			name: "multiple with fragments and comments",
+			document: `
+				# query Commented { a }
+				fragment UserFields on User { name, friends { ...UserFields } }
+				query GetUser { user { ...UserFields description(format: """{ mutation X }""") } }
+				mutation UpdateUser($name: String) { update(name: $name) { ...UserFields } }
+			`,
+			want: []operation{{typ: "query", name: "GetUser"}, {typ: "mutation", name: "UpdateUser"}},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, parseOperations(tc.document))
+		})
+	}
+}
+
+func TestSelectOperation(t *testing.T) {
+	t.Parallel()
+
+	document := `query A { a } mutation B { b }`
+	op, err := selectOperation(document, "B")
+	require.NoError(t, err)
+	assert.Equal(t, operation{typ: "mutation", name: "B"}, op)
+
+	_, err = selectOperation(document, "")
+	require.EqualError(t, err, "the document defines multiple operations, the operationName param is required")
+	_, err = selectOperation(document, "C")
+	require.EqualError(t, err, "the operation 'C' isn't defined in the document")
+	_, err = selectOperation(`fragment F on User { name }`, "")
+	require.EqualError(t, err, "the document doesn't define any operation")
+}
@@ -0,0 +1,199 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	// The hash functions need to be registered to be usable with crypto.Hash.
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// algorithm describes a JWS algorithm, as defined by RFC 7518.
+type algorithm struct {
+	name string
+	hash crypto.Hash
+
+	// family is either "HS", "RS" or "ES".
+	family string
+
+	// curve is the elliptic curve of the ES algorithms' keys.
+	curve elliptic.Curve
+}
+
+//nolint:gochecknoglobals
+var algorithms = map[string]algorithm{
+	"HS256": {name: "HS256", hash: crypto.SHA256, family: "HS"},
+	"HS384": {name: "HS384", hash: crypto.SHA384, family: "HS"},
+	"HS512": {name: "HS512", hash: crypto.SHA512, family: "HS"},
+	"RS256": {name: "RS256", hash: crypto.SHA256, family: "RS"},
+	"RS384": {name: "RS384", hash: crypto.SHA384, family: "RS"},
+	"RS512": {name: "RS512", hash: crypto.SHA512, family: "RS"},
+	"ES256": {name: "ES256", hash: crypto.SHA256, family: "ES", curve: elliptic.P256()},
+	"ES384": {name: "ES384", hash: crypto.SHA384, family: "ES", curve: elliptic.P384()},
+	"ES512": {name: "ES512", hash: crypto.SHA512, family: "ES", curve: elliptic.P521()},
+}
+
+var (
+	errUnsupportedAlgorithm = errors.New("unsupported algorithm")
+	errInvalidKey           = errors.New("invalid key")
+	errInvalidSignature     = errors.New("invalid signature")
+)
+
+func getAlgorithm(name string) (algorithm, error) {
+	alg, ok := algorithms[name]
+	if !ok {
+		return algorithm{}, fmt.Errorf("%w %q", errUnsupportedAlgorithm, name)
+	}
+	return alg, nil
+}
+
+func (a algorithm) digest(data string) []byte {
+	h := a.hash.New()
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// sign returns the signature of the data, with a key as returned by parseKey.
+func (a algorithm) sign(key interface{}, data string) ([]byte, error) {
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(a.hash.New, k)
+		mac.Write([]byte(data))
+		return mac.Sum(nil), nil
+	case *rsa.PrivateKey:
+		return rsa.SignPKCS1v15(rand.Reader, k, a.hash, a.digest(data))
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, a.digest(data))
+		if err != nil {
+			return nil, err
+		}
+		size := curveByteSize(k.Curve)
+		signature := make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		s.FillBytes(signature[size:])
+		return signature, nil
+	default:
+		return nil, fmt.Errorf("%w: a private key is required to sign with %s", errInvalidKey, a.name)
+	}
+}
+
+// verify checks the signature of the data, with a key as returned by parseKey.
+func (a algorithm) verify(key interface{}, data string, signature []byte) error {
+	var valid bool
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(a.hash.New, k)
+		mac.Write([]byte(data))
+		valid = hmac.Equal(signature, mac.Sum(nil))
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(k, a.hash, a.digest(data), signature) == nil
+	case *ecdsa.PublicKey:
+		size := curveByteSize(k.Curve)
+		if len(signature) == 2*size {
+			r := new(big.Int).SetBytes(signature[:size])
+			s := new(big.Int).SetBytes(signature[size:])
+			valid = ecdsa.Verify(k, a.digest(data), r, s)
+		}
+	default:
+		return fmt.Errorf("%w: unexpected key type %T", errInvalidKey, key)
+	}
+
+	if !valid {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+// parseKey returns the key to use with the algorithm: the secret for the HS
+// algorithms, and the private or the public key, parsed from its PEM encoding,
+// for the other ones. When public is true, the public key of a private key is
+// returned.
+func (a algorithm) parseKey(secret []byte, public bool) (interface{}, error) {
+	isPEM := strings.HasPrefix(strings.TrimSpace(string(secret)), "-----BEGIN")
+	if a.family == "HS" {
+		// Using a PEM encoded key as the secret of an HMAC is most likely the
+		// result of an algorithm confusion, and is refused.
+		if isPEM {
+			return nil, fmt.Errorf("%w: %s requires a secret, not a PEM encoded key", errInvalidKey, a.name)
+		}
+		if len(secret) == 0 {
+			return nil, fmt.Errorf("%w: the secret can't be empty", errInvalidKey)
+		}
+		// The secret is copied, as it may be backed by an ArrayBuffer of the script.
+		return append([]byte(nil), secret...), nil
+	}
+
+	block, _ := pem.Decode(secret)
+	if block == nil {
+		return nil, fmt.Errorf("%w: %s requires a PEM encoded key", errInvalidKey, a.name)
+	}
+
+	key, err := parsePEMBlock(block)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidKey, err.Error())
+	}
+
+	if public {
+		if signer, ok := key.(crypto.Signer); ok {
+			key = signer.Public()
+		}
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey, *rsa.PublicKey:
+		if a.family != "RS" {
+			return nil, fmt.Errorf("%w: %s can't be used with an RSA key", errInvalidKey, a.name)
+		}
+	case *ecdsa.PrivateKey:
+		if a.family != "ES" || k.Curve != a.curve {
+			return nil, fmt.Errorf("%w: %s can't be used with a %s key", errInvalidKey, a.name, k.Curve.Params().Name)
+		}
+	case *ecdsa.PublicKey:
+		if a.family != "ES" || k.Curve != a.curve {
+			return nil, fmt.Errorf("%w: %s can't be used with a %s key", errInvalidKey, a.name, k.Curve.Params().Name)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported key type %T", errInvalidKey, key)
+	}
+
+	return key, nil
+}
+
+// parsePEMBlock parses the keys of the usual PEM block types.
+func parsePEMBlock(block *pem.Block) (interface{}, error) {
+	switch block.Type {
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+}
+
+func curveByteSize(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8
+}
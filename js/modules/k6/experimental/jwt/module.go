@@ -0,0 +1,245 @@
+// Package jwt implements a k6 JS module for signing and verifying JSON Web
+// Tokens, so that scripts don't have to do it with bundled JS libraries.
+package jwt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+// maxCachedKeys is the number of parsed keys kept by each VU, so that the
+// PEM encoded keys aren't parsed again for each token.
+const maxCachedKeys = 32
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the JS module.
+	ModuleInstance struct {
+		vu   modules.VU
+		keys map[keyCacheEntry]interface{}
+	}
+
+	keyCacheEntry struct {
+		alg    string
+		public bool
+		key    string
+	}
+)
+
+// Ensure the interfaces are implemented correctly
+var (
+	_ modules.Instance = &ModuleInstance{}
+	_ modules.Module   = &RootModule{}
+)
+
+var (
+	errMalformedToken = errors.New("malformed token")
+	errExpiredToken   = errors.New("the token is expired")
+	errInactiveToken  = errors.New("the token is not valid yet")
+)
+
+// New returns a pointer to a new RootModule instance
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns
+// a new instance for each VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu, keys: make(map[keyCacheEntry]interface{})}
+}
+
+// Exports implements the modules.Instance interface and returns
+// the exports of the JS module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"sign":   mi.sign,
+			"verify": mi.verify,
+		},
+	}
+}
+
+// sign returns a token with the payload, signed with the key.
+//
+// The payload is either an object, which is encoded to JSON, or a JSON string.
+// The key is either the secret of the HS algorithms, as a string or an
+// ArrayBuffer, or the PEM encoded private key of the RS and ES algorithms.
+// The options are the algorithm, alg, which defaults to HS256, and header,
+// an object with additional header parameters, such as kid.
+func (mi *ModuleInstance) sign(payload goja.Value, key goja.Value, options goja.Value) string {
+	rt := mi.vu.Runtime()
+
+	algName := "HS256"
+	header := make(map[string]interface{})
+	if !common.IsNullish(options) {
+		opts := options.ToObject(rt)
+		if v := opts.Get("alg"); !common.IsNullish(v) {
+			algName = v.String()
+		}
+		if v := opts.Get("header"); !common.IsNullish(v) {
+			if err := rt.ExportTo(v, &header); err != nil {
+				common.Throw(rt, fmt.Errorf("invalid header: %w", err))
+			}
+		}
+	}
+
+	alg, err := getAlgorithm(algName)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	parsedKey, err := mi.parseKey(alg, key, false)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	header["alg"] = alg.name
+	if _, ok := header["typ"]; !ok {
+		header["typ"] = "JWT"
+	}
+	encodedHeader, err := json.Marshal(header)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid header: %w", err))
+	}
+
+	var encodedPayload []byte
+	if s, ok := payload.Export().(string); ok {
+		encodedPayload = []byte(s)
+	} else if encodedPayload, err = json.Marshal(payload.Export()); err != nil {
+		common.Throw(rt, fmt.Errorf("invalid payload: %w", err))
+	}
+
+	signingInput := encodeSegment(encodedHeader) + "." + encodeSegment(encodedPayload)
+	signature, err := alg.sign(parsedKey, signingInput)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	return signingInput + "." + encodeSegment(signature)
+}
+
+// verify checks the signature of the token with the key, and returns its
+// payload. It throws if the signature isn't valid, or if the token is expired
+// or not valid yet, according to its exp and nbf claims.
+//
+// The key is either the secret of the HS algorithms, or the PEM encoded public
+// key, certificate, or private key of the RS and ES algorithms. The algorithm is
+// the one of the token header, unless it is pinned with the alg option.
+func (mi *ModuleInstance) verify(token string, key goja.Value, options goja.Value) interface{} {
+	rt := mi.vu.Runtime()
+
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		common.Throw(rt, fmt.Errorf("%w: a token has 3 segments, got %d", errMalformedToken, len(segments)))
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(segments[0], &header); err != nil {
+		common.Throw(rt, fmt.Errorf("%w: invalid header: %s", errMalformedToken, err.Error()))
+	}
+
+	if !common.IsNullish(options) {
+		if v := options.ToObject(rt).Get("alg"); !common.IsNullish(v) && v.String() != header.Alg {
+			common.Throw(rt, fmt.Errorf("%w: expected %q, got %q", errUnsupportedAlgorithm, v.String(), header.Alg))
+		}
+	}
+
+	alg, err := getAlgorithm(header.Alg)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	parsedKey, err := mi.parseKey(alg, key, true)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(segments[2])
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("%w: invalid signature encoding: %s", errMalformedToken, err.Error()))
+	}
+
+	if err = alg.verify(parsedKey, segments[0]+"."+segments[1], signature); err != nil {
+		common.Throw(rt, err)
+	}
+
+	var payload interface{}
+	if err = decodeSegment(segments[1], &payload); err != nil {
+		common.Throw(rt, fmt.Errorf("%w: invalid payload: %s", errMalformedToken, err.Error()))
+	}
+
+	if claims, ok := payload.(map[string]interface{}); ok {
+		if err = checkTimeClaims(claims, time.Now()); err != nil {
+			common.Throw(rt, err)
+		}
+	}
+
+	return payload
+}
+
+// parseKey returns the parsed key, from the cache of the VU if it was already parsed.
+func (mi *ModuleInstance) parseKey(alg algorithm, key goja.Value, public bool) (interface{}, error) {
+	if common.IsNullish(key) {
+		return nil, fmt.Errorf("%w: the key is required", errInvalidKey)
+	}
+
+	secret, err := common.ToBytes(key.Export())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidKey, err.Error())
+	}
+
+	entry := keyCacheEntry{alg: alg.name, public: public, key: string(secret)}
+	if parsed, ok := mi.keys[entry]; ok {
+		return parsed, nil
+	}
+
+	parsed, err := alg.parseKey(secret, public)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(mi.keys) >= maxCachedKeys {
+		mi.keys = make(map[keyCacheEntry]interface{})
+	}
+	mi.keys[entry] = parsed
+
+	return parsed, nil
+}
+
+// checkTimeClaims checks the exp and nbf claims, which are NumericDates.
+func checkTimeClaims(claims map[string]interface{}, now time.Time) error {
+	unix := float64(now.Unix())
+	if exp, ok := claims["exp"].(float64); ok && unix >= exp {
+		return errExpiredToken
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && unix < nbf {
+		return errInactiveToken
+	}
+	return nil
+}
+
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,216 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/js/modulestest"
+)
+
+func newTestRuntime(t *testing.T) *modulestest.Runtime {
+	t.Helper()
+	ts := modulestest.NewRuntime(t)
+	m, ok := New().NewModuleInstance(ts.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, ts.VU.Runtime().Set("jwt", m.Exports().Named))
+	return ts
+}
+
+func encodePEM(t *testing.T, typ string, der []byte, err error) string {
+	t.Helper()
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}))
+}
+
+func TestSignHS256(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestRuntime(t)
+	_, err := ts.VU.Runtime().RunString(`
+		var token = jwt.sign('{"sub":"1234567890","name":"John Doe","iat":1516239022}', "your-256-bit-secret");
+		var expected = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9." +
+			"eyJzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4gRG9lIiwiaWF0IjoxNTE2MjM5MDIyfQ." +
+			"SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c";
+		if (token !== expected) {
+			throw new Error("unexpected token " + token);
+		}
+
+		var payload = jwt.verify(token, "your-256-bit-secret");
+		if (payload.name !== "John Doe" || payload.iat !== 1516239022) {
+			throw new Error("unexpected payload " + JSON.stringify(payload));
+		}
+	`)
+	require.NoError(t, err)
+}
+
+func TestSignVerify(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherRSAKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherECKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	rsaPrivate := encodePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey), nil)
+	otherRSAPrivate := encodePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(otherRSAKey), nil)
+	ecPKCS8, pkcs8Err := x509.MarshalPKCS8PrivateKey(ecKey)
+	ecPrivate := encodePEM(t, "PRIVATE KEY", ecPKCS8, pkcs8Err)
+	otherECPKCS8, otherPKCS8Err := x509.MarshalPKCS8PrivateKey(otherECKey)
+	otherECPrivate := encodePEM(t, "PRIVATE KEY", otherECPKCS8, otherPKCS8Err)
+	rsaPKIX, rsaPKIXErr := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	rsaPublic := encodePEM(t, "PUBLIC KEY", rsaPKIX, rsaPKIXErr)
+	ecPKIX, ecPKIXErr := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	ecPublic := encodePEM(t, "PUBLIC KEY", ecPKIX, ecPKIXErr)
+
+	tests := []struct {
+		name                     string
+		alg                      string
+		signKey, verifyKey, fail string
+	}{
+		{name: "HS256", alg: "HS256", signKey: "secret", verifyKey: "secret", fail: "other secret"},
+		{name: "HS512", alg: "HS512", signKey: "secret", verifyKey: "secret", fail: "other secret"},
+		{name: "RS256", alg: "RS256", signKey: rsaPrivate, verifyKey: rsaPublic, fail: otherRSAPrivate},
+		{name: "RS256 private key", alg: "RS256", signKey: rsaPrivate, verifyKey: rsaPrivate},
+		{name: "ES256", alg: "ES256", signKey: ecPrivate, verifyKey: ecPublic, fail: otherECPrivate},
+		{name: "ES256 private key", alg: "ES256", signKey: ecPrivate, verifyKey: ecPrivate, fail: otherECPrivate},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestRuntime(t)
+			rt := ts.VU.Runtime()
+			require.NoError(t, rt.Set("alg", tc.alg))
+			require.NoError(t, rt.Set("signKey", tc.signKey))
+			require.NoError(t, rt.Set("verifyKey", tc.verifyKey))
+
+			token, err := rt.RunString(`
+				var token = jwt.sign({sub: "user", exp: Math.floor(Date.now() / 1000) + 60}, signKey,
+					{alg: alg, header: {kid: "key-1"}});
+				for (var i = 0; i < 3; i++) {
+					var payload = jwt.verify(token, verifyKey, {alg: alg});
+					if (payload.sub !== "user") {
+						throw new Error("unexpected payload " + JSON.stringify(payload));
+					}
+				}
+				token;
+			`)
+			require.NoError(t, err)
+
+			header, err := base64.RawURLEncoding.DecodeString(strings.Split(token.String(), ".")[0])
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"alg":"`+tc.alg+`","kid":"key-1","typ":"JWT"}`, string(header))
+
+			if tc.fail == "" {
+				return
+			}
+			require.NoError(t, rt.Set("failKey", tc.fail))
+			_, err = rt.RunString(`jwt.verify(token, failKey)`)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestVerifyFailures(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaPKIX, rsaPKIXErr := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	rsaPublic := encodePEM(t, "PUBLIC KEY", rsaPKIX, rsaPKIXErr)
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	p384PKCS8, p384Err := x509.MarshalPKCS8PrivateKey(p384Key)
+	p384Private := encodePEM(t, "PRIVATE KEY", p384PKCS8, p384Err)
+
+	// confusedToken is a HS256 token, signed with the public key as the HMAC secret.
+	signingInput := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin"}`))
+	mac := hmac.New(sha256.New, []byte(rsaPublic))
+	mac.Write([]byte(signingInput))
+	confusedToken := signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name, script, err string
+	}{
+		{
+			name:   "expired",
+			script: `jwt.verify(jwt.sign({exp: Math.floor(Date.now() / 1000) - 10}, "secret"), "secret")`,
+			err:    "the token is expired",
+		},
+		{
+			name:   "not valid yet",
+			script: `jwt.verify(jwt.sign({nbf: Math.floor(Date.now() / 1000) + 60}, "secret"), "secret")`,
+			err:    "the token is not valid yet",
+		},
+		{
+			name:   "invalid signature",
+			script: `jwt.verify(jwt.sign({sub: "user"}, "secret"), "other secret")`,
+			err:    "invalid signature",
+		},
+		{
+			name:   "malformed",
+			script: `jwt.verify("abc.def", "secret")`,
+			err:    "malformed token: a token has 3 segments, got 2",
+		},
+		{
+			name:   "pinned algorithm",
+			script: `jwt.verify(jwt.sign({sub: "user"}, "secret"), "secret", {alg: "HS512"})`,
+			err:    `unsupported algorithm: expected "HS512", got "HS256"`,
+		},
+		{
+			name:   "unsupported algorithm",
+			script: `jwt.sign({sub: "user"}, "secret", {alg: "none"})`,
+			err:    `unsupported algorithm "none"`,
+		},
+		{
+			name:   "algorithm confusion",
+			script: `jwt.verify(confusedToken, rsaPublic)`,
+			err:    "invalid key: HS256 requires a secret, not a PEM encoded key",
+		},
+		{
+			name:   "curve mismatch",
+			script: `jwt.sign({sub: "user"}, p384Private, {alg: "ES256"})`,
+			err:    "invalid key: ES256 can't be used with a P-384 key",
+		},
+		{
+			name:   "public key signing",
+			script: `jwt.sign({sub: "user"}, rsaPublic, {alg: "RS256"})`,
+			err:    "invalid key: a private key is required to sign with RS256",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestRuntime(t)
+			rt := ts.VU.Runtime()
+			require.NoError(t, rt.Set("confusedToken", confusedToken))
+			require.NoError(t, rt.Set("rsaPublic", rsaPublic))
+			require.NoError(t, rt.Set("p384Private", p384Private))
+
+			_, err := rt.RunString(tc.script)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
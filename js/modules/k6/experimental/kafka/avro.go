@@ -0,0 +1,386 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// avroSchema is a parsed Avro schema, the fields are the ones of all the
+// types, only the ones of its type are used.
+type avroSchema struct {
+	typ      string
+	name     string
+	fields   []avroField
+	symbols  []string
+	items    *avroSchema
+	values   *avroSchema
+	branches []*avroSchema
+	size     int
+}
+
+type avroField struct {
+	name       string
+	schema     *avroSchema
+	def        interface{}
+	hasDefault bool
+}
+
+var avroPrimitives = map[string]bool{
+	"null": true, "boolean": true, "int": true, "long": true,
+	"float": true, "double": true, "bytes": true, "string": true,
+}
+
+// parseAvroSchema parses the JSON of an Avro schema.
+func parseAvroSchema(schema string) (*avroSchema, error) {
+	var raw interface{}
+	if err := json.Unmarshal([]byte(schema), &raw); err != nil {
+		return nil, fmt.Errorf("invalid Avro schema: %w", err)
+	}
+	p := avroParser{named: make(map[string]*avroSchema)}
+	s, err := p.parse(raw, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid Avro schema: %w", err)
+	}
+	return s, nil
+}
+
+type avroParser struct {
+	named map[string]*avroSchema
+}
+
+func fullName(name, namespace string) string {
+	if strings.Contains(name, ".") || namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+func (p *avroParser) parse(raw interface{}, namespace string) (*avroSchema, error) { //nolint:cyclop,funlen
+	switch v := raw.(type) {
+	case string:
+		if avroPrimitives[v] {
+			return &avroSchema{typ: v}, nil
+		}
+		if s, ok := p.named[fullName(v, namespace)]; ok {
+			return s, nil
+		}
+		if s, ok := p.named[v]; ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("unknown type '%s'", v)
+	case []interface{}:
+		s := &avroSchema{typ: "union"}
+		for _, b := range v {
+			branch, err := p.parse(b, namespace)
+			if err != nil {
+				return nil, err
+			}
+			s.branches = append(s.branches, branch)
+		}
+		return s, nil
+	case map[string]interface{}:
+		typ, _ := v["type"].(string)
+		if typ == "" {
+			if _, ok := v["type"]; ok {
+				return p.parse(v["type"], namespace)
+			}
+			return nil, errors.New("missing type")
+		}
+		s := &avroSchema{typ: typ}
+		switch typ {
+		case "record", "error", "enum", "fixed":
+			if typ == "error" {
+				s.typ = "record"
+			}
+			name, _ := v["name"].(string)
+			if name == "" {
+				return nil, fmt.Errorf("missing name of the %s", typ)
+			}
+			if ns, ok := v["namespace"].(string); ok {
+				namespace = ns
+			}
+			s.name = fullName(name, namespace)
+			if i := strings.LastIndex(s.name, "."); i >= 0 {
+				namespace = s.name[:i]
+			}
+			p.named[s.name] = s
+		}
+		switch s.typ {
+		case "record":
+			fields, _ := v["fields"].([]interface{})
+			for _, f := range fields {
+				fm, ok := f.(map[string]interface{})
+				if !ok {
+					return nil, errors.New("invalid field")
+				}
+				name, _ := fm["name"].(string)
+				fs, err := p.parse(fm["type"], namespace)
+				if err != nil {
+					return nil, fmt.Errorf("invalid field '%s': %w", name, err)
+				}
+				def, hasDefault := fm["default"]
+				s.fields = append(s.fields, avroField{name: name, schema: fs, def: def, hasDefault: hasDefault})
+			}
+		case "enum":
+			symbols, _ := v["symbols"].([]interface{})
+			for _, sym := range symbols {
+				str, _ := sym.(string)
+				s.symbols = append(s.symbols, str)
+			}
+		case "fixed":
+			size, _ := v["size"].(float64)
+			s.size = int(size)
+		case "array":
+			items, err := p.parse(v["items"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			s.items = items
+		case "map":
+			values, err := p.parse(v["values"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			s.values = values
+		default:
+			if !avroPrimitives[s.typ] {
+				return p.parse(s.typ, namespace)
+			}
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("invalid schema %v", raw)
+	}
+}
+
+// encode appends the value, as it's exported from JS, in the Avro binary encoding.
+func (s *avroSchema) encode(buf []byte, value interface{}) ([]byte, error) { //nolint:cyclop,funlen,gocognit
+	switch s.typ {
+	case "null":
+		if value != nil {
+			return nil, fmt.Errorf("expected null, got %v", value)
+		}
+		return buf, nil
+	case "boolean":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected a boolean, got %v", value)
+		}
+		if b {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case "int", "long":
+		n, ok := toFloat(value)
+		if !ok || n != math.Trunc(n) {
+			return nil, fmt.Errorf("expected an integer, got %v", value)
+		}
+		return binary.AppendVarint(buf, int64(n)), nil
+	case "float":
+		n, ok := toFloat(value)
+		if !ok {
+			return nil, fmt.Errorf("expected a number, got %v", value)
+		}
+		return binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(n))), nil
+	case "double":
+		n, ok := toFloat(value)
+		if !ok {
+			return nil, fmt.Errorf("expected a number, got %v", value)
+		}
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(n)), nil
+	case "bytes", "string":
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %v", value)
+		}
+		buf = binary.AppendVarint(buf, int64(len(str)))
+		return append(buf, str...), nil
+	case "fixed":
+		str, ok := value.(string)
+		if !ok || len(str) != s.size {
+			return nil, fmt.Errorf("expected a string of %d bytes, got %v", s.size, value)
+		}
+		return append(buf, str...), nil
+	case "enum":
+		str, _ := value.(string)
+		for i, sym := range s.symbols {
+			if sym == str {
+				return binary.AppendVarint(buf, int64(i)), nil
+			}
+		}
+		return nil, fmt.Errorf("'%v' isn't a symbol of the enum %s", value, s.name)
+	case "record":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an object for the record %s, got %v", s.name, value)
+		}
+		var err error
+		for _, f := range s.fields {
+			v, ok := obj[f.name]
+			if !ok {
+				if !f.hasDefault {
+					return nil, fmt.Errorf("missing field '%s' of the record %s", f.name, s.name)
+				}
+				v = f.def
+			}
+			if buf, err = f.schema.encode(buf, v); err != nil {
+				return nil, fmt.Errorf("invalid field '%s': %w", f.name, err)
+			}
+		}
+		return buf, nil
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an array, got %v", value)
+		}
+		if len(items) > 0 {
+			buf = binary.AppendVarint(buf, int64(len(items)))
+			var err error
+			for _, item := range items {
+				if buf, err = s.items.encode(buf, item); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return append(buf, 0), nil
+	case "map":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an object, got %v", value)
+		}
+		if len(obj) > 0 {
+			keys := make([]string, 0, len(obj))
+			for k := range obj {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			buf = binary.AppendVarint(buf, int64(len(keys)))
+			var err error
+			for _, k := range keys {
+				buf = binary.AppendVarint(buf, int64(len(k)))
+				buf = append(buf, k...)
+				if buf, err = s.values.encode(buf, obj[k]); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return append(buf, 0), nil
+	case "union":
+		i, v, err := s.unionBranch(value)
+		if err != nil {
+			return nil, err
+		}
+		return s.branches[i].encode(binary.AppendVarint(buf, int64(i)), v)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", s.typ)
+	}
+}
+
+// unionBranch returns the branch of the union for the value, which is
+// either the branch of the JSON encoding of the unions, e.g. {"string": "a"},
+// or the first branch that the value is valid for.
+func (s *avroSchema) unionBranch(value interface{}) (int, interface{}, error) {
+	if obj, ok := value.(map[string]interface{}); ok && len(obj) == 1 {
+		for k, v := range obj {
+			for i, b := range s.branches {
+				if b.typ == k || (b.name != "" && (b.name == k || strings.HasSuffix(b.name, "."+k))) {
+					return i, v, nil
+				}
+			}
+		}
+	}
+	for i, b := range s.branches {
+		if _, err := b.encode(nil, value); err == nil {
+			return i, value, nil
+		}
+	}
+	return 0, nil, fmt.Errorf("the value %v doesn't match any type of the union", value)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+// decode reads a value in the Avro binary encoding.
+func (s *avroSchema) decode(d *decoder) interface{} { //nolint:cyclop
+	switch s.typ {
+	case "null":
+		return nil
+	case "boolean":
+		return d.int8() != 0
+	case "int", "long":
+		return d.varint()
+	case "float":
+		if b := d.next(4); b != nil {
+			return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		}
+	case "double":
+		if b := d.next(8); b != nil {
+			return math.Float64frombits(binary.LittleEndian.Uint64(b))
+		}
+	case "bytes", "string":
+		return string(d.varintBytes())
+	case "fixed":
+		return string(d.next(s.size))
+	case "enum":
+		i := d.varint()
+		if i >= 0 && int(i) < len(s.symbols) {
+			return s.symbols[i]
+		}
+		d.err = fmt.Errorf("invalid index %d of the enum %s", i, s.name)
+	case "record":
+		obj := make(map[string]interface{}, len(s.fields))
+		for _, f := range s.fields {
+			obj[f.name] = f.schema.decode(d)
+		}
+		return obj
+	case "array":
+		items := []interface{}{}
+		s.decodeBlocks(d, func() { items = append(items, s.items.decode(d)) })
+		return items
+	case "map":
+		obj := map[string]interface{}{}
+		s.decodeBlocks(d, func() {
+			k := string(d.varintBytes())
+			obj[k] = s.values.decode(d)
+		})
+		return obj
+	case "union":
+		i := d.varint()
+		if i >= 0 && int(i) < len(s.branches) {
+			return s.branches[i].decode(d)
+		}
+		d.err = fmt.Errorf("invalid index %d of the union", i)
+	}
+	return nil
+}
+
+func (s *avroSchema) decodeBlocks(d *decoder, item func()) {
+	for d.err == nil {
+		n := d.varint()
+		if n == 0 {
+			return
+		}
+		if n < 0 {
+			n = -n
+			d.varint() // the size of the block
+		}
+		for i := int64(0); i < n && d.err == nil; i++ {
+			item()
+		}
+	}
+}
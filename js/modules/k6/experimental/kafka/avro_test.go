@@ -0,0 +1,85 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAvroEncoding(t *testing.T) {
+	t.Parallel()
+
+	// the example of the specification
+	s, err := parseAvroSchema(`{"type": "record", "name": "test", "fields": [
+		{"name": "a", "type": "long"},
+		{"name": "b", "type": "string"}
+	]}`)
+	require.NoError(t, err)
+	buf, err := s.encode(nil, map[string]interface{}{"a": int64(27), "b": "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x36, 0x06, 0x66, 0x6f, 0x6f}, buf)
+}
+
+func TestAvroRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	s, err := parseAvroSchema(`{"type": "record", "name": "Order", "namespace": "shop", "fields": [
+		{"name": "id", "type": "long"},
+		{"name": "price", "type": "double"},
+		{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["NEW", "PAID"]}},
+		{"name": "items", "type": {"type": "array", "items": {"type": "record", "name": "Item", "fields": [
+			{"name": "sku", "type": "string"},
+			{"name": "quantity", "type": "int"}
+		]}}},
+		{"name": "attributes", "type": {"type": "map", "values": "string"}},
+		{"name": "coupon", "type": ["null", "string"], "default": null},
+		{"name": "gift", "type": ["null", "shop.Item"]},
+		{"name": "paid", "type": "boolean", "default": false}
+	]}`)
+	require.NoError(t, err)
+
+	buf, err := s.encode(nil, map[string]interface{}{
+		"id":         int64(1),
+		"price":      9.5,
+		"status":     "PAID",
+		"items":      []interface{}{map[string]interface{}{"sku": "a", "quantity": int64(2)}},
+		"attributes": map[string]interface{}{"color": "red"},
+		"gift":       map[string]interface{}{"Item": map[string]interface{}{"sku": "b", "quantity": int64(1)}},
+	})
+	require.NoError(t, err)
+
+	d := &decoder{buf: buf}
+	v := s.decode(d)
+	require.NoError(t, d.err)
+	assert.Empty(t, d.buf)
+	assert.Equal(t, map[string]interface{}{
+		"id":         int64(1),
+		"price":      9.5,
+		"status":     "PAID",
+		"items":      []interface{}{map[string]interface{}{"sku": "a", "quantity": int64(2)}},
+		"attributes": map[string]interface{}{"color": "red"},
+		"coupon":     nil,
+		"gift":       map[string]interface{}{"sku": "b", "quantity": int64(1)},
+		"paid":       false,
+	}, v)
+}
+
+func TestAvroErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := parseAvroSchema(`{"type": "record", "name": "A", "fields": [{"name": "b", "type": "B"}]}`)
+	assert.EqualError(t, err, "invalid Avro schema: invalid field 'b': unknown type 'B'")
+
+	s, err := parseAvroSchema(`{"type": "record", "name": "A", "fields": [
+		{"name": "n", "type": "int"},
+		{"name": "u", "type": ["null", "long"]}
+	]}`)
+	require.NoError(t, err)
+	_, err = s.encode(nil, map[string]interface{}{"u": nil})
+	assert.EqualError(t, err, "missing field 'n' of the record A")
+	_, err = s.encode(nil, map[string]interface{}{"n": 1.5, "u": nil})
+	assert.EqualError(t, err, "invalid field 'n': expected an integer, got 1.5")
+	_, err = s.encode(nil, map[string]interface{}{"n": int64(1), "u": "a"})
+	assert.EqualError(t, err, "invalid field 'u': the value a doesn't match any type of the union")
+}
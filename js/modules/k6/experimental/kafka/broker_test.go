@@ -0,0 +1,273 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testBroker is a minimal Kafka broker with one topic, which is the leader
+// of all its partitions. The produced batches are kept as they are, with
+// their base offsets set, and they are returned by the fetch requests.
+type testBroker struct {
+	topic      string
+	partitions int
+	// username and password are required with the SASL PLAIN authentication if they are set
+	username string
+	password string
+
+	mx      sync.Mutex
+	host    string
+	port    int32
+	batches map[int32][][]byte
+	offsets map[int32]int64
+	acks    []int16
+}
+
+// newTestBroker starts a broker of the topic and returns its address.
+func newTestBroker(t *testing.T, topic string, partitions int) (*testBroker, string) {
+	t.Helper()
+	return startTestBroker(t, &testBroker{topic: topic, partitions: partitions})
+}
+
+// startTestBroker starts the broker, with the authentication if its
+// credentials are set, and returns its address.
+func startTestBroker(t *testing.T, b *testBroker) (*testBroker, string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = lis.Close() })
+
+	host, port, err := net.SplitHostPort(lis.Addr().String())
+	require.NoError(t, err)
+	p, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	b.host, b.port = host, int32(p)
+	b.batches = make(map[int32][][]byte)
+	b.offsets = make(map[int32]int64)
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go b.handle(conn)
+		}
+	}()
+	return b, lis.Addr().String()
+}
+
+func (b *testBroker) handle(conn net.Conn) { //nolint:cyclop
+	defer func() { _ = conn.Close() }()
+
+	authenticated := b.username == ""
+	for {
+		var sizeBuf [4]byte
+		if _, err := io.ReadFull(conn, sizeBuf[:]); err != nil {
+			return
+		}
+		req := make([]byte, binary.BigEndian.Uint32(sizeBuf[:]))
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return
+		}
+		d := &decoder{buf: req}
+		apiKey := d.int16()
+		d.int16() // version
+		correlationID := d.int32()
+		d.string() // client id
+
+		e := &encoder{}
+		switch apiKey {
+		case apiSaslHandshake:
+			if d.string() == "PLAIN" {
+				e.int16(0)
+			} else {
+				e.int16(33)
+			}
+			e.arrayLen(1)
+			e.string("PLAIN")
+		case apiSaslAuthenticate:
+			if string(d.bytes()) == "\x00"+b.username+"\x00"+b.password {
+				authenticated = true
+				e.int16(0)
+				e.nullableString(nil)
+			} else {
+				msg := "invalid credentials"
+				e.int16(58)
+				e.nullableString(&msg)
+			}
+			e.bytes([]byte{})
+		default:
+			if !authenticated {
+				return
+			}
+			if !b.respond(apiKey, d, e) {
+				continue
+			}
+		}
+
+		resp := binary.BigEndian.AppendUint32(nil, uint32(len(e.buf)+4))
+		resp = binary.BigEndian.AppendUint32(resp, uint32(correlationID))
+		if _, err := conn.Write(append(resp, e.buf...)); err != nil {
+			return
+		}
+	}
+}
+
+// respond encodes the response of the request, it returns false if there
+// isn't any response, like for the Produce requests with acks=0.
+func (b *testBroker) respond(apiKey int16, d *decoder, e *encoder) bool {
+	switch apiKey {
+	case apiMetadata:
+		e.arrayLen(1)
+		e.int32(1)
+		e.string(b.host)
+		e.int32(b.port)
+		e.nullableString(nil)
+		e.int32(1) // controller id
+		topics := make([]string, d.arrayLen())
+		for i := range topics {
+			topics[i] = d.string()
+		}
+		e.arrayLen(len(topics))
+		for _, topic := range topics {
+			if topic != b.topic {
+				e.int16(3)
+				e.string(topic)
+				e.int8(0)
+				e.arrayLen(0)
+				continue
+			}
+			e.int16(0)
+			e.string(topic)
+			e.int8(0)
+			e.arrayLen(b.partitions)
+			for i := 0; i < b.partitions; i++ {
+				e.int16(0)
+				e.int32(int32(i))
+				e.int32(1) // leader
+				e.arrayLen(1)
+				e.int32(1)
+				e.arrayLen(1)
+				e.int32(1)
+			}
+		}
+	case apiProduce:
+		d.string() // transactional id
+		acks := d.int16()
+		d.int32() // timeout
+		d.arrayLen()
+		topic := d.string()
+		d.arrayLen()
+		partition := d.int32()
+		batch := d.bytes()
+		offset := b.produce(acks, partition, batch)
+		if acks == 0 {
+			return false
+		}
+		e.arrayLen(1)
+		e.string(topic)
+		e.arrayLen(1)
+		e.int32(partition)
+		e.int16(0)
+		e.int64(offset)
+		e.int64(-1)
+		e.int64(0)
+		e.int32(0) // throttle time
+	case apiFetch:
+		d.int32() // replica id
+		maxWait := time.Duration(d.int32()) * time.Millisecond
+		d.int32() // min bytes
+		d.int32() // max bytes
+		d.int8()  // isolation level
+		d.int32() // session id
+		d.int32() // session epoch
+		d.arrayLen()
+		topic := d.string()
+		d.arrayLen()
+		partition := d.int32()
+		d.int32() // current leader epoch
+		offset := d.int64()
+
+		records := b.fetch(partition, offset, maxWait)
+		e.int32(0) // throttle time
+		e.int16(0)
+		e.int32(0) // session id
+		e.arrayLen(1)
+		e.string(topic)
+		e.arrayLen(1)
+		e.int32(partition)
+		e.int16(0)
+		e.int64(b.offset(partition))
+		e.int64(b.offset(partition))
+		e.int64(0)
+		e.arrayLen(0)
+		e.bytes(records)
+	case apiListOffsets:
+		d.int32() // replica id
+		d.arrayLen()
+		topic := d.string()
+		d.arrayLen()
+		partition := d.int32()
+		var offset int64
+		if d.int64() == latestTimestamp {
+			offset = b.offset(partition)
+		}
+		e.arrayLen(1)
+		e.string(topic)
+		e.arrayLen(1)
+		e.int32(partition)
+		e.int16(0)
+		e.int64(-1)
+		e.int64(offset)
+	}
+	return true
+}
+
+// produce appends the batch to the partition, and it returns its base offset.
+func (b *testBroker) produce(acks int16, partition int32, batch []byte) int64 {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	b.acks = append(b.acks, acks)
+	offset := b.offsets[partition]
+	batch = append([]byte{}, batch...)
+	binary.BigEndian.PutUint64(batch, uint64(offset))
+	b.batches[partition] = append(b.batches[partition], batch)
+	// the last offset delta is after the base offset, the length, the leader epoch,
+	// the magic byte, the CRC and the attributes
+	b.offsets[partition] += int64(binary.BigEndian.Uint32(batch[23:])) + 1
+	return offset
+}
+
+// fetch returns the batches with the records from the offset, it waits for
+// them up to maxWait.
+func (b *testBroker) fetch(partition int32, offset int64, maxWait time.Duration) []byte {
+	deadline := time.Now().Add(maxWait)
+	for {
+		b.mx.Lock()
+		var records []byte
+		for _, batch := range b.batches[partition] {
+			if int64(binary.BigEndian.Uint64(batch))+int64(binary.BigEndian.Uint32(batch[23:])) >= offset {
+				records = append(records, batch...)
+			}
+		}
+		b.mx.Unlock()
+		if len(records) > 0 || time.Now().After(deadline) {
+			return records
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (b *testBroker) offset(partition int32) int64 {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	return b.offsets[partition]
+}
@@ -0,0 +1,302 @@
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"go.k6.io/k6/lib"
+)
+
+// maxResponseSize is the maximum size of the responses, to not allocate
+// huge buffers for the malformed ones.
+const maxResponseSize = 256 << 20
+
+// brokerConn is a connection to a broker, the requests are made one at a time.
+type brokerConn struct {
+	mx            sync.Mutex
+	conn          net.Conn
+	clientID      string
+	correlationID int32
+}
+
+// request sends the request and returns the decoder of the body of the response.
+func (b *brokerConn) request(ctx context.Context, apiKey, version int16, body func(*encoder)) (*decoder, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	if err := b.write(ctx, apiKey, version, body); err != nil {
+		return nil, err
+	}
+
+	var sizeBuf [4]byte
+	if _, err := io.ReadFull(b.conn, sizeBuf[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(sizeBuf[:])
+	if size < 4 || size > maxResponseSize {
+		return nil, fmt.Errorf("invalid size of the response: %d", size)
+	}
+	resp := make([]byte, size)
+	if _, err := io.ReadFull(b.conn, resp); err != nil {
+		return nil, err
+	}
+	d := &decoder{buf: resp}
+	if id := d.int32(); id != b.correlationID {
+		return nil, fmt.Errorf("unexpected correlation id %d of the response, expected %d", id, b.correlationID)
+	}
+	return d, nil
+}
+
+// send sends the request without waiting for a response, for the Produce
+// requests with acks=0, which the brokers don't respond to.
+func (b *brokerConn) send(ctx context.Context, apiKey, version int16, body func(*encoder)) error {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	return b.write(ctx, apiKey, version, body)
+}
+
+func (b *brokerConn) write(ctx context.Context, apiKey, version int16, body func(*encoder)) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := b.conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	b.correlationID++
+	e := &encoder{buf: make([]byte, 4, 64)} // the size is set at the end
+	e.int16(apiKey)
+	e.int16(version)
+	e.int32(b.correlationID)
+	e.string(b.clientID)
+	body(e)
+	binary.BigEndian.PutUint32(e.buf, uint32(len(e.buf)-4))
+	_, err := b.conn.Write(e.buf)
+	return err
+}
+
+// saslConfig is the config of the SASL authentication.
+type saslConfig struct {
+	username string
+	password string
+}
+
+// clusterConfig is the config of the connections to the brokers.
+type clusterConfig struct {
+	brokers  []string
+	clientID string
+	dialer   lib.DialContexter
+	tls      *tls.Config
+	sasl     *saslConfig
+}
+
+// cluster keeps the connections to the brokers and the metadata of a topic.
+type cluster struct {
+	config clusterConfig
+
+	mx    sync.Mutex
+	conns map[string]*brokerConn
+	meta  *metadata
+}
+
+func newCluster(config clusterConfig) *cluster {
+	return &cluster{config: config, conns: make(map[string]*brokerConn)}
+}
+
+// conn returns the connection to the broker, it's opened if it isn't yet.
+func (c *cluster) conn(ctx context.Context, addr string) (*brokerConn, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if b, ok := c.conns[addr]; ok {
+		return b, nil
+	}
+
+	conn, err := c.config.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if c.config.tls != nil {
+		config := c.config.tls.Clone()
+		if config.ServerName == "" {
+			config.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		tlsConn := tls.Client(conn, config)
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("TLS handshake with %s failed: %w", addr, err)
+		}
+		conn = tlsConn
+	}
+
+	b := &brokerConn{conn: conn, clientID: c.config.clientID}
+	if c.config.sasl != nil {
+		if err = authenticate(ctx, b, c.config.sasl); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("unable to authenticate to %s: %w", addr, err)
+		}
+	}
+	c.conns[addr] = b
+	return b, nil
+}
+
+// authenticate makes the SASL authentication with the PLAIN mechanism.
+func authenticate(ctx context.Context, b *brokerConn, sasl *saslConfig) error {
+	d, err := b.request(ctx, apiSaslHandshake, versionSaslHandshake, func(e *encoder) {
+		e.string("PLAIN")
+	})
+	if err != nil {
+		return err
+	}
+	if err = errorCode(d.int16()); err != nil {
+		return err
+	}
+
+	d, err = b.request(ctx, apiSaslAuthenticate, versionSaslAuthenticate, func(e *encoder) {
+		e.bytes([]byte("\x00" + sasl.username + "\x00" + sasl.password))
+	})
+	if err != nil {
+		return err
+	}
+	code := d.int16()
+	msg := d.string()
+	if d.err != nil {
+		return d.err
+	}
+	if err = errorCode(code); err != nil && msg != "" {
+		return fmt.Errorf("%w: %s", err, msg)
+	}
+	return err
+}
+
+// metadata returns the metadata of the topic, which is requested from the
+// bootstrap brokers the first time or when refresh is true.
+func (c *cluster) metadata(ctx context.Context, topic string, refresh bool) (*metadata, error) {
+	c.mx.Lock()
+	meta := c.meta
+	c.mx.Unlock()
+	if meta != nil && !refresh {
+		return meta, nil
+	}
+
+	var errs []string
+	for _, addr := range c.config.brokers {
+		b, err := c.conn(ctx, addr)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		d, err := b.request(ctx, apiMetadata, versionMetadata, func(e *encoder) {
+			encodeMetadataRequest(e, []string{topic})
+		})
+		if err != nil {
+			c.closeConn(addr)
+			errs = append(errs, err.Error())
+			continue
+		}
+		meta = decodeMetadataResponse(d)
+		if d.err != nil {
+			return nil, fmt.Errorf("malformed metadata response: %w", d.err)
+		}
+		if err := meta.errors[topic]; err != nil {
+			return nil, fmt.Errorf("unable to get the metadata of the topic '%s': %w", topic, err)
+		}
+		if len(meta.topics[topic]) == 0 {
+			return nil, fmt.Errorf("the topic '%s' doesn't have any partition", topic)
+		}
+		c.mx.Lock()
+		c.meta = meta
+		c.mx.Unlock()
+		return meta, nil
+	}
+	return nil, fmt.Errorf("unable to connect to the brokers: %s", strings.Join(errs, "; "))
+}
+
+// leader returns the connection to the leader of the partition.
+func (c *cluster) leader(ctx context.Context, topic string, partition int32) (*brokerConn, error) {
+	for _, refresh := range []bool{false, true} {
+		meta, err := c.metadata(ctx, topic, refresh)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range meta.topics[topic] {
+			if p.index != partition {
+				continue
+			}
+			if p.err != nil && !errors.Is(p.err, Error(5)) {
+				return nil, p.err
+			}
+			if addr, ok := meta.brokers[p.leader]; ok {
+				return c.conn(ctx, addr)
+			}
+		}
+	}
+	return nil, fmt.Errorf("there is no leader for the partition %d of the topic '%s'", partition, topic)
+}
+
+// request makes the request to the leader of the partition, and it retries
+// once with the new leader if the broker isn't the leader anymore.
+func (c *cluster) request(
+	ctx context.Context, topic string, partition int32, apiKey, version int16,
+	body func(*encoder), decode func(*decoder) error,
+) error {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var b *brokerConn
+		if b, err = c.leader(ctx, topic, partition); err != nil {
+			return err
+		}
+		var d *decoder
+		if d, err = b.request(ctx, apiKey, version, body); err != nil {
+			c.closeConn(b.conn.RemoteAddr().String())
+			return err
+		}
+		err = decode(d)
+		if d.err != nil {
+			return fmt.Errorf("malformed response: %w", d.err)
+		}
+		if !errors.Is(err, Error(6)) && !errors.Is(err, Error(5)) {
+			return err
+		}
+		if _, merr := c.metadata(ctx, topic, true); merr != nil {
+			return merr
+		}
+	}
+	return err
+}
+
+func (c *cluster) closeConn(addr string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	for k, b := range c.conns {
+		if k == addr || b.conn.RemoteAddr().String() == addr {
+			_ = b.conn.Close()
+			delete(c.conns, k)
+		}
+	}
+}
+
+func (c *cluster) close() {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	for addr, b := range c.conns {
+		_ = b.conn.Close()
+		delete(c.conns, addr)
+	}
+}
+
+// timeoutMs returns the timeout in milliseconds for the broker, which is a
+// bit lower than the timeout of the request.
+func timeoutMs(timeout time.Duration) int32 {
+	ms := timeout.Milliseconds() * 9 / 10
+	if ms > 1<<31-1 {
+		ms = 1<<31 - 1
+	}
+	return int32(ms)
+}
@@ -0,0 +1,288 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	defaultClientID = "k6"
+	defaultTimeout  = 10 * time.Second
+)
+
+// config contains the params that are common to the producers and the consumers.
+type config struct {
+	brokers  []string
+	topic    string
+	clientID string
+	timeout  time.Duration
+	sasl     *saslConfig
+	tls      *tlsParams
+	registry *registryParams
+	key      serdeConfig
+	value    serdeConfig
+	tags     map[string]string
+}
+
+type tlsParams struct {
+	serverName         string
+	insecureSkipVerify bool
+}
+
+type registryParams struct {
+	url      string
+	username string
+	password string
+}
+
+// parseConfig parses the common params, and it passes the other ones to
+// the parse function of the producer or the consumer.
+func parseConfig( //nolint:cyclop,funlen
+	rt *goja.Runtime, input goja.Value, parse func(k string, v goja.Value) (bool, error),
+) (config, error) {
+	c := config{
+		clientID: defaultClientID,
+		timeout:  defaultTimeout,
+		key:      serdeConfig{format: formatString},
+		value:    serdeConfig{format: formatString},
+	}
+	if common.IsNullish(input) {
+		return c, errors.New("missing config, the brokers and the topic are required")
+	}
+	raw := input.ToObject(rt)
+	for _, k := range raw.Keys() {
+		v := raw.Get(k)
+		var err error
+		switch k {
+		case "brokers":
+			err = rt.ExportTo(v, &c.brokers)
+		case "topic":
+			c.topic = v.String()
+		case "clientId":
+			c.clientID = v.String()
+		case "timeout":
+			c.timeout, err = types.GetDurationValue(v.Export())
+		case "sasl":
+			c.sasl, err = parseSASL(rt, v)
+		case "tls":
+			c.tls = parseTLS(rt, v)
+		case "schemaRegistry":
+			c.registry, err = parseRegistry(rt, v)
+		case "key":
+			c.key, err = parseSerde(rt, v)
+		case "value":
+			c.value, err = parseSerde(rt, v)
+		case "tags":
+			err = rt.ExportTo(v, &c.tags)
+		default:
+			var ok bool
+			if ok, err = parse(k, v); err == nil && !ok {
+				return c, fmt.Errorf("unknown param '%s'", k)
+			}
+		}
+		if err != nil {
+			return c, fmt.Errorf("invalid %s value: %w", k, err)
+		}
+	}
+
+	if len(c.brokers) == 0 {
+		return c, errors.New("the brokers are required")
+	}
+	if c.topic == "" {
+		return c, errors.New("the topic is required")
+	}
+	if c.timeout <= 0 {
+		return c, fmt.Errorf("invalid timeout value: %s", c.timeout)
+	}
+	// the subjects of the TopicNameStrategy by default
+	for _, s := range []struct {
+		serde  *serdeConfig
+		suffix string
+	}{{&c.key, "-key"}, {&c.value, "-value"}} {
+		switch s.serde.format {
+		case formatString, formatBinary, formatJSON:
+		case formatAvro, formatProtobuf:
+			if c.registry == nil {
+				return c, fmt.Errorf("the %s format requires the schemaRegistry param", s.serde.format)
+			}
+			if s.serde.subject == "" {
+				s.serde.subject = c.topic + s.suffix
+			}
+		default:
+			return c, fmt.Errorf("unsupported format '%s', it needs to be string, binary, json, avro or protobuf",
+				s.serde.format)
+		}
+	}
+	return c, nil
+}
+
+func parseSASL(rt *goja.Runtime, v goja.Value) (*saslConfig, error) {
+	obj := v.ToObject(rt)
+	if m := obj.Get("mechanism"); !common.IsNullish(m) && !strings.EqualFold(m.String(), "plain") {
+		return nil, fmt.Errorf("unsupported SASL mechanism '%s', only plain is supported", m.String())
+	}
+	sasl := &saslConfig{}
+	if u := obj.Get("username"); !common.IsNullish(u) {
+		sasl.username = u.String()
+	}
+	if p := obj.Get("password"); !common.IsNullish(p) {
+		sasl.password = p.String()
+	}
+	return sasl, nil
+}
+
+// parseTLS parses the tls param, which is either a boolean or an object
+// with the serverName and insecureSkipVerify options.
+func parseTLS(rt *goja.Runtime, v goja.Value) *tlsParams {
+	if common.IsNullish(v) {
+		return nil
+	}
+	if _, ok := v.Export().(bool); ok {
+		if !v.ToBoolean() {
+			return nil
+		}
+		return &tlsParams{}
+	}
+	obj := v.ToObject(rt)
+	p := &tlsParams{}
+	if sn := obj.Get("serverName"); !common.IsNullish(sn) {
+		p.serverName = sn.String()
+	}
+	if skip := obj.Get("insecureSkipVerify"); skip != nil {
+		p.insecureSkipVerify = skip.ToBoolean()
+	}
+	return p
+}
+
+func parseRegistry(rt *goja.Runtime, v goja.Value) (*registryParams, error) {
+	obj := v.ToObject(rt)
+	p := &registryParams{}
+	if u := obj.Get("url"); !common.IsNullish(u) {
+		p.url = u.String()
+	}
+	if p.url == "" {
+		return nil, errors.New("the url is required")
+	}
+	if u := obj.Get("username"); !common.IsNullish(u) {
+		p.username = u.String()
+	}
+	if pw := obj.Get("password"); !common.IsNullish(pw) {
+		p.password = pw.String()
+	}
+	return p, nil
+}
+
+func parseSerde(rt *goja.Runtime, v goja.Value) (serdeConfig, error) {
+	s := serdeConfig{format: formatString}
+	obj := v.ToObject(rt)
+	for _, k := range obj.Keys() {
+		switch k {
+		case "format":
+			s.format = obj.Get(k).String()
+		case "subject":
+			s.subject = obj.Get(k).String()
+		case "messageType":
+			s.messageType = obj.Get(k).String()
+		default:
+			return s, fmt.Errorf("unknown param '%s'", k)
+		}
+	}
+	return s, nil
+}
+
+// newCluster returns the cluster of the config, with the dialer and the
+// TLS config of the VU, so the hosts and the blacklists are applied to the
+// connections to the brokers too.
+func (c config) newCluster(state *lib.State) *cluster {
+	cc := clusterConfig{
+		brokers:  c.brokers,
+		clientID: c.clientID,
+		dialer:   state.Dialer,
+		sasl:     c.sasl,
+	}
+	if c.tls != nil {
+		// the TLS config of the VU has the tlsAuth and the other TLS options
+		cc.tls = &tls.Config{MinVersion: tls.VersionTLS12} //nolint:gosec
+		if state.TLSConfig != nil {
+			cc.tls = state.TLSConfig.Clone()
+		}
+		cc.tls.ServerName = c.tls.serverName
+		if c.tls.insecureSkipVerify {
+			cc.tls.InsecureSkipVerify = true
+		}
+	}
+	return newCluster(cc)
+}
+
+// newRegistry returns the client of the schema registry, which uses the
+// transport of the VU.
+func (c config) newRegistry(state *lib.State) *schemaRegistry {
+	if c.registry == nil {
+		return nil
+	}
+	return &schemaRegistry{
+		url:       c.registry.url,
+		username:  c.registry.username,
+		password:  c.registry.password,
+		client:    &http.Client{Transport: state.Transport, Timeout: c.timeout},
+		bySubject: make(map[string]*registrySchema),
+		byID:      make(map[int32]*registrySchema),
+	}
+}
+
+// client contains the state that is common to the producers and the
+// consumers, the connections are opened on the first use in the VU context.
+type client struct {
+	mi       *ModuleInstance
+	config   config
+	cluster  *cluster
+	registry *schemaRegistry
+}
+
+// init opens the cluster on the first use, and it returns the tags and the
+// metadata of the metrics.
+func (c *client) init(action string) (*lib.State, metrics.TagsAndMeta, error) {
+	state := c.mi.vu.State()
+	if state == nil {
+		return nil, metrics.TagsAndMeta{}, common.NewInitContextError(
+			fmt.Sprintf("%s Kafka messages in the init context is not supported", action))
+	}
+	if c.cluster == nil {
+		c.cluster = c.config.newCluster(state)
+		c.registry = c.config.newRegistry(state)
+	}
+	ctm := state.Tags.GetCurrentValues()
+	tags := ctm.Tags.With("topic", c.config.topic)
+	for k, v := range c.config.tags {
+		tags = tags.With(k, v)
+	}
+	return state, metrics.TagsAndMeta{Tags: tags, Metadata: ctm.Metadata}, nil
+}
+
+// Close closes the connections to the brokers, they are reopened if the
+// client is used again.
+func (c *client) Close() {
+	if c.cluster != nil {
+		c.cluster.close()
+	}
+}
+
+func (c *client) push(state *lib.State, metric *metrics.Metric, tm metrics.TagsAndMeta, value float64) {
+	metrics.PushIfNotDone(c.mi.vu.Context(), state.Samples, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{Metric: metric, Tags: tm.Tags},
+		Time:       time.Now(),
+		Metadata:   tm.Metadata,
+		Value:      value,
+	})
+}
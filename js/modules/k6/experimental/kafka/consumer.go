@@ -0,0 +1,246 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+// defaultMaxBytes is the default maximum size of the fetched data.
+const defaultMaxBytes = 1 << 20
+
+// Consumer consumes the messages of a partition of a topic, from the
+// earliest, the latest or an explicit offset. The consumers aren't a part
+// of a consumer group, and their offsets aren't committed.
+type Consumer struct {
+	client
+
+	partition int32
+	start     int64 // the timestamp of the ListOffsets request of the initial offset
+	offset    int64 // the next offset to fetch, -1 until it's known
+	maxBytes  int32
+}
+
+// consumeParams are the params of the consume method.
+type consumeParams struct {
+	limit   int
+	timeout time.Duration
+}
+
+func newConsumer(mi *ModuleInstance, input goja.Value) (*Consumer, error) {
+	rt := mi.vu.Runtime()
+	c := &Consumer{client: client{mi: mi}, start: latestTimestamp, offset: -1, maxBytes: defaultMaxBytes}
+	var err error
+	c.config, err = parseConfig(rt, input, func(k string, v goja.Value) (bool, error) {
+		switch k {
+		case "partition":
+			c.partition = int32(v.ToInteger())
+		case "offset":
+			switch v.String() {
+			case "earliest":
+				c.start = earliestTimestamp
+			case "latest":
+				c.start = latestTimestamp
+			default:
+				if _, ok := v.Export().(string); ok {
+					return true, errors.New("it needs to be earliest, latest or a number")
+				}
+				c.offset = v.ToInteger()
+				if c.offset < 0 {
+					return true, errors.New("the offset can't be negative")
+				}
+			}
+		case "maxBytes":
+			c.maxBytes = int32(v.ToInteger())
+		default:
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid Consumer config: %w", err)
+	}
+	return c, nil
+}
+
+func (c *Consumer) newConsumeParams(input goja.Value) (consumeParams, error) {
+	params := consumeParams{limit: 1, timeout: c.config.timeout}
+	if common.IsNullish(input) {
+		return params, nil
+	}
+	raw := input.ToObject(c.mi.vu.Runtime())
+	for _, k := range raw.Keys() {
+		v := raw.Get(k)
+		var err error
+		switch k {
+		case "limit":
+			params.limit = int(v.ToInteger())
+			if params.limit <= 0 {
+				err = errors.New("it needs to be greater than 0")
+			}
+		case "timeout":
+			params.timeout, err = types.GetDurationValue(v.Export())
+		default:
+			return params, fmt.Errorf("unknown param '%s'", k)
+		}
+		if err != nil {
+			return params, fmt.Errorf("invalid %s value: %w", k, err)
+		}
+	}
+	return params, nil
+}
+
+// Consume returns the next messages of the partition, up to the limit. It
+// waits for the messages until the timeout, and it returns the ones that
+// are consumed until then, which can be none.
+func (c *Consumer) Consume(input goja.Value) ([]*goja.Object, error) {
+	state, tm, err := c.init("consuming")
+	if err != nil {
+		return nil, err
+	}
+	params, err := c.newConsumeParams(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid consume() parameters: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(c.mi.vu.Context(), params.timeout)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+
+	start := time.Now()
+	if c.offset < 0 {
+		if c.offset, err = c.listOffset(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var messages []*goja.Object
+	for len(messages) < params.limit {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			break
+		}
+		records, size, err := c.fetch(ctx, remaining)
+		if err != nil {
+			if !time.Now().Before(deadline) && c.mi.vu.Context().Err() == nil {
+				break // the timeout of the consume call
+			}
+			return nil, err
+		}
+		if size > 0 {
+			c.push(state, c.mi.metrics.BytesConsumed, tm, float64(size))
+		}
+		now := time.Now()
+		for _, r := range records {
+			if r.offset < c.offset || len(messages) >= params.limit {
+				continue
+			}
+			msg, err := c.newMessage(r)
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, msg)
+			c.offset = r.offset + 1
+			c.push(state, c.mi.metrics.ConsumeLatency, tm, metrics.D(now.Sub(r.timestamp)))
+		}
+	}
+	end := time.Now()
+
+	c.push(state, c.mi.metrics.ConsumeDuration, tm, metrics.D(end.Sub(start)))
+	if len(messages) > 0 {
+		c.push(state, c.mi.metrics.MessagesConsumed, tm, float64(len(messages)))
+	}
+	return messages, nil
+}
+
+// listOffset returns the earliest or the latest offset of the partition.
+func (c *Consumer) listOffset(ctx context.Context) (int64, error) {
+	var offset int64
+	topic := c.config.topic
+	err := c.cluster.request(ctx, topic, c.partition, apiListOffsets, versionListOffsets,
+		func(e *encoder) { encodeListOffsetsRequest(e, topic, c.partition, c.start) },
+		func(d *decoder) error {
+			var err error
+			offset, err = decodeListOffsetsResponse(d)
+			return err
+		},
+	)
+	if err != nil {
+		return -1, fmt.Errorf("unable to get the offset of the partition %d of the topic '%s': %w",
+			c.partition, topic, err)
+	}
+	return offset, nil
+}
+
+// fetch fetches the records from the current offset, the broker waits for
+// them up to the remaining time. It returns the size of the fetched data too.
+func (c *Consumer) fetch(ctx context.Context, remaining time.Duration) ([]record, int, error) {
+	var data []byte
+	topic := c.config.topic
+	err := c.cluster.request(ctx, topic, c.partition, apiFetch, versionFetch,
+		func(e *encoder) {
+			encodeFetchRequest(e, timeoutMs(remaining), 1, c.maxBytes, topic, c.partition, c.offset)
+		},
+		func(d *decoder) error {
+			var err error
+			data, err = decodeFetchResponse(d)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to fetch from the partition %d of the topic '%s': %w", c.partition, topic, err)
+	}
+	records, err := decodeRecordBatches(data)
+	return records, len(data), err
+}
+
+// newMessage returns the JS object of the record, with its deserialized key
+// and value.
+func (c *Consumer) newMessage(r record) (*goja.Object, error) {
+	rt := c.mi.vu.Runtime()
+	key, err := c.config.key.deserialize(c.registry, r.key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to deserialize the key of the message %d: %w", r.offset, err)
+	}
+	value, err := c.config.value.deserialize(c.registry, r.value)
+	if err != nil {
+		return nil, fmt.Errorf("unable to deserialize the value of the message %d: %w", r.offset, err)
+	}
+	headers := rt.NewObject()
+	for _, h := range r.headers {
+		if err = headers.Set(h.key, string(h.value)); err != nil {
+			return nil, err
+		}
+	}
+
+	msg := rt.NewObject()
+	for k, v := range map[string]interface{}{
+		"topic":     c.config.topic,
+		"partition": c.partition,
+		"offset":    r.offset,
+		"key":       toJSValue(rt, key),
+		"value":     toJSValue(rt, value),
+		"headers":   headers,
+		"timestamp": r.timestamp.UnixMilli(),
+	} {
+		if err = msg.Set(k, v); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
+// toJSValue converts the deserialized binary data to ArrayBuffer.
+func toJSValue(rt *goja.Runtime, v interface{}) interface{} {
+	if b, ok := v.([]byte); ok {
+		return rt.NewArrayBuffer(b)
+	}
+	return v
+}
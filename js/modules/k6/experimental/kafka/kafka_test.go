@@ -0,0 +1,321 @@
+package kafka
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/httpmultibin"
+	"go.k6.io/k6/metrics"
+)
+
+func newTestRuntime(t *testing.T) (*modulestest.Runtime, chan metrics.SampleContainer) {
+	t.Helper()
+	runtime := modulestest.NewRuntime(t)
+	mi, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, runtime.VU.Runtime().Set("kafka", mi.Exports().Named))
+
+	tb := httpmultibin.NewHTTPMultiBin(t)
+	registry := metrics.NewRegistry()
+	samples := make(chan metrics.SampleContainer, 1000)
+	runtime.MoveToVUContext(&lib.State{
+		Dialer:         tb.Dialer,
+		Transport:      tb.HTTPTransport,
+		TLSConfig:      tb.TLSClientConfig,
+		Samples:        samples,
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+	})
+	return runtime, samples
+}
+
+func TestProduceConsume(t *testing.T) {
+	t.Parallel()
+	runtime, samples := newTestRuntime(t)
+	_, addr := newTestBroker(t, "orders", 3)
+	require.NoError(t, runtime.VU.Runtime().Set("addr", addr))
+
+	_, err := runtime.RunOnEventLoop(`
+		var producer = new kafka.Producer({ brokers: [addr], topic: "orders", value: { format: "json" } });
+		var results = producer.produce([
+			{ key: "21", value: { id: 1 } },
+			{ key: "21", value: { id: 2 }, headers: { source: "k6" } },
+			{ value: { id: 3 }, partition: 2 },
+		]);
+		// the partition of the murmur2 hash of the key, like the other Kafka clients
+		if (JSON.stringify(results) !== '[{"partition":0,"offset":0},{"partition":0,"offset":1},{"partition":2,"offset":0}]') {
+			throw new Error("unexpected results: " + JSON.stringify(results));
+		}
+
+		var consumer = new kafka.Consumer({
+			brokers: [addr], topic: "orders", partition: 0, offset: "earliest", value: { format: "json" },
+			tags: { name: "consumer" },
+		});
+		var messages = consumer.consume({ limit: 10, timeout: "200ms" });
+		if (messages.length !== 2) {
+			throw new Error("unexpected number of messages: " + messages.length);
+		}
+		var m = messages[1];
+		if (m.topic !== "orders" || m.partition !== 0 || m.offset !== 1 || m.key !== "21" || m.value.id !== 2 ||
+			m.headers.source !== "k6" || !(m.timestamp > 0)) {
+			throw new Error("unexpected message: " + JSON.stringify(m));
+		}
+		if (consumer.consume({ timeout: "100ms" }).length !== 0) {
+			throw new Error("the messages were consumed twice");
+		}
+		producer.close();
+		consumer.close();
+	`)
+	require.NoError(t, err)
+
+	counts := map[string]float64{}
+	for _, c := range metrics.GetBufferedSamples(samples) {
+		for _, s := range c.GetSamples() {
+			counts[s.Metric.Name] += s.Value
+			topic, _ := s.Tags.Get("topic")
+			assert.Equal(t, "orders", topic)
+			if s.Metric.Name == "kafka_msgs_consumed" {
+				name, _ := s.Tags.Get("name")
+				assert.Equal(t, "consumer", name)
+			}
+		}
+	}
+	assert.Equal(t, float64(3), counts["kafka_msgs_produced"])
+	assert.Equal(t, float64(2), counts["kafka_msgs_consumed"])
+	assert.Greater(t, counts["kafka_bytes_produced"], float64(0))
+	assert.Greater(t, counts["kafka_bytes_consumed"], float64(0))
+	assert.Contains(t, counts, "kafka_produce_duration")
+	assert.Contains(t, counts, "kafka_consume_duration")
+	assert.Contains(t, counts, "kafka_consume_latency")
+}
+
+func TestConsumeLatest(t *testing.T) {
+	t.Parallel()
+	runtime, _ := newTestRuntime(t)
+	broker, addr := newTestBroker(t, "events", 1)
+	require.NoError(t, runtime.VU.Runtime().Set("addr", addr))
+
+	_, err := runtime.RunOnEventLoop(`
+		var producer = new kafka.Producer({ brokers: [addr], topic: "events", acks: 0, compression: "zstd" });
+		var results = producer.produce([{ value: "old" }]);
+		if (results[0].offset !== -1) {
+			throw new Error("unexpected offset with acks=0: " + results[0].offset);
+		}
+
+		var consumer = new kafka.Consumer({ brokers: [addr], topic: "events", value: { format: "binary" } });
+		if (consumer.consume({ timeout: "100ms" }).length !== 0) {
+			throw new Error("the old messages were consumed");
+		}
+		producer.produce([{ value: "new" }, { value: new Uint8Array([1, 2, 3]).buffer }]);
+		var messages = consumer.consume({ limit: 2 });
+		if (messages.length !== 2 || messages[0].offset !== 1 || new Uint8Array(messages[1].value).length !== 3) {
+			throw new Error("unexpected messages: " + JSON.stringify(messages));
+		}
+	`)
+	require.NoError(t, err)
+	broker.mx.Lock()
+	defer broker.mx.Unlock()
+	assert.Equal(t, []int16{0, 0}, broker.acks)
+}
+
+func TestSASL(t *testing.T) {
+	t.Parallel()
+	runtime, _ := newTestRuntime(t)
+	_, addr := startTestBroker(t, &testBroker{topic: "orders", partitions: 1, username: "k6", password: "secret"})
+	require.NoError(t, runtime.VU.Runtime().Set("addr", addr))
+
+	_, err := runtime.RunOnEventLoop(`
+		var producer = new kafka.Producer({
+			brokers: [addr], topic: "orders", sasl: { mechanism: "plain", username: "k6", password: "secret" },
+		});
+		producer.produce([{ value: "a" }]);
+	`)
+	require.NoError(t, err)
+
+	_, err = runtime.RunOnEventLoop(`
+		new kafka.Producer({
+			brokers: [addr], topic: "orders", sasl: { username: "k6", password: "wrong" },
+		}).produce([{ value: "a" }]);
+	`)
+	require.ErrorContains(t, err, "unable to authenticate to "+addr+
+		": the SASL authentication failed (error code 58): invalid credentials")
+}
+
+func newTestRegistry(t *testing.T, schemas map[string]map[string]interface{}) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		if user != "k6" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		schema, ok := schemas[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(schema)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestSchemaRegistry(t *testing.T) {
+	t.Parallel()
+	runtime, _ := newTestRuntime(t)
+	_, addr := newTestBroker(t, "orders", 1)
+
+	avroSchema := `{"type": "record", "name": "Order", "fields": [
+		{"name": "id", "type": "long"}, {"name": "note", "type": ["null", "string"], "default": null}
+	]}`
+	protoSchema := `syntax = "proto3"; package shop;
+		message Key { string id = 1; }
+		message Order { message Ref { string id = 1; } Ref ref = 1; }`
+	url := newTestRegistry(t, map[string]map[string]interface{}{
+		"/subjects/orders-value/versions/latest": {"id": 1, "schema": avroSchema},
+		"/schemas/ids/1":                         {"schema": avroSchema},
+		"/subjects/refs/versions/latest":         {"id": 2, "schema": protoSchema, "schemaType": "PROTOBUF"},
+		"/schemas/ids/2":                         {"schema": protoSchema, "schemaType": "PROTOBUF"},
+	})
+	require.NoError(t, runtime.VU.Runtime().Set("addr", addr))
+	require.NoError(t, runtime.VU.Runtime().Set("url", url))
+
+	_, err := runtime.RunOnEventLoop(`
+		var config = {
+			brokers: [addr], topic: "orders",
+			schemaRegistry: { url: url, username: "k6", password: "secret" },
+			key: { format: "protobuf", subject: "refs", messageType: "Order.Ref" },
+			value: { format: "avro" },
+		};
+		var producer = new kafka.Producer(config);
+		producer.produce([
+			{ key: { id: "a" }, value: { id: 1, note: "first" } },
+			{ key: { id: "b" }, value: { id: 2 } },
+		]);
+
+		var consumer = new kafka.Consumer(Object.assign({ offset: "earliest" }, config));
+		var messages = consumer.consume({ limit: 2 });
+		if (messages.length !== 2) {
+			throw new Error("unexpected number of messages: " + messages.length);
+		}
+		if (messages[0].key.id !== "a" || messages[0].value.id !== 1 || messages[0].value.note !== "first" ||
+			messages[1].key.id !== "b" || messages[1].value.id !== 2 || messages[1].value.note !== null) {
+			throw new Error("unexpected messages: " + JSON.stringify(messages));
+		}
+	`)
+	require.NoError(t, err)
+
+	_, err = runtime.RunOnEventLoop(`
+		new kafka.Producer({
+			brokers: [addr], topic: "orders", schemaRegistry: { url: url }, value: { format: "avro" },
+		}).produce([{ value: { id: 1 } }]);
+	`)
+	require.ErrorContains(t, err, "schema registry request failed with the status 401")
+}
+
+func TestProtobufMessageIndexes(t *testing.T) {
+	t.Parallel()
+
+	file, err := parseProtoSchema(`syntax = "proto3"; package shop;
+		message Key { string id = 1; }
+		message Order { message Ref { string id = 1; } Ref ref = 1; }`)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		messageType string
+		indexes     []byte
+	}{
+		{messageType: "", indexes: []byte{0}},
+		{messageType: "shop.Key", indexes: []byte{0}},
+		{messageType: "Order", indexes: []byte{2, 2}},
+		{messageType: "Order.Ref", indexes: []byte{4, 2, 0}},
+	}
+	for _, tc := range testCases {
+		c := serdeConfig{format: formatProtobuf, messageType: tc.messageType}
+		buf, err := c.serializeProto(nil, file, map[string]interface{}{})
+		require.NoError(t, err)
+		assert.Equal(t, tc.indexes, buf, tc.messageType)
+	}
+
+	_, err = findMessage(file, "Unknown")
+	assert.EqualError(t, err, "the message Unknown isn't defined in the Protobuf schema")
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		script string
+		err    string
+	}{
+		{
+			name:   "missing brokers",
+			script: `new kafka.Producer({ topic: "a" })`,
+			err:    "invalid Producer config: the brokers are required",
+		},
+		{
+			name:   "invalid acks",
+			script: `new kafka.Producer({ brokers: ["a:9092"], topic: "a", acks: 2 })`,
+			err:    "invalid Producer config: invalid acks value: it needs to be -1, 0 or 1",
+		},
+		{
+			name:   "unknown param",
+			script: `new kafka.Consumer({ brokers: ["a:9092"], topic: "a", acks: 1 })`,
+			err:    "invalid Consumer config: unknown param 'acks'",
+		},
+		{
+			name:   "invalid offset",
+			script: `new kafka.Consumer({ brokers: ["a:9092"], topic: "a", offset: "first" })`,
+			err:    "invalid Consumer config: invalid offset value: it needs to be earliest, latest or a number",
+		},
+		{
+			name:   "missing schema registry",
+			script: `new kafka.Consumer({ brokers: ["a:9092"], topic: "a", value: { format: "avro" } })`,
+			err:    "invalid Consumer config: the avro format requires the schemaRegistry param",
+		},
+		{
+			name:   "produce in the init context",
+			script: `new kafka.Producer({ brokers: ["a:9092"], topic: "a" }).produce([{ value: "a" }])`,
+			err:    "producing Kafka messages in the init context is not supported",
+		},
+		{
+			name:   "consume in the init context",
+			script: `new kafka.Consumer({ brokers: ["a:9092"], topic: "a" }).consume()`,
+			err:    "consuming Kafka messages in the init context is not supported",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			runtime := modulestest.NewRuntime(t)
+			mi, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+			require.True(t, ok)
+			require.NoError(t, runtime.VU.Runtime().Set("kafka", mi.Exports().Named))
+
+			_, err := runtime.VU.Runtime().RunString(tc.script)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestUnknownTopic(t *testing.T) {
+	t.Parallel()
+	runtime, _ := newTestRuntime(t)
+	_, addr := newTestBroker(t, "orders", 1)
+	require.NoError(t, runtime.VU.Runtime().Set("addr", addr))
+
+	_, err := runtime.RunOnEventLoop(`
+		new kafka.Producer({ brokers: [addr], topic: "unknown" }).produce([{ value: "a" }]);
+	`)
+	require.ErrorContains(t, err,
+		"unable to get the metadata of the topic 'unknown': the topic or partition doesn't exist (error code 3)")
+}
@@ -0,0 +1,50 @@
+package kafka
+
+import "go.k6.io/k6/metrics"
+
+// instanceMetrics contains the metrics of the Kafka producers and consumers.
+type instanceMetrics struct {
+	ProduceDuration  *metrics.Metric
+	MessagesProduced *metrics.Metric
+	BytesProduced    *metrics.Metric
+	ConsumeDuration  *metrics.Metric
+	MessagesConsumed *metrics.Metric
+	BytesConsumed    *metrics.Metric
+	ConsumeLatency   *metrics.Metric
+}
+
+// registerMetrics registers and returns the metrics in the provided registry
+func registerMetrics(registry *metrics.Registry) (*instanceMetrics, error) {
+	var err error
+	m := &instanceMetrics{}
+
+	if m.ProduceDuration, err = registry.NewMetric("kafka_produce_duration", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.MessagesProduced, err = registry.NewMetric("kafka_msgs_produced", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.BytesProduced, err = registry.NewMetric("kafka_bytes_produced", metrics.Counter, metrics.Data); err != nil {
+		return nil, err
+	}
+
+	if m.ConsumeDuration, err = registry.NewMetric("kafka_consume_duration", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.MessagesConsumed, err = registry.NewMetric("kafka_msgs_consumed", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.BytesConsumed, err = registry.NewMetric("kafka_bytes_consumed", metrics.Counter, metrics.Data); err != nil {
+		return nil, err
+	}
+
+	if m.ConsumeLatency, err = registry.NewMetric("kafka_consume_latency", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
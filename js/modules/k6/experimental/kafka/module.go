@@ -0,0 +1,82 @@
+// Package kafka implements the k6/experimental/kafka module, a Kafka client
+// for producing and consuming messages in the load tests of the
+// event-driven systems.
+//
+// It implements a subset of the Kafka protocol, for the brokers since Kafka 2.1:
+//   - the authentication is only with SASL/PLAIN, optionally over TLS;
+//   - the record batches can be compressed with gzip, snappy or zstd, but not lz4;
+//   - the consumers read a single partition, they aren't a part of a consumer
+//     group and their offsets aren't committed;
+//   - the transactions and the idempotent producers aren't supported.
+package kafka
+
+import (
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the JS module.
+	ModuleInstance struct {
+		vu      modules.VU
+		metrics *instanceMetrics
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new RootModule instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface to return
+// a new instance for each VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	m, err := registerMetrics(vu.InitEnv().Registry)
+	if err != nil {
+		common.Throw(vu.Runtime(), err)
+	}
+	return &ModuleInstance{vu: vu, metrics: m}
+}
+
+// Exports returns the exports of the kafka module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"Producer": mi.newProducer,
+			"Consumer": mi.newConsumer,
+		},
+	}
+}
+
+// newProducer is the constructor of the Producer, the producers can be
+// created in the init context, but they can produce only in the VU context.
+func (mi *ModuleInstance) newProducer(call goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+	p, err := newProducer(mi, call.Argument(0))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	return rt.ToValue(p).ToObject(rt)
+}
+
+// newConsumer is the constructor of the Consumer, the consumers can be
+// created in the init context, but they can consume only in the VU context.
+func (mi *ModuleInstance) newConsumer(call goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+	c, err := newConsumer(mi, call.Argument(0))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	return rt.ToValue(c).ToObject(rt)
+}
@@ -0,0 +1,231 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/metrics"
+)
+
+// Producer produces the messages to a topic, the messages of a produce call
+// are sent as one record batch per partition.
+type Producer struct {
+	client
+
+	acks        int16
+	compression int16
+	next        int32 // the next partition of the messages without key
+}
+
+// ProducedMessage is the partition and the offset of a produced message,
+// the offset is -1 if acks is 0.
+type ProducedMessage struct {
+	Partition int32 `js:"partition"`
+	Offset    int64 `js:"offset"`
+}
+
+// message is a message to produce, with its serialized key and value.
+type message struct {
+	partition *int32
+	record    record
+}
+
+func newProducer(mi *ModuleInstance, input goja.Value) (*Producer, error) {
+	rt := mi.vu.Runtime()
+	p := &Producer{client: client{mi: mi}, acks: -1, compression: compressionNone}
+	var err error
+	p.config, err = parseConfig(rt, input, func(k string, v goja.Value) (bool, error) {
+		switch k {
+		case "acks":
+			acks := v.ToInteger()
+			if acks < -1 || acks > 1 {
+				return true, errors.New("it needs to be -1, 0 or 1")
+			}
+			p.acks = int16(acks)
+		case "compression":
+			switch v.String() {
+			case "none":
+				p.compression = compressionNone
+			case "gzip":
+				p.compression = compressionGzip
+			case "snappy":
+				p.compression = compressionSnappy
+			case "zstd":
+				p.compression = compressionZstd
+			default:
+				return true, fmt.Errorf(
+					"unsupported compression '%s', it needs to be none, gzip, snappy or zstd", v.String())
+			}
+		default:
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid Producer config: %w", err)
+	}
+	return p, nil
+}
+
+// parseMessages parses and serializes the messages, which are objects with
+// the key, value, headers and partition properties.
+func (p *Producer) parseMessages(input goja.Value) ([]message, error) {
+	rt := p.mi.vu.Runtime()
+	var items []goja.Value
+	if common.IsNullish(input) {
+		return nil, errors.New("missing messages, expected an array of messages")
+	}
+	if err := rt.ExportTo(input, &items); err != nil {
+		return nil, fmt.Errorf("invalid messages, expected an array of messages: %w", err)
+	}
+
+	now := time.Now()
+	messages := make([]message, 0, len(items))
+	for i, item := range items {
+		if common.IsNullish(item) {
+			return nil, fmt.Errorf("invalid message %d", i)
+		}
+		obj := item.ToObject(rt)
+		m := message{record: record{timestamp: now}}
+		var err error
+		if v := obj.Get("key"); !common.IsNullish(v) {
+			if m.record.key, err = p.config.key.serialize(p.registry, v.Export()); err != nil {
+				return nil, fmt.Errorf("unable to serialize the key of the message %d: %w", i, err)
+			}
+		}
+		if v := obj.Get("value"); !common.IsNullish(v) {
+			if m.record.value, err = p.config.value.serialize(p.registry, v.Export()); err != nil {
+				return nil, fmt.Errorf("unable to serialize the value of the message %d: %w", i, err)
+			}
+		}
+		if v := obj.Get("headers"); !common.IsNullish(v) {
+			headers := v.ToObject(rt)
+			for _, k := range headers.Keys() {
+				value, err := common.ToBytes(headers.Get(k).Export())
+				if err != nil {
+					return nil, fmt.Errorf("invalid header '%s' of the message %d: %w", k, i, err)
+				}
+				m.record.headers = append(m.record.headers, header{key: k, value: value})
+			}
+		}
+		if v := obj.Get("partition"); !common.IsNullish(v) {
+			partition := int32(v.ToInteger())
+			m.partition = &partition
+		}
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+// Produce produces the messages, and it returns their partitions and offsets.
+// The messages are produced to their partition if it's set, else to the
+// partition of the hash of their key, like the other Kafka clients, or
+// to the partitions in turn if they don't have a key.
+func (p *Producer) Produce(input goja.Value) ([]ProducedMessage, error) { //nolint:funlen
+	state, tm, err := p.init("producing")
+	if err != nil {
+		return nil, err
+	}
+	messages, err := p.parseMessages(input)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(p.mi.vu.Context(), p.config.timeout)
+	defer cancel()
+	topic := p.config.topic
+	meta, err := p.cluster.metadata(ctx, topic, false)
+	if err != nil {
+		return nil, err
+	}
+	partitions := len(meta.topics[topic])
+
+	// the messages are grouped by partition, in the order of the first
+	// message of every partition
+	var order []int32
+	byPartition := make(map[int32][]int)
+	for i, m := range messages {
+		var partition int32
+		switch {
+		case m.partition != nil:
+			partition = *m.partition
+			if partition < 0 || int(partition) >= partitions {
+				return nil, fmt.Errorf("the topic '%s' doesn't have the partition %d", topic, partition)
+			}
+		case m.record.key != nil:
+			partition = partitionForKey(m.record.key, partitions)
+		default:
+			partition = p.next % int32(partitions)
+			p.next++
+		}
+		if _, ok := byPartition[partition]; !ok {
+			order = append(order, partition)
+		}
+		byPartition[partition] = append(byPartition[partition], i)
+	}
+
+	results := make([]ProducedMessage, len(messages))
+	for _, partition := range order {
+		indexes := byPartition[partition]
+		records := make([]record, len(indexes))
+		for i, index := range indexes {
+			records[i] = messages[index].record
+		}
+		batch, err := encodeRecordBatch(records, p.compression)
+		if err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		offset, err := p.produceBatch(ctx, partition, batch)
+		if err != nil {
+			return nil, fmt.Errorf("unable to produce to the partition %d of the topic '%s': %w", partition, topic, err)
+		}
+		end := time.Now()
+
+		for i, index := range indexes {
+			results[index] = ProducedMessage{Partition: partition, Offset: -1}
+			if offset >= 0 {
+				results[index].Offset = offset + int64(i)
+			}
+		}
+		p.push(state, p.mi.metrics.ProduceDuration, tm, metrics.D(end.Sub(start)))
+		p.push(state, p.mi.metrics.MessagesProduced, tm, float64(len(records)))
+		p.push(state, p.mi.metrics.BytesProduced, tm, float64(len(batch)))
+	}
+	return results, nil
+}
+
+// produceBatch produces the record batch to the leader of the partition, and
+// it returns the offset of the first record, which is -1 if acks is 0 as the
+// broker doesn't respond.
+func (p *Producer) produceBatch(ctx context.Context, partition int32, batch []byte) (int64, error) {
+	topic := p.config.topic
+	body := func(e *encoder) {
+		encodeProduceRequest(e, p.acks, timeoutMs(p.config.timeout), topic, partition, batch)
+	}
+	if p.acks == 0 {
+		b, err := p.cluster.leader(ctx, topic, partition)
+		if err != nil {
+			return -1, err
+		}
+		if err = b.send(ctx, apiProduce, versionProduce, body); err != nil {
+			p.cluster.closeConn(b.conn.RemoteAddr().String())
+			return -1, err
+		}
+		return -1, nil
+	}
+
+	var offset int64
+	err := p.cluster.request(ctx, topic, partition, apiProduce, versionProduce, body, func(d *decoder) error {
+		var err error
+		offset, err = decodeProduceResponse(d)
+		return err
+	})
+	return offset, err
+}
@@ -0,0 +1,374 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The keys and the versions of the requests of the Kafka protocol that are
+// used, the versions are the latest ones without the flexible encoding, which
+// are supported by all the brokers since Kafka 2.1. The Produce and the Fetch
+// requests need to be at least v7 and v10 for the zstd compressed batches.
+const (
+	apiProduce          int16 = 0
+	apiFetch            int16 = 1
+	apiListOffsets      int16 = 2
+	apiMetadata         int16 = 3
+	apiSaslHandshake    int16 = 17
+	apiSaslAuthenticate int16 = 36
+
+	versionProduce          int16 = 7
+	versionFetch            int16 = 10
+	versionListOffsets      int16 = 1
+	versionMetadata         int16 = 1
+	versionSaslHandshake    int16 = 1
+	versionSaslAuthenticate int16 = 0
+)
+
+// The special timestamps of the ListOffsets requests.
+const (
+	latestTimestamp   int64 = -1
+	earliestTimestamp int64 = -2
+)
+
+// kafkaErrors are the messages of the error codes that are the most likely.
+var kafkaErrors = map[int16]string{
+	1:  "the requested offset is out of range",
+	2:  "the message failed its CRC checksum",
+	3:  "the topic or partition doesn't exist",
+	5:  "there is no leader for the partition",
+	6:  "the broker isn't the leader of the partition",
+	7:  "the request timed out",
+	10: "the message is too large",
+	29: "the topic authorization failed",
+	33: "the SASL mechanism isn't supported",
+	58: "the SASL authentication failed",
+	76: "the compression type isn't supported",
+}
+
+// Error is an error code returned by the broker.
+type Error int16
+
+func (e Error) Error() string {
+	if msg, ok := kafkaErrors[int16(e)]; ok {
+		return fmt.Sprintf("%s (error code %d)", msg, int16(e))
+	}
+	return fmt.Sprintf("kafka error code %d", int16(e))
+}
+
+func errorCode(code int16) error {
+	if code == 0 {
+		return nil
+	}
+	return Error(code)
+}
+
+// encoder writes the fields of the requests in the wire format.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) int8(v int8)   { e.buf = append(e.buf, byte(v)) }
+func (e *encoder) int16(v int16) { e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(v)) }
+func (e *encoder) int32(v int32) { e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(v)) }
+func (e *encoder) int64(v int64) { e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(v)) }
+
+func (e *encoder) string(s string) {
+	e.int16(int16(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) nullableString(s *string) {
+	if s == nil {
+		e.int16(-1)
+		return
+	}
+	e.string(*s)
+}
+
+func (e *encoder) bytes(b []byte) {
+	if b == nil {
+		e.int32(-1)
+		return
+	}
+	e.int32(int32(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) arrayLen(n int) { e.int32(int32(n)) }
+
+func (e *encoder) varint(v int64) { e.buf = binary.AppendVarint(e.buf, v) }
+
+func (e *encoder) varintBytes(b []byte) {
+	if b == nil {
+		e.varint(-1)
+		return
+	}
+	e.varint(int64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// decoder reads the fields of the responses, the first error is kept in err
+// and the next reads return zero values.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.buf) < n {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) int8() int8 {
+	if b := d.next(1); b != nil {
+		return int8(b[0])
+	}
+	return 0
+}
+
+func (d *decoder) int16() int16 {
+	if b := d.next(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (d *decoder) int32() int32 {
+	if b := d.next(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *decoder) int64() int64 {
+	if b := d.next(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (d *decoder) bool() bool {
+	return d.int8() != 0
+}
+
+func (d *decoder) string() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.next(int(n)))
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.int32()
+	if n < 0 {
+		return nil
+	}
+	return d.next(int(n))
+}
+
+// arrayLen returns the length of an array, the null arrays are empty.
+func (d *decoder) arrayLen() int {
+	n := int(d.int32())
+	if n < 0 {
+		return 0
+	}
+	if n > len(d.buf) {
+		// every item is at least one byte long
+		d.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	return n
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.err = errors.New("malformed varint")
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) varintBytes() []byte {
+	n := d.varint()
+	if n < 0 {
+		return nil
+	}
+	return d.next(int(n))
+}
+
+// partitionMetadata is the metadata of a partition of a topic.
+type partitionMetadata struct {
+	index  int32
+	leader int32
+	err    error
+}
+
+// metadata is the response of the Metadata request.
+type metadata struct {
+	brokers map[int32]string
+	topics  map[string][]partitionMetadata
+	errors  map[string]error
+}
+
+func encodeMetadataRequest(e *encoder, topics []string) {
+	e.arrayLen(len(topics))
+	for _, t := range topics {
+		e.string(t)
+	}
+}
+
+func decodeMetadataResponse(d *decoder) *metadata {
+	m := &metadata{
+		brokers: make(map[int32]string),
+		topics:  make(map[string][]partitionMetadata),
+		errors:  make(map[string]error),
+	}
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		id := d.int32()
+		host := d.string()
+		port := d.int32()
+		d.string() // rack
+		m.brokers[id] = fmt.Sprintf("%s:%d", host, port)
+	}
+	d.int32() // controller id
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		code := d.int16()
+		name := d.string()
+		d.bool() // is internal
+		var partitions []partitionMetadata
+		for j, pn := 0, d.arrayLen(); j < pn; j++ {
+			p := partitionMetadata{err: errorCode(d.int16()), index: d.int32(), leader: d.int32()}
+			for k, rn := 0, d.arrayLen(); k < rn; k++ {
+				d.int32() // replicas
+			}
+			for k, in := 0, d.arrayLen(); k < in; k++ {
+				d.int32() // in-sync replicas
+			}
+			partitions = append(partitions, p)
+		}
+		m.topics[name] = partitions
+		if err := errorCode(code); err != nil {
+			m.errors[name] = err
+		}
+	}
+	return m
+}
+
+func encodeProduceRequest(e *encoder, acks int16, timeoutMs int32, topic string, partition int32, records []byte) {
+	e.nullableString(nil) // transactional id
+	e.int16(acks)
+	e.int32(timeoutMs)
+	e.arrayLen(1)
+	e.string(topic)
+	e.arrayLen(1)
+	e.int32(partition)
+	e.bytes(records)
+}
+
+// decodeProduceResponse returns the base offset of the produced records.
+func decodeProduceResponse(d *decoder) (int64, error) {
+	var offset int64
+	var err error
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		d.string() // topic
+		for j, pn := 0, d.arrayLen(); j < pn; j++ {
+			d.int32() // partition
+			if e := errorCode(d.int16()); e != nil {
+				err = e
+			}
+			offset = d.int64()
+			d.int64() // log append time
+			d.int64() // log start offset
+		}
+	}
+	d.int32() // throttle time
+	return offset, err
+}
+
+func encodeFetchRequest(
+	e *encoder, maxWaitMs, minBytes, maxBytes int32, topic string, partition int32, offset int64,
+) {
+	e.int32(-1) // replica id
+	e.int32(maxWaitMs)
+	e.int32(minBytes)
+	e.int32(maxBytes)
+	e.int8(0)   // read uncommitted
+	e.int32(0)  // session id, the fetch sessions aren't used
+	e.int32(-1) // session epoch
+	e.arrayLen(1)
+	e.string(topic)
+	e.arrayLen(1)
+	e.int32(partition)
+	e.int32(-1) // current leader epoch
+	e.int64(offset)
+	e.int64(-1) // log start offset
+	e.int32(maxBytes)
+	e.arrayLen(0) // forgotten topics
+}
+
+// decodeFetchResponse returns the record batches of the partition.
+func decodeFetchResponse(d *decoder) ([]byte, error) {
+	var records []byte
+	d.int32() // throttle time
+	err := errorCode(d.int16())
+	d.int32() // session id
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		d.string() // topic
+		for j, pn := 0, d.arrayLen(); j < pn; j++ {
+			d.int32() // partition
+			if e := errorCode(d.int16()); e != nil {
+				err = e
+			}
+			d.int64() // high watermark
+			d.int64() // last stable offset
+			d.int64() // log start offset
+			for k, an := 0, d.arrayLen(); k < an; k++ {
+				d.int64() // producer id
+				d.int64() // first offset
+			}
+			records = d.bytes()
+		}
+	}
+	return records, err
+}
+
+func encodeListOffsetsRequest(e *encoder, topic string, partition int32, timestamp int64) {
+	e.int32(-1) // replica id
+	e.arrayLen(1)
+	e.string(topic)
+	e.arrayLen(1)
+	e.int32(partition)
+	e.int64(timestamp)
+}
+
+func decodeListOffsetsResponse(d *decoder) (int64, error) {
+	var offset int64
+	var err error
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		d.string() // topic
+		for j, pn := 0, d.arrayLen(); j < pn; j++ {
+			d.int32() // partition
+			err = errorCode(d.int16())
+			d.int64() // timestamp
+			offset = d.int64()
+		}
+	}
+	return offset, err
+}
@@ -0,0 +1,290 @@
+package kafka
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// The compression codecs of the record batches, lz4 isn't supported.
+const (
+	compressionNone   int16 = 0
+	compressionGzip   int16 = 1
+	compressionSnappy int16 = 2
+	compressionZstd   int16 = 4
+
+	compressionMask  int16 = 0x07
+	controlBatchAttr int16 = 0x20
+	recordBatchMagic int8  = 2
+)
+
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+// header is a header of a record.
+type header struct {
+	key   string
+	value []byte
+}
+
+// record is a message of a topic.
+type record struct {
+	offset    int64
+	timestamp time.Time
+	key       []byte
+	value     []byte
+	headers   []header
+}
+
+// encodeRecordBatch encodes the records as a record batch, the format of the
+// messages since Kafka 0.11, with the compression codec.
+func encodeRecordBatch(records []record, compression int16) ([]byte, error) {
+	var firstTimestamp, maxTimestamp int64
+	for i, r := range records {
+		ts := r.timestamp.UnixMilli()
+		if i == 0 {
+			firstTimestamp = ts
+		}
+		if ts > maxTimestamp {
+			maxTimestamp = ts
+		}
+	}
+
+	body := encoder{}
+	for i, r := range records {
+		rec := encoder{}
+		rec.int8(0) // attributes
+		rec.varint(r.timestamp.UnixMilli() - firstTimestamp)
+		rec.varint(int64(i))
+		rec.varintBytes(r.key)
+		rec.varintBytes(r.value)
+		rec.varint(int64(len(r.headers)))
+		for _, h := range r.headers {
+			rec.varintBytes([]byte(h.key))
+			rec.varintBytes(h.value)
+		}
+		body.varint(int64(len(rec.buf)))
+		body.buf = append(body.buf, rec.buf...)
+	}
+
+	recordsData, err := compress(body.buf, compression)
+	if err != nil {
+		return nil, err
+	}
+
+	// the part of the batch that the CRC is computed for
+	crcPart := encoder{}
+	crcPart.int16(compression)
+	crcPart.int32(int32(len(records) - 1)) // last offset delta
+	crcPart.int64(firstTimestamp)
+	crcPart.int64(maxTimestamp)
+	crcPart.int64(-1) // producer id
+	crcPart.int16(-1) // producer epoch
+	crcPart.int32(-1) // base sequence
+	crcPart.int32(int32(len(records)))
+	crcPart.buf = append(crcPart.buf, recordsData...)
+
+	batch := encoder{}
+	batch.int64(0) // base offset
+	batch.int32(int32(4 + 1 + 4 + len(crcPart.buf)))
+	batch.int32(-1) // partition leader epoch
+	batch.int8(recordBatchMagic)
+	batch.int32(int32(crc32.Checksum(crcPart.buf, crc32c)))
+	batch.buf = append(batch.buf, crcPart.buf...)
+	return batch.buf, nil
+}
+
+// decodeRecordBatches decodes the records of the batches, the partial batch
+// that can be at the end of the fetched data is ignored.
+func decodeRecordBatches(data []byte) ([]record, error) { //nolint:cyclop
+	var records []record
+	for len(data) >= 12 {
+		d := &decoder{buf: data}
+		baseOffset := d.int64()
+		length := int(d.int32())
+		if len(d.buf) < length {
+			break
+		}
+		data = d.buf[length:]
+		d.buf = d.buf[:length]
+
+		d.int32() // partition leader epoch
+		if magic := d.int8(); magic != recordBatchMagic {
+			return nil, fmt.Errorf("unsupported message format version %d, only the record batches are supported", magic)
+		}
+		crc := uint32(d.int32())
+		if d.err == nil && crc32.Checksum(d.buf, crc32c) != crc {
+			return nil, errors.New("the record batch failed its CRC checksum")
+		}
+		attributes := d.int16()
+		d.int32() // last offset delta
+		firstTimestamp := d.int64()
+		d.int64() // max timestamp
+		d.int64() // producer id
+		d.int16() // producer epoch
+		d.int32() // base sequence
+		count := int(d.int32())
+		if d.err != nil {
+			return nil, fmt.Errorf("malformed record batch: %w", d.err)
+		}
+		if attributes&controlBatchAttr != 0 {
+			continue
+		}
+
+		recordsData, err := decompress(d.buf, attributes&compressionMask)
+		if err != nil {
+			return nil, err
+		}
+
+		rd := &decoder{buf: recordsData}
+		for i := 0; i < count && rd.err == nil; i++ {
+			rd.varint() // length
+			rd.int8()   // attributes
+			r := record{}
+			r.timestamp = time.UnixMilli(firstTimestamp + rd.varint())
+			r.offset = baseOffset + rd.varint()
+			r.key = rd.varintBytes()
+			r.value = rd.varintBytes()
+			for j, hn := 0, int(rd.varint()); j < hn && rd.err == nil; j++ {
+				r.headers = append(r.headers, header{key: string(rd.varintBytes()), value: rd.varintBytes()})
+			}
+			records = append(records, r)
+		}
+		if rd.err != nil {
+			return nil, fmt.Errorf("malformed record: %w", rd.err)
+		}
+	}
+	return records, nil
+}
+
+// xerialHeader is the header of the snappy framing of the Java clients, which
+// can be used by the brokers and the other clients for the record batches.
+var xerialHeader = []byte{0x82, 'S', 'N', 'A', 'P', 'P', 'Y', 0}
+
+// compress compresses the records of a batch with the compression codec.
+func compress(data []byte, compression int16) ([]byte, error) {
+	switch compression {
+	case compressionNone:
+		return data, nil
+	case compressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case compressionSnappy:
+		// the plain snappy blocks are read by the Java clients as well
+		return snappy.Encode(nil, data), nil
+	case compressionZstd:
+		enc, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = enc.Close() }()
+		return enc.EncodeAll(data, nil), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression codec %d", compression)
+	}
+}
+
+// decompress decompresses the records of a batch with the compression codec.
+func decompress(data []byte, compression int16) ([]byte, error) {
+	switch compression {
+	case compressionNone:
+		return data, nil
+	case compressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(r)
+	case compressionSnappy:
+		if !bytes.HasPrefix(data, xerialHeader) {
+			return snappy.Decode(nil, data)
+		}
+		// the header is followed by the version and the compatible version,
+		// and then by the blocks, each one prefixed with its length
+		d := &decoder{buf: data[len(xerialHeader):]}
+		d.int32()
+		d.int32()
+		var out []byte
+		for d.err == nil && len(d.buf) > 0 {
+			block := d.bytes()
+			if d.err != nil {
+				break
+			}
+			decoded, err := snappy.Decode(nil, block)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, decoded...)
+		}
+		if d.err != nil {
+			return nil, fmt.Errorf("malformed snappy framing: %w", d.err)
+		}
+		return out, nil
+	case compressionZstd:
+		dec, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		defer dec.Close()
+		return dec.DecodeAll(data, nil)
+	default:
+		return nil, fmt.Errorf("unsupported compression codec %d of the record batch", compression)
+	}
+}
+
+// murmur2 is the hash function of the default partitioner of the Kafka
+// clients, so the messages with the same key are produced to the same
+// partitions as by the other clients.
+func murmur2(data []byte) int32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+	length := len(data)
+	h := seed ^ uint32(length)
+	for i := 0; i+4 <= length; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+	rest := data[length&^3:]
+	switch len(rest) {
+	case 3:
+		h ^= uint32(rest[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(rest[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(rest[0])
+		h *= m
+	}
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return int32(h)
+}
+
+// partitionForKey returns the partition of the key, like the default
+// partitioner of the Kafka clients.
+func partitionForKey(key []byte, partitions int) int32 {
+	return (murmur2(key) & 0x7fffffff) % int32(partitions)
+}
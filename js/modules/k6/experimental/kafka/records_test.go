@@ -0,0 +1,103 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordBatch(t *testing.T) {
+	t.Parallel()
+
+	ts := time.UnixMilli(1690000000000)
+	records := []record{
+		{timestamp: ts, key: []byte("k1"), value: []byte("v1")},
+		{timestamp: ts.Add(time.Second), value: []byte("v2"), headers: []header{{key: "h", value: []byte("x")}}},
+		{timestamp: ts.Add(2 * time.Second), key: []byte("k3")},
+	}
+	compressions := map[string]int16{
+		"none":   compressionNone,
+		"gzip":   compressionGzip,
+		"snappy": compressionSnappy,
+		"zstd":   compressionZstd,
+	}
+	for name, compression := range compressions {
+		compression := compression
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			batch, err := encodeRecordBatch(records, compression)
+			require.NoError(t, err)
+
+			// two batches and a partial one, like in the fetch responses
+			data := append(append(append([]byte{}, batch...), batch...), batch[:20]...)
+			decoded, err := decodeRecordBatches(data)
+			require.NoError(t, err)
+			require.Len(t, decoded, 6)
+			for i, r := range decoded {
+				expected := records[i%3]
+				expected.offset = int64(i % 3)
+				assert.Equal(t, expected, r)
+			}
+		})
+	}
+
+	t.Run("xerial snappy", func(t *testing.T) {
+		t.Parallel()
+		batch, err := encodeRecordBatch(records, compressionNone)
+		require.NoError(t, err)
+
+		// the records are split in two snappy blocks, in the framing of the Java clients
+		recordsData := batch[61:] // after the header of the batch
+		framed := append([]byte{}, xerialHeader...)
+		framed = binary.BigEndian.AppendUint32(framed, 1) // version
+		framed = binary.BigEndian.AppendUint32(framed, 1) // compatible version
+		for _, part := range [][]byte{recordsData[:10], recordsData[10:]} {
+			block := snappy.Encode(nil, part)
+			framed = binary.BigEndian.AppendUint32(framed, uint32(len(block)))
+			framed = append(framed, block...)
+		}
+		decompressed, err := decompress(framed, compressionSnappy)
+		require.NoError(t, err)
+		assert.Equal(t, recordsData, decompressed)
+
+		_, err = decompress(framed[:len(framed)-1], compressionSnappy)
+		assert.EqualError(t, err, "malformed snappy framing: unexpected EOF")
+	})
+
+	t.Run("unsupported compression", func(t *testing.T) {
+		t.Parallel()
+		_, err := decompress([]byte{1}, 3)
+		assert.EqualError(t, err, "unsupported compression codec 3 of the record batch")
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+		t.Parallel()
+		batch, err := encodeRecordBatch(records, compressionNone)
+		require.NoError(t, err)
+		batch[len(batch)-1] ^= 0xff
+		_, err = decodeRecordBatches(batch)
+		assert.EqualError(t, err, "the record batch failed its CRC checksum")
+	})
+}
+
+func TestMurmur2(t *testing.T) {
+	t.Parallel()
+
+	// the values of the tests of the Java client
+	testCases := map[string]int32{
+		"21":                         -973932308,
+		"foobar":                     -790332482,
+		"a-little-bit-long-string":   -985981536,
+		"a-little-bit-longer-string": -1486304829,
+		"lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8": -58897971,
+		"abc": 479470107,
+	}
+	for key, expected := range testCases {
+		assert.Equal(t, expected, murmur2([]byte(key)), key)
+	}
+	assert.Equal(t, int32((-973932308&0x7fffffff)%6), partitionForKey([]byte("21"), 6))
+}
@@ -0,0 +1,320 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"go.k6.io/k6/js/common"
+)
+
+// The formats of the keys and the values of the messages.
+const (
+	formatString   = "string"
+	formatBinary   = "binary"
+	formatJSON     = "json"
+	formatAvro     = "avro"
+	formatProtobuf = "protobuf"
+)
+
+// schemaRegistryMagic is the first byte of the messages that are serialized
+// with a schema of the registry, it's followed by the ID of the schema.
+const schemaRegistryMagic = 0
+
+// serdeConfig is the config of the serialization of the keys or the values.
+type serdeConfig struct {
+	format string
+	// subject is the subject of the schema in the registry, for the producers
+	subject string
+	// messageType is the name of the Protobuf message, the first message of
+	// the schema is used if it's empty
+	messageType string
+}
+
+// registrySchema is a schema of the schema registry.
+type registrySchema struct {
+	id   int32
+	avro *avroSchema
+	file protoreflect.FileDescriptor
+}
+
+// schemaRegistry is a client of the Confluent schema registry API, the
+// schemas are cached for the whole life of the producers and consumers.
+type schemaRegistry struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+
+	mx        sync.Mutex
+	bySubject map[string]*registrySchema
+	byID      map[int32]*registrySchema
+}
+
+func (r *schemaRegistry) get(path string) (*registrySchema, error) {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(r.url, "/")+path, nil) //nolint:noctx
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("schema registry request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("schema registry request failed with the status %d: %s", resp.StatusCode, body)
+	}
+
+	var result struct {
+		ID         int32  `json:"id"`
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType"`
+	}
+	if err = json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("invalid response of the schema registry: %w", err)
+	}
+	s := &registrySchema{id: result.ID}
+	switch result.SchemaType {
+	case "", "AVRO":
+		s.avro, err = parseAvroSchema(result.Schema)
+	case "PROTOBUF":
+		s.file, err = parseProtoSchema(result.Schema)
+	default:
+		err = fmt.Errorf("unsupported schema type %s", result.SchemaType)
+	}
+	return s, err
+}
+
+// latest returns the latest version of the schema of the subject.
+func (r *schemaRegistry) latest(subject string) (*registrySchema, error) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if s, ok := r.bySubject[subject]; ok {
+		return s, nil
+	}
+	s, err := r.get("/subjects/" + url.PathEscape(subject) + "/versions/latest")
+	if err != nil {
+		return nil, err
+	}
+	r.bySubject[subject] = s
+	r.byID[s.id] = s
+	return s, nil
+}
+
+// schema returns the schema with the ID.
+func (r *schemaRegistry) schema(id int32) (*registrySchema, error) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if s, ok := r.byID[id]; ok {
+		return s, nil
+	}
+	s, err := r.get("/schemas/ids/" + strconv.Itoa(int(id)))
+	if err != nil {
+		return nil, err
+	}
+	s.id = id
+	r.byID[id] = s
+	return s, nil
+}
+
+func parseProtoSchema(schema string) (protoreflect.FileDescriptor, error) {
+	parser := protoparse.Parser{
+		Accessor: protoparse.FileContentsFromMap(map[string]string{"schema.proto": schema}),
+	}
+	fds, err := parser.ParseFiles("schema.proto")
+	if err != nil {
+		return nil, fmt.Errorf("invalid Protobuf schema: %w", err)
+	}
+	return fds[0].UnwrapFile(), nil
+}
+
+// serialize serializes the key or the value, as it's exported from JS.
+func (c serdeConfig) serialize(registry *schemaRegistry, value interface{}) ([]byte, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch c.format {
+	case formatString, formatBinary:
+		return common.ToBytes(value)
+	case formatJSON:
+		return json.Marshal(value)
+	case formatAvro, formatProtobuf:
+		s, err := registry.latest(c.subject)
+		if err != nil {
+			return nil, err
+		}
+		buf := []byte{schemaRegistryMagic}
+		buf = binary.BigEndian.AppendUint32(buf, uint32(s.id))
+		if c.format == formatAvro {
+			if s.avro == nil {
+				return nil, fmt.Errorf("the schema of the subject '%s' isn't an Avro schema", c.subject)
+			}
+			return s.avro.encode(buf, value)
+		}
+		if s.file == nil {
+			return nil, fmt.Errorf("the schema of the subject '%s' isn't a Protobuf schema", c.subject)
+		}
+		return c.serializeProto(buf, s.file, value)
+	default:
+		return nil, fmt.Errorf("unsupported format '%s'", c.format)
+	}
+}
+
+func (c serdeConfig) serializeProto(buf []byte, file protoreflect.FileDescriptor, value interface{}) ([]byte, error) {
+	md, err := findMessage(file, c.messageType)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	msg := dynamicpb.NewMessage(md)
+	if err = protojson.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("unable to serialize the %s message: %w", md.FullName(), err)
+	}
+
+	// the message indexes are the path of the message in the schema, with
+	// the [0] path of the first message encoded as a single 0
+	var indexes []int
+	for d := protoreflect.Descriptor(md); d != nil; d = d.Parent() {
+		if m, ok := d.(protoreflect.MessageDescriptor); ok {
+			indexes = append([]int{m.Index()}, indexes...)
+		}
+	}
+	if len(indexes) == 1 && indexes[0] == 0 {
+		buf = append(buf, 0)
+	} else {
+		buf = binary.AppendVarint(buf, int64(len(indexes)))
+		for _, i := range indexes {
+			buf = binary.AppendVarint(buf, int64(i))
+		}
+	}
+	return proto.MarshalOptions{}.MarshalAppend(buf, msg)
+}
+
+func findMessage(file protoreflect.FileDescriptor, name string) (protoreflect.MessageDescriptor, error) {
+	if file.Messages().Len() == 0 {
+		return nil, errors.New("the Protobuf schema doesn't have any message")
+	}
+	if name == "" {
+		return file.Messages().Get(0), nil
+	}
+	fullName := protoreflect.FullName(name)
+	if pkg := file.Package(); pkg != "" && !strings.HasPrefix(name, string(pkg)+".") {
+		fullName = pkg.Append(protoreflect.Name(name))
+	}
+	if md := findNestedMessage(file.Messages(), fullName); md != nil {
+		return md, nil
+	}
+	return nil, fmt.Errorf("the message %s isn't defined in the Protobuf schema", name)
+}
+
+func findNestedMessage(messages protoreflect.MessageDescriptors, name protoreflect.FullName) protoreflect.MessageDescriptor {
+	if md := messages.ByName(name.Name()); md != nil && md.FullName() == name {
+		return md
+	}
+	for i := 0; i < messages.Len(); i++ {
+		if md := findNestedMessage(messages.Get(i).Messages(), name); md != nil {
+			return md
+		}
+	}
+	return nil
+}
+
+// deserialize deserializes the key or the value, the binary ones are
+// returned as []byte, so they can be converted to ArrayBuffer.
+func (c serdeConfig) deserialize(registry *schemaRegistry, data []byte) (interface{}, error) { //nolint:cyclop
+	if data == nil {
+		return nil, nil
+	}
+	switch c.format {
+	case formatString:
+		return string(data), nil
+	case formatBinary:
+		return data, nil
+	case formatJSON:
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return v, nil
+	case formatAvro, formatProtobuf:
+		if len(data) < 5 || data[0] != schemaRegistryMagic {
+			return nil, errors.New("the data isn't serialized with a schema of the registry")
+		}
+		s, err := registry.schema(int32(binary.BigEndian.Uint32(data[1:5])))
+		if err != nil {
+			return nil, err
+		}
+		d := &decoder{buf: data[5:]}
+		if s.avro != nil {
+			v := s.avro.decode(d)
+			if d.err != nil {
+				return nil, fmt.Errorf("invalid Avro data: %w", d.err)
+			}
+			return v, nil
+		}
+		return deserializeProto(d, s.file)
+	default:
+		return nil, fmt.Errorf("unsupported format '%s'", c.format)
+	}
+}
+
+func deserializeProto(d *decoder, file protoreflect.FileDescriptor) (interface{}, error) {
+	indexes := []int64{0}
+	if n := d.varint(); n > 0 {
+		indexes = indexes[:0]
+		for i := int64(0); i < n && d.err == nil; i++ {
+			indexes = append(indexes, d.varint())
+		}
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("invalid message indexes: %w", d.err)
+	}
+
+	messages := file.Messages()
+	var md protoreflect.MessageDescriptor
+	for _, i := range indexes {
+		if i < 0 || int(i) >= messages.Len() {
+			return nil, fmt.Errorf("invalid message index %d", i)
+		}
+		md = messages.Get(int(i))
+		messages = md.Messages()
+	}
+
+	msg := dynamicpb.NewMessage(md)
+	if err := proto.Unmarshal(d.buf, msg); err != nil {
+		return nil, fmt.Errorf("invalid %s message: %w", md.FullName(), err)
+	}
+	data, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err = json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
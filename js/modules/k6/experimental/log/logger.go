@@ -0,0 +1,109 @@
+package log
+
+import (
+	"github.com/dop251/goja"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// Logger is a logger of a VU.
+type Logger struct {
+	mi       *ModuleInstance
+	output   *output
+	level    logrus.Level
+	throttle *throttle
+	fields   logrus.Fields
+}
+
+// throttle limits the rate of the messages of a logger and of its children,
+// and counts the dropped messages.
+type throttle struct {
+	limiter *rate.Limiter
+	dropped int
+}
+
+// Debug logs the message at the debug level, with the optional fields.
+func (l *Logger) Debug(msg string, fields goja.Value) {
+	l.log(logrus.DebugLevel, msg, fields)
+}
+
+// Info logs the message at the info level, with the optional fields.
+func (l *Logger) Info(msg string, fields goja.Value) {
+	l.log(logrus.InfoLevel, msg, fields)
+}
+
+// Warn logs the message at the warn level, with the optional fields.
+func (l *Logger) Warn(msg string, fields goja.Value) {
+	l.log(logrus.WarnLevel, msg, fields)
+}
+
+// Error logs the message at the error level, with the optional fields.
+func (l *Logger) Error(msg string, fields goja.Value) {
+	l.log(logrus.ErrorLevel, msg, fields)
+}
+
+// With returns a child logger, which logs the fields with all the messages,
+// and shares the output, level and rate limit of the logger.
+func (l *Logger) With(fields goja.Value) *Logger {
+	child := *l
+	child.fields = l.withFields(exportFields(l.mi.vu.Runtime(), fields))
+	return &child
+}
+
+func (l *Logger) withFields(fields logrus.Fields) logrus.Fields {
+	merged := make(logrus.Fields, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return merged
+}
+
+func (l *Logger) log(level logrus.Level, msg string, fieldsValue goja.Value) {
+	if level > l.level {
+		return
+	}
+	if !l.throttle.limiter.Allow() {
+		l.throttle.dropped++
+		return
+	}
+
+	fields := l.withFields(exportFields(l.mi.vu.Runtime(), fieldsValue))
+	for k, v := range l.mi.vuFields() {
+		fields[k] = v
+	}
+	// the count of the dropped messages is logged with the next message
+	if l.throttle.dropped > 0 {
+		fields["dropped"] = l.throttle.dropped
+		l.throttle.dropped = 0
+	}
+	fields["source"] = "log"
+
+	if l.output != nil {
+		l.mi.root.outputs.log(l.output, level, fields, msg)
+		return
+	}
+	var logger logrus.FieldLogger
+	if state := l.mi.vu.State(); state != nil {
+		logger = state.Logger
+	} else if initEnv := l.mi.vu.InitEnv(); initEnv != nil {
+		logger = initEnv.Logger
+	}
+	if logger == nil {
+		return
+	}
+
+	entry := logger.WithFields(fields)
+	switch level { //nolint:exhaustive
+	case logrus.DebugLevel:
+		entry.Debug(msg)
+	case logrus.InfoLevel:
+		entry.Info(msg)
+	case logrus.WarnLevel:
+		entry.Warn(msg)
+	case logrus.ErrorLevel:
+		entry.Error(msg)
+	}
+}
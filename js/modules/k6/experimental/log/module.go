@@ -0,0 +1,220 @@
+// Package log implements a k6 JS module for leveled, structured logging, so
+// that scripts can log key-value fields tagged with the scenario, VU and
+// iteration, rate-limited per VU, and routed to a file or to Loki
+// independently of the console.
+package log
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/dop251/goja"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+
+	"go.k6.io/k6/event"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+)
+
+const (
+	// defaultRateLimit is the number of messages per second that each logger
+	// of a VU logs by default, the others are dropped.
+	defaultRateLimit = 10
+
+	// consoleOutput is the output of the k6 logger, where console.log logs.
+	consoleOutput = "console"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct {
+		exitOnce sync.Once
+		outputs  *outputs
+	}
+
+	// ModuleInstance represents an instance of the JS module.
+	ModuleInstance struct {
+		vu            modules.VU
+		root          *RootModule
+		defaultLogger *Logger
+	}
+)
+
+// Ensure the interfaces are implemented correctly
+var (
+	_ modules.Instance = &ModuleInstance{}
+	_ modules.Module   = &RootModule{}
+)
+
+// New returns a pointer to a new RootModule instance
+func New() *RootModule {
+	return &RootModule{outputs: newOutputs(fsext.NewOsFs())}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns
+// a new instance for each VU.
+func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	rm.exitOnce.Do(func() {
+		if vu.Events().Global == nil {
+			return
+		}
+		// the outputs are flushed and closed at the exit of k6, so that the
+		// messages logged in the teardown aren't lost
+		sid, evtCh := vu.Events().Global.Subscribe(event.Exit)
+		go func() {
+			for evt := range evtCh {
+				rm.outputs.close()
+				evt.Done()
+				vu.Events().Global.Unsubscribe(sid)
+			}
+			rm.outputs.close()
+		}()
+	})
+
+	mi := &ModuleInstance{vu: vu, root: rm}
+	mi.defaultLogger = mi.newLogger(&loggerOptions{
+		output:    consoleOutput,
+		level:     logrus.InfoLevel,
+		rateLimit: defaultRateLimit,
+	})
+	return mi
+}
+
+// Exports implements the modules.Instance interface and returns
+// the exports of the JS module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"debug":  mi.defaultLogger.Debug,
+			"info":   mi.defaultLogger.Info,
+			"warn":   mi.defaultLogger.Warn,
+			"error":  mi.defaultLogger.Error,
+			"with":   mi.defaultLogger.With,
+			"logger": mi.logger,
+		},
+	}
+}
+
+// loggerOptions are the options of a logger.
+type loggerOptions struct {
+	output    string
+	level     logrus.Level
+	rateLimit float64
+	fields    logrus.Fields
+}
+
+// logger returns a new logger with the options, which are output, the
+// destination of the messages, either "console", "none" or a file or Loki
+// output configured like the --log-output option, e.g. "file=./debug.log",
+// level, the minimum level of the logged messages, rateLimit, the number of
+// messages logged per second by the VU, or 0 to log all of them, and fields,
+// the fields of all the messages.
+func (mi *ModuleInstance) logger(options goja.Value) *Logger {
+	rt := mi.vu.Runtime()
+	opts := &loggerOptions{output: consoleOutput, level: logrus.InfoLevel, rateLimit: defaultRateLimit}
+	if !common.IsNullish(options) {
+		obj := options.ToObject(rt)
+		for _, key := range obj.Keys() {
+			v := obj.Get(key)
+			switch key {
+			case "output":
+				opts.output = v.String()
+			case "level":
+				level, err := parseLevel(v.String())
+				if err != nil {
+					common.Throw(rt, err)
+				}
+				opts.level = level
+			case "rateLimit":
+				opts.rateLimit = v.ToFloat()
+				if math.IsNaN(opts.rateLimit) || opts.rateLimit < 0 {
+					common.Throw(rt, fmt.Errorf("the rateLimit must be a positive number, not %s", v))
+				}
+			case "fields":
+				opts.fields = exportFields(rt, v)
+			default:
+				common.Throw(rt, fmt.Errorf("unknown logger option %q", key))
+			}
+		}
+	}
+	return mi.newLogger(opts)
+}
+
+func (mi *ModuleInstance) newLogger(opts *loggerOptions) *Logger {
+	rt := mi.vu.Runtime()
+	var out *output
+	if opts.output != consoleOutput {
+		var fallbackLogger logrus.FieldLogger = logrus.StandardLogger()
+		if initEnv := mi.vu.InitEnv(); initEnv != nil {
+			fallbackLogger = initEnv.Logger
+		} else if state := mi.vu.State(); state != nil {
+			fallbackLogger = state.Logger
+		}
+		var err error
+		out, err = mi.root.outputs.get(opts.output, fallbackLogger)
+		if err != nil {
+			common.Throw(rt, err)
+		}
+	}
+
+	limiter := rate.NewLimiter(rate.Inf, 0)
+	if opts.rateLimit > 0 {
+		limiter = rate.NewLimiter(rate.Limit(opts.rateLimit), int(math.Ceil(opts.rateLimit)))
+	}
+	return &Logger{
+		mi:       mi,
+		output:   out,
+		level:    opts.level,
+		throttle: &throttle{limiter: limiter},
+		fields:   opts.fields,
+	}
+}
+
+// parseLevel parses the name of a level of the messages.
+func parseLevel(name string) (logrus.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return logrus.DebugLevel, nil
+	case "info":
+		return logrus.InfoLevel, nil
+	case "warn", "warning":
+		return logrus.WarnLevel, nil
+	case "error":
+		return logrus.ErrorLevel, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q, it must be debug, info, warn or error", name)
+	}
+}
+
+// exportFields exports the fields of an object.
+func exportFields(rt *goja.Runtime, v goja.Value) logrus.Fields {
+	if common.IsNullish(v) {
+		return nil
+	}
+	obj := v.ToObject(rt)
+	fields := make(logrus.Fields, len(obj.Keys()))
+	for _, key := range obj.Keys() {
+		fields[key] = obj.Get(key).Export()
+	}
+	return fields
+}
+
+// vuFields returns the fields identifying the scenario, VU and iteration, when
+// the VU runs an iteration.
+func (mi *ModuleInstance) vuFields() logrus.Fields {
+	state := mi.vu.State()
+	if state == nil {
+		return nil
+	}
+	fields := logrus.Fields{"vu": state.VUID, "iter": state.Iteration}
+	if scenario := lib.GetScenarioState(mi.vu.Context()); scenario != nil {
+		fields["scenario"] = scenario.Name
+	}
+	return fields
+}
@@ -0,0 +1,159 @@
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/lib/testutils"
+)
+
+func newTestRuntime(t *testing.T, rm *RootModule) (*modulestest.Runtime, *testutils.SimpleLogrusHook) {
+	t.Helper()
+	ts := modulestest.NewRuntime(t)
+	logger, hook := testutils.NewLoggerWithHook(t)
+	ts.VU.InitEnvField.Logger = logger
+	m, ok := rm.NewModuleInstance(ts.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, ts.VU.Runtime().Set("log", m.Exports().Named))
+	return ts, hook
+}
+
+func TestLogConsole(t *testing.T) {
+	t.Parallel()
+
+	ts, hook := newTestRuntime(t, New())
+	_, err := ts.VU.Runtime().RunString(`
+		log.info("init", { a: 1 });
+		var logger = log.logger({ level: "debug", fields: { component: "checkout" } });
+	`)
+	require.NoError(t, err)
+	entries := hook.Drain()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "init", entries[0].Message)
+	assert.Equal(t, logrus.Fields{"a": int64(1), "source": "log"}, entries[0].Data)
+
+	ts.MoveToVUContext(&lib.State{Logger: ts.VU.InitEnvField.Logger, VUID: 3, Iteration: 7})
+	_, err = ts.VU.Runtime().RunString(`
+		log.debug("hidden");
+		logger.debug("shown", { status: 200 });
+		logger.with({ user: "u1" }).warn("child", { component: "cart" });
+	`)
+	require.NoError(t, err)
+	entries = hook.Drain()
+	require.Len(t, entries, 2)
+	assert.Equal(t, logrus.DebugLevel, entries[0].Level)
+	assert.Equal(t, logrus.Fields{
+		"component": "checkout", "status": int64(200),
+		"vu": uint64(3), "iter": int64(7), "source": "log",
+	}, entries[0].Data)
+	assert.Equal(t, logrus.WarnLevel, entries[1].Level)
+	assert.Equal(t, logrus.Fields{
+		"component": "cart", "user": "u1",
+		"vu": uint64(3), "iter": int64(7), "source": "log",
+	}, entries[1].Data)
+}
+
+func TestLogRateLimit(t *testing.T) {
+	t.Parallel()
+
+	ts, hook := newTestRuntime(t, New())
+	_, err := ts.VU.Runtime().RunString(`
+		var logger = log.logger({ rateLimit: 2 });
+		for (var i = 0; i < 5; i++) {
+			logger.info("message " + i);
+		}
+		var unlimited = log.logger({ rateLimit: 0 });
+		for (var i = 0; i < 20; i++) {
+			unlimited.info("unlimited");
+		}
+	`)
+	require.NoError(t, err)
+	entries := hook.Drain()
+	require.Len(t, entries, 22)
+	assert.Equal(t, "message 1", entries[1].Message)
+	assert.NotContains(t, entries[1].Data, "dropped")
+
+	// the tokens of the limiter are refilled after half a second
+	_, err = ts.VU.Runtime().RunString(`
+		var start = Date.now();
+		while (Date.now() - start < 600) {}
+		logger.info("after");
+	`)
+	require.NoError(t, err)
+	entries = hook.Drain()
+	require.Len(t, entries, 1)
+	assert.Equal(t, 3, entries[0].Data["dropped"])
+}
+
+func TestLogOptions(t *testing.T) {
+	t.Parallel()
+
+	ts, _ := newTestRuntime(t, New())
+	for script, msg := range map[string]string{
+		`log.logger({ level: "trace" })`:                          `unknown log level "trace"`,
+		`log.logger({ rateLimit: -1 })`:                           "the rateLimit must be a positive number",
+		`log.logger({ output: "stdout" })`:                        `unsupported log output "stdout"`,
+		`log.logger({ output: "file" })`:                          "key `file` with no value",
+		`log.logger({ levels: "debug" })`:                         `unknown logger option "levels"`,
+		`log.logger({ output: "loki=http://127.0.0.1,limit=0" })`: "loki limit needs to be a positive number",
+	} {
+		_, err := ts.VU.Runtime().RunString(script)
+		require.ErrorContains(t, err, msg, script)
+	}
+}
+
+func TestLogFile(t *testing.T) {
+	t.Parallel()
+
+	fs := fsext.NewMemMapFs()
+	rm := &RootModule{outputs: newOutputs(fs)}
+	ts, hook := newTestRuntime(t, rm)
+	// the VUs logging to the same file share its output
+	other, _ := newTestRuntime(t, rm)
+	_, err := ts.VU.Runtime().RunString(`
+		var logger = log.logger({ output: "file=/debug.log", level: "debug" });
+		logger.debug("first", { a: "b" });
+		log.logger({ output: "none" }).error("dropped");
+	`)
+	require.NoError(t, err)
+	_, err = other.VU.Runtime().RunString(`
+		log.logger({ output: "file=/debug.log" }).info("second");
+	`)
+	require.NoError(t, err)
+	assert.Empty(t, hook.Drain())
+
+	rm.outputs.close()
+	f, err := fs.Open("/debug.log")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, f.Close()) }()
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		delete(line, "time")
+		lines = append(lines, line)
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, []map[string]interface{}{
+		{"level": "debug", "msg": "first", "a": "b", "source": "log"},
+		{"level": "info", "msg": "second", "source": "log"},
+	}, lines)
+
+	// the messages logged after the exit are dropped
+	_, err = ts.VU.Runtime().RunString(`logger.info("after")`)
+	require.NoError(t, err)
+	data, err := afero.ReadFile(fs, "/debug.log")
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "after")
+}
@@ -0,0 +1,109 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/lib/fsext"
+	k6log "go.k6.io/k6/log"
+)
+
+// output is a destination of the messages of the loggers other than the
+// console, shared by all the VUs.
+type output struct {
+	logger *logrus.Logger
+}
+
+// outputs are the outputs of the loggers by their configuration line, so that
+// the VUs logging to the same file, or Loki, share a single hook.
+type outputs struct {
+	fs fsext.Fs
+
+	mu      sync.RWMutex
+	outputs map[string]*output
+	cancel  context.CancelFunc
+	ctx     context.Context //nolint:containedctx
+	wg      sync.WaitGroup
+	closed  bool
+}
+
+func newOutputs(fs fsext.Fs) *outputs {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &outputs{fs: fs, outputs: make(map[string]*output), ctx: ctx, cancel: cancel}
+}
+
+// get returns the output of the configuration line, which is either "none", or
+// a file or Loki output configured like the --log-output option.
+func (o *outputs) get(line string, fallbackLogger logrus.FieldLogger) (*output, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if out, ok := o.outputs[line]; ok {
+		return out, nil
+	}
+	if o.closed {
+		return nil, fmt.Errorf("the log outputs are closed")
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.SetLevel(logrus.DebugLevel)
+
+	var (
+		hook k6log.AsyncHook
+		err  error
+	)
+	switch {
+	case line == "none":
+	case strings.HasPrefix(line, "loki"):
+		hook, err = k6log.LokiFromConfigLine(fallbackLogger, line)
+	case strings.HasPrefix(line, "file"):
+		hook, err = k6log.FileHookFromConfigLine(o.fs, os.Getwd, fallbackLogger, line)
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return nil, fmt.Errorf("unsupported log output %q, it must be console, none, file or loki", line)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if hook != nil {
+		logger.AddHook(hook)
+		o.wg.Add(1)
+		go func() {
+			defer o.wg.Done()
+			hook.Listen(o.ctx)
+		}()
+	}
+	out := &output{logger: logger}
+	o.outputs[line] = out
+	return out, nil
+}
+
+// log logs the entry to the output, unless the outputs are closed.
+func (o *outputs) log(out *output, level logrus.Level, fields logrus.Fields, msg string) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if o.closed {
+		return
+	}
+	out.logger.WithFields(fields).Log(level, msg)
+}
+
+// close flushes and closes the outputs.
+func (o *outputs) close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return
+	}
+	o.closed = true
+	o.cancel()
+	o.wg.Wait()
+}
@@ -0,0 +1,153 @@
+package mqtt
+
+import (
+	"bufio"
+	"crypto/tls"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testBroker is a minimal MQTT broker, which forwards the messages to the
+// subscriptions with the same topic, or with a "#" wildcard at the end.
+type testBroker struct {
+	mx            sync.Mutex
+	subscriptions map[*brokerConn]map[string]byte
+	clientIDs     []string
+}
+
+type brokerConn struct {
+	conn    net.Conn
+	writeMx sync.Mutex
+	mx      sync.Mutex
+	nextID  uint16
+}
+
+func (bc *brokerConn) write(p *packet) {
+	b, err := p.encode()
+	if err != nil {
+		panic(err)
+	}
+	bc.writeMx.Lock()
+	defer bc.writeMx.Unlock()
+	_, _ = bc.conn.Write(b)
+}
+
+// newTestBroker starts a broker, with TLS if the config isn't nil, and returns its address.
+func newTestBroker(t *testing.T, tlsConfig *tls.Config) (*testBroker, string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	if tlsConfig != nil {
+		lis = tls.NewListener(lis, tlsConfig)
+	}
+	t.Cleanup(func() { _ = lis.Close() })
+
+	b := &testBroker{subscriptions: make(map[*brokerConn]map[string]byte)}
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go b.handle(&brokerConn{conn: conn})
+		}
+	}()
+	return b, lis.Addr().String()
+}
+
+func (b *testBroker) handle(bc *brokerConn) { //nolint:cyclop
+	defer func() {
+		b.mx.Lock()
+		delete(b.subscriptions, bc)
+		b.mx.Unlock()
+		_ = bc.conn.Close()
+	}()
+
+	r := bufio.NewReader(bc.conn)
+	p, err := readPacket(r)
+	if err != nil || p.typ != packetConnect {
+		return
+	}
+	if p.password == "wrong" {
+		bc.write(&packet{typ: packetConnack, returnCode: 4})
+		return
+	}
+	b.mx.Lock()
+	b.clientIDs = append(b.clientIDs, p.clientID)
+	b.subscriptions[bc] = make(map[string]byte)
+	b.mx.Unlock()
+	bc.write(&packet{typ: packetConnack})
+
+	for {
+		p, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		switch p.typ {
+		case packetSubscribe:
+			ack := &packet{typ: packetSuback, id: p.id}
+			b.mx.Lock()
+			for i, topic := range p.topics {
+				if topic == "forbidden" {
+					ack.qoss = append(ack.qoss, 0x80)
+					continue
+				}
+				b.subscriptions[bc][topic] = p.qoss[i]
+				ack.qoss = append(ack.qoss, p.qoss[i])
+			}
+			b.mx.Unlock()
+			bc.write(ack)
+		case packetUnsubscribe:
+			b.mx.Lock()
+			for _, topic := range p.topics {
+				delete(b.subscriptions[bc], topic)
+			}
+			b.mx.Unlock()
+			bc.write(&packet{typ: packetUnsuback, id: p.id})
+		case packetPublish:
+			switch p.qos() {
+			case 1:
+				bc.write(&packet{typ: packetPuback, id: p.id})
+			case 2:
+				bc.write(&packet{typ: packetPubrec, id: p.id})
+			}
+			b.forward(p)
+		case packetPubrel:
+			bc.write(&packet{typ: packetPubcomp, id: p.id})
+		case packetPubrec:
+			bc.write(&packet{typ: packetPubrel, id: p.id})
+		case packetPingreq:
+			bc.write(&packet{typ: packetPingresp})
+		case packetDisconnect:
+			return
+		}
+	}
+}
+
+func (b *testBroker) forward(p *packet) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	for bc, subscriptions := range b.subscriptions {
+		for filter, qos := range subscriptions {
+			if filter != p.topic && !(strings.HasSuffix(filter, "#") && strings.HasPrefix(p.topic, filter[:len(filter)-1])) {
+				continue
+			}
+			if p.qos() < qos {
+				qos = p.qos()
+			}
+			var id uint16
+			if qos > 0 {
+				bc.mx.Lock()
+				bc.nextID++
+				id = bc.nextID
+				bc.mx.Unlock()
+			}
+			bc.write(newPublish(id, p.topic, p.payload, qos, p.retain()))
+			break
+		}
+	}
+}
@@ -0,0 +1,414 @@
+package mqtt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/mstoykov/k6-taskqueue-lib/taskqueue"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	eventMessage = "message"
+	eventError   = "error"
+	eventClose   = "close"
+
+	defaultTimeout   = time.Minute
+	defaultKeepAlive = time.Minute
+)
+
+// Client is an MQTT client of a VU. The requests to the broker are made
+// synchronously, while the messages of the subscriptions are passed to the
+// listeners of the message event on the event loop, until the client is closed.
+type Client struct {
+	mi *ModuleInstance
+
+	session     *session
+	tq          *taskqueue.TaskQueue
+	url         string
+	timeout     time.Duration
+	tagsAndMeta metrics.TagsAndMeta
+	closing     atomic.Bool
+
+	listeners map[string][]goja.Callable
+}
+
+// connectParams are the params of the connect method.
+type connectParams struct {
+	clientID     string
+	username     *string
+	password     *string
+	keepAlive    time.Duration
+	cleanSession bool
+	timeout      time.Duration
+	serverName   string
+	insecureTLS  bool
+	tags         map[string]string
+}
+
+func newConnectParams(rt *goja.Runtime, input goja.Value) (connectParams, error) { //nolint:cyclop
+	params := connectParams{keepAlive: defaultKeepAlive, cleanSession: true, timeout: defaultTimeout}
+	if !common.IsNullish(input) {
+		raw := input.ToObject(rt)
+		for _, k := range raw.Keys() {
+			v := raw.Get(k)
+			var err error
+			switch k {
+			case "clientId":
+				params.clientID = v.String()
+			case "username":
+				s := v.String()
+				params.username = &s
+			case "password":
+				s := v.String()
+				params.password = &s
+			case "keepAlive":
+				params.keepAlive, err = types.GetDurationValue(v.Export())
+			case "cleanSession":
+				params.cleanSession = v.ToBoolean()
+			case "timeout":
+				params.timeout, err = types.GetDurationValue(v.Export())
+			case "tls":
+				tlsObj := v.ToObject(rt)
+				if sn := tlsObj.Get("serverName"); !common.IsNullish(sn) {
+					params.serverName = sn.String()
+				}
+				if skip := tlsObj.Get("insecureSkipVerify"); skip != nil {
+					params.insecureTLS = skip.ToBoolean()
+				}
+			case "tags":
+				err = rt.ExportTo(v, &params.tags)
+			default:
+				return params, fmt.Errorf("unknown param '%s'", k)
+			}
+			if err != nil {
+				return params, fmt.Errorf("invalid %s value: %w", k, err)
+			}
+		}
+	}
+
+	if params.keepAlive < 0 || params.keepAlive > 65535*time.Second {
+		return params, fmt.Errorf("invalid keepAlive value: %s", params.keepAlive)
+	}
+	if params.clientID == "" {
+		id := make([]byte, 8)
+		if _, err := rand.Read(id); err != nil {
+			return params, err
+		}
+		params.clientID = "k6-" + hex.EncodeToString(id)
+	}
+	return params, nil
+}
+
+// On adds a listener of the message, error or close events.
+func (c *Client) On(event string, listener goja.Value) error {
+	fn, ok := goja.AssertFunction(listener)
+	if !ok {
+		return errors.New("the listener needs to be a function")
+	}
+	switch event {
+	case eventMessage, eventError, eventClose:
+	default:
+		return fmt.Errorf("unknown MQTT client's event type: %s", event)
+	}
+	if c.listeners == nil {
+		c.listeners = make(map[string][]goja.Callable)
+	}
+	c.listeners[event] = append(c.listeners[event], fn)
+	return nil
+}
+
+// Connect connects to the broker of the URL, with the mqtt:// or the mqtts://
+// scheme, and the default port is 1883 or 8883 respectively.
+func (c *Client) Connect(brokerURL string, params goja.Value) error {
+	state := c.mi.vu.State()
+	if state == nil {
+		return common.NewInitContextError("connecting to an MQTT broker in the init context is not supported")
+	}
+	if c.session != nil {
+		return errors.New("the client is already connected")
+	}
+
+	u, err := url.Parse(brokerURL)
+	if err != nil {
+		return fmt.Errorf("invalid broker URL: %w", err)
+	}
+	var useTLS bool
+	port := "1883"
+	switch u.Scheme {
+	case "mqtt", "tcp":
+	case "mqtts", "ssl", "tls":
+		useTLS, port = true, "8883"
+	default:
+		return fmt.Errorf("unsupported scheme '%s' of the broker URL, it needs to be mqtt or mqtts", u.Scheme)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	address := net.JoinHostPort(u.Hostname(), port)
+
+	p, err := newConnectParams(c.mi.vu.Runtime(), params)
+	if err != nil {
+		return fmt.Errorf("invalid connect() parameters: %w", err)
+	}
+
+	ctm := state.Tags.GetCurrentValues()
+	tags := ctm.Tags.With("url", brokerURL)
+	for k, v := range p.tags {
+		tags = tags.With(k, v)
+	}
+	c.url = brokerURL
+	c.timeout = p.timeout
+	c.tagsAndMeta = metrics.TagsAndMeta{Tags: tags, Metadata: ctm.Metadata}
+
+	ctx, cancel := context.WithTimeout(c.mi.vu.Context(), p.timeout)
+	defer cancel()
+
+	start := time.Now()
+	conn, err := state.Dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	if useTLS {
+		// the TLS config of the VU has the tlsAuth and the other TLS options
+		config := &tls.Config{MinVersion: tls.VersionTLS12} //nolint:gosec
+		if state.TLSConfig != nil {
+			config = state.TLSConfig.Clone()
+		}
+		config.ServerName = u.Hostname()
+		if p.serverName != "" {
+			config.ServerName = p.serverName
+		}
+		if p.insecureTLS {
+			config.InsecureSkipVerify = true
+		}
+		tlsConn := tls.Client(conn, config)
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("TLS handshake with %s failed: %w", address, err)
+		}
+		conn = tlsConn
+	}
+
+	s := newSession(conn, c.queueMessage)
+	connect := &packet{
+		typ:          packetConnect,
+		clientID:     p.clientID,
+		keepAlive:    uint16(p.keepAlive / time.Second),
+		cleanSession: p.cleanSession,
+	}
+	if p.username != nil {
+		connect.hasUsername, connect.username = true, *p.username
+	}
+	if p.password != nil {
+		connect.hasPassword, connect.password = true, *p.password
+	}
+	if err = s.connect(ctx, connect); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	end := time.Now()
+	c.push(c.mi.metrics.Connecting, tags, metrics.D(end.Sub(start)))
+
+	c.session = s
+	c.tq = taskqueue.New(c.mi.vu.RegisterCallback)
+	go s.loop(time.Duration(connect.keepAlive) * time.Second)
+	go c.wait()
+	return nil
+}
+
+// wait waits for the end of the session, to emit the events and to let the
+// event loop finish. The session is ended when the VU is done too.
+func (c *Client) wait() {
+	select {
+	case <-c.session.done:
+	case <-c.mi.vu.Context().Done():
+		c.closing.Store(true)
+		_ = c.session.conn.Close()
+		<-c.session.done
+	}
+
+	c.session.mx.Lock()
+	err := c.session.err
+	c.session.mx.Unlock()
+	closing := c.closing.Load()
+	c.tq.Queue(func() error {
+		if !closing && err != nil {
+			if err := c.emit(eventError, c.mi.vu.Runtime().NewGoError(err)); err != nil {
+				return err
+			}
+		}
+		return c.emit(eventClose)
+	})
+	c.tq.Close()
+}
+
+func (c *Client) emit(event string, args ...goja.Value) error {
+	for _, listener := range c.listeners[event] {
+		if _, err := listener(goja.Undefined(), args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queueMessage is called by the reading goroutine of the session, to pass
+// the message to the listeners.
+func (c *Client) queueMessage(p *packet) {
+	c.push(c.mi.metrics.MessagesReceived, c.tagsAndMeta.Tags.With("topic", p.topic), 1)
+	c.tq.Queue(func() error {
+		rt := c.mi.vu.Runtime()
+		msg := rt.NewObject()
+		for k, v := range map[string]interface{}{
+			"topic":         p.topic,
+			"payload":       string(p.payload),
+			"binaryPayload": rt.NewArrayBuffer(p.payload),
+			"qos":           p.qos(),
+			"retain":        p.retain(),
+		} {
+			if err := msg.Set(k, v); err != nil {
+				return err
+			}
+		}
+		if err := c.emit(eventMessage, msg); err != nil {
+			_ = c.Close()
+			return err
+		}
+		return nil
+	})
+}
+
+func (c *Client) getSession() (*session, error) {
+	if c.session == nil {
+		return nil, errors.New("the client isn't connected, you must call connect first")
+	}
+	return c.session, nil
+}
+
+func parseQoS(params goja.Value, rt *goja.Runtime) (byte, error) {
+	if common.IsNullish(params) {
+		return 0, nil
+	}
+	v := params.ToObject(rt).Get("qos")
+	if common.IsNullish(v) {
+		return 0, nil
+	}
+	qos := v.ToInteger()
+	if qos < 0 || qos > 2 {
+		return 0, fmt.Errorf("invalid qos value: %d, it needs to be 0, 1 or 2", qos)
+	}
+	return byte(qos), nil
+}
+
+// Publish publishes the string or ArrayBuffer payload to the topic, with
+// the qos and retain params. For the QoS 1 and 2, it waits until the
+// message is acknowledged by the broker, which is measured by the
+// mqtt_publish_duration metric.
+func (c *Client) Publish(topic string, payload goja.Value, params goja.Value) error {
+	s, err := c.getSession()
+	if err != nil {
+		return err
+	}
+	rt := c.mi.vu.Runtime()
+	qos, err := parseQoS(params, rt)
+	if err != nil {
+		return err
+	}
+	var retain bool
+	tags := c.tagsAndMeta.Tags.With("topic", topic)
+	if !common.IsNullish(params) {
+		obj := params.ToObject(rt)
+		if v := obj.Get("retain"); v != nil {
+			retain = v.ToBoolean()
+		}
+		if v := obj.Get("tags"); !common.IsNullish(v) {
+			var extra map[string]string
+			if err = rt.ExportTo(v, &extra); err != nil {
+				return fmt.Errorf("invalid tags value: %w", err)
+			}
+			for k, v := range extra {
+				tags = tags.With(k, v)
+			}
+		}
+	}
+	if common.IsNullish(payload) {
+		return errors.New("missing payload, expected a string or ArrayBuffer")
+	}
+	data, err := common.ToBytes(payload.Export())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.mi.vu.Context(), c.timeout)
+	defer cancel()
+	start := time.Now()
+	if err = s.publish(ctx, topic, data, qos, retain); err != nil {
+		return fmt.Errorf("unable to publish to '%s': %w", topic, err)
+	}
+	end := time.Now()
+	c.push(c.mi.metrics.MessagesSent, tags, 1)
+	c.push(c.mi.metrics.PublishDuration, tags.With("qos", fmt.Sprint(qos)), metrics.D(end.Sub(start)))
+	return nil
+}
+
+// Subscribe subscribes to the topic filter with the qos param, and it
+// returns the QoS that is granted by the broker.
+func (c *Client) Subscribe(topic string, params goja.Value) (int, error) {
+	s, err := c.getSession()
+	if err != nil {
+		return 0, err
+	}
+	qos, err := parseQoS(params, c.mi.vu.Runtime())
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(c.mi.vu.Context(), c.timeout)
+	defer cancel()
+	granted, err := s.subscribe(ctx, topic, qos)
+	return int(granted), err
+}
+
+// Unsubscribe removes the subscription to the topic filter.
+func (c *Client) Unsubscribe(topic string) error {
+	s, err := c.getSession()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(c.mi.vu.Context(), c.timeout)
+	defer cancel()
+	return s.unsubscribe(ctx, topic)
+}
+
+// Close disconnects from the broker, the close event is emitted after it.
+func (c *Client) Close() error {
+	if c.session == nil || c.closing.Swap(true) {
+		return nil
+	}
+	c.session.disconnect()
+	return nil
+}
+
+func (c *Client) push(metric *metrics.Metric, tags *metrics.TagSet, value float64) {
+	state := c.mi.vu.State()
+	if state == nil {
+		return
+	}
+	metrics.PushIfNotDone(c.mi.vu.Context(), state.Samples, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{Metric: metric, Tags: tags},
+		Time:       time.Now(),
+		Metadata:   c.tagsAndMeta.Metadata,
+		Value:      value,
+	})
+}
@@ -0,0 +1,148 @@
+package mqtt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/httpmultibin"
+	"go.k6.io/k6/metrics"
+)
+
+func newTestRuntime(t *testing.T) (*modulestest.Runtime, *httpmultibin.HTTPMultiBin, chan metrics.SampleContainer) {
+	t.Helper()
+	runtime := modulestest.NewRuntime(t)
+	mi, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, runtime.VU.Runtime().Set("mqtt", mi.Exports().Named))
+
+	tb := httpmultibin.NewHTTPMultiBin(t)
+	registry := metrics.NewRegistry()
+	samples := make(chan metrics.SampleContainer, 1000)
+	runtime.MoveToVUContext(&lib.State{
+		Dialer:         tb.Dialer,
+		TLSConfig:      tb.TLSClientConfig,
+		Samples:        samples,
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+	})
+	return runtime, tb, samples
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	t.Parallel()
+	runtime, _, samples := newTestRuntime(t)
+	broker, addr := newTestBroker(t, nil)
+	require.NoError(t, runtime.VU.Runtime().Set("addr", addr))
+
+	var received []string
+	require.NoError(t, runtime.VU.Runtime().Set("received", func(s string) { received = append(received, s) }))
+
+	_, err := runtime.RunOnEventLoop(`
+		var client = new mqtt.Client();
+		client.connect("mqtt://" + addr, { clientId: "k6-test", keepAlive: "1s" });
+		var granted = client.subscribe("sensors/#", { qos: 2 });
+		if (granted !== 2) { throw new Error("unexpected granted qos: " + granted); }
+
+		var count = 0;
+		client.on("message", function(msg) {
+			received(msg.topic + " " + msg.payload + " " + msg.qos + " " + new Uint8Array(msg.binaryPayload).length);
+			if (++count === 3) {
+				client.unsubscribe("sensors/#");
+				client.close();
+			}
+		});
+		client.on("close", function() { received("closed"); });
+
+		client.publish("sensors/a", "zero");
+		client.publish("sensors/b", new Uint8Array([1, 2]).buffer, { qos: 1, tags: { sensor: "b" } });
+		client.publish("sensors/c", "two", { qos: 2, retain: true });
+		client.publish("other", "ignored", { qos: 1 });
+	`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"sensors/a zero 0 4", "sensors/b \x01\x02 1 2", "sensors/c two 2 3", "closed"}, received)
+	assert.Equal(t, []string{"k6-test"}, broker.clientIDs)
+
+	counts := map[string]int{}
+	for _, c := range metrics.GetBufferedSamples(samples) {
+		for _, s := range c.GetSamples() {
+			counts[s.Metric.Name]++
+			url, _ := s.Tags.Get("url")
+			assert.Equal(t, "mqtt://"+addr, url)
+			if s.Metric.Name == "mqtt_publish_duration" {
+				topic, _ := s.Tags.Get("topic")
+				qos, _ := s.Tags.Get("qos")
+				sensor, _ := s.Tags.Get("sensor")
+				assert.Contains(t, []string{"sensors/a 0 ", "sensors/b 1 b", "sensors/c 2 ", "other 1 "}, topic+" "+qos+" "+sensor)
+			}
+		}
+	}
+	assert.Equal(t, map[string]int{
+		"mqtt_connecting":       1,
+		"mqtt_msgs_sent":        4,
+		"mqtt_msgs_received":    3,
+		"mqtt_publish_duration": 4,
+	}, counts)
+}
+
+func TestConnectTLS(t *testing.T) {
+	t.Parallel()
+	runtime, tb, _ := newTestRuntime(t)
+	_, addr := newTestBroker(t, tb.ServerHTTPS.TLS.Clone())
+	require.NoError(t, runtime.VU.Runtime().Set("addr", addr))
+
+	_, err := runtime.RunOnEventLoop(`
+		var client = new mqtt.Client();
+		client.connect("mqtts://" + addr);
+		client.publish("a", "b", { qos: 1 });
+		client.close();
+	`)
+	require.NoError(t, err)
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+	runtime, _, _ := newTestRuntime(t)
+	_, addr := newTestBroker(t, nil)
+	require.NoError(t, runtime.VU.Runtime().Set("addr", addr))
+
+	testCases := []struct {
+		name, script, err string
+	}{
+		{"scheme", `new mqtt.Client().connect("http://" + addr)`, "unsupported scheme 'http'"},
+		{"param", `new mqtt.Client().connect("mqtt://" + addr, { foo: 1 })`, "unknown param 'foo'"},
+		{
+			"refused", `new mqtt.Client().connect("mqtt://" + addr, { username: "k6", password: "wrong" })`,
+			"connection refused: bad user name or password",
+		},
+		{"not connected", `new mqtt.Client().publish("a", "b")`, "the client isn't connected, you must call connect first"},
+		{"event", `new mqtt.Client().on("foo", function() {})`, "unknown MQTT client's event type: foo"},
+		{
+			"qos", `var c = new mqtt.Client(); c.connect("mqtt://" + addr); try { c.publish("a", "b", { qos: 3 }) } finally { c.close() }`,
+			"invalid qos value: 3, it needs to be 0, 1 or 2",
+		},
+		{
+			"subscribe", `var c = new mqtt.Client(); c.connect("mqtt://" + addr); try { c.subscribe("forbidden") } finally { c.close() }`,
+			"the subscription to 'forbidden' was refused",
+		},
+	}
+	for _, tc := range testCases {
+		_, err := runtime.RunOnEventLoop(tc.script)
+		require.ErrorContains(t, err, tc.err, tc.name)
+	}
+}
+
+func TestConnectInInitContext(t *testing.T) {
+	t.Parallel()
+	runtime := modulestest.NewRuntime(t)
+	mi, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, runtime.VU.Runtime().Set("mqtt", mi.Exports().Named))
+
+	_, err := runtime.VU.Runtime().RunString(`new mqtt.Client().connect("mqtt://127.0.0.1:1883")`)
+	require.ErrorContains(t, err, "connecting to an MQTT broker in the init context is not supported")
+}
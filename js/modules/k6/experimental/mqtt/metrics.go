@@ -0,0 +1,35 @@
+package mqtt
+
+import "go.k6.io/k6/metrics"
+
+// instanceMetrics contains the metrics of the MQTT clients.
+type instanceMetrics struct {
+	Connecting       *metrics.Metric
+	MessagesSent     *metrics.Metric
+	MessagesReceived *metrics.Metric
+	PublishDuration  *metrics.Metric
+}
+
+// registerMetrics registers and returns the metrics in the provided registry
+func registerMetrics(registry *metrics.Registry) (*instanceMetrics, error) {
+	var err error
+	m := &instanceMetrics{}
+
+	if m.Connecting, err = registry.NewMetric("mqtt_connecting", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.MessagesSent, err = registry.NewMetric("mqtt_msgs_sent", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.MessagesReceived, err = registry.NewMetric("mqtt_msgs_received", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.PublishDuration, err = registry.NewMetric("mqtt_publish_duration", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
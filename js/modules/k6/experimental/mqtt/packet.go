@@ -0,0 +1,284 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The control packet types of MQTT 3.1.1.
+const (
+	packetConnect     byte = 1
+	packetConnack     byte = 2
+	packetPublish     byte = 3
+	packetPuback      byte = 4
+	packetPubrec      byte = 5
+	packetPubrel      byte = 6
+	packetPubcomp     byte = 7
+	packetSubscribe   byte = 8
+	packetSuback      byte = 9
+	packetUnsubscribe byte = 10
+	packetUnsuback    byte = 11
+	packetPingreq     byte = 12
+	packetPingresp    byte = 13
+	packetDisconnect  byte = 14
+)
+
+// maxRemainingLength is the maximum length of a packet after the fixed header.
+const maxRemainingLength = 268435455
+
+// packet is an MQTT control packet, the fields are the ones of all the types
+// of packets, only the ones of its type are used.
+type packet struct {
+	typ   byte
+	flags byte
+
+	id      uint16
+	topic   string
+	payload []byte
+
+	// CONNECT
+	clientID     string
+	username     string
+	password     string
+	hasUsername  bool
+	hasPassword  bool
+	keepAlive    uint16
+	cleanSession bool
+
+	// CONNACK
+	returnCode byte
+
+	// SUBSCRIBE, SUBACK and UNSUBSCRIBE
+	topics []string
+	qoss   []byte
+}
+
+func (p *packet) qos() byte {
+	return (p.flags >> 1) & 0x03
+}
+
+func (p *packet) retain() bool {
+	return p.flags&0x01 != 0
+}
+
+// newPublish returns a PUBLISH packet.
+func newPublish(id uint16, topic string, payload []byte, qos byte, retain bool) *packet {
+	p := &packet{typ: packetPublish, id: id, topic: topic, payload: payload, flags: qos << 1}
+	if retain {
+		p.flags |= 0x01
+	}
+	return p
+}
+
+// encode returns the packet in the wire format.
+func (p *packet) encode() ([]byte, error) {
+	var body []byte
+	flags := p.flags
+
+	switch p.typ {
+	case packetConnect:
+		body = appendString(body, "MQTT")
+		body = append(body, 4) // the protocol level of 3.1.1
+		var connectFlags byte
+		if p.hasUsername {
+			connectFlags |= 0x80
+		}
+		if p.hasPassword {
+			connectFlags |= 0x40
+		}
+		if p.cleanSession {
+			connectFlags |= 0x02
+		}
+		body = append(body, connectFlags)
+		body = binary.BigEndian.AppendUint16(body, p.keepAlive)
+		body = appendString(body, p.clientID)
+		if p.hasUsername {
+			body = appendString(body, p.username)
+		}
+		if p.hasPassword {
+			body = appendString(body, p.password)
+		}
+	case packetConnack:
+		body = []byte{0, p.returnCode}
+	case packetPublish:
+		body = appendString(body, p.topic)
+		if p.qos() > 0 {
+			body = binary.BigEndian.AppendUint16(body, p.id)
+		}
+		body = append(body, p.payload...)
+	case packetPuback, packetPubrec, packetPubcomp, packetUnsuback:
+		body = binary.BigEndian.AppendUint16(body, p.id)
+	case packetPubrel:
+		flags = 0x02
+		body = binary.BigEndian.AppendUint16(body, p.id)
+	case packetSubscribe:
+		flags = 0x02
+		body = binary.BigEndian.AppendUint16(body, p.id)
+		for i, topic := range p.topics {
+			body = appendString(body, topic)
+			body = append(body, p.qoss[i])
+		}
+	case packetSuback:
+		body = binary.BigEndian.AppendUint16(body, p.id)
+		body = append(body, p.qoss...)
+	case packetUnsubscribe:
+		flags = 0x02
+		body = binary.BigEndian.AppendUint16(body, p.id)
+		for _, topic := range p.topics {
+			body = appendString(body, topic)
+		}
+	case packetPingreq, packetPingresp, packetDisconnect:
+	default:
+		return nil, fmt.Errorf("unknown packet type %d", p.typ)
+	}
+
+	if len(body) > maxRemainingLength {
+		return nil, fmt.Errorf("the packet is too large, its length is %d bytes", len(body))
+	}
+	buf := make([]byte, 0, len(body)+5)
+	buf = append(buf, p.typ<<4|flags&0x0f)
+	length := len(body)
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+		if length == 0 {
+			break
+		}
+	}
+	return append(buf, body...), nil
+}
+
+// readPacket reads the next packet from the reader.
+func readPacket(r *bufio.Reader) (*packet, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return nil, errors.New("malformed remaining length")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		length += int(b&0x7f) * multiplier
+		multiplier *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	p := &packet{typ: header >> 4, flags: header & 0x0f}
+	d := decoder{buf: body}
+	switch p.typ {
+	case packetConnect:
+		if d.string() != "MQTT" || d.byte() != 4 {
+			return nil, errors.New("unsupported protocol, only MQTT 3.1.1 is supported")
+		}
+		connectFlags := d.byte()
+		p.hasUsername = connectFlags&0x80 != 0
+		p.hasPassword = connectFlags&0x40 != 0
+		p.cleanSession = connectFlags&0x02 != 0
+		p.keepAlive = d.uint16()
+		p.clientID = d.string()
+		if p.hasUsername {
+			p.username = d.string()
+		}
+		if p.hasPassword {
+			p.password = d.string()
+		}
+	case packetConnack:
+		d.byte()
+		p.returnCode = d.byte()
+	case packetPublish:
+		p.topic = d.string()
+		if p.qos() > 0 {
+			p.id = d.uint16()
+		}
+		p.payload = d.rest()
+	case packetPuback, packetPubrec, packetPubrel, packetPubcomp, packetUnsuback:
+		p.id = d.uint16()
+	case packetSubscribe:
+		p.id = d.uint16()
+		for d.err == nil && len(d.buf) > 0 {
+			p.topics = append(p.topics, d.string())
+			p.qoss = append(p.qoss, d.byte())
+		}
+	case packetSuback:
+		p.id = d.uint16()
+		p.qoss = d.rest()
+	case packetUnsubscribe:
+		p.id = d.uint16()
+		for d.err == nil && len(d.buf) > 0 {
+			p.topics = append(p.topics, d.string())
+		}
+	case packetPingreq, packetPingresp, packetDisconnect:
+	default:
+		return nil, fmt.Errorf("unknown packet type %d", p.typ)
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("malformed packet of type %d: %w", p.typ, d.err)
+	}
+	return p, nil
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// decoder reads the fields of a packet, the first error is kept in err.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.buf) < n {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) byte() byte {
+	if b := d.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *decoder) uint16() uint16 {
+	if b := d.next(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *decoder) string() string {
+	return string(d.next(int(d.uint16())))
+}
+
+func (d *decoder) rest() []byte {
+	b := d.buf
+	d.buf = nil
+	return b
+}
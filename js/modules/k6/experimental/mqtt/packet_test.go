@@ -0,0 +1,61 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketEncoding(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		packet *packet
+	}{
+		{
+			name: "connect",
+			packet: &packet{
+				typ: packetConnect, clientID: "k6", keepAlive: 30, cleanSession: true,
+				hasUsername: true, username: "user", hasPassword: true, password: "pass",
+			},
+		},
+		{name: "connack", packet: &packet{typ: packetConnack, returnCode: 5}},
+		{name: "publish qos 0", packet: newPublish(0, "a/b", []byte("hello"), 0, true)},
+		{name: "publish qos 2", packet: newPublish(7, "a/b", []byte(strings.Repeat("x", 20000)), 2, false)},
+		{name: "puback", packet: &packet{typ: packetPuback, id: 1}},
+		{name: "pubrel", packet: &packet{typ: packetPubrel, flags: 0x02, id: 2}},
+		{name: "subscribe", packet: &packet{
+			typ: packetSubscribe, flags: 0x02, id: 3, topics: []string{"a/#", "b"}, qoss: []byte{1, 2},
+		}},
+		{name: "suback", packet: &packet{typ: packetSuback, id: 3, qoss: []byte{1, 0x80}}},
+		{name: "unsubscribe", packet: &packet{typ: packetUnsubscribe, flags: 0x02, id: 4, topics: []string{"a/#"}}},
+		{name: "pingreq", packet: &packet{typ: packetPingreq}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			b, err := tc.packet.encode()
+			require.NoError(t, err)
+			p, err := readPacket(bufio.NewReader(bytes.NewReader(b)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.packet, p)
+		})
+	}
+}
+
+func TestReadPacketErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := readPacket(bufio.NewReader(bytes.NewReader([]byte{0x30, 0xff, 0xff, 0xff, 0xff, 0x01})))
+	require.EqualError(t, err, "malformed remaining length")
+	_, err = readPacket(bufio.NewReader(bytes.NewReader([]byte{0x30, 0x03, 0x00, 0x05, 'a'})))
+	require.EqualError(t, err, "malformed packet of type 3: unexpected EOF")
+	_, err = readPacket(bufio.NewReader(bytes.NewReader([]byte{0xf0, 0x00})))
+	require.EqualError(t, err, "unknown packet type 15")
+}
@@ -0,0 +1,252 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// connackErrors are the errors of the CONNACK return codes.
+var connackErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// errSessionClosed is returned for the requests of a closed session.
+var errSessionClosed = errors.New("the MQTT connection is closed")
+
+// session is an MQTT 3.1.1 connection to a broker. The packets are read by
+// a goroutine, which sends the acknowledgements of the packets that are
+// received and passes the acknowledgements of the sent packets to the
+// requests that wait for them.
+type session struct {
+	conn   net.Conn
+	reader *bufio.Reader
+
+	writeMx sync.Mutex
+
+	mx      sync.Mutex
+	nextID  uint16
+	pending map[uint16]chan *packet
+
+	// onPublish is called by the reading goroutine for the received messages.
+	onPublish func(*packet)
+
+	done chan struct{}
+	err  error
+}
+
+func newSession(conn net.Conn, onPublish func(*packet)) *session {
+	return &session{
+		conn:      conn,
+		reader:    bufio.NewReader(conn),
+		pending:   make(map[uint16]chan *packet),
+		onPublish: onPublish,
+		done:      make(chan struct{}),
+	}
+}
+
+// connect sends the CONNECT packet and waits for the CONNACK, it has to be
+// called before the loop is started.
+func (s *session) connect(ctx context.Context, p *packet) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := s.conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+		defer func() { _ = s.conn.SetDeadline(time.Time{}) }()
+	}
+	if err := s.write(p); err != nil {
+		return err
+	}
+	ack, err := readPacket(s.reader)
+	if err != nil {
+		return fmt.Errorf("unable to read the CONNACK: %w", err)
+	}
+	if ack.typ != packetConnack {
+		return fmt.Errorf("unexpected packet of type %d instead of the CONNACK", ack.typ)
+	}
+	if ack.returnCode != 0 {
+		if msg, ok := connackErrors[ack.returnCode]; ok {
+			return fmt.Errorf("connection refused: %s", msg)
+		}
+		return fmt.Errorf("connection refused with the return code %d", ack.returnCode)
+	}
+	return nil
+}
+
+// loop reads the packets until the connection is closed, and it sends a
+// PINGREQ every keepAlive if it isn't zero.
+func (s *session) loop(keepAlive time.Duration) {
+	if keepAlive > 0 {
+		go s.ping(keepAlive)
+	}
+
+	var err error
+	for err == nil {
+		var p *packet
+		if p, err = readPacket(s.reader); err != nil {
+			break
+		}
+		switch p.typ {
+		case packetPublish:
+			switch p.qos() {
+			case 1:
+				err = s.write(&packet{typ: packetPuback, id: p.id})
+			case 2:
+				err = s.write(&packet{typ: packetPubrec, id: p.id})
+			}
+			s.onPublish(p)
+		case packetPubrel:
+			err = s.write(&packet{typ: packetPubcomp, id: p.id})
+		case packetPuback, packetPubrec, packetPubcomp, packetSuback, packetUnsuback:
+			s.mx.Lock()
+			ch, ok := s.pending[p.id]
+			s.mx.Unlock()
+			if ok {
+				select {
+				case ch <- p:
+				default: // the request doesn't wait for it anymore
+				}
+			}
+		case packetPingresp:
+		default:
+			err = fmt.Errorf("unexpected packet of type %d", p.typ)
+		}
+	}
+
+	s.mx.Lock()
+	s.err = err
+	s.mx.Unlock()
+	_ = s.conn.Close()
+	close(s.done)
+}
+
+func (s *session) ping(keepAlive time.Duration) {
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if s.write(&packet{typ: packetPingreq}) != nil {
+				return
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *session) write(p *packet) error {
+	b, err := p.encode()
+	if err != nil {
+		return err
+	}
+	s.writeMx.Lock()
+	defer s.writeMx.Unlock()
+	_, err = s.conn.Write(b)
+	return err
+}
+
+// newID returns a packet identifier that isn't used by a pending request,
+// with the channel for its acknowledgements.
+func (s *session) newID() (uint16, chan *packet) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	for {
+		s.nextID++
+		if _, ok := s.pending[s.nextID]; s.nextID != 0 && !ok {
+			ch := make(chan *packet, 2)
+			s.pending[s.nextID] = ch
+			return s.nextID, ch
+		}
+	}
+}
+
+func (s *session) release(id uint16) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	delete(s.pending, id)
+}
+
+// wait waits for the acknowledgement of the type.
+func (s *session) wait(ctx context.Context, ch chan *packet, typ byte) (*packet, error) {
+	select {
+	case p := <-ch:
+		if p.typ != typ {
+			return nil, fmt.Errorf("unexpected packet of type %d instead of %d", p.typ, typ)
+		}
+		return p, nil
+	case <-s.done:
+		return nil, errSessionClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// publish sends the message, and it waits for the PUBACK or the PUBCOMP for
+// the QoS 1 and 2.
+func (s *session) publish(ctx context.Context, topic string, payload []byte, qos byte, retain bool) error {
+	if qos == 0 {
+		return s.write(newPublish(0, topic, payload, 0, retain))
+	}
+
+	id, ch := s.newID()
+	defer s.release(id)
+	if err := s.write(newPublish(id, topic, payload, qos, retain)); err != nil {
+		return err
+	}
+	if qos == 1 {
+		_, err := s.wait(ctx, ch, packetPuback)
+		return err
+	}
+	if _, err := s.wait(ctx, ch, packetPubrec); err != nil {
+		return err
+	}
+	if err := s.write(&packet{typ: packetPubrel, id: id}); err != nil {
+		return err
+	}
+	_, err := s.wait(ctx, ch, packetPubcomp)
+	return err
+}
+
+// subscribe subscribes to the topic filter, and returns the granted QoS.
+func (s *session) subscribe(ctx context.Context, topic string, qos byte) (byte, error) {
+	id, ch := s.newID()
+	defer s.release(id)
+	if err := s.write(&packet{typ: packetSubscribe, id: id, topics: []string{topic}, qoss: []byte{qos}}); err != nil {
+		return 0, err
+	}
+	ack, err := s.wait(ctx, ch, packetSuback)
+	if err != nil {
+		return 0, err
+	}
+	if len(ack.qoss) != 1 || ack.qoss[0] == 0x80 {
+		return 0, fmt.Errorf("the subscription to '%s' was refused", topic)
+	}
+	return ack.qoss[0], nil
+}
+
+// unsubscribe removes the subscription to the topic filter.
+func (s *session) unsubscribe(ctx context.Context, topic string) error {
+	id, ch := s.newID()
+	defer s.release(id)
+	if err := s.write(&packet{typ: packetUnsubscribe, id: id, topics: []string{topic}}); err != nil {
+		return err
+	}
+	_, err := s.wait(ctx, ch, packetUnsuback)
+	return err
+}
+
+// disconnect sends the DISCONNECT packet and closes the connection.
+func (s *session) disconnect() {
+	_ = s.write(&packet{typ: packetDisconnect})
+	_ = s.conn.Close()
+	<-s.done
+}
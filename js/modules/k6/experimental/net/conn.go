@@ -0,0 +1,259 @@
+package net
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+// Conn is a TCP or UDP connection opened by a VU.
+type Conn struct {
+	mi          *ModuleInstance
+	connMx      sync.Mutex // guards conn, which is replaced by startTLS while closeOnDone may close it
+	conn        net.Conn
+	network     string
+	address     string
+	timeout     time.Duration
+	tagsAndMeta metrics.TagsAndMeta
+
+	closeOnce sync.Once
+	closed    chan struct{}
+
+	LocalAddress  string `js:"localAddress"`
+	RemoteAddress string `js:"remoteAddress"`
+}
+
+// tlsParams are the params of the TLS handshake.
+type tlsParams struct {
+	serverName         string
+	insecureSkipVerify bool
+}
+
+func newTLSParams(rt *goja.Runtime, input goja.Value) (tlsParams, error) {
+	var params tlsParams
+	if common.IsNullish(input) {
+		return params, nil
+	}
+
+	raw := input.ToObject(rt)
+	for _, k := range raw.Keys() {
+		v := raw.Get(k).Export()
+		switch k {
+		case "serverName":
+			var ok bool
+			if params.serverName, ok = v.(string); !ok {
+				return params, fmt.Errorf("invalid serverName value: '%#v', it needs to be a string", v)
+			}
+		case "insecureSkipVerify":
+			var ok bool
+			if params.insecureSkipVerify, ok = v.(bool); !ok {
+				return params, fmt.Errorf("invalid insecureSkipVerify value: '%#v', it needs to be boolean", v)
+			}
+		default:
+			return params, fmt.Errorf("unknown TLS param '%s'", k)
+		}
+	}
+	return params, nil
+}
+
+// readParams are the params of the read functions.
+type readParams struct {
+	size    int
+	timeout time.Duration
+}
+
+func (c *Conn) newReadParams(input goja.Value) (readParams, error) {
+	params := readParams{size: defaultReadSize, timeout: c.timeout}
+	if common.IsNullish(input) {
+		return params, nil
+	}
+
+	raw := input.ToObject(c.mi.vu.Runtime())
+	for _, k := range raw.Keys() {
+		v := raw.Get(k)
+		switch k {
+		case "size":
+			size := v.ToInteger()
+			if size <= 0 {
+				return params, fmt.Errorf("invalid size value: '%s', it needs to be a positive integer", v)
+			}
+			params.size = int(size)
+		case "timeout":
+			var err error
+			if params.timeout, err = types.GetDurationValue(v.Export()); err != nil {
+				return params, fmt.Errorf("invalid timeout value: %w", err)
+			}
+		default:
+			return params, fmt.Errorf("unknown param '%s'", k)
+		}
+	}
+	return params, nil
+}
+
+// Write writes the string or ArrayBuffer data to the connection, as a single
+// datagram for the UDP connections, and returns the number of written bytes.
+func (c *Conn) Write(data goja.Value) (int, error) {
+	if common.IsNullish(data) {
+		return 0, errors.New("missing argument, expected a string or ArrayBuffer")
+	}
+	b, err := common.ToBytes(data.Export())
+	if err != nil {
+		return 0, err
+	}
+
+	conn := c.netConn()
+	if err := conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	n, err := conn.Write(b)
+	c.pushBytes(c.mi.metrics.BytesSent, n)
+	if err != nil {
+		return n, fmt.Errorf("unable to write to %s: %w", c.address, err)
+	}
+	return n, nil
+}
+
+// Read reads the data that is available on the connection, up to the size
+// param, or a single datagram for the UDP connections, as an ArrayBuffer.
+// It waits for the data until the timeout param, and it returns null when
+// the connection was closed by the other side.
+func (c *Conn) Read(params goja.Value) (goja.Value, error) {
+	b, err := c.read(params)
+	if err != nil || b == nil {
+		return goja.Null(), err
+	}
+	rt := c.mi.vu.Runtime()
+	return rt.ToValue(rt.NewArrayBuffer(b)), nil
+}
+
+// ReadString is like Read, but it returns the data as a string.
+func (c *Conn) ReadString(params goja.Value) (goja.Value, error) {
+	b, err := c.read(params)
+	if err != nil || b == nil {
+		return goja.Null(), err
+	}
+	return c.mi.vu.Runtime().ToValue(string(b)), nil
+}
+
+func (c *Conn) read(input goja.Value) ([]byte, error) {
+	params, err := c.newReadParams(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid read() parameters: %w", err)
+	}
+
+	conn := c.netConn()
+	if err := conn.SetReadDeadline(time.Now().Add(params.timeout)); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, params.size)
+	n, err := conn.Read(buf)
+	c.pushBytes(c.mi.metrics.BytesReceived, n)
+	if n > 0 {
+		return buf[:n], nil
+	}
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unable to read from %s: %w", c.address, err)
+}
+
+// StartTLS makes the TLS handshake on the TCP connection, so the next reads
+// and writes are encrypted, e.g. after a STARTTLS command of the protocol.
+func (c *Conn) StartTLS(params goja.Value) error {
+	p, err := newTLSParams(c.mi.vu.Runtime(), params)
+	if err != nil {
+		return fmt.Errorf("invalid startTLS() parameters: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(c.mi.vu.Context(), c.timeout)
+	defer cancel()
+	return c.startTLS(ctx, p)
+}
+
+func (c *Conn) startTLS(ctx context.Context, p tlsParams) error {
+	if c.network != "tcp" {
+		return errors.New("TLS isn't supported for the UDP connections")
+	}
+	conn := c.netConn()
+	if _, ok := conn.(*tls.Conn); ok {
+		return errors.New("the TLS handshake was already made")
+	}
+
+	// the TLS config of the VU has the tlsAuth and the other TLS options
+	config := &tls.Config{MinVersion: tls.VersionTLS12} //nolint:gosec
+	if state := c.mi.vu.State(); state != nil && state.TLSConfig != nil {
+		config = state.TLSConfig.Clone()
+	}
+	config.ServerName = p.serverName
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(c.address)
+		if err != nil {
+			return err
+		}
+		config.ServerName = host
+	}
+	if p.insecureSkipVerify {
+		config.InsecureSkipVerify = true
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return fmt.Errorf("TLS handshake with %s failed: %w", c.address, err)
+	}
+	// if the connection was closed during the handshake, the TLS
+	// connection wraps the closed one, so it can't be used either
+	c.connMx.Lock()
+	c.conn = tlsConn
+	c.connMx.Unlock()
+	return nil
+}
+
+// netConn returns the connection, which is the TLS one after startTLS.
+func (c *Conn) netConn() net.Conn {
+	c.connMx.Lock()
+	defer c.connMx.Unlock()
+	return c.conn
+}
+
+// Close closes the connection. The connections are closed at the end of
+// the test too, but they should be closed as soon as they aren't needed.
+func (c *Conn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		err = c.netConn().Close()
+	})
+	return err
+}
+
+// closeOnDone closes the connection when the VU is done.
+func (c *Conn) closeOnDone(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		_ = c.Close()
+	case <-c.closed:
+	}
+}
+
+func (c *Conn) pushBytes(metric *metrics.Metric, n int) {
+	state := c.mi.vu.State()
+	if n <= 0 || state == nil {
+		return
+	}
+	metrics.PushIfNotDone(c.mi.vu.Context(), state.Samples, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{Metric: metric, Tags: c.tagsAndMeta.Tags},
+		Time:       time.Now(),
+		Metadata:   c.tagsAndMeta.Metadata,
+		Value:      float64(n),
+	})
+}
@@ -0,0 +1,35 @@
+package net
+
+import "go.k6.io/k6/metrics"
+
+// instanceMetrics contains the metrics of the connections.
+type instanceMetrics struct {
+	Connections   *metrics.Metric
+	Connecting    *metrics.Metric
+	BytesSent     *metrics.Metric
+	BytesReceived *metrics.Metric
+}
+
+// registerMetrics registers and returns the metrics in the provided registry
+func registerMetrics(registry *metrics.Registry) (*instanceMetrics, error) {
+	var err error
+	m := &instanceMetrics{}
+
+	if m.Connections, err = registry.NewMetric("net_connections", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.Connecting, err = registry.NewMetric("net_connecting", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.BytesSent, err = registry.NewMetric("net_bytes_sent", metrics.Counter, metrics.Data); err != nil {
+		return nil, err
+	}
+
+	if m.BytesReceived, err = registry.NewMetric("net_bytes_received", metrics.Counter, metrics.Data); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
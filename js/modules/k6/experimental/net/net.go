@@ -0,0 +1,190 @@
+// Package net implements the k6/experimental/net module, for load testing
+// the protocols over raw TCP and UDP connections.
+package net
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	// defaultTimeout is the timeout of the connecting, the reads and the writes,
+	// when the timeout param isn't provided, the same as for the HTTP requests.
+	defaultTimeout = time.Minute
+
+	// defaultReadSize is the maximum number of bytes that are read at once,
+	// which is enough for any UDP datagram.
+	defaultReadSize = 64 * 1024
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the JS module.
+	ModuleInstance struct {
+		vu      modules.VU
+		metrics *instanceMetrics
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new RootModule instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface to return
+// a new instance for each VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	m, err := registerMetrics(vu.InitEnv().Registry)
+	if err != nil {
+		common.Throw(vu.Runtime(), err)
+	}
+	return &ModuleInstance{vu: vu, metrics: m}
+}
+
+// Exports returns the exports of the net module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"open": mi.Open,
+		},
+	}
+}
+
+// dialParams are the params of the open function.
+type dialParams struct {
+	timeout time.Duration
+	tls     *tlsParams
+	tags    map[string]string
+}
+
+func newDialParams(rt *goja.Runtime, input goja.Value) (dialParams, error) {
+	params := dialParams{timeout: defaultTimeout}
+	if common.IsNullish(input) {
+		return params, nil
+	}
+
+	raw := input.ToObject(rt)
+	for _, k := range raw.Keys() {
+		v := raw.Get(k)
+		switch k {
+		case "timeout":
+			var err error
+			if params.timeout, err = types.GetDurationValue(v.Export()); err != nil {
+				return params, fmt.Errorf("invalid timeout value: %w", err)
+			}
+		case "tls":
+			if b, ok := v.Export().(bool); ok {
+				if b {
+					params.tls = &tlsParams{}
+				}
+				continue
+			}
+			tlsParams, err := newTLSParams(rt, v)
+			if err != nil {
+				return params, err
+			}
+			params.tls = &tlsParams
+		case "tags":
+			if err := rt.ExportTo(v, &params.tags); err != nil {
+				return params, fmt.Errorf("invalid tags value: %w", err)
+			}
+		default:
+			return params, fmt.Errorf("unknown param '%s'", k)
+		}
+	}
+	return params, nil
+}
+
+// Open opens a connection to the address, with the "tcp" or "udp" network.
+// The connection is made with the dialer of the VU, so the hosts option and
+// the blocked IPs and hostnames apply to it. With the tls param, the TLS
+// handshake is made right after the TCP connection is opened.
+func (mi *ModuleInstance) Open(network, address string, params goja.Value) (*Conn, error) {
+	state := mi.vu.State()
+	if state == nil {
+		return nil, common.NewInitContextError("opening connections in the init context is not supported")
+	}
+	if network != "tcp" && network != "udp" {
+		return nil, fmt.Errorf("unsupported network '%s', it needs to be tcp or udp", network)
+	}
+	p, err := newDialParams(mi.vu.Runtime(), params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid net.open() parameters: %w", err)
+	}
+	if p.tls != nil && network == "udp" {
+		return nil, errors.New("TLS isn't supported for the UDP connections")
+	}
+
+	ctm := state.Tags.GetCurrentValues()
+	tags := ctm.Tags.With("proto", network).With("address", address)
+	for k, v := range p.tags {
+		tags = tags.With(k, v)
+	}
+
+	ctx, cancel := context.WithTimeout(mi.vu.Context(), p.timeout)
+	defer cancel()
+
+	start := time.Now()
+	netConn, err := state.Dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	end := time.Now()
+
+	c := &Conn{
+		mi:            mi,
+		conn:          netConn,
+		network:       network,
+		address:       address,
+		timeout:       p.timeout,
+		tagsAndMeta:   metrics.TagsAndMeta{Tags: tags, Metadata: ctm.Metadata},
+		closed:        make(chan struct{}),
+		LocalAddress:  netConn.LocalAddr().String(),
+		RemoteAddress: netConn.RemoteAddr().String(),
+	}
+	go c.closeOnDone(mi.vu.Context())
+
+	metrics.PushIfNotDone(mi.vu.Context(), state.Samples, metrics.ConnectedSamples{
+		Samples: []metrics.Sample{
+			{
+				TimeSeries: metrics.TimeSeries{Metric: mi.metrics.Connections, Tags: tags},
+				Time:       end,
+				Metadata:   ctm.Metadata,
+				Value:      1,
+			},
+			{
+				TimeSeries: metrics.TimeSeries{Metric: mi.metrics.Connecting, Tags: tags},
+				Time:       end,
+				Metadata:   ctm.Metadata,
+				Value:      metrics.D(end.Sub(start)),
+			},
+		},
+		Tags: tags,
+		Time: end,
+	})
+
+	if p.tls != nil {
+		if err := c.startTLS(ctx, *p.tls); err != nil {
+			_ = c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,221 @@
+package net
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/httpmultibin"
+	"go.k6.io/k6/metrics"
+)
+
+func newTestRuntime(t *testing.T) (*modulestest.Runtime, *httpmultibin.HTTPMultiBin, chan metrics.SampleContainer) {
+	t.Helper()
+	runtime := modulestest.NewRuntime(t)
+	mi, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, runtime.VU.Runtime().Set("net", mi.Exports().Named))
+
+	tb := httpmultibin.NewHTTPMultiBin(t)
+	registry := metrics.NewRegistry()
+	samples := make(chan metrics.SampleContainer, 100)
+	runtime.MoveToVUContext(&lib.State{
+		Dialer:         tb.Dialer,
+		TLSConfig:      tb.TLSClientConfig,
+		Samples:        samples,
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+	})
+	return runtime, tb, samples
+}
+
+// newTCPEchoServer starts a TCP server that replies to each line with the line prefixed by ">",
+// and that closes the connection on the "quit" line.
+func newTCPEchoServer(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = lis.Close() })
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					line := scanner.Text()
+					if line == "quit" {
+						return
+					}
+					_, _ = conn.Write([]byte(">" + line + "\n"))
+				}
+			}()
+		}
+	}()
+	return lis.Addr().String()
+}
+
+// newUDPEchoServer starts a UDP server that sends back the received datagrams.
+func newUDPEchoServer(t *testing.T) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = conn.WriteTo(buf[:n], addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestTCP(t *testing.T) {
+	t.Parallel()
+	runtime, _, samples := newTestRuntime(t)
+	rt := runtime.VU.Runtime()
+	require.NoError(t, rt.Set("addr", newTCPEchoServer(t)))
+
+	_, err := rt.RunString(`
+		var conn = net.open("tcp", addr, { timeout: "2s", tags: { service: "echo" } });
+		if (conn.write("hello\n") !== 6) { throw new Error("wrong number of written bytes"); }
+		var reply = conn.readString();
+		if (reply !== ">hello\n") { throw new Error("unexpected reply: " + reply); }
+
+		conn.write(new Uint8Array([98, 121, 101, 10]).buffer); // bye
+		var data = new Uint8Array(conn.read({ size: 2 }));
+		if (data.length !== 2 || data[0] !== 62 || data[1] !== 98) { throw new Error("unexpected data: " + data); }
+		if (conn.readString() !== "ye\n") { throw new Error("unexpected rest of the data"); }
+
+		conn.write("quit\n");
+		if (conn.read() !== null) { throw new Error("expected the end of the connection"); }
+		if (conn.remoteAddress !== addr) { throw new Error("wrong remote address: " + conn.remoteAddress); }
+		conn.close();
+	`)
+	require.NoError(t, err)
+
+	totals := map[string]float64{}
+	for _, c := range metrics.GetBufferedSamples(samples) {
+		for _, s := range c.GetSamples() {
+			totals[s.Metric.Name] += s.Value
+			proto, _ := s.Tags.Get("proto")
+			assert.Equal(t, "tcp", proto)
+			service, _ := s.Tags.Get("service")
+			assert.Equal(t, "echo", service)
+		}
+	}
+	assert.Equal(t, float64(1), totals["net_connections"])
+	assert.Contains(t, totals, "net_connecting")
+	assert.Equal(t, float64(15), totals["net_bytes_sent"])
+	assert.Equal(t, float64(12), totals["net_bytes_received"])
+}
+
+func TestUDP(t *testing.T) {
+	t.Parallel()
+	runtime, _, _ := newTestRuntime(t)
+	rt := runtime.VU.Runtime()
+	require.NoError(t, rt.Set("addr", newUDPEchoServer(t)))
+
+	_, err := rt.RunString(`
+		var conn = net.open("udp", addr);
+		conn.write("first");
+		conn.write("second");
+		var first = conn.readString({ timeout: "2s" });
+		var second = conn.readString({ timeout: "2s" });
+		if (first !== "first" || second !== "second") { throw new Error("unexpected datagrams: " + first + ", " + second); }
+		conn.close();
+	`)
+	require.NoError(t, err)
+}
+
+func TestTLS(t *testing.T) {
+	t.Parallel()
+	runtime, tb, _ := newTestRuntime(t)
+
+	_, err := runtime.VU.Runtime().RunString(tb.Replacer.Replace(`
+		var conn = net.open("tcp", "HTTPSBIN_DOMAIN:HTTPSBIN_PORT", { tls: true });
+		conn.write("GET /get HTTP/1.1\r\nHost: HTTPSBIN_DOMAIN\r\nConnection: close\r\n\r\n");
+		var reply = conn.readString({ timeout: "2s" });
+		if (reply.indexOf("HTTP/1.1 200 OK") !== 0) { throw new Error("unexpected reply: " + reply); }
+		conn.close();
+
+		conn = net.open("tcp", "HTTPSBIN_DOMAIN:HTTPSBIN_PORT");
+		conn.startTLS({ serverName: "HTTPSBIN_DOMAIN" });
+		conn.write("GET /get HTTP/1.1\r\nHost: HTTPSBIN_DOMAIN\r\nConnection: close\r\n\r\n");
+		reply = conn.readString({ timeout: "2s" });
+		if (reply.indexOf("HTTP/1.1 200 OK") !== 0) { throw new Error("unexpected reply: " + reply); }
+		conn.close();
+	`))
+	require.NoError(t, err)
+}
+
+func TestStartTLSWhileClosedOnDone(t *testing.T) {
+	t.Parallel()
+	runtime, tb, _ := newTestRuntime(t)
+
+	v, err := runtime.VU.Runtime().RunString(tb.Replacer.Replace(`net.open("tcp", "HTTPSBIN_DOMAIN:HTTPSBIN_PORT")`))
+	require.NoError(t, err)
+	conn, ok := v.Export().(*Conn)
+	require.True(t, ok)
+
+	// the connection is closed when the VU is done, concurrently with the TLS connection replacing it
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		runtime.CancelContext()
+	}()
+	err = conn.startTLS(context.Background(), tlsParams{serverName: tb.Replacer.Replace("HTTPSBIN_DOMAIN")})
+	require.NoError(t, err)
+	<-conn.closed
+
+	_, err = conn.netConn().Write([]byte("GET / HTTP/1.1\r\n\r\n"))
+	assert.Error(t, err)
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+	runtime, _, _ := newTestRuntime(t)
+	rt := runtime.VU.Runtime()
+	require.NoError(t, rt.Set("addr", newTCPEchoServer(t)))
+
+	testCases := []struct {
+		name, script, err string
+	}{
+		{"network", `net.open("sctp", addr)`, "unsupported network 'sctp', it needs to be tcp or udp"},
+		{"unknown param", `net.open("tcp", addr, { foo: 1 })`, "unknown param 'foo'"},
+		{"udp tls", `net.open("udp", addr, { tls: true })`, "TLS isn't supported for the UDP connections"},
+		{"write", `net.open("tcp", addr).write(null)`, "missing argument, expected a string or ArrayBuffer"},
+		{"read size", `net.open("tcp", addr).read({ size: 0 })`, "invalid size value"},
+		{"read timeout", `net.open("tcp", addr).read({ timeout: 10 })`, "i/o timeout"},
+	}
+	for _, tc := range testCases {
+		_, err := rt.RunString(tc.script)
+		require.ErrorContains(t, err, tc.err, tc.name)
+	}
+}
+
+func TestOpenInInitContext(t *testing.T) {
+	t.Parallel()
+	runtime := modulestest.NewRuntime(t)
+	mi, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, runtime.VU.Runtime().Set("net", mi.Exports().Named))
+
+	_, err := runtime.VU.Runtime().RunString(`net.open("tcp", "127.0.0.1:1")`)
+	require.ErrorContains(t, err, "opening connections in the init context is not supported")
+}
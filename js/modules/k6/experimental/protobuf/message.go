@@ -0,0 +1,80 @@
+package protobuf
+
+import (
+	"fmt"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MessageType is the JS object of a message of a schema, which encodes
+// the JS objects to the message, and decodes the message to JS objects.
+//
+// The objects are mapped to the messages with the JSON mapping of Protocol
+// Buffers, as for the gRPC requests: the fields are in lowerCamelCase, the
+// 64-bit integers are strings and the bytes are base64 encoded.
+type MessageType struct {
+	// Name is the full name of the message.
+	Name string `js:"name"`
+
+	vu          modules.VU
+	schema      *schema
+	messageType protoreflect.MessageType
+}
+
+// Encode returns the ArrayBuffer of the binary encoding of the value,
+// an object of the fields of the message.
+func (m *MessageType) Encode(value goja.Value) (goja.ArrayBuffer, error) {
+	rt := m.vu.Runtime()
+	if common.IsNullish(value) {
+		value = rt.NewObject()
+	}
+	b, err := value.ToObject(rt).MarshalJSON()
+	if err != nil {
+		return goja.ArrayBuffer{}, fmt.Errorf("couldn't serialize the value of %s: %w", m.Name, err)
+	}
+
+	msg := m.messageType.New().Interface()
+	if err = (protojson.UnmarshalOptions{Resolver: m.schema.types}).Unmarshal(b, msg); err != nil {
+		return goja.ArrayBuffer{}, fmt.Errorf("invalid value of %s: %w", m.Name, err)
+	}
+	encoded, err := proto.Marshal(msg)
+	if err != nil {
+		return goja.ArrayBuffer{}, fmt.Errorf("couldn't encode %s: %w", m.Name, err)
+	}
+
+	return rt.NewArrayBuffer(encoded), nil
+}
+
+// Decode returns the object of the fields of the message, of the binary
+// encoding in the data, e.g. the body of a response. All the fields are
+// set, with their default value if they aren't in the data.
+func (m *MessageType) Decode(data goja.Value) (goja.Value, error) {
+	rt := m.vu.Runtime()
+	b, err := toBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := m.messageType.New().Interface()
+	if err = (proto.UnmarshalOptions{Resolver: m.schema.types}).Unmarshal(b, msg); err != nil {
+		return nil, fmt.Errorf("couldn't decode %s: %w", m.Name, err)
+	}
+	marshaler := protojson.MarshalOptions{EmitUnpopulated: true, Resolver: m.schema.types}
+	raw, err := marshaler.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode %s: %w", m.Name, err)
+	}
+
+	// the JSON is parsed by the runtime, so that the
+	// fields are in the order of the message
+	parse, ok := goja.AssertFunction(rt.Get("JSON").ToObject(rt).Get("parse"))
+	if !ok {
+		return nil, fmt.Errorf("couldn't decode %s: JSON.parse isn't a function", m.Name)
+	}
+	return parse(goja.Undefined(), rt.ToValue(string(raw)))
+}
@@ -0,0 +1,282 @@
+// Package protobuf implements a k6 JS module to encode and decode arbitrary
+// Protocol Buffers messages, described by .proto files or protosets, e.g. to
+// test services which speak protobuf over HTTP or WebSockets, without gRPC.
+package protobuf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dop251/goja"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct {
+		// the schemas are loaded in the init context of the first VU, and
+		// shared by all the VUs, since the descriptors are immutable.
+		mx      sync.Mutex
+		schemas map[string]*schemaEntry
+	}
+
+	// ModuleInstance represents an instance of the JS module.
+	ModuleInstance struct {
+		vu   modules.VU
+		root *RootModule
+	}
+)
+
+// Ensure the interfaces are implemented correctly
+var (
+	_ modules.Instance = &ModuleInstance{}
+	_ modules.Module   = &RootModule{}
+)
+
+// New returns a pointer to a new RootModule instance
+func New() *RootModule {
+	return &RootModule{schemas: make(map[string]*schemaEntry)}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns
+// a new instance for each VU.
+func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu, root: rm}
+}
+
+// Exports implements the modules.Instance interface and returns
+// the exports of the JS module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"load":         mi.load,
+			"loadProtoset": mi.loadProtoset,
+		},
+	}
+}
+
+// schemaEntry is a schema which is loaded once, the errors are kept
+// too, since the loading would fail in the same way for every VU.
+type schemaEntry struct {
+	once   sync.Once
+	schema *schema
+	err    error
+}
+
+// getSchema returns the schema of the key, which is loaded with
+// the function the first time.
+func (rm *RootModule) getSchema(
+	key string, load func() (*descriptorpb.FileDescriptorSet, error),
+) (*schema, error) {
+	rm.mx.Lock()
+	entry, ok := rm.schemas[key]
+	if !ok {
+		entry = &schemaEntry{}
+		rm.schemas[key] = entry
+	}
+	rm.mx.Unlock()
+
+	entry.once.Do(func() {
+		var fdset *descriptorpb.FileDescriptorSet
+		if fdset, entry.err = load(); entry.err == nil {
+			entry.schema, entry.err = newSchema(fdset)
+		}
+	})
+	return entry.schema, entry.err
+}
+
+// load parses the .proto files, found in the import paths or relative to the
+// script, and returns their Schema. Like the load of the gRPC client, it must
+// be called in the init context.
+func (mi *ModuleInstance) load(importPaths []string, filenames ...string) (*Schema, error) {
+	if mi.vu.State() != nil {
+		return nil, errors.New("load must be called in the init context")
+	}
+	initEnv := mi.vu.InitEnv()
+	if initEnv == nil {
+		return nil, errors.New("missing init environment")
+	}
+	if len(filenames) == 0 {
+		return nil, errors.New("at least a .proto file is required")
+	}
+
+	// If no import paths are specified, use the current working directory
+	if len(importPaths) == 0 {
+		importPaths = append(importPaths, initEnv.CWD.Path)
+	}
+
+	key := fmt.Sprintf("load\x00%s\x00%s\x00%s",
+		initEnv.CWD, strings.Join(importPaths, "\x00"), strings.Join(filenames, "\x00"))
+	s, err := mi.root.getSchema(key, func() (*descriptorpb.FileDescriptorSet, error) {
+		parser := protoparse.Parser{
+			ImportPaths:      importPaths,
+			InferImportPaths: false,
+			Accessor: protoparse.FileAccessor(func(filename string) (io.ReadCloser, error) {
+				absFilePath := initEnv.GetAbsFilePath(filename)
+				return initEnv.FileSystems["file"].Open(absFilePath)
+			}),
+		}
+
+		fds, err := parser.ParseFiles(filenames...)
+		if err != nil {
+			return nil, err
+		}
+
+		fdset := &descriptorpb.FileDescriptorSet{}
+		seen := make(map[string]struct{})
+		for _, fd := range fds {
+			fdset.File = append(fdset.File, walkFileDescriptors(seen, fd)...)
+		}
+		return fdset, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{Messages: s.messages, vu: mi.vu, schema: s}, nil
+}
+
+// loadProtoset reads the protoset file, a serialized FileDescriptorSet, and
+// returns its Schema. It must be called in the init context.
+func (mi *ModuleInstance) loadProtoset(protosetPath string) (*Schema, error) {
+	if mi.vu.State() != nil {
+		return nil, errors.New("loadProtoset must be called in the init context")
+	}
+	initEnv := mi.vu.InitEnv()
+	if initEnv == nil {
+		return nil, errors.New("missing init environment")
+	}
+
+	absFilePath := initEnv.GetAbsFilePath(protosetPath)
+	s, err := mi.root.getSchema("protoset\x00"+absFilePath, func() (*descriptorpb.FileDescriptorSet, error) {
+		fdsetFile, err := initEnv.FileSystems["file"].Open(absFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't open protoset: %w", err)
+		}
+
+		defer func() { _ = fdsetFile.Close() }()
+		fdsetBytes, err := io.ReadAll(fdsetFile)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read protoset: %w", err)
+		}
+
+		fdset := &descriptorpb.FileDescriptorSet{}
+		if err = proto.Unmarshal(fdsetBytes, fdset); err != nil {
+			return nil, fmt.Errorf("couldn't unmarshal protoset file %s: %w", protosetPath, err)
+		}
+		return fdset, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{Messages: s.messages, vu: mi.vu, schema: s}, nil
+}
+
+func walkFileDescriptors(seen map[string]struct{}, fd *desc.FileDescriptor) []*descriptorpb.FileDescriptorProto {
+	fds := []*descriptorpb.FileDescriptorProto{}
+
+	if _, ok := seen[fd.GetName()]; ok {
+		return fds
+	}
+	seen[fd.GetName()] = struct{}{}
+	fds = append(fds, fd.AsFileDescriptorProto())
+
+	for _, dep := range fd.GetDependencies() {
+		deps := walkFileDescriptors(seen, dep)
+		fds = append(fds, deps...)
+	}
+
+	return fds
+}
+
+// schema holds the descriptors of the loaded files, which are shared by all
+// the VUs, and the types of their messages, to resolve the Any messages.
+type schema struct {
+	types    *dynamicpb.Types
+	messages []string
+}
+
+func newSchema(fdset *descriptorpb.FileDescriptorSet) (*schema, error) {
+	files, err := protodesc.NewFiles(fdset)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []string
+	var addMessages func(protoreflect.MessageDescriptors)
+	addMessages = func(mds protoreflect.MessageDescriptors) {
+		for i := 0; i < mds.Len(); i++ {
+			md := mds.Get(i)
+			if !md.IsMapEntry() {
+				messages = append(messages, string(md.FullName()))
+			}
+			addMessages(md.Messages())
+		}
+	}
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		addMessages(fd.Messages())
+		return true
+	})
+	sort.Strings(messages)
+
+	return &schema{types: dynamicpb.NewTypes(files), messages: messages}, nil
+}
+
+// Schema is the JS object of the loaded files, to look up their messages.
+type Schema struct {
+	// Messages are the full names of all the messages of the schema,
+	// including the messages of the imported files.
+	Messages []string `js:"messages"`
+
+	vu     modules.VU
+	schema *schema
+}
+
+// Lookup returns the type of the message of the full name, e.g. acme.v1.User.
+func (s *Schema) Lookup(name string) (*MessageType, error) {
+	mt, err := s.schema.types.FindMessageByName(protoreflect.FullName(strings.TrimPrefix(name, ".")))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find the message %q: %w", name, err)
+	}
+	return &MessageType{Name: string(mt.Descriptor().FullName()), vu: s.vu, schema: s.schema, messageType: mt}, nil
+}
+
+// Encode encodes the value as a message of the full name, like the encode
+// method of its MessageType.
+func (s *Schema) Encode(name string, value goja.Value) (goja.ArrayBuffer, error) {
+	mt, err := s.Lookup(name)
+	if err != nil {
+		return goja.ArrayBuffer{}, err
+	}
+	return mt.Encode(value)
+}
+
+// Decode decodes the data as a message of the full name, like the decode
+// method of its MessageType.
+func (s *Schema) Decode(name string, data goja.Value) (goja.Value, error) {
+	mt, err := s.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return mt.Decode(data)
+}
+
+// toBytes returns the bytes of a string, an ArrayBuffer or a Uint8Array.
+func toBytes(data goja.Value) ([]byte, error) {
+	if common.IsNullish(data) {
+		return nil, errors.New("the data is required")
+	}
+	return common.ToBytes(data.Export())
+}
@@ -0,0 +1,190 @@
+package protobuf
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const userProto = `
+syntax = "proto3";
+
+package acme.v1;
+
+import "google/protobuf/timestamp.proto";
+
+enum Status {
+	STATUS_UNKNOWN = 0;
+	STATUS_ACTIVE = 1;
+}
+
+message User {
+	message Address {
+		string city = 1;
+	}
+
+	string name = 1;
+	int64 id = 2;
+	repeated string roles = 3;
+	Address address = 4;
+	map<string, string> labels = 5;
+	google.protobuf.Timestamp created = 6;
+	Status status = 7;
+}
+`
+
+func newTestRuntime(t *testing.T) *modulestest.Runtime {
+	t.Helper()
+	ts := modulestest.NewRuntime(t)
+
+	fs := fsext.NewMemMapFs()
+	require.NoError(t, fsext.WriteFile(fs, "/scripts/protos/user.proto", []byte(userProto), 0o644))
+	require.NoError(t, fsext.WriteFile(fs, "/scripts/user.protoset", newProtoset(t), 0o644))
+	ts.VU.InitEnvField.FileSystems = map[string]fsext.Fs{"file": fs}
+	ts.VU.InitEnvField.CWD = &url.URL{Scheme: "file", Path: "/scripts/"}
+
+	m, ok := New().NewModuleInstance(ts.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, ts.VU.Runtime().Set("protobuf", m.Exports().Named))
+
+	_, err := ts.VU.Runtime().RunString(`
+		function assertEquals(actual, expected) {
+			if (JSON.stringify(actual) !== JSON.stringify(expected)) {
+				throw new Error("expected " + JSON.stringify(expected) + ", got " + JSON.stringify(actual));
+			}
+		}
+	`)
+	require.NoError(t, err)
+	return ts
+}
+
+// newProtoset returns the serialized FileDescriptorSet of the user.proto file.
+func newProtoset(t *testing.T) []byte {
+	t.Helper()
+	parser := protoparse.Parser{
+		Accessor: protoparse.FileContentsFromMap(map[string]string{"user.proto": userProto}),
+	}
+	fds, err := parser.ParseFiles("user.proto")
+	require.NoError(t, err)
+
+	fdset := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]struct{})
+	for _, fd := range fds {
+		fdset.File = append(fdset.File, walkFileDescriptors(seen, fd)...)
+	}
+	b, err := proto.Marshal(fdset)
+	require.NoError(t, err)
+	return b
+}
+
+func TestEncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	for _, load := range []string{`protobuf.load(["protos"], "user.proto")`, `protobuf.loadProtoset("user.protoset")`} {
+		load := load
+		t.Run(load, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestRuntime(t)
+			_, err := ts.VU.Runtime().RunString(`var schema = ` + load)
+			require.NoError(t, err)
+			ts.MoveToVUContext(&lib.State{})
+
+			_, err = ts.VU.Runtime().RunString(`
+				assertEquals(schema.messages, ["acme.v1.User", "acme.v1.User.Address", "google.protobuf.Timestamp"]);
+
+				const User = schema.lookup("acme.v1.User");
+				assertEquals(User.name, "acme.v1.User");
+
+				const user = {
+					name: "alice",
+					id: "9007199254740993",
+					roles: ["admin", "dev"],
+					address: { city: "Athens" },
+					labels: { team: "qa" },
+					created: "2023-01-02T03:04:05Z",
+					status: "STATUS_ACTIVE",
+				};
+				const encoded = User.encode(user);
+				if (!(encoded instanceof ArrayBuffer)) {
+					throw new Error("an ArrayBuffer is expected");
+				}
+				assertEquals(User.decode(encoded), user);
+				assertEquals(User.decode(new Uint8Array(encoded)), user);
+				assertEquals(schema.decode("acme.v1.User", schema.encode("acme.v1.User", { name: "bob" })), {
+					name: "bob", id: "0", roles: [], address: null, labels: {}, created: null, status: "STATUS_UNKNOWN",
+				});
+			`)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestRuntime(t)
+	v, err := ts.VU.Runtime().RunString(`
+		protobuf.load([], "protos/user.proto").lookup(".acme.v1.User.Address").encode({ city: "a" })
+	`)
+	require.NoError(t, err)
+
+	ab, ok := v.Export().(goja.ArrayBuffer)
+	require.True(t, ok)
+	// the field 1 of the length-delimited wire type, of the length 1
+	assert.Equal(t, []byte{0x0a, 0x01, 'a'}, ab.Bytes())
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		script, err string
+	}{
+		{script: `protobuf.load([], "missing.proto")`, err: "missing.proto"},
+		{script: `protobuf.load(["protos"])`, err: "at least a .proto file is required"},
+		{script: `protobuf.loadProtoset("missing.protoset")`, err: "couldn't open protoset"},
+		{script: `protobuf.loadProtoset("protos/user.proto")`, err: "couldn't unmarshal protoset file"},
+		{
+			script: `protobuf.load(["protos"], "user.proto").lookup("acme.v1.Missing")`,
+			err:    `couldn't find the message "acme.v1.Missing"`,
+		},
+		{
+			script: `protobuf.load(["protos"], "user.proto").encode("acme.v1.User", { nickname: "a" })`,
+			err:    `invalid value of acme.v1.User: proto:`,
+		},
+		{
+			script: `protobuf.load(["protos"], "user.proto").decode("acme.v1.User", "\xff\xff")`,
+			err:    "couldn't decode acme.v1.User",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.script, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestRuntime(t)
+			_, err := ts.VU.Runtime().RunString(tc.script)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+
+	t.Run("VU context", func(t *testing.T) {
+		t.Parallel()
+
+		ts := newTestRuntime(t)
+		ts.MoveToVUContext(&lib.State{})
+		_, err := ts.VU.Runtime().RunString(`protobuf.load(["protos"], "user.proto")`)
+		require.ErrorContains(t, err, "load must be called in the init context")
+	})
+}
@@ -14,7 +14,9 @@ import (
 // returns a new Redis client object.
 type Client struct {
 	vu           modules.VU
+	metrics      *instanceMetrics
 	redisOptions *redis.UniversalOptions
+	cluster      bool
 	redisClient  redis.UniversalClient
 }
 
@@ -346,6 +348,7 @@ func (c *Client) Expire(key string, seconds int) *goja.Promise {
 }
 
 // Ttl returns the remaining time to live of a key that has a timeout.
+//
 //nolint:revive,stylecheck
 func (c *Client) Ttl(key string) *goja.Promise {
 	promise, resolve, reject := c.makeHandledPromise()
@@ -1035,6 +1038,34 @@ func (c *Client) SendCommand(command string, args ...interface{}) *goja.Promise
 	return promise
 }
 
+// Publish posts the message to the channel, and it returns the number of
+// the clients that received the message.
+func (c *Client) Publish(channel string, message interface{}) *goja.Promise {
+	promise, resolve, reject := c.makeHandledPromise()
+
+	if err := c.connect(); err != nil {
+		reject(err)
+		return promise
+	}
+
+	if err := c.isSupportedType(1, message); err != nil {
+		reject(err)
+		return promise
+	}
+
+	go func() {
+		n, err := c.redisClient.Publish(c.vu.Context(), channel, message).Result()
+		if err != nil {
+			reject(err)
+			return
+		}
+
+		resolve(n)
+	}()
+
+	return promise
+}
+
 // makeHandledPromise will create a promise and return its resolve and reject methods,
 // wrapped in such a way that it will block the eventloop from exiting before they are
 // called even if the promise isn't resolved by the time the current script ends executing.
@@ -1087,8 +1118,14 @@ func (c *Client) connect() error {
 	c.redisOptions.Dialer = vuState.Dialer.DialContext
 
 	// Replace the internal redis client instance with a new
-	// one using our custom options.
-	c.redisClient = redis.NewUniversalClient(c.redisOptions)
+	// one using our custom options. The universal client uses
+	// a ClusterClient only with two or more addresses, so it's
+	// created explicitly when the cluster option is set.
+	if c.cluster {
+		c.redisClient = redis.NewClusterClient(c.redisOptions.Cluster())
+	} else {
+		c.redisClient = redis.NewUniversalClient(c.redisOptions)
+	}
 
 	return nil
 }
@@ -0,0 +1,31 @@
+package redis
+
+import "go.k6.io/k6/metrics"
+
+// instanceMetrics contains the metrics of the pipelines and of the
+// subscriptions.
+type instanceMetrics struct {
+	PipelineDuration *metrics.Metric
+	PipelineCommands *metrics.Metric
+	MessagesReceived *metrics.Metric
+}
+
+// registerMetrics registers and returns the metrics in the provided registry
+func registerMetrics(registry *metrics.Registry) (*instanceMetrics, error) {
+	var err error
+	m := &instanceMetrics{}
+
+	if m.PipelineDuration, err = registry.NewMetric("redis_pipeline_duration", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.PipelineCommands, err = registry.NewMetric("redis_pipeline_commands", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.MessagesReceived, err = registry.NewMetric("redis_messages_received", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -21,7 +21,8 @@ type (
 
 	// ModuleInstance represents an instance of the JS module.
 	ModuleInstance struct {
-		vu modules.VU
+		vu      modules.VU
+		metrics *instanceMetrics
 
 		*Client
 	}
@@ -41,7 +42,11 @@ func New() *RootModule {
 // NewModuleInstance implements the modules.Module interface and returns
 // a new instance for each VU.
 func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
-	return &ModuleInstance{vu: vu, Client: &Client{vu: vu}}
+	m, err := registerMetrics(vu.InitEnv().Registry)
+	if err != nil {
+		common.Throw(vu.Runtime(), err)
+	}
+	return &ModuleInstance{vu: vu, metrics: m, Client: &Client{vu: vu, metrics: m}}
 }
 
 // Exports implements the modules.Instance interface and returns
@@ -60,7 +65,9 @@ func (mi *ModuleInstance) Exports() modules.Exports {
 //
 // The type of the underlying client depends on the following conditions:
 // 1. If the MasterName option is specified, a sentinel-backed FailoverClient is used.
-// 2. if the number of Addrs is two or more, a ClusterClient is used.
+// 2. If the Cluster option is true, or the number of Addrs is two or more, a
+// ClusterClient is used, which discovers the topology of the cluster from the
+// Addrs, and follows its changes.
 // 3. Otherwise, a single-node Client is used.
 //
 // To support being instantiated in the init context, while not
@@ -81,6 +88,12 @@ func (mi *ModuleInstance) NewClient(call goja.ConstructorCall) *goja.Object {
 	if err != nil {
 		common.Throw(rt, fmt.Errorf("invalid options; reason: %w", err))
 	}
+	if opts.Cluster && opts.MasterName != "" {
+		common.Throw(rt, errors.New("invalid options; reason: cluster and masterName can't be used together"))
+	}
+	if opts.Cluster && opts.DB != 0 {
+		common.Throw(rt, errors.New("invalid options; reason: db can't be selected in the cluster mode"))
+	}
 
 	redisOptions := &redis.UniversalOptions{
 		Addrs:              opts.Addrs,
@@ -110,7 +123,9 @@ func (mi *ModuleInstance) NewClient(call goja.ConstructorCall) *goja.Object {
 
 	client := &Client{
 		vu:           mi.vu,
+		metrics:      mi.metrics,
 		redisOptions: redisOptions,
+		cluster:      opts.Cluster,
 		redisClient:  nil,
 	}
 
@@ -142,6 +157,10 @@ type options struct {
 
 	MasterName string `json:"masterName,omitempty"`
 
+	// Use the Addrs as the seed nodes of a Redis Cluster, even if there
+	// is only one of them.
+	Cluster bool `json:"cluster,omitempty"`
+
 	MaxRetries      int   `json:"maxRetries,omitempty"`
 	MinRetryBackoff int64 `json:"minRetryBackoff,omitempty"`
 	MaxRetryBackoff int64 `json:"maxRetryBackoff,omitempty"`
@@ -0,0 +1,209 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/go-redis/redis/v8"
+	"go.k6.io/k6/metrics"
+)
+
+// Pipeline queues commands, which are sent to the redis server(s)
+// in a single batch by Exec.
+//
+// The commands are queued synchronously, and they return the pipeline,
+// so they can be chained (i.e. `redis.pipeline().set("a", 1).get("a").exec()`).
+// In the cluster mode, the commands are grouped by the nodes of their keys.
+type Pipeline struct {
+	client   *Client
+	commands [][]interface{}
+
+	// err is the first error of the queued commands, which rejects Exec.
+	err error
+}
+
+// Pipeline returns a new empty pipeline of the client.
+func (c *Client) Pipeline() *Pipeline {
+	return &Pipeline{client: c}
+}
+
+// Set queues the set command, the value for `expiration` is interpreted as seconds.
+func (p *Pipeline) Set(key string, value interface{}, expiration int) *Pipeline {
+	if expiration > 0 {
+		return p.queue("set", key, value, "ex", expiration)
+	}
+	return p.queue("set", key, value)
+}
+
+// Get queues the get command, the result is null if the key does not exist.
+func (p *Pipeline) Get(key string) *Pipeline {
+	return p.queue("get", key)
+}
+
+// Del queues the del command.
+func (p *Pipeline) Del(keys ...string) *Pipeline {
+	args := make([]interface{}, len(keys))
+	for i, key := range keys {
+		args[i] = key
+	}
+	return p.queue("del", args...)
+}
+
+// Incr queues the incr command.
+func (p *Pipeline) Incr(key string) *Pipeline {
+	return p.queue("incr", key)
+}
+
+// IncrBy queues the incrby command.
+func (p *Pipeline) IncrBy(key string, increment int64) *Pipeline {
+	return p.queue("incrby", key, increment)
+}
+
+// Decr queues the decr command.
+func (p *Pipeline) Decr(key string) *Pipeline {
+	return p.queue("decr", key)
+}
+
+// Expire queues the expire command.
+func (p *Pipeline) Expire(key string, seconds int) *Pipeline {
+	return p.queue("expire", key, seconds)
+}
+
+// Hset queues the hset command.
+func (p *Pipeline) Hset(key string, field string, value interface{}) *Pipeline {
+	return p.queue("hset", key, field, value)
+}
+
+// Hget queues the hget command, the result is null if the field does not exist.
+func (p *Pipeline) Hget(key, field string) *Pipeline {
+	return p.queue("hget", key, field)
+}
+
+// Lpush queues the lpush command.
+func (p *Pipeline) Lpush(key string, values ...interface{}) *Pipeline {
+	return p.queue("lpush", append([]interface{}{key}, values...)...)
+}
+
+// Rpush queues the rpush command.
+func (p *Pipeline) Rpush(key string, values ...interface{}) *Pipeline {
+	return p.queue("rpush", append([]interface{}{key}, values...)...)
+}
+
+// SendCommand queues any command.
+func (p *Pipeline) SendCommand(command string, args ...interface{}) *Pipeline {
+	if err := p.client.isSupportedType(1, args...); err != nil {
+		return p.fail(command, err)
+	}
+	if p.err == nil {
+		p.commands = append(p.commands, append([]interface{}{command}, args...))
+	}
+	return p
+}
+
+// queue appends the command to the pipeline, its first argument is the key,
+// and the types of the other ones are checked.
+func (p *Pipeline) queue(command string, args ...interface{}) *Pipeline {
+	if len(args) > 1 {
+		if err := p.client.isSupportedType(1, args[1:]...); err != nil {
+			return p.fail(command, err)
+		}
+	}
+	if p.err == nil {
+		p.commands = append(p.commands, append([]interface{}{command}, args...))
+	}
+	return p
+}
+
+// fail records the error of the command, if it's the first one.
+func (p *Pipeline) fail(command string, err error) *Pipeline {
+	if p.err == nil {
+		p.err = fmt.Errorf("%s command at index %d: %w", command, len(p.commands), err)
+	}
+	return p
+}
+
+// Len returns the number of the queued commands.
+func (p *Pipeline) Len() int {
+	return len(p.commands)
+}
+
+// Exec sends the queued commands, and it resolves the promise with the
+// array of their results, in the same order. The pipeline is emptied, so
+// it can be reused.
+//
+// The duration of the whole batch is emitted as the redis_pipeline_duration
+// metric. If a command fails, the promise is rejected with its error, the
+// keys which do not exist are not errors but null results.
+func (p *Pipeline) Exec() *goja.Promise {
+	c := p.client
+	promise, resolve, reject := c.makeHandledPromise()
+
+	commands, queueErr := p.commands, p.err
+	p.commands, p.err = nil, nil
+	if queueErr != nil {
+		reject(queueErr)
+		return promise
+	}
+
+	if err := c.connect(); err != nil {
+		reject(err)
+		return promise
+	}
+
+	if len(commands) == 0 {
+		resolve([]interface{}{})
+		return promise
+	}
+
+	state := c.vu.State()
+	ctm := state.Tags.GetCurrentValues()
+
+	go func() {
+		ctx := c.vu.Context()
+		pipe := c.redisClient.Pipeline()
+		cmds := make([]*redis.Cmd, len(commands))
+		for i, args := range commands {
+			cmds[i] = pipe.Do(ctx, args...)
+		}
+
+		start := time.Now()
+		// the error of Exec is the first error of the commands,
+		// which are checked one by one below
+		_, _ = pipe.Exec(ctx)
+		end := time.Now()
+
+		metrics.PushIfNotDone(ctx, state.Samples, metrics.ConnectedSamples{
+			Samples: []metrics.Sample{
+				{
+					TimeSeries: metrics.TimeSeries{Metric: c.metrics.PipelineDuration, Tags: ctm.Tags},
+					Time:       end, Metadata: ctm.Metadata, Value: metrics.D(end.Sub(start)),
+				},
+				{
+					TimeSeries: metrics.TimeSeries{Metric: c.metrics.PipelineCommands, Tags: ctm.Tags},
+					Time:       end, Metadata: ctm.Metadata, Value: float64(len(commands)),
+				},
+			},
+			Tags: ctm.Tags,
+			Time: end,
+		})
+
+		results := make([]interface{}, len(cmds))
+		for i, cmd := range cmds {
+			result, err := cmd.Result()
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			if err != nil {
+				reject(fmt.Errorf("%s command at index %d failed: %w", cmd.Name(), i, err))
+				return
+			}
+			results[i] = result
+		}
+
+		resolve(results)
+	}()
+
+	return promise
+}
@@ -0,0 +1,157 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/metrics"
+)
+
+func TestPipelineExec(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestSetup(t)
+	rs := RunT(t)
+	rs.RegisterCommandHandler("SET", func(c *Connection, args []string) {
+		c.WriteOK()
+	})
+	rs.RegisterCommandHandler("GET", func(c *Connection, args []string) {
+		if args[0] == "existing_key" {
+			c.WriteBulkString("old_value")
+			return
+		}
+		c.WriteNull()
+	})
+	rs.RegisterCommandHandler("INCR", func(c *Connection, args []string) {
+		c.WriteInteger(11)
+	})
+
+	gotScriptErr := ts.ev.Start(func() error {
+		_, err := ts.rt.RunString(fmt.Sprintf(`
+			const redis = new Client({
+				addrs: new Array("%s"),
+			});
+
+			const pipeline = redis.pipeline()
+				.get("existing_key")
+				.set("existing_key", "new_value", 10)
+				.get("non_existing_key")
+				.incr("counter");
+			if (pipeline.len() !== 4) {
+				throw 'unexpected number of queued commands: ' + pipeline.len()
+			}
+
+			pipeline.exec()
+				.then(res => {
+					if (JSON.stringify(res) !== '["old_value","OK",null,11]') {
+						throw 'unexpected value for exec result: ' + JSON.stringify(res)
+					}
+					if (pipeline.len() !== 0) {
+						throw 'the pipeline was not emptied'
+					}
+				})
+				.then(() => pipeline.exec())
+				.then(res => { if (res.length !== 0) { throw 'unexpected value for empty exec result: ' + res } })
+		`, rs.Addr()))
+
+		return err
+	})
+
+	require.NoError(t, gotScriptErr)
+	assert.Equal(t, [][]string{
+		{"GET", "existing_key"},
+		{"SET", "existing_key", "new_value", "ex", "10"},
+		{"GET", "non_existing_key"},
+		{"INCR", "counter"},
+	}, rs.GotCommands())
+
+	var durations int
+	var commands float64
+	for _, c := range metrics.GetBufferedSamples(ts.samples) {
+		for _, s := range c.GetSamples() {
+			switch s.Metric.Name {
+			case "redis_pipeline_duration":
+				durations++
+			case "redis_pipeline_commands":
+				commands += s.Value
+			}
+		}
+	}
+	assert.Equal(t, 1, durations)
+	assert.Equal(t, float64(4), commands)
+}
+
+func TestPipelineErrors(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestSetup(t)
+	rs := RunT(t)
+	rs.RegisterCommandHandler("SET", func(c *Connection, args []string) {
+		c.WriteOK()
+	})
+	rs.RegisterCommandHandler("LPUSH", func(c *Connection, args []string) {
+		c.WriteError(errors.New("WRONGTYPE Operation against a key holding the wrong kind of value"))
+	})
+
+	gotScriptErr := ts.ev.Start(func() error {
+		_, err := ts.rt.RunString(fmt.Sprintf(`
+			const redis = new Client({
+				addrs: new Array("%s"),
+			});
+
+			redis.pipeline()
+				.set("key", "value")
+				.set("unsupported_type", new Array("unsupported"))
+				.exec()
+				.then(
+					res => { throw 'expected to fail queuing an unsupported type' },
+					err => {
+						if (!err.error().startsWith('set command at index 1: unsupported type')) {
+							throw 'unexpected error: ' + err
+						}
+					}
+				)
+				.then(() => redis.pipeline().set("key", "value").lpush("key", "a").exec())
+				.then(
+					res => { throw 'expected to fail the lpush command' },
+					err => {
+						if (!err.error().startsWith('lpush command at index 1 failed: WRONGTYPE')) {
+							throw 'unexpected error: ' + err
+						}
+					}
+				)
+		`, rs.Addr()))
+
+		return err
+	})
+
+	require.NoError(t, gotScriptErr)
+	assert.Equal(t, [][]string{
+		{"SET", "key", "value"},
+		{"LPUSH", "key", "a"},
+	}, rs.GotCommands())
+}
+
+func TestPipelineInInitContext(t *testing.T) {
+	t.Parallel()
+
+	ts := newInitContextTestSetup(t)
+
+	gotScriptErr := ts.ev.Start(func() error {
+		_, err := ts.rt.RunString(`
+			const redis = new Client({
+				addrs: new Array("unreachable:42424"),
+			});
+
+			redis.pipeline().get("key").exec()
+				.then(res => { throw 'expected to fail executing the pipeline in the init context' })
+		`)
+
+		return err
+	})
+
+	assert.Error(t, gotScriptErr)
+}
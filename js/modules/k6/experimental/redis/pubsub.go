@@ -0,0 +1,160 @@
+package redis
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/go-redis/redis/v8"
+	"github.com/mstoykov/k6-taskqueue-lib/taskqueue"
+	"go.k6.io/k6/metrics"
+)
+
+// Subscription is a subscription to channels, or to channel patterns, which
+// calls its callback with the received messages, until it's unsubscribed or
+// the VU is done.
+//
+// While it is active, the subscription keeps the iteration running.
+type Subscription struct {
+	client   *Client
+	pubsub   *redis.PubSub
+	callback goja.Callable
+	tq       *taskqueue.TaskQueue
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Subscribe subscribes the client to the channels, the callback is called
+// with the messages posted to them. The promise is resolved with the
+// subscription once the redis server confirmed it.
+func (c *Client) Subscribe(channels []string, callback goja.Value) *goja.Promise {
+	return c.subscribe("subscribe", channels, callback)
+}
+
+// Psubscribe subscribes the client to the channels matching the glob-style
+// patterns, the callback is called with the messages posted to them. The
+// promise is resolved with the subscription once the redis server confirmed it.
+func (c *Client) Psubscribe(patterns []string, callback goja.Value) *goja.Promise {
+	return c.subscribe("psubscribe", patterns, callback)
+}
+
+func (c *Client) subscribe(method string, channels []string, callback goja.Value) *goja.Promise {
+	promise, resolve, reject := c.makeHandledPromise()
+
+	fn, ok := goja.AssertFunction(callback)
+	if !ok {
+		reject(fmt.Errorf("%s() requires a callback function for the messages", method))
+		return promise
+	}
+
+	if len(channels) == 0 {
+		reject(fmt.Errorf("%s() requires at least one channel", method))
+		return promise
+	}
+
+	if err := c.connect(); err != nil {
+		reject(err)
+		return promise
+	}
+
+	state := c.vu.State()
+	ctm := state.Tags.GetCurrentValues()
+	s := &Subscription{
+		client:   c,
+		callback: fn,
+		tq:       taskqueue.New(c.vu.RegisterCallback),
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		ctx := c.vu.Context()
+		if method == "psubscribe" {
+			s.pubsub = c.redisClient.PSubscribe(ctx, channels...)
+		} else {
+			s.pubsub = c.redisClient.Subscribe(ctx, channels...)
+		}
+
+		// the first reply is the confirmation of the subscription, or its error
+		if _, err := s.pubsub.Receive(ctx); err != nil {
+			_ = s.pubsub.Close()
+			s.tq.Close()
+			reject(err)
+			return
+		}
+		resolve(s)
+
+		defer func() {
+			_ = s.pubsub.Close()
+			s.tq.Close()
+		}()
+		messages := s.pubsub.Channel()
+		for {
+			select {
+			case msg, ok := <-messages:
+				if !ok {
+					s.close()
+					return
+				}
+				s.queueMessage(msg, state.Samples, ctm)
+			case <-s.done:
+				return
+			case <-ctx.Done():
+				s.close()
+				return
+			}
+		}
+	}()
+
+	return promise
+}
+
+// queueMessage passes the message to the callback on the event loop.
+func (s *Subscription) queueMessage(
+	msg *redis.Message, samples chan<- metrics.SampleContainer, ctm metrics.TagsAndMeta,
+) {
+	tags := ctm.Tags.With("channel", msg.Channel)
+	now := time.Now()
+	metrics.PushIfNotDone(s.client.vu.Context(), samples, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{Metric: s.client.metrics.MessagesReceived, Tags: tags},
+		Time:       now, Metadata: ctm.Metadata, Value: 1,
+	})
+
+	s.tq.Queue(func() error {
+		select {
+		case <-s.done:
+			// the messages received before the unsubscription are dropped
+			return nil
+		default:
+		}
+
+		rt := s.client.vu.Runtime()
+		obj := rt.NewObject()
+		for k, v := range map[string]interface{}{
+			"channel": msg.Channel,
+			"pattern": msg.Pattern,
+			"payload": msg.Payload,
+		} {
+			if err := obj.Set(k, v); err != nil {
+				return err
+			}
+		}
+		if _, err := s.callback(goja.Undefined(), obj); err != nil {
+			s.close()
+			return err
+		}
+		return nil
+	})
+}
+
+// Unsubscribe ends the subscription, the callback isn't called anymore.
+func (s *Subscription) Unsubscribe() {
+	s.close()
+}
+
+func (s *Subscription) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
@@ -0,0 +1,131 @@
+package redis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/metrics"
+)
+
+func TestClientSubscribe(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestSetup(t)
+	rs := RunT(t)
+	rs.RegisterCommandHandler("SUBSCRIBE", func(c *Connection, args []string) {
+		for i, channel := range args {
+			c.WriteValues("subscribe", channel, i+1)
+		}
+		c.WriteValues("message", "orders", "first")
+		c.WriteValues("message", "payments", "second")
+	})
+
+	gotScriptErr := ts.ev.Start(func() error {
+		_, err := ts.rt.RunString(fmt.Sprintf(`
+			const redis = new Client({
+				addrs: new Array("%s"),
+			});
+
+			const received = [];
+			redis.subscribe(["orders", "payments"], msg => {
+				received.push(msg.channel + ":" + msg.payload);
+				if (received.length === 2) {
+					subscription.unsubscribe();
+				}
+			})
+			.then(sub => { subscription = sub; });
+			var subscription;
+
+			redis.subscribe([], msg => {})
+				.then(
+					res => { throw 'expected to fail subscribing to no channels' },
+					err => { if (!err.error().startsWith('subscribe() requires at least one channel')) { throw err } }
+				)
+		`, rs.Addr()))
+
+		return err
+	})
+	require.NoError(t, gotScriptErr)
+
+	received, err := ts.rt.RunString(`received.join(",")`)
+	require.NoError(t, err)
+	assert.Equal(t, "orders:first,payments:second", received.String())
+	assert.Equal(t, [][]string{{"SUBSCRIBE", "orders", "payments"}}, rs.GotCommands())
+
+	messages := map[string]float64{}
+	for _, c := range metrics.GetBufferedSamples(ts.samples) {
+		for _, s := range c.GetSamples() {
+			if s.Metric.Name == "redis_messages_received" {
+				channel, _ := s.Tags.Get("channel")
+				messages[channel] += s.Value
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{"orders": 1, "payments": 1}, messages)
+}
+
+func TestClientPsubscribe(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestSetup(t)
+	rs := RunT(t)
+	rs.RegisterCommandHandler("PSUBSCRIBE", func(c *Connection, args []string) {
+		c.WriteValues("psubscribe", args[0], 1)
+		c.WriteValues("pmessage", args[0], "orders.created", "42")
+	})
+
+	gotScriptErr := ts.ev.Start(func() error {
+		_, err := ts.rt.RunString(fmt.Sprintf(`
+			const redis = new Client({
+				addrs: new Array("%s"),
+			});
+
+			var message;
+			redis.psubscribe(["orders.*"], msg => {
+				message = msg;
+				throw 'stop';
+			})
+		`, rs.Addr()))
+
+		return err
+	})
+	// the errors of the callback are the errors of the iteration
+	require.ErrorContains(t, gotScriptErr, "stop")
+
+	message, err := ts.rt.RunString(`JSON.stringify(message)`)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"channel":"orders.created","pattern":"orders.*","payload":"42"}`, message.String())
+}
+
+func TestClientPublish(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestSetup(t)
+	rs := RunT(t)
+	rs.RegisterCommandHandler("PUBLISH", func(c *Connection, args []string) {
+		c.WriteInteger(2)
+	})
+
+	gotScriptErr := ts.ev.Start(func() error {
+		_, err := ts.rt.RunString(fmt.Sprintf(`
+			const redis = new Client({
+				addrs: new Array("%s"),
+			});
+
+			redis.publish("orders", "created")
+				.then(res => { if (res !== 2) { throw 'unexpected value for publish result: ' + res } })
+				.then(() => redis.publish("orders", new Array("unsupported")))
+				.then(
+					res => { throw 'expected to fail publishing unsupported type' },
+					err => { if (!err.error().startsWith('unsupported type')) { throw 'unexpected error: ' + err } }
+				)
+		`, rs.Addr()))
+
+		return err
+	})
+
+	require.NoError(t, gotScriptErr)
+	assert.Equal(t, [][]string{{"PUBLISH", "orders", "created"}}, rs.GotCommands())
+}
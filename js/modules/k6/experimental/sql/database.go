@@ -0,0 +1,241 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/metrics"
+)
+
+// queryer is implemented by the databases and the transactions.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// queryRunner runs the queries of a database or a transaction, and it
+// emits their metrics.
+type queryRunner struct {
+	mi      *ModuleInstance
+	q       queryer
+	driver  string
+	timeout time.Duration
+	tags    map[string]string
+}
+
+// Database is a database with a pool of connections.
+type Database struct {
+	queryRunner
+	db *sql.DB
+}
+
+// Transaction is a transaction of a database, it uses one connection of the
+// pool until it's committed or rolled back.
+type Transaction struct {
+	queryRunner
+	tx *sql.Tx
+}
+
+// ExecResult is the result of the exec method, the lastInsertId is 0 with
+// the postgres driver, which requires a RETURNING clause instead.
+type ExecResult struct {
+	RowsAffected int64 `js:"rowsAffected"`
+	LastInsertID int64 `js:"lastInsertId"`
+}
+
+// Query runs the query with the arguments, and it returns its rows as
+// objects with the columns as properties. The placeholders of the
+// arguments are $1, $2, ... with postgres and ? with mysql.
+func (r *queryRunner) Query(query string, args ...goja.Value) ([]*goja.Object, error) {
+	var result []*goja.Object
+	err := r.run("query", query, args, func(ctx context.Context, values []interface{}) error {
+		rows, err := r.q.QueryContext(ctx, query, values...)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = rows.Close() }()
+		result, err = r.toObjects(rows)
+		return err
+	})
+	return result, err
+}
+
+// Exec runs the statement with the arguments, and it returns the number of
+// the affected rows and the last inserted id.
+func (r *queryRunner) Exec(query string, args ...goja.Value) (*ExecResult, error) {
+	var result *ExecResult
+	err := r.run("exec", query, args, func(ctx context.Context, values []interface{}) error {
+		res, err := r.q.ExecContext(ctx, query, values...)
+		if err != nil {
+			return err
+		}
+		result = &ExecResult{}
+		result.RowsAffected, _ = res.RowsAffected()
+		result.LastInsertID, _ = res.LastInsertId()
+		return nil
+	})
+	return result, err
+}
+
+func (r *queryRunner) run(
+	method, query string, args []goja.Value, fn func(ctx context.Context, values []interface{}) error,
+) error {
+	state := r.mi.vu.State()
+	if state == nil {
+		return common.NewInitContextError("executing SQL queries in the init context is not supported")
+	}
+	values, err := exportArgs(args)
+	if err != nil {
+		return fmt.Errorf("invalid %s() arguments: %w", method, err)
+	}
+
+	ctm := state.Tags.GetCurrentValues()
+	tags := ctm.Tags.With("driver", r.driver).With("operation", operation(query))
+	for k, v := range r.tags {
+		tags = tags.With(k, v)
+	}
+
+	ctx, cancel := context.WithTimeout(r.mi.vu.Context(), r.timeout)
+	defer cancel()
+	start := time.Now()
+	err = fn(ctx, values)
+	end := time.Now()
+
+	samples := []metrics.Sample{
+		{
+			TimeSeries: metrics.TimeSeries{Metric: r.mi.metrics.Queries, Tags: tags},
+			Time:       end, Metadata: ctm.Metadata, Value: 1,
+		},
+		{
+			TimeSeries: metrics.TimeSeries{Metric: r.mi.metrics.QueryDuration, Tags: tags},
+			Time:       end, Metadata: ctm.Metadata, Value: metrics.D(end.Sub(start)),
+		},
+	}
+	if err != nil {
+		samples = append(samples, metrics.Sample{
+			TimeSeries: metrics.TimeSeries{Metric: r.mi.metrics.QueryErrors, Tags: tags},
+			Time:       end, Metadata: ctm.Metadata, Value: 1,
+		})
+	}
+	metrics.PushIfNotDone(r.mi.vu.Context(), state.Samples, metrics.ConnectedSamples{
+		Samples: samples, Tags: tags, Time: end,
+	})
+	return err
+}
+
+// operation returns the first keyword of the query in lower case, like
+// select or insert, which is the operation tag of the metrics.
+func operation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	op := strings.ToLower(fields[0])
+	if i := strings.IndexFunc(op, func(r rune) bool { return r < 'a' || r > 'z' }); i >= 0 {
+		op = op[:i]
+	}
+	return op
+}
+
+// exportArgs converts the arguments to the values of the drivers, the
+// ArrayBuffers are passed as bytes, and the objects and the arrays as JSON.
+func exportArgs(args []goja.Value) ([]interface{}, error) {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		if common.IsNullish(arg) {
+			continue
+		}
+		switch v := arg.Export().(type) {
+		case int64, float64, string, bool, time.Time:
+			values[i] = v
+		case goja.ArrayBuffer:
+			values[i] = v.Bytes()
+		case map[string]interface{}, []interface{}:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("argument %d: %w", i+1, err)
+			}
+			values[i] = string(b)
+		default:
+			return nil, fmt.Errorf("argument %d has the unsupported type %T", i+1, v)
+		}
+	}
+	return values, nil
+}
+
+// toObjects reads the rows as objects, the binary values are converted to
+// ArrayBuffers and the times to Dates.
+func (r *queryRunner) toObjects(rows *sql.Rows) ([]*goja.Object, error) {
+	rt := r.mi.vu.Runtime()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := []*goja.Object{}
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		obj := rt.NewObject()
+		for i, column := range columns {
+			var v interface{}
+			switch value := values[i].(type) {
+			case []byte:
+				v = rt.NewArrayBuffer(value)
+			case time.Time:
+				if v, err = rt.New(rt.Get("Date"), rt.ToValue(value.UnixMilli())); err != nil {
+					return nil, err
+				}
+			default:
+				v = value
+			}
+			if err = obj.Set(column, v); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, obj)
+	}
+	return result, rows.Err()
+}
+
+// Begin begins a transaction.
+func (d *Database) Begin() (*Transaction, error) {
+	if d.mi.vu.State() == nil {
+		return nil, common.NewInitContextError("beginning SQL transactions in the init context is not supported")
+	}
+	// the transaction is rolled back if it isn't committed by the end of the iteration
+	tx, err := d.db.BeginTx(d.mi.vu.Context(), nil)
+	if err != nil {
+		return nil, err
+	}
+	runner := d.queryRunner
+	runner.q = tx
+	return &Transaction{queryRunner: runner, tx: tx}, nil
+}
+
+// Close closes the connections of the database.
+func (d *Database) Close() error {
+	return d.db.Close()
+}
+
+// Commit commits the transaction.
+func (t *Transaction) Commit() error {
+	return t.tx.Commit()
+}
+
+// Rollback rolls back the transaction.
+func (t *Transaction) Rollback() error {
+	return t.tx.Rollback()
+}
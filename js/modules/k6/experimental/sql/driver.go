@@ -0,0 +1,240 @@
+package sql
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// dialer opens the network connections of the drivers, with the dialer and
+// the TLS config of the VU.
+type dialer interface {
+	dial(ctx context.Context, address string) (net.Conn, error)
+	tlsConfig(serverName string, verify bool) *tls.Config
+}
+
+// openFunc opens a connection of a driver.
+type openFunc func(ctx context.Context, d dialer, dsn string) (driver.Conn, error)
+
+// The drivers of the module, they are implemented in the module as they are
+// used with the connections of the VUs, like the other protocols.
+var drivers = map[string]openFunc{ //nolint:gochecknoglobals
+	"postgres": openPostgres,
+	"mysql":    openMySQL,
+}
+
+// connector is the driver.Connector of a database, it's used instead of
+// registering the drivers, as the connections are opened with the dialer of
+// the VU.
+type connector struct {
+	open   openFunc
+	dialer dialer
+	dsn    string
+}
+
+var _ driver.Connector = &connector{}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.open(ctx, c.dialer, c.dsn)
+}
+
+func (c *connector) Driver() driver.Driver {
+	return unsupportedDriver{}
+}
+
+// unsupportedDriver is the driver of the connectors, which can't open the
+// connections without the connector.
+type unsupportedDriver struct{}
+
+func (unsupportedDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("the connections can be opened only by the connector")
+}
+
+// wireConn is the protocol of a database, the statements are executed with
+// their arguments at once, and the rows are read entirely.
+type wireConn interface {
+	query(ctx context.Context, query string, args []driver.NamedValue) (*rows, error)
+	exec(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error)
+	close() error
+	valid() bool
+}
+
+// conn implements the database/sql interfaces for a wireConn.
+type conn struct {
+	wire wireConn
+	// begin, commit and rollback are the statements of the transactions
+	begin, commit, rollback string
+}
+
+var (
+	_ driver.Conn               = &conn{}
+	_ driver.ConnBeginTx        = &conn{}
+	_ driver.QueryerContext     = &conn{}
+	_ driver.ExecerContext      = &conn{}
+	_ driver.Validator          = &conn{}
+	_ driver.NamedValueChecker  = &conn{}
+	_ driver.ConnPrepareContext = &conn{}
+)
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) PrepareContext(_ context.Context, query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	return c.wire.close()
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if opts.Isolation != 0 || opts.ReadOnly {
+		return nil, errors.New("the isolation levels and the read-only transactions aren't supported")
+	}
+	if _, err := c.wire.exec(ctx, c.begin, nil); err != nil {
+		return nil, err
+	}
+	return &tx{conn: c}, nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.wire.query(ctx, query, args)
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.wire.exec(ctx, query, args)
+}
+
+func (c *conn) IsValid() bool {
+	return c.wire.valid()
+}
+
+// CheckNamedValue accepts the values of the default converter, and the
+// named arguments are rejected, as the protocols have positional ones.
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nv.Name != "" {
+		return errors.New("the named arguments aren't supported")
+	}
+	var err error
+	nv.Value, err = driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	return err
+}
+
+type tx struct {
+	conn *conn
+}
+
+func (t *tx) Commit() error {
+	_, err := t.conn.wire.exec(context.Background(), t.conn.commit, nil)
+	return err
+}
+
+func (t *tx) Rollback() error {
+	_, err := t.conn.wire.exec(context.Background(), t.conn.rollback, nil)
+	return err
+}
+
+// stmt is a statement that isn't prepared on the server, it's executed as a
+// query of its connection.
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+var (
+	_ driver.StmtQueryContext = &stmt{}
+	_ driver.StmtExecContext  = &stmt{}
+)
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("deprecated Exec isn't supported")
+}
+
+func (s *stmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("deprecated Query isn't supported")
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.wire.exec(ctx, s.query, args)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.wire.query(ctx, s.query, args)
+}
+
+// rows are the rows of a query, which are read entirely by the protocols.
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *rows) Columns() []string { return r.columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// result is the result of an exec, lastInsertID is -1 if the protocol
+// doesn't have it.
+type result struct {
+	rowsAffected int64
+	lastInsertID int64
+}
+
+func (r result) LastInsertId() (int64, error) {
+	if r.lastInsertID < 0 {
+		return 0, errors.New("the last insert id isn't supported by the driver")
+	}
+	return r.lastInsertID, nil
+}
+
+func (r result) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+// DatabaseError is an error returned by the database server.
+type DatabaseError struct {
+	// Code is the SQLSTATE code of Postgres, or the error number of MySQL.
+	Code    string
+	Message string
+}
+
+func (e *DatabaseError) Error() string {
+	return fmt.Sprintf("%s (%s)", e.Message, e.Code)
+}
+
+// isNetError returns true if the error breaks the connection, so it
+// isn't reused.
+func isNetError(err error) bool {
+	var dbErr *DatabaseError
+	return err != nil && !errors.As(err, &dbErr)
+}
+
+// splitHostPort returns the host and the port of the address, with the
+// default port if it doesn't have any.
+func splitHostPort(address, defaultPort string) (string, string) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return strings.Trim(address, "[]"), defaultPort
+	}
+	return host, port
+}
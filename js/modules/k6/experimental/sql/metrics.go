@@ -0,0 +1,30 @@
+package sql
+
+import "go.k6.io/k6/metrics"
+
+// instanceMetrics contains the metrics of the SQL queries.
+type instanceMetrics struct {
+	Queries       *metrics.Metric
+	QueryDuration *metrics.Metric
+	QueryErrors   *metrics.Metric
+}
+
+// registerMetrics registers and returns the metrics in the provided registry
+func registerMetrics(registry *metrics.Registry) (*instanceMetrics, error) {
+	var err error
+	m := &instanceMetrics{}
+
+	if m.Queries, err = registry.NewMetric("sql_queries", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.QueryDuration, err = registry.NewMetric("sql_query_duration", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.QueryErrors, err = registry.NewMetric("sql_query_errors", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,164 @@
+// Package sql implements the k6/experimental/sql module, a client of the
+// PostgreSQL and MySQL databases, for seeding the data, verifying the side
+// effects of the tests and load testing the databases.
+package sql
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib/types"
+)
+
+// defaultTimeout is the timeout of the queries, when the timeout option
+// isn't provided, the same as for the HTTP requests.
+const defaultTimeout = time.Minute
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the JS module.
+	ModuleInstance struct {
+		vu      modules.VU
+		metrics *instanceMetrics
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new RootModule instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface to return
+// a new instance for each VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	m, err := registerMetrics(vu.InitEnv().Registry)
+	if err != nil {
+		common.Throw(vu.Runtime(), err)
+	}
+	return &ModuleInstance{vu: vu, metrics: m}
+}
+
+// Exports returns the exports of the sql module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"open": mi.Open,
+		},
+	}
+}
+
+// openOptions are the options of the open function.
+type openOptions struct {
+	maxOpenConns    int
+	maxIdleConns    int
+	connMaxLifetime time.Duration
+	connMaxIdleTime time.Duration
+	timeout         time.Duration
+	tags            map[string]string
+}
+
+func newOpenOptions(rt *goja.Runtime, input goja.Value) (openOptions, error) {
+	// the pool of every VU has one idle connection by default, as the
+	// queries of a VU are made one at a time
+	opts := openOptions{maxIdleConns: 1, timeout: defaultTimeout}
+	if common.IsNullish(input) {
+		return opts, nil
+	}
+	raw := input.ToObject(rt)
+	for _, k := range raw.Keys() {
+		v := raw.Get(k)
+		var err error
+		switch k {
+		case "maxOpenConns":
+			opts.maxOpenConns = int(v.ToInteger())
+		case "maxIdleConns":
+			opts.maxIdleConns = int(v.ToInteger())
+		case "connMaxLifetime":
+			opts.connMaxLifetime, err = types.GetDurationValue(v.Export())
+		case "connMaxIdleTime":
+			opts.connMaxIdleTime, err = types.GetDurationValue(v.Export())
+		case "timeout":
+			opts.timeout, err = types.GetDurationValue(v.Export())
+		case "tags":
+			err = rt.ExportTo(v, &opts.tags)
+		default:
+			return opts, fmt.Errorf("unknown option '%s'", k)
+		}
+		if err != nil {
+			return opts, fmt.Errorf("invalid %s value: %w", k, err)
+		}
+	}
+	if opts.timeout <= 0 {
+		return opts, fmt.Errorf("invalid timeout value: %s", opts.timeout)
+	}
+	return opts, nil
+}
+
+// Open returns the database of the DSN, with the postgres or the mysql
+// driver. The database can be opened in the init context, as its pool of
+// connections is opened on the first queries, with the dialer of the VU.
+func (mi *ModuleInstance) Open(driverName, dsn string, options goja.Value) (*Database, error) {
+	open, ok := drivers[driverName]
+	if !ok {
+		return nil, fmt.Errorf("unsupported driver '%s', it needs to be postgres or mysql", driverName)
+	}
+	opts, err := newOpenOptions(mi.vu.Runtime(), options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid open() options: %w", err)
+	}
+
+	db := sql.OpenDB(&connector{open: open, dialer: vuDialer{vu: mi.vu}, dsn: dsn})
+	db.SetMaxOpenConns(opts.maxOpenConns)
+	db.SetMaxIdleConns(opts.maxIdleConns)
+	db.SetConnMaxLifetime(opts.connMaxLifetime)
+	db.SetConnMaxIdleTime(opts.connMaxIdleTime)
+	return &Database{
+		queryRunner: queryRunner{mi: mi, q: db, driver: driverName, timeout: opts.timeout, tags: opts.tags},
+		db:          db,
+	}, nil
+}
+
+// vuDialer opens the connections with the dialer of the VU, so the hosts
+// and the blocked hostnames and IPs are applied to the databases too.
+type vuDialer struct {
+	vu modules.VU
+}
+
+func (d vuDialer) dial(ctx context.Context, address string) (net.Conn, error) {
+	state := d.vu.State()
+	if state == nil {
+		return nil, errors.New("connecting to a database in the init context is not supported")
+	}
+	return state.Dialer.DialContext(ctx, "tcp", address)
+}
+
+// tlsConfig returns the TLS config of the VU, which has the tlsAuth and
+// the other TLS options, with the server name.
+func (d vuDialer) tlsConfig(serverName string, verify bool) *tls.Config {
+	config := &tls.Config{MinVersion: tls.VersionTLS12} //nolint:gosec
+	if state := d.vu.State(); state != nil && state.TLSConfig != nil {
+		config = state.TLSConfig.Clone()
+	}
+	config.ServerName = serverName
+	if !verify {
+		config.InsecureSkipVerify = true
+	}
+	return config
+}
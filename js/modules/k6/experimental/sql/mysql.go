@@ -0,0 +1,962 @@
+package sql
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const mysqlDefaultPort = "3306"
+
+// The capability flags of the client.
+const (
+	mysqlClientLongPassword     = 0x00000001
+	mysqlClientLongFlag         = 0x00000004
+	mysqlClientConnectWithDB    = 0x00000008
+	mysqlClientProtocol41       = 0x00000200
+	mysqlClientSSL              = 0x00000800
+	mysqlClientTransactions     = 0x00002000
+	mysqlClientSecureConn       = 0x00008000
+	mysqlClientMultiResults     = 0x00020000
+	mysqlClientPluginAuth       = 0x00080000
+	mysqlClientPluginAuthLenenc = 0x00200000
+)
+
+const (
+	mysqlComQuit        = 0x01
+	mysqlComQuery       = 0x03
+	mysqlComStmtPrepare = 0x16
+	mysqlComStmtExecute = 0x17
+	mysqlComStmtClose   = 0x19
+
+	mysqlMaxPacketSize       = 1<<24 - 1
+	mysqlCharsetUTF8MB4      = 45
+	mysqlCharsetBinary       = 63
+	mysqlServerMoreResults   = 0x0008
+	mysqlUnsignedFlag        = 0x0020
+	mysqlNativePassword      = "mysql_native_password"
+	mysqlCachingSHA2Password = "caching_sha2_password"
+)
+
+// The types of the columns and of the parameters.
+const (
+	mysqlTypeDecimal    = 0x00
+	mysqlTypeTiny       = 0x01
+	mysqlTypeShort      = 0x02
+	mysqlTypeLong       = 0x03
+	mysqlTypeFloat      = 0x04
+	mysqlTypeDouble     = 0x05
+	mysqlTypeNull       = 0x06
+	mysqlTypeTimestamp  = 0x07
+	mysqlTypeLongLong   = 0x08
+	mysqlTypeInt24      = 0x09
+	mysqlTypeDate       = 0x0a
+	mysqlTypeTime       = 0x0b
+	mysqlTypeDateTime   = 0x0c
+	mysqlTypeYear       = 0x0d
+	mysqlTypeBit        = 0x10
+	mysqlTypeNewDecimal = 0xf6
+	mysqlTypeBlob       = 0xfc
+	mysqlTypeString     = 0xfe
+)
+
+// mysqlConfig is the config of the connections of the mysql driver.
+type mysqlConfig struct {
+	address  string
+	user     string
+	password string
+	database string
+	// tls is empty, "true" or "skip-verify"
+	tls string
+}
+
+// parseMySQLDSN parses the mysql:// URLs and the DSNs of the Go MySQL
+// driver, like user:password@tcp(host:3306)/database.
+func parseMySQLDSN(dsn string) (*mysqlConfig, error) { //nolint:cyclop
+	c := &mysqlConfig{}
+	var query url.Values
+	if strings.HasPrefix(dsn, "mysql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DSN: %w", err)
+		}
+		c.user = u.User.Username()
+		c.password, _ = u.User.Password()
+		host, port := splitHostPort(u.Host, mysqlDefaultPort)
+		c.address = net.JoinHostPort(host, port)
+		c.database = strings.TrimPrefix(u.Path, "/")
+		query = u.Query()
+	} else {
+		i := strings.LastIndexByte(dsn, '@')
+		if i < 0 {
+			return nil, errors.New("invalid DSN, expected user:password@tcp(host:port)/database")
+		}
+		c.user, c.password, _ = strings.Cut(dsn[:i], ":")
+		rest := dsn[i+1:]
+		address, rest, ok := strings.Cut(rest, "/")
+		if !ok {
+			return nil, errors.New("invalid DSN, expected user:password@tcp(host:port)/database")
+		}
+		if strings.HasPrefix(address, "tcp(") && strings.HasSuffix(address, ")") {
+			address = address[4 : len(address)-1]
+		}
+		host, port := splitHostPort(address, mysqlDefaultPort)
+		c.address = net.JoinHostPort(host, port)
+		var rawQuery string
+		c.database, rawQuery, _ = strings.Cut(rest, "?")
+		var err error
+		if query, err = url.ParseQuery(rawQuery); err != nil {
+			return nil, fmt.Errorf("invalid DSN: %w", err)
+		}
+	}
+	for k, v := range query {
+		switch k {
+		case "tls":
+			switch v[0] {
+			case "true", "skip-verify":
+				c.tls = v[0]
+			case "false":
+			default:
+				return nil, fmt.Errorf("unsupported tls value '%s', it needs to be true, skip-verify or false", v[0])
+			}
+		default:
+			return nil, fmt.Errorf("unsupported DSN param '%s'", k)
+		}
+	}
+	if c.user == "" {
+		return nil, errors.New("the user is required")
+	}
+	return c, nil
+}
+
+// mysqlConn is a connection with the MySQL protocol, the queries without
+// arguments are made with the text protocol, and the others with prepared
+// statements.
+type mysqlConn struct {
+	conn   net.Conn
+	r      *bufio.Reader
+	seq    byte
+	tls    bool
+	broken bool
+}
+
+func openMySQL(ctx context.Context, d dialer, dsn string) (driver.Conn, error) {
+	config, err := parseMySQLDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	netConn, err := d.dial(ctx, config.address)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = netConn.SetDeadline(deadline)
+	}
+	c := &mysqlConn{conn: netConn, r: bufio.NewReader(netConn)}
+	if err = c.handshake(ctx, d, config); err != nil {
+		_ = c.conn.Close()
+		return nil, err
+	}
+	return &conn{wire: c, begin: "START TRANSACTION", commit: "COMMIT", rollback: "ROLLBACK"}, nil
+}
+
+func (c *mysqlConn) readPacket() ([]byte, error) {
+	var data []byte
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(c.r, header[:]); err != nil {
+			return nil, err
+		}
+		size := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+		c.seq = header[3] + 1
+		start := len(data)
+		data = append(data, make([]byte, size)...)
+		if _, err := io.ReadFull(c.r, data[start:]); err != nil {
+			return nil, err
+		}
+		// the payloads of 16MB are continued in the next packets
+		if size < mysqlMaxPacketSize {
+			return data, nil
+		}
+	}
+}
+
+func (c *mysqlConn) writePacket(data []byte) error {
+	for {
+		size := len(data)
+		if size > mysqlMaxPacketSize {
+			size = mysqlMaxPacketSize
+		}
+		packet := []byte{byte(size), byte(size >> 8), byte(size >> 16), c.seq}
+		c.seq++
+		if _, err := c.conn.Write(append(packet, data[:size]...)); err != nil {
+			return err
+		}
+		data = data[size:]
+		if size < mysqlMaxPacketSize {
+			return nil
+		}
+	}
+}
+
+func (c *mysqlConn) handshake(ctx context.Context, d dialer, config *mysqlConfig) error { //nolint:cyclop,funlen
+	data, err := c.readPacket()
+	if err != nil {
+		return err
+	}
+	if len(data) > 0 && data[0] == 0xff {
+		return mysqlError(data)
+	}
+	p := &mysqlPacket{data: data}
+	if v := p.int(1); v != 10 {
+		return fmt.Errorf("unsupported protocol version %d", v)
+	}
+	p.nullString() // server version
+	p.int(4)       // connection id
+	scramble := append([]byte{}, p.next(8)...)
+	p.next(1) // filler
+	capabilities := uint32(p.int(2))
+	p.next(3) // charset and status
+	capabilities |= uint32(p.int(2)) << 16
+	authDataLen := int(p.int(1))
+	p.next(10) // reserved
+	if n := authDataLen - 8; n > 0 {
+		if n < 13 {
+			n = 13
+		}
+		scramble = append(scramble, p.next(n)...)
+	}
+	scramble = []byte(strings.TrimRight(string(scramble), "\x00"))
+	plugin := p.nullString()
+	if p.err != nil {
+		return fmt.Errorf("malformed handshake: %w", p.err)
+	}
+	if capabilities&mysqlClientProtocol41 == 0 {
+		return errors.New("the server doesn't support the protocol 4.1")
+	}
+	if plugin == "" {
+		plugin = mysqlNativePassword
+	}
+
+	flags := uint32(mysqlClientLongPassword | mysqlClientLongFlag | mysqlClientProtocol41 | mysqlClientTransactions |
+		mysqlClientSecureConn | mysqlClientMultiResults | mysqlClientPluginAuth | mysqlClientPluginAuthLenenc)
+	if config.database != "" {
+		flags |= mysqlClientConnectWithDB
+	}
+	flags &= capabilities | mysqlClientLongPassword
+
+	header := binary.LittleEndian.AppendUint32(nil, 0)
+	header = binary.LittleEndian.AppendUint32(header, mysqlMaxPacketSize)
+	header = append(header, mysqlCharsetUTF8MB4)
+	header = append(header, make([]byte, 23)...)
+	if config.tls != "" {
+		if capabilities&mysqlClientSSL == 0 {
+			return errors.New("the server doesn't support TLS")
+		}
+		flags |= mysqlClientSSL
+		binary.LittleEndian.PutUint32(header, flags)
+		if err = c.writePacket(header); err != nil {
+			return err
+		}
+		host, _ := splitHostPort(config.address, mysqlDefaultPort)
+		tlsConn := tls.Client(c.conn, d.tlsConfig(host, config.tls == "true"))
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			return fmt.Errorf("TLS handshake with %s failed: %w", config.address, err)
+		}
+		c.conn, c.r, c.tls = tlsConn, bufio.NewReader(tlsConn), true
+	}
+	binary.LittleEndian.PutUint32(header, flags)
+
+	authData, err := mysqlAuthData(plugin, config.password, scramble)
+	if err != nil {
+		return err
+	}
+	resp := append(header, config.user...)
+	resp = append(resp, 0)
+	resp = appendLenencBytes(resp, authData)
+	if config.database != "" {
+		resp = append(append(resp, config.database...), 0)
+	}
+	resp = append(append(resp, plugin...), 0)
+	if err = c.writePacket(resp); err != nil {
+		return err
+	}
+	return c.authenticate(plugin, config.password, scramble)
+}
+
+// mysqlAuthData returns the data of the authentication with the plugin.
+func mysqlAuthData(plugin, password string, scramble []byte) ([]byte, error) {
+	if password == "" {
+		return nil, nil
+	}
+	if len(scramble) < 20 {
+		return nil, errors.New("invalid authentication data of the server")
+	}
+	switch plugin {
+	case mysqlNativePassword:
+		// SHA1(password) XOR SHA1(scramble + SHA1(SHA1(password)))
+		hash := sha1.Sum([]byte(password)) //nolint:gosec
+		hash2 := sha1.Sum(hash[:])         //nolint:gosec
+		h := sha1.New()                    //nolint:gosec
+		h.Write(scramble[:20])
+		h.Write(hash2[:])
+		return xorBytes(hash[:], h.Sum(nil)), nil
+	case mysqlCachingSHA2Password:
+		// SHA256(password) XOR SHA256(SHA256(SHA256(password)) + scramble)
+		hash := sha256.Sum256([]byte(password))
+		hash2 := sha256.Sum256(hash[:])
+		h := sha256.New()
+		h.Write(hash2[:])
+		h.Write(scramble[:20])
+		return xorBytes(hash[:], h.Sum(nil)), nil
+	default:
+		return nil, fmt.Errorf("unsupported authentication plugin '%s'", plugin)
+	}
+}
+
+func xorBytes(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = a[i] ^ b[i%len(b)]
+	}
+	return out
+}
+
+// authenticate reads the result of the authentication, with the switches of
+// the plugin and the full authentication of caching_sha2_password.
+func (c *mysqlConn) authenticate(plugin, password string, scramble []byte) error { //nolint:cyclop
+	for {
+		data, err := c.readPacket()
+		if err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			return errors.New("malformed authentication response")
+		}
+		switch data[0] {
+		case 0x00:
+			return nil
+		case 0xff:
+			return mysqlError(data)
+		case 0xfe: // auth switch request
+			p := &mysqlPacket{data: data[1:]}
+			plugin = p.nullString()
+			scramble = []byte(strings.TrimRight(string(p.data), "\x00"))
+			authData, err := mysqlAuthData(plugin, password, scramble)
+			if err != nil {
+				return err
+			}
+			if err = c.writePacket(authData); err != nil {
+				return err
+			}
+		case 0x01: // more data of caching_sha2_password
+			if plugin != mysqlCachingSHA2Password || len(data) < 2 {
+				return errors.New("unexpected authentication data")
+			}
+			switch data[1] {
+			case 3: // the fast authentication succeeded
+			case 4: // the full authentication is required
+				if err = c.fullAuth(password, scramble); err != nil {
+					return err
+				}
+			default:
+				return errors.New("unexpected authentication data")
+			}
+		default:
+			return errors.New("unexpected authentication response")
+		}
+	}
+}
+
+// fullAuth sends the password in clear text with TLS, or encrypted with the
+// public key of the server.
+func (c *mysqlConn) fullAuth(password string, scramble []byte) error {
+	plain := append([]byte(password), 0)
+	if c.tls {
+		return c.writePacket(plain)
+	}
+	if err := c.writePacket([]byte{2}); err != nil { // request the public key
+		return err
+	}
+	data, err := c.readPacket()
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 || data[0] != 0x01 {
+		return errors.New("unable to get the public key of the server")
+	}
+	block, _ := pem.Decode(data[1:])
+	if block == nil {
+		return errors.New("invalid public key of the server")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("invalid public key of the server: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("the public key of the server isn't an RSA key")
+	}
+	encrypted, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, rsaKey, xorBytes(plain, scramble), nil) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	return c.writePacket(encrypted)
+}
+
+func mysqlError(data []byte) error {
+	p := &mysqlPacket{data: data[1:]}
+	code := p.int(2)
+	msg := string(p.data)
+	if strings.HasPrefix(msg, "#") && len(msg) >= 6 {
+		msg = msg[6:]
+	}
+	return &DatabaseError{Code: strconv.FormatUint(code, 10), Message: msg}
+}
+
+// mysqlPacket reads the fields of a packet, the first error is kept in err.
+type mysqlPacket struct {
+	data []byte
+	err  error
+}
+
+func (p *mysqlPacket) next(n int) []byte {
+	if p.err != nil {
+		return nil
+	}
+	if n < 0 || len(p.data) < n {
+		p.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := p.data[:n]
+	p.data = p.data[n:]
+	return b
+}
+
+func (p *mysqlPacket) int(n int) uint64 {
+	var v uint64
+	for i, b := range p.next(n) {
+		v |= uint64(b) << (8 * i)
+	}
+	return v
+}
+
+func (p *mysqlPacket) nullString() string {
+	i := strings.IndexByte(string(p.data), 0)
+	if i < 0 {
+		p.err = io.ErrUnexpectedEOF
+		return ""
+	}
+	s := string(p.data[:i])
+	p.data = p.data[i+1:]
+	return s
+}
+
+// lenencInt returns the length-encoded integer, and false if it's NULL.
+func (p *mysqlPacket) lenencInt() (uint64, bool) {
+	first := p.int(1)
+	switch first {
+	case 0xfb:
+		return 0, false
+	case 0xfc:
+		return p.int(2), true
+	case 0xfd:
+		return p.int(3), true
+	case 0xfe:
+		return p.int(8), true
+	default:
+		return first, true
+	}
+}
+
+func (p *mysqlPacket) lenencBytes() ([]byte, bool) {
+	n, ok := p.lenencInt()
+	if !ok || p.err != nil {
+		return nil, false
+	}
+	if n > uint64(len(p.data)) {
+		p.err = io.ErrUnexpectedEOF
+		return nil, false
+	}
+	return p.next(int(n)), true
+}
+
+func appendLenencBytes(buf, b []byte) []byte {
+	n := len(b)
+	switch {
+	case n < 0xfb:
+		buf = append(buf, byte(n))
+	case n < 1<<16:
+		buf = append(buf, 0xfc, byte(n), byte(n>>8))
+	case n < 1<<24:
+		buf = append(buf, 0xfd, byte(n), byte(n>>8), byte(n>>16))
+	default:
+		buf = append(buf, 0xfe)
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(n))
+	}
+	return append(buf, b...)
+}
+
+type mysqlColumn struct {
+	name    string
+	typ     byte
+	charset uint64
+	flags   uint64
+}
+
+// run runs the query, and it returns the rows of the first result, if it
+// has any, and the result of the last OK packet.
+func (c *mysqlConn) run(ctx context.Context, query string, args []driver.NamedValue) (*rows, result, error) {
+	// the deadline of the previous query is reset if there isn't any
+	deadline, _ := ctx.Deadline()
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, result{}, err
+	}
+	var (
+		r   *rows
+		res result
+		err error
+	)
+	if len(args) == 0 {
+		r, res, err = c.textQuery(query)
+	} else {
+		r, res, err = c.stmtQuery(query, args)
+	}
+	if isNetError(err) {
+		c.broken = true
+	}
+	return r, res, err
+}
+
+// textQuery runs the query without arguments with the text protocol, so it
+// can have many statements.
+func (c *mysqlConn) textQuery(query string) (*rows, result, error) {
+	c.seq = 0
+	if err := c.writePacket(append([]byte{mysqlComQuery}, query...)); err != nil {
+		return nil, result{}, err
+	}
+	return c.readResults(false)
+}
+
+// stmtQuery prepares the query on the server and it executes it with the
+// arguments, they are sent apart from the query in their binary format, so
+// they are never parsed as SQL.
+func (c *mysqlConn) stmtQuery(query string, args []driver.NamedValue) (*rows, result, error) {
+	id, params, err := c.prepare(query)
+	if err != nil {
+		return nil, result{}, err
+	}
+	defer func() {
+		// the server doesn't answer to the close of the statements
+		c.seq = 0
+		_ = c.writePacket(binary.LittleEndian.AppendUint32([]byte{mysqlComStmtClose}, id))
+	}()
+	if params != len(args) {
+		return nil, result{}, fmt.Errorf("the query has %d placeholders, but there are %d arguments", params, len(args))
+	}
+
+	packet := binary.LittleEndian.AppendUint32([]byte{mysqlComStmtExecute}, id)
+	packet = append(packet, 0)                           // no cursor
+	packet = binary.LittleEndian.AppendUint32(packet, 1) // iteration count
+	if packet, err = mysqlAppendParams(packet, args); err != nil {
+		return nil, result{}, err
+	}
+	c.seq = 0
+	if err = c.writePacket(packet); err != nil {
+		return nil, result{}, err
+	}
+	return c.readResults(true)
+}
+
+// prepare prepares the statement of the query, and it returns its id and
+// its number of parameters.
+func (c *mysqlConn) prepare(query string) (uint32, int, error) {
+	c.seq = 0
+	if err := c.writePacket(append([]byte{mysqlComStmtPrepare}, query...)); err != nil {
+		return 0, 0, err
+	}
+	data, err := c.readPacket()
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(data) > 0 && data[0] == 0xff {
+		return 0, 0, mysqlError(data)
+	}
+	p := &mysqlPacket{data: data}
+	if p.int(1) != 0x00 {
+		return 0, 0, errors.New("malformed prepare response")
+	}
+	id := uint32(p.int(4))
+	columns := int(p.int(2))
+	params := int(p.int(2))
+	if p.err != nil {
+		return 0, 0, fmt.Errorf("malformed prepare response: %w", p.err)
+	}
+	// the definitions of the parameters and of the columns, each ended by
+	// an EOF, aren't used as the result sets have their own
+	for _, n := range []int{params, columns} {
+		if n == 0 {
+			continue
+		}
+		for i := 0; i <= n; i++ {
+			if _, err = c.readPacket(); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	return id, params, nil
+}
+
+// mysqlAppendParams appends the NULL bitmap, the types and the values of the
+// parameters of an execution of a statement.
+func mysqlAppendParams(buf []byte, args []driver.NamedValue) ([]byte, error) { //nolint:cyclop
+	nulls := make([]byte, (len(args)+7)/8)
+	types := make([]byte, 0, 2*len(args))
+	var values []byte
+	for i, arg := range args {
+		switch v := arg.Value.(type) {
+		case nil:
+			nulls[i/8] |= 1 << (i % 8)
+			types = append(types, mysqlTypeNull, 0)
+		case int64:
+			types = append(types, mysqlTypeLongLong, 0)
+			values = binary.LittleEndian.AppendUint64(values, uint64(v))
+		case float64:
+			types = append(types, mysqlTypeDouble, 0)
+			values = binary.LittleEndian.AppendUint64(values, math.Float64bits(v))
+		case bool:
+			types = append(types, mysqlTypeTiny, 0)
+			if v {
+				values = append(values, 1)
+			} else {
+				values = append(values, 0)
+			}
+		case string:
+			types = append(types, mysqlTypeString, 0)
+			values = appendLenencBytes(values, []byte(v))
+		case []byte:
+			types = append(types, mysqlTypeBlob, 0)
+			values = appendLenencBytes(values, v)
+		case time.Time:
+			v = v.UTC()
+			types = append(types, mysqlTypeDateTime, 0)
+			values = append(values, 11)
+			values = binary.LittleEndian.AppendUint16(values, uint16(v.Year()))
+			values = append(values, byte(v.Month()), byte(v.Day()), byte(v.Hour()), byte(v.Minute()), byte(v.Second()))
+			values = binary.LittleEndian.AppendUint32(values, uint32(v.Nanosecond()/1000))
+		default:
+			return nil, fmt.Errorf("invalid argument %d: unsupported type %T", i+1, v)
+		}
+	}
+	buf = append(buf, nulls...)
+	buf = append(buf, 1) // the types are sent
+	buf = append(buf, types...)
+	return append(buf, values...), nil
+}
+
+// readResults reads the results of a query, the rows are in the binary
+// format for the statements and in the text format otherwise.
+func (c *mysqlConn) readResults(binaryRows bool) (*rows, result, error) {
+	var first *rows
+	for {
+		data, err := c.readPacket()
+		if err != nil {
+			return nil, result{}, err
+		}
+		if len(data) == 0 {
+			return nil, result{}, errors.New("malformed response")
+		}
+		var (
+			res    result
+			status uint64
+		)
+		switch data[0] {
+		case 0x00:
+			p := &mysqlPacket{data: data[1:]}
+			affected, _ := p.lenencInt()
+			insertID, _ := p.lenencInt()
+			status = p.int(2)
+			res = result{rowsAffected: int64(affected), lastInsertID: int64(insertID)}
+		case 0xff:
+			return nil, result{}, mysqlError(data)
+		default:
+			r, s, err := c.readResultSet(data, binaryRows)
+			if err != nil {
+				return nil, result{}, err
+			}
+			if first == nil {
+				first = r
+			}
+			status = s
+		}
+		if status&mysqlServerMoreResults == 0 {
+			return first, res, nil
+		}
+	}
+}
+
+// readResultSet reads the columns and the rows of a result set, and it
+// returns the status flags of its end.
+func (c *mysqlConn) readResultSet(data []byte, binaryRows bool) (*rows, uint64, error) { //nolint:cyclop
+	p := &mysqlPacket{data: data}
+	count, _ := p.lenencInt()
+	if p.err != nil {
+		return nil, 0, fmt.Errorf("malformed result set: %w", p.err)
+	}
+	columns := make([]mysqlColumn, count)
+	r := &rows{columns: make([]string, count)}
+	for i := range columns {
+		data, err := c.readPacket()
+		if err != nil {
+			return nil, 0, err
+		}
+		p := &mysqlPacket{data: data}
+		for j := 0; j < 4; j++ {
+			p.lenencBytes() // catalog, schema, table, org_table
+		}
+		name, _ := p.lenencBytes()
+		p.lenencBytes() // org_name
+		p.lenencInt()   // length of the fixed fields
+		col := mysqlColumn{name: string(name), charset: p.int(2)}
+		p.int(4) // column length
+		col.typ = byte(p.int(1))
+		col.flags = p.int(2)
+		if p.err != nil {
+			return nil, 0, fmt.Errorf("malformed column definition: %w", p.err)
+		}
+		columns[i] = col
+		r.columns[i] = col.name
+	}
+	if _, err := c.readPacket(); err != nil { // EOF
+		return nil, 0, err
+	}
+
+	for {
+		data, err := c.readPacket()
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(data) > 0 && data[0] == 0xff {
+			return nil, 0, mysqlError(data)
+		}
+		if len(data) > 0 && data[0] == 0xfe && len(data) < 9 { // EOF
+			p := &mysqlPacket{data: data[1:]}
+			p.int(2) // warnings
+			return r, p.int(2), nil
+		}
+		var values []driver.Value
+		if binaryRows {
+			values, err = mysqlBinaryRow(columns, data)
+		} else {
+			values, err = mysqlTextRow(columns, data)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		r.values = append(r.values, values)
+	}
+}
+
+func mysqlTextRow(columns []mysqlColumn, data []byte) ([]driver.Value, error) {
+	p := &mysqlPacket{data: data}
+	values := make([]driver.Value, len(columns))
+	for i, col := range columns {
+		b, ok := p.lenencBytes()
+		if !ok {
+			continue
+		}
+		var err error
+		if values[i], err = mysqlDecode(col, string(b)); err != nil {
+			return nil, err
+		}
+	}
+	if p.err != nil {
+		return nil, fmt.Errorf("malformed row: %w", p.err)
+	}
+	return values, nil
+}
+
+// mysqlBinaryRow reads a row of the binary protocol, its header is followed
+// by the NULL bitmap, with an offset of 2 bits, and by the values that
+// aren't NULL.
+func mysqlBinaryRow(columns []mysqlColumn, data []byte) ([]driver.Value, error) {
+	p := &mysqlPacket{data: data}
+	p.int(1) // header
+	nulls := p.next((len(columns) + 7 + 2) / 8)
+	values := make([]driver.Value, len(columns))
+	for i, col := range columns {
+		if p.err != nil {
+			break
+		}
+		if nulls[(i+2)/8]&(1<<((i+2)%8)) != 0 {
+			continue
+		}
+		var err error
+		if values[i], err = mysqlDecodeBinary(col, p); err != nil {
+			return nil, err
+		}
+	}
+	if p.err != nil {
+		return nil, fmt.Errorf("malformed row: %w", p.err)
+	}
+	return values, nil
+}
+
+// mysqlDecodeBinary reads the binary format of the value, as per its
+// column, the values without a fixed length are decoded from their text.
+func mysqlDecodeBinary(col mysqlColumn, p *mysqlPacket) (driver.Value, error) { //nolint:cyclop
+	unsigned := col.flags&mysqlUnsignedFlag != 0
+	switch col.typ {
+	case mysqlTypeTiny:
+		v := p.int(1)
+		if unsigned {
+			return int64(v), nil
+		}
+		return int64(int8(v)), nil
+	case mysqlTypeShort, mysqlTypeYear:
+		v := p.int(2)
+		if unsigned {
+			return int64(v), nil
+		}
+		return int64(int16(v)), nil
+	case mysqlTypeLong, mysqlTypeInt24:
+		v := p.int(4)
+		if unsigned {
+			return int64(v), nil
+		}
+		return int64(int32(v)), nil
+	case mysqlTypeLongLong:
+		v := p.int(8)
+		if unsigned && v > math.MaxInt64 {
+			return strconv.FormatUint(v, 10), nil
+		}
+		return int64(v), nil
+	case mysqlTypeFloat:
+		// the shortest decimal of the float32, like the text protocol
+		f := math.Float32frombits(uint32(p.int(4)))
+		return strconv.ParseFloat(strconv.FormatFloat(float64(f), 'g', -1, 32), 64)
+	case mysqlTypeDouble:
+		return math.Float64frombits(p.int(8)), nil
+	case mysqlTypeDate, mysqlTypeDateTime, mysqlTypeTimestamp:
+		n := int(p.int(1))
+		b := p.next(n)
+		if p.err != nil || n < 4 || (b[0] == 0 && b[1] == 0 && b[2] == 0 && b[3] == 0) {
+			return nil, nil //nolint:nilnil
+		}
+		t := [4]int{} // hour, minute, second, microsecond
+		if n >= 7 {
+			t[0], t[1], t[2] = int(b[4]), int(b[5]), int(b[6])
+		}
+		if n >= 11 {
+			t[3] = int(binary.LittleEndian.Uint32(b[7:]))
+		}
+		return time.Date(int(binary.LittleEndian.Uint16(b)), time.Month(b[2]), int(b[3]),
+			t[0], t[1], t[2], t[3]*1000, time.UTC), nil
+	case mysqlTypeTime:
+		n := int(p.int(1))
+		b := p.next(n)
+		if p.err != nil || n < 8 {
+			return "00:00:00", nil
+		}
+		sign := ""
+		if b[0] == 1 {
+			sign = "-"
+		}
+		hours := int(binary.LittleEndian.Uint32(b[1:]))*24 + int(b[5])
+		s := fmt.Sprintf("%s%02d:%02d:%02d", sign, hours, b[6], b[7])
+		if n >= 12 {
+			s += fmt.Sprintf(".%06d", binary.LittleEndian.Uint32(b[8:]))
+		}
+		return s, nil
+	default:
+		b, _ := p.lenencBytes()
+		if p.err != nil {
+			return nil, nil //nolint:nilnil
+		}
+		return mysqlDecode(col, string(b))
+	}
+}
+
+// mysqlDecode converts the text format of the value, as per its column.
+func mysqlDecode(col mysqlColumn, s string) (driver.Value, error) { //nolint:cyclop
+	switch col.typ {
+	case mysqlTypeTiny, mysqlTypeShort, mysqlTypeLong, mysqlTypeInt24, mysqlTypeLongLong, mysqlTypeYear:
+		if col.flags&mysqlUnsignedFlag != 0 {
+			v, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			if v > math.MaxInt64 {
+				return s, nil
+			}
+			return int64(v), nil
+		}
+		return strconv.ParseInt(s, 10, 64)
+	case mysqlTypeFloat, mysqlTypeDouble:
+		return strconv.ParseFloat(s, 64)
+	case mysqlTypeDecimal, mysqlTypeNewDecimal:
+		return s, nil
+	case mysqlTypeDate, mysqlTypeDateTime, mysqlTypeTimestamp:
+		if strings.HasPrefix(s, "0000-00-00") {
+			return nil, nil
+		}
+		layout := "2006-01-02 15:04:05.999999"
+		if len(s) <= 10 {
+			layout = "2006-01-02"
+		}
+		return time.Parse(layout, s)
+	case mysqlTypeBit:
+		return []byte(s), nil
+	default:
+		if col.charset == mysqlCharsetBinary {
+			return []byte(s), nil
+		}
+		return s, nil
+	}
+}
+
+func (c *mysqlConn) query(ctx context.Context, query string, args []driver.NamedValue) (*rows, error) {
+	r, _, err := c.run(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		r = &rows{}
+	}
+	return r, nil
+}
+
+func (c *mysqlConn) exec(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	_, res, err := c.run(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *mysqlConn) close() error {
+	c.seq = 0
+	_ = c.writePacket([]byte{mysqlComQuit})
+	return c.conn.Close()
+}
+
+func (c *mysqlConn) valid() bool {
+	return !c.broken
+}
@@ -0,0 +1,637 @@
+package sql
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/md5" //nolint:gosec
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	pgProtocolVersion = 196608 // 3.0
+	pgSSLRequestCode  = 80877103
+	pgDefaultPort     = "5432"
+)
+
+// The authentication codes of the AuthenticationRequest messages.
+const (
+	pgAuthOK           = 0
+	pgAuthCleartext    = 3
+	pgAuthMD5          = 5
+	pgAuthSASL         = 10
+	pgAuthSASLContinue = 11
+	pgAuthSASLFinal    = 12
+)
+
+// The OIDs of the types that are converted from their text format.
+const (
+	pgBool        = 16
+	pgBytea       = 17
+	pgInt8        = 20
+	pgInt2        = 21
+	pgInt4        = 23
+	pgOID         = 26
+	pgFloat4      = 700
+	pgFloat8      = 701
+	pgDate        = 1082
+	pgTimestamp   = 1114
+	pgTimestampTZ = 1184
+)
+
+// pgConfig is the config of the connections of the postgres driver.
+type pgConfig struct {
+	address  string
+	user     string
+	password string
+	database string
+	sslMode  string
+	params   map[string]string
+}
+
+// parsePostgresDSN parses the postgres:// URLs and the key=value DSNs.
+func parsePostgresDSN(dsn string) (*pgConfig, error) { //nolint:cyclop
+	c := &pgConfig{sslMode: "prefer", params: map[string]string{}}
+	host, port := "localhost", pgDefaultPort
+	values := map[string]string{}
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DSN: %w", err)
+		}
+		if u.Hostname() != "" {
+			host = u.Hostname()
+		}
+		if u.Port() != "" {
+			port = u.Port()
+		}
+		c.user = u.User.Username()
+		c.password, _ = u.User.Password()
+		c.database = strings.TrimPrefix(u.Path, "/")
+		for k, v := range u.Query() {
+			values[k] = v[0]
+		}
+	} else {
+		for _, field := range strings.Fields(dsn) {
+			k, v, ok := strings.Cut(field, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid DSN field '%s', expected key=value", field)
+			}
+			values[k] = strings.Trim(v, "'")
+		}
+	}
+	for k, v := range values {
+		switch k {
+		case "host":
+			host = v
+		case "port":
+			port = v
+		case "user":
+			c.user = v
+		case "password":
+			c.password = v
+		case "dbname":
+			c.database = v
+		case "sslmode":
+			switch v {
+			case "disable", "prefer", "require", "verify-ca", "verify-full":
+				c.sslMode = v
+			default:
+				return nil, fmt.Errorf("unsupported sslmode '%s'", v)
+			}
+		default:
+			// the run-time parameters, like application_name
+			c.params[k] = v
+		}
+	}
+	if c.user == "" {
+		return nil, errors.New("the user is required")
+	}
+	c.address = net.JoinHostPort(host, port)
+	return c, nil
+}
+
+// pgConn is a connection with the PostgreSQL protocol, the queries are made
+// with the extended query protocol, with the parameters and the results in
+// the text format.
+type pgConn struct {
+	conn   net.Conn
+	r      *bufio.Reader
+	broken bool
+}
+
+func openPostgres(ctx context.Context, d dialer, dsn string) (driver.Conn, error) {
+	config, err := parsePostgresDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	netConn, err := d.dial(ctx, config.address)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = netConn.SetDeadline(deadline)
+	}
+	c := &pgConn{conn: netConn}
+	if err = c.startTLS(ctx, d, config); err != nil {
+		_ = netConn.Close()
+		return nil, err
+	}
+	c.r = bufio.NewReader(c.conn)
+	if err = c.startup(config); err != nil {
+		_ = c.conn.Close()
+		return nil, err
+	}
+	return &conn{wire: c, begin: "BEGIN", commit: "COMMIT", rollback: "ROLLBACK"}, nil
+}
+
+// startTLS requests the TLS, as per the sslmode.
+func (c *pgConn) startTLS(ctx context.Context, d dialer, config *pgConfig) error {
+	if config.sslMode == "disable" {
+		return nil
+	}
+	req := binary.BigEndian.AppendUint32(nil, 8)
+	req = binary.BigEndian.AppendUint32(req, pgSSLRequestCode)
+	if _, err := c.conn.Write(req); err != nil {
+		return err
+	}
+	var resp [1]byte
+	if _, err := io.ReadFull(c.conn, resp[:]); err != nil {
+		return err
+	}
+	if resp[0] != 'S' {
+		if config.sslMode == "prefer" {
+			return nil
+		}
+		return errors.New("the server doesn't support SSL")
+	}
+	host, _ := splitHostPort(config.address, pgDefaultPort)
+	// like libpq, only the verify modes verify the certificate of the server
+	tlsConn := tls.Client(c.conn, d.tlsConfig(host, strings.HasPrefix(config.sslMode, "verify")))
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return fmt.Errorf("TLS handshake with %s failed: %w", config.address, err)
+	}
+	c.conn = tlsConn
+	return nil
+}
+
+func (c *pgConn) startup(config *pgConfig) error { //nolint:cyclop
+	body := binary.BigEndian.AppendUint32(nil, pgProtocolVersion)
+	params := map[string]string{"user": config.user, "client_encoding": "UTF8"}
+	if config.database != "" {
+		params["database"] = config.database
+	}
+	for k, v := range config.params {
+		params[k] = v
+	}
+	for k, v := range params {
+		body = append(append(append(append(body, k...), 0), v...), 0)
+	}
+	body = append(body, 0)
+	msg := binary.BigEndian.AppendUint32(nil, uint32(len(body)+4))
+	if _, err := c.conn.Write(append(msg, body...)); err != nil {
+		return err
+	}
+
+	var scram *scramClient
+	for {
+		typ, data, err := c.read()
+		if err != nil {
+			return err
+		}
+		switch typ {
+		case 'R':
+			if len(data) < 4 {
+				return errors.New("malformed authentication message")
+			}
+			code := binary.BigEndian.Uint32(data)
+			data = data[4:]
+			switch code {
+			case pgAuthOK:
+			case pgAuthCleartext:
+				err = c.write('p', append([]byte(config.password), 0))
+			case pgAuthMD5:
+				err = c.write('p', append([]byte(pgMD5Password(config.user, config.password, data)), 0))
+			case pgAuthSASL:
+				if !strings.Contains(string(data), "SCRAM-SHA-256\x00") {
+					return errors.New("the server doesn't support the SCRAM-SHA-256 authentication")
+				}
+				if scram, err = newSCRAMClient(config.password); err != nil {
+					return err
+				}
+				first := scram.clientFirst()
+				resp := append([]byte("SCRAM-SHA-256\x00"), binary.BigEndian.AppendUint32(nil, uint32(len(first)))...)
+				err = c.write('p', append(resp, first...))
+			case pgAuthSASLContinue:
+				if scram == nil {
+					return errors.New("unexpected SASL message")
+				}
+				var final string
+				if final, err = scram.clientFinal(string(data)); err == nil {
+					err = c.write('p', []byte(final))
+				}
+			case pgAuthSASLFinal:
+				if scram == nil {
+					return errors.New("unexpected SASL message")
+				}
+				err = scram.verifyServerFinal(string(data))
+			default:
+				return fmt.Errorf("unsupported authentication method %d", code)
+			}
+			if err != nil {
+				return err
+			}
+		case 'E':
+			return pgError(data)
+		case 'Z':
+			return nil
+		}
+	}
+}
+
+func pgMD5Password(user, password string, salt []byte) string {
+	sum := md5.Sum([]byte(password + user))                            //nolint:gosec
+	sum = md5.Sum(append([]byte(hex.EncodeToString(sum[:])), salt...)) //nolint:gosec
+	return "md5" + hex.EncodeToString(sum[:])
+}
+
+// read reads a message, the notices and the parameter statuses are skipped.
+func (c *pgConn) read() (byte, []byte, error) {
+	for {
+		var header [5]byte
+		if _, err := io.ReadFull(c.r, header[:]); err != nil {
+			return 0, nil, err
+		}
+		size := binary.BigEndian.Uint32(header[1:])
+		if size < 4 {
+			return 0, nil, fmt.Errorf("invalid size of the message: %d", size)
+		}
+		data := make([]byte, size-4)
+		if _, err := io.ReadFull(c.r, data); err != nil {
+			return 0, nil, err
+		}
+		switch header[0] {
+		case 'N', 'S', 'K', 'A':
+			continue
+		}
+		return header[0], data, nil
+	}
+}
+
+func (c *pgConn) write(typ byte, body []byte) error {
+	msg := append([]byte{typ}, binary.BigEndian.AppendUint32(nil, uint32(len(body)+4))...)
+	_, err := c.conn.Write(append(msg, body...))
+	return err
+}
+
+// pgError returns the error of an ErrorResponse message.
+func pgError(data []byte) error {
+	e := &DatabaseError{}
+	for len(data) > 1 {
+		field := data[0]
+		value, rest, _ := strings.Cut(string(data[1:]), "\x00")
+		data = []byte(rest)
+		switch field {
+		case 'C':
+			e.Code = value
+		case 'M':
+			e.Message = value
+		}
+	}
+	return e
+}
+
+// run runs the query with the extended query protocol, and it returns the
+// rows, if the query returns any, and the tag of the command.
+func (c *pgConn) run(ctx context.Context, query string, args []driver.NamedValue) (*rows, string, error) {
+	// the deadline of the previous query is reset if there isn't any
+	deadline, _ := ctx.Deadline()
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, "", err
+	}
+	r, tag, err := c.extendedQuery(query, args)
+	if isNetError(err) {
+		c.broken = true
+	}
+	return r, tag, err
+}
+
+func (c *pgConn) extendedQuery(query string, args []driver.NamedValue) (*rows, string, error) { //nolint:cyclop,funlen
+	var msg []byte
+	appendMessage := func(typ byte, body []byte) {
+		msg = append(msg, typ)
+		msg = binary.BigEndian.AppendUint32(msg, uint32(len(body)+4))
+		msg = append(msg, body...)
+	}
+
+	// Parse with the types of the parameters inferred by the server
+	parse := append(append([]byte{0}, query...), 0, 0, 0)
+	appendMessage('P', parse)
+	// Bind with the parameters and the results in the text format
+	bind := []byte{0, 0, 0, 0}
+	bind = binary.BigEndian.AppendUint16(bind, uint16(len(args)))
+	for _, arg := range args {
+		value, isNull, err := pgEncode(arg.Value)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid argument %d: %w", arg.Ordinal, err)
+		}
+		if isNull {
+			bind = binary.BigEndian.AppendUint32(bind, 0xffffffff)
+			continue
+		}
+		bind = binary.BigEndian.AppendUint32(bind, uint32(len(value)))
+		bind = append(bind, value...)
+	}
+	bind = append(bind, 0, 0)
+	appendMessage('B', bind)
+	appendMessage('D', []byte{'P', 0})
+	appendMessage('E', []byte{0, 0, 0, 0, 0})
+	appendMessage('S', nil)
+	if _, err := c.conn.Write(msg); err != nil {
+		return nil, "", err
+	}
+
+	var (
+		r     *rows
+		types []uint32
+		tag   string
+		qErr  error
+	)
+	for {
+		typ, data, err := c.read()
+		if err != nil {
+			return nil, "", err
+		}
+		switch typ {
+		case 'T':
+			r, types, err = pgRowDescription(data)
+		case 'D':
+			if r == nil {
+				err = errors.New("unexpected data row")
+				break
+			}
+			var values []driver.Value
+			if values, err = pgDataRow(data, types); err == nil {
+				r.values = append(r.values, values)
+			}
+		case 'C':
+			tag, _, _ = strings.Cut(string(data), "\x00")
+		case 'E':
+			qErr = pgError(data)
+		case 'Z':
+			return r, tag, qErr
+		case '1', '2', 'n', 'I', 's':
+		default:
+			err = fmt.Errorf("unexpected message '%c'", typ)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+	}
+}
+
+func pgRowDescription(data []byte) (*rows, []uint32, error) {
+	if len(data) < 2 {
+		return nil, nil, errors.New("malformed row description")
+	}
+	n := int(binary.BigEndian.Uint16(data))
+	data = data[2:]
+	r := &rows{columns: make([]string, 0, n)}
+	types := make([]uint32, 0, n)
+	for i := 0; i < n; i++ {
+		name, rest, ok := strings.Cut(string(data), "\x00")
+		if !ok || len(rest) < 18 {
+			return nil, nil, errors.New("malformed row description")
+		}
+		r.columns = append(r.columns, name)
+		types = append(types, binary.BigEndian.Uint32([]byte(rest[6:10])))
+		data = []byte(rest[18:])
+	}
+	return r, types, nil
+}
+
+func pgDataRow(data []byte, types []uint32) ([]driver.Value, error) {
+	if len(data) < 2 || int(binary.BigEndian.Uint16(data)) != len(types) {
+		return nil, errors.New("malformed data row")
+	}
+	data = data[2:]
+	values := make([]driver.Value, len(types))
+	for i, oid := range types {
+		if len(data) < 4 {
+			return nil, errors.New("malformed data row")
+		}
+		size := int32(binary.BigEndian.Uint32(data))
+		data = data[4:]
+		if size < 0 {
+			continue
+		}
+		if int(size) > len(data) {
+			return nil, errors.New("malformed data row")
+		}
+		var err error
+		if values[i], err = pgDecode(oid, string(data[:size])); err != nil {
+			return nil, err
+		}
+		data = data[size:]
+	}
+	return values, nil
+}
+
+// pgEncode returns the text format of the value.
+func pgEncode(v driver.Value) (string, bool, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", true, nil
+	case int64:
+		return strconv.FormatInt(v, 10), false, nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), false, nil
+	case bool:
+		return strconv.FormatBool(v), false, nil
+	case string:
+		return v, false, nil
+	case []byte:
+		return `\x` + hex.EncodeToString(v), false, nil
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05.999999999Z07:00"), false, nil
+	default:
+		return "", false, fmt.Errorf("unsupported type %T", v)
+	}
+}
+
+// pgDecode converts the text format of the value, as per its type.
+func pgDecode(oid uint32, s string) (driver.Value, error) {
+	switch oid {
+	case pgBool:
+		return s == "t", nil
+	case pgInt2, pgInt4, pgInt8, pgOID:
+		return strconv.ParseInt(s, 10, 64)
+	case pgFloat4, pgFloat8:
+		return strconv.ParseFloat(s, 64)
+	case pgBytea:
+		if strings.HasPrefix(s, `\x`) {
+			return hex.DecodeString(s[2:])
+		}
+		return []byte(s), nil
+	case pgDate:
+		return time.Parse("2006-01-02", s)
+	case pgTimestamp:
+		return time.Parse("2006-01-02 15:04:05.999999999", s)
+	case pgTimestampTZ:
+		return time.Parse("2006-01-02 15:04:05.999999999Z07:00:00", pgTimezone(s))
+	default:
+		return s, nil
+	}
+}
+
+// pgTimezone pads the offset of the timestamptz values, which can have only
+// the hours, or the hours and the minutes.
+func pgTimezone(s string) string {
+	i := strings.LastIndexAny(s, "+-")
+	if i < 10 {
+		return s
+	}
+	switch len(s) - i {
+	case 3:
+		return s + ":00:00"
+	case 6:
+		return s + ":00"
+	}
+	return s
+}
+
+// pgRowsAffected returns the number of the rows of the command tag, like
+// "INSERT 0 1" or "UPDATE 2".
+func pgRowsAffected(tag string) int64 {
+	i := strings.LastIndexByte(tag, ' ')
+	if i < 0 {
+		return 0
+	}
+	n, _ := strconv.ParseInt(tag[i+1:], 10, 64)
+	return n
+}
+
+func (c *pgConn) query(ctx context.Context, query string, args []driver.NamedValue) (*rows, error) {
+	r, _, err := c.run(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		r = &rows{}
+	}
+	return r, nil
+}
+
+func (c *pgConn) exec(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	_, tag, err := c.run(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return result{rowsAffected: pgRowsAffected(tag), lastInsertID: -1}, nil
+}
+
+func (c *pgConn) close() error {
+	_ = c.write('X', nil)
+	return c.conn.Close()
+}
+
+func (c *pgConn) valid() bool {
+	return !c.broken
+}
+
+// scramClient is the client of the SCRAM-SHA-256 authentication, as per RFC 7677.
+type scramClient struct {
+	password    string
+	nonce       string
+	firstBare   string
+	authMessage string
+	salted      []byte
+}
+
+func newSCRAMClient(password string) (*scramClient, error) {
+	nonce := make([]byte, 18)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &scramClient{password: password, nonce: base64.StdEncoding.EncodeToString(nonce)}, nil
+}
+
+// clientFirst returns the client-first-message, the user is sent in the
+// startup message, so it's empty.
+func (s *scramClient) clientFirst() string {
+	s.firstBare = "n=,r=" + s.nonce
+	return "n,," + s.firstBare
+}
+
+func (s *scramClient) clientFinal(serverFirst string) (string, error) {
+	var nonce, salt string
+	var iterations int
+	for _, attr := range strings.Split(serverFirst, ",") {
+		k, v, _ := strings.Cut(attr, "=")
+		switch k {
+		case "r":
+			nonce = v
+		case "s":
+			salt = v
+		case "i":
+			iterations, _ = strconv.Atoi(v)
+		}
+	}
+	saltBytes, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil || !strings.HasPrefix(nonce, s.nonce) || iterations <= 0 {
+		return "", errors.New("invalid SCRAM server-first-message")
+	}
+
+	s.salted = scramHi([]byte(s.password), saltBytes, iterations)
+	clientKey := hmacSHA256(s.salted, "Client Key")
+	storedKey := sha256.Sum256(clientKey)
+	withoutProof := "c=biws,r=" + nonce
+	s.authMessage = s.firstBare + "," + serverFirst + "," + withoutProof
+	signature := hmacSHA256(storedKey[:], s.authMessage)
+	for i := range clientKey {
+		clientKey[i] ^= signature[i]
+	}
+	return withoutProof + ",p=" + base64.StdEncoding.EncodeToString(clientKey), nil
+}
+
+func (s *scramClient) verifyServerFinal(serverFinal string) error {
+	serverKey := hmacSHA256(s.salted, "Server Key")
+	expected := "v=" + base64.StdEncoding.EncodeToString(hmacSHA256(serverKey, s.authMessage))
+	if !hmac.Equal([]byte(serverFinal), []byte(expected)) {
+		return errors.New("invalid SCRAM server signature")
+	}
+	return nil
+}
+
+// scramHi is the Hi function of SCRAM, which is PBKDF2 with one block.
+func scramHi(password, salt []byte, iterations int) []byte {
+	u := hmacSHA256(password, string(salt)+"\x00\x00\x00\x01")
+	result := append([]byte{}, u...)
+	for i := 1; i < iterations; i++ {
+		u = hmacSHA256(password, string(u))
+		for j := range result {
+			result[j] ^= u[j]
+		}
+	}
+	return result
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
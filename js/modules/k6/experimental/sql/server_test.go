@@ -0,0 +1,554 @@
+package sql
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testResult is the result of a query of the test servers, the values of
+// the rows are in the text format, and nil is NULL.
+type testResult struct {
+	columns []testColumn
+	rows    [][]*string
+	// tag is the command tag of postgres, like "INSERT 0 1"
+	tag          string
+	rowsAffected uint64
+	lastInsertID uint64
+	err          *DatabaseError
+}
+
+// testColumn is a column of a result, typ is the OID of postgres or the
+// type of mysql.
+type testColumn struct {
+	name    string
+	typ     uint32
+	charset uint16
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+// testServer is the common part of the test servers, it records the
+// queries and their arguments, and it answers with the handler.
+type testServer struct {
+	user, password string
+	handler        func(query string, args []*string) testResult
+
+	mx      sync.Mutex
+	queries []string
+	args    [][]*string
+}
+
+func (s *testServer) handle(query string, args []*string) testResult {
+	s.mx.Lock()
+	s.queries = append(s.queries, query)
+	s.args = append(s.args, args)
+	s.mx.Unlock()
+	if s.handler == nil {
+		return testResult{}
+	}
+	return s.handler(query, args)
+}
+
+func (s *testServer) received() ([]string, [][]*string) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return append([]string{}, s.queries...), append([][]*string{}, s.args...)
+}
+
+func (s *testServer) listen(t *testing.T, serve func(net.Conn)) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				serve(conn)
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+// startTestPostgres starts a postgres server with the SCRAM-SHA-256
+// authentication, which doesn't support TLS.
+func startTestPostgres(t *testing.T, s *testServer) string {
+	t.Helper()
+	return s.listen(t, func(conn net.Conn) { _ = s.servePostgres(conn) })
+}
+
+func pgMessage(typ byte, body []byte) []byte {
+	msg := append([]byte{typ}, binary.BigEndian.AppendUint32(nil, uint32(len(body)+4))...)
+	return append(msg, body...)
+}
+
+func pgAuth(code uint32, data string) []byte {
+	return pgMessage('R', append(binary.BigEndian.AppendUint32(nil, code), data...))
+}
+
+func pgErrorResponse(e *DatabaseError) []byte {
+	body := []byte("SERROR\x00C" + e.Code + "\x00M" + e.Message + "\x00\x00")
+	return pgMessage('E', body)
+}
+
+func (s *testServer) servePostgres(conn net.Conn) error { //nolint:cyclop,funlen
+	r := bufio.NewReader(conn)
+	var startup []byte
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(r, size[:]); err != nil {
+			return err
+		}
+		startup = make([]byte, binary.BigEndian.Uint32(size[:])-4)
+		if _, err := io.ReadFull(r, startup); err != nil {
+			return err
+		}
+		if binary.BigEndian.Uint32(startup) != pgSSLRequestCode {
+			break
+		}
+		if _, err := conn.Write([]byte{'N'}); err != nil {
+			return err
+		}
+	}
+	params := map[string]string{}
+	fields := strings.Split(string(startup[4:]), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		params[fields[i]] = fields[i+1]
+	}
+
+	readMessage := func() (byte, []byte, error) {
+		var header [5]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return 0, nil, err
+		}
+		data := make([]byte, binary.BigEndian.Uint32(header[1:])-4)
+		_, err := io.ReadFull(r, data)
+		return header[0], data, err
+	}
+
+	// SCRAM-SHA-256 with the user of the startup message
+	if _, err := conn.Write(pgAuth(pgAuthSASL, "SCRAM-SHA-256\x00\x00")); err != nil {
+		return err
+	}
+	_, data, err := readMessage()
+	if err != nil {
+		return err
+	}
+	_, clientFirst, _ := strings.Cut(string(data), "SCRAM-SHA-256\x00")
+	firstBare := strings.TrimPrefix(clientFirst[4:], "n,,")
+	_, clientNonce, _ := strings.Cut(firstBare, ",r=")
+	salt := []byte("k6-salt")
+	serverFirst := "r=" + clientNonce + "server-nonce,s=" + base64.StdEncoding.EncodeToString(salt) + ",i=4096"
+	if _, err = conn.Write(pgAuth(pgAuthSASLContinue, serverFirst)); err != nil {
+		return err
+	}
+	if _, data, err = readMessage(); err != nil {
+		return err
+	}
+	withoutProof, proof, _ := strings.Cut(string(data), ",p=")
+	authMessage := firstBare + "," + serverFirst + "," + withoutProof
+	salted := scramHi([]byte(s.password), salt, 4096)
+	storedKey := sha256.Sum256(hmacSHA256(salted, "Client Key"))
+	clientKey, _ := base64.StdEncoding.DecodeString(proof)
+	signature := hmacSHA256(storedKey[:], authMessage)
+	for i := range clientKey {
+		clientKey[i] ^= signature[i%len(signature)]
+	}
+	if sum := sha256.Sum256(clientKey); params["user"] != s.user || !bytes.Equal(sum[:], storedKey[:]) {
+		_, err = conn.Write(pgErrorResponse(&DatabaseError{
+			Code: "28P01", Message: `password authentication failed for user "` + params["user"] + `"`,
+		}))
+		return err
+	}
+	serverSignature := hmacSHA256(hmacSHA256(salted, "Server Key"), authMessage)
+	final := pgAuth(pgAuthSASLFinal, "v="+base64.StdEncoding.EncodeToString(serverSignature))
+	final = append(final, pgAuth(pgAuthOK, "")...)
+	final = append(final, pgMessage('S', []byte("server_version\x0015.0\x00"))...)
+	final = append(final, pgMessage('Z', []byte{'I'})...)
+	if _, err = conn.Write(final); err != nil {
+		return err
+	}
+
+	var (
+		query string
+		args  []*string
+	)
+	for {
+		typ, data, err := readMessage()
+		if err != nil {
+			return err
+		}
+		switch typ {
+		case 'P':
+			_, rest, _ := strings.Cut(string(data), "\x00")
+			query, _, _ = strings.Cut(rest, "\x00")
+		case 'B':
+			args = pgBindArgs(data)
+		case 'S':
+			if _, err = conn.Write(pgResponse(s.handle(query, args))); err != nil {
+				return err
+			}
+		case 'X':
+			return nil
+		}
+	}
+}
+
+// pgBindArgs returns the parameters of a Bind message, which has only the
+// text formats.
+func pgBindArgs(data []byte) []*string {
+	data = data[4:] // the empty portal and statement, and the formats
+	n := int(binary.BigEndian.Uint16(data))
+	data = data[2:]
+	args := make([]*string, n)
+	for i := range args {
+		size := int32(binary.BigEndian.Uint32(data))
+		data = data[4:]
+		if size < 0 {
+			continue
+		}
+		args[i] = strPtr(string(data[:size]))
+		data = data[size:]
+	}
+	return args
+}
+
+func pgResponse(res testResult) []byte {
+	msg := pgMessage('1', nil)
+	msg = append(msg, pgMessage('2', nil)...)
+	if res.err != nil {
+		msg = append(msg, pgErrorResponse(res.err)...)
+		return append(msg, pgMessage('Z', []byte{'I'})...)
+	}
+	if len(res.columns) == 0 {
+		msg = append(msg, pgMessage('n', nil)...)
+	} else {
+		desc := binary.BigEndian.AppendUint16(nil, uint16(len(res.columns)))
+		for _, col := range res.columns {
+			desc = append(append(desc, col.name...), 0)
+			desc = append(desc, 0, 0, 0, 0, 0, 0) // table and attribute
+			desc = binary.BigEndian.AppendUint32(desc, col.typ)
+			desc = append(desc, 0, 0, 0, 0, 0, 0, 0, 0) // size, modifier and format
+		}
+		msg = append(msg, pgMessage('T', desc)...)
+	}
+	for _, row := range res.rows {
+		data := binary.BigEndian.AppendUint16(nil, uint16(len(row)))
+		for _, v := range row {
+			if v == nil {
+				data = binary.BigEndian.AppendUint32(data, 0xffffffff)
+				continue
+			}
+			data = binary.BigEndian.AppendUint32(data, uint32(len(*v)))
+			data = append(data, *v...)
+		}
+		msg = append(msg, pgMessage('D', data)...)
+	}
+	tag := res.tag
+	if tag == "" {
+		tag = "SELECT " + strconv.Itoa(len(res.rows))
+	}
+	msg = append(msg, pgMessage('C', append([]byte(tag), 0))...)
+	return append(msg, pgMessage('Z', []byte{'I'})...)
+}
+
+// startTestMySQL starts a mysql server with the mysql_native_password
+// authentication, which doesn't support TLS.
+func startTestMySQL(t *testing.T, s *testServer) string {
+	t.Helper()
+	return s.listen(t, func(conn net.Conn) { _ = s.serveMySQL(conn) })
+}
+
+// mysqlTestConn writes and reads the packets of the test server.
+type mysqlTestConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	seq  byte
+}
+
+func (c *mysqlTestConn) read() ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		return nil, err
+	}
+	data := make([]byte, int(header[0])|int(header[1])<<8|int(header[2])<<16)
+	c.seq = header[3] + 1
+	_, err := io.ReadFull(c.r, data)
+	return data, err
+}
+
+func (c *mysqlTestConn) write(packets ...[]byte) error {
+	var buf []byte
+	for _, p := range packets {
+		buf = append(buf, byte(len(p)), byte(len(p)>>8), byte(len(p)>>16), c.seq)
+		buf = append(buf, p...)
+		c.seq++
+	}
+	_, err := c.conn.Write(buf)
+	return err
+}
+
+func mysqlOK(affected, insertID uint64) []byte {
+	p := []byte{0x00}
+	p = appendLenencInt(p, affected)
+	p = appendLenencInt(p, insertID)
+	return append(p, 0x02, 0, 0, 0) // autocommit status and warnings
+}
+
+func appendLenencInt(buf []byte, n uint64) []byte {
+	if n < 0xfb {
+		return append(buf, byte(n))
+	}
+	return binary.LittleEndian.AppendUint64(append(buf, 0xfe), n)
+}
+
+func mysqlErrorPacket(e *DatabaseError) []byte {
+	p := []byte{0xff}
+	code := 0
+	for _, ch := range e.Code {
+		code = code*10 + int(ch-'0')
+	}
+	p = binary.LittleEndian.AppendUint16(p, uint16(code))
+	return append(p, "#HY000"+e.Message...)
+}
+
+func (s *testServer) serveMySQL(conn net.Conn) error { //nolint:cyclop,funlen
+	c := &mysqlTestConn{conn: conn, r: bufio.NewReader(conn)}
+	scramble := []byte("0123456789abcdefghij")
+	capabilities := uint32(mysqlClientLongPassword | mysqlClientLongFlag | mysqlClientConnectWithDB |
+		mysqlClientProtocol41 | mysqlClientTransactions | mysqlClientSecureConn | mysqlClientMultiResults |
+		mysqlClientPluginAuth | mysqlClientPluginAuthLenenc)
+	handshake := append([]byte{10}, "8.0.0-test\x00"...)
+	handshake = append(handshake, 1, 0, 0, 0)
+	handshake = append(append(handshake, scramble[:8]...), 0)
+	handshake = binary.LittleEndian.AppendUint16(handshake, uint16(capabilities))
+	handshake = append(handshake, mysqlCharsetUTF8MB4, 0x02, 0)
+	handshake = binary.LittleEndian.AppendUint16(handshake, uint16(capabilities>>16))
+	handshake = append(handshake, 21)
+	handshake = append(handshake, make([]byte, 10)...)
+	handshake = append(append(handshake, scramble[8:]...), 0)
+	handshake = append(handshake, mysqlNativePassword+"\x00"...)
+	if err := c.write(handshake); err != nil {
+		return err
+	}
+
+	data, err := c.read()
+	if err != nil {
+		return err
+	}
+	if len(data) < 32 {
+		return errors.New("malformed handshake response")
+	}
+	p := &mysqlPacket{data: data[32:]}
+	user := p.nullString()
+	authData, _ := p.lenencBytes()
+	expected, _ := mysqlAuthData(mysqlNativePassword, s.password, scramble)
+	if user != s.user || !bytes.Equal(authData, expected) {
+		return c.write(mysqlErrorPacket(&DatabaseError{
+			Code: "1045", Message: "Access denied for user '" + user + "'",
+		}))
+	}
+	if err = c.write(mysqlOK(0, 0)); err != nil {
+		return err
+	}
+
+	statements := make(map[uint32]string)
+	for {
+		data, err := c.read()
+		if err != nil {
+			return err
+		}
+		if len(data) == 0 || data[0] == mysqlComQuit {
+			return nil
+		}
+		var packets [][]byte
+		switch data[0] {
+		case mysqlComQuery:
+			packets = mysqlResponse(s.handle(string(data[1:]), nil), false)
+		case mysqlComStmtPrepare:
+			id := uint32(len(statements) + 1)
+			statements[id] = string(data[1:])
+			packets = mysqlPrepareResponse(id, strings.Count(string(data[1:]), "?"))
+		case mysqlComStmtExecute:
+			query, ok := statements[binary.LittleEndian.Uint32(data[1:])]
+			if !ok {
+				return errors.New("unknown statement")
+			}
+			args, err := mysqlExecuteArgs(data[10:], strings.Count(query, "?"))
+			if err != nil {
+				return err
+			}
+			packets = mysqlResponse(s.handle(query, args), true)
+		case mysqlComStmtClose:
+			delete(statements, binary.LittleEndian.Uint32(data[1:]))
+			continue
+		default:
+			return errors.New("unsupported command")
+		}
+		if err = c.write(packets...); err != nil {
+			return err
+		}
+	}
+}
+
+// mysqlPrepareResponse answers to the prepare of a statement, the test
+// server doesn't send the definitions of its columns.
+func mysqlPrepareResponse(id uint32, params int) [][]byte {
+	ok := binary.LittleEndian.AppendUint32([]byte{0x00}, id)
+	ok = binary.LittleEndian.AppendUint16(ok, 0)
+	ok = binary.LittleEndian.AppendUint16(ok, uint16(params))
+	packets := [][]byte{append(ok, 0, 0, 0)}
+	if params == 0 {
+		return packets
+	}
+	for i := 0; i < params; i++ {
+		packets = append(packets, mysqlColumnDefinition(testColumn{name: "?", typ: 0xfd, charset: mysqlCharsetBinary}))
+	}
+	return append(packets, []byte{0xfe, 0, 0, 0x02, 0})
+}
+
+// mysqlExecuteArgs reads the parameters of the execution of a statement in
+// their text format.
+func mysqlExecuteArgs(data []byte, n int) ([]*string, error) { //nolint:cyclop
+	args := make([]*string, n)
+	if n == 0 {
+		return args, nil
+	}
+	p := &mysqlPacket{data: data}
+	nulls := p.next((n + 7) / 8)
+	if p.int(1) != 1 {
+		return nil, errors.New("the types of the parameters are required")
+	}
+	types := p.next(2 * n)
+	for i := 0; i < n && p.err == nil; i++ {
+		if nulls[i/8]&(1<<(i%8)) != 0 {
+			continue
+		}
+		var v string
+		switch types[2*i] {
+		case mysqlTypeTiny:
+			v = strconv.FormatUint(p.int(1), 10)
+		case mysqlTypeLongLong:
+			v = strconv.FormatInt(int64(p.int(8)), 10)
+		case mysqlTypeDouble:
+			v = strconv.FormatFloat(math.Float64frombits(p.int(8)), 'g', -1, 64)
+		case mysqlTypeDateTime:
+			b := p.next(int(p.int(1)))
+			if len(b) != 11 {
+				return nil, errors.New("unexpected datetime length")
+			}
+			v = time.Date(int(binary.LittleEndian.Uint16(b)), time.Month(b[2]), int(b[3]), int(b[4]), int(b[5]), int(b[6]),
+				int(binary.LittleEndian.Uint32(b[7:]))*1000, time.UTC).Format("2006-01-02 15:04:05.999999")
+		case mysqlTypeString, mysqlTypeBlob:
+			b, _ := p.lenencBytes()
+			v = string(b)
+		default:
+			return nil, fmt.Errorf("unexpected parameter type %d", types[2*i])
+		}
+		args[i] = &v
+	}
+	return args, p.err
+}
+
+// mysqlResponse encodes the result, its rows are in the binary format of the
+// statements, or in the text format.
+func mysqlResponse(res testResult, binaryRows bool) [][]byte { //nolint:cyclop
+	if res.err != nil {
+		return [][]byte{mysqlErrorPacket(res.err)}
+	}
+	if len(res.columns) == 0 {
+		return [][]byte{mysqlOK(res.rowsAffected, res.lastInsertID)}
+	}
+	eof := []byte{0xfe, 0, 0, 0x02, 0}
+	packets := [][]byte{appendLenencInt(nil, uint64(len(res.columns)))}
+	for _, col := range res.columns {
+		packets = append(packets, mysqlColumnDefinition(col))
+	}
+	packets = append(packets, eof)
+	for _, row := range res.rows {
+		if binaryRows {
+			packets = append(packets, mysqlBinaryTestRow(res.columns, row))
+			continue
+		}
+		var data []byte
+		for _, v := range row {
+			if v == nil {
+				data = append(data, 0xfb)
+				continue
+			}
+			data = appendLenencBytes(data, []byte(*v))
+		}
+		packets = append(packets, data)
+	}
+	return append(packets, eof)
+}
+
+func mysqlColumnDefinition(col testColumn) []byte {
+	def := appendLenencBytes(nil, []byte("def"))
+	def = appendLenencBytes(def, []byte("test"))
+	def = appendLenencBytes(def, []byte("t"))
+	def = appendLenencBytes(def, []byte("t"))
+	def = appendLenencBytes(def, []byte(col.name))
+	def = appendLenencBytes(def, []byte(col.name))
+	def = append(def, 0x0c)
+	charset := col.charset
+	if charset == 0 {
+		charset = mysqlCharsetUTF8MB4
+	}
+	def = binary.LittleEndian.AppendUint16(def, charset)
+	return append(def, 0xff, 0, 0, 0, byte(col.typ), 0, 0, 0, 0, 0)
+}
+
+// mysqlBinaryTestRow encodes the row in the binary format, from the text
+// format of its values.
+func mysqlBinaryTestRow(columns []testColumn, row []*string) []byte {
+	nulls := make([]byte, (len(columns)+7+2)/8)
+	var values []byte
+	for i, v := range row {
+		if v == nil {
+			nulls[(i+2)/8] |= 1 << ((i + 2) % 8)
+			continue
+		}
+		switch columns[i].typ {
+		case mysqlTypeLong:
+			n, _ := strconv.ParseInt(*v, 10, 32)
+			values = binary.LittleEndian.AppendUint32(values, uint32(n))
+		case mysqlTypeLongLong:
+			n, _ := strconv.ParseInt(*v, 10, 64)
+			values = binary.LittleEndian.AppendUint64(values, uint64(n))
+		case mysqlTypeDouble:
+			f, _ := strconv.ParseFloat(*v, 64)
+			values = binary.LittleEndian.AppendUint64(values, math.Float64bits(f))
+		case mysqlTypeDateTime:
+			t, _ := time.Parse("2006-01-02 15:04:05.999999", *v)
+			values = binary.LittleEndian.AppendUint16(append(values, 11), uint16(t.Year()))
+			values = append(values, byte(t.Month()), byte(t.Day()), byte(t.Hour()), byte(t.Minute()), byte(t.Second()))
+			values = binary.LittleEndian.AppendUint32(values, uint32(t.Nanosecond()/1000))
+		default:
+			values = appendLenencBytes(values, []byte(*v))
+		}
+	}
+	return append(append([]byte{0x00}, nulls...), values...)
+}
@@ -0,0 +1,252 @@
+package sql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/httpmultibin"
+	"go.k6.io/k6/metrics"
+)
+
+func newTestRuntime(t *testing.T) (*modulestest.Runtime, chan metrics.SampleContainer) {
+	t.Helper()
+	runtime := modulestest.NewRuntime(t)
+	mi, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, runtime.VU.Runtime().Set("sql", mi.Exports().Named))
+
+	tb := httpmultibin.NewHTTPMultiBin(t)
+	registry := metrics.NewRegistry()
+	samples := make(chan metrics.SampleContainer, 1000)
+	runtime.MoveToVUContext(&lib.State{
+		Dialer:         tb.Dialer,
+		Transport:      tb.HTTPTransport,
+		TLSConfig:      tb.TLSClientConfig,
+		Samples:        samples,
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+	})
+	return runtime, samples
+}
+
+func TestPostgres(t *testing.T) {
+	t.Parallel()
+	runtime, samples := newTestRuntime(t)
+	server := &testServer{user: "k6", password: "secret", handler: func(query string, _ []*string) testResult {
+		if strings.HasPrefix(query, "INSERT") {
+			return testResult{tag: "INSERT 0 1"}
+		}
+		return testResult{
+			columns: []testColumn{
+				{name: "id", typ: pgInt8}, {name: "name", typ: 25}, {name: "active", typ: pgBool},
+				{name: "data", typ: pgBytea}, {name: "created", typ: pgTimestampTZ}, {name: "note", typ: 25},
+			},
+			rows: [][]*string{{
+				strPtr("1"), strPtr("k6"), strPtr("t"), strPtr(`\x0102`), strPtr("2023-05-01 10:20:30.5+02"), nil,
+			}},
+		}
+	}}
+	addr := startTestPostgres(t, server)
+	require.NoError(t, runtime.VU.Runtime().Set("dsn", "postgres://k6:secret@"+addr+"/shop"))
+
+	_, err := runtime.RunOnEventLoop(`
+		var db = sql.open("postgres", dsn, { tags: { name: "users" } });
+		var rows = db.query("SELECT * FROM users WHERE id = $1 AND name = $2", 1, "k6");
+		if (rows.length !== 1) {
+			throw new Error("unexpected rows: " + JSON.stringify(rows));
+		}
+		var r = rows[0];
+		if (r.id !== 1 || r.name !== "k6" || r.active !== true || r.note !== null ||
+			new Uint8Array(r.data)[1] !== 2 || r.created.toISOString() !== "2023-05-01T08:20:30.500Z") {
+			throw new Error("unexpected row: " + JSON.stringify(r));
+		}
+		var result = db.exec("INSERT INTO users (name, data) VALUES ($1, $2)", "k6", null);
+		if (result.rowsAffected !== 1 || result.lastInsertId !== 0) {
+			throw new Error("unexpected result: " + JSON.stringify(result));
+		}
+		db.close();
+	`)
+	require.NoError(t, err)
+
+	queries, args := server.received()
+	require.Len(t, queries, 2)
+	assert.Equal(t, []*string{strPtr("1"), strPtr("k6")}, args[0])
+	assert.Equal(t, []*string{strPtr("k6"), nil}, args[1])
+
+	operations := map[string]float64{}
+	for _, c := range metrics.GetBufferedSamples(samples) {
+		for _, s := range c.GetSamples() {
+			driver, _ := s.Tags.Get("driver")
+			assert.Equal(t, "postgres", driver)
+			name, _ := s.Tags.Get("name")
+			assert.Equal(t, "users", name)
+			if s.Metric.Name == "sql_queries" {
+				op, _ := s.Tags.Get("operation")
+				operations[op] += s.Value
+			}
+			assert.NotEqual(t, "sql_query_errors", s.Metric.Name)
+		}
+	}
+	assert.Equal(t, map[string]float64{"select": 1, "insert": 1}, operations)
+}
+
+func TestPostgresErrors(t *testing.T) {
+	t.Parallel()
+	runtime, samples := newTestRuntime(t)
+	server := &testServer{user: "k6", password: "secret", handler: func(string, []*string) testResult {
+		return testResult{err: &DatabaseError{Code: "42P01", Message: `relation "missing" does not exist`}}
+	}}
+	addr := startTestPostgres(t, server)
+	require.NoError(t, runtime.VU.Runtime().Set("addr", addr))
+
+	_, err := runtime.RunOnEventLoop(`
+		var db = sql.open("postgres", "postgres://k6:wrong@" + addr + "/shop?sslmode=disable");
+		try {
+			db.query("SELECT 1");
+			throw new Error("the authentication didn't fail");
+		} catch (e) {
+			if (String(e).indexOf('password authentication failed for user "k6" (28P01)') < 0) {
+				throw e;
+			}
+		}
+		db.close();
+
+		db = sql.open("postgres", "postgres://k6:secret@" + addr + "/shop");
+		try {
+			db.query("SELECT * FROM missing");
+			throw new Error("the query didn't fail");
+		} catch (e) {
+			if (String(e).indexOf('relation "missing" does not exist (42P01)') < 0) {
+				throw e;
+			}
+		}
+		// the connection is reused after the errors of the queries
+		db.exec("DELETE FROM missing");
+		db.close();
+	`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `relation "missing" does not exist (42P01)`)
+
+	errors := 0.0
+	for _, c := range metrics.GetBufferedSamples(samples) {
+		for _, s := range c.GetSamples() {
+			if s.Metric.Name == "sql_query_errors" {
+				errors += s.Value
+			}
+		}
+	}
+	assert.Equal(t, float64(3), errors)
+}
+
+func TestMySQL(t *testing.T) {
+	t.Parallel()
+	runtime, _ := newTestRuntime(t)
+	server := &testServer{user: "k6", password: "secret", handler: func(query string, _ []*string) testResult {
+		if !strings.HasPrefix(query, "SELECT") {
+			return testResult{rowsAffected: 1, lastInsertID: 42}
+		}
+		return testResult{
+			columns: []testColumn{
+				{name: "id", typ: mysqlTypeLong}, {name: "name", typ: 0xfd}, {name: "price", typ: mysqlTypeNewDecimal},
+				{name: "created", typ: mysqlTypeDateTime}, {name: "note", typ: 0xfd},
+			},
+			rows: [][]*string{
+				{strPtr("1"), strPtr("k6"), strPtr("9.90"), strPtr("2023-05-01 10:20:30"), nil},
+				{strPtr("2"), strPtr("xk6"), strPtr("1.00"), strPtr("2023-05-02 10:20:30"), strPtr("-")},
+			},
+		}
+	}}
+	addr := startTestMySQL(t, server)
+	require.NoError(t, runtime.VU.Runtime().Set("dsn", "k6:secret@tcp("+addr+")/shop"))
+
+	_, err := runtime.RunOnEventLoop(`
+		var db = sql.open("mysql", dsn);
+		var rows = db.query("SELECT * FROM products WHERE name <> ? AND price < ?", "O'Brien", 10.5);
+		if (rows.length !== 2 || rows[0].id !== 1 || rows[0].price !== "9.90" || rows[0].note !== null ||
+			rows[1].name !== "xk6" || rows[0].created.toISOString() !== "2023-05-01T10:20:30.000Z") {
+			throw new Error("unexpected rows: " + JSON.stringify(rows));
+		}
+
+		var tx = db.begin();
+		var result = tx.exec("INSERT INTO products (name, tags) VALUES (?, ?)", "k6", ["a", "b"]);
+		if (result.rowsAffected !== 1 || result.lastInsertId !== 42) {
+			throw new Error("unexpected result: " + JSON.stringify(result));
+		}
+		tx.commit();
+		db.close();
+	`)
+	require.NoError(t, err)
+
+	queries, args := server.received()
+	assert.Equal(t, []string{
+		"SELECT * FROM products WHERE name <> ? AND price < ?",
+		"START TRANSACTION",
+		"INSERT INTO products (name, tags) VALUES (?, ?)",
+		"COMMIT",
+	}, queries)
+	assert.Equal(t, [][]*string{
+		{strPtr("O'Brien"), strPtr("10.5")},
+		nil,
+		{strPtr("k6"), strPtr(`["a","b"]`)},
+		nil,
+	}, args)
+}
+
+func TestMySQLAuthError(t *testing.T) {
+	t.Parallel()
+	runtime, _ := newTestRuntime(t)
+	addr := startTestMySQL(t, &testServer{user: "k6", password: "secret"})
+	require.NoError(t, runtime.VU.Runtime().Set("dsn", "mysql://k6:wrong@"+addr+"/shop"))
+
+	_, err := runtime.RunOnEventLoop(`sql.open("mysql", dsn).query("SELECT 1")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Access denied for user 'k6' (1045)")
+}
+
+func TestOpenErrors(t *testing.T) {
+	t.Parallel()
+	runtime, _ := newTestRuntime(t)
+
+	tests := map[string]string{
+		`sql.open("sqlite", "file.db")`:                                 "unsupported driver 'sqlite', it needs to be postgres or mysql",
+		`sql.open("mysql", "k6@tcp(db)/", { poolSize: 1 })`:             "invalid open() options: unknown option 'poolSize'",
+		`sql.open("mysql", "k6@tcp(db)/", { timeout: "-1s" })`:          "invalid open() options: invalid timeout value: -1s",
+		`sql.open("postgres", "user=k6 sslmode=allow").query("SELECT")`: "unsupported sslmode 'allow'",
+	}
+	for code, expected := range tests {
+		_, err := runtime.RunOnEventLoop(code)
+		require.Error(t, err, code)
+		assert.Contains(t, err.Error(), expected, code)
+	}
+}
+
+func TestInitContext(t *testing.T) {
+	t.Parallel()
+	runtime := modulestest.NewRuntime(t)
+	mi, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, runtime.VU.Runtime().Set("sql", mi.Exports().Named))
+
+	// the databases can be opened in the init context, but not queried
+	_, err := runtime.VU.Runtime().RunString(`var db = sql.open("postgres", "postgres://k6@127.0.0.1/shop")`)
+	require.NoError(t, err)
+
+	_, err = runtime.VU.Runtime().RunString(`db.query("SELECT 1")`)
+	require.ErrorContains(t, err, "executing SQL queries in the init context is not supported")
+	_, err = runtime.VU.Runtime().RunString(`db.begin()`)
+	require.ErrorContains(t, err, "beginning SQL transactions in the init context is not supported")
+}
+
+func TestOperation(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "select", operation("  SELECT * FROM t"))
+	assert.Equal(t, "with", operation("with x as (select 1) select * from x"))
+	assert.Equal(t, "insert", operation("INSERT(1)"))
+	assert.Equal(t, "", operation(""))
+}
@@ -0,0 +1,78 @@
+package streams
+
+import (
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/modules"
+)
+
+// ByteTransformer transforms the chunks of a stream of bytes, e.g. compresses
+// them. Its methods are called off the event loop, one at a time.
+type ByteTransformer interface {
+	// Transform returns the transformed data of the chunk, which is empty
+	// if the transformer needs more data.
+	Transform(chunk []byte) ([]byte, error)
+	// Flush returns the remaining transformed data, once all the
+	// chunks have been transformed.
+	Flush() ([]byte, error)
+}
+
+// NewByteTransformStream returns a TransformStream which transforms its
+// ArrayBuffer or ArrayBufferView chunks with the transformer, off the event
+// loop, to Uint8Array chunks, e.g. to pipe a file or a response body
+// through a decompression.
+func NewByteTransformStream(vu modules.VU, t ByteTransformer) (*goja.Object, error) {
+	rt := vu.Runtime()
+	obj, readableObj, writableObj := rt.NewObject(), rt.NewObject(), rt.NewObject()
+
+	// transformOffLoop calls the transformation off the event loop,
+	// and enqueues its data back on it.
+	transformOffLoop := func(c *transformStreamController, transform func() ([]byte, error)) goja.Value {
+		promise, resolve, reject := rt.NewPromise()
+		callback := vu.RegisterCallback()
+		go func() {
+			data, err := transform()
+			callback(func() error {
+				if err != nil {
+					reject(rt.NewGoError(err))
+					return nil
+				}
+				if len(data) > 0 {
+					chunk, nerr := rt.New(rt.Get("Uint8Array"), rt.ToValue(rt.NewArrayBuffer(data)))
+					if nerr != nil {
+						reject(errorValue(rt, nerr))
+						return nil
+					}
+					if enqueueErr := c.enqueue(chunk); enqueueErr != nil {
+						reject(enqueueErr)
+						return nil
+					}
+				}
+				resolve(goja.Undefined())
+				return nil
+			})
+		}()
+		return rt.ToValue(promise)
+	}
+
+	_, err := newTransformStream(rt, readableObj, writableObj, transformer{
+		start: func(*transformStreamController) (goja.Value, error) { return goja.Undefined(), nil },
+		transform: func(chunk goja.Value, c *transformStreamController) goja.Value {
+			b, err := exportBytes(rt, chunk)
+			if err != nil {
+				return rt.ToValue(newRejectedPromise(rt, rt.NewTypeError(err.Error())))
+			}
+			// the chunk of the script can only be read on the event loop
+			b = append([]byte(nil), b...)
+			return transformOffLoop(c, func() ([]byte, error) { return t.Transform(b) })
+		},
+		flush: func(c *transformStreamController) goja.Value {
+			return transformOffLoop(c, t.Flush)
+		},
+	}, 1, extractSizeAlgorithm(rt, nil), 0, extractSizeAlgorithm(rt, nil))
+	if err != nil {
+		return nil, err
+	}
+
+	defineReadableWritable(rt, obj, readableObj, writableObj)
+	return obj, nil
+}
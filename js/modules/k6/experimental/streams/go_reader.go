@@ -0,0 +1,186 @@
+package streams
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/modules"
+)
+
+// readableStreamSymbol keys the implementation of the ReadableStream
+// objects, so that they can be read from Go, e.g. by the other modules.
+var readableStreamSymbol = goja.NewSymbol("k6/experimental/streams.ReadableStream") //nolint:gochecknoglobals
+
+var errReaderClosed = errors.New("the reader of the stream has been closed")
+
+// IsReadableStream returns true if the value is a ReadableStream of this module.
+func IsReadableStream(v goja.Value) bool {
+	_, ok := asReadableStream(v)
+	return ok
+}
+
+func asReadableStream(v goja.Value) (*readableStream, bool) {
+	obj, ok := v.(*goja.Object)
+	if !ok {
+		return nil, false
+	}
+	impl := obj.GetSymbol(readableStreamSymbol)
+	if impl == nil {
+		return nil, false
+	}
+	s, ok := impl.Export().(*readableStream)
+	return s, ok
+}
+
+// Reader reads the chunks of a ReadableStream from Go, e.g. for sending them
+// as the body of a request. The chunks must be ArrayBuffers or ArrayBufferViews.
+//
+// The streams made by NewReadableStreamFromReader, e.g. of the files, are read
+// directly from their Go reader. The others have a source implemented by the
+// script, so they are read on the event loop, which has to run meanwhile: they
+// can't be read during a synchronous call of the script.
+type Reader struct {
+	vu     modules.VU
+	stream *readableStream
+	reader *readableStreamReader
+	direct io.ReadCloser
+
+	// callback is registered while the reader is open and not reading,
+	// so the event loop waits for the reads of the stream.
+	mu       sync.Mutex
+	callback func(func() error)
+	closed   bool
+
+	buf []byte
+	err error
+}
+
+var _ io.ReadCloser = &Reader{}
+
+// NewReader locks the ReadableStream to a new Reader, which has to be closed
+// once it's not read anymore. It has to be called on the event loop.
+func NewReader(vu modules.VU, v goja.Value) (*Reader, error) {
+	s, ok := asReadableStream(v)
+	if !ok {
+		return nil, errors.New("the value isn't a ReadableStream")
+	}
+	if s.locked() {
+		return nil, errors.New("the stream is already locked to a reader")
+	}
+	if s.disturbed {
+		return nil, errors.New("the stream has already been read")
+	}
+
+	reader, jsErr := s.acquireReader()
+	if jsErr != nil {
+		return nil, errors.New(jsErr.String())
+	}
+
+	r := &Reader{vu: vu, stream: s, reader: reader, direct: s.goReader}
+	if r.direct == nil {
+		r.callback = vu.RegisterCallback()
+	}
+	return r, nil
+}
+
+// OnEventLoop returns true if the chunks are read on the event loop,
+// as the source of the stream is implemented by the script.
+func (r *Reader) OnEventLoop() bool {
+	return r.direct == nil
+}
+
+// Read implements io.Reader, it can be called from any goroutine.
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.direct != nil {
+		return r.direct.Read(p)
+	}
+
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.buf, r.err = r.readChunk()
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// readChunk reads the next chunk of the stream on the event loop.
+func (r *Reader) readChunk() ([]byte, error) {
+	r.mu.Lock()
+	callback := r.callback
+	r.callback = nil
+	r.mu.Unlock()
+	if callback == nil {
+		return nil, errReaderClosed
+	}
+
+	type result struct {
+		data []byte
+		err  error
+	}
+	results := make(chan result, 1)
+	callback(func() error {
+		r.mu.Lock()
+		if !r.closed {
+			r.callback = r.vu.RegisterCallback()
+		}
+		r.mu.Unlock()
+
+		rt := r.vu.Runtime()
+		r.reader.read(readRequest{
+			chunkSteps: func(chunk goja.Value) {
+				data, err := exportBytes(rt, chunk)
+				// the chunk is copied, as it can only be read on the event loop
+				results <- result{data: append([]byte{}, data...), err: err}
+			},
+			closeSteps: func() {
+				results <- result{err: io.EOF}
+			},
+			errorSteps: func(e goja.Value) {
+				results <- result{err: fmt.Errorf("the stream has errored: %s", e)}
+			},
+		})
+		return nil
+	})
+
+	select {
+	case res := <-results:
+		return res.data, res.err
+	case <-r.vu.Context().Done():
+		return nil, r.vu.Context().Err()
+	}
+}
+
+// Close implements io.Closer, it can be called from any goroutine. The stream
+// is canceled if it hasn't been fully read, and it stays locked.
+func (r *Reader) Close() error {
+	if r.direct != nil {
+		return r.direct.Close()
+	}
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	callback := r.callback
+	r.callback = nil
+	r.mu.Unlock()
+
+	if callback != nil {
+		callback(func() error {
+			if r.stream.state == stateReadable {
+				markAsHandled(r.vu.Runtime(), r.stream.cancel(goja.Undefined()))
+			}
+			return nil
+		})
+	}
+	return nil
+}
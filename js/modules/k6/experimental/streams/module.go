@@ -0,0 +1,247 @@
+// Package streams implements a k6 JS module with the WHATWG ReadableStream,
+// WritableStream and TransformStream, so that scripts can process big
+// datasets, e.g. files or response bodies, chunk by chunk.
+package streams
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the JS module.
+	ModuleInstance struct {
+		vu modules.VU
+
+		readableStreamCtor *goja.Object
+		writableStreamCtor *goja.Object
+	}
+)
+
+// Ensure the interfaces are implemented correctly
+var (
+	_ modules.Instance = &ModuleInstance{}
+	_ modules.Module   = &RootModule{}
+)
+
+// New returns a pointer to a new RootModule instance
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns
+// a new instance for each VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	mi := &ModuleInstance{vu: vu}
+	rt := vu.Runtime()
+	mi.readableStreamCtor = rt.ToValue(mi.newReadableStream).ToObject(rt)
+	mi.writableStreamCtor = rt.ToValue(mi.newWritableStream).ToObject(rt)
+	return mi
+}
+
+// Exports implements the modules.Instance interface and returns
+// the exports of the JS module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"ReadableStream":            mi.readableStreamCtor,
+			"WritableStream":            mi.writableStreamCtor,
+			"TransformStream":           mi.newTransformStream,
+			"TextDecoderStream":         mi.newTextDecoderStream,
+			"CountQueuingStrategy":      mi.newCountQueuingStrategy,
+			"ByteLengthQueuingStrategy": mi.newByteLengthQueuingStrategy,
+		},
+	}
+}
+
+// newReadableStream is the ReadableStream(underlyingSource, strategy) constructor.
+func (mi *ModuleInstance) newReadableStream(call goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+	source := newJSUnderlyingSource(rt, call.Argument(0))
+	strategy := call.Argument(1)
+
+	_, err := newReadableStream(rt, call.This, source,
+		extractHighWaterMark(rt, strategy, 1), extractSizeAlgorithm(rt, strategy))
+	if err != nil {
+		panic(err)
+	}
+	return nil
+}
+
+// newWritableStream is the WritableStream(underlyingSink, strategy) constructor.
+func (mi *ModuleInstance) newWritableStream(call goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+	sink := newJSUnderlyingSink(rt, call.Argument(0))
+	strategy := call.Argument(1)
+
+	_, err := newWritableStream(rt, call.This, sink,
+		extractHighWaterMark(rt, strategy, 1), extractSizeAlgorithm(rt, strategy))
+	if err != nil {
+		panic(err)
+	}
+	return nil
+}
+
+// newTransformStream is the TransformStream(transformer, writableStrategy,
+// readableStrategy) constructor.
+func (mi *ModuleInstance) newTransformStream(call goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+	t := newJSTransformer(rt, call.Argument(0))
+	writableStrategy, readableStrategy := call.Argument(1), call.Argument(2)
+
+	readableObj, writableObj := mi.newStreamObjects()
+	_, err := newTransformStream(rt, readableObj, writableObj, t,
+		extractHighWaterMark(rt, writableStrategy, 1), extractSizeAlgorithm(rt, writableStrategy),
+		extractHighWaterMark(rt, readableStrategy, 0), extractSizeAlgorithm(rt, readableStrategy))
+	if err != nil {
+		panic(err)
+	}
+
+	defineReadableWritable(rt, call.This, readableObj, writableObj)
+	return nil
+}
+
+// newTextDecoderStream is the TextDecoderStream(label, options) constructor.
+func (mi *ModuleInstance) newTextDecoderStream(call goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+
+	readableObj, writableObj := mi.newStreamObjects()
+	err := newTextDecoderStream(rt, call.This, readableObj, writableObj, call.Argument(0), call.Argument(1))
+	if err != nil {
+		panic(err)
+	}
+	return nil
+}
+
+// newStreamObjects returns the objects of the readable and the writable
+// sides of a transform stream, which are ReadableStream and WritableStream
+// instances.
+func (mi *ModuleInstance) newStreamObjects() (readableObj, writableObj *goja.Object) {
+	rt := mi.vu.Runtime()
+
+	readableObj = rt.NewObject()
+	must(rt, readableObj.SetPrototype(mi.readableStreamCtor.Get("prototype").ToObject(rt)))
+	writableObj = rt.NewObject()
+	must(rt, writableObj.SetPrototype(mi.writableStreamCtor.Get("prototype").ToObject(rt)))
+
+	return readableObj, writableObj
+}
+
+// newCountQueuingStrategy is the CountQueuingStrategy({highWaterMark}) constructor,
+// a queuing strategy which counts the number of chunks.
+func (mi *ModuleInstance) newCountQueuingStrategy(call goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+	defineQueuingStrategy(rt, call.This, call.Argument(0), func(goja.Value) float64 { return 1 })
+	return nil
+}
+
+// newByteLengthQueuingStrategy is the ByteLengthQueuingStrategy({highWaterMark})
+// constructor, a queuing strategy which counts the number of bytes of the chunks.
+func (mi *ModuleInstance) newByteLengthQueuingStrategy(call goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+	defineQueuingStrategy(rt, call.This, call.Argument(0), func(chunk goja.Value) float64 {
+		byteLength := chunk.ToObject(rt).Get("byteLength")
+		if byteLength == nil {
+			return math.NaN()
+		}
+		return byteLength.ToFloat()
+	})
+	return nil
+}
+
+func defineQueuingStrategy(rt *goja.Runtime, obj *goja.Object, init goja.Value, size func(goja.Value) float64) {
+	if common.IsNullish(init) || common.IsNullish(init.ToObject(rt).Get("highWaterMark")) {
+		panic(rt.NewTypeError("the highWaterMark of the queuing strategy is required"))
+	}
+
+	hwm := init.ToObject(rt).Get("highWaterMark").ToFloat()
+	must(rt, obj.DefineDataProperty("highWaterMark", rt.ToValue(hwm), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+	must(rt, obj.DefineDataProperty("size", rt.ToValue(size), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+}
+
+func defineReadableWritable(rt *goja.Runtime, obj, readableObj, writableObj *goja.Object) {
+	must(rt, obj.DefineDataProperty("readable", readableObj, goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+	must(rt, obj.DefineDataProperty("writable", writableObj, goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+}
+
+// pendingPromise is a promise with its resolve and reject functions.
+type pendingPromise struct {
+	promise *goja.Promise
+	resolve func(interface{})
+	reject  func(interface{})
+}
+
+func newPendingPromise(rt *goja.Runtime) *pendingPromise {
+	promise, resolve, reject := rt.NewPromise()
+	return &pendingPromise{promise: promise, resolve: resolve, reject: reject}
+}
+
+// defineMethod defines a non-enumerable method on the object.
+func defineMethod(rt *goja.Runtime, obj *goja.Object, name string, fn interface{}) {
+	must(rt, obj.DefineDataProperty(name, rt.ToValue(fn), goja.FLAG_TRUE, goja.FLAG_TRUE, goja.FLAG_FALSE))
+}
+
+// defineGetter defines a read-only accessor property on the object.
+func defineGetter(rt *goja.Runtime, obj *goja.Object, name string, getter interface{}) {
+	must(rt, obj.DefineAccessorProperty(name, rt.ToValue(getter), nil, goja.FLAG_TRUE, goja.FLAG_TRUE))
+}
+
+// getBool returns the boolean value of the property of the object,
+// which is false if it isn't defined.
+func getBool(obj *goja.Object, name string) bool {
+	value := obj.Get(name)
+	return value != nil && value.ToBoolean()
+}
+
+// getMethod returns the method of the object, or nil if it isn't defined.
+func getMethod(rt *goja.Runtime, obj *goja.Object, name string) goja.Callable {
+	value := obj.Get(name)
+	if common.IsNullish(value) {
+		return nil
+	}
+
+	method, ok := goja.AssertFunction(value)
+	if !ok {
+		panic(rt.NewTypeError(name + " must be a function"))
+	}
+	return method
+}
+
+// exportBytes returns the bytes of an ArrayBuffer, a typed array or a DataView.
+func exportBytes(rt *goja.Runtime, value goja.Value) ([]byte, error) {
+	if common.IsNullish(value) {
+		return nil, fmt.Errorf("invalid chunk %s, expected an ArrayBuffer or an ArrayBufferView", value)
+	}
+
+	switch v := value.Export().(type) {
+	case goja.ArrayBuffer:
+		return v.Bytes(), nil
+	case []byte:
+		return v, nil
+	}
+
+	// a DataView, or a typed array which isn't exported as a []byte
+	obj := value.ToObject(rt)
+	buffer, offset, length := obj.Get("buffer"), obj.Get("byteOffset"), obj.Get("byteLength")
+	if buffer != nil && offset != nil && length != nil {
+		if ab, ok := buffer.Export().(goja.ArrayBuffer); ok {
+			return ab.Bytes()[offset.ToInteger() : offset.ToInteger()+length.ToInteger()], nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid chunk %s, expected an ArrayBuffer or an ArrayBufferView", value)
+}
+
+func must(rt *goja.Runtime, err error) {
+	if err != nil {
+		common.Throw(rt, err)
+	}
+}
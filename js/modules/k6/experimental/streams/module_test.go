@@ -0,0 +1,505 @@
+package streams
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/js/modulestest"
+)
+
+func newTestRuntime(t *testing.T) *modulestest.Runtime {
+	t.Helper()
+	ts := modulestest.NewRuntime(t)
+	m, ok := New().NewModuleInstance(ts.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, ts.VU.Runtime().Set("streams", m.Exports().Named))
+	_, err := ts.VU.Runtime().RunString(`
+		var { ReadableStream, WritableStream, TransformStream, TextDecoderStream,
+			CountQueuingStrategy, ByteLengthQueuingStrategy } = streams;
+
+		async function readAll(stream) {
+			const reader = stream.getReader();
+			const chunks = [];
+			for (;;) {
+				const { value, done } = await reader.read();
+				if (done) {
+					return chunks;
+				}
+				chunks.push(value);
+			}
+		}
+
+		function assertEquals(actual, expected) {
+			if (JSON.stringify(actual) !== JSON.stringify(expected)) {
+				throw new Error("expected " + JSON.stringify(expected) + ", got " + JSON.stringify(actual));
+			}
+		}
+	`)
+	require.NoError(t, err)
+	return ts
+}
+
+func TestStreams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, script string
+	}{
+		{
+			name: "enqueue in start",
+			script: `
+				const stream = new ReadableStream({
+					start(controller) {
+						controller.enqueue("a");
+						controller.enqueue("b");
+						controller.close();
+					},
+				});
+				assertEquals(stream instanceof ReadableStream, true);
+				assertEquals(await readAll(stream), ["a", "b"]);
+			`,
+		},
+		{
+			name: "pull with backpressure",
+			script: `
+				let pulls = 0;
+				const stream = new ReadableStream({
+					pull(controller) {
+						pulls++;
+						if (pulls > 5) {
+							controller.close();
+							return;
+						}
+						controller.enqueue(pulls);
+					},
+				}, new CountQueuingStrategy({ highWaterMark: 2 }));
+				await Promise.resolve();
+				await Promise.resolve();
+				assertEquals(pulls, 2);
+				assertEquals(await readAll(stream), [1, 2, 3, 4, 5]);
+			`,
+		},
+		{
+			name: "async pull",
+			script: `
+				let i = 0;
+				const stream = new ReadableStream({
+					async pull(controller) {
+						await new Promise(resolve => resolve());
+						i < 3 ? controller.enqueue(i++) : controller.close();
+					},
+				}, { highWaterMark: 0 });
+				assertEquals(await readAll(stream), [0, 1, 2]);
+			`,
+		},
+		{
+			name: "cancel",
+			script: `
+				let reason;
+				const stream = new ReadableStream({
+					start(controller) {
+						controller.enqueue("a");
+					},
+					cancel(r) {
+						reason = r;
+					},
+				});
+				const reader = stream.getReader();
+				assertEquals(await reader.read(), { value: "a", done: false });
+				await reader.cancel("enough");
+				assertEquals(reason, "enough");
+				assertEquals(await reader.read(), { done: true });
+				await reader.closed;
+			`,
+		},
+		{
+			name: "error",
+			script: `
+				const stream = new ReadableStream({
+					pull(controller) {
+						throw new Error("boom");
+					},
+				});
+				const reader = stream.getReader();
+				let error;
+				try {
+					await reader.read();
+				} catch (e) {
+					error = e;
+				}
+				assertEquals(error.message, "boom");
+				try {
+					await reader.closed;
+					throw new Error("closed should be rejected");
+				} catch (e) {
+					assertEquals(e.message, "boom");
+				}
+			`,
+		},
+		{
+			name: "locked",
+			script: `
+				const stream = new ReadableStream();
+				const reader = stream.getReader();
+				assertEquals(stream.locked, true);
+				let error;
+				try {
+					stream.getReader();
+				} catch (e) {
+					error = e;
+				}
+				assertEquals(error instanceof TypeError, true);
+
+				const read = reader.read();
+				reader.releaseLock();
+				assertEquals(stream.locked, false);
+				try {
+					await read;
+					throw new Error("the pending read should be rejected");
+				} catch (e) {
+					assertEquals(e instanceof TypeError, true);
+				}
+				stream.getReader();
+			`,
+		},
+		{
+			name: "size errors the stream",
+			script: `
+				let controller;
+				const stream = new ReadableStream({
+					start(c) {
+						controller = c;
+					},
+				}, { highWaterMark: 10, size() { return -1 } });
+				let error;
+				try {
+					controller.enqueue("a");
+				} catch (e) {
+					error = e;
+				}
+				assertEquals(error instanceof RangeError, true);
+				assertEquals(controller.desiredSize, null);
+			`,
+		},
+		{
+			name: "write",
+			script: `
+				const written = [];
+				let closed = false;
+				const stream = new WritableStream({
+					async write(chunk) {
+						await Promise.resolve();
+						written.push(chunk);
+					},
+					close() {
+						closed = true;
+					},
+				}, new CountQueuingStrategy({ highWaterMark: 2 }));
+				const writer = stream.getWriter();
+				assertEquals(writer.desiredSize, 2);
+				writer.write("a");
+				writer.write("b");
+				assertEquals(writer.desiredSize, 0);
+				writer.write("c");
+				await writer.close();
+				assertEquals(written, ["a", "b", "c"]);
+				assertEquals(closed, true);
+				await writer.closed;
+			`,
+		},
+		{
+			name: "abort",
+			script: `
+				let reason;
+				const stream = new WritableStream({
+					abort(r) {
+						reason = r;
+					},
+				});
+				const writer = stream.getWriter();
+				await writer.abort("stop");
+				assertEquals(reason, "stop");
+				try {
+					await writer.write("a");
+					throw new Error("the write should be rejected");
+				} catch (e) {
+					assertEquals(e, "stop");
+				}
+			`,
+		},
+		{
+			name: "pipe through a transform stream",
+			script: `
+				const source = new ReadableStream({
+					start(controller) {
+						["a", "b", "c"].forEach(chunk => controller.enqueue(chunk));
+						controller.close();
+					},
+				});
+				const upperCase = new TransformStream({
+					transform(chunk, controller) {
+						controller.enqueue(chunk.toUpperCase());
+					},
+					flush(controller) {
+						controller.enqueue("!");
+					},
+				});
+				assertEquals(await readAll(source.pipeThrough(upperCase)), ["A", "B", "C", "!"]);
+			`,
+		},
+		{
+			name: "pipe to",
+			script: `
+				const written = [];
+				const source = new ReadableStream({
+					start(controller) {
+						for (let i = 0; i < 10; i++) {
+							controller.enqueue(i);
+						}
+						controller.close();
+					},
+				});
+				await source.pipeThrough(new TransformStream()).pipeTo(new WritableStream({
+					write(chunk) {
+						written.push(chunk);
+					},
+				}));
+				assertEquals(written, [0, 1, 2, 3, 4, 5, 6, 7, 8, 9]);
+			`,
+		},
+		{
+			name: "pipe to an erroring destination cancels the source",
+			script: `
+				let reason;
+				const source = new ReadableStream({
+					pull(controller) {
+						controller.enqueue("a");
+					},
+					cancel(r) {
+						reason = r;
+					},
+				});
+				try {
+					await source.pipeTo(new WritableStream({
+						write() {
+							throw new Error("full");
+						},
+					}));
+					throw new Error("the pipe should be rejected");
+				} catch (e) {
+					assertEquals(e.message, "full");
+				}
+				assertEquals(reason.message, "full");
+			`,
+		},
+		{
+			name: "transform errors",
+			script: `
+				const source = new ReadableStream({
+					start(controller) {
+						controller.enqueue("a");
+						controller.close();
+					},
+				});
+				const readable = source.pipeThrough(new TransformStream({
+					transform() {
+						throw new Error("invalid chunk");
+					},
+				}));
+				try {
+					await readAll(readable);
+					throw new Error("the read should be rejected");
+				} catch (e) {
+					assertEquals(e.message, "invalid chunk");
+				}
+			`,
+		},
+		{
+			name: "text decoder stream",
+			script: `
+				const bytes = [0xEF, 0xBB, 0xBF, 0x7B, 0x22, 0x61, 0x22, 0x3A, 0x22, 0xE2, 0x82,
+					0xAC, 0x22, 0x7D, 0x0A, 0x7B, 0x22, 0x61, 0x22, 0x3A, 0x31, 0x7D, 0x0A];
+				const source = new ReadableStream({
+					start(controller) {
+						// the euro sign is split between two chunks
+						controller.enqueue(new Uint8Array(bytes.slice(0, 10)));
+						controller.enqueue(new Uint8Array(bytes.slice(10)).buffer);
+						controller.close();
+					},
+				});
+				let buffered = "";
+				const lines = new TransformStream({
+					transform(chunk, controller) {
+						buffered += chunk;
+						const parts = buffered.split("\n");
+						buffered = parts.pop();
+						parts.forEach(line => controller.enqueue(JSON.parse(line)));
+					},
+				});
+				const decoder = new TextDecoderStream();
+				assertEquals(decoder.encoding, "utf-8");
+				assertEquals(await readAll(source.pipeThrough(decoder).pipeThrough(lines)), [{ a: "€" }, { a: 1 }]);
+			`,
+		},
+		{
+			name: "fatal text decoder stream",
+			script: `
+				const source = new ReadableStream({
+					start(controller) {
+						controller.enqueue(new Uint8Array([0x61, 0xFF, 0x62]));
+						controller.close();
+					},
+				});
+				try {
+					await readAll(source.pipeThrough(new TextDecoderStream("utf-8", { fatal: true })));
+					throw new Error("the read should be rejected");
+				} catch (e) {
+					assertEquals(e instanceof TypeError, true);
+				}
+			`,
+		},
+		{
+			name: "byte length queuing strategy",
+			script: `
+				const strategy = new ByteLengthQueuingStrategy({ highWaterMark: 16 });
+				assertEquals(strategy.highWaterMark, 16);
+				assertEquals(strategy.size(new ArrayBuffer(8)), 8);
+				let controller;
+				new ReadableStream({
+					start(c) {
+						controller = c;
+					},
+				}, strategy);
+				controller.enqueue(new Uint8Array(10));
+				assertEquals(controller.desiredSize, 6);
+			`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestRuntime(t)
+			_, err := ts.RunOnEventLoop(`
+				(async () => {` + tc.script + `})().catch(e => { throw e; });
+			`)
+			require.NoError(t, err)
+		})
+	}
+}
+
+type testReadCloser struct {
+	io.Reader
+	closed bool
+}
+
+func (r *testReadCloser) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestNewReadableStreamFromReader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("read", func(t *testing.T) {
+		t.Parallel()
+
+		ts := newTestRuntime(t)
+		r := &testReadCloser{Reader: strings.NewReader("0123456789")}
+		stream, err := NewReadableStreamFromReader(ts.VU, r, 4)
+		require.NoError(t, err)
+		require.NoError(t, ts.VU.Runtime().Set("stream", stream))
+
+		_, err = ts.RunOnEventLoop(`
+			(async () => {
+				const chunks = await readAll(stream);
+				assertEquals(chunks.map(chunk => chunk instanceof Uint8Array), [true, true, true]);
+				assertEquals(chunks.map(chunk => Array.from(chunk).map(c => String.fromCharCode(c)).join("")),
+					["0123", "4567", "89"]);
+			})().catch(e => { throw e; });
+		`)
+		require.NoError(t, err)
+		assert.True(t, r.closed)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+
+		ts := newTestRuntime(t)
+		r := &testReadCloser{Reader: strings.NewReader("0123456789")}
+		stream, err := NewReadableStreamFromReader(ts.VU, r, 4)
+		require.NoError(t, err)
+		require.NoError(t, ts.VU.Runtime().Set("stream", stream))
+
+		_, err = ts.RunOnEventLoop(`
+			(async () => {
+				const reader = stream.getReader();
+				await reader.read();
+				await reader.cancel();
+			})().catch(e => { throw e; });
+		`)
+		require.NoError(t, err)
+		assert.True(t, r.closed)
+	})
+}
+
+// upperTransformer upper cases the chunks, and appends an end marker.
+type upperTransformer struct{}
+
+func (upperTransformer) Transform(chunk []byte) ([]byte, error) {
+	if string(chunk) == "fail" {
+		return nil, errors.New("the transformation failed")
+	}
+	return bytes.ToUpper(chunk), nil
+}
+
+func (upperTransformer) Flush() ([]byte, error) {
+	return []byte("."), nil
+}
+
+func TestNewByteTransformStream(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestRuntime(t)
+	_, err := ts.VU.Runtime().RunString(`
+		var toBytes = s => new Uint8Array(Array.from(s, c => c.charCodeAt(0)));
+		var fromBytes = b => String.fromCharCode(...b);
+	`)
+	require.NoError(t, err)
+	require.NoError(t, ts.VU.Runtime().Set("newStream", func() (interface{}, error) {
+		return NewByteTransformStream(ts.VU, upperTransformer{})
+	}))
+
+	_, err = ts.RunOnEventLoop(`
+		(async () => {
+			const chunks = await readAll(new ReadableStream({
+				start(controller) {
+					controller.enqueue(toBytes("abc"));
+					controller.enqueue(toBytes("def").buffer);
+					controller.close();
+				},
+			}).pipeThrough(newStream()));
+			assertEquals(chunks.map(chunk => chunk instanceof Uint8Array), [true, true, true]);
+			assertEquals(chunks.map(fromBytes), ["ABC", "DEF", "."]);
+
+			try {
+				await readAll(new ReadableStream({
+					start(controller) {
+						controller.enqueue(toBytes("fail"));
+					},
+				}).pipeThrough(newStream()));
+				throw new Error("the transformation should fail");
+			} catch (e) {
+				assertEquals(e.message, "the transformation failed");
+			}
+		})().catch(e => { throw e; });
+	`)
+	require.NoError(t, err)
+}
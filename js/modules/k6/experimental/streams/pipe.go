@@ -0,0 +1,172 @@
+package streams
+
+import (
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+)
+
+// pipe pipes a readable stream to a writable stream. The destination is
+// used through the methods of its writer, so any object with a getWriter
+// method returning a WritableStreamDefaultWriter-like object can be used.
+//
+// [specification]: https://streams.spec.whatwg.org/#readable-stream-pipe-to
+type pipe struct {
+	rt     *goja.Runtime
+	reader *readableStreamReader
+	writer *goja.Object
+
+	preventClose, preventAbort, preventCancel bool
+
+	finished bool
+	resolve  func(interface{})
+	reject   func(interface{})
+}
+
+// pipeTo pipes the stream to the destination, and returns a promise that
+// is fulfilled once the whole stream has been written, or rejected with the
+// error of the source or of the destination.
+func (s *readableStream) pipeTo(destination goja.Value, options goja.Value) *goja.Promise {
+	rt := s.rt
+	if common.IsNullish(destination) {
+		return newRejectedPromise(rt, rt.NewTypeError("the destination must be a WritableStream"))
+	}
+	getWriter, ok := goja.AssertFunction(destination.ToObject(rt).Get("getWriter"))
+	if !ok {
+		return newRejectedPromise(rt, rt.NewTypeError("the destination must be a WritableStream"))
+	}
+	if s.locked() {
+		return newRejectedPromise(rt, rt.NewTypeError("cannot pipe a locked stream"))
+	}
+
+	writer, err := getWriter(destination)
+	if err != nil {
+		return newRejectedPromise(rt, errorValue(rt, err))
+	}
+	reader, _ := s.acquireReader()
+
+	promise, resolve, reject := rt.NewPromise()
+	p := &pipe{rt: rt, reader: reader, writer: writer.ToObject(rt), resolve: resolve, reject: reject}
+	if !common.IsNullish(options) {
+		opts := options.ToObject(rt)
+		p.preventClose = getBool(opts, "preventClose")
+		p.preventAbort = getBool(opts, "preventAbort")
+		p.preventCancel = getBool(opts, "preventCancel")
+	}
+
+	promiseThen(rt, p.writer.Get("closed"), func(goja.Value) {
+		p.destinationErrored(rt.NewTypeError("the destination has been closed"))
+	}, p.destinationErrored)
+
+	p.next()
+	return promise
+}
+
+// next waits for the destination to be ready, then reads the next chunk
+// of the source and writes it to the destination.
+func (p *pipe) next() {
+	promiseThen(p.rt, p.writer.Get("ready"), func(goja.Value) {
+		if p.finished {
+			return
+		}
+
+		p.reader.read(readRequest{
+			chunkSteps: func(chunk goja.Value) {
+				if p.finished {
+					return
+				}
+				promiseThen(p.rt, p.call("write", chunk), func(goja.Value) {}, p.destinationErrored)
+				p.next()
+			},
+			closeSteps: p.sourceClosed,
+			errorSteps: p.sourceErrored,
+		})
+	}, func(goja.Value) {
+		// the destination errored, which is handled with its closed promise
+	})
+}
+
+func (p *pipe) sourceClosed() {
+	if p.finished {
+		return
+	}
+	if p.preventClose {
+		p.finish(nil)
+		return
+	}
+
+	p.finished = true
+	promiseThen(p.rt, p.call("close"), func(goja.Value) {
+		p.release()
+		p.resolve(goja.Undefined())
+	}, func(r goja.Value) {
+		p.release()
+		p.reject(r)
+	})
+}
+
+func (p *pipe) sourceErrored(e goja.Value) {
+	if p.finished {
+		return
+	}
+	if p.preventAbort {
+		p.finish(e)
+		return
+	}
+
+	p.finished = true
+	promiseThen(p.rt, p.call("abort", e), func(goja.Value) {
+		p.release()
+		p.reject(e)
+	}, func(goja.Value) {
+		p.release()
+		p.reject(e)
+	})
+}
+
+func (p *pipe) destinationErrored(e goja.Value) {
+	if p.finished {
+		return
+	}
+	if p.preventCancel || p.reader.stream == nil {
+		p.finish(e)
+		return
+	}
+
+	p.finished = true
+	promiseThen(p.rt, p.rt.ToValue(p.reader.stream.cancel(e)), func(goja.Value) {
+		p.release()
+		p.reject(e)
+	}, func(goja.Value) {
+		p.release()
+		p.reject(e)
+	})
+}
+
+// finish releases the locks on the streams, and settles the promise
+// of the pipe with the error, if any.
+func (p *pipe) finish(e goja.Value) {
+	p.finished = true
+	p.release()
+	if e == nil {
+		p.resolve(goja.Undefined())
+	} else {
+		p.reject(e)
+	}
+}
+
+func (p *pipe) release() {
+	p.reader.releaseLock()
+	if releaseLock, ok := goja.AssertFunction(p.writer.Get("releaseLock")); ok {
+		_, _ = releaseLock(p.writer)
+	}
+}
+
+// call calls the method of the writer, a synchronous exception results in a
+// rejected promise.
+func (p *pipe) call(method string, args ...goja.Value) goja.Value {
+	fn, ok := goja.AssertFunction(p.writer.Get(method))
+	if !ok {
+		return p.rt.ToValue(newRejectedPromise(p.rt, p.rt.NewTypeError("the writer has no "+method+" method")))
+	}
+	return promiseCall(p.rt, fn, p.writer, args...)
+}
@@ -0,0 +1,74 @@
+package streams
+
+import (
+	"github.com/dop251/goja"
+)
+
+// newResolvedPromise returns a promise already fulfilled with the value.
+func newResolvedPromise(rt *goja.Runtime, value interface{}) *goja.Promise {
+	promise, resolve, _ := rt.NewPromise()
+	resolve(value)
+	return promise
+}
+
+// newRejectedPromise returns a promise already rejected with the reason.
+func newRejectedPromise(rt *goja.Runtime, reason interface{}) *goja.Promise {
+	promise, _, reject := rt.NewPromise()
+	reject(reason)
+	return promise
+}
+
+// promiseThen calls onFulfilled or onRejected, on the event loop, once the
+// value is settled. The value is either a promise, a thenable, or any other
+// value, which is then considered as fulfilled, the same way as with
+// Promise.resolve(value).then(onFulfilled, onRejected).
+func promiseThen(rt *goja.Runtime, value goja.Value, onFulfilled, onRejected func(goja.Value)) {
+	if value == nil {
+		value = goja.Undefined()
+	}
+	promiseCtor := rt.Get("Promise").ToObject(rt)
+	resolve, _ := goja.AssertFunction(promiseCtor.Get("resolve"))
+	promise, err := resolve(promiseCtor, value)
+	if err != nil {
+		panic(err)
+	}
+
+	promiseObj := promise.ToObject(rt)
+	then, _ := goja.AssertFunction(promiseObj.Get("then"))
+	_, err = then(promiseObj,
+		rt.ToValue(func(v goja.Value) { onFulfilled(v) }),
+		rt.ToValue(func(r goja.Value) { onRejected(r) }),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// markAsHandled prevents the rejection of the promise, which is
+// only exposed for information, e.g. closed, to be reported as unhandled.
+func markAsHandled(rt *goja.Runtime, promise *goja.Promise) {
+	promiseThen(rt, rt.ToValue(promise), func(goja.Value) {}, func(goja.Value) {})
+}
+
+// promiseCall calls the method of the underlying source, sink or
+// transformer, if it is defined, and returns its result as a promise. A
+// synchronous exception results in a rejected promise.
+func promiseCall(rt *goja.Runtime, method goja.Callable, this goja.Value, args ...goja.Value) goja.Value {
+	if method == nil {
+		return rt.ToValue(newResolvedPromise(rt, goja.Undefined()))
+	}
+
+	result, err := method(this, args...)
+	if err != nil {
+		return rt.ToValue(newRejectedPromise(rt, errorValue(rt, err)))
+	}
+	return result
+}
+
+// errorValue returns the JS value of an error, as it would be thrown.
+func errorValue(rt *goja.Runtime, err error) goja.Value {
+	if exception, ok := err.(*goja.Exception); ok { //nolint:errorlint
+		return exception.Value()
+	}
+	return rt.NewGoError(err)
+}
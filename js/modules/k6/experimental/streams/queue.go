@@ -0,0 +1,112 @@
+package streams
+
+import (
+	"math"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+)
+
+// sizeAlgorithm returns the size of a chunk, as used for backpressure, or
+// the error to throw if the size couldn't be computed.
+type sizeAlgorithm func(chunk goja.Value) (float64, goja.Value)
+
+// queueEntry is a chunk queued by a stream controller, with its size.
+type queueEntry struct {
+	value goja.Value
+	size  float64
+}
+
+// queue is the queue-with-sizes of the stream controllers.
+type queue struct {
+	entries   []queueEntry
+	totalSize float64
+}
+
+func (q *queue) len() int {
+	return len(q.entries)
+}
+
+func (q *queue) enqueue(value goja.Value, size float64) {
+	q.entries = append(q.entries, queueEntry{value: value, size: size})
+	q.totalSize += size
+}
+
+func (q *queue) peek() goja.Value {
+	return q.entries[0].value
+}
+
+func (q *queue) dequeue() goja.Value {
+	entry := q.entries[0]
+	q.entries[0] = queueEntry{}
+	q.entries = q.entries[1:]
+	q.totalSize -= entry.size
+	// rounding errors could make the total size negative
+	if q.totalSize < 0 {
+		q.totalSize = 0
+	}
+	return entry.value
+}
+
+func (q *queue) reset() {
+	q.entries = nil
+	q.totalSize = 0
+}
+
+// extractHighWaterMark returns the high water mark of the queuing strategy,
+// or the default one if it doesn't have one.
+func extractHighWaterMark(rt *goja.Runtime, strategy goja.Value, defaultHWM float64) float64 {
+	if common.IsNullish(strategy) {
+		return defaultHWM
+	}
+
+	value := strategy.ToObject(rt).Get("highWaterMark")
+	if common.IsNullish(value) {
+		return defaultHWM
+	}
+
+	hwm := value.ToFloat()
+	if math.IsNaN(hwm) || hwm < 0 {
+		panic(newRangeError(rt, "the highWaterMark must be a non-negative number"))
+	}
+	return hwm
+}
+
+// extractSizeAlgorithm returns the size algorithm of the queuing strategy,
+// which counts each chunk as 1 if the strategy doesn't have a size function.
+func extractSizeAlgorithm(rt *goja.Runtime, strategy goja.Value) sizeAlgorithm {
+	var size goja.Callable
+	if !common.IsNullish(strategy) {
+		if value := strategy.ToObject(rt).Get("size"); !common.IsNullish(value) {
+			var ok bool
+			if size, ok = goja.AssertFunction(value); !ok {
+				panic(rt.NewTypeError("the size of the queuing strategy must be a function"))
+			}
+		}
+	}
+
+	return func(chunk goja.Value) (float64, goja.Value) {
+		if size == nil {
+			return 1, nil
+		}
+
+		result, err := size(goja.Undefined(), chunk)
+		if err != nil {
+			return 0, errorValue(rt, err)
+		}
+
+		chunkSize := result.ToFloat()
+		if math.IsNaN(chunkSize) || math.IsInf(chunkSize, 0) || chunkSize < 0 {
+			return 0, newRangeError(rt, "the size of a chunk must be a finite, non-negative number")
+		}
+		return chunkSize, nil
+	}
+}
+
+func newRangeError(rt *goja.Runtime, msg string) *goja.Object {
+	rangeError, err := rt.New(rt.Get("RangeError"), rt.ToValue(msg))
+	if err != nil {
+		panic(err)
+	}
+	return rangeError
+}
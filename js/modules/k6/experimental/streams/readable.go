@@ -0,0 +1,531 @@
+package streams
+
+import (
+	"io"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+)
+
+// streamState is the state of a readable or a writable stream.
+type streamState int
+
+const (
+	stateReadable streamState = iota
+	stateWritable
+	stateClosed
+	stateErrored
+)
+
+// underlyingSource holds the algorithms of the source of a readable stream,
+// either implemented by the script or in Go, e.g. by a reader source.
+type underlyingSource struct {
+	start  func(c *readableStreamController) (goja.Value, error)
+	pull   func(c *readableStreamController) goja.Value
+	cancel func(reason goja.Value) goja.Value
+}
+
+// readableStream is the implementation of the WHATWG ReadableStream, with a
+// default controller. Byte streams and tee() aren't supported.
+//
+// [specification]: https://streams.spec.whatwg.org/#rs-class
+type readableStream struct {
+	rt  *goja.Runtime
+	obj *goja.Object
+
+	state       streamState
+	storedError goja.Value
+	disturbed   bool
+
+	controller *readableStreamController
+	reader     *readableStreamReader
+
+	// goReader is the reader of the stream's data,
+	// if it has been made by NewReadableStreamFromReader.
+	goReader io.ReadCloser
+}
+
+// newReadableStream sets up a readable stream on the object, with the
+// underlying source and the high water mark and the size algorithm of its
+// queuing strategy. It returns an error if the start of the source throws.
+func newReadableStream(
+	rt *goja.Runtime,
+	obj *goja.Object,
+	source underlyingSource,
+	highWaterMark float64,
+	size sizeAlgorithm,
+) (*readableStream, error) {
+	s := &readableStream{rt: rt, obj: obj, state: stateReadable}
+	s.controller = &readableStreamController{
+		stream:          s,
+		obj:             rt.NewObject(),
+		highWaterMark:   highWaterMark,
+		size:            size,
+		pullAlgorithm:   source.pull,
+		cancelAlgorithm: source.cancel,
+	}
+	s.controller.define()
+	s.define()
+
+	startResult, err := source.start(s.controller)
+	if err != nil {
+		return nil, err
+	}
+
+	c := s.controller
+	promiseThen(rt, startResult, func(goja.Value) {
+		c.started = true
+		c.callPullIfNeeded()
+	}, c.error)
+
+	return s, nil
+}
+
+// newJSUnderlyingSource returns the algorithms of an underlying source
+// implemented by the script, as an object with start, pull and cancel methods.
+func newJSUnderlyingSource(rt *goja.Runtime, source goja.Value) underlyingSource {
+	if common.IsNullish(source) {
+		source = rt.NewObject()
+	}
+	sourceObj := source.ToObject(rt)
+
+	if t := sourceObj.Get("type"); !common.IsNullish(t) {
+		panic(rt.NewTypeError("unsupported type of readable stream " + t.String()))
+	}
+
+	start := getMethod(rt, sourceObj, "start")
+	pull := getMethod(rt, sourceObj, "pull")
+	cancel := getMethod(rt, sourceObj, "cancel")
+
+	return underlyingSource{
+		start: func(c *readableStreamController) (goja.Value, error) {
+			if start == nil {
+				return goja.Undefined(), nil
+			}
+			return start(sourceObj, c.obj)
+		},
+		pull: func(c *readableStreamController) goja.Value {
+			return promiseCall(rt, pull, sourceObj, c.obj)
+		},
+		cancel: func(reason goja.Value) goja.Value {
+			return promiseCall(rt, cancel, sourceObj, reason)
+		},
+	}
+}
+
+// define defines the properties and the methods of the ReadableStream object.
+func (s *readableStream) define() {
+	rt := s.rt
+	must(rt, s.obj.DefineDataPropertySymbol(
+		readableStreamSymbol, rt.ToValue(s), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_FALSE))
+	defineGetter(rt, s.obj, "locked", func() bool { return s.locked() })
+	defineMethod(rt, s.obj, "cancel", s.jsCancel)
+	defineMethod(rt, s.obj, "getReader", s.getReader)
+	defineMethod(rt, s.obj, "pipeTo", s.pipeTo)
+	defineMethod(rt, s.obj, "pipeThrough", s.pipeThrough)
+}
+
+func (s *readableStream) locked() bool {
+	return s.reader != nil
+}
+
+func (s *readableStream) jsCancel(reason goja.Value) *goja.Promise {
+	if s.locked() {
+		return newRejectedPromise(s.rt, s.rt.NewTypeError("cannot cancel a locked stream"))
+	}
+	return s.cancel(reason)
+}
+
+func (s *readableStream) getReader(options goja.Value) *goja.Object {
+	if !common.IsNullish(options) {
+		if mode := options.ToObject(s.rt).Get("mode"); !common.IsNullish(mode) {
+			panic(s.rt.NewTypeError("unsupported reader mode " + mode.String()))
+		}
+	}
+
+	reader, err := s.acquireReader()
+	if err != nil {
+		panic(err)
+	}
+	return reader.obj
+}
+
+// pipeThrough pipes the stream to the writable side of the transform,
+// e.g. a TransformStream, and returns its readable side.
+func (s *readableStream) pipeThrough(transform goja.Value, options goja.Value) goja.Value {
+	rt := s.rt
+	if common.IsNullish(transform) {
+		panic(rt.NewTypeError("the transform must have a writable and a readable property"))
+	}
+
+	transformObj := transform.ToObject(rt)
+	writable, readable := transformObj.Get("writable"), transformObj.Get("readable")
+	if common.IsNullish(writable) || common.IsNullish(readable) {
+		panic(rt.NewTypeError("the transform must have a writable and a readable property"))
+	}
+	if s.locked() {
+		panic(rt.NewTypeError("cannot pipe a locked stream"))
+	}
+
+	markAsHandled(rt, s.pipeTo(writable, options))
+	return readable
+}
+
+// cancel implements the ReadableStreamCancel abstract operation.
+func (s *readableStream) cancel(reason goja.Value) *goja.Promise {
+	s.disturbed = true
+
+	switch s.state {
+	case stateClosed:
+		return newResolvedPromise(s.rt, goja.Undefined())
+	case stateErrored:
+		return newRejectedPromise(s.rt, s.storedError)
+	default:
+	}
+
+	s.close()
+
+	promise, resolve, reject := s.rt.NewPromise()
+	promiseThen(s.rt, s.controller.cancelSteps(reason), func(goja.Value) {
+		resolve(goja.Undefined())
+	}, func(r goja.Value) {
+		reject(r)
+	})
+	return promise
+}
+
+// close implements the ReadableStreamClose abstract operation.
+func (s *readableStream) close() {
+	s.state = stateClosed
+
+	if s.reader == nil {
+		return
+	}
+
+	s.reader.closed.resolve(goja.Undefined())
+	readRequests := s.reader.readRequests
+	s.reader.readRequests = nil
+	for _, readRequest := range readRequests {
+		readRequest.closeSteps()
+	}
+}
+
+// error implements the ReadableStreamError abstract operation.
+func (s *readableStream) error(e goja.Value) {
+	s.state = stateErrored
+	s.storedError = e
+
+	if s.reader == nil {
+		return
+	}
+
+	s.reader.closed.reject(e)
+	markAsHandled(s.rt, s.reader.closed.promise)
+	readRequests := s.reader.readRequests
+	s.reader.readRequests = nil
+	for _, readRequest := range readRequests {
+		readRequest.errorSteps(e)
+	}
+}
+
+// fulfillReadRequest fulfills the first pending read request with the chunk.
+func (s *readableStream) fulfillReadRequest(chunk goja.Value) {
+	readRequest := s.reader.readRequests[0]
+	s.reader.readRequests = s.reader.readRequests[1:]
+	readRequest.chunkSteps(chunk)
+}
+
+func (s *readableStream) numReadRequests() int {
+	if s.reader == nil {
+		return 0
+	}
+	return len(s.reader.readRequests)
+}
+
+// readableStreamController is the implementation of the WHATWG
+// ReadableStreamDefaultController.
+//
+// [specification]: https://streams.spec.whatwg.org/#rs-default-controller-class
+type readableStreamController struct {
+	stream *readableStream
+	obj    *goja.Object
+
+	queue          queue
+	started        bool
+	closeRequested bool
+	pulling        bool
+	pullAgain      bool
+
+	highWaterMark   float64
+	size            sizeAlgorithm
+	pullAlgorithm   func(c *readableStreamController) goja.Value
+	cancelAlgorithm func(reason goja.Value) goja.Value
+}
+
+// define defines the properties and the methods of the controller object.
+func (c *readableStreamController) define() {
+	rt := c.stream.rt
+	defineGetter(rt, c.obj, "desiredSize", c.jsDesiredSize)
+	defineMethod(rt, c.obj, "close", c.jsClose)
+	defineMethod(rt, c.obj, "enqueue", c.jsEnqueue)
+	defineMethod(rt, c.obj, "error", c.error)
+}
+
+func (c *readableStreamController) jsDesiredSize() goja.Value {
+	switch c.stream.state {
+	case stateErrored:
+		return goja.Null()
+	case stateClosed:
+		return c.stream.rt.ToValue(0)
+	default:
+		return c.stream.rt.ToValue(c.desiredSize())
+	}
+}
+
+func (c *readableStreamController) desiredSize() float64 {
+	return c.highWaterMark - c.queue.totalSize
+}
+
+func (c *readableStreamController) jsClose() {
+	if !c.canCloseOrEnqueue() {
+		panic(c.stream.rt.NewTypeError("the stream is closed or closing"))
+	}
+	c.close()
+}
+
+func (c *readableStreamController) jsEnqueue(chunk goja.Value) {
+	if !c.canCloseOrEnqueue() {
+		panic(c.stream.rt.NewTypeError("the stream is closed or closing"))
+	}
+	if err := c.enqueue(chunk); err != nil {
+		panic(err)
+	}
+}
+
+func (c *readableStreamController) canCloseOrEnqueue() bool {
+	return !c.closeRequested && c.stream.state == stateReadable
+}
+
+// close implements the ReadableStreamDefaultControllerClose abstract operation.
+func (c *readableStreamController) close() {
+	c.closeRequested = true
+	if c.queue.len() == 0 {
+		c.clearAlgorithms()
+		c.stream.close()
+	}
+}
+
+// enqueue implements the ReadableStreamDefaultControllerEnqueue abstract
+// operation. It returns the error of the size algorithm, if any, after
+// having errored the stream with it.
+func (c *readableStreamController) enqueue(chunk goja.Value) goja.Value {
+	if c.stream.numReadRequests() > 0 {
+		c.stream.fulfillReadRequest(chunk)
+	} else {
+		size, err := c.size(chunk)
+		if err != nil {
+			c.error(err)
+			return err
+		}
+		c.queue.enqueue(chunk, size)
+	}
+
+	c.callPullIfNeeded()
+	return nil
+}
+
+// error implements the ReadableStreamDefaultControllerError abstract operation.
+func (c *readableStreamController) error(e goja.Value) {
+	if c.stream.state != stateReadable {
+		return
+	}
+
+	c.queue.reset()
+	c.clearAlgorithms()
+	c.stream.error(e)
+}
+
+func (c *readableStreamController) clearAlgorithms() {
+	c.pullAlgorithm = nil
+	c.cancelAlgorithm = nil
+	c.size = nil
+}
+
+func (c *readableStreamController) shouldCallPull() bool {
+	if !c.canCloseOrEnqueue() || !c.started {
+		return false
+	}
+	if c.stream.numReadRequests() > 0 {
+		return true
+	}
+	return c.desiredSize() > 0
+}
+
+func (c *readableStreamController) callPullIfNeeded() {
+	if !c.shouldCallPull() {
+		return
+	}
+
+	if c.pulling {
+		c.pullAgain = true
+		return
+	}
+
+	c.pulling = true
+	promiseThen(c.stream.rt, c.pullAlgorithm(c), func(goja.Value) {
+		c.pulling = false
+		if c.pullAgain {
+			c.pullAgain = false
+			c.callPullIfNeeded()
+		}
+	}, c.error)
+}
+
+// cancelSteps implements the [[CancelSteps]] of the controller.
+func (c *readableStreamController) cancelSteps(reason goja.Value) goja.Value {
+	c.queue.reset()
+
+	if c.cancelAlgorithm == nil {
+		return goja.Undefined()
+	}
+
+	result := c.cancelAlgorithm(reason)
+	c.clearAlgorithms()
+	return result
+}
+
+// pullSteps implements the [[PullSteps]] of the controller.
+func (c *readableStreamController) pullSteps(readRequest readRequest) {
+	if c.queue.len() == 0 {
+		c.stream.reader.readRequests = append(c.stream.reader.readRequests, readRequest)
+		c.callPullIfNeeded()
+		return
+	}
+
+	chunk := c.queue.dequeue()
+	if c.closeRequested && c.queue.len() == 0 {
+		c.clearAlgorithms()
+		c.stream.close()
+	} else {
+		c.callPullIfNeeded()
+	}
+	readRequest.chunkSteps(chunk)
+}
+
+// readRequest is a pending read of a reader.
+type readRequest struct {
+	chunkSteps func(chunk goja.Value)
+	closeSteps func()
+	errorSteps func(e goja.Value)
+}
+
+// readableStreamReader is the implementation of the WHATWG
+// ReadableStreamDefaultReader.
+//
+// [specification]: https://streams.spec.whatwg.org/#default-reader-class
+type readableStreamReader struct {
+	rt     *goja.Runtime
+	obj    *goja.Object
+	stream *readableStream
+
+	closed       *pendingPromise
+	readRequests []readRequest
+}
+
+// acquireReader locks the stream to a new reader,
+// or returns the error to throw if it's already locked.
+func (s *readableStream) acquireReader() (*readableStreamReader, goja.Value) {
+	if s.locked() {
+		return nil, s.rt.NewTypeError("the stream is already locked to a reader")
+	}
+
+	r := &readableStreamReader{rt: s.rt, obj: s.rt.NewObject(), stream: s, closed: newPendingPromise(s.rt)}
+	s.reader = r
+
+	switch s.state {
+	case stateClosed:
+		r.closed.resolve(goja.Undefined())
+	case stateErrored:
+		r.closed.reject(s.storedError)
+		markAsHandled(s.rt, r.closed.promise)
+	default:
+	}
+
+	defineGetter(s.rt, r.obj, "closed", func() *goja.Promise { return r.closed.promise })
+	defineMethod(s.rt, r.obj, "read", r.jsRead)
+	defineMethod(s.rt, r.obj, "releaseLock", r.releaseLock)
+	defineMethod(s.rt, r.obj, "cancel", r.cancel)
+
+	return r, nil
+}
+
+// jsRead returns a promise of the next chunk of the stream,
+// as an object with value and done properties.
+func (r *readableStreamReader) jsRead() *goja.Promise {
+	if r.stream == nil {
+		return newRejectedPromise(r.rt, r.rt.NewTypeError("the reader has been released"))
+	}
+
+	promise, resolve, reject := r.rt.NewPromise()
+	r.read(readRequest{
+		chunkSteps: func(chunk goja.Value) { resolve(newReadResult(r.rt, chunk, false)) },
+		closeSteps: func() { resolve(newReadResult(r.rt, goja.Undefined(), true)) },
+		errorSteps: func(e goja.Value) { reject(e) },
+	})
+	return promise
+}
+
+// read implements the ReadableStreamDefaultReaderRead abstract operation.
+func (r *readableStreamReader) read(readRequest readRequest) {
+	s := r.stream
+	s.disturbed = true
+
+	switch s.state {
+	case stateClosed:
+		readRequest.closeSteps()
+	case stateErrored:
+		readRequest.errorSteps(s.storedError)
+	default:
+		s.controller.pullSteps(readRequest)
+	}
+}
+
+// releaseLock releases the lock of the reader on the stream,
+// the pending reads are rejected.
+func (r *readableStreamReader) releaseLock() {
+	if r.stream == nil {
+		return
+	}
+
+	releasedErr := r.rt.NewTypeError("the reader has been released")
+	if r.stream.state == stateReadable {
+		r.closed.reject(releasedErr)
+	} else {
+		r.closed = newPendingPromise(r.rt)
+		r.closed.reject(releasedErr)
+	}
+	markAsHandled(r.rt, r.closed.promise)
+
+	r.stream.reader = nil
+	r.stream = nil
+
+	readRequests := r.readRequests
+	r.readRequests = nil
+	for _, readRequest := range readRequests {
+		readRequest.errorSteps(releasedErr)
+	}
+}
+
+func (r *readableStreamReader) cancel(reason goja.Value) *goja.Promise {
+	if r.stream == nil {
+		return newRejectedPromise(r.rt, r.rt.NewTypeError("the reader has been released"))
+	}
+	return r.stream.cancel(reason)
+}
+
+func newReadResult(rt *goja.Runtime, value goja.Value, done bool) *goja.Object {
+	result := rt.NewObject()
+	must(rt, result.Set("value", value))
+	must(rt, result.Set("done", done))
+	return result
+}
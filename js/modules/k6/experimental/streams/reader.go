@@ -0,0 +1,106 @@
+package streams
+
+import (
+	"errors"
+	"io"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/modules"
+)
+
+// DefaultChunkSize is the maximum size of the chunks of the streams
+// returned by NewReadableStreamFromReader, if a different size isn't passed.
+const DefaultChunkSize = 64 * 1024
+
+// NewReadableStreamFromReader returns a ReadableStream of the data of the
+// reader, as Uint8Array chunks of up to chunkSize bytes. The reader is read
+// off the event loop, only when the script reads the stream, so that only a
+// chunk is kept in memory at a time. It is closed once it has been fully read,
+// or when the stream is canceled or errored.
+func NewReadableStreamFromReader(vu modules.VU, r io.ReadCloser, chunkSize int) (*goja.Object, error) {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	rt := vu.Runtime()
+	obj := rt.NewObject()
+	// reading is true while a chunk is read off the event loop, the reader is
+	// then closed once the read has finished, if the stream has been canceled.
+	var reading bool
+	s, err := newReadableStream(rt, obj, underlyingSource{
+		start: func(*readableStreamController) (goja.Value, error) {
+			return goja.Undefined(), nil
+		},
+		pull: func(c *readableStreamController) goja.Value {
+			promise, resolve, _ := rt.NewPromise()
+			callback := vu.RegisterCallback()
+			reading = true
+			go func() {
+				buf := make([]byte, chunkSize)
+				n, err := readChunk(r, buf)
+				callback(func() error {
+					reading = false
+					enqueueChunk(rt, c, r, buf[:n], err)
+					resolve(goja.Undefined())
+					return nil
+				})
+			}()
+			return rt.ToValue(promise)
+		},
+		cancel: func(goja.Value) goja.Value {
+			if !reading {
+				_ = r.Close()
+			}
+			return goja.Undefined()
+		},
+	}, 0, extractSizeAlgorithm(rt, nil))
+	if err != nil {
+		return nil, err
+	}
+	s.goReader = r
+
+	return obj, nil
+}
+
+// readChunk reads into the buffer until at least a byte has been read, or
+// an error has occurred.
+func readChunk(r io.Reader, buf []byte) (int, error) {
+	for {
+		n, err := r.Read(buf)
+		if n > 0 || err != nil {
+			return n, err
+		}
+	}
+}
+
+// enqueueChunk enqueues the data which has been read, and closes or errors
+// the stream if the reader has been fully read or has failed.
+func enqueueChunk(rt *goja.Runtime, c *readableStreamController, r io.Closer, data []byte, err error) {
+	if !c.canCloseOrEnqueue() {
+		// the stream has been canceled while reading
+		_ = r.Close()
+		return
+	}
+
+	if len(data) > 0 {
+		chunk, nerr := rt.New(rt.Get("Uint8Array"), rt.ToValue(rt.NewArrayBuffer(data)))
+		if nerr != nil {
+			c.error(errorValue(rt, nerr))
+			_ = r.Close()
+			return
+		}
+		if enqueueErr := c.enqueue(chunk); enqueueErr != nil {
+			_ = r.Close()
+			return
+		}
+	}
+
+	switch {
+	case errors.Is(err, io.EOF):
+		_ = r.Close()
+		c.close()
+	case err != nil:
+		_ = r.Close()
+		c.error(rt.NewGoError(err))
+	}
+}
@@ -0,0 +1,136 @@
+package streams
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+)
+
+// utf8BOM is the byte order mark which is skipped at the start of the stream.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF} //nolint:gochecknoglobals
+
+var errInvalidUTF8 = errors.New("the data isn't valid UTF-8")
+
+// textDecoder decodes a stream of UTF-8 encoded chunks, the bytes of a
+// character which is split across chunks are kept until the next chunk.
+//
+// [specification]: https://encoding.spec.whatwg.org/#interface-textdecoderstream
+type textDecoder struct {
+	fatal     bool
+	ignoreBOM bool
+
+	pending []byte
+	bomSeen bool
+}
+
+// decode returns the text of the chunk, up to the last complete character,
+// or all the remaining text when flush is true.
+func (d *textDecoder) decode(chunk []byte, flush bool) (string, error) {
+	data := append(d.pending, chunk...) //nolint:gocritic
+	d.pending = nil
+
+	if !d.bomSeen {
+		if len(data) < len(utf8BOM) && bytes.HasPrefix(utf8BOM, data) && !flush {
+			d.pending = data
+			return "", nil
+		}
+		d.bomSeen = true
+		if !d.ignoreBOM {
+			data = bytes.TrimPrefix(data, utf8BOM)
+		}
+	}
+
+	if !flush {
+		complete := lastCompleteIndex(data)
+		d.pending = append([]byte(nil), data[complete:]...)
+		data = data[:complete]
+	}
+
+	if utf8.Valid(data) {
+		return string(data), nil
+	}
+	if d.fatal {
+		return "", errInvalidUTF8
+	}
+	return strings.ToValidUTF8(string(data), string(utf8.RuneError)), nil
+}
+
+// lastCompleteIndex returns the index after the last complete character of
+// the data, i.e. the index of the start of a trailing incomplete character.
+func lastCompleteIndex(data []byte) int {
+	// a UTF-8 encoded character is at most 4 bytes long
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(data[i]) {
+			continue
+		}
+		if !utf8.FullRune(data[i:]) {
+			return i
+		}
+		break
+	}
+	return len(data)
+}
+
+// newTextDecoderStream sets up a TextDecoderStream on the object, a transform
+// stream which decodes UTF-8 encoded chunks to strings, with its readable and
+// writable sides defined on the other objects.
+func newTextDecoderStream(
+	rt *goja.Runtime,
+	obj, readableObj, writableObj *goja.Object,
+	label goja.Value,
+	options goja.Value,
+) error {
+	if !common.IsNullish(label) {
+		switch strings.ToLower(strings.TrimSpace(label.String())) {
+		case "utf-8", "utf8", "unicode-1-1-utf-8":
+		default:
+			panic(newRangeError(rt, "unsupported encoding "+label.String()+", only utf-8 is supported"))
+		}
+	}
+
+	d := &textDecoder{}
+	if !common.IsNullish(options) {
+		opts := options.ToObject(rt)
+		d.fatal = getBool(opts, "fatal")
+		d.ignoreBOM = getBool(opts, "ignoreBOM")
+	}
+
+	decodeAndEnqueue := func(chunk []byte, flush bool, c *transformStreamController) goja.Value {
+		text, err := d.decode(chunk, flush)
+		if err != nil {
+			return rt.ToValue(newRejectedPromise(rt, rt.NewTypeError(err.Error())))
+		}
+		if text == "" {
+			return rt.ToValue(newResolvedPromise(rt, goja.Undefined()))
+		}
+		return c.enqueueAsPromise(rt.ToValue(text))
+	}
+
+	_, err := newTransformStream(rt, readableObj, writableObj, transformer{
+		start: func(*transformStreamController) (goja.Value, error) { return goja.Undefined(), nil },
+		transform: func(chunk goja.Value, c *transformStreamController) goja.Value {
+			b, err := exportBytes(rt, chunk)
+			if err != nil {
+				return rt.ToValue(newRejectedPromise(rt, rt.NewTypeError(err.Error())))
+			}
+			return decodeAndEnqueue(b, false, c)
+		},
+		flush: func(c *transformStreamController) goja.Value {
+			return decodeAndEnqueue(nil, true, c)
+		},
+	}, 1, extractSizeAlgorithm(rt, nil), 0, extractSizeAlgorithm(rt, nil))
+	if err != nil {
+		return err
+	}
+
+	must(rt, obj.DefineDataProperty("encoding", rt.ToValue("utf-8"), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+	must(rt, obj.DefineDataProperty("fatal", rt.ToValue(d.fatal), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+	must(rt, obj.DefineDataProperty("ignoreBOM", rt.ToValue(d.ignoreBOM), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+	defineReadableWritable(rt, obj, readableObj, writableObj)
+
+	return nil
+}
@@ -0,0 +1,294 @@
+package streams
+
+import (
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+)
+
+// transformer holds the algorithms of a transform stream, either implemented
+// by the script or in Go, e.g. by a TextDecoderStream.
+type transformer struct {
+	start     func(c *transformStreamController) (goja.Value, error)
+	transform func(chunk goja.Value, c *transformStreamController) goja.Value
+	flush     func(c *transformStreamController) goja.Value
+}
+
+// transformStream is the implementation of the WHATWG TransformStream.
+//
+// [specification]: https://streams.spec.whatwg.org/#ts-class
+type transformStream struct {
+	rt *goja.Runtime
+
+	readable *readableStream
+	writable *writableStream
+
+	backpressure       bool
+	backpressureChange *pendingPromise
+
+	controller *transformStreamController
+}
+
+// transformStreamController is the implementation of the WHATWG
+// TransformStreamDefaultController.
+//
+// [specification]: https://streams.spec.whatwg.org/#ts-default-controller-class
+type transformStreamController struct {
+	stream *transformStream
+	obj    *goja.Object
+
+	transformAlgorithm func(chunk goja.Value, c *transformStreamController) goja.Value
+	flushAlgorithm     func(c *transformStreamController) goja.Value
+}
+
+// newTransformStream sets up a transform stream, with its readable and
+// writable sides defined on the objects. It returns an error if the start
+// of the transformer throws.
+func newTransformStream(
+	rt *goja.Runtime,
+	readableObj, writableObj *goja.Object,
+	t transformer,
+	writableHWM float64, writableSize sizeAlgorithm,
+	readableHWM float64, readableSize sizeAlgorithm,
+) (*transformStream, error) {
+	ts := &transformStream{rt: rt}
+	startPromise := newPendingPromise(rt)
+	startAlgorithm := rt.ToValue(startPromise.promise)
+
+	var err error
+	ts.writable, err = newWritableStream(rt, writableObj, underlyingSink{
+		start: func(*writableStreamController) (goja.Value, error) { return startAlgorithm, nil },
+		write: func(chunk goja.Value, _ *writableStreamController) goja.Value { return ts.sinkWrite(chunk) },
+		close: ts.sinkClose,
+		abort: ts.sinkAbort,
+	}, writableHWM, writableSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ts.readable, err = newReadableStream(rt, readableObj, underlyingSource{
+		start: func(*readableStreamController) (goja.Value, error) { return startAlgorithm, nil },
+		pull:  func(*readableStreamController) goja.Value { return ts.sourcePull() },
+		cancel: func(reason goja.Value) goja.Value {
+			ts.errorWritableAndUnblockWrite(reason)
+			return goja.Undefined()
+		},
+	}, readableHWM, readableSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ts.setBackpressure(true)
+
+	ts.controller = &transformStreamController{
+		stream:             ts,
+		obj:                rt.NewObject(),
+		transformAlgorithm: t.transform,
+		flushAlgorithm:     t.flush,
+	}
+	ts.controller.define()
+
+	startResult, err := t.start(ts.controller)
+	if err != nil {
+		return nil, err
+	}
+	startPromise.resolve(startResult)
+
+	return ts, nil
+}
+
+// newJSTransformer returns the algorithms of a transformer implemented by the
+// script, as an object with start, transform and flush methods. Without a
+// transform method, the chunks are enqueued as they are.
+func newJSTransformer(rt *goja.Runtime, value goja.Value) transformer {
+	if common.IsNullish(value) {
+		value = rt.NewObject()
+	}
+	transformerObj := value.ToObject(rt)
+
+	if t := transformerObj.Get("readableType"); !common.IsNullish(t) {
+		panic(newRangeError(rt, "unsupported readableType "+t.String()))
+	}
+	if t := transformerObj.Get("writableType"); !common.IsNullish(t) {
+		panic(newRangeError(rt, "unsupported writableType "+t.String()))
+	}
+
+	start := getMethod(rt, transformerObj, "start")
+	transform := getMethod(rt, transformerObj, "transform")
+	flush := getMethod(rt, transformerObj, "flush")
+
+	return transformer{
+		start: func(c *transformStreamController) (goja.Value, error) {
+			if start == nil {
+				return goja.Undefined(), nil
+			}
+			return start(transformerObj, c.obj)
+		},
+		transform: func(chunk goja.Value, c *transformStreamController) goja.Value {
+			if transform == nil {
+				return c.enqueueAsPromise(chunk)
+			}
+			return promiseCall(rt, transform, transformerObj, chunk, c.obj)
+		},
+		flush: func(c *transformStreamController) goja.Value {
+			return promiseCall(rt, flush, transformerObj, c.obj)
+		},
+	}
+}
+
+func (ts *transformStream) setBackpressure(backpressure bool) {
+	if ts.backpressureChange != nil {
+		ts.backpressureChange.resolve(goja.Undefined())
+	}
+	ts.backpressureChange = newPendingPromise(ts.rt)
+	ts.backpressure = backpressure
+}
+
+// error errors both sides of the stream.
+func (ts *transformStream) error(e goja.Value) {
+	ts.readable.controller.error(e)
+	ts.errorWritableAndUnblockWrite(e)
+}
+
+func (ts *transformStream) errorWritableAndUnblockWrite(e goja.Value) {
+	ts.controller.clearAlgorithms()
+	ts.writable.error(e)
+	if ts.backpressure {
+		ts.setBackpressure(false)
+	}
+}
+
+func (ts *transformStream) sinkWrite(chunk goja.Value) goja.Value {
+	if !ts.backpressure {
+		return ts.performTransform(chunk)
+	}
+
+	promise, resolve, reject := ts.rt.NewPromise()
+	promiseThen(ts.rt, ts.rt.ToValue(ts.backpressureChange.promise), func(goja.Value) {
+		if ts.writable.state == stateErrored {
+			reject(ts.writable.storedError)
+			return
+		}
+		promiseThen(ts.rt, ts.performTransform(chunk), func(goja.Value) {
+			resolve(goja.Undefined())
+		}, func(r goja.Value) {
+			reject(r)
+		})
+	}, func(r goja.Value) {
+		reject(r)
+	})
+	return ts.rt.ToValue(promise)
+}
+
+func (ts *transformStream) performTransform(chunk goja.Value) goja.Value {
+	c := ts.controller
+	if c.transformAlgorithm == nil {
+		return ts.rt.ToValue(newRejectedPromise(ts.rt, ts.rt.NewTypeError("the transform stream is errored")))
+	}
+
+	promise, resolve, reject := ts.rt.NewPromise()
+	promiseThen(ts.rt, c.transformAlgorithm(chunk, c), func(goja.Value) {
+		resolve(goja.Undefined())
+	}, func(r goja.Value) {
+		ts.error(r)
+		reject(r)
+	})
+	return ts.rt.ToValue(promise)
+}
+
+func (ts *transformStream) sinkClose() goja.Value {
+	c := ts.controller
+	flushAlgorithm := c.flushAlgorithm
+	if flushAlgorithm == nil {
+		return ts.rt.ToValue(newRejectedPromise(ts.rt, ts.rt.NewTypeError("the transform stream is errored")))
+	}
+	flushResult := flushAlgorithm(c)
+	c.clearAlgorithms()
+
+	promise, resolve, reject := ts.rt.NewPromise()
+	promiseThen(ts.rt, flushResult, func(goja.Value) {
+		readable := ts.readable
+		if readable.state == stateErrored {
+			reject(readable.storedError)
+			return
+		}
+		if readable.controller.canCloseOrEnqueue() {
+			readable.controller.close()
+		}
+		resolve(goja.Undefined())
+	}, func(r goja.Value) {
+		ts.error(r)
+		reject(r)
+	})
+	return ts.rt.ToValue(promise)
+}
+
+func (ts *transformStream) sinkAbort(reason goja.Value) goja.Value {
+	ts.readable.controller.error(reason)
+	ts.errorWritableAndUnblockWrite(reason)
+	return goja.Undefined()
+}
+
+func (ts *transformStream) sourcePull() goja.Value {
+	ts.setBackpressure(false)
+	return ts.rt.ToValue(ts.backpressureChange.promise)
+}
+
+// define defines the properties and the methods of the controller object.
+func (c *transformStreamController) define() {
+	rt := c.stream.rt
+	defineGetter(rt, c.obj, "desiredSize", func() goja.Value {
+		return c.stream.readable.controller.jsDesiredSize()
+	})
+	defineMethod(rt, c.obj, "enqueue", c.jsEnqueue)
+	defineMethod(rt, c.obj, "error", c.stream.error)
+	defineMethod(rt, c.obj, "terminate", c.terminate)
+}
+
+func (c *transformStreamController) clearAlgorithms() {
+	c.transformAlgorithm = nil
+	c.flushAlgorithm = nil
+}
+
+func (c *transformStreamController) jsEnqueue(chunk goja.Value) {
+	if err := c.enqueue(chunk); err != nil {
+		panic(err)
+	}
+}
+
+// enqueue enqueues the chunk to the readable side of the stream,
+// or returns the error to throw if it can't be enqueued.
+func (c *transformStreamController) enqueue(chunk goja.Value) goja.Value {
+	ts := c.stream
+	rc := ts.readable.controller
+	if !rc.canCloseOrEnqueue() {
+		return ts.rt.NewTypeError("the readable side of the transform stream is closed or errored")
+	}
+
+	if err := rc.enqueue(chunk); err != nil {
+		ts.errorWritableAndUnblockWrite(err)
+		return ts.readable.storedError
+	}
+
+	if backpressure := !rc.shouldCallPull(); backpressure && !ts.backpressure {
+		ts.setBackpressure(true)
+	}
+	return nil
+}
+
+// enqueueAsPromise enqueues the chunk, and returns a promise
+// rejected with the error if it couldn't be enqueued.
+func (c *transformStreamController) enqueueAsPromise(chunk goja.Value) goja.Value {
+	rt := c.stream.rt
+	if err := c.enqueue(chunk); err != nil {
+		return rt.ToValue(newRejectedPromise(rt, err))
+	}
+	return rt.ToValue(newResolvedPromise(rt, goja.Undefined()))
+}
+
+func (c *transformStreamController) terminate() {
+	ts := c.stream
+	if ts.readable.controller.canCloseOrEnqueue() {
+		ts.readable.controller.close()
+	}
+	ts.errorWritableAndUnblockWrite(ts.rt.NewTypeError("the transform stream has been terminated"))
+}
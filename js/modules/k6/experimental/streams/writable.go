@@ -0,0 +1,459 @@
+package streams
+
+import (
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+)
+
+// underlyingSink holds the algorithms of the sink of a writable stream,
+// either implemented by the script or in Go, e.g. by a TransformStream.
+type underlyingSink struct {
+	start func(c *writableStreamController) (goja.Value, error)
+	write func(chunk goja.Value, c *writableStreamController) goja.Value
+	close func() goja.Value
+	abort func(reason goja.Value) goja.Value
+}
+
+// writableStream is the implementation of the WHATWG WritableStream.
+//
+// It is simplified compared to the specification: a stream that errors, for
+// example when it's aborted, is errored right away instead of waiting for the
+// write in flight to finish, and the abort algorithm of the sink is called
+// right away too.
+//
+// [specification]: https://streams.spec.whatwg.org/#ws-class
+type writableStream struct {
+	rt  *goja.Runtime
+	obj *goja.Object
+
+	state        streamState
+	storedError  goja.Value
+	backpressure bool
+
+	controller *writableStreamController
+	writer     *writableStreamWriter
+}
+
+// newWritableStream sets up a writable stream on the object, with the
+// underlying sink and the high water mark and the size algorithm of its
+// queuing strategy. It returns an error if the start of the sink throws.
+func newWritableStream(
+	rt *goja.Runtime,
+	obj *goja.Object,
+	sink underlyingSink,
+	highWaterMark float64,
+	size sizeAlgorithm,
+) (*writableStream, error) {
+	s := &writableStream{rt: rt, obj: obj, state: stateWritable}
+	s.controller = &writableStreamController{
+		stream:         s,
+		obj:            rt.NewObject(),
+		highWaterMark:  highWaterMark,
+		size:           size,
+		writeAlgorithm: sink.write,
+		closeAlgorithm: sink.close,
+		abortAlgorithm: sink.abort,
+	}
+	s.controller.define()
+	s.define()
+	s.controller.updateBackpressure()
+
+	startResult, err := sink.start(s.controller)
+	if err != nil {
+		return nil, err
+	}
+
+	c := s.controller
+	promiseThen(rt, startResult, func(goja.Value) {
+		c.started = true
+		c.advanceQueueIfNeeded()
+	}, func(r goja.Value) {
+		c.started = true
+		s.error(r)
+	})
+
+	return s, nil
+}
+
+// newJSUnderlyingSink returns the algorithms of an underlying sink
+// implemented by the script, as an object with start, write, close and
+// abort methods.
+func newJSUnderlyingSink(rt *goja.Runtime, sink goja.Value) underlyingSink {
+	if common.IsNullish(sink) {
+		sink = rt.NewObject()
+	}
+	sinkObj := sink.ToObject(rt)
+
+	if t := sinkObj.Get("type"); !common.IsNullish(t) {
+		panic(newRangeError(rt, "unsupported type of writable stream "+t.String()))
+	}
+
+	start := getMethod(rt, sinkObj, "start")
+	write := getMethod(rt, sinkObj, "write")
+	closeFn := getMethod(rt, sinkObj, "close")
+	abort := getMethod(rt, sinkObj, "abort")
+
+	return underlyingSink{
+		start: func(c *writableStreamController) (goja.Value, error) {
+			if start == nil {
+				return goja.Undefined(), nil
+			}
+			return start(sinkObj, c.obj)
+		},
+		write: func(chunk goja.Value, c *writableStreamController) goja.Value {
+			return promiseCall(rt, write, sinkObj, chunk, c.obj)
+		},
+		close: func() goja.Value {
+			return promiseCall(rt, closeFn, sinkObj)
+		},
+		abort: func(reason goja.Value) goja.Value {
+			return promiseCall(rt, abort, sinkObj, reason)
+		},
+	}
+}
+
+// define defines the properties and the methods of the WritableStream object.
+func (s *writableStream) define() {
+	rt := s.rt
+	defineGetter(rt, s.obj, "locked", func() bool { return s.locked() })
+	defineMethod(rt, s.obj, "abort", s.jsAbort)
+	defineMethod(rt, s.obj, "close", s.jsClose)
+	defineMethod(rt, s.obj, "getWriter", s.getWriter)
+}
+
+func (s *writableStream) locked() bool {
+	return s.writer != nil
+}
+
+func (s *writableStream) jsAbort(reason goja.Value) *goja.Promise {
+	if s.locked() {
+		return newRejectedPromise(s.rt, s.rt.NewTypeError("cannot abort a locked stream"))
+	}
+	return s.abort(reason)
+}
+
+func (s *writableStream) jsClose() *goja.Promise {
+	if s.locked() {
+		return newRejectedPromise(s.rt, s.rt.NewTypeError("cannot close a locked stream"))
+	}
+	return s.close()
+}
+
+func (s *writableStream) getWriter() *goja.Object {
+	writer, err := s.acquireWriter()
+	if err != nil {
+		panic(err)
+	}
+	return writer.obj
+}
+
+// abort implements the WritableStreamAbort abstract operation.
+func (s *writableStream) abort(reason goja.Value) *goja.Promise {
+	if s.state != stateWritable {
+		return newResolvedPromise(s.rt, goja.Undefined())
+	}
+
+	abortAlgorithm := s.controller.abortAlgorithm
+	s.error(reason)
+	if abortAlgorithm == nil {
+		return newResolvedPromise(s.rt, goja.Undefined())
+	}
+
+	promise, resolve, reject := s.rt.NewPromise()
+	promiseThen(s.rt, abortAlgorithm(reason), func(goja.Value) {
+		resolve(goja.Undefined())
+	}, func(r goja.Value) {
+		reject(r)
+	})
+	return promise
+}
+
+// close implements the WritableStreamClose abstract operation.
+func (s *writableStream) close() *goja.Promise {
+	if s.state != stateWritable || s.controller.closeRequest != nil {
+		return newRejectedPromise(s.rt, s.rt.NewTypeError("the stream is closed or closing"))
+	}
+
+	c := s.controller
+	c.closeRequest = newPendingPromise(s.rt)
+	if s.writer != nil && s.backpressure {
+		s.writer.ready.resolve(goja.Undefined())
+	}
+	c.advanceQueueIfNeeded()
+
+	return c.closeRequest.promise
+}
+
+// error errors the stream, the queued writes and close are rejected.
+func (s *writableStream) error(e goja.Value) {
+	if s.state != stateWritable {
+		return
+	}
+
+	s.state = stateErrored
+	s.storedError = e
+
+	c := s.controller
+	c.queue.reset()
+	writeRequests := c.writeRequests
+	c.writeRequests = nil
+	for _, writeRequest := range writeRequests {
+		writeRequest.reject(e)
+	}
+	if c.closeRequest != nil && !c.inFlight {
+		c.closeRequest.reject(e)
+	}
+	c.clearAlgorithms()
+
+	if s.writer != nil {
+		s.writer.reject(e)
+	}
+}
+
+// writableStreamController is the implementation of the WHATWG
+// WritableStreamDefaultController.
+//
+// [specification]: https://streams.spec.whatwg.org/#ws-default-controller-class
+type writableStreamController struct {
+	stream *writableStream
+	obj    *goja.Object
+
+	queue         queue
+	writeRequests []*pendingPromise
+	closeRequest  *pendingPromise
+	started       bool
+	inFlight      bool
+
+	highWaterMark  float64
+	size           sizeAlgorithm
+	writeAlgorithm func(chunk goja.Value, c *writableStreamController) goja.Value
+	closeAlgorithm func() goja.Value
+	abortAlgorithm func(reason goja.Value) goja.Value
+}
+
+// define defines the methods of the controller object.
+func (c *writableStreamController) define() {
+	defineMethod(c.stream.rt, c.obj, "error", c.stream.error)
+}
+
+func (c *writableStreamController) desiredSize() float64 {
+	return c.highWaterMark - c.queue.totalSize
+}
+
+func (c *writableStreamController) clearAlgorithms() {
+	c.writeAlgorithm = nil
+	c.closeAlgorithm = nil
+	c.abortAlgorithm = nil
+	c.size = nil
+}
+
+// write queues the chunk, the returned promise is settled once the chunk
+// has been written by the sink.
+func (c *writableStreamController) write(chunk goja.Value) *goja.Promise {
+	s := c.stream
+	if s.state == stateErrored {
+		return newRejectedPromise(s.rt, s.storedError)
+	}
+	if s.state != stateWritable || c.closeRequest != nil {
+		return newRejectedPromise(s.rt, s.rt.NewTypeError("the stream is closed or closing"))
+	}
+
+	size, err := c.size(chunk)
+	if err != nil {
+		s.error(err)
+		return newRejectedPromise(s.rt, err)
+	}
+
+	writeRequest := newPendingPromise(s.rt)
+	c.queue.enqueue(chunk, size)
+	c.writeRequests = append(c.writeRequests, writeRequest)
+	c.updateBackpressure()
+	c.advanceQueueIfNeeded()
+
+	return writeRequest.promise
+}
+
+// updateBackpressure updates the backpressure of the stream, and the
+// ready promise of its writer accordingly.
+func (c *writableStreamController) updateBackpressure() {
+	s := c.stream
+	backpressure := c.desiredSize() <= 0
+	if backpressure != s.backpressure && s.writer != nil && s.state == stateWritable {
+		if backpressure {
+			s.writer.ready = newPendingPromise(s.rt)
+		} else {
+			s.writer.ready.resolve(goja.Undefined())
+		}
+	}
+	s.backpressure = backpressure
+}
+
+// advanceQueueIfNeeded writes the next queued chunk, or closes the
+// sink once all the chunks have been written.
+func (c *writableStreamController) advanceQueueIfNeeded() {
+	s := c.stream
+	if !c.started || c.inFlight || s.state != stateWritable {
+		return
+	}
+
+	if c.queue.len() == 0 {
+		if c.closeRequest != nil {
+			c.processClose()
+		}
+		return
+	}
+
+	writeRequest := c.writeRequests[0]
+	c.writeRequests = c.writeRequests[1:]
+	c.inFlight = true
+	promiseThen(s.rt, c.writeAlgorithm(c.queue.peek(), c), func(goja.Value) {
+		c.inFlight = false
+		writeRequest.resolve(goja.Undefined())
+		if s.state != stateWritable {
+			return
+		}
+		c.queue.dequeue()
+		c.updateBackpressure()
+		c.advanceQueueIfNeeded()
+	}, func(r goja.Value) {
+		c.inFlight = false
+		writeRequest.reject(r)
+		s.error(r)
+	})
+}
+
+func (c *writableStreamController) processClose() {
+	s := c.stream
+	c.inFlight = true
+	closeAlgorithm := c.closeAlgorithm
+	c.clearAlgorithms()
+
+	promiseThen(s.rt, closeAlgorithm(), func(goja.Value) {
+		c.inFlight = false
+		if s.state != stateWritable {
+			c.closeRequest.reject(s.storedError)
+			return
+		}
+		s.state = stateClosed
+		c.closeRequest.resolve(goja.Undefined())
+		if s.writer != nil {
+			s.writer.closed.resolve(goja.Undefined())
+		}
+	}, func(r goja.Value) {
+		c.inFlight = false
+		c.closeRequest.reject(r)
+		s.error(r)
+	})
+}
+
+// writableStreamWriter is the implementation of the WHATWG
+// WritableStreamDefaultWriter.
+//
+// [specification]: https://streams.spec.whatwg.org/#default-writer-class
+type writableStreamWriter struct {
+	rt     *goja.Runtime
+	obj    *goja.Object
+	stream *writableStream
+
+	ready  *pendingPromise
+	closed *pendingPromise
+}
+
+// acquireWriter locks the stream to a new writer,
+// or returns the error to throw if it's already locked.
+func (s *writableStream) acquireWriter() (*writableStreamWriter, goja.Value) {
+	if s.locked() {
+		return nil, s.rt.NewTypeError("the stream is already locked to a writer")
+	}
+
+	w := &writableStreamWriter{
+		rt:     s.rt,
+		obj:    s.rt.NewObject(),
+		stream: s,
+		ready:  newPendingPromise(s.rt),
+		closed: newPendingPromise(s.rt),
+	}
+	s.writer = w
+
+	switch s.state {
+	case stateWritable:
+		if !s.backpressure || s.controller.closeRequest != nil {
+			w.ready.resolve(goja.Undefined())
+		}
+	case stateClosed:
+		w.ready.resolve(goja.Undefined())
+		w.closed.resolve(goja.Undefined())
+	default:
+		w.reject(s.storedError)
+	}
+
+	defineGetter(s.rt, w.obj, "closed", func() *goja.Promise { return w.closed.promise })
+	defineGetter(s.rt, w.obj, "ready", func() *goja.Promise { return w.ready.promise })
+	defineGetter(s.rt, w.obj, "desiredSize", w.desiredSize)
+	defineMethod(s.rt, w.obj, "abort", w.abort)
+	defineMethod(s.rt, w.obj, "close", w.close)
+	defineMethod(s.rt, w.obj, "write", w.write)
+	defineMethod(s.rt, w.obj, "releaseLock", w.releaseLock)
+
+	return w, nil
+}
+
+func (w *writableStreamWriter) desiredSize() goja.Value {
+	if w.stream == nil {
+		panic(w.rt.NewTypeError("the writer has been released"))
+	}
+
+	switch w.stream.state {
+	case stateErrored:
+		return goja.Null()
+	case stateClosed:
+		return w.rt.ToValue(0)
+	default:
+		return w.rt.ToValue(w.stream.controller.desiredSize())
+	}
+}
+
+func (w *writableStreamWriter) abort(reason goja.Value) *goja.Promise {
+	if w.stream == nil {
+		return newRejectedPromise(w.rt, w.rt.NewTypeError("the writer has been released"))
+	}
+	return w.stream.abort(reason)
+}
+
+func (w *writableStreamWriter) close() *goja.Promise {
+	if w.stream == nil {
+		return newRejectedPromise(w.rt, w.rt.NewTypeError("the writer has been released"))
+	}
+	return w.stream.close()
+}
+
+func (w *writableStreamWriter) write(chunk goja.Value) *goja.Promise {
+	if w.stream == nil {
+		return newRejectedPromise(w.rt, w.rt.NewTypeError("the writer has been released"))
+	}
+	return w.stream.controller.write(chunk)
+}
+
+// releaseLock releases the lock of the writer on the stream,
+// the queued writes are still written.
+func (w *writableStreamWriter) releaseLock() {
+	if w.stream == nil {
+		return
+	}
+
+	w.reject(w.rt.NewTypeError("the writer has been released"))
+	w.stream.writer = nil
+	w.stream = nil
+}
+
+// reject rejects the ready and closed promises of the writer,
+// they are replaced by rejected ones if they're already settled.
+func (w *writableStreamWriter) reject(e goja.Value) {
+	for _, p := range []**pendingPromise{&w.ready, &w.closed} {
+		if (*p).promise.State() != goja.PromiseStatePending {
+			*p = newPendingPromise(w.rt)
+		}
+		(*p).reject(e)
+		markAsHandled(w.rt, (*p).promise)
+	}
+}
@@ -0,0 +1,126 @@
+package testing
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+)
+
+// Expectation makes assertions about a value, and throws an error when they
+// fail, which fails the test.
+type Expectation struct {
+	// Not is the expectation with the negated assertions.
+	Not *Expectation `js:"not"`
+
+	rt      *goja.Runtime
+	actual  goja.Value
+	negated bool
+}
+
+// expect returns the expectation of the value.
+func (mi *ModuleInstance) expect(actual goja.Value) *Expectation {
+	rt := mi.vu.Runtime()
+	if actual == nil {
+		actual = goja.Undefined()
+	}
+	e := &Expectation{rt: rt, actual: actual}
+	e.Not = &Expectation{rt: rt, actual: actual, negated: true}
+	return e
+}
+
+// ToBe asserts that the value is strictly equal to the expected one.
+func (e *Expectation) ToBe(expected goja.Value) {
+	e.assert(e.actual.StrictEquals(orUndefined(expected)), "to be %s", inspect(expected))
+}
+
+// ToEqual asserts that the value is deeply equal to the expected one, e.g.
+// an object with the same properties.
+func (e *Expectation) ToEqual(expected goja.Value) {
+	equal := reflect.DeepEqual(e.actual.Export(), orUndefined(expected).Export())
+	e.assert(equal, "to equal %s", inspect(expected))
+}
+
+// ToBeTruthy asserts that the value is truthy.
+func (e *Expectation) ToBeTruthy() {
+	e.assert(e.actual.ToBoolean(), "to be truthy")
+}
+
+// ToContain asserts that the string contains the substring, or that the
+// array contains the item.
+func (e *Expectation) ToContain(item goja.Value) {
+	item = orUndefined(item)
+	var contains bool
+	if s, ok := e.actual.Export().(string); ok {
+		contains = strings.Contains(s, item.String())
+	} else if obj, ok := e.actual.(*goja.Object); ok && obj.ClassName() == "Array" {
+		length := obj.Get("length").ToInteger()
+		for i := int64(0); i < length && !contains; i++ {
+			contains = obj.Get(fmt.Sprint(i)).StrictEquals(item)
+		}
+	} else {
+		common.Throw(e.rt, fmt.Errorf("expected %s to be a string or an array", inspect(e.actual)))
+	}
+	e.assert(contains, "to contain %s", inspect(item))
+}
+
+// ToThrow asserts that the function throws when it's called, with an error
+// whose message contains the optional substring.
+func (e *Expectation) ToThrow(substr goja.Value) {
+	fn, ok := goja.AssertFunction(e.actual)
+	if !ok {
+		common.Throw(e.rt, fmt.Errorf("expected %s to be a function", inspect(e.actual)))
+	}
+	_, err := fn(goja.Undefined())
+	if common.IsNullish(substr) {
+		e.assert(err != nil, "to throw")
+		return
+	}
+	e.assert(err != nil && strings.Contains(errorMessage(err), substr.String()), "to throw %s", inspect(substr))
+}
+
+// assert throws an error with the description of the expectation, if the
+// assertion, or its negation, failed.
+func (e *Expectation) assert(ok bool, format string, args ...interface{}) {
+	if ok != e.negated {
+		return
+	}
+	not := ""
+	if e.negated {
+		not = "not "
+	}
+	msg := fmt.Sprintf("expected %s %s%s", inspect(e.actual), not, fmt.Sprintf(format, args...))
+	assertionErr, err := e.rt.New(e.rt.Get("Error"), e.rt.ToValue(msg))
+	if err != nil {
+		common.Throw(e.rt, err)
+	}
+	if err = assertionErr.Set("name", "AssertionError"); err != nil {
+		common.Throw(e.rt, err)
+	}
+	panic(e.rt.ToValue(assertionErr))
+}
+
+func orUndefined(v goja.Value) goja.Value {
+	if v == nil {
+		return goja.Undefined()
+	}
+	return v
+}
+
+// inspect formats the value for the messages of the failed assertions.
+func inspect(v goja.Value) string {
+	if v == nil || goja.IsUndefined(v) {
+		return "undefined"
+	}
+	if _, ok := goja.AssertFunction(v); ok {
+		return "function"
+	}
+	if b, err := json.Marshal(v.Export()); err == nil {
+		return string(b)
+	}
+	return v.String()
+}
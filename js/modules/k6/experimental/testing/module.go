@@ -0,0 +1,155 @@
+// Package testing implements a k6 JS module for unit testing the helper
+// libraries of the scripts, with tests reported as checks, assertions, and
+// stubs of the functions of the built-in modules, e.g. of k6/http, so that the
+// tests don't generate load.
+package testing
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the JS module.
+	ModuleInstance struct {
+		vu    modules.VU
+		stubs []*Stub
+	}
+)
+
+// Ensure the interfaces are implemented correctly
+var (
+	_ modules.Instance = &ModuleInstance{}
+	_ modules.Module   = &RootModule{}
+)
+
+var errTestInInitContext = errors.New("running tests in the init context is not supported")
+
+// New returns a pointer to a new RootModule instance
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns
+// a new instance for each VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu}
+}
+
+// Exports implements the modules.Instance interface and returns
+// the exports of the JS module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"test":       mi.test,
+			"expect":     mi.expect,
+			"stub":       mi.stub,
+			"restoreAll": mi.restoreAll,
+		},
+	}
+}
+
+// test runs the test function, and reports its result as a check with the
+// name of the test. The test fails if the function throws, or if the promise
+// it returns is rejected, and then the test run is marked as failed.
+//
+// It returns whether the test passed, or a promise of it for the async tests.
+func (mi *ModuleInstance) test(name string, fn goja.Callable) (goja.Value, error) {
+	state := mi.vu.State()
+	if state == nil {
+		return nil, errTestInInitContext
+	}
+	rt := mi.vu.Runtime()
+
+	v, err := fn(goja.Undefined())
+	if err != nil {
+		return rt.ToValue(mi.report(name, err)), nil
+	}
+	promise, ok := v.Export().(*goja.Promise)
+	if !ok {
+		return rt.ToValue(mi.report(name, nil)), nil
+	}
+
+	// the async tests are reported once their promises are settled
+	then, ok := goja.AssertFunction(rt.ToValue(promise).ToObject(rt).Get("then"))
+	if !ok {
+		return nil, errors.New("the promise returned by the test has no then method")
+	}
+	return then(rt.ToValue(promise),
+		rt.ToValue(func() bool {
+			return mi.report(name, nil)
+		}),
+		rt.ToValue(func(reason goja.Value) bool {
+			return mi.report(name, errors.New(reason.String()))
+		}),
+	)
+}
+
+// report logs the result of the test and emits its check. If the test failed
+// with an interrupt of the runtime, e.g. because the iteration was aborted,
+// the interrupt is propagated instead.
+func (mi *ModuleInstance) report(name string, err error) bool {
+	var interruptErr *goja.InterruptedError
+	if errors.As(err, &interruptErr) {
+		panic(interruptErr)
+	}
+
+	state := mi.vu.State()
+	logger := state.Logger.WithField("source", "testing")
+	passed := err == nil
+	if passed {
+		logger.Infof("✓ %s", name)
+	} else {
+		logger.Errorf("✗ %s: %s", name, errorMessage(err))
+		if es := lib.GetExecutionState(mi.vu.Context()); es != nil {
+			es.MarkFailed(fmt.Sprintf("the test %q failed", name))
+		}
+	}
+
+	check, cerr := state.Group.Check(name)
+	if cerr != nil {
+		common.Throw(mi.vu.Runtime(), cerr)
+	}
+	tagsAndMeta := state.Tags.GetCurrentValues()
+	tags := tagsAndMeta.Tags
+	if state.Options.SystemTags.Has(metrics.TagCheck) {
+		tags = tags.With("check", check.Name)
+	}
+	sample := metrics.Sample{
+		TimeSeries: metrics.TimeSeries{Metric: state.BuiltinMetrics.Checks, Tags: tags},
+		Time:       time.Now(),
+		Metadata:   tagsAndMeta.Metadata,
+	}
+	if passed {
+		atomic.AddInt64(&check.Passes, 1)
+		sample.Value = 1
+	} else {
+		atomic.AddInt64(&check.Fails, 1)
+	}
+	metrics.PushIfNotDone(mi.vu.Context(), state.Samples, sample)
+
+	return passed
+}
+
+// errorMessage returns the message of the error thrown by a test, without
+// its stack trace.
+func errorMessage(err error) string {
+	var exception *goja.Exception
+	if errors.As(err, &exception) {
+		return exception.Value().String()
+	}
+	return err.Error()
+}
@@ -0,0 +1,161 @@
+package testing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/metrics"
+)
+
+type testCase struct {
+	runtime *modulestest.Runtime
+	samples chan metrics.SampleContainer
+	es      *lib.ExecutionState
+	hook    *testutils.SimpleLogrusHook
+}
+
+func newTestCase(t *testing.T) *testCase {
+	t.Helper()
+	ts := modulestest.NewRuntime(t)
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := lib.NewExecutionState(nil, et, 0, 0)
+	ts.VU.CtxField = lib.WithExecutionState(context.Background(), es)
+
+	m, ok := New().NewModuleInstance(ts.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, ts.VU.Runtime().Set("testing", m.Exports().Named))
+
+	registry := metrics.NewRegistry()
+	root, err := lib.NewGroup("", nil)
+	require.NoError(t, err)
+	samples := make(chan metrics.SampleContainer, 1000)
+	logger, hook := testutils.NewLoggerWithHook(t)
+	ts.MoveToVUContext(&lib.State{
+		Group:          root,
+		Options:        lib.Options{SystemTags: &metrics.DefaultSystemTagSet},
+		Samples:        samples,
+		Tags:           lib.NewVUStateTags(registry.RootTagSet().WithTagsFromMap(map[string]string{"group": root.Path})),
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Logger:         logger,
+	})
+	return &testCase{runtime: ts, samples: samples, es: es, hook: hook}
+}
+
+func TestTest(t *testing.T) {
+	t.Parallel()
+
+	tc := newTestCase(t)
+	_, err := tc.runtime.RunOnEventLoop(`
+		var results = [];
+		results.push(testing.test("passes", () => {
+			testing.expect(1 + 1).toBe(2);
+		}));
+		results.push(testing.test("fails", () => {
+			testing.expect([1, 2]).toContain(3);
+		}));
+		testing.test("async", async () => {
+			await Promise.resolve();
+			testing.expect({ a: [1] }).not.toEqual({ a: [1] });
+		}).then((passed) => results.push(passed));
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{true, false, false}, tc.runtime.VU.Runtime().Get("results").Export())
+
+	checks := make(map[string]float64)
+	for _, sample := range metrics.GetBufferedSamples(tc.samples) {
+		for _, s := range sample.GetSamples() {
+			name, _ := s.Tags.Get("check")
+			checks[name] = s.Value
+		}
+	}
+	assert.Equal(t, map[string]float64{"passes": 1, "fails": 0, "async": 0}, checks)
+	assert.Equal(t, []string{`the test "fails" failed`, `the test "async" failed`}, tc.es.GetFailureReasons())
+
+	entries := tc.hook.Drain()
+	require.Len(t, entries, 3)
+	assert.Equal(t, "✓ passes", entries[0].Message)
+	assert.Equal(t, logrus.ErrorLevel, entries[1].Level)
+	assert.Equal(t, "✗ fails: AssertionError: expected [1,2] to contain 3", entries[1].Message)
+	assert.Equal(t, `✗ async: AssertionError: expected {"a":[1]} not to equal {"a":[1]}`, entries[2].Message)
+
+	ts := modulestest.NewRuntime(t)
+	m, ok := New().NewModuleInstance(ts.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, ts.VU.Runtime().Set("testing", m.Exports().Named))
+	_, err = ts.VU.Runtime().RunString(`testing.test("init", () => {})`)
+	require.ErrorContains(t, err, errTestInInitContext.Error())
+}
+
+func TestExpect(t *testing.T) {
+	t.Parallel()
+
+	tc := newTestCase(t)
+	_, err := tc.runtime.VU.Runtime().RunString(`
+		testing.expect("abc").toBe("abc");
+		testing.expect(1).not.toBe("1");
+		testing.expect({ a: 1, b: ["c"] }).toEqual({ b: ["c"], a: 1 });
+		testing.expect(1).toBeTruthy();
+		testing.expect("").not.toBeTruthy();
+		testing.expect("hello").toContain("ell");
+		testing.expect(() => { throw new Error("boom"); }).toThrow("boom");
+		testing.expect(() => {}).not.toThrow();
+	`)
+	require.NoError(t, err)
+
+	for script, msg := range map[string]string{
+		`testing.expect(1).toBe(2)`:                         "expected 1 to be 2",
+		`testing.expect({ a: 1 }).toEqual({ a: 2 })`:        `expected {"a":1} to equal {"a":2}`,
+		`testing.expect(0).toBeTruthy()`:                    "expected 0 to be truthy",
+		`testing.expect(["a"]).not.toContain("a")`:          `expected ["a"] not to contain "a"`,
+		`testing.expect(1).toContain(1)`:                    "expected 1 to be a string or an array",
+		`testing.expect(() => {}).toThrow()`:                "expected function to throw",
+		`testing.expect(() => { throw "x"; }).toThrow("y")`: `expected function to throw "y"`,
+	} {
+		_, err := tc.runtime.VU.Runtime().RunString(script)
+		require.ErrorContains(t, err, msg, script)
+	}
+}
+
+func TestStub(t *testing.T) {
+	t.Parallel()
+
+	tc := newTestCase(t)
+	_, err := tc.runtime.VU.Runtime().RunString(`
+		var http = { get: (url) => "real " + url };
+		function getUser(id) {
+			return http.get("/users/" + id);
+		}
+
+		var get = testing.stub(http, "get", (url) => "stubbed " + url);
+		var results = [getUser(1), getUser(2)];
+		var calls = [get.callCount, get.calls[1][0]];
+
+		testing.stub(http, "get");
+		results.push(getUser(3));
+		testing.restoreAll();
+		results.push(getUser(4));
+	`)
+	require.NoError(t, err)
+	rt := tc.runtime.VU.Runtime()
+	assert.Equal(t,
+		[]interface{}{"stubbed /users/1", "stubbed /users/2", nil, "real /users/4"},
+		rt.Get("results").Export())
+	assert.Equal(t, []interface{}{int64(2), "/users/2"}, rt.Get("calls").Export())
+
+	for script, msg := range map[string]string{
+		`testing.stub(undefined, "get")`:     "the stubbed function must be the function of an object",
+		`testing.stub({ a: 1 }, "a")`:        `"a" isn't a function of the object`,
+		`testing.stub(http, "get", "value")`: "the implementation of the stub must be a function",
+	} {
+		_, err := rt.RunString(script)
+		require.ErrorContains(t, err, msg, script)
+	}
+}
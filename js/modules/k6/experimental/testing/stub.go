@@ -0,0 +1,86 @@
+package testing
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+)
+
+// Stub replaces a function of an object, records its calls, and calls the
+// implementation of the stub, if any, instead of the original function.
+type Stub struct {
+	// Calls are the arguments of each call of the stub.
+	Calls [][]goja.Value `js:"calls"`
+	// CallCount is the number of calls of the stub.
+	CallCount int `js:"callCount"`
+
+	target   *goja.Object
+	name     string
+	original goja.Value
+	restored bool
+}
+
+// stub replaces the function of the object with a stub calling the optional
+// implementation. The objects are shared by the whole VU, so stubbing e.g. the
+// get function of the default export of k6/http stubs it for the helper
+// libraries importing k6/http too, until the stub is restored.
+func (mi *ModuleInstance) stub(target goja.Value, name string, impl goja.Value) *Stub {
+	rt := mi.vu.Runtime()
+	obj, ok := target.(*goja.Object)
+	if !ok {
+		common.Throw(rt, errors.New("the stubbed function must be the function of an object"))
+	}
+	original := obj.Get(name)
+	if _, ok = goja.AssertFunction(original); !ok {
+		common.Throw(rt, fmt.Errorf("%q isn't a function of the object", name))
+	}
+	var fn goja.Callable
+	if !common.IsNullish(impl) {
+		if fn, ok = goja.AssertFunction(impl); !ok {
+			common.Throw(rt, errors.New("the implementation of the stub must be a function"))
+		}
+	}
+
+	s := &Stub{target: obj, name: name, original: original}
+	stubFn := func(call goja.FunctionCall) goja.Value {
+		s.Calls = append(s.Calls, append([]goja.Value(nil), call.Arguments...))
+		s.CallCount++
+		if fn == nil {
+			return goja.Undefined()
+		}
+		v, err := fn(call.This, call.Arguments...)
+		if err != nil {
+			panic(err)
+		}
+		return v
+	}
+	if err := obj.Set(name, stubFn); err != nil {
+		common.Throw(rt, err)
+	}
+	mi.stubs = append(mi.stubs, s)
+	return s
+}
+
+// Restore restores the original function of the object.
+func (s *Stub) Restore() error {
+	if s.restored {
+		return nil
+	}
+	s.restored = true
+	return s.target.Set(s.name, s.original)
+}
+
+// restoreAll restores all the stubbed functions, in the reverse order, so that
+// the functions stubbed more than once are restored to the original ones.
+func (mi *ModuleInstance) restoreAll() error {
+	for i := len(mi.stubs) - 1; i >= 0; i-- {
+		if err := mi.stubs[i].Restore(); err != nil {
+			return err
+		}
+	}
+	mi.stubs = nil
+	return nil
+}
@@ -0,0 +1,114 @@
+package tracing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// BaggageHeaderName is the name of the W3C baggage header
+	BaggageHeaderName = "baggage"
+
+	// maxBaggageEntries is the maximum number of list-members a
+	// baggage header is allowed to hold, as defined by the W3C
+	// baggage specification.
+	maxBaggageEntries = 180
+
+	// maxBaggageSize is the maximum size, in bytes, of a baggage header's value.
+	maxBaggageSize = 8192
+)
+
+// newBaggage produces the value of a baggage header from the provided
+// key/value entries, as defined by the [W3C specification].
+//
+// As Go maps are unordered, entries are sorted by key to ensure the produced
+// header is stable across requests. Values are percent-encoded when needed.
+//
+// [W3C specification]: https://www.w3.org/TR/baggage/#baggage-http-header-format
+func newBaggage(entries map[string]string) (string, error) {
+	if len(entries) == 0 {
+		return "", nil
+	}
+
+	if len(entries) > maxBaggageEntries {
+		return "", fmt.Errorf("baggage can hold at most %d entries, got %d", maxBaggageEntries, len(entries))
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	members := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !isBaggageKey(key) {
+			return "", fmt.Errorf("invalid baggage key %q", key)
+		}
+
+		members = append(members, key+"="+encodeBaggageValue(entries[key]))
+	}
+
+	baggage := strings.Join(members, ",")
+	if len(baggage) > maxBaggageSize {
+		return "", fmt.Errorf("baggage can be at most %d bytes long, got %d", maxBaggageSize, len(baggage))
+	}
+
+	return baggage, nil
+}
+
+// mergeBaggage returns a new set of baggage entries holding the base entries,
+// overridden by the override ones.
+func mergeBaggage(base, override map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(override))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range override {
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// isBaggageKey returns true if the given key is a valid baggage key,
+// which are defined as RFC 7230 tokens.
+func isBaggageKey(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	for _, c := range key {
+		isAlphaNum := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+		if !isAlphaNum && !strings.ContainsRune("!#$%&'*+-.^_`|~", c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// encodeBaggageValue percent-encodes the characters of the given value
+// which are not allowed as part of a baggage value.
+func encodeBaggageValue(value string) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+
+		// The allowed baggage-octet characters, minus the
+		// percent sign, which is used for encoding.
+		if c > 0x20 && c < 0x7f && c != '"' && c != ',' && c != ';' && c != '\\' && c != '%' {
+			sb.WriteByte(c)
+			continue
+		}
+
+		sb.WriteByte('%')
+		sb.WriteByte(hexDigits[c>>4])
+		sb.WriteByte(hexDigits[c&0x0f])
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBaggage(t *testing.T) {
+	t.Parallel()
+
+	tooManyEntries := make(map[string]string, maxBaggageEntries+1)
+	for i := 0; i <= maxBaggageEntries; i++ {
+		tooManyEntries[fmt.Sprintf("key%d", i)] = "value"
+	}
+
+	testCases := []struct {
+		name    string
+		entries map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "no entries produce an empty value",
+			entries: nil,
+			want:    "",
+		},
+		{
+			name:    "entries are sorted by key",
+			entries: map[string]string{"team": "payments", "run": "42"},
+			want:    "run=42,team=payments",
+		},
+		{
+			name:    "values are percent-encoded when needed",
+			entries: map[string]string{"user": `a b,c;d\e"f%g`},
+			want:    "user=a%20b%2Cc%3Bd%5Ce%22f%25g",
+		},
+		{
+			name:    "empty values are valid",
+			entries: map[string]string{"key": ""},
+			want:    "key=",
+		},
+		{
+			name:    "keys with spaces are invalid",
+			entries: map[string]string{"invalid key": "value"},
+			wantErr: true,
+		},
+		{
+			name:    "empty keys are invalid",
+			entries: map[string]string{"": "value"},
+			wantErr: true,
+		},
+		{
+			name:    "too many entries are invalid",
+			entries: tooManyEntries,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, gotErr := newBaggage(tc.entries)
+
+			if tc.wantErr {
+				assert.Error(t, gotErr)
+				return
+			}
+
+			assert.NoError(t, gotErr)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package tracing
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/dop251/goja"
@@ -26,6 +29,15 @@ type Client struct {
 	// formats: w3c, b3, jaeger.
 	propagator Propagator
 
+	// sampler holds the client's sampler, shared by the
+	// propagators the client instantiates.
+	sampler Sampler
+
+	// baggage holds the value of the baggage header the client
+	// propagates alongside the trace context. It is empty when
+	// no baggage was configured.
+	baggage string
+
 	// requestFunc holds the http module's request function
 	// used to emit HTTP requests in k6 script. The client
 	// uses it under the hood to emit the requests it
@@ -41,6 +53,47 @@ type Client struct {
 	// randSource holds the client's random source, used
 	// to generate random values for the trace ID.
 	randSource *rand.Rand
+
+	// spansMx guards the spans, the ended spans and the exporter, since
+	// the spans left active are ended by the handler of the IterEnd event.
+	spansMx sync.Mutex
+
+	// spans holds the spans started by the script that have not
+	// ended yet, the innermost one being the last.
+	spans []*Span
+
+	// endedSpans holds the spans that have ended, but whose
+	// root span has not ended yet.
+	endedSpans []*Span
+
+	// subscribedToEvents holds whether the client handles the end of
+	// the VU's iterations, where it ends the spans left active.
+	subscribedToEvents bool
+
+	// traceIDGenerator holds the client's trace ID generator. It is nil
+	// when the default k6 trace ID generation strategy is used.
+	traceIDGenerator traceIDGenerator
+
+	// parent holds the externally-provided parent trace context the
+	// client continues. It is nil when new traces should be started.
+	parent *traceParent
+
+	// currentTraceID holds the trace ID attached to the
+	// last request instrumented by the client.
+	currentTraceID string
+
+	// exporter holds the client's span exporter. It is nil when
+	// no exporter was configured, in which case spans are not exported.
+	exporter *otlpExporter
+
+	// exportCtx is the context of the VU when the client is created in
+	// the init context, i.e. the one of the test run, the spans are
+	// exported with it.
+	exportCtx context.Context //nolint:containedctx
+
+	// exports holds the queue of the traces to export. It is created
+	// when the first trace is exported, and closed on the Exit event.
+	exports *exportQueue
 }
 
 type (
@@ -85,6 +138,7 @@ func NewClient(vu modules.VU, opts options) (*Client, error) {
 		requestFunc:      requestFunc,
 		asyncRequestFunc: asyncRequestFunc,
 		randSource:       rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec
+		exportCtx:        vu.Context(),
 	}
 
 	if err := client.Configure(opts); err != nil {
@@ -101,20 +155,47 @@ func (c *Client) Configure(opts options) error {
 		return fmt.Errorf("invalid options: %w", err)
 	}
 
-	var sampler Sampler = NewAlwaysOnSampler()
-	if opts.Sampling != 1.0 {
-		sampler = NewProbabilisticSampler(opts.Sampling)
+	var parent *traceParent
+	if opts.Parent != "" {
+		var err error
+		if parent, err = parseTraceParent(opts.Parent); err != nil {
+			return err
+		}
 	}
 
-	switch opts.Propagator {
-	case "w3c":
-		c.propagator = NewW3CPropagator(sampler)
-	case "jaeger":
-		c.propagator = NewJaegerPropagator(sampler)
+	sampler := newSampler(opts.Sampling, parent)
+	propagator, err := newPropagator(opts.Propagator, sampler, opts)
+	if err != nil {
+		return err
+	}
+
+	baggage, err := newBaggage(opts.Baggage)
+	if err != nil {
+		return fmt.Errorf("invalid baggage: %w", err)
+	}
+
+	c.sampler = sampler
+	c.propagator = propagator
+	c.baggage = baggage
+	c.parent = parent
+
+	switch {
+	case opts.traceIDGeneratorFunc != nil:
+		c.traceIDGenerator = newCallbackTraceIDGenerator(opts.traceIDGeneratorFunc)
+	case opts.TraceIDGenerator == Random128TraceIDGeneratorName,
+		opts.TraceIDGenerator == W3CRandomTraceIDGeneratorName:
+		c.traceIDGenerator = func() (string, error) { return newRandomTraceID(c.randSource) }
 	default:
-		return fmt.Errorf("unknown propagator: %s", opts.Propagator)
+		c.traceIDGenerator = nil
 	}
 
+	c.spansMx.Lock()
+	c.exporter = nil
+	if opts.Exporter != nil {
+		c.exporter = newOTLPExporter(*opts.Exporter)
+	}
+	c.spansMx.Unlock()
+
 	c.opts = opts
 
 	return nil
@@ -194,23 +275,95 @@ func (c *Client) Put(url goja.Value, args ...goja.Value) (*httpmodule.Response,
 	return c.Request(http.MethodPut, url, args...)
 }
 
+// CurrentTraceID returns the trace ID attached to the last request
+// instrumented by the client, or an empty string if there isn't any.
+//
+// It allows scripts to correlate the requests they perform with their
+// own logs, or to tag their checks with the trace ID, for instance:
+//
+//	check(res, { 'is ok': (r) => r.status === 200 }, { trace_id: client.currentTraceID() })
+func (c *Client) CurrentTraceID() string {
+	return c.currentTraceID
+}
+
+// SetParent sets the externally-provided parent trace context the client
+// continues, from the value of a W3C traceparent header. This allows scripts
+// to pick the parent up at runtime, for instance from their setup data.
+//
+// Once set, requests and root spans are attached to the parent's trace, and
+// its sampling decision takes precedence over the client's sampling rate.
+// Passing an empty string resets the client to starting new traces.
+func (c *Client) SetParent(traceparent string) error {
+	var parent *traceParent
+	if traceparent != "" {
+		var err error
+		if parent, err = parseTraceParent(traceparent); err != nil {
+			return err
+		}
+	}
+
+	sampler := newSampler(c.opts.Sampling, parent)
+	propagator, err := newPropagator(c.opts.Propagator, sampler, c.opts)
+	if err != nil {
+		return err
+	}
+
+	c.sampler = sampler
+	c.propagator = propagator
+	c.parent = parent
+
+	return nil
+}
+
 func (c *Client) instrumentedCall(call func(args ...goja.Value) error, args ...goja.Value) error {
 	if len(args) == 0 {
 		args = []goja.Value{goja.Null()}
 	}
 
-	traceContextHeader, encodedTraceID, err := c.generateTraceContext()
+	var params goja.Value
+	if len(args) > 1 {
+		params = args[1]
+	}
+
+	tracing, enabled, err := c.requestTracing(params)
 	if err != nil {
-		return err
+		return fmt.Errorf("invalid request tracing params; reason: %w", err)
+	}
+
+	// Tracing was explicitly disabled for this request.
+	if !enabled {
+		return call(args...)
 	}
 
-	// update the `params` argument with the trace context header
-	// so that it can be used by the http module's request function.
-	args, err = c.instrumentArguments(traceContextHeader, args...)
+	return c.tracedCall(tracing, func(traceContextHeader http.Header) error {
+		// update the `params` argument with the trace context header
+		// so that it can be used by the http module's request function.
+		args, err = c.instrumentArguments(traceContextHeader, args...)
+		if err != nil {
+			return fmt.Errorf("failed to instrument request arguments; reason: %w", err)
+		}
+
+		return call(args...)
+	})
+}
+
+// tracedCall generates a new trace context using the given request tracing
+// configuration, and hands its headers over to the call function.
+//
+// For the duration of the call, the generated trace ID is added to the VU's
+// metadata, so that it ends up in the metadata of the samples emitted meanwhile.
+func (c *Client) tracedCall(tracing requestTracing, call func(traceContextHeader http.Header) error) error {
+	traceContextHeader, encodedTraceID, err := c.generateTraceContext(tracing.propagator)
 	if err != nil {
-		return fmt.Errorf("failed to instrument request arguments; reason: %w", err)
+		return err
 	}
 
+	if tracing.baggage != "" {
+		traceContextHeader[BaggageHeaderName] = []string{tracing.baggage}
+	}
+
+	c.currentTraceID = encodedTraceID
+
 	// Add the trace ID to the VU's state, so that it can be
 	// used in the metrics emitted by the HTTP module.
 	c.vu.State().Tags.Modify(func(t *metrics.TagsAndMeta) {
@@ -224,17 +377,31 @@ func (c *Client) instrumentedCall(call func(args ...goja.Value) error, args ...g
 		})
 	}()
 
-	return call(args...)
+	return call(traceContextHeader)
 }
 
-func (c *Client) generateTraceContext() (http.Header, string, error) {
-	traceID, err := newTraceID(k6Prefix, k6CloudCode, time.Now(), c.randSource)
-	if err != nil {
-		return http.Header{}, "", fmt.Errorf("failed to generate trace ID; reason: %w", err)
+func (c *Client) generateTraceContext(propagator Propagator) (http.Header, string, error) {
+	var (
+		traceID string
+		spanID  string
+	)
+
+	// If a span is active, the request is attached to its trace, and
+	// declares it as its parent. Otherwise, a new trace is started, or
+	// the external parent trace is continued, if any.
+	if span := c.currentSpan(); span != nil {
+		traceID, spanID = span.TraceID, span.SpanID
+	} else {
+		var err error
+		traceID, err = c.newTraceID()
+		if err != nil {
+			return http.Header{}, "", err
+		}
+		spanID = newSpanID()
 	}
 
 	// Produce a trace header in the format defined by the configured propagator.
-	traceContextHeader, err := c.propagator.Propagate(traceID)
+	traceContextHeader, err := propagator.Propagate(traceID, spanID)
 	if err != nil {
 		return http.Header{}, "", fmt.Errorf("failed to propagate trace ID; reason: %w", err)
 	}
@@ -242,6 +409,148 @@ func (c *Client) generateTraceContext() (http.Header, string, error) {
 	return traceContextHeader, traceID, nil
 }
 
+// instrumentedParamsCall performs the given call with the trace context injected
+// in the headers property, named headersParamName, of its params.
+//
+// It is meant to instrument calls of modules expecting the trace context headers
+// as part of a params object, rather than as part of k6/http request arguments.
+// The params object passed to the call is a copy of the provided one, stripped
+// of the `tracing` property, as the instrumented modules could reject it otherwise.
+func (c *Client) instrumentedParamsCall(
+	params goja.Value, headersParamName string, call func(params goja.Value) error,
+) error {
+	tracing, enabled, err := c.requestTracing(params)
+	if err != nil {
+		return fmt.Errorf("invalid request tracing params; reason: %w", err)
+	}
+
+	if !enabled {
+		return call(c.instrumentParams(params, headersParamName, nil))
+	}
+
+	return c.tracedCall(tracing, func(traceContextHeader http.Header) error {
+		return call(c.instrumentParams(params, headersParamName, traceContextHeader))
+	})
+}
+
+// instrumentParams returns a copy of the provided params, stripped of the `tracing`
+// property, and whose headers property holds the provided trace context entries.
+//
+// Header names are lowercased, as it is required by gRPC metadata, and doesn't
+// matter to HTTP-based protocols, whose header names are case-insensitive.
+func (c *Client) instrumentParams(params goja.Value, headersParamName string, traceContext http.Header) goja.Value {
+	rt := c.vu.Runtime()
+
+	result := rt.NewObject()
+	headers := rt.NewObject()
+
+	if !common.IsNullish(params) {
+		paramsObj := params.ToObject(rt)
+		for _, key := range paramsObj.Keys() {
+			if key == requestOptionsParamName {
+				continue
+			}
+
+			_ = result.Set(key, paramsObj.Get(key))
+		}
+
+		if value := paramsObj.Get(headersParamName); !common.IsNullish(value) {
+			valueObj := value.ToObject(rt)
+			for _, key := range valueObj.Keys() {
+				_ = headers.Set(key, valueObj.Get(key))
+			}
+		}
+	}
+
+	for key, values := range traceContext {
+		_ = headers.Set(strings.ToLower(key), strings.Join(values, ","))
+	}
+
+	_ = result.Set(headersParamName, headers)
+
+	return result
+}
+
+// requestTracing holds the tracing configuration applied to a single request.
+type requestTracing struct {
+	// propagator produces the request's trace context headers.
+	propagator Propagator
+
+	// baggage holds the value of the request's baggage header.
+	// It is omitted when empty.
+	baggage string
+}
+
+// requestTracing returns the tracing configuration to apply to a request,
+// based on the optional `tracing` property of its params object.
+//
+// Setting `tracing` to false disables the propagation of the trace context
+// for the request, in which case the returned boolean is false. Setting it to an
+// object allows to override the client's options for this request only.
+func (c *Client) requestTracing(params goja.Value) (requestTracing, bool, error) {
+	tracing := requestTracing{propagator: c.propagator, baggage: c.baggage}
+
+	if common.IsNullish(params) {
+		return tracing, true, nil
+	}
+
+	rt := c.vu.Runtime()
+
+	tracingValue := params.ToObject(rt).Get(requestOptionsParamName)
+	if common.IsNullish(tracingValue) {
+		return tracing, true, nil
+	}
+
+	if enabled, isBool := tracingValue.Export().(bool); isBool {
+		return tracing, enabled, nil
+	}
+
+	var ro requestOptions
+	if err := rt.ExportTo(tracingValue, &ro); err != nil {
+		return tracing, false, fmt.Errorf("unable to parse the %s param; reason: %w", requestOptionsParamName, err)
+	}
+
+	if ro.Propagator != "" && ro.Propagator != c.opts.Propagator {
+		propagator, err := newPropagator(ro.Propagator, c.sampler, c.opts)
+		if err != nil {
+			return tracing, false, err
+		}
+		tracing.propagator = propagator
+	}
+
+	// Per-request baggage entries are merged with, and take
+	// precedence over, the client's ones.
+	if len(ro.Baggage) > 0 {
+		baggage, err := newBaggage(mergeBaggage(c.opts.Baggage, ro.Baggage))
+		if err != nil {
+			return tracing, false, fmt.Errorf("invalid baggage: %w", err)
+		}
+		tracing.baggage = baggage
+	}
+
+	return tracing, true, nil
+}
+
+// newTraceID generates a new trace ID using the client's trace ID generator,
+// or the default k6 strategy and the client's random source. When the client
+// continues an external parent trace, the parent's trace ID is returned instead.
+func (c *Client) newTraceID() (string, error) {
+	if c.parent != nil {
+		return c.parent.TraceID, nil
+	}
+
+	if c.traceIDGenerator != nil {
+		return c.traceIDGenerator()
+	}
+
+	traceID, err := newTraceID(k6Prefix, k6CloudCode, time.Now(), c.randSource)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate trace ID; reason: %w", err)
+	}
+
+	return traceID, nil
+}
+
 // instrumentArguments: expects args to be in the format expected by the
 // request method (body, params)
 func (c *Client) instrumentArguments(traceContext http.Header, args ...goja.Value) ([]goja.Value, error) {
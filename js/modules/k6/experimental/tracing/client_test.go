@@ -3,6 +3,7 @@ package tracing
 import (
 	"math/rand"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/dop251/goja"
@@ -203,6 +204,204 @@ func TestClientInstrumentedCall(t *testing.T) {
 	assert.False(t, hasTraceIDKey)
 }
 
+func TestClientCurrentTraceID(t *testing.T) {
+	t.Parallel()
+
+	testCase := newTestCase(t)
+	testCase.testSetup.MoveToVUContext(&lib.State{
+		Tags: lib.NewVUStateTags(&metrics.TagSet{}),
+	})
+	testCase.client.propagator = NewW3CPropagator(NewAlwaysOnSampler())
+
+	// No request was instrumented yet.
+	assert.Empty(t, testCase.client.CurrentTraceID())
+
+	var gotMetadataTraceID string
+	callFn := func(args ...goja.Value) error {
+		gotMetadataTraceID = testCase.client.vu.State().Tags.GetCurrentValues().Metadata[metadataTraceIDKeyName]
+		return nil
+	}
+
+	require.NoError(t, testCase.client.instrumentedCall(callFn))
+	require.NotEmpty(t, gotMetadataTraceID)
+
+	// The trace ID remains available once the request is done.
+	assert.Equal(t, gotMetadataTraceID, testCase.client.CurrentTraceID())
+}
+
+func TestClientContinuesParentTrace(t *testing.T) {
+	t.Parallel()
+
+	const (
+		parentTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentSpanID  = "00f067aa0ba902b7"
+	)
+
+	testCase := newTestCase(t)
+
+	err := testCase.client.Configure(options{
+		Propagator: W3CPropagatorName,
+		Sampling:   1.0,
+		Parent:     W3CVersion + "-" + parentTraceID + "-" + parentSpanID + "-" + W3CUnsampledTraceFlag,
+	})
+	require.NoError(t, err)
+
+	// Requests are attached to the parent's trace, as children of
+	// new spans, and honor the parent's sampling decision.
+	gotHeader, gotTraceID, err := testCase.client.generateTraceContext(testCase.client.propagator)
+	require.NoError(t, err)
+	assert.Equal(t, parentTraceID, gotTraceID)
+
+	//nolint:staticcheck // as traceparent is not a canonical header
+	gotParts := strings.Split(gotHeader[W3CHeaderName][0], "-")
+	require.Len(t, gotParts, 4)
+	assert.Equal(t, parentTraceID, gotParts[1])
+	assert.NotEqual(t, parentSpanID, gotParts[2])
+	assert.Equal(t, W3CUnsampledTraceFlag, gotParts[3])
+
+	// The parent can be changed at runtime...
+	otherTraceID := strings.Repeat("a", 32)
+	err = testCase.client.SetParent(W3CVersion + "-" + otherTraceID + "-" + parentSpanID + "-" + W3CSampledTraceFlag)
+	require.NoError(t, err)
+
+	gotHeader, gotTraceID, err = testCase.client.generateTraceContext(testCase.client.propagator)
+	require.NoError(t, err)
+	assert.Equal(t, otherTraceID, gotTraceID)
+	//nolint:staticcheck // as traceparent is not a canonical header
+	assert.True(t, strings.HasSuffix(gotHeader[W3CHeaderName][0], "-"+W3CSampledTraceFlag))
+
+	// ...and reset, in which case new traces are started again.
+	require.NoError(t, testCase.client.SetParent(""))
+
+	_, gotTraceID, err = testCase.client.generateTraceContext(testCase.client.propagator)
+	require.NoError(t, err)
+	assert.NotEqual(t, otherTraceID, gotTraceID)
+
+	assert.Error(t, testCase.client.SetParent("invalid"))
+}
+
+func TestClientConfigureJaegerPropagator(t *testing.T) {
+	t.Parallel()
+
+	testCase := newTestCase(t)
+
+	err := testCase.client.Configure(options{Propagator: JaegerPropagatorName, Sampling: 0.5})
+	require.NoError(t, err)
+	require.IsType(t, &JaegerPropagator{}, testCase.client.propagator)
+
+	// A probabilistic sampler should be usable straight away, and
+	// produce a valid sampling flag.
+	gotHeader, _, err := testCase.client.generateTraceContext(testCase.client.propagator)
+	require.NoError(t, err)
+
+	//nolint:staticcheck // as uber-trace-id is not a canonical header
+	gotValue := gotHeader[JaegerHeaderName][0]
+	assert.True(t,
+		strings.HasSuffix(gotValue, ":"+JaegerSampledTraceFlag) ||
+			strings.HasSuffix(gotValue, ":"+JaegerUnsampledTraceFlag),
+	)
+}
+
+func TestClientRequestTracing(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		params         string
+		wantEnabled    bool
+		wantPropagator Propagator
+		wantBaggage    string
+		wantErr        bool
+	}{
+		{
+			name:           "no params uses the client's propagator",
+			params:         `null`,
+			wantEnabled:    true,
+			wantPropagator: &W3CPropagator{},
+		},
+		{
+			name:           "params without tracing property uses the client's propagator",
+			params:         `({ headers: {} })`,
+			wantEnabled:    true,
+			wantPropagator: &W3CPropagator{},
+		},
+		{
+			name:        "tracing set to false disables propagation",
+			params:      `({ tracing: false })`,
+			wantEnabled: false,
+		},
+		{
+			name:           "tracing set to true uses the client's propagator",
+			params:         `({ tracing: true })`,
+			wantEnabled:    true,
+			wantPropagator: &W3CPropagator{},
+		},
+		{
+			name:           "tracing propagator overrides the client's propagator",
+			params:         `({ tracing: { propagator: 'jaeger' } })`,
+			wantEnabled:    true,
+			wantPropagator: &JaegerPropagator{},
+		},
+		{
+			name:           "no params uses the client's baggage",
+			params:         `null`,
+			wantEnabled:    true,
+			wantPropagator: &W3CPropagator{},
+			wantBaggage:    "run=42,team=payments",
+		},
+		{
+			name:           "tracing baggage is merged with the client's baggage",
+			params:         `({ tracing: { baggage: { team: 'checkout', user: 'alice smith' } } })`,
+			wantEnabled:    true,
+			wantPropagator: &W3CPropagator{},
+			wantBaggage:    "run=42,team=checkout,user=alice%20smith",
+		},
+		{
+			name:    "invalid tracing baggage fails",
+			params:  `({ tracing: { baggage: { 'invalid key': 'value' } } })`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown tracing propagator fails",
+			params:  `({ tracing: { propagator: 'unknown' } })`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			testCase := newTestCase(t)
+			require.NoError(t, testCase.client.Configure(options{
+				Propagator: W3CPropagatorName,
+				Sampling:   1.0,
+				Baggage:    map[string]string{"team": "payments", "run": "42"},
+			}))
+
+			params, err := testCase.testSetup.VU.Runtime().RunString(tc.params)
+			require.NoError(t, err)
+
+			gotTracing, gotEnabled, gotErr := testCase.client.requestTracing(params)
+			if tc.wantErr {
+				assert.Error(t, gotErr)
+				return
+			}
+
+			require.NoError(t, gotErr)
+			assert.Equal(t, tc.wantEnabled, gotEnabled)
+			if tc.wantEnabled {
+				assert.IsType(t, tc.wantPropagator, gotTracing.propagator)
+			}
+			if tc.wantBaggage != "" {
+				assert.Equal(t, tc.wantBaggage, gotTracing.baggage)
+			}
+		})
+	}
+}
+
 // This test ensures that the trace_id is added to the vu metadata when
 // and instrumented request is called; and that we can find it in the
 // produced samples.
@@ -274,7 +473,7 @@ func TestCallingInstrumentedRequestEmitsTraceIdMetadata(t *testing.T) {
 type tracingClientTestCase struct {
 	t                  *testing.T
 	testSetup          *modulestest.Runtime
-	client             Client
+	client             *Client
 	traceContextHeader http.Header
 }
 
@@ -282,7 +481,7 @@ func newTestCase(t *testing.T) *tracingClientTestCase {
 	testSetup := modulestest.NewRuntime(t)
 	// Here we provide the client with a fixed seed to ensure that the
 	// generated trace IDs random part is deterministic.
-	client := Client{vu: testSetup.VU, randSource: rand.New(rand.NewSource(0))} //nolint:gosec
+	client := &Client{vu: testSetup.VU, randSource: rand.New(rand.NewSource(0))} //nolint:gosec
 	traceContextHeader := http.Header{}
 	traceContextHeader.Add(traceparentHeaderName, testTraceID)
 
@@ -0,0 +1,299 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// otlpInstrumentationScopeName is the name of the instrumentation
+	// scope the exported spans are attributed to.
+	otlpInstrumentationScopeName = "k6/experimental/tracing"
+
+	// otlpServiceName is the default value of the `service.name`
+	// resource attribute of the exported spans.
+	otlpServiceName = "k6"
+
+	// otlpSpanKindClient is the OTLP span kind value for client spans.
+	otlpSpanKindClient = 3
+
+	// defaultExporterTimeout is the default timeout applied to a
+	// single export request.
+	defaultExporterTimeout = 10 * time.Second
+
+	// exportQueueSize is the number of traces a client can queue for
+	// export, the traces ended while the queue is full are dropped.
+	exportQueueSize = 100
+)
+
+// exporterOptions are the options configuring the OTLP/HTTP span exporter.
+type exporterOptions struct {
+	// Endpoint is the URL of the OTLP/HTTP traces endpoint, for
+	// instance: http://localhost:4318/v1/traces.
+	Endpoint string `json:"endpoint"`
+
+	// Headers are additional headers sent along with every export request.
+	Headers map[string]string `json:"headers"`
+}
+
+func (eo *exporterOptions) validate() error {
+	if eo.Endpoint == "" {
+		return fmt.Errorf("exporter endpoint is required")
+	}
+
+	u, err := url.Parse(eo.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid exporter endpoint; reason: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid exporter endpoint scheme %q, expected http or https", u.Scheme)
+	}
+
+	return nil
+}
+
+// otlpExporter exports spans to an OTLP/HTTP endpoint using the JSON encoding.
+type otlpExporter struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+}
+
+// newOTLPExporter returns a new otlpExporter configured with the given options.
+func newOTLPExporter(opts exporterOptions) *otlpExporter {
+	return &otlpExporter{
+		endpoint: opts.Endpoint,
+		headers:  opts.Headers,
+		client:   &http.Client{Timeout: defaultExporterTimeout},
+	}
+}
+
+// Export sends the given spans to the exporter's endpoint.
+func (e *otlpExporter) Export(ctx context.Context, spans []*Span) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(newOTLPTracesPayload(spans))
+	if err != nil {
+		return fmt.Errorf("failed to encode spans; reason: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create export request; reason: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export spans; reason: %w", err)
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to export spans; unexpected response status: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// exportJob is a trace queued for export, with the
+// exporter configured when its root span has ended.
+type exportJob struct {
+	exporter *otlpExporter
+	spans    []*Span
+}
+
+// exportQueue exports the traces of a client one at a time from a single
+// goroutine, so a slow collector can't pile up export goroutines.
+type exportQueue struct {
+	ctx    context.Context //nolint:containedctx
+	logger logrus.FieldLogger
+
+	mx     sync.Mutex // guards closed, no job is queued after the queue is closed
+	closed bool
+	jobs   chan exportJob
+	done   chan struct{}
+}
+
+// newExportQueue starts the export goroutine, the exports are done
+// with the provided context, which should be the one of the VU.
+func newExportQueue(ctx context.Context, logger logrus.FieldLogger) *exportQueue {
+	q := &exportQueue{
+		ctx:    ctx,
+		logger: logger,
+		jobs:   make(chan exportJob, exportQueueSize),
+		done:   make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+func (q *exportQueue) run() {
+	defer close(q.done)
+	for job := range q.jobs {
+		if err := job.exporter.Export(q.ctx, job.spans); err != nil {
+			q.logger.WithError(err).Warn("Failed to export the tracing spans")
+		}
+	}
+}
+
+// push queues the trace for export, without blocking, the trace is
+// dropped with a warning if the queue is full or closed.
+func (q *exportQueue) push(job exportJob) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+	if !q.closed {
+		select {
+		case q.jobs <- job:
+			return
+		default:
+		}
+	}
+	q.logger.WithField("spans", len(job.spans)).
+		Warn("The tracing spans were dropped, since the export queue is full or closed")
+}
+
+// close waits for the queued traces to be exported.
+func (q *exportQueue) close() {
+	q.mx.Lock()
+	if q.closed {
+		q.mx.Unlock()
+		return
+	}
+	q.closed = true
+	close(q.jobs)
+	q.mx.Unlock()
+	<-q.done
+}
+
+// The following types model the subset of the OTLP/JSON traces
+// payload the exporter produces.
+//
+// See https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type (
+	otlpTracesPayload struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              int            `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	}
+
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+
+	otlpAnyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+func newOTLPTracesPayload(spans []*Span) otlpTracesPayload {
+	otlpSpans := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		otlpSpans = append(otlpSpans, otlpSpan{
+			TraceID:           span.TraceID,
+			SpanID:            span.SpanID,
+			ParentSpanID:      span.ParentSpanID,
+			Name:              span.Name,
+			Kind:              otlpSpanKindClient,
+			StartTimeUnixNano: strconv.FormatInt(span.startTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.endTime.UnixNano(), 10),
+			Attributes:        newOTLPAttributes(span.attributes),
+		})
+	}
+
+	return otlpTracesPayload{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: otlpResource{
+					Attributes: newOTLPAttributes(map[string]interface{}{"service.name": otlpServiceName}),
+				},
+				ScopeSpans: []otlpScopeSpans{
+					{
+						Scope: otlpScope{Name: otlpInstrumentationScopeName},
+						Spans: otlpSpans,
+					},
+				},
+			},
+		},
+	}
+}
+
+func newOTLPAttributes(attributes map[string]interface{}) []otlpKeyValue {
+	kvs := make([]otlpKeyValue, 0, len(attributes))
+	for key, value := range attributes {
+		kvs = append(kvs, otlpKeyValue{Key: key, Value: newOTLPAnyValue(value)})
+	}
+
+	return kvs
+}
+
+func newOTLPAnyValue(value interface{}) otlpAnyValue {
+	switch v := value.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &v}
+	case bool:
+		return otlpAnyValue{BoolValue: &v}
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		return otlpAnyValue{IntValue: &s}
+	case int:
+		s := strconv.Itoa(v)
+		return otlpAnyValue{IntValue: &s}
+	case float64:
+		return otlpAnyValue{DoubleValue: &v}
+	default:
+		s := fmt.Sprintf("%v", v)
+		return otlpAnyValue{StringValue: &s}
+	}
+}
@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"fmt"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+)
+
+const (
+	// grpcMetadataParamName is the name of the grpc module's params
+	// property holding the metadata sent along with an invocation.
+	grpcMetadataParamName = "metadata"
+
+	// grpcDeprecatedMetadataParamName is the deprecated alias of the
+	// grpc module's metadata params property.
+	grpcDeprecatedMetadataParamName = "headers"
+
+	// grpcReflectParamName is the name of the grpc module's connect params
+	// property enabling the use of the reflection protocol.
+	grpcReflectParamName = "reflect"
+
+	// grpcReflectMetadataParamName is the name of the grpc module's connect
+	// params property holding the metadata sent along with reflection requests.
+	grpcReflectMetadataParamName = "reflectMetadata"
+)
+
+// instrumentGRPCClient returns a copy of the provided k6/net/grpc client
+// object, whose invoke and connect methods are instrumented with tracing metadata.
+//
+// The returned object uses the original client as its prototype, hence any
+// other method of the original client remains available as-is.
+func (c *Client) instrumentGRPCClient(clientObj *goja.Object) (*goja.Object, error) {
+	rt := c.vu.Runtime()
+
+	var invoke func(method string, req goja.Value, params goja.Value) (goja.Value, error)
+	if err := rt.ExportTo(clientObj.Get("invoke"), &invoke); err != nil {
+		return nil, fmt.Errorf("unable to export the grpc client's invoke method; reason: %w", err)
+	}
+
+	var connect func(addr string, params goja.Value) (goja.Value, error)
+	if err := rt.ExportTo(clientObj.Get("connect"), &connect); err != nil {
+		return nil, fmt.Errorf("unable to export the grpc client's connect method; reason: %w", err)
+	}
+
+	instrumented := rt.NewObject()
+	if err := instrumented.SetPrototype(clientObj); err != nil {
+		return nil, err
+	}
+
+	instrumentedInvoke := func(method string, req goja.Value, params goja.Value) (goja.Value, error) {
+		// Honor the deprecated metadata alias, if used.
+		metadataParamName := grpcMetadataParamName
+		if !common.IsNullish(params) && !common.IsNullish(params.ToObject(rt).Get(grpcDeprecatedMetadataParamName)) {
+			metadataParamName = grpcDeprecatedMetadataParamName
+		}
+
+		var result goja.Value
+		err := c.instrumentedParamsCall(params, metadataParamName, func(params goja.Value) error {
+			var err error
+			result, err = invoke(method, req, params)
+			return err
+		})
+		return result, err
+	}
+
+	instrumentedConnect := func(addr string, params goja.Value) (goja.Value, error) {
+		if common.IsNullish(params) {
+			return connect(addr, params)
+		}
+
+		// Connecting only ever performs a call to the server when
+		// the reflection protocol is used, in which case we trace it.
+		if !params.ToObject(rt).Get(grpcReflectParamName).ToBoolean() {
+			return connect(addr, c.instrumentParams(params, grpcReflectMetadataParamName, nil))
+		}
+
+		var result goja.Value
+		err := c.instrumentedParamsCall(params, grpcReflectMetadataParamName, func(params goja.Value) error {
+			var err error
+			result, err = connect(addr, params)
+			return err
+		})
+		return result, err
+	}
+
+	if err := instrumented.Set("invoke", instrumentedInvoke); err != nil {
+		return nil, err
+	}
+
+	if err := instrumented.Set("connect", instrumentedConnect); err != nil {
+		return nil, err
+	}
+
+	return instrumented, nil
+}
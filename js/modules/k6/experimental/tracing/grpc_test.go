@@ -0,0 +1,220 @@
+package tracing
+
+import (
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/js/compiler"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/js/modules/k6/http"
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+func TestInstrumentGRPC(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invoke carries the trace context metadata", func(t *testing.T) {
+		t.Parallel()
+
+		ts, grpcModule := newGRPCTestSetup(t)
+
+		_, err := ts.VU.Runtime().RunString(`
+			instrumentGRPC({propagator: 'w3c'})
+			const client = new grpc.Client()
+		`)
+		require.NoError(t, err)
+
+		ts.MoveToVUContext(&lib.State{Tags: lib.NewVUStateTags(&metrics.TagSet{})})
+		grpcModule.onInvoke = func() {
+			_, hasTraceID := ts.VU.State().Tags.GetCurrentValues().Metadata[metadataTraceIDKeyName]
+			assert.True(t, hasTraceID)
+		}
+
+		_, err = ts.VU.Runtime().RunString(`
+			client.invoke('service/Method', {}, { metadata: { 'x-custom': 'value' } })
+		`)
+		require.NoError(t, err)
+
+		require.NotNil(t, grpcModule.lastParams)
+		metadata, ok := grpcModule.lastParams["metadata"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "value", metadata["x-custom"])
+		assert.NotEmpty(t, metadata[W3CHeaderName])
+
+		_, hasTraceID := ts.VU.State().Tags.GetCurrentValues().Metadata[metadataTraceIDKeyName]
+		assert.False(t, hasTraceID)
+	})
+
+	t.Run("invoke tracing params are stripped and honored", func(t *testing.T) {
+		t.Parallel()
+
+		ts, grpcModule := newGRPCTestSetup(t)
+
+		_, err := ts.VU.Runtime().RunString(`
+			instrumentGRPC({propagator: 'w3c'})
+			const client = new grpc.Client()
+		`)
+		require.NoError(t, err)
+
+		ts.MoveToVUContext(&lib.State{Tags: lib.NewVUStateTags(&metrics.TagSet{})})
+
+		_, err = ts.VU.Runtime().RunString(`
+			client.invoke('service/Method', {}, { tracing: { propagator: 'jaeger' } })
+		`)
+		require.NoError(t, err)
+
+		require.NotContains(t, grpcModule.lastParams, requestOptionsParamName)
+		metadata, ok := grpcModule.lastParams["metadata"].(map[string]interface{})
+		require.True(t, ok)
+		assert.NotEmpty(t, metadata[JaegerHeaderName])
+		assert.NotContains(t, metadata, W3CHeaderName)
+	})
+
+	t.Run("connect with reflection carries the trace context metadata", func(t *testing.T) {
+		t.Parallel()
+
+		ts, grpcModule := newGRPCTestSetup(t)
+
+		_, err := ts.VU.Runtime().RunString(`
+			instrumentGRPC({propagator: 'w3c'})
+			const client = new grpc.Client()
+		`)
+		require.NoError(t, err)
+
+		ts.MoveToVUContext(&lib.State{Tags: lib.NewVUStateTags(&metrics.TagSet{})})
+
+		_, err = ts.VU.Runtime().RunString(`
+			client.connect('localhost:4242', { reflect: true })
+		`)
+		require.NoError(t, err)
+
+		metadata, ok := grpcModule.lastParams["reflectMetadata"].(map[string]interface{})
+		require.True(t, ok)
+		assert.NotEmpty(t, metadata[W3CHeaderName])
+	})
+
+	t.Run("sharing the configuration with instrumentHTTP", func(t *testing.T) {
+		t.Parallel()
+
+		ts, _ := newGRPCTestSetup(t)
+
+		_, err := ts.VU.Runtime().RunString(`
+			instrumentHTTP({propagator: 'w3c'})
+			instrumentGRPC()
+		`)
+		assert.NoError(t, err)
+	})
+
+	t.Run("sharing a trace ID generator function with instrumentHTTP", func(t *testing.T) {
+		t.Parallel()
+
+		ts, _ := newGRPCTestSetup(t)
+
+		_, err := ts.VU.Runtime().RunString(`
+			var generator = () => '0123456789abcdef0123456789abcdef'
+			instrumentHTTP({propagator: 'w3c', traceIdGenerator: generator, baggage: {}})
+			instrumentGRPC({propagator: 'w3c', traceIdGenerator: generator})
+		`)
+		assert.NoError(t, err)
+	})
+
+	t.Run("using another trace ID generator function than instrumentHTTP fails", func(t *testing.T) {
+		t.Parallel()
+
+		ts, _ := newGRPCTestSetup(t)
+
+		_, err := ts.VU.Runtime().RunString(`
+			instrumentHTTP({propagator: 'w3c', traceIdGenerator: () => '0123456789abcdef0123456789abcdef'})
+			instrumentGRPC({propagator: 'w3c', traceIdGenerator: () => '0123456789abcdef0123456789abcdef'})
+		`)
+		assert.ErrorContains(t, err, "the provided options differ")
+	})
+
+	t.Run("conflicting with the configuration of instrumentHTTP fails", func(t *testing.T) {
+		t.Parallel()
+
+		ts, _ := newGRPCTestSetup(t)
+
+		_, err := ts.VU.Runtime().RunString(`
+			instrumentHTTP({propagator: 'w3c'})
+			instrumentGRPC({propagator: 'jaeger'})
+		`)
+		assert.Error(t, err)
+	})
+
+	t.Run("calling it twice fails", func(t *testing.T) {
+		t.Parallel()
+
+		ts, _ := newGRPCTestSetup(t)
+
+		_, err := ts.VU.Runtime().RunString(`
+			instrumentGRPC({propagator: 'w3c'})
+			instrumentGRPC({propagator: 'w3c'})
+		`)
+		assert.Error(t, err)
+	})
+}
+
+// fakeGRPCModule is a stand-in for the k6/net/grpc module, whose
+// client records the params it is called with.
+type fakeGRPCModule struct {
+	vu         modules.VU
+	lastParams map[string]interface{}
+	onInvoke   func()
+}
+
+func (m *fakeGRPCModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	m.vu = vu
+	return m
+}
+
+func (m *fakeGRPCModule) Exports() modules.Exports {
+	return modules.Exports{Named: map[string]interface{}{"Client": m.newClient}}
+}
+
+func (m *fakeGRPCModule) newClient(goja.ConstructorCall) *goja.Object {
+	rt := m.vu.Runtime()
+	return rt.ToValue(&fakeGRPCClient{module: m}).ToObject(rt)
+}
+
+type fakeGRPCClient struct {
+	module *fakeGRPCModule
+}
+
+func (c *fakeGRPCClient) Invoke(_ string, _ goja.Value, params goja.Value) bool {
+	c.module.lastParams = params.Export().(map[string]interface{}) //nolint:forcetypeassert
+	if c.module.onInvoke != nil {
+		c.module.onInvoke()
+	}
+	return true
+}
+
+func (c *fakeGRPCClient) Connect(_ string, params goja.Value) bool {
+	c.module.lastParams = params.Export().(map[string]interface{}) //nolint:forcetypeassert
+	return true
+}
+
+func newGRPCTestSetup(t *testing.T) (*modulestest.Runtime, *fakeGRPCModule) {
+	ts := modulestest.NewRuntime(t)
+	grpcModule := &fakeGRPCModule{}
+
+	err := ts.SetupModuleSystem(map[string]interface{}{
+		"k6/http":                 http.New(),
+		"k6/net/grpc":             grpcModule,
+		"k6/experimental/tracing": new(RootModule),
+	}, nil, compiler.New(ts.VU.InitEnvField.Logger))
+	require.NoError(t, err)
+
+	_, err = ts.VU.Runtime().RunString(`
+		var grpc = require('k6/net/grpc')
+		var instrumentHTTP = require('k6/experimental/tracing').instrumentHTTP
+		var instrumentGRPC = require('k6/experimental/tracing').instrumentGRPC
+	`)
+	require.NoError(t, err)
+
+	return ts, grpcModule
+}
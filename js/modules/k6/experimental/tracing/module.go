@@ -26,6 +26,12 @@ type (
 
 		// Client holds the module's default tracing client.
 		*Client
+
+		// httpInstrumented, grpcInstrumented and wsInstrumented track whether
+		// the http, grpc and websockets modules were instrumented already.
+		httpInstrumented bool
+		grpcInstrumented bool
+		wsInstrumented   bool
 	}
 )
 
@@ -59,8 +65,13 @@ func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 func (mi *ModuleInstance) Exports() modules.Exports {
 	return modules.Exports{
 		Named: map[string]interface{}{
-			"Client":         mi.newClient,
-			"instrumentHTTP": mi.instrumentHTTP,
+			"Client":               mi.newClient,
+			"instrumentHTTP":       mi.instrumentHTTP,
+			"instrumentGRPC":       mi.instrumentGRPC,
+			"instrumentWebSockets": mi.instrumentWebSockets,
+			"startSpan":            mi.startSpan,
+			"currentTraceID":       mi.currentTraceID,
+			"setParent":            mi.setParent,
 		},
 	}
 }
@@ -100,7 +111,7 @@ func (mi *ModuleInstance) instrumentHTTP(options goja.Value) {
 		common.Throw(rt, common.NewInitContextError("tracing module's instrumentHTTP can only be called in the init context"))
 	}
 
-	if mi.Client != nil {
+	if mi.httpInstrumented {
 		err := errors.New(
 			"tracing module's instrumentHTTP can only be called once. " +
 				"if you were attempting to reconfigure the instrumentation, " +
@@ -109,20 +120,8 @@ func (mi *ModuleInstance) instrumentHTTP(options goja.Value) {
 		common.Throw(rt, err)
 	}
 
-	// Parse the options instance from the JS value.
-	// This will also validate the options, and set the sampling
-	// rate to 1.0 if the option was not set.
-	opts, err := newOptions(rt, options)
-	if err != nil {
-		common.Throw(rt, fmt.Errorf("unable to parse options object; reason: %w", err))
-	}
-
-	// Initialize the tracing module's instance default client,
-	// and configure it using the user-supplied set of options.
-	mi.Client, err = NewClient(mi.vu, opts)
-	if err != nil {
-		common.Throw(rt, err)
-	}
+	mi.configureDefaultClient(options)
+	mi.httpInstrumented = true
 
 	// Explicitly inject the http module in the VU's runtime.
 	// This allows us to later on override the http module's methods
@@ -164,3 +163,207 @@ func (mi *ModuleInstance) instrumentHTTP(options goja.Value) {
 	mustSetHTTPMethod("request", httpModuleObj, mi.Client.Request)
 	mustSetHTTPMethod("asyncRequest", httpModuleObj, mi.Client.AsyncRequest)
 }
+
+// InstrumentGRPC instruments the grpc module with tracing metadata.
+//
+// When used in the context of a k6 script, it will automatically replace
+// the imported grpc module's Client constructor with one producing clients
+// whose invoke and connect methods are instrumented.
+func (mi *ModuleInstance) instrumentGRPC(options goja.Value) {
+	rt := mi.vu.Runtime()
+
+	if mi.vu.State() != nil {
+		common.Throw(rt, common.NewInitContextError("tracing module's instrumentGRPC can only be called in the init context"))
+	}
+
+	if mi.grpcInstrumented {
+		common.Throw(rt, errors.New("tracing module's instrumentGRPC can only be called once"))
+	}
+
+	mi.configureDefaultClient(options)
+	mi.grpcInstrumented = true
+
+	// Explicitly inject the grpc module in the VU's runtime.
+	// This allows us to later on override its Client constructor
+	// with an instrumented one.
+	grpcModuleValue, err := rt.RunString(`require('k6/net/grpc')`)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	grpcModuleObj := grpcModuleValue.ToObject(rt)
+
+	clientConstructor := grpcModuleObj.Get("Client")
+	client := mi.Client
+
+	instrumentedConstructor := func(cc goja.ConstructorCall) *goja.Object {
+		clientObj, err := rt.New(clientConstructor, cc.Arguments...)
+		if err != nil {
+			common.Throw(rt, err)
+		}
+
+		instrumented, err := client.instrumentGRPCClient(clientObj)
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("unable to instrument the grpc client; reason: %w", err))
+		}
+
+		return instrumented
+	}
+
+	mustSetConstructor(rt, grpcModuleObj, "Client", instrumentedConstructor)
+}
+
+// InstrumentWebSockets instruments the websockets modules with tracing headers.
+//
+// When used in the context of a k6 script, it will automatically replace
+// the imported k6/ws module's connect function, and the imported
+// k6/experimental/websockets module's WebSocket constructor, with
+// instrumented ones.
+func (mi *ModuleInstance) instrumentWebSockets(options goja.Value) {
+	rt := mi.vu.Runtime()
+
+	if mi.vu.State() != nil {
+		common.Throw(rt, common.NewInitContextError(
+			"tracing module's instrumentWebSockets can only be called in the init context",
+		))
+	}
+
+	if mi.wsInstrumented {
+		common.Throw(rt, errors.New("tracing module's instrumentWebSockets can only be called once"))
+	}
+
+	mi.configureDefaultClient(options)
+	mi.wsInstrumented = true
+
+	// Explicitly inject the websockets modules in the VU's runtime.
+	// This allows us to later on override their methods with
+	// instrumented ones.
+	wsModuleValue, err := rt.RunString(`require('k6/ws')`)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	wsModuleObj := wsModuleValue.ToObject(rt)
+
+	connect, isFunc := goja.AssertFunction(wsModuleObj.Get("connect"))
+	if !isFunc {
+		common.Throw(rt, errors.New("unable to instrument ws.connect; it is not a function"))
+	}
+
+	if err := wsModuleObj.Set("connect", mi.Client.instrumentWSConnect(connect)); err != nil {
+		common.Throw(rt, fmt.Errorf("unable to overwrite ws.connect with an instrumented one; reason: %w", err))
+	}
+
+	expWSModuleValue, err := rt.RunString(`require('k6/experimental/websockets')`)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	expWSModuleObj := expWSModuleValue.ToObject(rt)
+
+	instrumentedConstructor := mi.Client.instrumentWebSocketConstructor(expWSModuleObj.Get("WebSocket"))
+	mustSetConstructor(rt, expWSModuleObj, "WebSocket", instrumentedConstructor)
+}
+
+// mustSetConstructor replaces the constructor exported under the given name by
+// the provided module exports object.
+//
+// The exports object of Go modules exposing named exports is backed by a Go map, and
+// setting one of its properties from goja would export the constructor as a plain
+// function. Hence, we directly replace the constructor in the underlying map instead.
+func mustSetConstructor(
+	rt *goja.Runtime, exportsObj *goja.Object, name string, constructor func(goja.ConstructorCall) *goja.Object,
+) {
+	exports, ok := exportsObj.Export().(map[string]interface{})
+	if !ok {
+		common.Throw(rt, fmt.Errorf("unable to overwrite %s with an instrumented one; unexpected module exports", name))
+	}
+
+	exports[name] = constructor
+}
+
+// configureDefaultClient initializes the module's default tracing client, shared
+// by the instrumented modules, using the provided options.
+//
+// If the default client was already initialized by a previous instrumentation,
+// the options can be omitted. Providing different ones is an error, as the
+// instrumented modules would otherwise silently share the first configuration.
+func (mi *ModuleInstance) configureDefaultClient(options goja.Value) {
+	rt := mi.vu.Runtime()
+
+	if mi.Client != nil && common.IsNullish(options) {
+		return
+	}
+
+	// Parse the options instance from the JS value.
+	// This will also validate the options, and set the sampling
+	// rate to 1.0 if the option was not set.
+	opts, err := newOptions(rt, options)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("unable to parse options object; reason: %w", err))
+	}
+
+	if mi.Client != nil {
+		if !opts.equal(&mi.Client.opts) {
+			common.Throw(rt, errors.New(
+				"the tracing module's instrumentations share the same configuration; "+
+					"the provided options differ from the ones used by the previous instrumentation",
+			))
+		}
+
+		return
+	}
+
+	// Initialize the tracing module's instance default client,
+	// and configure it using the user-supplied set of options.
+	mi.Client, err = NewClient(mi.vu, opts)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+}
+
+// startSpan starts a new span using the module's default tracing client.
+//
+// The default client is configured by calling instrumentHTTP, hence
+// startSpan can only be used once the http module has been instrumented.
+func (mi *ModuleInstance) startSpan(name string, opts goja.Value) *Span {
+	rt := mi.vu.Runtime()
+
+	if mi.Client == nil {
+		common.Throw(rt, errors.New(
+			"tracing module's startSpan can only be used once one of its instrument functions has been called; "+
+				"alternatively, consider using the startSpan method of a tracing.Client instance",
+		))
+	}
+
+	span, err := mi.Client.StartSpan(name, opts)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	return span
+}
+
+// currentTraceID returns the trace ID attached to the last request instrumented
+// by the module's default tracing client, or an empty string if there isn't any.
+func (mi *ModuleInstance) currentTraceID() string {
+	if mi.Client == nil {
+		return ""
+	}
+
+	return mi.Client.CurrentTraceID()
+}
+
+// setParent sets the external parent trace context continued by
+// the module's default tracing client, from a W3C traceparent value.
+func (mi *ModuleInstance) setParent(traceparent string) {
+	rt := mi.vu.Runtime()
+
+	if mi.Client == nil {
+		common.Throw(rt, errors.New(
+			"tracing module's setParent can only be used once one of its instrument functions has been called; "+
+				"alternatively, consider using the parent option of the instrument functions",
+		))
+	}
+
+	if err := mi.Client.SetParent(traceparent); err != nil {
+		common.Throw(rt, err)
+	}
+}
@@ -52,6 +52,19 @@ func TestInstrumentHTTP_FailsInVUContext(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestCurrentTraceID_EmptyBeforeInstrumentHTTP(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestSetup(t)
+
+	gotValue, err := ts.TestRuntime.VU.Runtime().RunString(`
+		require('k6/experimental/tracing').currentTraceID()
+	`)
+
+	require.NoError(t, err)
+	assert.Equal(t, "", gotValue.String())
+}
+
 type testSetup struct {
 	t           *testing.T
 	TestRuntime *modulestest.Runtime
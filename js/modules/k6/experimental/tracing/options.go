@@ -21,8 +21,54 @@ type options struct {
 
 	// Baggage is a map of baggage items to add to the tracer.
 	Baggage map[string]string `json:"baggage"`
+
+	// TraceState is a map of vendor-specific key/value entries
+	// propagated through the W3C tracestate header.
+	TraceState map[string]string `json:"tracestate" js:"tracestate"`
+
+	// Exporter configures the OTLP/HTTP exporter the spans
+	// started from the script are sent to.
+	Exporter *exporterOptions `json:"exporter"`
+
+	// Parent is the value of an externally-provided W3C traceparent
+	// header, such as one passed down by a CI pipeline. When set, the
+	// client continues the parent's trace instead of starting new ones.
+	Parent string `json:"parent"`
+
+	// TraceIDGenerator is the name of the strategy used
+	// to generate trace IDs. It is parsed separately,
+	// as the option can also hold a function.
+	TraceIDGenerator string `js:"-"`
+
+	// traceIDGeneratorFunc holds the script-provided function
+	// used to generate trace IDs, if any.
+	traceIDGeneratorFunc goja.Callable
+
+	// traceIDGeneratorValue holds the JS value of traceIDGeneratorFunc,
+	// which the function is compared by, as Go functions can't be.
+	traceIDGeneratorValue goja.Value
+}
+
+// requestOptionsParamName is the name of the request params property
+// holding the per-request tracing options.
+const requestOptionsParamName = "tracing"
+
+// requestOptions are the options that can be passed to an
+// instrumented request through the `tracing` property of its params,
+// to override the client's options for that request only.
+type requestOptions struct {
+	// Propagator is the propagation format to use for the request.
+	Propagator string `json:"propagator"`
+
+	// Baggage is a map of baggage items to add to the request,
+	// merged with the client's ones.
+	Baggage map[string]string `json:"baggage"`
 }
 
+// traceIDGeneratorOptionName is the name of the option holding
+// the trace ID generation strategy, or function.
+const traceIDGeneratorOptionName = "traceIdGenerator"
+
 // defaultSamplingRate is the default sampling rate applied to options.
 const defaultSamplingRate float64 = 1.0
 
@@ -42,9 +88,70 @@ func newOptions(rt *goja.Runtime, from goja.Value) (options, error) {
 		opts.Sampling = defaultSamplingRate
 	}
 
+	// The trace ID generator option either holds the name
+	// of a generation strategy, or a generator function.
+	fromTraceIDGeneratorValue := from.ToObject(rt).Get(traceIDGeneratorOptionName)
+	if !common.IsNullish(fromTraceIDGeneratorValue) {
+		if fn, isFunc := goja.AssertFunction(fromTraceIDGeneratorValue); isFunc {
+			opts.traceIDGeneratorFunc = fn
+			opts.traceIDGeneratorValue = fromTraceIDGeneratorValue
+		} else if name, isString := fromTraceIDGeneratorValue.Export().(string); isString {
+			opts.TraceIDGenerator = name
+		} else {
+			return opts, fmt.Errorf("%s option must be either a string or a function", traceIDGeneratorOptionName)
+		}
+	}
+
 	return opts, nil
 }
 
+// equal reports whether the options are the same as the other ones.
+//
+// The trace ID generator functions are compared by identity: passing
+// the same function to different instrumentations is fine, passing two
+// different ones isn't, even if they happen to do the same thing.
+func (i *options) equal(other *options) bool {
+	if i.Propagator != other.Propagator ||
+		i.Sampling != other.Sampling ||
+		i.Parent != other.Parent ||
+		i.TraceIDGenerator != other.TraceIDGenerator ||
+		!equalStringMaps(i.Baggage, other.Baggage) ||
+		!equalStringMaps(i.TraceState, other.TraceState) {
+		return false
+	}
+
+	if i.Exporter == nil || other.Exporter == nil {
+		if i.Exporter != other.Exporter {
+			return false
+		}
+	} else if i.Exporter.Endpoint != other.Exporter.Endpoint ||
+		!equalStringMaps(i.Exporter.Headers, other.Exporter.Headers) {
+		return false
+	}
+
+	if i.traceIDGeneratorValue == nil || other.traceIDGeneratorValue == nil {
+		return i.traceIDGeneratorValue == nil && other.traceIDGeneratorValue == nil
+	}
+
+	return i.traceIDGeneratorValue.SameAs(other.traceIDGeneratorValue)
+}
+
+// equalStringMaps reports whether both maps hold the same entries,
+// a nil map being the same as an empty one.
+func equalStringMaps(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (i *options) validate() error {
 	var (
 		isW3C    = i.Propagator == W3CPropagatorName
@@ -58,9 +165,36 @@ func (i *options) validate() error {
 		return errors.New("sampling rate must be between 0.0 and 1.0")
 	}
 
-	// TODO: implement baggage support
-	if i.Baggage != nil {
-		return errors.New("baggage is not yet supported")
+	if i.TraceState != nil {
+		if !isW3C {
+			return fmt.Errorf("tracestate is only supported by the %s propagator", W3CPropagatorName)
+		}
+
+		if _, err := newTraceState(i.TraceState); err != nil {
+			return err
+		}
+	}
+
+	switch i.TraceIDGenerator {
+	case "", K6TraceIDGeneratorName, Random128TraceIDGeneratorName, W3CRandomTraceIDGeneratorName:
+	default:
+		return fmt.Errorf("unknown trace ID generator: %s", i.TraceIDGenerator)
+	}
+
+	if i.Parent != "" {
+		if _, err := parseTraceParent(i.Parent); err != nil {
+			return err
+		}
+	}
+
+	if i.Exporter != nil {
+		if err := i.Exporter.validate(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := newBaggage(i.Baggage); err != nil {
+		return fmt.Errorf("invalid baggage: %w", err)
 	}
 
 	return nil
@@ -63,6 +63,72 @@ func TestNewOptionsWithSamplingPropertySet(t *testing.T) {
 	assert.Equal(t, 0.5, gotOptions.Sampling)
 }
 
+func TestNewOptionsWithTraceStatePropertySet(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestSetup(t)
+
+	_, err := ts.TestRuntime.VU.Runtime().RunString(`
+		const options = {
+			propagator: 'w3c',
+			tracestate: { congo: 't61rcWkgMzE' },
+		}
+	`)
+	require.NoError(t, err)
+	optionsValue := ts.TestRuntime.VU.Runtime().Get("options")
+	gotOptions, gotOptionsErr := newOptions(ts.TestRuntime.VU.Runtime(), optionsValue)
+
+	assert.NoError(t, gotOptionsErr)
+	assert.Equal(t, map[string]string{"congo": "t61rcWkgMzE"}, gotOptions.TraceState)
+}
+
+func TestNewOptionsWithTraceIDGeneratorProperty(t *testing.T) {
+	t.Parallel()
+
+	t.Run("strategy name", func(t *testing.T) {
+		t.Parallel()
+
+		ts := newTestSetup(t)
+		rt := ts.TestRuntime.VU.Runtime()
+
+		optionsValue, err := rt.RunString(`({ propagator: 'w3c', traceIdGenerator: 'random128' })`)
+		require.NoError(t, err)
+		gotOptions, gotOptionsErr := newOptions(rt, optionsValue)
+
+		assert.NoError(t, gotOptionsErr)
+		assert.Equal(t, Random128TraceIDGeneratorName, gotOptions.TraceIDGenerator)
+		assert.Nil(t, gotOptions.traceIDGeneratorFunc)
+	})
+
+	t.Run("function", func(t *testing.T) {
+		t.Parallel()
+
+		ts := newTestSetup(t)
+		rt := ts.TestRuntime.VU.Runtime()
+
+		optionsValue, err := rt.RunString(`({ propagator: 'w3c', traceIdGenerator: () => 'abc' })`)
+		require.NoError(t, err)
+		gotOptions, gotOptionsErr := newOptions(rt, optionsValue)
+
+		assert.NoError(t, gotOptionsErr)
+		assert.Empty(t, gotOptions.TraceIDGenerator)
+		assert.NotNil(t, gotOptions.traceIDGeneratorFunc)
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		t.Parallel()
+
+		ts := newTestSetup(t)
+		rt := ts.TestRuntime.VU.Runtime()
+
+		optionsValue, err := rt.RunString(`({ propagator: 'w3c', traceIdGenerator: 42 })`)
+		require.NoError(t, err)
+		_, gotOptionsErr := newOptions(rt, optionsValue)
+
+		assert.Error(t, gotOptionsErr)
+	})
+}
+
 func TestOptionsValidate(t *testing.T) {
 	t.Parallel()
 
@@ -77,9 +143,12 @@ func TestOptionsValidate(t *testing.T) {
 	)
 
 	type fields struct {
-		Propagator string
-		Sampling   float64
-		Baggage    map[string]string
+		Propagator       string
+		Sampling         float64
+		Baggage          map[string]string
+		TraceState       map[string]string
+		TraceIDGenerator string
+		Parent           string
 	}
 	testCases := []struct {
 		name    string
@@ -148,11 +217,75 @@ func TestOptionsValidate(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name: "baggage is not yet supported",
+			name: "tracestate with w3c propagator is valid",
+			fields: fields{
+				Propagator: "w3c",
+				TraceState: map[string]string{"vendor": "value"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "tracestate with jaeger propagator is invalid",
+			fields: fields{
+				Propagator: "jaeger",
+				TraceState: map[string]string{"vendor": "value"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "tracestate with invalid entries is invalid",
+			fields: fields{
+				Propagator: "w3c",
+				TraceState: map[string]string{"Vendor": "value"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "known trace ID generator is valid",
+			fields: fields{
+				Propagator:       "w3c",
+				TraceIDGenerator: "w3c-random",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown trace ID generator is invalid",
+			fields: fields{
+				Propagator:       "w3c",
+				TraceIDGenerator: "unknown",
+			},
+			wantErr: true,
+		},
+		{
+			name: "baggage is valid",
 			fields: fields{
 				Propagator: "w3c",
 				Baggage:    map[string]string{"key": "value"},
 			},
+			wantErr: false,
+		},
+		{
+			name: "baggage with invalid key is invalid",
+			fields: fields{
+				Propagator: "w3c",
+				Baggage:    map[string]string{"invalid key": "value"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid parent is valid",
+			fields: fields{
+				Propagator: "w3c",
+				Parent:     "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid parent is invalid",
+			fields: fields{
+				Propagator: "w3c",
+				Parent:     "invalid",
+			},
 			wantErr: true,
 		},
 	}
@@ -164,9 +297,12 @@ func TestOptionsValidate(t *testing.T) {
 			t.Parallel()
 
 			i := &options{
-				Propagator: tc.fields.Propagator,
-				Sampling:   tc.fields.Sampling,
-				Baggage:    tc.fields.Baggage,
+				Propagator:       tc.fields.Propagator,
+				Sampling:         tc.fields.Sampling,
+				Baggage:          tc.fields.Baggage,
+				TraceState:       tc.fields.TraceState,
+				TraceIDGenerator: tc.fields.TraceIDGenerator,
+				Parent:           tc.fields.Parent,
 			}
 
 			if err := i.validate(); (err != nil) != tc.wantErr {
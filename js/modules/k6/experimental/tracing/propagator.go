@@ -1,12 +1,15 @@
 package tracing
 
 import (
+	"fmt"
 	"net/http"
 )
 
 // Propagator is an interface for trace context propagation
 type Propagator interface {
-	Propagate(traceID string) (http.Header, error)
+	// Propagate returns the trace context headers identifying the
+	// span with ID `spanID`, as part of the trace with ID `traceID`.
+	Propagate(traceID, spanID string) (http.Header, error)
 }
 
 const (
@@ -25,12 +28,28 @@ const (
 
 	// W3CSampledTraceFlag is the trace-flag value for a sampled trace.
 	W3CSampledTraceFlag = "01"
+
+	// W3CUnsampledRandomTraceFlag is the trace-flag value for an unsampled
+	// trace, whose trace ID is random.
+	W3CUnsampledRandomTraceFlag = "02"
+
+	// W3CSampledRandomTraceFlag is the trace-flag value for a sampled
+	// trace, whose trace ID is random.
+	W3CSampledRandomTraceFlag = "03"
 )
 
 // W3CPropagator is a Propagator for the W3C trace context header
 type W3CPropagator struct {
 	// Sampler is used to determine whether or not a trace should be sampled.
 	Sampler
+
+	// TraceState holds the value of the tracestate header to propagate
+	// alongside the traceparent one. It is omitted when empty.
+	TraceState string
+
+	// RandomTraceID indicates whether the propagated trace IDs are
+	// random, in which case the random trace-flag is set.
+	RandomTraceID bool
 }
 
 // NewW3CPropagator returns a new W3CPropagator using the provided sampler
@@ -45,16 +64,26 @@ func NewW3CPropagator(s Sampler) *W3CPropagator {
 	}
 }
 
-// Propagate returns a header with a random trace ID in the W3C format
-func (p *W3CPropagator) Propagate(traceID string) (http.Header, error) {
-	parentID := randHexString(16)
-	flags := pick(p.ShouldSample(), W3CSampledTraceFlag, W3CUnsampledTraceFlag)
+// Propagate returns a header with the given trace and span IDs in the W3C format
+func (p *W3CPropagator) Propagate(traceID, spanID string) (http.Header, error) {
+	sampled := p.ShouldSample()
 
-	return http.Header{
+	flags := pick(sampled, W3CSampledTraceFlag, W3CUnsampledTraceFlag)
+	if p.RandomTraceID {
+		flags = pick(sampled, W3CSampledRandomTraceFlag, W3CUnsampledRandomTraceFlag)
+	}
+
+	header := http.Header{
 		W3CHeaderName: {
-			W3CVersion + "-" + traceID + "-" + parentID + "-" + flags,
+			W3CVersion + "-" + traceID + "-" + spanID + "-" + flags,
 		},
-	}, nil
+	}
+
+	if p.TraceState != "" {
+		header[W3CTraceStateHeaderName] = []string{p.TraceState}
+	}
+
+	return header, nil
 }
 
 const (
@@ -89,9 +118,8 @@ func NewJaegerPropagator(s Sampler) *JaegerPropagator {
 	}
 }
 
-// Propagate returns a header with a random trace ID in the Jaeger format
-func (p *JaegerPropagator) Propagate(traceID string) (http.Header, error) {
-	spanID := randHexString(8)
+// Propagate returns a header with the given trace and span IDs in the Jaeger format
+func (p *JaegerPropagator) Propagate(traceID, spanID string) (http.Header, error) {
 	flags := pick(p.ShouldSample(), JaegerSampledTraceFlag, JaegerUnsampledTraceFlag)
 
 	return http.Header{
@@ -99,6 +127,30 @@ func (p *JaegerPropagator) Propagate(traceID string) (http.Header, error) {
 	}, nil
 }
 
+// newPropagator returns the propagator with the given name, using the
+// provided sampler to base its sampling decisions upon.
+//
+// The propagator is further configured using the relevant client options,
+// such as the tracestate entries, which only apply to the W3C propagator.
+func newPropagator(name string, sampler Sampler, opts options) (Propagator, error) {
+	switch name {
+	case W3CPropagatorName:
+		traceState, err := newTraceState(opts.TraceState)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tracestate: %w", err)
+		}
+
+		propagator := NewW3CPropagator(sampler)
+		propagator.TraceState = traceState
+		propagator.RandomTraceID = opts.TraceIDGenerator == W3CRandomTraceIDGeneratorName
+		return propagator, nil
+	case JaegerPropagatorName:
+		return NewJaegerPropagator(sampler), nil
+	default:
+		return nil, fmt.Errorf("unknown propagator: %s", name)
+	}
+}
+
 // Pick returns either the left or right value, depending on the value of the `decision`
 // boolean value.
 func pick[T any](decision bool, lhs, rhs T) T {
@@ -12,6 +12,7 @@ func TestPropagate(t *testing.T) {
 	t.Parallel()
 
 	traceID := "abc123"
+	spanID := newSpanID()
 
 	t.Run("W3C Propagator", func(t *testing.T) {
 		t.Parallel()
@@ -19,14 +20,14 @@ func TestPropagate(t *testing.T) {
 		sampler := mockSampler{decision: true}
 		propagator := NewW3CPropagator(sampler)
 
-		gotHeader, gotErr := propagator.Propagate(traceID)
+		gotHeader, gotErr := propagator.Propagate(traceID, spanID)
 
 		assert.NoError(t, gotErr)
 		assert.Contains(t, gotHeader, W3CHeaderName)
 
 		//nolint:staticcheck // as traceparent is not a canonical header
 		headerContent := gotHeader[W3CHeaderName][0]
-		assert.True(t, strings.HasPrefix(headerContent, W3CVersion+"-"+traceID+"-"))
+		assert.Equal(t, W3CVersion+"-"+traceID+"-"+spanID+"-01", headerContent)
 	})
 
 	t.Run("W3C propagator with sampled trace", func(t *testing.T) {
@@ -35,7 +36,7 @@ func TestPropagate(t *testing.T) {
 		sampler := mockSampler{decision: true}
 		propagator := NewW3CPropagator(sampler)
 
-		gotHeader, gotErr := propagator.Propagate(traceID)
+		gotHeader, gotErr := propagator.Propagate(traceID, spanID)
 		require.NoError(t, gotErr)
 		require.Contains(t, gotHeader, W3CHeaderName)
 
@@ -49,7 +50,7 @@ func TestPropagate(t *testing.T) {
 		sampler := mockSampler{decision: false}
 		propagator := NewW3CPropagator(sampler)
 
-		gotHeader, gotErr := propagator.Propagate(traceID)
+		gotHeader, gotErr := propagator.Propagate(traceID, spanID)
 		require.NoError(t, gotErr)
 		require.Contains(t, gotHeader, W3CHeaderName)
 
@@ -57,13 +58,55 @@ func TestPropagate(t *testing.T) {
 		assert.True(t, strings.HasSuffix(gotHeader[W3CHeaderName][0], "-00"))
 	})
 
+	t.Run("W3C propagator with random trace ID", func(t *testing.T) {
+		t.Parallel()
+
+		sampler := mockSampler{decision: true}
+		propagator := NewW3CPropagator(sampler)
+		propagator.RandomTraceID = true
+
+		gotHeader, gotErr := propagator.Propagate(traceID, spanID)
+		require.NoError(t, gotErr)
+		require.Contains(t, gotHeader, W3CHeaderName)
+
+		//nolint:staticcheck // as traceparent is not a canonical header
+		assert.True(t, strings.HasSuffix(gotHeader[W3CHeaderName][0], "-"+W3CSampledRandomTraceFlag))
+	})
+
+	t.Run("W3C propagator with trace state", func(t *testing.T) {
+		t.Parallel()
+
+		sampler := mockSampler{decision: true}
+		propagator := NewW3CPropagator(sampler)
+		propagator.TraceState = "congo=t61rcWkgMzE"
+
+		gotHeader, gotErr := propagator.Propagate(traceID, spanID)
+		require.NoError(t, gotErr)
+		require.Contains(t, gotHeader, W3CHeaderName)
+		require.Contains(t, gotHeader, W3CTraceStateHeaderName)
+
+		//nolint:staticcheck // as tracestate is not a canonical header
+		assert.Equal(t, []string{"congo=t61rcWkgMzE"}, gotHeader[W3CTraceStateHeaderName])
+	})
+
+	t.Run("W3C propagator without trace state", func(t *testing.T) {
+		t.Parallel()
+
+		sampler := mockSampler{decision: true}
+		propagator := NewW3CPropagator(sampler)
+
+		gotHeader, gotErr := propagator.Propagate(traceID, spanID)
+		require.NoError(t, gotErr)
+		assert.NotContains(t, gotHeader, W3CTraceStateHeaderName)
+	})
+
 	t.Run("Jaeger Propagator", func(t *testing.T) {
 		t.Parallel()
 
 		sampler := mockSampler{decision: true}
 		propagator := NewJaegerPropagator(sampler)
 
-		gotHeader, gotErr := propagator.Propagate(traceID)
+		gotHeader, gotErr := propagator.Propagate(traceID, spanID)
 
 		assert.NoError(t, gotErr)
 		assert.Contains(t, gotHeader, JaegerHeaderName)
@@ -72,6 +115,10 @@ func TestPropagate(t *testing.T) {
 		headerContent := gotHeader[JaegerHeaderName][0]
 		assert.True(t, strings.HasPrefix(headerContent, traceID+":"))
 		assert.True(t, strings.HasSuffix(headerContent, ":0:1"))
+
+		parts := strings.Split(headerContent, ":")
+		require.Len(t, parts, 4)
+		assert.Equal(t, spanID, parts[1])
 	})
 
 	t.Run("Jaeger propagator with sampled trace", func(t *testing.T) {
@@ -80,7 +127,7 @@ func TestPropagate(t *testing.T) {
 		sampler := mockSampler{decision: true}
 		propagator := NewJaegerPropagator(sampler)
 
-		gotHeader, gotErr := propagator.Propagate(traceID)
+		gotHeader, gotErr := propagator.Propagate(traceID, spanID)
 		require.NoError(t, gotErr)
 		require.Contains(t, gotHeader, JaegerHeaderName)
 
@@ -94,7 +141,7 @@ func TestPropagate(t *testing.T) {
 		sampler := mockSampler{decision: false}
 		propagator := NewJaegerPropagator(sampler)
 
-		gotHeader, gotErr := propagator.Propagate(traceID)
+		gotHeader, gotErr := propagator.Propagate(traceID, spanID)
 		require.NoError(t, gotErr)
 		require.Contains(t, gotHeader, JaegerHeaderName)
 
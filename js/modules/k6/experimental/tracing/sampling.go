@@ -2,6 +2,7 @@ package tracing
 
 import (
 	"math/rand"
+	"time"
 )
 
 // Sampler is an interface defining a sampling strategy.
@@ -11,6 +12,24 @@ type Sampler interface {
 	ShouldSample() bool
 }
 
+// newSampler returns the sampler matching the given sampling rate.
+//
+// When an external parent trace context is continued, its sampling
+// decision takes precedence over the sampling rate, so that the
+// parent trace is either fully sampled, or not at all.
+func newSampler(samplingRate float64, parent *traceParent) Sampler {
+	switch {
+	case parent != nil && parent.Sampled:
+		return NewAlwaysOnSampler()
+	case parent != nil:
+		return NewProbabilisticSampler(0.0)
+	case samplingRate != 1.0:
+		return NewProbabilisticSampler(samplingRate)
+	default:
+		return NewAlwaysOnSampler()
+	}
+}
+
 // ProbabilisticSampler implements the ProbabilisticSampler interface and allows
 // to take probabilistic sampling decisions based on a sampling rate.
 type ProbabilisticSampler struct {
@@ -35,7 +54,11 @@ func NewProbabilisticSampler(samplingRate float64) *ProbabilisticSampler {
 		samplingRate = 1.0
 	}
 
-	return &ProbabilisticSampler{samplingRate: samplingRate}
+	return &ProbabilisticSampler{
+		//nolint:gosec // we don't need cryptographic randomness here
+		random:       rand.New(rand.NewSource(time.Now().UTC().UnixNano())),
+		samplingRate: samplingRate,
+	}
 }
 
 // ShouldSample returns true if the trace should be sampled.
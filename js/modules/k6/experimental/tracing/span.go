@@ -0,0 +1,266 @@
+package tracing
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/event"
+	"go.k6.io/k6/js/common"
+)
+
+// spanIDSize is the size, in hexadecimal characters, of a span ID.
+// Both the W3C and Jaeger specifications define span IDs as 64 bits long.
+const spanIDSize = 16
+
+// newSpanID generates a new random hexadecimal-encoded span ID.
+func newSpanID() string {
+	return randHexString(spanIDSize)
+}
+
+// Span represents a single unit of work within a trace, as started
+// from a k6 script using the `startSpan` function.
+//
+// While a span is active, the requests performed by the tracing client
+// are attached to its trace, and declare it as their parent span.
+type Span struct {
+	client *Client
+
+	// Name is the name of the span.
+	Name string `js:"name"`
+
+	// TraceID is the hexadecimal-encoded ID of the trace the span is part of.
+	TraceID string `js:"traceId"`
+
+	// SpanID is the hexadecimal-encoded ID of the span.
+	SpanID string `js:"spanId"`
+
+	// ParentSpanID is the hexadecimal-encoded ID of the span's parent
+	// span. It is empty for root spans, unless the client continues an
+	// external parent trace, in which case it holds the parent's span ID.
+	ParentSpanID string `js:"parentSpanId"`
+
+	// root holds whether the span is the outermost span
+	// started by the script within its trace.
+	root bool
+
+	startTime  time.Time
+	endTime    time.Time
+	attributes map[string]interface{}
+	ended      bool
+}
+
+// spanOptions are the options that can be passed to the
+// startSpan() method.
+type spanOptions struct {
+	// Attributes are the attributes the span is started with.
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// StartSpan starts a new span with the given name.
+//
+// If another span is currently active, the new span is started as its child,
+// and is part of the same trace. Otherwise, a new trace is started, or the
+// client's external parent trace is continued, if any.
+func (c *Client) StartSpan(name string, opts goja.Value) (*Span, error) {
+	if c.vu.State() == nil {
+		return nil, common.NewInitContextError("spans can only be started in the VU context")
+	}
+
+	if name == "" {
+		return nil, errors.New("span name must not be empty")
+	}
+
+	var so spanOptions
+	if !common.IsNullish(opts) {
+		if err := c.vu.Runtime().ExportTo(opts, &so); err != nil {
+			return nil, fmt.Errorf("unable to parse span options; reason: %w", err)
+		}
+	}
+
+	span := &Span{
+		client:     c,
+		Name:       name,
+		SpanID:     newSpanID(),
+		startTime:  time.Now(),
+		attributes: make(map[string]interface{}, len(so.Attributes)),
+	}
+
+	for key, value := range so.Attributes {
+		span.attributes[key] = value
+	}
+
+	// the trace ID is generated without holding the lock,
+	// since its generator can be a function of the script
+	if parent := c.currentSpan(); parent != nil {
+		span.TraceID = parent.TraceID
+		span.ParentSpanID = parent.SpanID
+	} else {
+		traceID, err := c.newTraceID()
+		if err != nil {
+			return nil, err
+		}
+		span.TraceID = traceID
+		span.root = true
+
+		if c.parent != nil {
+			span.ParentSpanID = c.parent.SpanID
+		}
+	}
+
+	c.spansMx.Lock()
+	c.spans = append(c.spans, span)
+	c.spansMx.Unlock()
+	c.subscribeToEvents()
+
+	return span, nil
+}
+
+// SetAttribute sets the attribute with the given key on the span.
+func (s *Span) SetAttribute(key string, value goja.Value) error {
+	s.client.spansMx.Lock()
+	defer s.client.spansMx.Unlock()
+
+	if s.ended {
+		return fmt.Errorf("unable to set attribute %q on span %q; reason: span has already ended", key, s.Name)
+	}
+
+	s.attributes[key] = value.Export()
+
+	return nil
+}
+
+// End marks the span as ended.
+//
+// When the root span of a trace ends, the trace's ended spans are exported.
+// Ending a span more than once has no effect.
+func (s *Span) End() {
+	s.client.spansMx.Lock()
+	defer s.client.spansMx.Unlock()
+
+	s.end()
+}
+
+// end ends the span, the client's lock has to be held.
+func (s *Span) end() {
+	if s.ended {
+		return
+	}
+
+	s.ended = true
+	s.endTime = time.Now()
+
+	s.client.endSpan(s)
+}
+
+// currentSpan returns the innermost span that has not ended yet, or nil
+// if there isn't any.
+func (c *Client) currentSpan() *Span {
+	c.spansMx.Lock()
+	defer c.spansMx.Unlock()
+
+	return c.activeSpan()
+}
+
+// activeSpan is like currentSpan, but the client's lock has to be held.
+func (c *Client) activeSpan() *Span {
+	if len(c.spans) == 0 {
+		return nil
+	}
+
+	return c.spans[len(c.spans)-1]
+}
+
+// endSpan removes the span from the client's active spans and stores it
+// until its trace can be exported. The client's lock has to be held.
+func (c *Client) endSpan(span *Span) {
+	for i := len(c.spans) - 1; i >= 0; i-- {
+		if c.spans[i] == span {
+			c.spans = append(c.spans[:i], c.spans[i+1:]...)
+			break
+		}
+	}
+
+	c.endedSpans = append(c.endedSpans, span)
+
+	if !span.root {
+		return
+	}
+
+	spans := c.endedSpans
+	c.endedSpans = nil
+
+	if c.exporter == nil {
+		return
+	}
+
+	if c.exports == nil {
+		c.exports = newExportQueue(c.exportCtx, c.vu.State().Logger)
+	}
+	c.exports.push(exportJob{exporter: c.exporter, spans: spans})
+}
+
+// subscribeToEvents ends the spans left active by the script at the end of
+// each iteration, so they don't leak into the next ones, and it waits for the
+// queued traces to be exported on the Exit event.
+func (c *Client) subscribeToEvents() {
+	if c.subscribedToEvents {
+		return
+	}
+	c.subscribedToEvents = true
+
+	events := c.vu.Events()
+	if events.Global == nil || events.Local == nil {
+		return
+	}
+	globalSID, globalCh := events.Global.Subscribe(event.Exit)
+	localSID, localCh := events.Local.Subscribe(event.IterEnd)
+	go func() {
+		defer func() {
+			events.Global.Unsubscribe(globalSID)
+			events.Local.Unsubscribe(localSID)
+		}()
+		for {
+			select {
+			case evt, ok := <-globalCh:
+				if !ok {
+					return
+				}
+				c.closeExports()
+				evt.Done()
+				return
+			case evt, ok := <-localCh:
+				if !ok {
+					return
+				}
+				c.endIterationSpans()
+				evt.Done()
+			}
+		}
+	}()
+}
+
+// endIterationSpans ends the active spans, the innermost first, so the traces
+// whose root span is ended are exported. The ended spans of the traces whose
+// root span has already ended are dropped.
+func (c *Client) endIterationSpans() {
+	c.spansMx.Lock()
+	defer c.spansMx.Unlock()
+
+	for span := c.activeSpan(); span != nil; span = c.activeSpan() {
+		span.end()
+	}
+	c.endedSpans = nil
+}
+
+// closeExports waits for the queued traces to be exported.
+func (c *Client) closeExports() {
+	c.spansMx.Lock()
+	exports := c.exports
+	c.spansMx.Unlock()
+
+	if exports != nil {
+		exports.close()
+	}
+}
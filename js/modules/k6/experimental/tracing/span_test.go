@@ -0,0 +1,184 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/event"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/httpmultibin"
+	"go.k6.io/k6/metrics"
+)
+
+func TestStartSpanFailsBeforeInstrumentHTTP(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestSetup(t)
+	ts.TestRuntime.MoveToVUContext(&lib.State{})
+
+	_, err := ts.TestRuntime.VU.Runtime().RunString(`
+		require('k6/experimental/tracing').startSpan('journey')
+	`)
+
+	assert.Error(t, err)
+}
+
+func TestStartSpanFailsInInitContext(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestSetup(t)
+
+	_, err := ts.TestRuntime.VU.Runtime().RunString(`
+		instrumentHTTP({propagator: 'w3c'})
+		require('k6/experimental/tracing').startSpan('journey')
+	`)
+
+	assert.Error(t, err)
+}
+
+func TestSpansAreAttachedToRequestsAndExported(t *testing.T) {
+	t.Parallel()
+
+	exported := make(chan otlpTracesPayload, 1)
+	exporterServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+
+		var payload otlpTracesPayload
+		assert.NoError(t, json.Unmarshal(body, &payload))
+		exported <- payload
+	}))
+	t.Cleanup(exporterServer.Close)
+
+	ts := newTestSetup(t)
+	rt := ts.TestRuntime.VU.Runtime()
+
+	require.NoError(t, rt.Set("EXPORTER_URL", exporterServer.URL))
+	_, err := rt.RunString(`
+		let http = require('k6/http')
+		let tracing = require('k6/experimental/tracing')
+		instrumentHTTP({
+			propagator: 'w3c',
+			exporter: { endpoint: EXPORTER_URL, headers: { 'X-Api-Key': 'secret' } },
+		})
+	`)
+	require.NoError(t, err)
+
+	httpBin := httpmultibin.NewHTTPMultiBin(t)
+	ts.TestRuntime.MoveToVUContext(&lib.State{
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(ts.TestRuntime.VU.InitEnvField.Registry),
+		Tags:           lib.NewVUStateTags(ts.TestRuntime.VU.InitEnvField.Registry.RootTagSet()),
+		Transport:      httpBin.HTTPTransport,
+		BufferPool:     lib.NewBufferPool(),
+		Samples:        make(chan metrics.SampleContainer, 1000),
+		Options:        lib.Options{SystemTags: &metrics.DefaultSystemTagSet},
+		Logger:         ts.TestRuntime.VU.InitEnvField.Logger,
+	})
+
+	_, err = ts.TestRuntime.RunOnEventLoop(httpBin.Replacer.Replace(`
+		const root = tracing.startSpan('journey', { attributes: { user: 'alice' } })
+		const child = tracing.startSpan('login')
+		child.setAttribute('attempt', 1)
+
+		if (child.traceId !== root.traceId || child.parentSpanId !== root.spanId) {
+			throw new Error('child span is not attached to its parent')
+		}
+
+		const res = http.get('HTTPBIN_URL/headers')
+		const traceparent = res.json().headers['Traceparent'][0]
+		if (traceparent !== '00-' + child.traceId + '-' + child.spanId + '-01') {
+			throw new Error('unexpected traceparent header: ' + traceparent)
+		}
+
+		child.end()
+		root.end()
+	`))
+	require.NoError(t, err)
+
+	payload := <-exported
+	require.Len(t, payload.ResourceSpans, 1)
+	require.Len(t, payload.ResourceSpans[0].ScopeSpans, 1)
+
+	spans := payload.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+	assert.Equal(t, "login", spans[0].Name)
+	assert.Equal(t, "journey", spans[1].Name)
+	assert.Equal(t, spans[1].SpanID, spans[0].ParentSpanID)
+	assert.Empty(t, spans[1].ParentSpanID)
+	assert.Equal(t, spans[0].TraceID, spans[1].TraceID)
+
+	require.Len(t, spans[0].Attributes, 1)
+	assert.Equal(t, "attempt", spans[0].Attributes[0].Key)
+	require.NotNil(t, spans[0].Attributes[0].Value.IntValue)
+	assert.Equal(t, "1", *spans[0].Attributes[0].Value.IntValue)
+}
+
+func TestSpansAreEndedAtIterationEnd(t *testing.T) {
+	t.Parallel()
+
+	exported := make(chan otlpTracesPayload, 1)
+	exporterServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload otlpTracesPayload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		exported <- payload
+	}))
+	t.Cleanup(exporterServer.Close)
+
+	ts := newTestSetup(t)
+	rt := ts.TestRuntime.VU.Runtime()
+	logger := ts.TestRuntime.VU.InitEnvField.Logger
+	global, local := event.NewEventSystem(10, logger), event.NewEventSystem(10, logger)
+	ts.TestRuntime.VU.EventsField = common.Events{Global: global, Local: local}
+
+	require.NoError(t, rt.Set("EXPORTER_URL", exporterServer.URL))
+	_, err := rt.RunString(`
+		let tracing = require('k6/experimental/tracing')
+		instrumentHTTP({ propagator: 'w3c', exporter: { endpoint: EXPORTER_URL } })
+	`)
+	require.NoError(t, err)
+
+	ts.TestRuntime.MoveToVUContext(&lib.State{Logger: logger})
+	_, err = rt.RunString(`
+		var root = tracing.startSpan('journey')
+		tracing.startSpan('login')
+	`)
+	require.NoError(t, err)
+
+	waitDone := local.Emit(&event.Event{Type: event.IterEnd, Data: event.IterData{}})
+	require.NoError(t, waitDone(context.Background()))
+
+	// the queued traces are exported before the Exit event is done
+	waitDone = global.Emit(&event.Event{Type: event.Exit, Data: &event.ExitData{}})
+	require.NoError(t, waitDone(context.Background()))
+
+	var payload otlpTracesPayload
+	select {
+	case payload = <-exported:
+	default:
+		require.Fail(t, "the spans weren't exported before the end of the Exit event")
+	}
+	require.Len(t, payload.ResourceSpans, 1)
+	require.Len(t, payload.ResourceSpans[0].ScopeSpans, 1)
+	spans := payload.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+	assert.Equal(t, "login", spans[0].Name)
+	assert.Equal(t, "journey", spans[1].Name)
+
+	// the spans of the previous iteration don't leak into the next one
+	_, err = rt.RunString(`
+		const span = tracing.startSpan('journey')
+		if (span.parentSpanId !== '' || span.traceId === root.traceId) {
+			throw new Error('the span is attached to the trace of the previous iteration')
+		}
+	`)
+	require.NoError(t, err)
+}
@@ -3,9 +3,13 @@ package tracing
 import (
 	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"time"
+
+	"github.com/dop251/goja"
 )
 
 const (
@@ -64,3 +68,82 @@ func newTraceID(prefix int16, code int8, t time.Time, randSource io.Reader) (str
 
 	return hex.EncodeToString(buf), nil
 }
+
+const (
+	// K6TraceIDGeneratorName is the name of the default trace ID generation
+	// strategy, producing trace IDs encoding the k6 prefix, code and time.
+	K6TraceIDGeneratorName = "k6"
+
+	// Random128TraceIDGeneratorName is the name of the trace ID generation
+	// strategy producing fully random 128 bits trace IDs.
+	Random128TraceIDGeneratorName = "random128"
+
+	// W3CRandomTraceIDGeneratorName is the name of the trace ID generation
+	// strategy producing fully random 128 bits trace IDs, and flagging them as
+	// such in the W3C traceparent header, as defined by the [W3C Trace Context Level 2].
+	//
+	// [W3C Trace Context Level 2]: https://www.w3.org/TR/trace-context-2/#random-trace-id-flag
+	W3CRandomTraceIDGeneratorName = "w3c-random"
+)
+
+// traceIDGenerator is a function producing hexadecimal-encoded trace IDs.
+type traceIDGenerator func() (string, error)
+
+// newRandomTraceID generates a new fully random hexadecimal-encoded trace ID,
+// using randSource as the source of randomness.
+func newRandomTraceID(randSource io.Reader) (string, error) {
+	buf := make([]byte, traceIDEncodedSize)
+	if _, err := io.ReadFull(randSource, buf); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes; reason: %w", err)
+	}
+
+	traceID := hex.EncodeToString(buf)
+	if err := validateTraceID(traceID); err != nil {
+		return "", err
+	}
+
+	return traceID, nil
+}
+
+// newCallbackTraceIDGenerator returns a traceIDGenerator delegating
+// the generation of trace IDs to a script-provided function.
+func newCallbackTraceIDGenerator(fn goja.Callable) traceIDGenerator {
+	return func() (string, error) {
+		value, err := fn(goja.Undefined())
+		if err != nil {
+			return "", fmt.Errorf("trace ID generator function failed; reason: %w", err)
+		}
+
+		traceID, ok := value.Export().(string)
+		if !ok {
+			return "", fmt.Errorf("trace ID generator function must return a string, got %v", value)
+		}
+
+		traceID = strings.ToLower(traceID)
+		if err := validateTraceID(traceID); err != nil {
+			return "", fmt.Errorf("trace ID generator function returned an invalid trace ID; reason: %w", err)
+		}
+
+		return traceID, nil
+	}
+}
+
+// validateTraceID ensures the given trace ID is a valid hexadecimal-encoded
+// 16 bytes trace ID, as defined by the [W3C specification].
+//
+// [W3C specification]: https://www.w3.org/TR/trace-context/#trace-id
+func validateTraceID(traceID string) error {
+	if len(traceID) != hex.EncodedLen(traceIDEncodedSize) {
+		return fmt.Errorf("trace ID %q must be %d hexadecimal characters long", traceID, hex.EncodedLen(traceIDEncodedSize))
+	}
+
+	if _, err := hex.DecodeString(traceID); err != nil {
+		return fmt.Errorf("trace ID %q is not a valid hexadecimal value", traceID)
+	}
+
+	if strings.Trim(traceID, "0") == "" {
+		return errors.New("trace ID must not be all zeros")
+	}
+
+	return nil
+}
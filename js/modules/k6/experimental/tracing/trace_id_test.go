@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/dop251/goja"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -96,3 +97,100 @@ func TestNewTraceID(t *testing.T) {
 		})
 	}
 }
+
+func TestNewRandomTraceID(t *testing.T) {
+	t.Parallel()
+
+	t.Run("random bytes produce a valid trace ID", func(t *testing.T) {
+		t.Parallel()
+
+		randSource := bytes.NewReader([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+
+		gotTraceID, gotErr := newRandomTraceID(randSource)
+
+		require.NoError(t, gotErr)
+		assert.Equal(t, "0102030405060708090a0b0c0d0e0f10", gotTraceID)
+	})
+
+	t.Run("not enough random bytes fails", func(t *testing.T) {
+		t.Parallel()
+
+		_, gotErr := newRandomTraceID(bytes.NewReader([]byte{1, 2, 3}))
+
+		assert.Error(t, gotErr)
+	})
+
+	t.Run("all zeros random bytes fails", func(t *testing.T) {
+		t.Parallel()
+
+		_, gotErr := newRandomTraceID(bytes.NewReader(make([]byte, traceIDEncodedSize)))
+
+		assert.Error(t, gotErr)
+	})
+}
+
+func TestNewCallbackTraceIDGenerator(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		fn          string
+		wantTraceID string
+		wantErr     bool
+	}{
+		{
+			name:        "valid trace ID is returned as-is",
+			fn:          `() => '4bf92f3577b34da6a3ce929d0e0e4736'`,
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name:        "uppercase trace ID is lowercased",
+			fn:          `() => '4BF92F3577B34DA6A3CE929D0E0E4736'`,
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name:    "non-string value fails",
+			fn:      `() => 42`,
+			wantErr: true,
+		},
+		{
+			name:    "too short trace ID fails",
+			fn:      `() => '4bf92f35'`,
+			wantErr: true,
+		},
+		{
+			name:    "non-hexadecimal trace ID fails",
+			fn:      `() => 'zzf92f3577b34da6a3ce929d0e0e4736'`,
+			wantErr: true,
+		},
+		{
+			name:    "throwing function fails",
+			fn:      `() => { throw new Error('oops') }`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rt := goja.New()
+			fnValue, err := rt.RunString(tc.fn)
+			require.NoError(t, err)
+			fn, ok := goja.AssertFunction(fnValue)
+			require.True(t, ok)
+
+			gotTraceID, gotErr := newCallbackTraceIDGenerator(fn)()
+
+			if tc.wantErr {
+				assert.Error(t, gotErr)
+				return
+			}
+
+			require.NoError(t, gotErr)
+			assert.Equal(t, tc.wantTraceID, gotTraceID)
+		})
+	}
+}
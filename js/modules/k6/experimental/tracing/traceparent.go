@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// traceParentInvalidVersion is the W3C traceparent version
+// value that is forbidden by the specification.
+const traceParentInvalidVersion = "ff"
+
+// traceParent is an externally-provided parent trace context, that
+// the client continues instead of starting new traces.
+type traceParent struct {
+	// TraceID is the hexadecimal-encoded ID of the parent trace.
+	TraceID string
+
+	// SpanID is the hexadecimal-encoded ID of the parent span.
+	SpanID string
+
+	// Sampled holds whether the parent trace was sampled.
+	Sampled bool
+}
+
+// parseTraceParent parses the value of a traceparent header,
+// as defined by the [W3C specification].
+//
+// [W3C specification]: https://www.w3.org/TR/trace-context/#traceparent-header
+func parseTraceParent(value string) (*traceParent, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return nil, fmt.Errorf("invalid traceparent %q, expected version-traceid-parentid-flags", value)
+	}
+
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+
+	// Future versions may append fields to the header, hence
+	// extra fields are only rejected for the current version.
+	if version == W3CVersion && len(parts) != 4 {
+		return nil, fmt.Errorf("invalid traceparent %q, expected version-traceid-parentid-flags", value)
+	}
+
+	if len(version) != 2 || !isLowerHex(version) || version == traceParentInvalidVersion {
+		return nil, fmt.Errorf("invalid traceparent version %q", version)
+	}
+
+	if !isLowerHex(traceID) {
+		return nil, fmt.Errorf("invalid traceparent trace ID %q, expected lowercase hexadecimal characters", traceID)
+	}
+
+	if err := validateTraceID(traceID); err != nil {
+		return nil, fmt.Errorf("invalid traceparent trace ID; reason: %w", err)
+	}
+
+	if len(spanID) != spanIDSize || !isLowerHex(spanID) {
+		return nil, fmt.Errorf("invalid traceparent parent ID %q, expected %d lowercase hexadecimal characters",
+			spanID, spanIDSize)
+	}
+
+	if spanID == strings.Repeat("0", spanIDSize) {
+		return nil, errors.New("invalid traceparent parent ID, it must not be all zeros")
+	}
+
+	if len(flags) != 2 || !isLowerHex(flags) {
+		return nil, fmt.Errorf("invalid traceparent trace flags %q", flags)
+	}
+
+	flagsValue, err := hex.DecodeString(flags)
+	if err != nil {
+		return nil, fmt.Errorf("invalid traceparent trace flags %q; reason: %w", flags, err)
+	}
+
+	return &traceParent{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Sampled: flagsValue[0]&0x01 == 0x01,
+	}, nil
+}
+
+// isLowerHex returns true if s only holds lowercase hexadecimal characters.
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,113 @@
+package tracing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		value   string
+		want    *traceParent
+		wantErr bool
+	}{
+		{
+			name:  "sampled traceparent is valid",
+			value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			want: &traceParent{
+				TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:  "00f067aa0ba902b7",
+				Sampled: true,
+			},
+		},
+		{
+			name:  "unsampled traceparent is valid",
+			value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			want: &traceParent{
+				TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:  "00f067aa0ba902b7",
+				Sampled: false,
+			},
+		},
+		{
+			name:  "random trace ID flag is supported",
+			value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-03",
+			want: &traceParent{
+				TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:  "00f067aa0ba902b7",
+				Sampled: true,
+			},
+		},
+		{
+			name:  "future versions can hold extra fields",
+			value: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			want: &traceParent{
+				TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanID:  "00f067aa0ba902b7",
+				Sampled: true,
+			},
+		},
+		{
+			name:    "current version cannot hold extra fields",
+			value:   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			wantErr: true,
+		},
+		{
+			name:    "missing fields are invalid",
+			value:   "00-4bf92f3577b34da6a3ce929d0e0e4736-01",
+			wantErr: true,
+		},
+		{
+			name:    "forbidden version is invalid",
+			value:   "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantErr: true,
+		},
+		{
+			name:    "uppercase trace ID is invalid",
+			value:   "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+			wantErr: true,
+		},
+		{
+			name:    "all zeros trace ID is invalid",
+			value:   "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			wantErr: true,
+		},
+		{
+			name:    "all zeros parent ID is invalid",
+			value:   "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			wantErr: true,
+		},
+		{
+			name:    "short parent ID is invalid",
+			value:   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa-01",
+			wantErr: true,
+		},
+		{
+			name:    "invalid flags are invalid",
+			value:   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-zz",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseTraceParent(tc.value)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
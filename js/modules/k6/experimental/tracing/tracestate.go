@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// W3CTraceStateHeaderName is the name of the W3C trace state header
+	W3CTraceStateHeaderName = "tracestate"
+
+	// maxTraceStateEntries is the maximum number of list-members
+	// a tracestate header is allowed to hold, as defined by
+	// the W3C trace context specification.
+	maxTraceStateEntries = 32
+
+	// maxTraceStateValueSize is the maximum size of a tracestate
+	// list-member's value.
+	maxTraceStateValueSize = 256
+)
+
+var (
+	// traceStateSimpleKeyRegexp matches tracestate keys in the
+	// `simple-key` format defined by the W3C specification.
+	traceStateSimpleKeyRegexp = regexp.MustCompile(`^[a-z][a-z0-9_\-*/]{0,255}$`)
+
+	// traceStateMultiTenantKeyRegexp matches tracestate keys in the
+	// `tenant-id@system-id` format defined by the W3C specification.
+	traceStateMultiTenantKeyRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_\-*/]{0,240}@[a-z][a-z0-9_\-*/]{0,13}$`)
+)
+
+// newTraceState produces the value of a tracestate header from the provided
+// vendor key/value entries, as defined by the [W3C specification].
+//
+// As Go maps are unordered, entries are sorted by key to ensure the produced
+// header is stable across requests.
+//
+// [W3C specification]: https://www.w3.org/TR/trace-context/#tracestate-header
+func newTraceState(entries map[string]string) (string, error) {
+	if len(entries) == 0 {
+		return "", nil
+	}
+
+	if len(entries) > maxTraceStateEntries {
+		return "", fmt.Errorf("tracestate can hold at most %d entries, got %d", maxTraceStateEntries, len(entries))
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	members := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := entries[key]
+
+		if err := validateTraceStateKey(key); err != nil {
+			return "", err
+		}
+
+		if err := validateTraceStateValue(value); err != nil {
+			return "", fmt.Errorf("invalid tracestate value for key %q; reason: %w", key, err)
+		}
+
+		members = append(members, key+"="+value)
+	}
+
+	return strings.Join(members, ","), nil
+}
+
+func validateTraceStateKey(key string) error {
+	if !traceStateSimpleKeyRegexp.MatchString(key) && !traceStateMultiTenantKeyRegexp.MatchString(key) {
+		return fmt.Errorf("invalid tracestate key %q", key)
+	}
+
+	return nil
+}
+
+func validateTraceStateValue(value string) error {
+	if len(value) == 0 || len(value) > maxTraceStateValueSize {
+		return fmt.Errorf("value must be between 1 and %d characters long", maxTraceStateValueSize)
+	}
+
+	for _, c := range value {
+		if c < 0x20 || c > 0x7e || c == ',' || c == '=' {
+			return fmt.Errorf("value contains forbidden character %q", c)
+		}
+	}
+
+	if strings.HasSuffix(value, " ") {
+		return errors.New("value cannot end with a space")
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTraceState(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		entries map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "no entries produce an empty value",
+			entries: nil,
+			want:    "",
+			wantErr: false,
+		},
+		{
+			name:    "entries are sorted by key",
+			entries: map[string]string{"rojo": "00f067aa0ba902b7", "congo": "t61rcWkgMzE"},
+			want:    "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7",
+			wantErr: false,
+		},
+		{
+			name:    "multi-tenant keys are valid",
+			entries: map[string]string{"tenant@vendor": "value"},
+			want:    "tenant@vendor=value",
+			wantErr: false,
+		},
+		{
+			name:    "uppercase keys are invalid",
+			entries: map[string]string{"Vendor": "value"},
+			wantErr: true,
+		},
+		{
+			name:    "empty values are invalid",
+			entries: map[string]string{"vendor": ""},
+			wantErr: true,
+		},
+		{
+			name:    "values containing a comma are invalid",
+			entries: map[string]string{"vendor": "a,b"},
+			wantErr: true,
+		},
+		{
+			name:    "values containing an equal sign are invalid",
+			entries: map[string]string{"vendor": "a=b"},
+			wantErr: true,
+		},
+		{
+			name:    "values ending with a space are invalid",
+			entries: map[string]string{"vendor": "value "},
+			wantErr: true,
+		},
+		{
+			name:    "values longer than 256 characters are invalid",
+			entries: map[string]string{"vendor": strings.Repeat("a", 257)},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, gotErr := newTraceState(tc.entries)
+
+			if tc.wantErr {
+				assert.Error(t, gotErr)
+				return
+			}
+
+			assert.NoError(t, gotErr)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
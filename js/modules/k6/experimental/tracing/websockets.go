@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+)
+
+// wsHeadersParamName is the name of the websockets modules' params
+// property holding the headers sent along with the handshake request.
+const wsHeadersParamName = "headers"
+
+// instrumentWSConnect returns an instrumented version of the k6/ws module's connect
+// function, whose handshake request carries the trace context headers.
+//
+// As the connect function only returns once the connection is closed, and as the
+// ws module captures the VU's metadata upon connecting, the samples emitted for
+// the whole duration of the connection hold the trace_id metadata.
+func (c *Client) instrumentWSConnect(connect goja.Callable) func(url goja.Value, args ...goja.Value) (goja.Value, error) {
+	return func(url goja.Value, args ...goja.Value) (goja.Value, error) {
+		// The connect function expects either a (params, callback)
+		// or a (callback) set of arguments.
+		var params, callback goja.Value
+		switch len(args) {
+		case 2:
+			params, callback = args[0], args[1]
+		case 1:
+			callback = args[0]
+		default:
+			return connect(goja.Undefined(), append([]goja.Value{url}, args...)...)
+		}
+
+		var result goja.Value
+		err := c.instrumentedParamsCall(params, wsHeadersParamName, func(params goja.Value) error {
+			var err error
+			result, err = connect(goja.Undefined(), url, params, callback)
+			return err
+		})
+
+		return result, err
+	}
+}
+
+// instrumentWebSocketConstructor returns an instrumented version of the
+// k6/experimental/websockets module's WebSocket constructor, whose handshake
+// request carries the trace context headers.
+func (c *Client) instrumentWebSocketConstructor(constructor goja.Value) func(goja.ConstructorCall) *goja.Object {
+	rt := c.vu.Runtime()
+
+	return func(cc goja.ConstructorCall) *goja.Object {
+		// The WebSocket constructor expects a (url, protocols, params)
+		// set of arguments, the last two being optional.
+		args := []goja.Value{cc.Argument(0), cc.Argument(1), cc.Argument(2)}
+
+		// Let the original constructor report its usage in the init context.
+		if c.vu.State() == nil {
+			ws, err := rt.New(constructor, args...)
+			if err != nil {
+				common.Throw(rt, err)
+			}
+			return ws
+		}
+
+		var ws *goja.Object
+		err := c.instrumentedParamsCall(args[2], wsHeadersParamName, func(params goja.Value) error {
+			var err error
+			ws, err = rt.New(constructor, args[0], args[1], params)
+			return err
+		})
+		if err != nil {
+			common.Throw(rt, err)
+		}
+
+		return ws
+	}
+}
@@ -0,0 +1,152 @@
+package tracing
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/js/compiler"
+	expws "go.k6.io/k6/js/modules/k6/experimental/websockets"
+	httpmodule "go.k6.io/k6/js/modules/k6/http"
+	"go.k6.io/k6/js/modules/k6/ws"
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/httpmultibin"
+	"go.k6.io/k6/metrics"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestInstrumentWebSockets(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ws.connect carries the trace context", func(t *testing.T) {
+		t.Parallel()
+
+		ts, httpBin, samples, headers := newWebSocketsTestSetup(t)
+
+		_, err := ts.VU.Runtime().RunString(httpBin.Replacer.Replace(`
+			const res = ws.connect('WSBIN_URL/ws-traced', { headers: { 'X-Custom': 'value' } }, function (socket) {
+				socket.close()
+			})
+			if (res.status !== 101) {
+				throw new Error('connection failed with status: ' + res.status)
+			}
+		`))
+		require.NoError(t, err)
+
+		gotHeaders := <-headers
+		assert.NotEmpty(t, gotHeaders.Get(W3CHeaderName))
+		assert.Equal(t, "value", gotHeaders.Get("X-Custom"))
+		assertWSConnectingHasTraceID(t, samples)
+	})
+
+	t.Run("ws.connect honors the tracing opt-out", func(t *testing.T) {
+		t.Parallel()
+
+		ts, httpBin, _, headers := newWebSocketsTestSetup(t)
+
+		_, err := ts.VU.Runtime().RunString(httpBin.Replacer.Replace(`
+			ws.connect('WSBIN_URL/ws-traced', { tracing: false }, function (socket) {
+				socket.close()
+			})
+		`))
+		require.NoError(t, err)
+
+		gotHeaders := <-headers
+		assert.Empty(t, gotHeaders.Get(W3CHeaderName))
+	})
+
+	t.Run("WebSocket carries the trace context", func(t *testing.T) {
+		t.Parallel()
+
+		ts, httpBin, samples, headers := newWebSocketsTestSetup(t)
+
+		_, err := ts.RunOnEventLoop(httpBin.Replacer.Replace(`
+			const socket = new WebSocket('WSBIN_URL/ws-traced')
+			socket.onopen = () => socket.close()
+		`))
+		require.NoError(t, err)
+
+		gotHeaders := <-headers
+		assert.NotEmpty(t, gotHeaders.Get(W3CHeaderName))
+		assertWSConnectingHasTraceID(t, samples)
+	})
+}
+
+func assertWSConnectingHasTraceID(t *testing.T, samples chan metrics.SampleContainer) {
+	t.Helper()
+
+	var found bool
+	for _, sampleContainer := range metrics.GetBufferedSamples(samples) {
+		for _, sample := range sampleContainer.GetSamples() {
+			if sample.Metric.Name != metrics.WSConnectingName {
+				continue
+			}
+
+			assert.NotEmpty(t, sample.Metadata[metadataTraceIDKeyName])
+			found = true
+		}
+	}
+
+	assert.True(t, found)
+}
+
+func newWebSocketsTestSetup(
+	t *testing.T,
+) (*modulestest.Runtime, *httpmultibin.HTTPMultiBin, chan metrics.SampleContainer, chan http.Header) {
+	ts := modulestest.NewRuntime(t)
+	httpBin := httpmultibin.NewHTTPMultiBin(t)
+
+	headers := make(chan http.Header, 1)
+	httpBin.Mux.HandleFunc("/ws-traced", func(w http.ResponseWriter, req *http.Request) {
+		headers <- req.Header.Clone()
+
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, req, w.Header())
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		// Wait for the client to close the connection.
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	})
+
+	err := ts.SetupModuleSystem(map[string]interface{}{
+		"k6/http":                    httpmodule.New(),
+		"k6/ws":                      ws.New(),
+		"k6/experimental/websockets": new(expws.RootModule),
+		"k6/experimental/tracing":    new(RootModule),
+	}, nil, compiler.New(ts.VU.InitEnvField.Logger))
+	require.NoError(t, err)
+
+	_, err = ts.VU.Runtime().RunString(`
+		var ws = require('k6/ws')
+		var WebSocket = require('k6/experimental/websockets').WebSocket
+		require('k6/experimental/tracing').instrumentWebSockets({propagator: 'w3c'})
+		WebSocket = require('k6/experimental/websockets').WebSocket
+	`)
+	require.NoError(t, err)
+
+	samples := make(chan metrics.SampleContainer, 1000)
+	registry := metrics.NewRegistry()
+	ts.MoveToVUContext(&lib.State{
+		Dialer:         httpBin.Dialer,
+		TLSConfig:      httpBin.TLSClientConfig,
+		Samples:        samples,
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+		Options: lib.Options{
+			SystemTags: &metrics.DefaultSystemTagSet,
+			UserAgent:  null.StringFrom("TestUserAgent"),
+		},
+		Logger: ts.VU.InitEnvField.Logger,
+	})
+
+	return ts, httpBin, samples, headers
+}
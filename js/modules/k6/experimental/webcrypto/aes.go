@@ -6,6 +6,8 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/dop251/goja"
 )
@@ -52,7 +54,7 @@ func newAesKeyGenParams(rt *goja.Runtime, normalized Algorithm, params goja.Valu
 func (akgp *AesKeyGenParams) GenerateKey(
 	extractable bool,
 	keyUsages []CryptoKeyUsage,
-) (*CryptoKey, error) {
+) (CryptoKeyGenerationResult, error) {
 	for _, usage := range keyUsages {
 		switch usage {
 		case WrapKeyCryptoKeyUsage, UnwrapKeyCryptoKeyUsage:
@@ -114,9 +116,7 @@ type AesKeyAlgorithm struct {
 	Length int64 `json:"length"`
 }
 
-// exportAESKey exports an AES key to its raw representation.
-//
-// TODO @oleiade: support JWK format.
+// exportAESKey exports an AES key to its raw or JSON Web Key representation.
 func exportAESKey(key *CryptoKey, format KeyFormat) ([]byte, error) {
 	if !key.Extractable {
 		return nil, NewError(0, InvalidAccessError, "the key is not extractable")
@@ -127,20 +127,36 @@ func exportAESKey(key *CryptoKey, format KeyFormat) ([]byte, error) {
 		return nil, NewError(0, OperationError, "the key is not valid, no data")
 	}
 
+	handle, ok := key.handle.([]byte)
+	if !ok {
+		return nil, NewError(0, ImplementationError, "exporting key data's bytes failed")
+	}
+
 	switch format {
 	case RawKeyFormat:
-		handle, ok := key.handle.([]byte)
+		return handle, nil
+	case JwkKeyFormat:
+		algorithm, ok := key.Algorithm.(AesKeyAlgorithm)
 		if !ok {
-			return nil, NewError(0, ImplementationError, "exporting key data's bytes failed")
+			return nil, NewError(0, ImplementationError, "key algorithm does not describe an AES key")
 		}
 
-		return handle, nil
+		return marshalJWK(&JSONWebKey{
+			Kty: "oct",
+			K:   encodeBase64URL(handle),
+			Alg: aesJWKAlgorithm(algorithm.Name, len(handle)),
+		}, key)
 	default:
-		// FIXME: note that we do not support JWK format, yet.
 		return nil, NewError(0, NotSupportedError, "unsupported key format "+format)
 	}
 }
 
+// aesJWKAlgorithm returns the JSON Web Key algorithm of an AES key,
+// such as A256GCM for a 256 bits AES-GCM key.
+func aesJWKAlgorithm(name AlgorithmIdentifier, keyLength int) string {
+	return fmt.Sprintf("A%d%s", keyLength*8, strings.TrimPrefix(name, "AES-"))
+}
+
 // aesImportParams is an internal placeholder struct for AES import parameters.
 // Although not described by the specification, we define it to be able to implement
 // our internal KeyImporter interface.
@@ -154,10 +170,8 @@ func newAesImportParams(normalized Algorithm) *aesImportParams {
 	}
 }
 
-// ImportKey imports an AES key from its raw representation.
+// ImportKey imports an AES key from its raw or JSON Web Key representation.
 // It implements the KeyImporter interface.
-//
-// TODO @oleiade: support JWK format #37
 func (aip *aesImportParams) ImportKey(
 	format KeyFormat,
 	keyData []byte,
@@ -174,19 +188,33 @@ func (aip *aesImportParams) ImportKey(
 
 	switch format {
 	case RawKeyFormat:
-		var (
-			has128Bits = len(keyData) == 16
-			has192Bits = len(keyData) == 24
-			has256Bits = len(keyData) == 32
-		)
-
-		if !has128Bits && !has192Bits && !has256Bits {
-			return nil, NewError(0, DataError, "invalid key length")
+	case JwkKeyFormat:
+		jwk, err := parseJWK(keyData, "oct", "enc", keyUsages)
+		if err != nil {
+			return nil, err
+		}
+
+		if keyData, err = decodeBase64URL("k", jwk.K); err != nil {
+			return nil, err
+		}
+
+		if jwk.Alg != "" && jwk.Alg != aesJWKAlgorithm(aip.Name, len(keyData)) {
+			return nil, NewError(0, DataError, "invalid JSON Web Key algorithm "+jwk.Alg)
 		}
 	default:
 		return nil, NewError(0, NotSupportedError, "unsupported key format "+format)
 	}
 
+	var (
+		has128Bits = len(keyData) == 16
+		has192Bits = len(keyData) == 24
+		has256Bits = len(keyData) == 32
+	)
+
+	if !has128Bits && !has192Bits && !has256Bits {
+		return nil, NewError(0, DataError, "invalid key length")
+	}
+
 	key := &CryptoKey{
 		Algorithm: AesKeyAlgorithm{
 			Algorithm: aip.Algorithm,
@@ -51,6 +51,12 @@ const (
 
 	// ECDH represents the ECDH algorithm.
 	ECDH = "ECDH"
+
+	// PBKDF2 represents the PBKDF2 algorithm.
+	PBKDF2 = "PBKDF2"
+
+	// HKDF represents the HKDF algorithm.
+	HKDF = "HKDF"
 )
 
 // HashAlgorithmIdentifier represents the name of a hash algorithm.
@@ -115,6 +121,10 @@ const (
 
 	// OperationIdentifierDigest represents the digest operation.
 	OperationIdentifierDigest OperationIdentifier = "digest"
+
+	// OperationIdentifierGetKeyLength represents the get key length operation,
+	// which is used internally by the deriveKey operation.
+	OperationIdentifierGetKeyLength OperationIdentifier = "get key length"
 )
 
 // normalizeAlgorithm normalizes the given algorithm following the
@@ -145,10 +155,13 @@ func normalizeAlgorithm(rt *goja.Runtime, v goja.Value, op AlgorithmIdentifier)
 		return Algorithm{}, NewError(0, SyntaxError, "algorithm cannot be interpreted as a string or an object")
 	}
 
-	// Algorithm identifers are always upper cased.
-	// A registered algorithm provided in lower case format, should
-	// be considered valid.
+	// Algorithm identifers are always upper cased, except for
+	// RSASSA-PKCS1-v1_5. A registered algorithm provided in lower
+	// case format, should be considered valid.
 	algorithm.Name = strings.ToUpper(algorithm.Name)
+	if algorithm.Name == strings.ToUpper(RSASsaPkcs1v15) {
+		algorithm.Name = RSASsaPkcs1v15
+	}
 
 	if !isRegisteredAlgorithm(algorithm.Name, op) {
 		return Algorithm{}, NewError(0, NotSupportedError, "unsupported algorithm: "+algorithm.Name)
@@ -168,13 +181,23 @@ func isRegisteredAlgorithm(algorithmName string, forOperation string) bool {
 		return isHashAlgorithm(algorithmName)
 	case OperationIdentifierGenerateKey:
 		// FIXME: the presence of the hash algorithm here is for HMAC support and should be handled separately
-		return isAesAlgorithm(algorithmName) || isHashAlgorithm(algorithmName) || algorithmName == HMAC
-	case OperationIdentifierExportKey, OperationIdentifierImportKey:
-		return isAesAlgorithm(algorithmName) || algorithmName == HMAC
+		return isAesAlgorithm(algorithmName) || isHashAlgorithm(algorithmName) || algorithmName == HMAC ||
+			isRsaAlgorithm(algorithmName) || isEllipticCurveAlgorithm(algorithmName)
+	case OperationIdentifierImportKey:
+		return isAesAlgorithm(algorithmName) || algorithmName == HMAC || isRsaAlgorithm(algorithmName) ||
+			isEllipticCurveAlgorithm(algorithmName) || algorithmName == PBKDF2 || algorithmName == HKDF
+	case OperationIdentifierExportKey:
+		return isAesAlgorithm(algorithmName) || algorithmName == HMAC || isRsaAlgorithm(algorithmName) ||
+			isEllipticCurveAlgorithm(algorithmName)
 	case OperationIdentifierEncrypt, OperationIdentifierDecrypt:
-		return isAesAlgorithm(algorithmName)
+		return isAesAlgorithm(algorithmName) || algorithmName == RSAOaep
 	case OperationIdentifierSign, OperationIdentifierVerify:
-		return algorithmName == HMAC
+		return algorithmName == HMAC || algorithmName == RSASsaPkcs1v15 || algorithmName == RSAPss ||
+			algorithmName == ECDSA
+	case OperationIdentifierDeriveBits, OperationIdentifierDeriveKey:
+		return algorithmName == ECDH || algorithmName == PBKDF2 || algorithmName == HKDF
+	case OperationIdentifierGetKeyLength:
+		return isAesAlgorithm(algorithmName) || algorithmName == HMAC
 	default:
 		return false
 	}
@@ -184,6 +207,14 @@ func isAesAlgorithm(algorithmName string) bool {
 	return algorithmName == AESCbc || algorithmName == AESCtr || algorithmName == AESGcm || algorithmName == AESKw
 }
 
+func isRsaAlgorithm(algorithmName string) bool {
+	return algorithmName == RSASsaPkcs1v15 || algorithmName == RSAPss || algorithmName == RSAOaep
+}
+
+func isEllipticCurveAlgorithm(algorithmName string) bool {
+	return algorithmName == ECDSA || algorithmName == ECDH
+}
+
 func isHashAlgorithm(algorithmName string) bool {
 	return algorithmName == Sha1 || algorithmName == Sha256 || algorithmName == Sha384 || algorithmName == Sha512
 }
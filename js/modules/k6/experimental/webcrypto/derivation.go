@@ -0,0 +1,112 @@
+package webcrypto
+
+import (
+	"github.com/dop251/goja"
+	"gopkg.in/guregu/null.v3"
+)
+
+// BitsDeriver is the interface implemented by the algorithms used to derive
+// bits from a base key.
+type BitsDeriver interface {
+	DeriveBits(baseKey CryptoKey, length null.Int) ([]byte, error)
+}
+
+// newBitsDeriver instantiates a BitsDeriver based on the provided
+// algorithm and parameters `goja.Value`.
+func newBitsDeriver(rt *goja.Runtime, normalized Algorithm, params goja.Value) (BitsDeriver, error) {
+	var bd BitsDeriver
+	var err error
+
+	switch normalized.Name {
+	case ECDH:
+		bd, err = newECDHKeyDeriveParams(rt, normalized, params)
+	case PBKDF2:
+		bd, err = newPBKDF2Params(rt, normalized, params)
+	case HKDF:
+		bd, err = newHKDFParams(rt, normalized, params)
+	default:
+		return nil, NewError(0, NotSupportedError, "unsupported algorithm for bits derivation: "+normalized.Name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return bd, nil
+}
+
+// getKeyLength returns the length, in bits, of the keys described by the given
+// algorithm parameters, as defined by the get key length operations of the
+// [specification]. It is used to know how many bits to derive when deriving a key.
+//
+// [specification]: https://www.w3.org/TR/WebCryptoAPI/#aes-ctr-operations
+func getKeyLength(rt *goja.Runtime, normalized Algorithm, params goja.Value) (null.Int, error) {
+	switch normalized.Name {
+	case AESCbc, AESCtr, AESGcm, AESKw:
+		akgp, err := newAesKeyGenParams(rt, normalized, params)
+		if err != nil {
+			return null.Int{}, err
+		}
+
+		// 1.
+		if akgp.Length != 128 && akgp.Length != 192 && akgp.Length != 256 {
+			return null.Int{}, NewError(0, OperationError, "invalid AES key length")
+		}
+
+		// 2.
+		return null.IntFrom(akgp.Length), nil
+	case HMAC:
+		hip, err := newHmacImportParams(rt, normalized, params)
+		if err != nil {
+			return null.Int{}, err
+		}
+
+		// 1.
+		if hip.Length.Valid {
+			if hip.Length.Int64 == 0 {
+				return null.Int{}, NewError(0, TypeError, "the HMAC key length cannot be 0")
+			}
+
+			return hip.Length, nil
+		}
+
+		hashFn, ok := getHashFn(hip.Hash.Name)
+		if !ok {
+			return null.Int{}, NewError(0, NotSupportedError, "unsupported hash algorithm "+hip.Hash.Name)
+		}
+
+		// 2.
+		return null.IntFrom(int64(hashFn().BlockSize() * 8)), nil
+	default:
+		return null.Int{}, NewError(0, NotSupportedError, "unsupported algorithm for key derivation: "+normalized.Name)
+	}
+}
+
+// truncateBits returns the first length bits of the secret, or the
+// whole secret if the length is null.
+func truncateBits(secret []byte, length null.Int) ([]byte, error) {
+	if !length.Valid {
+		return secret, nil
+	}
+
+	if length.Int64 > int64(len(secret))*8 {
+		return nil, NewError(0, OperationError, "the length is greater than the derived secret length")
+	}
+
+	result := secret[:(length.Int64+7)/8]
+	if remainder := length.Int64 % 8; remainder != 0 {
+		result[len(result)-1] &= byte(0xff << (8 - remainder))
+	}
+
+	return result, nil
+}
+
+// validateDerivedBitsLength checks that the given length is a non-null,
+// non-zero multiple of 8, as required by the PBKDF2 and HKDF algorithms.
+func validateDerivedBitsLength(length null.Int) error {
+	if !length.Valid || length.Int64 <= 0 || length.Int64%8 != 0 {
+		return NewError(0, OperationError, "the length needs to be a non-zero multiple of 8")
+	}
+
+	return nil
+}
@@ -0,0 +1,560 @@
+package webcrypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"math/big"
+
+	"github.com/dop251/goja"
+	"gopkg.in/guregu/null.v3"
+)
+
+// EcKeyImportParams represents the object that should be passed as the algorithm parameter
+// into `SubtleCrypto.ImportKey` or `SubtleCrypto.UnwrapKey`, when generating any elliptic-curve-based
+// key pair: that is, when the algorithm is identified as either of ECDSA or ECDH.
+type EcKeyImportParams struct {
+	// Name should be set to AlgorithmKindEcdsa or AlgorithmKindEcdh.
+	Name AlgorithmIdentifier `json:"name"`
+
+	// NamedCurve holds (a String) the name of the elliptic curve to use.
+	NamedCurve EllipticCurveKind `json:"namedCurve"`
+}
+
+// newEcKeyImportParams creates a new EcKeyImportParams object from the given
+// algorithm and params objects.
+func newEcKeyImportParams(rt *goja.Runtime, normalized Algorithm, params goja.Value) (*EcKeyImportParams, error) {
+	namedCurve, err := extractNamedCurve(rt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EcKeyImportParams{Name: normalized.Name, NamedCurve: namedCurve}, nil
+}
+
+// ImportKey imports an elliptic curve key from its raw, SubjectPublicKeyInfo,
+// PKCS #8, or JSON Web Key representation. It implements the KeyImporter interface.
+func (ekip *EcKeyImportParams) ImportKey(
+	format KeyFormat,
+	keyData []byte,
+	keyUsages []CryptoKeyUsage,
+) (*CryptoKey, error) {
+	curve, _ := getCurve(ekip.NamedCurve)
+
+	var handle any
+	var err error
+
+	switch format {
+	case RawKeyFormat:
+		x, y := elliptic.Unmarshal(curve, keyData) //nolint:staticcheck // crypto/ecdh is not available in go 1.19
+		if x == nil {
+			return nil, NewError(0, DataError, "invalid elliptic curve public key")
+		}
+		handle = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	case SpkiKeyFormat:
+		handle, err = x509.ParsePKIXPublicKey(keyData)
+	case Pkcs8KeyFormat:
+		handle, err = x509.ParsePKCS8PrivateKey(keyData)
+	case JwkKeyFormat:
+		handle, err = ekip.importJWK(curve, keyData, keyUsages)
+	default:
+		return nil, NewError(0, NotSupportedError, "unsupported key format "+format)
+	}
+
+	if err != nil {
+		if _, ok := err.(*Error); ok { //nolint:errorlint
+			return nil, err
+		}
+		return nil, NewError(0, DataError, "unable to parse the elliptic curve key: "+err.Error())
+	}
+
+	publicUsages, privateUsages := ecKeyUsages(ekip.Name)
+	key := &CryptoKey{
+		Algorithm: EcKeyAlgorithm{
+			KeyAlgorithm: KeyAlgorithm{Algorithm{Name: ekip.Name}},
+			NamedCurve:   ekip.NamedCurve,
+		},
+		handle: handle,
+	}
+
+	var keyCurve elliptic.Curve
+	switch k := handle.(type) {
+	case *ecdsa.PublicKey:
+		key.Type = PublicCryptoKeyType
+		keyCurve = k.Curve
+		err = validateUsages(keyUsages, publicUsages)
+	case *ecdsa.PrivateKey:
+		key.Type = PrivateCryptoKeyType
+		keyCurve = k.Curve
+		err = validateUsages(keyUsages, privateUsages)
+	default:
+		return nil, NewError(0, DataError, "the key data does not describe an elliptic curve key")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if keyCurve != curve {
+		return nil, NewError(0, DataError, "the key curve does not match the named curve "+ekip.NamedCurve)
+	}
+
+	return key, nil
+}
+
+// importJWK returns the elliptic curve public or private key of a JSON Web Key.
+func (ekip *EcKeyImportParams) importJWK(curve elliptic.Curve, keyData []byte, keyUsages []CryptoKeyUsage) (any, error) {
+	use := "sig"
+	if ekip.Name == ECDH {
+		use = "enc"
+	}
+
+	jwk, err := parseJWK(keyData, "EC", use, keyUsages)
+	if err != nil {
+		return nil, err
+	}
+
+	if jwk.Crv != ekip.NamedCurve {
+		return nil, NewError(0, DataError, "the JSON Web Key curve does not match the named curve "+ekip.NamedCurve)
+	}
+
+	if ekip.Name == ECDSA && jwk.Alg != "" && jwk.Alg != ecdsaJWKAlgorithm(ekip.NamedCurve) {
+		return nil, NewError(0, DataError, "invalid JSON Web Key algorithm "+jwk.Alg)
+	}
+
+	x, err := decodeBigInt("x", jwk.X)
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := decodeBigInt("y", jwk.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	if !curve.IsOnCurve(x, y) { //nolint:staticcheck // crypto/ecdh is not available in go 1.19
+		return nil, NewError(0, DataError, "the JSON Web Key point is not on the curve")
+	}
+
+	publicKey := ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	if jwk.D == "" {
+		return &publicKey, nil
+	}
+
+	d, err := decodeBigInt("d", jwk.D)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ecdsa.PrivateKey{PublicKey: publicKey, D: d}, nil
+}
+
+// Ensure that EcKeyImportParams implements the KeyImporter interface.
+var _ KeyImporter = &EcKeyImportParams{}
+
+// ECKeyGenParams  represents the object that should be passed as the algorithm
+// parameter into `SubtleCrypto.GenerateKey`, when generating any
+// elliptic-curve-based key pair: that is, when the algorithm is identified
+// as either of AlgorithmKindEcdsa or AlgorithmKindEcdh.
+type ECKeyGenParams struct {
+	Algorithm
+
+	// NamedCurve holds (a String) the name of the curve to use.
+	// You can use any of the following: CurveKindP256, CurveKindP384, or CurveKindP521.
+	NamedCurve EllipticCurveKind
+}
+
+// newECKeyGenParams creates a new ECKeyGenParams object, from the
+// normalized algorithm, and the params parameters passed by the user.
+func newECKeyGenParams(rt *goja.Runtime, normalized Algorithm, params goja.Value) (*ECKeyGenParams, error) {
+	namedCurve, err := extractNamedCurve(rt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ECKeyGenParams{Algorithm: normalized, NamedCurve: namedCurve}, nil
+}
+
+// GenerateKey generates a new elliptic curve key pair, as described in the
+// generate key operations of the [specification].
+//
+// [specification]: https://www.w3.org/TR/WebCryptoAPI/#ecdsa-operations
+func (ekgp *ECKeyGenParams) GenerateKey(
+	extractable bool,
+	keyUsages []CryptoKeyUsage,
+) (CryptoKeyGenerationResult, error) {
+	// 1.
+	publicUsages, privateUsages := ecKeyUsages(ekgp.Name)
+	for _, usage := range keyUsages {
+		if !contains(publicUsages, usage) && !contains(privateUsages, usage) {
+			return nil, NewError(0, SyntaxError, "invalid key usage: "+usage)
+		}
+	}
+
+	// 2.
+	curve, _ := getCurve(ekgp.NamedCurve)
+	privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		// 3.
+		return nil, NewError(0, OperationError, "failed to generate elliptic curve key pair; reason: "+err.Error())
+	}
+
+	// 4. to 8.
+	algorithm := EcKeyAlgorithm{
+		KeyAlgorithm: KeyAlgorithm{Algorithm{Name: ekgp.Name}},
+		NamedCurve:   ekgp.NamedCurve,
+	}
+
+	// 9. to 13.
+	publicKey := &CryptoKey{
+		Type:        PublicCryptoKeyType,
+		Extractable: true,
+		Algorithm:   algorithm,
+		Usages:      UsageIntersection(keyUsages, publicUsages),
+		handle:      &privateKey.PublicKey,
+	}
+
+	// 14. to 18.
+	private := &CryptoKey{
+		Type:        PrivateCryptoKeyType,
+		Extractable: extractable,
+		Algorithm:   algorithm,
+		Usages:      UsageIntersection(keyUsages, privateUsages),
+		handle:      privateKey,
+	}
+
+	// 19. 20. 21.
+	return &CryptoKeyPair{PublicKey: publicKey, PrivateKey: private}, nil
+}
+
+// Ensure that ECKeyGenParams implements the KeyGenerator interface.
+var _ KeyGenerator = &ECKeyGenParams{}
+
+// EcKeyAlgorithm represents the algorithm of an elliptic curve key,
+// as defined in the [specification].
+//
+// [specification]: https://www.w3.org/TR/WebCryptoAPI/#EcKeyAlgorithm-dictionary
+type EcKeyAlgorithm struct {
+	KeyAlgorithm
+
+	// NamedCurve holds the name of the elliptic curve used by the key.
+	NamedCurve EllipticCurveKind `json:"namedCurve"`
+}
+
+// ecKeyUsages returns the usages that are valid for the public and the
+// private keys of the given elliptic curve algorithm.
+func ecKeyUsages(name AlgorithmIdentifier) (public []CryptoKeyUsage, private []CryptoKeyUsage) {
+	if name == ECDH {
+		return []CryptoKeyUsage{}, []CryptoKeyUsage{DeriveKeyCryptoKeyUsage, DeriveBitsCryptoKeyUsage}
+	}
+
+	return []CryptoKeyUsage{VerifyCryptoKeyUsage}, []CryptoKeyUsage{SignCryptoKeyUsage}
+}
+
+// extractNamedCurve returns the named curve held by the `namedCurve`
+// attribute of the given algorithm parameters.
+func extractNamedCurve(rt *goja.Runtime, params goja.Value) (EllipticCurveKind, error) {
+	namedCurveValue, err := traverseObject(rt, params, "namedCurve")
+	if err != nil {
+		return "", NewError(0, SyntaxError, "could not get namedCurve from algorithm parameter")
+	}
+
+	namedCurve := namedCurveValue.String()
+	if !IsEllipticCurve(namedCurve) {
+		return "", NewError(0, NotSupportedError, "unsupported named curve "+namedCurve)
+	}
+
+	return namedCurve, nil
+}
+
+// EllipticCurveKind represents the kind of elliptic curve that is being used.
+//
+// Note that it is defined as an alias of string, instead of a dedicated type,
+// to ensure it is handled as a string by goja.
+type EllipticCurveKind = string
+
+const (
+	// EllipticCurveKindP256 represents the P-256 curve.
+	EllipticCurveKindP256 EllipticCurveKind = "P-256"
+
+	// EllipticCurveKindP384 represents the P-384 curve.
+	EllipticCurveKindP384 EllipticCurveKind = "P-384"
+
+	// EllipticCurveKindP521 represents the P-521 curve.
+	EllipticCurveKindP521 EllipticCurveKind = "P-521"
+)
+
+// IsEllipticCurve returns true if the given string is a valid EllipticCurveKind,
+// false otherwise.
+func IsEllipticCurve(name string) bool {
+	_, ok := getCurve(name)
+	return ok
+}
+
+// getCurve returns the elliptic curve identified by the given name.
+func getCurve(name EllipticCurveKind) (elliptic.Curve, bool) {
+	switch name {
+	case EllipticCurveKindP256:
+		return elliptic.P256(), true
+	case EllipticCurveKindP384:
+		return elliptic.P384(), true
+	case EllipticCurveKindP521:
+		return elliptic.P521(), true
+	default:
+		return nil, false
+	}
+}
+
+// curveByteSize returns the size, in bytes, of the field elements of the curve.
+func curveByteSize(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8
+}
+
+// ecdsaJWKAlgorithm returns the JSON Web Key algorithm of an ECDSA key using the given curve.
+func ecdsaJWKAlgorithm(namedCurve EllipticCurveKind) string {
+	switch namedCurve {
+	case EllipticCurveKindP384:
+		return "ES384"
+	case EllipticCurveKindP521:
+		return "ES512"
+	default:
+		return "ES256"
+	}
+}
+
+// exportECKey exports an elliptic curve key to its raw, SubjectPublicKeyInfo,
+// PKCS #8, or JSON Web Key representation.
+func exportECKey(ck *CryptoKey, format KeyFormat) ([]byte, error) {
+	// 1.
+	if ck.handle == nil {
+		return nil, NewError(0, OperationError, "key data is not accesible")
+	}
+
+	switch format {
+	case RawKeyFormat:
+		// 3.1.
+		publicKey, ok := ck.handle.(*ecdsa.PublicKey)
+		if !ok || ck.Type != PublicCryptoKeyType {
+			return nil, NewError(0, InvalidAccessError, "only public keys can be exported in the raw format")
+		}
+
+		return elliptic.Marshal(publicKey.Curve, publicKey.X, publicKey.Y), nil //nolint:staticcheck
+	case SpkiKeyFormat:
+		// 3.1.
+		if ck.Type != PublicCryptoKeyType {
+			return nil, NewError(0, InvalidAccessError, "only public keys can be exported in the spki format")
+		}
+
+		return marshalKey(x509.MarshalPKIXPublicKey(ck.handle))
+	case Pkcs8KeyFormat:
+		// 3.1.
+		if ck.Type != PrivateCryptoKeyType {
+			return nil, NewError(0, InvalidAccessError, "only private keys can be exported in the pkcs8 format")
+		}
+
+		return marshalKey(x509.MarshalPKCS8PrivateKey(ck.handle))
+	case JwkKeyFormat:
+		return exportECJWK(ck)
+	default:
+		return nil, NewError(0, NotSupportedError, "unsupported key format "+format)
+	}
+}
+
+func exportECJWK(ck *CryptoKey) ([]byte, error) {
+	algorithm, ok := ck.Algorithm.(EcKeyAlgorithm)
+	if !ok {
+		return nil, NewError(0, ImplementationError, "key algorithm does not describe an elliptic curve key")
+	}
+
+	jwk := &JSONWebKey{Kty: "EC", Crv: algorithm.NamedCurve}
+	if algorithm.Name == ECDSA {
+		jwk.Alg = ecdsaJWKAlgorithm(algorithm.NamedCurve)
+	}
+
+	var publicKey *ecdsa.PublicKey
+	switch k := ck.handle.(type) {
+	case *ecdsa.PublicKey:
+		publicKey = k
+	case *ecdsa.PrivateKey:
+		publicKey = &k.PublicKey
+		jwk.D = encodeBase64URL(k.D.FillBytes(make([]byte, curveByteSize(k.Curve))))
+	default:
+		return nil, NewError(0, ImplementationError, "key handle is of incorrect type")
+	}
+
+	size := curveByteSize(publicKey.Curve)
+	jwk.X = encodeBase64URL(publicKey.X.FillBytes(make([]byte, size)))
+	jwk.Y = encodeBase64URL(publicKey.Y.FillBytes(make([]byte, size)))
+
+	return marshalJWK(jwk, ck)
+}
+
+// ECDSAParams represents the object that should be passed as the algorithm
+// parameter into `SubtleCrypto.Sign` or `SubtleCrypto.Verify` when using the
+// ECDSA algorithm.
+type ECDSAParams struct {
+	Algorithm
+
+	// Hash identifies the name of the digest algorithm to use.
+	// You can use any of the following:
+	//   * [Sha256]
+	//   * [Sha384]
+	//   * [Sha512]
+	Hash Algorithm
+}
+
+func newECDSAParams(rt *goja.Runtime, normalized Algorithm, params goja.Value) (*ECDSAParams, error) {
+	hash, err := extractHash(rt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ECDSAParams{Algorithm: normalized, Hash: hash}, nil
+}
+
+// Sign signs the data using the ECDSA algorithm. The signature is the
+// concatenation of the r and s values, each one padded to the size of the curve,
+// as described in the [specification]. It implements the SignerVerifier interface.
+//
+// [specification]: https://www.w3.org/TR/WebCryptoAPI/#ecdsa-operations
+func (ep *ECDSAParams) Sign(key CryptoKey, data []byte) ([]byte, error) {
+	// 1.
+	if key.Type != PrivateCryptoKeyType {
+		return nil, NewError(0, InvalidAccessError, "the key is not a private key")
+	}
+
+	privateKey, ok := key.handle.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, NewError(0, InvalidAccessError, "key handle is of incorrect type")
+	}
+
+	// 2. to 4.
+	digest, err := ep.digest(data)
+	if err != nil {
+		return nil, err
+	}
+
+	r, s, err := ecdsa.Sign(rand.Reader, privateKey, digest)
+	if err != nil {
+		return nil, NewError(0, OperationError, "unable to sign the data: "+err.Error())
+	}
+
+	// 5. to 7.
+	size := curveByteSize(privateKey.Curve)
+	signature := make([]byte, 2*size)
+	r.FillBytes(signature[:size])
+	s.FillBytes(signature[size:])
+
+	return signature, nil
+}
+
+// Verify verifies a signature produced by the Sign method.
+// It implements the SignerVerifier interface.
+func (ep *ECDSAParams) Verify(key CryptoKey, signature, data []byte) (bool, error) {
+	// 1.
+	if key.Type != PublicCryptoKeyType {
+		return false, NewError(0, InvalidAccessError, "the key is not a public key")
+	}
+
+	publicKey, ok := key.handle.(*ecdsa.PublicKey)
+	if !ok {
+		return false, NewError(0, InvalidAccessError, "key handle is of incorrect type")
+	}
+
+	// 2. to 4.
+	digest, err := ep.digest(data)
+	if err != nil {
+		return false, err
+	}
+
+	size := curveByteSize(publicKey.Curve)
+	if len(signature) != 2*size {
+		return false, nil
+	}
+
+	r := new(big.Int).SetBytes(signature[:size])
+	s := new(big.Int).SetBytes(signature[size:])
+
+	return ecdsa.Verify(publicKey, digest, r, s), nil
+}
+
+func (ep *ECDSAParams) digest(data []byte) ([]byte, error) {
+	hashFn, ok := getHashFn(ep.Hash.Name)
+	if !ok {
+		return nil, NewError(0, NotSupportedError, "unsupported hash algorithm "+ep.Hash.Name)
+	}
+
+	hasher := hashFn()
+	hasher.Write(data)
+
+	return hasher.Sum(nil), nil
+}
+
+// ECDHKeyDeriveParams represents the object that should be passed as the algorithm
+// parameter into `SubtleCrypto.DeriveKey` or `SubtleCrypto.DeriveBits`, when using
+// the ECDH algorithm.
+type ECDHKeyDeriveParams struct {
+	Algorithm
+
+	// Public holds the public key of the other entity.
+	Public *CryptoKey
+}
+
+func newECDHKeyDeriveParams(rt *goja.Runtime, normalized Algorithm, params goja.Value) (*ECDHKeyDeriveParams, error) {
+	publicValue, err := traverseObject(rt, params, "public")
+	if err != nil {
+		return nil, NewError(0, SyntaxError, "could not get public from algorithm parameter")
+	}
+
+	publicKey, ok := publicValue.Export().(*CryptoKey)
+	if !ok {
+		return nil, NewError(0, InvalidAccessError, "the public attribute does not hold a CryptoKey")
+	}
+
+	return &ECDHKeyDeriveParams{Algorithm: normalized, Public: publicKey}, nil
+}
+
+// DeriveBits computes the shared secret of the base private key and the
+// public key, as described in the derive bits operation of the [specification].
+// It implements the BitsDeriver interface.
+//
+// [specification]: https://www.w3.org/TR/WebCryptoAPI/#ecdh-operations
+func (ekdp *ECDHKeyDeriveParams) DeriveBits(baseKey CryptoKey, length null.Int) ([]byte, error) {
+	// 1. 2.
+	if ekdp.Public.Type != PublicCryptoKeyType {
+		return nil, NewError(0, InvalidAccessError, "the public attribute does not hold a public key")
+	}
+
+	// 3.
+	publicAlgorithm, ok := ekdp.Public.Algorithm.(EcKeyAlgorithm)
+	if !ok || publicAlgorithm.Name != ECDH {
+		return nil, NewError(0, InvalidAccessError, "the public key is not an ECDH key")
+	}
+
+	// 4.
+	baseAlgorithm, ok := baseKey.Algorithm.(EcKeyAlgorithm)
+	if !ok || baseAlgorithm.NamedCurve != publicAlgorithm.NamedCurve {
+		return nil, NewError(0, InvalidAccessError, "the public key and the base key curves do not match")
+	}
+
+	privateKey, ok := baseKey.handle.(*ecdsa.PrivateKey)
+	if !ok || baseKey.Type != PrivateCryptoKeyType {
+		return nil, NewError(0, InvalidAccessError, "the base key is not a private key")
+	}
+
+	publicKey, ok := ekdp.Public.handle.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, NewError(0, InvalidAccessError, "key handle is of incorrect type")
+	}
+
+	// 5. 6.
+	x, _ := privateKey.Curve.ScalarMult(publicKey.X, publicKey.Y, privateKey.D.Bytes()) //nolint:staticcheck
+	secret := x.FillBytes(make([]byte, curveByteSize(privateKey.Curve)))
+
+	// 7. 8.
+	return truncateBits(secret, length)
+}
+
+// Ensure that ECDHKeyDeriveParams implements the BitsDeriver interface.
+var _ BitsDeriver = &ECDHKeyDeriveParams{}
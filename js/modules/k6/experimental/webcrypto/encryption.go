@@ -26,7 +26,7 @@ type EncryptDecrypter interface {
 // algorithm and parameters `goja.Value`.
 //
 // The returned instance can be used to encrypt/decrypt data using the
-// corresponding AES algorithm.
+// corresponding AES or RSA algorithm.
 func newEncryptDecrypter(
 	rt *goja.Runtime,
 	algorithm Algorithm,
@@ -46,6 +46,8 @@ func newEncryptDecrypter(
 	case AESGcm:
 		ed = new(AesGcmParams)
 		paramsObjectName = "AesGcmParams"
+	case RSAOaep:
+		return newRSAOaepParams(rt, algorithm, params)
 	default:
 		return nil, NewError(0, NotSupportedError, "unsupported algorithm")
 	}
@@ -0,0 +1,55 @@
+package webcrypto
+
+import (
+	"crypto"
+	"hash"
+
+	"github.com/dop251/goja"
+)
+
+// getHashFn returns the hash function associated with the given name.
+//
+// It returns a generator function, that can be used to create a new
+// hash.Hash instance.
+func getHashFn(name string) (func() hash.Hash, bool) {
+	switch name {
+	case Sha1:
+		return crypto.SHA1.New, true
+	case Sha256:
+		return crypto.SHA256.New, true
+	case Sha384:
+		return crypto.SHA384.New, true
+	case Sha512:
+		return crypto.SHA512.New, true
+	default:
+		return nil, false
+	}
+}
+
+// extractHash returns the normalized hash algorithm held by the hash
+// attribute of the given algorithm parameters, which is either a string,
+// or an object with a name attribute.
+func extractHash(rt *goja.Runtime, params goja.Value) (Algorithm, error) {
+	hashValue, err := traverseObject(rt, params, "hash")
+	if err != nil {
+		return Algorithm{}, NewError(0, SyntaxError, "could not get hash from algorithm parameter")
+	}
+
+	return normalizeAlgorithm(rt, hashValue, OperationIdentifierDigest)
+}
+
+// getHash returns the crypto.Hash associated with the given name.
+func getHash(name string) (crypto.Hash, bool) {
+	switch name {
+	case Sha1:
+		return crypto.SHA1, true
+	case Sha256:
+		return crypto.SHA256, true
+	case Sha384:
+		return crypto.SHA384, true
+	case Sha512:
+		return crypto.SHA512, true
+	default:
+		return 0, false
+	}
+}
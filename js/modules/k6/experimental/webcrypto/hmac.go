@@ -1,6 +1,7 @@
 package webcrypto
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha1" //nolint:gosec
 	"crypto/sha256"
@@ -82,7 +83,7 @@ func newHmacKeyGenParams(rt *goja.Runtime, normalized Algorithm, params goja.Val
 func (hkgp *HmacKeyGenParams) GenerateKey(
 	extractable bool,
 	keyUsages []CryptoKeyUsage,
-) (*CryptoKey, error) {
+) (CryptoKeyGenerationResult, error) {
 	// 1.
 	for _, usage := range keyUsages {
 		switch usage {
@@ -182,12 +183,40 @@ func exportHmacKey(ck *CryptoKey, format KeyFormat) ([]byte, error) {
 	switch format {
 	case RawKeyFormat:
 		return bits, nil
+	case JwkKeyFormat:
+		algorithm, ok := ck.Algorithm.(HmacKeyAlgorithm)
+		if !ok {
+			return nil, NewError(0, ImplementationError, "key algorithm does not describe a HMAC key")
+		}
+
+		alg, err := hmacJWKAlgorithm(algorithm.Hash.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		return marshalJWK(&JSONWebKey{Kty: "oct", K: encodeBase64URL(bits), Alg: alg}, ck)
 	default:
-		// FIXME: note that we do not support JWK format, yet #37.
 		return nil, NewError(0, NotSupportedError, "unsupported key format "+format)
 	}
 }
 
+// hmacJWKAlgorithm returns the JSON Web Key algorithm of a HMAC key
+// using the given hash algorithm, such as HS256 for SHA-256.
+func hmacJWKAlgorithm(hash AlgorithmIdentifier) (string, error) {
+	switch hash {
+	case Sha1:
+		return "HS1", nil
+	case Sha256:
+		return "HS256", nil
+	case Sha384:
+		return "HS384", nil
+	case Sha512:
+		return "HS512", nil
+	default:
+		return "", NewError(0, NotSupportedError, "unsupported hash algorithm "+hash)
+	}
+}
+
 // HashFn returns the hash function to use for the HMAC key.
 func (hka *HmacKeyAlgorithm) HashFn() (func() hash.Hash, error) {
 	hashFn, ok := getHashFn(hka.Hash.Name)
@@ -279,6 +308,26 @@ func (hip *HmacImportParams) ImportKey(
 	// 4.
 	switch format {
 	case RawKeyFormat:
+		hash = KeyAlgorithm{Algorithm{Name: hip.Hash.Name}}
+	case JwkKeyFormat:
+		jwk, err := parseJWK(keyData, "oct", "sig", keyUsages)
+		if err != nil {
+			return nil, err
+		}
+
+		if keyData, err = decodeBase64URL("k", jwk.K); err != nil {
+			return nil, err
+		}
+
+		alg, err := hmacJWKAlgorithm(hip.Hash.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if jwk.Alg != "" && jwk.Alg != alg {
+			return nil, NewError(0, DataError, "invalid JSON Web Key algorithm "+jwk.Alg)
+		}
+
 		hash = KeyAlgorithm{Algorithm{Name: hip.Hash.Name}}
 	default:
 		return nil, NewError(0, NotSupportedError, "unsupported key format "+format)
@@ -329,3 +378,51 @@ func (hip *HmacImportParams) ImportKey(
 
 // Ensure that HmacImportParams implements the KeyImporter interface.
 var _ KeyImporter = &HmacImportParams{}
+
+// hmacSignerVerifier signs and verifies data using the HMAC algorithm,
+// with the hash algorithm of the key.
+type hmacSignerVerifier struct{}
+
+// Sign implements the SignerVerifier interface.
+func (hmacSignerVerifier) Sign(key CryptoKey, data []byte) ([]byte, error) {
+	hasher, err := newKeyHmac(key)
+	if err != nil {
+		return nil, err
+	}
+
+	hasher.Write(data)
+
+	return hasher.Sum(nil), nil
+}
+
+// Verify implements the SignerVerifier interface.
+func (hmacSignerVerifier) Verify(key CryptoKey, signature, data []byte) (bool, error) {
+	hasher, err := newKeyHmac(key)
+	if err != nil {
+		return false, err
+	}
+
+	hasher.Write(data)
+
+	return hmac.Equal(signature, hasher.Sum(nil)), nil
+}
+
+// newKeyHmac returns a new HMAC hash.Hash using the given key.
+func newKeyHmac(key CryptoKey) (hash.Hash, error) {
+	keyAlgorithm, ok := key.Algorithm.(HmacKeyAlgorithm)
+	if !ok {
+		return nil, NewError(0, InvalidAccessError, "key algorithm does not describe a HMAC key")
+	}
+
+	keyHandle, ok := key.handle.([]byte)
+	if !ok {
+		return nil, NewError(0, InvalidAccessError, "key handle is of incorrect type")
+	}
+
+	hashFn, err := keyAlgorithm.HashFn()
+	if err != nil {
+		return nil, err
+	}
+
+	return hmac.New(hashFn, keyHandle), nil
+}
@@ -0,0 +1,126 @@
+package webcrypto
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+)
+
+// JSONWebKey represents a key in the JSON Web Key format, as defined
+// in [RFC 7517], and as used by the WebCrypto API's [specification].
+//
+// [RFC 7517]: https://www.rfc-editor.org/rfc/rfc7517
+// [specification]: https://www.w3.org/TR/WebCryptoAPI/#JsonWebKey-dictionary
+type JSONWebKey struct {
+	// Kty holds the family of algorithms used with the key: "oct", "RSA" or "EC".
+	Kty string `json:"kty"`
+
+	// Use holds the intended use of a public key: "sig" or "enc".
+	Use string `json:"use,omitempty"`
+
+	// KeyOps holds the operations for which the key is intended to be used.
+	KeyOps []string `json:"key_ops,omitempty"`
+
+	// Alg holds the algorithm intended for use with the key.
+	Alg string `json:"alg,omitempty"`
+
+	// Ext holds the extractability of the key.
+	Ext *bool `json:"ext,omitempty"`
+
+	// K holds the value of a symmetric key.
+	K string `json:"k,omitempty"`
+
+	// Crv, X, Y and D hold the parameters of an elliptic curve key.
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	D   string `json:"d,omitempty"`
+
+	// N, E, P, Q, DP, DQ and QI hold the parameters of an RSA key,
+	// D being shared with the elliptic curve keys.
+	N  string `json:"n,omitempty"`
+	E  string `json:"e,omitempty"`
+	P  string `json:"p,omitempty"`
+	Q  string `json:"q,omitempty"`
+	DP string `json:"dp,omitempty"`
+	DQ string `json:"dq,omitempty"`
+	QI string `json:"qi,omitempty"`
+}
+
+// parseJWK parses the JSON representation of a JSON Web Key, and validates
+// its type, and the intended usages of the key, as described by the import
+// key operations of the [specification].
+//
+// [specification]: https://www.w3.org/TR/WebCryptoAPI/#concept-parse-a-jwk
+func parseJWK(keyData []byte, kty string, use string, keyUsages []CryptoKeyUsage) (*JSONWebKey, error) {
+	var jwk JSONWebKey
+	if err := json.Unmarshal(keyData, &jwk); err != nil {
+		return nil, NewError(0, DataError, "invalid JSON Web Key: "+err.Error())
+	}
+
+	if jwk.Kty != kty {
+		return nil, NewError(0, DataError, "invalid JSON Web Key type, expected "+kty)
+	}
+
+	if len(keyUsages) > 0 && jwk.Use != "" && jwk.Use != use {
+		return nil, NewError(0, DataError, "invalid JSON Web Key use, expected "+use)
+	}
+
+	if jwk.KeyOps != nil {
+		for _, usage := range keyUsages {
+			if !contains(jwk.KeyOps, usage) {
+				return nil, NewError(0, DataError, "the JSON Web Key operations do not contain the usage "+usage)
+			}
+		}
+	}
+
+	return &jwk, nil
+}
+
+// marshalJWK sets the attributes of the JSON Web Key that are common to all the
+// exported keys, and returns its JSON representation.
+func marshalJWK(jwk *JSONWebKey, key *CryptoKey) ([]byte, error) {
+	jwk.KeyOps = key.Usages
+	if jwk.KeyOps == nil {
+		jwk.KeyOps = []string{}
+	}
+	ext := key.Extractable
+	jwk.Ext = &ext
+
+	b, err := json.Marshal(jwk)
+	if err != nil {
+		return nil, NewError(0, ImplementationError, "unable to marshal the JSON Web Key: "+err.Error())
+	}
+
+	return b, nil
+}
+
+// decodeBase64URL decodes an unpadded base64url encoded attribute of a JSON Web Key.
+func decodeBase64URL(attribute, value string) ([]byte, error) {
+	if value == "" {
+		return nil, NewError(0, DataError, "the JSON Web Key "+attribute+" attribute is missing")
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, NewError(0, DataError, "invalid JSON Web Key "+attribute+" attribute: "+err.Error())
+	}
+
+	return b, nil
+}
+
+// decodeBigInt decodes an unpadded base64url encoded big-endian integer
+// attribute of a JSON Web Key.
+func decodeBigInt(attribute, value string) (*big.Int, error) {
+	b, err := decodeBase64URL(attribute, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+// encodeBase64URL encodes an attribute of a JSON Web Key, without padding.
+func encodeBase64URL(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
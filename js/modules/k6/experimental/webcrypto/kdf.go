@@ -0,0 +1,226 @@
+package webcrypto
+
+import (
+	"io"
+
+	"github.com/dop251/goja"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/pbkdf2"
+	"gopkg.in/guregu/null.v3"
+)
+
+// kdfImportParams represents the parameters used to import the base key
+// material of the PBKDF2 and HKDF key derivation algorithms.
+type kdfImportParams struct {
+	Algorithm
+}
+
+func newKdfImportParams(normalized Algorithm) *kdfImportParams {
+	return &kdfImportParams{Algorithm: normalized}
+}
+
+// ImportKey imports the raw base key material of a key derivation function.
+// It implements the KeyImporter interface.
+func (kip *kdfImportParams) ImportKey(
+	format KeyFormat,
+	keyData []byte,
+	keyUsages []CryptoKeyUsage,
+) (*CryptoKey, error) {
+	// 1.
+	if format != RawKeyFormat {
+		return nil, NewError(0, NotSupportedError, "unsupported key format "+format)
+	}
+
+	// 2.
+	if err := validateUsages(keyUsages, []CryptoKeyUsage{DeriveKeyCryptoKeyUsage, DeriveBitsCryptoKeyUsage}); err != nil {
+		return nil, err
+	}
+
+	// 4. to 8.
+	return &CryptoKey{
+		Type:      SecretCryptoKeyType,
+		Algorithm: KeyAlgorithm{Algorithm{Name: kip.Name}},
+		handle:    keyData,
+	}, nil
+}
+
+// Ensure that kdfImportParams implements the KeyImporter interface.
+var _ KeyImporter = &kdfImportParams{}
+
+// PBKDF2Params represents the object that should be passed as the algorithm
+// parameter into `SubtleCrypto.DeriveKey`, when using the PBKDF2 algorithm.
+type PBKDF2Params struct {
+	Algorithm
+
+	// Hash identifies the name of the digest algorithm to use.
+	// You can use any of the following:
+	//   * [Sha1]
+	//   * [Sha256]
+	//   * [Sha384]
+	//   * [Sha512]
+	Hash Algorithm
+
+	// Salt should hold a random or pseudo-random value of at
+	// least 16 bytes. Unlike the input key material passed into
+	// `SubtleCrypto.DeriveKey`, salt does not need to be kept secret.
+	Salt []byte
+
+	// Iterations the number of times the hash function will be executed
+	// in `SubtleCrypto.DeriveKey`. This determines how computationally
+	// expensive (that is, slow) the `SubtleCrypto.DeriveKey` operation will be.
+	//
+	// In this context, slow is good, since it makes it more expensive for an
+	// attacker to run a dictionary attack against the keys.
+	// The general guidance here is to use as many iterations as possible,
+	// subject to keeping an acceptable level of performance for your application.
+	Iterations int
+}
+
+func newPBKDF2Params(rt *goja.Runtime, normalized Algorithm, params goja.Value) (*PBKDF2Params, error) {
+	hash, err := extractHash(rt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	salt, err := extractBuffer(rt, params, "salt")
+	if err != nil {
+		return nil, err
+	}
+
+	iterationsValue, err := traverseObject(rt, params, "iterations")
+	if err != nil {
+		return nil, NewError(0, SyntaxError, "could not get iterations from algorithm parameter")
+	}
+
+	return &PBKDF2Params{
+		Algorithm:  normalized,
+		Hash:       hash,
+		Salt:       salt,
+		Iterations: int(iterationsValue.ToInteger()),
+	}, nil
+}
+
+// DeriveBits derives bits from the base key material using the PBKDF2
+// algorithm. It implements the BitsDeriver interface.
+//
+// [specification]: https://www.w3.org/TR/WebCryptoAPI/#pbkdf2-operations
+func (pp *PBKDF2Params) DeriveBits(baseKey CryptoKey, length null.Int) ([]byte, error) {
+	// 1.
+	if err := validateDerivedBitsLength(length); err != nil {
+		return nil, err
+	}
+
+	// 2.
+	if pp.Iterations <= 0 {
+		return nil, NewError(0, OperationError, "the number of iterations needs to be greater than 0")
+	}
+
+	password, ok := baseKey.handle.([]byte)
+	if !ok {
+		return nil, NewError(0, InvalidAccessError, "key handle is of incorrect type")
+	}
+
+	hashFn, ok := getHashFn(pp.Hash.Name)
+	if !ok {
+		return nil, NewError(0, NotSupportedError, "unsupported hash algorithm "+pp.Hash.Name)
+	}
+
+	// 3. to 5.
+	return pbkdf2.Key(password, pp.Salt, pp.Iterations, int(length.Int64/8), hashFn), nil
+}
+
+// Ensure that PBKDF2Params implements the BitsDeriver interface.
+var _ BitsDeriver = &PBKDF2Params{}
+
+// HKDFParams represents the object that should be passed as the algorithm parameter
+// into `SubtleCrypto.DeriveKey`, when using the HKDF algorithm.
+type HKDFParams struct {
+	Algorithm
+
+	// Hash should be set to the name of the digest algorithm to use.
+	// You can use any of the following:
+	//   * [Sha1]
+	//   * [Sha256]
+	//   * [Sha384]
+	//   * [Sha512]
+	Hash Algorithm
+
+	// Salt to use. The HKDF specification states that adding
+	// salt "adds significantly to the strength of HKDF".
+	// Ideally, the salt is a random or pseudo-random value with
+	// the same length as the output of the digest function.
+	// Unlike the input key material passed into `SubtleCrypto.DeriveKey`,
+	// salt does not need to be kept secret.
+	Salt []byte
+
+	// Info holds application-specific contextual information.
+	// This is used to bind the derived key to an application or
+	// context, and enables you to derive different keys for different
+	// contexts while using the same input key material.
+	//
+	// It's important that this should be independent of the input key material itself.
+	// This property is required but may be an empty buffer.
+	Info []byte
+}
+
+func newHKDFParams(rt *goja.Runtime, normalized Algorithm, params goja.Value) (*HKDFParams, error) {
+	hash, err := extractHash(rt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	salt, err := extractBuffer(rt, params, "salt")
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := extractBuffer(rt, params, "info")
+	if err != nil {
+		return nil, err
+	}
+
+	return &HKDFParams{Algorithm: normalized, Hash: hash, Salt: salt, Info: info}, nil
+}
+
+// DeriveBits derives bits from the base key material using the HKDF
+// algorithm. It implements the BitsDeriver interface.
+//
+// [specification]: https://www.w3.org/TR/WebCryptoAPI/#hkdf-operations
+func (hp *HKDFParams) DeriveBits(baseKey CryptoKey, length null.Int) ([]byte, error) {
+	// 1.
+	if err := validateDerivedBitsLength(length); err != nil {
+		return nil, err
+	}
+
+	secret, ok := baseKey.handle.([]byte)
+	if !ok {
+		return nil, NewError(0, InvalidAccessError, "key handle is of incorrect type")
+	}
+
+	hashFn, ok := getHashFn(hp.Hash.Name)
+	if !ok {
+		return nil, NewError(0, NotSupportedError, "unsupported hash algorithm "+hp.Hash.Name)
+	}
+
+	// 2. to 4.
+	result := make([]byte, length.Int64/8)
+	if _, err := io.ReadFull(hkdf.New(hashFn, secret, hp.Salt, hp.Info), result); err != nil {
+		return nil, NewError(0, OperationError, "unable to derive the bits: "+err.Error())
+	}
+
+	return result, nil
+}
+
+// Ensure that HKDFParams implements the BitsDeriver interface.
+var _ BitsDeriver = &HKDFParams{}
+
+// extractBuffer returns a copy of the buffer held by the given attribute
+// of the algorithm parameters.
+func extractBuffer(rt *goja.Runtime, params goja.Value, attribute string) ([]byte, error) {
+	value, err := traverseObject(rt, params, attribute)
+	if err != nil {
+		return nil, NewError(0, SyntaxError, "could not get "+attribute+" from algorithm parameter")
+	}
+
+	return exportArrayBuffer(rt, value)
+}
@@ -12,13 +12,39 @@ import (
 type CryptoKeyPair struct {
 	// PrivateKey holds the private key. For encryption and decryption algorithms,
 	// this key is used to decrypt. For signing and verification algorithms it is used to sign.
-	PrivateKey CryptoKey `json:"privateKey"`
+	PrivateKey *CryptoKey `json:"privateKey"`
 
 	// PublicKey holds the public key. For encryption and decryption algorithms,
 	// this key is used to encrypt. For signing and verification algorithms it is used to verify.
-	PublicKey CryptoKey `json:"publicKey"`
+	PublicKey *CryptoKey `json:"publicKey"`
 }
 
+// CryptoKeyGenerationResult is the result of a key generation, which is
+// either a CryptoKey for the symmetric algorithms, or a CryptoKeyPair for
+// the public-key algorithms.
+type CryptoKeyGenerationResult interface {
+	// IsKeyPair returns true if the result is a CryptoKeyPair.
+	IsKeyPair() bool
+
+	// ResolveCryptoKey returns the secret key of a CryptoKey result,
+	// or the private key of a CryptoKeyPair result.
+	ResolveCryptoKey() *CryptoKey
+}
+
+// IsKeyPair implements the CryptoKeyGenerationResult interface.
+func (ckp *CryptoKeyPair) IsKeyPair() bool {
+	return true
+}
+
+// ResolveCryptoKey implements the CryptoKeyGenerationResult interface,
+// and returns the private key of the pair.
+func (ckp *CryptoKeyPair) ResolveCryptoKey() *CryptoKey {
+	return ckp.PrivateKey
+}
+
+// Ensure that CryptoKeyPair implements the CryptoKeyGenerationResult interface.
+var _ CryptoKeyGenerationResult = &CryptoKeyPair{}
+
 // CryptoKey represents a cryptographic key obtained from one of the SubtleCrypto
 // methods `SubtleCrypto.generateKey`, `SubtleCrypto.DeriveKey`, `SubtleCrypto.ImportKey`,
 // or `SubtleCrypto.UnwrapKey`.
@@ -49,6 +75,19 @@ type CryptoKey struct {
 	handle any
 }
 
+// IsKeyPair implements the CryptoKeyGenerationResult interface.
+func (ck *CryptoKey) IsKeyPair() bool {
+	return false
+}
+
+// ResolveCryptoKey implements the CryptoKeyGenerationResult interface.
+func (ck *CryptoKey) ResolveCryptoKey() *CryptoKey {
+	return ck
+}
+
+// Ensure that CryptoKey implements the CryptoKeyGenerationResult interface.
+var _ CryptoKeyGenerationResult = &CryptoKey{}
+
 // ContainsUsage returns true if the key contains the specified usage.
 func (ck *CryptoKey) ContainsUsage(usage CryptoKeyUsage) bool {
 	return contains(ck.Usages, usage)
@@ -114,7 +153,7 @@ type KeyAlgorithm struct {
 // KeyGenerator is the interface implemented by the algorithms used to generate
 // cryptographic keys.
 type KeyGenerator interface {
-	GenerateKey(extractable bool, keyUsages []CryptoKeyUsage) (*CryptoKey, error)
+	GenerateKey(extractable bool, keyUsages []CryptoKeyUsage) (CryptoKeyGenerationResult, error)
 }
 
 func newKeyGenerator(rt *goja.Runtime, normalized Algorithm, params goja.Value) (KeyGenerator, error) {
@@ -126,6 +165,12 @@ func newKeyGenerator(rt *goja.Runtime, normalized Algorithm, params goja.Value)
 		kg, err = newAesKeyGenParams(rt, normalized, params)
 	case HMAC:
 		kg, err = newHmacKeyGenParams(rt, normalized, params)
+	case RSASsaPkcs1v15, RSAPss, RSAOaep:
+		kg, err = newRSAHashedKeyGenParams(rt, normalized, params)
+	case ECDSA, ECDH:
+		kg, err = newECKeyGenParams(rt, normalized, params)
+	default:
+		return nil, NewError(0, NotSupportedError, "unsupported algorithm for key generation: "+normalized.Name)
 	}
 
 	if err != nil {
@@ -150,6 +195,14 @@ func newKeyImporter(rt *goja.Runtime, normalized Algorithm, params goja.Value) (
 		ki = newAesImportParams(normalized)
 	case HMAC:
 		ki, err = newHmacImportParams(rt, normalized, params)
+	case RSASsaPkcs1v15, RSAPss, RSAOaep:
+		ki, err = newRSAHashedImportParams(rt, normalized, params)
+	case ECDSA, ECDH:
+		ki, err = newEcKeyImportParams(rt, normalized, params)
+	case PBKDF2, HKDF:
+		ki = newKdfImportParams(normalized)
+	default:
+		return nil, NewError(0, NotSupportedError, "unsupported algorithm for key import: "+normalized.Name)
 	}
 
 	if err != nil {
@@ -0,0 +1,72 @@
+package webcrypto
+
+// From is an interface representing the ability to produce
+// an instance from a given generic input. It is an attempt
+// to create a contract around construction of objects from
+// others.
+type From[Input, Output any] interface {
+	// From produces an output of type Output from the
+	// content of the given input.
+	From(Input) (Output, error)
+}
+
+// AESKeyGenParams represents the object that should be passed as
+// the algorithm parameter into `SubtleCrypto.generateKey`, when generating
+// an AES key: that is, when the algorithm is identified as any
+// of AES-CBC, AES-CTR, AES-GCM, or AES-KW.
+type AESKeyGenParams struct {
+	// Name should be set to `AES-CBC`, `AES-CTR`, `AES-GCM`, or `AES-KW`.
+	Name AlgorithmIdentifier
+
+	// Length holds (a Number) the length of the key, in bits.
+	Length int
+}
+
+// AESKwParams represents the object that should be passed as the algorithm parameter
+// into `SubtleCrypto.Encrypt`, `SubtleCrypto.Decrypt`, `SubtleCrypto.WrapKey`, or
+// `SubtleCrypto.UnwrapKey`, when using the AES-KW algorithm.
+type AESKwParams struct {
+	// Name should be set to AlgorithmKindAesKw.
+	Name AlgorithmIdentifier
+}
+
+// The ECDSAParams represents the object that should be passed as the algorithm
+// parameter into `SubtleCrypto.Sign` or `SubtleCrypto.Verify“ when using the
+// ECKeyGenParams  represents the object that should be passed as the algorithm
+// parameter into `SubtleCrypto.GenerateKey`, when generating any
+// elliptic-curve-based key pair: that is, when the algorithm is identified
+// ECKeyImportParams represents the object that should be passed as the algorithm parameter
+// into `SubtleCrypto.ImportKey` or `SubtleCrypto.UnwrapKey`, when generating any elliptic-curve-based
+// HKDFParams represents the object that should be passed as the algorithm parameter
+// HMACSignatureParams represents the object that should be passed as the algorithm parameter
+// into `SubtleCrypto.Sign`, when using the HMAC algorithm.
+type HMACSignatureParams struct {
+	// Name should be set to AlgorithmKindHmac.
+	Name AlgorithmIdentifier
+}
+
+// HMACImportParams represents the object that should be passed as the
+// algorithm parameter into `SubtleCrypto.ImportKey` or `SubtleCrypto.UnwrapKey`, when
+// generating a key for the HMAC algorithm.
+type HMACImportParams struct {
+	// Name should be set to AlgorithmKindHmac.
+	Name AlgorithmIdentifier
+
+	// Hash represents the name of the digest function to use.
+	Hash AlgorithmIdentifier
+}
+
+// PBKDF2Params represents the object that should be passed as the algorithm
+// RSAHashedKeyGenParams represents the object that should be passed as the algorithm
+// RSAHashedImportParams represents the object that should be passed as the
+// algorithm parameter into `SubtleCrypto.ImportKey` or `SubtleCrypto.UnwrapKey`, when
+// importing any RSA-based key pair: that is, when the algorithm is identified as any
+// RSAOaepParams represents the object that should be passed as the algorithm parameter
+// into `SubtleCrypto.Encrypt`, `SubtleCrypto.Decrypt`, `SubtleCrypto.WrapKey`, or
+// RSAPssParams represents the object that should be passed as the algorithm
+// parameter into `SubtleCrypto.Sign` or `SubtleCrypto.Verify`, when using the
+// RSASsaPkcs1v15Params represents the object that should be passed as the algorithm
+type RSASsaPkcs1v15Params struct {
+	// Name should be set to AlgorithmKindRsassaPkcs1v15.
+	Name AlgorithmIdentifier
+}
@@ -0,0 +1,648 @@
+package webcrypto
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"hash"
+	"math/big"
+
+	"github.com/dop251/goja"
+)
+
+// RSAHashedKeyGenParams represents the object that should be passed as the algorithm
+// parameter into `SubtleCrypto.GenerateKey`, when generating an RSA key pair: that is,
+// when the algorithm is identified as any of RSASSA-PKCS1-v1_5, RSA-PSS, or RSA-OAEP.
+//
+// [specification]: https://www.w3.org/TR/WebCryptoAPI/#RsaHashedKeyGenParams-dictionary
+type RSAHashedKeyGenParams struct {
+	Algorithm
+
+	// ModulusLength holds (a Number) the length of the RSA modulus, in bits.
+	// This should be at least 2048. Some organizations are now recommending
+	// that it should be 4096.
+	ModulusLength int
+
+	// PublicExponent holds (a Uint8Array) the public exponent to use,
+	// as a big-endian unsigned integer. Note that only 65537, represented
+	// as [0x01, 0x00, 0x01], is supported by the Go standard library.
+	PublicExponent []byte
+
+	// Hash represents the name of the digest function to use. You can
+	// use any of the following: [Sha1], [Sha256], [Sha384], or [Sha512].
+	Hash Algorithm
+}
+
+// newRSAHashedKeyGenParams creates a new RSAHashedKeyGenParams object, from the
+// normalized algorithm, and the params parameters passed by the user.
+func newRSAHashedKeyGenParams(
+	rt *goja.Runtime,
+	normalized Algorithm,
+	params goja.Value,
+) (*RSAHashedKeyGenParams, error) {
+	modulusLengthValue, err := traverseObject(rt, params, "modulusLength")
+	if err != nil {
+		return nil, NewError(0, SyntaxError, "could not get modulusLength from algorithm parameter")
+	}
+
+	publicExponentValue, err := traverseObject(rt, params, "publicExponent")
+	if err != nil {
+		return nil, NewError(0, SyntaxError, "could not get publicExponent from algorithm parameter")
+	}
+
+	publicExponent, err := exportArrayBuffer(rt, publicExponentValue)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := extractHash(rt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RSAHashedKeyGenParams{
+		Algorithm:      normalized,
+		ModulusLength:  int(modulusLengthValue.ToInteger()),
+		PublicExponent: publicExponent,
+		Hash:           hash,
+	}, nil
+}
+
+// GenerateKey generates a new RSA key pair, as described in the
+// generate key operations of the [specification].
+//
+// [specification]: https://www.w3.org/TR/WebCryptoAPI/#rsassa-pkcs1-operations
+func (rkgp *RSAHashedKeyGenParams) GenerateKey(
+	extractable bool,
+	keyUsages []CryptoKeyUsage,
+) (CryptoKeyGenerationResult, error) {
+	// 1.
+	publicUsages, privateUsages := rsaKeyUsages(rkgp.Name)
+	for _, usage := range keyUsages {
+		if !contains(publicUsages, usage) && !contains(privateUsages, usage) {
+			return nil, NewError(0, SyntaxError, "invalid key usage: "+usage)
+		}
+	}
+
+	// 2.
+	if new(big.Int).SetBytes(rkgp.PublicExponent).Int64() != 65537 {
+		return nil, NewError(0, OperationError, "only the 65537 public exponent is supported")
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, rkgp.ModulusLength)
+	if err != nil {
+		// 3.
+		return nil, NewError(0, OperationError, "failed to generate RSA key pair; reason: "+err.Error())
+	}
+
+	// 4. to 10.
+	algorithm := newRSAHashedKeyAlgorithm(rkgp.Name, rkgp.Hash.Name, &privateKey.PublicKey)
+
+	// 11. to 14.
+	publicKey := &CryptoKey{
+		Type:        PublicCryptoKeyType,
+		Extractable: true,
+		Algorithm:   algorithm,
+		Usages:      UsageIntersection(keyUsages, publicUsages),
+		handle:      &privateKey.PublicKey,
+	}
+
+	// 15. to 18.
+	private := &CryptoKey{
+		Type:        PrivateCryptoKeyType,
+		Extractable: extractable,
+		Algorithm:   algorithm,
+		Usages:      UsageIntersection(keyUsages, privateUsages),
+		handle:      privateKey,
+	}
+
+	// 19. 20. 21.
+	return &CryptoKeyPair{PublicKey: publicKey, PrivateKey: private}, nil
+}
+
+// Ensure that RSAHashedKeyGenParams implements the KeyGenerator interface.
+var _ KeyGenerator = &RSAHashedKeyGenParams{}
+
+// rsaKeyUsages returns the usages that are valid for the public and the
+// private keys of the given RSA algorithm.
+func rsaKeyUsages(name AlgorithmIdentifier) (public []CryptoKeyUsage, private []CryptoKeyUsage) {
+	if name == RSAOaep {
+		return []CryptoKeyUsage{EncryptCryptoKeyUsage, WrapKeyCryptoKeyUsage},
+			[]CryptoKeyUsage{DecryptCryptoKeyUsage, UnwrapKeyCryptoKeyUsage}
+	}
+
+	return []CryptoKeyUsage{VerifyCryptoKeyUsage}, []CryptoKeyUsage{SignCryptoKeyUsage}
+}
+
+// RSAHashedKeyAlgorithm represents the algorithm of an RSA key,
+// as defined in the [specification].
+//
+// [specification]: https://www.w3.org/TR/WebCryptoAPI/#RsaHashedKeyAlgorithm-dictionary
+type RSAHashedKeyAlgorithm struct {
+	KeyAlgorithm
+
+	// ModulusLength holds the length of the RSA modulus, in bits.
+	ModulusLength int `json:"modulusLength"`
+
+	// PublicExponent holds the public exponent, as a big-endian unsigned integer.
+	PublicExponent []byte `json:"publicExponent"`
+
+	// Hash represents the hash function used by the key.
+	Hash KeyAlgorithm `json:"hash"`
+}
+
+func newRSAHashedKeyAlgorithm(name, hash AlgorithmIdentifier, publicKey *rsa.PublicKey) RSAHashedKeyAlgorithm {
+	return RSAHashedKeyAlgorithm{
+		KeyAlgorithm:   KeyAlgorithm{Algorithm{Name: name}},
+		ModulusLength:  publicKey.N.BitLen(),
+		PublicExponent: big.NewInt(int64(publicKey.E)).Bytes(),
+		Hash:           KeyAlgorithm{Algorithm{Name: hash}},
+	}
+}
+
+// RSAHashedImportParams represents the object that should be passed as the
+// algorithm parameter into `SubtleCrypto.ImportKey` or `SubtleCrypto.UnwrapKey`, when
+// importing any RSA-based key pair: that is, when the algorithm is identified as any
+// of RSASSA-PKCS1-v1_5, RSA-PSS, or RSA-OAEP.
+type RSAHashedImportParams struct {
+	Algorithm
+
+	// Hash represents the name of the digest function to use.
+	// Note that although you can technically pass SHA-1 here, this is strongly
+	// discouraged as it is considered vulnerable.
+	Hash Algorithm
+}
+
+// newRSAHashedImportParams creates a new RSAHashedImportParams object from the given
+// algorithm and params objects.
+func newRSAHashedImportParams(
+	rt *goja.Runtime,
+	normalized Algorithm,
+	params goja.Value,
+) (*RSAHashedImportParams, error) {
+	hash, err := extractHash(rt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RSAHashedImportParams{Algorithm: normalized, Hash: hash}, nil
+}
+
+// ImportKey imports an RSA key from its SubjectPublicKeyInfo, PKCS #8, or
+// JSON Web Key representation. It implements the KeyImporter interface.
+func (rip *RSAHashedImportParams) ImportKey(
+	format KeyFormat,
+	keyData []byte,
+	keyUsages []CryptoKeyUsage,
+) (*CryptoKey, error) {
+	var handle any
+	var err error
+
+	switch format {
+	case SpkiKeyFormat:
+		handle, err = x509.ParsePKIXPublicKey(keyData)
+	case Pkcs8KeyFormat:
+		handle, err = x509.ParsePKCS8PrivateKey(keyData)
+	case JwkKeyFormat:
+		handle, err = rip.importJWK(keyData, keyUsages)
+	default:
+		return nil, NewError(0, NotSupportedError, "unsupported key format "+format)
+	}
+
+	if err != nil {
+		if _, ok := err.(*Error); ok { //nolint:errorlint
+			return nil, err
+		}
+		return nil, NewError(0, DataError, "unable to parse the RSA key: "+err.Error())
+	}
+
+	publicUsages, privateUsages := rsaKeyUsages(rip.Name)
+	key := &CryptoKey{handle: handle}
+
+	switch k := handle.(type) {
+	case *rsa.PublicKey:
+		key.Type = PublicCryptoKeyType
+		key.Algorithm = newRSAHashedKeyAlgorithm(rip.Name, rip.Hash.Name, k)
+		err = validateUsages(keyUsages, publicUsages)
+	case *rsa.PrivateKey:
+		key.Type = PrivateCryptoKeyType
+		key.Algorithm = newRSAHashedKeyAlgorithm(rip.Name, rip.Hash.Name, &k.PublicKey)
+		err = validateUsages(keyUsages, privateUsages)
+	default:
+		return nil, NewError(0, DataError, "the key data does not describe an RSA key")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// importJWK returns the RSA public or private key of a JSON Web Key.
+func (rip *RSAHashedImportParams) importJWK(keyData []byte, keyUsages []CryptoKeyUsage) (any, error) {
+	use := "sig"
+	if rip.Name == RSAOaep {
+		use = "enc"
+	}
+
+	jwk, err := parseJWK(keyData, "RSA", use, keyUsages)
+	if err != nil {
+		return nil, err
+	}
+
+	alg, err := rsaJWKAlgorithm(rip.Name, rip.Hash.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if jwk.Alg != "" && jwk.Alg != alg {
+		return nil, NewError(0, DataError, "invalid JSON Web Key algorithm "+jwk.Alg)
+	}
+
+	n, err := decodeBigInt("n", jwk.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := decodeBigInt("e", jwk.E)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey := rsa.PublicKey{N: n, E: int(e.Int64())}
+	if jwk.D == "" {
+		return &publicKey, nil
+	}
+
+	privateKey := &rsa.PrivateKey{PublicKey: publicKey}
+	if privateKey.D, err = decodeBigInt("d", jwk.D); err != nil {
+		return nil, err
+	}
+
+	// The other primes info, for the keys with more than two primes, is not supported.
+	p, err := decodeBigInt("p", jwk.P)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := decodeBigInt("q", jwk.Q)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey.Primes = []*big.Int{p, q}
+	privateKey.Precompute()
+	if err := privateKey.Validate(); err != nil {
+		return nil, NewError(0, DataError, "invalid RSA private key: "+err.Error())
+	}
+
+	return privateKey, nil
+}
+
+// Ensure that RSAHashedImportParams implements the KeyImporter interface.
+var _ KeyImporter = &RSAHashedImportParams{}
+
+// rsaJWKAlgorithm returns the JSON Web Key algorithm of an RSA key of the
+// given algorithm, and using the given hash algorithm.
+func rsaJWKAlgorithm(name, hash AlgorithmIdentifier) (string, error) {
+	var suffix string
+	switch hash {
+	case Sha1:
+		suffix = "1"
+	case Sha256:
+		suffix = "256"
+	case Sha384:
+		suffix = "384"
+	case Sha512:
+		suffix = "512"
+	default:
+		return "", NewError(0, NotSupportedError, "unsupported hash algorithm "+hash)
+	}
+
+	switch name {
+	case RSASsaPkcs1v15:
+		return "RS" + suffix, nil
+	case RSAPss:
+		return "PS" + suffix, nil
+	default:
+		if hash == Sha1 {
+			return "RSA-OAEP", nil
+		}
+		return "RSA-OAEP-" + suffix, nil
+	}
+}
+
+// exportRSAKey exports an RSA key to its SubjectPublicKeyInfo, PKCS #8, or
+// JSON Web Key representation.
+func exportRSAKey(ck *CryptoKey, format KeyFormat) ([]byte, error) {
+	// 1.
+	if ck.handle == nil {
+		return nil, NewError(0, OperationError, "key data is not accesible")
+	}
+
+	switch format {
+	case SpkiKeyFormat:
+		// 3.1.
+		if ck.Type != PublicCryptoKeyType {
+			return nil, NewError(0, InvalidAccessError, "only public keys can be exported in the spki format")
+		}
+
+		return marshalKey(x509.MarshalPKIXPublicKey(ck.handle))
+	case Pkcs8KeyFormat:
+		// 3.1.
+		if ck.Type != PrivateCryptoKeyType {
+			return nil, NewError(0, InvalidAccessError, "only private keys can be exported in the pkcs8 format")
+		}
+
+		return marshalKey(x509.MarshalPKCS8PrivateKey(ck.handle))
+	case JwkKeyFormat:
+		return exportRSAJWK(ck)
+	default:
+		return nil, NewError(0, NotSupportedError, "unsupported key format "+format)
+	}
+}
+
+func exportRSAJWK(ck *CryptoKey) ([]byte, error) {
+	algorithm, ok := ck.Algorithm.(RSAHashedKeyAlgorithm)
+	if !ok {
+		return nil, NewError(0, ImplementationError, "key algorithm does not describe an RSA key")
+	}
+
+	alg, err := rsaJWKAlgorithm(algorithm.Name, algorithm.Hash.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	jwk := &JSONWebKey{Kty: "RSA", Alg: alg}
+
+	var publicKey *rsa.PublicKey
+	switch k := ck.handle.(type) {
+	case *rsa.PublicKey:
+		publicKey = k
+	case *rsa.PrivateKey:
+		publicKey = &k.PublicKey
+		jwk.D = encodeBase64URL(k.D.Bytes())
+		jwk.P = encodeBase64URL(k.Primes[0].Bytes())
+		jwk.Q = encodeBase64URL(k.Primes[1].Bytes())
+		jwk.DP = encodeBase64URL(k.Precomputed.Dp.Bytes())
+		jwk.DQ = encodeBase64URL(k.Precomputed.Dq.Bytes())
+		jwk.QI = encodeBase64URL(k.Precomputed.Qinv.Bytes())
+	default:
+		return nil, NewError(0, ImplementationError, "key handle is of incorrect type")
+	}
+
+	jwk.N = encodeBase64URL(publicKey.N.Bytes())
+	jwk.E = encodeBase64URL(big.NewInt(int64(publicKey.E)).Bytes())
+
+	return marshalJWK(jwk, ck)
+}
+
+// marshalKey wraps the result of the marshaling of a key by the x509 package.
+func marshalKey(b []byte, err error) ([]byte, error) {
+	if err != nil {
+		return nil, NewError(0, OperationError, "unable to export the key: "+err.Error())
+	}
+
+	return b, nil
+}
+
+// validateUsages returns a SyntaxError if any of the usages is not one of the
+// valid ones.
+func validateUsages(keyUsages []CryptoKeyUsage, validUsages []CryptoKeyUsage) error {
+	for _, usage := range keyUsages {
+		if !contains(validUsages, usage) {
+			return NewError(0, SyntaxError, "invalid key usage: "+usage)
+		}
+	}
+
+	return nil
+}
+
+// rsaSsaPkcs1v15SignerVerifier signs and verifies data using the
+// RSASSA-PKCS1-v1_5 algorithm, with the hash algorithm of the key.
+type rsaSsaPkcs1v15SignerVerifier struct{}
+
+// Sign implements the SignerVerifier interface.
+func (rsaSsaPkcs1v15SignerVerifier) Sign(key CryptoKey, data []byte) ([]byte, error) {
+	privateKey, hash, digest, err := rsaPrivateKeyAndDigest(key, data)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, hash, digest)
+	if err != nil {
+		return nil, NewError(0, OperationError, "unable to sign the data: "+err.Error())
+	}
+
+	return signature, nil
+}
+
+// Verify implements the SignerVerifier interface.
+func (rsaSsaPkcs1v15SignerVerifier) Verify(key CryptoKey, signature, data []byte) (bool, error) {
+	publicKey, hash, digest, err := rsaPublicKeyAndDigest(key, data)
+	if err != nil {
+		return false, err
+	}
+
+	return rsa.VerifyPKCS1v15(publicKey, hash, digest, signature) == nil, nil
+}
+
+// RSAPssParams represents the object that should be passed as the algorithm
+// parameter into `SubtleCrypto.Sign` or `SubtleCrypto.Verify`, when using the
+// RSA-PSS algorithm.
+type RSAPssParams struct {
+	Algorithm
+
+	// SaltLength holds (a Number) the length of the random salt to use, in bytes.
+	// RFC 3447 says that "typical salt lengths" are either 0 or the length of the output
+	// of the digest algorithm selected whe this key was generated. For instance,
+	// when using the SHA256 digest algorithm, the salt length could be 32.
+	//
+	// Note that a salt length of 0 is not supported, as the Go standard library
+	// interprets it as the maximum salt length.
+	SaltLength int
+}
+
+func newRSAPssParams(rt *goja.Runtime, normalized Algorithm, params goja.Value) (*RSAPssParams, error) {
+	saltLengthValue, err := traverseObject(rt, params, "saltLength")
+	if err != nil {
+		return nil, NewError(0, SyntaxError, "could not get saltLength from algorithm parameter")
+	}
+
+	saltLength := int(saltLengthValue.ToInteger())
+	if saltLength <= 0 {
+		return nil, NewError(0, NotSupportedError, "the salt length needs to be greater than 0")
+	}
+
+	return &RSAPssParams{Algorithm: normalized, SaltLength: saltLength}, nil
+}
+
+// Sign implements the SignerVerifier interface.
+func (rpp *RSAPssParams) Sign(key CryptoKey, data []byte) ([]byte, error) {
+	privateKey, hash, digest, err := rsaPrivateKeyAndDigest(key, data)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := rsa.SignPSS(rand.Reader, privateKey, hash, digest, &rsa.PSSOptions{SaltLength: rpp.SaltLength})
+	if err != nil {
+		return nil, NewError(0, OperationError, "unable to sign the data: "+err.Error())
+	}
+
+	return signature, nil
+}
+
+// Verify implements the SignerVerifier interface.
+func (rpp *RSAPssParams) Verify(key CryptoKey, signature, data []byte) (bool, error) {
+	publicKey, hash, digest, err := rsaPublicKeyAndDigest(key, data)
+	if err != nil {
+		return false, err
+	}
+
+	return rsa.VerifyPSS(publicKey, hash, digest, signature, &rsa.PSSOptions{SaltLength: rpp.SaltLength}) == nil, nil
+}
+
+// RSAOaepParams represents the object that should be passed as the algorithm parameter
+// into `SubtleCrypto.Encrypt`, `SubtleCrypto.Decrypt`, `SubtleCrypto.WrapKey`, or
+// `SubtleCrypto.UnwrapKey`, when using the RSA_OAEP algorithm.
+type RSAOaepParams struct {
+	Algorithm
+
+	// Label holds (an ArrayBuffer, a TypedArray, or a DataView) an array of bytes that does not
+	// itself need to be encrypted but which should be bound to the ciphertext.
+	// A digest of the label is part of the input to the encryption operation.
+	//
+	// Unless your application calls for a label, you can just omit this argument
+	// and it will not affect the security of the encryption operation.
+	Label []byte
+}
+
+func newRSAOaepParams(rt *goja.Runtime, normalized Algorithm, params goja.Value) (*RSAOaepParams, error) {
+	var label []byte
+	if labelValue, err := traverseObject(rt, params, "label"); err == nil {
+		if label, err = exportArrayBuffer(rt, labelValue); err != nil {
+			return nil, err
+		}
+	}
+
+	return &RSAOaepParams{Algorithm: normalized, Label: label}, nil
+}
+
+// Encrypt encrypts the given plaintext using the RSA-OAEP algorithm, with the
+// hash algorithm of the key. It implements the EncryptDecrypter interface.
+func (rop *RSAOaepParams) Encrypt(plaintext []byte, key CryptoKey) ([]byte, error) {
+	// 1.
+	if key.Type != PublicCryptoKeyType {
+		return nil, NewError(0, InvalidAccessError, "the key is not a public key")
+	}
+
+	publicKey, ok := key.handle.(*rsa.PublicKey)
+	if !ok {
+		return nil, NewError(0, InvalidAccessError, "key handle is of incorrect type")
+	}
+
+	hashFn, err := rsaKeyHashFn(key)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := rsa.EncryptOAEP(hashFn(), rand.Reader, publicKey, plaintext, rop.Label)
+	if err != nil {
+		return nil, NewError(0, OperationError, "unable to encrypt the data: "+err.Error())
+	}
+
+	return ciphertext, nil
+}
+
+// Decrypt decrypts the given ciphertext using the RSA-OAEP algorithm, with the
+// hash algorithm of the key. It implements the EncryptDecrypter interface.
+func (rop *RSAOaepParams) Decrypt(ciphertext []byte, key CryptoKey) ([]byte, error) {
+	// 1.
+	if key.Type != PrivateCryptoKeyType {
+		return nil, NewError(0, InvalidAccessError, "the key is not a private key")
+	}
+
+	privateKey, ok := key.handle.(*rsa.PrivateKey)
+	if !ok {
+		return nil, NewError(0, InvalidAccessError, "key handle is of incorrect type")
+	}
+
+	hashFn, err := rsaKeyHashFn(key)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := rsa.DecryptOAEP(hashFn(), rand.Reader, privateKey, ciphertext, rop.Label)
+	if err != nil {
+		return nil, NewError(0, OperationError, "unable to decrypt the data: "+err.Error())
+	}
+
+	return plaintext, nil
+}
+
+// Ensure that RSAOaepParams implements the EncryptDecrypter interface.
+var _ EncryptDecrypter = &RSAOaepParams{}
+
+func rsaKeyHashFn(key CryptoKey) (func() hash.Hash, error) {
+	algorithm, ok := key.Algorithm.(RSAHashedKeyAlgorithm)
+	if !ok {
+		return nil, NewError(0, InvalidAccessError, "key algorithm does not describe an RSA key")
+	}
+
+	hashFn, ok := getHashFn(algorithm.Hash.Name)
+	if !ok {
+		return nil, NewError(0, NotSupportedError, "unsupported key hash algorithm "+algorithm.Hash.Name)
+	}
+
+	return hashFn, nil
+}
+
+// rsaDigest returns the hash algorithm of the RSA key, and the digest of the data.
+func rsaDigest(key CryptoKey, data []byte) (crypto.Hash, []byte, error) {
+	algorithm, ok := key.Algorithm.(RSAHashedKeyAlgorithm)
+	if !ok {
+		return 0, nil, NewError(0, InvalidAccessError, "key algorithm does not describe an RSA key")
+	}
+
+	hash, ok := getHash(algorithm.Hash.Name)
+	if !ok {
+		return 0, nil, NewError(0, NotSupportedError, "unsupported key hash algorithm "+algorithm.Hash.Name)
+	}
+
+	hasher := hash.New()
+	hasher.Write(data)
+
+	return hash, hasher.Sum(nil), nil
+}
+
+func rsaPrivateKeyAndDigest(key CryptoKey, data []byte) (*rsa.PrivateKey, crypto.Hash, []byte, error) {
+	// 1.
+	if key.Type != PrivateCryptoKeyType {
+		return nil, 0, nil, NewError(0, InvalidAccessError, "the key is not a private key")
+	}
+
+	privateKey, ok := key.handle.(*rsa.PrivateKey)
+	if !ok {
+		return nil, 0, nil, NewError(0, InvalidAccessError, "key handle is of incorrect type")
+	}
+
+	hash, digest, err := rsaDigest(key, data)
+	return privateKey, hash, digest, err
+}
+
+func rsaPublicKeyAndDigest(key CryptoKey, data []byte) (*rsa.PublicKey, crypto.Hash, []byte, error) {
+	// 1.
+	if key.Type != PublicCryptoKeyType {
+		return nil, 0, nil, NewError(0, InvalidAccessError, "the key is not a public key")
+	}
+
+	publicKey, ok := key.handle.(*rsa.PublicKey)
+	if !ok {
+		return nil, 0, nil, NewError(0, InvalidAccessError, "key handle is of incorrect type")
+	}
+
+	hash, digest, err := rsaDigest(key, data)
+	return publicKey, hash, digest, err
+}
@@ -0,0 +1,38 @@
+package webcrypto
+
+import (
+	"github.com/dop251/goja"
+)
+
+// SignerVerifier is the interface implemented by the algorithms used to sign
+// data, and to verify signatures.
+type SignerVerifier interface {
+	Sign(key CryptoKey, data []byte) ([]byte, error)
+	Verify(key CryptoKey, signature []byte, data []byte) (bool, error)
+}
+
+// newSignerVerifier instantiates a SignerVerifier based on the provided
+// algorithm and parameters `goja.Value`.
+func newSignerVerifier(rt *goja.Runtime, normalized Algorithm, params goja.Value) (SignerVerifier, error) {
+	var sv SignerVerifier
+	var err error
+
+	switch normalized.Name {
+	case HMAC:
+		sv = hmacSignerVerifier{}
+	case RSASsaPkcs1v15:
+		sv = rsaSsaPkcs1v15SignerVerifier{}
+	case RSAPss:
+		sv, err = newRSAPssParams(rt, normalized, params)
+	case ECDSA:
+		sv, err = newECDSAParams(rt, normalized, params)
+	default:
+		return nil, NewError(0, NotSupportedError, "unsupported algorithm for signing: "+normalized.Name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sv, nil
+}
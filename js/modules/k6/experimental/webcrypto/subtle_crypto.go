@@ -1,12 +1,13 @@
 package webcrypto
 
 import (
-	"crypto/hmac"
+	"encoding/json"
 	"errors"
 	"fmt"
 
 	"github.com/dop251/goja"
 	"go.k6.io/k6/js/modules"
+	"gopkg.in/guregu/null.v3"
 )
 
 // FIXME: SubtleCrypto is described as an "interface", should it be a nested module
@@ -88,7 +89,7 @@ func (sc *SubtleCrypto) Encrypt(algorithm, key, data goja.Value) *goja.Promise {
 		var ciphertext []byte
 
 		switch normalized.Name {
-		case AESCbc, AESCtr, AESGcm:
+		case AESCbc, AESCtr, AESGcm, RSAOaep:
 			// 10.
 			ciphertext, err = encrypter.Encrypt(plaintext, ck)
 			if err != nil {
@@ -180,7 +181,7 @@ func (sc *SubtleCrypto) Decrypt(algorithm, key, data goja.Value) *goja.Promise {
 		var plaintext []byte
 
 		switch normalized.Name {
-		case AESCbc, AESCtr, AESGcm:
+		case AESCbc, AESCtr, AESGcm, RSAOaep:
 			// 10.
 			plaintext, err = decrypter.Decrypt(ciphertext, ck)
 			if err != nil {
@@ -248,6 +249,12 @@ func (sc *SubtleCrypto) Sign(algorithm, key, data goja.Value) *goja.Promise {
 		return promise
 	}
 
+	signer, err := newSignerVerifier(rt, normalized, algorithm)
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
 	go func() {
 		// 8.
 		if normalized.Name != keyAlgorithmNameValue.String() {
@@ -256,42 +263,19 @@ func (sc *SubtleCrypto) Sign(algorithm, key, data goja.Value) *goja.Promise {
 		}
 
 		// 9.
-		for !ck.ContainsUsage(SignCryptoKeyUsage) {
+		if !ck.ContainsUsage(SignCryptoKeyUsage) {
 			reject(NewError(0, InvalidAccessError, "key does not contain the 'sign' usage"))
 			return
 		}
 
 		// 10.
-		switch normalized.Name {
-		case HMAC:
-			keyAlgorithm, ok := ck.Algorithm.(HmacKeyAlgorithm)
-			if !ok {
-				reject(NewError(0, InvalidAccessError, "key algorithm does not describe a HMAC key"))
-				return
-			}
-
-			keyHandle, ok := ck.handle.([]byte)
-			if !ok {
-				reject(NewError(0, InvalidAccessError, "key handle is of incorrect type"))
-				return
-			}
-
-			hashFn, err := keyAlgorithm.HashFn()
-			if err != nil {
-				reject(err)
-				return
-			}
-
-			hasher := hmac.New(hashFn, keyHandle)
-			hasher.Write(dataToSign)
-
-			// 10.
-			mac := hasher.Sum(nil)
-
-			resolve(rt.NewArrayBuffer(mac))
-		default:
-			reject(NewError(0, NotSupportedError, fmt.Sprintf("unsupported algorithm %q", normalized.Name)))
+		signature, err := signer.Sign(ck, dataToSign)
+		if err != nil {
+			reject(err)
+			return
 		}
+
+		resolve(rt.NewArrayBuffer(signature))
 	}()
 
 	return promise
@@ -357,6 +341,12 @@ func (sc *SubtleCrypto) Verify(algorithm, key, signature, data goja.Value) *goja
 		return promise
 	}
 
+	verifier, err := newSignerVerifier(rt, normalizedAlgorithm, algorithm)
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
 	go func() {
 		// 9.
 		if normalizedAlgorithm.Name != keyAlgorithmNameValue.String() {
@@ -365,38 +355,19 @@ func (sc *SubtleCrypto) Verify(algorithm, key, signature, data goja.Value) *goja
 		}
 
 		// 10.
-		for !ck.ContainsUsage(VerifyCryptoKeyUsage) {
-			reject(NewError(0, InvalidAccessError, "key does not contain the 'sign' usage"))
+		if !ck.ContainsUsage(VerifyCryptoKeyUsage) {
+			reject(NewError(0, InvalidAccessError, "key does not contain the 'verify' usage"))
 			return
 		}
 
-		switch normalizedAlgorithm.Name {
-		case HMAC:
-			keyAlgorithm, ok := ck.Algorithm.(HmacKeyAlgorithm)
-			if !ok {
-				reject(NewError(0, InvalidAccessError, "key algorithm does not describe a HMAC key"))
-				return
-			}
-
-			keyHandle, ok := ck.handle.([]byte)
-			if !ok {
-				reject(NewError(0, InvalidAccessError, "key handle is of incorrect type"))
-				return
-			}
-
-			hashFn, err := keyAlgorithm.HashFn()
-			if err != nil {
-				reject(err)
-				return
-			}
-
-			hasher := hmac.New(hashFn, keyHandle)
-			hasher.Write(signedData)
-
-			resolve(hmac.Equal(signatureData, hasher.Sum(nil)))
-		default:
-			reject(NewError(0, NotSupportedError, fmt.Sprintf("unsupported algorithm %q", normalizedAlgorithm.Name)))
+		// 11.
+		verified, err := verifier.Verify(ck, signatureData, signedData)
+		if err != nil {
+			reject(err)
+			return
 		}
+
+		resolve(verified)
 	}()
 
 	return promise
@@ -528,9 +499,11 @@ func (sc *SubtleCrypto) GenerateKey(algorithm goja.Value, extractable bool, keyU
 		}
 
 		// 8.
-		isSecretKey := result.Type == SecretCryptoKeyType
-		isPrivateKey := result.Type == PrivateCryptoKeyType
-		isUsagesEmpty := len(result.Usages) == 0
+		// For key pairs, the check applies to the private key.
+		resolved := result.ResolveCryptoKey()
+		isSecretKey := resolved.Type == SecretCryptoKeyType
+		isPrivateKey := resolved.Type == PrivateCryptoKeyType
+		isUsagesEmpty := len(resolved.Usages) == 0
 		if (isSecretKey || isPrivateKey) && isUsagesEmpty {
 			reject(NewError(0, SyntaxError, "usages cannot not be empty for a secret or private CryptoKey"))
 			return
@@ -580,8 +553,105 @@ func (sc *SubtleCrypto) DeriveKey(
 	extractable bool,
 	keyUsages []CryptoKeyUsage,
 ) *goja.Promise {
-	// TODO: implementation
-	return nil
+	rt := sc.vu.Runtime()
+	promise, resolve, reject := sc.makeHandledPromise()
+
+	// 2.
+	normalized, err := normalizeAlgorithm(rt, algorithm, OperationIdentifierDeriveBits)
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
+	// 4.
+	normalizedDerivedKeyAlgorithm, err := normalizeAlgorithm(rt, derivedKeyAlgorithm, OperationIdentifierImportKey)
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
+	// 6.
+	if _, err = normalizeAlgorithm(rt, derivedKeyAlgorithm, OperationIdentifierGetKeyLength); err != nil {
+		reject(err)
+		return promise
+	}
+
+	ck, ok := baseKey.Export().(*CryptoKey)
+	if !ok {
+		reject(NewError(0, InvalidAccessError, "baseKey argument does hold not a valid CryptoKey object"))
+		return promise
+	}
+
+	keyAlgorithmNameValue, err := traverseObject(rt, baseKey, "algorithm", "name")
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
+	deriver, err := newBitsDeriver(rt, normalized, algorithm)
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
+	// 15.
+	length, err := getKeyLength(rt, normalizedDerivedKeyAlgorithm, derivedKeyAlgorithm)
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
+	importer, err := newKeyImporter(rt, normalizedDerivedKeyAlgorithm, derivedKeyAlgorithm)
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
+	go func() {
+		// 11.
+		if normalized.Name != keyAlgorithmNameValue.String() {
+			reject(NewError(0, InvalidAccessError, "algorithm name does not match key algorithm name"))
+			return
+		}
+
+		// 12.
+		if !ck.ContainsUsage(DeriveKeyCryptoKeyUsage) {
+			reject(NewError(0, InvalidAccessError, "key does not contain the 'deriveKey' usage"))
+			return
+		}
+
+		// 16.
+		secret, err := deriver.DeriveBits(*ck, length)
+		if err != nil {
+			reject(err)
+			return
+		}
+
+		// 17.
+		result, err := importer.ImportKey(RawKeyFormat, secret, keyUsages)
+		if err != nil {
+			reject(err)
+			return
+		}
+
+		// 18.
+		isSecretKey := result.Type == SecretCryptoKeyType
+		isPrivateKey := result.Type == PrivateCryptoKeyType
+		if (isSecretKey || isPrivateKey) && len(keyUsages) == 0 {
+			reject(NewError(0, SyntaxError, "usages cannot not be empty for a secret or private CryptoKey"))
+			return
+		}
+
+		// 19.
+		result.Extractable = extractable
+
+		// 20.
+		result.Usages = keyUsages
+
+		resolve(result)
+	}()
+
+	return promise
 }
 
 // DeriveBits derives an array of bits from a base key.
@@ -610,9 +680,66 @@ func (sc *SubtleCrypto) DeriveKey(
 // using `SubtleCrypto.ImportKey`.
 //
 // The `length` parameter is the number of bits to derive. The number should be a multiple of 8.
-func (sc *SubtleCrypto) DeriveBits(algorithm goja.Value, baseKey goja.Value, length int) *goja.Promise {
-	// TODO: implementation
-	return nil
+// For ECDH, it can be null, in which case the whole shared secret is returned.
+func (sc *SubtleCrypto) DeriveBits(algorithm goja.Value, baseKey goja.Value, length goja.Value) *goja.Promise {
+	rt := sc.vu.Runtime()
+	promise, resolve, reject := sc.makeHandledPromise()
+
+	// 2.
+	normalized, err := normalizeAlgorithm(rt, algorithm, OperationIdentifierDeriveBits)
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
+	ck, ok := baseKey.Export().(*CryptoKey)
+	if !ok {
+		reject(NewError(0, InvalidAccessError, "baseKey argument does hold not a valid CryptoKey object"))
+		return promise
+	}
+
+	keyAlgorithmNameValue, err := traverseObject(rt, baseKey, "algorithm", "name")
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
+	deriver, err := newBitsDeriver(rt, normalized, algorithm)
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
+	var bitsLength null.Int
+	if !isNullish(length) {
+		bitsLength = null.IntFrom(length.ToInteger())
+	}
+
+	go func() {
+		// 7.
+		if normalized.Name != keyAlgorithmNameValue.String() {
+			reject(NewError(0, InvalidAccessError, "algorithm name does not match key algorithm name"))
+			return
+		}
+
+		// 8.
+		if !ck.ContainsUsage(DeriveBitsCryptoKeyUsage) {
+			reject(NewError(0, InvalidAccessError, "key does not contain the 'deriveBits' usage"))
+			return
+		}
+
+		// 9.
+		bits, err := deriver.DeriveBits(*ck, bitsLength)
+		if err != nil {
+			reject(err)
+			return
+		}
+
+		// 10.
+		resolve(rt.NewArrayBuffer(bits))
+	}()
+
+	return promise
 }
 
 // ImportKey imports a key: that is, it takes as input a key in an external, portable
@@ -634,7 +761,8 @@ func (sc *SubtleCrypto) DeriveBits(algorithm goja.Value, baseKey goja.Value, len
 //   - for PBKDF2: pass the string "PBKDF2"
 //   - for HKDF: pass the string "HKDF"
 //
-// TODO @oleiade: implement support for JWK format
+// When the `format` parameter is "jwk", the `keyData` parameter should be
+// an object describing a JSON Web Key.
 func (sc *SubtleCrypto) ImportKey(
 	format KeyFormat,
 	keyData goja.Value,
@@ -646,13 +774,11 @@ func (sc *SubtleCrypto) ImportKey(
 	promise, resolve, reject := sc.makeHandledPromise()
 
 	// 2.
-	ab, err := exportArrayBuffer(rt, keyData)
+	keyBytes, err := exportKeyData(rt, format, keyData, extractable)
 	if err != nil {
 		reject(err)
 		return promise
 	}
-	keyBytes := make([]byte, len(ab))
-	copy(keyBytes, ab)
 
 	// 3.
 	normalized, err := normalizeAlgorithm(rt, algorithm, OperationIdentifierImportKey)
@@ -661,6 +787,12 @@ func (sc *SubtleCrypto) ImportKey(
 		return promise
 	}
 
+	// The key material of the key derivation functions can never be extracted.
+	if (normalized.Name == PBKDF2 || normalized.Name == HKDF) && extractable {
+		reject(NewError(0, SyntaxError, "the "+normalized.Name+" keys cannot be extractable"))
+		return promise
+	}
+
 	ki, err := newKeyImporter(rt, normalized, algorithm)
 	if err != nil {
 		reject(err)
@@ -712,8 +844,6 @@ func (sc *SubtleCrypto) ImportKey(
 //
 // The `format` parameter identifies the format of the key data.
 // The `key` parameter is the key to export, as a CryptoKey object.
-//
-// TODO @oleiade: implement support for JWK format
 func (sc *SubtleCrypto) ExportKey(format KeyFormat, key goja.Value) *goja.Promise {
 	rt := sc.vu.Runtime()
 	promise, resolve, reject := sc.makeHandledPromise()
@@ -754,24 +884,37 @@ func (sc *SubtleCrypto) ExportKey(format KeyFormat, key goja.Value) *goja.Promis
 		var err error
 
 		switch keyAlgorithmName {
-		case AESCbc, AESCtr, AESGcm:
+		case AESCbc, AESCtr, AESGcm, AESKw:
 			result, err = exportAESKey(ck, format)
-			if err != nil {
-				reject(err)
-				return
-			}
 		case HMAC:
 			result, err = exportHmacKey(ck, format)
-			if err != nil {
-				reject(err)
-				return
-			}
+		case RSASsaPkcs1v15, RSAPss, RSAOaep:
+			result, err = exportRSAKey(ck, format)
+		case ECDSA, ECDH:
+			result, err = exportECKey(ck, format)
 		default:
 			reject(NewError(0, NotSupportedError, "unsupported algorithm "+keyAlgorithmName))
 			return
 		}
 
-		resolve(rt.NewArrayBuffer(result))
+		if err != nil {
+			reject(err)
+			return
+		}
+
+		if format != JwkKeyFormat {
+			resolve(rt.NewArrayBuffer(result))
+			return
+		}
+
+		// The JSON Web Keys are exported as objects.
+		var jwk map[string]interface{}
+		if err := json.Unmarshal(result, &jwk); err != nil {
+			reject(NewError(0, ImplementationError, "unable to unmarshal the JSON Web Key: "+err.Error()))
+			return
+		}
+
+		resolve(jwk)
 	}()
 
 	return promise
@@ -869,3 +1012,28 @@ func (sc *SubtleCrypto) UnwrapKey(
 	// TODO: implementation
 	return nil
 }
+
+// exportKeyData returns a copy of the key data passed to `SubtleCrypto.ImportKey`.
+// For the "jwk" format, the key data is an object, that is returned in its JSON
+// representation, otherwise it is an ArrayBuffer, a TypedArray or a DataView.
+func exportKeyData(rt *goja.Runtime, format KeyFormat, keyData goja.Value, extractable bool) ([]byte, error) {
+	if format != JwkKeyFormat {
+		return exportArrayBuffer(rt, keyData)
+	}
+
+	if isNullish(keyData) {
+		return nil, NewError(0, TypeError, "key data is null or undefined")
+	}
+
+	// A JSON Web Key explicitly marked as not extractable cannot be imported as an extractable key.
+	if ext := keyData.ToObject(rt).Get("ext"); !isNullish(ext) && !ext.ToBoolean() && extractable {
+		return nil, NewError(0, DataError, "the JSON Web Key is not extractable")
+	}
+
+	b, err := json.Marshal(keyData.Export())
+	if err != nil {
+		return nil, NewError(0, TypeError, "key data is not a valid JSON Web Key: "+err.Error())
+	}
+
+	return b, nil
+}
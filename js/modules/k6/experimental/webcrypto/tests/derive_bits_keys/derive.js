@@ -0,0 +1,136 @@
+// This file contains the tests of the deriveBits and deriveKey
+// operations of the PBKDF2, HKDF, and ECDH algorithms.
+//
+// The PBKDF2 and HKDF expectations are the test vectors of RFC 6070
+// and RFC 5869, respectively.
+
+var subtle = crypto.subtle;
+
+var kdfVectors = [
+    {
+        name: "PBKDF2 SHA-1 RFC 6070 test case 2",
+        key: stringToBytes("password"),
+        params: {name: "PBKDF2", hash: "SHA-1", salt: stringToBytes("salt"), iterations: 2},
+        length: 160,
+        expected: "ea6c014dc72d6f8ccd1ed92ace1d41f0d8de8957"
+    },
+    {
+        name: "PBKDF2 SHA-256",
+        key: stringToBytes("password"),
+        params: {name: "PBKDF2", hash: "SHA-256", salt: stringToBytes("salt"), iterations: 1},
+        length: 256,
+        expected: "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"
+    },
+    {
+        name: "HKDF SHA-256 RFC 5869 test case 1",
+        key: hexToBytes("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b"),
+        params: {
+            name: "HKDF",
+            hash: "SHA-256",
+            salt: hexToBytes("000102030405060708090a0b0c"),
+            info: hexToBytes("f0f1f2f3f4f5f6f7f8f9")
+        },
+        length: 336,
+        expected: "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865"
+    },
+];
+
+kdfVectors.forEach(function(vector) {
+    subtle.importKey("raw", vector.key, vector.params.name, false, ["deriveBits", "deriveKey"])
+        .then(function(baseKey) {
+            return subtle.deriveBits(vector.params, baseKey, vector.length)
+                .then(function(bits) {
+                    assert_equals(bytesToHex(bits), vector.expected, vector.name + " derives the expected bits");
+                    return subtle.deriveBits(vector.params, baseKey, 12);
+                })
+                .then(function() {
+                    assert_unreached(vector.name + " should not derive a number of bits that is not a multiple of 8");
+                }, function(err) {
+                    assert_equals(err.name, "OperationError", vector.name + " fails to derive 12 bits");
+                    return subtle.deriveKey(vector.params, baseKey, {name: "HMAC", hash: "SHA-256", length: 256}, true, ["sign"]);
+                })
+                .then(function(key) {
+                    assert_equals(key.algorithm.name, "HMAC", vector.name + " derives an HMAC key");
+                    assert_equals(key.algorithm.length, 256, vector.name + " derives a key of the expected length");
+                    return subtle.exportKey("raw", key);
+                })
+                .then(function(raw) {
+                    assert_equals(raw.byteLength, 32, vector.name + " derives a key of the expected length");
+                });
+        });
+});
+
+// The key derivation functions keys cannot be extractable.
+subtle.importKey("raw", stringToBytes("password"), "PBKDF2", true, ["deriveBits"])
+    .then(function() {
+        assert_unreached("Importing an extractable PBKDF2 key should have failed");
+    }, function(err) {
+        assert_equals(err.name, "SyntaxError", "Importing an extractable PBKDF2 key fails");
+    });
+
+// Both parties of an ECDH exchange derive the same secret, which can be used as an AES key.
+["P-256", "P-384", "P-521"].forEach(function(namedCurve) {
+    var alice;
+    var bob;
+    var aliceKey;
+    var bobKey;
+
+    Promise.all([
+        subtle.generateKey({name: "ECDH", namedCurve: namedCurve}, true, ["deriveBits", "deriveKey"]),
+        subtle.generateKey({name: "ECDH", namedCurve: namedCurve}, true, ["deriveBits", "deriveKey"]),
+    ])
+        .then(function(pairs) {
+            alice = pairs[0];
+            bob = pairs[1];
+
+            return Promise.all([
+                subtle.deriveBits({name: "ECDH", public: bob.publicKey}, alice.privateKey, null),
+                subtle.deriveBits({name: "ECDH", public: alice.publicKey}, bob.privateKey, null),
+            ]);
+        })
+        .then(function(secrets) {
+            assert_equals(bytesToHex(secrets[0]), bytesToHex(secrets[1]), namedCurve + " shared secrets match");
+
+            return Promise.all([
+                subtle.deriveKey({name: "ECDH", public: bob.publicKey}, alice.privateKey, {name: "AES-GCM", length: 256}, true, ["encrypt", "decrypt"]),
+                subtle.deriveKey({name: "ECDH", public: alice.publicKey}, bob.privateKey, {name: "AES-GCM", length: 256}, true, ["encrypt", "decrypt"]),
+            ]);
+        })
+        .then(function(keys) {
+            aliceKey = keys[0];
+            bobKey = keys[1];
+
+            var iv = new Uint8Array(12);
+            return subtle.encrypt({name: "AES-GCM", iv: iv}, aliceKey, stringToBytes("hello"))
+                .then(function(ciphertext) {
+                    return subtle.decrypt({name: "AES-GCM", iv: iv}, bobKey, ciphertext);
+                });
+        })
+        .then(function(plaintext) {
+            assert_equals(bytesToHex(plaintext), bytesToHex(stringToBytes("hello")), namedCurve + " derived keys match");
+        });
+});
+
+function stringToBytes(s) {
+    var bytes = new Uint8Array(s.length);
+    for (var i = 0; i < s.length; i++) {
+        bytes[i] = s.charCodeAt(i);
+    }
+
+    return bytes;
+}
+
+function hexToBytes(hex) {
+    var bytes = new Uint8Array(hex.length / 2);
+    for (var i = 0; i < bytes.length; i++) {
+        bytes[i] = parseInt(hex.substr(i * 2, 2), 16);
+    }
+
+    return bytes;
+}
+
+function bytesToHex(buffer) {
+    return Array.from(new Uint8Array(buffer)).map(function(b) {
+        return ("0" + b.toString(16)).slice(-2);
+    }).join("");
+}
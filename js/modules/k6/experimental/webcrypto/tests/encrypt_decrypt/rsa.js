@@ -0,0 +1,65 @@
+// This file contains the tests of the encryption and decryption
+// operations of the RSA-OAEP algorithm.
+//
+// As the encryption is not deterministic, the tests encrypt data using freshly
+// generated keys, and check the ciphertexts can be decrypted.
+
+var subtle = crypto.subtle;
+
+var plaintext = new Uint8Array([1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16]);
+var label = new Uint8Array([1, 2, 3, 4]);
+
+[
+    {hash: "SHA-1"},
+    {hash: "SHA-256", label: label},
+].forEach(function(vector) {
+    var keyPair;
+    var params = {name: "RSA-OAEP"};
+    if (vector.label) {
+        params.label = vector.label;
+    }
+
+    var generateParams = {
+        name: "RSA-OAEP",
+        modulusLength: 2048,
+        publicExponent: new Uint8Array([1, 0, 1]),
+        hash: vector.hash
+    };
+
+    subtle.generateKey(generateParams, false, ["encrypt", "decrypt"])
+        .then(function(result) {
+            keyPair = result;
+            return subtle.encrypt(params, keyPair.publicKey, plaintext);
+        })
+        .then(function(ciphertext) {
+            assert_equals(ciphertext.byteLength, 256, "Ciphertext length is correct");
+
+            return subtle.decrypt(params, keyPair.privateKey, ciphertext)
+                .then(function(result) {
+                    assert_true(equalBuffers(result, plaintext.buffer), "Round trip works");
+                    return subtle.decrypt({name: "RSA-OAEP", label: new Uint8Array([5])}, keyPair.privateKey, ciphertext);
+                })
+                .then(function() {
+                    assert_unreached("Decrypting with another label should have failed");
+                }, function(err) {
+                    assert_equals(err.name, "OperationError", "Decrypting with another label fails");
+                });
+        });
+});
+
+function equalBuffers(a, b) {
+    if (a.byteLength !== b.byteLength) {
+        return false;
+    }
+
+    var aBytes = new Uint8Array(a);
+    var bBytes = new Uint8Array(b);
+
+    for (var i = 0; i < a.byteLength; i++) {
+        if (aBytes[i] !== bBytes[i]) {
+            return false;
+        }
+    }
+
+    return true;
+}
@@ -0,0 +1,118 @@
+// This file contains the tests of the import and export operations of
+// the RSA and elliptic curve keys, in the spki, pkcs8, raw, and jwk formats.
+//
+// The tests export freshly generated keys, import them back, and check that the
+// imported keys can be used along with the keys of the original key pair.
+
+var subtle = crypto.subtle;
+
+var data = new Uint8Array([1, 2, 3, 4, 5, 6, 7, 8]);
+
+var testVectors = [
+    {
+        algorithm: {name: "RSASSA-PKCS1-v1_5", modulusLength: 2048, publicExponent: new Uint8Array([1, 0, 1]), hash: "SHA-256"},
+        signParams: {name: "RSASSA-PKCS1-v1_5"},
+        publicFormats: ["spki", "jwk"],
+        privateFormats: ["pkcs8", "jwk"],
+        alg: "RS256"
+    },
+    {
+        algorithm: {name: "RSA-PSS", modulusLength: 2048, publicExponent: new Uint8Array([1, 0, 1]), hash: "SHA-512"},
+        signParams: {name: "RSA-PSS", saltLength: 64},
+        publicFormats: ["spki", "jwk"],
+        privateFormats: ["pkcs8", "jwk"],
+        alg: "PS512"
+    },
+    {
+        algorithm: {name: "ECDSA", namedCurve: "P-256"},
+        signParams: {name: "ECDSA", hash: "SHA-256"},
+        publicFormats: ["raw", "spki", "jwk"],
+        privateFormats: ["pkcs8", "jwk"],
+        alg: "ES256"
+    },
+    {
+        algorithm: {name: "ECDSA", namedCurve: "P-384"},
+        signParams: {name: "ECDSA", hash: "SHA-384"},
+        publicFormats: ["raw", "spki", "jwk"],
+        privateFormats: ["pkcs8", "jwk"],
+        alg: "ES384"
+    },
+];
+
+testVectors.forEach(function(vector) {
+    subtle.generateKey(vector.algorithm, true, ["sign", "verify"]).then(function(keyPair) {
+        // Keys imported from the exported public key verify the signatures of the private key.
+        vector.publicFormats.forEach(function(format) {
+            var signature;
+
+            subtle.sign(vector.signParams, keyPair.privateKey, data)
+                .then(function(result) {
+                    signature = result;
+                    return subtle.exportKey(format, keyPair.publicKey);
+                })
+                .then(function(exported) {
+                    if (format === "jwk") {
+                        assert_equals(exported.alg, vector.alg, "JWK alg is correct");
+                        assert_equals(exported.d, undefined, "Public JWK does not contain the private exponent");
+                        assert_true(exported.ext, "Public JWK is extractable");
+                    }
+
+                    return subtle.importKey(format, exported, vector.algorithm, true, ["verify"]);
+                })
+                .then(function(imported) {
+                    assert_equals(imported.type, "public", "Imported key is public");
+                    assert_equals(imported.algorithm.name, vector.algorithm.name, "Imported key algorithm is correct");
+                    return subtle.verify(vector.signParams, imported, signature, data);
+                })
+                .then(function(verified) {
+                    assert_true(verified, vector.algorithm.name + " " + format + " imported public key verifies");
+                });
+        });
+
+        // Keys imported from the exported private key produce signatures verified by the public key.
+        vector.privateFormats.forEach(function(format) {
+            subtle.exportKey(format, keyPair.privateKey)
+                .then(function(exported) {
+                    return subtle.importKey(format, exported, vector.algorithm, false, ["sign"]);
+                })
+                .then(function(imported) {
+                    assert_equals(imported.type, "private", "Imported key is private");
+                    assert_false(imported.extractable, "Imported key is not extractable");
+                    return subtle.sign(vector.signParams, imported, data);
+                })
+                .then(function(signature) {
+                    return subtle.verify(vector.signParams, keyPair.publicKey, signature, data);
+                })
+                .then(function(verified) {
+                    assert_true(verified, vector.algorithm.name + " " + format + " imported private key signs");
+                });
+        });
+
+        // Public keys cannot be exported in the pkcs8 format, and private keys in the spki format.
+        subtle.exportKey("pkcs8", keyPair.publicKey).then(function() {
+            assert_unreached("Exporting a public key in the pkcs8 format should have failed");
+        }, function(err) {
+            assert_equals(err.name, "InvalidAccessError", "Exporting a public key in the pkcs8 format fails");
+        });
+
+        subtle.exportKey("spki", keyPair.privateKey).then(function() {
+            assert_unreached("Exporting a private key in the spki format should have failed");
+        }, function(err) {
+            assert_equals(err.name, "InvalidAccessError", "Exporting a private key in the spki format fails");
+        });
+    });
+});
+
+// A key with a JWK alg attribute not matching the algorithm cannot be imported.
+subtle.generateKey({name: "ECDSA", namedCurve: "P-256"}, true, ["sign", "verify"])
+    .then(function(keyPair) {
+        return subtle.exportKey("jwk", keyPair.publicKey);
+    })
+    .then(function(jwk) {
+        return subtle.importKey("jwk", jwk, {name: "ECDSA", namedCurve: "P-384"}, true, ["verify"]);
+    })
+    .then(function() {
+        assert_unreached("Importing a key of another curve should have failed");
+    }, function(err) {
+        assert_equals(err.name, "DataError", "Importing a key of another curve fails");
+    });
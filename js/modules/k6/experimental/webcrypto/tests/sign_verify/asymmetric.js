@@ -0,0 +1,75 @@
+// This file contains the tests of the signature and verification operations
+// of the RSASSA-PKCS1-v1_5, RSA-PSS, and ECDSA algorithms.
+//
+// As opposed to the HMAC tests, these are not adapted from the W3C WebCrypto API
+// test suite, as the asymmetric signatures are not deterministic: the tests sign
+// data using freshly generated keys, and check the signatures can be verified.
+
+var subtle = crypto.subtle;
+
+var data = new Uint8Array([1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16]);
+var otherData = new Uint8Array([16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1]);
+
+var testVectors = [
+    {
+        generateParams: {name: "RSASSA-PKCS1-v1_5", modulusLength: 2048, publicExponent: new Uint8Array([1, 0, 1]), hash: "SHA-256"},
+        signParams: {name: "RSASSA-PKCS1-v1_5"},
+        signatureLength: 256
+    },
+    {
+        generateParams: {name: "RSA-PSS", modulusLength: 2048, publicExponent: new Uint8Array([1, 0, 1]), hash: "SHA-384"},
+        signParams: {name: "RSA-PSS", saltLength: 48},
+        signatureLength: 256
+    },
+    {
+        generateParams: {name: "ECDSA", namedCurve: "P-256"},
+        signParams: {name: "ECDSA", hash: "SHA-256"},
+        signatureLength: 64
+    },
+    {
+        generateParams: {name: "ECDSA", namedCurve: "P-384"},
+        signParams: {name: "ECDSA", hash: "SHA-384"},
+        signatureLength: 96
+    },
+    {
+        generateParams: {name: "ECDSA", namedCurve: "P-521"},
+        signParams: {name: "ECDSA", hash: "SHA-512"},
+        signatureLength: 132
+    },
+];
+
+testVectors.forEach(function(vector) {
+    var keyPair;
+    var signature;
+
+    subtle.generateKey(vector.generateParams, false, ["sign", "verify"])
+        .then(function(result) {
+            keyPair = result;
+            return subtle.sign(vector.signParams, keyPair.privateKey, data);
+        })
+        .then(function(result) {
+            signature = result;
+            assert_equals(signature.byteLength, vector.signatureLength, "Signature length is correct");
+            return subtle.verify(vector.signParams, keyPair.publicKey, signature, data);
+        })
+        .then(function(verified) {
+            assert_true(verified, vector.signParams.name + " signature verifies");
+            return subtle.verify(vector.signParams, keyPair.publicKey, signature, otherData);
+        })
+        .then(function(verified) {
+            assert_false(verified, vector.signParams.name + " signature does not verify other data");
+
+            var tampered = new Uint8Array(signature);
+            tampered[0] ^= 0xff;
+            return subtle.verify(vector.signParams, keyPair.publicKey, tampered, data);
+        })
+        .then(function(verified) {
+            assert_false(verified, vector.signParams.name + " tampered signature does not verify");
+            return subtle.sign(vector.signParams, keyPair.publicKey, data)
+                .then(function() {
+                    assert_unreached("Signing with a public key should have failed");
+                }, function(err) {
+                    assert_equals(err.name, "InvalidAccessError", "Signing with a public key fails");
+                });
+        });
+});
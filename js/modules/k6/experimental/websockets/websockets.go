@@ -15,11 +15,11 @@ import (
 
 	"github.com/dop251/goja"
 	"github.com/gorilla/websocket"
-	"github.com/grafana/xk6-websockets/websockets/events"
 	"github.com/mstoykov/k6-taskqueue-lib/taskqueue"
 
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/js/modules/k6/experimental/websockets/events"
 	"go.k6.io/k6/metrics"
 )
 
@@ -381,15 +381,8 @@ func (w *webSocket) queueMessage(msg *message) {
 			return nil // TODO maybe still emit
 		}
 		// TODO maybe emit after all the listeners have fired and skip it if defaultPrevent was called?!?
-		metrics.PushIfNotDone(w.vu.Context(), w.vu.State().Samples, metrics.Sample{
-			TimeSeries: metrics.TimeSeries{
-				Metric: w.builtinMetrics.WSMessagesReceived,
-				Tags:   w.tagsAndMeta.Tags,
-			},
-			Time:     msg.t,
-			Metadata: w.tagsAndMeta.Metadata,
-			Value:    1,
-		})
+		w.emitMessageMetrics(w.vu.Context(), w.vu.State().Samples,
+			w.builtinMetrics.WSMessagesReceived, w.builtinMetrics.WSMessageBytesReceived, msg, msg.t)
 
 		rt := w.vu.Runtime()
 		ev := w.newEvent(events.MESSAGE, msg.t)
@@ -420,6 +413,33 @@ func (w *webSocket) queueMessage(msg *message) {
 	})
 }
 
+// emitMessageMetrics emits the count of the messages and, for the data
+// messages, the size of their payload in bytes.
+func (w *webSocket) emitMessageMetrics(
+	ctx context.Context, samplesOutput chan<- metrics.SampleContainer,
+	countMetric, bytesMetric *metrics.Metric, msg *message, t time.Time,
+) {
+	samples := []metrics.Sample{{
+		TimeSeries: metrics.TimeSeries{Metric: countMetric, Tags: w.tagsAndMeta.Tags},
+		Time:       t,
+		Metadata:   w.tagsAndMeta.Metadata,
+		Value:      1,
+	}}
+	if msg.mtype == websocket.TextMessage || msg.mtype == websocket.BinaryMessage {
+		samples = append(samples, metrics.Sample{
+			TimeSeries: metrics.TimeSeries{Metric: bytesMetric, Tags: w.tagsAndMeta.Tags},
+			Time:       t,
+			Metadata:   w.tagsAndMeta.Metadata,
+			Value:      float64(len(msg.data)),
+		})
+	}
+	metrics.PushIfNotDone(ctx, samplesOutput, metrics.ConnectedSamples{
+		Samples: samples,
+		Tags:    w.tagsAndMeta.Tags,
+		Time:    t,
+	})
+}
+
 func (w *webSocket) readPump(wg *sync.WaitGroup) {
 	defer wg.Done()
 	for {
@@ -494,15 +514,8 @@ func (w *webSocket) writePump(wg *sync.WaitGroup) {
 					return nil
 				})
 
-				metrics.PushIfNotDone(ctx, samplesOutput, metrics.Sample{
-					TimeSeries: metrics.TimeSeries{
-						Metric: w.builtinMetrics.WSMessagesSent,
-						Tags:   w.tagsAndMeta.Tags,
-					},
-					Time:     time.Now(),
-					Metadata: w.tagsAndMeta.Metadata,
-					Value:    1,
-				})
+				w.emitMessageMetrics(ctx, samplesOutput,
+					w.builtinMetrics.WSMessagesSent, w.builtinMetrics.WSMessageBytesSent, &msg, time.Now())
 			case <-w.done:
 				return
 			}
@@ -534,33 +547,58 @@ func (w *webSocket) writePump(wg *sync.WaitGroup) {
 func (w *webSocket) send(msg goja.Value) {
 	w.assertStateOpen()
 
-	switch o := msg.Export().(type) {
-	case string:
-		w.bufferedAmount += len(o)
+	if s, ok := msg.Export().(string); ok {
+		w.bufferedAmount += len(s)
 		w.writeQueueCh <- message{
 			mtype: websocket.TextMessage,
-			data:  []byte(o),
-			t:     time.Now(),
-		}
-	case *goja.ArrayBuffer:
-		b := o.Bytes()
-		w.bufferedAmount += len(b)
-		w.writeQueueCh <- message{
-			mtype: websocket.BinaryMessage,
-			data:  b,
+			data:  []byte(s),
 			t:     time.Now(),
 		}
+		return
+	}
+
+	b, ok := bufferSourceBytes(w.vu.Runtime(), msg)
+	if !ok {
+		common.Throw(w.vu.Runtime(), fmt.Errorf("unsupported send type %T", msg.Export()))
+	}
+	w.bufferedAmount += len(b)
+	w.writeQueueCh <- message{
+		mtype: websocket.BinaryMessage,
+		data:  b,
+		t:     time.Now(),
+	}
+}
+
+// bufferSourceBytes returns a copy of the bytes of an ArrayBuffer, or of the
+// part of the buffer a view like a typed array or a DataView refers to, so the
+// script can change the buffer after sending it.
+func bufferSourceBytes(rt *goja.Runtime, v goja.Value) ([]byte, bool) {
+	switch o := v.Export().(type) {
 	case goja.ArrayBuffer:
-		b := o.Bytes()
-		w.bufferedAmount += len(b)
-		w.writeQueueCh <- message{
-			mtype: websocket.BinaryMessage,
-			data:  b,
-			t:     time.Now(),
-		}
-	default:
-		common.Throw(w.vu.Runtime(), fmt.Errorf("unsupported send type %T", o))
+		return append([]byte(nil), o.Bytes()...), true
+	case *goja.ArrayBuffer:
+		return append([]byte(nil), o.Bytes()...), true
+	}
+
+	obj, ok := v.(*goja.Object)
+	if !ok {
+		return nil, false
+	}
+	buffer := obj.Get("buffer")
+	if buffer == nil {
+		return nil, false
+	}
+	ab, ok := buffer.Export().(goja.ArrayBuffer)
+	if !ok {
+		return nil, false
+	}
+	offset := obj.Get("byteOffset").ToInteger()
+	length := obj.Get("byteLength").ToInteger()
+	b := ab.Bytes()
+	if offset < 0 || length < 0 || offset+length > int64(len(b)) {
+		common.Throw(rt, errors.New("the view is out of the bounds of its buffer"))
 	}
+	return append([]byte(nil), b[offset:offset+length]...), true
 }
 
 // Ping sends a ping message over the websocket.
@@ -0,0 +1,91 @@
+package websockets
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/httpmultibin"
+	"go.k6.io/k6/metrics"
+)
+
+func TestMessageBytesMetrics(t *testing.T) {
+	t.Parallel()
+
+	ts := modulestest.NewRuntime(t)
+	tb := httpmultibin.NewHTTPMultiBin(t)
+	extensions := make(chan string, 1)
+	tb.Mux.HandleFunc("/ws-echo-all", func(w http.ResponseWriter, req *http.Request) {
+		extensions <- req.Header.Get("Sec-WebSocket-Extensions")
+		conn, err := (&websocket.Upgrader{EnableCompression: true}).Upgrade(w, req, w.Header())
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err = conn.WriteMessage(messageType, data); err != nil {
+				return
+			}
+		}
+	})
+	m, ok := new(RootModule).NewModuleInstance(ts.VU).(*WebSocketsAPI)
+	require.True(t, ok)
+	require.NoError(t, ts.VU.Runtime().Set("WebSocket", m.Exports().Named["WebSocket"]))
+
+	samples := make(chan metrics.SampleContainer, 1000)
+	registry := metrics.NewRegistry()
+	ts.MoveToVUContext(&lib.State{
+		Dialer:         tb.Dialer,
+		TLSConfig:      tb.TLSClientConfig,
+		Samples:        samples,
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+		Options: lib.Options{
+			SystemTags: &metrics.DefaultSystemTagSet,
+			UserAgent:  null.StringFrom("TestUserAgent"),
+		},
+		Logger: ts.VU.InitEnvField.Logger,
+	})
+
+	_, err := ts.RunOnEventLoop(tb.Replacer.Replace(`
+		var received = [];
+		var ws = new WebSocket("WSBIN_URL/ws-echo-all", null, { compression: "deflate" });
+		ws.onopen = () => {
+			ws.send("hello");
+			ws.send(new Uint8Array([1, 2, 3, 4, 5]).subarray(1, 4));
+		};
+		ws.onmessage = (e) => {
+			received.push(typeof e.data === "string" ? e.data : Array.from(new Uint8Array(e.data)));
+			if (received.length === 2) {
+				ws.close();
+			}
+		};
+	`))
+	require.NoError(t, err)
+	assert.Equal(t,
+		[]interface{}{"hello", []interface{}{int64(2), int64(3), int64(4)}},
+		ts.VU.Runtime().Get("received").Export())
+	assert.Contains(t, <-extensions, "permessage-deflate")
+
+	sizes := make(map[string][]float64)
+	for _, container := range metrics.GetBufferedSamples(samples) {
+		for _, sample := range container.GetSamples() {
+			switch sample.Metric.Name {
+			case metrics.WSMessageBytesSentName, metrics.WSMessageBytesReceivedName:
+				sizes[sample.Metric.Name] = append(sizes[sample.Metric.Name], sample.Value)
+			}
+		}
+	}
+	assert.ElementsMatch(t, []float64{5, 3}, sizes[metrics.WSMessageBytesSentName])
+	assert.ElementsMatch(t, []float64{5, 3}, sizes[metrics.WSMessageBytesReceivedName])
+}
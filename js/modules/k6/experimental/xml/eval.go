@@ -0,0 +1,419 @@
+package xml
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The values of the expressions are either a nodeSet, a string,
+// a float64 or a bool, the four types of XPath.
+type nodeSet []*node
+
+// evalContext is the context of the evaluation of an expression.
+type evalContext struct {
+	node     *node
+	position int
+	size     int
+}
+
+// evaluator evaluates the expressions, with the namespaces of their prefixes.
+type evaluator struct {
+	namespaces map[string]string
+}
+
+var errNotNodeSet = errors.New("the expression doesn't select nodes")
+
+func (ev *evaluator) eval(e expr, ctx evalContext) (interface{}, error) {
+	switch e := e.(type) {
+	case literalExpr:
+		return string(e), nil
+	case numberExpr:
+		return float64(e), nil
+	case negateExpr:
+		v, err := ev.eval(e.operand, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return -numberOf(v), nil
+	case binaryExpr:
+		return ev.evalBinary(e, ctx)
+	case unionExpr:
+		left, err := ev.evalNodeSet(e.left, ctx)
+		if err != nil {
+			return nil, err
+		}
+		right, err := ev.evalNodeSet(e.right, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return inDocumentOrder(append(append(nodeSet{}, left...), right...)), nil
+	case functionExpr:
+		return ev.call(e, ctx)
+	case filterExpr:
+		set, err := ev.evalNodeSet(e.primary, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return ev.filter(set, e.predicates)
+	case *pathExpr:
+		return ev.evalPath(e, ctx)
+	}
+	return nil, fmt.Errorf("unsupported expression %T", e)
+}
+
+func (ev *evaluator) evalNodeSet(e expr, ctx evalContext) (nodeSet, error) {
+	v, err := ev.eval(e, ctx)
+	if err != nil {
+		return nil, err
+	}
+	set, ok := v.(nodeSet)
+	if !ok {
+		return nil, errNotNodeSet
+	}
+	return set, nil
+}
+
+func (ev *evaluator) evalBinary(e binaryExpr, ctx evalContext) (interface{}, error) {
+	left, err := ev.eval(e.left, ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch e.op {
+	case "or":
+		if booleanOf(left) {
+			return true, nil
+		}
+	case "and":
+		if !booleanOf(left) {
+			return false, nil
+		}
+	}
+
+	right, err := ev.eval(e.right, ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch e.op {
+	case "or", "and":
+		return booleanOf(right), nil
+	case "=", "!=", "<", "<=", ">", ">=":
+		return compare(e.op, left, right), nil
+	case "+":
+		return numberOf(left) + numberOf(right), nil
+	case "-":
+		return numberOf(left) - numberOf(right), nil
+	case "*":
+		return numberOf(left) * numberOf(right), nil
+	case "div":
+		return numberOf(left) / numberOf(right), nil
+	default: // mod
+		return math.Mod(numberOf(left), numberOf(right)), nil
+	}
+}
+
+func (ev *evaluator) evalPath(p *pathExpr, ctx evalContext) (nodeSet, error) {
+	var set nodeSet
+	switch {
+	case p.filter != nil:
+		var err error
+		if set, err = ev.evalNodeSet(p.filter, ctx); err != nil {
+			return nil, err
+		}
+	case p.absolute:
+		root := ctx.node
+		for root.parent != nil {
+			root = root.parent
+		}
+		set = nodeSet{root}
+	default:
+		set = nodeSet{ctx.node}
+	}
+
+	for _, s := range p.steps {
+		var result nodeSet
+		for _, n := range set {
+			selected, err := ev.selectStep(n, s)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, selected...)
+		}
+		set = inDocumentOrder(result)
+	}
+	return set, nil
+}
+
+// selectStep returns the nodes of the axis of the step from the node, which
+// match its node test and its predicates.
+func (ev *evaluator) selectStep(n *node, s *step) (nodeSet, error) {
+	var selected nodeSet
+	for _, candidate := range axis(n, s.axis) {
+		ok, err := ev.matches(candidate, s)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			selected = append(selected, candidate)
+		}
+	}
+	return ev.filter(selected, s.predicates)
+}
+
+func (ev *evaluator) filter(set nodeSet, predicates []expr) (nodeSet, error) {
+	for _, predicate := range predicates {
+		var filtered nodeSet
+		for i, n := range set {
+			v, err := ev.eval(predicate, evalContext{node: n, position: i + 1, size: len(set)})
+			if err != nil {
+				return nil, err
+			}
+			if number, ok := v.(float64); ok {
+				if number == float64(i+1) {
+					filtered = append(filtered, n)
+				}
+			} else if booleanOf(v) {
+				filtered = append(filtered, n)
+			}
+		}
+		set = filtered
+	}
+	return set, nil
+}
+
+// matches returns true if the node matches the node test of the step. The
+// names without a prefix match the elements of any namespace, so that the
+// documents with a default namespace can be queried without namespaces.
+func (ev *evaluator) matches(n *node, s *step) (bool, error) {
+	switch s.test.kind {
+	case "node":
+		return true, nil
+	case "text":
+		return n.typ == textNode, nil
+	}
+
+	principal := elementNode
+	if s.axis == "attribute" {
+		principal = attributeNode
+	}
+	if n.typ != principal {
+		return false, nil
+	}
+
+	if s.test.prefix != "" {
+		space, ok := ev.namespaces[s.test.prefix]
+		if !ok {
+			return false, fmt.Errorf("unknown namespace prefix %q", s.test.prefix)
+		}
+		if n.space != space {
+			return false, nil
+		}
+	}
+	return s.test.local == "*" || s.test.local == n.local, nil
+}
+
+// axis returns the nodes of the axis from the node, in the reverse
+// document order for the reverse axes.
+func axis(n *node, name string) nodeSet {
+	var nodes nodeSet
+	switch name {
+	case "child":
+		nodes = append(nodes, n.children...)
+	case "attribute":
+		nodes = append(nodes, n.attributes...)
+	case "self":
+		nodes = nodeSet{n}
+	case "descendant", "descendant-or-self":
+		if name == "descendant-or-self" {
+			nodes = nodeSet{n}
+		}
+		var walk func(*node)
+		walk = func(n *node) {
+			for _, child := range n.children {
+				nodes = append(nodes, child)
+				walk(child)
+			}
+		}
+		walk(n)
+	case "parent":
+		if n.parent != nil {
+			nodes = nodeSet{n.parent}
+		}
+	case "ancestor", "ancestor-or-self":
+		if name == "ancestor-or-self" {
+			nodes = nodeSet{n}
+		}
+		for p := n.parent; p != nil; p = p.parent {
+			nodes = append(nodes, p)
+		}
+	case "following-sibling", "preceding-sibling":
+		if n.parent == nil || n.typ == attributeNode {
+			return nil
+		}
+		siblings := n.parent.children
+		i := 0
+		for siblings[i] != n {
+			i++
+		}
+		if name == "following-sibling" {
+			return append(nodes, siblings[i+1:]...)
+		}
+		for j := i - 1; j >= 0; j-- {
+			nodes = append(nodes, siblings[j])
+		}
+	}
+	return nodes
+}
+
+// inDocumentOrder sorts the nodes in the document order, without duplicates.
+func inDocumentOrder(set nodeSet) nodeSet {
+	sort.Slice(set, func(i, j int) bool { return set[i].order < set[j].order })
+	unique := set[:0]
+	for i, n := range set {
+		if i == 0 || n != set[i-1] {
+			unique = append(unique, n)
+		}
+	}
+	return unique
+}
+
+func stringOf(v interface{}) string {
+	switch v := v.(type) {
+	case nodeSet:
+		if len(v) == 0 {
+			return ""
+		}
+		return v[0].text()
+	case float64:
+		return formatNumber(v)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return v.(string) //nolint:forcetypeassert
+	}
+}
+
+func numberOf(v interface{}) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	default:
+		f, err := strconv.ParseFloat(strings.TrimSpace(stringOf(v)), 64)
+		if err != nil {
+			return math.NaN()
+		}
+		return f
+	}
+}
+
+func booleanOf(v interface{}) bool {
+	switch v := v.(type) {
+	case nodeSet:
+		return len(v) > 0
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	case bool:
+		return v
+	default:
+		return v.(string) != "" //nolint:forcetypeassert
+	}
+}
+
+func formatNumber(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	case f == 0:
+		return "0"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// compare compares the values with the operator, a node-set being equal
+// to a value if any of its nodes is.
+func compare(op string, left, right interface{}) bool {
+	leftSet, leftIsSet := left.(nodeSet)
+	rightSet, rightIsSet := right.(nodeSet)
+	_, leftIsBool := left.(bool)
+	_, rightIsBool := right.(bool)
+
+	switch {
+	case leftIsSet && rightIsBool, rightIsSet && leftIsBool:
+		return compareValues(op, booleanOf(left), booleanOf(right))
+	case leftIsSet && rightIsSet:
+		for _, l := range leftSet {
+			for _, r := range rightSet {
+				if compareValues(op, l.text(), r.text()) {
+					return true
+				}
+			}
+		}
+		return false
+	case leftIsSet:
+		for _, l := range leftSet {
+			if compareValues(op, atomOf(l, right), right) {
+				return true
+			}
+		}
+		return false
+	case rightIsSet:
+		for _, r := range rightSet {
+			if compareValues(op, left, atomOf(r, left)) {
+				return true
+			}
+		}
+		return false
+	}
+	return compareValues(op, left, right)
+}
+
+// atomOf returns the value of the node to compare it with the other value.
+func atomOf(n *node, other interface{}) interface{} {
+	if _, ok := other.(float64); ok {
+		return numberOf(n.text())
+	}
+	return n.text()
+}
+
+func compareValues(op string, left, right interface{}) bool {
+	if op == "=" || op == "!=" {
+		var equal bool
+		_, leftIsBool := left.(bool)
+		_, rightIsBool := right.(bool)
+		_, leftIsNumber := left.(float64)
+		_, rightIsNumber := right.(float64)
+		switch {
+		case leftIsBool || rightIsBool:
+			equal = booleanOf(left) == booleanOf(right)
+		case leftIsNumber || rightIsNumber:
+			equal = numberOf(left) == numberOf(right)
+		default:
+			equal = stringOf(left) == stringOf(right)
+		}
+		return equal == (op == "=")
+	}
+
+	l, r := numberOf(left), numberOf(right)
+	switch op {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	default:
+		return l >= r
+	}
+}
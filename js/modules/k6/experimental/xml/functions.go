@@ -0,0 +1,197 @@
+package xml
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// function is a function of the core library of XPath. Its arguments are
+// evaluated before it's called, or set to the context node if it's optional
+// and missing.
+type function struct {
+	minArgs, maxArgs int
+	// contextArg is true if the context node is the default argument.
+	contextArg bool
+	call       func(ctx evalContext, args []interface{}) (interface{}, error)
+}
+
+const variadic = -1
+
+//nolint:gochecknoglobals
+var functions = map[string]function{
+	"last": {call: func(ctx evalContext, _ []interface{}) (interface{}, error) {
+		return float64(ctx.size), nil
+	}},
+	"position": {call: func(ctx evalContext, _ []interface{}) (interface{}, error) {
+		return float64(ctx.position), nil
+	}},
+	"count": {minArgs: 1, maxArgs: 1, call: func(_ evalContext, args []interface{}) (interface{}, error) {
+		set, ok := args[0].(nodeSet)
+		if !ok {
+			return nil, errNotNodeSet
+		}
+		return float64(len(set)), nil
+	}},
+	"local-name": {maxArgs: 1, contextArg: true, call: nodeName(func(n *node) string { return n.local })},
+	// the prefixes of the documents aren't kept, so the names are local
+	"name":          {maxArgs: 1, contextArg: true, call: nodeName(func(n *node) string { return n.local })},
+	"namespace-uri": {maxArgs: 1, contextArg: true, call: nodeName(func(n *node) string { return n.space })},
+	"string": {maxArgs: 1, contextArg: true, call: func(_ evalContext, args []interface{}) (interface{}, error) {
+		return stringOf(args[0]), nil
+	}},
+	"concat": {minArgs: 2, maxArgs: variadic, call: func(_ evalContext, args []interface{}) (interface{}, error) {
+		var sb strings.Builder
+		for _, arg := range args {
+			sb.WriteString(stringOf(arg))
+		}
+		return sb.String(), nil
+	}},
+	"starts-with": stringsFunction(func(s, t string) interface{} { return strings.HasPrefix(s, t) }),
+	"ends-with":   stringsFunction(func(s, t string) interface{} { return strings.HasSuffix(s, t) }),
+	"contains":    stringsFunction(func(s, t string) interface{} { return strings.Contains(s, t) }),
+	"substring-before": stringsFunction(func(s, t string) interface{} {
+		before, _, _ := strings.Cut(s, t)
+		return before
+	}),
+	"substring-after": stringsFunction(func(s, t string) interface{} {
+		_, after, found := strings.Cut(s, t)
+		if !found {
+			return ""
+		}
+		return after
+	}),
+	"substring": {minArgs: 2, maxArgs: 3, call: func(_ evalContext, args []interface{}) (interface{}, error) {
+		return substring(args), nil
+	}},
+	"string-length": {maxArgs: 1, contextArg: true, call: func(_ evalContext, args []interface{}) (interface{}, error) {
+		return float64(utf8.RuneCountInString(stringOf(args[0]))), nil
+	}},
+	"normalize-space": {maxArgs: 1, contextArg: true, call: func(_ evalContext, args []interface{}) (interface{}, error) {
+		return strings.Join(strings.Fields(stringOf(args[0])), " "), nil
+	}},
+	"translate": {minArgs: 3, maxArgs: 3, call: func(_ evalContext, args []interface{}) (interface{}, error) {
+		return translate(stringOf(args[0]), stringOf(args[1]), stringOf(args[2])), nil
+	}},
+	"boolean": {minArgs: 1, maxArgs: 1, call: func(_ evalContext, args []interface{}) (interface{}, error) {
+		return booleanOf(args[0]), nil
+	}},
+	"not": {minArgs: 1, maxArgs: 1, call: func(_ evalContext, args []interface{}) (interface{}, error) {
+		return !booleanOf(args[0]), nil
+	}},
+	"true": {call: func(evalContext, []interface{}) (interface{}, error) {
+		return true, nil
+	}},
+	"false": {call: func(evalContext, []interface{}) (interface{}, error) {
+		return false, nil
+	}},
+	"number": {maxArgs: 1, contextArg: true, call: func(_ evalContext, args []interface{}) (interface{}, error) {
+		return numberOf(args[0]), nil
+	}},
+	"sum": {minArgs: 1, maxArgs: 1, call: func(_ evalContext, args []interface{}) (interface{}, error) {
+		set, ok := args[0].(nodeSet)
+		if !ok {
+			return nil, errNotNodeSet
+		}
+		sum := 0.0
+		for _, n := range set {
+			sum += numberOf(n.text())
+		}
+		return sum, nil
+	}},
+	"floor":   numberFunction(math.Floor),
+	"ceiling": numberFunction(math.Ceil),
+	"round":   numberFunction(round),
+}
+
+func (ev *evaluator) call(e functionExpr, ctx evalContext) (interface{}, error) {
+	f := functions[e.name]
+	if len(e.args) < f.minArgs || (f.maxArgs != variadic && len(e.args) > f.maxArgs) {
+		return nil, fmt.Errorf("wrong number of arguments of %s()", e.name)
+	}
+
+	args := make([]interface{}, 0, len(e.args))
+	for _, arg := range e.args {
+		v, err := ev.eval(arg, ctx)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	if f.contextArg && len(args) == 0 {
+		args = append(args, nodeSet{ctx.node})
+	}
+	return f.call(ctx, args)
+}
+
+func nodeName(name func(*node) string) func(evalContext, []interface{}) (interface{}, error) {
+	return func(_ evalContext, args []interface{}) (interface{}, error) {
+		set, ok := args[0].(nodeSet)
+		if !ok {
+			return nil, errNotNodeSet
+		}
+		if len(set) == 0 {
+			return "", nil
+		}
+		return name(set[0]), nil
+	}
+}
+
+func stringsFunction(f func(s, t string) interface{}) function {
+	return function{minArgs: 2, maxArgs: 2, call: func(_ evalContext, args []interface{}) (interface{}, error) {
+		return f(stringOf(args[0]), stringOf(args[1])), nil
+	}}
+}
+
+func numberFunction(f func(float64) float64) function {
+	return function{minArgs: 1, maxArgs: 1, call: func(_ evalContext, args []interface{}) (interface{}, error) {
+		return f(numberOf(args[0])), nil
+	}}
+}
+
+// round rounds to the closest integer, and to the positive infinity
+// for the halves, unlike math.Round.
+func round(f float64) float64 {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return f
+	}
+	return math.Floor(f + 0.5)
+}
+
+// substring returns the characters of the string from the 1-based position
+// of the second argument, and up to the length of the third one.
+func substring(args []interface{}) string {
+	start := round(numberOf(args[1]))
+	end := math.Inf(1)
+	if len(args) == 3 {
+		end = start + round(numberOf(args[2]))
+	}
+
+	var sb strings.Builder
+	position := 1.0
+	for _, r := range stringOf(args[0]) {
+		if position >= start && position < end {
+			sb.WriteRune(r)
+		}
+		position++
+	}
+	return sb.String()
+}
+
+// translate replaces the characters of s in from with the ones at the same
+// position in to, or removes them if to is shorter.
+func translate(s, from, to string) string {
+	fromRunes, toRunes := []rune(from), []rune(to)
+	return strings.Map(func(r rune) rune {
+		for i, f := range fromRunes {
+			if f == r {
+				if i < len(toRunes) {
+					return toRunes[i]
+				}
+				return -1
+			}
+		}
+		return r
+	}, s)
+}
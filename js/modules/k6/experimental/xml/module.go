@@ -0,0 +1,224 @@
+// Package xml implements a k6 JS module to parse XML documents and query them
+// with XPath, e.g. the responses of SOAP services, without bundling an XML
+// parser written in JS with the script.
+package xml
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dop251/goja"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+// maxCachedExpressions is the number of compiled XPath expressions kept by
+// each VU, so that the expressions of a script aren't compiled each iteration.
+const maxCachedExpressions = 256
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the JS module.
+	ModuleInstance struct {
+		vu          modules.VU
+		expressions map[string]expr
+	}
+)
+
+// Ensure the interfaces are implemented correctly
+var (
+	_ modules.Instance = &ModuleInstance{}
+	_ modules.Module   = &RootModule{}
+)
+
+// New returns a pointer to a new RootModule instance
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns
+// a new instance for each VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu, expressions: make(map[string]expr)}
+}
+
+// Exports implements the modules.Instance interface and returns
+// the exports of the JS module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"parse": mi.parse,
+		},
+	}
+}
+
+// parse parses the XML document, a string or an ArrayBuffer, e.g. the
+// body of a response, and returns its document node.
+func (mi *ModuleInstance) parse(data goja.Value) (*Node, error) {
+	if common.IsNullish(data) {
+		return nil, errors.New("the XML document is required")
+	}
+	b, err := common.ToBytes(data.Export())
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := parseDocument(b)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse the XML document: %w", err)
+	}
+	return mi.newNode(doc), nil
+}
+
+// compile returns the compiled XPath expression, from the cache if it
+// was already compiled.
+func (mi *ModuleInstance) compile(expression string) (expr, error) {
+	if e, ok := mi.expressions[expression]; ok {
+		return e, nil
+	}
+
+	e, err := compile(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(mi.expressions) >= maxCachedExpressions {
+		mi.expressions = make(map[string]expr)
+	}
+	mi.expressions[expression] = e
+	return e, nil
+}
+
+// Node is a node of a parsed document, either the document itself,
+// an element, an attribute or a text node.
+type Node struct {
+	Type      string `js:"type"`
+	Name      string `js:"name"`
+	Namespace string `js:"namespace"`
+
+	mi   *ModuleInstance
+	node *node
+}
+
+func (mi *ModuleInstance) newNode(n *node) *Node {
+	return &Node{Type: n.typ.String(), Name: n.local, Namespace: n.space, mi: mi, node: n}
+}
+
+// Text returns the text of the node and all its descendants.
+func (n *Node) Text() string {
+	return n.node.text()
+}
+
+// Attr returns the value of the attribute, or null if the node doesn't
+// have it. The namespace of the attribute doesn't matter.
+func (n *Node) Attr(name string) interface{} {
+	if attr := n.node.attribute(name); attr != nil {
+		return attr.data
+	}
+	return nil
+}
+
+// Attributes returns the values of all the attributes, by name,
+// in the order of the document.
+func (n *Node) Attributes() *goja.Object {
+	rt := n.mi.vu.Runtime()
+	attributes := rt.NewObject()
+	for _, attr := range n.node.attributes {
+		if err := attributes.Set(attr.local, attr.data); err != nil {
+			common.Throw(rt, err)
+		}
+	}
+	return attributes
+}
+
+// Children returns the child elements of the node.
+func (n *Node) Children() []*Node {
+	elements := n.node.elements()
+	children := make([]*Node, len(elements))
+	for i, element := range elements {
+		children[i] = n.mi.newNode(element)
+	}
+	return children
+}
+
+// Parent returns the parent of the node, or null for the document.
+func (n *Node) Parent() interface{} {
+	if n.node.parent == nil {
+		return nil
+	}
+	return n.mi.newNode(n.node.parent)
+}
+
+// Query returns the nodes selected by the XPath expression, from the node.
+// The elements are returned as nodes, and the attributes and the text nodes as
+// their values. The namespaces are the URIs of the prefixes of the expression.
+func (n *Node) Query(expression string, namespaces goja.Value) ([]interface{}, error) {
+	v, err := n.evaluate(expression, namespaces)
+	if err != nil {
+		return nil, err
+	}
+	set, ok := v.(nodeSet)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", errNotNodeSet, expression)
+	}
+	return n.values(set), nil
+}
+
+// QueryOne returns the first node selected by the XPath expression, like
+// Query, or null if there is none.
+func (n *Node) QueryOne(expression string, namespaces goja.Value) (interface{}, error) {
+	values, err := n.Query(expression, namespaces)
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+	return values[0], nil
+}
+
+// Evaluate returns the value of the XPath expression, from the node, which is
+// either a string, a number, a boolean, or an array of nodes, as with Query.
+func (n *Node) Evaluate(expression string, namespaces goja.Value) (interface{}, error) {
+	v, err := n.evaluate(expression, namespaces)
+	if err != nil {
+		return nil, err
+	}
+	if set, ok := v.(nodeSet); ok {
+		return n.values(set), nil
+	}
+	return v, nil
+}
+
+func (n *Node) evaluate(expression string, namespaces goja.Value) (interface{}, error) {
+	e, err := n.mi.compile(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	ev := &evaluator{}
+	if !common.IsNullish(namespaces) {
+		obj := namespaces.ToObject(n.mi.vu.Runtime())
+		ev.namespaces = make(map[string]string, len(obj.Keys()))
+		for _, prefix := range obj.Keys() {
+			ev.namespaces[prefix] = obj.Get(prefix).String()
+		}
+	}
+
+	v, err := ev.eval(e, evalContext{node: n.node, position: 1, size: 1})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't evaluate %q: %w", expression, err)
+	}
+	return v, nil
+}
+
+func (n *Node) values(set nodeSet) []interface{} {
+	values := make([]interface{}, len(set))
+	for i, selected := range set {
+		if selected.typ == attributeNode || selected.typ == textNode {
+			values[i] = selected.data
+		} else {
+			values[i] = n.mi.newNode(selected)
+		}
+	}
+	return values
+}
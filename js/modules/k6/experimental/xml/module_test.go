@@ -0,0 +1,185 @@
+package xml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/js/modulestest"
+)
+
+const soapResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:m="http://example.com/orders">
+	<soap:Body>
+		<m:GetOrdersResponse>
+			<!-- the orders of the customer -->
+			<m:Order id="1" status="shipped"><m:Item>book</m:Item><m:Price>12.5</m:Price></m:Order>
+			<m:Order id="2" status="pending"><m:Item>pen</m:Item><m:Price>1.5</m:Price></m:Order>
+			<m:Order id="3" status="shipped"><m:Item><![CDATA[<lamp>]]></m:Item><m:Price>30</m:Price></m:Order>
+		</m:GetOrdersResponse>
+	</soap:Body>
+</soap:Envelope>`
+
+func newTestRuntime(t *testing.T) *modulestest.Runtime {
+	t.Helper()
+	ts := modulestest.NewRuntime(t)
+	m, ok := New().NewModuleInstance(ts.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, ts.VU.Runtime().Set("xml", m.Exports().Named))
+	require.NoError(t, ts.VU.Runtime().Set("soapResponse", soapResponse))
+
+	_, err := ts.VU.Runtime().RunString(`
+		function assertEquals(actual, expected) {
+			if (JSON.stringify(actual) !== JSON.stringify(expected)) {
+				throw new Error("expected " + JSON.stringify(expected) + ", got " + JSON.stringify(actual));
+			}
+		}
+		var doc = xml.parse(soapResponse);
+		var ns = { soap: "http://schemas.xmlsoap.org/soap/envelope/", m: "http://example.com/orders" };
+	`)
+	require.NoError(t, err)
+	return ts
+}
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, script string
+	}{
+		{
+			name: "nodes",
+			script: `
+				assertEquals(doc.type, "document");
+				const envelope = doc.children()[0];
+				assertEquals([envelope.type, envelope.name, envelope.namespace],
+					["element", "Envelope", "http://schemas.xmlsoap.org/soap/envelope/"]);
+				const order = doc.queryOne("//Order");
+				assertEquals(order.attr("status"), "shipped");
+				assertEquals(order.attr("missing"), null);
+				assertEquals(order.attributes(), { id: "1", status: "shipped" });
+				assertEquals(order.text(), "book12.5");
+				assertEquals(order.parent().name, "GetOrdersResponse");
+				assertEquals(doc.parent(), null);
+			`,
+		},
+		{
+			name: "namespaces",
+			script: `
+				assertEquals(doc.query("/soap:Envelope/soap:Body/m:GetOrdersResponse/m:Order/@id", ns), ["1", "2", "3"]);
+				assertEquals(doc.query("//m:*", ns).length, 10);
+				assertEquals(doc.query("//soap:Order", ns), []);
+			`,
+		},
+		{
+			name: "predicates",
+			script: `
+				assertEquals(doc.query("//Order[@status='shipped']/Item/text()"), ["book", "<lamp>"]);
+				assertEquals(doc.query("//Order[Price > 10][last()]/@id"), ["3"]);
+				assertEquals(doc.query("//Order[2]/Item/text()"), ["pen"]);
+				assertEquals(doc.query("//Order[position() < 3 and not(@status = 'pending')]/@id"), ["1"]);
+				assertEquals(doc.query("//Item[contains(., 'o')] | //Item[starts-with(., 'p')]").map(n => n.text()),
+					["book", "pen"]);
+			`,
+		},
+		{
+			name: "axes",
+			script: `
+				assertEquals(doc.query("//Order[@id='2']/preceding-sibling::Order/@id"), ["1"]);
+				assertEquals(doc.query("//Order[@id='2']/following-sibling::*[1]/@id"), ["3"]);
+				assertEquals(doc.query("//Price[. = 30]/ancestor::*").map(n => n.name),
+					["Envelope", "Body", "GetOrdersResponse", "Order"]);
+				assertEquals(doc.query("//Price/../@id"), ["1", "2", "3"]);
+				const order = doc.queryOne("//Order[3]");
+				assertEquals(order.query("Item/text()"), ["<lamp>"]);
+				assertEquals(order.query("/descendant-or-self::Order/@id"), ["1", "2", "3"]);
+			`,
+		},
+		{
+			name: "evaluate",
+			script: `
+				assertEquals(doc.evaluate("count(//Order)"), 3);
+				assertEquals(doc.evaluate("sum(//Price)"), 44);
+				assertEquals(doc.evaluate("sum(//Price) div count(//Price) > 14"), true);
+				assertEquals(doc.evaluate("concat(local-name(/*), '-', string(//Order/@status))"), "Envelope-shipped");
+				assertEquals(doc.evaluate("round(2.5) + floor(-1.5) * 2 - 7 mod 4"), -4);
+				assertEquals(doc.evaluate("substring('12345', 1.5, 2.6)"), "234");
+				assertEquals(doc.evaluate("translate(normalize-space('  a  b '), 'ab', 'B')"), "B ");
+				assertEquals(doc.evaluate("string-length(substring-after('key=value', '='))"), 5);
+				assertEquals(doc.evaluate("//Order/@id"), ["1", "2", "3"]);
+			`,
+		},
+		{
+			name: "array buffer",
+			script: `
+				const bytes = new Uint8Array([...'<a><b>c</b></a>'].map(c => c.charCodeAt(0)));
+				assertEquals(xml.parse(bytes.buffer).queryOne("/a/b/text()"), "c");
+			`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestRuntime(t)
+			_, err := ts.VU.Runtime().RunString(tc.script)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, script, err string
+	}{
+		{
+			name:   "invalid document",
+			script: `xml.parse("<a><b></a>")`,
+			err:    "couldn't parse the XML document",
+		},
+		{
+			name:   "empty document",
+			script: `xml.parse("")`,
+			err:    "the document has no root element",
+		},
+		{
+			name:   "invalid expression",
+			script: `doc.query("//Order[")`,
+			err:    `unexpected end of the expression "//Order["`,
+		},
+		{
+			name:   "unknown function",
+			script: `doc.query("//Order[matches(., 'a')]")`,
+			err:    "unknown function matches()",
+		},
+		{
+			name:   "unknown prefix",
+			script: `doc.query("//x:Order")`,
+			err:    `unknown namespace prefix "x"`,
+		},
+		{
+			name:   "not a node-set",
+			script: `doc.query("count(//Order)")`,
+			err:    `the expression doesn't select nodes: "count(//Order)"`,
+		},
+		{
+			name:   "wrong number of arguments",
+			script: `doc.evaluate("contains('a')")`,
+			err:    "wrong number of arguments of contains()",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestRuntime(t)
+			_, err := ts.VU.Runtime().RunString(tc.script)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
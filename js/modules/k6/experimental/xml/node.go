@@ -0,0 +1,153 @@
+package xml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// nodeType is the type of a node of a document, the same as in XPath.
+type nodeType int
+
+const (
+	documentNode nodeType = iota
+	elementNode
+	attributeNode
+	textNode
+)
+
+func (t nodeType) String() string {
+	switch t {
+	case documentNode:
+		return "document"
+	case elementNode:
+		return "element"
+	case attributeNode:
+		return "attribute"
+	default:
+		return "text"
+	}
+}
+
+// node is a node of a parsed document. The names of the elements and the
+// attributes are the ones of encoding/xml, with the namespace URI as the
+// space, since the prefixes of the document don't matter to the queries.
+type node struct {
+	typ   nodeType
+	space string
+	local string
+	data  string
+
+	parent     *node
+	children   []*node
+	attributes []*node
+
+	// order is the position of the node in the document order.
+	order int
+}
+
+// parseDocument parses the XML document into a tree of nodes. Only the
+// elements, their attributes and the text are kept, the comments,
+// processing instructions and directives are skipped.
+func parseDocument(data []byte) (*node, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	order := 0
+	doc := &node{typ: documentNode}
+	current := doc
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			order++
+			element := &node{typ: elementNode, space: t.Name.Space, local: t.Name.Local, parent: current, order: order}
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+					continue
+				}
+				order++
+				element.attributes = append(element.attributes, &node{
+					typ:    attributeNode,
+					space:  attr.Name.Space,
+					local:  attr.Name.Local,
+					data:   attr.Value,
+					parent: element,
+					order:  order,
+				})
+			}
+			current.children = append(current.children, element)
+			current = element
+		case xml.EndElement:
+			current = current.parent
+		case xml.CharData:
+			// adjacent text, e.g. around a comment or a CDATA section, is a single node
+			if n := len(current.children); n > 0 && current.children[n-1].typ == textNode {
+				current.children[n-1].data += string(t)
+				continue
+			}
+			if current == doc {
+				continue
+			}
+			order++
+			current.children = append(current.children, &node{typ: textNode, data: string(t), parent: current, order: order})
+		}
+	}
+
+	if len(doc.children) == 0 {
+		return nil, errors.New("the document has no root element")
+	}
+	return doc, nil
+}
+
+// text returns the string value of the node, which is the text of all its
+// descendants for the documents and the elements.
+func (n *node) text() string {
+	if n.typ == attributeNode || n.typ == textNode {
+		return n.data
+	}
+
+	var sb strings.Builder
+	var walk func(*node)
+	walk = func(n *node) {
+		for _, child := range n.children {
+			if child.typ == textNode {
+				sb.WriteString(child.data)
+			} else {
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+// elements returns the child elements of the node.
+func (n *node) elements() []*node {
+	elements := make([]*node, 0, len(n.children))
+	for _, child := range n.children {
+		if child.typ == elementNode {
+			elements = append(elements, child)
+		}
+	}
+	return elements
+}
+
+// attribute returns the attribute with the local name, in any namespace.
+func (n *node) attribute(local string) *node {
+	for _, attr := range n.attributes {
+		if attr.local == local {
+			return attr
+		}
+	}
+	return nil
+}
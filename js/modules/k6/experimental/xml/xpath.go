@@ -0,0 +1,518 @@
+package xml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The XPath expressions are a subset of XPath 1.0: all the expressions and
+// the functions of the core library, but only the axes which don't go
+// through the whole document, i.e. not following and preceding.
+
+type tokenKind int
+
+const (
+	tokenName tokenKind = iota
+	tokenNumber
+	tokenLiteral
+	tokenSymbol
+	tokenOperator
+	tokenEnd
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// isOperator returns true if the token is an Operator of the lexical
+// structure of XPath, which is then followed by an operand.
+func (t token) isOperator() bool {
+	if t.kind == tokenOperator {
+		return true
+	}
+	if t.kind != tokenSymbol {
+		return false
+	}
+	switch t.value {
+	case "@", "::", "(", "[", ",", "/", "//", "|", "+", "-", "=", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+// symbols are the symbols of the expressions, the longest ones first.
+var symbols = []string{
+	"//", "::", "..", "!=", "<=", ">=",
+	"/", "(", ")", "[", "]", ".", "@", ",", "|", "+", "-", "=", "<", ">", "*",
+}
+
+func isNameStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isNameChar(r rune) bool {
+	return isNameStart(r) || r == '-' || r == '.' || unicode.IsDigit(r)
+}
+
+// tokenize splits the expression in its tokens, disambiguating the operator
+// names and the multiply operator from the names with the preceding token.
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expression)
+	previous := func() (token, bool) {
+		if len(tokens) == 0 {
+			return token{}, false
+		}
+		return tokens[len(tokens)-1], true
+	}
+	followsOperand := func() bool {
+		p, ok := previous()
+		return ok && !p.isOperator()
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated string literal in %q", expression)
+			}
+			tokens = append(tokens, token{kind: tokenLiteral, value: string(runes[i+1 : end])})
+			i = end + 1
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			end := i
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: string(runes[i:end])})
+			i = end
+		case isNameStart(r):
+			end := i
+			for end < len(runes) && isNameChar(runes[end]) {
+				end++
+			}
+			// a QName, or a name test of all the names of a namespace
+			if end+1 < len(runes) && runes[end] == ':' && runes[end+1] != ':' {
+				end++
+				if runes[end] == '*' {
+					end++
+				} else {
+					for end < len(runes) && isNameChar(runes[end]) {
+						end++
+					}
+				}
+			}
+			name := string(runes[i:end])
+			i = end
+			if followsOperand() && (name == "and" || name == "or" || name == "div" || name == "mod") {
+				tokens = append(tokens, token{kind: tokenOperator, value: name})
+			} else {
+				tokens = append(tokens, token{kind: tokenName, value: name})
+			}
+		default:
+			matched := false
+			for _, symbol := range symbols {
+				if strings.HasPrefix(string(runes[i:]), symbol) {
+					kind := tokenSymbol
+					if symbol == "*" && followsOperand() {
+						kind = tokenOperator
+					}
+					tokens = append(tokens, token{kind: kind, value: symbol})
+					i += len([]rune(symbol))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q in %q", r, expression)
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEnd}), nil
+}
+
+type (
+	expr interface{}
+
+	literalExpr string
+	numberExpr  float64
+	negateExpr  struct{ operand expr }
+	binaryExpr  struct {
+		op          string
+		left, right expr
+	}
+	unionExpr    struct{ left, right expr }
+	functionExpr struct {
+		name string
+		args []expr
+	}
+	// filterExpr is a primary expression, filtered by its predicates.
+	filterExpr struct {
+		primary    expr
+		predicates []expr
+	}
+	// pathExpr is a location path, from the root of the document if it's
+	// absolute, or from the node-set of its filter expression if there is one.
+	pathExpr struct {
+		filter   expr
+		absolute bool
+		steps    []*step
+	}
+	step struct {
+		axis       string
+		test       nodeTest
+		predicates []expr
+	}
+	nodeTest struct {
+		// kind is either name, text or node.
+		kind   string
+		prefix string
+		// local is the local name of the name tests, or * for any name.
+		local string
+	}
+)
+
+var axes = map[string]bool{
+	"ancestor": true, "ancestor-or-self": true, "attribute": true, "child": true, "descendant": true,
+	"descendant-or-self": true, "following-sibling": true, "parent": true, "preceding-sibling": true, "self": true,
+}
+
+type parser struct {
+	expression string
+	tokens     []token
+	pos        int
+}
+
+// compile parses the XPath expression.
+func compile(expression string) (expr, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{expression: expression, tokens: tokens}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEnd {
+		return nil, p.unexpected()
+	}
+	return e, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEnd {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) is(kind tokenKind, values ...string) bool {
+	t := p.peek()
+	if t.kind != kind {
+		return false
+	}
+	for _, v := range values {
+		if t.value == v {
+			return true
+		}
+	}
+	return len(values) == 0
+}
+
+func (p *parser) expect(value string) error {
+	if !p.is(tokenSymbol, value) {
+		return p.unexpected()
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	t := p.peek()
+	if t.kind == tokenEnd {
+		return fmt.Errorf("unexpected end of the expression %q", p.expression)
+	}
+	return fmt.Errorf("unexpected %q in the expression %q", t.value, p.expression)
+}
+
+func (p *parser) parseBinary(operand func() (expr, error), kind tokenKind, ops ...string) (expr, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.is(kind, ops...) {
+		op := p.next().value
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseOr() (expr, error) {
+	return p.parseBinary(p.parseAnd, tokenOperator, "or")
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	return p.parseBinary(p.parseEquality, tokenOperator, "and")
+}
+
+func (p *parser) parseEquality() (expr, error) {
+	return p.parseBinary(p.parseRelational, tokenSymbol, "=", "!=")
+}
+
+func (p *parser) parseRelational() (expr, error) {
+	return p.parseBinary(p.parseAdditive, tokenSymbol, "<", "<=", ">", ">=")
+}
+
+func (p *parser) parseAdditive() (expr, error) {
+	return p.parseBinary(p.parseMultiplicative, tokenSymbol, "+", "-")
+}
+
+func (p *parser) parseMultiplicative() (expr, error) {
+	return p.parseBinary(p.parseUnary, tokenOperator, "*", "div", "mod")
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	if p.is(tokenSymbol, "-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negateExpr{operand: operand}, nil
+	}
+
+	left, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	for p.is(tokenSymbol, "|") {
+		p.next()
+		right, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		left = unionExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+// isPrimaryStart returns true if the next token starts a filter expression,
+// rather than a location path.
+func (p *parser) isPrimaryStart() bool {
+	t := p.peek()
+	switch t.kind {
+	case tokenLiteral, tokenNumber:
+		return true
+	case tokenSymbol:
+		return t.value == "("
+	case tokenName:
+		following := p.tokens[p.pos+1]
+		if following.kind != tokenSymbol || following.value != "(" {
+			return false
+		}
+		return t.value != "node" && t.value != "text" && t.value != "comment" && t.value != "processing-instruction"
+	}
+	return false
+}
+
+func (p *parser) parsePath() (expr, error) {
+	if !p.isPrimaryStart() {
+		return p.parseLocationPath()
+	}
+
+	primary, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	predicates, err := p.parsePredicates()
+	if err != nil {
+		return nil, err
+	}
+	var e expr = primary
+	if len(predicates) > 0 {
+		e = filterExpr{primary: primary, predicates: predicates}
+	}
+
+	if !p.is(tokenSymbol, "/", "//") {
+		return e, nil
+	}
+	path := &pathExpr{filter: e}
+	if err := p.parseRelativePath(path); err != nil {
+		return nil, err
+	}
+	return path, nil
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenLiteral:
+		return literalExpr(t.value), nil
+	case tokenNumber:
+		v, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q in the expression %q", t.value, p.expression)
+		}
+		return numberExpr(v), nil
+	case tokenSymbol: // (
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(")")
+	}
+
+	// a function call
+	if _, ok := functions[t.value]; !ok {
+		return nil, fmt.Errorf("unknown function %s() in the expression %q", t.value, p.expression)
+	}
+	p.next()
+	call := functionExpr{name: t.value}
+	for !p.is(tokenSymbol, ")") {
+		if len(call.args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+	}
+	p.next()
+	return call, nil
+}
+
+func (p *parser) parsePredicates() ([]expr, error) {
+	var predicates []expr
+	for p.is(tokenSymbol, "[") {
+		p.next()
+		predicate, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, predicate)
+	}
+	return predicates, nil
+}
+
+func (p *parser) parseLocationPath() (expr, error) {
+	path := &pathExpr{}
+	if p.is(tokenSymbol, "/") {
+		p.next()
+		path.absolute = true
+		// the root alone
+		if !p.isStepStart() {
+			return path, nil
+		}
+	} else if p.is(tokenSymbol, "//") {
+		path.absolute = true
+	}
+
+	if !p.is(tokenSymbol, "//") {
+		s, err := p.parseStep()
+		if err != nil {
+			return nil, err
+		}
+		path.steps = append(path.steps, s)
+	}
+	return path, p.parseRelativePath(path)
+}
+
+func (p *parser) isStepStart() bool {
+	t := p.peek()
+	if t.kind == tokenName {
+		return true
+	}
+	return t.kind == tokenSymbol && (t.value == "." || t.value == ".." || t.value == "@" || t.value == "*")
+}
+
+func (p *parser) parseRelativePath(path *pathExpr) error {
+	for p.is(tokenSymbol, "/", "//") {
+		if p.next().value == "//" {
+			path.steps = append(path.steps, &step{axis: "descendant-or-self", test: nodeTest{kind: "node"}})
+		}
+		s, err := p.parseStep()
+		if err != nil {
+			return err
+		}
+		path.steps = append(path.steps, s)
+	}
+	return nil
+}
+
+func (p *parser) parseStep() (*step, error) {
+	if p.is(tokenSymbol, ".") {
+		p.next()
+		return &step{axis: "self", test: nodeTest{kind: "node"}}, nil
+	}
+	if p.is(tokenSymbol, "..") {
+		p.next()
+		return &step{axis: "parent", test: nodeTest{kind: "node"}}, nil
+	}
+
+	s := &step{axis: "child"}
+	if p.is(tokenSymbol, "@") {
+		p.next()
+		s.axis = "attribute"
+	} else if p.peek().kind == tokenName && p.tokens[p.pos+1].kind == tokenSymbol && p.tokens[p.pos+1].value == "::" {
+		s.axis = p.next().value
+		if !axes[s.axis] {
+			return nil, fmt.Errorf("unsupported axis %s in the expression %q", s.axis, p.expression)
+		}
+		p.next()
+	}
+
+	t := p.peek()
+	if t.kind != tokenName && (t.kind != tokenSymbol || t.value != "*") {
+		return nil, p.unexpected()
+	}
+	p.next()
+	switch {
+	case t.kind == tokenSymbol:
+		s.test = nodeTest{kind: "name", local: "*"}
+	case p.is(tokenSymbol, "(") && (t.value == "node" || t.value == "text"):
+		p.next()
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		s.test = nodeTest{kind: t.value}
+	default:
+		s.test = nodeTest{kind: "name", local: t.value}
+		if prefix, local, ok := strings.Cut(t.value, ":"); ok {
+			s.test.prefix, s.test.local = prefix, local
+		}
+	}
+
+	predicates, err := p.parsePredicates()
+	if err != nil {
+		return nil, err
+	}
+	s.predicates = predicates
+	return s, nil
+}
@@ -13,6 +13,7 @@ import (
 
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/netext/grpcext"
 	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
@@ -34,10 +35,11 @@ import (
 
 // Client represents a gRPC client that can be used to make RPC requests
 type Client struct {
-	mds  map[string]protoreflect.MethodDescriptor
-	conn *grpcext.Conn
-	vu   modules.VU
-	addr string
+	mds   map[string]protoreflect.MethodDescriptor
+	conn  *grpcext.Conn
+	vu    modules.VU
+	addr  string
+	cache *descriptorCache
 }
 
 // Load will parse the given proto files and make the file descriptors available to request.
@@ -56,27 +58,35 @@ func (c *Client) Load(importPaths []string, filenames ...string) ([]MethodInfo,
 		importPaths = append(importPaths, initEnv.CWD.Path)
 	}
 
-	parser := protoparse.Parser{
-		ImportPaths:      importPaths,
-		InferImportPaths: false,
-		Accessor: protoparse.FileAccessor(func(filename string) (io.ReadCloser, error) {
-			absFilePath := initEnv.GetAbsFilePath(filename)
-			return initEnv.FileSystems["file"].Open(absFilePath)
-		}),
-	}
+	key := fmt.Sprintf("load\x00%s\x00%s\x00%s",
+		initEnv.CWD, strings.Join(importPaths, "\x00"), strings.Join(filenames, "\x00"))
+	files, err := c.cache.get(key, func() (*descriptorpb.FileDescriptorSet, error) {
+		parser := protoparse.Parser{
+			ImportPaths:      importPaths,
+			InferImportPaths: false,
+			Accessor: protoparse.FileAccessor(func(filename string) (io.ReadCloser, error) {
+				absFilePath := initEnv.GetAbsFilePath(filename)
+				return initEnv.FileSystems["file"].Open(absFilePath)
+			}),
+		}
 
-	fds, err := parser.ParseFiles(filenames...)
-	if err != nil {
-		return nil, err
-	}
+		fds, err := parser.ParseFiles(filenames...)
+		if err != nil {
+			return nil, err
+		}
 
-	fdset := &descriptorpb.FileDescriptorSet{}
+		fdset := &descriptorpb.FileDescriptorSet{}
 
-	seen := make(map[string]struct{})
-	for _, fd := range fds {
-		fdset.File = append(fdset.File, walkFileDescriptors(seen, fd)...)
+		seen := make(map[string]struct{})
+		for _, fd := range fds {
+			fdset.File = append(fdset.File, walkFileDescriptors(seen, fd)...)
+		}
+		return fdset, nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return c.convertToMethodInfo(fdset)
+	return c.addMethodInfo(files)
 }
 
 // LoadProtoset will parse the given protoset file (serialized FileDescriptorSet) and make the file
@@ -92,23 +102,78 @@ func (c *Client) LoadProtoset(protosetPath string) ([]MethodInfo, error) {
 	}
 
 	absFilePath := initEnv.GetAbsFilePath(protosetPath)
-	fdsetFile, err := initEnv.FileSystems["file"].Open(absFilePath)
+	files, err := c.cache.get("protoset\x00"+absFilePath, func() (*descriptorpb.FileDescriptorSet, error) {
+		fdsetFile, err := initEnv.FileSystems["file"].Open(absFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't open protoset: %w", err)
+		}
+
+		defer func() { _ = fdsetFile.Close() }()
+		fdsetBytes, err := io.ReadAll(fdsetFile)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read protoset: %w", err)
+		}
+
+		fdset := &descriptorpb.FileDescriptorSet{}
+		if err = proto.Unmarshal(fdsetBytes, fdset); err != nil {
+			return nil, fmt.Errorf("couldn't unmarshal protoset file %s: %w", protosetPath, err)
+		}
+		return fdset, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("couldn't open protoset: %w", err)
+		return nil, err
 	}
+	return c.addMethodInfo(files)
+}
 
-	defer func() { _ = fdsetFile.Close() }()
-	fdsetBytes, err := io.ReadAll(fdsetFile)
-	if err != nil {
-		return nil, fmt.Errorf("couldn't read protoset: %w", err)
+// LoadReflection will load the file descriptors of the services of the gRPC server at
+// the given address with the server reflection, and make them available to request.
+// Unlike connect() with the reflect param, it's called in the init context, and the
+// server is queried only once, the descriptors are shared by all the VUs.
+func (c *Client) LoadReflection(addr string, params goja.Value) ([]MethodInfo, error) {
+	if c.vu.State() != nil {
+		return nil, errors.New("loadReflection must be called in the init context")
 	}
 
-	fdset := &descriptorpb.FileDescriptorSet{}
-	if err = proto.Unmarshal(fdsetBytes, fdset); err != nil {
-		return nil, fmt.Errorf("couldn't unmarshal protoset file %s: %w", protosetPath, err)
+	p, err := newConnectParams(c.vu.Runtime(), params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid grpc.loadReflection() parameters: %w", err)
 	}
 
-	return c.convertToMethodInfo(fdset)
+	files, err := c.cache.get("reflection\x00"+addr, func() (*descriptorpb.FileDescriptorSet, error) {
+		// There is no VU state in the init context, so the connection
+		// doesn't use the dialer and the TLS config of the VUs.
+		opts := []grpc.DialOption{
+			grpc.WithBlock(),
+			grpc.FailOnNonTempDialError(true),
+			grpc.WithReturnConnectionError(),
+		}
+		if p.IsPlaintext {
+			opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		} else {
+			tlsCfg, err := buildTLSConfigFromMap(&tls.Config{MinVersion: tls.VersionTLS12}, p.TLS)
+			if err != nil {
+				return nil, err
+			}
+			tlsCfg.NextProtos = []string{"h2"}
+			opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), p.Timeout)
+		defer cancel()
+
+		conn, err := grpcext.Dial(ctx, addr, opts...)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = conn.Close() }()
+
+		return conn.Reflect(metadata.NewOutgoingContext(ctx, p.ReflectionMetadata))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.addMethodInfo(files)
 }
 
 // Note: this function was lifted from `lib/options.go`
@@ -288,21 +353,20 @@ func (c *Client) Invoke(
 	if c.conn == nil {
 		return nil, errors.New("no gRPC connection, you must call connect first")
 	}
-	if method == "" {
-		return nil, errors.New("method to invoke cannot be empty")
-	}
-	if method[0] != '/' {
-		method = "/" + method
-	}
-	methodDesc := c.mds[method]
-	if methodDesc == nil {
-		return nil, fmt.Errorf("method %q not found in file descriptors", method)
+	methodDesc, err := c.getMethodDescriptor(method)
+	if err != nil {
+		return nil, err
 	}
+	method = sanitizeMethodName(method)
 
-	p, err := c.parseInvokeParams(params)
+	p, err := c.parseCallParams(params, time.Minute)
 	if err != nil {
 		return nil, fmt.Errorf("invalid grpc.invoke() parameters: %w", err)
 	}
+	if p.Signal != nil {
+		// the unary calls are synchronous, so they can't be aborted while they're made
+		return nil, errors.New("invalid grpc.invoke() parameters: the signal param is only supported by the streams")
+	}
 
 	if req == nil {
 		return nil, errors.New("request cannot be nil")
@@ -315,17 +379,7 @@ func (c *Client) Invoke(
 	ctx, cancel := context.WithTimeout(c.vu.Context(), p.Timeout)
 	defer cancel()
 
-	if state.Options.SystemTags.Has(metrics.TagURL) {
-		p.TagsAndMeta.SetSystemTagOrMeta(metrics.TagURL, fmt.Sprintf("%s%s", c.addr, method))
-	}
-	parts := strings.Split(method[1:], "/")
-	p.TagsAndMeta.SetSystemTagOrMetaIfEnabled(state.Options.SystemTags, metrics.TagService, parts[0])
-	p.TagsAndMeta.SetSystemTagOrMetaIfEnabled(state.Options.SystemTags, metrics.TagMethod, parts[1])
-
-	// Only set the name system tag if the user didn't explicitly set it beforehand
-	if _, ok := p.TagsAndMeta.Tags.Get("name"); !ok {
-		p.TagsAndMeta.SetSystemTagOrMetaIfEnabled(state.Options.SystemTags, metrics.TagName, method)
-	}
+	p.setSystemTags(state, c.addr, method)
 
 	reqmsg := grpcext.Request{
 		MethodDescriptor: methodDesc,
@@ -336,6 +390,31 @@ func (c *Client) Invoke(
 	return c.conn.Invoke(ctx, method, p.Metadata, reqmsg)
 }
 
+// sanitizeMethodName returns the method name with a leading slash.
+func sanitizeMethodName(name string) string {
+	if name == "" {
+		return name
+	}
+	if !strings.HasPrefix(name, "/") {
+		name = "/" + name
+	}
+	return name
+}
+
+// getMethodDescriptor returns the descriptor of the method, which has to be
+// loaded with load(), loadProtoset() or the reflection.
+func (c *Client) getMethodDescriptor(method string) (protoreflect.MethodDescriptor, error) {
+	method = sanitizeMethodName(method)
+	if method == "" {
+		return nil, errors.New("method to invoke cannot be empty")
+	}
+	methodDesc := c.mds[method]
+	if methodDesc == nil {
+		return nil, fmt.Errorf("method %q not found in file descriptors", method)
+	}
+	return methodDesc, nil
+}
+
 // Close will close the client gRPC connection
 func (c *Client) Close() error {
 	if c.conn == nil {
@@ -360,6 +439,12 @@ func (c *Client) convertToMethodInfo(fdset *descriptorpb.FileDescriptorSet) ([]M
 	if err != nil {
 		return nil, err
 	}
+	return c.addMethodInfo(files)
+}
+
+// addMethodInfo makes the methods of the files available to request.
+func (c *Client) addMethodInfo(files *protoregistry.Files) ([]MethodInfo, error) {
+	var err error
 	var rtn []MethodInfo
 	if c.mds == nil {
 		// This allows us to call load() multiple times, without overwriting the
@@ -427,15 +512,19 @@ func (c *Client) convertToMethodInfo(fdset *descriptorpb.FileDescriptorSet) ([]M
 	return rtn, nil
 }
 
-type invokeParams struct {
+// callParams are the params of the invoke() calls and of the streams.
+type callParams struct {
 	Metadata    metadata.MD
 	TagsAndMeta metrics.TagsAndMeta
 	Timeout     time.Duration
+	Signal      *common.AbortSignal
 }
 
-func (c *Client) parseInvokeParams(paramsVal goja.Value) (*invokeParams, error) {
-	result := &invokeParams{
-		Timeout:     1 * time.Minute,
+// parseCallParams parses the params of a call, the timeout is used if the
+// params don't have one, no timeout is used if it's 0.
+func (c *Client) parseCallParams(paramsVal goja.Value, timeout time.Duration) (*callParams, error) {
+	result := &callParams{
+		Timeout:     timeout,
 		TagsAndMeta: c.vu.State().Tags.GetCurrentValues(),
 		Metadata:    metadata.New(nil),
 	}
@@ -467,6 +556,12 @@ func (c *Client) parseInvokeParams(paramsVal goja.Value) (*invokeParams, error)
 			if err != nil {
 				return result, fmt.Errorf("invalid timeout value: %w", err)
 			}
+		case "signal":
+			signal, err := common.GetAbortSignal(params.Get(k))
+			if err != nil {
+				return result, fmt.Errorf("invalid signal param: %w", err)
+			}
+			result.Signal = signal
 		default:
 			return result, fmt.Errorf("unknown param: %q", k)
 		}
@@ -474,6 +569,21 @@ func (c *Client) parseInvokeParams(paramsVal goja.Value) (*invokeParams, error)
 	return result, nil
 }
 
+// setSystemTags sets the system tags of the call to the method.
+func (p *callParams) setSystemTags(state *lib.State, addr string, method string) {
+	if state.Options.SystemTags.Has(metrics.TagURL) {
+		p.TagsAndMeta.SetSystemTagOrMeta(metrics.TagURL, fmt.Sprintf("%s%s", addr, method))
+	}
+	parts := strings.Split(method[1:], "/")
+	p.TagsAndMeta.SetSystemTagOrMetaIfEnabled(state.Options.SystemTags, metrics.TagService, parts[0])
+	p.TagsAndMeta.SetSystemTagOrMetaIfEnabled(state.Options.SystemTags, metrics.TagMethod, parts[1])
+
+	// Only set the name system tag if the user didn't explicitly set it beforehand
+	if _, ok := p.TagsAndMeta.Tags.Get("name"); !ok {
+		p.TagsAndMeta.SetSystemTagOrMetaIfEnabled(state.Options.SystemTags, metrics.TagName, method)
+	}
+}
+
 // newMetadata constructs a metadata.MD from the input value.
 func newMetadata(input goja.Value) (metadata.MD, error) {
 	md := metadata.New(nil)
@@ -0,0 +1,60 @@
+package grpc
+
+import (
+	"sync"
+
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// descriptorCache keeps the file descriptors that are loaded in the init
+// context, so the proto files, the protosets and the reflection are loaded
+// only once and the descriptors are shared by all VUs. The descriptors are
+// immutable, so they can be used concurrently.
+type descriptorCache struct {
+	mx      sync.Mutex
+	entries map[string]*descriptorCacheEntry
+}
+
+type descriptorCacheEntry struct {
+	once  sync.Once
+	files *protoregistry.Files
+	err   error
+}
+
+func newDescriptorCache() *descriptorCache {
+	return &descriptorCache{entries: make(map[string]*descriptorCacheEntry)}
+}
+
+// get returns the descriptors for the key, they are loaded with the provided
+// function the first time. The errors are cached too, since the loading would
+// fail in the same way for every VU.
+func (dc *descriptorCache) get(
+	key string, load func() (*descriptorpb.FileDescriptorSet, error),
+) (*protoregistry.Files, error) {
+	if dc == nil {
+		return newFiles(load)
+	}
+
+	dc.mx.Lock()
+	entry, ok := dc.entries[key]
+	if !ok {
+		entry = &descriptorCacheEntry{}
+		dc.entries[key] = entry
+	}
+	dc.mx.Unlock()
+
+	entry.once.Do(func() {
+		entry.files, entry.err = newFiles(load)
+	})
+	return entry.files, entry.err
+}
+
+func newFiles(load func() (*descriptorpb.FileDescriptorSet, error)) (*protoregistry.Files, error) {
+	fdset, err := load()
+	if err != nil {
+		return nil, err
+	}
+	return protodesc.NewFiles(fdset)
+}
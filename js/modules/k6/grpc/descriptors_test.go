@@ -0,0 +1,152 @@
+package grpc_test
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	k6grpc "go.k6.io/k6/js/modules/k6/grpc"
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/lib/testutils/httpmultibin"
+	"go.k6.io/k6/lib/testutils/httpmultibin/grpc_testing"
+)
+
+// newPlaintextGRPCServer starts a gRPC server without TLS and with the server reflection.
+func newPlaintextGRPCServer(t *testing.T) (string, *grpc.Server) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := grpc.NewServer()
+	grpc_testing.RegisterTestServiceServer(srv, &httpmultibin.GRPCStub{})
+	reflection.Register(srv)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String(), srv
+}
+
+// newInitRuntime returns a runtime in the init context with an instance of the root module.
+func newInitRuntime(t *testing.T, root *k6grpc.RootModule) *modulestest.Runtime {
+	t.Helper()
+
+	rt := modulestest.NewRuntime(t)
+	cwd, err := os.Getwd() //nolint:golint,forbidigo
+	require.NoError(t, err)
+	fs := fsext.NewOsFs()
+	if isWindows {
+		fs = fsext.NewTrimFilePathSeparatorFs(fs)
+	}
+	rt.VU.InitEnvField.CWD = &url.URL{Path: cwd}
+	rt.VU.InitEnvField.FileSystems = map[string]fsext.Fs{"file": fs}
+
+	m, ok := root.NewModuleInstance(rt.VU).(*k6grpc.ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, rt.VU.Runtime().Set("grpc", m.Exports().Named))
+	return rt
+}
+
+func TestClientLoadReflection(t *testing.T) {
+	t.Parallel()
+
+	addr, _ := newPlaintextGRPCServer(t)
+	ts := newTestState(t)
+	require.NoError(t, ts.VU.Runtime().Set("addr", addr))
+
+	val, err := ts.Run(`
+		var client = new grpc.Client();
+		client.loadReflection(addr, { plaintext: true });
+	`)
+	require.NoError(t, err)
+
+	var methods []k6grpc.MethodInfo
+	require.NoError(t, ts.VU.Runtime().ExportTo(val, &methods))
+	fullMethods := make([]string, 0, len(methods))
+	for _, m := range methods {
+		fullMethods = append(fullMethods, m.FullMethod)
+	}
+	assert.Contains(t, fullMethods, "/grpc.testing.TestService/UnaryCall")
+	assert.Contains(t, fullMethods, "/grpc.testing.TestService/FullDuplexCall")
+
+	// the loaded methods can be invoked on the regular connections
+	ts.httpBin.GRPCStub.EmptyCallFunc = func(_ context.Context, _ *grpc_testing.Empty) (*grpc_testing.Empty, error) {
+		return &grpc_testing.Empty{}, nil
+	}
+	ts.ToVUContext()
+	_, err = ts.Run(ts.httpBin.Replacer.Replace(`
+		client.connect("GRPCBIN_ADDR");
+		var resp = client.invoke("grpc.testing.TestService/EmptyCall", {});
+		if (resp.status !== grpc.StatusOK) {
+			throw new Error("unexpected error: " + JSON.stringify(resp.error));
+		}
+		client.close();
+	`))
+	require.NoError(t, err)
+
+	_, err = ts.Run(`client.loadReflection(addr, { plaintext: true })`)
+	require.ErrorContains(t, err, "loadReflection must be called in the init context")
+}
+
+func TestClientLoadReflectionSharedDescriptors(t *testing.T) {
+	t.Parallel()
+
+	addr, srv := newPlaintextGRPCServer(t)
+	root := k6grpc.New()
+
+	first := newInitRuntime(t, root)
+	require.NoError(t, first.VU.Runtime().Set("addr", addr))
+	_, err := first.VU.Runtime().RunString(`
+		var client = new grpc.Client();
+		client.loadReflection(addr, { plaintext: true });
+	`)
+	require.NoError(t, err)
+
+	// the other VUs get the descriptors from the cache, so they don't
+	// query the server, which isn't running anymore
+	srv.Stop()
+	second := newInitRuntime(t, root)
+	require.NoError(t, second.VU.Runtime().Set("addr", addr))
+	val, err := second.VU.Runtime().RunString(`
+		var client = new grpc.Client();
+		client.loadReflection(addr, { plaintext: true, timeout: "1s" });
+	`)
+	require.NoError(t, err)
+	assert.NotEmpty(t, val.Export())
+
+	// a module instance of another test run queries the server again
+	other := newInitRuntime(t, k6grpc.New())
+	require.NoError(t, other.VU.Runtime().Set("addr", addr))
+	_, err = other.VU.Runtime().RunString(`
+		var client = new grpc.Client();
+		client.loadReflection(addr, { plaintext: true, timeout: "1s" });
+	`)
+	require.Error(t, err)
+}
+
+func TestClientLoadProtosetSharedDescriptors(t *testing.T) {
+	t.Parallel()
+
+	root := k6grpc.New()
+	for i := 0; i < 2; i++ {
+		rt := newInitRuntime(t, root)
+		val, err := rt.VU.Runtime().RunString(`
+			var client = new grpc.Client();
+			client.loadProtoset("../../../../lib/testutils/httpmultibin/grpc_protoset_testing/test.protoset");
+		`)
+		require.NoError(t, err)
+
+		var methods []k6grpc.MethodInfo
+		require.NoError(t, rt.VU.Runtime().ExportTo(val, &methods))
+		require.Len(t, methods, 1)
+		assert.Equal(t, "/grpc.protoset.testing.TestService/Test", methods[0].FullMethod)
+	}
+}
@@ -1,7 +1,12 @@
 package grpc
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/dop251/goja"
+	"github.com/mstoykov/k6-taskqueue-lib/taskqueue"
+	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
 	"google.golang.org/grpc/codes"
 )
@@ -9,12 +14,17 @@ import (
 type (
 	// RootModule is the global module instance that will create module
 	// instances for each VU.
-	RootModule struct{}
+	RootModule struct {
+		// descriptors are shared by the VUs of the test
+		descriptors *descriptorCache
+	}
 
 	// ModuleInstance represents an instance of the GRPC module for every VU.
 	ModuleInstance struct {
-		vu      modules.VU
-		exports map[string]interface{}
+		vu          modules.VU
+		exports     map[string]interface{}
+		metrics     *instanceMetrics
+		descriptors *descriptorCache
 	}
 )
 
@@ -25,18 +35,26 @@ var (
 
 // New returns a pointer to a new RootModule instance.
 func New() *RootModule {
-	return &RootModule{}
+	return &RootModule{descriptors: newDescriptorCache()}
 }
 
 // NewModuleInstance implements the modules.Module interface to return
 // a new instance for each VU.
-func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+func (r *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	metrics, err := registerMetrics(vu.InitEnv().Registry)
+	if err != nil {
+		common.Throw(vu.Runtime(), fmt.Errorf("failed to register GRPC module metrics: %w", err))
+	}
+
 	mi := &ModuleInstance{
-		vu:      vu,
-		exports: make(map[string]interface{}),
+		vu:          vu,
+		exports:     make(map[string]interface{}),
+		metrics:     metrics,
+		descriptors: r.descriptors,
 	}
 
 	mi.exports["Client"] = mi.NewClient
+	mi.exports["Stream"] = mi.stream
 	mi.defineConstants()
 	return mi
 }
@@ -44,7 +62,7 @@ func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 // NewClient is the JS constructor for the grpc Client.
 func (mi *ModuleInstance) NewClient(call goja.ConstructorCall) *goja.Object {
 	rt := mi.vu.Runtime()
-	return rt.ToValue(&Client{vu: mi.vu}).ToObject(rt)
+	return rt.ToValue(&Client{vu: mi.vu, cache: mi.descriptors}).ToObject(rt)
 }
 
 // defineConstants defines the constant variables of the module.
@@ -79,3 +97,77 @@ func (mi *ModuleInstance) Exports() modules.Exports {
 		Named: mi.exports,
 	}
 }
+
+// stream is the JS constructor for the gRPC streams, which are used for the
+// client-streaming, server-streaming and bidirectional-streaming methods.
+func (mi *ModuleInstance) stream(c goja.ConstructorCall) *goja.Object {
+	rt := mi.vu.Runtime()
+	state := mi.vu.State()
+	if state == nil {
+		common.Throw(rt, common.NewInitContextError("creating a gRPC stream in the init context is not supported"))
+	}
+
+	client, err := extractClient(c.Argument(0), rt)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid GRPC Stream's client: %w", err))
+	}
+
+	methodDescriptor, err := client.getMethodDescriptor(c.Argument(1).String())
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid GRPC Stream's method: %w", err))
+	}
+	methodName := sanitizeMethodName(c.Argument(1).String())
+
+	p, err := client.parseCallParams(c.Argument(2), 0)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid GRPC Stream's parameters: %w", err))
+	}
+	p.setSystemTags(state, client.addr, methodName)
+
+	s := &stream{
+		vu:               mi.vu,
+		client:           client,
+		methodDescriptor: methodDescriptor,
+		method:           methodName,
+		logger:           state.Logger.WithField("streamMethod", methodName),
+
+		tq: taskqueue.New(mi.vu.RegisterCallback),
+
+		instanceMetrics: mi.metrics,
+		done:            make(chan struct{}),
+		writingState:    opened,
+
+		writeQueueCh: make(chan message),
+
+		eventListeners: newEventListeners(),
+		obj:            rt.NewObject(),
+		tagsAndMeta:    &p.TagsAndMeta,
+	}
+
+	defineStream(rt, s)
+
+	if err = s.beginStream(p); err != nil {
+		s.tq.Close()
+		common.Throw(rt, err)
+	}
+
+	return s.obj
+}
+
+// extractClient extracts & validates a grpc.Client from a goja.Value.
+func extractClient(v goja.Value, rt *goja.Runtime) (*Client, error) {
+	if common.IsNullish(v) {
+		return nil, errors.New("empty gRPC client")
+	}
+
+	client, ok := v.ToObject(rt).Export().(*Client)
+	if !ok {
+		return nil, errors.New("not a gRPC client")
+	}
+
+	if client.conn == nil {
+		return nil, errors.New("no gRPC connection, you must call connect first")
+	}
+
+	return client, nil
+}
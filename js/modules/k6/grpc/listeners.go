@@ -7,18 +7,16 @@ import (
 )
 
 const (
-	eventData   = "data"
-	eventError  = "error"
-	eventEnd    = "end"
-	eventStatus = "status"
+	eventData  = "data"
+	eventError = "error"
+	eventEnd   = "end"
 )
 
 // eventListeners keeps track of the eventListeners for each event type
 type eventListeners struct {
-	data   *eventListener
-	error  *eventListener
-	end    *eventListener
-	status *eventListener
+	data  *eventListener
+	error *eventListener
+	end   *eventListener
 }
 
 // eventListener keeps listeners of a certain type
@@ -42,15 +40,13 @@ func (l *eventListener) add(fn func(goja.Value) (goja.Value, error)) {
 	l.list = append(l.list, fn)
 }
 
-// getTypes return event listener of a certain type
+// getType returns the event listener of a certain type
 func (l *eventListeners) getType(t string) *eventListener {
 	switch t {
 	case eventData:
 		return l.data
 	case eventError:
 		return l.error
-	case eventStatus:
-		return l.status
 	case eventEnd:
 		return l.end
 	default:
@@ -84,9 +80,8 @@ func (l *eventListeners) all(t string) []func(goja.Value) (goja.Value, error) {
 
 func newEventListeners() *eventListeners {
 	return &eventListeners{
-		data:   newListener(eventData),
-		error:  newListener(eventError),
-		status: newListener(eventStatus),
-		end:    newListener(eventEnd),
+		data:  newListener(eventData),
+		error: newListener(eventError),
+		end:   newListener(eventEnd),
 	}
 }
@@ -2,7 +2,7 @@ package grpc
 
 import "go.k6.io/k6/metrics"
 
-// instanceMetrics contains the metrics for the grpc extension.
+// instanceMetrics contains the metrics of the gRPC streams.
 type instanceMetrics struct {
 	Streams                 *metrics.Metric
 	StreamsMessagesSent     *metrics.Metric
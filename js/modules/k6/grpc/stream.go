@@ -9,19 +9,24 @@ import (
 	"sync"
 	"time"
 
-	"github.com/dop251/goja"
-	"github.com/grafana/xk6-grpc/lib/netext/grpcext"
-	"github.com/mstoykov/k6-taskqueue-lib/taskqueue"
-	"github.com/sirupsen/logrus"
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib/netext/grpcext"
 	"go.k6.io/k6/metrics"
+
+	"github.com/dop251/goja"
+	"github.com/mstoykov/k6-taskqueue-lib/taskqueue"
+	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
-// message is a struct that
+// errAborted is the error of the streams whose signal was aborted before they were created.
+var errAborted = errors.New("the signal is aborted")
+
+// message is a message that is queued to be written to the stream, or the
+// closing of the sending side of the stream.
 type message struct {
 	isClosing bool
 	msg       []byte
@@ -32,6 +37,8 @@ const (
 	closed
 )
 
+// stream is a client-streaming, server-streaming or bidirectional-streaming
+// RPC, the received messages and the errors are passed to the event listeners.
 type stream struct {
 	vu     modules.VU
 	client *Client
@@ -47,7 +54,6 @@ type stream struct {
 	tq          *taskqueue.TaskQueue
 
 	instanceMetrics *instanceMetrics
-	builtinMetrics  *metrics.BuiltinMetrics
 
 	obj *goja.Object // the object that is given to js to interact with the stream
 
@@ -74,11 +80,10 @@ func defineStream(rt *goja.Runtime, s *stream) {
 }
 
 func (s *stream) beginStream(p *callParams) error {
-	tags := s.vu.State().Tags.GetCurrentValues()
 	req := &grpcext.StreamRequest{
 		Method:           s.method,
 		MethodDescriptor: s.methodDescriptor,
-		TagsAndMeta:      &tags,
+		TagsAndMeta:      s.tagsAndMeta,
 		Metadata:         p.Metadata,
 	}
 
@@ -88,6 +93,16 @@ func (s *stream) beginStream(p *callParams) error {
 	if p.Timeout != time.Duration(0) {
 		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
 	}
+	if p.Signal != nil {
+		// the stream is canceled once the signal is aborted, and its samples are tagged as aborted
+		if p.Signal.Aborted {
+			return fmt.Errorf("failed to create a new stream: %w", errAborted)
+		}
+		req.Abort = p.Signal.Done()
+		if cancel == nil {
+			ctx, cancel = context.WithCancel(ctx)
+		}
+	}
 
 	s.timeoutCancel = cancel
 
@@ -315,9 +330,10 @@ func (s *stream) write(input goja.Value) {
 	b, err := input.ToObject(rt).MarshalJSON()
 	if err != nil {
 		s.logger.WithError(err).Warnf("can't marshal message")
+		return
 	}
 
-	s.writeQueueCh <- message{msg: b}
+	s.queueWrite(message{msg: b})
 }
 
 // end closes client the stream
@@ -329,7 +345,19 @@ func (s *stream) end() {
 	s.logger.Debugf("finishing stream %s writing", s.method)
 
 	s.writingState = closed
-	s.writeQueueCh <- message{isClosing: true}
+	s.queueWrite(message{isClosing: true})
+}
+
+// queueWrite passes the message to the writing goroutine, unless the stream
+// was already closed (e.g. by the server) or the VU is shutting down, in which
+// case nothing is going to read it anymore.
+func (s *stream) queueWrite(msg message) {
+	select {
+	case s.writeQueueCh <- msg:
+	case <-s.done:
+		s.logger.Debugf("stream %s is already closed, the message isn't sent", s.method)
+	case <-s.vu.Context().Done():
+	}
 }
 
 func (s *stream) closeWithError(err error) error {
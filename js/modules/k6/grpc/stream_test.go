@@ -0,0 +1,246 @@
+package grpc_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib/testutils/httpmultibin/grpc_testing"
+	"go.k6.io/k6/metrics"
+)
+
+func TestStreamClientStreaming(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+	ts.httpBin.GRPCStub.StreamingInputCallFunc = func(stream grpc_testing.TestService_StreamingInputCallServer) error {
+		var size int32
+		for {
+			req, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return stream.SendAndClose(&grpc_testing.StreamingInputCallResponse{AggregatedPayloadSize: size})
+			}
+			if err != nil {
+				return err
+			}
+			size += int32(len(req.GetPayload().GetBody()))
+		}
+	}
+
+	_, err := ts.Run(`
+		var client = new grpc.Client();
+		client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");
+	`)
+	require.NoError(t, err)
+	ts.ToVUContext()
+
+	var received []interface{}
+	var ended bool
+	require.NoError(t, ts.VU.Runtime().Set("received", func(v interface{}) { received = append(received, v) }))
+	require.NoError(t, ts.VU.Runtime().Set("ended", func() { ended = true }))
+
+	_, err = ts.RunOnEventLoop(ts.httpBin.Replacer.Replace(`
+		client.connect("GRPCBIN_ADDR");
+		var stream = new grpc.Stream(client, "grpc.testing.TestService/StreamingInputCall");
+		stream.on("data", function(data) { received(data); });
+		stream.on("end", function() { ended(); client.close(); });
+		stream.write({ payload: { body: "aGVsbG8=" } }); // hello
+		stream.write({ payload: { body: "d29ybGQh" } }); // world!
+		stream.end();
+	`))
+	require.NoError(t, err)
+
+	assert.True(t, ended)
+	assert.Equal(t, []interface{}{map[string]interface{}{"aggregatedPayloadSize": float64(11)}}, received)
+
+	close(ts.samples)
+	counts := map[string]float64{}
+	for container := range ts.samples {
+		for _, sample := range container.GetSamples() {
+			counts[sample.Metric.Name] += sample.Value
+			if sample.Metric.Name == "grpc_streams_msgs_sent" {
+				name, _ := sample.Tags.Get("name")
+				assert.Equal(t, "/grpc.testing.TestService/StreamingInputCall", name)
+			}
+		}
+	}
+	assert.Equal(t, float64(1), counts["grpc_streams"])
+	assert.Equal(t, float64(2), counts["grpc_streams_msgs_sent"])
+	assert.Equal(t, float64(1), counts["grpc_streams_msgs_received"])
+	assert.Contains(t, counts, metrics.GRPCReqDurationName)
+}
+
+func TestStreamBidirectional(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+	ts.httpBin.GRPCStub.FullDuplexCallFunc = func(stream grpc_testing.TestService_FullDuplexCallServer) error {
+		for {
+			req, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(&grpc_testing.StreamingOutputCallResponse{Payload: req.GetPayload()}); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := ts.Run(`
+		var client = new grpc.Client();
+		client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");
+	`)
+	require.NoError(t, err)
+	ts.ToVUContext()
+
+	var received []string
+	require.NoError(t, ts.VU.Runtime().Set("received", func(v string) { received = append(received, v) }))
+
+	_, err = ts.RunOnEventLoop(ts.httpBin.Replacer.Replace(`
+		client.connect("GRPCBIN_ADDR");
+		var stream = new grpc.Stream(client, "/grpc.testing.TestService/FullDuplexCall");
+		var count = 0;
+		stream.on("data", function(data) {
+			received(data.payload.body);
+			// reply to each of the messages of the server, until there are 3
+			if (++count < 3) {
+				stream.write({ payload: { body: data.payload.body } });
+			} else {
+				stream.end();
+			}
+		});
+		stream.on("end", function() { client.close(); });
+		stream.write({ payload: { body: "aGVsbG8=" } });
+	`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aGVsbG8=", "aGVsbG8=", "aGVsbG8="}, received)
+}
+
+func TestStreamWriteAfterServerClose(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+	ts.httpBin.GRPCStub.FullDuplexCallFunc = func(grpc_testing.TestService_FullDuplexCallServer) error {
+		return nil // the server ends the stream without reading anything
+	}
+
+	_, err := ts.Run(`
+		var client = new grpc.Client();
+		client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");
+	`)
+	require.NoError(t, err)
+	ts.ToVUContext()
+
+	var ended bool
+	require.NoError(t, ts.VU.Runtime().Set("ended", func() { ended = true }))
+
+	_, err = ts.RunOnEventLoop(ts.httpBin.Replacer.Replace(`
+		client.connect("GRPCBIN_ADDR");
+		var stream = new grpc.Stream(client, "grpc.testing.TestService/FullDuplexCall");
+		stream.on("end", function() {
+			// nothing reads the messages of the closed stream anymore, this mustn't block
+			stream.write({ payload: { body: "aGVsbG8=" } });
+			stream.end();
+			ended();
+			client.close();
+		});
+	`))
+	require.NoError(t, err)
+	assert.True(t, ended)
+}
+
+func TestStreamAbort(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+	ts.httpBin.GRPCStub.FullDuplexCallFunc = func(stream grpc_testing.TestService_FullDuplexCallServer) error {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(&grpc_testing.StreamingOutputCallResponse{Payload: req.GetPayload()}); err != nil {
+				return err
+			}
+		}
+	}
+	require.NoError(t, common.SetupAbortController(ts.VU.Runtime()))
+
+	_, err := ts.Run(`
+		var client = new grpc.Client();
+		client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");
+	`)
+	require.NoError(t, err)
+	ts.ToVUContext()
+	ts.VU.State().Options.SystemTags = metrics.NewSystemTagSet(metrics.TagName, metrics.TagAborted)
+
+	var ended bool
+	require.NoError(t, ts.VU.Runtime().Set("ended", func() { ended = true }))
+
+	_, err = ts.RunOnEventLoop(ts.httpBin.Replacer.Replace(`
+		client.connect("GRPCBIN_ADDR");
+		var controller = new AbortController();
+		var stream = new grpc.Stream(client, "grpc.testing.TestService/FullDuplexCall", { signal: controller.signal });
+		// the server never ends the stream, the user gives up after the first message
+		stream.on("data", function() { controller.abort(); });
+		stream.on("error", function() {});
+		stream.on("end", function() { ended(); client.close(); });
+		stream.write({ payload: { body: "aGVsbG8=" } });
+	`))
+	require.NoError(t, err)
+	assert.True(t, ended)
+
+	_, err = ts.Run(ts.httpBin.Replacer.Replace(`client.connect("GRPCBIN_ADDR")`))
+	require.NoError(t, err)
+	_, err = ts.Run(`new grpc.Stream(client, "grpc.testing.TestService/FullDuplexCall", { signal: AbortSignal.abort() })`)
+	require.ErrorContains(t, err, "failed to create a new stream: the signal is aborted")
+	_, err = ts.Run(`client.invoke("grpc.testing.TestService/EmptyCall", {}, { signal: controller.signal })`)
+	require.ErrorContains(t, err, "the signal param is only supported by the streams")
+	_, err = ts.Run(`client.close()`)
+	require.NoError(t, err)
+
+	close(ts.samples)
+	var aborted bool
+	for container := range ts.samples {
+		for _, sample := range container.GetSamples() {
+			if sample.Metric.Name == metrics.GRPCReqDurationName {
+				value, ok := sample.Tags.Get("aborted")
+				aborted = ok && value == "true"
+			}
+		}
+	}
+	assert.True(t, aborted)
+}
+
+func TestStreamErrors(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+	_, err := ts.Run(`
+		var client = new grpc.Client();
+		client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");
+	`)
+	require.NoError(t, err)
+
+	_, err = ts.Run(`new grpc.Stream(client, "grpc.testing.TestService/FullDuplexCall")`)
+	require.ErrorContains(t, err, "creating a gRPC stream in the init context is not supported")
+
+	ts.ToVUContext()
+	_, err = ts.Run(`new grpc.Stream(client, "grpc.testing.TestService/FullDuplexCall")`)
+	require.ErrorContains(t, err, "no gRPC connection, you must call connect first")
+
+	_, err = ts.Run(`
+		client.connect("GRPCBIN_ADDR");
+		new grpc.Stream(client, "grpc.testing.TestService/Unknown");
+	`)
+	require.ErrorContains(t, err, `method "/grpc.testing.TestService/Unknown" not found in file descriptors`)
+	_, err = ts.Run(`client.close()`)
+	require.NoError(t, err)
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/metrics"
 )
 
 func wrapInAsyncLambda(input string) string {
@@ -260,3 +262,45 @@ func TestAsyncRequestErrors(t *testing.T) {
 		assert.Contains(t, promiseRejected.ToString(), expErr)
 	})
 }
+
+func TestAsyncRequestAbort(t *testing.T) {
+	t.Parallel()
+	ts := newTestCase(t)
+	require.NoError(t, common.SetupAbortController(ts.runtime.VU.Runtime()))
+
+	start := time.Now()
+	_, err := ts.runtime.RunOnEventLoop(wrapInAsyncLambda(ts.tb.Replacer.Replace(`
+		const controller = new AbortController();
+		const p = http.asyncRequest("GET", "HTTPBIN_URL/delay/10", null, { signal: controller.signal });
+		Promise.resolve().then(() => controller.abort());
+		try {
+			await p;
+			throw new Error("the request wasn't aborted");
+		} catch (e) {
+			if (e.name !== "AbortError") { throw e; }
+		}
+
+		try {
+			await http.asyncRequest("GET", "HTTPBIN_URL/get", null, { signal: AbortSignal.abort("gave up") });
+			throw new Error("the request wasn't aborted");
+		} catch (e) {
+			if (e !== "gave up") { throw e; }
+		}
+
+		try {
+			await http.asyncRequest("GET", "HTTPBIN_URL/get", null, { signal: "abort" });
+		} catch (e) {
+			if (!String(e).includes("the signal must be an AbortSignal")) { throw e; }
+		}
+	`)))
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	bufSamples := metrics.GetBufferedSamples(ts.samples)
+	require.Len(t, bufSamples, 1)
+	for _, sample := range bufSamples[0].GetSamples() {
+		aborted, ok := sample.Tags.Get("aborted")
+		assert.True(t, ok)
+		assert.Equal(t, "true", aborted)
+	}
+}
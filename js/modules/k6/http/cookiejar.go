@@ -1,6 +1,7 @@
 package http
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/dop251/goja"
 	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib"
 )
 
 // ErrJarForbiddenInInitContext is used when a cookie jar was made in the init context
@@ -23,8 +25,13 @@ type CookieJar struct {
 	// js is to make it not be accessible from inside goja/js, the json is
 	// for when it is returned from setup().
 	Jar *cookiejar.Jar `js:"-" json:"-"`
+	// Tracked wraps Jar to keep track of its cookies, so they can be exported.
+	Tracked *lib.CookieJar `js:"-" json:"-"`
 }
 
+// ErrJarNotTracked is used when the cookies of a jar that isn't tracked are exported or imported.
+var ErrJarNotTracked = errors.New("the cookies of this jar can't be exported or imported")
+
 // CookiesForURL return the cookies for a given url as a map of key and values
 func (j CookieJar) CookiesForURL(url string) map[string][]string {
 	u, err := neturl.Parse(url)
@@ -78,7 +85,7 @@ func (j CookieJar) Set(url, name, value string, opts goja.Value) (bool, error) {
 			}
 		}
 	}
-	j.Jar.SetCookies(u, []*http.Cookie{&c})
+	lib.ActiveCookieJar(j.Jar, j.Tracked).SetCookies(u, []*http.Cookie{&c})
 	return true, nil
 }
 
@@ -93,7 +100,7 @@ func (j CookieJar) Clear(url string) error {
 	for _, c := range cookies {
 		c.MaxAge = -1
 	}
-	j.Jar.SetCookies(u, cookies)
+	lib.ActiveCookieJar(j.Jar, j.Tracked).SetCookies(u, cookies)
 
 	return nil
 }
@@ -110,7 +117,42 @@ func (j CookieJar) Delete(url, name string) error {
 	}
 
 	c := http.Cookie{Name: name, MaxAge: -1}
-	j.Jar.SetCookies(u, []*http.Cookie{&c})
+	lib.ActiveCookieJar(j.Jar, j.Tracked).SetCookies(u, []*http.Cookie{&c})
 
 	return nil
 }
+
+// Export returns all the unexpired cookies in the jar, in a format that can be
+// imported back, e.g. in another VU or in a later iteration.
+func (j CookieJar) Export() ([]lib.ExportedCookie, error) {
+	if j.Tracked == nil || j.Tracked.Jar != j.Jar {
+		return nil, ErrJarNotTracked
+	}
+	return j.Tracked.Export(), nil
+}
+
+// Import adds the cookies to the jar, from either an array of cookies like the
+// one returned by Export(), a HAR object, or a JSON string with either of them.
+func (j CookieJar) Import(cookies goja.Value) error {
+	if j.Tracked == nil || j.Tracked.Jar != j.Jar {
+		return ErrJarNotTracked
+	}
+	if cookies == nil || goja.IsUndefined(cookies) || goja.IsNull(cookies) {
+		return errors.New("the cookies to import are required")
+	}
+
+	var data []byte
+	if s, ok := cookies.Export().(string); ok {
+		data = []byte(s)
+	} else {
+		var err error
+		if data, err = json.Marshal(cookies.Export()); err != nil {
+			return err
+		}
+	}
+	parsed, err := lib.ParseCookies(data)
+	if err != nil {
+		return err
+	}
+	return j.Tracked.Import(parsed)
+}
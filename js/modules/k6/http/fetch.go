@@ -0,0 +1,604 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/js/modules/k6/experimental/streams"
+	"go.k6.io/k6/lib/netext/httpext"
+)
+
+// fetchAPI implements the fetch() global of the WHATWG Fetch standard, with
+// its Headers, Request and Response classes, over the default client of the
+// VU, so the requests are made and measured like the ones of k6/http. It lets
+// the scripts reuse the code of the browsers and of Node.js.
+//
+// The k6 params of the requests, e.g. the tags, are passed as the k6 property
+// of the init of fetch() or of the Request. The bodies of the responses are
+// buffered, unless the responseType of the k6 params is stream.
+type fetchAPI struct {
+	vu     modules.VU
+	rt     *goja.Runtime
+	client *Client
+
+	// internal is the symbol of the property of the JS objects
+	// which holds their Go value
+	internal *goja.Symbol
+
+	headers, request, response *goja.Object
+}
+
+// SetupGlobals defines the fetch, Headers, Request and Response globals of the
+// runtime of the VU.
+func (r *RootModule) SetupGlobals(vu modules.VU) error {
+	mi, ok := r.NewModuleInstance(vu).(*ModuleInstance)
+	if !ok {
+		return errors.New("the http module instance isn't a ModuleInstance")
+	}
+	rt := vu.Runtime()
+	f := &fetchAPI{vu: vu, rt: rt, client: mi.defaultClient, internal: goja.NewSymbol("k6.fetch")}
+	f.defineHeaders()
+	f.defineRequest()
+	f.defineResponse()
+
+	for name, value := range map[string]interface{}{
+		"fetch":    f.fetch,
+		"Headers":  f.headers,
+		"Request":  f.request,
+		"Response": f.response,
+	} {
+		if err := rt.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func must(rt *goja.Runtime, err error) {
+	if err != nil {
+		common.Throw(rt, err)
+	}
+}
+
+func (f *fetchAPI) setInternal(obj *goja.Object, v interface{}) {
+	must(f.rt, obj.DefineDataPropertySymbol(f.internal, f.rt.ToValue(v), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_FALSE))
+}
+
+// internalOf returns the Go value of a Headers, Request or Response object.
+func (f *fetchAPI) internalOf(v goja.Value) interface{} {
+	obj, ok := v.(*goja.Object)
+	if !ok {
+		return nil
+	}
+	internal := obj.GetSymbol(f.internal)
+	if internal == nil {
+		return nil
+	}
+	return internal.Export()
+}
+
+func (f *fetchAPI) defineMethod(proto *goja.Object, name string, fn func(goja.FunctionCall) goja.Value) {
+	must(f.rt, proto.DefineDataProperty(name, f.rt.ToValue(fn), goja.FLAG_TRUE, goja.FLAG_FALSE, goja.FLAG_TRUE))
+}
+
+func (f *fetchAPI) defineGetter(proto *goja.Object, name string, fn func(goja.FunctionCall) goja.Value) {
+	must(f.rt, proto.DefineAccessorProperty(name, f.rt.ToValue(fn), nil, goja.FLAG_TRUE, goja.FLAG_TRUE))
+}
+
+// fetchBody is the body of a Request or of a Response, which can only be read once.
+type fetchBody struct {
+	data   []byte
+	stream io.ReadCloser // the streamed body of a response, instead of data
+	null   bool
+
+	used     bool
+	readable *goja.Object
+}
+
+// toBody returns the body of a BodyInit, a string, an ArrayBuffer or a view
+// of an ArrayBuffer, and its default Content-Type. The other objects are
+// converted to strings, as in the browsers.
+func (f *fetchAPI) toBody(v goja.Value) (*fetchBody, string) {
+	rt := f.rt
+	if common.IsNullish(v) {
+		return &fetchBody{null: true}, ""
+	}
+	switch exported := v.Export().(type) {
+	case goja.ArrayBuffer:
+		return &fetchBody{data: append([]byte{}, exported.Bytes()...)}, ""
+	case []byte:
+		return &fetchBody{data: append([]byte{}, exported...)}, ""
+	}
+	if obj, ok := v.(*goja.Object); ok {
+		if _, isReadable := goja.AssertFunction(obj.Get("getReader")); isReadable {
+			panic(rt.NewTypeError("streamed request bodies aren't supported by fetch"))
+		}
+		// the other typed arrays and the DataViews
+		if buffer, ok := obj.Get("buffer").Export().(goja.ArrayBuffer); ok {
+			offset, length := obj.Get("byteOffset").ToInteger(), obj.Get("byteLength").ToInteger()
+			return &fetchBody{data: append([]byte{}, buffer.Bytes()[offset:offset+length]...)}, ""
+		}
+	}
+	return &fetchBody{data: []byte(v.String())}, "text/plain;charset=UTF-8"
+}
+
+// readable returns the ReadableStream of the body, or null if there's no body.
+// The body is then only read through the stream.
+func (f *fetchAPI) readable(b *fetchBody) goja.Value {
+	if b.null {
+		return goja.Null()
+	}
+	if b.readable == nil {
+		r := b.stream
+		if r == nil {
+			r = io.NopCloser(bytes.NewReader(b.data))
+		}
+		readable, err := streams.NewReadableStreamFromReader(f.vu, r, 0)
+		must(f.rt, err)
+		b.used, b.readable = true, readable
+	}
+	return b.readable
+}
+
+// consume reads the whole body, off the event loop if it's streamed, and
+// returns a promise of its conversion.
+func (f *fetchAPI) consume(b *fetchBody, convert func([]byte) (goja.Value, error)) *goja.Promise {
+	rt := f.rt
+	promise, resolve, reject := rt.NewPromise()
+	if b.used {
+		reject(rt.NewTypeError("the body has already been read"))
+		return promise
+	}
+	b.used = true
+
+	settle := func(data []byte, err error) {
+		var v goja.Value
+		if err == nil {
+			v, err = convert(data)
+		}
+		if err != nil {
+			var exception *goja.Exception
+			if errors.As(err, &exception) {
+				reject(exception.Value())
+			} else {
+				reject(rt.NewTypeError(err.Error()))
+			}
+			return
+		}
+		resolve(v)
+	}
+	if b.stream == nil {
+		settle(b.data, nil)
+		return promise
+	}
+
+	callback := f.vu.RegisterCallback()
+	go func() {
+		data, err := io.ReadAll(b.stream)
+		callback(func() error {
+			_ = b.stream.Close()
+			settle(data, err)
+			return nil
+		})
+	}()
+	return promise
+}
+
+// defineBodyMethods defines the methods and the properties of the body of
+// the Request or Response class of the prototype.
+func (f *fetchAPI) defineBodyMethods(proto *goja.Object, bodyOf func(goja.Value) *fetchBody) {
+	rt := f.rt
+	f.defineGetter(proto, "body", func(call goja.FunctionCall) goja.Value {
+		return f.readable(bodyOf(call.This))
+	})
+	f.defineGetter(proto, "bodyUsed", func(call goja.FunctionCall) goja.Value {
+		return rt.ToValue(bodyOf(call.This).used)
+	})
+	f.defineMethod(proto, "text", func(call goja.FunctionCall) goja.Value {
+		return rt.ToValue(f.consume(bodyOf(call.This), func(data []byte) (goja.Value, error) {
+			return rt.ToValue(string(data)), nil
+		}))
+	})
+	f.defineMethod(proto, "json", func(call goja.FunctionCall) goja.Value {
+		return rt.ToValue(f.consume(bodyOf(call.This), func(data []byte) (goja.Value, error) {
+			// the JSON is parsed by the runtime, so that the keys are in their order
+			parse, ok := goja.AssertFunction(rt.Get("JSON").ToObject(rt).Get("parse"))
+			if !ok {
+				return nil, errors.New("JSON.parse isn't a function")
+			}
+			return parse(goja.Undefined(), rt.ToValue(string(data)))
+		}))
+	})
+	f.defineMethod(proto, "arrayBuffer", func(call goja.FunctionCall) goja.Value {
+		return rt.ToValue(f.consume(bodyOf(call.This), func(data []byte) (goja.Value, error) {
+			return rt.ToValue(rt.NewArrayBuffer(data)), nil
+		}))
+	})
+	f.defineMethod(proto, "bytes", func(call goja.FunctionCall) goja.Value {
+		return rt.ToValue(f.consume(bodyOf(call.This), func(data []byte) (goja.Value, error) {
+			return rt.New(rt.Get("Uint8Array"), rt.ToValue(rt.NewArrayBuffer(data)))
+		}))
+	})
+}
+
+// clone returns a copy of the body, which can be read separately.
+func (f *fetchAPI) clone(b *fetchBody) *fetchBody {
+	if b.used {
+		panic(f.rt.NewTypeError("the body has already been read"))
+	}
+	if b.stream != nil {
+		panic(f.rt.NewTypeError("the streamed bodies can't be cloned"))
+	}
+	return &fetchBody{data: b.data, null: b.null}
+}
+
+// fetchRequest is the Go value of a Request object.
+type fetchRequest struct {
+	method   string
+	url      string
+	headers  *fetchHeaders
+	body     *fetchBody
+	signal   *common.AbortSignal
+	redirect string
+	params   goja.Value // the k6 params
+}
+
+// normalizedMethods are the methods which are normalized to upper case.
+var normalizedMethods = map[string]bool{ //nolint:gochecknoglobals
+	http.MethodDelete: true, http.MethodGet: true, http.MethodHead: true,
+	http.MethodOptions: true, http.MethodPost: true, http.MethodPut: true,
+}
+
+// newRequest returns the request of the input, a URL or a Request, and the init.
+//
+//nolint:cyclop
+func (f *fetchAPI) newRequest(input, init goja.Value) *fetchRequest {
+	rt := f.rt
+	req := &fetchRequest{method: http.MethodGet, headers: &fetchHeaders{}, body: &fetchBody{null: true}, redirect: "follow"}
+	if other, ok := f.internalOf(input).(*fetchRequest); ok {
+		body := other.body
+		if !common.IsNullish(init) && !common.IsNullish(init.ToObject(rt).Get("body")) {
+			body = &fetchBody{null: true} // replaced by the body of the init
+		} else if !body.null {
+			if body.used {
+				panic(rt.NewTypeError("the body of the request has already been read"))
+			}
+			other.body = &fetchBody{used: true}
+		}
+		*req = fetchRequest{
+			method: other.method, url: other.url, body: body, signal: other.signal,
+			redirect: other.redirect, params: other.params,
+			headers: &fetchHeaders{list: append([][2]string{}, other.headers.list...)},
+		}
+	} else {
+		if common.IsNullish(input) {
+			panic(rt.NewTypeError("the URL of the request is required"))
+		}
+		req.url = input.String()
+	}
+	if common.IsNullish(init) {
+		return req
+	}
+
+	opts := init.ToObject(rt)
+	if v := opts.Get("method"); !common.IsNullish(v) {
+		req.method = v.String()
+		if upper := strings.ToUpper(req.method); normalizedMethods[upper] {
+			req.method = upper
+		}
+	}
+	if v := opts.Get("headers"); v != nil && !goja.IsUndefined(v) {
+		req.headers = &fetchHeaders{}
+		f.fill(req.headers, v)
+	}
+	if v := opts.Get("signal"); v != nil && !goja.IsUndefined(v) {
+		signal, err := common.GetAbortSignal(v)
+		if err != nil {
+			panic(rt.NewTypeError(err.Error()))
+		}
+		req.signal = signal
+	}
+	if v := opts.Get("redirect"); !common.IsNullish(v) {
+		switch req.redirect = v.String(); req.redirect {
+		case "follow", "manual", "error":
+		default:
+			panic(rt.NewTypeError("invalid redirect mode %q, it must be follow, manual or error", req.redirect))
+		}
+	}
+	if v := opts.Get("k6"); v != nil && !goja.IsUndefined(v) {
+		req.params = v
+	}
+	if v := opts.Get("body"); v != nil && !goja.IsUndefined(v) {
+		body, contentType := f.toBody(v)
+		req.body = body
+		if _, ok := req.headers.get("content-type"); !ok && contentType != "" {
+			req.headers.append("content-type", contentType)
+		}
+	}
+	if !req.body.null && (req.method == http.MethodGet || req.method == http.MethodHead) {
+		panic(rt.NewTypeError("the %s requests can't have a body", req.method))
+	}
+	return req
+}
+
+// defineRequest defines the Request class.
+func (f *fetchAPI) defineRequest() {
+	rt := f.rt
+	f.request = rt.ToValue(func(call goja.ConstructorCall) *goja.Object {
+		f.setInternal(call.This, f.newRequest(call.Argument(0), call.Argument(1)))
+		return call.This
+	}).ToObject(rt)
+
+	proto := f.request.Get("prototype").ToObject(rt)
+	requestOf := func(this goja.Value) *fetchRequest {
+		req, ok := f.internalOf(this).(*fetchRequest)
+		if !ok {
+			panic(rt.NewTypeError("the object isn't a Request"))
+		}
+		return req
+	}
+
+	f.defineGetter(proto, "method", func(call goja.FunctionCall) goja.Value {
+		return rt.ToValue(requestOf(call.This).method)
+	})
+	f.defineGetter(proto, "url", func(call goja.FunctionCall) goja.Value {
+		return rt.ToValue(requestOf(call.This).url)
+	})
+	f.defineGetter(proto, "headers", func(call goja.FunctionCall) goja.Value {
+		return f.newHeaders(requestOf(call.This).headers)
+	})
+	f.defineGetter(proto, "redirect", func(call goja.FunctionCall) goja.Value {
+		return rt.ToValue(requestOf(call.This).redirect)
+	})
+	f.defineGetter(proto, "signal", func(call goja.FunctionCall) goja.Value {
+		req := requestOf(call.This)
+		if req.signal == nil {
+			req.signal = common.NewAbortSignal(rt)
+		}
+		return rt.ToValue(req.signal)
+	})
+	f.defineBodyMethods(proto, func(this goja.Value) *fetchBody {
+		return requestOf(this).body
+	})
+	f.defineMethod(proto, "clone", func(call goja.FunctionCall) goja.Value {
+		req := *requestOf(call.This)
+		req.headers = &fetchHeaders{list: append([][2]string{}, req.headers.list...)}
+		req.body = f.clone(req.body)
+		obj := rt.NewObject()
+		must(rt, obj.SetPrototype(proto))
+		f.setInternal(obj, &req)
+		return obj
+	})
+}
+
+// fetchResponse is the Go value of a Response object.
+type fetchResponse struct {
+	typ        string
+	url        string
+	redirected bool
+	status     int
+	statusText string
+	headers    *fetchHeaders
+	body       *fetchBody
+}
+
+// newResponse returns a response of the body and of the init,
+// with its status, statusText and headers.
+func (f *fetchAPI) newResponse(body, init goja.Value) *fetchResponse {
+	rt := f.rt
+	resp := &fetchResponse{typ: "default", status: http.StatusOK, headers: &fetchHeaders{}}
+	var contentType string
+	resp.body, contentType = f.toBody(body)
+	if !common.IsNullish(init) {
+		opts := init.ToObject(rt)
+		if v := opts.Get("status"); !common.IsNullish(v) {
+			resp.status = int(v.ToInteger())
+			if resp.status < 200 || resp.status > 599 {
+				panic(rt.NewGoError(fmt.Errorf("invalid status %d, it must be between 200 and 599", resp.status)))
+			}
+		}
+		if v := opts.Get("statusText"); !common.IsNullish(v) {
+			resp.statusText = v.String()
+		}
+		if v := opts.Get("headers"); !common.IsNullish(v) {
+			f.fill(resp.headers, v)
+		}
+	}
+	if _, ok := resp.headers.get("content-type"); !ok && contentType != "" {
+		resp.headers.append("content-type", contentType)
+	}
+	return resp
+}
+
+// newResponseObject returns a new Response object of the response.
+func (f *fetchAPI) newResponseObject(resp *fetchResponse) *goja.Object {
+	obj := f.rt.NewObject()
+	must(f.rt, obj.SetPrototype(f.response.Get("prototype").ToObject(f.rt)))
+	f.setInternal(obj, resp)
+	return obj
+}
+
+// defineResponse defines the Response class.
+func (f *fetchAPI) defineResponse() {
+	rt := f.rt
+	f.response = rt.ToValue(func(call goja.ConstructorCall) *goja.Object {
+		f.setInternal(call.This, f.newResponse(call.Argument(0), call.Argument(1)))
+		return call.This
+	}).ToObject(rt)
+
+	f.defineMethod(f.response, "json", func(call goja.FunctionCall) goja.Value {
+		stringify, ok := goja.AssertFunction(rt.Get("JSON").ToObject(rt).Get("stringify"))
+		if !ok {
+			panic(rt.NewTypeError("JSON.stringify isn't a function"))
+		}
+		data, err := stringify(goja.Undefined(), call.Argument(0))
+		if err != nil {
+			panic(err)
+		}
+		resp := f.newResponse(data, call.Argument(1))
+		resp.headers.set("content-type", "application/json")
+		return f.newResponseObject(resp)
+	})
+	f.defineMethod(f.response, "error", func(call goja.FunctionCall) goja.Value {
+		return f.newResponseObject(&fetchResponse{typ: "error", headers: &fetchHeaders{immutable: true}, body: &fetchBody{null: true}})
+	})
+
+	proto := f.response.Get("prototype").ToObject(rt)
+	responseOf := func(this goja.Value) *fetchResponse {
+		resp, ok := f.internalOf(this).(*fetchResponse)
+		if !ok {
+			panic(rt.NewTypeError("the object isn't a Response"))
+		}
+		return resp
+	}
+
+	f.defineGetter(proto, "type", func(call goja.FunctionCall) goja.Value {
+		return rt.ToValue(responseOf(call.This).typ)
+	})
+	f.defineGetter(proto, "url", func(call goja.FunctionCall) goja.Value {
+		return rt.ToValue(responseOf(call.This).url)
+	})
+	f.defineGetter(proto, "redirected", func(call goja.FunctionCall) goja.Value {
+		return rt.ToValue(responseOf(call.This).redirected)
+	})
+	f.defineGetter(proto, "status", func(call goja.FunctionCall) goja.Value {
+		return rt.ToValue(responseOf(call.This).status)
+	})
+	f.defineGetter(proto, "ok", func(call goja.FunctionCall) goja.Value {
+		status := responseOf(call.This).status
+		return rt.ToValue(status >= 200 && status <= 299)
+	})
+	f.defineGetter(proto, "statusText", func(call goja.FunctionCall) goja.Value {
+		return rt.ToValue(responseOf(call.This).statusText)
+	})
+	f.defineGetter(proto, "headers", func(call goja.FunctionCall) goja.Value {
+		return f.newHeaders(responseOf(call.This).headers)
+	})
+	f.defineBodyMethods(proto, func(this goja.Value) *fetchBody {
+		return responseOf(this).body
+	})
+	f.defineMethod(proto, "clone", func(call goja.FunctionCall) goja.Value {
+		resp := *responseOf(call.This)
+		resp.headers = &fetchHeaders{list: append([][2]string{}, resp.headers.list...), immutable: resp.headers.immutable}
+		resp.body = f.clone(resp.body)
+		return f.newResponseObject(&resp)
+	})
+}
+
+// fetch makes the request of the input and the init off the event loop, and
+// returns a promise of its Response. The promise is only rejected for the
+// network errors, as in the browsers, not for the error statuses.
+func (f *fetchAPI) fetch(input, init goja.Value) (*goja.Promise, error) {
+	rt := f.rt
+	state := f.vu.State()
+	if state == nil {
+		return nil, ErrHTTPForbiddenInInitContext
+	}
+
+	req := f.newRequest(input, init)
+	if req.body.used {
+		panic(rt.NewTypeError("the body of the request has already been read"))
+	}
+	req.body.used = true
+
+	params := rt.NewObject()
+	if !common.IsNullish(req.params) {
+		k6Params := req.params.ToObject(rt)
+		for _, key := range k6Params.Keys() {
+			must(rt, params.Set(key, k6Params.Get(key)))
+		}
+	}
+	headers := make(map[string]string)
+	for _, entry := range req.headers.entries() {
+		if value, ok := headers[entry[0]]; ok {
+			headers[entry[0]] = value + ", " + entry[1]
+		} else {
+			headers[entry[0]] = entry[1]
+		}
+	}
+	must(rt, params.Set("headers", headers))
+	must(rt, params.Set("throw", true))
+	if req.redirect != "follow" {
+		must(rt, params.Set("redirects", 0))
+	}
+	if common.IsNullish(params.Get("responseType")) {
+		must(rt, params.Set("responseType", httpext.ResponseTypeBinary.String()))
+	}
+
+	var body interface{}
+	if !req.body.null {
+		body = req.body.data
+	}
+	preq, err := f.client.parseRequest(req.method, rt.ToValue(req.url), body, params)
+	promise, resolve, reject := rt.NewPromise()
+	if err != nil {
+		reject(rt.NewTypeError("fetch failed: %s", err))
+		return promise, nil
+	}
+	signal := req.signal
+	if signal != nil {
+		if signal.Aborted {
+			reject(signal.Reason)
+			return promise, nil
+		}
+		preq.Abort = signal.Done()
+	}
+
+	callback := f.vu.RegisterCallback()
+	go func() {
+		resp, err := httpext.MakeRequest(f.vu.Context(), state, preq)
+		callback(func() error {
+			switch {
+			case err != nil && signal != nil && signal.Aborted:
+				reject(signal.Reason)
+			case err != nil:
+				reject(rt.NewTypeError("fetch failed: %s", err))
+			case req.redirect == "error" && resp.Status >= 300 && resp.Status < 400:
+				reject(rt.NewTypeError("fetch failed: the response is a redirect, with the error redirect mode"))
+			default:
+				resolve(f.newResponseObject(f.fetchedResponse(req, resp)))
+			}
+			return nil
+		})
+	}()
+	return promise, nil
+}
+
+// fetchedResponse returns the response of the request which was made.
+func (f *fetchAPI) fetchedResponse(req *fetchRequest, resp *httpext.Response) *fetchResponse {
+	fr := &fetchResponse{
+		typ:        "basic",
+		url:        resp.URL,
+		redirected: resp.URL != req.url,
+		status:     resp.Status,
+		statusText: strings.TrimPrefix(resp.StatusText, strconv.Itoa(resp.Status)+" "),
+		headers:    &fetchHeaders{immutable: true},
+		body:       &fetchBody{},
+	}
+	for name, value := range resp.Headers {
+		fr.headers.append(strings.ToLower(name), value)
+	}
+	fr.headers.list = fr.headers.entries()
+
+	switch body := resp.Body.(type) {
+	case []byte:
+		fr.body.data = body
+	case string:
+		fr.body.data = []byte(body)
+	case *httpext.ResponseStream:
+		fr.body.stream = body
+		closeOnEventLoop(f.vu, body)
+	case nil:
+		fr.body.null = true
+	}
+	return fr
+}
@@ -0,0 +1,245 @@
+package http
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dop251/goja"
+	"golang.org/x/net/http/httpguts"
+
+	"go.k6.io/k6/js/common"
+)
+
+// fetchHeaders are the headers of a Headers object of the fetch API, in the
+// order they were added, with the names in lower case.
+type fetchHeaders struct {
+	list [][2]string
+
+	// the headers of the fetched responses can't be modified
+	immutable bool
+}
+
+func normalizeHeader(rt *goja.Runtime, name, value string) (string, string) {
+	if !httpguts.ValidHeaderFieldName(name) {
+		panic(rt.NewTypeError("invalid header name %q", name))
+	}
+	value = strings.Trim(value, " \t\r\n")
+	if !httpguts.ValidHeaderFieldValue(value) {
+		panic(rt.NewTypeError("invalid value of the header %q", name))
+	}
+	return strings.ToLower(name), value
+}
+
+func (h *fetchHeaders) append(name, value string) {
+	h.list = append(h.list, [2]string{name, value})
+}
+
+// set replaces the value of the first header of the name, and removes the others.
+func (h *fetchHeaders) set(name, value string) {
+	list := h.list[:0]
+	found := false
+	for _, header := range h.list {
+		if header[0] != name {
+			list = append(list, header)
+		} else if !found {
+			found = true
+			list = append(list, [2]string{name, value})
+		}
+	}
+	if !found {
+		list = append(list, [2]string{name, value})
+	}
+	h.list = list
+}
+
+func (h *fetchHeaders) delete(name string) {
+	list := h.list[:0]
+	for _, header := range h.list {
+		if header[0] != name {
+			list = append(list, header)
+		}
+	}
+	h.list = list
+}
+
+func (h *fetchHeaders) values(name string) []string {
+	var values []string
+	for _, header := range h.list {
+		if header[0] == name {
+			values = append(values, header[1])
+		}
+	}
+	return values
+}
+
+// get returns the values of the headers of the name, separated by commas.
+func (h *fetchHeaders) get(name string) (string, bool) {
+	values := h.values(name)
+	return strings.Join(values, ", "), values != nil
+}
+
+// entries returns the headers sorted by name, with the values of the headers
+// of the same name combined, except for the Set-Cookie headers.
+func (h *fetchHeaders) entries() [][2]string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, header := range h.list {
+		if !seen[header[0]] {
+			seen[header[0]] = true
+			names = append(names, header[0])
+		}
+	}
+	sort.Strings(names)
+
+	entries := make([][2]string, 0, len(names))
+	for _, name := range names {
+		if name == "set-cookie" {
+			for _, value := range h.values(name) {
+				entries = append(entries, [2]string{name, value})
+			}
+			continue
+		}
+		value, _ := h.get(name)
+		entries = append(entries, [2]string{name, value})
+	}
+	return entries
+}
+
+// fill adds the headers of the init of a Headers object, which is either
+// another Headers object, an iterable of [name, value] pairs or an object.
+func (f *fetchAPI) fill(h *fetchHeaders, init goja.Value) {
+	rt := f.rt
+	if common.IsNullish(init) {
+		return
+	}
+	if other, ok := f.internalOf(init).(*fetchHeaders); ok {
+		h.list = append(h.list, other.list...)
+		return
+	}
+
+	obj := init.ToObject(rt)
+	if !common.IsNullish(obj.GetSymbol(goja.SymIterator)) {
+		from, ok := goja.AssertFunction(rt.Get("Array").ToObject(rt).Get("from"))
+		if !ok {
+			panic(rt.NewTypeError("Array.from isn't a function"))
+		}
+		pairs, err := from(goja.Undefined(), init)
+		if err != nil {
+			panic(err)
+		}
+		pairsObj := pairs.ToObject(rt)
+		for i := int64(0); i < pairsObj.Get("length").ToInteger(); i++ {
+			pair := pairsObj.Get(strconv.FormatInt(i, 10)).ToObject(rt)
+			if pair.Get("length").ToInteger() != 2 {
+				panic(rt.NewTypeError("invalid headers, each header must be a [name, value] pair"))
+			}
+			h.append(normalizeHeader(rt, pair.Get("0").String(), pair.Get("1").String()))
+		}
+		return
+	}
+	for _, key := range obj.Keys() {
+		h.append(normalizeHeader(rt, key, obj.Get(key).String()))
+	}
+}
+
+// defineHeaders defines the Headers class.
+func (f *fetchAPI) defineHeaders() {
+	rt := f.rt
+	f.headers = rt.ToValue(func(call goja.ConstructorCall) *goja.Object {
+		h := &fetchHeaders{}
+		f.fill(h, call.Argument(0))
+		f.setInternal(call.This, h)
+		return call.This
+	}).ToObject(rt)
+
+	proto := f.headers.Get("prototype").ToObject(rt)
+	headersOf := func(this goja.Value) *fetchHeaders {
+		h, ok := f.internalOf(this).(*fetchHeaders)
+		if !ok {
+			panic(rt.NewTypeError("the object isn't a Headers"))
+		}
+		return h
+	}
+	mutableHeadersOf := func(this goja.Value) *fetchHeaders {
+		h := headersOf(this)
+		if h.immutable {
+			panic(rt.NewTypeError("the headers are immutable"))
+		}
+		return h
+	}
+
+	f.defineMethod(proto, "append", func(call goja.FunctionCall) goja.Value {
+		mutableHeadersOf(call.This).append(normalizeHeader(rt, call.Argument(0).String(), call.Argument(1).String()))
+		return goja.Undefined()
+	})
+	f.defineMethod(proto, "set", func(call goja.FunctionCall) goja.Value {
+		mutableHeadersOf(call.This).set(normalizeHeader(rt, call.Argument(0).String(), call.Argument(1).String()))
+		return goja.Undefined()
+	})
+	f.defineMethod(proto, "delete", func(call goja.FunctionCall) goja.Value {
+		mutableHeadersOf(call.This).delete(strings.ToLower(call.Argument(0).String()))
+		return goja.Undefined()
+	})
+	f.defineMethod(proto, "get", func(call goja.FunctionCall) goja.Value {
+		value, ok := headersOf(call.This).get(strings.ToLower(call.Argument(0).String()))
+		if !ok {
+			return goja.Null()
+		}
+		return rt.ToValue(value)
+	})
+	f.defineMethod(proto, "getSetCookie", func(call goja.FunctionCall) goja.Value {
+		values := headersOf(call.This).values("set-cookie")
+		return rt.ToValue(append([]string{}, values...))
+	})
+	f.defineMethod(proto, "has", func(call goja.FunctionCall) goja.Value {
+		_, ok := headersOf(call.This).get(strings.ToLower(call.Argument(0).String()))
+		return rt.ToValue(ok)
+	})
+	f.defineMethod(proto, "forEach", func(call goja.FunctionCall) goja.Value {
+		callback, ok := goja.AssertFunction(call.Argument(0))
+		if !ok {
+			panic(rt.NewTypeError("the callback of forEach isn't a function"))
+		}
+		for _, entry := range headersOf(call.This).entries() {
+			if _, err := callback(call.Argument(1), rt.ToValue(entry[1]), rt.ToValue(entry[0]), call.This); err != nil {
+				panic(err)
+			}
+		}
+		return goja.Undefined()
+	})
+
+	// the iterators are the ones of the arrays of the entries, names and values
+	iterate := func(item func(entry [2]string) goja.Value) func(call goja.FunctionCall) goja.Value {
+		return func(call goja.FunctionCall) goja.Value {
+			entries := headersOf(call.This).entries()
+			items := make([]interface{}, len(entries))
+			for i, entry := range entries {
+				items[i] = item(entry)
+			}
+			array := rt.NewArray(items...)
+			values, ok := goja.AssertFunction(array.Get("values"))
+			if !ok {
+				panic(rt.NewTypeError("the values of the arrays isn't a function"))
+			}
+			iterator, err := values(array)
+			if err != nil {
+				panic(err)
+			}
+			return iterator
+		}
+	}
+	entries := iterate(func(entry [2]string) goja.Value { return rt.NewArray(entry[0], entry[1]) })
+	f.defineMethod(proto, "entries", entries)
+	f.defineMethod(proto, "keys", iterate(func(entry [2]string) goja.Value { return rt.ToValue(entry[0]) }))
+	f.defineMethod(proto, "values", iterate(func(entry [2]string) goja.Value { return rt.ToValue(entry[1]) }))
+	must(rt, proto.SetSymbol(goja.SymIterator, entries))
+}
+
+// newHeaders returns a new Headers object of the headers.
+func (f *fetchAPI) newHeaders(h *fetchHeaders) *goja.Object {
+	obj := f.rt.NewObject()
+	must(f.rt, obj.SetPrototype(f.headers.Get("prototype").ToObject(f.rt)))
+	f.setInternal(obj, h)
+	return obj
+}
@@ -0,0 +1,250 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/metrics"
+)
+
+func newFetchTestCase(t *testing.T) *httpTestCase {
+	t.Helper()
+	ts := newTestCase(t)
+	require.NoError(t, common.SetupAbortController(ts.runtime.VU.Runtime()))
+	require.NoError(t, New().SetupGlobals(ts.runtime.VU))
+	_, err := ts.runtime.VU.Runtime().RunString(`
+		function assertEquals(actual, expected) {
+			if (JSON.stringify(actual) !== JSON.stringify(expected)) {
+				throw new Error("expected " + JSON.stringify(expected) + ", got " + JSON.stringify(actual));
+			}
+		}
+	`)
+	require.NoError(t, err)
+	return ts
+}
+
+func TestFetch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Get", func(t *testing.T) {
+		t.Parallel()
+		ts := newFetchTestCase(t)
+		_, err := ts.runtime.RunOnEventLoop(wrapInAsyncLambda(ts.tb.Replacer.Replace(`
+			const res = await fetch("HTTPBIN_URL/get?a=1", {
+				headers: { "X-Test": "first" },
+				k6: { tags: { endpoint: "get" } },
+			});
+			assertEquals([res.ok, res.status, res.statusText, res.type, res.redirected], [true, 200, "OK", "basic", false]);
+			assertEquals(res.url, "HTTPBIN_URL/get?a=1");
+			assertEquals(res.headers.get("Content-Type"), "application/json; encoding=utf-8");
+			assertEquals(res.bodyUsed, false);
+			const body = await res.json();
+			assertEquals([body.args.a, body.headers["X-Test"]], [["1"], ["first"]]);
+			assertEquals(res.bodyUsed, true);
+			try {
+				await res.text();
+				throw new Error("the body was read twice");
+			} catch (e) {
+				assertEquals(e.message, "the body has already been read");
+			}
+		`)))
+		require.NoError(t, err)
+
+		bufSamples := metrics.GetBufferedSamples(ts.samples)
+		require.Len(t, bufSamples, 1)
+		for _, sample := range bufSamples[0].GetSamples() {
+			endpoint, _ := sample.Tags.Get("endpoint")
+			assert.Equal(t, "get", endpoint)
+			method, _ := sample.Tags.Get("method")
+			assert.Equal(t, "GET", method)
+		}
+	})
+
+	t.Run("Post", func(t *testing.T) {
+		t.Parallel()
+		ts := newFetchTestCase(t)
+		_, err := ts.runtime.RunOnEventLoop(wrapInAsyncLambda(ts.tb.Replacer.Replace(`
+			let res = await fetch("HTTPBIN_URL/post", { method: "post", body: "hello" });
+			let body = await res.json();
+			assertEquals([body.data, body.headers["Content-Type"]], ["hello", ["text/plain;charset=UTF-8"]]);
+
+			const req = new Request("HTTPBIN_URL/patch", {
+				method: "PATCH",
+				headers: [["Content-Type", "application/octet-stream"]],
+				body: new Uint8Array([104, 105]),
+			});
+			assertEquals([req.method, req.url, req.headers.get("content-type")],
+				["PATCH", "HTTPBIN_URL/patch", "application/octet-stream"]);
+			res = await fetch(req);
+			body = await res.json();
+			assertEquals(body.data, "hi");
+			try {
+				await fetch(req);
+				throw new Error("the body of the request was sent twice");
+			} catch (e) {
+				assertEquals(e.message, "the body of the request has already been read");
+			}
+
+			try {
+				new Request("HTTPBIN_URL/get", { body: "hello" });
+				throw new Error("the GET request has a body");
+			} catch (e) {
+				assertEquals(e.message, "the GET requests can't have a body");
+			}
+		`)))
+		require.NoError(t, err)
+	})
+
+	t.Run("Statuses", func(t *testing.T) {
+		t.Parallel()
+		ts := newFetchTestCase(t)
+		_, err := ts.runtime.RunOnEventLoop(wrapInAsyncLambda(ts.tb.Replacer.Replace(`
+			let res = await fetch("HTTPBIN_URL/status/404");
+			assertEquals([res.ok, res.status, res.statusText], [false, 404, "Not Found"]);
+
+			res = await fetch("HTTPBIN_URL/redirect-to?url=/get");
+			assertEquals([res.status, res.redirected, res.url], [200, true, "HTTPBIN_URL/get"]);
+
+			res = await fetch("HTTPBIN_URL/redirect-to?url=/get", { redirect: "manual" });
+			assertEquals([res.status, res.headers.get("location")], [302, "/get"]);
+
+			try {
+				await fetch("HTTPBIN_URL/redirect-to?url=/get", { redirect: "error" });
+				throw new Error("the redirect was followed");
+			} catch (e) {
+				assertEquals(e.message, "fetch failed: the response is a redirect, with the error redirect mode");
+			}
+
+			try {
+				await fetch("HTTPSBIN_URL:1/get");
+				throw new Error("the request didn't fail");
+			} catch (e) {
+				assertEquals([e instanceof TypeError, e.message.startsWith("fetch failed: ")], [true, true]);
+			}
+		`)))
+		require.NoError(t, err)
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		t.Parallel()
+		ts := newFetchTestCase(t)
+		_, err := ts.runtime.RunOnEventLoop(wrapInAsyncLambda(ts.tb.Replacer.Replace(`
+			let res = await fetch("HTTPBIN_URL/bytes/100000", { k6: { responseType: "stream" } });
+			const reader = res.body.getReader();
+			let size = 0;
+			while (true) {
+				const { done, value } = await reader.read();
+				if (done) { break; }
+				size += value.length;
+			}
+			assertEquals([size, res.bodyUsed], [100000, true]);
+
+			res = await fetch("HTTPBIN_URL/get", { k6: { responseType: "stream" } });
+			assertEquals((await res.json()).url, "HTTPBIN_URL/get");
+		`)))
+		require.NoError(t, err)
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		t.Parallel()
+		ts := newFetchTestCase(t)
+		_, err := ts.runtime.RunOnEventLoop(wrapInAsyncLambda(ts.tb.Replacer.Replace(`
+			const controller = new AbortController();
+			const p = fetch("HTTPBIN_URL/delay/10", { signal: controller.signal });
+			Promise.resolve().then(() => controller.abort());
+			try {
+				await p;
+				throw new Error("the request wasn't aborted");
+			} catch (e) {
+				assertEquals(e.name, "AbortError");
+			}
+		`)))
+		require.NoError(t, err)
+	})
+
+	t.Run("InitContext", func(t *testing.T) {
+		t.Parallel()
+		ts := newTestCase(t)
+		ts.runtime.VU.StateField = nil
+		require.NoError(t, New().SetupGlobals(ts.runtime.VU))
+		_, err := ts.runtime.VU.Runtime().RunString(`fetch("https://k6.io")`)
+		require.ErrorContains(t, err, ErrHTTPForbiddenInInitContext.Error())
+	})
+}
+
+func TestFetchHeaders(t *testing.T) {
+	t.Parallel()
+	ts := newFetchTestCase(t)
+	_, err := ts.runtime.RunOnEventLoop(wrapInAsyncLambda(`
+		const headers = new Headers({ "X-B": "1", "Set-Cookie": "a=1" });
+		headers.append("x-b", "2");
+		headers.append("X-A", " 3 ");
+		headers.append("set-cookie", "b=2");
+		assertEquals([headers.get("x-b"), headers.get("X-A"), headers.get("missing")], ["1, 2", "3", null]);
+		assertEquals([...headers], [["set-cookie", "a=1"], ["set-cookie", "b=2"], ["x-a", "3"], ["x-b", "1, 2"]]);
+		assertEquals([...headers.keys()], ["set-cookie", "set-cookie", "x-a", "x-b"]);
+		assertEquals(headers.getSetCookie(), ["a=1", "b=2"]);
+
+		headers.set("X-B", "4");
+		headers.delete("set-cookie");
+		assertEquals([headers.has("Set-Cookie"), headers.has("x-b")], [false, true]);
+		const seen = [];
+		headers.forEach((value, name) => seen.push(name + "=" + value));
+		assertEquals(seen, ["x-a=3", "x-b=4"]);
+		assertEquals([...new Headers(headers).entries()], [["x-a", "3"], ["x-b", "4"]]);
+
+		try {
+			headers.set("invalid name", "1");
+			throw new Error("the invalid header was set");
+		} catch (e) {
+			assertEquals(e instanceof TypeError, true);
+		}
+		try {
+			Response.error().headers.set("x-a", "1");
+			throw new Error("the immutable headers were modified");
+		} catch (e) {
+			assertEquals(e.message, "the headers are immutable");
+		}
+	`))
+	require.NoError(t, err)
+}
+
+func TestFetchResponse(t *testing.T) {
+	t.Parallel()
+	ts := newFetchTestCase(t)
+	_, err := ts.runtime.RunOnEventLoop(wrapInAsyncLambda(`
+		let res = new Response("hello", { status: 201, statusText: "Created", headers: { "X-A": "1" } });
+		assertEquals([res instanceof Response, res.status, res.statusText, res.ok, res.type],
+			[true, 201, "Created", true, "default"]);
+		assertEquals(res.headers.get("content-type"), "text/plain;charset=UTF-8");
+		const clone = res.clone();
+		assertEquals([await res.text(), await clone.text()], ["hello", "hello"]);
+
+		res = Response.json({ b: 1, a: [2] }, { status: 404 });
+		assertEquals([res.ok, res.headers.get("content-type")], [false, "application/json"]);
+		assertEquals(await res.text(), '{"b":1,"a":[2]}');
+
+		res = new Response(new Uint8Array([1, 2, 3]).buffer);
+		assertEquals([...new Uint8Array(await res.arrayBuffer())], [1, 2, 3]);
+		res = new Response(new Uint8Array([4, 5]));
+		assertEquals([...(await res.bytes())], [4, 5]);
+
+		res = new Response(null, { status: 204 });
+		assertEquals([res.body, await res.text()], [null, ""]);
+
+		res = new Response("chunk");
+		const { value } = await res.body.getReader().read();
+		assertEquals([...value], [99, 104, 117, 110, 107]);
+
+		try {
+			await new Response("{").json();
+			throw new Error("the invalid JSON was parsed");
+		} catch (e) {
+			assertEquals(e instanceof SyntaxError, true);
+		}
+	`))
+	require.NoError(t, err)
+}
@@ -2,11 +2,11 @@ package http
 
 import (
 	"net/http"
-	"net/http/cookiejar"
 
 	"github.com/dop251/goja"
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/netext"
 	"go.k6.io/k6/lib/netext/httpext"
 )
@@ -27,8 +27,9 @@ type ModuleInstance struct {
 }
 
 var (
-	_ modules.Module   = &RootModule{}
-	_ modules.Instance = &ModuleInstance{}
+	_ modules.Module        = &RootModule{}
+	_ modules.GlobalsModule = &RootModule{}
+	_ modules.Instance      = &ModuleInstance{}
 )
 
 // New returns a pointer to a new HTTP RootModule.
@@ -52,6 +53,7 @@ func (r *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 		// https://github.com/grafana/k6/issues/2293
 		moduleInstance:   mi,
 		responseCallback: defaultExpectedStatuses.match,
+		authCache:        httpext.NewAuthCache(),
 	}
 
 	mustExport := func(name string, value interface{}) {
@@ -143,18 +145,18 @@ func (mi *ModuleInstance) defineConstants() {
 
 func (mi *ModuleInstance) newCookieJar(call goja.ConstructorCall) *goja.Object {
 	rt := mi.vu.Runtime()
-	jar, err := cookiejar.New(nil)
+	jar, err := lib.NewCookieJar()
 	if err != nil {
 		common.Throw(rt, err)
 	}
-	return rt.ToValue(&CookieJar{mi, jar}).ToObject(rt)
+	return rt.ToValue(&CookieJar{mi, jar.Jar, jar}).ToObject(rt)
 }
 
 // getVUCookieJar returns the active cookie jar for the current VU.
 func (mi *ModuleInstance) getVUCookieJar(call goja.FunctionCall) goja.Value {
 	rt := mi.vu.Runtime()
 	if state := mi.vu.State(); state != nil {
-		return rt.ToValue(&CookieJar{mi, state.CookieJar})
+		return rt.ToValue(&CookieJar{mi, state.CookieJar, state.TrackedCookieJar})
 	}
 	common.Throw(rt, ErrJarForbiddenInInitContext)
 	return nil
@@ -180,4 +182,6 @@ func (mi *ModuleInstance) URL(parts []string, pieces ...string) (httpext.URL, er
 type Client struct {
 	moduleInstance   *ModuleInstance
 	responseCallback func(int) bool
+	// authCache holds the digest challenges for the preemptive authentication
+	authCache *httpext.AuthCache
 }
@@ -0,0 +1,191 @@
+package http
+
+import (
+	"errors"
+	"io"
+
+	"github.com/dop251/goja"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules/k6/experimental/streams"
+	"go.k6.io/k6/lib/netext/httpext"
+)
+
+var errRequestFinished = errors.New("the request has finished before its body was fully sent")
+
+// iteratorBody streams the body of a request from the chunks returned by a JS
+// iterator, e.g. a generator, so huge uploads don't have to be loaded in memory.
+// The request is sent from another goroutine, and the chunks are pulled on
+// the goroutine of the VU's runtime, while the request is being made.
+type iteratorBody struct {
+	rt       *goja.Runtime
+	iterator *goja.Object
+	next     goja.Callable
+
+	pulls    chan chan<- iteratorChunk
+	finished chan struct{}
+	buf      []byte
+	err      error
+}
+
+type iteratorChunk struct {
+	data []byte
+	done bool
+	err  error
+}
+
+var _ io.Reader = &iteratorBody{}
+
+// isIterator returns true if the value is an object with a next() method.
+func isIterator(v goja.Value) bool {
+	obj, ok := v.(*goja.Object)
+	if !ok {
+		return false
+	}
+	_, ok = goja.AssertFunction(obj.Get("next"))
+	return ok
+}
+
+// isStreamedBody returns true if the request body is streamed while the request
+// is made, it's an iterator, a ReadableStream of k6/experimental/streams or an
+// object with a readable() method, e.g. a File of k6/experimental/fs.
+func isStreamedBody(v goja.Value) bool {
+	if isIterator(v) || streams.IsReadableStream(v) {
+		return true
+	}
+	obj, ok := v.(*goja.Object)
+	if !ok {
+		return false
+	}
+	_, ok = goja.AssertFunction(obj.Get("readable"))
+	return ok
+}
+
+// newStreamedBody returns the reader of the streamed request body. The
+// readers of the ReadableStreams have to be closed once the request is done.
+func (c *Client) newStreamedBody(body *goja.Object) (io.Reader, error) {
+	vu := c.moduleInstance.vu
+	if isIterator(body) {
+		return newIteratorBody(vu.Runtime(), body), nil
+	}
+
+	stream := goja.Value(body)
+	if !streams.IsReadableStream(body) {
+		// the File objects are read through their ReadableStream
+		readable, _ := goja.AssertFunction(body.Get("readable"))
+		var err error
+		if stream, err = readable(body); err != nil {
+			return nil, err
+		}
+	}
+	return streams.NewReader(vu, stream)
+}
+
+// closeRequestBody closes the reader of the request body, if it has to be.
+func closeRequestBody(req *httpext.ParsedHTTPRequest) {
+	if body, ok := req.BodyReader.(io.Closer); ok {
+		_ = body.Close()
+	}
+}
+
+func newIteratorBody(rt *goja.Runtime, iterator *goja.Object) *iteratorBody {
+	next, _ := goja.AssertFunction(iterator.Get("next"))
+	return &iteratorBody{
+		rt:       rt,
+		iterator: iterator,
+		next:     next,
+		pulls:    make(chan chan<- iteratorChunk),
+		finished: make(chan struct{}),
+	}
+}
+
+// Read implements io.Reader, it's called by the HTTP transport
+// while the request is sent, from a goroutine other than the VU's.
+func (b *iteratorBody) Read(p []byte) (int, error) {
+	for len(b.buf) == 0 {
+		if b.err != nil {
+			return 0, b.err
+		}
+		reply := make(chan iteratorChunk, 1)
+		select {
+		case b.pulls <- reply:
+		case <-b.finished:
+			return 0, errRequestFinished
+		}
+		chunk := <-reply
+		switch {
+		case chunk.err != nil:
+			b.err = chunk.err
+		case chunk.done:
+			b.err = io.EOF
+		default:
+			b.buf = chunk.data
+		}
+	}
+
+	n := copy(p, b.buf)
+	b.buf = b.buf[n:]
+	return n, nil
+}
+
+// pull returns the next chunk of the iterator, it
+// has to be called on the goroutine of the VU's runtime.
+func (b *iteratorBody) pull() iteratorChunk {
+	res, err := b.next(b.iterator)
+	if err != nil {
+		return iteratorChunk{err: err}
+	}
+	obj := res.ToObject(b.rt)
+	if obj.Get("done").ToBoolean() {
+		return iteratorChunk{done: true}
+	}
+	data, err := common.ToBytes(obj.Get("value").Export())
+	if err != nil {
+		return iteratorChunk{err: err}
+	}
+	return iteratorChunk{data: data}
+}
+
+// makeRequest makes the request, and if its body is streamed from an iterator,
+// it pulls the chunks of the body on the current goroutine, which must be the
+// one of the VU's runtime, while the request is made on another goroutine.
+//
+// The event loop doesn't run during the request, so the ReadableStreams with
+// a source implemented by the script can't be read: they can only be sent
+// by http.asyncRequest.
+func (c *Client) makeRequest(req *httpext.ParsedHTTPRequest) (*httpext.Response, error) {
+	ctx, state := c.moduleInstance.vu.Context(), c.moduleInstance.vu.State()
+	var body *iteratorBody
+	switch b := req.BodyReader.(type) {
+	case *iteratorBody:
+		body = b
+	case *streams.Reader:
+		defer closeRequestBody(req)
+		if b.OnEventLoop() {
+			return nil, errors.New("the ReadableStreams with a source implemented by the script " +
+				"can be sent only by http.asyncRequest")
+		}
+		return httpext.MakeRequest(ctx, state, req)
+	default:
+		return httpext.MakeRequest(ctx, state, req)
+	}
+	defer close(body.finished)
+
+	type result struct {
+		resp *httpext.Response
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := httpext.MakeRequest(ctx, state, req)
+		done <- result{resp: resp, err: err}
+	}()
+	for {
+		select {
+		case reply := <-body.pulls:
+			reply <- body.pull()
+		case res := <-done:
+			return res.resp, res.err
+		}
+	}
+}
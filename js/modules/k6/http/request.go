@@ -2,6 +2,8 @@ package http
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"mime/multipart"
@@ -16,6 +18,8 @@ import (
 	"gopkg.in/guregu/null.v3"
 
 	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/netext/httpext"
 	"go.k6.io/k6/lib/types"
 )
@@ -46,7 +50,7 @@ func (c *Client) Request(method string, url goja.Value, args ...goja.Value) (*Re
 		return c.handleParseRequestError(err)
 	}
 
-	resp, err := httpext.MakeRequest(c.moduleInstance.vu.Context(), state, req)
+	resp, err := c.makeRequest(req)
 	if err != nil {
 		return nil, err
 	}
@@ -56,7 +60,12 @@ func (c *Client) Request(method string, url goja.Value, args ...goja.Value) (*Re
 
 func splitRequestArgs(args []goja.Value) (body interface{}, params goja.Value) {
 	if len(args) > 0 {
-		body = args[0].Export()
+		if isStreamedBody(args[0]) {
+			// the chunks of the body are read while the request is made
+			body = args[0]
+		} else {
+			body = args[0].Export()
+		}
 	}
 	if len(args) > 1 {
 		params = args[1]
@@ -91,8 +100,23 @@ func (c *Client) asyncRequest(method string, url goja.Value, args ...goja.Value)
 	body, params := splitRequestArgs(args)
 	rt := c.moduleInstance.vu.Runtime()
 	req, err := c.parseRequest(method, url, body, params)
+	if err == nil {
+		if _, ok := req.BodyReader.(*iteratorBody); ok {
+			err = errors.New("request bodies can be streamed from iterators only by the synchronous http functions")
+		}
+	}
+	// the signal param aborts the request, e.g. to model the users who give up waiting
+	var signal *common.AbortSignal
+	if err == nil && !common.IsNullish(params) {
+		if signal, err = common.GetAbortSignal(params.ToObject(rt).Get("signal")); err == nil && signal != nil {
+			req.Abort = signal.Done()
+		}
+	}
 	p, resolve, reject := rt.NewPromise()
 	if err != nil {
+		if req != nil {
+			closeRequestBody(req)
+		}
 		var resp *Response
 		if resp, err = c.handleParseRequestError(err); err != nil {
 			reject(err)
@@ -101,12 +125,22 @@ func (c *Client) asyncRequest(method string, url goja.Value, args ...goja.Value)
 		}
 		return p, nil
 	}
+	if signal != nil && signal.Aborted {
+		closeRequestBody(req)
+		reject(signal.Reason)
+		return p, nil
+	}
 
 	callback := c.moduleInstance.vu.RegisterCallback()
 
 	go func() {
 		resp, err := httpext.MakeRequest(c.moduleInstance.vu.Context(), state, req)
+		closeRequestBody(req)
 		callback(func() error {
+			if err != nil && signal != nil && signal.Aborted {
+				reject(signal.Reason)
+				return nil
+			}
 			if err != nil {
 				reject(err)
 				return nil //nolint:nilerr // we want to reject the promise in this case
@@ -120,15 +154,40 @@ func (c *Client) asyncRequest(method string, url goja.Value, args ...goja.Value)
 	return p, nil
 }
 
-// processResponse stores the body as an ArrayBuffer if indicated by
-// respType. This is done here instead of in httpext.readResponseBody to avoid
-// a reverse dependency on js/common or goja.
+// processResponse stores the body as an ArrayBuffer or as a ResponseBodyStream
+// if indicated by respType. This is done here instead of in httpext.readResponseBody
+// to avoid a reverse dependency on js/common or goja.
 func (c *Client) processResponse(resp *httpext.Response, respType httpext.ResponseType) {
-	if respType == httpext.ResponseTypeBinary && resp.Body != nil {
+	if resp.Body == nil {
+		return
+	}
+	switch respType { //nolint:exhaustive
+	case httpext.ResponseTypeBinary:
 		resp.Body = c.moduleInstance.vu.Runtime().NewArrayBuffer(resp.Body.([]byte))
+	case httpext.ResponseTypeStream:
+		stream := resp.Body.(*httpext.ResponseStream) //nolint:forcetypeassert
+		resp.Body = &ResponseBodyStream{stream: stream, client: c}
+		closeOnEventLoop(c.moduleInstance.vu, stream)
 	}
 }
 
+// closeOnEventLoop closes the response body stream on the event loop, after
+// its reading has ended or the context of its request is done. The request is
+// finished, and its Response is updated, when the stream is closed, so that's
+// done on the event loop even when the body is read off it, e.g. through a
+// ReadableStream, or when the request times out before the body is read. An
+// unread body isn't a failure.
+func closeOnEventLoop(vu modules.VU, stream *httpext.ResponseStream) {
+	callback := vu.RegisterCallback()
+	go func() {
+		stream.Wait()
+		callback(func() error {
+			_ = stream.Close()
+			return nil
+		})
+	}()
+}
+
 func (c *Client) responseFromHTTPext(resp *httpext.Response) *Response {
 	return &Response{Response: resp, client: c}
 }
@@ -165,6 +224,7 @@ func (c *Client) parseRequest(
 		Redirects:        state.Options.MaxRedirects,
 		Cookies:          make(map[string]*httpext.HTTPRequestCookie),
 		ResponseCallback: c.responseCallback,
+		AuthCache:        c.authCache,
 		TagsAndMeta:      c.moduleInstance.vu.State().Tags.GetCurrentValues(),
 	}
 
@@ -245,6 +305,7 @@ func (c *Client) parseRequest(
 		return nil
 	}
 
+	var streamedBody *goja.Object
 	if body != nil {
 		switch data := body.(type) {
 		case map[string]goja.Value:
@@ -256,6 +317,9 @@ func (c *Client) parseRequest(
 			if err := handleObjectBody(newData); err != nil {
 				return nil, err
 			}
+		case *goja.Object:
+			// it's read once the request is fully parsed, so it isn't locked by failed parsings
+			streamedBody = data
 		case goja.ArrayBuffer:
 			result.Body = bytes.NewBuffer(data.Bytes())
 		case map[string]interface{}:
@@ -273,9 +337,7 @@ func (c *Client) parseRequest(
 
 	result.Req.Header.Set("User-Agent", state.Options.UserAgent.String)
 
-	if state.CookieJar != nil {
-		result.ActiveJar = state.CookieJar
-	}
+	result.ActiveJar = lib.ActiveCookieJar(state.CookieJar, state.TrackedCookieJar)
 
 	// TODO: ditch goja.Value, reflections and Object and use a simple go map and type assertions?
 	if params != nil && !goja.IsUndefined(params) && !goja.IsNull(params) {
@@ -335,7 +397,7 @@ func (c *Client) parseRequest(
 				}
 				switch v := jarV.Export().(type) {
 				case *CookieJar:
-					result.ActiveJar = v.Jar
+					result.ActiveJar = lib.ActiveCookieJar(v.Jar, v.Tracked)
 				}
 			case "compression":
 				algosString := strings.TrimSpace(params.Get(k).ToString().String())
@@ -361,6 +423,12 @@ func (c *Client) parseRequest(
 				}
 			case "auth":
 				result.Auth = params.Get(k).String()
+			case "authMode":
+				authMode, err := httpext.AuthModeString(params.Get(k).String())
+				if err != nil {
+					return nil, err
+				}
+				result.AuthMode = authMode
 			case "timeout":
 				t, err := types.GetDurationValue(params.Get(k).Export())
 				if err != nil {
@@ -375,6 +443,43 @@ func (c *Client) parseRequest(
 					return nil, err
 				}
 				result.ResponseType = responseType
+			case "protocol":
+				protocol, err := httpext.ProtocolString(params.Get(k).String())
+				if err != nil {
+					return nil, err
+				}
+				result.Protocol = protocol
+			case "connection":
+				connection, err := httpext.ConnectionModeString(params.Get(k).String())
+				if err != nil {
+					return nil, err
+				}
+				result.Connection = connection
+			case "hosts":
+				if common.IsNullish(params.Get(k)) {
+					continue
+				}
+				var source map[string]string
+				if err := rt.ExportTo(params.Get(k), &source); err != nil {
+					return nil, fmt.Errorf("invalid hosts value: %w", err)
+				}
+				hosts, err := types.ParseHosts(source)
+				if err != nil {
+					return nil, fmt.Errorf("invalid hosts value: %w", err)
+				}
+				result.Hosts = hosts
+			case "tlsAuth":
+				tlsConfig, err := parseTLSAuth(rt, params.Get(k), state.TLSConfig)
+				if err != nil {
+					return nil, fmt.Errorf("invalid tlsAuth value: %w", err)
+				}
+				result.TLSConfig = tlsConfig
+			case "retries":
+				retries, err := parseRetries(rt, params.Get(k))
+				if err != nil {
+					return nil, fmt.Errorf("invalid retries value: %w", err)
+				}
+				result.Retries = retries
 			case "responseCallback":
 				v := params.Get(k).Export()
 				if v == nil {
@@ -392,9 +497,128 @@ func (c *Client) parseRequest(
 		httpext.SetRequestCookies(result.Req, result.ActiveJar, result.Cookies)
 	}
 
+	if streamedBody != nil {
+		if result.BodyReader, err = c.newStreamedBody(streamedBody); err != nil {
+			return nil, err
+		}
+	}
+
 	return result, nil
 }
 
+// parseRetries parses the retries param, which is either the number of retries with
+// the default policy, or an object that overrides the parts of the default policy.
+func parseRetries(rt *goja.Runtime, v goja.Value) (httpext.RetryPolicy, error) {
+	if common.IsNullish(v) {
+		return httpext.RetryPolicy{}, nil
+	}
+	obj, ok := v.Export().(map[string]interface{})
+	if !ok {
+		count := v.ToInteger()
+		if count < 0 {
+			return httpext.RetryPolicy{}, fmt.Errorf("the count of retries can't be negative, got %d", count)
+		}
+		return httpext.DefaultRetryPolicy(count), nil
+	}
+
+	policy := httpext.DefaultRetryPolicy(1)
+	for k, val := range obj {
+		switch k {
+		case "count":
+			policy.Count = rt.ToValue(val).ToInteger()
+			if policy.Count < 0 {
+				return httpext.RetryPolicy{}, fmt.Errorf("the count of retries can't be negative, got %d", policy.Count)
+			}
+		case "backoff":
+			d, err := types.GetDurationValue(val)
+			if err != nil {
+				return httpext.RetryPolicy{}, fmt.Errorf("invalid backoff: %w", err)
+			}
+			policy.Backoff = d
+		case "statuses":
+			var statuses []int
+			if err := rt.ExportTo(rt.ToValue(val), &statuses); err != nil {
+				return httpext.RetryPolicy{}, fmt.Errorf("invalid statuses: %w", err)
+			}
+			policy.Statuses = statuses
+		case "networkErrors":
+			policy.NetworkErrors = rt.ToValue(val).ToBoolean()
+		case "allowNonIdempotent":
+			policy.NonIdempotent = rt.ToValue(val).ToBoolean()
+		default:
+			return httpext.RetryPolicy{}, fmt.Errorf("unknown option '%s'", k)
+		}
+	}
+	return policy, nil
+}
+
+// parseTLSAuth returns a copy of the parent TLS config with the overrides of
+// the request, or nil if it doesn't override anything.
+func parseTLSAuth(rt *goja.Runtime, v goja.Value, parent *tls.Config) (*tls.Config, error) {
+	if common.IsNullish(v) {
+		return nil, nil //nolint:nilnil
+	}
+	obj, ok := v.Export().(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an object, got %s", v.ExportType())
+	}
+
+	tlsConfig := &tls.Config{} //nolint:gosec
+	if parent != nil {
+		tlsConfig = parent.Clone()
+	}
+	var auth lib.TLSAuth
+	for k, val := range obj {
+		switch k {
+		case "cert":
+			auth.Cert = rt.ToValue(val).String()
+		case "key":
+			auth.Key = rt.ToValue(val).String()
+		case "password":
+			auth.Password = null.StringFrom(rt.ToValue(val).String())
+		case "cacerts":
+			var cacerts []string
+			if s, isString := val.(string); isString {
+				cacerts = []string{s}
+			} else if err := rt.ExportTo(rt.ToValue(val), &cacerts); err != nil {
+				return nil, fmt.Errorf("invalid cacerts: %w", err)
+			}
+			pool := x509.NewCertPool()
+			for _, cacert := range cacerts {
+				if !pool.AppendCertsFromPEM([]byte(cacert)) {
+					return nil, errors.New("failed to append the ca certificate from PEM")
+				}
+			}
+			tlsConfig.RootCAs = pool
+		case "minVersion", "maxVersion":
+			version, found := lib.SupportedTLSVersions[rt.ToValue(val).String()]
+			if !found {
+				return nil, fmt.Errorf("unknown TLS version '%s'", rt.ToValue(val).String())
+			}
+			if k == "minVersion" {
+				tlsConfig.MinVersion = uint16(version)
+			} else {
+				tlsConfig.MaxVersion = uint16(version)
+			}
+		case "serverName":
+			tlsConfig.ServerName = rt.ToValue(val).String()
+		default:
+			return nil, fmt.Errorf("unknown option '%s'", k)
+		}
+	}
+
+	if auth.Cert != "" || auth.Key != "" {
+		cert, err := auth.Certificate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+		//nolint:staticcheck // the certificate of the request replaces all of the VU ones
+		tlsConfig.NameToCertificate = nil
+	}
+	return tlsConfig, nil
+}
+
 func (c *Client) prepareBatchArray(requests []interface{}) (
 	[]httpext.BatchParsedHTTPRequest, []*Response, error,
 ) {
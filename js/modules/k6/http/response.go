@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/tidwall/gjson"
 
 	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules/k6/experimental/streams"
 	"go.k6.io/k6/js/modules/k6/html"
 	"go.k6.io/k6/lib/netext/httpext"
 )
@@ -261,3 +263,81 @@ func (res *Response) ClickLink(args ...goja.Value) (*Response, error) {
 
 	return res.client.Request(http.MethodGet, rt.ToValue(requestURL.String()), goja.Undefined(), requestParams)
 }
+
+// defaultStreamChunkSize is the maximum size of the chunks read from
+// the body stream, if a different size isn't passed to read().
+const defaultStreamChunkSize = 64 * 1024
+
+// ResponseBodyStream is the body of a response with the 'stream' responseType,
+// which the script reads in chunks instead of it being buffered in memory. The
+// iteration doesn't end until the body is fully read or closed, or until the
+// request times out.
+type ResponseBodyStream struct {
+	stream *httpext.ResponseStream
+	client *Client
+
+	// readable is true once the body is read through a ReadableStream.
+	readable bool
+}
+
+var errBodyReadable = errors.New("the body is already read through a ReadableStream")
+
+// Read returns the next chunk of the body, with up to size bytes, as an
+// ArrayBuffer. It returns null after the whole body has been read.
+func (s *ResponseBodyStream) Read(size ...int64) (goja.Value, error) {
+	if s.readable {
+		return nil, errBodyReadable
+	}
+
+	chunkSize := int64(defaultStreamChunkSize)
+	if len(size) > 0 {
+		if size[0] <= 0 {
+			return nil, fmt.Errorf("the chunk size must be greater than zero, got %d", size[0])
+		}
+		chunkSize = size[0]
+	}
+
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := s.stream.Read(buf)
+		if err != nil {
+			// the reading has ended, so the request is finished
+			_ = s.stream.Close()
+		}
+		if n > 0 {
+			rt := s.client.moduleInstance.vu.Runtime()
+			return rt.ToValue(rt.NewArrayBuffer(buf[:n])), nil
+		}
+		if errors.Is(err, io.EOF) {
+			return goja.Null(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Readable returns a ReadableStream, as the ones of the k6/experimental/streams
+// module, of the body, with Uint8Array chunks of up to size bytes. The body is
+// then only read through the stream, which is read off the event loop.
+func (s *ResponseBodyStream) Readable(size ...int64) (*goja.Object, error) {
+	if s.readable {
+		return nil, errBodyReadable
+	}
+
+	chunkSize := int64(defaultStreamChunkSize)
+	if len(size) > 0 {
+		if size[0] <= 0 {
+			return nil, fmt.Errorf("the chunk size must be greater than zero, got %d", size[0])
+		}
+		chunkSize = size[0]
+	}
+
+	s.readable = true
+	return streams.NewReadableStreamFromReader(s.client.moduleInstance.vu, s.stream, int(chunkSize))
+}
+
+// Close stops reading the body and finishes the request, the rest of the body is discarded.
+func (s *ResponseBodyStream) Close() {
+	_ = s.stream.Close()
+}
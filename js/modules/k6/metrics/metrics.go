@@ -34,7 +34,12 @@ func (mi *ModuleInstance) newMetric(call goja.ConstructorCall, t metrics.MetricT
 		if len(isTime) > 0 && isTime[0] {
 			valueType = metrics.Time
 		}
-		m, err := initEnv.Registry.NewMetric(name, t, valueType)
+		// the options are after the buckets for the Histogram metrics
+		buckets, options := goja.Undefined(), call.Argument(2)
+		if t == metrics.Histogram {
+			buckets, options = call.Argument(2), call.Argument(3)
+		}
+		m, err := mi.registerMetric(name, t, valueType, buckets, options)
 		if err != nil {
 			return nil, err
 		}
@@ -57,6 +62,36 @@ func (mi *ModuleInstance) newMetric(call goja.ConstructorCall, t metrics.MetricT
 	return v.ToObject(rt), nil
 }
 
+// registerMetric registers the metric on the registry, the buckets
+// argument is only used by the Histogram metrics and it's optional.
+// The options can set the unit and the description of the metric.
+func (mi *ModuleInstance) registerMetric(
+	name string, t metrics.MetricType, valueType metrics.ValueType, buckets, options goja.Value,
+) (*metrics.Metric, error) {
+	registry := mi.vu.InitEnv().Registry
+	if t == metrics.Histogram && !common.IsNullish(buckets) {
+		var bounds []float64
+		if err := mi.vu.Runtime().ExportTo(buckets, &bounds); err != nil {
+			return nil, fmt.Errorf("invalid buckets for the histogram metric '%s', an array of numbers is expected: %w",
+				name, err)
+		}
+		if _, err := registry.NewHistogram(name, bounds, valueType); err != nil {
+			return nil, err
+		}
+	}
+
+	var info struct {
+		Unit        string `js:"unit"`
+		Description string `js:"description"`
+	}
+	if !common.IsNullish(options) {
+		if err := mi.vu.Runtime().ExportTo(options, &info); err != nil {
+			return nil, fmt.Errorf("invalid options for the metric '%s': %w", name, err)
+		}
+	}
+	return registry.NewMetricWithInfo(name, t, info.Unit, info.Description, valueType)
+}
+
 const warnMessageValueMaxSize = 100
 
 func limitValue(v string) string {
@@ -154,10 +189,11 @@ func New() *RootModule {
 func (mi *ModuleInstance) Exports() modules.Exports {
 	return modules.Exports{
 		Named: map[string]interface{}{
-			"Counter": mi.XCounter,
-			"Gauge":   mi.XGauge,
-			"Trend":   mi.XTrend,
-			"Rate":    mi.XRate,
+			"Counter":   mi.XCounter,
+			"Gauge":     mi.XGauge,
+			"Trend":     mi.XTrend,
+			"Rate":      mi.XRate,
+			"Histogram": mi.XHistogram,
 		},
 	}
 }
@@ -197,3 +233,12 @@ func (mi *ModuleInstance) XRate(call goja.ConstructorCall, rt *goja.Runtime) *go
 	}
 	return v
 }
+
+// XHistogram is a histogram constructor
+func (mi *ModuleInstance) XHistogram(call goja.ConstructorCall, rt *goja.Runtime) *goja.Object {
+	v, err := mi.newMetric(call, metrics.Histogram)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	return v
+}
@@ -69,10 +69,11 @@ func (a addTest) run(t *testing.T) {
 func TestMetrics(t *testing.T) {
 	t.Parallel()
 	types := map[string]metrics.MetricType{
-		"Counter": metrics.Counter,
-		"Gauge":   metrics.Gauge,
-		"Trend":   metrics.Trend,
-		"Rate":    metrics.Rate,
+		"Counter":   metrics.Counter,
+		"Gauge":     metrics.Gauge,
+		"Trend":     metrics.Trend,
+		"Rate":      metrics.Rate,
+		"Histogram": metrics.Histogram,
 	}
 	values := map[string]addTestValue{
 		"Float":                 {JS: `2.5`, Float: 2.5},
@@ -235,3 +236,84 @@ func TestMetricDuplicates(t *testing.T) {
 
 	require.True(t, v.ToBoolean())
 }
+
+func TestMetricHistogramBuckets(t *testing.T) {
+	t.Parallel()
+	rt := goja.New()
+	rt.SetFieldNameMapper(common.FieldNameMapper{})
+
+	registry := metrics.NewRegistry()
+	mii := &modulestest.VU{
+		RuntimeField: rt,
+		InitEnvField: &common.InitEnvironment{TestPreInitState: &lib.TestPreInitState{Registry: registry}},
+		CtxField:     context.Background(),
+	}
+	m, ok := New().NewModuleInstance(mii).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, rt.Set("metrics", m.Exports().Named))
+
+	_, err := rt.RunString(`
+		var sizes = new metrics.Histogram("payload_size", false, [10, 100, 1000])
+		var depths = new metrics.Histogram("queue_depth")
+	`)
+	require.NoError(t, err)
+
+	sizes, ok := registry.Get("payload_size").Sink.(*metrics.HistogramSink)
+	require.True(t, ok)
+	assert.Equal(t, []float64{10, 100, 1000}, sizes.Buckets())
+
+	depths, ok := registry.Get("queue_depth").Sink.(*metrics.HistogramSink)
+	require.True(t, ok)
+	assert.Equal(t, metrics.DefaultHistogramBuckets, depths.Buckets())
+
+	_, err = rt.RunString(`new metrics.Histogram("payload_size", false, [10, 100])`)
+	require.ErrorContains(t, err, "already exists but with the buckets")
+
+	_, err = rt.RunString(`new metrics.Histogram("unsorted", false, [100, 10])`)
+	require.ErrorContains(t, err, "increasing order")
+
+	_, err = rt.RunString(`new metrics.Histogram("invalid", false, "buckets")`)
+	require.ErrorContains(t, err, "an array of numbers is expected")
+}
+
+func TestMetricInfo(t *testing.T) {
+	t.Parallel()
+	rt := goja.New()
+	rt.SetFieldNameMapper(common.FieldNameMapper{})
+
+	registry := metrics.NewRegistry()
+	mii := &modulestest.VU{
+		RuntimeField: rt,
+		InitEnvField: &common.InitEnvironment{TestPreInitState: &lib.TestPreInitState{Registry: registry}},
+		CtxField:     context.Background(),
+	}
+	m, ok := New().NewModuleInstance(mii).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, rt.Set("metrics", m.Exports().Named))
+
+	_, err := rt.RunString(`
+		new metrics.Trend("payload_size", false, { unit: "bytes", description: "The size of the payloads" });
+		new metrics.Gauge("queue_usage", false, { unit: "percent" });
+		new metrics.Histogram("queue_depth", false, [10, 100], { description: "The depth of the queue" });
+		new metrics.Counter("plain");
+	`)
+	require.NoError(t, err)
+
+	size := registry.Get("payload_size")
+	assert.Equal(t, metrics.UnitBytes, size.Unit)
+	assert.Equal(t, "The size of the payloads", size.Description)
+	assert.Equal(t, metrics.UnitPercent, registry.Get("queue_usage").Unit)
+	depth := registry.Get("queue_depth")
+	assert.Empty(t, depth.Unit)
+	assert.Equal(t, "The depth of the queue", depth.Description)
+	depthSink, ok := depth.Sink.(*metrics.HistogramSink)
+	require.True(t, ok)
+	assert.Equal(t, []float64{10, 100}, depthSink.Buckets())
+	assert.Empty(t, registry.Get("plain").Unit)
+
+	_, err = rt.RunString(`new metrics.Trend("payload_size", false, { unit: "ms" })`)
+	require.ErrorContains(t, err, "already exists but with unit bytes, instead of ms")
+
+	_, err = rt.RunString(`new metrics.Trend("invalid", false, 42)`)
+	require.ErrorContains(t, err, "invalid options for the metric 'invalid'")
+}
@@ -0,0 +1,315 @@
+// Package timers implements the k6/timers JS module, also available as
+// k6/experimental/timers, with the timers of the event loop: setTimeout,
+// setInterval, queueMicrotask and the postTask of the scheduler. The intervals
+// don't drift, so that the scripts which pace themselves with timers keep
+// their rate in long tests.
+package timers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/sirupsen/logrus"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the JS module.
+	ModuleInstance struct {
+		vu modules.VU
+
+		// the tasks are only accessed on the event loop
+		tasks  map[uint32]*task
+		lastID uint32
+		seq    uint64
+	}
+)
+
+// Ensure the interfaces are implemented correctly
+var (
+	_ modules.Instance = &ModuleInstance{}
+	_ modules.Module   = &RootModule{}
+)
+
+// New returns a pointer to a new RootModule instance
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns
+// a new instance for each VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu, tasks: make(map[uint32]*task)}
+}
+
+// Exports implements the modules.Instance interface and returns
+// the exports of the JS module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"setTimeout":     mi.setTimeout,
+			"clearTimeout":   mi.clear,
+			"setInterval":    mi.setInterval,
+			"clearInterval":  mi.clear,
+			"queueMicrotask": mi.queueMicrotask,
+			"scheduler": map[string]interface{}{
+				"postTask": mi.postTask,
+			},
+		},
+	}
+}
+
+// priority is the priority of a task of the scheduler, the tasks of a higher
+// priority run first, when several tasks are due.
+type priority int
+
+const (
+	priorityUserBlocking priority = iota
+	priorityUserVisible
+	priorityBackground
+)
+
+func parsePriority(s string) (priority, error) {
+	switch s {
+	case "user-blocking":
+		return priorityUserBlocking, nil
+	case "user-visible":
+		return priorityUserVisible, nil
+	case "background":
+		return priorityBackground, nil
+	default:
+		return 0, fmt.Errorf("invalid priority %q, it must be user-blocking, user-visible or background", s)
+	}
+}
+
+// task is a timer, or a task posted to the scheduler, which runs on the event
+// loop once it's due.
+type task struct {
+	id       uint32
+	name     string // the function which created the task
+	priority priority
+	run      func() error
+
+	// when is the time when the task is due, and seq is the order in which
+	// the tasks are scheduled, for the tasks which are due at the same time.
+	when time.Time
+	seq  uint64
+	stop chan struct{}
+
+	// the intervals are rescheduled at the next multiple of the interval
+	// since their start, so that the time spent running them doesn't drift
+	// the next runs.
+	repeat   bool
+	start    time.Time
+	interval time.Duration
+}
+
+// before returns true if the task runs before the other one, when both are due.
+func (t *task) before(o *task) bool {
+	if t.priority != o.priority {
+		return t.priority < o.priority
+	}
+	if !t.when.Equal(o.when) {
+		return t.when.Before(o.when)
+	}
+	return t.seq < o.seq
+}
+
+func (mi *ModuleInstance) newTask(name string, delay float64, run func() error) *task {
+	if delay < 0 {
+		delay = 0
+	}
+	mi.lastID++
+	now := time.Now()
+	d := time.Duration(delay * float64(time.Millisecond))
+	return &task{
+		id: mi.lastID, name: name, priority: priorityUserVisible, run: run,
+		when: now.Add(d), start: now, interval: d,
+	}
+}
+
+// schedule waits for the task to be due off the event loop, and then runs
+// the task which is due first, which is either this task or one which was
+// due before it but whose wait hasn't ended yet. So the tasks run in the
+// order they are due, even if the waits end in a different order.
+func (mi *ModuleInstance) schedule(t *task) {
+	mi.seq++
+	t.seq = mi.seq
+	t.stop = make(chan struct{})
+	mi.tasks[t.id] = t
+
+	enqueue := mi.vu.RegisterCallback()
+	ctx, logger := mi.vu.Context(), mi.logger()
+	when, stop := t.when, t.stop
+	go func() {
+		timer := time.NewTimer(time.Until(when))
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			enqueue(mi.runNext)
+		case <-stop:
+			enqueue(func() error { return nil })
+		case <-ctx.Done():
+			logger.Warnf("%s %d was stopped because the VU iteration was interrupted", t.name, t.id)
+			enqueue(func() error {
+				if current, ok := mi.tasks[t.id]; ok && current.stop == stop {
+					delete(mi.tasks, t.id)
+				}
+				return nil
+			})
+		}
+	}()
+}
+
+// logger returns the logger of the VU, or the one of the init context.
+func (mi *ModuleInstance) logger() logrus.FieldLogger {
+	if state := mi.vu.State(); state != nil {
+		return state.Logger
+	}
+	return mi.vu.InitEnv().Logger
+}
+
+// runNext runs the task which is due first, if any.
+func (mi *ModuleInstance) runNext() error {
+	now := time.Now()
+	var next *task
+	for _, t := range mi.tasks {
+		if t.when.After(now) {
+			continue
+		}
+		if next == nil || t.before(next) {
+			next = t
+		}
+	}
+	if next == nil {
+		return nil
+	}
+
+	delete(mi.tasks, next.id)
+	if next.repeat {
+		// the interval is rescheduled first, so that it can be cleared by its callback
+		next.when = nextRun(next.start, next.interval, now)
+		mi.schedule(next)
+	}
+	return next.run()
+}
+
+// nextRun returns the next time after now which is a multiple of the interval
+// since the start. The runs which were missed, because the event loop was
+// busy, are skipped rather than run in a burst.
+func nextRun(start time.Time, interval time.Duration, now time.Time) time.Time {
+	if interval <= 0 {
+		return now
+	}
+	return start.Add((now.Sub(start)/interval + 1) * interval)
+}
+
+func (mi *ModuleInstance) clear(id uint32) {
+	if t, ok := mi.tasks[id]; ok {
+		delete(mi.tasks, id)
+		close(t.stop)
+	}
+}
+
+// call returns the function which calls the callback with the arguments.
+func (mi *ModuleInstance) call(name string, callback goja.Value, args []goja.Value) func() error {
+	rt := mi.vu.Runtime()
+	fn, ok := goja.AssertFunction(callback)
+	if !ok {
+		panic(rt.NewTypeError(name + "'s callback isn't a function"))
+	}
+	return func() error {
+		_, err := fn(rt.GlobalObject(), args...)
+		return err
+	}
+}
+
+// setTimeout calls the callback with the arguments once, after the delay in
+// milliseconds, and returns the ID of the timer.
+func (mi *ModuleInstance) setTimeout(callback goja.Value, delay float64, args ...goja.Value) uint32 {
+	t := mi.newTask("setTimeout", delay, mi.call("setTimeout", callback, args))
+	mi.schedule(t)
+	return t.id
+}
+
+// setInterval calls the callback with the arguments every delay in
+// milliseconds, until the interval is cleared, and returns its ID.
+func (mi *ModuleInstance) setInterval(callback goja.Value, delay float64, args ...goja.Value) uint32 {
+	t := mi.newTask("setInterval", delay, mi.call("setInterval", callback, args))
+	t.repeat = true
+	mi.schedule(t)
+	return t.id
+}
+
+// queueMicrotask calls the callback once the current task has finished, before
+// the timers and the other tasks, as the reactions of the resolved promises.
+func (mi *ModuleInstance) queueMicrotask(callback goja.Value) {
+	rt := mi.vu.Runtime()
+	fn, ok := goja.AssertFunction(callback)
+	if !ok {
+		panic(rt.NewTypeError("queueMicrotask's callback isn't a function"))
+	}
+
+	promise, resolve, _ := rt.NewPromise()
+	resolve(goja.Undefined())
+	then, ok := goja.AssertFunction(rt.ToValue(promise).ToObject(rt).Get("then"))
+	if !ok {
+		common.Throw(rt, fmt.Errorf("the then of the promise isn't a function"))
+	}
+	_, err := then(rt.ToValue(promise), rt.ToValue(func() (goja.Value, error) {
+		return fn(goja.Undefined())
+	}))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+}
+
+// postTask schedules the callback as a task of the priority option, after
+// the delay option in milliseconds, and returns a promise of its result.
+// When several tasks are due, the tasks of the higher priority run first.
+func (mi *ModuleInstance) postTask(callback goja.Value, options goja.Value) *goja.Promise {
+	rt := mi.vu.Runtime()
+	fn, ok := goja.AssertFunction(callback)
+	if !ok {
+		panic(rt.NewTypeError("postTask's callback isn't a function"))
+	}
+
+	p, delay := priorityUserVisible, float64(0)
+	if !common.IsNullish(options) {
+		opts := options.ToObject(rt)
+		if v := opts.Get("priority"); !common.IsNullish(v) {
+			var err error
+			if p, err = parsePriority(v.String()); err != nil {
+				panic(rt.NewTypeError(err.Error()))
+			}
+		}
+		if v := opts.Get("delay"); !common.IsNullish(v) {
+			delay = v.ToFloat()
+		}
+	}
+
+	promise, resolve, reject := rt.NewPromise()
+	t := mi.newTask("postTask", delay, func() error {
+		result, err := fn(goja.Undefined())
+		if err != nil {
+			if exception, ok := err.(*goja.Exception); ok { //nolint:errorlint
+				reject(exception.Value())
+			} else {
+				reject(err)
+			}
+			return nil
+		}
+		resolve(result)
+		return nil
+	})
+	t.priority = p
+	mi.schedule(t)
+	return promise
+}
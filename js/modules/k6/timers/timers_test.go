@@ -0,0 +1,177 @@
+package timers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/js/modulestest"
+)
+
+func newTestRuntime(t *testing.T) *modulestest.Runtime {
+	t.Helper()
+	ts := modulestest.NewRuntime(t)
+	m, ok := New().NewModuleInstance(ts.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, ts.VU.Runtime().Set("timers", m.Exports().Named))
+
+	_, err := ts.VU.Runtime().RunString(`
+		var { setTimeout, clearTimeout, setInterval, clearInterval, queueMicrotask, scheduler } = timers;
+
+		function assertEquals(actual, expected) {
+			if (JSON.stringify(actual) !== JSON.stringify(expected)) {
+				throw new Error("expected " + JSON.stringify(expected) + ", got " + JSON.stringify(actual));
+			}
+		}
+	`)
+	require.NoError(t, err)
+	return ts
+}
+
+func TestSetTimeout(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestRuntime(t)
+	_, err := ts.RunOnEventLoop(`
+		var log = [];
+		setTimeout(() => log.push("30"), 30);
+		setTimeout((a, b) => log.push("10 " + a + b), 10, "a", "b");
+		setTimeout(() => log.push("10 bis"), 10);
+		const id = setTimeout(() => log.push("cleared"), 5);
+		setTimeout(() => log.push("0"));
+		clearTimeout(id);
+		clearTimeout(12345);
+		log.push("sync");
+	`)
+	require.NoError(t, err)
+
+	v, err := ts.VU.Runtime().RunString(`log`)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"sync", "0", "10 ab", "10 bis", "30"}, v.Export())
+}
+
+func TestSetInterval(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestRuntime(t)
+	_, err := ts.RunOnEventLoop(`
+		var runs = 0;
+		var start = Date.now();
+		var elapsed;
+		const id = setInterval(() => {
+			runs++;
+			// the time spent in the callback doesn't delay the next runs
+			const end = Date.now() + 5;
+			while (Date.now() < end) {}
+			if (runs === 4) {
+				elapsed = Date.now() - start;
+				clearInterval(id);
+			}
+		}, 10);
+	`)
+	require.NoError(t, err)
+
+	v, err := ts.VU.Runtime().RunString(`[runs, elapsed]`)
+	require.NoError(t, err)
+	var result []int64
+	require.NoError(t, ts.VU.Runtime().ExportTo(v, &result))
+	assert.Equal(t, int64(4), result[0])
+	assert.GreaterOrEqual(t, result[1], int64(40))
+}
+
+func TestNextRun(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(100, 0)
+	interval := 10 * time.Millisecond
+
+	tests := []struct {
+		name     string
+		now      time.Duration
+		expected time.Duration
+	}{
+		{name: "on time", now: 10 * time.Millisecond, expected: 20 * time.Millisecond},
+		{name: "late", now: 14 * time.Millisecond, expected: 20 * time.Millisecond},
+		{name: "missed runs", now: 37 * time.Millisecond, expected: 40 * time.Millisecond},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, start.Add(tc.expected), nextRun(start, interval, start.Add(tc.now)), tc.name)
+	}
+
+	now := start.Add(time.Second)
+	assert.Equal(t, now, nextRun(start, 0, now))
+}
+
+func TestQueueMicrotask(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestRuntime(t)
+	_, err := ts.RunOnEventLoop(`
+		var log = [];
+		setTimeout(() => log.push("timeout"));
+		queueMicrotask(() => log.push("microtask"));
+		Promise.resolve().then(() => log.push("promise"));
+		log.push("sync");
+	`)
+	require.NoError(t, err)
+
+	v, err := ts.VU.Runtime().RunString(`log`)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"sync", "microtask", "promise", "timeout"}, v.Export())
+
+	_, err = ts.RunOnEventLoop(`queueMicrotask(() => { throw new Error("oops"); })`)
+	require.ErrorContains(t, err, "oops")
+}
+
+func TestPostTask(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestRuntime(t)
+	_, err := ts.RunOnEventLoop(`
+		var log = [];
+		var results = [];
+		scheduler.postTask(() => log.push("background"), { priority: "background" });
+		scheduler.postTask(() => log.push("user-visible"));
+		scheduler.postTask(() => log.push("user-blocking"), { priority: "user-blocking" });
+		scheduler.postTask(() => log.push("delayed"), { priority: "user-blocking", delay: 10 });
+		scheduler.postTask(() => 42).then(v => results.push(v));
+		scheduler.postTask(() => { throw new Error("oops"); }).catch(e => results.push(e.message));
+	`)
+	require.NoError(t, err)
+
+	v, err := ts.VU.Runtime().RunString(`[log, results]`)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		[]interface{}{"user-blocking", "user-visible", "background", "delayed"},
+		[]interface{}{int64(42), "oops"},
+	}, v.Export())
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		script, err string
+	}{
+		{script: `setTimeout("code", 10)`, err: "setTimeout's callback isn't a function"},
+		{script: `setInterval(null, 10)`, err: "setInterval's callback isn't a function"},
+		{script: `queueMicrotask(1)`, err: "queueMicrotask's callback isn't a function"},
+		{
+			script: `scheduler.postTask(() => {}, { priority: "high" })`,
+			err:    `invalid priority "high", it must be user-blocking, user-visible or background`,
+		},
+		{script: `setTimeout(() => { throw new Error("oops"); })`, err: "oops"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.script, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestRuntime(t)
+			_, err := ts.RunOnEventLoop(tc.script)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"io"
 	"net"
 	"net/http"
-	"net/http/cookiejar"
 	"strconv"
 	"strings"
 	"sync"
@@ -103,8 +102,9 @@ type wsConnectArgs struct {
 	setupFn           goja.Callable
 	headers           http.Header
 	enableCompression bool
-	cookieJar         *cookiejar.Jar
+	cookieJar         http.CookieJar
 	tagsAndMeta       *metrics.TagsAndMeta
+	signal            *common.AbortSignal
 }
 
 const writeWait = 10 * time.Second
@@ -132,6 +132,14 @@ func (mi *WS) Connect(url string, args ...goja.Value) (*HTTPResponse, error) {
 
 	parsedArgs.tagsAndMeta.SetSystemTagOrMetaIfEnabled(state.Options.SystemTags, metrics.TagURL, url)
 
+	// the signal closes the connection, e.g. to model the users who leave,
+	// it's aborted by the handlers, so it's waited on in the control loop
+	var aborted <-chan struct{}
+	if parsedArgs.signal != nil {
+		parsedArgs.signal.ThrowIfAborted()
+		aborted = parsedArgs.signal.Done()
+	}
+
 	socket, httpResponse, connEndHook, err := mi.dial(ctx, state, rt, url, parsedArgs)
 	defer connEndHook()
 	if err != nil {
@@ -236,6 +244,11 @@ func (mi *WS) Connect(url string, args ...goja.Value) (*HTTPResponse, error) {
 			// socket events will not be forwarded to the VU
 			_ = socket.closeConnection(websocket.CloseGoingAway)
 
+		case <-aborted:
+			aborted = nil
+			socket.tagsAndMeta.SetSystemTagOrMetaIfEnabled(state.Options.SystemTags, metrics.TagAborted, "true")
+			_ = socket.closeConnection(websocket.CloseGoingAway)
+
 		case <-socket.done:
 			// This is the final exit point normally triggered by closeConnection
 			return wsResponse, nil
@@ -263,10 +276,7 @@ func (mi *WS) dial(
 		TLSClientConfig:   tlsConfig,
 		EnableCompression: args.enableCompression,
 	}
-	// this is needed because of how interfaces work and that wsd.Jar is http.Cookiejar
-	if args.cookieJar != nil {
-		wsd.Jar = args.cookieJar
-	}
+	wsd.Jar = args.cookieJar
 
 	connStart := time.Now()
 	conn, httpResponse, dialErr := wsd.DialContext(ctx, url, args.headers)
@@ -647,7 +657,7 @@ func parseConnectArgs(state *lib.State, rt *goja.Runtime, args ...goja.Value) (*
 	parsedArgs := &wsConnectArgs{
 		setupFn:     setupFn,
 		headers:     headers,
-		cookieJar:   state.CookieJar,
+		cookieJar:   lib.ActiveCookieJar(state.CookieJar, state.TrackedCookieJar),
 		tagsAndMeta: &tagsAndMeta,
 	}
 
@@ -681,7 +691,7 @@ func parseConnectArgs(state *lib.State, rt *goja.Runtime, args ...goja.Value) (*
 				continue
 			}
 			if v, ok := jarV.Export().(*httpModule.CookieJar); ok {
-				parsedArgs.cookieJar = v.Jar
+				parsedArgs.cookieJar = lib.ActiveCookieJar(v.Jar, v.Tracked)
 			}
 		case "compression":
 			// deflate compression algorithm is supported - as defined in RFC7692
@@ -699,6 +709,12 @@ func parseConnectArgs(state *lib.State, rt *goja.Runtime, args ...goja.Value) (*
 			}
 
 			parsedArgs.enableCompression = true
+		case "signal":
+			signal, err := common.GetAbortSignal(params.Get(k))
+			if err != nil {
+				return nil, fmt.Errorf("invalid ws.connect() signal: %w", err)
+			}
+			parsedArgs.signal = signal
 		}
 	}
 
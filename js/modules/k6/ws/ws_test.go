@@ -18,6 +18,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"gopkg.in/guregu/null.v3"
 
+	"go.k6.io/k6/js/common"
 	httpModule "go.k6.io/k6/js/modules/k6/http"
 	"go.k6.io/k6/js/modulestest"
 	"go.k6.io/k6/lib"
@@ -383,6 +384,50 @@ func TestSessionClientClose(t *testing.T) {
 	assertSessionMetricsEmitted(t, metrics.GetBufferedSamples(test.samples), "", sr("WSBIN_URL/ws-echo"), statusProtocolSwitch, "")
 }
 
+func TestSessionAbort(t *testing.T) {
+	t.Parallel()
+	tb := httpmultibin.NewHTTPMultiBin(t)
+	sr := tb.Replacer.Replace
+
+	test := newTestState(t)
+	test.VU.State().Options.SystemTags = metrics.NewSystemTagSet(metrics.TagURL, metrics.TagAborted)
+	require.NoError(t, common.SetupAbortController(test.VU.Runtime()))
+	_, err := test.VU.Runtime().RunString(sr(`
+		var closed = false;
+		var controller = new AbortController();
+		ws.connect("WSBIN_URL/ws-echo", { signal: controller.signal }, function(socket){
+			socket.on("open", function() {
+				socket.send("test");
+			})
+			// the user leaves once the first message is received
+			socket.on("message", function() {
+				controller.abort();
+			})
+			socket.on("close", function() {
+				closed = true;
+			})
+		});
+		if (!closed) { throw new Error ("close event not fired"); }
+		`))
+	require.NoError(t, err)
+
+	var aborted bool
+	for _, container := range metrics.GetBufferedSamples(test.samples) {
+		for _, sample := range container.GetSamples() {
+			if sample.Metric.Name == metrics.WSSessionDurationName {
+				value, ok := sample.Tags.Get("aborted")
+				aborted = ok && value == "true"
+			}
+		}
+	}
+	assert.True(t, aborted)
+
+	_, err = test.VU.Runtime().RunString(sr(`
+		ws.connect("WSBIN_URL/ws-echo", { signal: AbortSignal.abort("gave up") }, function(socket){});
+		`))
+	require.ErrorContains(t, err, "gave up")
+}
+
 func TestSessionClose(t *testing.T) {
 	t.Parallel()
 	serverCloseTests := []struct {
@@ -32,6 +32,15 @@ type Module interface {
 	NewModuleInstance(VU) Instance
 }
 
+// GlobalsModule is implemented by the modules which, besides their exports,
+// define globals in the runtime of every VU, e.g. k6/http defines fetch. The
+// globals are defined before the script is run, even if it doesn't import
+// the module.
+type GlobalsModule interface {
+	Module
+	SetupGlobals(VU) error
+}
+
 // Instance is what a module needs to return
 type Instance interface {
 	Exports() Exports
@@ -3,6 +3,7 @@ package modules
 import (
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
 
 	"github.com/dop251/goja"
@@ -46,6 +47,27 @@ func NewModuleResolver(goModules map[string]interface{}, loadCJS FileLoader, c *
 	}
 }
 
+// SetupGlobals defines the globals of the Go modules which implement
+// GlobalsModule in the runtime of the provided VU.
+func (mr *ModuleResolver) SetupGlobals(vu VU) error {
+	names := make([]string, 0, len(mr.goModules))
+	for name := range mr.goModules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		m, ok := mr.goModules[name].(GlobalsModule)
+		if !ok {
+			continue
+		}
+		if err := m.SetupGlobals(vu); err != nil {
+			return fmt.Errorf("unable to set up the globals of the %s module: %w", name, err)
+		}
+	}
+	return nil
+}
+
 func (mr *ModuleResolver) resolveSpecifier(basePWD *url.URL, arg string) (*url.URL, error) {
 	specifier, err := loader.Resolve(basePWD, arg)
 	if err != nil {
@@ -7,9 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"net/http"
-	"net/http/cookiejar"
 	"reflect"
 	"strconv"
 	"strings"
@@ -30,13 +30,17 @@ import (
 	"go.k6.io/k6/lib/consts"
 	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/lib/netext"
+	"go.k6.io/k6/lib/netext/httpext"
 	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/loader"
 	"go.k6.io/k6/metrics"
 )
 
 // Ensure Runner implements the lib.Runner interface
-var _ lib.Runner = &Runner{}
+var (
+	_ lib.Runner            = &Runner{}
+	_ lib.FunctionEvaluator = &Runner{}
+)
 
 // TODO: https://github.com/grafana/k6/issues/2186
 // An advanced TLS support should cover the rid of the warning
@@ -56,9 +60,10 @@ type Runner struct {
 	RPSLimit       *rate.Limiter
 	RunTags        *metrics.TagSet
 
-	console    *console
-	setupData  []byte
-	BufferPool *lib.BufferPool
+	console     *console
+	harRecorder *lib.HARRecorder
+	setupData   []byte
+	BufferPool  *lib.BufferPool
 }
 
 // New returns a new Runner for the provided source
@@ -196,58 +201,91 @@ func (r *Runner) newVU(
 		//nolint:staticcheck // ignore SA1019 we can deprecate it but we have to continue to support the previous code.
 		tlsConfig.NameToCertificate = nameToCert
 	}
-	transport := &http.Transport{
-		Proxy:               http.ProxyFromEnvironment,
-		TLSClientConfig:     tlsConfig,
-		DialContext:         dialer.DialContext,
-		DisableCompression:  true,
-		DisableKeepAlives:   r.Bundle.Options.NoConnectionReuse.Bool,
-		MaxIdleConns:        int(r.Bundle.Options.Batch.Int64),
-		MaxIdleConnsPerHost: int(r.Bundle.Options.BatchPerHost.Int64),
-	}
+	maxIdleConns := r.Bundle.Options.Batch.Int64
+	if r.Bundle.Options.MaxIdleConnections.Valid {
+		maxIdleConns = r.Bundle.Options.MaxIdleConnections.Int64
+	}
+	dialContext := dialer.DialContext
+	if connStats := r.preInitState.ConnStats; connStats != nil {
+		dialContext = connStats.TrackDialContext(dialContext)
+	}
+	newTransport := func(disableKeepAlives bool, tlsConfig *tls.Config) *http.Transport {
+		transport := &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			TLSClientConfig:     tlsConfig,
+			DialContext:         dialContext,
+			DisableCompression:  true,
+			DisableKeepAlives:   disableKeepAlives,
+			MaxIdleConns:        int(maxIdleConns),
+			MaxIdleConnsPerHost: int(r.Bundle.Options.BatchPerHost.Int64),
+			MaxConnsPerHost:     int(r.Bundle.Options.MaxConnectionsPerHost.Int64),
+		}
 
-	if r.forceHTTP1() {
-		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper) // send over h1 protocol
-	} else {
-		_ = http2.ConfigureTransport(transport) // send over h2 protocol
+		if r.forceHTTP1() {
+			transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper) // send over h1 protocol
+		} else {
+			_ = http2.ConfigureTransport(transport) // send over h2 protocol
+		}
+		return transport
+	}
+	// the requests can explicitly ask for a pooled or a new connection, regardless of the options
+	transportReuse, transportNoReuse := newTransport(false, tlsConfig), newTransport(true, tlsConfig)
+	transport := transportReuse
+	if r.Bundle.Options.NoConnectionReuse.Bool {
+		transport = transportNoReuse
 	}
 
-	cookieJar, err := cookiejar.New(nil)
+	cookieJar, err := r.newCookieJar()
 	if err != nil {
 		return nil, err
 	}
 
 	vu := &VU{
-		ID:             idLocal,
-		IDGlobal:       idGlobal,
-		iteration:      int64(-1),
-		BundleInstance: *bi,
-		Runner:         r,
-		Transport:      transport,
-		Dialer:         dialer,
-		CookieJar:      cookieJar,
-		TLSConfig:      tlsConfig,
-		Console:        r.console,
-		BufferPool:     r.BufferPool,
-		Samples:        samplesOut,
-		scenarioIter:   make(map[string]uint64),
+		ID:               idLocal,
+		IDGlobal:         idGlobal,
+		iteration:        int64(-1),
+		BundleInstance:   *bi,
+		Runner:           r,
+		Transport:        transport,
+		TransportH2C:     httpext.NewH2CTransport(dialer.DialContext),
+		TransportH3:      httpext.NewHTTP3Transport(dialer, tlsConfig),
+		TransportReuse:   transportReuse,
+		TransportNoReuse: transportNoReuse,
+		ConnStats:        r.preInitState.ConnStats,
+		Dialer:           dialer,
+		CookieJar:        cookieJar,
+		TLSConfig:        tlsConfig,
+		Console:          r.console,
+		BufferPool:       r.BufferPool,
+		Samples:          samplesOut,
+		scenarioIter:     make(map[string]uint64),
 	}
 
 	vu.state = &lib.State{
-		Logger:         vu.Runner.preInitState.Logger,
-		Options:        vu.Runner.Bundle.Options,
-		Transport:      vu.Transport,
-		Dialer:         vu.Dialer,
-		TLSConfig:      vu.TLSConfig,
-		CookieJar:      cookieJar,
-		RPSLimit:       vu.Runner.RPSLimit,
-		BufferPool:     vu.BufferPool,
-		VUID:           vu.ID,
-		VUIDGlobal:     vu.IDGlobal,
-		Samples:        vu.Samples,
-		Tags:           lib.NewVUStateTags(vu.Runner.RunTags),
-		Group:          r.defaultGroup,
-		BuiltinMetrics: r.preInitState.BuiltinMetrics,
+		Logger:           vu.Runner.preInitState.Logger,
+		Options:          vu.Runner.Bundle.Options,
+		Transport:        vu.Transport,
+		TransportH2C:     vu.TransportH2C,
+		TransportH3:      vu.TransportH3,
+		TransportReuse:   vu.TransportReuse,
+		TransportNoReuse: vu.TransportNoReuse,
+		NewTLSTransport: func(tlsConfig *tls.Config) http.RoundTripper {
+			return newTransport(true, tlsConfig)
+		},
+		ConnStats:        vu.ConnStats,
+		Dialer:           vu.Dialer,
+		TLSConfig:        vu.TLSConfig,
+		CookieJar:        cookieJar.Jar,
+		TrackedCookieJar: cookieJar,
+		HARRecorder:      vu.Runner.harRecorder,
+		RPSLimit:         vu.Runner.RPSLimit,
+		BufferPool:       vu.BufferPool,
+		VUID:             vu.ID,
+		VUIDGlobal:       vu.IDGlobal,
+		Samples:          vu.Samples,
+		Tags:             lib.NewVUStateTags(vu.Runner.RunTags),
+		Group:            r.defaultGroup,
+		BuiltinMetrics:   r.preInitState.BuiltinMetrics,
 	}
 	vu.moduleVUImpl.state = vu.state
 	_ = vu.Runtime.Set("console", vu.Console)
@@ -432,6 +470,53 @@ func (r *Runner) HandleSummary(ctx context.Context, summary *lib.Summary) (map[s
 	return getSummaryResult(rawResult)
 }
 
+// EvaluateFunction calls the exported function once for each of the
+// arguments, in a single transient VU, and returns the numeric results.
+func (r *Runner) EvaluateFunction(ctx context.Context, name string, args []float64) ([]float64, error) {
+	if !r.IsExecutable(name) {
+		return nil, fmt.Errorf("exported function '%s' not found", name)
+	}
+
+	out := make(chan metrics.SampleContainer, 100)
+	defer close(out)
+	go func() { // discard all metrics
+		for range out {
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	vu, err := r.newVU(ctx, 0, 0, out)
+	if err != nil {
+		return nil, err
+	}
+	fn, ok := goja.AssertFunction(vu.getExported(name))
+	if !ok {
+		return nil, fmt.Errorf("exported identifier %s must be a function", name)
+	}
+
+	go func() {
+		<-ctx.Done()
+		vu.Runtime.Interrupt(context.Canceled)
+	}()
+	vu.moduleVUImpl.ctx = ctx
+
+	results := make([]float64, len(args))
+	for i, arg := range args {
+		v, err := fn(goja.Undefined(), vu.Runtime.ToValue(arg))
+		if err != nil {
+			return nil, fmt.Errorf("error while calling %s(%v): %w", name, arg, err)
+		}
+		result := v.ToFloat()
+		if math.IsNaN(result) || math.IsInf(result, 0) {
+			return nil, fmt.Errorf("%s(%v) returned %s, which isn't a finite number", name, arg, v.String())
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
 func (r *Runner) SetOptions(opts lib.Options) error {
 	r.Bundle.Options = opts
 	r.RPSLimit = nil
@@ -456,6 +541,21 @@ func (r *Runner) SetOptions(opts lib.Options) error {
 		r.console = c
 	}
 
+	if opts.HAROutput.Valid {
+		if r.harRecorder != nil {
+			_ = r.harRecorder.Close()
+		}
+		sampleRate := 1.0
+		if opts.HARSampleRate.Valid {
+			sampleRate = opts.HARSampleRate.Float64
+		}
+		hr, err := lib.NewHARRecorder(opts.HAROutput.String, sampleRate, opts.HARTags)
+		if err != nil {
+			return fmt.Errorf("unable to create the HAR output file: %w", err)
+		}
+		r.harRecorder = hr
+	}
+
 	// FIXME: Resolver probably shouldn't be reset here...
 	// It's done because the js.Runner is created before the full
 	// configuration has been processed, at which point we don't have
@@ -476,6 +576,29 @@ func (r *Runner) SetOptions(opts lib.Options) error {
 	return nil
 }
 
+// Close releases the resources of the runner which outlive its VUs, i.e. it
+// writes the rest of the recorded requests and closes the HAR file.
+func (r *Runner) Close() error {
+	if r.harRecorder == nil {
+		return nil
+	}
+	return r.harRecorder.Close()
+}
+
+// newCookieJar returns a new cookie jar for a VU, seeded with the cookies from the options.
+func (r *Runner) newCookieJar() (*lib.CookieJar, error) {
+	jar, err := lib.NewCookieJar()
+	if err != nil {
+		return nil, err
+	}
+	if cookies := r.Bundle.Options.Cookies; cookies.Valid {
+		if err = jar.Import(cookies.Cookies); err != nil {
+			return nil, fmt.Errorf("invalid cookies option: %w", err)
+		}
+	}
+	return jar, nil
+}
+
 func (r *Runner) setResolver(dns types.DNSConfig) error {
 	ttl, err := parseTTL(dns.TTL.String)
 	if err != nil {
@@ -573,6 +696,17 @@ func (r *Runner) runPart(
 //nolint:gochecknoglobals
 var gojaPromiseType = reflect.TypeOf((*goja.Promise)(nil))
 
+// isIterationSkipped returns whether the error is the interruption of the
+// runtime by iteration.skipRemaining(), which can't be caught by the script.
+func isIterationSkipped(err error) bool {
+	var interrupted *goja.InterruptedError
+	if !errors.As(err, &interrupted) {
+		return false
+	}
+	skipErr, ok := interrupted.Value().(error)
+	return ok && errors.Is(skipErr, lib.ErrIterationSkipped)
+}
+
 // unPromisify gets the result of v if it is a promise, otherwise returns v
 func unPromisify(v goja.Value) goja.Value {
 	if !common.IsNullish(v) {
@@ -605,14 +739,19 @@ func (r *Runner) getTimeoutFor(stage string) time.Duration {
 type VU struct {
 	BundleInstance
 
-	Runner    *Runner
-	Transport *http.Transport
-	Dialer    *netext.Dialer
-	CookieJar *cookiejar.Jar
-	TLSConfig *tls.Config
-	ID        uint64 // local to the current instance
-	IDGlobal  uint64 // global across all instances
-	iteration int64
+	Runner           *Runner
+	Transport        *http.Transport
+	TransportH2C     *httpext.H2CTransport
+	TransportH3      *httpext.HTTP3Transport
+	TransportReuse   *http.Transport
+	TransportNoReuse *http.Transport
+	ConnStats        *lib.ConnStats
+	Dialer           *netext.Dialer
+	CookieJar        *lib.CookieJar
+	TLSConfig        *tls.Config
+	ID               uint64 // local to the current instance
+	IDGlobal         uint64 // global across all instances
+	iteration        int64
 
 	Console    *console
 	BufferPool *lib.BufferPool
@@ -763,10 +902,26 @@ func (u *ActiveVU) RunOnce() error {
 		panic(fmt.Errorf("error setting __ITER in goja runtime: %w", err))
 	}
 
+	if !u.WarmupUntil.IsZero() {
+		inWarmup := time.Now().Before(u.WarmupUntil)
+		u.state.Tags.Modify(func(tagsAndMeta *metrics.TagsAndMeta) {
+			if inWarmup {
+				tagsAndMeta.SetTag(metrics.WarmupTagName, "true")
+			} else {
+				tagsAndMeta.DeleteTag(metrics.WarmupTagName)
+			}
+		})
+	}
+
 	ctx, cancel := context.WithCancel(u.RunContext)
 	defer cancel()
 	u.moduleVUImpl.ctx = ctx
 
+	var stopDeadline func() bool
+	if u.MaxIterationDuration > 0 {
+		stopDeadline = u.enforceMaxIterationDuration(u.MaxIterationDuration, cancel)
+	}
+
 	eventIterData := event.IterData{
 		Iteration:    u.iteration,
 		VUID:         u.ID,
@@ -786,6 +941,9 @@ func (u *ActiveVU) RunOnce() error {
 
 	// Call the exported function.
 	_, isFullIteration, totalTime, err := u.runFn(ctx, true, fn, cancel, u.setupData)
+	if stopDeadline != nil && stopDeadline() && !isFullIteration {
+		err = fmt.Errorf("%w of %s", lib.ErrMaxIterationDurationExceeded, u.MaxIterationDuration)
+	}
 	if err != nil {
 		var x *goja.InterruptedError
 		if errors.As(err, &x) {
@@ -814,6 +972,29 @@ func (u *ActiveVU) RunOnce() error {
 	return err
 }
 
+// enforceMaxIterationDuration interrupts the current iteration, including any
+// in-flight requests, if it runs for longer than maxDuration. The returned
+// function has to be called at the end of the iteration, it reports whether the
+// iteration was interrupted.
+func (u *ActiveVU) enforceMaxIterationDuration(maxDuration time.Duration, cancel func()) func() bool {
+	interrupted := make(chan struct{})
+	timer := time.AfterFunc(maxDuration, func() {
+		defer close(interrupted)
+		cancel()
+		u.Runtime.Interrupt(fmt.Errorf("%w of %s", lib.ErrMaxIterationDurationExceeded, maxDuration))
+	})
+	return func() bool {
+		if timer.Stop() {
+			return false
+		}
+		<-interrupted
+		// The runtime may not have noticed the interrupt, e.g. if the iteration
+		// was just finishing, and it shouldn't affect the next iteration.
+		u.Runtime.ClearInterrupt()
+		return true
+	}
+}
+
 func (u *VU) getExported(name string) goja.Value {
 	return u.BundleInstance.getExported(name)
 }
@@ -824,10 +1005,11 @@ func (u *VU) runFn(
 	ctx context.Context, isDefault bool, fn goja.Callable, cancel func(), args ...goja.Value,
 ) (v goja.Value, isFullIteration bool, t time.Duration, err error) {
 	if !u.Runner.Bundle.Options.NoCookiesReset.ValueOrZero() {
-		u.state.CookieJar, err = cookiejar.New(nil)
+		u.state.TrackedCookieJar, err = u.Runner.newCookieJar()
 		if err != nil {
 			return goja.Undefined(), false, time.Duration(0), err
 		}
+		u.state.CookieJar = u.state.TrackedCookieJar.Jar
 	}
 
 	opts := &u.Runner.Bundle.Options
@@ -849,6 +1031,12 @@ func (u *VU) runFn(
 			return err
 		})
 	})
+	if isIterationSkipped(err) {
+		// the script skipped the rest of the function, which isn't an error,
+		// and the runtime has to run the next functions
+		u.Runtime.ClearInterrupt()
+		v, err = goja.Undefined(), nil
+	}
 
 	select {
 	case <-ctx.Done():
@@ -868,7 +1056,10 @@ func (u *VU) runFn(
 	}
 
 	if u.Runner.Bundle.Options.NoVUConnectionReuse.Bool {
-		u.Transport.CloseIdleConnections()
+		u.TransportReuse.CloseIdleConnections()
+		u.TransportNoReuse.CloseIdleConnections()
+		u.TransportH2C.CloseIdleConnections()
+		u.TransportH3.CloseIdleConnections()
 	}
 
 	u.state.Samples <- u.Dialer.GetTrail(
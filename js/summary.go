@@ -32,12 +32,12 @@ func metricValueGetter(summaryTrendStats []string) func(metrics.Sink, time.Durat
 	return func(sink metrics.Sink, t time.Duration) (result map[string]float64) {
 		switch sink := sink.(type) {
 		case *metrics.CounterSink:
-			result = sink.Format(t)
+			// The recent rate isn't meaningful for the whole test run
 			rate := 0.0
 			if t > 0 {
 				rate = sink.Value / (float64(t) / float64(time.Second))
 			}
-			result["rate"] = rate
+			result = map[string]float64{"count": sink.Value, "rate": rate}
 		case *metrics.GaugeSink:
 			result = sink.Format(t)
 			result["min"] = sink.Min
@@ -46,6 +46,8 @@ func metricValueGetter(summaryTrendStats []string) func(metrics.Sink, time.Durat
 			result = sink.Format(t)
 			result["passes"] = float64(sink.Trues)
 			result["fails"] = float64(sink.Total - sink.Trues)
+		case *metrics.HistogramSink:
+			result = sink.Format(t)
 		case *metrics.TrendSink:
 			result = make(map[string]float64, len(summaryTrendStats))
 			for _, col := range summaryTrendStats {
@@ -76,13 +78,25 @@ func summarizeMetricsToObject(data *lib.Summary, options lib.Options, setupData
 
 	getMetricValues := metricValueGetter(options.SummaryTrendStats)
 
+	now, gaugeTTL := time.Now(), options.GaugeTTL.TimeDuration()
 	metricsData := make(map[string]interface{})
 	for name, m := range data.Metrics {
+		if sink, ok := m.Sink.(*metrics.GaugeSink); ok && sink.IsStale(now, gaugeTTL) {
+			// the gauge isn't reported as current,
+			// since it hasn't been updated for a while
+			continue
+		}
 		metricData := map[string]interface{}{
 			"type":     m.Type.String(),
 			"contains": m.Contains.String(),
 			"values":   getMetricValues(m.Sink, data.TestRunDuration),
 		}
+		if m.Unit != "" {
+			metricData["unit"] = m.Unit
+		}
+		if m.Description != "" {
+			metricData["description"] = m.Description
+		}
 
 		if len(m.Thresholds.Thresholds) > 0 {
 			thresholds := make(map[string]interface{})
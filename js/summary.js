@@ -213,7 +213,25 @@ function humanizeValue(val, metric, timeUnit) {
     case 'time':
       return humanizeDuration(val, timeUnit)
     default:
+      return humanizeValueWithUnit(val, metric.unit, timeUnit)
+  }
+}
+
+// humanizeValueWithUnit formats the values of the metrics
+// which have a unit but don't contain time or data.
+function humanizeValueWithUnit(val, unit, timeUnit) {
+  switch (unit) {
+    case undefined:
+    case '':
       return toFixedNoTrailingZeros(val, 6)
+    case 'bytes':
+      return humanizeBytes(val)
+    case 'ms':
+      return humanizeDuration(val, timeUnit)
+    case 'percent':
+      return toFixedNoTrailingZeros(val, 2) + '%'
+    default:
+      return toFixedNoTrailingZeros(val, 6) + ' ' + unit
   }
 }
 
@@ -236,6 +254,14 @@ function nonTrendMetricValueForSum(metric, timeUnit) {
         succMark + ' ' + metric.values.passes,
         failMark + ' ' + metric.values.fails,
       ]
+    case 'histogram':
+      return [
+        metric.values.count,
+        'avg=' + humanizeValue(metric.values.avg, metric, timeUnit),
+        'min=' + humanizeValue(metric.values.min, metric, timeUnit),
+        'med=' + humanizeValue(metric.values.med, metric, timeUnit),
+        'max=' + humanizeValue(metric.values.max, metric, timeUnit),
+      ]
     default:
       return ['[no data]']
   }
@@ -15,6 +15,7 @@ import (
 
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 )
 
@@ -75,6 +76,56 @@ func TestTextSummary(t *testing.T) {
 	}
 }
 
+func TestTextSummaryWithUnits(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	sizeMetric, err := registry.NewMetricWithInfo("queue_size", metrics.Gauge, metrics.UnitBytes, "The queue size")
+	require.NoError(t, err)
+	sizeMetric.Sink.Add(metrics.Sample{Value: 2048})
+
+	usageMetric, err := registry.NewMetricWithInfo("usage", metrics.Gauge, metrics.UnitPercent, "")
+	require.NoError(t, err)
+	usageMetric.Sink.Add(metrics.Sample{Value: 42.5})
+
+	itemsMetric, err := registry.NewMetricWithInfo("items", metrics.Counter, "items", "")
+	require.NoError(t, err)
+	itemsMetric.Sink.Add(metrics.Sample{Value: 3})
+
+	summary := &lib.Summary{
+		Metrics: map[string]*metrics.Metric{
+			sizeMetric.Name:  sizeMetric,
+			usageMetric.Name: usageMetric,
+			itemsMetric.Name: itemsMetric,
+		},
+		RootGroup:       &lib.Group{},
+		TestRunDuration: time.Second,
+	}
+
+	runner, err := getSimpleRunner(
+		t,
+		"/script.js",
+		"exports.default = function() {/* we don't run this, metrics are mocked */};",
+		lib.RuntimeOptions{CompatibilityMode: null.NewString("base", true)},
+	)
+	require.NoError(t, err)
+
+	result, err := runner.HandleSummary(context.Background(), summary)
+	require.NoError(t, err)
+
+	require.Len(t, result, 1)
+	stdout := result["stdout"]
+	require.NotNil(t, stdout)
+
+	summaryOut, err := io.ReadAll(stdout)
+	require.NoError(t, err)
+
+	expected := "     items........: 3 items 3 items/s\n" +
+		"     queue_size...: 2.0 kB  min=2.0 kB max=2.0 kB\n" +
+		"     usage........: 42.5%   min=42.5%  max=42.5% \n"
+	assert.Equal(t, "\n"+expected+"\n", string(summaryOut))
+}
+
 func TestTextSummaryWithSubMetrics(t *testing.T) {
 	t.Parallel()
 
@@ -700,3 +751,21 @@ func TestExceptionInHandleSummaryFallsBackToTextSummary(t *testing.T) {
 	require.NoError(t, err)
 	assert.Contains(t, errMsg, "intentional error")
 }
+
+func TestSummarizeMetricsToObjectStaleGauges(t *testing.T) {
+	t.Parallel()
+
+	summary := createTestSummary(t)
+	staleGauge, err := metrics.NewRegistry().NewMetric("stale_gauge", metrics.Gauge)
+	require.NoError(t, err)
+	staleGauge.Sink.Add(metrics.Sample{Value: 1, Time: time.Now().Add(-time.Hour)})
+	summary.Metrics["stale_gauge"] = staleGauge
+
+	obj := summarizeMetricsToObject(summary, lib.Options{}, nil)
+	assert.Contains(t, obj["metrics"], "stale_gauge")
+
+	obj = summarizeMetricsToObject(summary, lib.Options{GaugeTTL: types.NullDurationFrom(time.Minute)}, nil)
+	assert.NotContains(t, obj["metrics"], "stale_gauge")
+	// the gauges without a time are still reported
+	assert.Contains(t, obj["metrics"], "vus")
+}
@@ -0,0 +1,151 @@
+package lib
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"go.k6.io/k6/metrics"
+)
+
+// ConnStats tracks the open connections of all the VUs of the test per address,
+// and how many of the requests to each address are in progress, to know which
+// connections are idle.
+type ConnStats struct {
+	mx     sync.Mutex
+	open   map[string]int64
+	active map[string]int64
+
+	// the addresses whose gauges were emitted by the last GetSamples() call,
+	// they are emitted once more with zero values after their connections
+	// are closed, and the totals are emitted once there were connections
+	emitted     map[string]struct{}
+	emittedOnce bool
+}
+
+// NewConnStats returns a new ConnStats without any connections.
+func NewConnStats() *ConnStats {
+	return &ConnStats{
+		open:    make(map[string]int64),
+		active:  make(map[string]int64),
+		emitted: make(map[string]struct{}),
+	}
+}
+
+// TrackDialContext returns a dial function that calls the provided one,
+// and tracks the connections that it opens until they are closed.
+func (s *ConnStats) TrackDialContext(
+	dialContext func(ctx context.Context, network, addr string) (net.Conn, error),
+) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		s.add(s.open, addr, 1)
+		return &trackedConn{Conn: conn, close: func() { s.add(s.open, addr, -1) }}, nil
+	}
+}
+
+// Acquire marks the start of a request to the address.
+func (s *ConnStats) Acquire(addr string) {
+	s.add(s.active, addr, 1)
+}
+
+// Release marks the end of a request to the address.
+func (s *ConnStats) Release(addr string) {
+	s.add(s.active, addr, -1)
+}
+
+// Get returns the number of the open and the idle connections to the address.
+// The idle connections are estimated by the number of the requests in progress,
+// since there could be more of them than the connections with HTTP/2.
+func (s *ConnStats) Get(addr string) (open, idle int64) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.get(addr)
+}
+
+func (s *ConnStats) get(addr string) (open, idle int64) {
+	open = s.open[addr]
+	if idle = open - s.active[addr]; idle < 0 {
+		idle = 0
+	}
+	return open, idle
+}
+
+// GetSamples returns the samples of the http_conns_open and http_conns_idle
+// gauges for the current connections. They are the totals for all the
+// addresses, unless withHost is enabled, when there are samples for each of
+// the addresses, tagged with it. Nothing is returned before the first connection.
+func (s *ConnStats) GetSamples(
+	builtinMetrics *metrics.BuiltinMetrics, tags *metrics.TagSet, withHost bool, t time.Time,
+) []metrics.Sample {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	newSamples := func(tags *metrics.TagSet, open, idle int64) []metrics.Sample {
+		return []metrics.Sample{
+			{
+				TimeSeries: metrics.TimeSeries{Metric: builtinMetrics.HTTPConnsOpen, Tags: tags},
+				Time:       t,
+				Value:      float64(open),
+			},
+			{
+				TimeSeries: metrics.TimeSeries{Metric: builtinMetrics.HTTPConnsIdle, Tags: tags},
+				Time:       t,
+				Value:      float64(idle),
+			},
+		}
+	}
+
+	if !withHost {
+		var open, idle int64
+		for addr := range s.open {
+			addrOpen, addrIdle := s.get(addr)
+			open += addrOpen
+			idle += addrIdle
+		}
+		if open == 0 && !s.emittedOnce {
+			return nil
+		}
+		s.emittedOnce = true
+		return newSamples(tags, open, idle)
+	}
+
+	samples := make([]metrics.Sample, 0, 2*(len(s.open)+len(s.emitted)))
+	for addr := range s.open {
+		open, idle := s.get(addr)
+		samples = append(samples, newSamples(tags.With(metrics.TagHost.String(), addr), open, idle)...)
+		s.emitted[addr] = struct{}{}
+	}
+	for addr := range s.emitted {
+		if _, ok := s.open[addr]; !ok {
+			samples = append(samples, newSamples(tags.With(metrics.TagHost.String(), addr), 0, 0)...)
+			delete(s.emitted, addr)
+		}
+	}
+	return samples
+}
+
+func (s *ConnStats) add(counts map[string]int64, addr string, delta int64) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	counts[addr] += delta
+	if counts[addr] <= 0 {
+		delete(counts, addr)
+	}
+}
+
+// trackedConn calls the close function once, when the connection is closed.
+type trackedConn struct {
+	net.Conn
+	closeOnce sync.Once
+	close     func()
+}
+
+func (c *trackedConn) Close() error {
+	c.closeOnce.Do(c.close)
+	return c.Conn.Close()
+}
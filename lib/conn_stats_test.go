@@ -0,0 +1,124 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/metrics"
+)
+
+func TestConnStats(t *testing.T) {
+	t.Parallel()
+
+	stats := NewConnStats()
+	dial := stats.TrackDialContext(func(_ context.Context, _, addr string) (net.Conn, error) {
+		if addr == "fail:80" {
+			return nil, errors.New("dial error")
+		}
+		conn, _ := net.Pipe()
+		return conn, nil
+	})
+
+	conn1, err := dial(context.Background(), "tcp", "example.com:80")
+	require.NoError(t, err)
+	conn2, err := dial(context.Background(), "tcp", "example.com:80")
+	require.NoError(t, err)
+	_, err = dial(context.Background(), "tcp", "fail:80")
+	require.Error(t, err)
+
+	stats.Acquire("example.com:80")
+	open, idle := stats.Get("example.com:80")
+	assert.Equal(t, int64(2), open)
+	assert.Equal(t, int64(1), idle)
+	open, idle = stats.Get("fail:80")
+	assert.Zero(t, open)
+	assert.Zero(t, idle)
+
+	// more requests than connections, e.g. with HTTP/2
+	stats.Acquire("example.com:80")
+	stats.Acquire("example.com:80")
+	_, idle = stats.Get("example.com:80")
+	assert.Zero(t, idle)
+
+	stats.Release("example.com:80")
+	stats.Release("example.com:80")
+	stats.Release("example.com:80")
+	require.NoError(t, conn1.Close())
+	require.NoError(t, conn1.Close())
+	open, idle = stats.Get("example.com:80")
+	assert.Equal(t, int64(1), open)
+	assert.Equal(t, int64(1), idle)
+
+	require.NoError(t, conn2.Close())
+	open, idle = stats.Get("example.com:80")
+	assert.Zero(t, open)
+	assert.Zero(t, idle)
+}
+
+func TestConnStatsGetSamples(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+	tags := registry.RootTagSet().With("testid", "123")
+	getValues := func(samples []metrics.Sample) map[string][2]float64 {
+		values := make(map[string][2]float64)
+		for _, s := range samples {
+			host, _ := s.Tags.Get("host")
+			v := values[host]
+			switch s.Metric {
+			case builtinMetrics.HTTPConnsOpen:
+				v[0] = s.Value
+			case builtinMetrics.HTTPConnsIdle:
+				v[1] = s.Value
+			}
+			values[host] = v
+		}
+		return values
+	}
+
+	stats := NewConnStats()
+	assert.Empty(t, stats.GetSamples(builtinMetrics, tags, false, time.Now()))
+	assert.Empty(t, stats.GetSamples(builtinMetrics, tags, true, time.Now()))
+
+	dial := stats.TrackDialContext(func(context.Context, string, string) (net.Conn, error) {
+		conn, _ := net.Pipe()
+		return conn, nil
+	})
+	// the connections of different VUs to the same and to different hosts
+	conn1, err := dial(context.Background(), "tcp", "example.com:80")
+	require.NoError(t, err)
+	_, err = dial(context.Background(), "tcp", "example.com:80")
+	require.NoError(t, err)
+	conn3, err := dial(context.Background(), "tcp", "example.net:443")
+	require.NoError(t, err)
+	stats.Acquire("example.com:80")
+
+	assert.Equal(t, map[string][2]float64{"": {3, 2}}, getValues(stats.GetSamples(builtinMetrics, tags, false, time.Now())))
+	assert.Equal(t, map[string][2]float64{
+		"example.com:80":  {2, 1},
+		"example.net:443": {1, 1},
+	}, getValues(stats.GetSamples(builtinMetrics, tags, true, time.Now())))
+
+	// the gauges of the closed connections drop to zero, once
+	require.NoError(t, conn1.Close())
+	require.NoError(t, conn3.Close())
+	assert.Equal(t, map[string][2]float64{
+		"example.com:80":  {1, 0},
+		"example.net:443": {0, 0},
+	}, getValues(stats.GetSamples(builtinMetrics, tags, true, time.Now())))
+	assert.Equal(t, map[string][2]float64{
+		"example.com:80": {1, 0},
+	}, getValues(stats.GetSamples(builtinMetrics, tags, true, time.Now())))
+
+	for _, s := range stats.GetSamples(builtinMetrics, tags, false, time.Now()) {
+		testID, _ := s.Tags.Get("testid")
+		assert.Equal(t, "123", testID)
+	}
+}
@@ -0,0 +1,331 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CookieJar wraps a cookiejar.Jar and keeps track of the cookies that are
+// stored in it, since the standard library doesn't allow listing them, so
+// they can be exported and imported.
+type CookieJar struct {
+	*cookiejar.Jar
+
+	mx      sync.Mutex
+	cookies map[cookieID]*http.Cookie
+}
+
+type cookieID struct {
+	domain, path, name string
+}
+
+// NewCookieJar returns a new empty CookieJar.
+func NewCookieJar() (*CookieJar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &CookieJar{Jar: jar, cookies: make(map[cookieID]*http.Cookie)}, nil
+}
+
+// ActiveCookieJar returns the tracked jar if it wraps the provided one, so the
+// cookies that are set through it are tracked, or the provided jar otherwise.
+func ActiveCookieJar(jar *cookiejar.Jar, tracked *CookieJar) http.CookieJar {
+	switch {
+	case tracked != nil && tracked.Jar == jar:
+		return tracked
+	case jar != nil:
+		return jar
+	default:
+		return nil
+	}
+}
+
+// SetCookies implements the http.CookieJar interface.
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.Jar.SetCookies(u, cookies)
+
+	now := time.Now()
+	j.mx.Lock()
+	defer j.mx.Unlock()
+	for _, c := range cookies {
+		stored := &http.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   strings.ToLower(u.Hostname()),
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+		}
+		// Domain cookies are kept with a leading dot, to distinguish them
+		// from the host-only ones, the way browsers show them.
+		if c.Domain != "" {
+			stored.Domain = "." + strings.TrimPrefix(strings.ToLower(c.Domain), ".")
+		}
+		if stored.Path == "" || stored.Path[0] != '/' {
+			stored.Path = defaultCookiePath(u.Path)
+		}
+		id := cookieID{strings.TrimPrefix(stored.Domain, "."), stored.Path, c.Name}
+
+		switch {
+		case c.MaxAge < 0:
+			delete(j.cookies, id)
+			continue
+		case c.MaxAge > 0:
+			stored.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		case !c.Expires.IsZero():
+			if !c.Expires.After(now) {
+				delete(j.cookies, id)
+				continue
+			}
+			stored.Expires = c.Expires
+		}
+		j.cookies[id] = stored
+	}
+}
+
+// Export returns all the unexpired cookies in the jar.
+func (j *CookieJar) Export() []ExportedCookie {
+	j.mx.Lock()
+	defer j.mx.Unlock()
+
+	now := time.Now()
+	result := make([]ExportedCookie, 0, len(j.cookies))
+	for id, c := range j.cookies {
+		// The jar may have rejected or evicted the cookie, so only the ones
+		// that it would still send are exported.
+		if (!c.Expires.IsZero() && !c.Expires.After(now)) || !j.has(id, c) {
+			delete(j.cookies, id)
+			continue
+		}
+		ec := ExportedCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			HTTPOnly: c.HttpOnly,
+			Secure:   c.Secure,
+		}
+		if !c.Expires.IsZero() {
+			ec.Expires = c.Expires.UTC().Format(time.RFC3339)
+		}
+		result = append(result, ec)
+	}
+	sort.Slice(result, func(a, b int) bool {
+		if result[a].Domain != result[b].Domain {
+			return result[a].Domain < result[b].Domain
+		}
+		if result[a].Path != result[b].Path {
+			return result[a].Path < result[b].Path
+		}
+		return result[a].Name < result[b].Name
+	})
+	return result
+}
+
+func (j *CookieJar) has(id cookieID, c *http.Cookie) bool {
+	u := &url.URL{Scheme: "https", Host: id.domain, Path: id.path}
+	for _, jc := range j.Jar.Cookies(u) {
+		if jc.Name == c.Name && jc.Value == c.Value {
+			return true
+		}
+	}
+	return false
+}
+
+// Import adds the provided cookies to the jar, replacing any existing ones
+// with the same domain, path and name.
+func (j *CookieJar) Import(cookies []ExportedCookie) error {
+	for _, ec := range cookies {
+		c, u, err := ec.toCookie()
+		if err != nil {
+			return err
+		}
+		j.SetCookies(u, []*http.Cookie{c})
+	}
+	return nil
+}
+
+// defaultCookiePath returns the directory part of an URL's path, as
+// specified in RFC 6265 section 5.1.4, which is what cookiejar.Jar uses.
+func defaultCookiePath(path string) string {
+	if path == "" || path[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(path, "/")
+	if i == 0 {
+		return "/"
+	}
+	return path[:i]
+}
+
+// ExportedCookie is the serializable form of a cookie in a CookieJar. It has
+// the same fields as the cookie objects in HAR files. The domain of domain
+// cookies starts with a dot, while the host-only cookies have the bare host.
+type ExportedCookie struct {
+	Name     string `json:"name" js:"name"`
+	Value    string `json:"value" js:"value"`
+	Domain   string `json:"domain" js:"domain"`
+	Path     string `json:"path" js:"path"`
+	Expires  string `json:"expires,omitempty" js:"expires"`
+	HTTPOnly bool   `json:"httpOnly" js:"httpOnly"`
+	Secure   bool   `json:"secure" js:"secure"`
+}
+
+// toCookie returns the cookie and the URL that it should be set for.
+func (ec ExportedCookie) toCookie() (*http.Cookie, *url.URL, error) {
+	if ec.Name == "" {
+		return nil, nil, errors.New("the cookie name is required")
+	}
+	domain := strings.TrimPrefix(ec.Domain, ".")
+	if domain == "" {
+		return nil, nil, fmt.Errorf("the domain of the cookie '%s' is required", ec.Name)
+	}
+	c := &http.Cookie{
+		Name:     ec.Name,
+		Value:    ec.Value,
+		Path:     ec.Path,
+		Secure:   ec.Secure,
+		HttpOnly: ec.HTTPOnly,
+	}
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	if strings.HasPrefix(ec.Domain, ".") {
+		c.Domain = domain
+	}
+	if ec.Expires != "" {
+		expires, err := time.Parse(time.RFC3339, ec.Expires)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to parse the expiration date of the cookie '%s': %w", ec.Name, err)
+		}
+		c.Expires = expires
+	}
+	scheme := "http"
+	if c.Secure {
+		scheme = "https"
+	}
+	return c, &url.URL{Scheme: scheme, Host: domain, Path: c.Path}, nil
+}
+
+// ParseCookies parses cookies either from a JSON array of cookie objects, like
+// the ones returned by CookieJar.Export(), or from a HAR file, in which case
+// the cookies of all requests and responses are used in order.
+func ParseCookies(data []byte) ([]ExportedCookie, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var cookies []ExportedCookie
+		if err := json.Unmarshal(data, &cookies); err != nil {
+			return nil, fmt.Errorf("unable to parse the cookies: %w", err)
+		}
+		return cookies, validateCookies(cookies)
+	}
+
+	var har struct {
+		Log *struct {
+			Entries []struct {
+				Request struct {
+					URL     string           `json:"url"`
+					Cookies []ExportedCookie `json:"cookies"`
+				} `json:"request"`
+				Response struct {
+					Cookies []ExportedCookie `json:"cookies"`
+				} `json:"response"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("unable to parse the cookies: %w", err)
+	}
+	if har.Log == nil {
+		return nil, errors.New("the cookies should be either an array or a HAR object")
+	}
+
+	var cookies []ExportedCookie
+	for _, entry := range har.Log.Entries {
+		u, err := url.Parse(entry.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HAR request URL '%s': %w", entry.Request.URL, err)
+		}
+		// The HAR cookies often lack the domain and path, so the ones the
+		// browser would have used for the request are filled in.
+		for _, c := range append(entry.Request.Cookies, entry.Response.Cookies...) {
+			if c.Domain == "" {
+				c.Domain = strings.ToLower(u.Hostname())
+			}
+			if c.Path == "" {
+				c.Path = defaultCookiePath(u.Path)
+			}
+			cookies = append(cookies, c)
+		}
+	}
+	return cookies, validateCookies(cookies)
+}
+
+func validateCookies(cookies []ExportedCookie) error {
+	for _, c := range cookies {
+		if _, _, err := c.toCookie(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NullCookies is a list of cookies that can be set in the options, either as
+// an array of cookies, a HAR object, or a string with either of them in JSON.
+type NullCookies struct {
+	Cookies []ExportedCookie
+	Valid   bool
+}
+
+// UnmarshalJSON parses the cookies with ParseCookies.
+func (c *NullCookies) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*c = NullCookies{}
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		data = []byte(s)
+	}
+	cookies, err := ParseCookies(data)
+	if err != nil {
+		return err
+	}
+	*c = NullCookies{Cookies: cookies, Valid: true}
+	return nil
+}
+
+// MarshalJSON returns the cookies as a JSON array, or null.
+func (c NullCookies) MarshalJSON() ([]byte, error) {
+	if !c.Valid {
+		return []byte("null"), nil
+	}
+	if c.Cookies == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(c.Cookies)
+}
+
+// Decode parses the cookies from an environment variable.
+func (c *NullCookies) Decode(value string) error {
+	cookies, err := ParseCookies([]byte(value))
+	if err != nil {
+		return err
+	}
+	*c = NullCookies{Cookies: cookies, Valid: true}
+	return nil
+}
@@ -0,0 +1,110 @@
+package lib
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCookieJarExportImport(t *testing.T) {
+	t.Parallel()
+
+	jar, err := NewCookieJar()
+	require.NoError(t, err)
+
+	u, err := url.Parse("https://www.example.com/app/login")
+	require.NoError(t, err)
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "host", Value: "1"},
+		{Name: "domain", Value: "2", Domain: "example.com", Path: "/", Secure: true, HttpOnly: true},
+		{Name: "persistent", Value: "3", Expires: expires},
+		{Name: "expired", Value: "4", Expires: time.Now().Add(-time.Hour)},
+		{Name: "rejected", Value: "5", Domain: "example.net"},
+	})
+	jar.SetCookies(u, []*http.Cookie{{Name: "deleted", Value: "6"}})
+	jar.SetCookies(u, []*http.Cookie{{Name: "deleted", MaxAge: -1}})
+
+	exported := jar.Export()
+	assert.Equal(t, []ExportedCookie{
+		{Name: "domain", Value: "2", Domain: ".example.com", Path: "/", HTTPOnly: true, Secure: true},
+		{Name: "host", Value: "1", Domain: "www.example.com", Path: "/app"},
+		{Name: "persistent", Value: "3", Domain: "www.example.com", Path: "/app", Expires: expires.Format(time.RFC3339)},
+	}, exported)
+
+	imported, err := NewCookieJar()
+	require.NoError(t, err)
+	require.NoError(t, imported.Import(exported))
+	assert.Equal(t, exported, imported.Export())
+
+	sub, err := url.Parse("https://api.example.com/")
+	require.NoError(t, err)
+	cookies := imported.Cookies(sub)
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "domain", cookies[0].Name)
+
+	err = imported.Import([]ExportedCookie{{Name: "nodomain"}})
+	require.EqualError(t, err, "the domain of the cookie 'nodomain' is required")
+}
+
+func TestParseCookies(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Array", func(t *testing.T) {
+		t.Parallel()
+		cookies, err := ParseCookies([]byte(`[{"name":"a","value":"1","domain":"example.com","path":"/"}]`))
+		require.NoError(t, err)
+		assert.Equal(t, []ExportedCookie{{Name: "a", Value: "1", Domain: "example.com", Path: "/"}}, cookies)
+	})
+
+	t.Run("HAR", func(t *testing.T) {
+		t.Parallel()
+		cookies, err := ParseCookies([]byte(`{"log":{"version":"1.2","entries":[{
+			"request":{"url":"https://example.com/app/login","cookies":[{"name":"a","value":"1"}]},
+			"response":{"cookies":[
+				{"name":"b","value":"2","domain":".example.com","path":"/","expires":"2030-01-02T03:04:05.000Z","secure":true}
+			]}
+		}]}}`))
+		require.NoError(t, err)
+		assert.Equal(t, []ExportedCookie{
+			{Name: "a", Value: "1", Domain: "example.com", Path: "/app"},
+			{Name: "b", Value: "2", Domain: ".example.com", Path: "/", Expires: "2030-01-02T03:04:05.000Z", Secure: true},
+		}, cookies)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		_, err := ParseCookies([]byte(`{"name":"a"}`))
+		require.EqualError(t, err, "the cookies should be either an array or a HAR object")
+		_, err = ParseCookies([]byte(`[{"name":"a","domain":"example.com","expires":"tomorrow"}]`))
+		require.ErrorContains(t, err, "unable to parse the expiration date of the cookie 'a'")
+		_, err = ParseCookies([]byte(`[{"value":"1","domain":"example.com"}]`))
+		require.EqualError(t, err, "the cookie name is required")
+	})
+}
+
+func TestNullCookiesJSON(t *testing.T) {
+	t.Parallel()
+
+	var c NullCookies
+	require.NoError(t, json.Unmarshal([]byte(`"[{\"name\":\"a\",\"value\":\"1\",\"domain\":\"example.com\"}]"`), &c))
+	assert.Equal(t, NullCookies{Cookies: []ExportedCookie{{Name: "a", Value: "1", Domain: "example.com"}}, Valid: true}, c)
+
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"name":"a","value":"1","domain":"example.com","path":"","httpOnly":false,"secure":false}]`, string(data))
+
+	require.NoError(t, json.Unmarshal([]byte(`null`), &c))
+	assert.False(t, c.Valid)
+	data, err = json.Marshal(c)
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(data))
+
+	require.NoError(t, c.Decode(`[{"name":"b","domain":"example.com"}]`))
+	assert.Equal(t, NullCookies{Cookies: []ExportedCookie{{Name: "b", Domain: "example.com"}}, Valid: true}, c)
+}
@@ -141,6 +141,11 @@ type ExecutionState struct {
 	// simplification of the used atomic arithmetic operations.
 	activeVUs *int64
 
+	// The number of currently active VUs, partitioned by the name of the
+	// scenario they are executing. The values are *int64 counters that are
+	// lazily created the first time a scenario activates a VU.
+	scenarioActiveVUs *sync.Map
+
 	// The total number of full (i.e uninterrupted) iterations that have been
 	// completed so far.
 	fullIterationsCount *uint64
@@ -196,6 +201,12 @@ type ExecutionState struct {
 	pauseStateLock      sync.RWMutex
 	totalPausedDuration time.Duration // only modified behind the lock
 	resumeNotify        chan struct{}
+
+	// The distinct reasons the script gave for marking the test run as failed,
+	// with test.fail() in k6/execution, in the order they were first given.
+	// The test run isn't stopped, but k6 exits with an error at its end.
+	failureReasonsMx sync.Mutex
+	failureReasons   []string
 }
 
 // NewExecutionState initializes all of the pointers in the ExecutionState
@@ -222,6 +233,7 @@ func NewExecutionState(
 		initializedVUs:             new(int64),
 		uninitializedUnplannedVUs:  &maxUnplannedUninitializedVUs,
 		activeVUs:                  new(int64),
+		scenarioActiveVUs:          new(sync.Map),
 		fullIterationsCount:        new(uint64),
 		interruptedIterationsCount: new(uint64),
 		startTime:                  new(int64),
@@ -277,6 +289,30 @@ func (es *ExecutionState) ModCurrentlyActiveVUsCount(mod int64) int64 {
 	return atomic.AddInt64(es.activeVUs, mod)
 }
 
+// GetScenarioActiveVUsCounts returns the number of VUs that are currently
+// executing the test script, partitioned by scenario name. Scenarios that have
+// never had an active VU are not included.
+//
+// IMPORTANT: for UI/information purposes only, don't use for synchronization.
+func (es *ExecutionState) GetScenarioActiveVUsCounts() map[string]int64 {
+	counts := make(map[string]int64)
+	es.scenarioActiveVUs.Range(func(key, value interface{}) bool {
+		counts[key.(string)] = atomic.LoadInt64(value.(*int64)) //nolint:forcetypeassert
+		return true
+	})
+	return counts
+}
+
+// ModScenarioActiveVUsCount changes the number of currently active VUs for the
+// given scenario. It doesn't modify the total active VUs count, that is still
+// done via ModCurrentlyActiveVUsCount().
+//
+// IMPORTANT: for UI/information purposes only, don't use for synchronization.
+func (es *ExecutionState) ModScenarioActiveVUsCount(scenario string, mod int64) int64 {
+	counter, _ := es.scenarioActiveVUs.LoadOrStore(scenario, new(int64))
+	return atomic.AddInt64(counter.(*int64), mod) //nolint:forcetypeassert
+}
+
 // GetFullIterationCount returns the total of full (i.e uninterrupted) iterations
 // that have been completed so far.
 //
@@ -309,6 +345,27 @@ func (es *ExecutionState) AddInterruptedIterations(count uint64) uint64 {
 	return atomic.AddUint64(es.interruptedIterationsCount, count)
 }
 
+// MarkFailed marks the test run as failed for the provided reason, without
+// stopping it.
+func (es *ExecutionState) MarkFailed(reason string) {
+	es.failureReasonsMx.Lock()
+	defer es.failureReasonsMx.Unlock()
+	for _, r := range es.failureReasons {
+		if r == reason {
+			return
+		}
+	}
+	es.failureReasons = append(es.failureReasons, reason)
+}
+
+// GetFailureReasons returns the reasons the test run was marked as failed
+// for, or nil if it wasn't.
+func (es *ExecutionState) GetFailureReasons() []string {
+	es.failureReasonsMx.Lock()
+	defer es.failureReasonsMx.Unlock()
+	return append([]string(nil), es.failureReasons...)
+}
+
 // SetExecutionStatus changes the current execution status to the supplied value
 // and returns the current value.
 func (es *ExecutionState) SetExecutionStatus(newStatus ExecutionStatus) (oldStatus ExecutionStatus) {
@@ -24,14 +24,18 @@ const executorNameErr = "the executor name should contain only numbers, latin le
 
 // BaseConfig contains the common config fields for all executors
 type BaseConfig struct {
-	Name         string               `json:"-"` // set via the JS object key
-	Type         string               `json:"executor"`
-	StartTime    types.NullDuration   `json:"startTime"`
-	GracefulStop types.NullDuration   `json:"gracefulStop"`
-	Env          map[string]string    `json:"env"`
-	Exec         null.String          `json:"exec"` // function name, externally validated
-	Tags         map[string]string    `json:"tags"`
-	Options      *lib.ScenarioOptions `json:"options,omitempty"`
+	Name                 string               `json:"-"` // set via the JS object key
+	Type                 string               `json:"executor"`
+	StartTime            types.NullDuration   `json:"startTime"`
+	StartAfter           []string             `json:"startAfter"`
+	Dormant              null.Bool            `json:"dormant"`
+	GracefulStop         types.NullDuration   `json:"gracefulStop"`
+	MaxIterationDuration types.NullDuration   `json:"maxIterationDuration"`
+	WarmupDuration       types.NullDuration   `json:"warmupDuration"`
+	Env                  map[string]string    `json:"env"`
+	Exec                 null.String          `json:"exec"` // function name, externally validated
+	Tags                 map[string]string    `json:"tags"`
+	Options              *lib.ScenarioOptions `json:"options,omitempty"`
 
 	// TODO: future extensions like distribution, others?
 }
@@ -68,6 +72,27 @@ func (bc BaseConfig) Validate() (errors []error) {
 	if bc.GracefulStop.Duration < 0 {
 		errors = append(errors, fmt.Errorf("the gracefulStop timeout can't be negative"))
 	}
+	if bc.MaxIterationDuration.Valid && bc.MaxIterationDuration.Duration <= 0 {
+		errors = append(errors, fmt.Errorf("the maxIterationDuration must be more than 0"))
+	}
+	if bc.WarmupDuration.Duration < 0 {
+		errors = append(errors, fmt.Errorf("the warmupDuration can't be negative"))
+	}
+	startAfter := make(map[string]struct{}, len(bc.StartAfter))
+	for _, name := range bc.StartAfter {
+		switch _, duplicate := startAfter[name]; {
+		case name == "":
+			errors = append(errors, fmt.Errorf("the startAfter scenario names can't be empty"))
+		case name == bc.Name:
+			errors = append(errors, fmt.Errorf("the scenario can't start after itself"))
+		case duplicate:
+			errors = append(errors, fmt.Errorf("the scenario '%s' is specified more than once in startAfter", name))
+		}
+		startAfter[name] = struct{}{}
+	}
+	if bc.Dormant.Bool && len(bc.StartAfter) > 0 {
+		errors = append(errors, fmt.Errorf("a dormant scenario can't have startAfter, it's started manually"))
+	}
 	return errors
 }
 
@@ -87,6 +112,19 @@ func (bc BaseConfig) GetStartTime() time.Duration {
 	return bc.StartTime.TimeDuration()
 }
 
+// GetStartAfter returns the names of the scenarios that have to finish before
+// this executor starts. Its startTime is then relative to their end.
+func (bc BaseConfig) GetStartAfter() []string {
+	return bc.StartAfter
+}
+
+// IsDormant returns whether the executor waits to be started manually, e.g.
+// via the REST API, instead of starting on its own. Its startTime is then
+// relative to that moment.
+func (bc BaseConfig) IsDormant() bool {
+	return bc.Dormant.Bool
+}
+
 // GetGracefulStop returns how long k6 is supposed to wait for any still
 // running iterations to finish executing at the end of the normal executor
 // duration, before it actually kills them.
@@ -97,6 +135,19 @@ func (bc BaseConfig) GetGracefulStop() time.Duration {
 	return bc.GracefulStop.TimeDuration()
 }
 
+// GetMaxIterationDuration returns how long a single iteration of the executor
+// can run before it's interrupted, or 0 if there is no such limit.
+func (bc BaseConfig) GetMaxIterationDuration() time.Duration {
+	return bc.MaxIterationDuration.TimeDuration()
+}
+
+// GetWarmupDuration returns how long, since the start of the executor, the
+// metric samples of its iterations are marked as warm-up ones, so they don't
+// affect the thresholds and the end-of-test summary.
+func (bc BaseConfig) GetWarmupDuration() time.Duration {
+	return bc.WarmupDuration.TimeDuration()
+}
+
 // GetEnv returns any specific environment key=value pairs that
 // are configured for the executor.
 func (bc BaseConfig) GetEnv() map[string]string {
@@ -133,12 +184,24 @@ func (bc BaseConfig) getBaseInfo(facts ...string) string {
 	if bc.Exec.Valid {
 		facts = append(facts, fmt.Sprintf("exec: %s", bc.Exec.String))
 	}
+	if bc.Dormant.Bool {
+		facts = append(facts, "dormant")
+	}
+	if len(bc.StartAfter) > 0 {
+		facts = append(facts, fmt.Sprintf("startAfter: %s", strings.Join(bc.StartAfter, ", ")))
+	}
 	if bc.StartTime.Duration > 0 {
 		facts = append(facts, fmt.Sprintf("startTime: %s", bc.StartTime.Duration))
 	}
 	if bc.GracefulStop.Duration > 0 {
 		facts = append(facts, fmt.Sprintf("gracefulStop: %s", bc.GracefulStop.Duration))
 	}
+	if bc.MaxIterationDuration.Duration > 0 {
+		facts = append(facts, fmt.Sprintf("maxIterationDuration: %s", bc.MaxIterationDuration.Duration))
+	}
+	if bc.WarmupDuration.Duration > 0 {
+		facts = append(facts, fmt.Sprintf("warmupDuration: %s", bc.WarmupDuration.Duration))
+	}
 	if len(facts) == 0 {
 		return ""
 	}
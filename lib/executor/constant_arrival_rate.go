@@ -2,9 +2,11 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/big"
+	"reflect"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -150,6 +152,8 @@ func (carc ConstantArrivalRateConfig) NewExecutor(
 	return &ConstantArrivalRate{
 		BaseExecutor: NewBaseExecutor(&carc, es, logger),
 		config:       carc,
+		configLock:   &sync.RWMutex{},
+		rateUpdates:  make(chan updateRateEvent),
 	}, nil
 }
 
@@ -162,12 +166,70 @@ func (carc ConstantArrivalRateConfig) HasWork(et *lib.ExecutionTuple) bool {
 // specific period.
 type ConstantArrivalRate struct {
 	*BaseExecutor
-	config ConstantArrivalRateConfig
-	et     *lib.ExecutionTuple
+	config      ConstantArrivalRateConfig
+	configLock  *sync.RWMutex // guards config, which is changed by UpdateConfig
+	et          *lib.ExecutionTuple
+	rateUpdates chan updateRateEvent
 }
 
-// Make sure we implement the lib.Executor interface.
-var _ lib.Executor = &ConstantArrivalRate{}
+type updateRateEvent struct {
+	rate int64
+	err  chan error
+}
+
+// Make sure we implement the lib.Executor and lib.LiveUpdatableExecutor interfaces.
+var (
+	_ lib.Executor              = &ConstantArrivalRate{}
+	_ lib.LiveUpdatableExecutor = &ConstantArrivalRate{}
+)
+
+// GetCurrentConfig returns the executor's current configuration, i.e. with the
+// rate it was last updated to.
+func (car *ConstantArrivalRate) GetCurrentConfig() ConstantArrivalRateConfig {
+	car.configLock.RLock()
+	defer car.configLock.RUnlock()
+	return car.config
+}
+
+// GetConfig is an alias of GetCurrentConfig that implements the more generic
+// interface, so the updated rate is reported by the REST API.
+func (car *ConstantArrivalRate) GetConfig() lib.ExecutorConfig {
+	config := car.GetCurrentConfig()
+	return &config
+}
+
+// UpdateConfig changes the arrival rate of the running executor, the new
+// iterations start at the new rate right away. Only the rate of the supplied
+// ConstantArrivalRateConfig can be different from the original config. If the
+// executor isn't running yet, it waits for it to start or for ctx to be done.
+func (car *ConstantArrivalRate) UpdateConfig(ctx context.Context, newConf interface{}) error {
+	newConfig, ok := newConf.(ConstantArrivalRateConfig)
+	if !ok {
+		return errors.New("invalid config type")
+	}
+	sameRate := car.GetCurrentConfig()
+	sameRate.Rate = newConfig.Rate
+	if !reflect.DeepEqual(sameRate, newConfig) {
+		return fmt.Errorf("only the rate of the %s executor can be changed", constantArrivalRateType)
+	}
+	if newConfig.Rate.Int64 <= 0 {
+		return errors.New("invalid configuration supplied: the iteration rate must be more than 0")
+	}
+
+	event := updateRateEvent{rate: newConfig.Rate.Int64, err: make(chan error)}
+	select {
+	case car.rateUpdates <- event:
+		if err := <-event.err; err != nil {
+			return err
+		}
+		car.configLock.Lock()
+		car.config.Rate = newConfig.Rate
+		car.configLock.Unlock()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
 
 // Init values needed for the execution
 func (car *ConstantArrivalRate) Init(ctx context.Context) error {
@@ -218,7 +280,7 @@ func (car ConstantArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 		<-waitOnProgressChannel
 	}()
 
-	vusPool := newActiveVUPool(car.executionState)
+	vusPool := newActiveVUPool(car.executionState, car.config.Name)
 	defer func() {
 		// Make sure all VUs aren't executing iterations anymore, for the cancel()
 		// below to deactivate them.
@@ -320,12 +382,18 @@ func (car ConstantArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 			car.config.Rate.Int64,
 			int64(car.config.TimeUnit.TimeDuration()),
 		)).TimeDuration()
+	// the iterations are scheduled from the base ones,
+	// which are changed every time the rate is updated
+	var (
+		baseTime time.Duration
+		baseGi   int64
+	)
 
 	droppedIterationMetric := car.executionState.Test.BuiltinMetrics.DroppedIterations
 	shownWarning := false
 	metricTags := car.getMetricTags(nil)
 	for li, gi := 0, start; ; li, gi = li+1, gi+offsets[li%len(offsets)] {
-		t := notScaledTickerPeriod*time.Duration(gi) - time.Since(startTime)
+		t := baseTime + notScaledTickerPeriod*time.Duration(gi-baseGi) - time.Since(startTime)
 		timer.Reset(t)
 		select {
 		case <-timer.C:
@@ -361,6 +429,19 @@ func (car ConstantArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 			default: // we're already allocating a new VU
 			}
 
+		case update := <-car.rateUpdates:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			// the skipped iteration is replaced by the next one, which starts
+			// right away, and the following ones are spaced by the new rate
+			notScaledTickerPeriod = getTickerPeriod(
+				big.NewRat(update.rate, int64(car.config.TimeUnit.TimeDuration())),
+			).TimeDuration()
+			baseTime, baseGi = time.Since(startTime), gi+offsets[li%len(offsets)]
+			car.logger.WithField("rate", update.rate).Debug("Updated the arrival rate")
+			update.err <- nil
+
 		case <-regDurationCtx.Done():
 			return nil
 		}
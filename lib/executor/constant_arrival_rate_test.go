@@ -393,3 +393,46 @@ func TestConstantArrivalRateActiveVUs(t *testing.T) {
 	assert.GreaterOrEqual(t, running, int64(5))
 	assert.LessOrEqual(t, running, int64(10))
 }
+
+func TestConstantArrivalRateUpdateConfig(t *testing.T) {
+	t.Parallel()
+
+	var count int64
+	runner := simpleRunner(func(ctx context.Context, _ *lib.State) error {
+		atomic.AddInt64(&count, 1)
+		return nil
+	})
+
+	config := getTestConstantArrivalRateConfig()
+	config.Rate = null.IntFrom(20)
+	config.Duration = types.NullDurationFrom(2 * time.Second)
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+	defer test.cancel()
+	executor := test.executor.(*ConstantArrivalRate) //nolint:forcetypeassert
+
+	invalidConfig := *config
+	invalidConfig.MaxVUs = null.IntFrom(30)
+	require.ErrorContains(t, executor.UpdateConfig(test.ctx, invalidConfig), "only the rate")
+	invalidConfig = *config
+	invalidConfig.Rate = null.IntFrom(0)
+	require.ErrorContains(t, executor.UpdateConfig(test.ctx, invalidConfig), "invalid configuration supplied")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		time.Sleep(time.Second)
+		assert.InDelta(t, 20, atomic.SwapInt64(&count, 0), 2)
+
+		newConfig := *config
+		newConfig.Rate = null.IntFrom(60)
+		assert.NoError(t, executor.UpdateConfig(test.ctx, newConfig))
+	}()
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	require.NoError(t, test.executor.Run(test.ctx, engineOut))
+	wg.Wait()
+
+	assert.InDelta(t, 60, atomic.LoadInt64(&count), 3)
+	assert.Equal(t, null.IntFrom(60), executor.GetCurrentConfig().Rate)
+	assert.Equal(t, null.IntFrom(60), executor.GetConfig().(*ConstantArrivalRateConfig).Rate) //nolint:forcetypeassert
+}
@@ -35,6 +35,7 @@ const minDuration = 1 * time.Second
 // ConstantVUsConfig stores VUs and duration
 type ConstantVUsConfig struct {
 	BaseConfig
+	PacingConfig
 	VUs      null.Int           `json:"vus"`
 	Duration types.NullDuration `json:"duration"`
 }
@@ -58,7 +59,7 @@ func (clvc ConstantVUsConfig) GetVUs(et *lib.ExecutionTuple) int64 {
 // GetDescription returns a human-readable description of the executor options
 func (clvc ConstantVUsConfig) GetDescription(et *lib.ExecutionTuple) string {
 	return fmt.Sprintf("%d looping VUs for %s%s",
-		clvc.GetVUs(et), clvc.Duration.Duration, clvc.getBaseInfo())
+		clvc.GetVUs(et), clvc.Duration.Duration, clvc.getBaseInfo(clvc.getPacingInfo()...))
 }
 
 // Validate makes sure all options are configured and valid
@@ -76,6 +77,8 @@ func (clvc ConstantVUsConfig) Validate() []error {
 		))
 	}
 
+	errors = append(errors, clvc.PacingConfig.validate()...)
+
 	return errors
 }
 
@@ -172,6 +175,7 @@ func (clv ConstantVUs) Run(parentCtx context.Context, out chan<- metrics.SampleC
 
 	returnVU := func(u lib.InitializedVU) {
 		clv.executionState.ReturnVU(u, true)
+		clv.executionState.ModScenarioActiveVUsCount(clv.config.Name, -1)
 		activeVUs.Done()
 	}
 
@@ -183,13 +187,16 @@ func (clv ConstantVUs) Run(parentCtx context.Context, out chan<- metrics.SampleC
 			getVUActivationParams(ctx, clv.config.BaseConfig, returnVU,
 				clv.nextIterationCounters))
 
+		pacer := clv.config.newPacer()
 		for {
+			pacer.wait(regDurationCtx)
 			select {
 			case <-regDurationDone:
 				return // don't make more iterations
 			default:
 				// continue looping
 			}
+			pacer.start()
 			runIteration(maxDurationCtx, activeVU)
 		}
 	}
@@ -200,6 +207,7 @@ func (clv ConstantVUs) Run(parentCtx context.Context, out chan<- metrics.SampleC
 			cancel()
 			return err
 		}
+		clv.executionState.ModScenarioActiveVUsCount(clv.config.Name, +1)
 		activeVUs.Add(1)
 		go handleVU(initVU)
 	}
@@ -52,3 +52,62 @@ func TestConstantVUsRun(t *testing.T) {
 	})
 	assert.Equal(t, uint64(50), totalIters)
 }
+
+func TestConstantVUsRunScenarioActiveVUs(t *testing.T) {
+	t.Parallel()
+
+	var test *executorTest
+	var activeVUs sync.Map
+	runner := simpleRunner(func(ctx context.Context, state *lib.State) error {
+		activeVUs.Store(state.VUID, test.state.GetScenarioActiveVUsCounts()["checkout"])
+		time.Sleep(210 * time.Millisecond)
+		return nil
+	})
+
+	config := getTestConstantVUsConfig()
+	config.Name = "checkout"
+	test = setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+	defer test.cancel()
+
+	require.NoError(t, test.executor.Run(test.ctx, nil))
+
+	activeVUs.Range(func(key, value interface{}) bool {
+		assert.Equal(t, int64(10), value)
+		return true
+	})
+	assert.Equal(t, map[string]int64{"checkout": 0}, test.state.GetScenarioActiveVUsCounts())
+}
+
+func TestConstantVUsRunPacing(t *testing.T) {
+	t.Parallel()
+	var result sync.Map
+
+	start := time.Now()
+	runner := simpleRunner(func(ctx context.Context, state *lib.State) error {
+		starts, _ := result.LoadOrStore(state.VUID, []time.Duration{})
+		result.Store(state.VUID, append(starts.([]time.Duration), time.Since(start))) //nolint:forcetypeassert
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+
+	config := getTestConstantVUsConfig()
+	config.VUs = null.IntFrom(2)
+	config.Pacing = types.NullDurationFrom(300 * time.Millisecond)
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+	defer test.cancel()
+
+	require.NoError(t, test.executor.Run(test.ctx, nil))
+
+	vus := 0
+	result.Range(func(key, value interface{}) bool {
+		vus++
+		starts := value.([]time.Duration) //nolint:forcetypeassert
+		// the iterations start at 0, 300ms, 600ms and 900ms
+		require.Len(t, starts, 4)
+		for i := 1; i < len(starts); i++ {
+			assert.InDelta(t, 300*time.Millisecond, starts[i]-starts[i-1], float64(30*time.Millisecond))
+		}
+		return true
+	})
+	assert.Equal(t, 2, vus)
+}
@@ -98,6 +98,22 @@ func TestExecutionStateGettingVUsWhenNonAreAvailable(t *testing.T) {
 	}
 }
 
+func TestExecutionStateScenarioActiveVUs(t *testing.T) {
+	t.Parallel()
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := lib.NewExecutionState(nil, et, 0, 0)
+	assert.Empty(t, es.GetScenarioActiveVUsCounts())
+
+	assert.EqualValues(t, 1, es.ModScenarioActiveVUsCount("checkout", +1))
+	assert.EqualValues(t, 2, es.ModScenarioActiveVUsCount("checkout", +1))
+	assert.EqualValues(t, 1, es.ModScenarioActiveVUsCount("browse", +1))
+	assert.EqualValues(t, 1, es.ModScenarioActiveVUsCount("checkout", -1))
+
+	assert.Equal(t, map[string]int64{"checkout": 1, "browse": 1}, es.GetScenarioActiveVUsCounts())
+	assert.EqualValues(t, 0, es.GetCurrentlyActiveVUsCount())
+}
+
 func TestExecutionStateGettingVUs(t *testing.T) {
 	t.Parallel()
 	logHook := testutils.NewLogHook(logrus.WarnLevel, logrus.DebugLevel)
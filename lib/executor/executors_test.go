@@ -103,6 +103,102 @@ var configMapTestCases = []configMapTestCase{
 	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startTime": "-10s"}}`, exp{validationError: true}},
 	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "exec": ""}}`, exp{validationError: true}},
 	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "gracefulStop": "-2s"}}`, exp{validationError: true}},
+	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "warmupDuration": "-2s"}}`, exp{validationError: true}},
+	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "maxIterationDuration": "0s"}}`, exp{validationError: true}},
+	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "maxIterationDuration": "-2s"}}`, exp{validationError: true}},
+	{
+		`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "60s", "maxIterationDuration": "5s"}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			et, err := lib.NewExecutionTuple(nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, 5*time.Second, cm["aname"].(ConstantVUsConfig).GetMaxIterationDuration())
+			assert.Equal(t, "10 looping VUs for 1m0s (gracefulStop: 30s, maxIterationDuration: 5s)", cm["aname"].GetDescription(et))
+		}},
+	},
+	{
+		`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "60s", "warmupDuration": "15s"}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			et, err := lib.NewExecutionTuple(nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, 15*time.Second, cm["aname"].(ConstantVUsConfig).GetWarmupDuration())
+			assert.Equal(t, "10 looping VUs for 1m0s (gracefulStop: 30s, warmupDuration: 15s)", cm["aname"].GetDescription(et))
+		}},
+	},
+	// startAfter
+	{
+		`{"seed": {"executor": "per-vu-iterations", "vus": 5, "iterations": 10, "maxDuration": "10s", "gracefulStop": "0s"},
+		  "load": {"executor": "constant-vus", "vus": 10, "duration": "30s", "gracefulStop": "0s",
+		    "startAfter": ["seed"], "startTime": "5s"}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			assert.Equal(t, []string{"seed"}, cm["load"].GetStartAfter())
+
+			et, err := lib.NewExecutionTuple(nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, "10 looping VUs for 30s (startAfter: seed, startTime: 5s)", cm["load"].GetDescription(et))
+
+			// The load scenario can start between 5s and 15s, since the seed
+			// one can finish before its maxDuration.
+			assert.Equal(t, []lib.ExecutionStep{
+				{TimeOffset: 0, PlannedVUs: 5},
+				{TimeOffset: 5 * time.Second, PlannedVUs: 15},
+				{TimeOffset: 10 * time.Second, PlannedVUs: 10},
+				{TimeOffset: 45 * time.Second, PlannedVUs: 0},
+			}, cm.GetFullExecutionRequirements(et))
+		}},
+	},
+	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": ["other"]}}`, exp{validationError: true}},
+	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": ["aname"]}}`, exp{validationError: true}},
+	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": [""]}}`, exp{validationError: true}},
+	{
+		`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": ["other", "other"]},
+		  "other": {"executor": "constant-vus", "vus": 10, "duration": "10s"}}`,
+		exp{validationError: true},
+	},
+	{
+		`{"first": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": ["third"]},
+		  "second": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": ["first"]},
+		  "third": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": ["second"]}}`,
+		exp{validationError: true, custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			errs := cm.Validate()
+			require.Len(t, errs, 1)
+			assert.EqualError(t, errs[0], "the scenarios first -> third -> second -> first depend on each other with startAfter")
+		}},
+	},
+	// dormant
+	{
+		`{"baseline": {"executor": "constant-vus", "vus": 10, "duration": "30s", "gracefulStop": "0s"},
+		  "spike": {"executor": "shared-iterations", "vus": 5, "iterations": 10, "maxDuration": "10s",
+		    "gracefulStop": "0s", "dormant": true}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			assert.False(t, cm["baseline"].IsDormant())
+			assert.True(t, cm["spike"].IsDormant())
+
+			et, err := lib.NewExecutionTuple(nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, "10 iterations shared among 5 VUs (maxDuration: 10s, dormant)", cm["spike"].GetDescription(et))
+
+			// The spike scenario can be started at any moment until the
+			// baseline one is done.
+			assert.Equal(t, []lib.ExecutionStep{
+				{TimeOffset: 0, PlannedVUs: 10},
+				{TimeOffset: 0, PlannedVUs: 15},
+				{TimeOffset: 30 * time.Second, PlannedVUs: 5},
+				{TimeOffset: 40 * time.Second, PlannedVUs: 0},
+			}, cm.GetFullExecutionRequirements(et))
+		}},
+	},
+	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "dormant": true}}`, exp{validationError: true}},
+	{
+		`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "dormant": true, "startAfter": ["other"]},
+		  "other": {"executor": "constant-vus", "vus": 10, "duration": "10s"}}`,
+		exp{validationError: true},
+	},
+	{
+		`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": ["other"]},
+		  "other": {"executor": "constant-vus", "vus": 10, "duration": "10s", "dormant": true},
+		  "third": {"executor": "constant-vus", "vus": 10, "duration": "10s"}}`,
+		exp{validationError: true},
+	},
 	// ramping-vus
 	{
 		`{"varloops": {"executor": "ramping-vus", "startVUs": 20, "gracefulStop": "15s", "gracefulRampDown": "10s",
@@ -404,6 +500,57 @@ var configMapTestCases = []configMapTestCase{
 	{`{"varrival": {"executor": "ramping-arrival-rate", "preAllocatedVUs": 20, "maxVUs": 50, "stages": []}}`, exp{validationError: true}},
 	{`{"varrival": {"executor": "ramping-arrival-rate", "preAllocatedVUs": 20, "maxVUs": 50, "stages": [{"duration": "5m", "target": 10}], "timeUnit": "-1s"}}`, exp{validationError: true}},
 	{`{"varrival": {"executor": "ramping-arrival-rate", "preAllocatedVUs": 30, "maxVUs": 20, "stages": [{"duration": "5m", "target": 10}]}}`, exp{validationError: true}},
+	{
+		`{"varrival": {"executor": "ramping-arrival-rate", "preAllocatedVUs": 20, "curve": "sine", "stages": [{"duration": "5m", "target": 10}]}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			assert.Empty(t, cm["varrival"].Validate())
+			require.Equal(t, RateCurveSine, cm["varrival"].(*RampingArrivalRateConfig).Curve)
+		}},
+	},
+	{`{"varrival": {"executor": "ramping-arrival-rate", "preAllocatedVUs": 20, "curve": "cubic", "stages": [{"duration": "5m", "target": 10}]}}`, exp{parseError: true}},
+	{`{"varrival": {"executor": "ramping-arrival-rate", "preAllocatedVUs": 20, "curve": "function", "curveFunction": "rate", "stages": [{"duration": "5m", "target": 10}]}}`, exp{}},
+	{`{"varrival": {"executor": "ramping-arrival-rate", "preAllocatedVUs": 20, "curve": "function", "stages": [{"duration": "5m", "target": 10}]}}`, exp{validationError: true}},
+	{`{"varrival": {"executor": "ramping-arrival-rate", "preAllocatedVUs": 20, "curve": "step", "curveFunction": "rate", "stages": [{"duration": "5m", "target": 10}]}}`, exp{validationError: true}},
+	// pacing
+	{
+		`{"paced": {"executor": "constant-vus", "vus": 10, "duration": "1m", "pacing": "5s", "pacingJitter": "1s"}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			assert.Empty(t, cm["paced"].Validate())
+			et := mustNewExecutionTuple(nil, nil)
+			assert.Equal(t, "10 looping VUs for 1m0s (pacing: 5s±1s, gracefulStop: 30s)", cm["paced"].GetDescription(et))
+		}},
+	},
+	{
+		`{"paced": {"executor": "per-vu-iterations", "vus": 10, "iterations": 5, "pacing": "5s"}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			assert.Empty(t, cm["paced"].Validate())
+			et := mustNewExecutionTuple(nil, nil)
+			assert.Equal(t, "5 iterations for each of 10 VUs (maxDuration: 10m0s, pacing: 5s, gracefulStop: 30s)",
+				cm["paced"].GetDescription(et))
+		}},
+	},
+	{`{"paced": {"executor": "constant-vus", "vus": 10, "duration": "1m", "pacing": "-5s"}}`, exp{validationError: true}},
+	{`{"paced": {"executor": "constant-vus", "vus": 10, "duration": "1m", "pacing": "5s", "pacingJitter": "6s"}}`, exp{validationError: true}},
+	{`{"paced": {"executor": "per-vu-iterations", "vus": 10, "iterations": 5, "pacingJitter": "1s"}}`, exp{validationError: true}},
+	// profile-replay
+	{
+		`{"replay": {"executor": "profile-replay", "timeScale": 0.5, "preAllocatedVUs": 20,
+		"points": [{"time": 0, "rate": 10}, {"time": "1m", "rate": 30}]}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			assert.Empty(t, cm["replay"].Validate())
+			prc, ok := cm["replay"].(*ProfileReplayConfig)
+			require.True(t, ok)
+			assert.Equal(t, null.FloatFrom(0.5), prc.TimeScale)
+			assert.Equal(t, types.Duration(time.Minute), prc.Points[1].Time)
+			assert.EqualValues(t, 20, prc.MaxVUs.Int64)
+		}},
+	},
+	{`{"replay": {"executor": "profile-replay", "preAllocatedVUs": 20}}`, exp{validationError: true}},
+	{`{"replay": {"executor": "profile-replay", "preAllocatedVUs": 20, "points": [{"time": 0, "rate": 10}]}}`, exp{validationError: true}},
+	{`{"replay": {"executor": "profile-replay", "preAllocatedVUs": 20, "points": [{"time": "1s", "rate": 10}, {"time": 0, "rate": 10}]}}`, exp{validationError: true}},
+	{`{"replay": {"executor": "profile-replay", "preAllocatedVUs": 20, "points": [{"time": 0, "rate": -1}, {"time": "1s", "rate": 10}]}}`, exp{validationError: true}},
+	{`{"replay": {"executor": "profile-replay", "preAllocatedVUs": 20, "timeScale": 0, "points": [{"time": 0, "rate": 1}, {"time": "1s", "rate": 10}]}}`, exp{validationError: true}},
+	{`{"replay": {"executor": "profile-replay", "preAllocatedVUs": 20, "profile": "profile.csv"}}`, exp{validationError: true}},
 	// TODO: more tests of mixed executors and execution plans
 
 	// scenario options
@@ -12,6 +12,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"gopkg.in/guregu/null.v3"
 
+	"go.k6.io/k6/execution"
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
@@ -153,6 +154,7 @@ func (mec ExternallyControlledConfig) NewExecutor(es *lib.ExecutionState, logger
 		currentControlConfig: mec.ExternallyControlledConfigParams,
 		configLock:           &sync.RWMutex{},
 		newControlConfigs:    make(chan updateConfigEvent),
+		newExecutionTuples:   make(chan updateExecutionTupleEvent),
 		pauseEvents:          make(chan pauseEvent),
 		hasStarted:           make(chan struct{}),
 	}, nil
@@ -174,24 +176,32 @@ type updateConfigEvent struct {
 	err       chan error
 }
 
+type updateExecutionTupleEvent struct {
+	newExecutionTuple *lib.ExecutionTuple
+	err               chan error
+}
+
 // ExternallyControlled is an implementation of the old k6 executor that could be
-// controlled externally, via the k6 REST API. It implements both the
-// lib.PausableExecutor and the lib.LiveUpdatableExecutor interfaces.
+// controlled externally, via the k6 REST API. It implements the
+// lib.PausableExecutor, the lib.LiveUpdatableExecutor and the
+// lib.SegmentUpdatableExecutor interfaces.
 type ExternallyControlled struct {
 	*BaseExecutor
 	config               ExternallyControlledConfig
 	currentControlConfig ExternallyControlledConfigParams
 	configLock           *sync.RWMutex
 	newControlConfigs    chan updateConfigEvent
+	newExecutionTuples   chan updateExecutionTupleEvent
 	pauseEvents          chan pauseEvent
 	hasStarted           chan struct{}
 }
 
 // Make sure we implement all the interfaces
 var (
-	_ lib.Executor              = &ExternallyControlled{}
-	_ lib.PausableExecutor      = &ExternallyControlled{}
-	_ lib.LiveUpdatableExecutor = &ExternallyControlled{}
+	_ lib.Executor                 = &ExternallyControlled{}
+	_ lib.PausableExecutor         = &ExternallyControlled{}
+	_ lib.LiveUpdatableExecutor    = &ExternallyControlled{}
+	_ lib.SegmentUpdatableExecutor = &ExternallyControlled{}
 )
 
 // GetCurrentConfig just returns the executor's current configuration.
@@ -285,6 +295,24 @@ func (mex *ExternallyControlled) UpdateConfig(ctx context.Context, newConf inter
 	}
 }
 
+// UpdateExecutionTuple changes the execution segment of the running executor,
+// scaling its active and max VUs to the new segment.
+func (mex *ExternallyControlled) UpdateExecutionTuple(ctx context.Context, et *lib.ExecutionTuple) error {
+	select {
+	case <-mex.hasStarted:
+	default:
+		return errors.New("cannot change the execution segment of the externally controlled executor before it has started")
+	}
+
+	event := updateExecutionTupleEvent{newExecutionTuple: et, err: make(chan error)}
+	select {
+	case mex.newExecutionTuples <- event:
+		return <-event.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // This is a helper function that is used in run for non-infinite durations.
 func (mex *ExternallyControlled) stopWhenDurationIsReached(ctx context.Context, duration time.Duration, cancel func()) {
 	ctxDone := ctx.Done()
@@ -331,11 +359,13 @@ func (rs *externallyControlledRunState) newManualVUHandle(
 	getVU := func() (lib.InitializedVU, error) {
 		wg.Add(1)
 		state.ModCurrentlyActiveVUsCount(+1)
+		state.ModScenarioActiveVUsCount(rs.executor.config.Name, +1)
 		atomic.AddInt64(rs.activeVUsCount, +1)
 		return initVU, nil
 	}
 	returnVU := func(_ lib.InitializedVU) {
 		state.ModCurrentlyActiveVUsCount(-1)
+		state.ModScenarioActiveVUsCount(rs.executor.config.Name, -1)
 		atomic.AddInt64(rs.activeVUsCount, -1)
 		wg.Done()
 	}
@@ -356,12 +386,13 @@ func (rs *externallyControlledRunState) newManualVUHandle(
 type externallyControlledRunState struct {
 	ctx             context.Context
 	executor        *ExternallyControlled
-	startMaxVUs     int64             // the scaled number of initially configured MaxVUs
-	duration        time.Duration     // the total duration of the executor, could be 0 for infinite
-	activeVUsCount  *int64            // the current number of active VUs, used only for the progress display
-	maxVUs          *int64            // the current number of initialized VUs
-	vuHandles       []*manualVUHandle // handles for manipulating and tracking all of the VUs
-	currentlyPaused bool              // whether the executor is currently paused
+	et              *lib.ExecutionTuple // the current execution segment, it can be changed while running
+	startMaxVUs     int64               // the scaled number of initially configured MaxVUs
+	duration        time.Duration       // the total duration of the executor, could be 0 for infinite
+	activeVUsCount  *int64              // the current number of active VUs, used only for the progress display
+	maxVUs          *int64              // the current number of initialized VUs
+	vuHandles       []*manualVUHandle   // handles for manipulating and tracking all of the VUs
+	currentlyPaused bool                // whether the executor is currently paused
 
 	runIteration func(context.Context, lib.ActiveVU) bool // a helper closure function that runs a single iteration
 }
@@ -412,12 +443,29 @@ func (rs *externallyControlledRunState) progressFn() (float64, []string) {
 }
 
 func (rs *externallyControlledRunState) handleConfigChange(oldCfg, newCfg ExternallyControlledConfigParams) error {
+	return rs.rescale(
+		rs.et.ScaleInt64(oldCfg.VUs.Int64), rs.et.ScaleInt64(oldCfg.MaxVUs.Int64),
+		rs.et.ScaleInt64(newCfg.VUs.Int64), rs.et.ScaleInt64(newCfg.MaxVUs.Int64),
+	)
+}
+
+func (rs *externallyControlledRunState) handleExecutionTupleChange(
+	cfg ExternallyControlledConfigParams, newET *lib.ExecutionTuple,
+) error {
+	err := rs.rescale(
+		rs.et.ScaleInt64(cfg.VUs.Int64), rs.et.ScaleInt64(cfg.MaxVUs.Int64),
+		newET.ScaleInt64(cfg.VUs.Int64), newET.ScaleInt64(cfg.MaxVUs.Int64),
+	)
+	if err != nil {
+		return err
+	}
+	rs.et = newET
+	return nil
+}
+
+// rescale changes the (already scaled) numbers of active and max VUs.
+func (rs *externallyControlledRunState) rescale(oldActiveVUs, oldMaxVUs, newActiveVUs, newMaxVUs int64) error {
 	executionState := rs.executor.executionState
-	et := executionState.ExecutionTuple
-	oldActiveVUs := et.ScaleInt64(oldCfg.VUs.Int64)
-	oldMaxVUs := et.ScaleInt64(oldCfg.MaxVUs.Int64)
-	newActiveVUs := et.ScaleInt64(newCfg.VUs.Int64)
-	newMaxVUs := et.ScaleInt64(newCfg.MaxVUs.Int64)
 
 	rs.executor.logger.WithFields(logrus.Fields{
 		"oldActiveVUs": oldActiveVUs, "oldMaxVUs": oldMaxVUs,
@@ -517,6 +565,7 @@ func (mex *ExternallyControlled) Run(parentCtx context.Context, out chan<- metri
 	runState := &externallyControlledRunState{
 		ctx:             ctx,
 		executor:        mex,
+		et:              mex.executionState.ExecutionTuple,
 		startMaxVUs:     startMaxVUs,
 		duration:        duration,
 		vuHandles:       make([]*manualVUHandle, startMaxVUs),
@@ -548,10 +597,21 @@ func (mex *ExternallyControlled) Run(parentCtx context.Context, out chan<- metri
 		err = runState.handleConfigChange(currentControlConfig, ExternallyControlledConfigParams{})
 	}()
 
+	drainSignal := execution.GetAbortDrainSignal(ctx)
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
+		case <-drainSignal:
+			// The test run was aborted, so the VUs stop after their current
+			// iterations, unless they're interrupted by the end of ctx before that.
+			for _, vuHandle := range runState.vuHandles {
+				vuHandle.gracefulStop()
+			}
+			for _, vuHandle := range runState.vuHandles {
+				vuHandle.wg.Wait()
+			}
+			return nil
 		case updateConfigEvent := <-mex.newControlConfigs:
 			err := runState.handleConfigChange(currentControlConfig, updateConfigEvent.newConfig)
 			if err != nil {
@@ -567,6 +627,17 @@ func (mex *ExternallyControlled) Run(parentCtx context.Context, out chan<- metri
 			mex.configLock.Unlock()
 			updateConfigEvent.err <- nil
 
+		case tupleEvent := <-mex.newExecutionTuples:
+			err := runState.handleExecutionTupleChange(currentControlConfig, tupleEvent.newExecutionTuple)
+			tupleEvent.err <- err
+			if err != nil {
+				if ctx.Err() == err {
+					return nil
+				}
+				return err
+			}
+			mex.logger.WithField("segment", tupleEvent.newExecutionTuple).Debug("Updated the execution segment")
+
 		case pauseEvent := <-mex.pauseEvents:
 			if pauseEvent.isPaused == runState.currentlyPaused {
 				pauseEvent.err <- nil
@@ -107,3 +107,46 @@ func TestExternallyControlledRun(t *testing.T) {
 	assert.InDelta(t, 48, int(atomic.LoadUint64(doneIters)), 2)
 	assert.Equal(t, [][]int64{{2, 10}, {4, 10}, {8, 20}, {4, 10}, {0, 10}}, resultVUCount)
 }
+
+func TestExternallyControlledUpdateExecutionTuple(t *testing.T) {
+	t.Parallel()
+
+	runner := simpleRunner(func(ctx context.Context, _ *lib.State) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	config := ExternallyControlledConfig{
+		ExternallyControlledConfigParams: ExternallyControlledConfigParams{
+			VUs:      null.IntFrom(4),
+			MaxVUs:   null.IntFrom(10),
+			Duration: types.NullDurationFrom(1 * time.Second),
+		},
+	}
+	test := setupExecutorTest(t, "0:1/2", "0,1/2,1", lib.Options{}, runner, config)
+	defer test.cancel()
+	executor := test.executor.(*ExternallyControlled) //nolint:forcetypeassert
+
+	fullSegment, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	require.ErrorContains(t, executor.UpdateExecutionTuple(test.ctx, fullSegment), "before it has started")
+
+	errCh := make(chan error, 1)
+	go func() {
+		test.state.MarkStarted()
+		errCh <- test.executor.Run(test.ctx, nil)
+		test.state.MarkEnded()
+	}()
+
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, int64(2), test.state.GetCurrentlyActiveVUsCount())
+	assert.Equal(t, int64(5), test.state.GetInitializedVUsCount())
+
+	// the instance takes over the whole test, e.g. after the other one has been lost
+	require.NoError(t, executor.UpdateExecutionTuple(test.ctx, fullSegment))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int64(4), test.state.GetCurrentlyActiveVUsCount())
+	assert.Equal(t, int64(10), test.state.GetInitializedVUsCount())
+
+	require.NoError(t, <-errCh)
+	assert.Equal(t, int64(0), test.state.GetCurrentlyActiveVUsCount())
+}
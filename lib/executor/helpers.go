@@ -95,6 +95,11 @@ func getIterationRunner(
 					executionState.AddInterruptedIterations(1)
 					return false
 				}
+				if errors.Is(err, lib.ErrMaxIterationDurationExceeded) {
+					logger.Warn(err.Error())
+					executionState.AddInterruptedIterations(1)
+					return false
+				}
 
 				var exception errext.Exception
 				if errors.As(err, &exception) {
@@ -138,6 +143,8 @@ func getIterationRunner(
 //   - If the whole test is aborted, the parent context will be cancelled, so
 //     that will also cancel these contexts, thus the "general abort" case is
 //     handled transparently.
+//   - If the whole test is aborted and its in-flight iterations are drained,
+//     only regDurationCtx will be cancelled, so no new iterations are started.
 func getDurationContexts(parentCtx context.Context, regularDuration, gracefulStop time.Duration) (
 	startTime time.Time, maxDurationCtx, regDurationCtx context.Context, maxDurationCancel func(),
 ) {
@@ -146,10 +153,29 @@ func getDurationContexts(parentCtx context.Context, regularDuration, gracefulSto
 
 	maxDurationCtx, maxDurationCancel = context.WithDeadline(parentCtx, maxEndTime)
 	if gracefulStop == 0 {
-		return startTime, maxDurationCtx, maxDurationCtx, maxDurationCancel
+		return startTime, maxDurationCtx, withAbortDrain(maxDurationCtx), maxDurationCancel
 	}
 	regDurationCtx, _ = context.WithDeadline(maxDurationCtx, startTime.Add(regularDuration)) //nolint:govet
-	return startTime, maxDurationCtx, regDurationCtx, maxDurationCancel
+	return startTime, maxDurationCtx, withAbortDrain(regDurationCtx), maxDurationCancel
+}
+
+// withAbortDrain returns a sub-context of the supplied one that's also
+// cancelled when the test run is aborted and its in-flight iterations are
+// drained, or the same context if the iterations aren't drained.
+func withAbortDrain(ctx context.Context) context.Context {
+	drainSignal := execution.GetAbortDrainSignal(ctx)
+	if drainSignal == nil {
+		return ctx
+	}
+	drainCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		defer cancel()
+		select {
+		case <-drainSignal:
+		case <-drainCtx.Done():
+		}
+	}()
+	return drainCtx
 }
 
 // trackProgress is a helper function that monitors certain end-events in an
@@ -225,7 +251,7 @@ func getVUActivationParams(
 	ctx context.Context, conf BaseConfig, deactivateCallback func(lib.InitializedVU),
 	nextIterationCounters func() (uint64, uint64),
 ) *lib.VUActivationParams {
-	return &lib.VUActivationParams{
+	params := &lib.VUActivationParams{
 		RunContext:               ctx,
 		Scenario:                 conf.Name,
 		Exec:                     conf.GetExec(),
@@ -233,5 +259,12 @@ func getVUActivationParams(
 		Tags:                     conf.GetTags(),
 		DeactivateCallback:       deactivateCallback,
 		GetNextIterationCounters: nextIterationCounters,
+		MaxIterationDuration:     conf.GetMaxIterationDuration(),
+	}
+	if warmup := conf.GetWarmupDuration(); warmup > 0 {
+		if ss := lib.GetScenarioState(ctx); ss != nil {
+			params.WarmupUntil = ss.StartTime.Add(warmup)
+		}
 	}
+	return params
 }
@@ -0,0 +1,95 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"go.k6.io/k6/lib/types"
+)
+
+// PacingConfig contains the options that make the
+// iterations of every VU start on a fixed cadence.
+type PacingConfig struct {
+	// Pacing is the time between the starts of two consecutive iterations of
+	// a VU. The iterations that take longer are followed immediately by the
+	// next one.
+	Pacing types.NullDuration `json:"pacing"`
+	// PacingJitter randomly shortens or lengthens the
+	// time between the iterations, by up to its value.
+	PacingJitter types.NullDuration `json:"pacingJitter"`
+}
+
+func (pc PacingConfig) validate() []error {
+	var errors []error
+	if pc.Pacing.TimeDuration() < 0 {
+		errors = append(errors, fmt.Errorf("the pacing can't be negative"))
+	}
+	if jitter := pc.PacingJitter.TimeDuration(); jitter < 0 {
+		errors = append(errors, fmt.Errorf("the pacingJitter can't be negative"))
+	} else if jitter > pc.Pacing.TimeDuration() {
+		errors = append(errors, fmt.Errorf("the pacingJitter can't be more than the pacing"))
+	}
+	return errors
+}
+
+// getPacingInfo returns the pacing details for the description
+// of the executor, if the pacing is configured.
+func (pc PacingConfig) getPacingInfo() []string {
+	if pc.Pacing.TimeDuration() <= 0 {
+		return nil
+	}
+	if pc.PacingJitter.TimeDuration() > 0 {
+		return []string{fmt.Sprintf("pacing: %s±%s", pc.Pacing.Duration, pc.PacingJitter.Duration)}
+	}
+	return []string{fmt.Sprintf("pacing: %s", pc.Pacing.Duration)}
+}
+
+// newPacer returns a pacer for a single VU, or nil
+// if the pacing isn't configured.
+func (pc PacingConfig) newPacer() *pacer {
+	if pc.Pacing.TimeDuration() <= 0 {
+		return nil
+	}
+	return &pacer{pacing: pc.Pacing.TimeDuration(), jitter: pc.PacingJitter.TimeDuration()}
+}
+
+// pacer delays the iterations of a VU, so they start on a fixed cadence. All
+// of its methods are no-ops for a nil pacer.
+type pacer struct {
+	pacing, jitter time.Duration
+	lastStart      time.Time
+}
+
+// start records the start of an iteration.
+func (p *pacer) start() {
+	if p == nil {
+		return
+	}
+	p.lastStart = time.Now()
+}
+
+// wait blocks until it's time to start the next iteration, or until the
+// context is done.
+func (p *pacer) wait(ctx context.Context) {
+	if p == nil || p.lastStart.IsZero() {
+		return
+	}
+
+	interval := p.pacing
+	if p.jitter > 0 {
+		interval += time.Duration(rand.Int63n(int64(2*p.jitter)+1)) - p.jitter //nolint:gosec
+	}
+	delay := time.Until(p.lastStart.Add(interval))
+	if delay <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
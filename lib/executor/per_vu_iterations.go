@@ -29,6 +29,7 @@ func init() {
 // PerVUIterationsConfig stores the number of VUs iterations, as well as maxDuration settings
 type PerVUIterationsConfig struct {
 	BaseConfig
+	PacingConfig
 	VUs         null.Int           `json:"vus"`
 	Iterations  null.Int           `json:"iterations"`
 	MaxDuration types.NullDuration `json:"maxDuration"`
@@ -64,7 +65,9 @@ func (pvic PerVUIterationsConfig) GetIterations() int64 {
 func (pvic PerVUIterationsConfig) GetDescription(et *lib.ExecutionTuple) string {
 	return fmt.Sprintf("%d iterations for each of %d VUs%s",
 		pvic.GetIterations(), pvic.GetVUs(et),
-		pvic.getBaseInfo(fmt.Sprintf("maxDuration: %s", pvic.MaxDuration.Duration)))
+		pvic.getBaseInfo(append(
+			[]string{fmt.Sprintf("maxDuration: %s", pvic.MaxDuration.Duration)}, pvic.getPacingInfo()...,
+		)...))
 }
 
 // Validate makes sure all options are configured and valid
@@ -84,6 +87,8 @@ func (pvic PerVUIterationsConfig) Validate() []error {
 		))
 	}
 
+	errors = append(errors, pvic.PacingConfig.validate()...)
+
 	return errors
 }
 
@@ -196,6 +201,7 @@ func (pvi PerVUIterations) Run(parentCtx context.Context, out chan<- metrics.Sam
 
 	returnVU := func(u lib.InitializedVU) {
 		pvi.executionState.ReturnVU(u, true)
+		pvi.executionState.ModScenarioActiveVUsCount(pvi.config.Name, -1)
 		activeVUs.Done()
 	}
 
@@ -210,7 +216,9 @@ func (pvi PerVUIterations) Run(parentCtx context.Context, out chan<- metrics.Sam
 			getVUActivationParams(ctx, pvi.config.BaseConfig, returnVU,
 				pvi.nextIterationCounters))
 
+		pacer := pvi.config.newPacer()
 		for i := int64(0); i < iterations; i++ {
+			pacer.wait(regDurationCtx)
 			select {
 			case <-regDurationDone:
 				metrics.PushIfNotDone(parentCtx, out, metrics.Sample{
@@ -225,6 +233,7 @@ func (pvi PerVUIterations) Run(parentCtx context.Context, out chan<- metrics.Sam
 			default:
 				// continue looping
 			}
+			pacer.start()
 			runIteration(maxDurationCtx, activeVU)
 			atomic.AddUint64(doneIters, 1)
 		}
@@ -236,6 +245,7 @@ func (pvi PerVUIterations) Run(parentCtx context.Context, out chan<- metrics.Sam
 			cancel()
 			return err
 		}
+		pvi.executionState.ModScenarioActiveVUsCount(pvi.config.Name, +1)
 		activeVUs.Add(1)
 		handleVUsWG.Add(1)
 		go handleVU(initializedVU)
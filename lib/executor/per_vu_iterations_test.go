@@ -120,3 +120,40 @@ func TestPerVuIterationsEmitDroppedIterations(t *testing.T) {
 	assert.Equal(t, int64(5), count)
 	assert.Equal(t, float64(95), sumMetricValues(engineOut, metrics.DroppedIterationsName))
 }
+
+func TestPerVUIterationsRunPacing(t *testing.T) {
+	t.Parallel()
+	var count int64
+
+	config := PerVUIterationsConfig{
+		PacingConfig: PacingConfig{
+			Pacing:       types.NullDurationFrom(300 * time.Millisecond),
+			PacingJitter: types.NullDurationFrom(100 * time.Millisecond),
+		},
+		VUs:         null.IntFrom(2),
+		Iterations:  null.IntFrom(10),
+		MaxDuration: types.NullDurationFrom(1 * time.Second),
+	}
+	require.Empty(t, config.PacingConfig.validate())
+
+	runner := simpleRunner(func(ctx context.Context, _ *lib.State) error {
+		atomic.AddInt64(&count, 1)
+		return nil
+	})
+
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+	defer test.cancel()
+
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	start := time.Now()
+	require.NoError(t, test.executor.Run(test.ctx, engineOut))
+	// the wait for the next iteration is interrupted by the end of the maxDuration
+	assert.InDelta(t, time.Second, time.Since(start), float64(100*time.Millisecond))
+	assert.Empty(t, test.logHook.Drain())
+
+	// each VU starts an iteration every 200ms to 400ms
+	done := atomic.LoadInt64(&count)
+	assert.GreaterOrEqual(t, done, int64(2*3))
+	assert.LessOrEqual(t, done, int64(2*5))
+	assert.Equal(t, float64(20-done), sumMetricValues(engineOut, metrics.DroppedIterationsName))
+}
@@ -0,0 +1,304 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/lib/types"
+)
+
+const profileReplayType = "profile-replay"
+
+// profileReplayTimeUnit is the time unit of the ramping arrival rate that
+// replays the profile, it's big so the fractions of the rates per second
+// aren't lost when they are converted to the integer stage targets.
+const profileReplayTimeUnit = 1000 * time.Second
+
+func init() {
+	lib.RegisterExecutorConfigType(
+		profileReplayType,
+		func(name string, rawJSON []byte) (lib.ExecutorConfig, error) {
+			config := NewProfileReplayConfig(name)
+			err := lib.StrictJSONUnmarshal(rawJSON, &config)
+			return config, err
+		},
+	)
+}
+
+// ProfilePoint is the target rate of iterations per second
+// at a specific time, relative to the start of the profile.
+type ProfilePoint struct {
+	Time types.Duration `json:"time"`
+	Rate float64        `json:"rate"`
+}
+
+// ProfileReplayConfig stores the configuration for the profile-replay
+// executor, which replays the rates of iterations recorded in a profile, for
+// example one exported from the metrics of the production traffic.
+type ProfileReplayConfig struct {
+	BaseConfig
+	// Profile is the path to a CSV or JSON file with the points of the
+	// profile, which are loaded in Points before the test starts.
+	Profile null.String    `json:"profile"`
+	Points  []ProfilePoint `json:"points"`
+	// Interval is the time between the points of the profiles that
+	// contain only the rates, without the times.
+	Interval types.NullDuration `json:"interval"`
+	// TimeScale stretches (when it's more than 1) or compresses (when
+	// it's less than 1) the duration of the profile.
+	TimeScale null.Float `json:"timeScale"`
+	// RateScale multiplies all the rates of the profile.
+	RateScale null.Float `json:"rateScale"`
+
+	PreAllocatedVUs null.Int `json:"preAllocatedVUs"`
+	MaxVUs          null.Int `json:"maxVUs"`
+}
+
+// NewProfileReplayConfig returns a ProfileReplayConfig with default values
+func NewProfileReplayConfig(name string) *ProfileReplayConfig {
+	return &ProfileReplayConfig{
+		BaseConfig: NewBaseConfig(name, profileReplayType),
+		Interval:   types.NewNullDuration(time.Second, false),
+		TimeScale:  null.NewFloat(1, false),
+		RateScale:  null.NewFloat(1, false),
+	}
+}
+
+// Make sure we implement the lib.ExecutorConfig interface
+var _ lib.ExecutorConfig = &ProfileReplayConfig{}
+
+// GetDescription returns a human-readable description of the executor options
+func (prc ProfileReplayConfig) GetDescription(et *lib.ExecutionTuple) string {
+	rarc := prc.getRampingArrivalRateConfig()
+	maxVUsRange := fmt.Sprintf("maxVUs: %d", et.ScaleInt64(prc.PreAllocatedVUs.Int64))
+	if prc.MaxVUs.Int64 > prc.PreAllocatedVUs.Int64 {
+		maxVUsRange += fmt.Sprintf("-%d", et.ScaleInt64(prc.MaxVUs.Int64))
+	}
+	maxUnscaledRate := getStagesUnscaledMaxTarget(rarc.StartRate.Int64, rarc.Stages)
+	maxArrRatePerSec, _ := getArrivalRatePerSec(
+		getScaledArrivalRate(et.Segment, maxUnscaledRate, profileReplayTimeUnit),
+	).Float64()
+
+	return fmt.Sprintf("Up to %.2f iterations/s for %s, replaying %d profile points%s",
+		maxArrRatePerSec, sumStagesDuration(rarc.Stages), len(prc.Points), prc.getBaseInfo(maxVUsRange))
+}
+
+// Validate makes sure all options are configured and valid
+func (prc *ProfileReplayConfig) Validate() []error {
+	errors := prc.BaseConfig.Validate()
+
+	if len(prc.Points) < 2 {
+		if prc.Profile.Valid {
+			errors = append(errors, fmt.Errorf("the profile '%s' needs at least 2 points", prc.Profile.String))
+		} else {
+			errors = append(errors, fmt.Errorf("either the profile or at least 2 points have to be specified"))
+		}
+	}
+	for i, point := range prc.Points {
+		if point.Rate < 0 || math.IsNaN(point.Rate) || math.IsInf(point.Rate, 0) {
+			errors = append(errors, fmt.Errorf("the rate of point %d is invalid", i+1))
+		}
+		if i > 0 && point.Time <= prc.Points[i-1].Time {
+			errors = append(errors, fmt.Errorf("the time of point %d has to be after the previous point", i+1))
+		}
+	}
+
+	if prc.Interval.TimeDuration() <= 0 {
+		errors = append(errors, fmt.Errorf("the interval must be more than 0"))
+	}
+	if prc.TimeScale.Float64 <= 0 {
+		errors = append(errors, fmt.Errorf("the timeScale must be more than 0"))
+	}
+	if prc.RateScale.Float64 < 0 {
+		errors = append(errors, fmt.Errorf("the rateScale can't be negative"))
+	}
+
+	if !prc.PreAllocatedVUs.Valid {
+		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs isn't specified"))
+	} else if prc.PreAllocatedVUs.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs can't be negative"))
+	}
+
+	if !prc.MaxVUs.Valid {
+		// TODO: don't change the config while validating
+		prc.MaxVUs.Int64 = prc.PreAllocatedVUs.Int64
+	} else if prc.MaxVUs.Int64 < prc.PreAllocatedVUs.Int64 {
+		errors = append(errors, fmt.Errorf("maxVUs can't be less than preAllocatedVUs"))
+	}
+
+	return errors
+}
+
+// getRampingArrivalRateConfig returns the config of the ramping arrival rate
+// executor that replays the profile, with a stage for every point after the
+// first one. The rate changes linearly between the points.
+func (prc ProfileReplayConfig) getRampingArrivalRateConfig() RampingArrivalRateConfig {
+	scaledRate := func(rate float64) null.Int {
+		perUnit := rate * prc.RateScale.Float64 * float64(profileReplayTimeUnit/time.Second)
+		return null.IntFrom(int64(math.Round(perUnit)))
+	}
+
+	rarc := RampingArrivalRateConfig{
+		BaseConfig:      prc.BaseConfig,
+		TimeUnit:        types.NullDurationFrom(profileReplayTimeUnit),
+		PreAllocatedVUs: prc.PreAllocatedVUs,
+		MaxVUs:          prc.MaxVUs,
+	}
+	if len(prc.Points) == 0 {
+		return rarc
+	}
+
+	rarc.StartRate = scaledRate(prc.Points[0].Rate)
+	rarc.Stages = make([]Stage, 0, len(prc.Points)-1)
+	for i := 1; i < len(prc.Points); i++ {
+		duration := float64(prc.Points[i].Time-prc.Points[i-1].Time) * prc.TimeScale.Float64
+		rarc.Stages = append(rarc.Stages, Stage{
+			Duration: types.NullDurationFrom(time.Duration(duration)),
+			Target:   scaledRate(prc.Points[i].Rate),
+		})
+	}
+	return rarc
+}
+
+// GetExecutionRequirements returns the number of required VUs to run the
+// executor for its whole duration (disregarding any startTime), including the
+// maximum waiting time for any iterations to gracefully stop. This is used by
+// the execution scheduler in its VU reservation calculations, so it knows how
+// many VUs to pre-initialize.
+func (prc ProfileReplayConfig) GetExecutionRequirements(et *lib.ExecutionTuple) []lib.ExecutionStep {
+	return prc.getRampingArrivalRateConfig().GetExecutionRequirements(et)
+}
+
+// NewExecutor creates a new ProfileReplay executor, which is a ramping arrival
+// rate executor with the stages that follow the points of the profile.
+func (prc ProfileReplayConfig) NewExecutor(es *lib.ExecutionState, logger *logrus.Entry) (lib.Executor, error) {
+	return prc.getRampingArrivalRateConfig().NewExecutor(es, logger)
+}
+
+// HasWork reports whether there is any work to be done for the given execution segment.
+func (prc ProfileReplayConfig) HasWork(et *lib.ExecutionTuple) bool {
+	return et.ScaleInt64(prc.MaxVUs.Int64) > 0
+}
+
+// LoadProfile reads the points from the profile file, if they aren't already
+// loaded. Relative paths are resolved from the provided working directory.
+//
+// CSV files have a rate per second in every row, optionally preceded by its
+// time, in seconds, as a timestamp or as a duration. A header row is
+// skipped. JSON files have an array of rates or an array of points, like
+// the ones in the points option. The rates without times are separated by
+// the configured interval.
+func (prc *ProfileReplayConfig) LoadProfile(fs fsext.Fs, pwd string) error {
+	if !prc.Profile.Valid || len(prc.Points) > 0 {
+		return nil
+	}
+
+	path := prc.Profile.String
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(pwd, path)
+	}
+	data, err := fsext.ReadFile(fs, path)
+	if err != nil {
+		return fmt.Errorf("couldn't read the profile of scenario %s: %w", prc.Name, err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		prc.Points, err = parseCSVProfile(data, prc.Interval.TimeDuration())
+	case ".json":
+		prc.Points, err = parseJSONProfile(data, prc.Interval.TimeDuration())
+	default:
+		err = fmt.Errorf("unsupported file type '%s', only CSV and JSON files can be used", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid profile '%s' of scenario %s: %w", prc.Profile.String, prc.Name, err)
+	}
+	return nil
+}
+
+func parseCSVProfile(data []byte, interval time.Duration) ([]ProfilePoint, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]ProfilePoint, 0, len(records))
+	var times []time.Time
+	for i, record := range records {
+		if len(record) < 1 || len(record) > 2 {
+			return nil, fmt.Errorf("row %d should have a rate, optionally preceded by a time", i+1)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(record[len(record)-1]), 64)
+		if err != nil {
+			if i == 0 {
+				continue // the header
+			}
+			return nil, fmt.Errorf("invalid rate in row %d: %w", i+1, err)
+		}
+
+		point := ProfilePoint{Time: types.Duration(time.Duration(len(points)) * interval), Rate: rate}
+		if len(record) == 2 {
+			offset, timestamp, err := parseProfileTime(strings.TrimSpace(record[0]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid time in row %d: %w", i+1, err)
+			}
+			if !timestamp.IsZero() {
+				times = append(times, timestamp)
+				offset = timestamp.Sub(times[0])
+			}
+			point.Time = types.Duration(offset)
+		}
+		points = append(points, point)
+	}
+
+	// the times are relative to the first point
+	for i := len(points) - 1; i >= 0; i-- {
+		points[i].Time -= points[0].Time
+	}
+	return points, nil
+}
+
+// parseProfileTime parses a time of a CSV profile, which can be a number of
+// seconds, a duration or an RFC3339 timestamp.
+func parseProfileTime(s string) (time.Duration, time.Time, error) {
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), time.Time{}, nil
+	}
+	if timestamp, err := time.Parse(time.RFC3339, s); err == nil {
+		return 0, timestamp, nil
+	}
+	d, err := types.ParseExtendedDuration(s)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("'%s' isn't a number of seconds, a duration or an RFC3339 timestamp", s)
+	}
+	return d, time.Time{}, nil
+}
+
+func parseJSONProfile(data []byte, interval time.Duration) ([]ProfilePoint, error) {
+	var rates []float64
+	if err := json.Unmarshal(data, &rates); err == nil {
+		points := make([]ProfilePoint, len(rates))
+		for i, rate := range rates {
+			points[i] = ProfilePoint{Time: types.Duration(time.Duration(i) * interval), Rate: rate}
+		}
+		return points, nil
+	}
+
+	var points []ProfilePoint
+	if err := json.Unmarshal(data, &points); err != nil {
+		return nil, fmt.Errorf("it should be an array of rates or of points with a time and a rate: %w", err)
+	}
+	return points, nil
+}
@@ -0,0 +1,185 @@
+package executor
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+func TestProfileReplayLoadProfile(t *testing.T) {
+	t.Parallel()
+
+	points := func(values ...float64) []ProfilePoint {
+		result := make([]ProfilePoint, 0, len(values)/2)
+		for i := 0; i < len(values); i += 2 {
+			result = append(result, ProfilePoint{
+				Time: types.Duration(time.Duration(values[i] * float64(time.Second))),
+				Rate: values[i+1],
+			})
+		}
+		return result
+	}
+
+	testCases := []struct {
+		name, file, data string
+		expPoints        []ProfilePoint
+		expErr           string
+	}{
+		{
+			name: "csv rates", file: "profile.csv", data: "rps\n10\n20.5\n30\n",
+			expPoints: points(0, 10, 2, 20.5, 4, 30),
+		},
+		{
+			name: "csv seconds", file: "profile.csv", data: "time,rps\n100,10\n101.5,20\n",
+			expPoints: points(0, 10, 1.5, 20),
+		},
+		{
+			name: "csv durations", file: "profile.csv", data: "0s,10\n1m,20\n",
+			expPoints: points(0, 10, 60, 20),
+		},
+		{
+			name: "csv timestamps", file: "/data/profile.CSV",
+			data:      "2023-01-02T10:00:00Z,5\n2023-01-02T10:00:30Z,15\n2023-01-02T10:01:00Z,0\n",
+			expPoints: points(0, 5, 30, 15, 60, 0),
+		},
+		{
+			name: "csv invalid rate", file: "profile.csv", data: "rps\n10\nten\n",
+			expErr: "invalid rate in row 3",
+		},
+		{
+			name: "csv invalid time", file: "profile.csv", data: "yesterday,10\n",
+			expErr: "invalid time in row 1",
+		},
+		{
+			name: "json rates", file: "profile.json", data: "[1, 2, 3]",
+			expPoints: points(0, 1, 2, 2, 4, 3),
+		},
+		{
+			name: "json points", file: "profile.json", data: `[{"time": 0, "rate": 1}, {"time": "90s", "rate": 2}]`,
+			expPoints: points(0, 1, 90, 2),
+		},
+		{
+			name: "json invalid", file: "profile.json", data: `{"rate": 1}`,
+			expErr: "it should be an array of rates or of points",
+		},
+		{
+			name: "unsupported", file: "profile.txt", data: "10",
+			expErr: "unsupported file type '.txt'",
+		},
+		{
+			name: "missing", file: "missing.csv",
+			expErr: "couldn't read the profile of scenario test",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fs := fsext.NewMemMapFs()
+			if tc.data != "" {
+				path := tc.file
+				if !filepath.IsAbs(path) {
+					path = filepath.Join("/data", path)
+				}
+				require.NoError(t, fsext.WriteFile(fs, path, []byte(tc.data), 0o644))
+			}
+			config := NewProfileReplayConfig("test")
+			config.Profile = null.StringFrom(tc.file)
+			config.Interval = types.NullDurationFrom(2 * time.Second)
+
+			err := config.LoadProfile(fs, "/data")
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expPoints, config.Points)
+		})
+	}
+}
+
+func TestProfileReplayLoadedProfile(t *testing.T) {
+	t.Parallel()
+
+	// the points are already loaded, for example from an archive, so the
+	// missing file isn't read again
+	config := NewProfileReplayConfig("test")
+	config.Profile = null.StringFrom("missing.csv")
+	config.Points = []ProfilePoint{{Rate: 1}, {Time: types.Duration(time.Second), Rate: 2}}
+	require.NoError(t, config.LoadProfile(fsext.NewMemMapFs(), "/"))
+	assert.Len(t, config.Points, 2)
+}
+
+func TestProfileReplayRampingArrivalRateConfig(t *testing.T) {
+	t.Parallel()
+
+	config := NewProfileReplayConfig("test")
+	config.Points = []ProfilePoint{
+		{Time: 0, Rate: 10},
+		{Time: types.Duration(10 * time.Second), Rate: 20.25},
+		{Time: types.Duration(30 * time.Second), Rate: 0},
+	}
+	config.TimeScale = null.FloatFrom(0.5)
+	config.RateScale = null.FloatFrom(2)
+	config.PreAllocatedVUs = null.IntFrom(5)
+	require.Empty(t, config.Validate())
+
+	rarc := config.getRampingArrivalRateConfig()
+	assert.Equal(t, config.BaseConfig, rarc.BaseConfig)
+	assert.Equal(t, null.IntFrom(20000), rarc.StartRate)
+	assert.Equal(t, []Stage{
+		{Duration: types.NullDurationFrom(5 * time.Second), Target: null.IntFrom(40500)},
+		{Duration: types.NullDurationFrom(10 * time.Second), Target: null.IntFrom(0)},
+	}, rarc.Stages)
+
+	et := mustNewExecutionTuple(nil, nil)
+	assert.Equal(t, "Up to 40.50 iterations/s for 15s, replaying 3 profile points "+
+		"(maxVUs: 5, gracefulStop: 30s)", config.GetDescription(et))
+	endOffset, isFinal := lib.GetEndOffset(config.GetExecutionRequirements(et))
+	assert.True(t, isFinal)
+	assert.Equal(t, 15*time.Second+30*time.Second, endOffset)
+}
+
+func TestProfileReplayRun(t *testing.T) {
+	t.Parallel()
+
+	var count int64
+	runner := simpleRunner(func(ctx context.Context, _ *lib.State) error {
+		atomic.AddInt64(&count, 1)
+		return nil
+	})
+
+	config := NewProfileReplayConfig("test")
+	config.Points = []ProfilePoint{
+		{Time: 0, Rate: 10},
+		{Time: types.Duration(2 * time.Second), Rate: 10},
+		{Time: types.Duration(4 * time.Second), Rate: 30},
+	}
+	config.TimeScale = null.FloatFrom(0.5)
+	config.PreAllocatedVUs = null.IntFrom(5)
+	require.Empty(t, config.Validate())
+
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+	defer test.cancel()
+
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	start := time.Now()
+	require.NoError(t, test.executor.Run(test.ctx, engineOut))
+	assert.InDelta(t, 2*time.Second, time.Since(start), float64(200*time.Millisecond))
+	// 10 iterations in the first second and 20 in the second one
+	assert.InDelta(t, 30, atomic.LoadInt64(&count), 2)
+	require.Empty(t, test.logHook.Drain())
+}
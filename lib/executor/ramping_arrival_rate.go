@@ -30,6 +30,33 @@ func init() {
 	)
 }
 
+// RateCurve is the shape of the transition between the
+// arrival rates of two consecutive stages.
+//
+//go:generate enumer -type=RateCurve -transform=snake -trimprefix RateCurve -text -output rate_curve_gen.go
+type RateCurve uint8
+
+const (
+	// RateCurveLinear changes the rate by the same amount every second.
+	RateCurveLinear RateCurve = iota
+	// RateCurveSine changes the rate slowly at the start and the end of the
+	// stage and faster in the middle, following a half period of a cosine.
+	RateCurveSine
+	// RateCurveExponential changes the rate by the same factor every second,
+	// the changes from or to a zero rate are linear.
+	RateCurveExponential
+	// RateCurveStep jumps to the target rate at the start of the stage.
+	RateCurveStep
+	// RateCurveFunction uses the exported function set in curveFunction,
+	// called for every second of the stage with its progress, from 0 to 1. It
+	// returns the fraction of the change between the two rates at that moment.
+	RateCurveFunction
+)
+
+// curveSteps is the number of linear pieces that approximate the
+// sine and exponential curves for every stage.
+const curveSteps = 100
+
 // RampingArrivalRateConfig stores config for the ramping (i.e. variable)
 // arrival-rate executor.
 type RampingArrivalRateConfig struct {
@@ -38,11 +65,19 @@ type RampingArrivalRateConfig struct {
 	TimeUnit  types.NullDuration `json:"timeUnit"`
 	Stages    []Stage            `json:"stages"`
 
+	// Curve is the shape of the transitions between the rates of the stages.
+	Curve         RateCurve   `json:"curve"`
+	CurveFunction null.String `json:"curveFunction"`
+
 	// Initialize `PreAllocatedVUs` number of VUs, and if more than that are needed,
 	// they will be dynamically allocated, until `MaxVUs` is reached, which is an
 	// absolutely hard limit on the number of VUs the executor will use
 	PreAllocatedVUs null.Int `json:"preAllocatedVUs"`
 	MaxVUs          null.Int `json:"maxVUs"`
+
+	// curveValues are the results of the curve function for every
+	// stage, evaluated by the executor when it's initialized.
+	curveValues [][]float64
 }
 
 // NewRampingArrivalRateConfig returns a RampingArrivalRateConfig with default values
@@ -97,6 +132,14 @@ func (varc *RampingArrivalRateConfig) Validate() []error {
 
 	errors = append(errors, validateStages(varc.Stages)...)
 
+	if !varc.Curve.IsARateCurve() {
+		errors = append(errors, fmt.Errorf("invalid curve %s", varc.Curve))
+	} else if varc.Curve == RateCurveFunction && varc.CurveFunction.String == "" {
+		errors = append(errors, fmt.Errorf("the curveFunction has to be specified for the function curve"))
+	} else if varc.Curve != RateCurveFunction && varc.CurveFunction.Valid {
+		errors = append(errors, fmt.Errorf("the curveFunction can only be used with the function curve"))
+	}
+
 	if !varc.PreAllocatedVUs.Valid {
 		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs isn't specified"))
 	} else if varc.PreAllocatedVUs.Int64 < 0 {
@@ -167,8 +210,143 @@ func (varr *RampingArrivalRate) Init(ctx context.Context) error {
 	et, err := varr.BaseExecutor.executionState.ExecutionTuple.GetNewExecutionTupleFromValue(varr.config.MaxVUs.Int64)
 	varr.et = et
 	varr.iterSegIndex = lib.NewSegmentedIndex(et)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if varr.config.Curve == RateCurveFunction {
+		return varr.evaluateCurveFunction(ctx)
+	}
+	return nil
+}
+
+// evaluateCurveFunction calls the curve function of the script for every
+// second of every stage, so the rates are known before the test starts.
+func (varr *RampingArrivalRate) evaluateCurveFunction(ctx context.Context) error {
+	evaluator, ok := varr.executionState.Test.Runner.(lib.FunctionEvaluator)
+	if !ok {
+		return fmt.Errorf("the %s curve isn't supported by the test runner", varr.config.Curve)
+	}
+
+	var args []float64
+	for _, stage := range varr.config.Stages {
+		args = append(args, getCurveProgress(stage.Duration.TimeDuration(), time.Second)...)
+	}
+	results, err := evaluator.EvaluateFunction(ctx, varr.config.CurveFunction.String, args)
+	if err != nil {
+		return fmt.Errorf("unable to evaluate the curve function of scenario %s: %w", varr.config.Name, err)
+	}
 
-	return err //nolint:wrapcheck
+	varr.config.curveValues = make([][]float64, len(varr.config.Stages))
+	for i, stage := range varr.config.Stages {
+		n := len(getCurveProgress(stage.Duration.TimeDuration(), time.Second))
+		varr.config.curveValues[i], results = results[:n], results[n:]
+	}
+	return nil
+}
+
+// getCurveProgress returns the progress of a stage with the given duration,
+// from 0 to 1, at the end of every step with the given length. The last
+// step can be shorter.
+func getCurveProgress(duration, step time.Duration) []float64 {
+	var progress []float64
+	for end := step; end-step < duration; end += step {
+		if end > duration {
+			end = duration
+		}
+		progress = append(progress, float64(end)/float64(duration))
+	}
+	return progress
+}
+
+// ratePiece is a part of the test during which the
+// arrival rate changes linearly, up to the target.
+type ratePiece struct {
+	duration time.Duration
+	target   float64
+}
+
+// getRatePieces returns the linear pieces that follow the curve of the
+// rates of the stages, the non-linear curves are approximated by many short
+// pieces. The targets are unscaled rates per time unit.
+func (varc RampingArrivalRateConfig) getRatePieces() []ratePiece {
+	pieces := make([]ratePiece, 0, len(varc.Stages))
+	from := float64(varc.StartRate.ValueOrZero())
+	for i, stage := range varc.Stages {
+		to := float64(stage.Target.ValueOrZero())
+		duration := stage.Duration.TimeDuration()
+
+		switch {
+		case varc.Curve == RateCurveStep:
+			pieces = append(pieces, ratePiece{target: to}, ratePiece{duration: duration, target: to})
+		case varc.Curve == RateCurveLinear || from == to || duration <= 0:
+			pieces = append(pieces, ratePiece{duration: duration, target: to})
+		case varc.Curve == RateCurveFunction:
+			var values []float64
+			if i < len(varc.curveValues) {
+				values = varc.curveValues[i]
+			}
+			pieces = appendCurvePieces(pieces, duration, time.Second, func(j int, _ float64) float64 {
+				if j >= len(values) {
+					return to
+				}
+				return from + (to-from)*values[j]
+			})
+		default:
+			curve := varc.Curve
+			pieces = appendCurvePieces(pieces, duration, duration/curveSteps, func(_ int, p float64) float64 {
+				return getCurveRate(curve, from, to, p)
+			})
+		}
+		from = to
+	}
+	return pieces
+}
+
+// appendCurvePieces splits the stage in pieces with the given length, the
+// rate at the end of each of them is returned by the rate function.
+func appendCurvePieces(
+	pieces []ratePiece, duration, step time.Duration, rate func(i int, progress float64) float64,
+) []ratePiece {
+	if step <= 0 {
+		step = duration
+	}
+	var elapsed time.Duration
+	for i := 0; elapsed < duration; i++ {
+		end := time.Duration(i+1) * step
+		if end > duration {
+			end = duration
+		}
+		progress := float64(end) / float64(duration)
+		pieces = append(pieces, ratePiece{duration: end - elapsed, target: math.Max(0, rate(i, progress))})
+		elapsed = end
+	}
+	return pieces
+}
+
+// getCurveRate returns the rate at the given progress of a stage,
+// from 0 to 1, that changes the rate with the sine or exponential curve.
+func getCurveRate(curve RateCurve, from, to, progress float64) float64 {
+	switch {
+	case curve == RateCurveSine:
+		return from + (to-from)*(1-math.Cos(math.Pi*progress))/2
+	case curve == RateCurveExponential && from > 0 && to > 0:
+		return from * math.Pow(to/from, progress)
+	default:
+		return from + (to-from)*progress
+	}
+}
+
+// getRatePiecesUnscaledMaxTarget returns the maximum rate of the pieces,
+// which can be more than the targets of the stages with the function curve.
+func getRatePiecesUnscaledMaxTarget(unscaledStartValue int64, pieces []ratePiece) int64 {
+	max := unscaledStartValue
+	for _, piece := range pieces {
+		if target := int64(math.Ceil(piece.target)); target > max {
+			max = target
+		}
+	}
+	return max
 }
 
 // cal calculates the  transtitions between stages and gives the next full value produced by the
@@ -228,6 +406,9 @@ func (varr *RampingArrivalRate) Init(ctx context.Context) error {
 // stage will do some given amount (the area of the stage) events and if we past that one we
 // know we are not in that stage.
 //
+// The other curves are approximated by splitting their stages in many short
+// linear pieces, see getRatePieces.
+//
 // The specific implementation here can only go forward and does incorporate
 // the striping algorithm from the lib.ExecutionTuple for additional speed up but this could
 // possibly be refactored if need for this arises.
@@ -249,9 +430,9 @@ func (varc RampingArrivalRateConfig) cal(et *lib.ExecutionTuple, ch chan<- time.
 		i = float64(start + 1)
 	)
 
-	for _, stage := range varc.Stages {
-		to = float64(stage.Target.ValueOrZero()) / timeUnit
-		dur = float64(stage.Duration.Duration)
+	for _, piece := range varc.getRatePieces() {
+		to = piece.target / timeUnit
+		dur = float64(piece.duration)
 		if from != to { // ramp up/down
 			endCount += dur * ((to-from)/2 + from)
 			for ; i <= endCount; i += float64(next()) {
@@ -269,7 +450,7 @@ func (varc RampingArrivalRateConfig) cal(et *lib.ExecutionTuple, ch chan<- time.
 		}
 		doneSoFar = endCount
 		from = to
-		stageStart += stage.Duration.TimeDuration()
+		stageStart += piece.duration
 	}
 }
 
@@ -312,7 +493,7 @@ func (varr RampingArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 	// TODO: refactor and simplify
 	timeUnit := varr.config.TimeUnit.TimeDuration()
 	startArrivalRate := getScaledArrivalRate(segment, varr.config.StartRate.Int64, timeUnit)
-	maxUnscaledRate := getStagesUnscaledMaxTarget(varr.config.StartRate.Int64, varr.config.Stages)
+	maxUnscaledRate := getRatePiecesUnscaledMaxTarget(varr.config.StartRate.Int64, varr.config.getRatePieces())
 	maxArrivalRatePerSec, _ := getArrivalRatePerSec(getScaledArrivalRate(segment, maxUnscaledRate, timeUnit)).Float64()
 	startTickerPeriod := getTickerPeriod(startArrivalRate)
 
@@ -328,7 +509,7 @@ func (varr RampingArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 	waitOnProgressChannel := make(chan struct{})
 	startTime, maxDurationCtx, regDurationCtx, cancel := getDurationContexts(parentCtx, duration, gracefulStop)
 
-	vusPool := newActiveVUPool(varr.executionState)
+	vusPool := newActiveVUPool(varr.executionState, varr.config.Name)
 
 	defer func() {
 		// Make sure all VUs aren't executing iterations anymore, for the cancel()
@@ -501,14 +682,16 @@ type activeVUPool struct {
 	iterations chan struct{}
 	running    uint64
 	execState  *lib.ExecutionState
+	scenario   string
 	wg         sync.WaitGroup
 }
 
 // newActiveVUPool returns an activeVUPool.
-func newActiveVUPool(es *lib.ExecutionState) *activeVUPool {
+func newActiveVUPool(es *lib.ExecutionState, scenario string) *activeVUPool {
 	return &activeVUPool{
 		iterations: make(chan struct{}),
 		execState:  es,
+		scenario:   scenario,
 	}
 }
 
@@ -543,7 +726,9 @@ func (p *activeVUPool) AddVU(ctx context.Context, avu lib.ActiveVU, runfn func(c
 		for range p.iterations {
 			atomic.AddUint64(&p.running, uint64(1))
 			p.execState.ModCurrentlyActiveVUsCount(+1)
+			p.execState.ModScenarioActiveVUsCount(p.scenario, +1)
 			runfn(ctx, avu)
+			p.execState.ModScenarioActiveVUsCount(p.scenario, -1)
 			p.execState.ModCurrentlyActiveVUsCount(-1)
 			atomic.AddUint64(&p.running, ^uint64(0))
 		}
@@ -15,6 +15,7 @@ import (
 	"gopkg.in/guregu/null.v3"
 
 	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/minirunner"
 	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 )
@@ -430,6 +431,116 @@ func TestRampingArrivalRateCal(t *testing.T) {
 	}
 }
 
+func TestRampingArrivalRateCalCurves(t *testing.T) {
+	t.Parallel()
+
+	stage := func(duration time.Duration, target int64) Stage {
+		return Stage{Duration: types.NullDurationFrom(duration), Target: null.IntFrom(target)}
+	}
+	testCases := map[string]struct {
+		config        RampingArrivalRateConfig
+		expIterations int
+		expFirstTime  time.Duration
+	}{
+		"linear": {
+			config: RampingArrivalRateConfig{
+				Stages: []Stage{stage(10*time.Second, 10)},
+			},
+			expIterations: 50,
+			expFirstTime:  1414 * time.Millisecond, // sqrt(2)
+		},
+		"sine": {
+			config: RampingArrivalRateConfig{
+				Curve:  RateCurveSine,
+				Stages: []Stage{stage(10*time.Second, 10)},
+			},
+			expIterations: 50,
+			expFirstTime:  2319 * time.Millisecond,
+		},
+		"exponential": {
+			config: RampingArrivalRateConfig{
+				Curve:     RateCurveExponential,
+				StartRate: null.IntFrom(1),
+				Stages:    []Stage{stage(10*time.Second, 10)},
+			},
+			expIterations: 39, // 9 * 10 / ln(10)
+			expFirstTime:  900 * time.Millisecond,
+		},
+		"step": {
+			config: RampingArrivalRateConfig{
+				Curve:  RateCurveStep,
+				Stages: []Stage{stage(5*time.Second, 10), stage(5*time.Second, 20)},
+			},
+			expIterations: 150,
+			expFirstTime:  100 * time.Millisecond,
+		},
+		"function": {
+			config: RampingArrivalRateConfig{
+				Curve:         RateCurveFunction,
+				CurveFunction: null.StringFrom("rate"),
+				Stages:        []Stage{stage(5*time.Second, 10)},
+				curveValues:   [][]float64{{1, 1, 1, 1, 1}},
+			},
+			expIterations: 45,
+			expFirstTime:  447 * time.Millisecond, // sqrt(0.2)
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tc.config.TimeUnit = types.NullDurationFrom(time.Second)
+			ch := make(chan time.Duration)
+			go tc.config.cal(mustNewExecutionTuple(nil, nil), ch)
+			var times []time.Duration
+			for c := range ch {
+				times = append(times, c)
+			}
+
+			require.Len(t, times, tc.expIterations)
+			assert.InDelta(t, tc.expFirstTime, times[0], float64(5*time.Millisecond))
+			for i := 1; i < len(times); i++ {
+				require.GreaterOrEqual(t, times[i], times[i-1])
+			}
+			assert.LessOrEqual(t, times[len(times)-1], sumStagesDuration(tc.config.Stages))
+		})
+	}
+}
+
+func TestRampingArrivalRateCurveFunction(t *testing.T) {
+	t.Parallel()
+
+	var args []float64
+	runner := &minirunner.MiniRunner{
+		EvaluateFn: func(_ context.Context, name string, arg float64) (float64, error) {
+			assert.Equal(t, "rate", name)
+			args = append(args, arg)
+			return arg * arg, nil
+		},
+	}
+
+	config := &RampingArrivalRateConfig{
+		BaseConfig:      BaseConfig{GracefulStop: types.NullDurationFrom(0)},
+		TimeUnit:        types.NullDurationFrom(time.Second),
+		Curve:           RateCurveFunction,
+		CurveFunction:   null.StringFrom("rate"),
+		Stages:          []Stage{{Duration: types.NullDurationFrom(2500 * time.Millisecond), Target: null.IntFrom(10)}},
+		PreAllocatedVUs: null.IntFrom(1),
+		MaxVUs:          null.IntFrom(1),
+	}
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+	defer test.cancel()
+
+	assert.Equal(t, []float64{0.4, 0.8, 1}, args)
+	pieces := test.executor.(*RampingArrivalRate).config.getRatePieces()
+	require.Len(t, pieces, 3)
+	assert.InDelta(t, 1.6, pieces[0].target, 0.0001)
+	assert.InDelta(t, 6.4, pieces[1].target, 0.0001)
+	assert.Equal(t, ratePiece{duration: 500 * time.Millisecond, target: 10}, pieces[2])
+}
+
 func BenchmarkCal(b *testing.B) {
 	for _, t := range []time.Duration{
 		time.Second, time.Minute,
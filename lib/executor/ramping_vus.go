@@ -10,6 +10,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"gopkg.in/guregu/null.v3"
 
+	"go.k6.io/k6/execution"
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
@@ -544,10 +545,20 @@ func (vlv *RampingVUs) Run(ctx context.Context, _ chan<- metrics.SampleContainer
 		handleNewScheduledVUs  = runState.scheduledVUsHandlerStrategy()
 	)
 	handledGracefulSteps := runState.iterateSteps(
-		ctx,
+		withAbortDrain(ctx),
 		handleNewMaxAllowedVUs,
 		handleNewScheduledVUs,
 	)
+	select {
+	case <-execution.GetAbortDrainSignal(ctx):
+		// The test run was aborted, so the VUs stop after their current
+		// iterations, unless they're interrupted by the end of ctx before that.
+		for _, handle := range runState.vuHandles {
+			handle.gracefulStop()
+		}
+		return nil
+	default:
+	}
 	go runState.runRemainingGracefulSteps(
 		ctx,
 		handleNewMaxAllowedVUs,
@@ -597,6 +608,7 @@ func (rs *rampingVUsRunState) runLoopsIfPossible(ctx context.Context, cancel fun
 		rs.wg.Add(1)
 		atomic.AddInt64(rs.activeVUsCount, 1)
 		rs.executor.executionState.ModCurrentlyActiveVUsCount(+1)
+		rs.executor.executionState.ModScenarioActiveVUsCount(rs.executor.config.Name, +1)
 		return pvu, err
 	}
 	returnVU := func(initVU lib.InitializedVU) {
@@ -604,6 +616,7 @@ func (rs *rampingVUsRunState) runLoopsIfPossible(ctx context.Context, cancel fun
 		atomic.AddInt64(rs.activeVUsCount, -1)
 		rs.wg.Done()
 		rs.executor.executionState.ModCurrentlyActiveVUsCount(-1)
+		rs.executor.executionState.ModScenarioActiveVUsCount(rs.executor.config.Name, -1)
 	}
 	for i := uint64(0); i < rs.maxVUs; i++ {
 		rs.vuHandles[i] = newStoppedVUHandle(
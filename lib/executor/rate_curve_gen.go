@@ -0,0 +1,64 @@
+// Code generated by "enumer -type=RateCurve -transform=snake -trimprefix RateCurve -text -output rate_curve_gen.go"; DO NOT EDIT.
+
+package executor
+
+import (
+	"fmt"
+)
+
+const _RateCurveName = "linearsineexponentialstepfunction"
+
+var _RateCurveIndex = [...]uint8{0, 6, 10, 21, 25, 33}
+
+func (i RateCurve) String() string {
+	if i >= RateCurve(len(_RateCurveIndex)-1) {
+		return fmt.Sprintf("RateCurve(%d)", i)
+	}
+	return _RateCurveName[_RateCurveIndex[i]:_RateCurveIndex[i+1]]
+}
+
+var _RateCurveValues = []RateCurve{0, 1, 2, 3, 4}
+
+var _RateCurveNameToValueMap = map[string]RateCurve{
+	_RateCurveName[0:6]:   0,
+	_RateCurveName[6:10]:  1,
+	_RateCurveName[10:21]: 2,
+	_RateCurveName[21:25]: 3,
+	_RateCurveName[25:33]: 4,
+}
+
+// RateCurveString retrieves an enum value from the enum constants string name.
+// Throws an error if the param is not part of the enum.
+func RateCurveString(s string) (RateCurve, error) {
+	if val, ok := _RateCurveNameToValueMap[s]; ok {
+		return val, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to RateCurve values", s)
+}
+
+// RateCurveValues returns all values of the enum
+func RateCurveValues() []RateCurve {
+	return _RateCurveValues
+}
+
+// IsARateCurve returns "true" if the value is listed in the enum definition. "false" otherwise
+func (i RateCurve) IsARateCurve() bool {
+	for _, v := range _RateCurveValues {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for RateCurve
+func (i RateCurve) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for RateCurve
+func (i *RateCurve) UnmarshalText(text []byte) error {
+	var err error
+	*i, err = RateCurveString(string(text))
+	return err
+}
@@ -233,6 +233,7 @@ func (si SharedIterations) Run(parentCtx context.Context, out chan<- metrics.Sam
 
 	returnVU := func(u lib.InitializedVU) {
 		si.executionState.ReturnVU(u, true)
+		si.executionState.ModScenarioActiveVUsCount(si.config.Name, -1)
 		activeVUs.Done()
 	}
 
@@ -267,6 +268,7 @@ func (si SharedIterations) Run(parentCtx context.Context, out chan<- metrics.Sam
 			cancel()
 			return err
 		}
+		si.executionState.ModScenarioActiveVUsCount(si.config.Name, +1)
 		activeVUs.Add(1)
 		go handleVU(initVU)
 	}
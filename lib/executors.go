@@ -63,6 +63,12 @@ type ExecutorConfig interface {
 	GetName() string
 	GetType() string
 	GetStartTime() time.Duration
+	// Returns the names of the scenarios that have to finish before the
+	// executor starts, if any. Its start time is then relative to their end.
+	GetStartAfter() []string
+	// Returns whether the executor waits to be started manually during the
+	// test run, instead of starting on its own.
+	IsDormant() bool
 	GetGracefulStop() time.Duration
 
 	// This is used to validate whether a particular script can run in the cloud
@@ -130,11 +136,21 @@ type PausableExecutor interface {
 
 // LiveUpdatableExecutor should be implemented for the executors whose
 // configuration can be modified in the middle of the test execution. Currently,
-// only the manual execution executor implements it.
+// only the externally controlled and the constant arrival rate executors
+// implement it.
 type LiveUpdatableExecutor interface {
 	UpdateConfig(ctx context.Context, newConfig interface{}) error
 }
 
+// SegmentUpdatableExecutor should be implemented for the executors whose
+// execution segment can be changed in the middle of the test execution, e.g.
+// when the instances of a distributed test are rebalanced. Currently, only the
+// externally controlled executor implements it, the execution segment of the
+// tests with any other executors can't be changed.
+type SegmentUpdatableExecutor interface {
+	UpdateExecutionTuple(ctx context.Context, et *ExecutionTuple) error
+}
+
 // ExecutorConfigConstructor is a simple function that returns a concrete
 // Config instance with the specified name and all default values correctly
 // initialized
@@ -197,15 +213,141 @@ func (scs *ScenarioConfigs) UnmarshalJSON(data []byte) error {
 
 // Validate checks if all of the specified executor options make sense
 func (scs ScenarioConfigs) Validate() (errors []error) {
+	hasActive := false
 	for name, exec := range scs {
 		if execErr := exec.Validate(); len(execErr) != 0 {
 			errors = append(errors,
 				fmt.Errorf("scenario %s has configuration errors: %s", name, ConcatErrors(execErr, ", ")))
 		}
+		for _, dep := range exec.GetStartAfter() {
+			depConfig, ok := scs[dep]
+			switch {
+			case !ok && dep != "":
+				errors = append(errors, fmt.Errorf("scenario %s starts after the unknown scenario '%s'", name, dep))
+			case ok && depConfig.IsDormant():
+				errors = append(errors, fmt.Errorf("scenario %s can't start after the dormant scenario '%s'", name, dep))
+			}
+		}
+		if !exec.IsDormant() {
+			hasActive = true
+		}
+	}
+	if len(scs) > 0 && !hasActive {
+		errors = append(errors, fmt.Errorf("at least one scenario has to not be dormant"))
+	}
+	if cycle := scs.findStartAfterCycle(); len(cycle) > 0 {
+		errors = append(errors, fmt.Errorf("the scenarios %s depend on each other with startAfter",
+			strings.Join(cycle, " -> ")))
 	}
 	return errors
 }
 
+// findStartAfterCycle returns the names of the scenarios in a startAfter
+// dependency cycle, if there is one.
+func (scs ScenarioConfigs) findStartAfterCycle() []string {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	states := make(map[string]int, len(scs))
+	var path []string
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch states[name] {
+		case visited:
+			return nil
+		case visiting:
+			for i, n := range path {
+				if n == name {
+					return append(append([]string{}, path[i:]...), name)
+				}
+			}
+		}
+		config, ok := scs[name]
+		if !ok {
+			return nil
+		}
+		states[name] = visiting
+		path = append(path, name)
+		for _, dep := range config.GetStartAfter() {
+			if dep == name {
+				continue // self-references are reported by the executor configs
+			}
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		states[name] = visited
+		return nil
+	}
+
+	names := make([]string, 0, len(scs))
+	for name := range scs {
+		names = append(names, name)
+	}
+	sort.Strings(names) // for consistent errors
+	for _, name := range names {
+		if cycle := visit(name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// getStartOffsets returns the earliest and the latest time offsets, relative
+// to the beginning of the test, at which every scenario can start. They differ
+// for the scenarios with startAfter, since the scenarios they wait for can
+// finish before their planned end, e.g. when they run out of iterations. They
+// differ for the dormant scenarios as well, since those can be started at any
+// moment until all of the other scenarios are done.
+func (scs ScenarioConfigs) getStartOffsets(et *ExecutionTuple) (earliest, latest map[string]time.Duration) {
+	earliest = make(map[string]time.Duration, len(scs))
+	latest = make(map[string]time.Duration, len(scs))
+	var calculate func(name string)
+	calculate = func(name string) {
+		if _, ok := latest[name]; ok {
+			return
+		}
+		config := scs[name]
+		// This guards against cycles in invalid configs, the dependencies in
+		// them are ignored.
+		earliest[name], latest[name] = config.GetStartTime(), config.GetStartTime()
+
+		deps := config.GetStartAfter()
+		if config.IsDormant() {
+			deps = nil
+			for depName, depConfig := range scs {
+				if !depConfig.IsDormant() {
+					deps = append(deps, depName)
+				}
+			}
+		}
+
+		var depsEarliestEnd, depsLatestEnd time.Duration
+		for _, dep := range deps {
+			depConfig, ok := scs[dep]
+			if !ok || dep == name {
+				continue
+			}
+			calculate(dep)
+			depEndOffset, _ := GetEndOffset(depConfig.GetExecutionRequirements(et))
+			if earliest[dep] > depsEarliestEnd && !config.IsDormant() {
+				depsEarliestEnd = earliest[dep]
+			}
+			if end := latest[dep] + depEndOffset; end > depsLatestEnd {
+				depsLatestEnd = end
+			}
+		}
+		earliest[name] += depsEarliestEnd
+		latest[name] += depsLatestEnd
+	}
+	for name := range scs {
+		calculate(name)
+	}
+	return earliest, latest
+}
+
 // GetSortedConfigs returns a slice with the executor configurations,
 // sorted in a consistent and predictable manner. It is useful when we want or
 // have to avoid using maps with string keys (and tons of string lookups in
@@ -252,10 +394,29 @@ func (scs ScenarioConfigs) GetFullExecutionRequirements(et *ExecutionTuple) []Ex
 		ExecutionStep
 		configID int
 	}
+	earliestStarts, latestStarts := scs.getStartOffsets(et)
 	trackedSteps := []trackedStep{}
 	for configID, config := range sortedConfigs { // orderly iteration over a slice
-		configStartTime := config.GetStartTime()
+		configStartTime := latestStarts[config.GetName()]
 		configSteps := config.GetExecutionRequirements(et)
+		if earliestStart := earliestStarts[config.GetName()]; earliestStart != configStartTime && len(configSteps) > 0 {
+			// The scenario can start anywhere between its earliest and its
+			// latest start, so its max VUs are reserved for that whole period.
+			endOffset, _ := GetEndOffset(configSteps)
+			lastStep := configSteps[len(configSteps)-1]
+			configSteps = []ExecutionStep{
+				{
+					TimeOffset:      earliestStart - configStartTime,
+					PlannedVUs:      GetMaxPlannedVUs(configSteps),
+					MaxUnplannedVUs: GetMaxPossibleVUs(configSteps) - GetMaxPlannedVUs(configSteps),
+				},
+				{
+					TimeOffset:      endOffset,
+					PlannedVUs:      lastStep.PlannedVUs,
+					MaxUnplannedVUs: lastStep.MaxUnplannedVUs,
+				},
+			}
+		}
 		for _, cs := range configSteps {
 			cs.TimeOffset += configStartTime // add the executor start time to the step time offset
 			trackedSteps = append(trackedSteps, trackedStep{cs, configID})
@@ -0,0 +1,294 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"go.k6.io/k6/lib/consts"
+)
+
+const (
+	harFooter = "\n]}}\n"
+
+	// harQueueSize is the number of entries that can be queued for writing,
+	// the requests are recorded synchronously only when the queue is full.
+	harQueueSize = 1000
+)
+
+var errHARRecorderClosed = errors.New("the HAR recorder is closed")
+
+// HARRecorder writes the HTTP requests of the VUs as the entries of a HAR
+// file, so they can be inspected with the browser devtools or converted back
+// to a script. The entries are queued and written in batches by a separate
+// goroutine, so the requests aren't blocked on the file. The file is rewritten
+// after each batch, so it stays a valid HAR file even if k6 is interrupted.
+type HARRecorder struct {
+	mx     sync.RWMutex // guards closed, no entry is queued after the queue is closed
+	closed bool
+	queue  chan []byte
+	done   chan struct{}
+
+	errMx sync.Mutex
+	err   error // the first error of the writer, the next entries are dropped
+
+	w       io.WriteSeeker
+	closer  io.Closer
+	offset  int64 // where the next entry is written, i.e. the start of the footer
+	entries int
+
+	sampleRate float64
+	tags       map[string]string
+}
+
+// NewHARRecorder creates the HAR file at the provided path and returns a
+// recorder that writes the requests of the sampled iterations to it. Only the
+// requests that have all of the provided tags are recorded. The recorder has
+// to be closed, so all of the recorded entries are written.
+func NewHARRecorder(path string, sampleRate float64, tags map[string]string) (*HARRecorder, error) {
+	f, err := os.Create(path) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	r, err := newHARRecorder(f, sampleRate, tags)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	r.closer = f
+	return r, nil
+}
+
+func newHARRecorder(w io.WriteSeeker, sampleRate float64, tags map[string]string) (*HARRecorder, error) {
+	header, err := json.Marshal(HARCreator{Name: "k6", Version: consts.Version})
+	if err != nil {
+		return nil, err
+	}
+	header = append([]byte(`{"log":{"version":"1.2","creator":`), header...)
+	header = append(header, `,"entries":[`...)
+	if _, err := w.Write(append(header, harFooter...)); err != nil {
+		return nil, err
+	}
+	r := &HARRecorder{
+		queue:      make(chan []byte, harQueueSize),
+		done:       make(chan struct{}),
+		w:          w,
+		offset:     int64(len(header)),
+		sampleRate: sampleRate,
+		tags:       tags,
+	}
+	go r.write()
+	return r, nil
+}
+
+// ShouldRecord returns true if the requests with the provided tags, which are
+// made in the provided iteration of the VU, should be recorded. All requests
+// of an iteration are either sampled or not, so the sampled iterations can be
+// replayed.
+func (r *HARRecorder) ShouldRecord(vuID uint64, iteration int64, tags map[string]string) bool {
+	for k, v := range r.tags {
+		if tags[k] != v {
+			return false
+		}
+	}
+	if r.sampleRate >= 1 {
+		return true
+	}
+
+	var buf [16]byte
+	binary.LittleEndian.PutUint64(buf[:8], vuID)
+	binary.LittleEndian.PutUint64(buf[8:], uint64(iteration))
+	h := fnv.New64a()
+	_, _ = h.Write(buf[:])
+	return float64(h.Sum64()%10000)/10000 < r.sampleRate
+}
+
+// Record queues the entry to be written to the HAR file. It returns the error
+// of a previous write, if any, since the file can't be written anymore.
+func (r *HARRecorder) Record(entry *HAREntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+	if r.closed {
+		return errHARRecorderClosed
+	}
+	if err := r.writeErr(); err != nil {
+		return err
+	}
+	r.queue <- data
+	return nil
+}
+
+// Close writes the queued entries and closes the HAR file.
+func (r *HARRecorder) Close() error {
+	r.mx.Lock()
+	if r.closed {
+		r.mx.Unlock()
+		return nil
+	}
+	r.closed = true
+	close(r.queue)
+	r.mx.Unlock()
+
+	<-r.done
+	err := r.writeErr()
+	if r.closer != nil {
+		if cerr := r.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// write writes the queued entries until the queue is closed. All of the
+// entries which are queued while a batch is written are written at once.
+func (r *HARRecorder) write() {
+	defer close(r.done)
+	for data := range r.queue {
+		batch := [][]byte{data}
+	queued:
+		for {
+			select {
+			case data, ok := <-r.queue:
+				if !ok {
+					break queued
+				}
+				batch = append(batch, data)
+			default:
+				break queued
+			}
+		}
+
+		if r.writeErr() != nil {
+			continue
+		}
+		if err := r.writeBatch(batch); err != nil {
+			r.errMx.Lock()
+			r.err = err
+			r.errMx.Unlock()
+		}
+	}
+}
+
+func (r *HARRecorder) writeBatch(batch [][]byte) error {
+	var buf bytes.Buffer
+	for _, data := range batch {
+		if r.entries > 0 {
+			buf.WriteString(",\n")
+		} else {
+			buf.WriteString("\n")
+		}
+		buf.Write(data)
+		r.entries++
+	}
+	n := buf.Len()
+	buf.WriteString(harFooter)
+
+	if _, err := r.w.Seek(r.offset, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := r.w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	r.offset += int64(n)
+	return nil
+}
+
+func (r *HARRecorder) writeErr() error {
+	r.errMx.Lock()
+	defer r.errMx.Unlock()
+	return r.err
+}
+
+// HARCreator is the application that has created a HAR file.
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry is a request and its response in a HAR file. Besides the standard
+// fields, it has the VU, the iteration and the tags of the request, and the
+// error if the request has failed.
+type HAREntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+
+	VU        uint64            `json:"_vu"`
+	Iteration int64             `json:"_iteration"`
+	Tags      map[string]string `json:"_tags,omitempty"`
+	Error     string            `json:"_error,omitempty"`
+}
+
+// HARRequest is the request of a HAR entry.
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+// HARResponse is the response of a HAR entry.
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+// HARNameValue is a header, a cookie or a query string parameter.
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData is the body of a request.
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARContent is the body of a response, the text is base64 encoded if it
+// isn't valid UTF-8.
+type HARContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HARTimings are the durations of the phases of a request in milliseconds,
+// -1 is used for the ones that don't apply.
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
@@ -0,0 +1,77 @@
+package lib
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHARRecorder(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "out.har")
+	r, err := NewHARRecorder(path, 1, nil)
+	require.NoError(t, err)
+
+	readHAR := func() []map[string]interface{} {
+		data, err := os.ReadFile(path) //nolint:gosec
+		require.NoError(t, err)
+		var har struct {
+			Log struct {
+				Version string                   `json:"version"`
+				Creator HARCreator               `json:"creator"`
+				Entries []map[string]interface{} `json:"entries"`
+			} `json:"log"`
+		}
+		require.NoError(t, json.Unmarshal(data, &har))
+		assert.Equal(t, "1.2", har.Log.Version)
+		assert.Equal(t, "k6", har.Log.Creator.Name)
+		return har.Log.Entries
+	}
+
+	assert.Empty(t, readHAR())
+	require.NoError(t, r.Record(&HAREntry{Request: HARRequest{URL: "https://example.com/1"}, VU: 1}))
+	require.NoError(t, r.Record(&HAREntry{Request: HARRequest{URL: "https://example.com/2"}, Error: "failed"}))
+	require.NoError(t, r.Close())
+
+	entries := readHAR()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "https://example.com/1", entries[0]["request"].(map[string]interface{})["url"])
+	assert.Equal(t, float64(1), entries[0]["_vu"])
+	assert.Equal(t, "failed", entries[1]["_error"])
+
+	require.ErrorIs(t, r.Record(&HAREntry{}), errHARRecorderClosed)
+	require.NoError(t, r.Close())
+}
+
+func TestHARRecorderShouldRecord(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Tags", func(t *testing.T) {
+		t.Parallel()
+		r := &HARRecorder{sampleRate: 1, tags: map[string]string{"expected_response": "false"}}
+		assert.True(t, r.ShouldRecord(1, 0, map[string]string{"expected_response": "false", "name": "a"}))
+		assert.False(t, r.ShouldRecord(1, 0, map[string]string{"expected_response": "true"}))
+		assert.False(t, r.ShouldRecord(1, 0, nil))
+	})
+
+	t.Run("SampleRate", func(t *testing.T) {
+		t.Parallel()
+		none := &HARRecorder{sampleRate: 0}
+		half := &HARRecorder{sampleRate: 0.5}
+		var sampled int
+		for i := int64(0); i < 1000; i++ {
+			assert.False(t, none.ShouldRecord(1, i, nil))
+			if half.ShouldRecord(1, i, nil) {
+				sampled++
+				// all the requests of a sampled iteration are recorded
+				assert.True(t, half.ShouldRecord(1, i, nil))
+			}
+		}
+		assert.InDelta(t, 500, sampled, 100)
+	})
+}
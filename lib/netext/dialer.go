@@ -6,6 +6,7 @@ import (
 	"net"
 	"strconv"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"go.k6.io/k6/lib"
@@ -23,6 +24,9 @@ type Dialer struct {
 	BlockedHostnames *types.HostnameTrie
 	Hosts            *types.Hosts
 
+	// vuHosts overrides the DNS entries of Hosts, and it can be changed by the VU
+	vuHosts atomic.Pointer[types.Hosts]
+
 	BytesRead    int64
 	BytesWritten int64
 }
@@ -57,7 +61,7 @@ func (b BlockedHostError) Error() string {
 
 // DialContext wraps the net.Dialer.DialContext and handles the k6 specifics
 func (d *Dialer) DialContext(ctx context.Context, proto, addr string) (net.Conn, error) {
-	dialAddr, err := d.getDialAddr(addr)
+	dialAddr, err := d.getDialAddr(ctx, addr)
 	if err != nil {
 		return nil, err
 	}
@@ -69,6 +73,32 @@ func (d *Dialer) DialContext(ctx context.Context, proto, addr string) (net.Conn,
 	return conn, err
 }
 
+// DialPacket resolves the address like DialContext, applying the blocked IPs
+// and hostnames and the hosts overrides, and returns a new packet connection
+// for sending datagrams to it, e.g. for QUIC. The sent and received data of
+// the connection is counted like the one of the connections from DialContext.
+func (d *Dialer) DialPacket(ctx context.Context, addr string) (*PacketConn, *net.UDPAddr, error) {
+	dialAddr, err := d.getDialAddr(ctx, addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	remoteAddr, err := net.ResolveUDPAddr("udp", dialAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var localAddr *net.UDPAddr
+	if tcpAddr, ok := d.Dialer.LocalAddr.(*net.TCPAddr); ok {
+		localAddr = &net.UDPAddr{IP: tcpAddr.IP}
+	}
+	conn, err := net.ListenUDP("udp", localAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &PacketConn{PacketConn: conn, udpConn: conn, BytesRead: &d.BytesRead, BytesWritten: &d.BytesWritten},
+		remoteAddr, nil
+}
+
 // GetTrail creates a new NetTrail instance with the Dialer
 // sent and received data metrics and the supplied times and tags.
 // TODO: Refactor this according to
@@ -120,6 +150,16 @@ func (d *Dialer) GetTrail(
 				Value:    1,
 			})
 		}
+	} else if emitIterations {
+		samples = append(samples, metrics.Sample{
+			TimeSeries: metrics.TimeSeries{
+				Metric: builtinMetrics.IterationsInterrupted,
+				Tags:   ctm.Tags,
+			},
+			Time:     endTime,
+			Metadata: ctm.Metadata,
+			Value:    1,
+		})
 	}
 
 	return &NetTrail{
@@ -133,8 +173,34 @@ func (d *Dialer) GetTrail(
 	}
 }
 
-func (d *Dialer) getDialAddr(addr string) (string, error) {
-	remote, err := d.findRemote(addr)
+// SetVUHosts sets the DNS entries that override the ones of the hosts option
+// and the resolver, for the next connections of the VU. It removes them if the
+// provided hosts are nil.
+func (d *Dialer) SetVUHosts(hosts *types.Hosts) {
+	d.vuHosts.Store(hosts)
+}
+
+// LookupHost returns the IP address that the hostname is resolved to for the
+// connections, with the hosts overrides from the context, the VU and the
+// options, and it returns an error if the hostname is blocked.
+func (d *Dialer) LookupHost(ctx context.Context, host string) (net.IP, error) {
+	remote, err := d.resolve(ctx, host, host, "")
+	if err != nil {
+		return nil, err
+	}
+	return remote.IP, nil
+}
+
+type hostsCtxKey struct{}
+
+// WithHosts returns a new context with the DNS entries that override the ones
+// of the VU and the hosts option, for the connections dialed with the context.
+func WithHosts(ctx context.Context, hosts *types.Hosts) context.Context {
+	return context.WithValue(ctx, hostsCtxKey{}, hosts)
+}
+
+func (d *Dialer) getDialAddr(ctx context.Context, addr string) (string, error) {
+	remote, err := d.findRemote(ctx, addr)
 	if err != nil {
 		return "", err
 	}
@@ -148,12 +214,15 @@ func (d *Dialer) getDialAddr(addr string) (string, error) {
 	return remote.String(), nil
 }
 
-func (d *Dialer) findRemote(addr string) (*types.Host, error) {
+func (d *Dialer) findRemote(ctx context.Context, addr string) (*types.Host, error) {
 	host, port, err := net.SplitHostPort(addr)
 	if err != nil {
 		return nil, err
 	}
+	return d.resolve(ctx, addr, host, port)
+}
 
+func (d *Dialer) resolve(ctx context.Context, addr, host, port string) (*types.Host, error) {
 	ip := net.ParseIP(host)
 	if d.BlockedHostnames != nil && ip == nil {
 		if match, blocked := d.BlockedHostnames.Contains(host); blocked {
@@ -161,8 +230,12 @@ func (d *Dialer) findRemote(addr string) (*types.Host, error) {
 		}
 	}
 
-	if d.Hosts != nil {
-		remote, e := d.getConfiguredHost(addr, host, port)
+	ctxHosts, _ := ctx.Value(hostsCtxKey{}).(*types.Hosts)
+	for _, hosts := range []*types.Hosts{ctxHosts, d.vuHosts.Load(), d.Hosts} {
+		if hosts == nil {
+			continue
+		}
+		remote, e := getConfiguredHost(hosts, addr, host, port)
 		if e != nil || remote != nil {
 			return remote, e
 		}
@@ -172,7 +245,7 @@ func (d *Dialer) findRemote(addr string) (*types.Host, error) {
 		return types.NewHost(ip, port)
 	}
 
-	ip, err = d.Resolver.LookupIP(host)
+	ip, err := d.Resolver.LookupIP(host)
 	if err != nil {
 		return nil, err
 	}
@@ -184,12 +257,12 @@ func (d *Dialer) findRemote(addr string) (*types.Host, error) {
 	return types.NewHost(ip, port)
 }
 
-func (d *Dialer) getConfiguredHost(addr, host, port string) (*types.Host, error) {
-	if remote := d.Hosts.Match(addr); remote != nil {
+func getConfiguredHost(hosts *types.Hosts, addr, host, port string) (*types.Host, error) {
+	if remote := hosts.Match(addr); remote != nil {
 		return remote, nil
 	}
 
-	if remote := d.Hosts.Match(host); remote != nil {
+	if remote := hosts.Match(host); remote != nil {
 		if remote.Port != 0 || port == "" {
 			return remote, nil
 		}
@@ -260,3 +333,47 @@ func (c *Conn) Write(b []byte) (int, error) {
 	}
 	return n, err
 }
+
+// PacketConn wraps net.PacketConn and keeps track of sent and received data size
+type PacketConn struct {
+	net.PacketConn
+
+	// udpConn is the same connection, it isn't embedded so the methods
+	// for reading and writing without counting the data aren't exposed.
+	udpConn *net.UDPConn
+
+	BytesRead, BytesWritten *int64
+}
+
+// SetReadBuffer sets the size of the receive buffer of the connection.
+func (c *PacketConn) SetReadBuffer(bytes int) error {
+	return c.udpConn.SetReadBuffer(bytes)
+}
+
+// SetWriteBuffer sets the size of the send buffer of the connection.
+func (c *PacketConn) SetWriteBuffer(bytes int) error {
+	return c.udpConn.SetWriteBuffer(bytes)
+}
+
+// SyscallConn returns the raw network connection.
+func (c *PacketConn) SyscallConn() (syscall.RawConn, error) {
+	return c.udpConn.SyscallConn()
+}
+
+// ReadFrom reads a packet from the connection, counting its size.
+func (c *PacketConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	n, addr, err := c.PacketConn.ReadFrom(b)
+	if n > 0 {
+		atomic.AddInt64(c.BytesRead, int64(n))
+	}
+	return n, addr, err
+}
+
+// WriteTo writes a packet to the connection, counting its size.
+func (c *PacketConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	n, err := c.PacketConn.WriteTo(b, addr)
+	if n > 0 {
+		atomic.AddInt64(c.BytesWritten, int64(n))
+	}
+	return n, err
+}
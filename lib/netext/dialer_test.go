@@ -1,14 +1,19 @@
 package netext
 
 import (
+	"context"
 	"net"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/testutils/mockresolver"
 	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
 )
 
 func TestDialerAddr(t *testing.T) {
@@ -62,7 +67,7 @@ func TestDialerAddr(t *testing.T) {
 
 		t.Run(tc.address, func(t *testing.T) {
 			t.Parallel()
-			addr, err := dialer.getDialAddr(tc.address)
+			addr, err := dialer.getDialAddr(context.Background(), tc.address)
 
 			if tc.expErr != "" {
 				require.EqualError(t, err, tc.expErr)
@@ -101,7 +106,7 @@ func TestDialerAddrBlockHostnamesStar(t *testing.T) {
 
 		t.Run(tc.address, func(t *testing.T) {
 			t.Parallel()
-			addr, err := dialer.getDialAddr(tc.address)
+			addr, err := dialer.getDialAddr(context.Background(), tc.address)
 
 			if tc.expErr != "" {
 				require.EqualError(t, err, tc.expErr)
@@ -134,7 +139,7 @@ func BenchmarkDialerHosts(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		for _, tc := range tcs {
 			//nolint:gosec,errcheck
-			dialer.getDialAddr(tc)
+			dialer.getDialAddr(context.Background(), tc)
 		}
 	}
 }
@@ -148,3 +153,108 @@ func newResolver() *mockresolver.MockResolver {
 		},
 	)
 }
+
+func TestDialerGetTrail(t *testing.T) {
+	t.Parallel()
+	registry := metrics.NewRegistry()
+	builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+	ctm := metrics.TagsAndMeta{Tags: registry.RootTagSet()}
+
+	testCases := []struct {
+		fullIteration, emitIterations bool
+		expMetrics                    []string
+	}{
+		{true, true, []string{"data_sent", "data_received", "iteration_duration", "iterations"}},
+		{true, false, []string{"data_sent", "data_received", "iteration_duration"}},
+		{false, true, []string{"data_sent", "data_received", "iterations_interrupted"}},
+		{false, false, []string{"data_sent", "data_received"}},
+	}
+	for _, tc := range testCases {
+		dialer := NewDialer(net.Dialer{}, newResolver())
+		trail := dialer.GetTrail(time.Now(), time.Now(), tc.fullIteration, tc.emitIterations, ctm, builtinMetrics)
+
+		names := make([]string, 0, len(trail.Samples))
+		for _, sample := range trail.Samples {
+			names = append(names, sample.Metric.Name)
+		}
+		assert.Equal(t, tc.expMetrics, names)
+		assert.Equal(t, tc.fullIteration, trail.FullIteration)
+	}
+}
+
+func TestDialerDialPacket(t *testing.T) {
+	t.Parallel()
+
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = server.Close() })
+	go func() {
+		buf := make([]byte, 64)
+		_, addr, rerr := server.ReadFrom(buf)
+		if rerr == nil {
+			_, _ = server.WriteTo([]byte("pong pong"), addr)
+		}
+	}()
+
+	dialer := NewDialer(net.Dialer{}, newResolver())
+	ipNet, err := lib.ParseCIDR("8.9.10.0/24")
+	require.NoError(t, err)
+	dialer.Blacklist = []*lib.IPNet{ipNet}
+
+	_, _, err = dialer.DialPacket(context.Background(), "8.9.10.11:443")
+	require.ErrorContains(t, err, "IP (8.9.10.11) is in a blacklisted range (8.9.10.0/24)")
+
+	conn, remoteAddr, err := dialer.DialPacket(context.Background(), server.LocalAddr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	assert.Equal(t, server.LocalAddr().String(), remoteAddr.String())
+
+	_, err = conn.WriteTo([]byte("ping"), remoteAddr)
+	require.NoError(t, err)
+	buf := make([]byte, 64)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "pong pong", string(buf[:n]))
+	assert.Equal(t, int64(4), atomic.LoadInt64(&dialer.BytesWritten))
+	assert.Equal(t, int64(9), atomic.LoadInt64(&dialer.BytesRead))
+}
+
+func TestDialerHostsOverrides(t *testing.T) {
+	t.Parallel()
+	dialer := NewDialer(net.Dialer{}, newResolver())
+	var err error
+	dialer.Hosts, err = types.ParseHosts(map[string]string{"example.com": "3.4.5.6", "example.net": "3.4.5.7"})
+	require.NoError(t, err)
+	vuHosts, err := types.ParseHosts(map[string]string{"example.com": "5.6.7.8", "example-resolver.com": "5.6.7.9"})
+	require.NoError(t, err)
+	ctxHosts, err := types.ParseHosts(map[string]string{"example.com": "7.8.9.10:8443"})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	addr, err := dialer.getDialAddr(ctx, "example.com:443")
+	require.NoError(t, err)
+	assert.Equal(t, "3.4.5.6:443", addr)
+
+	dialer.SetVUHosts(vuHosts)
+	for host, expAddr := range map[string]string{
+		"example.com:443":          "5.6.7.8:443",
+		"example-resolver.com:443": "5.6.7.9:443",
+		"example.net:443":          "3.4.5.7:443",
+	} {
+		addr, err = dialer.getDialAddr(ctx, host)
+		require.NoError(t, err)
+		assert.Equal(t, expAddr, addr)
+	}
+
+	addr, err = dialer.getDialAddr(WithHosts(ctx, ctxHosts), "example.com:443")
+	require.NoError(t, err)
+	assert.Equal(t, "7.8.9.10:8443", addr)
+	ip, err := dialer.LookupHost(WithHosts(ctx, ctxHosts), "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "7.8.9.10", ip.String())
+
+	dialer.SetVUHosts(nil)
+	ip, err = dialer.LookupHost(ctx, "example-resolver.com")
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", ip.String())
+}
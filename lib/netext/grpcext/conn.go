@@ -34,6 +34,15 @@ type Request struct {
 	Message          []byte
 }
 
+// StreamRequest represents a gRPC stream request.
+type StreamRequest struct {
+	Method           string
+	MethodDescriptor protoreflect.MethodDescriptor
+	TagsAndMeta      *metrics.TagsAndMeta
+	Metadata         metadata.MD
+	Abort            <-chan struct{} // is closed when the script aborts the stream, which is then tagged as aborted
+}
+
 // Response represents a gRPC response.
 type Response struct {
 	Message  interface{}
@@ -168,6 +177,48 @@ func (c *Conn) Invoke(
 	return &response, nil
 }
 
+// NewStream creates a new gRPC stream, for the client-streaming,
+// server-streaming and bidirectional-streaming RPCs.
+func (c *Conn) NewStream(
+	ctx context.Context,
+	req StreamRequest,
+	opts ...grpc.CallOption,
+) (*Stream, error) {
+	ctx = metadata.NewOutgoingContext(ctx, req.Metadata)
+	rs := &rpcState{tagsAndMeta: req.TagsAndMeta}
+	if req.Abort != nil {
+		// the samples of the aborted stream are pushed with the
+		// context of the stream, from before it's canceled by the abort
+		rs.abort, rs.pushCtx = req.Abort, ctx
+		abortCtx, cancel := context.WithCancel(ctx)
+		go func() {
+			select {
+			case <-req.Abort:
+				cancel()
+			case <-abortCtx.Done():
+			}
+		}()
+		ctx = abortCtx
+	}
+	ctx = withRPCState(ctx, rs)
+
+	stream, err := c.raw.NewStream(ctx, &grpc.StreamDesc{
+		StreamName:    string(req.MethodDescriptor.Name()),
+		ServerStreams: req.MethodDescriptor.IsStreamingServer(),
+		ClientStreams: req.MethodDescriptor.IsStreamingClient(),
+	}, req.Method, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stream{
+		raw:              stream,
+		method:           req.Method,
+		methodDescriptor: req.MethodDescriptor,
+		marshaler:        protojson.MarshalOptions{EmitUnpopulated: true},
+	}, nil
+}
+
 // Close closes the underhood connection.
 func (c *Conn) Close() error {
 	return c.raw.Close()
@@ -218,14 +269,22 @@ func (h statsHandler) HandleRPC(ctx context.Context, stat grpcstats.RPCStats) {
 		if state.Options.SystemTags.Has(metrics.TagStatus) {
 			stateRPC.tagsAndMeta.SetSystemTagOrMeta(metrics.TagStatus, strconv.Itoa(int(status.Code(s.Error))))
 		}
+		tagsAndMeta := stateRPC.tagsAndMeta
+		if stateRPC.isAborted() {
+			// the tags of the stream are still read by its goroutines, so they are copied
+			aborted := tagsAndMeta.Clone()
+			aborted.SetSystemTagOrMetaIfEnabled(state.Options.SystemTags, metrics.TagAborted, "true")
+			tagsAndMeta = &aborted
+			ctx = stateRPC.pushCtx
+		}
 
 		metrics.PushIfNotDone(ctx, state.Samples, metrics.Sample{
 			TimeSeries: metrics.TimeSeries{
 				Metric: state.BuiltinMetrics.GRPCReqDuration,
-				Tags:   stateRPC.tagsAndMeta.Tags,
+				Tags:   tagsAndMeta.Tags,
 			},
 			Time:     s.EndTime,
-			Metadata: stateRPC.tagsAndMeta.Metadata,
+			Metadata: tagsAndMeta.Metadata,
 			Value:    metrics.D(s.EndTime.Sub(s.BeginTime)),
 		})
 	}
@@ -309,6 +368,21 @@ var ctxKeyRPCState = contextKey("rpcState") //nolint:gochecknoglobals
 
 type rpcState struct {
 	tagsAndMeta *metrics.TagsAndMeta
+	abort       <-chan struct{}
+	pushCtx     context.Context //nolint:containedctx // the samples of the aborted RPCs are pushed with it
+}
+
+// isAborted returns true if the RPC was aborted by the script.
+func (s *rpcState) isAborted() bool {
+	if s.abort == nil {
+		return false
+	}
+	select {
+	case <-s.abort:
+		return true
+	default:
+		return false
+	}
 }
 
 func withRPCState(ctx context.Context, rpcState *rpcState) context.Context {
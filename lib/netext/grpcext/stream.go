@@ -79,8 +79,6 @@ func (s *Stream) receive() (*dynamicpb.Message, error) {
 // rather than the desired:
 // {"x":6,"y":4,"z":0}
 func convert(marshaler protojson.MarshalOptions, msg *dynamicpb.Message) (interface{}, error) {
-	// TODO(olegbespalov): add the test that checks that message is not nil
-
 	raw, err := marshaler.Marshal(msg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal the message: %w", err)
@@ -96,12 +94,12 @@ func convert(marshaler protojson.MarshalOptions, msg *dynamicpb.Message) (interf
 	return back, err
 }
 
-// CloseSend closes the stream
+// CloseSend closes the sending side of the stream
 func (s *Stream) CloseSend() error {
 	return s.raw.CloseSend()
 }
 
-// BuildMessage builds a message from the input
+// buildMessage builds a message from the input
 func (s *Stream) buildMessage(b []byte) (*dynamicpb.Message, error) {
 	msg := dynamicpb.NewMessage(s.methodDescriptor.Input())
 	if err := protojson.Unmarshal(b, msg); err != nil {
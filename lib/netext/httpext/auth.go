@@ -0,0 +1,130 @@
+package httpext
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/Azure/go-ntlmssp"
+	digest "github.com/Soontao/goHttpDigestClient"
+)
+
+// AuthMode is used in the request to specify how the digest and NTLM authentication are done.
+// The conversion and validation methods are auto-generated with https://github.com/alvaroloes/enumer:
+//
+//go:generate enumer -type=AuthMode -transform=lower -trimprefix AuthMode -output auth_mode_gen.go
+type AuthMode uint
+
+const (
+	// AuthModeChallenge sends the request without the credentials first, and
+	// then again with them, in response to the challenge of the server. This is the default.
+	AuthModeChallenge AuthMode = iota
+	// AuthModePreemptive sends the credentials with the first request. For digest
+	// authentication, that's possible only after the server has sent a challenge
+	// for the same origin, which is cached by the VU. For NTLM authentication, the
+	// negotiation is started with the first request.
+	AuthModePreemptive
+)
+
+// AuthCache holds the digest authentication challenges of the servers, per
+// origin, so the next requests to them can be authenticated preemptively.
+type AuthCache struct {
+	mx     sync.Mutex
+	digest map[string]*cachedDigestChallenge
+}
+
+type cachedDigestChallenge struct {
+	challenge  digest.Challenge
+	nonceCount int64
+}
+
+// NewAuthCache returns a new empty AuthCache.
+func NewAuthCache() *AuthCache {
+	return &AuthCache{digest: make(map[string]*cachedDigestChallenge)}
+}
+
+// hasDigestChallenge returns true if there's a cached challenge for the origin of the URL.
+func (c *AuthCache) hasDigestChallenge(u *url.URL) bool {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	_, ok := c.digest[origin(u)]
+	return ok
+}
+
+// nextDigestChallenge returns a copy of the cached challenge for the origin of
+// the URL, with the next nonce count, or nil if there's no cached challenge.
+func (c *AuthCache) nextDigestChallenge(u *url.URL) digest.Challenge {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	cached, ok := c.digest[origin(u)]
+	if !ok {
+		return nil
+	}
+	cached.nonceCount++
+	challenge := make(digest.Challenge, len(cached.challenge))
+	for k, v := range cached.challenge {
+		challenge[k] = v
+	}
+	challenge.SetChallengeItem(digest.KEY_NONCE_COUNT, fmt.Sprintf("%08x", cached.nonceCount))
+	return challenge
+}
+
+// setDigestChallenge caches the challenge, which has been used
+// for the first time, for the origin of the URL.
+func (c *AuthCache) setDigestChallenge(u *url.URL, challenge digest.Challenge) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.digest[origin(u)] = &cachedDigestChallenge{challenge: challenge, nonceCount: 1}
+}
+
+func origin(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
+
+// wrapAuthTransport wraps the transport with the digest or NTLM authentication,
+// if the request uses one of them, and makes the tracer transport expect the 401
+// responses of the authentication handshake, instead of marking them as failed.
+func wrapAuthTransport(
+	preq *ParsedHTTPRequest, tracerTransport *transport, transport http.RoundTripper,
+) http.RoundTripper {
+	switch preq.Auth {
+	case "digest":
+		if tracerTransport.responseCallback != nil {
+			originalResponseCallback := tracerTransport.responseCallback
+			preemptive := preq.AuthMode == AuthModePreemptive && preq.AuthCache != nil &&
+				preq.AuthCache.hasDigestChallenge(preq.Req.URL)
+			tracerTransport.responseCallback = func(status int) bool {
+				tracerTransport.responseCallback = originalResponseCallback
+				if preemptive {
+					// the cached challenge could be stale, so it could be sent again
+					return status == 401 || originalResponseCallback(status)
+				}
+				// Until digest authentication is refactored, the first response will always
+				// be a 401 error, so we expect that.
+				return status == 401
+			}
+		}
+		return digestTransport{
+			originalTransport: transport,
+			cache:             preq.AuthCache,
+			preemptive:        preq.AuthMode == AuthModePreemptive,
+		}
+	case "ntlm":
+		// The first response of NTLM auth may be a 401 error.
+		if tracerTransport.responseCallback != nil {
+			originalResponseCallback := tracerTransport.responseCallback
+			tracerTransport.responseCallback = func(status int) bool {
+				tracerTransport.responseCallback = originalResponseCallback
+				// ntlm is connection-level based so we could've already authorized the connection and to now reuse it
+				return status == 401 || originalResponseCallback(status)
+			}
+		}
+		if preq.AuthMode == AuthModePreemptive {
+			return preemptiveNTLMTransport{originalTransport: transport}
+		}
+		return ntlmssp.Negotiator{RoundTripper: transport}
+	default:
+		return transport
+	}
+}
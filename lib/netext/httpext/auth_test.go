@@ -0,0 +1,182 @@
+package httpext
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mccutchen/go-httpbin/httpbin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+func makeAuthRequest(
+	t *testing.T, srv *httptest.Server, path, auth string, mode AuthMode, cache *AuthCache,
+) *Response {
+	t.Helper()
+	registry := metrics.NewRegistry()
+	state := &lib.State{
+		Options: lib.Options{
+			SystemTags: &metrics.DefaultSystemTagSet,
+		},
+		Transport:      srv.Client().Transport,
+		Samples:        make(chan metrics.SampleContainer, 100),
+		Logger:         logrus.New(),
+		BufferPool:     lib.NewBufferPool(),
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+	}
+	u, err := url.Parse(srv.URL + path)
+	require.NoError(t, err)
+	u.User = url.UserPassword("bob", "pass")
+	req, err := http.NewRequest(http.MethodPost, u.String(), nil)
+	require.NoError(t, err)
+	preq := &ParsedHTTPRequest{
+		Req:         req,
+		URL:         &URL{u: req.URL, URL: u.String()},
+		Body:        bytes.NewBufferString("data"),
+		Timeout:     10 * time.Second,
+		Auth:        auth,
+		AuthMode:    mode,
+		AuthCache:   cache,
+		TagsAndMeta: state.Tags.GetCurrentValues(),
+	}
+	res, err := MakeRequest(context.Background(), state, preq)
+	require.NoError(t, err)
+	return res
+}
+
+func TestDigestAuthPreemptive(t *testing.T) {
+	t.Parallel()
+
+	var mx sync.Mutex
+	var requests []bool // whether each request had the digest credentials
+	var stale bool      // whether the server should reject the next credentials as stale
+	bin := httpbin.New()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := new(bytes.Buffer)
+		_, _ = body.ReadFrom(r.Body)
+		assert.Equal(t, "data", body.String())
+		mx.Lock()
+		hasCredentials := strings.HasPrefix(r.Header.Get("Authorization"), "Digest ")
+		requests = append(requests, hasCredentials)
+		if stale && hasCredentials {
+			stale = false
+			r.Header.Del("Authorization") // respond with a new challenge
+		}
+		mx.Unlock()
+		bin.DigestAuth(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	getRequests := func() []bool {
+		mx.Lock()
+		defer mx.Unlock()
+		r := requests
+		requests = nil
+		return r
+	}
+
+	path := "/digest-auth/auth/bob/pass"
+	cache := NewAuthCache()
+	res := makeAuthRequest(t, srv, path, "digest", AuthModeChallenge, cache)
+	assert.Equal(t, http.StatusOK, res.Status)
+	assert.Equal(t, []bool{false, true}, getRequests())
+
+	res = makeAuthRequest(t, srv, path, "digest", AuthModePreemptive, cache)
+	assert.Equal(t, http.StatusOK, res.Status)
+	assert.Equal(t, []bool{true}, getRequests())
+
+	res = makeAuthRequest(t, srv, path, "digest", AuthModePreemptive, cache)
+	assert.Equal(t, http.StatusOK, res.Status)
+	assert.Equal(t, []bool{true}, getRequests())
+
+	// a stale challenge is replaced by the new one
+	mx.Lock()
+	stale = true
+	mx.Unlock()
+	res = makeAuthRequest(t, srv, path, "digest", AuthModePreemptive, cache)
+	assert.Equal(t, http.StatusOK, res.Status)
+	assert.Equal(t, []bool{true, true}, getRequests())
+	res = makeAuthRequest(t, srv, path, "digest", AuthModePreemptive, cache)
+	assert.Equal(t, http.StatusOK, res.Status)
+	assert.Equal(t, []bool{true}, getRequests())
+
+	// without a cached challenge for the origin, it's the same as the challenge mode
+	res = makeAuthRequest(t, srv, path, "digest", AuthModePreemptive, NewAuthCache())
+	assert.Equal(t, http.StatusOK, res.Status)
+	assert.Equal(t, []bool{false, true}, getRequests())
+}
+
+// ntlmChallengeMessage is a minimal NTLM challenge message,
+// without a target name and info, that's enough for the client.
+func ntlmChallengeMessage() []byte {
+	msg := make([]byte, 48)
+	copy(msg, "NTLMSSP\x00")
+	binary.LittleEndian.PutUint32(msg[8:], 2)
+	binary.LittleEndian.PutUint32(msg[20:], 1) // NTLMSSP_NEGOTIATE_UNICODE
+	copy(msg[24:32], "12345678")
+	return msg
+}
+
+func TestNTLMAuthModes(t *testing.T) {
+	t.Parallel()
+
+	var mx sync.Mutex
+	var messages []byte // the types of the NTLM messages that were received, 0 without one
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := new(bytes.Buffer)
+		_, _ = body.ReadFrom(r.Body)
+		assert.Equal(t, "data", body.String())
+
+		var msgType byte
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "NTLM ") {
+			msg, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(auth, "NTLM "))
+			require.NoError(t, err)
+			require.True(t, bytes.HasPrefix(msg, []byte("NTLMSSP\x00")))
+			msgType = msg[8]
+		}
+		mx.Lock()
+		messages = append(messages, msgType)
+		mx.Unlock()
+
+		switch msgType {
+		case 1:
+			w.Header().Set("WWW-Authenticate", "NTLM "+base64.StdEncoding.EncodeToString(ntlmChallengeMessage()))
+			w.WriteHeader(http.StatusUnauthorized)
+		case 3:
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.Header().Set("WWW-Authenticate", "NTLM")
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	getMessages := func() []byte {
+		mx.Lock()
+		defer mx.Unlock()
+		m := messages
+		messages = nil
+		return m
+	}
+
+	res := makeAuthRequest(t, srv, "/", "ntlm", AuthModeChallenge, nil)
+	assert.Equal(t, http.StatusOK, res.Status)
+	assert.Equal(t, []byte{0, 1, 3}, getMessages())
+
+	res = makeAuthRequest(t, srv, "/", "ntlm", AuthModePreemptive, nil)
+	assert.Equal(t, http.StatusOK, res.Status)
+	assert.Equal(t, []byte{1, 3}, getMessages())
+}
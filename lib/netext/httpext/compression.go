@@ -114,35 +114,12 @@ func wrapDecompressionError(err error) error {
 	return err
 }
 
-func readResponseBody(
-	state *lib.State,
-	respType ResponseType,
-	resp *http.Response,
-	respErr error,
-) (interface{}, error) {
-	if resp == nil || respErr != nil {
-		return nil, respErr
-	}
-
-	if respType == ResponseTypeNone {
-		_, err := io.Copy(io.Discard, resp.Body)
-		_ = resp.Body.Close()
-		if err != nil {
-			respErr = err
-		}
-		return nil, respErr
-	}
-
+// newDecompressingReader returns a reader of the response body, which
+// transparently decompresses it if it has a content-encoding we support.
+// If not, the body is simply returned as it is.
+func newDecompressingReader(resp *http.Response) (*readCloser, error) {
 	rc := &readCloser{resp.Body}
-	// Ensure that the entire response body is read and closed, e.g. in case of decoding errors
-	defer func(respBody io.ReadCloser) {
-		_, _ = io.Copy(io.Discard, respBody)
-		_ = respBody.Close()
-	}(resp.Body)
-
 	contentEncodings := strings.Split(resp.Header.Get("Content-Encoding"), ",")
-	// Transparently decompress the body if it's has a content-encoding we
-	// support. If not, simply return it as it is.
 	for i := len(contentEncodings) - 1; i >= 0; i-- {
 		contentEncoding := strings.TrimSpace(contentEncodings[i])
 		if compression, err := CompressionTypeString(contentEncoding); err == nil {
@@ -171,9 +148,42 @@ func readResponseBody(
 		}
 	}
 
+	return rc, nil
+}
+
+func readResponseBody(
+	state *lib.State,
+	respType ResponseType,
+	resp *http.Response,
+	respErr error,
+) (interface{}, error) {
+	if resp == nil || respErr != nil {
+		return nil, respErr
+	}
+
+	if respType == ResponseTypeNone {
+		_, err := io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			respErr = err
+		}
+		return nil, respErr
+	}
+
+	// Ensure that the entire response body is read and closed, e.g. in case of decoding errors
+	defer func(respBody io.ReadCloser) {
+		_, _ = io.Copy(io.Discard, respBody)
+		_ = respBody.Close()
+	}(resp.Body)
+
+	rc, err := newDecompressingReader(resp)
+	if err != nil {
+		return nil, err
+	}
+
 	buf := state.BufferPool.Get()
 	defer state.BufferPool.Put(buf)
-	_, err := io.Copy(buf, rc.Reader)
+	_, err = io.Copy(buf, rc.Reader)
 	if err != nil {
 		respErr = wrapDecompressionError(err)
 	}
@@ -0,0 +1,50 @@
+// Code generated by "enumer -type=ConnectionMode -transform=lower -trimprefix ConnectionMode -output connection_mode_gen.go"; DO NOT EDIT.
+
+package httpext
+
+import (
+	"fmt"
+)
+
+const _ConnectionModeName = "defaultreusenew"
+
+var _ConnectionModeIndex = [...]uint8{0, 7, 12, 15}
+
+func (i ConnectionMode) String() string {
+	if i >= ConnectionMode(len(_ConnectionModeIndex)-1) {
+		return fmt.Sprintf("ConnectionMode(%d)", i)
+	}
+	return _ConnectionModeName[_ConnectionModeIndex[i]:_ConnectionModeIndex[i+1]]
+}
+
+var _ConnectionModeValues = []ConnectionMode{0, 1, 2}
+
+var _ConnectionModeNameToValueMap = map[string]ConnectionMode{
+	_ConnectionModeName[0:7]:   0,
+	_ConnectionModeName[7:12]:  1,
+	_ConnectionModeName[12:15]: 2,
+}
+
+// ConnectionModeString retrieves an enum value from the enum constants string name.
+// Throws an error if the param is not part of the enum.
+func ConnectionModeString(s string) (ConnectionMode, error) {
+	if val, ok := _ConnectionModeNameToValueMap[s]; ok {
+		return val, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to ConnectionMode values", s)
+}
+
+// ConnectionModeValues returns all values of the enum
+func ConnectionModeValues() []ConnectionMode {
+	return _ConnectionModeValues
+}
+
+// IsAConnectionMode returns "true" if the value is listed in the enum definition. "false" otherwise
+func (i ConnectionMode) IsAConnectionMode() bool {
+	for _, v := range _ConnectionModeValues {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
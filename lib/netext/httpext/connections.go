@@ -0,0 +1,80 @@
+package httpext
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go.k6.io/k6/lib"
+)
+
+// ConnectionMode is used in the request to specify if it should reuse a pooled connection or open a new one.
+// The conversion and validation methods are auto-generated with https://github.com/alvaroloes/enumer:
+//
+//go:generate enumer -type=ConnectionMode -transform=lower -trimprefix ConnectionMode -output connection_mode_gen.go
+type ConnectionMode uint
+
+const (
+	// ConnectionModeDefault reuses the connections, unless the noConnectionReuse option is enabled.
+	ConnectionModeDefault ConnectionMode = iota
+	// ConnectionModeReuse reuses the pooled connections, even if the noConnectionReuse option is enabled.
+	ConnectionModeReuse
+	// ConnectionModeNew opens a new connection for the request, which is closed after it.
+	ConnectionModeNew
+)
+
+// getConnRoundTripper returns the transport of the VU for the connection mode of the request.
+func getConnRoundTripper(state *lib.State, preq *ParsedHTTPRequest) (http.RoundTripper, error) {
+	if preq.TLSConfig != nil {
+		// the pooled connections were made with the TLS settings of the VU
+		if preq.Connection == ConnectionModeReuse {
+			return nil, errors.New("the pooled connections can't be reused by requests with TLS overrides")
+		}
+		if state.NewTLSTransport == nil {
+			return nil, errors.New("TLS overrides for requests aren't supported")
+		}
+		return state.NewTLSTransport(preq.TLSConfig), nil
+	}
+
+	var transport http.RoundTripper
+	switch preq.Connection {
+	case ConnectionModeDefault:
+		if preq.Hosts != nil {
+			// the pooled connections could be to other IPs
+			transport = state.TransportNoReuse
+			break
+		}
+		return state.Transport, nil
+	case ConnectionModeReuse:
+		if preq.Hosts != nil {
+			return nil, errors.New("the pooled connections can't be reused by requests with hosts overrides")
+		}
+		transport = state.TransportReuse
+	case ConnectionModeNew:
+		transport = state.TransportNoReuse
+	default:
+		return nil, fmt.Errorf("unknown connection mode %s", preq.Connection)
+	}
+	if transport == nil && preq.Hosts != nil {
+		return nil, errors.New("hosts overrides for requests aren't supported")
+	}
+	if transport == nil {
+		return nil, fmt.Errorf("the %s connection mode isn't supported", preq.Connection)
+	}
+	return transport, nil
+}
+
+// acquireConn marks the start of the request in the connection stats of the
+// test, and returns the function that marks its end. The connection gauges are
+// emitted periodically from these stats. It returns a noop function if the
+// connections of the request aren't tracked.
+func acquireConn(state *lib.State, preq *ParsedHTTPRequest) func() {
+	if state.ConnStats == nil || preq.Protocol != ProtocolAuto {
+		return func() {}
+	}
+	addr := authorityAddr(preq.Req.URL)
+	state.ConnStats.Acquire(addr)
+	return func() {
+		state.ConnStats.Release(addr)
+	}
+}
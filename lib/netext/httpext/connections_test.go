@@ -0,0 +1,151 @@
+package httpext
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+func TestMakeRequestConnectionMode(t *testing.T) {
+	t.Parallel()
+
+	var mx sync.Mutex
+	remoteAddrs := make(map[string]struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mx.Lock()
+		remoteAddrs[r.RemoteAddr] = struct{}{}
+		mx.Unlock()
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+
+	samples := make(chan metrics.SampleContainer, 100)
+	registry := metrics.NewRegistry()
+	connStats := lib.NewConnStats()
+	dialer := &net.Dialer{}
+	newTransport := func(disableKeepAlives bool) *http.Transport {
+		return &http.Transport{
+			DialContext:       connStats.TrackDialContext(dialer.DialContext),
+			DisableKeepAlives: disableKeepAlives,
+		}
+	}
+	transportReuse, transportNoReuse := newTransport(false), newTransport(true)
+	t.Cleanup(transportReuse.CloseIdleConnections)
+	state := &lib.State{
+		Options: lib.Options{
+			SystemTags: &metrics.DefaultSystemTagSet,
+		},
+		Transport:        transportNoReuse,
+		TransportReuse:   transportReuse,
+		TransportNoReuse: transportNoReuse,
+		ConnStats:        connStats,
+		Samples:          samples,
+		Logger:           logrus.New(),
+		BufferPool:       lib.NewBufferPool(),
+		BuiltinMetrics:   metrics.RegisterBuiltinMetrics(registry),
+		Tags:             lib.NewVUStateTags(registry.RootTagSet()),
+	}
+
+	makeRequests := func(t *testing.T, mode ConnectionMode) int {
+		mx.Lock()
+		remoteAddrs = make(map[string]struct{})
+		mx.Unlock()
+		for i := 0; i < 3; i++ {
+			req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+			require.NoError(t, err)
+			preq := &ParsedHTTPRequest{
+				Req:         req,
+				URL:         &URL{u: req.URL, URL: srv.URL},
+				Body:        new(bytes.Buffer),
+				Timeout:     10 * time.Second,
+				Connection:  mode,
+				TagsAndMeta: state.Tags.GetCurrentValues(),
+			}
+			res, err := MakeRequest(context.Background(), state, preq)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, res.Status)
+		}
+		mx.Lock()
+		defer mx.Unlock()
+		return len(remoteAddrs)
+	}
+
+	// the default transport doesn't reuse the connections in this case
+	assert.Equal(t, 3, makeRequests(t, ConnectionModeDefault))
+	assert.Equal(t, 3, makeRequests(t, ConnectionModeNew))
+	assert.Equal(t, 1, makeRequests(t, ConnectionModeReuse))
+
+	// the pooled connection is tracked as idle after the requests
+	open, idle := connStats.Get(srv.Listener.Addr().String())
+	assert.Equal(t, int64(1), open)
+	assert.Equal(t, int64(1), idle)
+}
+
+func TestGetRoundTripperConnectionMode(t *testing.T) {
+	t.Parallel()
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	preq := &ParsedHTTPRequest{
+		Req:        req,
+		URL:        &URL{u: req.URL, URL: "http://example.com"},
+		Connection: ConnectionModeNew,
+	}
+
+	_, err = getRoundTripper(&lib.State{Transport: http.DefaultTransport}, preq)
+	require.EqualError(t, err, "the new connection mode isn't supported")
+
+	preq.Protocol = ProtocolH2C
+	_, err = getRoundTripper(&lib.State{TransportH2C: &H2CTransport{}}, preq)
+	require.EqualError(t, err, "the new connection mode can't be used with the h2c protocol")
+}
+
+func TestGetRoundTripperTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	require.NoError(t, err)
+	tlsConfig := &tls.Config{ServerName: "example.net"} //nolint:gosec
+	preq := &ParsedHTTPRequest{
+		Req:       req,
+		URL:       &URL{u: req.URL, URL: "https://example.com"},
+		TLSConfig: tlsConfig,
+	}
+
+	_, err = getRoundTripper(&lib.State{Transport: http.DefaultTransport}, preq)
+	require.EqualError(t, err, "TLS overrides for requests aren't supported")
+
+	var usedConfig *tls.Config
+	state := &lib.State{
+		Transport: http.DefaultTransport,
+		NewTLSTransport: func(c *tls.Config) http.RoundTripper {
+			usedConfig = c
+			return &http.Transport{TLSClientConfig: c}
+		},
+	}
+	transport, err := getRoundTripper(state, preq)
+	require.NoError(t, err)
+	assert.NotSame(t, http.DefaultTransport, transport)
+	assert.Same(t, tlsConfig, usedConfig)
+
+	preq.Connection = ConnectionModeReuse
+	_, err = getRoundTripper(state, preq)
+	require.EqualError(t, err, "the pooled connections can't be reused by requests with TLS overrides")
+
+	preq.Connection = ConnectionModeDefault
+	preq.Protocol = ProtocolH3
+	_, err = getRoundTripper(&lib.State{TransportH3: &HTTP3Transport{}}, preq)
+	require.EqualError(t, err, "TLS overrides can't be used with the h3 protocol")
+}
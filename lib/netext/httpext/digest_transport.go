@@ -9,14 +9,18 @@ import (
 
 type digestTransport struct {
 	originalTransport http.RoundTripper
+	// cache holds the challenges for the preemptive authentication, it can be nil
+	cache      *AuthCache
+	preemptive bool
 }
 
 // RoundTrip handles digest auth by behaving like an http.RoundTripper
 //
 // TODO: fix - this is a preliminary solution and is somewhat broken! we're
-// always making 2 HTTP requests when digest authentication is enabled... we
-// should cache the nonces and behave more like a browser... or we should
-// ditch the hacky http.RoundTripper approach and write our own client...
+// always making 2 HTTP requests when digest authentication is enabled, unless
+// it's preemptive and the nonce is cached... we should behave more like a
+// browser... or we should ditch the hacky http.RoundTripper approach and write
+// our own client...
 //
 // Github issue: https://github.com/k6io/k6/issues/800
 func (t digestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -29,7 +33,24 @@ func (t digestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	// header for basic auth
 	req.URL.User = nil
 
-	noAuthResponse, err := t.originalTransport.RoundTrip(req)
+	var noAuthResponse *http.Response
+	var err error
+	if challenge := t.cachedChallenge(req); challenge != nil {
+		// Authenticate with the challenge that the server has sent for a previous request
+		challenge.ComputeResponse(req.Method, req.URL.RequestURI(), "", username, password)
+		req.Header.Set(digest.KEY_AUTHORIZATION, challenge.ToAuthorizationStr())
+		noAuthResponse, err = t.originalTransport.RoundTrip(req)
+		if err != nil || noAuthResponse.StatusCode != http.StatusUnauthorized {
+			return noAuthResponse, err
+		}
+		// The cached nonce is probably stale, so authenticate with the new challenge
+		req.Header.Del(digest.KEY_AUTHORIZATION)
+		if err = resetBody(req); err != nil {
+			return nil, err
+		}
+	} else {
+		noAuthResponse, err = t.originalTransport.RoundTrip(req)
+	}
 	if err != nil || noAuthResponse.StatusCode != http.StatusUnauthorized {
 		// If there was an error, or if the remote server didn't respond with
 		// status 401, we simply return, so the upstream code can deal with it.
@@ -51,14 +72,36 @@ func (t digestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	authorization := challenge.ToAuthorizationStr()
 	req.Header.Set(digest.KEY_AUTHORIZATION, authorization)
 
-	if req.GetBody != nil {
-		// Reset the request body if we need to
-		req.Body, err = req.GetBody()
-		if err != nil {
-			return nil, err
-		}
+	if err = resetBody(req); err != nil {
+		return nil, err
 	}
 
 	// Actually make the HTTP request with the proper Authorization
-	return t.originalTransport.RoundTrip(req)
+	resp, err := t.originalTransport.RoundTrip(req)
+	if err == nil && t.cache != nil && resp.StatusCode != http.StatusUnauthorized {
+		t.cache.setDigestChallenge(req.URL, challenge)
+	}
+	return resp, err
+}
+
+// cachedChallenge returns the cached challenge for the request,
+// if it should be authenticated preemptively.
+func (t digestTransport) cachedChallenge(req *http.Request) digest.Challenge {
+	if !t.preemptive || t.cache == nil {
+		return nil
+	}
+	return t.cache.nextDigestChallenge(req.URL)
+}
+
+// resetBody resets the body of the request, so it can be sent again.
+func resetBody(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
 }
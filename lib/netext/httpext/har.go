@@ -0,0 +1,150 @@
+package httpext
+
+import (
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"go.k6.io/k6/lib"
+)
+
+// recordHAR adds the request to the HAR file of the VU, if it's sampled. Only
+// the last request is recorded if the request was redirected.
+func recordHAR(
+	state *lib.State, preq *ParsedHTTPRequest, respReq *Request,
+	res *http.Response, resp *Response, finishedReq *finishedRequest, resErr error,
+) {
+	tags := preq.TagsAndMeta.Tags
+	if finishedReq != nil {
+		tags = finishedReq.trail.Tags
+	}
+	var tagsMap map[string]string
+	if tags != nil {
+		tagsMap = tags.Map()
+	}
+	if !state.HARRecorder.ShouldRecord(state.VUID, state.Iteration, tagsMap) {
+		return
+	}
+
+	req := preq.Req
+	if res != nil && res.Request != nil {
+		req = res.Request
+	}
+	entry := &lib.HAREntry{
+		StartedDateTime: time.Now(),
+		Request: lib.HARRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			Cookies:     make([]lib.HARNameValue, 0),
+			Headers:     harHeaders(req.Header),
+			QueryString: make([]lib.HARNameValue, 0),
+			HeadersSize: -1,
+			BodySize:    int64(len(respReq.Body)),
+		},
+		Response: lib.HARResponse{
+			Status:      resp.Status,
+			StatusText:  resp.StatusText,
+			HTTPVersion: resp.Proto,
+			Cookies:     make([]lib.HARNameValue, 0),
+			Headers:     make([]lib.HARNameValue, 0),
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		ServerIPAddress: resp.RemoteIP,
+		VU:              state.VUID,
+		Iteration:       state.Iteration,
+		Tags:            tagsMap,
+	}
+	if entry.Request.HTTPVersion == "" {
+		entry.Request.HTTPVersion = "HTTP/1.1"
+	}
+	for _, c := range req.Cookies() {
+		entry.Request.Cookies = append(entry.Request.Cookies, lib.HARNameValue{Name: c.Name, Value: c.Value})
+	}
+	for k, vs := range req.URL.Query() {
+		for _, v := range vs {
+			entry.Request.QueryString = append(entry.Request.QueryString, lib.HARNameValue{Name: k, Value: v})
+		}
+	}
+	sortHARNameValues(entry.Request.QueryString)
+	if respReq.Body != "" {
+		entry.Request.PostData = &lib.HARPostData{MimeType: req.Header.Get("Content-Type"), Text: respReq.Body}
+	}
+
+	if res != nil {
+		entry.Response.Headers = harHeaders(res.Header)
+		entry.Response.RedirectURL = res.Header.Get("Location")
+		for _, c := range res.Cookies() {
+			entry.Response.Cookies = append(entry.Response.Cookies, lib.HARNameValue{Name: c.Name, Value: c.Value})
+		}
+		entry.Response.Content.MimeType = res.Header.Get("Content-Type")
+	}
+	var body []byte
+	switch b := resp.Body.(type) {
+	case []byte:
+		body = b
+	case string:
+		body = []byte(b)
+	}
+	entry.Response.Content.Size = int64(len(body))
+	if body != nil {
+		entry.Response.BodySize = int64(len(body))
+		if utf8.Valid(body) {
+			entry.Response.Content.Text = string(body)
+		} else {
+			entry.Response.Content.Text = base64.StdEncoding.EncodeToString(body)
+			entry.Response.Content.Encoding = "base64"
+		}
+	}
+
+	if finishedReq != nil {
+		trail := finishedReq.trail
+		entry.StartedDateTime = trail.EndTime.Add(-trail.Duration - trail.ConnDuration - trail.Blocked)
+		entry.Time = harDuration(trail.Blocked + trail.ConnDuration + trail.Duration)
+		entry.Timings = lib.HARTimings{
+			Blocked: harDuration(trail.Blocked),
+			DNS:     -1,
+			Connect: -1,
+			SSL:     -1,
+			Send:    harDuration(trail.Sending),
+			Wait:    harDuration(trail.Waiting),
+			Receive: harDuration(trail.Receiving),
+		}
+		if !trail.ConnReused {
+			// the connect time of HAR files includes the TLS handshake
+			entry.Timings.Connect = harDuration(trail.ConnDuration)
+			if trail.TLSHandshaking > 0 {
+				entry.Timings.SSL = harDuration(trail.TLSHandshaking)
+			}
+		}
+	}
+	if resErr != nil {
+		entry.Error = resErr.Error()
+	}
+
+	if err := state.HARRecorder.Record(entry); err != nil {
+		state.Logger.WithError(err).Warn("Unable to record the request into the HAR file")
+	}
+}
+
+func harHeaders(h http.Header) []lib.HARNameValue {
+	headers := make([]lib.HARNameValue, 0, len(h))
+	for k, vs := range h {
+		for _, v := range vs {
+			headers = append(headers, lib.HARNameValue{Name: k, Value: v})
+		}
+	}
+	sortHARNameValues(headers)
+	return headers
+}
+
+func sortHARNameValues(values []lib.HARNameValue) {
+	sort.SliceStable(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+}
+
+func harDuration(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
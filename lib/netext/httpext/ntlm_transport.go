@@ -0,0 +1,76 @@
+package httpext
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/go-ntlmssp"
+)
+
+// preemptiveNTLMTransport handles NTLM auth by starting the negotiation with the
+// first request, unlike ntlmssp.Negotiator, which first tries the request without
+// the credentials, in case the connection is already authenticated.
+type preemptiveNTLMTransport struct {
+	originalTransport http.RoundTripper
+}
+
+// RoundTrip handles NTLM auth by behaving like an http.RoundTripper
+func (t preemptiveNTLMTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the credentials from the URL are set as basic auth by the http.Client
+	username, password, ok := req.BasicAuth()
+	if !ok {
+		return t.originalTransport.RoundTrip(req)
+	}
+	username, domain, domainNeeded := ntlmssp.GetDomain(username)
+
+	negotiateMessage, err := ntlmssp.NewNegotiateMessage(domain, "")
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "NTLM "+base64.StdEncoding.EncodeToString(negotiateMessage))
+	res, err := t.originalTransport.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+
+	challengeMessage, err := ntlmChallenge(res.Header.Values("Www-Authenticate"))
+	if err != nil {
+		_ = res.Body.Close()
+		return nil, err
+	}
+	if challengeMessage == nil {
+		// the negotiation has failed, let the client deal with the response
+		return res, nil
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+
+	authenticateMessage, err := ntlmssp.ProcessChallenge(challengeMessage, username, password, domainNeeded)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "NTLM "+base64.StdEncoding.EncodeToString(authenticateMessage))
+	if err = resetBody(req); err != nil {
+		return nil, err
+	}
+	return t.originalTransport.RoundTrip(req)
+}
+
+// ntlmChallenge returns the NTLM challenge message from the WWW-Authenticate
+// header values, or nil if there isn't one.
+func ntlmChallenge(values []string) ([]byte, error) {
+	for _, v := range values {
+		if !strings.HasPrefix(v, "NTLM ") {
+			continue
+		}
+		challenge, err := base64.StdEncoding.DecodeString(strings.TrimSpace(strings.TrimPrefix(v, "NTLM ")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid NTLM challenge: %w", err)
+		}
+		return challenge, nil
+	}
+	return nil, nil
+}
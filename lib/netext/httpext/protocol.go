@@ -0,0 +1,276 @@
+package httpext
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sync"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/net/http2"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/netext"
+)
+
+// Protocol is used in the request to specify which HTTP protocol is used for it
+// The conversion and validation methods are auto-generated with https://github.com/alvaroloes/enumer:
+//
+//go:generate enumer -type=Protocol -transform=lower -trimprefix Protocol -output protocol_gen.go
+type Protocol uint
+
+const (
+	// ProtocolAuto uses HTTP/1.1 or HTTP/2, as negotiated with the server
+	// over TLS, or HTTP/1.1 for the plain http:// URLs. This is the default.
+	ProtocolAuto Protocol = iota
+	// ProtocolH2C uses HTTP/2 over cleartext TCP for http:// URLs, with prior
+	// knowledge that the server supports it, i.e. without upgrading from HTTP/1.1.
+	ProtocolH2C
+	// ProtocolH3 uses HTTP/3 over QUIC for https:// URLs.
+	ProtocolH3
+)
+
+// getRoundTripper returns the round tripper of the VU for the protocol of
+// the request, or an error if the protocol can't be used for its URL.
+func getRoundTripper(state *lib.State, preq *ParsedHTTPRequest) (http.RoundTripper, error) {
+	var scheme string
+	var transport http.RoundTripper
+	switch preq.Protocol {
+	case ProtocolAuto:
+		return getConnRoundTripper(state, preq)
+	case ProtocolH2C:
+		scheme, transport = "http", state.TransportH2C
+	case ProtocolH3:
+		scheme, transport = "https", state.TransportH3
+	default:
+		return nil, fmt.Errorf("unknown protocol %s", preq.Protocol)
+	}
+
+	if transport == nil {
+		return nil, fmt.Errorf("the %s protocol isn't supported", preq.Protocol)
+	}
+	if preq.Hosts != nil {
+		return nil, fmt.Errorf("hosts overrides can't be used with the %s protocol", preq.Protocol)
+	}
+	if preq.TLSConfig != nil {
+		return nil, fmt.Errorf("TLS overrides can't be used with the %s protocol", preq.Protocol)
+	}
+	if preq.Connection != ConnectionModeDefault {
+		return nil, fmt.Errorf("the %s connection mode can't be used with the %s protocol", preq.Connection, preq.Protocol)
+	}
+	if preq.Req.URL.Scheme != scheme {
+		return nil, fmt.Errorf("the %s protocol can be used only for %s:// URLs, got '%s'",
+			preq.Protocol, scheme, preq.URL.Clean())
+	}
+	return transport, nil
+}
+
+// H2CTransport is an http.RoundTripper for HTTP/2 over cleartext TCP, with
+// prior knowledge that the server supports it.
+type H2CTransport struct {
+	transport *http2.Transport
+}
+
+var _ http.RoundTripper = &H2CTransport{}
+
+// NewH2CTransport returns a new H2CTransport, which dials
+// the connections with the provided function.
+func NewH2CTransport(
+	dialContext func(ctx context.Context, network, addr string) (net.Conn, error),
+) *H2CTransport {
+	return &H2CTransport{transport: &http2.Transport{
+		AllowHTTP:          true,
+		DisableCompression: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return dialContext(ctx, network, addr)
+		},
+	}}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *H2CTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the redirects could lead to other schemes
+	if req.URL.Scheme != "http" {
+		return nil, fmt.Errorf("the h2c protocol can be used only for http:// URLs, got '%s'", req.URL)
+	}
+	return t.transport.RoundTrip(req)
+}
+
+// CloseIdleConnections closes the connections that aren't used by any requests.
+func (t *H2CTransport) CloseIdleConnections() {
+	t.transport.CloseIdleConnections()
+}
+
+// HTTP3Transport is an http.RoundTripper for HTTP/3 over QUIC. It dials the
+// QUIC connections with the k6 dialer, and it calls the httptrace hooks of the
+// requests, so they have the same http_req_* metrics as the ones over TCP.
+type HTTP3Transport struct {
+	roundTripper *http3.RoundTripper
+	dialer       *netext.Dialer
+
+	mx sync.Mutex
+	// conns holds the open QUIC connection to each address.
+	conns map[string]quic.EarlyConnection
+}
+
+var _ http.RoundTripper = &HTTP3Transport{}
+
+// NewHTTP3Transport returns a new HTTP3Transport, which dials the
+// connections with the provided dialer and TLS config.
+func NewHTTP3Transport(dialer *netext.Dialer, tlsConfig *tls.Config) *HTTP3Transport {
+	t := &HTTP3Transport{
+		dialer: dialer,
+		conns:  make(map[string]quic.EarlyConnection),
+	}
+	t.roundTripper = &http3.RoundTripper{
+		TLSClientConfig:    tlsConfig.Clone(),
+		DisableCompression: true,
+		Dial:               t.dial,
+	}
+	return t
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *HTTP3Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the redirects could lead to other schemes
+	if req.URL.Scheme != "https" {
+		return nil, fmt.Errorf("the h3 protocol can be used only for https:// URLs, got '%s'", req.URL)
+	}
+
+	trace := httptrace.ContextClientTrace(req.Context())
+	if trace == nil {
+		return t.roundTripper.RoundTrip(req)
+	}
+
+	addr := authorityAddr(req.URL)
+	if trace.GetConn != nil {
+		trace.GetConn(addr)
+	}
+	t.mx.Lock()
+	conn, reused := t.conns[addr]
+	t.mx.Unlock()
+	if reused {
+		// otherwise, dial calls the hooks for the new connection
+		traceGotConn(trace, conn, true)
+	}
+
+	resp, err := t.roundTripper.RoundTrip(req)
+	if err == nil && trace.GotFirstResponseByte != nil {
+		// the response is returned as soon as its headers are received
+		trace.GotFirstResponseByte()
+	}
+	return resp, err
+}
+
+// CloseIdleConnections closes the connections that aren't used by any requests.
+func (t *HTTP3Transport) CloseIdleConnections() {
+	t.roundTripper.CloseIdleConnections()
+}
+
+// dial opens a new QUIC connection to the address and completes its handshake,
+// calling the httptrace hooks of the request that it's opened for.
+func (t *HTTP3Transport) dial(
+	ctx context.Context, addr string, tlsCfg *tls.Config, cfg *quic.Config,
+) (quic.EarlyConnection, error) {
+	trace := httptrace.ContextClientTrace(ctx)
+	if trace == nil {
+		trace = &httptrace.ClientTrace{}
+	}
+
+	// there isn't a connection phase with UDP, the QUIC
+	// handshake is measured as the TLS handshake
+	if trace.ConnectStart != nil {
+		trace.ConnectStart("udp", addr)
+	}
+	packetConn, remoteAddr, err := t.dialer.DialPacket(ctx, addr)
+	if trace.ConnectDone != nil {
+		trace.ConnectDone("udp", addr, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if trace.TLSHandshakeStart != nil {
+		trace.TLSHandshakeStart()
+	}
+	conn, err := quic.DialEarly(ctx, packetConn, remoteAddr, tlsCfg, cfg)
+	if err == nil {
+		select {
+		case <-conn.HandshakeComplete():
+		case <-ctx.Done():
+			_ = conn.CloseWithError(0, "")
+			err = ctx.Err()
+		}
+	}
+	if err != nil {
+		_ = packetConn.Close()
+		if trace.TLSHandshakeDone != nil {
+			trace.TLSHandshakeDone(tls.ConnectionState{}, err)
+		}
+		return nil, err
+	}
+	if trace.TLSHandshakeDone != nil {
+		trace.TLSHandshakeDone(conn.ConnectionState().TLS, nil)
+	}
+
+	t.mx.Lock()
+	t.conns[addr] = conn
+	t.mx.Unlock()
+	go func() {
+		<-conn.Context().Done()
+		t.mx.Lock()
+		if t.conns[addr] == conn {
+			delete(t.conns, addr)
+		}
+		t.mx.Unlock()
+		_ = packetConn.Close()
+	}()
+
+	traceGotConn(trace, conn, false)
+	return conn, nil
+}
+
+// traceGotConn calls the GotConn hook for the QUIC connection. The request is
+// written on a new stream of the connection right after it's obtained, so the
+// WroteRequest hook is called as well.
+func traceGotConn(trace *httptrace.ClientTrace, conn quic.Connection, reused bool) {
+	if trace.GotConn != nil {
+		trace.GotConn(httptrace.GotConnInfo{Conn: quicConnAddrs{conn: conn}, Reused: reused})
+	}
+	if trace.WroteRequest != nil {
+		trace.WroteRequest(httptrace.WroteRequestInfo{})
+	}
+}
+
+// quicConnAddrs exposes the addresses of a QUIC connection as a net.Conn,
+// for the httptrace hooks. None of the other net.Conn methods can be used.
+type quicConnAddrs struct {
+	net.Conn
+	conn quic.Connection
+}
+
+func (c quicConnAddrs) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+func (c quicConnAddrs) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// authorityAddr returns the host:port address of the URL, with the default
+// port for its scheme if it doesn't have one, like net/http and http3 do.
+func authorityAddr(u *url.URL) string {
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		host, port = u.Host, "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(host, port)
+}
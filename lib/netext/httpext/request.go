@@ -3,21 +3,22 @@ package httpext
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
-	"net/http/cookiejar"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/Azure/go-ntlmssp"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/guregu/null.v3"
 
 	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/netext"
+	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 )
 
@@ -40,17 +41,26 @@ type Request struct {
 type ParsedHTTPRequest struct {
 	URL              *URL
 	Body             *bytes.Buffer
+	BodyReader       io.Reader // streams the body, for huge uploads, instead of Body
 	Req              *http.Request
 	Timeout          time.Duration
 	Auth             string
+	AuthMode         AuthMode
+	AuthCache        *AuthCache
 	Throw            bool
 	ResponseType     ResponseType
+	Protocol         Protocol
 	ResponseCallback func(int) bool
 	Compressions     []CompressionType
 	Redirects        null.Int
-	ActiveJar        *cookiejar.Jar
+	Retries          RetryPolicy
+	Connection       ConnectionMode
+	Hosts            *types.Hosts // overrides the DNS entries, for new connections only
+	TLSConfig        *tls.Config  // overrides the TLS config of the VU, for new connections only
+	ActiveJar        http.CookieJar
 	Cookies          map[string]*HTTPRequestCookie
 	TagsAndMeta      metrics.TagsAndMeta
+	Abort            <-chan struct{} // is closed when the script aborts the request, which is then tagged as aborted
 }
 
 // Matches non-compliant io.Closer implementations (e.g. zstd.Decoder)
@@ -106,6 +116,48 @@ func updateK6Response(k6Response *Response, finishedReq *finishedRequest) {
 	}
 }
 
+// wrapTimeoutError returns a timeout error if the reading of the response body
+// was interrupted because the request timed out.
+func wrapTimeoutError(err error) error {
+	if err != nil && errors.Is(err, context.DeadlineExceeded) {
+		// TODO This can be more specific that the timeout happened in the middle of the reading of the body
+		return NewK6Error(requestTimeoutErrorCode, requestTimeoutErrorCodeMsg, err)
+	}
+	return err
+}
+
+// withAbort returns a context which is also canceled when the abort channel
+// is closed, and its cancel function, which cancels the context too.
+func withAbort(
+	ctx context.Context, cancel context.CancelFunc, abort <-chan struct{},
+) (context.Context, context.CancelFunc) {
+	abortCtx, cancelAbort := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-abort:
+			cancelAbort()
+		case <-abortCtx.Done():
+		}
+	}()
+	return abortCtx, func() {
+		cancelAbort()
+		cancel()
+	}
+}
+
+// isAborted returns true if the abort channel is closed.
+func isAborted(abort <-chan struct{}) bool {
+	if abort == nil {
+		return false
+	}
+	select {
+	case <-abort:
+		return true
+	default:
+		return false
+	}
+}
+
 // MakeRequest makes http request for tor the provided ParsedHTTPRequest.
 //
 // TODO: split apart...
@@ -153,12 +205,30 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 		preq.Req.Body, _ = preq.Req.GetBody()
 	}
 
+	if preq.BodyReader != nil {
+		if len(preq.Compressions) > 0 {
+			return nil, errors.New("streamed request bodies can't be compressed")
+		}
+		if preq.Auth == "digest" || preq.Auth == "ntlm" {
+			return nil, fmt.Errorf("streamed request bodies can't be used with %s authentication, "+
+				"since the body has to be sent more than once", preq.Auth)
+		}
+		// the length of the body is unknown, unless the Content-Length header is set,
+		// and it can't be sent again, e.g. for redirects that preserve the body
+		preq.Req.Body = io.NopCloser(preq.BodyReader)
+		preq.Req.ContentLength = 0
+		preq.Req.GetBody = nil
+	}
+
 	if contentLengthHeader := preq.Req.Header.Get("Content-Length"); contentLengthHeader != "" {
 		// The content-length header was set by the user, delete it (since Go
 		// will set it automatically) and warn if there were differences
 		preq.Req.Header.Del("Content-Length")
 		length, err := strconv.Atoi(contentLengthHeader)
-		if err != nil || preq.Req.ContentLength != int64(length) {
+		if preq.BodyReader != nil && err == nil && length >= 0 {
+			// the length of the streamed body can be known only from the header
+			preq.Req.ContentLength = int64(length)
+		} else if err != nil || preq.Req.ContentLength != int64(length) {
 			state.Logger.Warnf(
 				"The specified Content-Length header %q in the %s request for %s "+
 					"doesn't match the actual request body length of %d, so it will be ignored!",
@@ -174,14 +244,47 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 		preq.TagsAndMeta.SetSystemTagOrMeta(metrics.TagName, preq.URL.Name)
 	}
 
+	resp, resErr := makeAttempts(ctx, state, preq, respReq)
+	if resp == nil {
+		return nil, resErr
+	}
+
+	if resErr != nil {
+		if preq.Throw { // if we are going to throw, we shouldn't log it
+			return nil, resErr
+		}
+
+		// Do *not* log errors about the context being cancelled.
+		select {
+		case <-ctx.Done():
+		default:
+			state.Logger.WithField("error", resErr).Warn("Request Failed")
+		}
+	}
+
+	return resp, nil
+}
+
+// sendRequest makes a single attempt of the prepared request, and returns its
+// response and the measurements of the attempt, with the error that the attempt
+// has failed with, if any. The response is nil if the request can't be made.
+//
+//nolint:cyclop, funlen, gocognit
+func sendRequest(
+	ctx context.Context, state *lib.State, preq *ParsedHTTPRequest, respReq *Request,
+) (*Response, *finishedRequest, error) {
 	// Check rate limit *after* we've prepared a request; no need to wait with that part.
 	if rpsLimit := state.RPSLimit; rpsLimit != nil {
 		if err := rpsLimit.Wait(ctx); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
-	tracerTransport := newTransport(ctx, state, &preq.TagsAndMeta, preq.ResponseCallback)
+	roundTripper, err := getRoundTripper(state, preq)
+	if err != nil {
+		return nil, nil, err
+	}
+	tracerTransport := newTransport(ctx, state, roundTripper, &preq.TagsAndMeta, preq.ResponseCallback, preq.Abort)
 	var transport http.RoundTripper = tracerTransport
 
 	if state.Options.HTTPDebug.String != "" {
@@ -204,29 +307,7 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 		}
 	}
 
-	if preq.Auth == "digest" {
-		// Until digest authentication is refactored, the first response will always
-		// be a 401 error, so we expect that.
-		if tracerTransport.responseCallback != nil {
-			originalResponseCallback := tracerTransport.responseCallback
-			tracerTransport.responseCallback = func(status int) bool {
-				tracerTransport.responseCallback = originalResponseCallback
-				return status == 401
-			}
-		}
-		transport = digestTransport{originalTransport: transport}
-	} else if preq.Auth == "ntlm" {
-		// The first response of NTLM auth may be a 401 error.
-		if tracerTransport.responseCallback != nil {
-			originalResponseCallback := tracerTransport.responseCallback
-			tracerTransport.responseCallback = func(status int) bool {
-				tracerTransport.responseCallback = originalResponseCallback
-				// ntlm is connection-level based so we could've already authorized the connection and to now reuse it
-				return status == 401 || originalResponseCallback(status)
-			}
-		}
-		transport = ntlmssp.Negotiator{RoundTripper: transport}
-	}
+	transport = wrapAuthTransport(preq, tracerTransport, transport)
 
 	resp := &Response{URL: preq.URL.URL, Request: respReq}
 	client := http.Client{
@@ -259,8 +340,22 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 		},
 	}
 
+	var stream *ResponseStream
 	reqCtx, cancelFunc := context.WithTimeout(ctx, preq.Timeout)
-	defer cancelFunc()
+	if preq.Abort != nil {
+		reqCtx, cancelFunc = withAbort(reqCtx, cancelFunc, preq.Abort)
+	}
+	if preq.Hosts != nil {
+		reqCtx = netext.WithHosts(reqCtx, preq.Hosts)
+	}
+	releaseConn := acquireConn(state, preq)
+	defer func() {
+		// the body of a stream is read after the request is returned
+		if stream == nil {
+			cancelFunc()
+			releaseConn()
+		}
+	}()
 	mreq := preq.Req.WithContext(reqCtx)
 	res, resErr := client.Do(mreq)
 
@@ -270,19 +365,30 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 	// unusable until https://github.com/golang/go/issues/31391 is fixed.
 	if res != nil && res.StatusCode == http.StatusSwitchingProtocols {
 		_ = res.Body.Close()
-		return nil, fmt.Errorf("unsupported response status: %s", res.Status)
+		return nil, nil, fmt.Errorf("unsupported response status: %s", res.Status)
 	}
 
-	if resErr == nil {
-		resp.Body, resErr = readResponseBody(state, preq.ResponseType, res, resErr)
-		if resErr != nil && errors.Is(resErr, context.DeadlineExceeded) {
-			// TODO This can be more specific that the timeout happened in the middle of the reading of the body
-			resErr = NewK6Error(requestTimeoutErrorCode, requestTimeoutErrorCodeMsg, resErr)
+	if resErr == nil && preq.ResponseType == ResponseTypeStream {
+		stream, resErr = newResponseStream(reqCtx, res, func(err error) {
+			defer releaseConn()
+			defer cancelFunc()
+			if finishedReq := tracerTransport.processLastSavedRequest(wrapTimeoutError(err)); finishedReq != nil {
+				updateK6Response(resp, finishedReq)
+			}
+		})
+		if stream != nil {
+			resp.Body = stream
 		}
+	} else if resErr == nil {
+		resp.Body, resErr = readResponseBody(state, preq.ResponseType, res, resErr)
+		resErr = wrapTimeoutError(resErr)
 	}
-	finishedReq := tracerTransport.processLastSavedRequest(wrapDecompressionError(resErr))
-	if finishedReq != nil {
-		updateK6Response(resp, finishedReq)
+	var finishedReq *finishedRequest
+	if stream == nil {
+		finishedReq = tracerTransport.processLastSavedRequest(wrapDecompressionError(resErr))
+		if finishedReq != nil {
+			updateK6Response(resp, finishedReq)
+		}
 	}
 
 	if resErr == nil {
@@ -322,25 +428,16 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 		}
 	}
 
-	if resErr != nil {
-		if preq.Throw { // if we are going to throw, we shouldn't log it
-			return nil, resErr
-		}
-
-		// Do *not* log errors about the context being cancelled.
-		select {
-		case <-ctx.Done():
-		default:
-			state.Logger.WithField("error", resErr).Warn("Request Failed")
-		}
+	if state.HARRecorder != nil && stream == nil {
+		recordHAR(state, preq, respReq, res, resp, finishedReq, resErr)
 	}
 
-	return resp, nil
+	return resp, finishedReq, resErr
 }
 
 // SetRequestCookies sets the cookies of the requests getting those cookies both from the jar and
 // from the reqCookies map. The Replace field of the HTTPRequestCookie will be taken into account
-func SetRequestCookies(req *http.Request, jar *cookiejar.Jar, reqCookies map[string]*HTTPRequestCookie) {
+func SetRequestCookies(req *http.Request, jar http.CookieJar, reqCookies map[string]*HTTPRequestCookie) {
 	replacedCookies := make(map[string]struct{})
 	for key, reqCookie := range reqCookies {
 		req.AddCookie(&http.Cookie{Name: key, Value: reqCookie.Value})
@@ -29,6 +29,11 @@ const (
 	// want to  measure, but we don't care about their responses' contents. This is the
 	// default value for all requests if the global discardResponseBodies is enablled.
 	ResponseTypeNone
+	// ResponseTypeStream causes k6 to return the response body as a ResponseStream,
+	// which is read incrementally instead of being buffered in memory, so it's suitable
+	// for huge downloads and for processing the data as it arrives. The request is
+	// measured until its body is fully read or closed.
+	ResponseTypeStream
 )
 
 // ResponseTimings is a struct to put all timings for a given HTTP response/request
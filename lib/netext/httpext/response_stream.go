@@ -0,0 +1,104 @@
+package httpext
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ResponseStream is the body of a response with ResponseTypeStream. The
+// request is finished, i.e. its metrics are emitted and the timings of its
+// response are set, when the stream is closed, after the body is fully read or
+// instead of reading the rest of it. So the response is updated only by the
+// goroutine that closes the stream, which has to close it even if the context
+// of the request is done, see Wait.
+type ResponseStream struct {
+	ctx      context.Context //nolint:containedctx
+	mx       sync.Mutex
+	reader   *readCloser
+	body     io.ReadCloser
+	done     bool  // the reading of the body has ended
+	err      error // the error that the reading of the body has ended with
+	closed   bool
+	finished chan struct{}
+	finish   func(error)
+}
+
+var _ io.ReadCloser = &ResponseStream{}
+
+// newResponseStream returns a stream of the response body, transparently
+// decompressing it. The finish function is called once, when the stream is
+// closed, with the error that the reading of the body has ended with, if any.
+func newResponseStream(ctx context.Context, resp *http.Response, finish func(error)) (*ResponseStream, error) {
+	reader, err := newDecompressingReader(resp)
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+
+	return &ResponseStream{
+		ctx:      ctx,
+		reader:   reader,
+		body:     resp.Body,
+		finished: make(chan struct{}),
+		finish:   finish,
+	}, nil
+}
+
+// Wait blocks until the reading of the body has ended, or until the context
+// of the request is done, e.g. because it has timed out. The stream has to be
+// closed after it returns, if it hasn't been already.
+func (s *ResponseStream) Wait() {
+	select {
+	case <-s.finished:
+	case <-s.ctx.Done():
+	}
+}
+
+// Read reads the next chunk of the body, it returns io.EOF
+// after the body is fully read or if the stream is closed.
+func (s *ResponseStream) Read(p []byte) (int, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if s.done {
+		return 0, io.EOF
+	}
+
+	n, err := s.reader.Read(p)
+	if errors.Is(err, io.EOF) {
+		s.end(nil)
+	} else if err != nil {
+		err = wrapDecompressionError(err)
+		s.end(err)
+	}
+	return n, err
+}
+
+// Close stops reading the body, the rest of it is discarded,
+// and it finishes the request.
+func (s *ResponseStream) Close() error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.end(nil)
+	if !s.closed {
+		s.closed = true
+		s.finish(s.err)
+	}
+	return nil
+}
+
+// end ends the reading of the body, with the provided error, if any.
+func (s *ResponseStream) end(err error) {
+	if s.done {
+		return
+	}
+	s.done = true
+	if cerr := s.reader.Close(); cerr != nil && err == nil {
+		err = wrapDecompressionError(cerr)
+	}
+	_ = s.body.Close()
+	s.err = err
+	close(s.finished)
+}
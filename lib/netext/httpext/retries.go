@@ -0,0 +1,129 @@
+package httpext
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+// RetryPolicy configures the automatic retries of a request. Each attempt of
+// the request is measured like a separate request, and each retry is counted
+// by the http_req_retries metric.
+type RetryPolicy struct {
+	// Count is the maximum number of retries, the request isn't retried if it's 0.
+	Count int64
+	// Backoff is the delay before the first retry, it's doubled for each next one.
+	Backoff time.Duration
+	// Statuses are the response status codes that the request is retried for.
+	Statuses []int
+	// NetworkErrors enables the retries for network errors, e.g. refused connections or timeouts.
+	NetworkErrors bool
+	// NonIdempotent enables the retries of requests with non-idempotent methods, like
+	// POST, which could have side effects if they were processed by the server.
+	NonIdempotent bool
+}
+
+// DefaultRetryPolicy returns the retry policy for the provided count of retries,
+// which retries requests with idempotent methods for network errors and for the
+// status codes of responses to overloaded or temporarily unavailable servers.
+func DefaultRetryPolicy(count int64) RetryPolicy {
+	return RetryPolicy{
+		Count:         count,
+		Backoff:       100 * time.Millisecond,
+		Statuses:      []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		NetworkErrors: true,
+	}
+}
+
+// isIdempotentMethod returns true for the methods that are idempotent per RFC 9110.
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// isNetworkError returns false for the errors that retrying the request won't
+// fix, e.g. blocked hosts or invalid certificates, and true for the rest.
+func isNetworkError(err error) bool {
+	code, _ := errorCodeForError(err)
+	switch code { //nolint:exhaustive
+	case invalidURLErrorCode, blackListedIPErrorCode, blockedHostnameErrorCode,
+		x509UnknownAuthorityErrorCode, x509HostnameErrorCode, responseDecompressionErrorCode:
+		return false
+	default:
+		return true
+	}
+}
+
+// shouldRetry returns true if the attempt of the request, which has
+// finished with the status and the error, should be retried.
+func (p RetryPolicy) shouldRetry(method string, status int, err error) bool {
+	if !p.NonIdempotent && !isIdempotentMethod(method) {
+		return false
+	}
+	if err != nil {
+		return p.NetworkErrors && isNetworkError(err)
+	}
+	for _, s := range p.Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// makeAttempts makes the request, retrying it according to its retry policy,
+// and returns the response and the error of the last attempt.
+// The response is nil if the request can't be made.
+func makeAttempts(
+	ctx context.Context, state *lib.State, preq *ParsedHTTPRequest, respReq *Request,
+) (*Response, error) {
+	if preq.Retries.Count > 0 && (preq.BodyReader != nil || preq.ResponseType == ResponseTypeStream) {
+		return nil, errors.New("requests with streamed bodies can't be retried")
+	}
+
+	backoff := preq.Retries.Backoff
+	for retry := int64(0); ; retry++ {
+		resp, finishedReq, resErr := sendRequest(ctx, state, preq, respReq)
+		if resp == nil || retry >= preq.Retries.Count || finishedReq == nil ||
+			!preq.Retries.shouldRetry(preq.Req.Method, resp.Status, resErr) {
+			return resp, resErr
+		}
+
+		state.Logger.WithError(resErr).Debugf("Retrying the %s request for %s with status %d in %s",
+			preq.Req.Method, resp.URL, resp.Status, backoff)
+		select {
+		case <-ctx.Done():
+			return resp, resErr
+		case <-preq.Abort: // the aborted requests aren't retried
+			return resp, resErr
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+
+		// the retry is tagged like the attempt that has failed
+		metrics.PushIfNotDone(ctx, state.Samples, metrics.Sample{
+			TimeSeries: metrics.TimeSeries{
+				Metric: state.BuiltinMetrics.HTTPReqRetries,
+				Tags:   finishedReq.trail.Tags,
+			},
+			Time:     time.Now(),
+			Metadata: finishedReq.trail.Metadata,
+			Value:    1,
+		})
+		if preq.Req.GetBody != nil {
+			body, err := preq.Req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			preq.Req.Body = body
+		}
+	}
+}
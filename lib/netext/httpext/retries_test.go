@@ -0,0 +1,184 @@
+package httpext
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+func TestMakeRequestRetries(t *testing.T) {
+	t.Parallel()
+
+	newServer := func(t *testing.T, failures int64, status int) (*httptest.Server, *int64) {
+		var attempts int64
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body := new(bytes.Buffer)
+			_, _ = body.ReadFrom(r.Body)
+			assert.Equal(t, r.Method == http.MethodPost, body.String() == "data")
+			if atomic.AddInt64(&attempts, 1) <= failures {
+				w.WriteHeader(status)
+				return
+			}
+			_, _ = w.Write([]byte("ok"))
+		}))
+		t.Cleanup(srv.Close)
+		return srv, &attempts
+	}
+
+	makeRequest := func(
+		t *testing.T, srv *httptest.Server, method string, policy RetryPolicy,
+	) (*Response, []metrics.SampleContainer, error) {
+		samples := make(chan metrics.SampleContainer, 100)
+		registry := metrics.NewRegistry()
+		state := &lib.State{
+			Options: lib.Options{
+				SystemTags: &metrics.DefaultSystemTagSet,
+			},
+			Transport:      srv.Client().Transport,
+			Samples:        samples,
+			Logger:         logrus.New(),
+			BufferPool:     lib.NewBufferPool(),
+			BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+			Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+		}
+		var body *bytes.Buffer
+		if method == http.MethodPost {
+			body = bytes.NewBufferString("data")
+		} else {
+			body = new(bytes.Buffer)
+		}
+		req, err := http.NewRequest(method, srv.URL, bytes.NewReader(body.Bytes()))
+		require.NoError(t, err)
+		preq := &ParsedHTTPRequest{
+			Req:         req,
+			URL:         &URL{u: req.URL, URL: srv.URL},
+			Body:        body,
+			Timeout:     10 * time.Second,
+			Retries:     policy,
+			TagsAndMeta: state.Tags.GetCurrentValues(),
+		}
+
+		res, err := MakeRequest(context.Background(), state, preq)
+		close(samples)
+		var containers []metrics.SampleContainer
+		for c := range samples {
+			containers = append(containers, c)
+		}
+		return res, containers, err
+	}
+
+	countRetries := func(containers []metrics.SampleContainer) (retries float64) {
+		for _, c := range containers {
+			for _, s := range c.GetSamples() {
+				if s.Metric.Name == metrics.HTTPReqRetriesName {
+					retries += s.Value
+					status, _ := s.Tags.Get("status")
+					assert.Equal(t, "503", status)
+				}
+			}
+		}
+		return retries
+	}
+
+	policy := DefaultRetryPolicy(3)
+	policy.Backoff = time.Millisecond
+
+	t.Run("retried", func(t *testing.T) {
+		t.Parallel()
+		srv, attempts := newServer(t, 2, http.StatusServiceUnavailable)
+		res, samples, err := makeRequest(t, srv, http.MethodGet, policy)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.Status)
+		assert.Equal(t, int64(3), atomic.LoadInt64(attempts))
+		assert.Equal(t, float64(2), countRetries(samples))
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		t.Parallel()
+		srv, attempts := newServer(t, 10, http.StatusServiceUnavailable)
+		res, samples, err := makeRequest(t, srv, http.MethodGet, policy)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, res.Status)
+		assert.Equal(t, int64(4), atomic.LoadInt64(attempts))
+		assert.Equal(t, float64(3), countRetries(samples))
+	})
+
+	t.Run("other status", func(t *testing.T) {
+		t.Parallel()
+		srv, attempts := newServer(t, 1, http.StatusInternalServerError)
+		res, samples, err := makeRequest(t, srv, http.MethodGet, policy)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, res.Status)
+		assert.Equal(t, int64(1), atomic.LoadInt64(attempts))
+		assert.Equal(t, float64(0), countRetries(samples))
+	})
+
+	t.Run("non-idempotent", func(t *testing.T) {
+		t.Parallel()
+		srv, attempts := newServer(t, 1, http.StatusServiceUnavailable)
+		res, samples, err := makeRequest(t, srv, http.MethodPost, policy)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, res.Status)
+		assert.Equal(t, int64(1), atomic.LoadInt64(attempts))
+		assert.Equal(t, float64(0), countRetries(samples))
+	})
+
+	t.Run("non-idempotent allowed", func(t *testing.T) {
+		t.Parallel()
+		srv, attempts := newServer(t, 1, http.StatusServiceUnavailable)
+		policy := policy
+		policy.NonIdempotent = true
+		res, samples, err := makeRequest(t, srv, http.MethodPost, policy)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.Status)
+		assert.Equal(t, int64(2), atomic.LoadInt64(attempts))
+		assert.Equal(t, float64(1), countRetries(samples))
+	})
+
+	t.Run("network error", func(t *testing.T) {
+		t.Parallel()
+		srv, _ := newServer(t, 0, 0)
+		srv.Close()
+		start := time.Now()
+		policy := policy
+		policy.Count, policy.Backoff = 2, 10*time.Millisecond
+		res, samples, err := makeRequest(t, srv, http.MethodGet, policy)
+		require.NoError(t, err)
+		assert.NotEmpty(t, res.Error)
+		assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+		var retries float64
+		for _, c := range samples {
+			for _, s := range c.GetSamples() {
+				if s.Metric.Name == metrics.HTTPReqRetriesName {
+					retries += s.Value
+				}
+			}
+		}
+		assert.Equal(t, float64(2), retries)
+	})
+}
+
+func TestRetryPolicyStreamedBodies(t *testing.T) {
+	t.Parallel()
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	preq := &ParsedHTTPRequest{
+		Req:        req,
+		URL:        &URL{u: req.URL, URL: "http://example.com"},
+		BodyReader: strings.NewReader("data"),
+		Retries:    DefaultRetryPolicy(1),
+	}
+	_, err = makeAttempts(context.Background(), &lib.State{}, preq, &Request{})
+	require.ErrorContains(t, err, "requests with streamed bodies can't be retried")
+}
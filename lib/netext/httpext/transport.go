@@ -19,8 +19,10 @@ import (
 type transport struct {
 	ctx              context.Context
 	state            *lib.State
+	roundTripper     http.RoundTripper
 	tagsAndMeta      *metrics.TagsAndMeta
 	responseCallback func(int) bool
+	abort            <-chan struct{}
 
 	lastRequest     *unfinishedRequest
 	lastRequestLock *sync.Mutex
@@ -51,20 +53,24 @@ type finishedRequest struct {
 var _ http.RoundTripper = &transport{}
 
 // newTransport returns a new http.RoundTripper implementation that wraps around
-// the provided state's Transport. It uses a httpext.Tracer to measure all HTTP
+// the provided round tripper, one of the state's transports. It uses a httpext.Tracer to measure all HTTP
 // requests made through it and annotates and emits the recorded metric samples
 // through the state.Samples channel.
 func newTransport(
 	ctx context.Context,
 	state *lib.State,
+	roundTripper http.RoundTripper,
 	tagsAndMeta *metrics.TagsAndMeta,
 	responseCallback func(int) bool,
+	abort <-chan struct{},
 ) *transport {
 	return &transport{
 		ctx:              ctx,
 		state:            state,
+		roundTripper:     roundTripper,
 		tagsAndMeta:      tagsAndMeta,
 		responseCallback: responseCallback,
+		abort:            abort,
 		lastRequestLock:  new(sync.Mutex),
 	}
 }
@@ -91,7 +97,11 @@ func (t *transport) measureAndEmitMetrics(unfReq *unfinishedRequest) *finishedRe
 		// If the user *didn't* manually set a `name` tag value and didn't use
 		// the http.url template literal helper to have k6 automatically set
 		// it (see `lib/netext/httpext.MakeRequest()`), we will use the cleaned
-		// URL value as the value of both `name` and `url` tags.
+		// URL value as the value of both `name` and `url` tags. With the
+		// urlGrouping option, its ID-like parts are replaced beforehand.
+		if t.state.Options.URLGrouping.Bool {
+			cleanURL = GroupURL(cleanURL)
+		}
 		tagsAndMeta.SetSystemTagOrMetaIfEnabled(enabledTags, metrics.TagName, cleanURL)
 		tagsAndMeta.SetSystemTagOrMetaIfEnabled(enabledTags, metrics.TagURL, cleanURL)
 	} else {
@@ -128,6 +138,9 @@ func (t *transport) measureAndEmitMetrics(unfReq *unfinishedRequest) *finishedRe
 			tagsAndMeta.SetSystemTagOrMeta(metrics.TagIP, ip)
 		}
 	}
+	if isAborted(t.abort) {
+		tagsAndMeta.SetSystemTagOrMetaIfEnabled(enabledTags, metrics.TagAborted, "true")
+	}
 	var failed float64
 	if t.responseCallback != nil {
 		var statusCode int
@@ -203,7 +216,7 @@ func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
 	ctx := req.Context()
 	tracer := &Tracer{}
 	reqWithTracer := req.WithContext(httptrace.WithClientTrace(ctx, tracer.Trace()))
-	resp, err := t.state.Transport.RoundTrip(reqWithTracer)
+	resp, err := t.roundTripper.RoundTrip(reqWithTracer)
 
 	var netError net.Error
 	if errors.As(err, &netError) && netError.Timeout() {
@@ -7,10 +7,71 @@ import (
 	"testing"
 
 	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/metrics"
 )
 
+func TestMeasureAndEmitMetricsURLGrouping(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		urlGrouping null.Bool
+		nameTag     string
+		expected    string
+	}{
+		"Disabled":    {expected: "https://example.com/users/1234"},
+		"Enabled":     {urlGrouping: null.BoolFrom(true), expected: "https://example.com/users/${}"},
+		"ExplicitTag": {urlGrouping: null.BoolFrom(true), nameTag: "users", expected: "users"},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			samples := make(chan metrics.SampleContainer, 10)
+			registry := metrics.NewRegistry()
+			tags := registry.RootTagSet()
+			if tc.nameTag != "" {
+				tags = tags.With(metrics.TagName.String(), tc.nameTag)
+			}
+			tr := transport{
+				state: &lib.State{
+					Options: lib.Options{
+						SystemTags:  &metrics.DefaultSystemTagSet,
+						URLGrouping: tc.urlGrouping,
+					},
+					BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+					Samples:        samples,
+					Logger:         logrus.New(),
+				},
+				ctx:         context.Background(),
+				tagsAndMeta: &metrics.TagsAndMeta{Tags: tags},
+			}
+
+			u, err := url.Parse("https://example.com/users/1234")
+			require.NoError(t, err)
+			tr.measureAndEmitMetrics(&unfinishedRequest{
+				tracer:   &Tracer{},
+				response: &http.Response{StatusCode: http.StatusOK},
+				request:  &http.Request{URL: u},
+			})
+
+			sampleContainer := <-samples
+			for _, sample := range sampleContainer.GetSamples() {
+				nameTag, _ := sample.Tags.Get(metrics.TagName.String())
+				assert.Equal(t, tc.expected, nameTag)
+				urlTag, _ := sample.Tags.Get(metrics.TagURL.String())
+				assert.Equal(t, tc.expected, urlTag)
+			}
+		})
+	}
+}
+
 func BenchmarkMeasureAndEmitMetrics(b *testing.B) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
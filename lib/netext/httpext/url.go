@@ -3,6 +3,7 @@ package httpext
 import (
 	"fmt"
 	"net/url"
+	"strings"
 )
 
 // A URL wraps net.URL, and preserves the template (if any) the URL was constructed from.
@@ -67,3 +68,92 @@ func ToURL(u interface{}) (URL, error) {
 			fmt.Sprintf("%s: '#%v'", invalidURLErrorCodeMsg, u), nil)
 	}
 }
+
+// urlGroupPlaceholder replaces the ID-like parts of the grouped URLs, it's
+// the same placeholder the http.url template literal helper uses.
+const urlGroupPlaceholder = "${}"
+
+// GroupURL returns the provided URL with all of its ID-like path segments and
+// query values, like numbers, UUIDs and hashes, replaced by ${}. It's meant to
+// keep the cardinality of the name and url metric tags low, when the requested
+// URLs weren't templated with the http.url helper.
+func GroupURL(rawURL string) string {
+	if _, err := url.Parse(rawURL); err != nil {
+		return rawURL
+	}
+
+	withoutFragment, fragment, hasFragment := strings.Cut(rawURL, "#")
+	withoutQuery, query, hasQuery := strings.Cut(withoutFragment, "?")
+
+	// The path is split from the raw URL, instead of using the parsed one,
+	// so that its escaping and any existing ${} placeholders are preserved
+	pathStart := 0
+	if i := strings.Index(withoutQuery, "//"); i >= 0 {
+		pathStart = len(withoutQuery)
+		if j := strings.IndexByte(withoutQuery[i+2:], '/'); j >= 0 {
+			pathStart = i + 2 + j
+		}
+	}
+	path := withoutQuery[pathStart:]
+
+	grouped := false
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isIDLike(segment) {
+			segments[i] = urlGroupPlaceholder
+			grouped = true
+		}
+	}
+
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		if key, value, ok := strings.Cut(param, "="); ok && isIDLike(value) {
+			params[i] = key + "=" + urlGroupPlaceholder
+			grouped = true
+		}
+	}
+
+	if !grouped {
+		return rawURL
+	}
+
+	result := withoutQuery[:pathStart] + strings.Join(segments, "/")
+	if hasQuery {
+		result += "?" + strings.Join(params, "&")
+	}
+	if hasFragment {
+		result += "#" + fragment
+	}
+	return result
+}
+
+// isIDLike returns true if the URL part looks like a unique identifier: a
+// number, a UUID, or a long hexadecimal string like the hashes.
+func isIDLike(part string) bool {
+	if part == "" {
+		return false
+	}
+
+	digits, hexLetters, dashes := 0, 0, 0
+	for _, c := range part {
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F'):
+			hexLetters++
+		case c == '-':
+			dashes++
+		default:
+			return false
+		}
+	}
+
+	switch {
+	case digits == len(part):
+		return true
+	case dashes == 4 && len(part) == 36: // UUID
+		return part[8] == '-' && part[13] == '-' && part[18] == '-' && part[23] == '-'
+	default:
+		return dashes == 0 && len(part) >= 16 && digits > 0
+	}
+}
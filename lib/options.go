@@ -19,6 +19,19 @@ import (
 // iterations+vus, or stages)
 const DefaultScenarioName = "default"
 
+// The policies for the in-flight iterations when the test run is aborted, e.g.
+// by a threshold, by Ctrl+C or via the REST API.
+const (
+	// AbortPolicyInterrupt interrupts the in-flight iterations immediately.
+	AbortPolicyInterrupt = "interrupt"
+	// AbortPolicyGraceful lets the in-flight iterations finish, but only
+	// within the abort grace period.
+	AbortPolicyGraceful = "graceful"
+	// AbortPolicyFinish lets the in-flight iterations finish, within the
+	// gracefulStop of their scenarios.
+	AbortPolicyFinish = "finish"
+)
+
 // DefaultSummaryTrendStats are the default trend columns shown in the test summary output
 //
 //nolint:gochecknoglobals
@@ -243,6 +256,11 @@ type Options struct {
 	NoTeardown      null.Bool          `json:"noTeardown" envconfig:"K6_NO_TEARDOWN"`
 	TeardownTimeout types.NullDuration `json:"teardownTimeout" envconfig:"K6_TEARDOWN_TIMEOUT"`
 
+	// What happens with the in-flight iterations when the test run is aborted, and how
+	// long they can keep running with the graceful policy before they're interrupted.
+	AbortPolicy      null.String        `json:"abortPolicy" envconfig:"K6_ABORT_POLICY"`
+	AbortGracePeriod types.NullDuration `json:"abortGracePeriod" envconfig:"K6_ABORT_GRACE_PERIOD"`
+
 	// Limit HTTP requests per second.
 	RPS null.Int `json:"rps" envconfig:"K6_RPS"`
 
@@ -278,6 +296,9 @@ type Options struct {
 	// metric on a nonexistent metric named 'real_metric{tagA:valueA,tagB:valueB}'.
 	Thresholds map[string]metrics.Thresholds `json:"thresholds" envconfig:"K6_THRESHOLDS"`
 
+	// URL the details of a threshold are POST-ed to, when it crosses into a failing state.
+	ThresholdsWebhook null.String `json:"thresholdsWebhook" envconfig:"K6_THRESHOLDS_WEBHOOK"`
+
 	// Blacklist IP ranges that tests may not contact. Mainly useful in hosted setups.
 	BlacklistIPs []*IPNet `json:"blacklistIPs" envconfig:"K6_BLACKLIST_IPS"`
 
@@ -290,6 +311,14 @@ type Options struct {
 	// Disable keep-alive connections
 	NoConnectionReuse null.Bool `json:"noConnectionReuse" envconfig:"K6_NO_CONNECTION_REUSE"`
 
+	// MaxConnectionsPerHost limits the connections that each VU can have open to
+	// the same host, the requests wait for a free connection over the limit.
+	MaxConnectionsPerHost null.Int `json:"maxConnectionsPerHost" envconfig:"K6_MAX_CONNECTIONS_PER_HOST"`
+
+	// MaxIdleConnections limits the idle keep-alive connections that each VU
+	// keeps open, it's the same as the batch option by default.
+	MaxIdleConnections null.Int `json:"maxIdleConnections" envconfig:"K6_MAX_IDLE_CONNECTIONS"`
+
 	// Do not reuse connections between VU iterations. This gives more realistic results (depending
 	// on what you're looking for), but you need to raise various kernel limits or you'll get
 	// errors about running out of file handles or sockets, or being unable to bind addresses.
@@ -309,6 +338,28 @@ type Options struct {
 	// Summary time unit for summary metrics (response times) in CLI output
 	SummaryTimeUnit null.String `json:"summaryTimeUnit" envconfig:"K6_SUMMARY_TIME_UNIT"`
 
+	// The max number of values stored by each trend metric for calculating
+	// the percentiles, beyond it the percentiles are estimated from a random sample.
+	// Zero or unset means no limit, so the percentiles are always exact.
+	TrendSinkMaxValues null.Int `json:"trendSinkMaxValues" envconfig:"K6_TREND_SINK_MAX_VALUES"`
+
+	// The number of unique time series, i.e. metric and tag set combinations, beyond which
+	// k6 warns about the high cardinality of the test metrics.
+	TimeSeriesLimit null.Int `json:"timeSeriesLimit" envconfig:"K6_TIME_SERIES_LIMIT"`
+
+	// Replace the ID-like parts of the requested URLs with ${} in the name and url tags,
+	// unless a name was explicitly set, so un-templated URLs don't explode the cardinality.
+	URLGrouping null.Bool `json:"urlGrouping" envconfig:"K6_URL_GROUPING"`
+
+	// The time after its last update beyond which a gauge metric is considered stale,
+	// so it isn't reported as current by the REST API and the end-of-test summary.
+	// Zero or unset means that the gauges never expire.
+	GaugeTTL types.NullDuration `json:"gaugeTTL" envconfig:"K6_GAUGE_TTL"`
+
+	// Don't send the metric samples from the warm-up periods of the scenarios to
+	// the outputs. They are never used for the thresholds and the summary.
+	NoWarmupExport null.Bool `json:"noWarmupExport" envconfig:"K6_NO_WARMUP_EXPORT"`
+
 	// Which system tags to include with metrics ("method", "vu" etc.)
 	// Use pointer for identifying whether user provide any tag or not.
 	SystemTags *metrics.SystemTagSet `json:"systemTags" envconfig:"K6_SYSTEM_TAGS"`
@@ -322,12 +373,24 @@ type Options struct {
 	// Do not reset cookies after a VU iteration
 	NoCookiesReset null.Bool `json:"noCookiesReset" envconfig:"K6_NO_COOKIES_RESET"`
 
+	// Cookies that the VU cookie jars are seeded with, every time they are reset
+	Cookies NullCookies `json:"cookies" envconfig:"K6_COOKIES"`
+
 	// Discard Http Responses Body
 	DiscardResponseBodies null.Bool `json:"discardResponseBodies" envconfig:"K6_DISCARD_RESPONSE_BODIES"`
 
 	// Redirect console logging to a file
 	ConsoleOutput null.String `json:"-" envconfig:"K6_CONSOLE_OUTPUT"`
 
+	// Record the HTTP requests into a HAR file
+	HAROutput null.String `json:"-" envconfig:"K6_HAR_OUTPUT"`
+
+	// The ratio of the iterations, from 0 to 1, whose HTTP requests are recorded
+	HARSampleRate null.Float `json:"harSampleRate" envconfig:"K6_HAR_SAMPLE_RATE"`
+
+	// Only the HTTP requests that have all of these tags are recorded
+	HARTags map[string]string `json:"harTags" envconfig:"K6_HAR_TAGS"`
+
 	// Specify client IP ranges and/or CIDR from which VUs will make requests
 	LocalIPs types.NullIPPool `json:"-" envconfig:"K6_LOCAL_IPS"`
 }
@@ -401,6 +464,12 @@ func (o Options) Apply(opts Options) Options {
 	if opts.TeardownTimeout.Valid {
 		o.TeardownTimeout = opts.TeardownTimeout
 	}
+	if opts.AbortPolicy.Valid {
+		o.AbortPolicy = opts.AbortPolicy
+	}
+	if opts.AbortGracePeriod.Valid {
+		o.AbortGracePeriod = opts.AbortGracePeriod
+	}
 	if opts.RPS.Valid {
 		o.RPS = opts.RPS
 	}
@@ -437,6 +506,9 @@ func (o Options) Apply(opts Options) Options {
 	if opts.Thresholds != nil {
 		o.Thresholds = opts.Thresholds
 	}
+	if opts.ThresholdsWebhook.Valid {
+		o.ThresholdsWebhook = opts.ThresholdsWebhook
+	}
 	if opts.BlacklistIPs != nil {
 		o.BlacklistIPs = opts.BlacklistIPs
 	}
@@ -452,12 +524,21 @@ func (o Options) Apply(opts Options) Options {
 	if opts.NoVUConnectionReuse.Valid {
 		o.NoVUConnectionReuse = opts.NoVUConnectionReuse
 	}
+	if opts.MaxConnectionsPerHost.Valid {
+		o.MaxConnectionsPerHost = opts.MaxConnectionsPerHost
+	}
+	if opts.MaxIdleConnections.Valid {
+		o.MaxIdleConnections = opts.MaxIdleConnections
+	}
 	if opts.MinIterationDuration.Valid {
 		o.MinIterationDuration = opts.MinIterationDuration
 	}
 	if opts.NoCookiesReset.Valid {
 		o.NoCookiesReset = opts.NoCookiesReset
 	}
+	if opts.Cookies.Valid {
+		o.Cookies = opts.Cookies
+	}
 	if opts.External != nil {
 		o.External = opts.External
 	}
@@ -467,6 +548,21 @@ func (o Options) Apply(opts Options) Options {
 	if opts.SummaryTimeUnit.Valid {
 		o.SummaryTimeUnit = opts.SummaryTimeUnit
 	}
+	if opts.TrendSinkMaxValues.Valid {
+		o.TrendSinkMaxValues = opts.TrendSinkMaxValues
+	}
+	if opts.TimeSeriesLimit.Valid {
+		o.TimeSeriesLimit = opts.TimeSeriesLimit
+	}
+	if opts.URLGrouping.Valid {
+		o.URLGrouping = opts.URLGrouping
+	}
+	if opts.GaugeTTL.Valid {
+		o.GaugeTTL = opts.GaugeTTL
+	}
+	if opts.NoWarmupExport.Valid {
+		o.NoWarmupExport = opts.NoWarmupExport
+	}
 	if opts.SystemTags != nil {
 		o.SystemTags = opts.SystemTags
 	}
@@ -482,6 +578,15 @@ func (o Options) Apply(opts Options) Options {
 	if opts.ConsoleOutput.Valid {
 		o.ConsoleOutput = opts.ConsoleOutput
 	}
+	if opts.HAROutput.Valid {
+		o.HAROutput = opts.HAROutput
+	}
+	if opts.HARSampleRate.Valid {
+		o.HARSampleRate = opts.HARSampleRate
+	}
+	if len(opts.HARTags) > 0 {
+		o.HARTags = opts.HARTags
+	}
 	if opts.LocalIPs.Valid {
 		o.LocalIPs = opts.LocalIPs
 	}
@@ -517,6 +622,18 @@ func (o Options) Validate() []error {
 					o.ExecutionSegment, o.ExecutionSegmentSequence))
 		}
 	}
+	switch o.AbortPolicy.String {
+	case "", AbortPolicyInterrupt, AbortPolicyGraceful, AbortPolicyFinish:
+	default:
+		errors = append(errors, fmt.Errorf("invalid abortPolicy '%s', it must be %s, %s or %s",
+			o.AbortPolicy.String, AbortPolicyInterrupt, AbortPolicyGraceful, AbortPolicyFinish))
+	}
+	if o.AbortGracePeriod.Duration < 0 {
+		errors = append(errors, fmt.Errorf("the abortGracePeriod can't be negative"))
+	}
+	if o.HARSampleRate.Valid && (o.HARSampleRate.Float64 < 0 || o.HARSampleRate.Float64 > 1) {
+		errors = append(errors, fmt.Errorf("the harSampleRate must be between 0 and 1"))
+	}
 	return append(errors, o.Scenarios.Validate()...)
 }
 
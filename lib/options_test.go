@@ -411,12 +411,48 @@ func TestOptions(t *testing.T) {
 		assert.True(t, opts.NoVUConnectionReuse.Valid)
 		assert.True(t, opts.NoVUConnectionReuse.Bool)
 	})
+	t.Run("MaxConnectionsPerHost", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{MaxConnectionsPerHost: null.IntFrom(12345)})
+		assert.True(t, opts.MaxConnectionsPerHost.Valid)
+		assert.Equal(t, int64(12345), opts.MaxConnectionsPerHost.Int64)
+	})
+	t.Run("MaxIdleConnections", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{MaxIdleConnections: null.IntFrom(12345)})
+		assert.True(t, opts.MaxIdleConnections.Valid)
+		assert.Equal(t, int64(12345), opts.MaxIdleConnections.Int64)
+	})
+	t.Run("NoWarmupExport", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{NoWarmupExport: null.BoolFrom(true)})
+		assert.True(t, opts.NoWarmupExport.Valid)
+		assert.True(t, opts.NoWarmupExport.Bool)
+	})
 	t.Run("NoCookiesReset", func(t *testing.T) {
 		t.Parallel()
 		opts := Options{}.Apply(Options{NoCookiesReset: null.BoolFrom(true)})
 		assert.True(t, opts.NoCookiesReset.Valid)
 		assert.True(t, opts.NoCookiesReset.Bool)
 	})
+	t.Run("Cookies", func(t *testing.T) {
+		t.Parallel()
+		cookies := []ExportedCookie{{Name: "session", Value: "abc", Domain: "example.com", Path: "/"}}
+		opts := Options{}.Apply(Options{Cookies: NullCookies{Cookies: cookies, Valid: true}})
+		assert.True(t, opts.Cookies.Valid)
+		assert.Equal(t, cookies, opts.Cookies.Cookies)
+	})
+	t.Run("HAR", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{
+			HAROutput:     null.StringFrom("out.har"),
+			HARSampleRate: null.FloatFrom(0.1),
+			HARTags:       map[string]string{"expected_response": "false"},
+		})
+		assert.Equal(t, null.StringFrom("out.har"), opts.HAROutput)
+		assert.Equal(t, null.FloatFrom(0.1), opts.HARSampleRate)
+		assert.Equal(t, map[string]string{"expected_response": "false"}, opts.HARTags)
+	})
 	t.Run("BlacklistIPs", func(t *testing.T) {
 		t.Parallel()
 		opts := Options{}.Apply(Options{
@@ -487,6 +523,31 @@ func TestOptions(t *testing.T) {
 		assert.NotNil(t, opts.Thresholds)
 		assert.NotEmpty(t, opts.Thresholds)
 	})
+	t.Run("ThresholdsWebhook", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{ThresholdsWebhook: null.StringFrom("https://hooks.example.com/k6")})
+		assert.True(t, opts.ThresholdsWebhook.Valid)
+		assert.Equal(t, "https://hooks.example.com/k6", opts.ThresholdsWebhook.String)
+	})
+	t.Run("AbortPolicy", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{
+			AbortPolicy:      null.StringFrom(AbortPolicyGraceful),
+			AbortGracePeriod: types.NullDurationFrom(10 * time.Second),
+		})
+		assert.Equal(t, null.StringFrom("graceful"), opts.AbortPolicy)
+		assert.Equal(t, types.NullDurationFrom(10*time.Second), opts.AbortGracePeriod)
+		assert.Empty(t, opts.Validate())
+
+		opts = opts.Apply(Options{
+			AbortPolicy:      null.StringFrom("wait"),
+			AbortGracePeriod: types.NullDurationFrom(-time.Second),
+		})
+		errs := opts.Validate()
+		require.Len(t, errs, 2)
+		assert.EqualError(t, errs[0], "invalid abortPolicy 'wait', it must be interrupt, graceful or finish")
+		assert.EqualError(t, errs[1], "the abortGracePeriod can't be negative")
+	})
 	t.Run("External", func(t *testing.T) {
 		t.Parallel()
 		ext := map[string]json.RawMessage{"a": json.RawMessage("1")}
@@ -621,6 +682,14 @@ func TestOptionsEnv(t *testing.T) {
 			"true":  null.BoolFrom(true),
 			"false": null.BoolFrom(false),
 		},
+		{"AbortPolicy", "K6_ABORT_POLICY"}: {
+			"":         null.String{},
+			"graceful": null.StringFrom("graceful"),
+		},
+		{"AbortGracePeriod", "K6_ABORT_GRACE_PERIOD"}: {
+			"":    types.NullDuration{},
+			"10s": types.NullDurationFrom(10 * time.Second),
+		},
 		{"InsecureSkipTLSVerify", "K6_INSECURE_SKIP_TLS_VERIFY"}: {
 			"":      null.Bool{},
 			"true":  null.BoolFrom(true),
@@ -653,6 +722,32 @@ func TestOptionsEnv(t *testing.T) {
 			"true":  null.BoolFrom(true),
 			"false": null.BoolFrom(false),
 		},
+		{"ThresholdsWebhook", "K6_THRESHOLDS_WEBHOOK"}: {
+			"":                             null.String{},
+			"https://hooks.example.com/k6": null.StringFrom("https://hooks.example.com/k6"),
+		},
+		{"TrendSinkMaxValues", "K6_TREND_SINK_MAX_VALUES"}: {
+			"":       null.Int{},
+			"100000": null.IntFrom(100000),
+		},
+		{"TimeSeriesLimit", "K6_TIME_SERIES_LIMIT"}: {
+			"":      null.Int{},
+			"50000": null.IntFrom(50000),
+		},
+		{"URLGrouping", "K6_URL_GROUPING"}: {
+			"":      null.Bool{},
+			"true":  null.BoolFrom(true),
+			"false": null.BoolFrom(false),
+		},
+		{"GaugeTTL", "K6_GAUGE_TTL"}: {
+			"":   types.NullDuration{},
+			"5m": types.NullDurationFrom(5 * time.Minute),
+		},
+		{"NoWarmupExport", "K6_NO_WARMUP_EXPORT"}: {
+			"":      null.Bool{},
+			"true":  null.BoolFrom(true),
+			"false": null.BoolFrom(false),
+		},
 		{"NoCookiesReset", "K6_NO_COOKIES_RESET"}: {
 			"":      null.Bool{},
 			"true":  null.BoolFrom(true),
@@ -2,6 +2,7 @@ package lib
 
 import (
 	"context"
+	"errors"
 	"io"
 	"time"
 
@@ -12,10 +13,20 @@ import (
 type ActiveVU interface {
 	// Run the configured exported function in the VU once. The only
 	// way to interrupt the execution is to cancel the context given
-	// to InitializedVU.Activate()
+	// to InitializedVU.Activate(), or for the iteration to exceed the
+	// MaxIterationDuration given there.
 	RunOnce() error
 }
 
+// ErrMaxIterationDurationExceeded is returned by ActiveVU.RunOnce() when the
+// iteration was interrupted because it exceeded its MaxIterationDuration.
+var ErrMaxIterationDurationExceeded = errors.New("the iteration exceeded the maxIterationDuration")
+
+// ErrIterationSkipped interrupts the JS runtime when the script skips the
+// rest of the iteration, with iteration.skipRemaining() in the k6/execution
+// module. Such an iteration ends early, but it isn't an error.
+var ErrIterationSkipped = errors.New("the rest of the iteration was skipped")
+
 // InitializedVU represents a virtual user ready for work. It needs to be
 // activated (i.e. given a context) before it can actually be used. Activation
 // also requires a callback function, which will be called when the supplied
@@ -36,6 +47,8 @@ type VUActivationParams struct {
 	Env, Tags                map[string]string
 	Exec, Scenario           string
 	GetNextIterationCounters func() (uint64, uint64)
+	MaxIterationDuration     time.Duration // no limit if 0
+	WarmupUntil              time.Time     // no warm-up if zero
 }
 
 // A Runner is a factory for VUs. It should precompute as much as possible upon
@@ -89,6 +102,15 @@ type Runner interface {
 	HandleSummary(context.Context, *Summary) (map[string]io.Reader, error)
 }
 
+// FunctionEvaluator is an optional interface implemented by the runners that
+// can call the exported functions of the script outside of the iterations,
+// for computing values needed by the executors.
+type FunctionEvaluator interface {
+	// EvaluateFunction calls the given exported function once for each of
+	// the arguments, in order, and returns the numbers it has returned.
+	EvaluateFunction(ctx context.Context, name string, args []float64) ([]float64, error)
+}
+
 // UIState describes the state of the UI, which might influence what
 // handleSummary() returns.
 type UIState struct {
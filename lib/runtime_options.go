@@ -41,6 +41,17 @@ type RuntimeOptions struct {
 	NoSummary     null.Bool   `json:"noSummary"`
 	SummaryExport null.String `json:"summaryExport"`
 	KeyWriter     null.String `json:"-"`
+
+	// The lockfile of the remote modules, and whether the modules missing
+	// from it can't be loaded
+	ImportsLockfile null.String `json:"-"`
+	FrozenImports   null.Bool   `json:"-"`
+
+	// Whether the scripts can write files with k6/experimental/fs, the
+	// directory they are confined to, and the quota of the written bytes
+	FSWrite      null.Bool   `json:"-"`
+	FSWriteDir   null.String `json:"-"`
+	FSWriteQuota null.String `json:"-"`
 }
 
 // ValidateCompatibilityMode checks if the provided val is a valid compatibility mode
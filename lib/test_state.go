@@ -18,6 +18,23 @@ type TestPreInitState struct {
 	KeyLogger      io.Writer
 	LookupEnv      func(key string) (val string, ok bool)
 	Logger         logrus.FieldLogger
+	// ImportsVerifier optionally checks the remote modules, e.g. against
+	// the imports lockfile.
+	ImportsVerifier ImportsVerifier
+	// ConnStats is the optional tracker of the HTTP connections of all the
+	// VUs, their gauges are emitted periodically by the execution scheduler.
+	ConnStats *ConnStats
+}
+
+// ImportsVerifier checks the remote modules imported by a test, e.g. against
+// the resolved URLs and the integrity hashes recorded in the imports lockfile.
+type ImportsVerifier interface {
+	// LockedURL returns the URL the module was resolved to before, if any.
+	LockedURL(specifier string) (string, bool)
+	// Verify checks the data of the module fetched from the URL, or records
+	// it. The URL is empty for the modules which weren't fetched, e.g. the
+	// ones of an archive, which are only checked.
+	Verify(specifier, url string, data []byte) error
 }
 
 // TestRunState contains the pre-init state as well as all of the state and
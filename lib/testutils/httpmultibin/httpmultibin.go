@@ -225,6 +225,9 @@ type GRPCStub struct {
 	grpctest.TestServiceServer
 	EmptyCallFunc func(context.Context, *grpctest.Empty) (*grpctest.Empty, error)
 	UnaryCallFunc func(context.Context, *grpctest.SimpleRequest) (*grpctest.SimpleResponse, error)
+
+	StreamingInputCallFunc func(grpctest.TestService_StreamingInputCallServer) error
+	FullDuplexCallFunc     func(grpctest.TestService_FullDuplexCallServer) error
 }
 
 // EmptyCall implements the interface for the gRPC TestServiceServer
@@ -253,12 +256,20 @@ func (*GRPCStub) StreamingOutputCall(*grpctest.StreamingOutputCallRequest,
 }
 
 // StreamingInputCall implements the interface for the gRPC TestServiceServer
-func (*GRPCStub) StreamingInputCall(grpctest.TestService_StreamingInputCallServer) error {
+func (s *GRPCStub) StreamingInputCall(stream grpctest.TestService_StreamingInputCallServer) error {
+	if s.StreamingInputCallFunc != nil {
+		return s.StreamingInputCallFunc(stream)
+	}
+
 	return status.Errorf(codes.Unimplemented, "method StreamingInputCall not implemented")
 }
 
 // FullDuplexCall implements the interface for the gRPC TestServiceServer
-func (*GRPCStub) FullDuplexCall(grpctest.TestService_FullDuplexCallServer) error {
+func (s *GRPCStub) FullDuplexCall(stream grpctest.TestService_FullDuplexCallServer) error {
+	if s.FullDuplexCallFunc != nil {
+		return s.FullDuplexCallFunc(stream)
+	}
+
 	return status.Errorf(codes.Unimplemented, "method FullDuplexCall not implemented")
 }
 
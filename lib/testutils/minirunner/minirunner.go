@@ -2,6 +2,8 @@ package minirunner
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 
 	"go.k6.io/k6/lib"
@@ -10,9 +12,10 @@ import (
 
 // Ensure mock implementations conform to the interfaces.
 var (
-	_ lib.Runner        = &MiniRunner{}
-	_ lib.InitializedVU = &VU{}
-	_ lib.ActiveVU      = &ActiveVU{}
+	_ lib.Runner            = &MiniRunner{}
+	_ lib.FunctionEvaluator = &MiniRunner{}
+	_ lib.InitializedVU     = &VU{}
+	_ lib.ActiveVU          = &ActiveVU{}
 )
 
 // MiniRunner partially implements the lib.Runner interface, but instead of
@@ -23,6 +26,7 @@ type MiniRunner struct {
 	SetupFn         func(ctx context.Context, out chan<- metrics.SampleContainer) ([]byte, error)
 	TeardownFn      func(ctx context.Context, out chan<- metrics.SampleContainer) error
 	HandleSummaryFn func(context.Context, *lib.Summary) (map[string]io.Reader, error)
+	EvaluateFn      func(ctx context.Context, name string, arg float64) (float64, error)
 
 	SetupData []byte
 
@@ -122,6 +126,23 @@ func (r *MiniRunner) HandleSummary(ctx context.Context, s *lib.Summary) (map[str
 	return nil, nil
 }
 
+// EvaluateFunction calls the specified evaluate function for every argument,
+// it returns an error if the function isn't supplied.
+func (r *MiniRunner) EvaluateFunction(ctx context.Context, name string, args []float64) ([]float64, error) {
+	if r.EvaluateFn == nil {
+		return nil, fmt.Errorf("exported function '%s' not found", name)
+	}
+	results := make([]float64, len(args))
+	for i, arg := range args {
+		result, err := r.EvaluateFn(ctx, name, arg)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
 // VU is a mock VU, spawned by a MiniRunner.
 type VU struct {
 	R            *MiniRunner
@@ -223,5 +244,15 @@ func (vu *ActiveVU) RunOnce() error {
 	}()
 
 	vu.incrIteration()
-	return vu.R.Fn(vu.RunContext, vu.State(), vu.Out)
+	if vu.MaxIterationDuration <= 0 {
+		return vu.R.Fn(vu.RunContext, vu.State(), vu.Out)
+	}
+
+	ctx, cancel := context.WithTimeout(vu.RunContext, vu.MaxIterationDuration)
+	defer cancel()
+	err := vu.R.Fn(ctx, vu.State(), vu.Out)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && vu.RunContext.Err() == nil {
+		return fmt.Errorf("%w of %s", lib.ErrMaxIterationDurationExceeded, vu.MaxIterationDuration)
+	}
+	return err
 }
@@ -64,28 +64,37 @@ func (n *NullHosts) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	source := make(map[string]Host)
-	for k, v := range jsonSource {
+	hosts, err := ParseHosts(jsonSource)
+	if err != nil {
+		return err
+	}
+	n.Trie = hosts
+	n.Valid = true
+	return nil
+}
+
+// ParseHosts returns new Hosts from the given addresses, which are
+// IPs with optional ports, e.g. "1.2.3.4" or "1.2.3.4:8443".
+func ParseHosts(source map[string]string) (*Hosts, error) {
+	hosts := make(map[string]Host, len(source))
+	for k, v := range source {
 		ip, port, err := net.SplitHostPort(v)
 		if err == nil {
 			pInt, err := strconv.Atoi(port)
 			if err != nil {
-				return err
+				return nil, err
 			}
 
-			source[k] = Host{IP: net.ParseIP(ip), Port: pInt}
+			hosts[k] = Host{IP: net.ParseIP(ip), Port: pInt}
 		} else {
-			source[k] = Host{IP: net.ParseIP(v)}
+			hosts[k] = Host{IP: net.ParseIP(v)}
+		}
+		if hosts[k].IP == nil {
+			return nil, fmt.Errorf("invalid IP address '%s' for the host '%s'", v, k)
 		}
 	}
 
-	hosts, err := NewHosts(source)
-	if err != nil {
-		return err
-	}
-	n.Trie = hosts
-	n.Valid = true
-	return nil
+	return NewHosts(hosts)
 }
 
 // Hosts is wrapper around trieNode to integrate with net.TCPAddr
@@ -189,6 +189,25 @@ func runTcs(t *testing.T, at *Hosts, tcs []HostTestCase) {
 	}
 }
 
+func TestParseHosts(t *testing.T) {
+	t.Parallel()
+
+	hosts, err := ParseHosts(map[string]string{
+		"example.com":      "1.2.3.4",
+		"example-port.com": "5.6.7.8:443",
+		"example-ipv6.com": "[cc::dd]:443",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &Host{IP: net.ParseIP("1.2.3.4")}, hosts.Match("example.com"))
+	assert.Equal(t, &Host{IP: net.ParseIP("5.6.7.8"), Port: 443}, hosts.Match("example-port.com"))
+	assert.Equal(t, &Host{IP: net.ParseIP("cc::dd"), Port: 443}, hosts.Match("example-ipv6.com"))
+
+	_, err = ParseHosts(map[string]string{"example.com": "invalid"})
+	require.EqualError(t, err, "invalid IP address 'invalid' for the host 'example.com'")
+	_, err = ParseHosts(map[string]string{"example.com": "1.2.3.4:port"})
+	require.Error(t, err)
+}
+
 func TestHostsJSON(t *testing.T) {
 	t.Parallel()
 
@@ -45,8 +45,29 @@ type State struct {
 	// TODO: move a lot of the things below to the k6/http ModuleInstance, see
 	// https://github.com/grafana/k6/issues/2293.
 	Transport http.RoundTripper
+	// The transports for the HTTP protocols that are used only when they are
+	// explicitly requested, i.e. HTTP/2 with prior knowledge and HTTP/3.
+	TransportH2C http.RoundTripper
+	TransportH3  http.RoundTripper
+	// The transports with keep-alive connections enabled and disabled, that are
+	// used when a request explicitly asks to reuse a pooled connection or to
+	// open a new one. Transport is one of them, depending on the options.
+	TransportReuse   http.RoundTripper
+	TransportNoReuse http.RoundTripper
+	// NewTLSTransport returns a transport like TransportNoReuse, but with the
+	// provided TLS config, for the requests that override the TLS settings.
+	NewTLSTransport func(tlsConfig *tls.Config) http.RoundTripper
+	// ConnStats tracks the connections of TransportReuse and TransportNoReuse,
+	// it's shared by all the VUs and it's nil if they aren't tracked.
+	ConnStats *ConnStats
 	CookieJar *cookiejar.Jar
-	TLSConfig *tls.Config
+	// TrackedCookieJar wraps CookieJar and keeps track of its cookies, so
+	// they can be exported. The cookies that are set directly in CookieJar,
+	// e.g. by extensions, aren't exported.
+	TrackedCookieJar *CookieJar
+	// HARRecorder records the HTTP requests into a HAR file, if it's enabled.
+	HARRecorder *HARRecorder
+	TLSConfig   *tls.Config
 
 	// Rate limits.
 	RPSLimit *rate.Limiter
@@ -70,7 +70,7 @@ func TestCDNJS(t *testing.T) {
 			require.Empty(t, resolvedURL.Scheme)
 			require.Equal(t, path, resolvedURL.Opaque)
 
-			data, err := Load(logger, map[string]fsext.Fs{"https": fsext.NewMemMapFs()}, resolvedURL, path)
+			data, err := Load(logger, map[string]fsext.Fs{"https": fsext.NewMemMapFs()}, nil, resolvedURL, path)
 			require.NoError(t, err)
 			assert.Equal(t, resolvedURL, data.URL)
 			assert.NotEmpty(t, data.Data)
@@ -100,7 +100,7 @@ func TestCDNJS(t *testing.T) {
 		pathURL, err := url.Parse(src)
 		require.NoError(t, err)
 
-		_, err = Load(logger, map[string]fsext.Fs{"https": fsext.NewMemMapFs()}, pathURL, path)
+		_, err = Load(logger, map[string]fsext.Fs{"https": fsext.NewMemMapFs()}, nil, pathURL, path)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "not found: https://cdnjs.cloudflare.com/ajax/libs/Faker/3.1.0/nonexistent.js")
 	})
@@ -32,7 +32,7 @@ func TestGithub(t *testing.T) {
 	require.Equal(t, path, resolvedURL.Opaque)
 	t.Run("not cached", func(t *testing.T) {
 		t.Parallel()
-		data, err := Load(logger, map[string]fsext.Fs{"https": fsext.NewMemMapFs()}, resolvedURL, path)
+		data, err := Load(logger, map[string]fsext.Fs{"https": fsext.NewMemMapFs()}, nil, resolvedURL, path)
 		require.NoError(t, err)
 		assert.Equal(t, data.URL, resolvedURL)
 		assert.Equal(t, path, data.URL.String())
@@ -47,7 +47,7 @@ func TestGithub(t *testing.T) {
 		err := fsext.WriteFile(fs, "/github.com/github/gitignore/Go.gitignore", testData, 0o644)
 		require.NoError(t, err)
 
-		data, err := Load(logger, map[string]fsext.Fs{"https": fs}, resolvedURL, path)
+		data, err := Load(logger, map[string]fsext.Fs{"https": fs}, nil, resolvedURL, path)
 		require.NoError(t, err)
 		assert.Equal(t, path, data.URL.String())
 		assert.Equal(t, data.Data, testData)
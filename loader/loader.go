@@ -151,9 +151,12 @@ func Dir(old *url.URL) *url.URL {
 
 // Load loads the provided moduleSpecifier from the given filesystems which are map of fsext.Fs
 // for a given scheme which is they key of the map. If the scheme is https then a request will
-// be made if the files is not found in the map and written to the map.
+// be made if the files is not found in the map and written to the map. The remote modules
+// are checked with the optional verifier, e.g. against the imports lockfile, and recorded
+// in it. The ones which are already in the map, e.g. from an archive, are only checked.
 func Load(
-	logger logrus.FieldLogger, filesystems map[string]fsext.Fs, moduleSpecifier *url.URL, originalModuleSpecifier string,
+	logger logrus.FieldLogger, filesystems map[string]fsext.Fs, verifier Verifier,
+	moduleSpecifier *url.URL, originalModuleSpecifier string,
 ) (*SourceData, error) {
 	logger.WithFields(
 		logrus.Fields{
@@ -183,6 +186,11 @@ func Load(
 	data, err := fsext.ReadFile(filesystems[scheme], pathOnFs)
 
 	if err == nil {
+		if scheme == "https" && verifier != nil {
+			if err = verifier.Verify(moduleSpecifier.String(), "", data); err != nil {
+				return nil, err
+			}
+		}
 		return &SourceData{URL: moduleSpecifier, Data: data}, nil
 	}
 	if !errors.Is(err, fs.ErrNotExist) {
@@ -191,7 +199,20 @@ func Load(
 	if scheme == "https" {
 		finalModuleSpecifierURL := &url.URL{}
 
+		var (
+			lockedURL string
+			isLocked  bool
+		)
+		if verifier != nil {
+			lockedURL, isLocked = verifier.LockedURL(moduleSpecifier.String())
+		}
 		switch {
+		case isLocked:
+			// the module is loaded from the URL it was resolved to before
+			finalModuleSpecifierURL, err = url.Parse(lockedURL)
+			if err != nil {
+				return nil, err
+			}
 		case moduleSpecifier.Opaque != "": // This is loader
 			finalModuleSpecifierURL, err = resolveUsingLoaders(logger, moduleSpecifier.Opaque)
 			if err != nil {
@@ -208,6 +229,12 @@ func Load(
 		}
 		var result *SourceData
 		result, err = loadRemoteURL(logger, finalModuleSpecifierURL)
+		if err == nil && verifier != nil {
+			err = verifier.Verify(moduleSpecifier.String(), finalModuleSpecifierURL.String(), result.Data)
+			if err != nil {
+				return nil, err
+			}
+		}
 		if err == nil {
 			result.URL = moduleSpecifier
 			// TODO maybe make an fsext.Fs which makes request directly and than use CacheOnReadFs
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/sirupsen/logrus"
@@ -142,7 +143,7 @@ func TestLoad(t *testing.T) {
 				filesystems["file"] = fsext.NewMemMapFs()
 				assert.NoError(t, filesystems["file"].MkdirAll("/path/to", 0o755))
 				assert.NoError(t, fsext.WriteFile(filesystems["file"], "/path/to/file.txt", []byte("hi"), 0o644))
-				src, err := loader.Load(logger, filesystems, moduleURL, data.path)
+				src, err := loader.Load(logger, filesystems, nil, moduleURL, data.path)
 				require.NoError(t, err)
 
 				assert.Equal(t, "file:///path/to/file.txt", src.URL.String())
@@ -164,7 +165,7 @@ func TestLoad(t *testing.T) {
 			pathURL, err := loader.Resolve(root, "/nonexistent")
 			require.NoError(t, err)
 
-			_, err = loader.Load(logger, filesystems, pathURL, path)
+			_, err = loader.Load(logger, filesystems, nil, pathURL, path)
 			require.Error(t, err)
 			assert.Contains(t, err.Error(),
 				fmt.Sprintf(`The moduleSpecifier "%s" couldn't be found on local disk. `,
@@ -184,7 +185,7 @@ func TestLoad(t *testing.T) {
 			moduleSpecifierURL, err := loader.Resolve(root, moduleSpecifier)
 			require.NoError(t, err)
 
-			src, err := loader.Load(logger, filesystems, moduleSpecifierURL, moduleSpecifier)
+			src, err := loader.Load(logger, filesystems, nil, moduleSpecifierURL, moduleSpecifier)
 			require.NoError(t, err)
 			assert.Equal(t, src.URL, moduleSpecifierURL)
 			assert.Contains(t, string(src.Data), "Herman Melville - Moby-Dick")
@@ -200,7 +201,7 @@ func TestLoad(t *testing.T) {
 			moduleSpecifierURL, err := loader.Resolve(pwdURL, moduleSpecifier)
 			require.NoError(t, err)
 
-			src, err := loader.Load(logger, filesystems, moduleSpecifierURL, moduleSpecifier)
+			src, err := loader.Load(logger, filesystems, nil, moduleSpecifierURL, moduleSpecifier)
 			require.NoError(t, err)
 			assert.Equal(t, src.URL.String(), sr("HTTPSBIN_URL/robots.txt"))
 			assert.Equal(t, string(src.Data), "User-agent: *\nDisallow: /deny\n")
@@ -216,7 +217,7 @@ func TestLoad(t *testing.T) {
 			moduleSpecifierURL, err := loader.Resolve(pwdURL, moduleSpecifier)
 			require.NoError(t, err)
 
-			src, err := loader.Load(logger, filesystems, moduleSpecifierURL, moduleSpecifier)
+			src, err := loader.Load(logger, filesystems, nil, moduleSpecifierURL, moduleSpecifier)
 			require.NoError(t, err)
 			assert.Equal(t, sr("HTTPSBIN_URL/robots.txt"), src.URL.String())
 			assert.Equal(t, "User-agent: *\nDisallow: /deny\n", string(src.Data))
@@ -233,13 +234,90 @@ func TestLoad(t *testing.T) {
 		require.NoError(t, err)
 
 		filesystems := map[string]fsext.Fs{"https": fsext.NewMemMapFs()}
-		src, err := loader.Load(logger, filesystems, moduleSpecifierURL, moduleSpecifier)
+		src, err := loader.Load(logger, filesystems, nil, moduleSpecifierURL, moduleSpecifier)
 
 		require.NoError(t, err)
 		assert.Equal(t, src.URL.String(), sr("HTTPSBIN_URL/raw/something"))
 		assert.Equal(t, responseStr, string(src.Data))
 	})
 
+	t.Run("Lockfile", func(t *testing.T) {
+		t.Parallel()
+		fs := fsext.NewMemMapFs()
+		root, err := url.Parse("file:///")
+		require.NoError(t, err)
+
+		moduleSpecifier := sr("HTTPSBIN_URL/robots.txt")
+		moduleSpecifierURL, err := loader.Resolve(root, moduleSpecifier)
+		require.NoError(t, err)
+		load := func(lockfile *loader.Lockfile) error {
+			filesystems := map[string]fsext.Fs{"https": fsext.NewMemMapFs()}
+			_, err := loader.Load(logger, filesystems, lockfile, moduleSpecifierURL, moduleSpecifier)
+			return err
+		}
+
+		lockfile, err := loader.ReadLockfile(fs, "/k6.lock.json", false)
+		require.NoError(t, err)
+		require.NoError(t, load(lockfile))
+		require.NoError(t, lockfile.Save())
+		data, err := fsext.ReadFile(fs, "/k6.lock.json")
+		require.NoError(t, err)
+		assert.JSONEq(t, fmt.Sprintf(`{"lockfileVersion": 1, "modules": {%q: {
+			"url": %q,
+			"integrity": "sha256-vna4qzodjbgMr7DHp2ivbHtrSsKP/vO/bWQcftTOwFo="
+		}}}`, moduleSpecifier, moduleSpecifier), string(data))
+
+		frozen, err := loader.ReadLockfile(fs, "/k6.lock.json", true)
+		require.NoError(t, err)
+		require.NoError(t, load(frozen))
+
+		tampered := strings.Replace(string(data), "sha256-", "sha256-x", 1)
+		require.NoError(t, fsext.WriteFile(fs, "/k6.lock.json", []byte(tampered), 0o644))
+		lockfile, err = loader.ReadLockfile(fs, "/k6.lock.json", false)
+		require.NoError(t, err)
+		require.ErrorContains(t, load(lockfile), "the module was modified")
+
+		require.NoError(t, fsext.WriteFile(fs, "/empty.lock.json", []byte(`{"lockfileVersion": 1}`), 0o644))
+		frozen, err = loader.ReadLockfile(fs, "/empty.lock.json", true)
+		require.NoError(t, err)
+		require.ErrorContains(t, load(frozen), "the imports are frozen")
+	})
+
+	t.Run("Lockfile with cached module", func(t *testing.T) {
+		t.Parallel()
+		fs := fsext.NewMemMapFs()
+		root, err := url.Parse("file:///")
+		require.NoError(t, err)
+
+		moduleSpecifier := sr("HTTPSBIN_URL/robots.txt")
+		moduleSpecifierURL, err := loader.Resolve(root, moduleSpecifier)
+		require.NoError(t, err)
+		// the modules of an archive are served from its https filesystem
+		filesystems := map[string]fsext.Fs{"https": fsext.NewMemMapFs()}
+		load := func(lockfile *loader.Lockfile) error {
+			_, err := loader.Load(logger, filesystems, lockfile, moduleSpecifierURL, moduleSpecifier)
+			return err
+		}
+
+		lockfile, err := loader.ReadLockfile(fs, "/k6.lock.json", false)
+		require.NoError(t, err)
+		require.NoError(t, load(lockfile))
+		require.NoError(t, lockfile.Save())
+
+		frozen, err := loader.ReadLockfile(fs, "/k6.lock.json", true)
+		require.NoError(t, err)
+		require.NoError(t, load(frozen))
+
+		cachedPath := "/" + moduleSpecifierURL.Host + moduleSpecifierURL.Path
+		require.NoError(t, fsext.WriteFile(filesystems["https"], cachedPath, []byte("tampered"), 0o644))
+		require.ErrorContains(t, load(frozen), "the module was modified")
+
+		require.NoError(t, fsext.WriteFile(fs, "/empty.lock.json", []byte(`{"lockfileVersion": 1}`), 0o644))
+		frozen, err = loader.ReadLockfile(fs, "/empty.lock.json", true)
+		require.NoError(t, err)
+		require.ErrorContains(t, load(frozen), "the imports are frozen")
+	})
+
 	t.Run("Invalid", func(t *testing.T) {
 		t.Parallel()
 		root, err := url.Parse("file:///")
@@ -266,9 +344,28 @@ func TestLoad(t *testing.T) {
 				moduleSpecifierURL, err := loader.Resolve(root, moduleSpecifier)
 				require.NoError(t, err)
 
-				_, err = loader.Load(logger, filesystems, moduleSpecifierURL, moduleSpecifier)
+				_, err = loader.Load(logger, filesystems, nil, moduleSpecifierURL, moduleSpecifier)
 				require.Error(t, err)
 			})
 		}
 	})
 }
+
+func TestReadLockfile(t *testing.T) {
+	t.Parallel()
+	fs := fsext.NewMemMapFs()
+
+	lockfile, err := loader.ReadLockfile(fs, "/k6.lock.json", false)
+	require.NoError(t, err)
+	require.NoError(t, lockfile.Save())
+	exists, err := fsext.Exists(fs, "/k6.lock.json")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = loader.ReadLockfile(fs, "/k6.lock.json", true)
+	require.ErrorContains(t, err, `the lockfile "/k6.lock.json" doesn't exist, but the imports are frozen`)
+
+	require.NoError(t, fsext.WriteFile(fs, "/k6.lock.json", []byte(`{"lockfileVersion": 2}`), 0o644))
+	_, err = loader.ReadLockfile(fs, "/k6.lock.json", false)
+	require.ErrorContains(t, err, "the version 2 of the lockfile")
+}
@@ -0,0 +1,151 @@
+package loader
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+
+	"go.k6.io/k6/lib/fsext"
+)
+
+// lockfileVersion is the version of the format of the lockfiles.
+const lockfileVersion = 1
+
+// Lockfile records the resolved URLs and the integrity hashes of the remote
+// modules imported by a test, so that the next runs of the test load exactly
+// the same modules. The methods of a nil Lockfile don't do anything.
+type Lockfile struct {
+	fs       fsext.Fs
+	filename string
+	frozen   bool
+
+	mx      sync.Mutex
+	modules map[string]LockedModule
+	changed bool
+}
+
+var _ Verifier = &Lockfile{}
+
+// Verifier checks the remote modules, it's satisfied by the lib.ImportsVerifier
+// of the test, since the lib package can't be imported by the loader.
+type Verifier interface {
+	LockedURL(specifier string) (string, bool)
+	Verify(specifier, url string, data []byte) error
+}
+
+// LockedModule is a remote module recorded in a lockfile.
+type LockedModule struct {
+	// URL is the URL the module was resolved to, e.g. by the cdnjs loader.
+	URL string `json:"url"`
+	// Integrity is the SHA-256 hash of the module, in the format of the
+	// subresource integrity, e.g. sha256-<base64 encoded hash>.
+	Integrity string `json:"integrity"`
+}
+
+type lockfileData struct {
+	Version int                     `json:"lockfileVersion"`
+	Modules map[string]LockedModule `json:"modules"`
+}
+
+// ReadLockfile reads the lockfile, or returns an empty one if it doesn't exist
+// yet. If frozen is true, the remote modules missing from the lockfile can't
+// be loaded, and the lockfile is never written.
+func ReadLockfile(filesystem fsext.Fs, filename string, frozen bool) (*Lockfile, error) {
+	l := &Lockfile{fs: filesystem, filename: filename, frozen: frozen, modules: make(map[string]LockedModule)}
+
+	data, err := fsext.ReadFile(filesystem, filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		if frozen {
+			return nil, fmt.Errorf("the lockfile %q doesn't exist, but the imports are frozen", filename)
+		}
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the lockfile %q: %w", filename, err)
+	}
+
+	var lf lockfileData
+	if err = json.Unmarshal(data, &lf); err != nil {
+		return nil, fmt.Errorf("couldn't parse the lockfile %q: %w", filename, err)
+	}
+	if lf.Version != lockfileVersion {
+		return nil, fmt.Errorf("the version %d of the lockfile %q isn't supported", lf.Version, filename)
+	}
+	for specifier, module := range lf.Modules {
+		l.modules[specifier] = module
+	}
+	return l, nil
+}
+
+// Save writes the lockfile, if new modules were recorded in it.
+func (l *Lockfile) Save() error {
+	if l == nil {
+		return nil
+	}
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	if !l.changed {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(lockfileData{Version: lockfileVersion, Modules: l.modules}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = fsext.WriteFile(l.fs, l.filename, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("couldn't write the lockfile %q: %w", l.filename, err)
+	}
+	l.changed = false
+	return nil
+}
+
+// LockedURL returns the URL the module was resolved to, if it's locked.
+func (l *Lockfile) LockedURL(specifier string) (string, bool) {
+	if l == nil {
+		return "", false
+	}
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	module, ok := l.modules[specifier]
+	return module.URL, ok
+}
+
+// Verify checks that the data of the module fetched from the URL matches its
+// integrity in the lockfile, or records the module if it isn't locked yet. The
+// modules which weren't fetched, i.e. with an empty URL, aren't recorded.
+func (l *Lockfile) Verify(specifier, u string, data []byte) error {
+	if l == nil {
+		return nil
+	}
+	hash := sha256.Sum256(data)
+	integrity := "sha256-" + base64.StdEncoding.EncodeToString(hash[:])
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	module, ok := l.modules[specifier]
+	if !ok {
+		if l.frozen {
+			return fmt.Errorf("the remote module %q isn't in the lockfile %q, "+
+				"and it can't be added because the imports are frozen", specifier, l.filename)
+		}
+		if u == "" {
+			return nil
+		}
+		l.modules[specifier] = LockedModule{URL: u, Integrity: integrity}
+		l.changed = true
+		return nil
+	}
+	if module.Integrity != integrity {
+		if u == "" {
+			u = module.URL
+		}
+		return fmt.Errorf("the integrity %s of the remote module %q fetched from %q doesn't match "+
+			"the integrity %s in the lockfile %q, the module was modified", integrity, specifier, u,
+			module.Integrity, l.filename)
+	}
+	return nil
+}
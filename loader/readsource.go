@@ -12,9 +12,11 @@ import (
 	"go.k6.io/k6/lib/fsext"
 )
 
-// ReadSource Reads a source file from any supported destination.
+// ReadSource Reads a source file from any supported destination. The remote source is
+// checked with the optional verifier, e.g. against the imports lockfile, and recorded in it.
 func ReadSource(
-	logger logrus.FieldLogger, src, pwd string, filesystems map[string]fsext.Fs, stdin io.Reader,
+	logger logrus.FieldLogger, src, pwd string, filesystems map[string]fsext.Fs, verifier Verifier,
+	stdin io.Reader,
 ) (*SourceData, error) {
 	if src == "-" {
 		data, err := io.ReadAll(stdin)
@@ -40,7 +42,7 @@ func ReadSource(
 	srcLocalPath = filepath.Clean(fsext.FilePathSeparator + srcLocalPath)
 	if ok, _ := fsext.Exists(filesystems["file"], srcLocalPath); ok {
 		// there is file on the local disk ... lets use it :)
-		return Load(logger, filesystems, &url.URL{Scheme: "file", Path: filepath.ToSlash(srcLocalPath)}, src)
+		return Load(logger, filesystems, verifier, &url.URL{Scheme: "file", Path: filepath.ToSlash(srcLocalPath)}, src)
 	}
 
 	pwdURL := &url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Clean(pwd)) + "/"}
@@ -53,7 +55,7 @@ func ReadSource(
 		}
 		return nil, err
 	}
-	result, err := Load(logger, filesystems, srcURL, src)
+	result, err := Load(logger, filesystems, verifier, srcURL, src)
 	var noSchemeError noSchemeRemoteModuleResolutionError
 	if errors.As(err, &noSchemeError) {
 		// TODO maybe try to wrap the original error here as well, without butchering the message
@@ -26,7 +26,7 @@ func TestReadSourceSTDINError(t *testing.T) {
 	t.Parallel()
 	logger := logrus.New()
 	logger.SetOutput(testutils.NewTestOutput(t))
-	_, err := ReadSource(logger, "-", "", nil, errorReader("1234"))
+	_, err := ReadSource(logger, "-", "", nil, nil, errorReader("1234"))
 	require.Error(t, err)
 	require.Equal(t, "1234", err.Error())
 }
@@ -39,7 +39,7 @@ func TestReadSourceSTDINCache(t *testing.T) {
 	r := bytes.NewReader(data)
 	fs := fsext.NewMemMapFs()
 	sourceData, err := ReadSource(logger, "-", "/path/to/pwd",
-		map[string]fsext.Fs{"file": fsext.NewCacheOnReadFs(nil, fs, 0)}, r)
+		map[string]fsext.Fs{"file": fsext.NewCacheOnReadFs(nil, fs, 0)}, nil, r)
 	require.NoError(t, err)
 	require.Equal(t, &SourceData{
 		URL:  &url.URL{Scheme: "file", Path: "/-"},
@@ -57,7 +57,7 @@ func TestReadSourceRelative(t *testing.T) {
 	data := []byte(`test contents`)
 	fs := fsext.NewMemMapFs()
 	require.NoError(t, fsext.WriteFile(fs, "/path/to/somewhere/script.js", data, 0o644))
-	sourceData, err := ReadSource(logger, "../somewhere/script.js", "/path/to/pwd", map[string]fsext.Fs{"file": fs}, nil)
+	sourceData, err := ReadSource(logger, "../somewhere/script.js", "/path/to/pwd", map[string]fsext.Fs{"file": fs}, nil, nil)
 	require.NoError(t, err)
 	require.Equal(t, &SourceData{
 		URL:  &url.URL{Scheme: "file", Path: "/path/to/somewhere/script.js"},
@@ -74,7 +74,7 @@ func TestReadSourceAbsolute(t *testing.T) {
 	fs := fsext.NewMemMapFs()
 	require.NoError(t, fsext.WriteFile(fs, "/a/b", data, 0o644))
 	require.NoError(t, fsext.WriteFile(fs, "/c/a/b", []byte("wrong"), 0o644))
-	sourceData, err := ReadSource(logger, "/a/b", "/c", map[string]fsext.Fs{"file": fs}, r)
+	sourceData, err := ReadSource(logger, "/a/b", "/c", map[string]fsext.Fs{"file": fs}, nil, r)
 	require.NoError(t, err)
 	require.Equal(t, &SourceData{
 		URL:  &url.URL{Scheme: "file", Path: "/a/b"},
@@ -90,7 +90,7 @@ func TestReadSourceHttps(t *testing.T) {
 	fs := fsext.NewMemMapFs()
 	require.NoError(t, fsext.WriteFile(fs, "/github.com/something", data, 0o644))
 	sourceData, err := ReadSource(logger, "https://github.com/something", "/c",
-		map[string]fsext.Fs{"file": fsext.NewMemMapFs(), "https": fs}, nil)
+		map[string]fsext.Fs{"file": fsext.NewMemMapFs(), "https": fs}, nil, nil)
 	require.NoError(t, err)
 	require.Equal(t, &SourceData{
 		URL:  &url.URL{Scheme: "https", Host: "github.com", Path: "/something"},
@@ -106,7 +106,7 @@ func TestReadSourceHttpError(t *testing.T) {
 	fs := fsext.NewMemMapFs()
 	require.NoError(t, fsext.WriteFile(fs, "/github.com/something", data, 0o644))
 	_, err := ReadSource(logger, "http://github.com/something", "/c",
-		map[string]fsext.Fs{"file": fsext.NewMemMapFs(), "https": fs}, nil)
+		map[string]fsext.Fs{"file": fsext.NewMemMapFs(), "https": fs}, nil, nil)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), `only supported schemes for imports are file and https`)
 }
@@ -117,7 +117,7 @@ func TestReadSourceMissingFileError(t *testing.T) {
 	logger.SetOutput(testutils.NewTestOutput(t))
 	fs := fsext.NewMemMapFs()
 	_, err := ReadSource(logger, "some file with spaces.js", "/c",
-		map[string]fsext.Fs{"file": fsext.NewMemMapFs(), "https": fs}, nil)
+		map[string]fsext.Fs{"file": fsext.NewMemMapFs(), "https": fs}, nil, nil)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), `The moduleSpecifier "some file with spaces.js" couldn't be found on local disk.`)
 }
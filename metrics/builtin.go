@@ -3,10 +3,13 @@ package metrics
 const (
 	VUsName               = "vus" //nolint:revive
 	VUsMaxName            = "vus_max"
+	ScenarioVUsName       = "scenario_vus"
 	IterationsName        = "iterations"
 	IterationDurationName = "iteration_duration"
 	DroppedIterationsName = "dropped_iterations"
 
+	IterationsInterruptedName = "iterations_interrupted"
+
 	ChecksName        = "checks"
 	GroupDurationName = "group_duration"
 
@@ -19,13 +22,20 @@ const (
 	HTTPReqSendingName        = "http_req_sending"
 	HTTPReqWaitingName        = "http_req_waiting"
 	HTTPReqReceivingName      = "http_req_receiving"
+	HTTPReqRetriesName        = "http_req_retries"
+	HTTPConnsOpenName         = "http_conns_open"
+	HTTPConnsIdleName         = "http_conns_idle"
+
+	DNSLookupDurationName = "dns_lookup_duration"
 
-	WSSessionsName         = "ws_sessions"
-	WSMessagesSentName     = "ws_msgs_sent"
-	WSMessagesReceivedName = "ws_msgs_received"
-	WSPingName             = "ws_ping"
-	WSSessionDurationName  = "ws_session_duration"
-	WSConnectingName       = "ws_connecting"
+	WSSessionsName             = "ws_sessions"
+	WSMessagesSentName         = "ws_msgs_sent"
+	WSMessagesReceivedName     = "ws_msgs_received"
+	WSMessageBytesSentName     = "ws_msg_bytes_sent"
+	WSMessageBytesReceivedName = "ws_msg_bytes_received"
+	WSPingName                 = "ws_ping"
+	WSSessionDurationName      = "ws_session_duration"
+	WSConnectingName           = "ws_connecting"
 
 	GRPCReqDurationName = "grpc_req_duration"
 
@@ -37,10 +47,13 @@ const (
 type BuiltinMetrics struct {
 	VUs               *Metric
 	VUsMax            *Metric
+	ScenarioVUs       *Metric
 	Iterations        *Metric
 	IterationDuration *Metric
 	DroppedIterations *Metric
 
+	IterationsInterrupted *Metric
+
 	// Runner-emitted.
 	Checks        *Metric
 	GroupDuration *Metric
@@ -55,14 +68,22 @@ type BuiltinMetrics struct {
 	HTTPReqSending        *Metric
 	HTTPReqWaiting        *Metric
 	HTTPReqReceiving      *Metric
+	HTTPReqRetries        *Metric
+	HTTPConnsOpen         *Metric
+	HTTPConnsIdle         *Metric
+
+	// DNS-related.
+	DNSLookupDuration *Metric
 
 	// Websocket-related
-	WSSessions         *Metric
-	WSMessagesSent     *Metric
-	WSMessagesReceived *Metric
-	WSPing             *Metric
-	WSSessionDuration  *Metric
-	WSConnecting       *Metric
+	WSSessions             *Metric
+	WSMessagesSent         *Metric
+	WSMessagesReceived     *Metric
+	WSMessageBytesSent     *Metric
+	WSMessageBytesReceived *Metric
+	WSPing                 *Metric
+	WSSessionDuration      *Metric
+	WSConnecting           *Metric
 
 	// gRPC-related
 	GRPCReqDuration *Metric
@@ -77,10 +98,13 @@ func RegisterBuiltinMetrics(registry *Registry) *BuiltinMetrics {
 	return &BuiltinMetrics{
 		VUs:               registry.MustNewMetric(VUsName, Gauge),
 		VUsMax:            registry.MustNewMetric(VUsMaxName, Gauge),
+		ScenarioVUs:       registry.MustNewMetric(ScenarioVUsName, Gauge),
 		Iterations:        registry.MustNewMetric(IterationsName, Counter),
 		IterationDuration: registry.MustNewMetric(IterationDurationName, Trend, Time),
 		DroppedIterations: registry.MustNewMetric(DroppedIterationsName, Counter),
 
+		IterationsInterrupted: registry.MustNewMetric(IterationsInterruptedName, Counter),
+
 		Checks:        registry.MustNewMetric(ChecksName, Rate),
 		GroupDuration: registry.MustNewMetric(GroupDurationName, Trend, Time),
 
@@ -93,13 +117,20 @@ func RegisterBuiltinMetrics(registry *Registry) *BuiltinMetrics {
 		HTTPReqSending:        registry.MustNewMetric(HTTPReqSendingName, Trend, Time),
 		HTTPReqWaiting:        registry.MustNewMetric(HTTPReqWaitingName, Trend, Time),
 		HTTPReqReceiving:      registry.MustNewMetric(HTTPReqReceivingName, Trend, Time),
-
-		WSSessions:         registry.MustNewMetric(WSSessionsName, Counter),
-		WSMessagesSent:     registry.MustNewMetric(WSMessagesSentName, Counter),
-		WSMessagesReceived: registry.MustNewMetric(WSMessagesReceivedName, Counter),
-		WSPing:             registry.MustNewMetric(WSPingName, Trend, Time),
-		WSSessionDuration:  registry.MustNewMetric(WSSessionDurationName, Trend, Time),
-		WSConnecting:       registry.MustNewMetric(WSConnectingName, Trend, Time),
+		HTTPReqRetries:        registry.MustNewMetric(HTTPReqRetriesName, Counter),
+		HTTPConnsOpen:         registry.MustNewMetric(HTTPConnsOpenName, Gauge),
+		HTTPConnsIdle:         registry.MustNewMetric(HTTPConnsIdleName, Gauge),
+
+		DNSLookupDuration: registry.MustNewMetric(DNSLookupDurationName, Trend, Time),
+
+		WSSessions:             registry.MustNewMetric(WSSessionsName, Counter),
+		WSMessagesSent:         registry.MustNewMetric(WSMessagesSentName, Counter),
+		WSMessagesReceived:     registry.MustNewMetric(WSMessagesReceivedName, Counter),
+		WSMessageBytesSent:     registry.MustNewMetric(WSMessageBytesSentName, Trend, Data),
+		WSMessageBytesReceived: registry.MustNewMetric(WSMessageBytesReceivedName, Trend, Data),
+		WSPing:                 registry.MustNewMetric(WSPingName, Trend, Time),
+		WSSessionDuration:      registry.MustNewMetric(WSSessionDurationName, Trend, Time),
+		WSConnecting:           registry.MustNewMetric(WSConnectingName, Trend, Time),
 
 		GRPCReqDuration: registry.MustNewMetric(GRPCReqDurationName, Trend, Time),
 
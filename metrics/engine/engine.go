@@ -20,6 +20,17 @@ import (
 
 const thresholdsRate = 2 * time.Second
 
+// scenarioBuiltinMetrics are the built-in metrics that are automatically
+// broken down by scenario, when the test has more than one of them.
+//
+//nolint:gochecknoglobals
+var scenarioBuiltinMetrics = []string{
+	metrics.ScenarioVUsName,
+	metrics.IterationsName,
+	metrics.IterationDurationName,
+	metrics.HTTPReqDurationName,
+}
+
 // MetricsEngine is the internal metrics engine that k6 uses to keep track of
 // aggregated metric sample values. They are used to generate the end-of-test
 // summary and to evaluate the test thresholds.
@@ -31,6 +42,14 @@ type MetricsEngine struct {
 	metricsWithThresholds   []*metrics.Metric
 	breachedThresholdsCount uint32
 
+	// timeSeriesLimit is the number of unique time series
+	// beyond which the ingester warns about the cardinality.
+	timeSeriesLimit int
+
+	// breachHandlers are called every time a threshold
+	// crosses from a passing into a failing state.
+	breachHandlers []func(ThresholdBreach)
+
 	// TODO: completely refactor:
 	//   - make these private, add a method to export the raw data
 	//   - do not use an unnecessary map for the observed metrics
@@ -45,19 +64,39 @@ func NewMetricsEngine(registry *metrics.Registry, logger logrus.FieldLogger) (*M
 	me := &MetricsEngine{
 		registry:        registry,
 		logger:          logger.WithField("component", "metrics-engine"),
+		timeSeriesLimit: timeSeriesFirstLimit,
 		ObservedMetrics: make(map[string]*metrics.Metric),
 	}
 
 	return me, nil
 }
 
+// ThresholdBreach describes a threshold that crossed
+// from a passing into a failing state during the test run.
+type ThresholdBreach struct {
+	Metric    string    `json:"metric"`
+	Threshold string    `json:"threshold"`
+	Value     float64   `json:"value"`
+	Time      time.Time `json:"time"`
+}
+
+// OnThresholdBreach registers a handler that is called every time a threshold
+// crosses from a passing into a failing state. A threshold that recovers and
+// then fails again is reported again. The handlers are called sequentially,
+// outside of the metrics lock, from the goroutine evaluating the thresholds.
+//
+// It's not safe to call it after the threshold calculations were started.
+func (me *MetricsEngine) OnThresholdBreach(handler func(ThresholdBreach)) {
+	me.breachHandlers = append(me.breachHandlers, handler)
+}
+
 // CreateIngester returns a pseudo-Output that uses the given metric samples to
 // update the engine's inner state.
 func (me *MetricsEngine) CreateIngester() *OutputIngester {
 	return &OutputIngester{
 		logger:        me.logger.WithField("component", "metrics-engine-ingester"),
 		metricsEngine: me,
-		cardinality:   newCardinalityControl(),
+		cardinality:   newCardinalityControl(me.timeSeriesLimit),
 	}
 }
 
@@ -118,6 +157,17 @@ func (me *MetricsEngine) markObserved(metric *metrics.Metric) {
 // initializes both the thresholds themselves, as well as any submetrics that
 // were referenced in them.
 func (me *MetricsEngine) InitSubMetricsAndThresholds(options lib.Options, onlyLogErrors bool) error {
+	if options.TrendSinkMaxValues.Int64 > 0 {
+		me.registry.SetTrendSinkMaxValues(int(options.TrendSinkMaxValues.Int64))
+	}
+	if options.TimeSeriesLimit.Valid {
+		if options.TimeSeriesLimit.Int64 <= 0 {
+			return fmt.Errorf("the time series limit must be a positive number, but it is %d",
+				options.TimeSeriesLimit.Int64)
+		}
+		me.timeSeriesLimit = int(options.TimeSeriesLimit.Int64)
+	}
+
 	for metricName, thresholds := range options.Thresholds {
 		metric, err := me.getThresholdMetricOrSubmetric(metricName)
 
@@ -153,6 +203,36 @@ func (me *MetricsEngine) InitSubMetricsAndThresholds(options lib.Options, onlyLo
 		}
 	}
 
+	return me.initScenarioSubmetrics(options)
+}
+
+// initScenarioSubmetrics creates a per-scenario submetric for every one of
+// the scenarioBuiltinMetrics, so their values are tracked and shown in the
+// end-of-test summary for each scenario, without the need of any thresholds.
+// It's only done when there is more than one scenario to break down.
+func (me *MetricsEngine) initScenarioSubmetrics(options lib.Options) error {
+	if !options.SystemTags.Has(metrics.TagScenario) || len(options.Scenarios) < 2 {
+		return nil
+	}
+
+	scenarios := make([]string, 0, len(options.Scenarios))
+	for name := range options.Scenarios {
+		scenarios = append(scenarios, name)
+	}
+	sort.Strings(scenarios)
+
+	for _, metricName := range scenarioBuiltinMetrics {
+		if me.registry.Get(metricName) == nil {
+			continue
+		}
+		for _, scenario := range scenarios {
+			_, err := me.getThresholdMetricOrSubmetric(fmt.Sprintf("%s{scenario:%s}", metricName, scenario))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -219,6 +299,13 @@ func (me *MetricsEngine) evaluateThresholds(
 	ignoreEmptySinks bool,
 	getCurrentTestRunDuration func() time.Duration,
 ) (breachedThresholds []string, shouldAbort bool) {
+	// The deferred functions run in reverse order, so
+	// the handlers are called after the lock is released
+	var breaches []ThresholdBreach
+	defer func() {
+		me.notifyThresholdBreaches(breaches)
+	}()
+
 	me.MetricsLock.Lock()
 	defer me.MetricsLock.Unlock()
 
@@ -233,11 +320,28 @@ func (me *MetricsEngine) evaluateThresholds(
 		}
 		m.Tainted = null.BoolFrom(false)
 
+		lastFailed := make([]bool, len(m.Thresholds.Thresholds))
+		for i, threshold := range m.Thresholds.Thresholds {
+			lastFailed[i] = threshold.LastFailed
+		}
+
 		succ, err := m.Thresholds.Run(m.Sink, t)
 		if err != nil {
 			me.logger.WithField("metric_name", m.Name).WithError(err).Error("Threshold error")
 			continue
 		}
+
+		for i, threshold := range m.Thresholds.Thresholds {
+			if lastFailed[i] || !threshold.LastFailed {
+				continue
+			}
+			breaches = append(breaches, ThresholdBreach{
+				Metric:    m.Name,
+				Threshold: threshold.Source,
+				Value:     threshold.LastValue(),
+				Time:      time.Now(),
+			})
+		}
 		if succ {
 			continue // threshold passed
 		}
@@ -255,6 +359,15 @@ func (me *MetricsEngine) evaluateThresholds(
 	return breachedThresholds, shouldAbort
 }
 
+// notifyThresholdBreaches passes every breach to all the registered handlers.
+func (me *MetricsEngine) notifyThresholdBreaches(breaches []ThresholdBreach) {
+	for _, breach := range breaches {
+		for _, handler := range me.breachHandlers {
+			handler(breach)
+		}
+	}
+}
+
 // GetMetricsWithBreachedThresholdsCount returns the number of metrics for which
 // the thresholds were breached (failed) during the last processing phase. This
 // API is safe to use concurrently.
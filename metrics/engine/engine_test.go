@@ -1,14 +1,17 @@
 package engine
 
 import (
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/executor"
 	"go.k6.io/k6/lib/testutils"
 	"go.k6.io/k6/metrics"
+	"gopkg.in/guregu/null.v3"
 )
 
 func TestNewMetricsEngineWithThresholds(t *testing.T) {
@@ -41,6 +44,72 @@ func TestNewMetricsEngineWithThresholds(t *testing.T) {
 	assert.Len(t, me.metricsWithThresholds, 2)
 }
 
+func TestMetricsEngineInitScenarioSubmetrics(t *testing.T) {
+	t.Parallel()
+
+	twoScenarios := lib.ScenarioConfigs{
+		"browse":   executor.NewConstantVUsConfig("browse"),
+		"checkout": executor.NewConstantVUsConfig("checkout"),
+	}
+
+	cases := map[string]struct {
+		options       lib.Options
+		expSubmetrics []string
+	}{
+		"MultipleScenarios": {
+			options: lib.Options{Scenarios: twoScenarios, SystemTags: &metrics.DefaultSystemTagSet},
+			expSubmetrics: []string{
+				"iterations{scenario:browse}", "iterations{scenario:checkout}",
+				"scenario_vus{scenario:browse}", "scenario_vus{scenario:checkout}",
+			},
+		},
+		"SingleScenario": {
+			options: lib.Options{
+				Scenarios:  lib.ScenarioConfigs{"default": executor.NewConstantVUsConfig("default")},
+				SystemTags: &metrics.DefaultSystemTagSet,
+			},
+		},
+		"ScenarioTagDisabled": {
+			options: lib.Options{Scenarios: twoScenarios, SystemTags: metrics.NewSystemTagSet(metrics.TagVU)},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			me := newTestMetricsEngine(t)
+			builtin := metrics.RegisterBuiltinMetrics(me.registry)
+			require.NoError(t, me.InitSubMetricsAndThresholds(tc.options, false))
+
+			var submetrics []string
+			for _, m := range []*metrics.Metric{builtin.ScenarioVUs, builtin.Iterations} {
+				for _, sm := range m.Submetrics {
+					submetrics = append(submetrics, sm.Name)
+				}
+			}
+			sort.Strings(submetrics)
+			assert.Equal(t, tc.expSubmetrics, submetrics)
+			assert.Empty(t, me.metricsWithThresholds)
+		})
+	}
+}
+
+func TestMetricsEngineTimeSeriesLimit(t *testing.T) {
+	t.Parallel()
+
+	me := newTestMetricsEngine(t)
+	require.NoError(t, me.InitSubMetricsAndThresholds(lib.Options{}, false))
+	assert.Equal(t, timeSeriesFirstLimit, me.CreateIngester().cardinality.timeSeriesLimit)
+
+	require.NoError(t, me.InitSubMetricsAndThresholds(lib.Options{TimeSeriesLimit: null.IntFrom(500)}, false))
+	assert.Equal(t, 500, me.CreateIngester().cardinality.timeSeriesLimit)
+
+	err := me.InitSubMetricsAndThresholds(lib.Options{TimeSeriesLimit: null.IntFrom(0)}, false)
+	assert.ErrorContains(t, err, "the time series limit must be a positive number")
+}
+
 func TestMetricsEngineGetThresholdMetricOrSubmetricError(t *testing.T) {
 	t.Parallel()
 
@@ -153,6 +222,46 @@ func TestMetricsEngineEvaluateIgnoreEmptySink(t *testing.T) {
 	assert.Empty(t, breached)
 }
 
+func TestMetricsEngineEvaluateThresholdBreaches(t *testing.T) {
+	t.Parallel()
+
+	me := newTestMetricsEngine(t)
+	var breaches []ThresholdBreach
+	me.OnThresholdBreach(func(b ThresholdBreach) {
+		breaches = append(breaches, b)
+	})
+
+	m1, err := me.registry.NewMetric("m1", metrics.Gauge)
+	require.NoError(t, err)
+
+	ths := metrics.NewThresholds([]string{"value<5", "value<10"})
+	require.NoError(t, ths.Parse())
+	m1.Thresholds = ths
+	me.metricsWithThresholds = []*metrics.Metric{m1}
+
+	m1.Sink.Add(metrics.Sample{Value: 6})
+	me.evaluateThresholds(false, zeroTestRunDuration)
+	require.Len(t, breaches, 1)
+	assert.Equal(t, "m1", breaches[0].Metric)
+	assert.Equal(t, "value<5", breaches[0].Threshold)
+	assert.Equal(t, 6.0, breaches[0].Value)
+
+	// a threshold that is still failing isn't reported again
+	m1.Sink.Add(metrics.Sample{Value: 7})
+	me.evaluateThresholds(false, zeroTestRunDuration)
+	require.Len(t, breaches, 1)
+
+	// but it is, if it recovers and then fails again
+	m1.Sink.Add(metrics.Sample{Value: 1})
+	me.evaluateThresholds(false, zeroTestRunDuration)
+	m1.Sink.Add(metrics.Sample{Value: 12})
+	me.evaluateThresholds(false, zeroTestRunDuration)
+	require.Len(t, breaches, 3)
+	assert.Equal(t, "value<5", breaches[1].Threshold)
+	assert.Equal(t, "value<10", breaches[2].Threshold)
+	assert.Equal(t, 12.0, breaches[2].Value)
+}
+
 func newTestMetricsEngine(t *testing.T) *MetricsEngine {
 	m, err := NewMetricsEngine(metrics.NewRegistry(), testutils.NewLogger(t))
 	require.NoError(t, err)
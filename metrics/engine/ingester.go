@@ -85,24 +85,29 @@ func (oi *OutputIngester) flushMetrics() {
 		}
 
 		for _, sample := range samples {
-			m := sample.Metric               // this should have come from the Registry, no need to look it up
-			oi.metricsEngine.markObserved(m) // mark it as observed so it shows in the end-of-test summary
-			m.Sink.Add(sample)               // finally, add its value to its own sink
+			if metrics.IsWarmup(sample.Tags) {
+				continue // the warm-up samples are only for the outputs
+			}
+			m := sample.Metric                     // this should have come from the Registry, no need to look it up
+			oi.metricsEngine.markObserved(m)       // mark it as observed so it shows in the end-of-test summary
+			m.Sink.Add(sample)                     // finally, add its value to its own sink
+			m.Thresholds.AddSample(m.Sink, sample) // and to its thresholds evaluated over a sliding window
 
 			// and also to the same for any submetrics that match the metric sample
 			for _, sm := range m.Submetrics {
-				if !sample.Tags.Contains(sm.Tags) {
+				if !sm.Matches(sample.Tags) {
 					continue
 				}
 				oi.metricsEngine.markObserved(sm.Metric)
 				sm.Metric.Sink.Add(sample)
+				sm.Metric.Thresholds.AddSample(sm.Metric.Sink, sample)
 			}
 
 			oi.cardinality.Add(sample.TimeSeries)
 		}
 	}
 
-	if oi.cardinality.LimitHit() {
+	if limit := oi.cardinality.timeSeriesLimit; oi.cardinality.LimitHit() {
 		// TODO: suggest using the Metadata API as an alternative, once it's
 		// available (e.g. move high-cardinality tags as Metadata)
 		// https://github.com/grafana/k6/issues/2766
@@ -112,8 +117,9 @@ func (oi *OutputIngester) flushMetrics() {
 				"which is higher than the suggested limit of %d "+
 				"and could cause high memory usage. "+
 				"Consider not using high-cardinality values like unique IDs as metric tags "+
-				"or, if you need them in the URL, use the name metric tag or URL grouping. "+
-				"See https://k6.io/docs/using-k6/tags-and-groups for details.", oi.cardinality.Count(), timeSeriesFirstLimit)
+				"or, if you need them in the URL, use the name metric tag or URL grouping, "+
+				"which can be automatically done with the urlGrouping option. "+
+				"See https://k6.io/docs/using-k6/tags-and-groups for details.", oi.cardinality.Count(), limit)
 	}
 }
 
@@ -122,9 +128,9 @@ type cardinalityControl struct {
 	timeSeriesLimit int
 }
 
-func newCardinalityControl() *cardinalityControl {
+func newCardinalityControl(timeSeriesLimit int) *cardinalityControl {
 	return &cardinalityControl{
-		timeSeriesLimit: timeSeriesFirstLimit,
+		timeSeriesLimit: timeSeriesLimit,
 		seen:            make(map[metrics.TimeSeries]struct{}),
 	}
 }
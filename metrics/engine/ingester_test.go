@@ -24,7 +24,7 @@ func TestIngesterOutputFlushMetrics(t *testing.T) {
 		metricsEngine: &MetricsEngine{
 			ObservedMetrics: make(map[string]*metrics.Metric),
 		},
-		cardinality: newCardinalityControl(),
+		cardinality: newCardinalityControl(timeSeriesFirstLimit),
 	}
 	require.NoError(t, ingester.Start())
 	ingester.AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
@@ -47,6 +47,41 @@ func TestIngesterOutputFlushMetrics(t *testing.T) {
 	assert.Equal(t, 42.0, sink.Total())
 }
 
+func TestIngesterOutputSkipsWarmupSamples(t *testing.T) {
+	t.Parallel()
+
+	piState := newTestPreInitState(t)
+	testMetric, err := piState.Registry.NewMetric("test_metric", metrics.Trend)
+	require.NoError(t, err)
+
+	ingester := OutputIngester{
+		logger: piState.Logger,
+		metricsEngine: &MetricsEngine{
+			ObservedMetrics: make(map[string]*metrics.Metric),
+		},
+		cardinality: newCardinalityControl(timeSeriesFirstLimit),
+	}
+	require.NoError(t, ingester.Start())
+	ingester.AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: testMetric,
+			Tags:   piState.Registry.RootTagSet().With(metrics.WarmupTagName, "true"),
+		},
+		Value: 100,
+	}})
+	ingester.AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
+		TimeSeries: metrics.TimeSeries{Metric: testMetric, Tags: piState.Registry.RootTagSet()},
+		Value:      21,
+	}})
+	require.NoError(t, ingester.Stop())
+
+	metric := ingester.metricsEngine.ObservedMetrics["test_metric"]
+	require.NotNil(t, metric)
+	sink := metric.Sink.(*metrics.TrendSink) //nolint:forcetypeassert
+	assert.Equal(t, uint64(1), sink.Count())
+	assert.Equal(t, 21.0, sink.Total())
+}
+
 func TestIngesterOutputFlushSubmetrics(t *testing.T) {
 	t.Parallel()
 
@@ -68,7 +103,7 @@ func TestIngesterOutputFlushSubmetrics(t *testing.T) {
 	ingester := OutputIngester{
 		logger:        piState.Logger,
 		metricsEngine: me,
-		cardinality:   newCardinalityControl(),
+		cardinality:   newCardinalityControl(timeSeriesFirstLimit),
 	}
 	require.NoError(t, ingester.Start())
 	ingester.AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
@@ -111,9 +146,8 @@ func TestOutputFlushMetricsTimeSeriesWarning(t *testing.T) {
 		metricsEngine: &MetricsEngine{
 			ObservedMetrics: make(map[string]*metrics.Metric),
 		},
-		cardinality: newCardinalityControl(),
+		cardinality: newCardinalityControl(2),
 	}
-	ingester.cardinality.timeSeriesLimit = 2 // mock the limit
 
 	require.NoError(t, ingester.Start())
 	for i := 0; i < 3; i++ {
@@ -128,10 +162,8 @@ func TestOutputFlushMetricsTimeSeriesWarning(t *testing.T) {
 	}
 	require.NoError(t, ingester.Stop())
 
-	// to keep things simple the internal limit is not passed to the message
-	// the code uses directly the global constant limit
 	expLine := "generated metrics with 3 unique time series, " +
-		"which is higher than the suggested limit of 100000"
+		"which is higher than the suggested limit of 2"
 	assert.True(t, testutils.LogContains(hook.Drain(), logrus.WarnLevel, expLine))
 }
 
@@ -147,7 +179,7 @@ func TestCardinalityControlAdd(t *testing.T) {
 
 	tags := registry.RootTagSet().With("k", "v")
 
-	cc := newCardinalityControl()
+	cc := newCardinalityControl(timeSeriesFirstLimit)
 	// the first iteration adds two new time series
 	// the second does not change the count
 	// because the time series have been already seen before
@@ -171,8 +203,7 @@ func TestCardinalityControlLimitHit(t *testing.T) {
 	m1, err := registry.NewMetric("metric1", metrics.Counter)
 	require.NoError(t, err)
 
-	cc := newCardinalityControl()
-	cc.timeSeriesLimit = 1
+	cc := newCardinalityControl(1)
 
 	cc.Add(metrics.TimeSeries{
 		Metric: m1,
@@ -205,3 +236,40 @@ func newTestPreInitState(tb testing.TB) *lib.TestPreInitState {
 		BuiltinMetrics: metrics.RegisterBuiltinMetrics(reg),
 	}
 }
+
+func TestIngesterOutputFlushRegexSubmetrics(t *testing.T) {
+	t.Parallel()
+
+	piState := newTestPreInitState(t)
+	testMetric, err := piState.Registry.NewMetric("test_metric", metrics.Counter)
+	require.NoError(t, err)
+
+	me := &MetricsEngine{
+		logger:          piState.Logger,
+		registry:        piState.Registry,
+		ObservedMetrics: make(map[string]*metrics.Metric),
+	}
+	submetric, err := me.getThresholdMetricOrSubmetric(`test_metric{name~"^/api/v1/.*"}`)
+	require.NoError(t, err)
+
+	ingester := OutputIngester{
+		logger:        piState.Logger,
+		metricsEngine: me,
+		cardinality:   newCardinalityControl(timeSeriesFirstLimit),
+	}
+	require.NoError(t, ingester.Start())
+	for _, name := range []string{"/api/v1/users", "/api/v1/orders", "/api/v2/users"} {
+		ingester.AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
+			TimeSeries: metrics.TimeSeries{
+				Metric: testMetric,
+				Tags:   piState.Registry.RootTagSet().With("name", name),
+			},
+			Value: 1,
+		}})
+	}
+	require.NoError(t, ingester.Stop())
+
+	sink, ok := submetric.Sink.(*metrics.CounterSink)
+	require.True(t, ok)
+	assert.Equal(t, 2.0, sink.Value)
+}
@@ -0,0 +1,39 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const thresholdsWebhookTimeout = 5 * time.Second
+
+// NewThresholdsWebhook returns a threshold breach handler that POSTs every
+// breach, encoded as JSON, to the provided URL. Any failure to deliver the
+// breach is logged, and doesn't affect the test run.
+func NewThresholdsWebhook(url string, logger logrus.FieldLogger) func(ThresholdBreach) {
+	logger = logger.WithField("component", "thresholds-webhook")
+	client := &http.Client{Timeout: thresholdsWebhookTimeout}
+
+	return func(breach ThresholdBreach) {
+		body, err := json.Marshal(breach)
+		if err != nil {
+			logger.WithError(err).Error("Unable to encode the threshold breach")
+			return
+		}
+
+		resp, err := client.Post(url, "application/json", bytes.NewReader(body)) //nolint:noctx
+		if err != nil {
+			logger.WithError(err).Warn("Unable to notify the threshold breach")
+			return
+		}
+		_ = resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			logger.Warnf("Unable to notify the threshold breach, the webhook responded with status %d", resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/lib/testutils"
+)
+
+func TestThresholdsWebhook(t *testing.T) {
+	t.Parallel()
+
+	var got ThresholdBreach
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	breach := ThresholdBreach{
+		Metric:    "http_req_duration",
+		Threshold: "p(95)<200",
+		Value:     250.5,
+		Time:      time.Date(2023, time.January, 2, 3, 4, 5, 0, time.UTC),
+	}
+	NewThresholdsWebhook(srv.URL, testutils.NewLogger(t))(breach)
+	assert.Equal(t, breach, got)
+}
+
+func TestThresholdsWebhookFailure(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	logHook := testutils.NewLogHook(logrus.WarnLevel)
+	logger := logrus.New()
+	logger.AddHook(logHook)
+	logger.SetOutput(testutils.NewTestOutput(t))
+
+	NewThresholdsWebhook(srv.URL, logger)(ThresholdBreach{Metric: "m1"})
+
+	entries := logHook.Drain()
+	require.Len(t, entries, 1)
+	assert.Contains(t, entries[0].Message, "status 500")
+}
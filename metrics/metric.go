@@ -3,6 +3,8 @@ package metrics
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 
 	"gopkg.in/guregu/null.v3"
@@ -15,6 +17,11 @@ type Metric struct {
 	Type     MetricType `json:"type"`
 	Contains ValueType  `json:"contains"`
 
+	// Unit is the optional unit of the values (e.g. ms, bytes, percent)
+	// and Description is an optional human-readable description.
+	Unit        string `json:"unit,omitempty"`
+	Description string `json:"description,omitempty"`
+
 	// TODO: decouple the metrics from the sinks and thresholds... have them
 	// linked, but not in the same struct?
 	Tainted    null.Bool    `json:"tainted"`
@@ -31,48 +38,113 @@ type Submetric struct {
 	Suffix string  `json:"suffix"` // TODO: rename?
 	Tags   *TagSet `json:"tags"`
 
+	// patterns are the tags matched by regular expression,
+	// i.e. the key~"regex" criteria, sorted by key.
+	patterns []tagPattern
+
 	Metric *Metric `json:"-"`
 	Parent *Metric `json:"-"`
 }
 
+// tagPattern is a submetric criteria matching
+// the values of a tag with a regular expression.
+type tagPattern struct {
+	key   string
+	value *regexp.Regexp
+}
+
+// Matches returns true if the tags satisfy all the submetric's criteria.
+func (sm *Submetric) Matches(tags *TagSet) bool {
+	if !tags.Contains(sm.Tags) {
+		return false
+	}
+	for _, p := range sm.patterns {
+		v, ok := tags.Get(p.key)
+		if !ok || !p.value.MatchString(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// samePatterns returns true if the submetric has exactly the provided patterns.
+func (sm *Submetric) samePatterns(patterns []tagPattern) bool {
+	if len(sm.patterns) != len(patterns) {
+		return false
+	}
+	for i, p := range patterns {
+		if sm.patterns[i].key != p.key || sm.patterns[i].value.String() != p.value.String() {
+			return false
+		}
+	}
+	return true
+}
+
 // AddSubmetric creates a new submetric from the key:value threshold definition
 // and adds it to the metric's submetrics list.
+//
+// Besides the exact key:value criteria, the tag values can be matched with
+// a regular expression using the key~"regex" form, for instance:
+// name~"^/api/v1/.*" matches all the names starting with /api/v1/.
 func (m *Metric) AddSubmetric(keyValues string) (*Submetric, error) {
 	keyValues = strings.TrimSpace(keyValues)
 	if len(keyValues) == 0 {
 		return nil, fmt.Errorf("submetric criteria for metric '%s' cannot be empty", m.Name)
 	}
-	kvs := strings.Split(keyValues, ",")
+	kvs := splitSubmetricCriteria(keyValues)
 	tags := m.registry.RootTagSet()
+	var patterns []tagPattern
 	for _, kv := range kvs {
 		if kv == "" {
 			continue
 		}
-		parts := strings.SplitN(kv, ":", 2)
+		key, op, value, ok := cutSubmetricCriterion(kv)
 
-		key := strings.Trim(strings.TrimSpace(parts[0]), `"'`)
-		if len(parts) != 2 {
+		key = strings.Trim(strings.TrimSpace(key), `"'`)
+		if !ok {
 			tags = tags.With(key, "")
 			continue
 		}
 
-		value := strings.Trim(strings.TrimSpace(parts[1]), `"'`)
-		tags = tags.With(key, value)
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if op == ':' {
+			tags = tags.With(key, value)
+			continue
+		}
+
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("submetric criteria for metric '%s' has an invalid regular expression "+
+				"for the tag '%s': %w", m.Name, key, err)
+		}
+		patterns = append(patterns, tagPattern{key: key, value: re})
 	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if patterns[i].key != patterns[j].key {
+			return patterns[i].key < patterns[j].key
+		}
+		return patterns[i].value.String() < patterns[j].value.String()
+	})
 
 	for _, sm := range m.Submetrics {
-		if tags == sm.Tags {
+		if tags == sm.Tags && sm.samePatterns(patterns) {
 			return sm, nil
 		}
 	}
 
 	subMetric := &Submetric{
-		Name:   m.Name + "{" + keyValues + "}",
-		Suffix: keyValues,
-		Tags:   tags,
-		Parent: m,
+		Name:     m.Name + "{" + keyValues + "}",
+		Suffix:   keyValues,
+		Tags:     tags,
+		patterns: patterns,
+		Parent:   m,
 	}
 	subMetricMetric := m.registry.newMetric(subMetric.Name, m.Type, m.Contains)
+	subMetricMetric.Unit = m.Unit
+	subMetricMetric.Description = m.Description
+	if hs, ok := m.Sink.(*HistogramSink); ok {
+		subMetricMetric.Sink = NewHistogramSink(hs.Buckets())
+	}
 	subMetricMetric.Sub = subMetric // sigh
 	subMetric.Metric = subMetricMetric
 
@@ -81,12 +153,56 @@ func (m *Metric) AddSubmetric(keyValues string) (*Submetric, error) {
 	return subMetric, nil
 }
 
+// splitSubmetricCriteria splits the comma-separated submetric criteria,
+// the commas between quotes (e.g. in a regular expression) are not separators.
+// A quote opens only as the first character of a value, right after the ':'
+// or '~' operator, so the apostrophes inside unquoted values are literal.
+func splitSubmetricCriteria(keyValues string) []string {
+	var (
+		criteria  []string
+		quote     rune
+		start     int
+		inValue   bool // the operator of the current criterion was found
+		valueSeen bool // the first character of the current value was found
+	)
+	for i, c := range keyValues {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == ',':
+			criteria = append(criteria, keyValues[start:i])
+			start = i + 1
+			inValue, valueSeen = false, false
+		case !inValue:
+			inValue = c == ':' || c == '~'
+		case !valueSeen && c != ' ':
+			valueSeen = true
+			if c == '"' || c == '\'' {
+				quote = c
+			}
+		}
+	}
+	return append(criteria, keyValues[start:])
+}
+
+// cutSubmetricCriterion splits a key:value or key~regex criterion around
+// its first operator, it returns false if there is no operator.
+func cutSubmetricCriterion(kv string) (key string, op byte, value string, ok bool) {
+	i := strings.IndexAny(kv, ":~")
+	if i < 0 {
+		return kv, 0, "", false
+	}
+	return kv[:i], kv[i], kv[i+1:], true
+}
+
 // ErrMetricNameParsing indicates parsing a metric name failed
 var ErrMetricNameParsing = errors.New("parsing metric name failed")
 
 // ParseMetricName parses a metric name expression of the form metric_name{tag_key:tag_value,...}
 // Its first return value is the parsed metric name, second are parsed tags as as slice
-// of "key:value" strings. On failure, it returns an error containing the `ErrMetricNameParsing` in its chain.
+// of "key:value" (or "key~regex") strings. On failure, it returns an error containing the `ErrMetricNameParsing` in its chain.
 func ParseMetricName(name string) (string, []string, error) {
 	openingTokenPos := strings.IndexByte(name, '{')
 	closingTokenPos := strings.LastIndexByte(name, '}')
@@ -129,13 +245,13 @@ func ParseMetricName(name string) (string, []string, error) {
 	// We already know the position of the opening and closing curly brace
 	// tokens. Thus, we extract the string in between them, and split its
 	// content to obtain the tags key values.
-	tags := strings.Split(name[openingTokenPos+1:closingTokenPos], ",")
+	tags := splitSubmetricCriteria(name[openingTokenPos+1 : closingTokenPos])
 
 	// For each tag definition, ensure it is correctly formed
 	for i, t := range tags {
-		keyValue := strings.SplitN(t, ":", 2)
+		_, _, value, ok := cutSubmetricCriterion(t)
 
-		if len(keyValue) != 2 || keyValue[1] == "" {
+		if !ok || value == "" {
 			return "", nil, fmt.Errorf("%w, metric %q tag expression is malformed", ErrMetricNameParsing, t)
 		}
 
@@ -48,6 +48,10 @@ func TestAddSubmetric(t *testing.T) {
 		` a : 1, b : 2 `:          {false, map[string]string{"a": "1", "b": "2"}},
 		`a : '1' , b : "2"`:       {false, map[string]string{"a": "1", "b": "2"}},
 		`" a" : ' 1' , b : "2 " `: {false, map[string]string{" a": " 1", "b": "2 "}}, //nolint:gocritic
+		`check:user's page loaded,scenario:main`: {
+			false, map[string]string{"check": "user's page loaded", "scenario": "main"},
+		},
+		`check:it's "done",scenario:main`: {false, map[string]string{"check": `it's "done`, "scenario": "main"}},
 	}
 
 	for name, expected := range testdata {
@@ -69,6 +73,48 @@ func TestAddSubmetric(t *testing.T) {
 	}
 }
 
+func TestAddSubmetricWithRegex(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	m := r.MustNewMetric("http_req_duration", Trend)
+
+	sm, err := m.AddSubmetric(`name~"^/api/v1/.*",method:GET`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"method": "GET"}, sm.Tags.Map())
+
+	sameSm, err := m.AddSubmetric(`name~"^/api/v1/.*",method:GET`)
+	require.NoError(t, err)
+	assert.Same(t, sm, sameSm)
+
+	exactSm, err := m.AddSubmetric(`method:GET`)
+	require.NoError(t, err)
+	assert.NotSame(t, sm, exactSm)
+
+	tests := []struct {
+		tags    map[string]string
+		matches bool
+	}{
+		{tags: map[string]string{"name": "/api/v1/users", "method": "GET"}, matches: true},
+		{tags: map[string]string{"name": "/api/v1/users", "method": "GET", "status": "200"}, matches: true},
+		{tags: map[string]string{"name": "/api/v1/users", "method": "POST"}, matches: false},
+		{tags: map[string]string{"name": "/api/v2/users", "method": "GET"}, matches: false},
+		{tags: map[string]string{"method": "GET"}, matches: false},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.matches, sm.Matches(r.RootTagSet().WithTagsFromMap(tc.tags)), tc.tags)
+	}
+
+	// the commas between quotes are part of the regular expression
+	sm, err = m.AddSubmetric(`status~"^2[0-9]{1,2}$", method:GET`)
+	require.NoError(t, err)
+	assert.True(t, sm.Matches(r.RootTagSet().WithTagsFromMap(map[string]string{"status": "204", "method": "GET"})))
+	assert.False(t, sm.Matches(r.RootTagSet().WithTagsFromMap(map[string]string{"status": "2040", "method": "GET"})))
+
+	_, err = m.AddSubmetric(`name~"(invalid"`)
+	require.ErrorContains(t, err, "invalid regular expression for the tag 'name'")
+}
+
 func TestParseMetricName(t *testing.T) {
 	t.Parallel()
 
@@ -127,6 +173,27 @@ func TestParseMetricName(t *testing.T) {
 			wantTags:             []string{"name:http://${}.com", "url:ssh://github.com:grafana/k6"},
 			wantErr:              false,
 		},
+		{
+			name:                 "metric name with regex tags",
+			metricNameExpression: `http_req_duration{name~"^/api/v1/.*",status~"^2[0-9]{1,2}$"}`,
+			wantMetricName:       "http_req_duration",
+			wantTags:             []string{`name~"^/api/v1/.*"`, `status~"^2[0-9]{1,2}$"`},
+			wantErr:              false,
+		},
+		{
+			name:                 "metric name with apostrophes inside unquoted tag values",
+			metricNameExpression: "checks{check:user's page loaded,scenario:main}",
+			wantMetricName:       "checks",
+			wantTags:             []string{"check:user's page loaded", "scenario:main"},
+			wantErr:              false,
+		},
+		{
+			name:                 "metric name with quoted tag values containing commas",
+			metricNameExpression: `checks{check:"a, b", scenario: 'main, it'}`,
+			wantMetricName:       "checks",
+			wantTags:             []string{`check:"a, b"`, `scenario: 'main, it'`},
+			wantErr:              false,
+		},
 		{
 			name:                 "metric name with tag definition missing `:value`",
 			metricNameExpression: "test_metric{easyas}",
@@ -7,20 +7,22 @@ type MetricType int
 
 // Possible values for MetricType.
 const (
-	Counter = MetricType(iota) // A counter that sums its data points
-	Gauge                      // A gauge that displays the latest value
-	Trend                      // A trend, min/max/avg/med are interesting
-	Rate                       // A rate, displays % of values that aren't 0
+	Counter   = MetricType(iota) // A counter that sums its data points
+	Gauge                        // A gauge that displays the latest value
+	Trend                        // A trend, min/max/avg/med are interesting
+	Rate                         // A rate, displays % of values that aren't 0
+	Histogram                    // A histogram, counts the values in configurable buckets
 )
 
 // ErrInvalidMetricType indicates the serialized metric type is invalid.
 var ErrInvalidMetricType = errors.New("invalid metric type")
 
 const (
-	counterString = "counter"
-	gaugeString   = "gauge"
-	trendString   = "trend"
-	rateString    = "rate"
+	counterString   = "counter"
+	gaugeString     = "gauge"
+	trendString     = "trend"
+	rateString      = "rate"
+	histogramString = "histogram"
 
 	defaultString = "default"
 	timeString    = "time"
@@ -47,6 +49,8 @@ func (t MetricType) MarshalText() ([]byte, error) {
 		return []byte(trendString), nil
 	case Rate:
 		return []byte(rateString), nil
+	case Histogram:
+		return []byte(histogramString), nil
 	default:
 		return nil, ErrInvalidMetricType
 	}
@@ -63,6 +67,8 @@ func (t *MetricType) UnmarshalText(data []byte) error {
 		*t = Trend
 	case rateString:
 		*t = Rate
+	case histogramString:
+		*t = Histogram
 	default:
 		return ErrInvalidMetricType
 	}
@@ -80,6 +86,8 @@ func (t MetricType) String() string {
 		return trendString
 	case Rate:
 		return rateString
+	case Histogram:
+		return histogramString
 	default:
 		return "[INVALID]"
 	}
@@ -103,6 +111,15 @@ func (t MetricType) supportedAggregationMethods() []string {
 			tokenMed,
 			tokenPercentile,
 		}
+	case Histogram:
+		return []string{
+			tokenCount,
+			tokenAvg,
+			tokenMin,
+			tokenMax,
+			tokenMed,
+			tokenPercentile,
+		}
 	default:
 		// unreachable!
 		panic("unreachable")
@@ -14,6 +14,10 @@ type Registry struct {
 	l       sync.RWMutex
 
 	rootTagSet *atlas.Node
+
+	// trendSinkMaxValues is the max number of values
+	// stored by the Trend sinks, zero means no limit.
+	trendSinkMaxValues int
 }
 
 // NewRegistry returns a new registry
@@ -37,6 +41,52 @@ func checkName(name string) bool {
 // NewMetric returns new metric registered to this registry
 // TODO have multiple versions returning specific metric types when we have such things
 func (r *Registry) NewMetric(name string, typ MetricType, t ...ValueType) (*Metric, error) {
+	return r.getOrNewMetric(name, typ, nil, t...)
+}
+
+// NewMetricWithInfo is like NewMetric, but it also sets the unit (e.g. ms, bytes, percent)
+// and the human-readable description of the metric, both are optional.
+//
+// It returns an error if the metric already exists with a different unit.
+// The description is only set if the already existing metric doesn't have one.
+func (r *Registry) NewMetricWithInfo(
+	name string, typ MetricType, unit, description string, t ...ValueType,
+) (*Metric, error) {
+	m, err := r.NewMetric(name, typ, t...)
+	if err != nil {
+		return nil, err
+	}
+
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	if unit != "" {
+		if m.Unit != "" && m.Unit != unit {
+			return nil, fmt.Errorf("metric '%s' already exists but with unit %s, instead of %s", name, m.Unit, unit)
+		}
+		m.Unit = unit
+	}
+	if m.Description == "" {
+		m.Description = description
+	}
+	return m, nil
+}
+
+// NewHistogram returns a new Histogram metric registered to this registry,
+// counting the values in buckets with the provided upper bounds.
+// It returns an error if a Histogram with the same name but
+// different buckets already exists.
+func (r *Registry) NewHistogram(name string, buckets []float64, t ...ValueType) (*Metric, error) {
+	if err := ValidateHistogramBuckets(buckets); err != nil {
+		return nil, fmt.Errorf("invalid buckets for the histogram metric '%s': %w", name, err)
+	}
+	return r.getOrNewMetric(name, Histogram, buckets, t...)
+}
+
+// getOrNewMetric returns the metric with the given name, creating it if it doesn't exist.
+// For the Histogram metrics, a nil buckets means the default ones on creation
+// and any of them on an already existing metric.
+func (r *Registry) getOrNewMetric(name string, typ MetricType, buckets []float64, t ...ValueType) (*Metric, error) {
 	r.l.Lock()
 	defer r.l.Unlock()
 
@@ -47,6 +97,9 @@ func (r *Registry) NewMetric(name string, typ MetricType, t ...ValueType) (*Metr
 
 	if !ok {
 		m := r.newMetric(name, typ, t...)
+		if buckets != nil {
+			m.Sink = NewHistogramSink(buckets)
+		}
 		r.metrics[name] = m
 		return m, nil
 	}
@@ -59,9 +112,27 @@ func (r *Registry) NewMetric(name string, typ MetricType, t ...ValueType) (*Metr
 				name, oldMetric.Contains, t[0])
 		}
 	}
+	if hs, isHistogram := oldMetric.Sink.(*HistogramSink); isHistogram && buckets != nil {
+		if !equalBuckets(hs.Buckets(), buckets) {
+			return nil, fmt.Errorf("metric '%s' already exists but with the buckets %v, instead of %v",
+				name, hs.Buckets(), buckets)
+		}
+	}
 	return oldMetric, nil
 }
 
+func equalBuckets(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // MustNewMetric is like NewMetric, but will panic if there is an error
 func (r *Registry) MustNewMetric(name string, typ MetricType, t ...ValueType) *Metric {
 	m, err := r.NewMetric(name, typ, t...)
@@ -92,7 +163,12 @@ func (r *Registry) newMetric(name string, mt MetricType, vt ...ValueType) *Metri
 		valueType = vt[0]
 	}
 
-	sink := NewSink(mt)
+	var sink Sink
+	if mt == Trend && r.trendSinkMaxValues > 0 {
+		sink = NewTrendSinkWithMaxValues(r.trendSinkMaxValues)
+	} else {
+		sink = NewSink(mt)
+	}
 	return &Metric{
 		registry: r,
 		Name:     name,
@@ -102,6 +178,25 @@ func (r *Registry) newMetric(name string, mt MetricType, vt ...ValueType) *Metri
 	}
 }
 
+// SetTrendSinkMaxValues sets the max number of values stored by the Trend sinks,
+// after which the percentiles are estimated. Zero means no limit.
+//
+// It applies to the metrics registered afterwards and
+// to the already registered metrics without any value.
+func (r *Registry) SetTrendSinkMaxValues(maxValues int) {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	r.trendSinkMaxValues = maxValues
+	for _, m := range r.metrics {
+		ts, ok := m.Sink.(*TrendSink)
+		if !ok || !ts.IsEmpty() {
+			continue
+		}
+		ts.maxValues = maxValues
+	}
+}
+
 // Get returns the Metric with the given name. If that metric doesn't exist,
 // Get() will return a nil value.
 func (r *Registry) Get(name string) *Metric {
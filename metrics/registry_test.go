@@ -28,6 +28,77 @@ func TestRegistryNewMetric(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestRegistryNewMetricWithInfo(t *testing.T) {
+	t.Parallel()
+	r := NewRegistry()
+
+	size, err := r.NewMetricWithInfo("size", Trend, UnitBytes, "The size of the payloads")
+	require.NoError(t, err)
+	assert.Equal(t, UnitBytes, size.Unit)
+	assert.Equal(t, "The size of the payloads", size.Description)
+
+	sizeAgain, err := r.NewMetricWithInfo("size", Trend, UnitBytes, "Another description")
+	require.NoError(t, err)
+	require.Same(t, size, sizeAgain)
+	assert.Equal(t, "The size of the payloads", sizeAgain.Description)
+
+	sizeAgain, err = r.NewMetric("size", Trend)
+	require.NoError(t, err)
+	require.Same(t, size, sizeAgain)
+
+	_, err = r.NewMetricWithInfo("size", Trend, UnitPercent, "")
+	require.ErrorContains(t, err, "already exists but with unit bytes")
+
+	_, err = r.NewMetricWithInfo("size", Gauge, UnitBytes, "")
+	require.Error(t, err)
+
+	plain, err := r.NewMetric("plain", Counter)
+	require.NoError(t, err)
+	described, err := r.NewMetricWithInfo("plain", Counter, "", "A plain counter")
+	require.NoError(t, err)
+	require.Same(t, plain, described)
+	assert.Empty(t, described.Unit)
+	assert.Equal(t, "A plain counter", described.Description)
+}
+
+func TestRegistryNewHistogram(t *testing.T) {
+	t.Parallel()
+	r := NewRegistry()
+
+	sizes, err := r.NewHistogram("sizes", []float64{1, 10, 100})
+	require.NoError(t, err)
+	require.Equal(t, Histogram, sizes.Type)
+	sink, ok := sizes.Sink.(*HistogramSink)
+	require.True(t, ok)
+	assert.Equal(t, []float64{1, 10, 100}, sink.Buckets())
+
+	sizesAgain, err := r.NewHistogram("sizes", []float64{1, 10, 100})
+	require.NoError(t, err)
+	require.Same(t, sizes, sizesAgain)
+
+	sizesAgain, err = r.NewMetric("sizes", Histogram)
+	require.NoError(t, err)
+	require.Same(t, sizes, sizesAgain)
+
+	_, err = r.NewHistogram("sizes", []float64{1, 10})
+	require.ErrorContains(t, err, "already exists but with the buckets")
+
+	_, err = r.NewHistogram("invalid", []float64{10, 1})
+	require.ErrorContains(t, err, "invalid buckets")
+
+	depths, err := r.NewMetric("depths", Histogram)
+	require.NoError(t, err)
+	sink, ok = depths.Sink.(*HistogramSink)
+	require.True(t, ok)
+	assert.Equal(t, DefaultHistogramBuckets, sink.Buckets())
+
+	sub, err := sizes.AddSubmetric("a:1")
+	require.NoError(t, err)
+	sink, ok = sub.Metric.Sink.(*HistogramSink)
+	require.True(t, ok)
+	assert.Equal(t, []float64{1, 10, 100}, sink.Buckets())
+}
+
 func TestMetricNames(t *testing.T) {
 	t.Parallel()
 	testMap := map[string]bool{
@@ -101,3 +172,20 @@ func TestRegistryAll(t *testing.T) {
 		assert.ElementsMatch(t, exp, names(metrics))
 	})
 }
+
+func TestRegistrySetTrendSinkMaxValues(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	before := r.MustNewMetric("before", Trend)
+	r.SetTrendSinkMaxValues(10)
+	after := r.MustNewMetric("after", Trend)
+	counter := r.MustNewMetric("counter", Counter)
+
+	for _, m := range []*Metric{before, after} {
+		sink, ok := m.Sink.(*TrendSink)
+		require.True(t, ok)
+		assert.Equal(t, 10, sink.maxValues)
+	}
+	assert.IsType(t, &CounterSink{}, counter.Sink)
+}
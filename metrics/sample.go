@@ -25,6 +25,12 @@ type Sample struct {
 	Time  time.Time
 	Value float64
 
+	// Optional number of occurrences of Value that the sample represents,
+	// it allows to add pre-aggregated data with a single sample.
+	//
+	// Zero is the same as one, use Occurrences to get the actual number.
+	Weight uint64
+
 	// Optional high-cardinality metadata that won't be indexed in atlas.
 	//
 	// It can be nil if it wasn't explicitly specified, reduce memory
@@ -32,6 +38,15 @@ type Sample struct {
 	Metadata map[string]string
 }
 
+// Occurrences returns the number of occurrences of the value
+// that the sample represents, taking into account its Weight.
+func (s Sample) Occurrences() uint64 {
+	if s.Weight == 0 {
+		return 1
+	}
+	return s.Weight
+}
+
 // SampleContainer is a simple abstraction that allows sample
 // producers to attach extra information to samples they return
 type SampleContainer interface {
@@ -107,3 +107,11 @@ func createTestTrendSink(count int) *TrendSink {
 
 	return sink
 }
+
+func TestSampleOccurrences(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, uint64(1), Sample{}.Occurrences())
+	assert.Equal(t, uint64(1), Sample{Weight: 1}.Occurrences())
+	assert.Equal(t, uint64(42), Sample{Weight: 42}.Occurrences())
+}
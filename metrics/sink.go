@@ -1,8 +1,11 @@
 package metrics
 
 import (
+	"container/heap"
+	"errors"
 	"fmt"
 	"math"
+	"math/rand"
 	"sort"
 	"time"
 )
@@ -12,6 +15,7 @@ var (
 	_ Sink = &GaugeSink{}
 	_ Sink = NewTrendSink()
 	_ Sink = &RateSink{}
+	_ Sink = NewHistogramSink(DefaultHistogramBuckets)
 )
 
 type Sink interface {
@@ -33,6 +37,8 @@ func NewSink(mt MetricType) Sink {
 		sink = NewTrendSink()
 	case Rate:
 		sink = &RateSink{}
+	case Histogram:
+		sink = NewHistogramSink(DefaultHistogramBuckets)
 	default:
 		// Should not be possible to create
 		// an invalid metric type except for specific
@@ -42,25 +48,74 @@ func NewSink(mt MetricType) Sink {
 	return sink
 }
 
+// CounterRateWindow is the time window over which the CounterSink
+// calculates its recent rate, reported as recent_rate by Format.
+const CounterRateWindow = 10 * time.Second
+
+const counterRateWindowSeconds = int64(CounterRateWindow / time.Second)
+
 type CounterSink struct {
 	Value float64
 	First time.Time
+
+	// window holds the sum of the values added during each one of the
+	// last seconds, indexed by the second's Unix time modulo its length.
+	// It has room for the current second too, on top of the whole window.
+	window [counterRateWindowSeconds + 1]counterSecond
+}
+
+// counterSecond is the sum of the values added during a second.
+type counterSecond struct {
+	unix  int64
+	value float64
 }
 
 func (c *CounterSink) Add(s Sample) {
-	c.Value += s.Value
+	value := s.Value * float64(s.Occurrences())
+	c.Value += value
 	if c.First.IsZero() {
 		c.First = s.Time
 	}
+	c.addToWindow(s.Time.Unix(), value)
+}
+
+func (c *CounterSink) addToWindow(unix int64, value float64) {
+	size := int64(len(c.window))
+	bucket := &c.window[(unix%size+size)%size]
+	switch {
+	case bucket.unix == unix:
+		bucket.value += value
+	case bucket.unix < unix:
+		*bucket = counterSecond{unix: unix, value: value}
+	default:
+		// the sample is older than the whole window
+	}
 }
 
 // IsEmpty indicates whether the CounterSink is empty.
 func (c *CounterSink) IsEmpty() bool { return c.First.IsZero() }
 
+// RecentRate returns the per-second rate of the values added during the
+// CounterRateWindow that precedes the provided time. The current second isn't
+// included, since its values are likely still being added.
+func (c *CounterSink) RecentRate(now time.Time) float64 {
+	end := now.Unix()
+	start := end - counterRateWindowSeconds
+
+	var sum float64
+	for _, bucket := range c.window {
+		if bucket.unix >= start && bucket.unix < end {
+			sum += bucket.value
+		}
+	}
+	return sum / float64(counterRateWindowSeconds)
+}
+
 func (c *CounterSink) Format(t time.Duration) map[string]float64 {
 	return map[string]float64{
-		"count": c.Value,
-		"rate":  c.Value / (float64(t) / float64(time.Second)),
+		"count":       c.Value,
+		"rate":        c.Value / (float64(t) / float64(time.Second)),
+		"recent_rate": c.RecentRate(time.Now()),
 	}
 }
 
@@ -68,13 +123,31 @@ type GaugeSink struct {
 	Value    float64
 	Max, Min float64
 	minSet   bool
+
+	// last is the time of the sample of the current value.
+	last time.Time
 }
 
 // IsEmpty indicates whether the GaugeSink is empty.
 func (g *GaugeSink) IsEmpty() bool { return !g.minSet }
 
+// LastUpdate returns the time of the sample of the current value.
+func (g *GaugeSink) LastUpdate() time.Time { return g.last }
+
+// IsStale indicates whether the current value is older than the provided TTL,
+// so it isn't expected to be reported as current anymore.
+// A non-positive TTL means that the value never expires, as well as
+// the values of the samples without a time.
+func (g *GaugeSink) IsStale(now time.Time, ttl time.Duration) bool {
+	if ttl <= 0 || g.last.IsZero() {
+		return false
+	}
+	return now.Sub(g.last) > ttl
+}
+
 func (g *GaugeSink) Add(s Sample) {
 	g.Value = s.Value
+	g.last = s.Time
 	if s.Value > g.Max {
 		g.Max = s.Value
 	}
@@ -88,14 +161,40 @@ func (g *GaugeSink) Format(t time.Duration) map[string]float64 {
 	return map[string]float64{"value": g.Value}
 }
 
-// NewTrendSink makes a Trend sink with the OpenHistogram circllhist histogram.
+// NewTrendSink makes a Trend sink storing all the values,
+// so the percentiles are exact.
 func NewTrendSink() *TrendSink {
 	return &TrendSink{}
 }
 
+// NewTrendSinkWithMaxValues makes a Trend sink storing at most maxValues values.
+// The percentiles are exact until the limit is reached, then they are estimated
+// from a random sample of all the added values, weighted by their occurrences.
+// Min, max, average and count are always exact. Zero means no limit.
+func NewTrendSinkWithMaxValues(maxValues int) *TrendSink {
+	return &TrendSink{maxValues: maxValues}
+}
+
 type TrendSink struct {
 	values []float64
-	sorted bool
+	// weights are the numbers of occurrences of the stored values,
+	// nil while all of them are one.
+	weights []uint64
+	// cumWeights are the cumulative weights of the sorted values,
+	// computed when the values are sorted if they are weighted.
+	cumWeights []float64
+	sorted     bool
+
+	// maxValues is the max number of stored values, zero means no limit.
+	maxValues int
+	rnd       *rand.Rand
+	// priorities are the priorities of the stored values for the priority
+	// sampling, they are kept in a min-heap when heapified is true.
+	priorities []float64
+	heapified  bool
+	// threshold is the highest priority of a value that was not stored,
+	// zero while all the values are stored.
+	threshold float64
 
 	count    uint64
 	min, max float64
@@ -105,6 +204,7 @@ type TrendSink struct {
 // IsEmpty indicates whether the TrendSink is empty.
 func (t *TrendSink) IsEmpty() bool { return t.count == 0 }
 
+// Add adds the sample's value, with all its occurrences at once.
 func (t *TrendSink) Add(s Sample) {
 	if t.count == 0 {
 		t.max, t.min = s.Value, s.Value
@@ -117,36 +217,176 @@ func (t *TrendSink) Add(s Sample) {
 		}
 	}
 
-	t.values = append(t.values, s.Value)
+	n := s.Occurrences()
+	t.count += n
+	t.sum += s.Value * float64(n)
+
+	if t.maxValues <= 0 {
+		t.store(s.Value, n)
+		return
+	}
+	t.sample(s.Value, n, t.priority(n))
+}
+
+// priority returns a random priority for the value with n occurrences.
+func (t *TrendSink) priority(n uint64) float64 {
+	if t.rnd == nil {
+		t.rnd = rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
+	}
+	// the random number is in (0, 1], so the priority is always finite
+	return float64(n) / (1 - t.rnd.Float64())
+}
+
+// store appends the value with its number of occurrences.
+func (t *TrendSink) store(v float64, n uint64) {
+	if n != 1 && t.weights == nil {
+		t.weights = make([]uint64, len(t.values), cap(t.values))
+		for i := range t.weights {
+			t.weights[i] = 1
+		}
+	}
+	t.values = append(t.values, v)
+	if t.weights != nil {
+		t.weights = append(t.weights, n)
+	}
 	t.sorted = false
-	t.count++
-	t.sum += s.Value
+}
+
+// sample stores the value with its number of occurrences, if its priority is
+// one of the maxValues highest ones (priority sampling, as described by
+// Duffield, Lund and Thorup). The values are then weighted by the max of their
+// number of occurrences and the threshold, for an unbiased estimation.
+func (t *TrendSink) sample(v float64, n uint64, priority float64) {
+	if t.weights == nil {
+		t.weights = make([]uint64, len(t.values), t.maxValues)
+		for i := range t.weights {
+			t.weights[i] = 1
+		}
+	}
+	if len(t.values) < t.maxValues {
+		t.values = append(t.values, v)
+		t.weights = append(t.weights, n)
+		t.priorities = append(t.priorities, priority)
+		t.sorted, t.heapified = false, false
+		return
+	}
+
+	h := trendHeap{t}
+	if !t.heapified {
+		heap.Init(h)
+		t.heapified = true
+	}
+	if priority <= t.priorities[0] {
+		t.threshold = math.Max(t.threshold, priority)
+		return
+	}
+	t.threshold = math.Max(t.threshold, t.priorities[0])
+	t.values[0], t.weights[0], t.priorities[0] = v, n, priority
+	heap.Fix(h, 0)
+	t.sorted = false
+}
+
+// weight returns the estimated number of occurrences of the i-th stored value.
+func (t *TrendSink) weight(i int) float64 {
+	if t.weights == nil {
+		return 1
+	}
+	return math.Max(float64(t.weights[i]), t.threshold)
+}
+
+// IsExact returns true if all the values are stored,
+// so the percentiles are exact and not estimated.
+func (t *TrendSink) IsExact() bool {
+	return t.threshold == 0
+}
+
+// sort sorts the stored values, with their weights and priorities.
+func (t *TrendSink) sort() {
+	if t.sorted {
+		return
+	}
+	if t.weights == nil {
+		sort.Float64s(t.values)
+	} else {
+		sort.Sort(trendValues{t})
+		t.cumWeights = t.cumWeights[:0]
+		var cum float64
+		for i := range t.values {
+			cum += t.weight(i)
+			t.cumWeights = append(t.cumWeights, cum)
+		}
+	}
+	t.sorted, t.heapified = true, false
+}
+
+// valueAt returns the value at the position of the sorted values,
+// as if each value was repeated as many times as its weight.
+func (t *TrendSink) valueAt(pos float64) float64 {
+	if t.weights == nil {
+		return t.values[int(pos)]
+	}
+	i := sort.Search(len(t.cumWeights), func(i int) bool { return t.cumWeights[i] > pos })
+	if i == len(t.values) {
+		i--
+	}
+	return t.values[i]
 }
 
 // P calculates the given percentile from sink values.
+//
+// If the sink has a limit of stored values and it has been exceeded,
+// the percentile is estimated from the stored values.
 func (t *TrendSink) P(pct float64) float64 {
-	switch t.count {
+	n := len(t.values)
+	switch n {
 	case 0:
 		return 0
 	case 1:
 		return t.values[0]
 	default:
-		if !t.sorted {
-			sort.Float64s(t.values)
-			t.sorted = true
+		t.sort()
+
+		total := float64(n)
+		if t.weights != nil {
+			total = t.cumWeights[n-1]
 		}
 
 		// If percentile falls on a value in Values slice, we return that value.
 		// If percentile does not fall on a value in Values slice, we calculate (linear interpolation)
 		// the value that would fall at percentile, given the values above and below that percentile.
-		i := pct * (float64(t.count) - 1.0)
-		j := t.values[int(math.Floor(i))]
-		k := t.values[int(math.Ceil(i))]
+		i := pct * (total - 1.0)
+		j := t.valueAt(math.Floor(i))
+		k := t.valueAt(math.Ceil(i))
 		f := i - math.Floor(i)
 		return j + (k-j)*f
 	}
 }
 
+// trendValues sorts the stored values of a TrendSink,
+// along with their weights and priorities.
+type trendValues struct{ *TrendSink }
+
+func (tv trendValues) Len() int           { return len(tv.values) }
+func (tv trendValues) Less(i, j int) bool { return tv.values[i] < tv.values[j] }
+func (tv trendValues) Swap(i, j int) {
+	tv.values[i], tv.values[j] = tv.values[j], tv.values[i]
+	tv.weights[i], tv.weights[j] = tv.weights[j], tv.weights[i]
+	if tv.priorities != nil {
+		tv.priorities[i], tv.priorities[j] = tv.priorities[j], tv.priorities[i]
+	}
+}
+
+// trendHeap is a min-heap of the stored values of a TrendSink by priority.
+type trendHeap struct{ *TrendSink }
+
+func (th trendHeap) Len() int           { return len(th.values) }
+func (th trendHeap) Less(i, j int) bool { return th.priorities[i] < th.priorities[j] }
+func (th trendHeap) Swap(i, j int)      { trendValues(th).Swap(i, j) }
+
+// Push and Pop are never called, as the heap is only initialized and fixed.
+func (trendHeap) Push(interface{}) { panic("not supported") }
+func (trendHeap) Pop() interface{} { panic("not supported") }
+
 // Min returns the minimum value.
 func (t *TrendSink) Min() float64 {
 	return t.min
@@ -175,8 +415,9 @@ func (t *TrendSink) Total() float64 {
 	return t.sum
 }
 
+// Format returns the default trend stats,
+// FormatTrendStats can be used for getting specific ones.
 func (t *TrendSink) Format(tt time.Duration) map[string]float64 {
-	// TODO: respect the summaryTrendStats for REST API
 	return map[string]float64{
 		"min":   t.Min(),
 		"max":   t.Max(),
@@ -187,6 +428,21 @@ func (t *TrendSink) Format(tt time.Duration) map[string]float64 {
 	}
 }
 
+// FormatTrendStats returns the provided trend stats (e.g. "avg", "count" or "p(99.9)"),
+// it returns an error if any of them is not a valid trend stat.
+func (t *TrendSink) FormatTrendStats(stats []string) (map[string]float64, error) {
+	resolvers, err := GetResolversForTrendColumns(stats)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]float64, len(stats))
+	for _, stat := range stats {
+		result[stat] = resolvers[stat](t)
+	}
+	return result, nil
+}
+
 type RateSink struct {
 	Trues int64
 	Total int64
@@ -196,9 +452,10 @@ type RateSink struct {
 func (r *RateSink) IsEmpty() bool { return r.Total == 0 }
 
 func (r *RateSink) Add(s Sample) {
-	r.Total += 1
+	n := int64(s.Occurrences())
+	r.Total += n
 	if s.Value != 0 {
-		r.Trues += 1
+		r.Trues += n
 	}
 }
 
@@ -210,3 +467,151 @@ func (r RateSink) Format(t time.Duration) map[string]float64 {
 
 	return map[string]float64{"rate": rate}
 }
+
+// DefaultHistogramBuckets are the upper bounds of the buckets
+// used by the Histogram metrics without explicit buckets.
+var DefaultHistogramBuckets = []float64{ //nolint:gochecknoglobals
+	1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000,
+}
+
+// ValidateHistogramBuckets checks that the provided upper bounds
+// are usable as Histogram buckets: they must be finite and sorted
+// in strictly increasing order.
+func ValidateHistogramBuckets(buckets []float64) error {
+	if len(buckets) == 0 {
+		return errors.New("at least one bucket is required")
+	}
+	for i, b := range buckets {
+		if math.IsNaN(b) || math.IsInf(b, 0) {
+			return fmt.Errorf("the bucket upper bound %g is not a finite number", b)
+		}
+		if i > 0 && b <= buckets[i-1] {
+			return fmt.Errorf("the bucket upper bounds must be in increasing order, %g follows %g", b, buckets[i-1])
+		}
+	}
+	return nil
+}
+
+// HistogramSink counts the values in buckets with fixed upper bounds,
+// the last bucket has no upper bound and counts the values greater than
+// all the others.
+// The percentiles are estimated by linear interpolation inside the buckets.
+type HistogramSink struct {
+	buckets []float64
+	counts  []uint64
+
+	count    uint64
+	min, max float64
+	sum      float64
+}
+
+// NewHistogramSink makes a Histogram sink with the provided bucket upper bounds,
+// they are expected to be valid as checked by ValidateHistogramBuckets.
+func NewHistogramSink(buckets []float64) *HistogramSink {
+	return &HistogramSink{
+		buckets: append([]float64(nil), buckets...),
+		counts:  make([]uint64, len(buckets)+1),
+	}
+}
+
+// IsEmpty indicates whether the HistogramSink is empty.
+func (h *HistogramSink) IsEmpty() bool { return h.count == 0 }
+
+// Add counts the sample's value in the first bucket
+// with an upper bound greater than or equal to it.
+func (h *HistogramSink) Add(s Sample) {
+	if h.count == 0 {
+		h.max, h.min = s.Value, s.Value
+	} else {
+		if s.Value > h.max {
+			h.max = s.Value
+		}
+		if s.Value < h.min {
+			h.min = s.Value
+		}
+	}
+
+	n := s.Occurrences()
+	h.count += n
+	h.sum += s.Value * float64(n)
+	h.counts[sort.SearchFloat64s(h.buckets, s.Value)] += n
+}
+
+// Buckets returns the upper bounds of the buckets,
+// without the last unbounded one.
+func (h *HistogramSink) Buckets() []float64 {
+	return h.buckets
+}
+
+// BucketCounts returns the number of values counted in each bucket,
+// the last item is the count of the values greater than all the upper bounds.
+func (h *HistogramSink) BucketCounts() []uint64 {
+	return h.counts
+}
+
+// Min returns the minimum value.
+func (h *HistogramSink) Min() float64 {
+	return h.min
+}
+
+// Max returns the maximum value.
+func (h *HistogramSink) Max() float64 {
+	return h.max
+}
+
+// Count returns the number of recorded values.
+func (h *HistogramSink) Count() uint64 {
+	return h.count
+}
+
+// Avg returns the average (i.e. mean) value.
+func (h *HistogramSink) Avg() float64 {
+	if h.count > 0 {
+		return h.sum / float64(h.count)
+	}
+	return 0
+}
+
+// Total returns the total (i.e. "sum") value for all measurements.
+func (h *HistogramSink) Total() float64 {
+	return h.sum
+}
+
+// P estimates the given percentile, assuming the values
+// are evenly distributed inside each bucket.
+// The bounds of the buckets are clamped to the min and max values.
+func (h *HistogramSink) P(pct float64) float64 {
+	if h.count == 0 {
+		return 0
+	}
+
+	rank := pct * float64(h.count)
+	var cumulative uint64
+	for i, c := range h.counts {
+		if c == 0 || float64(cumulative+c) < rank {
+			cumulative += c
+			continue
+		}
+
+		lower, upper := h.min, h.max
+		if i > 0 && h.buckets[i-1] > lower {
+			lower = h.buckets[i-1]
+		}
+		if i < len(h.buckets) && h.buckets[i] < upper {
+			upper = h.buckets[i]
+		}
+		return lower + (upper-lower)*(rank-float64(cumulative))/float64(c)
+	}
+	return h.max
+}
+
+// Format returns the count, min, max, avg and median values.
+func (h *HistogramSink) Format(time.Duration) map[string]float64 {
+	return map[string]float64{
+		"count": float64(h.Count()),
+		"min":   h.Min(),
+		"max":   h.Max(),
+		"avg":   h.Avg(),
+		"med":   h.P(0.5),
+	}
+}
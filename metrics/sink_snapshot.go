@@ -0,0 +1,528 @@
+package metrics
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// SnapshotSink is a Sink whose state can be serialized into a snapshot and merged
+// with the state of other sinks of the same kind, for instance to aggregate
+// the sinks of multiple instances of a distributed test and evaluate
+// the thresholds globally.
+type SnapshotSink interface {
+	Sink
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+
+	// Merge adds the state of the other sink, which must be of the same kind.
+	Merge(other Sink) error
+}
+
+var (
+	_ SnapshotSink = &CounterSink{}
+	_ SnapshotSink = &GaugeSink{}
+	_ SnapshotSink = NewTrendSink()
+	_ SnapshotSink = &RateSink{}
+	_ SnapshotSink = NewHistogramSink(DefaultHistogramBuckets)
+)
+
+// sinkSnapshotVersion is the version of the binary format of the sink snapshots.
+const sinkSnapshotVersion = 2
+
+// ErrInvalidSinkSnapshot indicates a sink snapshot can't be decoded.
+var ErrInvalidSinkSnapshot = errors.New("invalid sink snapshot")
+
+// The kinds of sink a snapshot can hold, they prevent
+// the snapshot of a sink to be decoded into another kind.
+const (
+	snapshotKindCounter byte = iota + 1
+	snapshotKindGauge
+	snapshotKindTrend
+	snapshotKindRate
+	snapshotKindHistogram
+)
+
+// snapshotWriter encodes the fields of a sink snapshot.
+type snapshotWriter struct {
+	buf []byte
+}
+
+func newSnapshotWriter(kind byte) *snapshotWriter {
+	return &snapshotWriter{buf: []byte{sinkSnapshotVersion, kind}}
+}
+
+func (w *snapshotWriter) uint64(v uint64) {
+	w.buf = binary.LittleEndian.AppendUint64(w.buf, v)
+}
+
+func (w *snapshotWriter) float64(v float64) {
+	w.uint64(math.Float64bits(v))
+}
+
+func (w *snapshotWriter) bool(v bool) {
+	if v {
+		w.buf = append(w.buf, 1)
+		return
+	}
+	w.buf = append(w.buf, 0)
+}
+
+func (w *snapshotWriter) time(t time.Time) {
+	w.bool(!t.IsZero())
+	if !t.IsZero() {
+		w.uint64(uint64(t.UnixNano()))
+	}
+}
+
+// snapshotReader decodes the fields of a sink snapshot,
+// the first error is retained and the next reads are no-op.
+type snapshotReader struct {
+	buf []byte
+	err error
+}
+
+func newSnapshotReader(data []byte, kind byte) *snapshotReader {
+	r := &snapshotReader{buf: data}
+	if len(data) < 2 {
+		r.err = fmt.Errorf("%w: the snapshot is too short", ErrInvalidSinkSnapshot)
+		return r
+	}
+	if data[0] != sinkSnapshotVersion {
+		r.err = fmt.Errorf("%w: unsupported version %d", ErrInvalidSinkSnapshot, data[0])
+		return r
+	}
+	if data[1] != kind {
+		r.err = fmt.Errorf("%w: the snapshot is of another kind of sink", ErrInvalidSinkSnapshot)
+		return r
+	}
+	r.buf = data[2:]
+	return r
+}
+
+func (r *snapshotReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.buf) < n {
+		r.err = fmt.Errorf("%w: unexpected end of the snapshot", ErrInvalidSinkSnapshot)
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *snapshotReader) uint64() uint64 {
+	b := r.next(8)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+func (r *snapshotReader) float64() float64 {
+	return math.Float64frombits(r.uint64())
+}
+
+func (r *snapshotReader) bool() bool {
+	b := r.next(1)
+	return b != nil && b[0] == 1
+}
+
+func (r *snapshotReader) time() time.Time {
+	if !r.bool() {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(r.uint64()))
+}
+
+// length reads the length of a list, with items of itemSize bytes,
+// checking that the snapshot is long enough to hold it.
+func (r *snapshotReader) length(itemSize int) int {
+	n := r.uint64()
+	if r.err == nil && n > uint64(len(r.buf)/itemSize) {
+		r.err = fmt.Errorf("%w: unexpected end of the snapshot", ErrInvalidSinkSnapshot)
+	}
+	if r.err != nil {
+		return 0
+	}
+	return int(n)
+}
+
+// close returns the first error, or an error
+// if there is unexpected data after the snapshot.
+func (r *snapshotReader) close() error {
+	if r.err == nil && len(r.buf) > 0 {
+		r.err = fmt.Errorf("%w: unexpected data after the snapshot", ErrInvalidSinkSnapshot)
+	}
+	return r.err
+}
+
+func mergeKindError(sink, other Sink) error {
+	return fmt.Errorf("unable to merge a %T into a %T, the sinks must be of the same kind", other, sink)
+}
+
+// MarshalBinary returns a snapshot of the CounterSink.
+func (c *CounterSink) MarshalBinary() ([]byte, error) {
+	w := newSnapshotWriter(snapshotKindCounter)
+	w.float64(c.Value)
+	w.time(c.First)
+	return w.buf, nil
+}
+
+// UnmarshalBinary restores the CounterSink from a snapshot.
+func (c *CounterSink) UnmarshalBinary(data []byte) error {
+	r := newSnapshotReader(data, snapshotKindCounter)
+	restored := CounterSink{
+		Value: r.float64(),
+		First: r.time(),
+	}
+	if err := r.close(); err != nil {
+		return err
+	}
+	*c = restored
+	return nil
+}
+
+// Merge adds the value of the other CounterSink,
+// the first sample time is the earliest of the two.
+func (c *CounterSink) Merge(other Sink) error {
+	o, ok := other.(*CounterSink)
+	if !ok {
+		return mergeKindError(c, other)
+	}
+	if o.IsEmpty() {
+		return nil
+	}
+
+	c.Value += o.Value
+	if c.First.IsZero() || o.First.Before(c.First) {
+		c.First = o.First
+	}
+	for _, bucket := range o.window {
+		if bucket.unix != 0 {
+			c.addToWindow(bucket.unix, bucket.value)
+		}
+	}
+	return nil
+}
+
+// MarshalBinary returns a snapshot of the GaugeSink.
+func (g *GaugeSink) MarshalBinary() ([]byte, error) {
+	w := newSnapshotWriter(snapshotKindGauge)
+	w.float64(g.Value)
+	w.float64(g.Max)
+	w.float64(g.Min)
+	w.bool(g.minSet)
+	w.time(g.last)
+	return w.buf, nil
+}
+
+// UnmarshalBinary restores the GaugeSink from a snapshot.
+func (g *GaugeSink) UnmarshalBinary(data []byte) error {
+	r := newSnapshotReader(data, snapshotKindGauge)
+	restored := GaugeSink{
+		Value:  r.float64(),
+		Max:    r.float64(),
+		Min:    r.float64(),
+		minSet: r.bool(),
+		last:   r.time(),
+	}
+	if err := r.close(); err != nil {
+		return err
+	}
+	*g = restored
+	return nil
+}
+
+// Merge merges the other GaugeSink, the value is the one of
+// the sink with the latest sample and the min and max are merged.
+func (g *GaugeSink) Merge(other Sink) error {
+	o, ok := other.(*GaugeSink)
+	if !ok {
+		return mergeKindError(g, other)
+	}
+	if o.IsEmpty() {
+		return nil
+	}
+	if g.IsEmpty() {
+		*g = *o
+		return nil
+	}
+
+	if !o.last.Before(g.last) {
+		g.Value = o.Value
+		g.last = o.last
+	}
+	if o.Max > g.Max {
+		g.Max = o.Max
+	}
+	if o.Min < g.Min {
+		g.Min = o.Min
+	}
+	return nil
+}
+
+// MarshalBinary returns a snapshot of the TrendSink, including its stored values.
+func (t *TrendSink) MarshalBinary() ([]byte, error) {
+	w := newSnapshotWriter(snapshotKindTrend)
+	w.uint64(t.count)
+	w.float64(t.min)
+	w.float64(t.max)
+	w.float64(t.sum)
+	w.uint64(uint64(t.maxValues))
+	w.float64(t.threshold)
+	w.uint64(uint64(len(t.values)))
+	for _, v := range t.values {
+		w.float64(v)
+	}
+	w.bool(t.weights != nil)
+	for _, n := range t.weights {
+		w.uint64(n)
+	}
+	w.bool(t.priorities != nil)
+	for _, p := range t.priorities {
+		w.float64(p)
+	}
+	return w.buf, nil
+}
+
+// UnmarshalBinary restores the TrendSink from a snapshot.
+func (t *TrendSink) UnmarshalBinary(data []byte) error {
+	r := newSnapshotReader(data, snapshotKindTrend)
+	restored := TrendSink{
+		count:     r.uint64(),
+		min:       r.float64(),
+		max:       r.float64(),
+		sum:       r.float64(),
+		maxValues: int(r.uint64()),
+		threshold: r.float64(),
+	}
+	restored.values = make([]float64, r.length(8))
+	for i := range restored.values {
+		restored.values[i] = r.float64()
+	}
+	if r.bool() {
+		restored.weights = make([]uint64, len(restored.values))
+		for i := range restored.weights {
+			restored.weights[i] = r.uint64()
+		}
+	}
+	if r.bool() {
+		restored.priorities = make([]float64, len(restored.values))
+		for i := range restored.priorities {
+			restored.priorities[i] = r.float64()
+		}
+	}
+	if err := r.close(); err != nil {
+		return err
+	}
+	if restored.maxValues > 0 && (restored.weights == nil || restored.priorities == nil) {
+		return fmt.Errorf("%w: the sampled values have no weights or priorities", ErrInvalidSinkSnapshot)
+	}
+	*t = restored
+	return nil
+}
+
+// Merge adds the values of the other TrendSink.
+//
+// If any of the sinks has a limit of stored values, the merged sink keeps the
+// limit, and the values of both sinks are sampled together with their
+// priorities, so the merged sample is weighted the same way as a single sink
+// of all the values, and the percentiles are estimated.
+func (t *TrendSink) Merge(other Sink) error {
+	o, ok := other.(*TrendSink)
+	if !ok {
+		return mergeKindError(t, other)
+	}
+	if o.IsEmpty() {
+		return nil
+	}
+
+	if t.maxValues <= 0 && o.maxValues <= 0 {
+		for i, v := range o.values {
+			t.store(v, o.occurrences(i))
+		}
+	} else {
+		if t.maxValues <= 0 {
+			t.maxValues = o.maxValues
+		}
+		t.prioritize()
+		for i, v := range o.values {
+			n := o.occurrences(i)
+			priority := t.priority(n)
+			if o.priorities != nil {
+				priority = o.priorities[i]
+			}
+			t.values = append(t.values, v)
+			t.weights = append(t.weights, n)
+			t.priorities = append(t.priorities, priority)
+		}
+		t.threshold = math.Max(t.threshold, o.threshold)
+		t.resample()
+	}
+
+	if t.count == 0 || o.min < t.min {
+		t.min = o.min
+	}
+	if t.count == 0 || o.max > t.max {
+		t.max = o.max
+	}
+	t.count += o.count
+	t.sum += o.sum
+	return nil
+}
+
+// occurrences returns the number of occurrences of the i-th stored value.
+func (t *TrendSink) occurrences(i int) uint64 {
+	if t.weights == nil {
+		return 1
+	}
+	return t.weights[i]
+}
+
+// prioritize gives a weight and a priority to the stored values
+// that don't have them yet, e.g. because the sink had no limit.
+func (t *TrendSink) prioritize() {
+	if t.weights == nil {
+		t.weights = make([]uint64, len(t.values))
+		for i := range t.weights {
+			t.weights[i] = 1
+		}
+	}
+	if t.priorities == nil {
+		t.priorities = make([]float64, len(t.values))
+		for i := range t.priorities {
+			t.priorities[i] = t.priority(t.weights[i])
+		}
+	}
+}
+
+// resample drops the stored values with a priority lower than the threshold,
+// then the ones with the lowest priorities beyond the limit of stored values.
+func (t *TrendSink) resample() {
+	kept := 0
+	for i := range t.values {
+		if t.priorities[i] > t.threshold {
+			t.values[kept], t.weights[kept], t.priorities[kept] = t.values[i], t.weights[i], t.priorities[i]
+			kept++
+		}
+	}
+	t.values, t.weights, t.priorities = t.values[:kept], t.weights[:kept], t.priorities[:kept]
+
+	if len(t.values) > t.maxValues {
+		sort.Sort(sort.Reverse(trendHeap{t}))
+		t.threshold = math.Max(t.threshold, t.priorities[t.maxValues])
+		t.values, t.weights, t.priorities = t.values[:t.maxValues], t.weights[:t.maxValues], t.priorities[:t.maxValues]
+	}
+	t.sorted, t.heapified = false, false
+}
+
+// MarshalBinary returns a snapshot of the RateSink.
+func (r *RateSink) MarshalBinary() ([]byte, error) {
+	w := newSnapshotWriter(snapshotKindRate)
+	w.uint64(uint64(r.Trues))
+	w.uint64(uint64(r.Total))
+	return w.buf, nil
+}
+
+// UnmarshalBinary restores the RateSink from a snapshot.
+func (r *RateSink) UnmarshalBinary(data []byte) error {
+	sr := newSnapshotReader(data, snapshotKindRate)
+	restored := RateSink{
+		Trues: int64(sr.uint64()),
+		Total: int64(sr.uint64()),
+	}
+	if err := sr.close(); err != nil {
+		return err
+	}
+	*r = restored
+	return nil
+}
+
+// Merge adds the counts of the other RateSink.
+func (r *RateSink) Merge(other Sink) error {
+	o, ok := other.(*RateSink)
+	if !ok {
+		return mergeKindError(r, other)
+	}
+
+	r.Trues += o.Trues
+	r.Total += o.Total
+	return nil
+}
+
+// MarshalBinary returns a snapshot of the HistogramSink.
+func (h *HistogramSink) MarshalBinary() ([]byte, error) {
+	w := newSnapshotWriter(snapshotKindHistogram)
+	w.uint64(h.count)
+	w.float64(h.min)
+	w.float64(h.max)
+	w.float64(h.sum)
+	w.uint64(uint64(len(h.buckets)))
+	for _, b := range h.buckets {
+		w.float64(b)
+	}
+	for _, c := range h.counts {
+		w.uint64(c)
+	}
+	return w.buf, nil
+}
+
+// UnmarshalBinary restores the HistogramSink from a snapshot.
+func (h *HistogramSink) UnmarshalBinary(data []byte) error {
+	r := newSnapshotReader(data, snapshotKindHistogram)
+	restored := HistogramSink{
+		count: r.uint64(),
+		min:   r.float64(),
+		max:   r.float64(),
+		sum:   r.float64(),
+	}
+	// each bucket has an upper bound and a count, plus the unbounded bucket's count
+	restored.buckets = make([]float64, r.length(16))
+	for i := range restored.buckets {
+		restored.buckets[i] = r.float64()
+	}
+	restored.counts = make([]uint64, len(restored.buckets)+1)
+	for i := range restored.counts {
+		restored.counts[i] = r.uint64()
+	}
+	if err := r.close(); err != nil {
+		return err
+	}
+	*h = restored
+	return nil
+}
+
+// Merge adds the counts of the other HistogramSink,
+// which must have the same buckets.
+func (h *HistogramSink) Merge(other Sink) error {
+	o, ok := other.(*HistogramSink)
+	if !ok {
+		return mergeKindError(h, other)
+	}
+	if !equalBuckets(h.buckets, o.buckets) {
+		return fmt.Errorf("unable to merge histograms with different buckets, %v and %v", h.buckets, o.buckets)
+	}
+	if o.IsEmpty() {
+		return nil
+	}
+
+	if h.count == 0 || o.min < h.min {
+		h.min = o.min
+	}
+	if h.count == 0 || o.max > h.max {
+		h.max = o.max
+	}
+	h.count += o.count
+	h.sum += o.sum
+	for i, c := range o.counts {
+		h.counts[i] += c
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addSamples(sink Sink, start time.Time, values ...float64) {
+	for i, v := range values {
+		sink.Add(Sample{Time: start.Add(time.Duration(i) * time.Second), Value: v})
+	}
+}
+
+func TestSinkSnapshotRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	tests := map[string]struct {
+		sink     SnapshotSink
+		restored SnapshotSink
+	}{
+		"Counter":   {sink: &CounterSink{}, restored: &CounterSink{}},
+		"Gauge":     {sink: &GaugeSink{}, restored: &GaugeSink{}},
+		"Trend":     {sink: NewTrendSinkWithMaxValues(100), restored: NewTrendSink()},
+		"Rate":      {sink: &RateSink{}, restored: &RateSink{}},
+		"Histogram": {sink: NewHistogramSink([]float64{1, 5, 10}), restored: NewHistogramSink(DefaultHistogramBuckets)},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			addSamples(tc.sink, now, 3, 0, 7, 12, 1)
+
+			snapshot, err := tc.sink.MarshalBinary()
+			require.NoError(t, err)
+			require.NoError(t, tc.restored.UnmarshalBinary(snapshot))
+
+			restoredSnapshot, err := tc.restored.MarshalBinary()
+			require.NoError(t, err)
+			assert.Equal(t, snapshot, restoredSnapshot)
+
+			assert.Equal(t, tc.sink.Format(time.Second), tc.restored.Format(time.Second))
+			assert.Equal(t, tc.sink.IsEmpty(), tc.restored.IsEmpty())
+		})
+	}
+}
+
+func TestSinkSnapshotInvalid(t *testing.T) {
+	t.Parallel()
+
+	counter := &CounterSink{}
+	addSamples(counter, time.Now(), 1)
+	snapshot, err := counter.MarshalBinary()
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		data []byte
+		sink SnapshotSink
+	}{
+		"Empty":           {data: nil, sink: &CounterSink{}},
+		"AnotherKind":     {data: snapshot, sink: &RateSink{}},
+		"Truncated":       {data: snapshot[:len(snapshot)-1], sink: &CounterSink{}},
+		"TrailingData":    {data: append(append([]byte{}, snapshot...), 0), sink: &CounterSink{}},
+		"UnknownVersion":  {data: append([]byte{99}, snapshot[1:]...), sink: &CounterSink{}},
+		"TrendValuesSize": {data: []byte{sinkSnapshotVersion, snapshotKindTrend}, sink: NewTrendSink()},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.ErrorIs(t, tc.sink.UnmarshalBinary(tc.data), ErrInvalidSinkSnapshot)
+		})
+	}
+}
+
+func TestSinkMerge(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+
+	t.Run("Counter", func(t *testing.T) {
+		t.Parallel()
+		a, b := &CounterSink{}, &CounterSink{}
+		addSamples(a, now.Add(time.Minute), 1, 2)
+		addSamples(b, now, 3)
+
+		require.NoError(t, a.Merge(b))
+		assert.Equal(t, 6.0, a.Value)
+		assert.Equal(t, now, a.First)
+		assert.Equal(t, 0.3, a.RecentRate(now.Add(time.Minute+2*time.Second)))
+	})
+
+	t.Run("Gauge", func(t *testing.T) {
+		t.Parallel()
+		a, b := &GaugeSink{}, &GaugeSink{}
+		addSamples(a, now, 5, 20)
+		addSamples(b, now.Add(time.Minute), 2, 8)
+
+		require.NoError(t, a.Merge(b))
+		assert.Equal(t, 8.0, a.Value)
+		assert.Equal(t, 2.0, a.Min)
+		assert.Equal(t, 20.0, a.Max)
+
+		// an older value doesn't replace the current one
+		c := &GaugeSink{}
+		addSamples(c, now.Add(-time.Minute), 1)
+		require.NoError(t, a.Merge(c))
+		assert.Equal(t, 8.0, a.Value)
+		assert.Equal(t, 1.0, a.Min)
+	})
+
+	t.Run("Trend", func(t *testing.T) {
+		t.Parallel()
+		a, b := NewTrendSink(), NewTrendSink()
+		addSamples(a, now, 1, 2, 3)
+		addSamples(b, now, 4, 5)
+
+		require.NoError(t, a.Merge(b))
+		assert.Equal(t, uint64(5), a.Count())
+		assert.Equal(t, 1.0, a.Min())
+		assert.Equal(t, 5.0, a.Max())
+		assert.Equal(t, 3.0, a.Avg())
+		assert.Equal(t, 3.0, a.P(0.5))
+		assert.True(t, a.IsExact())
+
+		limited := NewTrendSinkWithMaxValues(3)
+		require.NoError(t, limited.Merge(a))
+		assert.Equal(t, uint64(5), limited.Count())
+		assert.Len(t, limited.values, 3)
+		assert.False(t, limited.IsExact())
+	})
+
+	t.Run("SampledTrends", func(t *testing.T) {
+		t.Parallel()
+		// a few values of a sink are as likely to be kept as
+		// the ones of a sink with many more values
+		few, many := NewTrendSinkWithMaxValues(100), NewTrendSinkWithMaxValues(100)
+		for i := 0; i < 100; i++ {
+			few.Add(Sample{Value: 1})
+		}
+		for i := 0; i < 100000; i++ {
+			many.Add(Sample{Value: 2})
+		}
+
+		require.NoError(t, few.Merge(many))
+		assert.Equal(t, uint64(100100), few.Count())
+		assert.Equal(t, 1.0, few.Min())
+		assert.Len(t, few.values, 100)
+		assert.False(t, few.IsExact())
+		assert.Equal(t, 2.0, few.P(0.5))
+		assert.Equal(t, 2.0, few.P(0.9))
+
+		// the unlimited sink gets the limit of the sampled one
+		unlimited := NewTrendSink()
+		unlimited.Add(Sample{Value: 3, Weight: 1000})
+		require.NoError(t, unlimited.Merge(few))
+		assert.Equal(t, 100, unlimited.maxValues)
+		assert.Len(t, unlimited.values, 100)
+		assert.Equal(t, uint64(101100), unlimited.Count())
+	})
+
+	t.Run("Rate", func(t *testing.T) {
+		t.Parallel()
+		a, b := &RateSink{}, &RateSink{}
+		addSamples(a, now, 1, 0)
+		addSamples(b, now, 1, 1)
+
+		require.NoError(t, a.Merge(b))
+		assert.Equal(t, RateSink{Trues: 3, Total: 4}, *a)
+	})
+
+	t.Run("Histogram", func(t *testing.T) {
+		t.Parallel()
+		a, b := NewHistogramSink([]float64{10, 100}), NewHistogramSink([]float64{10, 100})
+		addSamples(a, now, 5, 50)
+		addSamples(b, now, 500)
+
+		require.NoError(t, a.Merge(b))
+		assert.Equal(t, []uint64{1, 1, 1}, a.BucketCounts())
+		assert.Equal(t, 500.0, a.Max())
+
+		require.Error(t, a.Merge(NewHistogramSink([]float64{10})))
+	})
+
+	t.Run("AnotherKind", func(t *testing.T) {
+		t.Parallel()
+		require.Error(t, (&CounterSink{}).Merge(&RateSink{}))
+	})
+}
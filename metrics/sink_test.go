@@ -20,6 +20,7 @@ func TestNewSink(t *testing.T) {
 		{mt: Gauge, sink: &GaugeSink{}},
 		{mt: Rate, sink: &RateSink{}},
 		{mt: Trend, sink: NewTrendSink()},
+		{mt: Histogram, sink: NewHistogramSink(DefaultHistogramBuckets)},
 	}
 	for _, tc := range tests {
 		assert.Equal(t, tc.sink, NewSink(tc.mt))
@@ -54,9 +55,27 @@ func TestCounterSink(t *testing.T) {
 	t.Run("format", func(t *testing.T) {
 		sink := CounterSink{}
 		for _, s := range samples10 {
-			sink.Add(Sample{TimeSeries: TimeSeries{Metric: &Metric{}}, Value: s, Time: now})
+			sink.Add(Sample{TimeSeries: TimeSeries{Metric: &Metric{}}, Value: s, Time: now.Add(-time.Hour)})
 		}
-		assert.Equal(t, map[string]float64{"count": 145, "rate": 145.0}, sink.Format(1*time.Second))
+		assert.Equal(t, map[string]float64{"count": 145, "rate": 145.0, "recent_rate": 0}, sink.Format(1*time.Second))
+	})
+	t.Run("recent rate", func(t *testing.T) {
+		start := time.Unix(1700000000, 0)
+		sink := CounterSink{}
+		for i := 0; i < 30; i++ {
+			sink.Add(Sample{TimeSeries: TimeSeries{Metric: &Metric{}}, Value: float64(i), Time: start.Add(time.Duration(i) * time.Second)})
+		}
+		// an old sample doesn't replace the newer ones
+		sink.Add(Sample{TimeSeries: TimeSeries{Metric: &Metric{}}, Value: 1000, Time: start})
+
+		// the seconds from 19 to 28, since the current one is excluded
+		assert.Equal(t, 23.5, sink.RecentRate(start.Add(29*time.Second+500*time.Millisecond)))
+		// the seconds from 20 to 29
+		assert.Equal(t, 24.5, sink.RecentRate(start.Add(30*time.Second)))
+		// half of the window is empty
+		assert.Equal(t, 13.5, sink.RecentRate(start.Add(35*time.Second)))
+		assert.Equal(t, 0.0, sink.RecentRate(start.Add(time.Minute)))
+		assert.Equal(t, 1435.0, sink.Value)
 	})
 }
 
@@ -90,6 +109,23 @@ func TestGaugeSink(t *testing.T) {
 		}
 		assert.Equal(t, map[string]float64{"value": 5.0}, sink.Format(0))
 	})
+	t.Run("stale", func(t *testing.T) {
+		now := time.Unix(1700000000, 0)
+		sink := GaugeSink{}
+		assert.False(t, sink.IsStale(now, time.Minute))
+
+		sink.Add(Sample{TimeSeries: TimeSeries{Metric: &Metric{}}, Value: 1, Time: now.Add(-2 * time.Minute)})
+		sink.Add(Sample{TimeSeries: TimeSeries{Metric: &Metric{}}, Value: 2, Time: now.Add(-90 * time.Second)})
+		assert.Equal(t, now.Add(-90*time.Second), sink.LastUpdate())
+		assert.True(t, sink.IsStale(now, time.Minute))
+		assert.False(t, sink.IsStale(now, 2*time.Minute))
+		assert.False(t, sink.IsStale(now, 0))
+
+		// the samples without a time never expire
+		sink = GaugeSink{}
+		sink.Add(Sample{TimeSeries: TimeSeries{Metric: &Metric{}}, Value: 1})
+		assert.False(t, sink.IsStale(now, time.Minute))
+	})
 }
 
 func TestTrendSink(t *testing.T) {
@@ -202,6 +238,39 @@ func TestTrendSink(t *testing.T) {
 			assert.Equal(t, true, sink.sorted)
 		})
 	})
+	t.Run("max values", func(t *testing.T) {
+		t.Run("within the limit", func(t *testing.T) {
+			t.Parallel()
+
+			sink := NewTrendSinkWithMaxValues(len(unsortedSamples10))
+			for _, s := range unsortedSamples10 {
+				sink.Add(Sample{TimeSeries: TimeSeries{Metric: &Metric{}}, Value: s})
+			}
+			assert.True(t, sink.IsExact())
+			assert.Equal(t, unsortedSamples10, sink.values)
+			assert.InDelta(t, 95.5, sink.P(0.95), tolerance)
+		})
+		t.Run("beyond the limit", func(t *testing.T) {
+			t.Parallel()
+
+			sink := NewTrendSinkWithMaxValues(1000)
+			for i := 1; i <= 100000; i++ {
+				sink.Add(Sample{TimeSeries: TimeSeries{Metric: &Metric{}}, Value: float64(i)})
+			}
+			assert.False(t, sink.IsExact())
+			assert.Len(t, sink.values, 1000)
+
+			// min, max, avg and count are still exact
+			assert.Equal(t, uint64(100000), sink.Count())
+			assert.Equal(t, 1.0, sink.Min())
+			assert.Equal(t, 100000.0, sink.Max())
+			assert.Equal(t, 50000.5, sink.Avg())
+
+			// the percentiles are estimated from a uniform random sample
+			assert.InDelta(t, 50000, sink.P(0.5), 10000)
+			assert.InDelta(t, 90000, sink.P(0.9), 10000)
+		})
+	})
 	t.Run("format", func(t *testing.T) {
 		t.Parallel()
 
@@ -226,6 +295,85 @@ func TestTrendSink(t *testing.T) {
 	})
 }
 
+func TestTrendSinkFormatTrendStats(t *testing.T) {
+	t.Parallel()
+
+	sink := NewTrendSink()
+	for _, v := range []float64{10, 20, 30, 40} {
+		sink.Add(Sample{TimeSeries: TimeSeries{Metric: &Metric{}}, Value: v})
+	}
+
+	result, err := sink.FormatTrendStats([]string{"count", "avg", "p(99.9)"})
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+	assert.Equal(t, 4.0, result["count"])
+	assert.Equal(t, 25.0, result["avg"])
+	assert.InDelta(t, 39.97, result["p(99.9)"], 0.000001)
+
+	_, err = sink.FormatTrendStats([]string{"p(101)"})
+	assert.Error(t, err)
+}
+
+func TestHistogramSink(t *testing.T) {
+	t.Parallel()
+
+	sink := NewHistogramSink([]float64{10, 100, 1000})
+	assert.True(t, sink.IsEmpty())
+	assert.Equal(t, 0.0, sink.P(0.5))
+
+	for _, v := range []float64{5, 50, 60, 500, 2000} {
+		sink.Add(Sample{TimeSeries: TimeSeries{Metric: &Metric{}}, Value: v})
+	}
+
+	assert.False(t, sink.IsEmpty())
+	assert.Equal(t, []uint64{1, 2, 1, 1}, sink.BucketCounts())
+	assert.Equal(t, uint64(5), sink.Count())
+	assert.Equal(t, 5.0, sink.Min())
+	assert.Equal(t, 2000.0, sink.Max())
+	assert.Equal(t, 2615.0, sink.Total())
+	assert.Equal(t, 523.0, sink.Avg())
+
+	assert.Equal(t, 5.0, sink.P(0))
+	assert.Equal(t, 77.5, sink.P(0.5))
+	assert.Equal(t, 2000.0, sink.P(1))
+
+	assert.Equal(t, map[string]float64{
+		"count": 5,
+		"min":   5,
+		"max":   2000,
+		"avg":   523,
+		"med":   77.5,
+	}, sink.Format(0))
+}
+
+func TestValidateHistogramBuckets(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		buckets []float64
+		expErr  string
+	}{
+		"Valid":      {buckets: []float64{-1, 0, 1.5, 10}},
+		"Empty":      {buckets: nil, expErr: "at least one bucket"},
+		"NotSorted":  {buckets: []float64{1, 10, 5}, expErr: "increasing order"},
+		"Duplicated": {buckets: []float64{1, 1}, expErr: "increasing order"},
+		"Infinite":   {buckets: []float64{1, math.Inf(1)}, expErr: "not a finite number"},
+		"NaN":        {buckets: []float64{math.NaN()}, expErr: "not a finite number"},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateHistogramBuckets(tc.buckets)
+			if tc.expErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expErr)
+		})
+	}
+}
+
 func TestRateSink(t *testing.T) {
 	samples6 := []float64{1.0, 0.0, 1.0, 0.0, 0.0, 1.0}
 
@@ -259,3 +407,67 @@ func TestRateSink(t *testing.T) {
 		assert.Equal(t, map[string]float64{"rate": 0.5}, sink.Format(0))
 	})
 }
+
+func TestSinkWeightedSamples(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	tests := map[string]func() Sink{
+		"Counter":   func() Sink { return &CounterSink{} },
+		"Rate":      func() Sink { return &RateSink{} },
+		"Trend":     func() Sink { return NewTrendSink() },
+		"Limited":   func() Sink { return NewTrendSinkWithMaxValues(10) },
+		"Histogram": func() Sink { return NewHistogramSink(DefaultHistogramBuckets) },
+	}
+	for name, newSink := range tests {
+		newSink := newSink
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			weighted, repeated := newSink(), newSink()
+			weighted.Add(Sample{Time: now, Value: 0, Weight: 2})
+			weighted.Add(Sample{Time: now, Value: 7, Weight: 3})
+			weighted.Add(Sample{Time: now, Value: 1})
+			for _, v := range []float64{0, 0, 7, 7, 7, 1} {
+				repeated.Add(Sample{Time: now, Value: v})
+			}
+
+			assert.Equal(t, repeated.Format(time.Second), weighted.Format(time.Second))
+		})
+	}
+}
+
+func TestTrendSinkHeavyWeights(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unlimited", func(t *testing.T) {
+		t.Parallel()
+
+		sink := NewTrendSink()
+		sink.Add(Sample{Value: 1, Weight: 1 << 40})
+		sink.Add(Sample{Value: 3, Weight: 1 << 40})
+		sink.Add(Sample{Value: 2})
+		assert.Len(t, sink.values, 3)
+		assert.Equal(t, uint64(1<<41+1), sink.Count())
+		assert.True(t, sink.IsExact())
+		assert.Equal(t, 1.0, sink.P(0.25))
+		assert.Equal(t, 2.0, sink.P(0.5))
+		assert.Equal(t, 3.0, sink.P(0.75))
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		t.Parallel()
+
+		sink := NewTrendSinkWithMaxValues(100)
+		sink.Add(Sample{Value: 1, Weight: 1 << 40})
+		for i := 0; i < 10000; i++ {
+			sink.Add(Sample{Value: 2})
+		}
+		assert.Len(t, sink.values, 100)
+		assert.False(t, sink.IsExact())
+		assert.Equal(t, uint64(1<<40+10000), sink.Count())
+		// the heavy value is always kept, and it's most of the occurrences
+		assert.Equal(t, 1.0, sink.P(0.99))
+		assert.Equal(t, 2.0, sink.P(1))
+	})
+}
@@ -38,15 +38,23 @@ const (
 	TagVU   // non-indexable
 	TagOCSPStatus
 	TagIP
+
+	// TagAborted is set on the samples of the operations aborted by an AbortSignal.
+	TagAborted
+
+	// TagHost is set on the samples of the HTTP connection gauges, which
+	// are otherwise the totals for all the hosts. It's not enabled by default.
+	TagHost
 )
 
 // DefaultSystemTagSet includes all of the system tags emitted with metrics by default.
-// Other tags that are not enabled by default include: iter, vu, ocsp_status, ip
+// Other tags that are not enabled by default include: iter, vu, ocsp_status, ip, host
 //
 //nolint:gochecknoglobals
 var DefaultSystemTagSet = SystemTagSet(
 	TagProto | TagSubproto | TagStatus | TagMethod | TagURL | TagName | TagGroup |
-		TagCheck | TagError | TagErrorCode | TagTLSVersion | TagScenario | TagService | TagExpectedResponse)
+		TagCheck | TagError | TagErrorCode | TagTLSVersion | TagScenario | TagService | TagExpectedResponse |
+		TagAborted)
 
 // NonIndexableSystemTags are high cardinality system tags (i.e. metadata).
 //
@@ -156,6 +156,20 @@ var _ interface {
 	json.Unmarshaler
 } = &TagSet{}
 
+// WarmupTagName is the name of the tag that marks the metric samples emitted
+// during the warm-up period of a scenario. They aren't used for the thresholds
+// and the end-of-test summary.
+const WarmupTagName = "warmup"
+
+// IsWarmup reports whether the tag set marks a sample from a warm-up period.
+func IsWarmup(tags *TagSet) bool {
+	if tags == nil {
+		return false
+	}
+	value, ok := tags.Get(WarmupTagName)
+	return ok && value == "true"
+}
+
 // TagsAndMeta is a helper type that provides easy group manipulation of the
 // indexed Tags and the non-indexed Metadata values together. While both of them
 // are part of a metric Sample, the TagsAndMeta type isn't used there because
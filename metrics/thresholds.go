@@ -26,6 +26,16 @@ type Threshold struct {
 	AbortGracePeriod types.NullDuration
 	// parsed is the threshold expression parsed from the Source
 	parsed *thresholdExpression
+
+	// window aggregates the recent samples if the threshold
+	// is evaluated over a sliding window, and windowSinked
+	// holds the values computed from them by the last run.
+	window       *sampleWindow
+	windowSinked map[string]float64
+
+	// lastValue is the aggregated metric value the
+	// threshold was asserted against by the last run.
+	lastValue float64
 }
 
 func newThreshold(src string, abortOnFail bool, gracePeriod types.NullDuration) *Threshold {
@@ -46,6 +56,7 @@ func (t *Threshold) runNoTaint(sinks map[string]float64) (bool, error) {
 	if !ok {
 		return true, nil
 	}
+	t.lastValue = lhs
 
 	// Apply the threshold expression operator to the left and
 	// right hand side values
@@ -79,8 +90,23 @@ func (t *Threshold) runNoTaint(sinks map[string]float64) (bool, error) {
 	return passes, nil
 }
 
+// LastValue returns the aggregated metric value, e.g. the p(95) of a trend
+// metric, that the threshold was asserted against by its last run.
+func (t *Threshold) LastValue() float64 {
+	return t.lastValue
+}
+
 func (t *Threshold) run(sinks map[string]float64) (bool, error) {
+	windowed := t.parsed != nil && t.parsed.Window > 0
+	if windowed {
+		sinks = t.windowSinked
+	}
 	passes, err := t.runNoTaint(sinks)
+	if windowed && t.LastFailed {
+		// A breach in any window fails the threshold for the rest
+		// of the test, the following windows passing don't undo it.
+		passes = false
+	}
 	t.LastFailed = !passes
 	return passes, err
 }
@@ -167,9 +193,68 @@ func (ts *Thresholds) runAll(timeSpentInTest time.Duration) (bool, error) {
 
 // Run processes all the thresholds with the provided Sink at the provided time and returns if any
 // of them fails
+//
+// The thresholds evaluated over a sliding window use the samples added to them
+// by AddSample during the window instead of the provided Sink. Once one of their
+// windows has failed, they stay failed.
 func (ts *Thresholds) Run(sink Sink, duration time.Duration) (bool, error) {
-	// Initialize the sinks store
-	ts.sinked = make(map[string]float64)
+	sinked, err := sinkValues(sink, duration, ts.Thresholds)
+	if err != nil {
+		return false, err
+	}
+	ts.sinked = sinked
+
+	now := time.Now()
+	for _, threshold := range ts.Thresholds {
+		if threshold.parsed == nil || threshold.parsed.Window <= 0 {
+			continue
+		}
+
+		threshold.windowSinked = nil
+		if threshold.window == nil {
+			continue
+		}
+		windowSink, err := threshold.window.sink(now)
+		if err != nil {
+			return false, err
+		}
+		if windowSink == nil {
+			continue
+		}
+
+		windowDuration := threshold.parsed.Window
+		if duration < windowDuration {
+			windowDuration = duration
+		}
+		threshold.windowSinked, err = sinkValues(windowSink, windowDuration, []*Threshold{threshold})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return ts.runAll(duration)
+}
+
+// AddSample adds the sample to the thresholds evaluated over
+// a sliding window, it's a no-op for the other thresholds.
+// The windows aggregate the samples in sinks of the same kind
+// as the provided one, expected to be the metric's sink.
+func (ts *Thresholds) AddSample(sink Sink, s Sample) {
+	for _, threshold := range ts.Thresholds {
+		if threshold.parsed == nil || threshold.parsed.Window <= 0 {
+			continue
+		}
+		if threshold.window == nil {
+			threshold.window = newSampleWindow(threshold.parsed.Window, sink)
+		}
+		threshold.window.add(s)
+	}
+}
+
+// sinkValues returns the values of the sink the thresholds can be
+// asserted against, the percentiles are computed only if used by them.
+func sinkValues(sink Sink, duration time.Duration, thresholds []*Threshold) (map[string]float64, error) {
+	sinked := make(map[string]float64)
 
 	// FIXME: Remove this comment as soon as the metrics.Sink does not expose Format anymore.
 	//
@@ -180,37 +265,52 @@ func (ts *Thresholds) Run(sink Sink, duration time.Duration) (bool, error) {
 	// For more details, see https://github.com/grafana/k6/issues/2320
 	switch sinkImpl := sink.(type) {
 	case *CounterSink:
-		ts.sinked["count"] = sinkImpl.Value
-		ts.sinked["rate"] = sinkImpl.Value / (float64(duration) / float64(time.Second))
+		sinked["count"] = sinkImpl.Value
+		sinked["rate"] = sinkImpl.Value / (float64(duration) / float64(time.Second))
 	case *GaugeSink:
-		ts.sinked["value"] = sinkImpl.Value
+		sinked["value"] = sinkImpl.Value
 	case *TrendSink:
-		ts.sinked["min"] = sinkImpl.Min()
-		ts.sinked["max"] = sinkImpl.Max()
-		ts.sinked["avg"] = sinkImpl.Avg()
-		ts.sinked["med"] = sinkImpl.P(0.5)
+		sinked["min"] = sinkImpl.Min()
+		sinked["max"] = sinkImpl.Max()
+		sinked["avg"] = sinkImpl.Avg()
+		sinked["med"] = sinkImpl.P(0.5)
 
 		// Parse the percentile thresholds and insert them in
 		// the sinks mapping.
-		for _, threshold := range ts.Thresholds {
+		for _, threshold := range thresholds {
 			if threshold.parsed.AggregationMethod != tokenPercentile {
 				continue
 			}
 
 			key := fmt.Sprintf("p(%g)", threshold.parsed.AggregationValue.Float64)
-			ts.sinked[key] = sinkImpl.P(threshold.parsed.AggregationValue.Float64 / 100)
+			sinked[key] = sinkImpl.P(threshold.parsed.AggregationValue.Float64 / 100)
+		}
+	case *HistogramSink:
+		sinked["count"] = float64(sinkImpl.Count())
+		sinked["min"] = sinkImpl.Min()
+		sinked["max"] = sinkImpl.Max()
+		sinked["avg"] = sinkImpl.Avg()
+		sinked["med"] = sinkImpl.P(0.5)
+
+		for _, threshold := range thresholds {
+			if threshold.parsed.AggregationMethod != tokenPercentile {
+				continue
+			}
+
+			key := fmt.Sprintf("p(%g)", threshold.parsed.AggregationValue.Float64)
+			sinked[key] = sinkImpl.P(threshold.parsed.AggregationValue.Float64 / 100)
 		}
 	case *RateSink:
 		// We want to avoid division by zero, which
 		// would lead to [#2520](https://github.com/grafana/k6/issues/2520)
 		if sinkImpl.Total > 0 {
-			ts.sinked["rate"] = float64(sinkImpl.Trues) / float64(sinkImpl.Total)
+			sinked["rate"] = float64(sinkImpl.Trues) / float64(sinkImpl.Total)
 		}
 	default:
-		return false, fmt.Errorf("unable to run Thresholds; reason: unknown sink type")
+		return nil, fmt.Errorf("unable to run Thresholds; reason: unknown sink type")
 	}
 
-	return ts.runAll(duration)
+	return sinked, nil
 }
 
 // Parse parses the Thresholds and fills each Threshold.parsed field with the result.
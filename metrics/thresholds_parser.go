@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/guregu/null.v3"
 )
@@ -27,6 +28,12 @@ type thresholdExpression struct {
 
 	// Value holds the value parsed from the threshold expression.
 	Value float64
+
+	// Window holds the size of the sliding time window the expression
+	// is evaluated over, for instance: an expression of the form
+	// p(95) < 300 over last 60s, would result in Window to be set to 60s.
+	// It's zero for the expressions evaluated over the whole test run.
+	Window time.Duration
 }
 
 // SinkKey computes the key used to index a thresholdExpression in the engine's sinks.
@@ -68,10 +75,19 @@ func (te *thresholdExpression) SinkKey() string {
 // digit               -> "0" | "1" | "2" | "3" | "4" | "5" | "6" | "7" | "8" | "9"
 // whitespace          -> " "
 // ```
+//
+// The assertion can be followed by a sliding window definition of the form
+// `over last duration` (for instance p(95)<300 over last 60s), in which case
+// it's evaluated over the samples of the window instead of the whole test run.
 func parseThresholdExpression(input string) (*thresholdExpression, error) {
+	assertion, window, err := scanThresholdWindow(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing threshold expression %q; reason: %w", input, err)
+	}
+
 	// Scanning makes no assumption on the underlying values, and only
 	// checks that the expression has the right format.
-	method, operator, value, err := scanThresholdExpression(input)
+	method, operator, value, err := scanThresholdExpression(assertion)
 	if err != nil {
 		return nil, fmt.Errorf("failed parsing threshold expression %q; reason: %w", input, err)
 	}
@@ -97,11 +113,34 @@ func parseThresholdExpression(input string) (*thresholdExpression, error) {
 		AggregationValue:  parsedMethodValue,
 		Operator:          operator,
 		Value:             parsedValue,
+		Window:            window,
 	}
 
 	return condition, nil
 }
 
+// tokenWindow introduces the sliding window definition of a threshold expression.
+const tokenWindow = " over last "
+
+// scanThresholdWindow splits a threshold expression into its assertion
+// and the size of its sliding window, the latter is zero if not defined.
+func scanThresholdWindow(input string) (string, time.Duration, error) {
+	i := strings.Index(input, tokenWindow)
+	if i < 0 {
+		return input, 0, nil
+	}
+
+	window, err := time.ParseDuration(strings.TrimSpace(input[i+len(tokenWindow):]))
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid sliding window duration: %w", err)
+	}
+	if window <= 0 {
+		return "", 0, fmt.Errorf("the sliding window duration must be positive, got %s", window)
+	}
+
+	return input[:i], window, nil
+}
+
 // Define accepted threshold expression operators tokens
 const (
 	tokenLessEqual     = "<="
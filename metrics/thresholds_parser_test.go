@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/guregu/null.v3"
@@ -40,6 +41,30 @@ func TestParseThresholdExpression(t *testing.T) {
 			wantExpression: &thresholdExpression{AggregationMethod: "count", Operator: ">", Value: 20},
 			wantErr:        false,
 		},
+		{
+			name:  "valid threshold expression over a sliding window",
+			input: "p(95)<300 over last 60s",
+			wantExpression: &thresholdExpression{
+				AggregationMethod: "p",
+				AggregationValue:  null.FloatFrom(95),
+				Operator:          "<",
+				Value:             300,
+				Window:            60 * time.Second,
+			},
+			wantErr: false,
+		},
+		{
+			name:           "invalid sliding window duration fails",
+			input:          "p(95)<300 over last minute",
+			wantExpression: nil,
+			wantErr:        true,
+		},
+		{
+			name:           "non positive sliding window duration fails",
+			input:          "p(95)<300 over last 0s",
+			wantExpression: nil,
+			wantErr:        true,
+		},
 	}
 	for _, testCase := range tests {
 		testCase := testCase
@@ -42,7 +42,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 	}{
 		{
 			name:             "valid expression using the > operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 1},
 			wantOk:           true,
@@ -50,7 +50,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using the > operator over passing threshold and defined abort grace period",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(2 * time.Second),
 			sinks:            map[string]float64{"rate": 1},
 			wantOk:           true,
@@ -58,7 +58,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using the >= operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreaterEqual, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreaterEqual, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.01},
 			wantOk:           true,
@@ -66,7 +66,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using the <= operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenLessEqual, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenLessEqual, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.01},
 			wantOk:           true,
@@ -74,7 +74,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using the < operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenLess, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenLess, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.00001},
 			wantOk:           true,
@@ -82,7 +82,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using the == operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenLooselyEqual, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenLooselyEqual, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.01},
 			wantOk:           true,
@@ -90,7 +90,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using the === operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenStrictlyEqual, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenStrictlyEqual, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.01},
 			wantOk:           true,
@@ -98,7 +98,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using != operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenBangEqual, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenBangEqual, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.02},
 			wantOk:           true,
@@ -106,7 +106,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression over failing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.00001},
 			wantOk:           false,
@@ -114,7 +114,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression over non-existing sink",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"med": 27.2},
 			wantOk:           true,
@@ -124,7 +124,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 			// The ParseThresholdCondition constructor should ensure that no invalid
 			// operator gets through, but let's protect our future selves anyhow.
 			name:             "invalid expression operator",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, "&", 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, "&", 0.01, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.00001},
 			wantOk:           false,
@@ -168,7 +168,7 @@ func BenchmarkRunNoTaint(b *testing.B) {
 		LastFailed:       false,
 		AbortOnFail:      false,
 		AbortGracePeriod: types.NullDurationFrom(2 * time.Second),
-		parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01},
+		parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01, 0},
 	}
 
 	sinks := map[string]float64{"rate": 1}
@@ -647,6 +647,14 @@ func getTrendSink(values ...float64) *TrendSink {
 	return sink
 }
 
+func getHistogramSink(buckets []float64, values ...float64) *HistogramSink {
+	sink := NewHistogramSink(buckets)
+	for _, v := range values {
+		sink.Add(Sample{Value: v})
+	}
+	return sink
+}
+
 func TestThresholdsRun(t *testing.T) {
 	t.Parallel()
 
@@ -731,6 +739,26 @@ func TestThresholdsRun(t *testing.T) {
 			want:    false,
 			wantErr: false,
 		},
+		{
+			name: "Running thresholds on histogram sink with values succeeds",
+			args: args{
+				sink:                 getHistogramSink([]float64{10, 100, 1000}, 5, 50, 60, 500, 2000),
+				thresholdExpressions: []string{"count==5", "p(50)<100", "max<=2000"},
+				duration:             0,
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "Running thresholds on histogram sink with values and failing percentile fails",
+			args: args{
+				sink:                 getHistogramSink([]float64{10, 100, 1000}, 5, 50, 60, 500, 2000),
+				thresholdExpressions: []string{"p(90)<1000"},
+				duration:             0,
+			},
+			want:    false,
+			wantErr: false,
+		},
 	}
 	for _, testCase := range tests {
 		testCase := testCase
@@ -749,6 +777,210 @@ func TestThresholdsRun(t *testing.T) {
 	}
 }
 
+func TestThresholdsRunOverSlidingWindow(t *testing.T) {
+	t.Parallel()
+
+	thresholds := NewThresholds([]string{"p(95)<300 over last 1m", "p(95)<300", "count<10 over last 1m"})
+	require.NoError(t, thresholds.Parse())
+
+	now := time.Now()
+	sink := NewTrendSink()
+	add := func(ts time.Time, values ...float64) {
+		for _, v := range values {
+			s := Sample{Time: ts, Value: v}
+			sink.Add(s)
+			thresholds.AddSample(sink, s)
+		}
+	}
+
+	// a spike out of the window only fails the cumulative threshold
+	add(now.Add(-5*time.Minute), 1000, 1000, 1000)
+	add(now.Add(-10*time.Second), 100, 100)
+
+	ok, err := thresholds.Run(sink, 10*time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.False(t, thresholds.Thresholds[0].LastFailed)
+	assert.True(t, thresholds.Thresholds[1].LastFailed)
+	assert.False(t, thresholds.Thresholds[2].LastFailed)
+	assert.Equal(t, float64(100), thresholds.Thresholds[0].LastValue())
+
+	// a recent spike fails the threshold over the window
+	add(now, 1000)
+
+	ok, err = thresholds.Run(sink, 10*time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.True(t, thresholds.Thresholds[0].LastFailed)
+	assert.Greater(t, thresholds.Thresholds[0].LastValue(), float64(300))
+}
+
+func TestThresholdsRunOverSlidingWindowLatchesFailures(t *testing.T) {
+	t.Parallel()
+
+	thresholds := NewThresholds([]string{"rate>0.9 over last 30s"})
+	require.NoError(t, thresholds.Parse())
+
+	sink := &RateSink{}
+	add := func(ts time.Time, v float64) {
+		s := Sample{Time: ts, Value: v}
+		sink.Add(s)
+		thresholds.AddSample(sink, s)
+	}
+
+	now := time.Now()
+	add(now.Add(-time.Minute), 0)
+	add(now.Add(-time.Second), 1)
+
+	// the failing sample is out of the window
+	ok, err := thresholds.Run(sink, 2*time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	add(now, 0)
+	ok, err = thresholds.Run(sink, 2*time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// the next windows passing don't undo the failure
+	for i := 0; i < 10; i++ {
+		add(now, 1)
+	}
+	assert.Equal(t, float64(11)/12, mustWindowRate(t, thresholds.Thresholds[0], now))
+	ok, err = thresholds.Run(sink, 2*time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.True(t, thresholds.Thresholds[0].LastFailed)
+}
+
+func mustWindowRate(t *testing.T, threshold *Threshold, now time.Time) float64 {
+	t.Helper()
+	sink, err := threshold.window.sink(now)
+	require.NoError(t, err)
+	rate, ok := sink.(*RateSink)
+	require.True(t, ok)
+	return float64(rate.Trues) / float64(rate.Total)
+}
+
+func TestThresholdsRunOverEmptySlidingWindow(t *testing.T) {
+	t.Parallel()
+
+	thresholds := NewThresholds([]string{"rate>0.9 over last 30s"})
+	require.NoError(t, thresholds.Parse())
+
+	sink := &RateSink{}
+	s := Sample{Time: time.Now().Add(-time.Minute), Value: 0}
+	sink.Add(s)
+	thresholds.AddSample(sink, s)
+
+	// the only sample is out of the window, so there is nothing to assert
+	ok, err := thresholds.Run(sink, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	for _, slot := range thresholds.Thresholds[0].window.slots {
+		assert.Nil(t, slot.sink)
+	}
+}
+
+func TestSampleWindowAggregates(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	testCases := []struct {
+		name   string
+		like   Sink
+		values []float64
+		check  func(t *testing.T, sink Sink)
+	}{
+		{
+			name:   "Counter",
+			like:   &CounterSink{},
+			values: []float64{1, 2, 3},
+			check: func(t *testing.T, sink Sink) {
+				assert.Equal(t, float64(6), sink.(*CounterSink).Value) //nolint:forcetypeassert
+			},
+		},
+		{
+			name:   "Gauge",
+			like:   &GaugeSink{},
+			values: []float64{5, 1, 3},
+			check: func(t *testing.T, sink Sink) {
+				g := sink.(*GaugeSink) //nolint:forcetypeassert
+				assert.Equal(t, float64(3), g.Value)
+				assert.Equal(t, float64(1), g.Min)
+				assert.Equal(t, float64(5), g.Max)
+			},
+		},
+		{
+			name:   "Trend",
+			like:   NewTrendSink(),
+			values: []float64{1, 2, 3, 4},
+			check: func(t *testing.T, sink Sink) {
+				trend := sink.(*TrendSink) //nolint:forcetypeassert
+				assert.Equal(t, uint64(4), trend.Count())
+				assert.Equal(t, float64(1), trend.Min())
+				assert.Equal(t, float64(4), trend.Max())
+				assert.Equal(t, 2.5, trend.Avg())
+				assert.Equal(t, 2.5, trend.P(0.5))
+			},
+		},
+		{
+			name:   "Histogram",
+			like:   NewHistogramSink([]float64{2, 4}),
+			values: []float64{1, 2, 3, 5},
+			check: func(t *testing.T, sink Sink) {
+				h := sink.(*HistogramSink) //nolint:forcetypeassert
+				assert.Equal(t, []uint64{2, 1, 1}, h.BucketCounts())
+				assert.Equal(t, float64(11), h.Total())
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := newSampleWindow(time.Minute, tc.like)
+			// each value in a different slot, the first one expired
+			w.add(Sample{Time: now.Add(-2 * time.Minute), Value: 100})
+			for i, v := range tc.values {
+				w.add(Sample{Time: now.Add(-time.Duration(len(tc.values)-i) * time.Second), Value: v})
+			}
+
+			sink, err := w.sink(now)
+			require.NoError(t, err)
+			require.NotNil(t, sink)
+			tc.check(t, sink)
+		})
+	}
+}
+
+func TestSampleWindowTrendIsBounded(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	w := newSampleWindow(time.Minute, NewTrendSink())
+	for i := 0; i < 10*windowTrendMaxValues; i++ {
+		w.add(Sample{Time: now, Value: float64(i)})
+	}
+
+	var stored int
+	for _, slot := range w.slots {
+		if slot.sink != nil {
+			stored += len(slot.sink.(*TrendSink).values) //nolint:forcetypeassert
+		}
+	}
+	assert.Equal(t, windowTrendMaxValues, stored)
+
+	sink, err := w.sink(now)
+	require.NoError(t, err)
+	trend := sink.(*TrendSink) //nolint:forcetypeassert
+	assert.Equal(t, uint64(10*windowTrendMaxValues), trend.Count())
+	assert.Equal(t, float64(10*windowTrendMaxValues-1), trend.Max())
+	assert.InDelta(t, 5*windowTrendMaxValues, trend.P(0.5), windowTrendMaxValues)
+}
+
 func TestThresholdsJSON(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,229 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// windowSlots is the number of slots a sliding window is split into.
+	// The samples are aggregated per slot, so the memory used by a window
+	// is bounded and the window moves forward by a slot at a time.
+	windowSlots = 60
+
+	// windowTrendMaxValues is the max number of values stored by each slot
+	// of a window over a Trend metric, beyond it the percentiles are estimated.
+	windowTrendMaxValues = 1000
+)
+
+// windowSlot aggregates the samples observed during a slot of a window.
+type windowSlot struct {
+	// index is the slot's start time divided by the slot duration.
+	index int64
+	sink  Sink
+}
+
+// sampleWindow aggregates the samples of the last size duration,
+// for the thresholds evaluated over a sliding time window.
+type sampleWindow struct {
+	size     time.Duration
+	slotSize time.Duration
+
+	// like is the metric's sink, the slots are sinks of the same kind.
+	like  Sink
+	slots [windowSlots]windowSlot
+}
+
+func newSampleWindow(size time.Duration, like Sink) *sampleWindow {
+	slotSize := size / windowSlots
+	if slotSize <= 0 {
+		slotSize = 1
+	}
+	return &sampleWindow{size: size, slotSize: slotSize, like: like}
+}
+
+func (w *sampleWindow) slotIndex(t time.Time) int64 {
+	return t.UnixNano() / int64(w.slotSize)
+}
+
+// add aggregates the sample in the slot of its time, replacing
+// the slot's previous content if it is older than the window.
+//
+// The samples of unknown sink kinds are dropped, running
+// the thresholds reports the error.
+func (w *sampleWindow) add(s Sample) {
+	index := w.slotIndex(s.Time)
+	slot := &w.slots[(index%windowSlots+windowSlots)%windowSlots]
+	switch {
+	case slot.sink != nil && slot.index == index:
+	case slot.sink != nil && slot.index > index:
+		// the sample is older than the whole window
+		return
+	default:
+		sink, err := newSlotSinkLike(w.like)
+		if err != nil {
+			return
+		}
+		slot.index, slot.sink = index, sink
+	}
+	slot.sink.Add(s)
+}
+
+// sink returns a new sink, of the same kind as the metric's one, with the
+// samples of the window ending at now. It returns nil if the window doesn't
+// have any sample.
+func (w *sampleWindow) sink(now time.Time) (Sink, error) {
+	var sink Sink
+	oldest := w.slotIndex(now) - windowSlots
+	for i := range w.slots {
+		slot := &w.slots[i]
+		if slot.sink == nil {
+			continue
+		}
+		if slot.index <= oldest {
+			// release the expired aggregates
+			slot.sink = nil
+			continue
+		}
+
+		if sink == nil {
+			var err error
+			if sink, err = newEmptySinkLike(w.like); err != nil {
+				return nil, err
+			}
+		}
+		if err := mergeSink(sink, slot.sink); err != nil {
+			return nil, err
+		}
+	}
+	return sink, nil
+}
+
+// newEmptySinkLike returns a new empty sink of the same kind
+// and with the same configuration as the provided one.
+func newEmptySinkLike(sink Sink) (Sink, error) {
+	switch sinkImpl := sink.(type) {
+	case *CounterSink:
+		return &CounterSink{}, nil
+	case *GaugeSink:
+		return &GaugeSink{}, nil
+	case *TrendSink:
+		return NewTrendSinkWithMaxValues(sinkImpl.maxValues), nil
+	case *RateSink:
+		return &RateSink{}, nil
+	case *HistogramSink:
+		return NewHistogramSink(sinkImpl.Buckets()), nil
+	default:
+		return nil, fmt.Errorf("unable to run Thresholds over a sliding window; reason: unknown sink type")
+	}
+}
+
+// newSlotSinkLike returns a new empty sink for a slot of a window,
+// the Trend sinks store at most windowTrendMaxValues values.
+func newSlotSinkLike(sink Sink) (Sink, error) {
+	if trend, ok := sink.(*TrendSink); ok {
+		maxValues := windowTrendMaxValues
+		if trend.maxValues > 0 && trend.maxValues < maxValues {
+			maxValues = trend.maxValues
+		}
+		return NewTrendSinkWithMaxValues(maxValues), nil
+	}
+	return newEmptySinkLike(sink)
+}
+
+// mergeSink adds the aggregated values of src to dst,
+// they are expected to be sinks of the same kind.
+func mergeSink(dst, src Sink) error {
+	switch dstImpl := dst.(type) {
+	case *CounterSink:
+		srcImpl, ok := src.(*CounterSink)
+		if !ok {
+			break
+		}
+		dstImpl.Value += srcImpl.Value
+		if dstImpl.First.IsZero() || srcImpl.First.Before(dstImpl.First) {
+			dstImpl.First = srcImpl.First
+		}
+		return nil
+	case *GaugeSink:
+		srcImpl, ok := src.(*GaugeSink)
+		if !ok {
+			break
+		}
+		if !srcImpl.minSet {
+			return nil
+		}
+		if !dstImpl.minSet || srcImpl.Min < dstImpl.Min {
+			dstImpl.Min = srcImpl.Min
+		}
+		if !dstImpl.minSet || srcImpl.Max > dstImpl.Max {
+			dstImpl.Max = srcImpl.Max
+		}
+		if !dstImpl.minSet || !srcImpl.last.Before(dstImpl.last) {
+			dstImpl.Value, dstImpl.last = srcImpl.Value, srcImpl.last
+		}
+		dstImpl.minSet = true
+		return nil
+	case *TrendSink:
+		srcImpl, ok := src.(*TrendSink)
+		if !ok {
+			break
+		}
+		mergeTrendSink(dstImpl, srcImpl)
+		return nil
+	case *RateSink:
+		srcImpl, ok := src.(*RateSink)
+		if !ok {
+			break
+		}
+		dstImpl.Trues += srcImpl.Trues
+		dstImpl.Total += srcImpl.Total
+		return nil
+	case *HistogramSink:
+		srcImpl, ok := src.(*HistogramSink)
+		if !ok || len(srcImpl.counts) != len(dstImpl.counts) {
+			break
+		}
+		if srcImpl.count == 0 {
+			return nil
+		}
+		if dstImpl.count == 0 || srcImpl.min < dstImpl.min {
+			dstImpl.min = srcImpl.min
+		}
+		if dstImpl.count == 0 || srcImpl.max > dstImpl.max {
+			dstImpl.max = srcImpl.max
+		}
+		for i, c := range srcImpl.counts {
+			dstImpl.counts[i] += c
+		}
+		dstImpl.count += srcImpl.count
+		dstImpl.sum += srcImpl.sum
+		return nil
+	}
+	return fmt.Errorf("unable to run Thresholds over a sliding window; reason: mismatching sink types")
+}
+
+// mergeTrendSink adds the values stored by src to dst, weighted by their
+// estimated number of occurrences. The count, the sum, the min and the max
+// are kept exact.
+func mergeTrendSink(dst, src *TrendSink) {
+	if src.count == 0 {
+		return
+	}
+
+	count, sum := dst.count+src.count, dst.sum+src.sum
+	minValue, maxValue := src.min, src.max
+	if dst.count > 0 {
+		minValue, maxValue = math.Min(minValue, dst.min), math.Max(maxValue, dst.max)
+	}
+
+	for i, v := range src.values {
+		weight := uint64(math.Round(src.weight(i)))
+		if weight == 0 {
+			weight = 1
+		}
+		dst.Add(Sample{Value: v, Weight: weight})
+	}
+	dst.count, dst.sum, dst.min, dst.max = count, sum, minValue, maxValue
+}
@@ -6,6 +6,13 @@ import (
 
 const timeUnit = time.Millisecond
 
+// The common units of the metrics' values.
+const (
+	UnitMilliseconds = "ms"
+	UnitBytes        = "bytes"
+	UnitPercent      = "percent"
+)
+
 // D formats a duration for emission.
 // The reverse of D() is ToD().
 func D(d time.Duration) float64 {
@@ -0,0 +1,144 @@
+package expv2
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mstoykov/atlas"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	// seriesLimitPolicyDrop drops the samples of the new
+	// time series once the limit has been reached.
+	seriesLimitPolicyDrop = "drop"
+
+	// seriesLimitPolicyOverflow aggregates the samples of the new
+	// time series, once the limit has been reached, in a single
+	// time series per metric labeled with overflowLabelName.
+	seriesLimitPolicyOverflow = "overflow"
+
+	// overflowLabelName is the label of the time series
+	// aggregating the samples beyond the series limit.
+	overflowLabelName = "__overflow__"
+
+	// activeSeriesTTL is the time after its last sample when a time series
+	// isn't active anymore, so it doesn't count toward the series limit.
+	activeSeriesTTL = 5 * time.Minute
+)
+
+func validateSeriesLimitPolicy(policy string) error {
+	switch policy {
+	case "", seriesLimitPolicyDrop, seriesLimitPolicyOverflow:
+		return nil
+	default:
+		return fmt.Errorf("unsupported active series limit policy %q, the supported values are %s and %s",
+			policy, seriesLimitPolicyDrop, seriesLimitPolicyOverflow)
+	}
+}
+
+// seriesLimiter limits the cardinality of the active time series tracked by
+// the output, i.e. the ones with samples in the last ttl. It is not expected to
+// be used concurrently.
+type seriesLimiter struct {
+	max    int
+	policy string
+	ttl    time.Duration
+
+	// seen tracks the time of the last sample of the accepted time series.
+	seen map[metrics.TimeSeries]time.Time
+
+	// nextEviction is the time when the first of the seen
+	// time series isn't active anymore, so it can be evicted.
+	nextEviction time.Time
+
+	// overflow holds the overflow time series for each metric.
+	overflow map[*metrics.Metric]metrics.TimeSeries
+
+	// limited counts the samples of the time series beyond the limit
+	// since the last PopLimited call.
+	limited int
+}
+
+func newSeriesLimiter(maxSeries int, policy string, ttl time.Duration) *seriesLimiter {
+	if policy == "" {
+		policy = seriesLimitPolicyOverflow
+	}
+	return &seriesLimiter{
+		max:      maxSeries,
+		policy:   policy,
+		ttl:      ttl,
+		seen:     make(map[metrics.TimeSeries]time.Time),
+		overflow: make(map[*metrics.Metric]metrics.TimeSeries),
+	}
+}
+
+// Limit returns the time series the sample, with the provided time, has to be
+// aggregated to. It returns false if the sample has to be dropped.
+func (l *seriesLimiter) Limit(ts metrics.TimeSeries, t time.Time) (metrics.TimeSeries, bool) {
+	if last, ok := l.seen[ts]; ok {
+		if t.After(last) {
+			l.seen[ts] = t
+		}
+		return ts, true
+	}
+	if len(l.seen) >= l.max {
+		l.evict(t)
+	}
+	if len(l.seen) < l.max {
+		l.seen[ts] = t
+		return ts, true
+	}
+
+	l.limited++
+	if l.policy == seriesLimitPolicyDrop {
+		return metrics.TimeSeries{}, false
+	}
+
+	overflow, ok := l.overflow[ts.Metric]
+	if !ok {
+		overflow = metrics.TimeSeries{
+			Metric: ts.Metric,
+			Tags:   rootTagSet(ts.Tags).With(overflowLabelName, "true"),
+		}
+		l.overflow[ts.Metric] = overflow
+	}
+	return overflow, true
+}
+
+// evict removes the time series without samples in the ttl before the
+// provided time. The seen time series aren't scanned again until the
+// first of the remaining ones can be evicted.
+func (l *seriesLimiter) evict(now time.Time) {
+	if now.Before(l.nextEviction) {
+		return
+	}
+	var oldest time.Time
+	for ts, last := range l.seen {
+		if now.Sub(last) >= l.ttl {
+			delete(l.seen, ts)
+			continue
+		}
+		if oldest.IsZero() || last.Before(oldest) {
+			oldest = last
+		}
+	}
+	l.nextEviction = oldest.Add(l.ttl)
+}
+
+// PopLimited returns the number of the samples beyond the limit
+// since the previous call and it resets the counter.
+func (l *seriesLimiter) PopLimited() int {
+	n := l.limited
+	l.limited = 0
+	return n
+}
+
+// rootTagSet returns the root of the tag set's tree.
+func rootTagSet(tags *metrics.TagSet) *metrics.TagSet {
+	n := (*atlas.Node)(tags)
+	for !n.IsRoot() {
+		n, _, _ = n.Data()
+	}
+	return (*metrics.TagSet)(n)
+}
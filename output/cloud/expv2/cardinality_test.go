@@ -0,0 +1,98 @@
+package expv2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/metrics"
+)
+
+func TestValidateSeriesLimitPolicy(t *testing.T) {
+	t.Parallel()
+
+	for _, p := range []string{"", seriesLimitPolicyDrop, seriesLimitPolicyOverflow} {
+		assert.NoError(t, validateSeriesLimitPolicy(p))
+	}
+	assert.Error(t, validateSeriesLimitPolicy("evict"))
+}
+
+func TestSeriesLimiterLimit(t *testing.T) {
+	t.Parallel()
+
+	r := metrics.NewRegistry()
+	m1 := r.MustNewMetric("metric1", metrics.Counter)
+	series := func(v string) metrics.TimeSeries {
+		return metrics.TimeSeries{Metric: m1, Tags: r.RootTagSet().With("key1", v)}
+	}
+	now := time.Unix(1, 0)
+
+	t.Run("Drop", func(t *testing.T) {
+		t.Parallel()
+
+		l := newSeriesLimiter(2, seriesLimitPolicyDrop, time.Minute)
+		for _, v := range []string{"a", "b", "a"} {
+			ts, ok := l.Limit(series(v), now)
+			require.True(t, ok)
+			assert.Equal(t, series(v), ts)
+		}
+
+		_, ok := l.Limit(series("c"), now)
+		assert.False(t, ok)
+		assert.Equal(t, 1, l.PopLimited())
+		assert.Zero(t, l.PopLimited())
+	})
+
+	t.Run("Overflow", func(t *testing.T) {
+		t.Parallel()
+
+		l := newSeriesLimiter(1, "", time.Minute)
+		_, ok := l.Limit(series("a"), now)
+		require.True(t, ok)
+
+		ts1, ok := l.Limit(series("b"), now)
+		require.True(t, ok)
+		ts2, ok := l.Limit(series("c"), now)
+		require.True(t, ok)
+
+		assert.Equal(t, ts1, ts2)
+		assert.Equal(t, map[string]string{overflowLabelName: "true"}, ts1.Tags.Map())
+		assert.Equal(t, 2, l.PopLimited())
+
+		labels, discarded := mapTimeSeriesLabelsProto(ts1.Tags)
+		assert.Empty(t, discarded)
+		require.Len(t, labels, 1)
+		assert.Equal(t, overflowLabelName, labels[0].Name)
+	})
+
+	t.Run("Eviction", func(t *testing.T) {
+		t.Parallel()
+
+		l := newSeriesLimiter(2, seriesLimitPolicyDrop, time.Minute)
+		_, ok := l.Limit(series("a"), now)
+		require.True(t, ok)
+		_, ok = l.Limit(series("b"), now)
+		require.True(t, ok)
+
+		// the series are still active
+		_, ok = l.Limit(series("a"), now.Add(30*time.Second))
+		require.True(t, ok)
+		_, ok = l.Limit(series("c"), now.Add(59*time.Second))
+		assert.False(t, ok)
+
+		// b is evicted, a is still active
+		ts, ok := l.Limit(series("c"), now.Add(time.Minute))
+		require.True(t, ok)
+		assert.Equal(t, series("c"), ts)
+		_, ok = l.Limit(series("d"), now.Add(time.Minute))
+		assert.False(t, ok)
+		assert.Len(t, l.seen, 2)
+		assert.Contains(t, l.seen, series("a"))
+
+		// a is evicted too
+		_, ok = l.Limit(series("d"), now.Add(90*time.Second))
+		assert.True(t, ok)
+		assert.Equal(t, 2, l.PopLimited())
+	})
+}
@@ -2,6 +2,7 @@ package expv2
 
 import (
 	"errors"
+	"math"
 	"sync"
 	"time"
 
@@ -18,6 +19,15 @@ type timeBucket struct {
 type bucketQ struct {
 	m       sync.Mutex
 	buckets []timeBucket
+
+	// limit is the max number of buckets the queue can hold,
+	// when it is exceeded the first enqueued buckets are dropped.
+	// Zero means no limit.
+	limit int
+
+	// dropped counts the buckets dropped because of the limit
+	// since the last PopDropped call.
+	dropped int
 }
 
 // PopAll returns a slice with all the pushed buckets.
@@ -44,9 +54,23 @@ func (q *bucketQ) Push(b []timeBucket) {
 	}
 	q.m.Lock()
 	q.buckets = append(q.buckets, b...)
+	if exceeding := len(q.buckets) - q.limit; q.limit > 0 && exceeding > 0 {
+		q.buckets = q.buckets[exceeding:]
+		q.dropped += exceeding
+	}
 	q.m.Unlock()
 }
 
+// PopDropped returns the number of buckets dropped
+// since the previous call and it resets the counter.
+func (q *bucketQ) PopDropped() int {
+	q.m.Lock()
+	defer q.m.Unlock()
+	d := q.dropped
+	q.dropped = 0
+	return d
+}
+
 type collector struct {
 	bq      bucketQ
 	nowFunc func() time.Time
@@ -54,6 +78,15 @@ type collector struct {
 	aggregationPeriod time.Duration
 	waitPeriod        time.Duration
 
+	// trendResolutions holds the per-metric minimum resolutions
+	// of the Trend histograms. The metrics without an override
+	// use defaultTrendResolution, or defaultMinimumResolution if it is zero.
+	trendResolutions       map[string]float64
+	defaultTrendResolution float64
+
+	// limiter is optional, if set it limits the cardinality of the time series.
+	limiter *seriesLimiter
+
 	// we should no longer have to handle metrics that have times long in the past. So instead of a
 	// map, we can probably use a simple slice (or even an array!) as a ring buffer to store the
 	// aggregation buckets. This should save us a some time, since it would make the lookups and WaitPeriod
@@ -88,7 +121,10 @@ func newCollector(aggrPeriod, waitPeriod time.Duration) (*collector, error) {
 }
 
 // CollectSamples drain the buffer and collect all the samples.
-func (c *collector) CollectSamples(containers []metrics.SampleContainer) {
+// It returns the number of the collected samples.
+func (c *collector) CollectSamples(containers []metrics.SampleContainer) int {
+	var collected int
+
 	// Distribute all newly buffered samples into related buckets
 	for _, sampleContainer := range containers {
 		samples := sampleContainer.GetSamples()
@@ -96,8 +132,10 @@ func (c *collector) CollectSamples(containers []metrics.SampleContainer) {
 		for i := 0; i < len(samples); i++ {
 			c.collectSample(samples[i])
 		}
+		collected += len(samples)
 	}
 	c.bq.Push(c.expiredBuckets())
+	return collected
 }
 
 // DropExpiringDelay drops the waiting time for buckets
@@ -107,6 +145,14 @@ func (c *collector) DropExpiringDelay() {
 }
 
 func (c *collector) collectSample(s metrics.Sample) {
+	if c.limiter != nil {
+		ts, ok := c.limiter.Limit(s.TimeSeries, s.Time)
+		if !ok {
+			return
+		}
+		s.TimeSeries = ts
+	}
+
 	bucketID := c.bucketID(s.Time)
 
 	// Get or create a time bucket
@@ -119,11 +165,36 @@ func (c *collector) collectSample(s metrics.Sample) {
 	// Get or create the bucket's sinks map per time series
 	sink, ok := bucket[s.TimeSeries]
 	if !ok {
-		sink = newMetricValue(s.Metric.Type)
+		sink = c.newSink(s.Metric)
 		bucket[s.TimeSeries] = sink
 	}
 
-	sink.Add(s.Value)
+	sink.AddWeighted(s.Value, clampedOccurrences(s))
+}
+
+// clampedOccurrences returns the occurrences of the sample, clamped
+// to the max value of the uint32 counters of the aggregated values.
+func clampedOccurrences(s metrics.Sample) uint32 {
+	n := s.Occurrences()
+	if n > math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return uint32(n)
+}
+
+// newSink returns a new sink for the metric, Trend and Histogram metrics
+// get a histogram using the metric's configured resolution.
+func (c *collector) newSink(m *metrics.Metric) metricValue {
+	if m.Type != metrics.Trend && m.Type != metrics.Histogram {
+		return newMetricValue(m.Type)
+	}
+	if r, ok := c.trendResolutions[m.Name]; ok {
+		return newHistogramWithResolution(r)
+	}
+	if c.defaultTrendResolution > 0 {
+		return newHistogramWithResolution(c.defaultTrendResolution)
+	}
+	return newHistogram()
 }
 
 func (c *collector) expiredBuckets() []timeBucket {
@@ -1,6 +1,7 @@
 package expv2
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -54,6 +55,43 @@ func TestCollectorCollectSample(t *testing.T) {
 	assert.Len(t, c.timeBuckets, 3)
 }
 
+func TestCollectorCollectSampleTrendResolution(t *testing.T) {
+	t.Parallel()
+
+	r := metrics.NewRegistry()
+	m1, err := r.NewMetric("metric1", metrics.Trend)
+	require.NoError(t, err)
+	m2, err := r.NewMetric("metric2", metrics.Trend)
+	require.NoError(t, err)
+
+	c := collector{
+		aggregationPeriod:      3 * time.Second,
+		waitPeriod:             1 * time.Second,
+		timeBuckets:            make(map[int64]map[metrics.TimeSeries]metricValue),
+		defaultTrendResolution: .01,
+		trendResolutions:       map[string]float64{"metric2": .00001},
+		nowFunc: func() time.Time {
+			return time.Unix(31, 0)
+		},
+	}
+
+	ts1 := metrics.TimeSeries{Metric: m1, Tags: r.RootTagSet()}
+	ts2 := metrics.TimeSeries{Metric: m2, Tags: r.RootTagSet()}
+	c.collectSample(metrics.Sample{TimeSeries: ts1, Value: 1.5, Time: time.Unix(10, 0)})
+	c.collectSample(metrics.Sample{TimeSeries: ts2, Value: 1.5, Time: time.Unix(10, 0)})
+
+	bucket := c.timeBuckets[c.bucketID(time.Unix(10, 0))]
+	require.Len(t, bucket, 2)
+
+	h1, ok := bucket[ts1].(*histogram)
+	require.True(t, ok)
+	assert.Equal(t, .01, h1.MinimumResolution)
+
+	h2, ok := bucket[ts2].(*histogram)
+	require.True(t, ok)
+	assert.Equal(t, .00001, h2.MinimumResolution)
+}
+
 func TestCollectorCollectSampleAggregateNumbers(t *testing.T) {
 	t.Parallel()
 
@@ -100,6 +138,30 @@ func TestCollectorCollectSampleAggregateNumbers(t *testing.T) {
 	assert.Equal(t, 7.0, sink.Sum)
 }
 
+func TestCollectorCollectSampleClampsWeight(t *testing.T) {
+	t.Parallel()
+
+	r := metrics.NewRegistry()
+	m1, err := r.NewMetric("metric1", metrics.Rate)
+	require.NoError(t, err)
+
+	c := collector{
+		aggregationPeriod: 3 * time.Second,
+		waitPeriod:        1 * time.Second,
+		timeBuckets:       make(map[int64]map[metrics.TimeSeries]metricValue),
+		nowFunc: func() time.Time {
+			return time.Unix(31, 0)
+		},
+	}
+	ts := metrics.TimeSeries{Metric: m1, Tags: r.RootTagSet()}
+	c.collectSample(metrics.Sample{TimeSeries: ts, Value: 1, Weight: 1 << 40, Time: time.Unix(11, 0)})
+
+	sink, ok := c.timeBuckets[3][ts].(*rate)
+	require.True(t, ok)
+	assert.Equal(t, uint32(math.MaxUint32), sink.Total)
+	assert.Equal(t, uint32(math.MaxUint32), sink.NonZeroCount)
+}
+
 func TestDropExpiringDelay(t *testing.T) {
 	t.Parallel()
 
@@ -244,6 +306,22 @@ func TestBucketQPush(t *testing.T) {
 	require.Len(t, bq.buckets, 1)
 }
 
+func TestBucketQPushWithLimit(t *testing.T) {
+	t.Parallel()
+
+	bq := bucketQ{limit: 2}
+	bq.Push([]timeBucket{{Time: 1}, {Time: 2}})
+	assert.Zero(t, bq.PopDropped())
+
+	bq.Push([]timeBucket{{Time: 3}, {Time: 4}, {Time: 5}})
+	require.Len(t, bq.buckets, 2)
+	assert.Equal(t, int64(4), bq.buckets[0].Time)
+	assert.Equal(t, int64(5), bq.buckets[1].Time)
+
+	assert.Equal(t, 3, bq.PopDropped())
+	assert.Zero(t, bq.PopDropped())
+}
+
 func TestBucketQPopAll(t *testing.T) {
 	t.Parallel()
 	bq := bucketQ{
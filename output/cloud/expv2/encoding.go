@@ -0,0 +1,98 @@
+package expv2
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// encodingSnappy is the default encoding of the metrics payloads.
+	encodingSnappy = "snappy"
+
+	// encodingGzip encodes the metrics payloads using gzip.
+	encodingGzip = "gzip"
+
+	// encodingZstd encodes the metrics payloads using zstd. It usually gives
+	// the best compression ratio for large and label-heavy payloads.
+	encodingZstd = "zstd"
+)
+
+// payloadEncoder compresses the Protobuf encoded payloads.
+// The name is used as the value of the Content-Encoding header.
+type payloadEncoder interface {
+	Name() string
+	Encode(b []byte) ([]byte, error)
+}
+
+// newPayloadEncoder returns the encoder for the provided Content-Encoding.
+// It defaults to snappy if the encoding is empty.
+func newPayloadEncoder(encoding string) (payloadEncoder, error) {
+	switch encoding {
+	case "", encodingSnappy:
+		return snappyEncoder{}, nil
+	case encodingGzip:
+		return gzipEncoder{}, nil
+	case encodingZstd:
+		// The encoder is safe to use concurrently when only EncodeAll is used.
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize the zstd encoder: %w", err)
+		}
+		return zstdEncoder{enc: enc}, nil
+	default:
+		return nil, fmt.Errorf("unsupported metrics payload encoding %q, the supported values are %s, %s and %s",
+			encoding, encodingSnappy, encodingGzip, encodingZstd)
+	}
+}
+
+type snappyEncoder struct{}
+
+func (snappyEncoder) Name() string {
+	return encodingSnappy
+}
+
+func (snappyEncoder) Encode(b []byte) ([]byte, error) {
+	// TODO: use the framing format
+	// https://github.com/google/snappy/blob/main/framing_format.txt
+	// It can be done replacing the encode with
+	// https://pkg.go.dev/github.com/klauspost/compress/snappy#NewBufferedWriter
+	if snappy.MaxEncodedLen(len(b)) < 0 {
+		return nil, fmt.Errorf("the Protobuf message is too large to be handled by Snappy encoder; "+
+			"size: %d, limit: %d", len(b), 0xffffffff)
+	}
+	return snappy.Encode(nil, b), nil
+}
+
+type gzipEncoder struct{}
+
+func (gzipEncoder) Name() string {
+	return encodingGzip
+}
+
+func (gzipEncoder) Encode(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type zstdEncoder struct {
+	enc *zstd.Encoder
+}
+
+func (zstdEncoder) Name() string {
+	return encodingZstd
+}
+
+func (e zstdEncoder) Encode(b []byte) ([]byte, error) {
+	return e.enc.EncodeAll(b, make([]byte, 0, len(b)/2)), nil
+}
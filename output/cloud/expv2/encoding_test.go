@@ -0,0 +1,69 @@
+package expv2
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadEncoders(t *testing.T) {
+	t.Parallel()
+
+	payload := []byte(strings.Repeat("label-heavy-payload", 100))
+
+	decoders := map[string]func([]byte) ([]byte, error){
+		"": func(b []byte) ([]byte, error) {
+			return snappy.Decode(nil, b)
+		},
+		encodingSnappy: func(b []byte) ([]byte, error) {
+			return snappy.Decode(nil, b)
+		},
+		encodingGzip: func(b []byte) ([]byte, error) {
+			r, err := gzip.NewReader(bytes.NewReader(b))
+			if err != nil {
+				return nil, err
+			}
+			return io.ReadAll(r)
+		},
+		encodingZstd: func(b []byte) ([]byte, error) {
+			d, err := zstd.NewReader(nil)
+			if err != nil {
+				return nil, err
+			}
+			defer d.Close()
+			return d.DecodeAll(b, nil)
+		},
+	}
+
+	for encoding, decode := range decoders {
+		encoding, decode := encoding, decode
+		t.Run(encoding, func(t *testing.T) {
+			t.Parallel()
+
+			enc, err := newPayloadEncoder(encoding)
+			require.NoError(t, err)
+
+			b, err := enc.Encode(payload)
+			require.NoError(t, err)
+			assert.Less(t, len(b), len(payload))
+
+			decoded, err := decode(b)
+			require.NoError(t, err)
+			assert.Equal(t, payload, decoded)
+		})
+	}
+}
+
+func TestPayloadEncoderUnsupported(t *testing.T) {
+	t.Parallel()
+
+	_, err := newPayloadEncoder("brotli")
+	assert.ErrorContains(t, err, `unsupported metrics payload encoding "brotli"`)
+}
@@ -0,0 +1,68 @@
+package expv2
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/output/cloud/expv2/pbcloud"
+)
+
+const (
+	// failurePolicyAbort stops the test run when the metrics
+	// can't be pushed to the cloud anymore.
+	failurePolicyAbort = "abort"
+
+	// failurePolicyWarn keeps the test running, logging a warning
+	// about the metrics in the cloud that could be incomplete.
+	failurePolicyWarn = "warn"
+
+	// failurePolicyLocalFallback keeps the test running, writing the
+	// metrics payloads to local files for uploading them later.
+	failurePolicyLocalFallback = "local-fallback"
+)
+
+func validateFailurePolicy(policy string) error {
+	switch policy {
+	case "", failurePolicyAbort, failurePolicyWarn, failurePolicyLocalFallback:
+		return nil
+	default:
+		return fmt.Errorf("unsupported metrics push failure policy %q, the supported values are %s, %s and %s",
+			policy, failurePolicyAbort, failurePolicyWarn, failurePolicyLocalFallback)
+	}
+}
+
+// filePusher implements the pusher interface writing the metrics
+// payloads to files, one for each push, instead of sending them.
+//
+// The files hold the same body the metrics client would send,
+// so they can be uploaded later as they are.
+type filePusher struct {
+	fs      fsext.Fs
+	dir     string
+	encoder payloadEncoder
+	seq     uint64
+}
+
+func newFilePusher(fs fsext.Fs, dir string, encoder payloadEncoder) (*filePusher, error) {
+	if err := fs.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create the metrics fallback directory: %w", err)
+	}
+	return &filePusher{fs: fs, dir: dir, encoder: encoder}, nil
+}
+
+func (fp *filePusher) push(_ context.Context, samples *pbcloud.MetricSet) error {
+	b, err := newRequestBody(samples, fp.encoder)
+	if err != nil {
+		return err
+	}
+
+	n := atomic.AddUint64(&fp.seq, 1)
+	name := filepath.Join(fp.dir, fmt.Sprintf("metrics-%06d.pb.%s", n, fp.encoder.Name()))
+	if err := fsext.WriteFile(fp.fs, name, b, 0o600); err != nil {
+		return fmt.Errorf("failed to write the metrics to the fallback file: %w", err)
+	}
+	return nil
+}
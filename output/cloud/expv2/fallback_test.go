@@ -0,0 +1,51 @@
+package expv2
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/output/cloud/expv2/pbcloud"
+)
+
+func TestFilePusher(t *testing.T) {
+	t.Parallel()
+
+	fs := fsext.NewMemMapFs()
+	dir := filepath.Join("nested", "fallback")
+	fp, err := newFilePusher(fs, dir, snappyEncoder{})
+	require.NoError(t, err)
+
+	require.NoError(t, fp.push(context.Background(), &pbcloud.MetricSet{TestRunId: "123"}))
+	require.NoError(t, fp.push(context.Background(), &pbcloud.MetricSet{TestRunId: "456"}))
+
+	files, err := fsext.ReadDir(fs, dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "metrics-000001.pb.snappy", files[0].Name())
+	assert.Equal(t, "metrics-000002.pb.snappy", files[1].Name())
+
+	b, err := fsext.ReadFile(fs, filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	b, err = snappy.Decode(nil, b)
+	require.NoError(t, err)
+
+	var ms pbcloud.MetricSet
+	require.NoError(t, proto.Unmarshal(b, &ms))
+	assert.Equal(t, "123", ms.TestRunId)
+}
+
+func TestValidateFailurePolicy(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range []string{"", failurePolicyAbort, failurePolicyWarn, failurePolicyLocalFallback} {
+		assert.NoError(t, validateFailurePolicy(policy))
+	}
+	assert.Error(t, validateFailurePolicy("retry-forever"))
+}
@@ -1,18 +1,30 @@
 package expv2
 
 import (
+	"context"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
 
 	"go.k6.io/k6/metrics"
 	"go.k6.io/k6/output/cloud/expv2/pbcloud"
 )
 
 type pusher interface {
-	push(samples *pbcloud.MetricSet) error
+	push(ctx context.Context, samples *pbcloud.MetricSet) error
 }
 
+// maxRequeuedBatches is the max number of batches kept
+// for being retried on the next flush after a failed push.
+// The oldest batches are dropped when the limit is hit.
+const maxRequeuedBatches = 100
+
+// defaultMaxBatchPayloadSize is the default max size, in bytes,
+// of the encoded Protobuf payload of a single batch. Batches
+// exceeding it are split before being pushed.
+const defaultMaxBatchPayloadSize = 5 * 1024 * 1024
+
 type metricsFlusher struct {
 	testRunID                  string
 	bq                         *bucketQ
@@ -22,15 +34,41 @@ type metricsFlusher struct {
 	aggregationPeriodInSeconds uint32
 	maxSeriesInBatch           int
 	batchPushConcurrency       int
+
+	// maxBatchPayloadSize is the max size, in bytes, of the encoded
+	// Protobuf payload of a batch. Zero means no limit.
+	maxBatchPayloadSize int
+
+	// stats is optional, if set it tracks the flushed
+	// series and the dropped buckets and batches.
+	stats *stats
+
+	// labels caches the mapped labels of the time series across the flushes.
+	labels *labelsCache
+
+	// requeued holds the batches whose push failed with a retryable error,
+	// they are pushed again on the next flush.
+	requeued []*pbcloud.MetricSet
 }
 
 // flush flushes the queued buckets sending them to the remote Cloud service.
 // If the number of time series collected is bigger than maximum batch size
-// then it splits in chunks.
-func (f *metricsFlusher) flush() error {
+// then it splits in chunks. The pushes are canceled when the context is done.
+func (f *metricsFlusher) flush(ctx context.Context) error {
+	if dropped := f.bq.PopDropped(); dropped > 0 {
+		if f.stats != nil {
+			f.stats.bucketsDropped.Add(int64(dropped))
+		}
+		f.logger.WithField("buckets", dropped).
+			Warn("Dropped the oldest aggregated metrics, the push queue is full because the flushing is falling behind")
+	}
+
 	// drain the buffer
 	buckets := f.bq.PopAll()
-	if len(buckets) < 1 {
+	if f.stats != nil {
+		f.stats.observePushQueueDepth(len(buckets))
+	}
+	if len(buckets) < 1 && len(f.requeued) < 1 {
 		return nil
 	}
 
@@ -45,11 +83,15 @@ func (f *metricsFlusher) flush() error {
 
 	var (
 		start       = time.Now()
-		batches     []*pbcloud.MetricSet
+		batches     = f.requeued
 		seriesCount int
 	)
+	f.requeued = nil
 
 	defer func() {
+		if f.stats != nil {
+			f.stats.seriesFlushed.Add(int64(seriesCount))
+		}
 		f.logger.
 			WithField("t", time.Since(start)).
 			WithField("series", seriesCount).
@@ -57,7 +99,11 @@ func (f *metricsFlusher) flush() error {
 			WithField("batches", len(batches)).Debug("Flush the queued buckets")
 	}()
 
+	if f.labels == nil {
+		f.labels = newLabelsCache()
+	}
 	msb := newMetricSetBuilder(f.testRunID, f.aggregationPeriodInSeconds)
+	msb.labels = f.labels
 	for i := 0; i < len(buckets); i++ {
 		for timeSeries, sink := range buckets[i].Sinks {
 			msb.addTimeSeries(buckets[i].Time, timeSeries, sink)
@@ -67,25 +113,25 @@ func (f *metricsFlusher) flush() error {
 
 			// We hit the batch size, let's flush
 			seriesCount += len(msb.seriesIndex)
-			batches = append(batches, msb.MetricSet)
+			batches = append(batches, f.splitBySize(msb.MetricSet)...)
 			f.reportDiscardedLabels(msb.discardedLabels)
 
 			// Reset the builder
-			msb = newMetricSetBuilder(f.testRunID, f.aggregationPeriodInSeconds)
+			msb.reset()
 		}
 	}
 
 	// send the last (or the unique) MetricSet chunk to the remote service
 	if len(msb.seriesIndex) != 0 {
 		seriesCount += len(msb.seriesIndex)
-		batches = append(batches, msb.MetricSet)
+		batches = append(batches, f.splitBySize(msb.MetricSet)...)
 		f.reportDiscardedLabels(msb.discardedLabels)
 	}
 
-	return f.flushBatches(batches)
+	return f.flushBatches(ctx, batches)
 }
 
-func (f *metricsFlusher) flushBatches(batches []*pbcloud.MetricSet) error {
+func (f *metricsFlusher) flushBatches(ctx context.Context, batches []*pbcloud.MetricSet) error {
 	// TODO remove after go 1.21 becomes the minimum supported version - it has `min` in it
 	min := func(a, b int) int {
 		if a < b {
@@ -97,14 +143,17 @@ func (f *metricsFlusher) flushBatches(batches []*pbcloud.MetricSet) error {
 	var (
 		workers  = min(len(batches), f.batchPushConcurrency)
 		errs     = make(chan error, workers)
+		failed   = make(chan *pbcloud.MetricSet, workers)
 		feed     = make(chan *pbcloud.MetricSet)
 		finalErr error
+		unsent   []*pbcloud.MetricSet
 	)
 
 	for i := 0; i < workers; i++ {
 		go func() {
 			for chunk := range feed {
-				if err := f.client.push(chunk); err != nil {
+				if err := f.client.push(ctx, chunk); err != nil {
+					failed <- chunk
 					errs <- err
 					return
 				}
@@ -119,6 +168,7 @@ outer:
 		case err := <-errs:
 			workers--
 			finalErr = err
+			unsent = batches[i:]
 			break outer
 		case feed <- batches[i]:
 		}
@@ -132,9 +182,89 @@ outer:
 			finalErr = err
 		}
 	}
+	close(failed)
+
+	if finalErr != nil && isRetryableError(finalErr) {
+		// the failed batches were fed before the unsent ones,
+		// so they are requeued first for preserving the order
+		var requeued []*pbcloud.MetricSet
+		for chunk := range failed {
+			requeued = append(requeued, chunk)
+		}
+		f.requeue(append(requeued, unsent...))
+	}
 	return finalErr
 }
 
+// requeue stores the provided batches for pushing them on the next flush.
+func (f *metricsFlusher) requeue(batches []*pbcloud.MetricSet) {
+	f.requeued = append(f.requeued, batches...)
+	if dropped := len(f.requeued) - maxRequeuedBatches; dropped > 0 {
+		f.requeued = f.requeued[dropped:]
+		if f.stats != nil {
+			f.stats.batchesDropped.Add(int64(dropped))
+		}
+		f.logger.WithField("batches", dropped).
+			Warn("Dropped the oldest metrics batches, too many failed pushes are waiting to be retried")
+	}
+}
+
+// splitBySize splits the provided metric set in chunks,
+// each with an encoded payload smaller than the max batch payload size.
+//
+// The time series are halved recursively until the chunks fit the limit.
+// A chunk with a single time series is never split further, even if it
+// doesn't fit, because it is the minimum unit the remote service accepts.
+func (f *metricsFlusher) splitBySize(ms *pbcloud.MetricSet) []*pbcloud.MetricSet {
+	if f.maxBatchPayloadSize <= 0 || proto.Size(ms) <= f.maxBatchPayloadSize {
+		return []*pbcloud.MetricSet{ms}
+	}
+
+	type seriesRef struct {
+		metric *pbcloud.Metric
+		series *pbcloud.TimeSeries
+	}
+
+	var refs []seriesRef
+	for _, m := range ms.Metrics {
+		for _, ts := range m.TimeSeries {
+			refs = append(refs, seriesRef{metric: m, series: ts})
+		}
+	}
+	if len(refs) < 2 {
+		return []*pbcloud.MetricSet{ms}
+	}
+
+	newChunk := func(refs []seriesRef) *pbcloud.MetricSet {
+		chunk := &pbcloud.MetricSet{
+			TestRunId:         ms.TestRunId,
+			AggregationPeriod: ms.AggregationPeriod,
+		}
+
+		var last *pbcloud.Metric
+		for _, ref := range refs {
+			// the series are grouped by metric, so a new metric
+			// is required only when the metric changes
+			if last == nil || last.Name != ref.metric.Name {
+				last = &pbcloud.Metric{
+					Name:        ref.metric.Name,
+					Type:        ref.metric.Type,
+					Unit:        ref.metric.Unit,
+					Description: ref.metric.Description,
+				}
+				chunk.Metrics = append(chunk.Metrics, last)
+			}
+			last.TimeSeries = append(last.TimeSeries, ref.series)
+		}
+		return chunk
+	}
+
+	half := len(refs) / 2
+	return append(
+		f.splitBySize(newChunk(refs[:half])),
+		f.splitBySize(newChunk(refs[half:]))...)
+}
+
 func (f *metricsFlusher) reportDiscardedLabels(discardedLabels map[string]struct{}) {
 	for key := range discardedLabels {
 		if _, ok := f.discardedLabels[key]; ok {
@@ -156,11 +286,6 @@ type metricSetBuilder struct {
 	// if an array, with the length equals to the number of registered metrics,
 	// could eventually work.
 	//
-	// TODO: we may evaluate to replace it with
-	// map[*metrics.Metric][]*pbcloud.TimeSeries)
-	// and use a sync.Pool for the series slice.
-	// We need dedicated benchmarks before doing it.
-	//
 	// metrics tracks the related metric conversion
 	// into a protobuf structure.
 	metrics map[*metrics.Metric]*pbcloud.Metric
@@ -175,6 +300,10 @@ type metricSetBuilder struct {
 	// discardedLabels tracks the labels that have been discarded
 	// since they are reserved for internal usage by the Cloud service.
 	discardedLabels map[string]struct{}
+
+	// labels is optional, if set it is used for reusing
+	// the mapped labels across the builders.
+	labels *labelsCache
 }
 
 func newMetricSetBuilder(testRunID string, aggrPeriodSec uint32) metricSetBuilder {
@@ -191,12 +320,32 @@ func newMetricSetBuilder(testRunID string, aggrPeriodSec uint32) metricSetBuilde
 	return builder
 }
 
+// reset prepares the builder for a new MetricSet.
+// The internal indexes are reused for reducing the allocations,
+// instead the MetricSet is always a new one because
+// the previous one could be still referenced by a batch.
+func (msb *metricSetBuilder) reset() {
+	msb.MetricSet = &pbcloud.MetricSet{
+		TestRunId:         msb.MetricSet.TestRunId,
+		AggregationPeriod: msb.MetricSet.AggregationPeriod,
+	}
+	for k := range msb.metrics {
+		delete(msb.metrics, k)
+	}
+	for k := range msb.seriesIndex {
+		delete(msb.seriesIndex, k)
+	}
+	msb.discardedLabels = nil
+}
+
 func (msb *metricSetBuilder) addTimeSeries(timestamp int64, timeSeries metrics.TimeSeries, sink metricValue) {
 	pbmetric, ok := msb.metrics[timeSeries.Metric]
 	if !ok {
 		pbmetric = &pbcloud.Metric{
-			Name: timeSeries.Metric.Name,
-			Type: mapMetricTypeProto(timeSeries.Metric.Type),
+			Name:        timeSeries.Metric.Name,
+			Type:        mapMetricTypeProto(timeSeries.Metric.Type),
+			Unit:        timeSeries.Metric.Unit,
+			Description: timeSeries.Metric.Description,
 		}
 		msb.metrics[timeSeries.Metric] = pbmetric
 		msb.MetricSet.Metrics = append(msb.MetricSet.Metrics, pbmetric)
@@ -204,7 +353,15 @@ func (msb *metricSetBuilder) addTimeSeries(timestamp int64, timeSeries metrics.T
 
 	var pbTimeSeries *pbcloud.TimeSeries
 	if ix, ok := msb.seriesIndex[timeSeries]; !ok {
-		labels, discardedLabels := mapTimeSeriesLabelsProto(timeSeries.Tags)
+		var (
+			labels          []*pbcloud.Label
+			discardedLabels []string
+		)
+		if msb.labels != nil {
+			labels, discardedLabels = msb.labels.get(timeSeries.Tags)
+		} else {
+			labels, discardedLabels = mapTimeSeriesLabelsProto(timeSeries.Tags)
+		}
 		msb.recordDiscardedLabels(discardedLabels)
 
 		pbTimeSeries = &pbcloud.TimeSeries{
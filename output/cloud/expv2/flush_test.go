@@ -1,15 +1,18 @@
 package expv2
 
 import (
+	"context"
 	"errors"
 	"strconv"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 
 	"go.k6.io/k6/lib/testutils"
 	"go.k6.io/k6/metrics"
@@ -41,6 +44,25 @@ func TestMetricSetBuilderAddTimeBucket(t *testing.T) {
 	assert.Len(t, msb.MetricSet.Metrics[0].TimeSeries, 1)
 }
 
+func TestMetricSetBuilderAddTimeSeriesWithInfo(t *testing.T) {
+	t.Parallel()
+
+	r := metrics.NewRegistry()
+	m1, err := r.NewMetricWithInfo("payload_size", metrics.Trend, metrics.UnitBytes, "The size of the payloads")
+	require.NoError(t, err)
+	timeSeries := metrics.TimeSeries{
+		Metric: m1,
+		Tags:   r.RootTagSet().With("key1", "val1"),
+	}
+
+	msb := newMetricSetBuilder("testrunid-123", 1)
+	msb.addTimeSeries(1, timeSeries, newHistogram())
+
+	require.Len(t, msb.MetricSet.Metrics, 1)
+	assert.Equal(t, metrics.UnitBytes, msb.MetricSet.Metrics[0].Unit)
+	assert.Equal(t, "The size of the payloads", msb.MetricSet.Metrics[0].Description)
+}
+
 func TestMetricsFlusherFlushInBatchWithinBucket(t *testing.T) {
 	t.Parallel()
 
@@ -81,7 +103,7 @@ func TestMetricsFlusherFlushInBatchWithinBucket(t *testing.T) {
 		require.Len(t, sinks, tc.series)
 
 		bq.Push([]timeBucket{{Time: 1, Sinks: sinks}})
-		err := mf.flush()
+		err := mf.flush(context.Background())
 		require.NoError(t, err)
 		assert.Equal(t, tc.expFlushCalls, pm.timesCalled())
 	}
@@ -131,7 +153,7 @@ func TestMetricsFlusherFlushInBatchAcrossBuckets(t *testing.T) {
 		}
 		require.Len(t, bq.buckets, tc.series)
 
-		err := mf.flush()
+		err := mf.flush(context.Background())
 		require.NoError(t, err)
 		assert.Equal(t, tc.expPushCalls, pm.timesCalled())
 	}
@@ -192,7 +214,7 @@ func TestFlushWithReservedLabels(t *testing.T) {
 		},
 	})
 
-	err := mf.flush()
+	err := mf.flush(context.Background())
 	require.NoError(t, err)
 
 	loglines := hook.Drain()
@@ -282,7 +304,7 @@ func TestFlushMaxSeriesInBatch(t *testing.T) {
 			},
 		},
 	})
-	err := mf.flush()
+	err := mf.flush(context.Background())
 	require.NoError(t, err)
 
 	require.Len(t, collected, 2)
@@ -317,7 +339,7 @@ func (pm *pusherMock) timesCalled() int {
 	return int(atomic.LoadInt64(&pm.pushCalled))
 }
 
-func (pm *pusherMock) push(ms *pbcloud.MetricSet) error {
+func (pm *pusherMock) push(_ context.Context, ms *pbcloud.MetricSet) error {
 	if pm.hook != nil {
 		pm.hook(ms)
 	}
@@ -373,7 +395,7 @@ func TestMetricsFlusherErrorCase(t *testing.T) {
 	}
 	require.Len(t, bq.buckets, series)
 
-	err := mf.flush()
+	err := mf.flush(context.Background())
 	require.Error(t, err)
 	// since the push happens concurrently the number of the calls could vary,
 	// but at least one call should happen and it should be less than the
@@ -381,3 +403,213 @@ func TestMetricsFlusherErrorCase(t *testing.T) {
 	assert.LessOrEqual(t, pm.timesCalled(), mf.batchPushConcurrency)
 	assert.GreaterOrEqual(t, pm.timesCalled(), 1)
 }
+
+func TestMetricSetBuilderAddTrendTimeSeries(t *testing.T) {
+	t.Parallel()
+
+	r := metrics.NewRegistry()
+	m1 := r.MustNewMetric("http_req_duration", metrics.Trend)
+	timeSeries := metrics.TimeSeries{
+		Metric: m1,
+		Tags:   r.RootTagSet().With("key1", "val1"),
+	}
+
+	h1 := newHistogram()
+	h1.Add(3.14)
+	h1.Add(42)
+
+	h2 := newHistogram()
+	h2.Add(7)
+
+	msb := newMetricSetBuilder("testrunid-123", 1)
+	msb.addTimeSeries(int64(time.Second), timeSeries, h1)
+	msb.addTimeSeries(int64(2*time.Second), timeSeries, h2)
+
+	require.Len(t, msb.MetricSet.Metrics, 1)
+	pbmetric := msb.MetricSet.Metrics[0]
+	assert.Equal(t, pbcloud.MetricType_METRIC_TYPE_TREND, pbmetric.Type)
+	require.Len(t, pbmetric.TimeSeries, 1)
+
+	samples := pbmetric.TimeSeries[0].GetTrendHdrSamples()
+	require.NotNil(t, samples)
+	require.Len(t, samples.Values, 2)
+
+	assert.Equal(t, histogramAsProto(h1, int64(time.Second)), samples.Values[0])
+	assert.Equal(t, uint32(2), samples.Values[0].Count)
+	assert.Equal(t, 3.14, samples.Values[0].MinValue)
+	assert.Equal(t, 42.0, samples.Values[0].MaxValue)
+
+	assert.Equal(t, histogramAsProto(h2, int64(2*time.Second)), samples.Values[1])
+	assert.Equal(t, int64(2), samples.Values[1].Time.Seconds)
+}
+
+func TestMetricsFlusherRequeueOnRetryableError(t *testing.T) {
+	t.Parallel()
+
+	r := metrics.NewRegistry()
+	m1 := r.MustNewMetric("metric1", metrics.Counter)
+
+	var failing atomic.Bool
+	failing.Store(true)
+
+	var collected []*pbcloud.MetricSet
+	bq := &bucketQ{}
+	pm := &pusherMock{
+		hook: func(ms *pbcloud.MetricSet) {
+			if !failing.Load() {
+				collected = append(collected, ms)
+			}
+		},
+		errFn: func() error {
+			if failing.Load() {
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+	}
+	mf := metricsFlusher{
+		bq:                   bq,
+		client:               pm,
+		logger:               testutils.NewLogger(t),
+		discardedLabels:      make(map[string]struct{}),
+		maxSeriesInBatch:     3,
+		batchPushConcurrency: 1,
+	}
+
+	ts := metrics.TimeSeries{
+		Metric: m1,
+		Tags:   r.RootTagSet().With("key1", "val1"),
+	}
+	bq.Push([]timeBucket{{Time: 1, Sinks: map[metrics.TimeSeries]metricValue{ts: &counter{Sum: 1}}}})
+
+	require.Error(t, mf.flush(context.Background()))
+	require.Len(t, mf.requeued, 1)
+
+	// the next flush pushes the requeued batch, even if no new bucket is available
+	failing.Store(false)
+	require.NoError(t, mf.flush(context.Background()))
+	assert.Empty(t, mf.requeued)
+	require.Len(t, collected, 1)
+	assert.Equal(t, "metric1", collected[0].Metrics[0].Name)
+}
+
+func TestMetricsFlusherRequeueLimit(t *testing.T) {
+	t.Parallel()
+
+	logger, hook := testutils.NewLoggerWithHook(t)
+	mf := metricsFlusher{logger: logger}
+
+	batches := make([]*pbcloud.MetricSet, maxRequeuedBatches+2)
+	for i := range batches {
+		batches[i] = &pbcloud.MetricSet{TestRunId: strconv.Itoa(i)}
+	}
+	mf.requeue(batches)
+
+	require.Len(t, mf.requeued, maxRequeuedBatches)
+	assert.Equal(t, "2", mf.requeued[0].TestRunId)
+	assert.Len(t, testutils.FilterEntries(hook.Drain(), logrus.WarnLevel,
+		"Dropped the oldest metrics batches, too many failed pushes are waiting to be retried"), 1)
+}
+
+func TestMetricsFlusherSplitBySize(t *testing.T) {
+	t.Parallel()
+
+	r := metrics.NewRegistry()
+	m1 := r.MustNewMetric("metric1", metrics.Counter)
+	m2 := r.MustNewMetric("metric2", metrics.Gauge)
+
+	msb := newMetricSetBuilder("testrunid-123", 3)
+	for i := 0; i < 4; i++ {
+		msb.addTimeSeries(1, metrics.TimeSeries{
+			Metric: m1,
+			Tags:   r.RootTagSet().With("key1", "val"+strconv.Itoa(i)),
+		}, &counter{Sum: 1})
+		msb.addTimeSeries(1, metrics.TimeSeries{
+			Metric: m2,
+			Tags:   r.RootTagSet().With("key1", "val"+strconv.Itoa(i)),
+		}, &gauge{Last: 1})
+	}
+
+	mf := metricsFlusher{}
+	assert.Len(t, mf.splitBySize(msb.MetricSet), 1, "no limit")
+
+	mf.maxBatchPayloadSize = proto.Size(msb.MetricSet)
+	assert.Len(t, mf.splitBySize(msb.MetricSet), 1, "it fits the limit")
+
+	mf.maxBatchPayloadSize = proto.Size(msb.MetricSet) / 2
+	chunks := mf.splitBySize(msb.MetricSet)
+	require.Greater(t, len(chunks), 1)
+
+	series := 0
+	for _, chunk := range chunks {
+		assert.LessOrEqual(t, proto.Size(chunk), mf.maxBatchPayloadSize)
+		assert.Equal(t, "testrunid-123", chunk.TestRunId)
+		assert.Equal(t, uint32(3), chunk.AggregationPeriod)
+		for _, m := range chunk.Metrics {
+			series += len(m.TimeSeries)
+		}
+	}
+	assert.Equal(t, 8, series)
+
+	// a single time series can't be split further
+	mf.maxBatchPayloadSize = 1
+	chunks = mf.splitBySize(msb.MetricSet)
+	assert.Len(t, chunks, 8)
+}
+
+func TestMetricsFlusherReportsDroppedBuckets(t *testing.T) {
+	t.Parallel()
+
+	logger, hook := testutils.NewLoggerWithHook(t)
+
+	bq := &bucketQ{limit: 1}
+	mf := metricsFlusher{
+		bq:                   bq,
+		client:               &pusherMock{},
+		logger:               logger,
+		discardedLabels:      make(map[string]struct{}),
+		maxSeriesInBatch:     3,
+		batchPushConcurrency: 1,
+		stats:                &stats{},
+	}
+
+	bq.Push([]timeBucket{{Time: 1}, {Time: 2}})
+	require.NoError(t, mf.flush(context.Background()))
+
+	assert.Len(t, testutils.FilterEntries(hook.Drain(), logrus.WarnLevel,
+		"Dropped the oldest aggregated metrics, the push queue is full because the flushing is falling behind"), 1)
+	assert.Equal(t, int64(1), mf.stats.bucketsDropped.Load())
+	assert.Equal(t, int64(1), mf.stats.pushQueueDepth.Load())
+	assert.Equal(t, int64(1), mf.stats.pushQueueMaxDepth.Load())
+}
+
+func BenchmarkMetricSetBuilderAddTimeSeries(b *testing.B) {
+	r := metrics.NewRegistry()
+	m1 := r.MustNewMetric("metric1", metrics.Counter)
+
+	series := make([]metrics.TimeSeries, 20000)
+	for i := 0; i < len(series); i++ {
+		series[i] = metrics.TimeSeries{
+			Metric: m1,
+			Tags:   r.RootTagSet().With("key1", "val"+strconv.Itoa(i)).With("key2", "val2"),
+		}
+	}
+
+	for _, withCache := range []bool{false, true} {
+		var labels *labelsCache
+		if withCache {
+			labels = newLabelsCache()
+		}
+		b.Run("labels-cache-"+strconv.FormatBool(withCache), func(b *testing.B) {
+			b.ReportAllocs()
+			msb := newMetricSetBuilder("testrunid-123", 1)
+			msb.labels = labels
+			for i := 0; i < b.N; i++ {
+				for _, ts := range series {
+					msb.addTimeSeries(1, ts, &counter{Sum: 1})
+				}
+				msb.reset()
+			}
+		})
+	}
+}
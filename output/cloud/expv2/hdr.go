@@ -14,13 +14,8 @@ const (
 	// supporting floating points up to 3 digits.
 	defaultMinimumResolution = .001
 
-	// lowestTrackable represents the minimum value that the histogram tracks.
-	// Essentially, it excludes negative numbers.
-	// Most of metrics tracked by histograms are durations
-	// where we don't expect negative numbers.
-	//
-	// In the future, we may expand and include them,
-	// probably after https://github.com/grafana/k6/issues/763.
+	// lowestTrackable represents the minimum absolute value that the histogram tracks.
+	// Negative values are tracked in a dedicated set of buckets, mirroring the positive ones.
 	lowestTrackable = 0
 )
 
@@ -41,8 +36,16 @@ type histogram struct {
 	// because they contain exception cases and require to be tracked in a dedicated way.
 	Buckets map[uint32]uint32
 
+	// NegativeBuckets stores the counters for the negative values.
+	// They are counted in the bucket of their absolute value,
+	// mirroring the positive buckets.
+	// It is allocated only when the first negative value is observed,
+	// since most of the metrics tracked by histograms are durations
+	// where we don't expect negative numbers.
+	NegativeBuckets map[uint32]uint32
+
 	// ExtraLowBucket counts occurrences of observed values smaller
-	// than the minimum trackable value.
+	// than the negated maximum trackable value.
 	ExtraLowBucket uint32
 
 	// ExtraLowBucket counts occurrences of observed values bigger
@@ -67,18 +70,24 @@ type histogram struct {
 }
 
 func newHistogram() *histogram {
+	return newHistogramWithResolution(defaultMinimumResolution)
+}
+
+// newHistogramWithResolution returns a new histogram
+// tracking the values with the provided minimum resolution.
+func newHistogramWithResolution(minResolution float64) *histogram {
 	return &histogram{
-		MinimumResolution: defaultMinimumResolution,
+		MinimumResolution: minResolution,
 		Buckets:           make(map[uint32]uint32),
 		Max:               -math.MaxFloat64,
 		Min:               math.MaxFloat64,
 	}
 }
 
-// addToBucket increments the counter of the bucket of the provided value.
+// addToBucket increments by n the counter of the bucket of the provided value.
 // If the value is lower or higher than the trackable limits
 // then it is counted into specific buckets. All the stats are also updated accordingly.
-func (h *histogram) addToBucket(v float64) {
+func (h *histogram) addToBucket(v float64, n uint32) {
 	if v > h.Max {
 		h.Max = v
 	}
@@ -86,25 +95,63 @@ func (h *histogram) addToBucket(v float64) {
 		h.Min = v
 	}
 
-	h.Count++
-	h.Sum += v
+	h.Count += n
+	h.Sum += v * float64(n)
 
 	v /= h.MinimumResolution
 
-	if v < lowestTrackable {
-		h.ExtraLowBucket++
+	if v < -math.MaxInt64 {
+		h.ExtraLowBucket += n
 		return
 	}
 	if v > math.MaxInt64 {
-		h.ExtraHighBucket++
+		h.ExtraHighBucket += n
 		return
 	}
 
-	h.Buckets[resolveBucketIndex(v)]++
+	if v < lowestTrackable {
+		if h.NegativeBuckets == nil {
+			h.NegativeBuckets = make(map[uint32]uint32)
+		}
+		h.NegativeBuckets[resolveBucketIndex(-v)] += n
+		return
+	}
+
+	h.Buckets[resolveBucketIndex(v)] += n
 }
 
 // histogramAsProto converts the histogram into the equivalent Protobuf version.
 func histogramAsProto(h *histogram, time int64) *pbcloud.TrendHdrValue {
+	counters, spans := bucketsAsProto(h.Buckets)
+	negativeCounters, negativeSpans := bucketsAsProto(h.NegativeBuckets)
+
+	hval := &pbcloud.TrendHdrValue{
+		Time:     timestampAsProto(time),
+		MinValue: h.Min,
+		MaxValue: h.Max,
+		Sum:      h.Sum,
+		Count:    h.Count,
+		Counters: counters,
+		Spans:    spans,
+
+		NegativeCounters: negativeCounters,
+		NegativeSpans:    negativeSpans,
+	}
+	if h.ExtraLowBucket > 0 {
+		hval.ExtraLowValuesCounter = &h.ExtraLowBucket
+	}
+	if h.ExtraHighBucket > 0 {
+		hval.ExtraHighValuesCounter = &h.ExtraHighBucket
+	}
+	// We don't expect to change the minimum resolution at runtime
+	// so it is safe use directly a pointer without creating a copy
+	hval.MinResolution = &h.MinimumResolution
+	return hval
+}
+
+// bucketsAsProto converts the buckets into the equivalent Protobuf
+// counters and spans, in the ascending order of their indexes.
+func bucketsAsProto(buckets map[uint32]uint32) ([]uint32, []*pbcloud.BucketSpan) {
 	var (
 		indexes  []uint32
 		counters []uint32
@@ -113,9 +160,9 @@ func histogramAsProto(h *histogram, time int64) *pbcloud.TrendHdrValue {
 
 	// allocate only if at least one item is available, in the case of only
 	// untrackable values, then Indexes and Buckets are expected to be empty.
-	if len(h.Buckets) > 0 {
-		indexes = make([]uint32, 0, len(h.Buckets))
-		for index := range h.Buckets {
+	if len(buckets) > 0 {
+		indexes = make([]uint32, 0, len(buckets))
+		for index := range buckets {
 			indexes = append(indexes, index)
 		}
 		sort.Slice(indexes, func(i, j int) bool {
@@ -123,14 +170,14 @@ func histogramAsProto(h *histogram, time int64) *pbcloud.TrendHdrValue {
 		})
 
 		// init the counters
-		counters = make([]uint32, 1, len(h.Buckets))
-		counters[0] = h.Buckets[indexes[0]]
+		counters = make([]uint32, 1, len(buckets))
+		counters[0] = buckets[indexes[0]]
 		// open the first span
 		spans = append(spans, &pbcloud.BucketSpan{Offset: indexes[0], Length: 1})
 	}
 
 	for i := 1; i < len(indexes); i++ {
-		counters = append(counters, h.Buckets[indexes[i]])
+		counters = append(counters, buckets[indexes[i]])
 
 		// if the current and the previous indexes are not consecutive
 		// consider as closed the current on-going span and start a new one.
@@ -142,25 +189,7 @@ func histogramAsProto(h *histogram, time int64) *pbcloud.TrendHdrValue {
 		spans[len(spans)-1].Length++
 	}
 
-	hval := &pbcloud.TrendHdrValue{
-		Time:     timestampAsProto(time),
-		MinValue: h.Min,
-		MaxValue: h.Max,
-		Sum:      h.Sum,
-		Count:    h.Count,
-		Counters: counters,
-		Spans:    spans,
-	}
-	if h.ExtraLowBucket > 0 {
-		hval.ExtraLowValuesCounter = &h.ExtraLowBucket
-	}
-	if h.ExtraHighBucket > 0 {
-		hval.ExtraHighValuesCounter = &h.ExtraHighBucket
-	}
-	// We don't expect to change the minimum resolution at runtime
-	// so it is safe use directly a pointer without creating a copy
-	hval.MinResolution = &h.MinimumResolution
-	return hval
+	return counters, spans
 }
 
 // resolveBucketIndex returns the index
@@ -224,5 +253,10 @@ func resolveBucketIndex(val float64) uint32 {
 
 // Add implements the metricValue interface.
 func (h *histogram) Add(v float64) {
-	h.addToBucket(v)
+	h.addToBucket(v, 1)
+}
+
+// AddWeighted implements the metricValue interface.
+func (h *histogram) AddWeighted(v float64, n uint32) {
+	h.addToBucket(v, n)
 }
@@ -145,7 +145,7 @@ func TestHistogramAddWithUntrackables(t *testing.T) {
 
 	h := newHistogram()
 	h.MinimumResolution = 1.0
-	for _, v := range []float64{5, -3.14, math.MaxInt64 + 3239, 1} {
+	for _, v := range []float64{5, -math.MaxInt64 - 3239, math.MaxInt64 + 3239, 1} {
 		h.Add(v)
 	}
 
@@ -154,8 +154,8 @@ func TestHistogramAddWithUntrackables(t *testing.T) {
 		ExtraLowBucket:    1,
 		ExtraHighBucket:   1,
 		Max:               9223372036854779046,
-		Min:               -3.14,
-		Sum:               math.MaxInt64 + 3239 + 5 + 1 - 3.14,
+		Min:               -9223372036854779046,
+		Sum:               1,
 		Count:             4,
 		MinimumResolution: 1.0,
 	}
@@ -195,7 +195,8 @@ func TestHistogramAddWithNegativeNum(t *testing.T) {
 		Max:               -2.42314,
 		Min:               -2.42314,
 		Buckets:           map[uint32]uint32{},
-		ExtraLowBucket:    1,
+		NegativeBuckets:   map[uint32]uint32{3: 1},
+		ExtraLowBucket:    0,
 		ExtraHighBucket:   0,
 		Sum:               -2.42314,
 		Count:             1,
@@ -208,18 +209,19 @@ func TestHistogramAddWithMultipleNegativeNums(t *testing.T) {
 	t.Parallel()
 	h := newHistogram()
 	h.MinimumResolution = 1.0
-	for _, v := range []float64{-0.001, -0.001, -0.001} {
+	for _, v := range []float64{-0.001, -0.001, -0.001, -3, -260} {
 		h.Add(v)
 	}
 
 	exp := &histogram{
 		Buckets:           map[uint32]uint32{},
-		ExtraLowBucket:    3,
+		NegativeBuckets:   map[uint32]uint32{1: 3, 3: 1, 258: 1},
+		ExtraLowBucket:    0,
 		ExtraHighBucket:   0,
 		Max:               -0.001,
-		Min:               -0.001,
-		Sum:               -0.003,
-		Count:             3,
+		Min:               -260,
+		Sum:               -263.003,
+		Count:             5,
 		MinimumResolution: 1.0,
 	}
 	h.MinimumResolution = 1.0
@@ -284,16 +286,34 @@ func TestHistogramAsProto(t *testing.T) {
 		},
 		{
 			name: "UntrackableValues",
-			vals: []float64{-0.23, 1<<64 - 1},
+			vals: []float64{-(1<<64 - 1), 1<<64 - 1},
 			exp: &pbcloud.TrendHdrValue{
 				ExtraLowValuesCounter:  uint32ptr(1),
 				ExtraHighValuesCounter: uint32ptr(1),
 				Counters:               nil,
 				Spans:                  nil,
 				Count:                  2,
-				MinValue:               -0.23,
+				MinValue:               -(1<<64 - 1),
 				MaxValue:               1<<64 - 1,
-				Sum:                    (1 << 64) - 1 - 0.23,
+				Sum:                    0,
+			},
+		},
+		{
+			name: "NegativeValues",
+			vals: []float64{-0.23, -7, -8, 2, -10.5},
+			exp: &pbcloud.TrendHdrValue{
+				Count:            5,
+				Counters:         []uint32{1},
+				Spans:            []*pbcloud.BucketSpan{{Offset: 2, Length: 1}},
+				NegativeCounters: []uint32{1, 1, 1, 1},
+				NegativeSpans: []*pbcloud.BucketSpan{
+					{Offset: 1, Length: 1},
+					{Offset: 5, Length: 2},
+					{Offset: 2, Length: 1},
+				},
+				MinValue: -10.5,
+				MaxValue: 2,
+				Sum:      -23.73,
 			},
 		},
 		{
@@ -39,7 +39,103 @@ func mapTimeSeriesLabelsProto(tags *metrics.TagSet) ([]*pbcloud.Label, []string)
 	return labels, discardedLabels
 }
 
+// maxCachedLabelSets is the max number of tag sets
+// the labelsCache holds before it gets reset.
+const maxCachedLabelSets = 100_000
+
+type cachedLabels struct {
+	labels          []*pbcloud.Label
+	discardedLabels []string
+}
+
+// labelsCache caches the mapping of the tag sets into protobuf labels
+// across the flushes, so the labels of the active time series are allocated only once.
+//
+// It is safe to share the cached labels between the MetricSets
+// because tag sets are immutable and the labels are never modified
+// after they are mapped. It is not expected to be used concurrently.
+type labelsCache struct {
+	entries map[*metrics.TagSet]cachedLabels
+}
+
+func newLabelsCache() *labelsCache {
+	return &labelsCache{entries: make(map[*metrics.TagSet]cachedLabels)}
+}
+
+// get returns the labels mapped from the tag set,
+// it maps and caches them if they are not cached yet.
+func (lc *labelsCache) get(tags *metrics.TagSet) ([]*pbcloud.Label, []string) {
+	if entry, ok := lc.entries[tags]; ok {
+		return entry.labels, entry.discardedLabels
+	}
+
+	// the cache is reset when it grows too much,
+	// so it doesn't retain the tag sets of the no longer active series forever.
+	if len(lc.entries) >= maxCachedLabelSets {
+		lc.entries = make(map[*metrics.TagSet]cachedLabels)
+	}
+
+	labels, discardedLabels := mapTimeSeriesLabelsProto(tags)
+	lc.entries[tags] = cachedLabels{labels: labels, discardedLabels: discardedLabels}
+	return labels, discardedLabels
+}
+
+// labelDictionaryEncode returns a copy of the MetricSet where the labels
+// of the time series are replaced by references to a dictionary of strings,
+// so the repeated names and values are sent only once per payload.
+//
+// The samples are shared with the original MetricSet,
+// which is not modified so it can be pushed again.
+func labelDictionaryEncode(ms *pbcloud.MetricSet) *pbcloud.MetricSet {
+	var (
+		dictionary []string
+		indexes    = make(map[string]uint32)
+	)
+	ref := func(s string) uint32 {
+		ix, ok := indexes[s]
+		if !ok {
+			ix = uint32(len(dictionary))
+			indexes[s] = ix
+			dictionary = append(dictionary, s)
+		}
+		return ix
+	}
+
+	encoded := &pbcloud.MetricSet{
+		TestRunId:         ms.TestRunId,
+		AggregationPeriod: ms.AggregationPeriod,
+		Metrics:           make([]*pbcloud.Metric, 0, len(ms.Metrics)),
+	}
+	for _, m := range ms.Metrics {
+		em := &pbcloud.Metric{
+			Name:        m.Name,
+			Type:        m.Type,
+			Unit:        m.Unit,
+			Description: m.Description,
+			TimeSeries:  make([]*pbcloud.TimeSeries, 0, len(m.TimeSeries)),
+		}
+		for _, ts := range m.TimeSeries {
+			refs := make([]uint32, 0, len(ts.Labels)*2)
+			for _, l := range ts.Labels {
+				refs = append(refs, ref(l.Name), ref(l.Value))
+			}
+			em.TimeSeries = append(em.TimeSeries, &pbcloud.TimeSeries{
+				LabelRefs: refs,
+				Samples:   ts.Samples,
+			})
+		}
+		encoded.Metrics = append(encoded.Metrics, em)
+	}
+	encoded.LabelDictionary = dictionary
+	return encoded
+}
+
 func isReservedLabelName(name string) bool {
+	// the overflow label is reserved but it is set by the output itself
+	if name == overflowLabelName {
+		return false
+	}
+
 	// this is a reserved label prefix for the prometheus
 	if strings.HasPrefix(name, "__") {
 		return true
@@ -58,7 +154,7 @@ func mapMetricTypeProto(mt metrics.MetricType) pbcloud.MetricType {
 		mtype = pbcloud.MetricType_METRIC_TYPE_GAUGE
 	case metrics.Rate:
 		mtype = pbcloud.MetricType_METRIC_TYPE_RATE
-	case metrics.Trend:
+	case metrics.Trend, metrics.Histogram:
 		mtype = pbcloud.MetricType_METRIC_TYPE_TREND
 	}
 	return mtype
@@ -122,7 +218,7 @@ func initTimeSeriesSamples(timeSeries *pbcloud.TimeSeries, mt metrics.MetricType
 		timeSeries.Samples = &pbcloud.TimeSeries_RateSamples{
 			RateSamples: &pbcloud.RateSamples{},
 		}
-	case metrics.Trend:
+	case metrics.Trend, metrics.Histogram:
 		timeSeries.Samples = &pbcloud.TimeSeries_TrendHdrSamples{
 			TrendHdrSamples: &pbcloud.TrendHdrSamples{},
 		}
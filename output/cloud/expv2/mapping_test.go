@@ -5,6 +5,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/output/cloud/expv2/pbcloud"
 )
 
 func TestTimestampAsProto(t *testing.T) {
@@ -19,3 +22,69 @@ func TestTimestampAsProto(t *testing.T) {
 	timestamp = timestampAsProto(date.UnixNano())
 	assert.Equal(t, time.Unix(10, 0).UTC(), timestamp.AsTime())
 }
+
+func TestLabelsCacheGet(t *testing.T) {
+	t.Parallel()
+
+	r := metrics.NewRegistry()
+	tags := r.RootTagSet().With("key1", "val1").With("__name", "reserved")
+
+	lc := newLabelsCache()
+	labels, discarded := lc.get(tags)
+	require.Len(t, labels, 1)
+	assert.Equal(t, "key1", labels[0].Name)
+	assert.Equal(t, "val1", labels[0].Value)
+	assert.Equal(t, []string{"__name"}, discarded)
+
+	// the same tag set returns the same cached labels
+	cached, _ := lc.get(tags)
+	assert.Same(t, labels[0], cached[0])
+	assert.Len(t, lc.entries, 1)
+}
+
+func TestLabelDictionaryEncode(t *testing.T) {
+	t.Parallel()
+
+	samples := &pbcloud.TimeSeries_CounterSamples{CounterSamples: &pbcloud.CounterSamples{}}
+	ms := &pbcloud.MetricSet{
+		TestRunId:         "test-run-id",
+		AggregationPeriod: 3,
+		Metrics: []*pbcloud.Metric{
+			{
+				Name:        "metric1",
+				Type:        pbcloud.MetricType_METRIC_TYPE_COUNTER,
+				Unit:        "bytes",
+				Description: "The sent data",
+				TimeSeries: []*pbcloud.TimeSeries{
+					{
+						Labels:  []*pbcloud.Label{{Name: "key1", Value: "val1"}, {Name: "key2", Value: "val1"}},
+						Samples: samples,
+					},
+					{
+						Labels:  []*pbcloud.Label{{Name: "key1", Value: "val2"}},
+						Samples: samples,
+					},
+				},
+			},
+		},
+	}
+
+	encoded := labelDictionaryEncode(ms)
+	assert.Equal(t, "test-run-id", encoded.TestRunId)
+	assert.Equal(t, uint32(3), encoded.AggregationPeriod)
+	assert.Equal(t, []string{"key1", "val1", "key2", "val2"}, encoded.LabelDictionary)
+
+	require.Len(t, encoded.Metrics, 1)
+	assert.Equal(t, "bytes", encoded.Metrics[0].Unit)
+	assert.Equal(t, "The sent data", encoded.Metrics[0].Description)
+	series := encoded.Metrics[0].TimeSeries
+	require.Len(t, series, 2)
+	assert.Empty(t, series[0].Labels)
+	assert.Equal(t, []uint32{0, 1, 2, 1}, series[0].LabelRefs)
+	assert.Equal(t, []uint32{0, 3}, series[1].LabelRefs)
+	assert.Same(t, samples, series[0].Samples)
+
+	// the original MetricSet is not modified
+	assert.Len(t, ms.Metrics[0].TimeSeries[0].Labels, 2)
+	assert.Empty(t, ms.LabelDictionary)
+}
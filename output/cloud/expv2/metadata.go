@@ -0,0 +1,56 @@
+package expv2
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/consts"
+	"go.k6.io/k6/output/cloud/expv2/pbcloud"
+)
+
+// testRunMetadata holds the test run's details pushed to the cloud
+// at the start of the test, so it can render the thresholds and
+// the scenarios without depending on the v1 test run creation.
+type testRunMetadata struct {
+	options    lib.Options
+	thresholds map[string][]string
+}
+
+// asProto converts the metadata into its Protobuf message.
+func (md testRunMetadata) asProto() (*pbcloud.TestRunMetadata, error) {
+	// The options are converted passing through their JSON encoding
+	// so the JSON names and the custom marshalers are respected.
+	b, err := json.Marshal(md.options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the options: %w", err)
+	}
+	var options map[string]any
+	if err := json.Unmarshal(b, &options); err != nil {
+		return nil, fmt.Errorf("failed to decode the options: %w", err)
+	}
+	optionsStruct, err := structpb.NewStruct(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert the options: %w", err)
+	}
+
+	metricNames := make([]string, 0, len(md.thresholds))
+	for name := range md.thresholds {
+		metricNames = append(metricNames, name)
+	}
+	sort.Strings(metricNames)
+
+	thresholds := make([]*pbcloud.Threshold, 0, len(metricNames))
+	for _, name := range metricNames {
+		thresholds = append(thresholds, &pbcloud.Threshold{Metric: name, Sources: md.thresholds[name]})
+	}
+
+	return &pbcloud.TestRunMetadata{
+		K6Version:  consts.Version,
+		Options:    optionsStruct,
+		Thresholds: thresholds,
+	}, nil
+}
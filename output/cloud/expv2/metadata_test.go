@@ -0,0 +1,36 @@
+package expv2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/consts"
+)
+
+func TestTestRunMetadataAsProto(t *testing.T) {
+	t.Parallel()
+
+	md := testRunMetadata{
+		options: lib.Options{VUs: null.IntFrom(10)},
+		thresholds: map[string][]string{
+			"http_reqs":         {"count>0"},
+			"http_req_duration": {"p(95)<100", "avg<50"},
+		},
+	}
+
+	pb, err := md.asProto()
+	require.NoError(t, err)
+
+	assert.Equal(t, consts.Version, pb.K6Version)
+	assert.Equal(t, float64(10), pb.Options.AsMap()["vus"])
+
+	require.Len(t, pb.Thresholds, 2)
+	assert.Equal(t, "http_req_duration", pb.Thresholds[0].Metric)
+	assert.Equal(t, []string{"p(95)<100", "avg<50"}, pb.Thresholds[0].Sources)
+	assert.Equal(t, "http_reqs", pb.Thresholds[1].Metric)
+	assert.Equal(t, []string{"count>0"}, pb.Thresholds[1].Sources)
+}
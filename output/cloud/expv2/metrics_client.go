@@ -6,26 +6,91 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"strings"
+	"time"
 
-	"github.com/klauspost/compress/snappy"
 	"google.golang.org/protobuf/proto"
 
 	"go.k6.io/k6/cloudapi"
 	"go.k6.io/k6/output/cloud/expv2/pbcloud"
 )
 
+// The versions of the protocol the payloads are encoded with.
+// Each version extends the previous one, a payload is sent
+// with the lowest version supporting all the features it uses.
+const (
+	protocolVersionBase = "2.0"
+
+	// protocolVersionLabelDictionary references the labels
+	// from the dictionary of the MetricSet.
+	protocolVersionLabelDictionary = "2.1"
+
+	// protocolVersionNegativeValues counts the trends' negative
+	// values in the negative buckets of the histograms.
+	protocolVersionNegativeValues = "2.2"
+)
+
 // metricsClient is a Protobuf over HTTP client for sending
 // the collected metrics from the Cloud output
 // to the remote service.
 type metricsClient struct {
 	httpClient *cloudapi.Client
 	url        string
+	retry      retryPolicy
+	encoder    payloadEncoder
+
+	// labelDictionary enables the labels' dictionary encoding
+	// supported since the protocol version 2.1.
+	labelDictionary bool
+
+	// stats is optional, if set it tracks the pushed bytes and the failed pushes.
+	stats *stats
+}
+
+// retryPolicy defines how a failed push is retried.
+type retryPolicy struct {
+	// maxAttempts is the max number of attempts, including the first one.
+	maxAttempts int
+
+	// interval is the base interval to wait before the first retry.
+	// It doubles for each subsequent attempt.
+	interval time.Duration
+
+	// maxElapsedTime is the max time spent retrying before giving up.
+	// Zero means no limit.
+	maxElapsedTime time.Duration
+}
+
+// backoff returns the time to wait before retrying
+// after the provided, 1-based, failed attempt.
+//
+// The wait grows exponentially and a jitter of +/-50% is applied,
+// so concurrent pushes failing at the same time don't retry in lockstep.
+func (rp retryPolicy) backoff(attempt int) time.Duration {
+	if rp.interval <= 0 {
+		return 0
+	}
+
+	// cap the exponent for avoiding to overflow
+	if attempt > 16 {
+		attempt = 16
+	}
+	d := rp.interval << (attempt - 1)
+
+	//nolint:gosec // we don't need cryptographic randomness for the jitter
+	return d/2 + time.Duration(rand.Int63n(int64(d)))
 }
 
 // newMetricsClient creates and initializes a new MetricsClient.
-func newMetricsClient(c *cloudapi.Client, testRunID string) (*metricsClient, error) {
+// The payloads are compressed using the provided Content-Encoding.
+//
+// The metrics client implements its own retry strategy,
+// so retrying is disabled on the provided cloudapi.Client.
+func newMetricsClient(
+	c *cloudapi.Client, testRunID string, retry retryPolicy, encoding string,
+) (*metricsClient, error) {
 	// The cloudapi.Client works across different versions of the API, the test
 	// lifecycle management is under /v1 instead the metrics ingestion is /v2.
 	// Unfortunately, the current client has v1 hard-coded so we need to trim the wrong path
@@ -40,21 +105,75 @@ func newMetricsClient(c *cloudapi.Client, testRunID string) (*metricsClient, err
 	if testRunID == "" {
 		return nil, errors.New("TestRunID of the test is required")
 	}
+	encoder, err := newPayloadEncoder(encoding)
+	if err != nil {
+		return nil, err
+	}
+	if retry.maxAttempts < 1 {
+		retry.maxAttempts = 1
+	}
+	c.SetRetries(1, 0)
+	baseURL := strings.TrimSuffix(u, "/v1")
 	return &metricsClient{
 		httpClient: c,
-		url:        strings.TrimSuffix(u, "/v1") + "/v2/metrics/" + testRunID,
+		url:        baseURL + "/v2/metrics/" + testRunID,
+		retry:      retry,
+		encoder:    encoder,
 	}, nil
 }
 
 // Push the provided metrics for the given test run ID.
-func (mc *metricsClient) push(samples *pbcloud.MetricSet) error {
-	b, err := newRequestBody(samples)
+//
+// Pushes failing because of network errors or transient server-side
+// errors are retried according to the client's retry policy, until
+// the context is done.
+func (mc *metricsClient) push(ctx context.Context, samples *pbcloud.MetricSet) error {
+	version := mc.protocolVersion(samples)
+	if mc.labelDictionary && samples != nil {
+		samples = labelDictionaryEncode(samples)
+	}
+	b, err := newRequestBody(samples, mc.encoder)
 	if err != nil {
 		return err
 	}
 
+	err = mc.sendWithRetry(ctx, b, version)
+	if mc.stats != nil {
+		if err != nil {
+			mc.stats.pushesFailed.Add(1)
+		} else {
+			mc.stats.bytesPushed.Add(int64(len(b)))
+		}
+	}
+	return err
+}
+
+func (mc *metricsClient) sendWithRetry(ctx context.Context, b []byte, version string) error {
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := mc.send(ctx, b, version)
+		if err == nil || !isRetryableError(err) || attempt >= mc.retry.maxAttempts {
+			return err
+		}
+
+		wait := mc.retry.backoff(attempt)
+		if mc.retry.maxElapsedTime > 0 && time.Since(start)+wait > mc.retry.maxElapsedTime {
+			return err
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		}
+	}
+}
+
+func (mc *metricsClient) send(ctx context.Context, b []byte, version string) error {
 	req, err := http.NewRequestWithContext(
-		context.Background(), http.MethodPost, mc.url, io.NopCloser(bytes.NewReader(b)))
+		ctx, http.MethodPost, mc.url, io.NopCloser(bytes.NewReader(b)))
 	if err != nil {
 		return err
 	}
@@ -64,29 +183,58 @@ func (mc *metricsClient) push(samples *pbcloud.MetricSet) error {
 	}
 
 	req.Header.Set("Content-Type", "application/x-protobuf")
-	req.Header.Set("Content-Encoding", "snappy")
-	req.Header.Set("K6-Metrics-Protocol-Version", "2.0")
+	req.Header.Set("Content-Encoding", mc.encoder.Name())
+	req.Header.Set("K6-Metrics-Protocol-Version", version)
 
-	err = mc.httpClient.Do(req, nil)
-	if err != nil {
-		return err
+	return mc.httpClient.Do(req, nil)
+}
+
+// protocolVersion returns the version of the protocol
+// the provided payload is encoded with.
+func (mc *metricsClient) protocolVersion(samples *pbcloud.MetricSet) string {
+	if hasNegativeValues(samples) {
+		return protocolVersionNegativeValues
+	}
+	if mc.labelDictionary {
+		return protocolVersionLabelDictionary
+	}
+	return protocolVersionBase
+}
+
+// hasNegativeValues returns true if any trend
+// in the MetricSet has observed negative values.
+func hasNegativeValues(samples *pbcloud.MetricSet) bool {
+	for _, m := range samples.GetMetrics() {
+		if m.GetType() != pbcloud.MetricType_METRIC_TYPE_TREND {
+			continue
+		}
+		for _, ts := range m.GetTimeSeries() {
+			for _, v := range ts.GetTrendHdrSamples().GetValues() {
+				if len(v.GetNegativeCounters()) > 0 {
+					return true
+				}
+			}
+		}
 	}
+	return false
+}
 
-	return nil
+// isRetryableError returns true if the push failed because of a network error
+// or a transient server-side error, so it is worth to retry it.
+func isRetryableError(err error) bool {
+	var errResp cloudapi.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil {
+		return true
+	}
+
+	code := errResp.Response.StatusCode
+	return code >= http.StatusInternalServerError || code == http.StatusTooManyRequests
 }
 
-func newRequestBody(data *pbcloud.MetricSet) ([]byte, error) {
+func newRequestBody(data *pbcloud.MetricSet, encoder payloadEncoder) ([]byte, error) {
 	b, err := proto.Marshal(data)
 	if err != nil {
-		return nil, fmt.Errorf("encoding metrics as Protobuf write request failed: %w", err)
-	}
-	// TODO: use the framing format
-	// https://github.com/google/snappy/blob/main/framing_format.txt
-	// It can be done replacing the encode with
-	// https://pkg.go.dev/github.com/klauspost/compress/snappy#NewBufferedWriter
-	if snappy.MaxEncodedLen(len(b)) < 0 {
-		return nil, fmt.Errorf("the Protobuf message is too large to be handled by Snappy encoder; "+
-			"size: %d, limit: %d", len(b), 0xffffffff)
+		return nil, fmt.Errorf("encoding as Protobuf write request failed: %w", err)
 	}
-	return snappy.Encode(nil, b), nil
+	return encoder.Encode(b)
 }
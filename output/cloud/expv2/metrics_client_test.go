@@ -1,14 +1,19 @@
 package expv2
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/klauspost/compress/snappy"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
 	"go.k6.io/k6/cloudapi"
 	"go.k6.io/k6/output/cloud/expv2/pbcloud"
 )
@@ -36,15 +41,128 @@ func TestMetricsClientPush(t *testing.T) {
 	defer ts.Close()
 
 	c := cloudapi.NewClient(nil, "fake-token", ts.URL, "k6cloud/v0.4", 1*time.Second)
-	mc, err := newMetricsClient(c, "test-ref-id")
+	mc, err := newMetricsClient(c, "test-ref-id", retryPolicy{}, "")
 	require.NoError(t, err)
 
 	mset := pbcloud.MetricSet{}
-	err = mc.push(&mset)
+	err = mc.push(context.Background(), &mset)
 	require.NoError(t, err)
 	assert.Equal(t, 1, reqs)
 }
 
+func TestMetricsClientPushLabelDictionary(t *testing.T) {
+	t.Parallel()
+
+	var got pbcloud.MetricSet
+	h := func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2.1", r.Header.Get("K6-Metrics-Protocol-Version"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		b, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		require.NoError(t, proto.Unmarshal(b, &got))
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(h))
+	defer ts.Close()
+
+	c := cloudapi.NewClient(nil, "fake-token", ts.URL, "k6cloud/v0.4", 1*time.Second)
+	mc, err := newMetricsClient(c, "test-ref-id", retryPolicy{}, "")
+	require.NoError(t, err)
+	mc.labelDictionary = true
+
+	err = mc.push(context.Background(), &pbcloud.MetricSet{
+		Metrics: []*pbcloud.Metric{{
+			Name: "metric1",
+			Type: pbcloud.MetricType_METRIC_TYPE_COUNTER,
+			TimeSeries: []*pbcloud.TimeSeries{{
+				Labels: []*pbcloud.Label{{Name: "key1", Value: "val1"}},
+				Samples: &pbcloud.TimeSeries_CounterSamples{
+					CounterSamples: &pbcloud.CounterSamples{},
+				},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"key1", "val1"}, got.LabelDictionary)
+	require.Len(t, got.Metrics, 1)
+	require.Len(t, got.Metrics[0].TimeSeries, 1)
+	assert.Equal(t, []uint32{0, 1}, got.Metrics[0].TimeSeries[0].LabelRefs)
+}
+
+func TestMetricsClientPushNegativeValues(t *testing.T) {
+	t.Parallel()
+
+	versions := make(chan string, 2)
+	h := func(rw http.ResponseWriter, r *http.Request) {
+		versions <- r.Header.Get("K6-Metrics-Protocol-Version")
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(h))
+	defer ts.Close()
+
+	c := cloudapi.NewClient(nil, "fake-token", ts.URL, "k6cloud/v0.4", 1*time.Second)
+	mc, err := newMetricsClient(c, "test-ref-id", retryPolicy{}, "")
+	require.NoError(t, err)
+	mc.labelDictionary = true
+
+	trend := func(vals ...float64) *pbcloud.MetricSet {
+		h := newHistogram()
+		for _, v := range vals {
+			h.Add(v)
+		}
+		return &pbcloud.MetricSet{
+			Metrics: []*pbcloud.Metric{{
+				Name: "trend1",
+				Type: pbcloud.MetricType_METRIC_TYPE_TREND,
+				TimeSeries: []*pbcloud.TimeSeries{{
+					Samples: &pbcloud.TimeSeries_TrendHdrSamples{
+						TrendHdrSamples: &pbcloud.TrendHdrSamples{
+							Values: []*pbcloud.TrendHdrValue{histogramAsProto(h, 1)},
+						},
+					},
+				}},
+			}},
+		}
+	}
+
+	require.NoError(t, mc.push(context.Background(), trend(1, 2)))
+	assert.Equal(t, "2.1", <-versions)
+
+	require.NoError(t, mc.push(context.Background(), trend(1, -2)))
+	assert.Equal(t, "2.2", <-versions)
+}
+
+func TestMetricsClientPushCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var reqs atomic.Int64
+	h := func(rw http.ResponseWriter, _ *http.Request) {
+		reqs.Add(1)
+		// cancel while the client is waiting to retry
+		cancel()
+		rw.WriteHeader(http.StatusInternalServerError)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(h))
+	defer ts.Close()
+
+	c := cloudapi.NewClient(nil, "fake-token", ts.URL, "k6cloud/v0.4", 1*time.Second)
+	mc, err := newMetricsClient(c, "test-ref-id", retryPolicy{maxAttempts: 10, interval: time.Hour}, "")
+	require.NoError(t, err)
+
+	start := time.Now()
+	err = mc.push(ctx, &pbcloud.MetricSet{TestRunId: "test-ref-id"})
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, int64(1), reqs.Load())
+}
+
 func TestMetricsClientPushUnexpectedStatus(t *testing.T) {
 	t.Parallel()
 
@@ -55,9 +173,134 @@ func TestMetricsClientPushUnexpectedStatus(t *testing.T) {
 	defer ts.Close()
 
 	c := cloudapi.NewClient(nil, "fake-token", ts.URL, "k6cloud/v0.4", 1*time.Second)
-	mc, err := newMetricsClient(c, "test-ref-id")
+	mc, err := newMetricsClient(c, "test-ref-id", retryPolicy{}, "")
 	require.NoError(t, err)
 
-	err = mc.push(nil)
+	err = mc.push(context.Background(), nil)
 	assert.ErrorContains(t, err, "500 Internal Server Error")
 }
+
+func TestMetricsClientPushRetry(t *testing.T) {
+	t.Parallel()
+
+	var reqs int64
+	h := func(rw http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.NotEmpty(t, b)
+
+		if atomic.AddInt64(&reqs, 1) < 3 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}
+	ts := httptest.NewServer(http.HandlerFunc(h))
+	defer ts.Close()
+
+	c := cloudapi.NewClient(nil, "fake-token", ts.URL, "k6cloud/v0.4", 1*time.Second)
+	mc, err := newMetricsClient(c, "test-ref-id", retryPolicy{
+		maxAttempts: 3,
+		interval:    time.Millisecond,
+	}, "")
+	require.NoError(t, err)
+
+	err = mc.push(context.Background(), &pbcloud.MetricSet{TestRunId: "test-ref-id"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), atomic.LoadInt64(&reqs))
+}
+
+func TestMetricsClientPushRetryGivesUp(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		status  int
+		body    string
+		policy  retryPolicy
+		expReqs int64
+	}{
+		{
+			name:    "MaxAttempts",
+			status:  http.StatusInternalServerError,
+			policy:  retryPolicy{maxAttempts: 3, interval: time.Millisecond},
+			expReqs: 3,
+		},
+		{
+			name:    "MaxElapsedTime",
+			status:  http.StatusInternalServerError,
+			policy:  retryPolicy{maxAttempts: 10, interval: time.Hour, maxElapsedTime: time.Second},
+			expReqs: 1,
+		},
+		{
+			name:    "NotRetryableStatus",
+			status:  http.StatusBadRequest,
+			body:    `{"error":{"code":1,"message":"bad request"}}`,
+			policy:  retryPolicy{maxAttempts: 3, interval: time.Millisecond},
+			expReqs: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var reqs int64
+			h := func(rw http.ResponseWriter, _ *http.Request) {
+				atomic.AddInt64(&reqs, 1)
+				rw.WriteHeader(tc.status)
+				_, _ = rw.Write([]byte(tc.body))
+			}
+			ts := httptest.NewServer(http.HandlerFunc(h))
+			defer ts.Close()
+
+			c := cloudapi.NewClient(nil, "fake-token", ts.URL, "k6cloud/v0.4", 1*time.Second)
+			mc, err := newMetricsClient(c, "test-ref-id", tc.policy, "")
+			require.NoError(t, err)
+
+			err = mc.push(context.Background(), &pbcloud.MetricSet{})
+			require.Error(t, err)
+			assert.Equal(t, tc.expReqs, atomic.LoadInt64(&reqs))
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	t.Parallel()
+
+	rp := retryPolicy{interval: 100 * time.Millisecond}
+	for attempt, exp := range []time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+	} {
+		if attempt == 0 {
+			continue
+		}
+		d := rp.backoff(attempt)
+		assert.GreaterOrEqual(t, d, exp/2)
+		assert.Less(t, d, exp+exp/2)
+	}
+
+	assert.Zero(t, retryPolicy{}.backoff(1))
+}
+
+func TestMetricsClientPushEncoding(t *testing.T) {
+	t.Parallel()
+
+	var contentEncoding string
+	h := func(rw http.ResponseWriter, r *http.Request) {
+		contentEncoding = r.Header.Get("Content-Encoding")
+	}
+	ts := httptest.NewServer(http.HandlerFunc(h))
+	defer ts.Close()
+
+	c := cloudapi.NewClient(nil, "fake-token", ts.URL, "k6cloud/v0.4", 1*time.Second)
+	mc, err := newMetricsClient(c, "test-ref-id", retryPolicy{}, encodingZstd)
+	require.NoError(t, err)
+
+	require.NoError(t, mc.push(context.Background(), &pbcloud.MetricSet{}))
+	assert.Equal(t, "zstd", contentEncoding)
+
+	_, err = newMetricsClient(c, "test-ref-id", retryPolicy{}, "unknown")
+	assert.Error(t, err)
+}
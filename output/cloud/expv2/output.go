@@ -8,24 +8,40 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.k6.io/k6/cloudapi"
 	"go.k6.io/k6/cloudapi/insights"
 	"go.k6.io/k6/errext"
 	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/consts"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 	"go.k6.io/k6/output"
+	"go.k6.io/k6/output/cloud/expv2/pbcloud"
 	insightsOutput "go.k6.io/k6/output/cloud/insights"
 
 	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
 )
 
+// defaultAggregationPeriod is the aggregation period used when the config
+// doesn't set one. The samples are pre-aggregated in time buckets of this
+// duration, aligned to the wall clock, before being pushed.
+const defaultAggregationPeriod = 3 * time.Second
+
+// metadataPushTimeout is the maximum time spent pushing the test run's
+// metadata, including the retries.
+const metadataPushTimeout = 10 * time.Second
+
 // flusher is an interface for flushing data to the cloud.
 type flusher interface {
-	flush() error
+	flush(ctx context.Context) error
 }
 
 // Output sends result data to the k6 Cloud service.
@@ -36,6 +52,7 @@ type Output struct {
 	config      cloudapi.Config
 	cloudClient *cloudapi.Client
 	testRunID   string
+	fs          fsext.Fs
 
 	collector *collector
 	flushing  flusher
@@ -54,15 +71,36 @@ type Output struct {
 	abort        chan struct{}
 	abortOnce    sync.Once
 	testStopFunc func(error)
+
+	// stats tracks the internal accounting of the output.
+	stats *stats
+
+	// metadata is optional, if set it is pushed when the output starts.
+	metadata *testRunMetadata
+
+	// seriesLimitWarned is true after the warning
+	// for the exceeded series limit has been logged.
+	seriesLimitWarned bool
+
+	// consecutiveFlushFailures counts the flushes
+	// failed since the last successful one.
+	consecutiveFlushFailures atomic.Int64
 }
 
 // New creates a new cloud output.
 func New(logger logrus.FieldLogger, conf cloudapi.Config, _ *cloudapi.Client) (*Output, error) {
-	return &Output{
+	// The aggregation is required by the protocol v2 so, differently from v1,
+	// it can't be disabled and a default is used if it is not configured.
+	if conf.AggregationPeriod.Duration <= 0 {
+		conf.AggregationPeriod = types.NewNullDuration(defaultAggregationPeriod, false)
+	}
+
+	o := &Output{
 		config: conf,
 		logger: logger.WithField("output", "cloudv2"),
 		abort:  make(chan struct{}),
 		stop:   make(chan struct{}),
+		stats:  &stats{},
 
 		// TODO: move this creation operation to the centralized output. Reducing the probability to
 		// break the logic for the config overwriting.
@@ -71,7 +109,17 @@ func New(logger logrus.FieldLogger, conf cloudapi.Config, _ *cloudapi.Client) (*
 		// the config we need to use the new set.
 		cloudClient: cloudapi.NewClient(
 			logger, conf.Token.String, conf.Host.String, consts.Version, conf.Timeout.TimeDuration()),
-	}, nil
+	}
+
+	transport, err := newHTTPTransport(conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize the HTTP transport for pushing the metrics: %w", err)
+	}
+	if transport != nil {
+		o.cloudClient.SetTransport(transport)
+	}
+
+	return o, nil
 }
 
 // SetTestRunID sets the Cloud's test run id.
@@ -79,6 +127,18 @@ func (o *Output) SetTestRunID(id string) {
 	o.testRunID = id
 }
 
+// SetTestRunMetadata sets the test run's details
+// pushed to the cloud when the output starts.
+func (o *Output) SetTestRunMetadata(opts lib.Options, thresholds map[string][]string) {
+	o.metadata = &testRunMetadata{options: opts, thresholds: thresholds}
+}
+
+// SetFS sets the file system used for writing
+// the metrics by the local-fallback failure policy.
+func (o *Output) SetFS(fs fsext.Fs) {
+	o.fs = fs
+}
+
 // SetTestRunStopCallback receives the function that
 // that stops the engine when it is called.
 // It should be called on critical errors.
@@ -92,6 +152,13 @@ func (o *Output) Start() error {
 	o.logger.Debug("Starting...")
 	defer o.logger.Debug("Started!")
 
+	if err := validateFailurePolicy(o.config.MetricPushFailurePolicy.String); err != nil {
+		return err
+	}
+	if err := validateSeriesLimitPolicy(o.config.ActiveSeriesLimitPolicy.String); err != nil {
+		return err
+	}
+
 	var err error
 	o.collector, err = newCollector(
 		o.config.AggregationPeriod.TimeDuration(),
@@ -99,11 +166,27 @@ func (o *Output) Start() error {
 	if err != nil {
 		return fmt.Errorf("failed to initialize the samples collector: %w", err)
 	}
+	o.collector.bq.limit = int(o.config.MetricPushQueueSize.Int64)
+
+	if err := o.configureTrendResolutions(); err != nil {
+		return err
+	}
+	if maxSeries := o.config.MaxActiveSeries.Int64; maxSeries > 0 {
+		o.collector.limiter = newSeriesLimiter(int(maxSeries), o.config.ActiveSeriesLimitPolicy.String, activeSeriesTTL)
+	}
 
-	mc, err := newMetricsClient(o.cloudClient, o.testRunID)
+	mc, err := newMetricsClient(o.cloudClient, o.testRunID, retryPolicy{
+		maxAttempts:    int(o.config.MetricPushMaxAttempts.Int64),
+		interval:       o.config.MetricPushRetryInterval.TimeDuration(),
+		maxElapsedTime: o.config.MetricPushRetryMaxElapsedTime.TimeDuration(),
+	}, o.config.MetricPushEncoding.String)
 	if err != nil {
 		return fmt.Errorf("failed to initialize the http metrics flush client: %w", err)
 	}
+	mc.stats = o.stats
+	mc.labelDictionary = o.config.MetricPushLabelDictionary.Bool
+	o.runPushMetadata(mc)
+
 	o.flushing = &metricsFlusher{
 		testRunID:                  o.testRunID,
 		bq:                         &o.collector.bq,
@@ -115,6 +198,8 @@ func (o *Output) Start() error {
 		// TODO: when the migration from v1 is over
 		// change the default of cloudapi.MetricPushConcurrency to use GOMAXPROCS(0)
 		batchPushConcurrency: int(o.config.MetricPushConcurrency.Int64),
+		maxBatchPayloadSize:  defaultMaxBatchPayloadSize,
+		stats:                o.stats,
 	}
 
 	o.runPeriodicFlush()
@@ -152,6 +237,7 @@ func (o *Output) Start() error {
 func (o *Output) StopWithTestError(_ error) error {
 	o.logger.Debug("Stopping...")
 	defer o.logger.Debug("Stopped!")
+	defer o.logStats()
 
 	close(o.stop)
 	o.wg.Wait()
@@ -167,7 +253,7 @@ func (o *Output) StopWithTestError(_ error) error {
 	// wait period.
 	o.collector.DropExpiringDelay()
 	o.collectSamples()
-	o.flushMetrics()
+	o.finalFlush()
 
 	// Flush all the remaining request metadatas.
 	if insightsOutput.Enabled(o.config) {
@@ -180,6 +266,27 @@ func (o *Output) StopWithTestError(_ error) error {
 	return nil
 }
 
+// finalFlush flushes the remaining metrics waiting at most the configured
+// timeout. When the timeout is exceeded, the pending pushes are canceled
+// and it returns false once they have returned.
+func (o *Output) finalFlush() bool {
+	ctx := context.Background()
+	timeout := o.config.MetricPushFinalFlushTimeout.TimeDuration()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	o.flushMetrics(ctx)
+	if ctx.Err() != nil {
+		o.logger.WithField("timeout", timeout).
+			Warn("The final flush of the metrics has timed out, the test run's results in the cloud could be incomplete")
+		return false
+	}
+	return true
+}
+
 func (o *Output) runPeriodicFlush() {
 	t := time.NewTicker(o.config.MetricPushInterval.TimeDuration())
 
@@ -194,7 +301,7 @@ func (o *Output) runPeriodicFlush() {
 		for {
 			select {
 			case <-t.C:
-				o.flushMetrics()
+				o.flushMetrics(context.Background())
 			case <-o.stop:
 				return
 			case <-o.abort:
@@ -211,6 +318,13 @@ func (o *Output) AddMetricSamples(s []metrics.SampleContainer) {
 	// queue.
 	select {
 	case <-o.abort:
+		if o.stats != nil {
+			var dropped int64
+			for _, c := range s {
+				dropped += int64(len(c.GetSamples()))
+			}
+			o.stats.samplesDropped.Add(dropped)
+		}
 		return
 	default:
 	}
@@ -250,7 +364,11 @@ func (o *Output) periodicInvoke(d time.Duration, callback func()) {
 
 func (o *Output) collectSamples() {
 	samples := o.GetBufferedSamples()
-	o.collector.CollectSamples(samples)
+	collected := o.collector.CollectSamples(samples)
+	if o.stats != nil {
+		o.stats.samplesCollected.Add(int64(collected))
+	}
+	o.reportLimitedSeries()
 
 	if insightsOutput.Enabled(o.config) {
 		o.requestMetadatasCollector.CollectRequestMetadatas(samples)
@@ -258,14 +376,20 @@ func (o *Output) collectSamples() {
 }
 
 // flushMetrics receives a set of metric samples.
-func (o *Output) flushMetrics() {
+func (o *Output) flushMetrics(ctx context.Context) {
 	start := time.Now()
 
-	err := o.flushing.flush()
+	err := o.flushing.flush(ctx)
 	if err != nil {
+		if ctx.Err() != nil {
+			// the caller has given up on the flush, it handles the failure
+			return
+		}
 		o.handleFlushError(err)
+		o.handleConsecutiveFlushFailures(err)
 		return
 	}
+	o.consecutiveFlushFailures.Store(0)
 
 	o.logger.WithField("t", time.Since(start)).Debug("Successfully flushed buffered samples to the cloud")
 }
@@ -307,6 +431,136 @@ func (o *Output) flushRequestMetadatas() {
 	o.logger.WithField("t", time.Since(start)).Debug("Successfully flushed buffered trace samples to the cloud")
 }
 
+// runPushMetadata pushes the test run's metadata, if any, in the background.
+// The push is bounded by metadataPushTimeout and it is canceled on abort.
+// A failure doesn't stop the test, the metrics are still valuable without it.
+func (o *Output) runPushMetadata(mc *metricsClient) {
+	if o.metadata == nil {
+		return
+	}
+	md, err := o.metadata.asProto()
+	if err != nil {
+		o.logger.WithError(err).Warn("Failed to encode the test run's metadata")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), metadataPushTimeout)
+	o.wg.Add(1)
+	go func() {
+		defer o.wg.Done()
+		defer cancel()
+
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-o.abort:
+				cancel()
+			case <-done:
+			}
+		}()
+
+		err := mc.push(ctx, &pbcloud.MetricSet{
+			TestRunId:         o.testRunID,
+			AggregationPeriod: uint32(o.config.AggregationPeriod.TimeDuration().Seconds()),
+			Metadata:          md,
+		})
+		if err != nil {
+			o.logger.WithError(err).Warn("Failed to push the test run's metadata to the cloud")
+		}
+	}()
+}
+
+// reportLimitedSeries reports the samples beyond the active series limit.
+// It logs a warning only the first time the limit is exceeded.
+func (o *Output) reportLimitedSeries() {
+	if o.collector.limiter == nil {
+		return
+	}
+	limited := o.collector.limiter.PopLimited()
+	if limited < 1 {
+		return
+	}
+
+	if o.collector.limiter.policy == seriesLimitPolicyDrop && o.stats != nil {
+		o.stats.samplesDropped.Add(int64(limited))
+	}
+	if o.seriesLimitWarned {
+		return
+	}
+	o.seriesLimitWarned = true
+
+	msg := "The samples of the new time series are aggregated under the " + overflowLabelName + " label"
+	if o.collector.limiter.policy == seriesLimitPolicyDrop {
+		msg = "The samples of the new time series are dropped"
+	}
+	o.logger.WithField("maxActiveSeries", o.collector.limiter.max).
+		Warnf("The limit of active time series has been exceeded. %s, "+
+			"consider reducing the number of unique tag combinations.", msg)
+}
+
+// configureTrendResolutions sets the minimum resolutions
+// of the Trend histograms on the collector.
+func (o *Output) configureTrendResolutions() error {
+	r := o.config.TrendMinResolution.Float64
+	if o.config.TrendMinResolution.Valid && r <= 0 {
+		return fmt.Errorf("the trend min resolution must be positive, got %v", r)
+	}
+	if r > 0 {
+		o.collector.defaultTrendResolution = r
+	}
+
+	overrides, err := parseTrendResolutions(o.config.TrendMinResolutionOverrides.String)
+	if err != nil {
+		return err
+	}
+	o.collector.trendResolutions = overrides
+	return nil
+}
+
+// parseTrendResolutions parses a comma-separated list
+// of metric=resolution pairs.
+func parseTrendResolutions(s string) (map[string]float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	resolutions := make(map[string]float64)
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid trend min resolution override %q, expected metric=resolution", pair)
+		}
+		r, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trend min resolution for the metric %q: %w", name, err)
+		}
+		if r <= 0 {
+			return nil, fmt.Errorf("the trend min resolution for the metric %q must be positive, got %v", name, r)
+		}
+		resolutions[name] = r
+	}
+	return resolutions, nil
+}
+
+// logStats logs the internal accounting of the output. It logs
+// a warning if any data has been lost, so the results in the cloud
+// could be incomplete.
+func (o *Output) logStats() {
+	if o.stats == nil {
+		return
+	}
+
+	logger := o.logger.WithFields(o.stats.fields())
+	if o.stats.hasLosses() {
+		logger.Warn("Some metrics have been lost, the test run's results in the cloud could be incomplete")
+		return
+	}
+	logger.Debug("Metrics accounting")
+}
+
 // handleFlushError handles errors generated from the flushing operation.
 // It may interrupt the metric collection or invoke aborting of the test.
 //
@@ -335,6 +589,12 @@ func (o *Output) handleFlushError(err error) {
 		return
 	}
 
+	o.interrupt(err, o.config.StopOnError.Bool)
+}
+
+// interrupt stops sending metrics to the cloud and,
+// if stopTest is true, it stops the test run too.
+func (o *Output) interrupt(err error, stopTest bool) {
 	// Do not close multiple times (that would panic) in the case
 	// we hit this multiple times and/or concurrently
 	o.abortOnce.Do(func() {
@@ -342,7 +602,7 @@ func (o *Output) handleFlushError(err error) {
 
 		close(o.abort)
 
-		if o.config.StopOnError.Bool {
+		if stopTest {
 			serr := errext.WithAbortReasonIfNone(
 				errext.WithExitCodeIfNone(err, exitcodes.ExternalAbort),
 				errext.AbortedByOutput,
@@ -355,22 +615,91 @@ func (o *Output) handleFlushError(err error) {
 	})
 }
 
+// handleConsecutiveFlushFailures applies the configured failure policy
+// when the flushes fail for the configured number of consecutive times.
+func (o *Output) handleConsecutiveFlushFailures(err error) {
+	failures := o.consecutiveFlushFailures.Add(1)
+	if failures != o.config.MetricPushMaxConsecutiveFailures.Int64 {
+		return
+	}
+
+	logger := o.logger.WithError(err).WithField("failures", failures)
+
+	switch o.config.MetricPushFailurePolicy.String {
+	case failurePolicyAbort:
+		o.interrupt(err, true)
+	case failurePolicyLocalFallback:
+		if ferr := o.switchToLocalFallback(); ferr != nil {
+			logger.WithField("fallbackError", ferr).
+				Error("Failed to switch the metrics flushing to the local fallback")
+			return
+		}
+		logger.WithField("dir", o.config.MetricPushFallbackDir.String).
+			Warn("The metrics can't be pushed to the cloud, they are written to the local fallback directory")
+	default:
+		logger.Warn("The metrics pushes are repeatedly failing, the test run's results in the cloud could be incomplete")
+	}
+}
+
+// switchToLocalFallback replaces the metrics client with a pusher
+// writing the metrics to the configured fallback directory.
+func (o *Output) switchToLocalFallback() error {
+	mf, ok := o.flushing.(*metricsFlusher)
+	if !ok {
+		return errors.New("the local fallback is not supported by the current flusher")
+	}
+
+	if o.fs == nil {
+		return errors.New("the file system for writing the metrics is not set")
+	}
+
+	dir := o.config.MetricPushFallbackDir.String
+	if dir == "" {
+		dir = "k6-cloud-metrics-" + o.testRunID
+		o.config.MetricPushFallbackDir = null.StringFrom(dir)
+	}
+
+	encoder, err := newPayloadEncoder(o.config.MetricPushEncoding.String)
+	if err != nil {
+		return err
+	}
+	fp, err := newFilePusher(o.fs, dir, encoder)
+	if err != nil {
+		return err
+	}
+
+	mf.client = fp
+	return nil
+}
+
 func printableConfig(c cloudapi.Config) map[string]any {
 	m := map[string]any{
-		"host":                  c.Host.String,
-		"name":                  c.Name.String,
-		"timeout":               c.Timeout.String(),
-		"webAppURL":             c.WebAppURL.String,
-		"projectID":             c.ProjectID.Int64,
-		"pushRefID":             c.PushRefID.String,
-		"stopOnError":           c.StopOnError.Bool,
-		"testRunDetails":        c.TestRunDetails.String,
-		"aggregationPeriod":     c.AggregationPeriod.String(),
-		"aggregationWaitPeriod": c.AggregationWaitPeriod.String(),
-		"maxTimeSeriesInBatch":  c.MaxTimeSeriesInBatch.Int64,
-		"metricPushConcurrency": c.MetricPushConcurrency.Int64,
-		"metricPushInterval":    c.MetricPushInterval.String(),
-		"token":                 "",
+		"host":                          c.Host.String,
+		"name":                          c.Name.String,
+		"timeout":                       c.Timeout.String(),
+		"webAppURL":                     c.WebAppURL.String,
+		"projectID":                     c.ProjectID.Int64,
+		"pushRefID":                     c.PushRefID.String,
+		"stopOnError":                   c.StopOnError.Bool,
+		"testRunDetails":                c.TestRunDetails.String,
+		"aggregationPeriod":             c.AggregationPeriod.String(),
+		"aggregationWaitPeriod":         c.AggregationWaitPeriod.String(),
+		"maxTimeSeriesInBatch":          c.MaxTimeSeriesInBatch.Int64,
+		"metricPushConcurrency":         c.MetricPushConcurrency.Int64,
+		"metricPushInterval":            c.MetricPushInterval.String(),
+		"metricPushMaxAttempts":         c.MetricPushMaxAttempts.Int64,
+		"metricPushEncoding":            c.MetricPushEncoding.String,
+		"metricPushFailurePolicy":       c.MetricPushFailurePolicy.String,
+		"metricPushQueueSize":           c.MetricPushQueueSize.Int64,
+		"metricPushRetryInterval":       c.MetricPushRetryInterval.String(),
+		"metricPushRetryMaxElapsedTime": c.MetricPushRetryMaxElapsedTime.String(),
+		"metricPushFinalFlushTimeout":   c.MetricPushFinalFlushTimeout.String(),
+		"metricPushLabelDictionary":     c.MetricPushLabelDictionary.Bool,
+		"trendMinResolution":            c.TrendMinResolution.Float64,
+		"trendMinResolutionOverrides":   c.TrendMinResolutionOverrides.String,
+		"maxActiveSeries":               c.MaxActiveSeries.Int64,
+		"activeSeriesLimitPolicy":       c.ActiveSeriesLimitPolicy.String,
+		"token":                         "",
 	}
 
 	if c.Token.Valid {
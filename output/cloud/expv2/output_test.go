@@ -1,22 +1,31 @@
 package expv2
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/klauspost/compress/snappy"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 	"gopkg.in/guregu/null.v3"
 
 	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/lib/testutils"
 	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/output/cloud/expv2/pbcloud"
 )
 
 func TestNew(t *testing.T) {
@@ -50,6 +59,21 @@ func TestNewWithConfigOverwritten(t *testing.T) {
 	assert.Equal(t, "the-new-host/v1", o.cloudClient.BaseURL())
 }
 
+func TestNewDefaultAggregationPeriod(t *testing.T) {
+	t.Parallel()
+
+	logger := testutils.NewLogger(t)
+	c := cloudapi.NewClient(logger, "my-token", "the-host", "v/foo", 1*time.Second)
+
+	o, err := New(logger, cloudapi.Config{}, c)
+	require.NoError(t, err)
+	assert.Equal(t, defaultAggregationPeriod, o.config.AggregationPeriod.TimeDuration())
+
+	o, err = New(logger, cloudapi.Config{AggregationPeriod: types.NewNullDuration(5*time.Second, true)}, c)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, o.config.AggregationPeriod.TimeDuration())
+}
+
 func TestOutputSetTestRunID(t *testing.T) {
 	t.Parallel()
 	o := Output{}
@@ -305,6 +329,78 @@ func TestOutputStopWithTestError(t *testing.T) {
 	require.NoError(t, o.StopWithTestError(errors.New("an error")))
 }
 
+func TestOutputStartPushesMetadata(t *testing.T) {
+	t.Parallel()
+
+	pushed := make(chan *pbcloud.MetricSet, 1)
+	h := func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/metrics/ref-id-123" {
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		b, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+
+		var got pbcloud.MetricSet
+		require.NoError(t, proto.Unmarshal(b, &got))
+		if got.Metadata != nil {
+			pushed <- &got
+		}
+	}
+	ts := httptest.NewServer(http.HandlerFunc(h))
+	defer ts.Close()
+
+	config := cloudapi.NewConfig()
+	config.Host = null.StringFrom(ts.URL)
+	config.Token = null.StringFrom("token-is-required")
+	config.AggregationPeriod = types.NullDurationFrom(3 * time.Second)
+
+	logger := testutils.NewLogger(t)
+	cc := cloudapi.NewClient(
+		logger, config.Token.String, config.Host.String+"/v1", "v/test", config.Timeout.TimeDuration())
+	o, err := New(logger, config, cc)
+	require.NoError(t, err)
+
+	o.SetTestRunID("ref-id-123")
+	o.SetTestRunMetadata(lib.Options{VUs: null.IntFrom(10)}, map[string][]string{
+		"http_req_duration": {"p(95)<100"},
+	})
+	require.NoError(t, o.Start())
+
+	select {
+	case ms := <-pushed:
+		assert.Empty(t, ms.Metrics)
+		assert.Equal(t, uint32(3), ms.AggregationPeriod)
+		assert.Equal(t, float64(10), ms.Metadata.Options.AsMap()["vus"])
+		require.Len(t, ms.Metadata.Thresholds, 1)
+		assert.Equal(t, "http_req_duration", ms.Metadata.Thresholds[0].Metric)
+	case <-time.After(5 * time.Second):
+		t.Error("the metadata hasn't been pushed")
+	}
+	require.NoError(t, o.StopWithTestError(nil))
+}
+
+func TestOutputFinalFlushTimeout(t *testing.T) {
+	t.Parallel()
+
+	o := Output{logger: testutils.NewLogger(t)}
+	o.config.MetricPushFinalFlushTimeout = types.NullDurationFrom(10 * time.Millisecond)
+
+	var canceled bool
+	o.flushing = flusherCtxFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		canceled = true
+		return ctx.Err()
+	})
+	assert.False(t, o.finalFlush())
+	// the flush has been waited after canceling it
+	assert.True(t, canceled)
+
+	o.flushing = flusherCtxFunc(func(context.Context) error { return nil })
+	assert.True(t, o.finalFlush())
+}
+
 func TestOutputFlushTicks(t *testing.T) {
 	t.Parallel()
 
@@ -493,13 +589,19 @@ func TestOutputFlushRequestMetadatasAbort(t *testing.T) {
 	}
 }
 
+type flusherCtxFunc func(context.Context) error
+
+func (ff flusherCtxFunc) flush(ctx context.Context) error {
+	return ff(ctx)
+}
+
 type flusherFunc func()
 
 func (ff flusherFunc) Flush() error {
-	return ff.flush()
+	return ff.flush(context.Background())
 }
 
-func (ff flusherFunc) flush() error {
+func (ff flusherFunc) flush(_ context.Context) error {
 	ff()
 	return nil
 }
@@ -530,3 +632,119 @@ func TestPrintableConfig(t *testing.T) {
 
 	assert.Subset(t, printableConfig(c), exp)
 }
+
+func TestParseTrendResolutions(t *testing.T) {
+	t.Parallel()
+
+	r, err := parseTrendResolutions("")
+	require.NoError(t, err)
+	assert.Nil(t, r)
+
+	r, err = parseTrendResolutions("http_req_waiting=0.0001, my_trend = 1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"http_req_waiting": .0001, "my_trend": 1}, r)
+
+	for _, invalid := range []string{"http_req_waiting", "=1", "my_trend=abc", "my_trend=0", "my_trend=-1"} {
+		_, err = parseTrendResolutions(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestOutputConsecutiveFlushFailuresPolicy(t *testing.T) {
+	t.Parallel()
+
+	r := metrics.NewRegistry()
+	m1 := r.MustNewMetric("metric1", metrics.Counter)
+	ts := metrics.TimeSeries{
+		Metric: m1,
+		Tags:   r.RootTagSet().With("key1", "val1"),
+	}
+
+	newOutput := func(t *testing.T, policy string) (*Output, *bucketQ, *bool) {
+		logger := testutils.NewLogger(t)
+		bq := &bucketQ{}
+		stopFuncCalled := false
+		o := &Output{
+			logger: logger,
+			abort:  make(chan struct{}),
+			testStopFunc: func(error) {
+				stopFuncCalled = true
+			},
+			testRunID: "123",
+			flushing: &metricsFlusher{
+				bq:     bq,
+				logger: logger,
+				client: &pusherMock{
+					errFn: func() error { return errors.New("connection refused") },
+				},
+				discardedLabels:      make(map[string]struct{}),
+				maxSeriesInBatch:     10,
+				batchPushConcurrency: 1,
+			},
+		}
+		o.config.MetricPushFailurePolicy = null.StringFrom(policy)
+		o.config.MetricPushMaxConsecutiveFailures = null.IntFrom(2)
+		return o, bq, &stopFuncCalled
+	}
+
+	flush := func(o *Output, bq *bucketQ) {
+		bq.Push([]timeBucket{{Time: 1, Sinks: map[metrics.TimeSeries]metricValue{ts: &counter{Sum: 1}}}})
+		o.flushMetrics(context.Background())
+	}
+
+	t.Run("abort", func(t *testing.T) {
+		t.Parallel()
+
+		o, bq, stopFuncCalled := newOutput(t, failurePolicyAbort)
+		flush(o, bq)
+		assert.False(t, *stopFuncCalled)
+
+		flush(o, bq)
+		assert.True(t, *stopFuncCalled)
+		select {
+		case <-o.abort:
+		default:
+			t.Fatal("the output should be aborted")
+		}
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		t.Parallel()
+
+		o, bq, stopFuncCalled := newOutput(t, failurePolicyWarn)
+		logger, hook := testutils.NewLoggerWithHook(t)
+		o.logger = logger
+
+		flush(o, bq)
+		flush(o, bq)
+		assert.False(t, *stopFuncCalled)
+		assert.Len(t, testutils.FilterEntries(hook.Drain(), logrus.WarnLevel,
+			"The metrics pushes are repeatedly failing, the test run's results in the cloud could be incomplete"), 1)
+	})
+
+	t.Run("local-fallback", func(t *testing.T) {
+		t.Parallel()
+
+		o, bq, stopFuncCalled := newOutput(t, failurePolicyLocalFallback)
+		fs := fsext.NewMemMapFs()
+		o.SetFS(fs)
+		o.config.MetricPushFallbackDir = null.StringFrom("fallback")
+
+		flush(o, bq)
+		flush(o, bq)
+		assert.False(t, *stopFuncCalled)
+
+		mf, ok := o.flushing.(*metricsFlusher)
+		require.True(t, ok)
+		require.IsType(t, &filePusher{}, mf.client)
+
+		// the requeued batches are written to the fallback directory
+		flush(o, bq)
+		assert.Zero(t, o.consecutiveFlushFailures.Load())
+
+		files, err := fsext.ReadDir(fs, "fallback")
+		require.NoError(t, err)
+		// two batches requeued by the failed flushes and the new one
+		assert.Len(t, files, 3)
+	})
+}
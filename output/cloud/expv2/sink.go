@@ -10,6 +10,9 @@ import (
 // https://github.com/grafana/k6/pull/3085#discussion_r1210415981
 type metricValue interface {
 	Add(v float64)
+
+	// AddWeighted adds n occurrences of the value.
+	AddWeighted(v float64, n uint32)
 }
 
 func newMetricValue(mt metrics.MetricType) metricValue {
@@ -21,7 +24,7 @@ func newMetricValue(mt metrics.MetricType) metricValue {
 		am = &gauge{}
 	case metrics.Rate:
 		am = &rate{}
-	case metrics.Trend:
+	case metrics.Trend, metrics.Histogram:
 		am = newHistogram()
 	default:
 		// Should not be possible to create
@@ -37,7 +40,11 @@ type counter struct {
 }
 
 func (c *counter) Add(v float64) {
-	c.Sum += v
+	c.AddWeighted(v, 1)
+}
+
+func (c *counter) AddWeighted(v float64, n uint32) {
+	c.Sum += v * float64(n)
 }
 
 type gauge struct {
@@ -51,9 +58,13 @@ type gauge struct {
 }
 
 func (g *gauge) Add(v float64) {
+	g.AddWeighted(v, 1)
+}
+
+func (g *gauge) AddWeighted(v float64, n uint32) {
 	g.Last = v
-	g.Count++
-	g.Sum += v
+	g.Count += n
+	g.Sum += v * float64(n)
 	g.Avg = g.Sum / float64(g.Count)
 
 	if v > g.Max {
@@ -71,8 +82,12 @@ type rate struct {
 }
 
 func (r *rate) Add(v float64) {
-	r.Total++
+	r.AddWeighted(v, 1)
+}
+
+func (r *rate) AddWeighted(v float64, n uint32) {
+	r.Total += n
 	if v != 0 {
-		r.NonZeroCount++
+		r.NonZeroCount += n
 	}
 }
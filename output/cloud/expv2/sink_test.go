@@ -80,3 +80,18 @@ func TestRateAdd(t *testing.T) {
 	exp.Total = 2
 	assert.Equal(t, exp, r)
 }
+
+func TestMetricValueAddWeighted(t *testing.T) {
+	t.Parallel()
+
+	for _, mt := range []metrics.MetricType{metrics.Counter, metrics.Gauge, metrics.Rate, metrics.Trend} {
+		weighted, repeated := newMetricValue(mt), newMetricValue(mt)
+		weighted.AddWeighted(0, 2)
+		weighted.AddWeighted(-2.5, 3)
+		weighted.AddWeighted(7, 1)
+		for _, v := range []float64{0, 0, -2.5, -2.5, -2.5, 7} {
+			repeated.Add(v)
+		}
+		assert.Equal(t, repeated, weighted, mt.String())
+	}
+}
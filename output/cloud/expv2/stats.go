@@ -0,0 +1,80 @@
+package expv2
+
+import (
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// stats tracks the internal accounting of the output,
+// so it is possible to evaluate the completeness
+// of the results in the cloud after a flaky run.
+//
+// It is expected to be used concurrently.
+type stats struct {
+	// samplesCollected counts the samples aggregated in time buckets.
+	samplesCollected atomic.Int64
+
+	// samplesDropped counts the samples discarded
+	// because the output has been aborted.
+	samplesDropped atomic.Int64
+
+	// seriesFlushed counts the time series mapped in the flushed batches.
+	seriesFlushed atomic.Int64
+
+	// bucketsDropped counts the time buckets dropped
+	// because the push queue was full.
+	bucketsDropped atomic.Int64
+
+	// pushQueueDepth is the number of time buckets
+	// which were waiting in the push queue at the last flush.
+	pushQueueDepth atomic.Int64
+
+	// pushQueueMaxDepth is the highest number of time buckets
+	// which were waiting in the push queue at a flush.
+	pushQueueMaxDepth atomic.Int64
+
+	// batchesDropped counts the batches dropped because too many
+	// failed pushes were waiting to be retried.
+	batchesDropped atomic.Int64
+
+	// pushesFailed counts the pushes failed after all the retries.
+	pushesFailed atomic.Int64
+
+	// bytesPushed counts the bytes of the successfully pushed payloads.
+	bytesPushed atomic.Int64
+}
+
+// hasLosses returns true if any data has been lost.
+func (s *stats) hasLosses() bool {
+	return s.samplesDropped.Load() > 0 ||
+		s.bucketsDropped.Load() > 0 ||
+		s.batchesDropped.Load() > 0
+}
+
+// observePushQueueDepth records the number of time buckets
+// which were waiting in the push queue at a flush.
+func (s *stats) observePushQueueDepth(depth int) {
+	d := int64(depth)
+	s.pushQueueDepth.Store(d)
+	for {
+		maxDepth := s.pushQueueMaxDepth.Load()
+		if d <= maxDepth || s.pushQueueMaxDepth.CompareAndSwap(maxDepth, d) {
+			return
+		}
+	}
+}
+
+func (s *stats) fields() logrus.Fields {
+	return logrus.Fields{
+		"samplesCollected":  s.samplesCollected.Load(),
+		"samplesDropped":    s.samplesDropped.Load(),
+		"seriesFlushed":     s.seriesFlushed.Load(),
+		"bucketsDropped":    s.bucketsDropped.Load(),
+		"pushQueueDepth":    s.pushQueueDepth.Load(),
+		"pushQueueMaxDepth": s.pushQueueMaxDepth.Load(),
+		"batchesDropped":    s.batchesDropped.Load(),
+		"pushesFailed":      s.pushesFailed.Load(),
+		"bytesPushed":       s.bytesPushed.Load(),
+	}
+}
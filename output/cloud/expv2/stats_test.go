@@ -0,0 +1,48 @@
+package expv2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsHasLosses(t *testing.T) {
+	t.Parallel()
+
+	s := &stats{}
+	s.samplesCollected.Add(10)
+	s.bytesPushed.Add(100)
+	s.pushesFailed.Add(1)
+	assert.False(t, s.hasLosses())
+
+	s.bucketsDropped.Add(1)
+	assert.True(t, s.hasLosses())
+}
+
+func TestStatsFields(t *testing.T) {
+	t.Parallel()
+
+	s := &stats{}
+	s.samplesCollected.Add(3)
+	s.samplesDropped.Add(2)
+	s.seriesFlushed.Add(1)
+
+	fields := s.fields()
+	assert.Equal(t, int64(3), fields["samplesCollected"])
+	assert.Equal(t, int64(2), fields["samplesDropped"])
+	assert.Equal(t, int64(1), fields["seriesFlushed"])
+	assert.Equal(t, int64(0), fields["bytesPushed"])
+}
+
+func TestStatsObservePushQueueDepth(t *testing.T) {
+	t.Parallel()
+
+	s := &stats{}
+	s.observePushQueueDepth(3)
+	s.observePushQueueDepth(5)
+	s.observePushQueueDepth(2)
+
+	assert.Equal(t, int64(2), s.pushQueueDepth.Load())
+	assert.Equal(t, int64(5), s.pushQueueMaxDepth.Load())
+	assert.False(t, s.hasLosses())
+}
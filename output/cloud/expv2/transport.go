@@ -0,0 +1,77 @@
+package expv2
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"go.k6.io/k6/cloudapi"
+)
+
+// newHTTPTransport returns the transport used for pushing the metrics,
+// configured with the proxy and the TLS options from the provided config.
+// It returns nil if the config doesn't require a custom transport.
+func newHTTPTransport(conf cloudapi.Config) (*http.Transport, error) {
+	var (
+		hasProxy      = conf.ProxyURL.String != ""
+		hasCACert     = conf.TLSCACert.String != ""
+		hasClientCert = conf.TLSClientCert.String != "" || conf.TLSClientKey.String != ""
+	)
+	if !hasProxy && !hasCACert && !hasClientCert {
+		return nil, nil //nolint:nilnil
+	}
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("the default HTTP transport has an unexpected type")
+	}
+	transport = transport.Clone()
+
+	if hasProxy {
+		u, err := url.Parse(conf.ProxyURL.String)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+
+	if !hasCACert && !hasClientCert {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if hasCACert {
+		//nolint:forbidigo // the CA bundle is a path of the local machine from the output config
+		pem, err := os.ReadFile(conf.TLSCACert.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the CA certificates: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid PEM certificate found in %s", conf.TLSCACert.String)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if hasClientCert {
+		if conf.TLSClientCert.String == "" || conf.TLSClientKey.String == "" {
+			return nil, errors.New("the TLS client certificate and key have to be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(conf.TLSClientCert.String, conf.TLSClientKey.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the TLS client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
@@ -0,0 +1,96 @@
+package expv2
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/cloudapi"
+	"go.k6.io/k6/output/cloud/expv2/pbcloud"
+)
+
+func TestNewHTTPTransportDefault(t *testing.T) {
+	t.Parallel()
+
+	transport, err := newHTTPTransport(cloudapi.Config{})
+	require.NoError(t, err)
+	assert.Nil(t, transport)
+}
+
+func TestNewHTTPTransportProxy(t *testing.T) {
+	t.Parallel()
+
+	transport, err := newHTTPTransport(cloudapi.Config{
+		ProxyURL: null.StringFrom("http://proxy.internal:3128"),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, transport)
+
+	req, err := http.NewRequest(http.MethodPost, "https://ingest.k6.io/v2/metrics/123", nil)
+	require.NoError(t, err)
+	proxyURL, err := transport.Proxy(req)
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy.internal:3128", proxyURL.String())
+}
+
+func TestNewHTTPTransportCustomCA(t *testing.T) {
+	t.Parallel()
+
+	reqs := 0
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		reqs++
+	}))
+	defer ts.Close()
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caPath, caPEM, 0o600))
+
+	transport, err := newHTTPTransport(cloudapi.Config{TLSCACert: null.StringFrom(caPath)})
+	require.NoError(t, err)
+	require.NotNil(t, transport)
+
+	c := cloudapi.NewClient(nil, "fake-token", ts.URL, "k6cloud/v0.4", 1*time.Second)
+	c.SetTransport(transport)
+	mc, err := newMetricsClient(c, "test-ref-id", retryPolicy{}, "")
+	require.NoError(t, err)
+
+	require.NoError(t, mc.push(context.Background(), &pbcloud.MetricSet{}))
+	assert.Equal(t, 1, reqs)
+}
+
+func TestNewHTTPTransportErrors(t *testing.T) {
+	t.Parallel()
+
+	invalidCA := filepath.Join(t.TempDir(), "invalid.pem")
+	require.NoError(t, os.WriteFile(invalidCA, []byte("not a certificate"), 0o600))
+
+	testCases := map[string]cloudapi.Config{
+		"MissingCAFile":  {TLSCACert: null.StringFrom(filepath.Join(t.TempDir(), "missing.pem"))},
+		"InvalidCAFile":  {TLSCACert: null.StringFrom(invalidCA)},
+		"CertWithoutKey": {TLSClientCert: null.StringFrom(invalidCA)},
+		"InvalidKeyPair": {
+			TLSClientCert: null.StringFrom(invalidCA),
+			TLSClientKey:  null.StringFrom(invalidCA),
+		},
+	}
+
+	for name, conf := range testCases {
+		conf := conf
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newHTTPTransport(conf)
+			assert.Error(t, err)
+		})
+	}
+}
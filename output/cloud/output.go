@@ -13,6 +13,7 @@ import (
 	"go.k6.io/k6/errext"
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/consts"
+	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/metrics"
 	"go.k6.io/k6/output"
 	cloudv2 "go.k6.io/k6/output/cloud/expv2"
@@ -57,10 +58,12 @@ type Output struct {
 	testRunID string
 
 	executionPlan []lib.ExecutionStep
+	scriptOptions lib.Options
 	duration      int64 // in seconds
 	thresholds    map[string][]*metrics.Threshold
 
 	client       *cloudapi.Client
+	fs           fsext.Fs
 	testStopFunc func(error)
 }
 
@@ -129,7 +132,9 @@ func newOutput(params output.Params) (*Output, error) {
 	return &Output{
 		config:        conf,
 		client:        apiClient,
+		fs:            params.FS,
 		executionPlan: params.ExecutionPlan,
+		scriptOptions: params.ScriptOptions,
 		duration:      int64(duration / time.Second),
 		logger:        logger,
 	}, nil
@@ -167,18 +172,11 @@ func (out *Output) Start() error {
 		return out.startVersionedOutput()
 	}
 
-	thresholds := make(map[string][]string)
-	for name, t := range out.thresholds {
-		for _, threshold := range t {
-			thresholds[name] = append(thresholds[name], threshold.Source)
-		}
-	}
-
 	testRun := &cloudapi.TestRun{
 		Name:       out.config.Name.String,
 		ProjectID:  out.config.ProjectID.Int64,
 		VUsMax:     int64(lib.GetMaxPossibleVUs(out.executionPlan)),
-		Thresholds: thresholds,
+		Thresholds: out.thresholdSources(),
 		Duration:   out.duration,
 	}
 
@@ -223,6 +221,17 @@ func (out *Output) SetThresholds(scriptThresholds map[string]metrics.Thresholds)
 	out.thresholds = thresholds
 }
 
+// thresholdSources returns the source expressions of the thresholds, by metric.
+func (out *Output) thresholdSources() map[string][]string {
+	thresholds := make(map[string][]string)
+	for name, t := range out.thresholds {
+		for _, threshold := range t {
+			thresholds[name] = append(thresholds[name], threshold.Source)
+		}
+	}
+	return thresholds
+}
+
 // SetTestRunStopCallback receives the function that stops the engine on error
 func (out *Output) SetTestRunStopCallback(stopFunc func(error)) {
 	out.testStopFunc = stopFunc
@@ -273,6 +282,12 @@ func (out *Output) testFinished(testErr error) error {
 		}
 	}
 
+	// the script can mark the test run as failed, without stopping it
+	var abortReasonErr errext.HasAbortReason
+	if errors.As(testErr, &abortReasonErr) && abortReasonErr.AbortReason() == errext.AbortedByScriptFail {
+		testTainted = true
+	}
+
 	runStatus := out.getRunStatus(testErr)
 	out.logger.WithFields(logrus.Fields{
 		"ref":        out.testRunID,
@@ -305,6 +320,10 @@ func (out *Output) getRunStatus(testErr error) cloudapi.RunStatus {
 			return cloudapi.RunStatusAbortedLimit
 		case errext.AbortedByOutput:
 			return cloudapi.RunStatusAbortedSystem
+		case errext.AbortedByScriptFail:
+			// The test run finished normally, the failure is only communicated
+			// via result_status, like for the thresholds below.
+			return cloudapi.RunStatusFinished
 		case errext.AbortedByThresholdsAfterTestEnd:
 			// The test run finished normally, it wasn't prematurely aborted by
 			// anything while running, but the thresholds failed at the end and
@@ -349,7 +368,13 @@ func (out *Output) startVersionedOutput() error {
 	case int64(apiVersion1):
 		out.versionedOutput, err = cloudv1.New(out.logger, out.config, out.client)
 	case int64(apiVersion2):
-		out.versionedOutput, err = cloudv2.New(out.logger, out.config, out.client)
+		var v2 *cloudv2.Output
+		v2, err = cloudv2.New(out.logger, out.config, out.client)
+		if err == nil {
+			v2.SetFS(out.fs)
+			v2.SetTestRunMetadata(out.scriptOptions, out.thresholdSources())
+			out.versionedOutput = v2
+		}
 	default:
 		err = fmt.Errorf("v%d is an unexpected version", out.config.APIVersion.Int64)
 	}
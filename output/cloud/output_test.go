@@ -324,6 +324,15 @@ func TestOutputGetStatusRun(t *testing.T) {
 		)
 		assert.Equal(t, cloudapi.RunStatusAbortedSystem, o.getRunStatus(errWithReason))
 	})
+	t.Run("WithScriptFail", func(t *testing.T) {
+		t.Parallel()
+		o := Output{}
+		errWithReason := errext.WithAbortReasonIfNone(
+			errors.New("my-failure"),
+			errext.AbortedByScriptFail,
+		)
+		assert.Equal(t, cloudapi.RunStatusFinished, o.getRunStatus(errWithReason))
+	})
 }
 
 func TestOutputProxyAddMetricSamples(t *testing.T) {
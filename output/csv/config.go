@@ -19,6 +19,10 @@ type Config struct {
 	FileName     null.String        `json:"file_name" envconfig:"K6_CSV_FILENAME"`
 	SaveInterval types.NullDuration `json:"save_interval" envconfig:"K6_CSV_SAVE_INTERVAL"`
 	TimeFormat   null.String        `json:"time_format" envconfig:"K6_CSV_TIME_FORMAT"`
+
+	// Metadata.
+	MetadataInclude []string `json:"metadata_include" envconfig:"K6_CSV_METADATA_INCLUDE"`
+	MetadataExclude []string `json:"metadata_exclude" envconfig:"K6_CSV_METADATA_EXCLUDE"`
 }
 
 // TimeFormat custom enum type
@@ -56,6 +60,12 @@ func (c Config) Apply(cfg Config) Config {
 	if cfg.TimeFormat.Valid {
 		c.TimeFormat = cfg.TimeFormat
 	}
+	if cfg.MetadataInclude != nil {
+		c.MetadataInclude = cfg.MetadataInclude
+	}
+	if cfg.MetadataExclude != nil {
+		c.MetadataExclude = cfg.MetadataExclude
+	}
 	return c
 }
 
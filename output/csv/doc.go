@@ -1,4 +1,8 @@
 /*
 Package csv implements an output writing metrics in csv format
+
+Each sample is written as a single row, the weight of the weighted samples
+(the number of occurrences of their value) isn't written, so they are
+indistinguishable from the samples of a single occurrence.
 */
 package csv
@@ -31,11 +31,12 @@ type Output struct {
 	csvLock   sync.Mutex
 	closeFn   func() error
 
-	resTags      []string
-	ignoredTags  []string
-	row          []string
-	saveInterval time.Duration
-	timeFormat   TimeFormat
+	resTags        []string
+	ignoredTags    []string
+	row            []string
+	saveInterval   time.Duration
+	timeFormat     TimeFormat
+	metadataFilter output.MetadataFilter
 }
 
 // New Creates new instance of CSV output
@@ -86,21 +87,23 @@ func newOutput(params output.Params) (*Output, error) {
 	}
 
 	saveInterval := config.SaveInterval.TimeDuration()
+	metadataFilter := output.NewMetadataFilter(config.MetadataInclude, config.MetadataExclude)
 	fname := config.FileName.String
 
 	if fname == "" || fname == "-" {
 		stdoutWriter := csv.NewWriter(os.Stdout)
 		return &Output{
-			fname:        "-",
-			resTags:      resTags,
-			ignoredTags:  ignoredTags,
-			csvWriter:    stdoutWriter,
-			row:          make([]string, 3+len(resTags)+2),
-			saveInterval: saveInterval,
-			timeFormat:   timeFormat,
-			closeFn:      func() error { return nil },
-			logger:       logger,
-			params:       params,
+			fname:          "-",
+			resTags:        resTags,
+			ignoredTags:    ignoredTags,
+			csvWriter:      stdoutWriter,
+			row:            make([]string, 3+len(resTags)+2),
+			saveInterval:   saveInterval,
+			timeFormat:     timeFormat,
+			metadataFilter: metadataFilter,
+			closeFn:        func() error { return nil },
+			logger:         logger,
+			params:         params,
 		}, nil
 	}
 
@@ -110,14 +113,15 @@ func newOutput(params output.Params) (*Output, error) {
 	}
 
 	c := Output{
-		fname:        fname,
-		resTags:      resTags,
-		ignoredTags:  ignoredTags,
-		row:          make([]string, 3+len(resTags)+2),
-		saveInterval: saveInterval,
-		timeFormat:   timeFormat,
-		logger:       logger,
-		params:       params,
+		fname:          fname,
+		resTags:        resTags,
+		ignoredTags:    ignoredTags,
+		row:            make([]string, 3+len(resTags)+2),
+		saveInterval:   saveInterval,
+		timeFormat:     timeFormat,
+		metadataFilter: metadataFilter,
+		logger:         logger,
+		params:         params,
 	}
 
 	if strings.HasSuffix(fname, ".gz") {
@@ -184,6 +188,7 @@ func (o *Output) flushMetrics() {
 		for _, sc := range samples {
 			for _, sample := range sc.GetSamples() {
 				sample := sample
+				sample.Metadata = o.metadataFilter.Filter(sample.Metadata)
 				row := SampleToRow(&sample, o.resTags, o.ignoredTags, o.row, o.timeFormat)
 				err := o.csvWriter.Write(row)
 				if err != nil {
@@ -267,8 +272,13 @@ func SampleToRow(sample *metrics.Sample, resTags []string, ignoredTags []string,
 	extraTags.Reset()
 	prev = false
 
-	for key, val := range sample.Metadata {
-		if !writeTag(key, val) {
+	metadataKeys := make([]string, 0, len(sample.Metadata))
+	for key := range sample.Metadata {
+		metadataKeys = append(metadataKeys, key)
+	}
+	sort.Strings(metadataKeys)
+	for _, key := range metadataKeys {
+		if !writeTag(key, sample.Metadata[key]) {
 			break
 		}
 	}
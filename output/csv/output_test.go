@@ -357,6 +357,7 @@ func TestRun(t *testing.T) {
 		fileName       string
 		fileReaderFunc func(fileName string, fs fsext.Fs) string
 		timeFormat     string
+		metadataFilter string
 		outputContent  string
 	}{
 		{
@@ -468,6 +469,26 @@ func TestRun(t *testing.T) {
 				"my_metric," + time.Unix(1562324644, 0).Format(time.RFC3339) + ",1.000000,val1,val3,url=val2,\n" +
 				"my_metric," + time.Unix(1562324644, 0).Format(time.RFC3339) + ",1.000000,val1,val3,name=val4&url=val2,y=2&z=3\n",
 		},
+		{
+			samples: []metrics.SampleContainer{
+				metrics.Sample{
+					TimeSeries: metrics.TimeSeries{
+						Metric: testMetric,
+						Tags: registry.RootTagSet().WithTagsFromMap(map[string]string{
+							"check": "val1",
+						}),
+					},
+					Time:     time.Unix(1562324644, 0),
+					Metadata: map[string]string{"y": "2", "z": "3", "trace_id": "abc"},
+					Value:    1,
+				},
+			},
+			fileName:       "test",
+			fileReaderFunc: readUnCompressedFile,
+			metadataFilter: "z",
+			outputContent: "metric_name,timestamp,metric_value,check,error,extra_tags,metadata\n" +
+				"my_metric,1562324644,1.000000,val1,,,trace_id=abc&y=2\n",
+		},
 	}
 
 	for i, data := range testData {
@@ -480,6 +501,9 @@ func TestRun(t *testing.T) {
 			if data.timeFormat != "" {
 				env["K6_CSV_TIME_FORMAT"] = data.timeFormat
 			}
+			if data.metadataFilter != "" {
+				env["K6_CSV_METADATA_EXCLUDE"] = data.metadataFilter
+			}
 
 			output, err := newOutput(output.Params{
 				Logger:         testutils.NewLogger(t),
@@ -30,6 +30,10 @@ type Config struct {
 	Retention    null.String `json:"retention,omitempty" envconfig:"K6_INFLUXDB_RETENTION"`
 	Consistency  null.String `json:"consistency,omitempty" envconfig:"K6_INFLUXDB_CONSISTENCY"`
 	TagsAsFields []string    `json:"tagsAsFields,omitempty" envconfig:"K6_INFLUXDB_TAGS_AS_FIELDS"`
+
+	// Metadata, always written as fields.
+	MetadataInclude []string `json:"metadataInclude,omitempty" envconfig:"K6_INFLUXDB_METADATA_INCLUDE"`
+	MetadataExclude []string `json:"metadataExclude,omitempty" envconfig:"K6_INFLUXDB_METADATA_EXCLUDE"`
 }
 
 // NewConfig creates a new InfluxDB output config with some default values.
@@ -84,6 +88,12 @@ func (c Config) Apply(cfg Config) Config {
 	if len(cfg.TagsAsFields) > 0 {
 		c.TagsAsFields = cfg.TagsAsFields
 	}
+	if len(cfg.MetadataInclude) > 0 {
+		c.MetadataInclude = cfg.MetadataInclude
+	}
+	if len(cfg.MetadataExclude) > 0 {
+		c.MetadataExclude = cfg.MetadataExclude
+	}
 	if cfg.PushInterval.Valid {
 		c.PushInterval = cfg.PushInterval
 	}
@@ -159,6 +169,10 @@ func ParseURL(text string) (Config, error) {
 			c.ConcurrentWrites = null.IntFrom(int64(writes))
 		case "tagsAsFields":
 			c.TagsAsFields = vs
+		case "metadataInclude":
+			c.MetadataInclude = vs
+		case "metadataExclude":
+			c.MetadataExclude = vs
 		default:
 			return c, fmt.Errorf("unknown query parameter: %s", k)
 		}
@@ -40,6 +40,7 @@ type Output struct {
 	logger          logrus.FieldLogger
 	params          output.Params
 	fieldKinds      map[string]FieldKind
+	metadataFilter  output.MetadataFilter
 	periodicFlusher *output.PeriodicFlusher
 	semaphoreCh     chan struct{}
 	wg              sync.WaitGroup
@@ -69,47 +70,63 @@ func newOutput(params output.Params) (*Output, error) {
 		logger: params.Logger.WithFields(logrus.Fields{
 			"output": "InfluxDBv1",
 		}),
-		Client:      cl,
-		Config:      conf,
-		BatchConf:   batchConf,
-		fieldKinds:  fldKinds,
-		semaphoreCh: make(chan struct{}, conf.ConcurrentWrites.Int64),
-		wg:          sync.WaitGroup{},
+		Client:         cl,
+		Config:         conf,
+		BatchConf:      batchConf,
+		fieldKinds:     fldKinds,
+		metadataFilter: output.NewMetadataFilter(conf.MetadataInclude, conf.MetadataExclude),
+		semaphoreCh:    make(chan struct{}, conf.ConcurrentWrites.Int64),
+		wg:             sync.WaitGroup{},
 	}, err
 }
 
 func (o *Output) extractTagsToValues(tags map[string]string, values map[string]interface{}) map[string]interface{} {
 	for tag, kind := range o.fieldKinds {
 		if val, ok := tags[tag]; ok {
-			var v interface{}
-			var err error
-			switch kind {
-			case String:
-				v = val
-			case Bool:
-				v, err = strconv.ParseBool(val)
-			case Float:
-				v, err = strconv.ParseFloat(val, 64)
-			case Int:
-				v, err = strconv.ParseInt(val, 10, 64)
-			}
-			if err == nil {
-				values[tag] = v
-			} else {
-				values[tag] = val
-			}
+			values[tag] = fieldValue(kind, val)
 			delete(tags, tag)
 		}
 	}
 	return values
 }
 
+// extractMetadataToValues adds the sample metadata to the point's fields,
+// converted to the kind configured for them with tagsAsFields, if any.
+func (o *Output) extractMetadataToValues(metadata map[string]string, values map[string]interface{}) {
+	for key, val := range o.metadataFilter.Filter(metadata) {
+		values[key] = fieldValue(o.fieldKinds[key], val)
+	}
+}
+
+// fieldValue converts the value to the given kind, or it
+// returns it unchanged, as a string, if that isn't possible.
+func fieldValue(kind FieldKind, val string) interface{} {
+	var v interface{}
+	var err error
+	switch kind {
+	case String:
+		v = val
+	case Bool:
+		v, err = strconv.ParseBool(val)
+	case Float:
+		v, err = strconv.ParseFloat(val, 64)
+	case Int:
+		v, err = strconv.ParseInt(val, 10, 64)
+	}
+	if err != nil {
+		return val
+	}
+	return v
+}
+
 func (o *Output) batchFromSamples(containers []metrics.SampleContainer) (client.BatchPoints, error) {
 	batch, err := client.NewBatchPoints(o.BatchConf)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't make a batch: %w", err)
 	}
 
+	// the cached values are only the ones extracted from the tags,
+	// each point gets its own copy with its metadata and value
 	type cacheItem struct {
 		tags   map[string]string
 		values map[string]interface{}
@@ -118,19 +135,23 @@ func (o *Output) batchFromSamples(containers []metrics.SampleContainer) (client.
 	for _, container := range containers {
 		samples := container.GetSamples()
 		for _, sample := range samples {
-			var tags map[string]string
-			values := make(map[string]interface{})
-			if cached, ok := cache[sample.Tags]; ok {
-				tags = cached.tags
-				for k, v := range cached.values {
-					values[k] = v
-				}
-			} else {
-				tags = sample.Tags.Map()
-				o.extractTagsToValues(tags, values)
-				cache[sample.Tags] = cacheItem{tags, values}
+			cached, ok := cache[sample.Tags]
+			if !ok {
+				cached.tags = sample.Tags.Map()
+				cached.values = o.extractTagsToValues(cached.tags, make(map[string]interface{}))
+				cache[sample.Tags] = cached
+			}
+			tags := cached.tags
+			values := make(map[string]interface{}, len(cached.values)+len(sample.Metadata)+1)
+			for k, v := range cached.values {
+				values[k] = v
 			}
+			o.extractMetadataToValues(sample.Metadata, values)
 			values["value"] = sample.Value
+			// the number of occurrences of the value, for the pre-aggregated samples
+			if sample.Weight > 1 {
+				values["weight"] = int64(sample.Weight)
+			}
 			var p *client.Point
 			p, err = client.NewPoint(
 				sample.Metric.Name,
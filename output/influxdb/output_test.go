@@ -215,3 +215,69 @@ func TestExtractTagsToValues(t *testing.T) {
 	require.Equal(t, 3.14, values["floatField"])
 	require.Equal(t, int64(12345), values["intField"])
 }
+
+func TestExtractMetadataToValues(t *testing.T) {
+	t.Parallel()
+	o, err := newOutput(output.Params{
+		Logger:         testutils.NewLogger(t),
+		ConfigArgument: "?tagsAsFields=vu:int&metadataExclude=secret",
+	})
+	require.NoError(t, err)
+
+	values := map[string]interface{}{}
+	o.extractMetadataToValues(map[string]string{"vu": "12", "trace_id": "abc", "secret": "s3cr3t"}, values)
+	assert.Equal(t, map[string]interface{}{"vu": int64(12), "trace_id": "abc"}, values)
+}
+
+func TestBatchFromSamplesMetadata(t *testing.T) {
+	t.Parallel()
+	o, err := newOutput(output.Params{
+		Logger:         testutils.NewLogger(t),
+		ConfigArgument: "?tagsAsFields=url",
+	})
+	require.NoError(t, err)
+
+	registry := metrics.NewRegistry()
+	m, err := registry.NewMetric("test_metric", metrics.Trend)
+	require.NoError(t, err)
+	ts := metrics.TimeSeries{Metric: m, Tags: registry.RootTagSet().With("url", "https://k6.io")}
+	batch, err := o.batchFromSamples([]metrics.SampleContainer{metrics.Samples{
+		{TimeSeries: ts, Time: time.Now(), Value: 1, Metadata: map[string]string{"trace_id": "abc"}},
+		{TimeSeries: ts, Time: time.Now(), Value: 2},
+	}})
+	require.NoError(t, err)
+
+	points := batch.Points()
+	require.Len(t, points, 2)
+	fields, err := points[0].Fields()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"url": "https://k6.io", "trace_id": "abc", "value": 1.0}, fields)
+	fields, err = points[1].Fields()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"url": "https://k6.io", "value": 2.0}, fields)
+}
+
+func TestBatchFromSamplesWeight(t *testing.T) {
+	t.Parallel()
+	o, err := newOutput(output.Params{Logger: testutils.NewLogger(t)})
+	require.NoError(t, err)
+
+	registry := metrics.NewRegistry()
+	m, err := registry.NewMetric("test_metric", metrics.Trend)
+	require.NoError(t, err)
+	ts := metrics.TimeSeries{Metric: m, Tags: registry.RootTagSet()}
+	batch, err := o.batchFromSamples([]metrics.SampleContainer{metrics.Samples{
+		{TimeSeries: ts, Time: time.Now(), Value: 1, Weight: 3},
+		{TimeSeries: ts, Time: time.Now(), Value: 2},
+	}})
+	require.NoError(t, err)
+
+	points := batch.Points()
+	require.Len(t, points, 2)
+	fields, err := points[0].Fields()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"value": 1.0, "weight": int64(3)}, fields)
+	fields, err = points[1].Fields()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"value": 2.0}, fields)
+}
@@ -0,0 +1,48 @@
+package json
+
+import (
+	"encoding/json"
+
+	"github.com/mstoykov/envconfig"
+)
+
+// Config is the config for the json output. The file name isn't part of it,
+// since it is always provided as the output's argument.
+type Config struct {
+	MetadataInclude []string `json:"metadataInclude" envconfig:"K6_JSON_METADATA_INCLUDE"`
+	MetadataExclude []string `json:"metadataExclude" envconfig:"K6_JSON_METADATA_EXCLUDE"`
+}
+
+// Apply merges two configs by overwriting properties in the old config
+func (c Config) Apply(cfg Config) Config {
+	if cfg.MetadataInclude != nil {
+		c.MetadataInclude = cfg.MetadataInclude
+	}
+	if cfg.MetadataExclude != nil {
+		c.MetadataExclude = cfg.MetadataExclude
+	}
+	return c
+}
+
+// GetConsolidatedConfig combines {default config values + JSON config +
+// environment vars}, and returns the final result.
+func GetConsolidatedConfig(jsonRawConf json.RawMessage, env map[string]string) (Config, error) {
+	result := Config{}
+	if jsonRawConf != nil {
+		jsonConf := Config{}
+		if err := json.Unmarshal(jsonRawConf, &jsonConf); err != nil {
+			return result, err
+		}
+		result = result.Apply(jsonConf)
+	}
+
+	envConfig := Config{}
+	if err := envconfig.Process("", &envConfig, func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}); err != nil {
+		return result, err
+	}
+
+	return result.Apply(envConfig), nil
+}
@@ -25,16 +25,22 @@ type Output struct {
 	params          output.Params
 	periodicFlusher *output.PeriodicFlusher
 
-	logger      logrus.FieldLogger
-	filename    string
-	out         io.Writer
-	closeFn     func() error
-	seenMetrics map[string]struct{}
-	thresholds  map[string]metrics.Thresholds
+	logger         logrus.FieldLogger
+	filename       string
+	out            io.Writer
+	closeFn        func() error
+	seenMetrics    map[string]struct{}
+	thresholds     map[string]metrics.Thresholds
+	metadataFilter output.MetadataFilter
 }
 
 // New returns a new JSON output.
 func New(params output.Params) (output.Output, error) {
+	config, err := GetConsolidatedConfig(params.JSONConfig, params.Environment)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Output{
 		params:   params,
 		filename: params.ConfigArgument,
@@ -42,7 +48,8 @@ func New(params output.Params) (output.Output, error) {
 			"output":   "json",
 			"filename": params.ConfigArgument,
 		}),
-		seenMetrics: make(map[string]struct{}),
+		seenMetrics:    make(map[string]struct{}),
+		metadataFilter: output.NewMetadataFilter(config.MetadataInclude, config.MetadataExclude),
 	}, nil
 }
 
@@ -129,6 +136,7 @@ func (o *Output) flushMetrics() {
 		count += len(samples)
 		for _, sample := range samples {
 			sample := sample
+			sample.Metadata = o.metadataFilter.Filter(sample.Metadata)
 			o.handleMetric(sample.Metric, jw)
 			wrapSample(sample).MarshalEasyJSON(jw)
 			jw.RawByte('\n')
@@ -157,6 +165,8 @@ func (o *Output) handleMetric(m *metrics.Metric, jw *jwriter.Writer) {
 	wrapped.Data.Name = m.Name
 	wrapped.Data.Type = m.Type
 	wrapped.Data.Contains = m.Contains
+	wrapped.Data.Unit = m.Unit
+	wrapped.Data.Description = m.Description
 	wrapped.Data.Submetrics = m.Submetrics
 
 	if ts, ok := o.thresholds[m.Name]; ok {
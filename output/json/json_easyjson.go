@@ -88,6 +88,7 @@ func (v *sampleEnvelope) UnmarshalEasyJSON(l *jlexer.Lexer) {
 func easyjson42239ddeDecode(in *jlexer.Lexer, out *struct {
 	Time     time.Time         `json:"time"`
 	Value    float64           `json:"value"`
+	Weight   uint64            `json:"weight,omitempty"`
 	Tags     *metrics.TagSet   `json:"tags"`
 	Metadata map[string]string `json:"metadata,omitempty"`
 }) {
@@ -115,6 +116,8 @@ func easyjson42239ddeDecode(in *jlexer.Lexer, out *struct {
 			}
 		case "value":
 			out.Value = float64(in.Float64())
+		case "weight":
+			out.Weight = uint64(in.Uint64())
 		case "tags":
 			if in.IsNull() {
 				in.Skip()
@@ -158,6 +161,7 @@ func easyjson42239ddeDecode(in *jlexer.Lexer, out *struct {
 func easyjson42239ddeEncode(out *jwriter.Writer, in struct {
 	Time     time.Time         `json:"time"`
 	Value    float64           `json:"value"`
+	Weight   uint64            `json:"weight,omitempty"`
 	Tags     *metrics.TagSet   `json:"tags"`
 	Metadata map[string]string `json:"metadata,omitempty"`
 }) {
@@ -174,6 +178,11 @@ func easyjson42239ddeEncode(out *jwriter.Writer, in struct {
 		out.RawString(prefix)
 		out.Float64(float64(in.Value))
 	}
+	if in.Weight != 0 {
+		const prefix string = ",\"weight\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Weight))
+	}
 	{
 		const prefix string = ",\"tags\":"
 		out.RawString(prefix)
@@ -271,11 +280,13 @@ func (v *metricEnvelope) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjson42239ddeDecodeGoK6IoK6OutputJson1(l, v)
 }
 func easyjson42239ddeDecode1(in *jlexer.Lexer, out *struct {
-	Name       string               `json:"name"`
-	Type       metrics.MetricType   `json:"type"`
-	Contains   metrics.ValueType    `json:"contains"`
-	Thresholds metrics.Thresholds   `json:"thresholds"`
-	Submetrics []*metrics.Submetric `json:"submetrics"`
+	Name        string               `json:"name"`
+	Type        metrics.MetricType   `json:"type"`
+	Contains    metrics.ValueType    `json:"contains"`
+	Unit        string               `json:"unit,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Thresholds  metrics.Thresholds   `json:"thresholds"`
+	Submetrics  []*metrics.Submetric `json:"submetrics"`
 }) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
@@ -305,6 +316,10 @@ func easyjson42239ddeDecode1(in *jlexer.Lexer, out *struct {
 			if data := in.UnsafeBytes(); in.Ok() {
 				in.AddError((out.Contains).UnmarshalText(data))
 			}
+		case "unit":
+			out.Unit = string(in.String())
+		case "description":
+			out.Description = string(in.String())
 		case "thresholds":
 			if data := in.Raw(); in.Ok() {
 				in.AddError((out.Thresholds).UnmarshalJSON(data))
@@ -351,11 +366,13 @@ func easyjson42239ddeDecode1(in *jlexer.Lexer, out *struct {
 	}
 }
 func easyjson42239ddeEncode1(out *jwriter.Writer, in struct {
-	Name       string               `json:"name"`
-	Type       metrics.MetricType   `json:"type"`
-	Contains   metrics.ValueType    `json:"contains"`
-	Thresholds metrics.Thresholds   `json:"thresholds"`
-	Submetrics []*metrics.Submetric `json:"submetrics"`
+	Name        string               `json:"name"`
+	Type        metrics.MetricType   `json:"type"`
+	Contains    metrics.ValueType    `json:"contains"`
+	Unit        string               `json:"unit,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Thresholds  metrics.Thresholds   `json:"thresholds"`
+	Submetrics  []*metrics.Submetric `json:"submetrics"`
 }) {
 	out.RawByte('{')
 	first := true
@@ -375,6 +392,16 @@ func easyjson42239ddeEncode1(out *jwriter.Writer, in struct {
 		out.RawString(prefix)
 		out.Raw((in.Contains).MarshalJSON())
 	}
+	if in.Unit != "" {
+		const prefix string = ",\"unit\":"
+		out.RawString(prefix)
+		out.String(string(in.Unit))
+	}
+	if in.Description != "" {
+		const prefix string = ",\"description\":"
+		out.RawString(prefix)
+		out.String(string(in.Description))
+	}
 	{
 		const prefix string = ",\"thresholds\":"
 		out.RawString(prefix)
@@ -43,7 +43,7 @@ func generateTestMetricSamples(t testing.TB) ([]metrics.SampleContainer, func(io
 	_, err = metric1.AddSubmetric("a:1,b:2")
 	require.NoError(t, err)
 
-	metric2, err := registry.NewMetric("my_metric2", metrics.Counter, metrics.Data)
+	metric2, err := registry.NewMetricWithInfo("my_metric2", metrics.Counter, metrics.UnitBytes, "The sent data", metrics.Data)
 	require.NoError(t, err)
 
 	time1 := time.Date(2021, time.February, 24, 13, 37, 10, 0, time.UTC)
@@ -104,7 +104,7 @@ func generateTestMetricSamples(t testing.TB) ([]metrics.SampleContainer, func(io
 		`{"type":"Metric","data":{"name":"my_metric1","type":"gauge","contains":"default","thresholds":["rate<0.01","p(99)<250"],"submetrics":[{"name":"my_metric1{a:1,b:2}","suffix":"a:1,b:2","tags":{"a":"1","b":"2"}}]},"metric":"my_metric1"}`,
 		`{"type":"Point","data":{"time":"2021-02-24T13:37:10Z","value":1,"tags":{"tag1":"val1"},"metadata":{"meta1":"foo","meta2":"bar"}},"metric":"my_metric1"}`,
 		`{"type":"Point","data":{"time":"2021-02-24T13:37:10Z","value":2,"tags":{"tag2":"val2"}},"metric":"my_metric1"}`,
-		`{"type":"Metric","data":{"name":"my_metric2","type":"counter","contains":"data","thresholds":[],"submetrics":null},"metric":"my_metric2"}`,
+		`{"type":"Metric","data":{"name":"my_metric2","type":"counter","contains":"data","unit":"bytes","description":"The sent data","thresholds":[],"submetrics":null},"metric":"my_metric2"}`,
 		`{"type":"Point","data":{"time":"2021-02-24T13:37:20Z","value":3,"tags":{"key":"val"}},"metric":"my_metric2"}`,
 		`{"type":"Point","data":{"time":"2021-02-24T13:37:20Z","value":4,"tags":{"key":"val"}},"metric":"my_metric1"}`,
 		`{"type":"Point","data":{"time":"2021-02-24T13:37:30Z","value":5,"tags":{"tag3":"val3","tag4":"val4"},"metadata":{"meta3":"metaval3"}},"metric":"my_metric2"}`,
@@ -133,6 +133,33 @@ func TestJsonOutputStdout(t *testing.T) {
 	validateResults(stdout)
 }
 
+func TestJsonOutputMetadataFilter(t *testing.T) {
+	t.Parallel()
+
+	stdout := new(bytes.Buffer)
+	out, err := New(output.Params{
+		Logger:      testutils.NewLogger(t),
+		StdOut:      stdout,
+		Environment: map[string]string{"K6_JSON_METADATA_EXCLUDE": "meta2,meta3"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, out.Start())
+
+	samples, _ := generateTestMetricSamples(t)
+	out.AddMetricSamples(samples)
+	require.NoError(t, out.Stop())
+
+	getValidator(t, []string{
+		`{"type":"Metric","data":{"name":"my_metric1","type":"gauge","contains":"default","thresholds":[],"submetrics":[{"name":"my_metric1{a:1,b:2}","suffix":"a:1,b:2","tags":{"a":"1","b":"2"}}]},"metric":"my_metric1"}`,
+		`{"type":"Point","data":{"time":"2021-02-24T13:37:10Z","value":1,"tags":{"tag1":"val1"},"metadata":{"meta1":"foo"}},"metric":"my_metric1"}`,
+		`{"type":"Point","data":{"time":"2021-02-24T13:37:10Z","value":2,"tags":{"tag2":"val2"}},"metric":"my_metric1"}`,
+		`{"type":"Metric","data":{"name":"my_metric2","type":"counter","contains":"data","unit":"bytes","description":"The sent data","thresholds":[],"submetrics":null},"metric":"my_metric2"}`,
+		`{"type":"Point","data":{"time":"2021-02-24T13:37:20Z","value":3,"tags":{"key":"val"}},"metric":"my_metric2"}`,
+		`{"type":"Point","data":{"time":"2021-02-24T13:37:20Z","value":4,"tags":{"key":"val"}},"metric":"my_metric1"}`,
+		`{"type":"Point","data":{"time":"2021-02-24T13:37:30Z","value":5,"tags":{"tag3":"val3","tag4":"val4"}},"metric":"my_metric2"}`,
+	})(stdout)
+}
+
 func TestJsonOutputFileError(t *testing.T) {
 	t.Parallel()
 
@@ -15,6 +15,7 @@ type sampleEnvelope struct {
 	Data   struct {
 		Time     time.Time         `json:"time"`
 		Value    float64           `json:"value"`
+		Weight   uint64            `json:"weight,omitempty"`
 		Tags     *metrics.TagSet   `json:"tags"`
 		Metadata map[string]string `json:"metadata,omitempty"`
 	} `json:"data"`
@@ -29,6 +30,7 @@ func wrapSample(sample metrics.Sample) sampleEnvelope {
 	}
 	s.Data.Time = sample.Time
 	s.Data.Value = sample.Value
+	s.Data.Weight = sample.Weight
 	s.Data.Tags = sample.Tags
 	s.Data.Metadata = sample.Metadata
 	return s
@@ -38,11 +40,13 @@ func wrapSample(sample metrics.Sample) sampleEnvelope {
 type metricEnvelope struct {
 	Type string `json:"type"`
 	Data struct {
-		Name       string               `json:"name"`
-		Type       metrics.MetricType   `json:"type"`
-		Contains   metrics.ValueType    `json:"contains"`
-		Thresholds metrics.Thresholds   `json:"thresholds"`
-		Submetrics []*metrics.Submetric `json:"submetrics"`
+		Name        string               `json:"name"`
+		Type        metrics.MetricType   `json:"type"`
+		Contains    metrics.ValueType    `json:"contains"`
+		Unit        string               `json:"unit,omitempty"`
+		Description string               `json:"description,omitempty"`
+		Thresholds  metrics.Thresholds   `json:"thresholds"`
+		Submetrics  []*metrics.Submetric `json:"submetrics"`
 	} `json:"data"`
 	Metric string `json:"metric"`
 }
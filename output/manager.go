@@ -16,6 +16,8 @@ type Manager struct {
 	outputs []Output
 	logger  logrus.FieldLogger
 
+	dropWarmupSamples bool
+
 	testStopCallback func(error)
 }
 
@@ -28,6 +30,13 @@ func NewManager(outputs []Output, logger logrus.FieldLogger, testStopCallback fu
 	}
 }
 
+// DropWarmupSamples makes the manager drop the metric samples from the warm-up
+// periods of the scenarios, instead of sending them to the outputs. It has to
+// be called before Start().
+func (om *Manager) DropWarmupSamples() {
+	om.dropWarmupSamples = true
+}
+
 // Start spins up all configured outputs and then starts a new goroutine that
 // pipes metrics from the given samples channel to them.
 //
@@ -48,6 +57,9 @@ func (om *Manager) Start(samplesChan chan metrics.SampleContainer) (wait func(),
 	wg.Add(1)
 
 	sendToOutputs := func(sampleContainers []metrics.SampleContainer) {
+		if om.dropWarmupSamples {
+			sampleContainers = withoutWarmupSamples(sampleContainers)
+		}
 		for _, out := range om.outputs {
 			out.AddMetricSamples(sampleContainers)
 		}
@@ -117,3 +129,26 @@ func (om *Manager) stopOutputs(testErr error, upToID int) {
 		}
 	}
 }
+
+// withoutWarmupSamples filters out the sample containers that only have samples
+// from the warm-up periods of the scenarios. The containers are kept as a
+// whole otherwise, since some outputs rely on their concrete types.
+func withoutWarmupSamples(sampleContainers []metrics.SampleContainer) []metrics.SampleContainer {
+	result := sampleContainers[:0]
+	for _, sampleContainer := range sampleContainers {
+		if !isWarmupContainer(sampleContainer) {
+			result = append(result, sampleContainer)
+		}
+	}
+	return result
+}
+
+func isWarmupContainer(sampleContainer metrics.SampleContainer) bool {
+	samples := sampleContainer.GetSamples()
+	for _, sample := range samples {
+		if !metrics.IsWarmup(sample.Tags) {
+			return false
+		}
+	}
+	return len(samples) > 0
+}
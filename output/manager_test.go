@@ -0,0 +1,49 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/lib/testutils/mockoutput"
+	"go.k6.io/k6/metrics"
+)
+
+func TestManagerDropWarmupSamples(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	metric := registry.MustNewMetric("test_metric", metrics.Counter)
+	warmupTags := registry.RootTagSet().With(metrics.WarmupTagName, "true")
+	getSample := func(tags *metrics.TagSet, value float64) metrics.Sample {
+		return metrics.Sample{TimeSeries: metrics.TimeSeries{Metric: metric, Tags: tags}, Value: value}
+	}
+
+	for _, drop := range []bool{false, true} {
+		mockOut := mockoutput.New()
+		manager := NewManager([]Output{mockOut}, testutils.NewLogger(t), nil)
+		if drop {
+			manager.DropWarmupSamples()
+		}
+
+		samples := make(chan metrics.SampleContainer, 10)
+		wait, finish, err := manager.Start(samples)
+		require.NoError(t, err)
+		samples <- getSample(warmupTags, 1)
+		samples <- getSample(registry.RootTagSet(), 2)
+		samples <- metrics.Samples{getSample(warmupTags, 3), getSample(registry.RootTagSet(), 4)}
+		close(samples)
+		wait()
+		finish(nil)
+
+		if drop {
+			assert.Len(t, mockOut.SampleContainers, 2)
+			assert.Len(t, mockOut.Samples, 3)
+		} else {
+			assert.Len(t, mockOut.SampleContainers, 3)
+			assert.Len(t, mockOut.Samples, 4)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package output
+
+// MetadataFilter selects which of the metric samples' metadata entries an
+// output serializes. The metadata is meant for high-cardinality values, like
+// trace IDs, so outputs should always store it as non-indexed fields, unlike
+// the metric tags.
+//
+// The zero value keeps all of the metadata entries.
+type MetadataFilter struct {
+	include map[string]struct{}
+	exclude map[string]struct{}
+}
+
+// NewMetadataFilter returns a MetadataFilter that only keeps the metadata
+// entries with the included keys, if any are specified, and drops the ones
+// with the excluded keys.
+func NewMetadataFilter(include, exclude []string) MetadataFilter {
+	return MetadataFilter{
+		include: keySet(include),
+		exclude: keySet(exclude),
+	}
+}
+
+// Filter returns the metadata entries that should be serialized. The provided
+// map is returned as it is if all of its entries are kept, and it's never
+// modified, so it's safe to use it with the samples' metadata.
+func (mf MetadataFilter) Filter(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 || (len(mf.include) == 0 && len(mf.exclude) == 0) {
+		return metadata
+	}
+
+	var filtered map[string]string
+	for key, value := range metadata {
+		if !mf.Keeps(key) {
+			continue
+		}
+		if filtered == nil {
+			filtered = make(map[string]string, len(metadata))
+		}
+		filtered[key] = value
+	}
+	return filtered
+}
+
+// Keeps returns whether the metadata entry with the given key should be serialized.
+func (mf MetadataFilter) Keeps(key string) bool {
+	if _, ok := mf.exclude[key]; ok {
+		return false
+	}
+	if len(mf.include) == 0 {
+		return true
+	}
+	_, ok := mf.include[key]
+	return ok
+}
+
+func keySet(keys []string) map[string]struct{} {
+	if len(keys) == 0 {
+		return nil
+	}
+	set := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		set[key] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,49 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataFilter(t *testing.T) {
+	t.Parallel()
+
+	metadata := map[string]string{"trace_id": "abc", "vu": "1", "iter": "2"}
+	testCases := map[string]struct {
+		include, exclude []string
+		expected         map[string]string
+	}{
+		"All": {
+			expected: metadata,
+		},
+		"Include": {
+			include:  []string{"trace_id", "missing"},
+			expected: map[string]string{"trace_id": "abc"},
+		},
+		"Exclude": {
+			exclude:  []string{"vu", "iter"},
+			expected: map[string]string{"trace_id": "abc"},
+		},
+		"IncludeAndExclude": {
+			include:  []string{"trace_id", "vu"},
+			exclude:  []string{"vu"},
+			expected: map[string]string{"trace_id": "abc"},
+		},
+		"Nothing": {
+			include:  []string{"missing"},
+			expected: nil,
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			mf := NewMetadataFilter(tc.include, tc.exclude)
+			assert.Equal(t, tc.expected, mf.Filter(metadata))
+		})
+	}
+
+	assert.Len(t, metadata, 3, "the filtered metadata must not be modified")
+}
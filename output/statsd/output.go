@@ -43,7 +43,11 @@ type Output struct {
 	client *statsd.Client
 }
 
+// dispatch sends the sample to statsd. The counters include all the occurrences
+// of the weighted samples, but the other metric types are sent as a single
+// occurrence of the value, as statsd has no way to send a weight.
 func (o *Output) dispatch(entry metrics.Sample) error {
+	n := int64(entry.Occurrences())
 	var tagList []string
 	if o.config.EnableTags.Bool {
 		tagList = processTags(o.config.TagBlocklist, entry.Tags.Map())
@@ -51,21 +55,23 @@ func (o *Output) dispatch(entry metrics.Sample) error {
 
 	switch entry.Metric.Type {
 	case metrics.Counter:
-		return o.client.Count(entry.Metric.Name, int64(entry.Value), tagList, 1)
+		return o.client.Count(entry.Metric.Name, int64(entry.Value)*n, tagList, 1)
 	case metrics.Trend:
 		return o.client.TimeInMilliseconds(entry.Metric.Name, entry.Value, tagList, 1)
+	case metrics.Histogram:
+		return o.client.Histogram(entry.Metric.Name, entry.Value, tagList, 1)
 	case metrics.Gauge:
 		return o.client.Gauge(entry.Metric.Name, entry.Value, tagList, 1)
 	case metrics.Rate:
 		if check, ok := entry.Tags.Get("check"); ok {
 			return o.client.Count(
 				checkToString(check, entry.Value),
-				1,
+				n,
 				tagList,
 				1,
 			)
 		}
-		return o.client.Count(entry.Metric.Name, int64(entry.Value), tagList, 1)
+		return o.client.Count(entry.Metric.Name, int64(entry.Value)*n, tagList, 1)
 	default:
 		return fmt.Errorf("unsupported metric type %s", entry.Metric.Type)
 	}